@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// handleAccountBatchQuery handles POST /v1/accounts/batch-query, returning
+// balances, positions and open order counts for up to
+// types.MaxBatchQueryTraders traders. Duplicate addresses are queried once.
+func (s *Server) handleAccountBatchQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	batch, ok := s.accountService.(types.AccountBatchService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Batch account queries are not supported by this service")
+		return
+	}
+
+	var req types.AccountBatchQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+		return
+	}
+
+	traders := make([]string, 0, len(req.Traders))
+	seen := make(map[string]bool, len(req.Traders))
+	for _, trader := range req.Traders {
+		if trader == "" || seen[trader] {
+			continue
+		}
+		seen[trader] = true
+		traders = append(traders, trader)
+	}
+	if len(traders) == 0 {
+		writeError(w, types.ErrCodeMissingField, "traders is required")
+		return
+	}
+	if len(traders) > types.MaxBatchQueryTraders {
+		writeAPIError(w, types.NewAPIError(types.ErrCodeBatchTooLarge, "Too many traders in one query").
+			WithDetail("max", types.MaxBatchQueryTraders))
+		return
+	}
+
+	summaries, err := batch.BatchQueryAccounts(r.Context(), traders)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"accounts": summaries,
+		"count":    len(summaries),
+	})
+}
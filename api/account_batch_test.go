@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestAccountBatchQuery tests that summaries come back once per trader, in
+// request order, and that oversized batches are rejected
+func TestAccountBatchQuery(t *testing.T) {
+	svc := NewMockService()
+	ctx := context.Background()
+	if _, err := svc.Deposit(ctx, &types.DepositRequest{Trader: "alice", Amount: "500"}); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	if _, err := svc.PlaceOrder(ctx, &types.PlaceOrderRequest{
+		MarketID: "BTC-USDC", Trader: "alice", Side: "buy", Type: "limit", Price: "40000", Quantity: "0.1",
+	}); err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+	s := &Server{accountService: svc}
+
+	query := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.handleAccountBatchQuery(rec, httptest.NewRequest(http.MethodPost, "/v1/accounts/batch-query", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := query(`{"traders":["bob","alice","bob",""]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Accounts []*types.AccountSummary `json:"accounts"`
+		Count    int                     `json:"count"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Count != 2 || resp.Accounts[0].Trader != "bob" || resp.Accounts[1].Trader != "alice" {
+		t.Fatalf("expected bob then alice, got %+v", resp.Accounts)
+	}
+	if alice := resp.Accounts[1]; alice.OpenOrders != 1 || alice.Account.Balance == "0.00" {
+		t.Errorf("expected alice's order and deposit, got %d orders, balance %s", alice.OpenOrders, alice.Account.Balance)
+	}
+	if bob := resp.Accounts[0]; bob.OpenOrders != 0 || bob.Positions == nil {
+		t.Errorf("expected empty summary for bob, got %+v", bob)
+	}
+
+	if rec := query(`{"traders":[]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for empty traders, got %d", rec.Code)
+	}
+	traders := make([]string, types.MaxBatchQueryTraders+1)
+	for i := range traders {
+		traders[i] = strings.Repeat("t", i+1)
+	}
+	body, _ := json.Marshal(types.AccountBatchQueryRequest{Traders: traders})
+	if rec := query(string(body)); rec.Code == http.StatusOK {
+		t.Errorf("expected oversized batch to be rejected")
+	}
+}
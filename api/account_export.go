@@ -0,0 +1,440 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// Account export defaults
+const (
+	DefaultAccountExportTTL = 24 * time.Hour
+
+	// accountExportPageSize is the page size used to walk order and trade history
+	accountExportPageSize = 500
+	// accountExportMaxRecords caps the funding payments and transfers exported
+	accountExportMaxRecords = 100000
+)
+
+// accountExportDatasets are the files of an export, in the order written
+var accountExportDatasets = []string{"account", "orders", "trades", "funding_payments", "transfers", "pool_deposits", "pool_withdrawals"}
+
+// accountExports tracks the export jobs of POST /v1/account/export. Finished
+// exports are kept in memory until their download link expires.
+type accountExports struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu   sync.Mutex
+	jobs map[string]*accountExportJob // export ID -> job
+}
+
+type accountExportJob struct {
+	export types.AccountExport
+	token  string // download link secret
+	data   []byte // zip, once completed
+}
+
+func newAccountExports(config *Config) *accountExports {
+	ttl := config.AccountExportTTL
+	if ttl <= 0 {
+		ttl = DefaultAccountExportTTL
+	}
+	return &accountExports{ttl: ttl, now: time.Now, jobs: make(map[string]*accountExportJob)}
+}
+
+// create starts tracking a new export of trader, unless one is still running
+func (e *accountExports) create(trader string) (*accountExportJob, *types.APIError) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sweep()
+	for _, job := range e.jobs {
+		if job.export.Trader == trader && (job.export.Status == types.ExportStatusPending || job.export.Status == types.ExportStatusRunning) {
+			return nil, types.NewAPIError(types.ErrCodeExportInProgress, "An export of this account is already in progress: "+job.export.ExportID)
+		}
+	}
+	job := &accountExportJob{
+		export: types.AccountExport{
+			ExportID:  "exp_" + randomHex(12),
+			Trader:    trader,
+			Status:    types.ExportStatusPending,
+			CreatedAt: e.now().UnixMilli(),
+		},
+		token: randomHex(32),
+	}
+	e.jobs[job.export.ExportID] = job
+	return job, nil
+}
+
+// get returns a copy of an export's status, or nil when it does not exist,
+// belongs to another trader or has expired
+func (e *accountExports) get(exportID, trader string) *types.AccountExport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sweep()
+	job, ok := e.jobs[exportID]
+	if !ok || job.export.Trader != trader {
+		return nil
+	}
+	export := job.export
+	return &export
+}
+
+// download returns a completed export's zip if token matches its link
+func (e *accountExports) download(exportID, token string) ([]byte, *types.APIError) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	job, ok := e.jobs[exportID]
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(job.token)) != 1 {
+		return nil, types.NewAPIError(types.ErrCodeExportNotFound, "Export not found")
+	}
+	if job.export.Status != types.ExportStatusCompleted {
+		return nil, types.NewAPIError(types.ErrCodeExportInProgress, "Export is "+job.export.Status)
+	}
+	if e.now().UnixMilli() >= job.export.ExpiresAt {
+		delete(e.jobs, exportID)
+		return nil, types.NewAPIError(types.ErrCodeExportExpired, "Download link has expired")
+	}
+	return job.data, nil
+}
+
+// finish records the outcome of an export
+func (e *accountExports) finish(job *accountExportJob, data []byte, records map[string]int, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	job.export.CompletedAt = now.UnixMilli()
+	job.export.ExpiresAt = now.Add(e.ttl).UnixMilli()
+	if err != nil {
+		job.export.Status = types.ExportStatusFailed
+		job.export.Error = err.Error()
+		return
+	}
+	job.export.Status = types.ExportStatusCompleted
+	job.export.Records = records
+	job.export.Size = len(data)
+	job.export.DownloadURL = fmt.Sprintf("/v1/account/export/%s/download?token=%s", job.export.ExportID, job.token)
+	job.data = data
+}
+
+func (e *accountExports) setStatus(job *accountExportJob, status string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	job.export.Status = status
+}
+
+// sweep drops finished exports past their expiry; callers hold mu
+func (e *accountExports) sweep() {
+	now := e.now().UnixMilli()
+	for id, job := range e.jobs {
+		if job.export.ExpiresAt > 0 && now >= job.export.ExpiresAt {
+			delete(e.jobs, id)
+		}
+	}
+}
+
+// handleAccountExport handles POST /v1/account/export: starts compiling the
+// trader's orders, trades, funding payments, transfers and pool activity
+// into a zip and returns the export to poll
+func (s *Server) handleAccountExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+
+	job, apiErr := s.accountExports.create(trader)
+	if apiErr != nil {
+		writeAPIError(w, apiErr)
+		return
+	}
+	export := job.export
+	go s.runAccountExport(job)
+
+	writeJSON(w, http.StatusAccepted, &export)
+}
+
+// handleAccountExportJob handles GET /v1/account/export/{id} (status, for the
+// owning trader) and GET /v1/account/export/{id}/download?token= (the zip;
+// the token in the link is the credential)
+func (s *Server) handleAccountExportJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	exportID, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v1/account/export/"), "/")
+	if exportID == "" || (action != "" && action != "download") {
+		writeError(w, types.ErrCodeInvalidPath, "Invalid export path")
+		return
+	}
+
+	if action == "download" {
+		data, apiErr := s.accountExports.download(exportID, r.URL.Query().Get("token"))
+		if apiErr != nil {
+			writeAPIError(w, apiErr)
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exportID+".zip"))
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+		return
+	}
+
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+	export := s.accountExports.get(exportID, trader)
+	if export == nil {
+		writeError(w, types.ErrCodeExportNotFound, "Export not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, export)
+}
+
+// runAccountExport compiles an export in the background
+func (s *Server) runAccountExport(job *accountExportJob) {
+	s.accountExports.setStatus(job, types.ExportStatusRunning)
+	trader := job.export.Trader
+
+	datasets, err := s.collectAccountExport(context.Background(), trader)
+	var (
+		data    []byte
+		records map[string]int
+	)
+	if err == nil {
+		data, records, err = buildAccountExportZip(trader, s.accountExports.now(), datasets)
+	}
+	if err != nil {
+		log.Printf("Account export %s failed: %v", job.export.ExportID, err)
+	}
+	s.accountExports.finish(job, data, records, err)
+}
+
+// collectAccountExport reads every dataset of a trader's export. Datasets the
+// server's services do not provide are exported empty.
+func (s *Server) collectAccountExport(ctx context.Context, trader string) (map[string][]interface{}, error) {
+	datasets := make(map[string][]interface{}, len(accountExportDatasets))
+	for _, name := range accountExportDatasets {
+		datasets[name] = []interface{}{}
+	}
+
+	account, err := s.accountService.GetAccount(ctx, trader)
+	if err != nil {
+		return nil, fmt.Errorf("account: %w", err)
+	}
+	datasets["account"] = append(datasets["account"], account)
+
+	req := &types.ListOrdersRequest{Trader: trader}
+	req.Sort, req.Limit = types.SortOldest, accountExportPageSize
+	for {
+		page, err := s.orderService.ListOrders(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("orders: %w", err)
+		}
+		for _, order := range page.Orders {
+			datasets["orders"] = append(datasets["orders"], order)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		req.Cursor = page.NextCursor
+	}
+
+	if history, ok := s.orderService.(types.TradeHistoryService); ok {
+		req := &types.ListTradesRequest{Trader: trader}
+		req.Sort, req.Limit = types.SortOldest, accountExportPageSize
+		for {
+			page, err := history.ListTrades(ctx, req)
+			if err != nil {
+				return nil, fmt.Errorf("trades: %w", err)
+			}
+			for _, trade := range page.Trades {
+				datasets["trades"] = append(datasets["trades"], trade)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			req.Cursor = page.NextCursor
+		}
+	}
+
+	if funding, ok := s.accountService.(types.FundingPaymentService); ok {
+		payments, err := funding.GetFundingPayments(ctx, trader, accountExportMaxRecords)
+		if err != nil {
+			return nil, fmt.Errorf("funding payments: %w", err)
+		}
+		for _, payment := range payments {
+			datasets["funding_payments"] = append(datasets["funding_payments"], payment)
+		}
+	}
+
+	if transfers, ok := s.accountService.(types.TransferHistoryService); ok {
+		list, err := transfers.GetTransfers(ctx, trader, accountExportMaxRecords)
+		if err != nil {
+			return nil, fmt.Errorf("transfers: %w", err)
+		}
+		for _, transfer := range list {
+			datasets["transfers"] = append(datasets["transfers"], transfer)
+		}
+	}
+
+	if s.riverpoolService != nil {
+		deposits, err := s.riverpoolService.GetUserDeposits(trader)
+		if err != nil {
+			return nil, fmt.Errorf("pool deposits: %w", err)
+		}
+		for _, deposit := range deposits {
+			datasets["pool_deposits"] = append(datasets["pool_deposits"], deposit)
+		}
+		withdrawals, err := s.riverpoolService.GetUserWithdrawals(trader)
+		if err != nil {
+			return nil, fmt.Errorf("pool withdrawals: %w", err)
+		}
+		for _, withdrawal := range withdrawals {
+			datasets["pool_withdrawals"] = append(datasets["pool_withdrawals"], withdrawal)
+		}
+	}
+	return datasets, nil
+}
+
+// buildAccountExportZip writes each dataset as <name>.json and <name>.csv,
+// plus a manifest.json with the record counts
+func buildAccountExportZip(trader string, generatedAt time.Time, datasets map[string][]interface{}) ([]byte, map[string]int, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	records := make(map[string]int, len(datasets))
+
+	for _, name := range accountExportDatasets {
+		rows := datasets[name]
+		records[name] = len(rows)
+
+		f, err := zw.Create(name + ".json")
+		if err != nil {
+			return nil, nil, err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rows); err != nil {
+			return nil, nil, fmt.Errorf("%s.json: %w", name, err)
+		}
+
+		f, err = zw.Create(name + ".csv")
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := writeExportCSV(f, rows); err != nil {
+			return nil, nil, fmt.Errorf("%s.csv: %w", name, err)
+		}
+	}
+
+	f, err := zw.Create("manifest.json")
+	if err != nil {
+		return nil, nil, err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(map[string]interface{}{
+		"trader":       trader,
+		"generated_at": generatedAt.UnixMilli(),
+		"records":      records,
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), records, nil
+}
+
+// writeExportCSV writes records as CSV with one column per JSON field, in
+// sorted order. Nested values are written as JSON.
+func writeExportCSV(w io.Writer, records []interface{}) error {
+	rows := make([]map[string]interface{}, 0, len(records))
+	columns := make(map[string]bool)
+	for _, record := range records {
+		bz, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(bz))
+		dec.UseNumber()
+		var row map[string]interface{}
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		for column := range row {
+			columns[column] = true
+		}
+		rows = append(rows, row)
+	}
+	header := make([]string, 0, len(columns))
+	for column := range columns {
+		header = append(header, column)
+	}
+	sort.Strings(header)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		fields := make([]string, len(header))
+		for i, column := range header {
+			switch v := row[column].(type) {
+			case nil:
+			case string:
+				fields[i] = v
+			case json.Number:
+				fields[i] = v.String()
+			case bool:
+				fields[i] = fmt.Sprint(v)
+			default:
+				bz, _ := json.Marshal(v)
+				fields[i] = string(bz)
+			}
+		}
+		if err := cw.Write(fields); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
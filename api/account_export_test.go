@@ -0,0 +1,117 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestAccountExport tests an export from request to download: status polling
+// by the owner only, the zip contents and the expiring link
+func TestAccountExport(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+	do := func(method, path, trader string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if trader != "" {
+			req.Header.Set("X-Trader-Address", trader)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, order := range []string{
+		`{"market_id":"BTC-USDC","side":"sell","type":"limit","price":"50000","quantity":"0.1","trader":"export-maker"}`,
+		`{"market_id":"BTC-USDC","side":"buy","type":"limit","price":"50000","quantity":"0.1","trader":"export-taker"}`,
+		`{"market_id":"BTC-USDC","side":"buy","type":"limit","price":"49000","quantity":"0.2","trader":"export-taker"}`,
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(order)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("failed to place order: %d %s", rec.Code, rec.Body)
+		}
+	}
+
+	rec := do(http.MethodPost, "/v1/account/export", "export-taker")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body)
+	}
+	var export types.AccountExport
+	json.Unmarshal(rec.Body.Bytes(), &export)
+	if export.ExportID == "" || export.DownloadURL != "" {
+		t.Fatalf("unexpected new export %+v", export)
+	}
+
+	if rec := do(http.MethodGet, "/v1/account/export/"+export.ExportID, "export-maker"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected another trader to get 404, got %d", rec.Code)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for export.Status != types.ExportStatusCompleted {
+		if export.Status == types.ExportStatusFailed || time.Now().After(deadline) {
+			t.Fatalf("export did not complete: %+v", export)
+		}
+		time.Sleep(10 * time.Millisecond)
+		rec := do(http.MethodGet, "/v1/account/export/"+export.ExportID, "export-taker")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+		}
+		json.Unmarshal(rec.Body.Bytes(), &export)
+	}
+	if export.Records["orders"] != 2 || export.Records["trades"] != 1 || export.Records["account"] != 1 {
+		t.Fatalf("unexpected record counts %v", export.Records)
+	}
+
+	if rec := do(http.MethodGet, "/v1/account/export/"+export.ExportID+"/download?token=wrong", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected a wrong token to get 404, got %d", rec.Code)
+	}
+	rec = do(http.MethodGet, export.DownloadURL, "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("expected the zip, got %d: %s", rec.Code, rec.Body)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		bz, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(bz)
+	}
+	for _, name := range accountExportDatasets {
+		if _, ok := files[name+".json"]; !ok {
+			t.Errorf("missing %s.json", name)
+		}
+		if _, ok := files[name+".csv"]; !ok {
+			t.Errorf("missing %s.csv", name)
+		}
+	}
+	orders := strings.Split(strings.TrimSpace(files["orders.csv"]), "\n")
+	if len(orders) != 3 || !strings.Contains(orders[0], "order_id") || !strings.Contains(orders[2], "49000") {
+		t.Errorf("unexpected orders.csv:\n%s", files["orders.csv"])
+	}
+
+	// The link and the export expire with the TTL
+	s.accountExports.now = func() time.Time { return time.Now().Add(DefaultAccountExportTTL) }
+	if rec := do(http.MethodGet, export.DownloadURL, ""); rec.Code != http.StatusGone {
+		t.Fatalf("expected an expired link to get 410, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/v1/account/export/"+export.ExportID, "export-taker"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected an expired export to be gone, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,544 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/api/webhook"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Account report defaults
+const (
+	DefaultReportCheckInterval = time.Minute
+	DefaultReportRetention     = 30 // daily reports kept per trader
+
+	// reportTopPositions is how many of the largest open positions a report lists
+	reportTopPositions = 5
+	reportDateLayout   = "2006-01-02"
+)
+
+// accountReports generates a daily summary of every opted-in account once
+// the UTC day closes and keeps the latest reports for download. Traders opt
+// in through /v1/account/reports or by subscribing a webhook to
+// daily_report, which also delivers the report. Reports are held in memory:
+// after a restart the previous day is reported again on the first check.
+type accountReports struct {
+	interval  time.Duration
+	retention int
+	now       func() time.Time
+
+	mu       sync.Mutex
+	enabled  map[string]bool
+	reports  map[string][]*types.AccountReport // trader -> reports, oldest first
+	reported time.Time                         // start of the latest day reported
+}
+
+// newAccountReports returns nil, disabling daily reports, when
+// Config.ReportCheckInterval is negative
+func newAccountReports(config *Config) *accountReports {
+	interval := config.ReportCheckInterval
+	if interval < 0 {
+		return nil
+	}
+	if interval == 0 {
+		interval = DefaultReportCheckInterval
+	}
+	retention := config.ReportRetention
+	if retention <= 0 {
+		retention = DefaultReportRetention
+	}
+	return &accountReports{
+		interval:  interval,
+		retention: retention,
+		now:       time.Now,
+		enabled:   make(map[string]bool),
+		reports:   make(map[string][]*types.AccountReport),
+	}
+}
+
+// due returns the start of the UTC day before today if it has not been
+// reported yet
+func (a *accountReports) due() (time.Time, bool) {
+	today := a.now().UTC().Truncate(24 * time.Hour)
+	day := today.Add(-24 * time.Hour)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return day, a.reported.Before(day)
+}
+
+// markReported records that every opted-in account has its report of day
+func (a *accountReports) markReported(day time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if day.After(a.reported) {
+		a.reported = day
+	}
+}
+
+// setEnabled records a trader's daily report preference
+func (a *accountReports) setEnabled(trader string, enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if enabled {
+		a.enabled[trader] = true
+	} else {
+		delete(a.enabled, trader)
+	}
+}
+
+// optedIn returns the traders that enabled reports or subscribed a webhook
+// to them, sorted
+func (a *accountReports) optedIn(webhooks *webhook.Dispatcher) []string {
+	seen := make(map[string]bool)
+	a.mu.Lock()
+	for trader := range a.enabled {
+		seen[trader] = true
+	}
+	a.mu.Unlock()
+	if webhooks != nil {
+		for _, trader := range webhooks.Traders(webhook.EventDailyReport) {
+			seen[trader] = true
+		}
+	}
+
+	traders := make([]string, 0, len(seen))
+	for trader := range seen {
+		traders = append(traders, trader)
+	}
+	sort.Strings(traders)
+	return traders
+}
+
+// record keeps a report, replacing one of the same date and dropping the
+// oldest beyond the retention
+func (a *accountReports) record(report *types.AccountReport) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	list := a.reports[report.Trader]
+	for i, existing := range list {
+		if existing.Date == report.Date {
+			list[i] = report
+			return
+		}
+	}
+	if len(list) >= a.retention {
+		list = list[len(list)-a.retention+1:]
+	}
+	a.reports[report.Trader] = append(list, report)
+}
+
+// get returns a trader's report of a date, or nil
+func (a *accountReports) get(trader, date string) *types.AccountReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, report := range a.reports[trader] {
+		if report.Date == date {
+			return report
+		}
+	}
+	return nil
+}
+
+// settings returns a trader's preference and the dates of their reports
+func (a *accountReports) settings(trader string, webhooks *webhook.Dispatcher) *types.AccountReportSettings {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	list := a.reports[trader]
+	settings := &types.AccountReportSettings{
+		Trader:  trader,
+		Enabled: a.enabled[trader],
+		Reports: make([]string, 0, len(list)),
+	}
+	for i := len(list) - 1; i >= 0; i-- {
+		settings.Reports = append(settings.Reports, list[i].Date)
+	}
+	if webhooks != nil {
+		settings.Webhook = webhooks.HasSubscribers(trader, webhook.EventDailyReport)
+	}
+	return settings
+}
+
+// newAccountReport summarizes a day of activity with the account as of
+// generatedAt. Net PnL is realized PnL plus funding less fees.
+func newAccountReport(day time.Time, activity *types.AccountActivity, summary *types.AccountSummary, generatedAt time.Time) (*types.AccountReport, error) {
+	volume, fees, realized, funding := math.LegacyZeroDec(), math.LegacyZeroDec(), math.LegacyZeroDec(), math.LegacyZeroDec()
+	trades := 0
+	for _, m := range activity.Markets {
+		for _, field := range []struct {
+			name  string
+			value string
+			total *math.LegacyDec
+		}{
+			{"volume", m.Volume, &volume},
+			{"fees", m.Fees, &fees},
+			{"realized PnL", m.RealizedPnl, &realized},
+			{"funding", m.Funding, &funding},
+		} {
+			d, err := math.LegacyNewDecFromStr(field.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s in %s: %w", field.name, m.MarketID, err)
+			}
+			*field.total = field.total.Add(d)
+		}
+		trades += m.Trades
+	}
+
+	balance, err := math.LegacyNewDecFromStr(summary.Account.Balance)
+	if err != nil {
+		return nil, fmt.Errorf("invalid balance: %w", err)
+	}
+	upnl := math.LegacyZeroDec()
+	positions := make([]*types.ReportPosition, 0, len(summary.Positions))
+	notionals := make(map[*types.ReportPosition]math.LegacyDec, len(summary.Positions))
+	for _, pos := range summary.Positions {
+		size, err := math.LegacyNewDecFromStr(pos.Size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size in %s: %w", pos.MarketID, err)
+		}
+		pnl, err := math.LegacyNewDecFromStr(pos.UnrealizedPnl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unrealized PnL in %s: %w", pos.MarketID, err)
+		}
+		// Positions without a mark price are valued at their entry
+		price, err := math.LegacyNewDecFromStr(pos.MarkPrice)
+		if err != nil {
+			if price, err = math.LegacyNewDecFromStr(pos.EntryPrice); err != nil {
+				return nil, fmt.Errorf("invalid price in %s: %w", pos.MarketID, err)
+			}
+		}
+		upnl = upnl.Add(pnl)
+
+		notional := size.Abs().Mul(price)
+		position := &types.ReportPosition{
+			MarketID:      pos.MarketID,
+			Side:          pos.Side,
+			Size:          pos.Size,
+			EntryPrice:    pos.EntryPrice,
+			MarkPrice:     price.String(),
+			Notional:      notional.String(),
+			UnrealizedPnl: pos.UnrealizedPnl,
+		}
+		notionals[position] = notional
+		positions = append(positions, position)
+	}
+	sort.SliceStable(positions, func(i, j int) bool {
+		return notionals[positions[i]].GT(notionals[positions[j]])
+	})
+	if len(positions) > reportTopPositions {
+		positions = positions[:reportTopPositions]
+	}
+
+	markets := activity.Markets
+	if markets == nil {
+		markets = []*types.MarketActivity{}
+	}
+	return &types.AccountReport{
+		Trader:        summary.Trader,
+		Date:          day.Format(reportDateLayout),
+		From:          activity.From,
+		To:            activity.To,
+		GeneratedAt:   generatedAt.UnixMilli(),
+		Trades:        trades,
+		Volume:        volume.String(),
+		Fees:          fees.String(),
+		RealizedPnl:   realized.String(),
+		Funding:       funding.String(),
+		NetPnl:        realized.Add(funding).Sub(fees).String(),
+		Balance:       balance.String(),
+		Equity:        balance.Add(upnl).String(),
+		UnrealizedPnl: upnl.String(),
+		Markets:       markets,
+		TopPositions:  positions,
+	}, nil
+}
+
+// reportSources returns the services reports are built from
+func (s *Server) reportSources() (types.AccountActivityService, types.AccountBatchService, bool) {
+	activity, ok1 := s.orderService.(types.AccountActivityService)
+	batch, ok2 := s.accountService.(types.AccountBatchService)
+	return activity, batch, ok1 && ok2
+}
+
+// generateAccountReports reports day for every opted-in account, querying
+// them in batches of types.MaxBatchQueryTraders, and delivers each report to
+// the trader's daily_report webhooks
+func (s *Server) generateAccountReports(ctx context.Context, day time.Time, activity types.AccountActivityService, batch types.AccountBatchService) error {
+	traders := s.accountReports.optedIn(s.webhooks)
+	for start := 0; start < len(traders); start += types.MaxBatchQueryTraders {
+		end := start + types.MaxBatchQueryTraders
+		if end > len(traders) {
+			end = len(traders)
+		}
+		summaries, err := batch.BatchQueryAccounts(ctx, traders[start:end])
+		if err != nil {
+			return err
+		}
+		for _, summary := range summaries {
+			dayActivity, err := activity.GetAccountActivity(ctx, summary.Trader, day, day.Add(24*time.Hour))
+			if err != nil {
+				return err
+			}
+			report, err := newAccountReport(day, dayActivity, summary, s.accountReports.now())
+			if err != nil {
+				log.Printf("Daily report skipped for %s: %v", summary.Trader, err)
+				continue
+			}
+			s.accountReports.record(report)
+			if s.webhooks != nil {
+				s.webhooks.PublishAccountEvent(summary.Trader, webhook.EventDailyReport, report)
+			}
+		}
+	}
+	return nil
+}
+
+// startAccountReportScheduler checks each Config.ReportCheckInterval whether
+// a UTC day has closed and reports it, until the server stops
+func (s *Server) startAccountReportScheduler() {
+	if s.accountReports == nil {
+		return
+	}
+	activity, batch, ok := s.reportSources()
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(s.accountReports.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+		day, due := s.accountReports.due()
+		if !due {
+			continue
+		}
+		if err := s.generateAccountReports(context.Background(), day, activity, batch); err != nil {
+			log.Printf("Daily reports for %s failed: %v", day.Format(reportDateLayout), err)
+			continue
+		}
+		s.accountReports.markReported(day)
+	}
+}
+
+// handleAccountReports handles /v1/account/reports (GET settings and report
+// dates, POST {"enabled": bool})
+func (s *Server) handleAccountReports(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if s.accountReports == nil {
+		writeError(w, types.ErrCodeNotImplemented, "Daily reports are disabled on this server")
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.accountReports.settings(trader, s.webhooks))
+
+	case http.MethodPost:
+		var req struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		if req.Enabled == nil {
+			writeError(w, types.ErrCodeMissingField, "enabled is required")
+			return
+		}
+		s.accountReports.setEnabled(trader, *req.Enabled)
+		writeJSON(w, http.StatusOK, s.accountReports.settings(trader, s.webhooks))
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleAccountReport handles GET /v1/account/reports/{date}?format=json|csv:
+// one of the trader's daily reports
+func (s *Server) handleAccountReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if s.accountReports == nil {
+		writeError(w, types.ErrCodeNotImplemented, "Daily reports are disabled on this server")
+		return
+	}
+	date := strings.TrimPrefix(r.URL.Path, "/v1/account/reports/")
+	if _, err := time.Parse(reportDateLayout, date); err != nil {
+		writeError(w, types.ErrCodeInvalidPath, "Report date must be YYYY-MM-DD")
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+	report := s.accountReports.get(trader, date)
+	if report == nil {
+		writeError(w, types.ErrCodeReportNotFound, "No report for "+date)
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		writeJSON(w, http.StatusOK, report)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "report-"+date+".csv"))
+		w.WriteHeader(http.StatusOK)
+		if err := writeExportCSV(w, accountReportRows(report)); err != nil {
+			log.Printf("Daily report CSV for %s failed: %v", trader, err)
+		}
+	default:
+		writeError(w, types.ErrCodeInvalidRequest, "format must be json or csv")
+	}
+}
+
+// accountReportRow is one CSV row of a report: the day's totals, a market's
+// activity or an open position, told apart by section
+type accountReportRow struct {
+	Date          string `json:"date"`
+	Section       string `json:"section"` // total, market or position
+	MarketID      string `json:"market_id,omitempty"`
+	Trades        string `json:"trades,omitempty"`
+	Volume        string `json:"volume,omitempty"`
+	Fees          string `json:"fees,omitempty"`
+	RealizedPnl   string `json:"realized_pnl,omitempty"`
+	Funding       string `json:"funding,omitempty"`
+	NetPnl        string `json:"net_pnl,omitempty"`
+	Balance       string `json:"balance,omitempty"`
+	Equity        string `json:"equity,omitempty"`
+	Side          string `json:"side,omitempty"`
+	Size          string `json:"size,omitempty"`
+	EntryPrice    string `json:"entry_price,omitempty"`
+	MarkPrice     string `json:"mark_price,omitempty"`
+	Notional      string `json:"notional,omitempty"`
+	UnrealizedPnl string `json:"unrealized_pnl,omitempty"`
+}
+
+// accountReportRows flattens a report into CSV rows
+func accountReportRows(report *types.AccountReport) []interface{} {
+	rows := []interface{}{&accountReportRow{
+		Date:          report.Date,
+		Section:       "total",
+		Trades:        fmt.Sprint(report.Trades),
+		Volume:        report.Volume,
+		Fees:          report.Fees,
+		RealizedPnl:   report.RealizedPnl,
+		Funding:       report.Funding,
+		NetPnl:        report.NetPnl,
+		Balance:       report.Balance,
+		Equity:        report.Equity,
+		UnrealizedPnl: report.UnrealizedPnl,
+	}}
+	for _, m := range report.Markets {
+		rows = append(rows, &accountReportRow{
+			Date:        report.Date,
+			Section:     "market",
+			MarketID:    m.MarketID,
+			Trades:      fmt.Sprint(m.Trades),
+			Volume:      m.Volume,
+			Fees:        m.Fees,
+			RealizedPnl: m.RealizedPnl,
+			Funding:     m.Funding,
+		})
+	}
+	for _, pos := range report.TopPositions {
+		rows = append(rows, &accountReportRow{
+			Date:          report.Date,
+			Section:       "position",
+			MarketID:      pos.MarketID,
+			Side:          pos.Side,
+			Size:          pos.Size,
+			EntryPrice:    pos.EntryPrice,
+			MarkPrice:     pos.MarkPrice,
+			Notional:      pos.Notional,
+			UnrealizedPnl: pos.UnrealizedPnl,
+		})
+	}
+	return rows
+}
+
+// GetAccountActivity summarizes a trader's fills from the trade history and
+// the funding payments settled on their positions
+func (rs *RealService) GetAccountActivity(ctx context.Context, trader string, from, to time.Time) (*types.AccountActivity, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	markets := make(map[string]*obtypes.MarketActivity)
+	for _, m := range rs.obKeeper.TraderActivity(rs.sdkCtx, trader, from, to).Markets {
+		markets[m.MarketID] = m
+	}
+	funding := make(map[string]math.LegacyDec)
+	if rs.perpKeeper != nil {
+		for _, p := range rs.perpKeeper.GetFundingPaymentsByTrader(rs.sdkCtx, trader, accountExportMaxRecords) {
+			if p.Timestamp.Before(from) || !p.Timestamp.Before(to) {
+				continue
+			}
+			if _, ok := markets[p.MarketID]; !ok {
+				markets[p.MarketID] = obtypes.NewMarketActivity(p.MarketID)
+			}
+			if total, ok := funding[p.MarketID]; ok {
+				funding[p.MarketID] = total.Add(p.Amount)
+			} else {
+				funding[p.MarketID] = p.Amount
+			}
+		}
+	}
+
+	resp := &types.AccountActivity{
+		Trader:  trader,
+		From:    from.UnixMilli(),
+		To:      to.UnixMilli(),
+		Markets: make([]*types.MarketActivity, 0, len(markets)),
+	}
+	for marketID, m := range markets {
+		paid, ok := funding[marketID]
+		if !ok {
+			paid = math.LegacyZeroDec()
+		}
+		resp.Markets = append(resp.Markets, &types.MarketActivity{
+			MarketID:    marketID,
+			Trades:      m.Trades,
+			Volume:      m.Volume.String(),
+			Fees:        m.Fees.String(),
+			RealizedPnl: m.RealizedPnL.String(),
+			Funding:     paid.String(),
+		})
+	}
+	sort.Slice(resp.Markets, func(i, j int) bool {
+		return resp.Markets[i].MarketID < resp.Markets[j].MarketID
+	})
+	return resp, nil
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// fakeAccountActivity serves the same day of activity for every trader
+type fakeAccountActivity struct {
+	markets []*types.MarketActivity
+}
+
+func (f *fakeAccountActivity) GetAccountActivity(ctx context.Context, trader string, from, to time.Time) (*types.AccountActivity, error) {
+	return &types.AccountActivity{Trader: trader, From: from.UnixMilli(), To: to.UnixMilli(), Markets: f.markets}, nil
+}
+
+// TestAccountReports tests that a closed UTC day is reported once for opted-in
+// traders only, with net PnL and the largest positions first, and that the
+// report downloads as JSON and CSV
+func TestAccountReports(t *testing.T) {
+	svc := NewMockService()
+	ctx := context.Background()
+	for _, trader := range []string{"reporter", "silent"} {
+		if _, err := svc.Deposit(ctx, &types.DepositRequest{Trader: trader, Amount: "1000"}); err != nil {
+			t.Fatalf("failed to deposit: %v", err)
+		}
+	}
+	svc.positions["reporter:BTC-USDC"] = &types.Position{MarketID: "BTC-USDC", Trader: "reporter", Side: "long", Size: "0.1", EntryPrice: "50000", MarkPrice: "51000", UnrealizedPnl: "100"}
+	svc.positions["reporter:ETH-USDC"] = &types.Position{MarketID: "ETH-USDC", Trader: "reporter", Side: "short", Size: "3", EntryPrice: "3000", MarkPrice: "3100", UnrealizedPnl: "-300"}
+
+	reports := newAccountReports(&Config{})
+	reports.now = func() time.Time { return time.Date(2024, 1, 2, 0, 5, 0, 0, time.UTC) }
+	s := &Server{accountService: svc, accountReports: reports}
+	activity := &fakeAccountActivity{markets: []*types.MarketActivity{
+		{MarketID: "BTC-USDC", Trades: 3, Volume: "15000", Fees: "7.5", RealizedPnl: "200", Funding: "-2.5"},
+	}}
+
+	post := httptest.NewRequest(http.MethodPost, "/v1/account/reports?trader=reporter", strings.NewReader(`{"enabled":true}`))
+	rec := httptest.NewRecorder()
+	s.handleAccountReports(rec, post)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	day, due := reports.due()
+	if !due || day.Format(reportDateLayout) != "2024-01-01" {
+		t.Fatalf("expected 2024-01-01 to be due, got %s %v", day, due)
+	}
+	if err := s.generateAccountReports(ctx, day, activity, svc); err != nil {
+		t.Fatalf("failed to generate reports: %v", err)
+	}
+	reports.markReported(day)
+	if _, due := reports.due(); due {
+		t.Error("expected the day to be reported once")
+	}
+	if reports.get("silent", "2024-01-01") != nil {
+		t.Error("expected no report for a trader who did not opt in")
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.handleAccountReport(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	rec = get("/v1/account/reports/2024-01-01?trader=reporter")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var report types.AccountReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if report.Trades != 3 || report.NetPnl != "190.000000000000000000" || report.Equity != "800.000000000000000000" {
+		t.Errorf("expected 3 trades, 190 net PnL and 800 equity, got %+v", report)
+	}
+	if len(report.TopPositions) != 2 || report.TopPositions[0].MarketID != "ETH-USDC" || report.TopPositions[0].Notional != "9300.000000000000000000" {
+		t.Errorf("expected the 9300 ETH position first, got %+v", report.TopPositions)
+	}
+
+	rec = get("/v1/account/reports/2024-01-01?trader=reporter&format=csv")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("expected a CSV, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(rows) != 5 { // header, total, one market, two positions
+		t.Errorf("expected 5 rows, got %d: %v", len(rows), rows)
+	}
+
+	if rec := get("/v1/account/reports/2023-12-31?trader=reporter"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a day without a report, got %d", rec.Code)
+	}
+	if rec := get("/v1/account/reports/yesterday?trader=reporter"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid date, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleAccountReports(rec, httptest.NewRequest(http.MethodGet, "/v1/account/reports?trader=reporter", nil))
+	var settings types.AccountReportSettings
+	if err := json.Unmarshal(rec.Body.Bytes(), &settings); err != nil {
+		t.Fatalf("failed to decode settings: %v", err)
+	}
+	if !settings.Enabled || len(settings.Reports) != 1 || settings.Reports[0] != "2024-01-01" {
+		t.Errorf("expected reports enabled with the 2024-01-01 report, got %+v", settings)
+	}
+}
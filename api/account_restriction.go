@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// handleAdminAccounts handles /v1/admin/accounts/restrictions (GET), every
+// account restriction in force, and /v1/admin/accounts/{trader}/restriction
+// (GET, PUT with a types.AccountRestrictionRequest, DELETE): an account's
+// suspension or freeze. Changes require the X-Admin-Actor header, which is
+// kept with the restriction and in its events. Freezing an account cancels
+// its open orders.
+func (s *Server) handleAdminAccounts(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	restrictions, ok := s.orderService.(types.AccountRestrictionService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Account restrictions require a keeper-backed service")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/admin/accounts/")
+	if path == "restrictions" {
+		if r.Method != http.MethodGet {
+			writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+			return
+		}
+		list, err := restrictions.ListAccountRestrictions(r.Context())
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"restrictions": list})
+		return
+	}
+	trader, found := strings.CutSuffix(path, "/restriction")
+	if !found || trader == "" || strings.Contains(trader, "/") {
+		writeError(w, types.ErrCodeInvalidPath, "Invalid account restriction path")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		restriction, err := restrictions.GetAccountRestriction(r.Context(), trader)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, restriction)
+
+	case http.MethodPut, http.MethodDelete:
+		actor := r.Header.Get(adminActorHeader)
+		if actor == "" {
+			writeError(w, types.ErrCodeMissingField, adminActorHeader+" header is required")
+			return
+		}
+		if r.Method == http.MethodDelete {
+			restriction, err := restrictions.LiftAccountRestriction(r.Context(), trader, actor)
+			if err != nil {
+				writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+				return
+			}
+			writeJSON(w, http.StatusOK, restriction)
+			return
+		}
+
+		var req types.AccountRestrictionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		restriction, err := restrictions.SetAccountRestriction(r.Context(), trader, actor, &req)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusOK, restriction)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (rs *RealService) ListAccountRestrictions(ctx context.Context) ([]*types.AccountRestriction, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.perpKeeper == nil {
+		return nil, types.NewAPIError(types.ErrCodeNotImplemented, "Account restrictions require a keeper-backed service")
+	}
+	restrictions := rs.perpKeeper.GetAllAccountRestrictions(rs.clockCtx())
+	result := make([]*types.AccountRestriction, 0, len(restrictions))
+	for _, restriction := range restrictions {
+		result = append(result, fromPerpAccountRestriction(restriction))
+	}
+	return result, nil
+}
+
+func (rs *RealService) GetAccountRestriction(ctx context.Context, trader string) (*types.AccountRestriction, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.perpKeeper == nil {
+		return nil, types.NewAPIError(types.ErrCodeNotImplemented, "Account restrictions require a keeper-backed service")
+	}
+	restriction := rs.perpKeeper.GetAccountRestriction(rs.clockCtx(), trader)
+	if restriction == nil {
+		return nil, perptypes.ErrAccountRestrictionNotFound
+	}
+	return fromPerpAccountRestriction(restriction), nil
+}
+
+func (rs *RealService) SetAccountRestriction(ctx context.Context, trader, actor string, req *types.AccountRestrictionRequest) (*types.AccountRestriction, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.perpKeeper == nil {
+		return nil, types.NewAPIError(types.ErrCodeNotImplemented, "Account restrictions require a keeper-backed service")
+	}
+	restriction := &perptypes.AccountRestriction{
+		Trader:     trader,
+		Level:      perptypes.RestrictionLevel(req.Level),
+		ReasonCode: req.ReasonCode,
+		Reason:     req.Reason,
+		Actor:      actor,
+	}
+	if req.ExpiresAt != 0 {
+		restriction.ExpiresAt = time.UnixMilli(req.ExpiresAt).UTC()
+	}
+	sdkCtx := rs.clockCtx()
+	if err := rs.perpKeeper.SetAccountRestriction(sdkCtx, restriction); err != nil {
+		return nil, err
+	}
+	// Resting orders would still fill, so a freeze cancels them
+	if restriction.Level == perptypes.RestrictionFrozen {
+		for _, order := range rs.obKeeper.GetOpenOrders(sdkCtx, trader) {
+			if _, err := rs.obKeeper.CancelOrder(sdkCtx, trader, order.OrderID); err != nil {
+				rs.logger.Error("failed to cancel order of frozen account", "order_id", order.OrderID, "error", err)
+			}
+		}
+	}
+	return fromPerpAccountRestriction(restriction), nil
+}
+
+func (rs *RealService) LiftAccountRestriction(ctx context.Context, trader, actor string) (*types.AccountRestriction, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.perpKeeper == nil {
+		return nil, types.NewAPIError(types.ErrCodeNotImplemented, "Account restrictions require a keeper-backed service")
+	}
+	restriction, err := rs.perpKeeper.LiftAccountRestriction(rs.clockCtx(), trader, actor)
+	if err != nil {
+		return nil, err
+	}
+	return fromPerpAccountRestriction(restriction), nil
+}
+
+// fromPerpAccountRestriction converts a keeper account restriction to the API type
+func fromPerpAccountRestriction(restriction *perptypes.AccountRestriction) *types.AccountRestriction {
+	result := &types.AccountRestriction{
+		Trader:     restriction.Trader,
+		Level:      string(restriction.Level),
+		ReasonCode: restriction.ReasonCode,
+		Reason:     restriction.Reason,
+		Actor:      restriction.Actor,
+		CreatedAt:  restriction.CreatedAt.UnixMilli(),
+	}
+	if !restriction.ExpiresAt.IsZero() {
+		result.ExpiresAt = restriction.ExpiresAt.UnixMilli()
+	}
+	return result
+}
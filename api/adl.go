@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	clearinghousetypes "github.com/openalpha/perp-dex/x/clearinghouse/types"
+)
+
+// adlIndicatorInterval is how often ADL indicators are recomputed for WS subscribers
+const adlIndicatorInterval = 5 * time.Second
+
+// adlIndicators ranks the positions of a market in their side's ADL queue with
+// the same scoring the clearinghouse uses, and returns an indicator for each
+func adlIndicators(marketID string, positions []*types.Position, timestamp int64) []*types.ADLIndicator {
+	queues := make(map[string][]*clearinghousetypes.ADLPosition)
+	indicators := make([]*types.ADLIndicator, 0, len(positions))
+	byTrader := make(map[string]*types.ADLIndicator, len(positions))
+
+	for _, pos := range positions {
+		if pos.MarketID != marketID {
+			continue
+		}
+		indicator := &types.ADLIndicator{
+			MarketID:  marketID,
+			Trader:    pos.Trader,
+			Side:      pos.Side,
+			Lights:    clearinghousetypes.ADLLights(0, 0),
+			Score:     math.LegacyZeroDec().String(),
+			Timestamp: timestamp,
+		}
+		indicators = append(indicators, indicator)
+		byTrader[pos.Side+"/"+pos.Trader] = indicator
+
+		pnl, err1 := math.LegacyNewDecFromStr(pos.UnrealizedPnl)
+		margin, err2 := math.LegacyNewDecFromStr(pos.Margin)
+		size, err3 := math.LegacyNewDecFromStr(pos.Size)
+		mark, err4 := math.LegacyNewDecFromStr(pos.MarkPrice)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || !pnl.IsPositive() {
+			continue
+		}
+		score, pnlPercent, leverage := clearinghousetypes.ADLScore(pnl, margin, size.Mul(mark))
+		queues[pos.Side] = append(queues[pos.Side], &clearinghousetypes.ADLPosition{
+			Trader:        pos.Trader,
+			MarketID:      marketID,
+			Side:          pos.Side,
+			Size:          size,
+			UnrealizedPnL: pnl,
+			PnLPercent:    pnlPercent,
+			Leverage:      leverage,
+			Score:         score,
+		})
+	}
+
+	for side, queue := range queues {
+		clearinghousetypes.RankADLPositions(queue)
+		for _, adlPos := range queue {
+			indicator := byTrader[side+"/"+adlPos.Trader]
+			indicator.InQueue = true
+			indicator.Rank = adlPos.ADLRanking
+			indicator.Lights = adlPos.Lights
+			indicator.Score = adlPos.Score.String()
+		}
+	}
+	for _, indicator := range indicators {
+		indicator.QueueSize = len(queues[indicator.Side])
+	}
+	return indicators
+}
+
+// startADLIndicatorBroadcaster pushes ADL indicator changes to each position
+// owner's private positions channel
+func (s *Server) startADLIndicatorBroadcaster() {
+	adl, ok := s.positionService.(types.ADLService)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(adlIndicatorInterval)
+	defer ticker.Stop()
+
+	lights := make(map[string]int) // market/trader -> last broadcast lights
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		ctx := context.Background()
+		seen := make(map[string]bool, len(lights))
+		for _, market := range s.getMockMarkets() {
+			marketID, _ := market["market_id"].(string)
+			indicators, err := adl.GetADLIndicators(ctx, marketID)
+			if err != nil {
+				continue
+			}
+			for _, indicator := range indicators {
+				key := marketID + "/" + indicator.Trader
+				seen[key] = true
+				if last, ok := lights[key]; ok && last == indicator.Lights {
+					continue
+				}
+				lights[key] = indicator.Lights
+				s.wsServer.BroadcastADLIndicator(indicator.Trader, indicator)
+			}
+		}
+		for key := range lights {
+			if !seen[key] {
+				delete(lights, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	obkeeper "github.com/openalpha/perp-dex/x/orderbook/keeper"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// analyticsBookDepth is the number of oracle book levels analytics are computed
+// over, enough to cover the widest depth band on liquid markets
+const analyticsBookDepth = 100
+
+// newMarketAnalytics converts keeper book analytics to the API response
+func newMarketAnalytics(a *obkeeper.BookAnalytics) *types.MarketAnalytics {
+	depth := make([]*types.DepthBand, len(a.Bands))
+	for i, band := range a.Bands {
+		depth[i] = &types.DepthBand{
+			Bps:       band.Bps,
+			BidDepth:  band.BidQty.String(),
+			AskDepth:  band.AskQty.String(),
+			Imbalance: band.Imbalance.String(),
+		}
+	}
+	return &types.MarketAnalytics{
+		MarketID:          a.MarketID,
+		BestBid:           a.BestBid.String(),
+		BestAsk:           a.BestAsk.String(),
+		MidPrice:          a.MidPrice.String(),
+		Spread:            a.Spread.String(),
+		SpreadBps:         a.SpreadBps.String(),
+		Imbalance:         a.Imbalance.String(),
+		Depth:             depth,
+		Volatility:        a.Volatility.String(),
+		VolatilitySamples: a.VolatilitySamples,
+		VolatilityWindow:  a.VolatilityWindow,
+		Timestamp:         a.UpdatedAt.UnixMilli(),
+	}
+}
+
+// GetMarketAnalytics computes book analytics from the Hyperliquid L2 book and
+// recent trades. Unlike the keeper, nothing is tracked between requests.
+func (o *HyperliquidOracle) GetMarketAnalytics(ctx context.Context, marketID string) (*types.MarketAnalytics, error) {
+	ob, err := o.GetOrderbook(marketID, analyticsBookDepth)
+	if err != nil {
+		return nil, err
+	}
+	bids, err := oracleLevels(ob.Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := oracleLevels(ob.Asks)
+	if err != nil {
+		return nil, err
+	}
+	a := obkeeper.ComputeBookAnalytics(marketID, bids, asks)
+	a.UpdatedAt = time.UnixMilli(ob.Timestamp)
+
+	trades, err := o.GetRecentTrades(marketID, obkeeper.DefaultVolatilityWindow+1)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].Timestamp < trades[j].Timestamp })
+	window := obkeeper.NewVolatilityWindow(obkeeper.DefaultVolatilityWindow)
+	for _, t := range trades {
+		price, err := math.LegacyNewDecFromStr(t.Price)
+		if err != nil {
+			continue
+		}
+		window.Add(price)
+	}
+	a.Volatility = window.Value()
+	a.VolatilitySamples = window.Samples()
+	a.VolatilityWindow = window.Size()
+
+	return newMarketAnalytics(a), nil
+}
+
+// oracleLevels parses oracle book levels into orderbook price levels
+func oracleLevels(levels []OrderbookLevel) ([]*obtypes.PriceLevel, error) {
+	result := make([]*obtypes.PriceLevel, 0, len(levels))
+	for _, l := range levels {
+		price, err := math.LegacyNewDecFromStr(l.Price)
+		if err != nil {
+			return nil, fmt.Errorf("invalid level price %q: %w", l.Price, err)
+		}
+		qty, err := math.LegacyNewDecFromStr(l.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid level quantity %q: %w", l.Quantity, err)
+		}
+		result = append(result, &obtypes.PriceLevel{Price: price, Quantity: qty})
+	}
+	return result, nil
+}
+
+// handleMarketAnalytics handles GET /v1/markets/{id}/analytics
+func (s *Server) handleMarketAnalytics(w http.ResponseWriter, r *http.Request, marketID string) {
+	if s.getMockMarket(marketID) == nil {
+		writeError(w, types.ErrCodeMarketNotFound, "Market not found")
+		return
+	}
+
+	var (
+		analytics *types.MarketAnalytics
+		err       error
+	)
+	if s.marketData != nil {
+		analytics, err = s.marketData.GetMarketAnalytics(r.Context(), marketID)
+	} else {
+		analytics, err = s.oracle.GetMarketAnalytics(r.Context(), marketID)
+	}
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeServiceUnavailable))
+		return
+	}
+	writeJSON(w, http.StatusOK, analytics)
+}
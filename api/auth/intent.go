@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MaxIntentLifetime caps how far in the future a signed intent may expire, so
+// the nonces the verifier remembers stay bounded
+const MaxIntentLifetime = time.Hour
+
+// Intent errors
+var (
+	ErrIntentExpired  = errors.New("signed intent expired or expires too far ahead")
+	ErrIntentReplayed = errors.New("signed intent nonce already used")
+)
+
+// ADR036SignBytes returns the bytes a wallet signs for arbitrary data under
+// ADR-036 (Keplr signArbitrary): the amino JSON of a zero-fee, zero-sequence
+// sign doc with one sign/MsgSignData message, keys sorted, no whitespace
+func ADR036SignBytes(signer string, data []byte) []byte {
+	return []byte(`{"account_number":"0","chain_id":"","fee":{"amount":[],"gas":"0"},"memo":"",` +
+		`"msgs":[{"type":"sign/MsgSignData","value":{"data":"` + base64.StdEncoding.EncodeToString(data) +
+		`","signer":"` + signer + `"}}],"sequence":"0"}`)
+}
+
+// VerifyADR036 checks that signature is signer's ADR-036 signature over data.
+// pubKey is the compressed secp256k1 public key and signature the 64-byte r||s
+// signature.
+func VerifyADR036(signer string, data, pubKey, signature []byte) error {
+	addr, err := sdk.AccAddressFromBech32(signer)
+	if err != nil {
+		return fmt.Errorf("%w: invalid signer address", ErrInvalidSignature)
+	}
+	if len(pubKey) != secp256k1.PubKeySize {
+		return fmt.Errorf("%w: invalid public key", ErrInvalidSignature)
+	}
+
+	pk := &secp256k1.PubKey{Key: pubKey}
+	if !bytes.Equal(pk.Address(), addr) {
+		return fmt.Errorf("%w: public key does not match signer", ErrInvalidSignature)
+	}
+	if !pk.VerifySignature(ADR036SignBytes(signer, data), signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// IntentVerifier verifies wallet-signed intents: payloads a trader signs with
+// ADR-036 that carry a nonce and an expiry. Each signer's nonce is accepted
+// once until the intent expires, so a captured intent cannot be replayed.
+// Nonces are kept in memory, so behind a load balancer every API node must
+// see the same intents, or intents must be short-lived.
+type IntentVerifier struct {
+	mu   sync.Mutex
+	used map[string]time.Time // signer/nonce -> expiry
+	now  func() time.Time
+}
+
+// NewIntentVerifier creates an intent verifier
+func NewIntentVerifier() *IntentVerifier {
+	return &IntentVerifier{
+		used: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// Verify checks an intent's expiry (Unix ms) and signature and consumes its
+// nonce. payload is the exact data the wallet signed.
+func (v *IntentVerifier) Verify(signer, nonce string, expiresAt int64, payload, pubKey, signature []byte) error {
+	now := v.now()
+	expiry := time.UnixMilli(expiresAt)
+	if !expiry.After(now) || expiry.Sub(now) > MaxIntentLifetime {
+		return ErrIntentExpired
+	}
+	if err := VerifyADR036(signer, payload, pubKey, signature); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for key, exp := range v.used {
+		if !exp.After(now) {
+			delete(v.used, key)
+		}
+	}
+	key := signer + "/" + nonce
+	if _, ok := v.used[key]; ok {
+		return ErrIntentReplayed
+	}
+	v.used[key] = expiry
+	return nil
+}
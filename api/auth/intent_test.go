@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// TestIntentVerifier tests ADR-036 signature checks, expiry bounds and nonce
+// replay protection
+func TestIntentVerifier(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	v := NewIntentVerifier()
+	v.now = func() time.Time { return now }
+
+	priv := secp256k1.GenPrivKey()
+	pub := priv.PubKey().Bytes()
+	trader := sdk.AccAddress(priv.PubKey().Address()).String()
+	payload := []byte(`{"market_id":"BTC-USDC","nonce":"1"}`)
+	expiresAt := now.Add(time.Minute).UnixMilli()
+
+	sign := func(data []byte) []byte {
+		t.Helper()
+		sig, err := priv.Sign(ADR036SignBytes(trader, data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	sig := sign(payload)
+
+	// A signature over the raw payload is not an ADR-036 signature
+	raw, _ := priv.Sign(payload)
+	if err := v.Verify(trader, "1", expiresAt, payload, pub, raw); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for a raw signature, got %v", err)
+	}
+	if err := v.Verify(trader, "1", expiresAt, []byte(`{"market_id":"ETH-USDC","nonce":"1"}`), pub, sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for a tampered payload, got %v", err)
+	}
+	other := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address()).String()
+	if err := v.Verify(other, "1", expiresAt, payload, pub, sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for another signer, got %v", err)
+	}
+
+	for _, exp := range []time.Time{now, now.Add(-time.Second), now.Add(MaxIntentLifetime + time.Second)} {
+		if err := v.Verify(trader, "1", exp.UnixMilli(), payload, pub, sig); !errors.Is(err, ErrIntentExpired) {
+			t.Errorf("expected ErrIntentExpired for expiry %v, got %v", exp, err)
+		}
+	}
+
+	if err := v.Verify(trader, "1", expiresAt, payload, pub, sig); err != nil {
+		t.Fatalf("expected intent to verify: %v", err)
+	}
+	if err := v.Verify(trader, "1", expiresAt, payload, pub, sig); !errors.Is(err, ErrIntentReplayed) {
+		t.Errorf("expected ErrIntentReplayed, got %v", err)
+	}
+
+	// Nonces are forgotten once their intent has expired
+	now = now.Add(2 * time.Minute)
+	if err := v.Verify(trader, "1", now.Add(time.Minute).UnixMilli(), payload, pub, sig); err != nil {
+		t.Errorf("expected an expired nonce to be reusable: %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+// Package auth issues and verifies short-lived session tokens that gate
+// private and licensed WebSocket channels, and verifies wallet-signed order
+// intents.
+//
+// A token is base64url(JSON session) + "." + base64url(HMAC-SHA256). Tokens are
+// stateless, so every API node sharing the same secret accepts them.
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// DefaultSessionTTL is the lifetime of a single token; clients refresh in-band before it lapses
+	DefaultSessionTTL = 15 * time.Minute
+
+	// DefaultMaxSessionAge caps how long a session can be kept alive by refreshing
+	DefaultMaxSessionAge = 24 * time.Hour
+
+	// MaxLoginClockSkew is how far a wallet login timestamp may be from server time
+	MaxLoginClockSkew = 5 * time.Minute
+
+	// loginMessagePrefix is the domain separator for wallet login signatures
+	loginMessagePrefix = "perpdex-ws-login"
+)
+
+// Session errors
+var (
+	ErrInvalidToken     = errors.New("invalid session token")
+	ErrTokenExpired     = errors.New("session token expired")
+	ErrSessionTooOld    = errors.New("session exceeded maximum age, login again")
+	ErrInvalidAPIKey    = errors.New("invalid API key")
+	ErrInvalidSignature = errors.New("invalid wallet signature")
+	ErrLoginExpired     = errors.New("login timestamp outside allowed window")
+)
+
+// Method identifies how a session was established
+type Method string
+
+const (
+	MethodAPIKey Method = "api_key"
+	MethodWallet Method = "wallet"
+)
+
+// Permission grants a session access to licensed data
+type Permission string
+
+const (
+	// PermissionL3Data grants the market-by-order (L3) feed
+	PermissionL3Data Permission = "l3_data"
+)
+
+// Session is the signed payload of a session token
+type Session struct {
+	SessionID   string       `json:"sid"`
+	Trader      string       `json:"sub"`
+	Method      Method       `json:"mth"`
+	AuthTime    int64        `json:"auth_time"` // unix seconds of the original login
+	IssuedAt    int64        `json:"iat"`
+	ExpiresAt   int64        `json:"exp"`
+	Permissions []Permission `json:"perms,omitempty"`
+}
+
+// Expiry returns the token expiry time
+func (s *Session) Expiry() time.Time {
+	return time.Unix(s.ExpiresAt, 0)
+}
+
+// HasPermission returns true if the session was granted p
+func (s *Session) HasPermission(p Permission) bool {
+	for _, granted := range s.Permissions {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyStore resolves API keys to the trader address they act for
+type APIKeyStore interface {
+	Trader(apiKey string) (string, bool)
+}
+
+// StaticAPIKeys is an in-memory API key store (key -> trader address)
+type StaticAPIKeys map[string]string
+
+// Trader implements APIKeyStore
+func (s StaticAPIKeys) Trader(apiKey string) (string, bool) {
+	trader, ok := s[apiKey]
+	return trader, ok
+}
+
+// PermissionStore resolves the data permissions granted to a trader
+type PermissionStore interface {
+	Permissions(trader string) []Permission
+}
+
+// StaticPermissions is an in-memory permission store (trader -> permissions)
+type StaticPermissions map[string][]Permission
+
+// Permissions implements PermissionStore
+func (s StaticPermissions) Permissions(trader string) []Permission {
+	return s[trader]
+}
+
+// Config contains session manager configuration
+type Config struct {
+	Secret        []byte        // HMAC key; must be shared by all API nodes. Empty generates a per-process key.
+	TTL           time.Duration // Token lifetime
+	MaxSessionAge time.Duration // Maximum time since login a session can be refreshed for
+	APIKeys       APIKeyStore
+	Permissions   PermissionStore // Data licenses; resolved at login and on every refresh
+}
+
+// SessionManager issues, verifies and refreshes session tokens
+type SessionManager struct {
+	secret        []byte
+	ttl           time.Duration
+	maxSessionAge time.Duration
+	apiKeys       APIKeyStore
+	permissions   PermissionStore
+	now           func() time.Time
+}
+
+// NewSessionManager creates a new SessionManager
+func NewSessionManager(config Config) *SessionManager {
+	secret := config.Secret
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		_, _ = rand.Read(secret)
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultSessionTTL
+	}
+	if config.MaxSessionAge <= 0 {
+		config.MaxSessionAge = DefaultMaxSessionAge
+	}
+	if config.APIKeys == nil {
+		config.APIKeys = StaticAPIKeys{}
+	}
+	if config.Permissions == nil {
+		config.Permissions = StaticPermissions{}
+	}
+
+	return &SessionManager{
+		secret:        secret,
+		ttl:           config.TTL,
+		maxSessionAge: config.MaxSessionAge,
+		apiKeys:       config.APIKeys,
+		permissions:   config.Permissions,
+		now:           time.Now,
+	}
+}
+
+// LoginMessage returns the message a wallet signs to log in
+func LoginMessage(trader string, timestamp int64) string {
+	return fmt.Sprintf("%s:%s:%d", loginMessagePrefix, trader, timestamp)
+}
+
+// LoginWithAPIKey starts a session for the trader bound to an API key
+func (m *SessionManager) LoginWithAPIKey(apiKey string) (string, *Session, error) {
+	trader, ok := m.apiKeys.Trader(apiKey)
+	if !ok || apiKey == "" {
+		return "", nil, ErrInvalidAPIKey
+	}
+	return m.issue(newSessionID(), trader, MethodAPIKey, m.now().Unix())
+}
+
+// LoginWithWallet starts a session for a trader proving ownership of its address.
+// pubKey is the compressed secp256k1 public key and signature the 64-byte r||s
+// signature of LoginMessage(trader, timestamp).
+func (m *SessionManager) LoginWithWallet(trader string, timestamp int64, pubKey, signature []byte) (string, *Session, error) {
+	now := m.now()
+	skew := now.Sub(time.Unix(timestamp, 0))
+	if skew > MaxLoginClockSkew || skew < -MaxLoginClockSkew {
+		return "", nil, ErrLoginExpired
+	}
+
+	addr, err := sdk.AccAddressFromBech32(trader)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: invalid trader address", ErrInvalidSignature)
+	}
+	if len(pubKey) != secp256k1.PubKeySize {
+		return "", nil, fmt.Errorf("%w: invalid public key", ErrInvalidSignature)
+	}
+
+	pk := &secp256k1.PubKey{Key: pubKey}
+	if !bytes.Equal(pk.Address(), addr) {
+		return "", nil, fmt.Errorf("%w: public key does not match trader", ErrInvalidSignature)
+	}
+	if !pk.VerifySignature([]byte(LoginMessage(trader, timestamp)), signature) {
+		return "", nil, ErrInvalidSignature
+	}
+
+	return m.issue(newSessionID(), trader, MethodWallet, now.Unix())
+}
+
+// Verify checks a token's signature and expiry and returns its session
+func (m *SessionManager) Verify(token string) (*Session, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	expected := m.sign(payload)
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, expected) {
+		return nil, ErrInvalidToken
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var session Session
+	if err := json.Unmarshal(raw, &session); err != nil || session.Trader == "" {
+		return nil, ErrInvalidToken
+	}
+
+	if m.now().Unix() >= session.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+	return &session, nil
+}
+
+// Refresh issues a new token for a live session, keeping its ID and login
+// time. Permissions are resolved again, so a revoked license lapses with the
+// current token.
+func (m *SessionManager) Refresh(session *Session) (string, *Session, error) {
+	now := m.now()
+	if now.Unix() >= session.ExpiresAt {
+		return "", nil, ErrTokenExpired
+	}
+	if now.Sub(time.Unix(session.AuthTime, 0)) >= m.maxSessionAge {
+		return "", nil, ErrSessionTooOld
+	}
+	return m.issue(session.SessionID, session.Trader, session.Method, session.AuthTime)
+}
+
+// issue signs a new token carrying the trader's current permissions
+func (m *SessionManager) issue(sessionID, trader string, method Method, authTime int64) (string, *Session, error) {
+	now := m.now()
+	session := &Session{
+		SessionID:   sessionID,
+		Trader:      trader,
+		Method:      method,
+		AuthTime:    authTime,
+		IssuedAt:    now.Unix(),
+		ExpiresAt:   now.Add(m.ttl).Unix(),
+		Permissions: m.permissions.Permissions(trader),
+	}
+
+	raw, err := json.Marshal(session)
+	if err != nil {
+		return "", nil, err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	token := payload + "." + base64.RawURLEncoding.EncodeToString(m.sign(payload))
+	return token, session, nil
+}
+
+func (m *SessionManager) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
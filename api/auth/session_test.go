@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func newTestManager(now *time.Time) *SessionManager {
+	m := NewSessionManager(Config{
+		Secret:  []byte("test-secret"),
+		TTL:     time.Minute,
+		APIKeys: StaticAPIKeys{"key-1": "cosmos1trader"},
+	})
+	m.now = func() time.Time { return *now }
+	return m
+}
+
+// TestAPIKeyLoginAndVerify tests issuing and verifying a token
+func TestAPIKeyLoginAndVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := newTestManager(&now)
+
+	if _, _, err := m.LoginWithAPIKey("wrong"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected ErrInvalidAPIKey, got %v", err)
+	}
+
+	token, session, err := m.LoginWithAPIKey("key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verified, err := m.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified.Trader != "cosmos1trader" || verified.SessionID != session.SessionID {
+		t.Errorf("unexpected session: %+v", verified)
+	}
+
+	// Tampering with the payload invalidates the signature
+	if _, err := m.Verify("x" + token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken, got %v", err)
+	}
+
+	// Another node with a different secret rejects the token
+	other := NewSessionManager(Config{Secret: []byte("other-secret")})
+	if _, err := other.Verify(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken from other secret, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := m.Verify(token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+}
+
+// TestRefresh tests that refresh keeps the session and enforces max age
+func TestRefresh(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := newTestManager(&now)
+
+	_, session, err := m.LoginWithAPIKey("key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(30 * time.Second)
+	token, refreshed, err := m.Refresh(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.SessionID != session.SessionID || refreshed.ExpiresAt <= session.ExpiresAt {
+		t.Errorf("expected same session with later expiry, got %+v", refreshed)
+	}
+	if _, err := m.Verify(token); err != nil {
+		t.Errorf("refreshed token should verify: %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, _, err := m.Refresh(refreshed); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+
+	// Keep refreshing until the maximum session age is reached
+	m.maxSessionAge = time.Hour
+	live := &Session{SessionID: "s", Trader: "cosmos1trader", AuthTime: now.Add(-time.Hour).Unix(), ExpiresAt: now.Add(time.Minute).Unix()}
+	if _, _, err := m.Refresh(live); !errors.Is(err, ErrSessionTooOld) {
+		t.Errorf("expected ErrSessionTooOld, got %v", err)
+	}
+}
+
+// TestWalletLogin tests login with a secp256k1 signature
+func TestWalletLogin(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := newTestManager(&now)
+
+	priv := secp256k1.GenPrivKey()
+	pub := priv.PubKey().Bytes()
+	trader := sdk.AccAddress(priv.PubKey().Address()).String()
+
+	sig, err := priv.Sign([]byte(LoginMessage(trader, now.Unix())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, session, err := m.LoginWithWallet(trader, now.Unix(), pub, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Trader != trader || session.Method != MethodWallet {
+		t.Errorf("unexpected session: %+v", session)
+	}
+
+	// Signature for another trader's address is rejected
+	otherTrader := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address()).String()
+	if _, _, err := m.LoginWithWallet(otherTrader, now.Unix(), pub, sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	// Stale timestamps cannot be replayed
+	stale := now.Add(-10 * time.Minute).Unix()
+	staleSig, _ := priv.Sign([]byte(LoginMessage(trader, stale)))
+	if _, _, err := m.LoginWithWallet(trader, stale, pub, staleSig); !errors.Is(err, ErrLoginExpired) {
+		t.Errorf("expected ErrLoginExpired, got %v", err)
+	}
+}
+
+// TestPermissions tests that tokens carry the trader's data licenses and that
+// a revoked license lapses on refresh
+func TestPermissions(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	licenses := StaticPermissions{"cosmos1trader": {PermissionL3Data}}
+	m := NewSessionManager(Config{
+		Secret:      []byte("test-secret"),
+		TTL:         time.Minute,
+		APIKeys:     StaticAPIKeys{"key-1": "cosmos1trader", "key-2": "cosmos1other"},
+		Permissions: licenses,
+	})
+	m.now = func() time.Time { return now }
+
+	token, session, err := m.LoginWithAPIKey("key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verified, err := m.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !session.HasPermission(PermissionL3Data) || !verified.HasPermission(PermissionL3Data) {
+		t.Errorf("expected the L3 license in the token, got %v", verified.Permissions)
+	}
+
+	_, other, err := m.LoginWithAPIKey("key-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.HasPermission(PermissionL3Data) {
+		t.Error("expected an unlicensed trader to have no L3 permission")
+	}
+
+	delete(licenses, "cosmos1trader")
+	_, refreshed, err := m.Refresh(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.HasPermission(PermissionL3Data) {
+		t.Error("expected the revoked license to lapse on refresh")
+	}
+}
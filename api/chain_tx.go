@@ -0,0 +1,304 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+
+	"github.com/openalpha/perp-dex/api/chaintx"
+	"github.com/openalpha/perp-dex/api/types"
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// chainTxRequest carries a signed transaction as base64 protobuf TxRaw bytes
+type chainTxRequest struct {
+	TxBytes []byte `json:"tx_bytes"`
+}
+
+// decodeChainTx reads the signed transaction of a POST /v1/tx request, or
+// writes the error and returns nil
+func (s *Server) decodeChainTx(w http.ResponseWriter, r *http.Request) []byte {
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return nil
+	}
+	if s.config.ChainTx == nil {
+		writeError(w, types.ErrCodeNotImplemented, "Transaction submission requires a chain node (--chain-grpc)")
+		return nil
+	}
+	var req chainTxRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body, tx_bytes must be base64")
+		return nil
+	}
+	if len(req.TxBytes) == 0 {
+		writeError(w, types.ErrCodeMissingField, "tx_bytes is required")
+		return nil
+	}
+	return req.TxBytes
+}
+
+// handleTxSimulate handles POST /v1/tx/simulate
+func (s *Server) handleTxSimulate(w http.ResponseWriter, r *http.Request) {
+	txBytes := s.decodeChainTx(w, r)
+	if txBytes == nil {
+		return
+	}
+	sim, err := s.config.ChainTx.Simulate(r.Context(), txBytes)
+	if err != nil {
+		writeAPIError(w, chainTxAPIError(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, sim)
+}
+
+// handleTx handles POST /v1/tx: the transaction is simulated and only
+// broadcast if it succeeds within its gas limit
+func (s *Server) handleTx(w http.ResponseWriter, r *http.Request) {
+	txBytes := s.decodeChainTx(w, r)
+	if txBytes == nil {
+		return
+	}
+	res, err := s.config.ChainTx.Broadcast(r.Context(), txBytes)
+	if err != nil {
+		writeAPIError(w, chainTxAPIError(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// txOrderRequest is an order message to build as a chain transaction.
+// Without PubKey it is signed by the API's hot key, which only signs for its
+// own address and only for admin requests.
+type txOrderRequest struct {
+	Trader   string `json:"trader"`
+	MarketID string `json:"market_id"`
+	Side     string `json:"side"`
+	Type     string `json:"type"`
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+	OrderID  string `json:"order_id"` // cancels only
+	PubKey   []byte `json:"pub_key"`  // compressed secp256k1 key of the trader, base64
+}
+
+// txSignedRequest is a prepared transaction with the trader's signature
+type txSignedRequest struct {
+	BodyBytes     []byte `json:"body_bytes"`
+	AuthInfoBytes []byte `json:"auth_info_bytes"`
+	Signature     []byte `json:"signature"`
+}
+
+// txService returns the transaction service, or writes the error and
+// returns nil
+func (s *Server) txService(w http.ResponseWriter, r *http.Request, method string) *chaintx.TxService {
+	if r.Method != method {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return nil
+	}
+	if s.config.TxService == nil {
+		writeError(w, types.ErrCodeNotImplemented, "Transaction submission requires a chain node (--chain-grpc)")
+		return nil
+	}
+	return s.config.TxService
+}
+
+// handleTxOrder handles POST /v1/tx/orders
+func (s *Server) handleTxOrder(w http.ResponseWriter, r *http.Request) {
+	svc := s.txService(w, r, http.MethodPost)
+	if svc == nil {
+		return
+	}
+	var req txOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+		return
+	}
+	if req.Trader == "" || req.MarketID == "" || req.Quantity == "" {
+		writeError(w, types.ErrCodeMissingField, "trader, market_id and quantity are required")
+		return
+	}
+
+	msg := &orderbooktypes.MsgPlaceOrder{
+		Trader:   req.Trader,
+		MarketId: req.MarketID,
+		Price:    req.Price,
+		Quantity: req.Quantity,
+	}
+	switch req.Side {
+	case "buy":
+		msg.Side = orderbooktypes.SideBuy
+	case "sell":
+		msg.Side = orderbooktypes.SideSell
+	default:
+		writeError(w, types.ErrCodeInvalidSide, "side must be buy or sell")
+		return
+	}
+	switch req.Type {
+	case "limit", "":
+		if req.Price == "" {
+			writeError(w, types.ErrCodeMissingField, "price is required for limit orders")
+			return
+		}
+		msg.OrderType = orderbooktypes.OrderTypeLimit
+	case "market":
+		msg.OrderType = orderbooktypes.OrderTypeMarket
+	default:
+		writeError(w, types.ErrCodeInvalidOrderType, "type must be limit or market")
+		return
+	}
+	s.executeTxMsg(w, r, svc, msg, req.PubKey)
+}
+
+// handleTxCancel handles POST /v1/tx/orders/cancel
+func (s *Server) handleTxCancel(w http.ResponseWriter, r *http.Request) {
+	svc := s.txService(w, r, http.MethodPost)
+	if svc == nil {
+		return
+	}
+	var req txOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+		return
+	}
+	if req.Trader == "" || req.OrderID == "" {
+		writeError(w, types.ErrCodeMissingField, "trader and order_id are required")
+		return
+	}
+	s.executeTxMsg(w, r, svc, &orderbooktypes.MsgCancelOrder{Trader: req.Trader, OrderId: req.OrderID}, req.PubKey)
+}
+
+// executeTxMsg signs a message with the hot key and writes its receipt, or
+// with a public key writes the transaction for the trader to sign
+func (s *Server) executeTxMsg(w http.ResponseWriter, r *http.Request, svc *chaintx.TxService, msg chaintx.TraderMsg, pubKey []byte) {
+	if len(pubKey) > 0 {
+		if len(pubKey) != secp256k1.PubKeySize {
+			writeError(w, types.ErrCodeInvalidRequest, "pub_key must be a 33-byte compressed secp256k1 key")
+			return
+		}
+		prepared, err := svc.Prepare(r.Context(), msg, &secp256k1.PubKey{Key: pubKey})
+		if err != nil {
+			writeAPIError(w, chainTxAPIError(err))
+			return
+		}
+		writeJSON(w, http.StatusOK, prepared)
+		return
+	}
+
+	if svc.HotAddress() == "" || msg.GetTrader() != svc.HotAddress() {
+		writeError(w, types.ErrCodeMissingField, "pub_key is required to sign for "+msg.GetTrader())
+		return
+	}
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required to sign with the hot key")
+		return
+	}
+	receipt, err := svc.Execute(r.Context(), msg)
+	s.writeTxReceipt(w, receipt, err)
+}
+
+// handleTxSigned handles POST /v1/tx/signed
+func (s *Server) handleTxSigned(w http.ResponseWriter, r *http.Request) {
+	svc := s.txService(w, r, http.MethodPost)
+	if svc == nil {
+		return
+	}
+	var req txSignedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body, bytes must be base64")
+		return
+	}
+	if len(req.BodyBytes) == 0 || len(req.AuthInfoBytes) == 0 || len(req.Signature) == 0 {
+		writeError(w, types.ErrCodeMissingField, "body_bytes, auth_info_bytes and signature are required")
+		return
+	}
+	receipt, err := svc.Submit(r.Context(), req.BodyBytes, req.AuthInfoBytes, req.Signature)
+	s.writeTxReceipt(w, receipt, err)
+}
+
+// handleTxLookup handles GET /v1/tx/{hash}
+func (s *Server) handleTxLookup(w http.ResponseWriter, r *http.Request) {
+	svc := s.txService(w, r, http.MethodGet)
+	if svc == nil {
+		return
+	}
+	hash := strings.TrimPrefix(r.URL.Path, "/v1/tx/")
+	if hash == "" || strings.Contains(hash, "/") {
+		writeError(w, types.ErrCodeNotFound, "Endpoint not found")
+		return
+	}
+	receipt, err := svc.Lookup(r.Context(), hash)
+	if err != nil {
+		writeAPIError(w, chainTxAPIError(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, receipt)
+}
+
+// writeTxReceipt writes the receipt of an included transaction, 202 for a
+// transaction still waiting for a block
+func (s *Server) writeTxReceipt(w http.ResponseWriter, receipt *chaintx.Receipt, err error) {
+	var pending *chaintx.PendingError
+	if errors.As(err, &pending) {
+		writeJSON(w, http.StatusAccepted, map[string]string{
+			"tx_hash": pending.TxHash,
+			"status":  "pending",
+		})
+		return
+	}
+	if err != nil {
+		writeAPIError(w, chainTxAPIError(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, receipt)
+}
+
+// chainTxAPIError maps a simulation or broadcast failure to the API error
+// code of its module error where one is known, keeping the node's reason
+func chainTxAPIError(err error) *types.APIError {
+	var (
+		simErr      *chaintx.SimulationError
+		gasErr      *chaintx.InsufficientGasError
+		rejectedErr *chaintx.RejectedError
+		failedErr   *chaintx.FailedError
+	)
+	switch {
+	case errors.Is(err, chaintx.ErrInvalidTx), errors.Is(err, chaintx.ErrSignerMismatch):
+		return types.NewAPIError(types.ErrCodeInvalidRequest, err.Error())
+
+	case errors.Is(err, chaintx.ErrAccountNotFound):
+		return types.NewAPIError(types.ErrCodeAccountNotFound, err.Error())
+
+	case errors.Is(err, chaintx.ErrTxNotFound):
+		return types.NewAPIError(types.ErrCodeTxNotFound, err.Error())
+
+	case errors.As(err, &simErr):
+		apiErr := types.NewAPIError(types.ErrorCodeOf(errors.New(simErr.Reason), types.ErrCodeTxSimulationFailed), simErr.Reason).
+			WithDetail("gas_used", simErr.GasUsed)
+		if simErr.MessageIndex >= 0 {
+			apiErr.WithDetail("message_index", simErr.MessageIndex)
+		}
+		return apiErr
+
+	case errors.As(err, &gasErr):
+		return types.NewAPIError(types.ErrCodeInsufficientGas, err.Error()).
+			WithDetail("gas_limit", gasErr.GasLimit).
+			WithDetail("gas_used", gasErr.GasUsed).
+			WithDetail("recommended_gas_limit", gasErr.RecommendedGasLimit)
+
+	case errors.As(err, &rejectedErr):
+		return types.ToAPIError(err, types.ErrCodeTxRejected).
+			WithDetail("codespace", rejectedErr.Codespace).
+			WithDetail("abci_code", rejectedErr.Code)
+
+	case errors.As(err, &failedErr):
+		return types.ToAPIError(err, types.ErrCodeTxFailed).
+			WithDetail("tx_hash", failedErr.TxHash).
+			WithDetail("height", failedErr.Height).
+			WithDetail("codespace", failedErr.Codespace).
+			WithDetail("abci_code", failedErr.Code)
+	}
+	return types.NewAPIError(types.ErrCodeServiceUnavailable, err.Error())
+}
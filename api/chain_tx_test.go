@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/chaintx"
+	"github.com/openalpha/perp-dex/api/types"
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestChainTxErrors tests that simulation failures reach the client with the
+// node's reason, the failing message and the gas to retry with
+func TestChainTxErrors(t *testing.T) {
+	apiErr := chainTxAPIError(&chaintx.SimulationError{Reason: "insufficient margin: need 500", MessageIndex: 0, GasUsed: 61234})
+	if apiErr.Code != types.ErrCodeInsufficientMargin || apiErr.Message != "insufficient margin: need 500" || apiErr.Details["message_index"] != 0 {
+		t.Errorf("expected the module's error code and reason, got %+v", apiErr)
+	}
+	if apiErr := chainTxAPIError(&chaintx.SimulationError{Reason: "unknown failure", MessageIndex: -1}); apiErr.Code != types.ErrCodeTxSimulationFailed {
+		t.Errorf("expected an unmapped reason to be a simulation failure, got %+v", apiErr)
+	}
+	apiErr = chainTxAPIError(&chaintx.InsufficientGasError{GasLimit: 50000, GasUsed: 80000, RecommendedGasLimit: 104000})
+	if apiErr.Code != types.ErrCodeInsufficientGas || apiErr.Details["recommended_gas_limit"] != uint64(104000) {
+		t.Errorf("expected the recommended gas limit, got %+v", apiErr)
+	}
+	apiErr = chainTxAPIError(&chaintx.FailedError{TxHash: "ABC", Height: 42, Codespace: "orderbook", Code: orderbooktypes.ErrOrderNotFound.ABCICode(), Log: "order not found"})
+	if apiErr.Code != types.ErrCodeOrderNotFound || apiErr.Details["height"] != int64(42) {
+		t.Errorf("expected a failed transaction to map to its module error, got %+v", apiErr)
+	}
+	if apiErr := chainTxAPIError(fmt.Errorf("%w: ABC", chaintx.ErrTxNotFound)); apiErr.Code != types.ErrCodeTxNotFound {
+		t.Errorf("expected an unknown transaction not to be found, got %+v", apiErr)
+	}
+
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	rec := httptest.NewRecorder()
+	NewServer(config).handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tx", strings.NewReader(`{"tx_bytes":"AA=="}`)))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 without a chain node, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	NewServer(config).handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tx/orders", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 for order transactions without a chain node, got %d", rec.Code)
+	}
+}
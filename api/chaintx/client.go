@@ -0,0 +1,418 @@
+// Package chaintx submits signed transactions to a perpdexd node for the API.
+// Every transaction is simulated against the node's state before it is
+// broadcast, so a client learns the gas it needs and why a transaction would
+// fail without paying fees for a rejected transaction. TxService builds and
+// signs orderbook transactions and tracks them until they are included.
+package chaintx
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	errorsmod "cosmossdk.io/errors"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultGasAdjustment is the factor simulated gas is multiplied by for the
+// recommended gas limit, covering state that changes before the transaction
+// is included
+const DefaultGasAdjustment = 1.3
+
+// Sentinel errors
+var (
+	ErrInvalidTx       = errors.New("invalid transaction")
+	ErrTxNotFound      = errors.New("transaction not found")
+	ErrAccountNotFound = errors.New("account not found on chain")
+)
+
+// Config contains chain node client configuration
+type Config struct {
+	GRPCAddr         string        // host:port of the node's gRPC server
+	GasAdjustment    float64       // Simulated gas multiplier for the recommended gas limit; 0 uses DefaultGasAdjustment
+	Timeout          time.Duration // Per-call timeout
+	InclusionTimeout time.Duration // How long to wait for a broadcast transaction to be included in a block
+	PollInterval     time.Duration // Time between lookups of a broadcast transaction
+}
+
+// DefaultConfig returns default chain node client configuration
+func DefaultConfig() *Config {
+	return &Config{
+		GRPCAddr:         "localhost:9090",
+		GasAdjustment:    DefaultGasAdjustment,
+		Timeout:          10 * time.Second,
+		InclusionTimeout: 30 * time.Second,
+		PollInterval:     500 * time.Millisecond,
+	}
+}
+
+// Simulation is the gas a transaction consumed when simulated
+type Simulation struct {
+	GasUsed             uint64  `json:"gas_used"`
+	GasLimit            uint64  `json:"gas_limit"` // the transaction's own limit
+	RecommendedGasLimit uint64  `json:"recommended_gas_limit"`
+	GasAdjustment       float64 `json:"gas_adjustment"`
+}
+
+// Result is a transaction accepted into the node's mempool
+type Result struct {
+	TxHash   string `json:"tx_hash"`
+	GasUsed  uint64 `json:"gas_used"` // simulated
+	GasLimit uint64 `json:"gas_limit"`
+}
+
+// SimulationError is a transaction that failed in simulation. MessageIndex
+// is the failing message, -1 when the ante handler rejected the transaction.
+type SimulationError struct {
+	Reason       string
+	MessageIndex int
+	GasUsed      uint64
+}
+
+func (e *SimulationError) Error() string { return e.Reason }
+
+// InsufficientGasError is a transaction whose gas limit is below the gas it
+// used in simulation
+type InsufficientGasError struct {
+	GasLimit            uint64
+	GasUsed             uint64
+	RecommendedGasLimit uint64
+}
+
+func (e *InsufficientGasError) Error() string {
+	return fmt.Sprintf("gas limit %d is below the %d gas used in simulation; use at least %d",
+		e.GasLimit, e.GasUsed, e.RecommendedGasLimit)
+}
+
+// RejectedError is a transaction the node's CheckTx rejected. It unwraps to
+// the module error registered under its codespace and code, if any.
+type RejectedError struct {
+	Codespace string
+	Code      uint32
+	Log       string
+}
+
+func (e *RejectedError) Error() string { return e.Log }
+
+func (e *RejectedError) Unwrap() error {
+	return errorsmod.ABCIError(e.Codespace, e.Code, e.Log)
+}
+
+// Event is an ABCI event emitted by an included transaction
+type Event struct {
+	Type       string            `json:"type"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// Inclusion is a transaction included in a block
+type Inclusion struct {
+	TxHash    string  `json:"tx_hash"`
+	Height    int64   `json:"height"`
+	GasWanted int64   `json:"gas_wanted"`
+	GasUsed   int64   `json:"gas_used"`
+	Events    []Event `json:"events"`
+
+	// msgResponses are the packed responses of the transaction's messages
+	msgResponses []*codectypes.Any
+}
+
+// FailedError is a transaction included in a block whose messages failed.
+// It unwraps to the module error registered under its codespace and code.
+type FailedError struct {
+	TxHash    string
+	Height    int64
+	Codespace string
+	Code      uint32
+	Log       string
+}
+
+func (e *FailedError) Error() string { return e.Log }
+
+func (e *FailedError) Unwrap() error {
+	return errorsmod.ABCIError(e.Codespace, e.Code, e.Log)
+}
+
+// PendingError is a broadcast transaction not yet included when the client
+// stopped waiting; it may still be included later
+type PendingError struct {
+	TxHash string
+}
+
+func (e *PendingError) Error() string {
+	return fmt.Sprintf("transaction %s not yet included in a block", e.TxHash)
+}
+
+// Client simulates, broadcasts and looks up transactions through a node's
+// tx service
+type Client struct {
+	config *Config
+	conn   *grpc.ClientConn
+	tx     txtypes.ServiceClient
+	auth   authtypes.QueryClient
+}
+
+// NewClient connects to a chain node
+func NewClient(config *Config) (*Client, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	conn, err := grpc.Dial(
+		config.GRPCAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(gogoCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to chain node at %s: %w", config.GRPCAddr, err)
+	}
+	client := newClient(config, txtypes.NewServiceClient(conn), authtypes.NewQueryClient(conn))
+	client.conn = conn
+	return client, nil
+}
+
+func newClient(config *Config, tx txtypes.ServiceClient, auth authtypes.QueryClient) *Client {
+	defaults := DefaultConfig()
+	if config.GasAdjustment <= 0 {
+		config.GasAdjustment = defaults.GasAdjustment
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.InclusionTimeout <= 0 {
+		config.InclusionTimeout = defaults.InclusionTimeout
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+	return &Client{config: config, tx: tx, auth: auth}
+}
+
+// GasAdjustment returns the factor applied to simulated gas
+func (c *Client) GasAdjustment() float64 {
+	return c.config.GasAdjustment
+}
+
+// Close closes the connection to the node
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Simulate runs a signed transaction against the node's latest state without
+// committing it and returns the gas it used
+func (c *Client) Simulate(ctx context.Context, txBytes []byte) (*Simulation, error) {
+	gasLimit, err := gasLimitOf(txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTx, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	resp, err := c.tx.Simulate(ctx, &txtypes.SimulateRequest{TxBytes: txBytes})
+	if err != nil {
+		return nil, simulationError(err)
+	}
+
+	var gasUsed uint64
+	if resp.GasInfo != nil {
+		gasUsed = resp.GasInfo.GasUsed
+	}
+	return &Simulation{
+		GasUsed:             gasUsed,
+		GasLimit:            gasLimit,
+		RecommendedGasLimit: uint64(math.Ceil(float64(gasUsed) * c.config.GasAdjustment)),
+		GasAdjustment:       c.config.GasAdjustment,
+	}, nil
+}
+
+// Broadcast simulates a signed transaction and, if it succeeds within its gas
+// limit, broadcasts it and waits for CheckTx
+func (c *Client) Broadcast(ctx context.Context, txBytes []byte) (*Result, error) {
+	sim, err := c.Simulate(ctx, txBytes)
+	if err != nil {
+		return nil, err
+	}
+	if sim.GasLimit < sim.GasUsed {
+		return nil, &InsufficientGasError{
+			GasLimit:            sim.GasLimit,
+			GasUsed:             sim.GasUsed,
+			RecommendedGasLimit: sim.RecommendedGasLimit,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	resp, err := c.tx.BroadcastTx(ctx, &txtypes.BroadcastTxRequest{
+		TxBytes: txBytes,
+		Mode:    txtypes.BroadcastMode_BROADCAST_MODE_SYNC,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("broadcast failed: %w", err)
+	}
+	if resp.TxResponse == nil {
+		return nil, fmt.Errorf("broadcast failed: empty response")
+	}
+	if resp.TxResponse.Code != 0 {
+		return nil, &RejectedError{
+			Codespace: resp.TxResponse.Codespace,
+			Code:      resp.TxResponse.Code,
+			Log:       resp.TxResponse.RawLog,
+		}
+	}
+	return &Result{TxHash: resp.TxResponse.TxHash, GasUsed: sim.GasUsed, GasLimit: sim.GasLimit}, nil
+}
+
+// Account returns the account number and next sequence of an address
+func (c *Client) Account(ctx context.Context, address string) (accountNumber, sequence uint64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	resp, err := c.auth.AccountInfo(ctx, &authtypes.QueryAccountInfoRequest{Address: address})
+	if status.Code(err) == codes.NotFound {
+		return 0, 0, fmt.Errorf("%w: %s", ErrAccountNotFound, address)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("account lookup failed: %w", err)
+	}
+	if resp.Info == nil {
+		return 0, 0, fmt.Errorf("%w: %s", ErrAccountNotFound, address)
+	}
+	return resp.Info.AccountNumber, resp.Info.Sequence, nil
+}
+
+// GetTx looks up an included transaction by hash
+func (c *Client) GetTx(ctx context.Context, hash string) (*Inclusion, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	resp, err := c.tx.GetTx(ctx, &txtypes.GetTxRequest{Hash: hash})
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("%w: %s", ErrTxNotFound, hash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("transaction lookup failed: %w", err)
+	}
+	if resp.TxResponse == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTxNotFound, hash)
+	}
+	return inclusionOf(resp.TxResponse)
+}
+
+// WaitForInclusion polls for a broadcast transaction until it is included,
+// returning a PendingError once the inclusion timeout passes
+func (c *Client) WaitForInclusion(ctx context.Context, hash string) (*Inclusion, error) {
+	deadline := time.Now().Add(c.config.InclusionTimeout)
+	for {
+		inclusion, err := c.GetTx(ctx, hash)
+		if !errors.Is(err, ErrTxNotFound) {
+			return inclusion, err
+		}
+		if time.Now().Add(c.config.PollInterval).After(deadline) {
+			return nil, &PendingError{TxHash: hash}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, &PendingError{TxHash: hash}
+		case <-time.After(c.config.PollInterval):
+		}
+	}
+}
+
+// inclusionOf converts an included transaction, or its failure
+func inclusionOf(res *sdk.TxResponse) (*Inclusion, error) {
+	if res.Code != 0 {
+		return nil, &FailedError{
+			TxHash:    res.TxHash,
+			Height:    res.Height,
+			Codespace: res.Codespace,
+			Code:      res.Code,
+			Log:       res.RawLog,
+		}
+	}
+	inclusion := &Inclusion{
+		TxHash:    res.TxHash,
+		Height:    res.Height,
+		GasWanted: res.GasWanted,
+		GasUsed:   res.GasUsed,
+		Events:    make([]Event, 0, len(res.Events)),
+	}
+	for _, e := range res.Events {
+		event := Event{Type: e.Type, Attributes: make(map[string]string, len(e.Attributes))}
+		for _, attr := range e.Attributes {
+			event.Attributes[attr.Key] = attr.Value
+		}
+		inclusion.Events = append(inclusion.Events, event)
+	}
+	if data, err := hex.DecodeString(res.Data); err == nil && len(data) > 0 {
+		var msgData sdk.TxMsgData
+		if err := msgData.Unmarshal(data); err == nil {
+			inclusion.msgResponses = msgData.MsgResponses
+		}
+	}
+	return inclusion, nil
+}
+
+// gasLimitOf reads the gas limit from a transaction's auth info, which
+// decodes without the chain's interface registry
+func gasLimitOf(txBytes []byte) (uint64, error) {
+	if len(txBytes) == 0 {
+		return 0, fmt.Errorf("empty transaction")
+	}
+	var raw txtypes.TxRaw
+	if err := raw.Unmarshal(txBytes); err != nil {
+		return 0, err
+	}
+	var authInfo txtypes.AuthInfo
+	if err := authInfo.Unmarshal(raw.AuthInfoBytes); err != nil {
+		return 0, err
+	}
+	if authInfo.Fee == nil || authInfo.Fee.GasLimit == 0 {
+		return 0, fmt.Errorf("transaction has no gas limit")
+	}
+	return authInfo.Fee.GasLimit, nil
+}
+
+var (
+	// The tx service appends the gas used to simulation failures
+	gasUsedSuffix = regexp.MustCompile(` with gas used: '(\d+)'\s*$`)
+	// Message failures are wrapped with the failing message's index
+	messageIndex = regexp.MustCompile(`^failed to execute message; message index: (\d+): `)
+)
+
+// simulationError converts a failed Simulate call. The tx service reports
+// every state failure as codes.Unknown carrying the error text.
+func simulationError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("simulation failed: %w", err)
+	}
+	switch st.Code() {
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", ErrInvalidTx, st.Message())
+	case codes.Unknown:
+	default:
+		return fmt.Errorf("simulation failed: %w", err)
+	}
+
+	simErr := &SimulationError{Reason: st.Message(), MessageIndex: -1}
+	if m := gasUsedSuffix.FindStringSubmatch(simErr.Reason); m != nil {
+		simErr.GasUsed, _ = strconv.ParseUint(m[1], 10, 64)
+		simErr.Reason = simErr.Reason[:len(simErr.Reason)-len(m[0])]
+	}
+	if m := messageIndex.FindStringSubmatch(simErr.Reason); m != nil {
+		simErr.MessageIndex, _ = strconv.Atoi(m[1])
+		simErr.Reason = simErr.Reason[len(m[0]):]
+	}
+	return simErr
+}
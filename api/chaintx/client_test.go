@@ -0,0 +1,108 @@
+package chaintx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeTxService simulates with a fixed result, records broadcasts and serves
+// included transactions by hash
+type fakeTxService struct {
+	txtypes.ServiceClient
+	gasUsed     uint64
+	simErr      error
+	broadcast   *sdk.TxResponse
+	broadcasted int
+	lastTx      []byte
+	included    map[string]*sdk.TxResponse
+}
+
+func (f *fakeTxService) Simulate(ctx context.Context, req *txtypes.SimulateRequest, opts ...grpc.CallOption) (*txtypes.SimulateResponse, error) {
+	if f.simErr != nil {
+		return nil, f.simErr
+	}
+	return &txtypes.SimulateResponse{GasInfo: &sdk.GasInfo{GasUsed: f.gasUsed}}, nil
+}
+
+func (f *fakeTxService) BroadcastTx(ctx context.Context, req *txtypes.BroadcastTxRequest, opts ...grpc.CallOption) (*txtypes.BroadcastTxResponse, error) {
+	f.broadcasted++
+	f.lastTx = req.TxBytes
+	return &txtypes.BroadcastTxResponse{TxResponse: f.broadcast}, nil
+}
+
+func (f *fakeTxService) GetTx(ctx context.Context, req *txtypes.GetTxRequest, opts ...grpc.CallOption) (*txtypes.GetTxResponse, error) {
+	res, ok := f.included[req.Hash]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tx not found: %s", req.Hash)
+	}
+	return &txtypes.GetTxResponse{TxResponse: res}, nil
+}
+
+func signedTx(t *testing.T, gasLimit uint64) []byte {
+	t.Helper()
+	authInfo, err := (&txtypes.AuthInfo{Fee: &txtypes.Fee{GasLimit: gasLimit}}).Marshal()
+	if err != nil {
+		t.Fatalf("failed to encode auth info: %v", err)
+	}
+	bz, err := (&txtypes.TxRaw{BodyBytes: []byte{}, AuthInfoBytes: authInfo, Signatures: [][]byte{{1}}}).Marshal()
+	if err != nil {
+		t.Fatalf("failed to encode tx: %v", err)
+	}
+	return bz
+}
+
+// TestSimulateBeforeBroadcast tests that a transaction is broadcast only when
+// it succeeds in simulation within its gas limit
+func TestSimulateBeforeBroadcast(t *testing.T) {
+	ctx := context.Background()
+	svc := &fakeTxService{gasUsed: 80000, broadcast: &sdk.TxResponse{TxHash: "ABC"}}
+	client := newClient(&Config{GasAdjustment: 1.5}, svc, nil)
+
+	sim, err := client.Simulate(ctx, signedTx(t, 100000))
+	if err != nil {
+		t.Fatalf("failed to simulate: %v", err)
+	}
+	if sim.GasUsed != 80000 || sim.GasLimit != 100000 || sim.RecommendedGasLimit != 120000 {
+		t.Errorf("expected 80000 used and 120000 recommended, got %+v", sim)
+	}
+
+	res, err := client.Broadcast(ctx, signedTx(t, 100000))
+	if err != nil || res.TxHash != "ABC" || svc.broadcasted != 1 {
+		t.Fatalf("expected the transaction to be broadcast, got %+v %v", res, err)
+	}
+
+	var gasErr *InsufficientGasError
+	if _, err := client.Broadcast(ctx, signedTx(t, 50000)); !errors.As(err, &gasErr) || gasErr.RecommendedGasLimit != 120000 {
+		t.Errorf("expected a gas limit below the simulated gas to be rejected, got %v", err)
+	}
+	if _, err := client.Broadcast(ctx, []byte("not a tx")); !errors.Is(err, ErrInvalidTx) {
+		t.Errorf("expected undecodable bytes to be rejected, got %v", err)
+	}
+
+	svc.simErr = status.Error(codes.Unknown,
+		"failed to execute message; message index: 1: insufficient margin: need 500 with gas used: '61234'")
+	var simErr *SimulationError
+	if _, err := client.Broadcast(ctx, signedTx(t, 100000)); !errors.As(err, &simErr) {
+		t.Fatalf("expected a simulation error, got %v", err)
+	}
+	if simErr.Reason != "insufficient margin: need 500" || simErr.MessageIndex != 1 || simErr.GasUsed != 61234 {
+		t.Errorf("expected the failing message and reason, got %+v", simErr)
+	}
+	if svc.broadcasted != 1 {
+		t.Errorf("expected failing transactions not to be broadcast, got %d broadcasts", svc.broadcasted)
+	}
+
+	svc.simErr = nil
+	svc.broadcast = &sdk.TxResponse{Codespace: sdkerrors.RootCodespace, Code: sdkerrors.ErrWrongSequence.ABCICode(), RawLog: "account sequence mismatch"}
+	if _, err := client.Broadcast(ctx, signedTx(t, 100000)); !errors.Is(err, sdkerrors.ErrWrongSequence) {
+		t.Errorf("expected the CheckTx rejection to unwrap to its registered error, got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package chaintx
+
+import (
+	"fmt"
+
+	gogoproto "github.com/cosmos/gogoproto/proto"
+)
+
+// gogoCodec encodes the node's gogoproto messages with their generated
+// methods. The default gRPC codec cannot handle gogoproto custom types such
+// as coin amounts, and the SDK's codec would need the interface registry of
+// every module to unpack transactions; Any fields are left packed here.
+type gogoCodec struct{}
+
+func (gogoCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(gogoproto.Message)
+	if !ok {
+		return nil, fmt.Errorf("cannot marshal %T: not a gogoproto message", v)
+	}
+	return gogoproto.Marshal(msg)
+}
+
+func (gogoCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(gogoproto.Message)
+	if !ok {
+		return fmt.Errorf("cannot unmarshal %T: not a gogoproto message", v)
+	}
+	return gogoproto.Unmarshal(data, msg)
+}
+
+func (gogoCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,321 @@
+package chaintx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	gogoproto "github.com/cosmos/gogoproto/proto"
+
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// draftGasLimit is the gas limit of the unsigned draft simulated to size a
+// transaction's real gas limit
+const draftGasLimit = 10_000_000
+
+// Sentinel errors
+var (
+	ErrNoHotKey       = errors.New("no hot key configured")
+	ErrSignerMismatch = errors.New("signer does not match the message trader")
+)
+
+// TxServiceConfig contains transaction building configuration
+type TxServiceConfig struct {
+	ChainID  string
+	GasPrice sdk.DecCoin         // Fee per unit of gas; a zero amount builds fee-less transactions
+	HotKey   cryptotypes.PrivKey // Optional key that signs for its own address; nil requires user signatures
+}
+
+// TraderMsg is an orderbook message signed by its trader
+type TraderMsg interface {
+	sdk.Msg
+	GetTrader() string
+}
+
+// Prepared is an unsigned transaction for a user to sign. The signature is
+// over SignDoc, a SIGN_MODE_DIRECT sign doc, and is submitted with the body
+// and auth info bytes unchanged.
+type Prepared struct {
+	BodyBytes     []byte `json:"body_bytes"`
+	AuthInfoBytes []byte `json:"auth_info_bytes"`
+	SignDoc       []byte `json:"sign_doc"`
+	ChainID       string `json:"chain_id"`
+	AccountNumber uint64 `json:"account_number"`
+	Sequence      uint64 `json:"sequence"`
+	GasLimit      uint64 `json:"gas_limit"`
+	Fee           string `json:"fee"`
+}
+
+// Receipt is an included transaction with the results of its order messages
+type Receipt struct {
+	Inclusion
+	OrderID      string `json:"order_id,omitempty"`
+	FilledQty    string `json:"filled_qty,omitempty"`
+	AvgPrice     string `json:"avg_price,omitempty"`
+	CancelledQty string `json:"cancelled_qty,omitempty"`
+}
+
+// TxService builds, signs and broadcasts orderbook messages and waits for
+// their inclusion. Transactions are signed either by the configured hot key,
+// for its own address, or by the trader from a prepared sign doc.
+type TxService struct {
+	client *Client
+	config TxServiceConfig
+
+	hotAddr  string
+	hotMu    sync.Mutex
+	hotSeq   uint64
+	hotAccNo uint64
+	seqValid bool // hotSeq is the next sequence; reset when a broadcast fails
+}
+
+// NewTxService creates a transaction service on a chain node client
+func NewTxService(client *Client, config TxServiceConfig) *TxService {
+	s := &TxService{client: client, config: config}
+	if config.HotKey != nil {
+		s.hotAddr = sdk.AccAddress(config.HotKey.PubKey().Address()).String()
+	}
+	return s
+}
+
+// HotAddress returns the address the hot key signs for, empty without one
+func (s *TxService) HotAddress() string {
+	return s.hotAddr
+}
+
+// Execute signs a message with the hot key, broadcasts it and waits for its
+// inclusion
+func (s *TxService) Execute(ctx context.Context, msg TraderMsg) (*Receipt, error) {
+	if s.config.HotKey == nil {
+		return nil, ErrNoHotKey
+	}
+	if msg.GetTrader() != s.hotAddr {
+		return nil, fmt.Errorf("%w: hot key signs for %s, not %s", ErrSignerMismatch, s.hotAddr, msg.GetTrader())
+	}
+
+	res, err := s.broadcastHot(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	return s.wait(ctx, res.TxHash)
+}
+
+// BroadcastCommit signs a settlement batch commitment with the hot key, which
+// must be the settlement operator's, broadcasts it and waits for its
+// inclusion
+func (s *TxService) BroadcastCommit(ctx context.Context, msg *orderbooktypes.MsgCommitTradeBatch) error {
+	if s.config.HotKey == nil {
+		return ErrNoHotKey
+	}
+	if msg.Submitter != s.hotAddr {
+		return fmt.Errorf("%w: hot key signs for %s, not %s", ErrSignerMismatch, s.hotAddr, msg.Submitter)
+	}
+
+	res, err := s.broadcastHot(ctx, msg)
+	if err != nil {
+		return err
+	}
+	_, err = s.wait(ctx, res.TxHash)
+	return err
+}
+
+// broadcastHot signs and broadcasts with the hot key. The lock is held until
+// the node accepts the transaction so the cached sequence stays in order.
+func (s *TxService) broadcastHot(ctx context.Context, msg sdk.Msg) (*Result, error) {
+	s.hotMu.Lock()
+	defer s.hotMu.Unlock()
+
+	if !s.seqValid {
+		accNo, seq, err := s.client.Account(ctx, s.hotAddr)
+		if err != nil {
+			return nil, err
+		}
+		s.hotAccNo, s.hotSeq, s.seqValid = accNo, seq, true
+	}
+
+	prepared, err := s.prepare(ctx, msg, s.config.HotKey.PubKey(), s.hotAccNo, s.hotSeq)
+	if err != nil {
+		s.seqValid = false
+		return nil, err
+	}
+	sig, err := s.config.HotKey.Sign(prepared.SignDoc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	res, err := s.broadcast(ctx, prepared.BodyBytes, prepared.AuthInfoBytes, sig)
+	if err != nil {
+		s.seqValid = false
+		return nil, err
+	}
+	s.hotSeq++
+	return res, nil
+}
+
+// Prepare builds an unsigned transaction for the trader of a message, who
+// must own pubKey, with its gas limit sized by simulation
+func (s *TxService) Prepare(ctx context.Context, msg TraderMsg, pubKey cryptotypes.PubKey) (*Prepared, error) {
+	if signer := sdk.AccAddress(pubKey.Address()).String(); signer != msg.GetTrader() {
+		return nil, fmt.Errorf("%w: public key is for %s, not %s", ErrSignerMismatch, signer, msg.GetTrader())
+	}
+	accNo, seq, err := s.client.Account(ctx, msg.GetTrader())
+	if err != nil {
+		return nil, err
+	}
+	return s.prepare(ctx, msg, pubKey, accNo, seq)
+}
+
+// Submit broadcasts a transaction prepared by Prepare with the trader's
+// signature and waits for its inclusion
+func (s *TxService) Submit(ctx context.Context, bodyBytes, authInfoBytes, signature []byte) (*Receipt, error) {
+	res, err := s.broadcast(ctx, bodyBytes, authInfoBytes, signature)
+	if err != nil {
+		return nil, err
+	}
+	return s.wait(ctx, res.TxHash)
+}
+
+// Lookup returns the receipt of an included transaction
+func (s *TxService) Lookup(ctx context.Context, hash string) (*Receipt, error) {
+	inclusion, err := s.client.GetTx(ctx, strings.ToUpper(hash))
+	if err != nil {
+		return nil, err
+	}
+	return receiptOf(inclusion), nil
+}
+
+func (s *TxService) broadcast(ctx context.Context, bodyBytes, authInfoBytes, signature []byte) (*Result, error) {
+	txBytes, err := (&txtypes.TxRaw{
+		BodyBytes:     bodyBytes,
+		AuthInfoBytes: authInfoBytes,
+		Signatures:    [][]byte{signature},
+	}).Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTx, err)
+	}
+	return s.client.Broadcast(ctx, txBytes)
+}
+
+func (s *TxService) wait(ctx context.Context, hash string) (*Receipt, error) {
+	inclusion, err := s.client.WaitForInclusion(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return receiptOf(inclusion), nil
+}
+
+// prepare simulates a draft of the transaction with an empty signature and
+// builds the transaction to sign with the recommended gas limit and its fee
+func (s *TxService) prepare(ctx context.Context, msg sdk.Msg, pubKey cryptotypes.PubKey, accNo, seq uint64) (*Prepared, error) {
+	msgAny, err := codectypes.NewAnyWithValue(msg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTx, err)
+	}
+	bodyBytes, err := (&txtypes.TxBody{Messages: []*codectypes.Any{msgAny}}).Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTx, err)
+	}
+	pubKeyAny, err := codectypes.NewAnyWithValue(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTx, err)
+	}
+
+	// The draft pays no fee: simulation skips the minimum gas price check but
+	// would still deduct a fee sized for the draft's gas limit
+	draft, err := authInfoBytesOf(pubKeyAny, seq, draftGasLimit, nil)
+	if err != nil {
+		return nil, err
+	}
+	draftBytes, err := (&txtypes.TxRaw{BodyBytes: bodyBytes, AuthInfoBytes: draft, Signatures: [][]byte{{}}}).Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTx, err)
+	}
+	sim, err := s.client.Simulate(ctx, draftBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	gasLimit := sim.RecommendedGasLimit
+	fee := s.feeFor(gasLimit)
+	authInfoBytes, err := authInfoBytesOf(pubKeyAny, seq, gasLimit, fee)
+	if err != nil {
+		return nil, err
+	}
+	signDoc, err := (&txtypes.SignDoc{
+		BodyBytes:     bodyBytes,
+		AuthInfoBytes: authInfoBytes,
+		ChainId:       s.config.ChainID,
+		AccountNumber: accNo,
+	}).Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTx, err)
+	}
+
+	return &Prepared{
+		BodyBytes:     bodyBytes,
+		AuthInfoBytes: authInfoBytes,
+		SignDoc:       signDoc,
+		ChainID:       s.config.ChainID,
+		AccountNumber: accNo,
+		Sequence:      seq,
+		GasLimit:      gasLimit,
+		Fee:           fee.String(),
+	}, nil
+}
+
+// feeFor returns the fee for a gas limit at the configured gas price,
+// rounded up
+func (s *TxService) feeFor(gasLimit uint64) sdk.Coins {
+	price := s.config.GasPrice
+	if price.Denom == "" || price.Amount.IsNil() || !price.Amount.IsPositive() {
+		return nil
+	}
+	amount := price.Amount.MulInt64(int64(gasLimit)).Ceil().TruncateInt()
+	return sdk.NewCoins(sdk.NewCoin(price.Denom, amount))
+}
+
+func authInfoBytesOf(pubKey *codectypes.Any, seq, gasLimit uint64, fee sdk.Coins) ([]byte, error) {
+	bz, err := (&txtypes.AuthInfo{
+		SignerInfos: []*txtypes.SignerInfo{{
+			PublicKey: pubKey,
+			ModeInfo: &txtypes.ModeInfo{
+				Sum: &txtypes.ModeInfo_Single_{Single: &txtypes.ModeInfo_Single{Mode: signing.SignMode_SIGN_MODE_DIRECT}},
+			},
+			Sequence: seq,
+		}},
+		Fee: &txtypes.Fee{Amount: fee, GasLimit: gasLimit},
+	}).Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTx, err)
+	}
+	return bz, nil
+}
+
+// receiptOf decodes the responses of a transaction's orderbook messages
+func receiptOf(inclusion *Inclusion) *Receipt {
+	receipt := &Receipt{Inclusion: *inclusion}
+	for _, resp := range inclusion.msgResponses {
+		switch resp.TypeUrl {
+		case "/" + gogoproto.MessageName(&orderbooktypes.MsgPlaceOrderResponse{}):
+			var placed orderbooktypes.MsgPlaceOrderResponse
+			if err := gogoproto.Unmarshal(resp.Value, &placed); err == nil {
+				receipt.OrderID = placed.OrderId
+				receipt.FilledQty = placed.FilledQty
+				receipt.AvgPrice = placed.AvgPrice
+			}
+		case "/" + gogoproto.MessageName(&orderbooktypes.MsgCancelOrderResponse{}):
+			var cancelled orderbooktypes.MsgCancelOrderResponse
+			if err := gogoproto.Unmarshal(resp.Value, &cancelled); err == nil {
+				receipt.CancelledQty = cancelled.CancelledQty
+			}
+		}
+	}
+	return receipt
+}
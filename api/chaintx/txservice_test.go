@@ -0,0 +1,213 @@
+package chaintx
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	abci "github.com/cometbft/cometbft/abci/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	gogoproto "github.com/cosmos/gogoproto/proto"
+	"google.golang.org/grpc"
+
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// fakeAuthQuery serves a single account
+type fakeAuthQuery struct {
+	authtypes.QueryClient
+	account *authtypes.BaseAccount
+}
+
+func (f *fakeAuthQuery) AccountInfo(ctx context.Context, req *authtypes.QueryAccountInfoRequest, opts ...grpc.CallOption) (*authtypes.QueryAccountInfoResponse, error) {
+	return &authtypes.QueryAccountInfoResponse{Info: f.account}, nil
+}
+
+// includedOrder is the block result of a placed order
+func includedOrder(t *testing.T, hash string) *sdk.TxResponse {
+	t.Helper()
+	resp, err := codectypes.NewAnyWithValue(&orderbooktypes.MsgPlaceOrderResponse{OrderId: "order-1", FilledQty: "2", AvgPrice: "50000"})
+	if err != nil {
+		t.Fatalf("failed to pack response: %v", err)
+	}
+	data, err := (&sdk.TxMsgData{MsgResponses: []*codectypes.Any{resp}}).Marshal()
+	if err != nil {
+		t.Fatalf("failed to encode msg data: %v", err)
+	}
+	return &sdk.TxResponse{
+		TxHash: hash,
+		Height: 42,
+		Data:   strings.ToUpper(hex.EncodeToString(data)),
+		Events: []abci.Event{{Type: "order_placed", Attributes: []abci.EventAttribute{{Key: "order_id", Value: "order-1"}}}},
+	}
+}
+
+// TestTxServiceExecute tests that the hot key signs for its own address with
+// the simulated gas limit and that the order result is read from the block
+func TestTxServiceExecute(t *testing.T) {
+	ctx := context.Background()
+	hotKey := secp256k1.GenPrivKey()
+	hotAddr := sdk.AccAddress(hotKey.PubKey().Address()).String()
+
+	svc := &fakeTxService{
+		gasUsed:   80000,
+		broadcast: &sdk.TxResponse{TxHash: "ABC"},
+		included:  map[string]*sdk.TxResponse{"ABC": includedOrder(t, "ABC")},
+	}
+	auth := &fakeAuthQuery{account: &authtypes.BaseAccount{Address: hotAddr, AccountNumber: 7, Sequence: 3}}
+	txs := NewTxService(newClient(&Config{GasAdjustment: 1.5}, svc, auth), TxServiceConfig{
+		ChainID:  "perpdex-1",
+		GasPrice: sdk.NewDecCoinFromDec("usdc", sdkmath.LegacyMustNewDecFromStr("0.001")),
+		HotKey:   hotKey,
+	})
+
+	msg := &orderbooktypes.MsgPlaceOrder{Trader: hotAddr, MarketId: "BTC-USDC", Side: orderbooktypes.SideBuy, OrderType: orderbooktypes.OrderTypeLimit, Price: "50000", Quantity: "2"}
+	receipt, err := txs.Execute(ctx, msg)
+	if err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+	if receipt.OrderID != "order-1" || receipt.FilledQty != "2" || receipt.Height != 42 {
+		t.Errorf("expected the placed order from the block, got %+v", receipt)
+	}
+	if len(receipt.Events) != 1 || receipt.Events[0].Attributes["order_id"] != "order-1" {
+		t.Errorf("expected the order event, got %+v", receipt.Events)
+	}
+
+	var raw txtypes.TxRaw
+	if err := raw.Unmarshal(svc.lastTx); err != nil {
+		t.Fatalf("failed to decode broadcast tx: %v", err)
+	}
+	var authInfo txtypes.AuthInfo
+	if err := authInfo.Unmarshal(raw.AuthInfoBytes); err != nil {
+		t.Fatalf("failed to decode auth info: %v", err)
+	}
+	if authInfo.Fee.GasLimit != 120000 || authInfo.Fee.Amount.String() != "120usdc" || authInfo.SignerInfos[0].Sequence != 3 {
+		t.Errorf("expected 120000 gas for 120usdc at sequence 3, got %+v", authInfo)
+	}
+	signDoc, _ := (&txtypes.SignDoc{BodyBytes: raw.BodyBytes, AuthInfoBytes: raw.AuthInfoBytes, ChainId: "perpdex-1", AccountNumber: 7}).Marshal()
+	if !hotKey.PubKey().VerifySignature(signDoc, raw.Signatures[0]) {
+		t.Error("expected the hot key's signature over the sign doc")
+	}
+
+	// The next transaction uses the cached sequence
+	if _, err := txs.Execute(ctx, msg); err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+	var next txtypes.TxRaw
+	if err := next.Unmarshal(svc.lastTx); err != nil {
+		t.Fatalf("failed to decode broadcast tx: %v", err)
+	}
+	var nextAuthInfo txtypes.AuthInfo
+	if err := nextAuthInfo.Unmarshal(next.AuthInfoBytes); err != nil || nextAuthInfo.SignerInfos[0].Sequence != 4 {
+		t.Errorf("expected sequence 4, got %+v", nextAuthInfo.SignerInfos)
+	}
+
+	other := secp256k1.GenPrivKey()
+	msg.Trader = sdk.AccAddress(other.PubKey().Address()).String()
+	if _, err := txs.Execute(ctx, msg); !errors.Is(err, ErrSignerMismatch) {
+		t.Errorf("expected the hot key to sign only for itself, got %v", err)
+	}
+
+	svc.included = nil
+	txs.client.config.InclusionTimeout = 10 * time.Millisecond
+	txs.client.config.PollInterval = time.Millisecond
+	msg.Trader = hotAddr
+	var pending *PendingError
+	if _, err := txs.Execute(ctx, msg); !errors.As(err, &pending) || pending.TxHash != "ABC" {
+		t.Errorf("expected an uncommitted transaction to be pending, got %v", err)
+	}
+}
+
+// TestTxServicePrepareSubmit tests that a trader signs the prepared sign doc
+// and that failed transactions unwrap to their module error
+func TestTxServicePrepareSubmit(t *testing.T) {
+	ctx := context.Background()
+	key := secp256k1.GenPrivKey()
+	trader := sdk.AccAddress(key.PubKey().Address()).String()
+
+	svc := &fakeTxService{gasUsed: 50000, broadcast: &sdk.TxResponse{TxHash: "DEF"}, included: map[string]*sdk.TxResponse{}}
+	auth := &fakeAuthQuery{account: &authtypes.BaseAccount{Address: trader, AccountNumber: 9, Sequence: 1}}
+	txs := NewTxService(newClient(&Config{}, svc, auth), TxServiceConfig{ChainID: "perpdex-1"})
+
+	msg := &orderbooktypes.MsgCancelOrder{Trader: trader, OrderId: "order-1"}
+	if _, err := txs.Execute(ctx, msg); !errors.Is(err, ErrNoHotKey) {
+		t.Errorf("expected execution without a hot key to fail, got %v", err)
+	}
+	if _, err := txs.Prepare(ctx, msg, secp256k1.GenPrivKey().PubKey()); !errors.Is(err, ErrSignerMismatch) {
+		t.Errorf("expected another trader's key to be rejected, got %v", err)
+	}
+
+	prepared, err := txs.Prepare(ctx, msg, key.PubKey())
+	if err != nil {
+		t.Fatalf("failed to prepare: %v", err)
+	}
+	if prepared.AccountNumber != 9 || prepared.Sequence != 1 || prepared.GasLimit != 65000 || prepared.Fee != "" {
+		t.Errorf("expected a fee-less transaction with 65000 gas, got %+v", prepared)
+	}
+
+	sig, err := key.Sign(prepared.SignDoc)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	svc.included["DEF"] = &sdk.TxResponse{TxHash: "DEF", Height: 43, Codespace: "orderbook",
+		Code: orderbooktypes.ErrOrderNotFound.ABCICode(), RawLog: "order not found"}
+	var failed *FailedError
+	_, err = txs.Submit(ctx, prepared.BodyBytes, prepared.AuthInfoBytes, sig)
+	if !errors.As(err, &failed) || failed.Height != 43 || !errors.Is(err, orderbooktypes.ErrOrderNotFound) {
+		t.Errorf("expected the failed cancel to unwrap to its module error, got %v", err)
+	}
+
+	if _, err := txs.Lookup(ctx, "unknown"); !errors.Is(err, ErrTxNotFound) {
+		t.Errorf("expected an unknown hash not to be found, got %v", err)
+	}
+}
+
+// TestTxServiceBroadcastCommit tests that the hot key signs the settlement
+// operator's batch commitment and that a failed commit unwraps to its module
+// error
+func TestTxServiceBroadcastCommit(t *testing.T) {
+	ctx := context.Background()
+	hotKey := secp256k1.GenPrivKey()
+	operator := sdk.AccAddress(hotKey.PubKey().Address()).String()
+
+	svc := &fakeTxService{gasUsed: 40000, broadcast: &sdk.TxResponse{TxHash: "C0"}, included: map[string]*sdk.TxResponse{"C0": {TxHash: "C0", Height: 44}}}
+	auth := &fakeAuthQuery{account: &authtypes.BaseAccount{Address: operator, AccountNumber: 2, Sequence: 0}}
+	txs := NewTxService(newClient(&Config{}, svc, auth), TxServiceConfig{ChainID: "perpdex-1", HotKey: hotKey})
+
+	msg := &orderbooktypes.MsgCommitTradeBatch{Submitter: operator, BatchId: 1, MerkleRoot: strings.Repeat("ab", 32), TradeCount: 3}
+	if err := txs.BroadcastCommit(ctx, msg); err != nil {
+		t.Fatalf("failed to broadcast commit: %v", err)
+	}
+	var raw txtypes.TxRaw
+	if err := raw.Unmarshal(svc.lastTx); err != nil {
+		t.Fatalf("failed to decode broadcast tx: %v", err)
+	}
+	var body txtypes.TxBody
+	if err := body.Unmarshal(raw.BodyBytes); err != nil || len(body.Messages) != 1 {
+		t.Fatalf("failed to decode tx body: %v", err)
+	}
+	var sent orderbooktypes.MsgCommitTradeBatch
+	if err := gogoproto.Unmarshal(body.Messages[0].Value, &sent); err != nil || sent.BatchId != 1 || sent.MerkleRoot != msg.MerkleRoot {
+		t.Errorf("expected batch 1 in the transaction, got %+v, %v", sent, err)
+	}
+
+	svc.broadcast = &sdk.TxResponse{TxHash: "C1"}
+	svc.included["C1"] = &sdk.TxResponse{TxHash: "C1", Height: 45, Codespace: "orderbook",
+		Code: orderbooktypes.ErrInvalidBatchSequence.ABCICode(), RawLog: "settlement batch ID out of sequence"}
+	if err := txs.BroadcastCommit(ctx, msg); !errors.Is(err, orderbooktypes.ErrInvalidBatchSequence) {
+		t.Errorf("expected a replayed batch to fail, got %v", err)
+	}
+
+	msg.Submitter = sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address()).String()
+	if err := txs.BroadcastCommit(ctx, msg); !errors.Is(err, ErrSignerMismatch) {
+		t.Errorf("expected the hot key to sign only for itself, got %v", err)
+	}
+}
@@ -0,0 +1,320 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// ClientConfig contains matcher client configuration
+type ClientConfig struct {
+	MatcherAddr  string        // host:port of the matcher node
+	Token        string        // Shared token the matcher requires on every call
+	Timeout      time.Duration // Per-call timeout
+	BookCacheTTL time.Duration // How long order book snapshots are reused; 0 disables caching
+}
+
+// DefaultClientConfig returns default matcher client configuration
+func DefaultClientConfig() *ClientConfig {
+	return &ClientConfig{
+		MatcherAddr:  "localhost:9095",
+		Timeout:      5 * time.Second,
+		BookCacheTTL: 100 * time.Millisecond,
+	}
+}
+
+// MatcherClient implements the API service interfaces by forwarding every call to
+// the shared matcher. It holds no trading state, so any number of API nodes
+// can run behind a load balancer and observe the same books and accounts.
+type MatcherClient struct {
+	config *ClientConfig
+	conn   *grpc.ClientConn
+
+	// Short-lived order book cache to absorb polling bursts
+	bookCache map[string]*cachedBook
+	bookMu    sync.Mutex
+}
+
+type cachedBook struct {
+	snapshot  *types.OrderBookSnapshot
+	fetchedAt time.Time
+}
+
+// Compile-time interface checks
+var (
+	_ types.OrderService      = (*MatcherClient)(nil)
+	_ types.PositionService   = (*MatcherClient)(nil)
+	_ types.AccountService    = (*MatcherClient)(nil)
+	_ types.MarketDataService = (*MatcherClient)(nil)
+)
+
+// NewMatcherClient connects to the matcher node
+func NewMatcherClient(config *ClientConfig) (*MatcherClient, error) {
+	if config == nil {
+		config = DefaultClientConfig()
+	}
+	if config.Token == "" {
+		return nil, ErrTokenRequired
+	}
+
+	conn, err := grpc.Dial(
+		config.MatcherAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(tokenCredentials(config.Token)),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(codecName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to matcher at %s: %w", config.MatcherAddr, err)
+	}
+
+	return &MatcherClient{
+		config:    config,
+		conn:      conn,
+		bookCache: make(map[string]*cachedBook),
+	}, nil
+}
+
+// Close closes the connection to the matcher
+func (c *MatcherClient) Close() error {
+	return c.conn.Close()
+}
+
+// invoke calls a matcher method and converts gRPC statuses back into API errors
+func (c *MatcherClient) invoke(ctx context.Context, method string, req, resp interface{}) error {
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
+
+	if err := c.conn.Invoke(ctx, "/"+ServiceName+"/"+method, req, resp); err != nil {
+		return fromStatusError(err)
+	}
+	return nil
+}
+
+// fromStatusError rebuilds an API error from a matcher status.
+// Transport failures are reported as service_unavailable.
+func fromStatusError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return types.NewAPIError(types.ErrCodeServiceUnavailable, "matcher unavailable: "+err.Error())
+	}
+
+	if code, message, found := strings.Cut(st.Message(), ": "); found && !strings.Contains(code, " ") {
+		return types.NewAPIError(types.ErrorCode(code), message)
+	}
+
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+		return types.NewAPIError(types.ErrCodeServiceUnavailable, "matcher unavailable: "+st.Message())
+	default:
+		return types.NewAPIError(types.ErrCodeInternal, st.Message())
+	}
+}
+
+// Ping checks that the matcher is reachable and serving, for readiness probes
+func (c *MatcherClient) Ping(ctx context.Context) error {
+	return c.invoke(ctx, "Ping", &PingRequest{}, new(PingResponse))
+}
+
+// ============ OrderService Implementation ============
+
+func (c *MatcherClient) PlaceOrder(ctx context.Context, req *types.PlaceOrderRequest) (*types.PlaceOrderResponse, error) {
+	resp := new(types.PlaceOrderResponse)
+	if err := c.invoke(ctx, "PlaceOrder", req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *MatcherClient) CancelOrder(ctx context.Context, trader, orderID string) (*types.CancelOrderResponse, error) {
+	resp := new(types.CancelOrderResponse)
+	if err := c.invoke(ctx, "CancelOrder", &OrderRequest{Trader: trader, OrderID: orderID}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *MatcherClient) ModifyOrder(ctx context.Context, trader, orderID string, req *types.ModifyOrderRequest) (*types.ModifyOrderResponse, error) {
+	resp := new(types.ModifyOrderResponse)
+	if err := c.invoke(ctx, "ModifyOrder", &ModifyOrderRequest{Trader: trader, OrderID: orderID, Modify: req}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *MatcherClient) GetOrder(ctx context.Context, orderID string) (*types.Order, error) {
+	resp := new(types.Order)
+	if err := c.invoke(ctx, "GetOrder", &OrderRequest{OrderID: orderID}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *MatcherClient) ListOrders(ctx context.Context, req *types.ListOrdersRequest) (*types.ListOrdersResponse, error) {
+	resp := new(types.ListOrdersResponse)
+	if err := c.invoke(ctx, "ListOrders", req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ============ PositionService Implementation ============
+
+func (c *MatcherClient) GetPositions(ctx context.Context, trader string) ([]*types.Position, error) {
+	resp := new(PositionsResponse)
+	if err := c.invoke(ctx, "GetPositions", &TraderRequest{Trader: trader}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Positions, nil
+}
+
+func (c *MatcherClient) GetPosition(ctx context.Context, trader, marketID string) (*types.Position, error) {
+	resp := new(types.Position)
+	if err := c.invoke(ctx, "GetPosition", &TraderRequest{Trader: trader, MarketID: marketID}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *MatcherClient) ClosePosition(ctx context.Context, req *types.ClosePositionRequest) (*types.ClosePositionResponse, error) {
+	resp := new(types.ClosePositionResponse)
+	if err := c.invoke(ctx, "ClosePosition", req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *MatcherClient) GetPositionHistory(ctx context.Context, req *types.PositionHistoryRequest) (*types.PositionHistoryResponse, error) {
+	resp := new(types.PositionHistoryResponse)
+	if err := c.invoke(ctx, "GetPositionHistory", req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ============ AccountService Implementation ============
+
+func (c *MatcherClient) GetAccount(ctx context.Context, trader string) (*types.Account, error) {
+	resp := new(types.Account)
+	if err := c.invoke(ctx, "GetAccount", &TraderRequest{Trader: trader}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *MatcherClient) Deposit(ctx context.Context, req *types.DepositRequest) (*types.AccountResponse, error) {
+	resp := new(types.AccountResponse)
+	if err := c.invoke(ctx, "Deposit", req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *MatcherClient) Withdraw(ctx context.Context, req *types.WithdrawRequest) (*types.AccountResponse, error) {
+	resp := new(types.AccountResponse)
+	if err := c.invoke(ctx, "Withdraw", req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DebitCollateral takes collateral out of a trading account on the matcher
+func (c *MatcherClient) DebitCollateral(ctx context.Context, trader, amount string) (*types.AccountResponse, error) {
+	resp := new(types.AccountResponse)
+	if err := c.invoke(ctx, "DebitCollateral", &CollateralRequest{Trader: trader, Amount: amount}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ============ MarketDataService Implementation ============
+
+func (c *MatcherClient) GetOrderBook(ctx context.Context, marketID string, depth int) (*types.OrderBookSnapshot, error) {
+	key := fmt.Sprintf("%s/%d", marketID, depth)
+
+	if c.config.BookCacheTTL > 0 {
+		c.bookMu.Lock()
+		cached, ok := c.bookCache[key]
+		c.bookMu.Unlock()
+		if ok && time.Since(cached.fetchedAt) < c.config.BookCacheTTL {
+			return cached.snapshot, nil
+		}
+	}
+
+	resp := new(types.OrderBookSnapshot)
+	if err := c.invoke(ctx, "GetOrderBook", &MarketDataRequest{MarketID: marketID, Limit: depth}, resp); err != nil {
+		return nil, err
+	}
+
+	if c.config.BookCacheTTL > 0 {
+		c.bookMu.Lock()
+		c.bookCache[key] = &cachedBook{snapshot: resp, fetchedAt: time.Now()}
+		c.bookMu.Unlock()
+	}
+	return resp, nil
+}
+
+// GetOrderBooks reads several books from the matcher in one round trip.
+// The book cache is bypassed so the books share one read.
+func (c *MatcherClient) GetOrderBooks(ctx context.Context, marketIDs []string, depth int) (*types.OrderBooks, error) {
+	resp := new(types.OrderBooks)
+	if err := c.invoke(ctx, "GetOrderBooks", &OrderBooksRequest{MarketIDs: marketIDs, Depth: depth}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *MatcherClient) GetRecentTrades(ctx context.Context, marketID string, limit int) ([]*types.MarketTrade, error) {
+	resp := new(TradesResponse)
+	if err := c.invoke(ctx, "GetRecentTrades", &MarketDataRequest{MarketID: marketID, Limit: limit}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Trades, nil
+}
+
+func (c *MatcherClient) GetMarketAnalytics(ctx context.Context, marketID string) (*types.MarketAnalytics, error) {
+	resp := new(types.MarketAnalytics)
+	if err := c.invoke(ctx, "GetMarketAnalytics", &MarketDataRequest{MarketID: marketID}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *MatcherClient) GetTradingSchedule(ctx context.Context, marketID string) (*types.TradingSchedule, error) {
+	resp := new(types.TradingSchedule)
+	if err := c.invoke(ctx, "GetTradingSchedule", &MarketDataRequest{MarketID: marketID}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *MatcherClient) SetTradingSchedule(ctx context.Context, schedule *types.TradingSchedule) (*types.TradingSchedule, error) {
+	resp := new(types.TradingSchedule)
+	if err := c.invoke(ctx, "SetTradingSchedule", schedule, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *MatcherClient) DeleteTradingSchedule(ctx context.Context, marketID string) error {
+	return c.invoke(ctx, "DeleteTradingSchedule", &MarketDataRequest{MarketID: marketID}, new(types.TradingSchedule))
+}
+
+func (c *MatcherClient) GetTradingStatus(ctx context.Context, marketID string) (*types.TradingStatus, error) {
+	resp := new(types.TradingStatus)
+	if err := c.invoke(ctx, "GetTradingStatus", &MarketDataRequest{MarketID: marketID}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,34 @@
+// Package cluster lets stateless API nodes route order flow and market data reads
+// to a single shared matcher over gRPC.
+//
+// The matcher service is described by hand (no protobuf codegen) and uses a JSON
+// codec, so the existing api/types request and response structs travel unchanged.
+package cluster
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// codecName is the gRPC content subtype used by the matcher service
+const codecName = "json"
+
+// jsonCodec implements encoding.Codec using encoding/json
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
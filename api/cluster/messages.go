@@ -0,0 +1,97 @@
+package cluster
+
+import (
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// Request/response envelopes for RPCs whose service methods take several arguments.
+// Single-struct methods reuse the api/types messages directly.
+
+// OrderRequest identifies an order, optionally on behalf of a trader
+type OrderRequest struct {
+	Trader  string `json:"trader,omitempty"`
+	OrderID string `json:"order_id"`
+}
+
+// ModifyOrderRequest carries a modify call
+type ModifyOrderRequest struct {
+	Trader  string                    `json:"trader"`
+	OrderID string                    `json:"order_id"`
+	Modify  *types.ModifyOrderRequest `json:"modify"`
+}
+
+// TraderRequest identifies a trader and optional market
+type TraderRequest struct {
+	Trader   string `json:"trader"`
+	MarketID string `json:"market_id,omitempty"`
+}
+
+// CollateralRequest moves an amount of a trader's collateral
+type CollateralRequest struct {
+	Trader string `json:"trader"`
+	Amount string `json:"amount"`
+}
+
+// PositionsResponse wraps a position list
+type PositionsResponse struct {
+	Positions []*types.Position `json:"positions"`
+}
+
+// MarketDataRequest selects a market and result size
+type MarketDataRequest struct {
+	MarketID string `json:"market_id"`
+	Limit    int    `json:"limit"`
+}
+
+// OrderBooksRequest selects several markets' books
+type OrderBooksRequest struct {
+	MarketIDs []string `json:"market_ids"`
+	Depth     int      `json:"depth"`
+}
+
+// TradesResponse wraps a trade list
+type TradesResponse struct {
+	Trades []*types.MarketTrade `json:"trades"`
+}
+
+// PingRequest is an empty health check call
+type PingRequest struct{}
+
+// PingResponse carries the matcher's clock at the time of a ping
+type PingResponse struct {
+	Time int64 `json:"time"` // Unix milliseconds
+}
+
+// RiverpoolRequest carries the arguments of any riverpool call; each method
+// reads the fields its service method takes
+type RiverpoolRequest struct {
+	PoolID       string                     `json:"pool_id,omitempty"`
+	PoolType     string                     `json:"pool_type,omitempty"`
+	User         string                     `json:"user,omitempty"`
+	Owner        string                     `json:"owner,omitempty"`
+	WithdrawalID string                     `json:"withdrawal_id,omitempty"`
+	Code         string                     `json:"code,omitempty"`
+	MarketID     string                     `json:"market_id,omitempty"`
+	Side         string                     `json:"side,omitempty"`
+	PositionID   string                     `json:"position_id,omitempty"`
+	Days         int                        `json:"days,omitempty"`
+	Offset       int                        `json:"offset,omitempty"`
+	Limit        int                        `json:"limit,omitempty"`
+	Amount       math.LegacyDec             `json:"amount"`
+	Shares       math.LegacyDec             `json:"shares"`
+	Size         math.LegacyDec             `json:"size"`
+	Price        math.LegacyDec             `json:"price"`
+	Leverage     math.LegacyDec             `json:"leverage"`
+	Params       *types.CommunityPoolParams `json:"params,omitempty"`
+}
+
+// RiverpoolPage wraps one page of a paginated riverpool list
+type RiverpoolPage[T any] struct {
+	Items []*T `json:"items"`
+	Total int  `json:"total"`
+}
+
+// Empty is the response of calls that return nothing but an error
+type Empty struct{}
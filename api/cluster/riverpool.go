@@ -0,0 +1,420 @@
+package cluster
+
+import (
+	"context"
+
+	"cosmossdk.io/math"
+	"google.golang.org/grpc"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// WithRiverpool serves the riverpool service, so every stateless node reads
+// and moves the same pool state as the matcher
+func (s *MatcherServer) WithRiverpool(riverpool types.RiverpoolService) *MatcherServer {
+	s.riverpool = riverpool
+	return s
+}
+
+// riverpoolMethod builds a method descriptor for a riverpool call. Method
+// names are prefixed with "Riverpool" to keep them apart from the account
+// service's Deposit and Withdraw.
+func riverpoolMethod[Resp any](name string, call func(types.RiverpoolService, *RiverpoolRequest) (Resp, error)) grpc.MethodDesc {
+	return unaryMethod("Riverpool"+name, func(s *MatcherServer, _ context.Context, req *RiverpoolRequest) (Resp, error) {
+		if s.riverpool == nil {
+			var zero Resp
+			return zero, types.NewAPIError(types.ErrCodeNotImplemented, "riverpool not served by this matcher")
+		}
+		return call(s.riverpool, req)
+	})
+}
+
+// riverpoolPage adapts a paginated list call to a single response
+func riverpoolPage[T any](items []*T, total int, err error) (*RiverpoolPage[T], error) {
+	if err != nil {
+		return nil, err
+	}
+	return &RiverpoolPage[T]{Items: items, Total: total}, nil
+}
+
+// riverpoolEmpty adapts a call returning only an error
+func riverpoolEmpty(err error) (*Empty, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+var riverpoolMethods = []grpc.MethodDesc{
+	riverpoolMethod("GetPools", func(rp types.RiverpoolService, _ *RiverpoolRequest) ([]*types.PoolInfo, error) {
+		return rp.GetPools()
+	}),
+	riverpoolMethod("GetPool", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.PoolInfo, error) {
+		return rp.GetPool(req.PoolID)
+	}),
+	riverpoolMethod("GetPoolsByType", func(rp types.RiverpoolService, req *RiverpoolRequest) ([]*types.PoolInfo, error) {
+		return rp.GetPoolsByType(req.PoolType)
+	}),
+	riverpoolMethod("GetPoolStats", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.PoolStats, error) {
+		return rp.GetPoolStats(req.PoolID)
+	}),
+	riverpoolMethod("GetNAVHistory", func(rp types.RiverpoolService, req *RiverpoolRequest) ([]*types.NAVPoint, error) {
+		return rp.GetNAVHistory(req.PoolID, req.Days)
+	}),
+	riverpoolMethod("GetDDGuardState", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.DDGuardState, error) {
+		return rp.GetDDGuardState(req.PoolID)
+	}),
+	riverpoolMethod("GetUserDeposits", func(rp types.RiverpoolService, req *RiverpoolRequest) ([]*types.DepositInfo, error) {
+		return rp.GetUserDeposits(req.User)
+	}),
+	riverpoolMethod("GetUserWithdrawals", func(rp types.RiverpoolService, req *RiverpoolRequest) ([]*types.WithdrawalInfo, error) {
+		return rp.GetUserWithdrawals(req.User)
+	}),
+	riverpoolMethod("GetUserPoolBalance", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.UserBalance, error) {
+		return rp.GetUserPoolBalance(req.PoolID, req.User)
+	}),
+	riverpoolMethod("GetUserOwnedPools", func(rp types.RiverpoolService, req *RiverpoolRequest) ([]*types.PoolInfo, error) {
+		return rp.GetUserOwnedPools(req.User)
+	}),
+	riverpoolMethod("GetPoolDeposits", func(rp types.RiverpoolService, req *RiverpoolRequest) (*RiverpoolPage[types.DepositInfo], error) {
+		return riverpoolPage(rp.GetPoolDeposits(req.PoolID, req.Offset, req.Limit))
+	}),
+	riverpoolMethod("GetPoolWithdrawals", func(rp types.RiverpoolService, req *RiverpoolRequest) (*RiverpoolPage[types.WithdrawalInfo], error) {
+		return riverpoolPage(rp.GetPoolWithdrawals(req.PoolID, req.Offset, req.Limit))
+	}),
+	riverpoolMethod("GetPendingWithdrawals", func(rp types.RiverpoolService, req *RiverpoolRequest) ([]*types.WithdrawalInfo, error) {
+		return rp.GetPendingWithdrawals(req.PoolID)
+	}),
+	riverpoolMethod("EstimateDeposit", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.DepositEstimate, error) {
+		return rp.EstimateDeposit(req.PoolID, req.Amount)
+	}),
+	riverpoolMethod("EstimateWithdrawal", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.WithdrawalEstimate, error) {
+		return rp.EstimateWithdrawal(req.PoolID, req.Shares)
+	}),
+	riverpoolMethod("Deposit", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.DepositResult, error) {
+		return rp.Deposit(req.PoolID, req.User, req.Amount, req.Code)
+	}),
+	riverpoolMethod("RequestWithdrawal", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.WithdrawalResult, error) {
+		return rp.RequestWithdrawal(req.PoolID, req.User, req.Shares)
+	}),
+	riverpoolMethod("ClaimWithdrawal", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.ClaimResult, error) {
+		return rp.ClaimWithdrawal(req.WithdrawalID, req.User)
+	}),
+	riverpoolMethod("CancelWithdrawal", func(rp types.RiverpoolService, req *RiverpoolRequest) (*Empty, error) {
+		return riverpoolEmpty(rp.CancelWithdrawal(req.WithdrawalID, req.User))
+	}),
+	riverpoolMethod("GetPoolRevenue", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.RevenueStats, error) {
+		return rp.GetPoolRevenue(req.PoolID)
+	}),
+	riverpoolMethod("GetRevenueRecords", func(rp types.RiverpoolService, req *RiverpoolRequest) ([]*types.RevenueRecord, error) {
+		return rp.GetRevenueRecords(req.PoolID, req.Limit)
+	}),
+	riverpoolMethod("GetRevenueBreakdown", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.RevenueBreakdown, error) {
+		return rp.GetRevenueBreakdown(req.PoolID)
+	}),
+	riverpoolMethod("CreateCommunityPool", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.PoolInfo, error) {
+		return rp.CreateCommunityPool(req.Owner, req.Params)
+	}),
+	riverpoolMethod("UpdateCommunityPool", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.PoolInfo, error) {
+		return rp.UpdateCommunityPool(req.PoolID, req.Owner, req.Params)
+	}),
+	riverpoolMethod("GetPoolHolders", func(rp types.RiverpoolService, req *RiverpoolRequest) ([]*types.HolderInfo, error) {
+		return rp.GetPoolHolders(req.PoolID)
+	}),
+	riverpoolMethod("GetPoolPositions", func(rp types.RiverpoolService, req *RiverpoolRequest) ([]*types.PositionInfo, error) {
+		return rp.GetPoolPositions(req.PoolID)
+	}),
+	riverpoolMethod("GetPoolTrades", func(rp types.RiverpoolService, req *RiverpoolRequest) ([]*types.PoolTradeInfo, error) {
+		return rp.GetPoolTrades(req.PoolID, req.Limit)
+	}),
+	riverpoolMethod("GetInviteCodes", func(rp types.RiverpoolService, req *RiverpoolRequest) ([]*types.InviteCode, error) {
+		return rp.GetInviteCodes(req.PoolID, req.Owner)
+	}),
+	riverpoolMethod("GenerateInviteCode", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.InviteCode, error) {
+		return rp.GenerateInviteCode(req.PoolID, req.Owner)
+	}),
+	riverpoolMethod("RevokeInviteCode", func(rp types.RiverpoolService, req *RiverpoolRequest) (*Empty, error) {
+		return riverpoolEmpty(rp.RevokeInviteCode(req.PoolID, req.Owner, req.Code))
+	}),
+	riverpoolMethod("GetInviteRedemptions", func(rp types.RiverpoolService, req *RiverpoolRequest) ([]*types.InviteRedemption, error) {
+		return rp.GetInviteRedemptions(req.PoolID, req.Owner, req.Code)
+	}),
+	riverpoolMethod("PlacePoolOrder", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.PoolOrderResult, error) {
+		return rp.PlacePoolOrder(req.PoolID, req.Owner, req.MarketID, req.Side, req.Size, req.Price, req.Leverage)
+	}),
+	riverpoolMethod("ClosePoolPosition", func(rp types.RiverpoolService, req *RiverpoolRequest) (*types.PoolCloseResult, error) {
+		return rp.ClosePoolPosition(req.PoolID, req.Owner, req.PositionID)
+	}),
+	riverpoolMethod("PausePool", func(rp types.RiverpoolService, req *RiverpoolRequest) (*Empty, error) {
+		return riverpoolEmpty(rp.PausePool(req.PoolID, req.Owner))
+	}),
+	riverpoolMethod("ResumePool", func(rp types.RiverpoolService, req *RiverpoolRequest) (*Empty, error) {
+		return riverpoolEmpty(rp.ResumePool(req.PoolID, req.Owner))
+	}),
+	riverpoolMethod("ClosePool", func(rp types.RiverpoolService, req *RiverpoolRequest) (*Empty, error) {
+		return riverpoolEmpty(rp.ClosePool(req.PoolID, req.Owner))
+	}),
+}
+
+// RiverpoolClient implements the riverpool service by forwarding every call
+// to the matcher. It is a separate type from MatcherClient because both
+// services define Deposit.
+type RiverpoolClient struct {
+	client *MatcherClient
+}
+
+var _ types.RiverpoolService = (*RiverpoolClient)(nil)
+
+// Riverpool returns the riverpool service served by the matcher
+func (c *MatcherClient) Riverpool() *RiverpoolClient {
+	return &RiverpoolClient{client: c}
+}
+
+// call invokes a riverpool method. The riverpool service takes no context,
+// so calls are bounded by the client's timeout alone.
+func (c *RiverpoolClient) call(method string, req *RiverpoolRequest, resp interface{}) error {
+	return c.client.invoke(context.Background(), "Riverpool"+method, req, resp)
+}
+
+func (c *RiverpoolClient) GetPools() ([]*types.PoolInfo, error) {
+	var resp []*types.PoolInfo
+	err := c.call("GetPools", &RiverpoolRequest{}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) GetPool(poolID string) (*types.PoolInfo, error) {
+	resp := new(types.PoolInfo)
+	if err := c.call("GetPool", &RiverpoolRequest{PoolID: poolID}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) GetPoolsByType(poolType string) ([]*types.PoolInfo, error) {
+	var resp []*types.PoolInfo
+	err := c.call("GetPoolsByType", &RiverpoolRequest{PoolType: poolType}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) GetPoolStats(poolID string) (*types.PoolStats, error) {
+	resp := new(types.PoolStats)
+	if err := c.call("GetPoolStats", &RiverpoolRequest{PoolID: poolID}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) GetNAVHistory(poolID string, days int) ([]*types.NAVPoint, error) {
+	var resp []*types.NAVPoint
+	err := c.call("GetNAVHistory", &RiverpoolRequest{PoolID: poolID, Days: days}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) GetDDGuardState(poolID string) (*types.DDGuardState, error) {
+	resp := new(types.DDGuardState)
+	if err := c.call("GetDDGuardState", &RiverpoolRequest{PoolID: poolID}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) GetUserDeposits(user string) ([]*types.DepositInfo, error) {
+	var resp []*types.DepositInfo
+	err := c.call("GetUserDeposits", &RiverpoolRequest{User: user}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) GetUserWithdrawals(user string) ([]*types.WithdrawalInfo, error) {
+	var resp []*types.WithdrawalInfo
+	err := c.call("GetUserWithdrawals", &RiverpoolRequest{User: user}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) GetUserPoolBalance(poolID, user string) (*types.UserBalance, error) {
+	resp := new(types.UserBalance)
+	if err := c.call("GetUserPoolBalance", &RiverpoolRequest{PoolID: poolID, User: user}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) GetUserOwnedPools(user string) ([]*types.PoolInfo, error) {
+	var resp []*types.PoolInfo
+	err := c.call("GetUserOwnedPools", &RiverpoolRequest{User: user}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) GetPoolDeposits(poolID string, offset, limit int) ([]*types.DepositInfo, int, error) {
+	resp := new(RiverpoolPage[types.DepositInfo])
+	if err := c.call("GetPoolDeposits", &RiverpoolRequest{PoolID: poolID, Offset: offset, Limit: limit}, resp); err != nil {
+		return nil, 0, err
+	}
+	return resp.Items, resp.Total, nil
+}
+
+func (c *RiverpoolClient) GetPoolWithdrawals(poolID string, offset, limit int) ([]*types.WithdrawalInfo, int, error) {
+	resp := new(RiverpoolPage[types.WithdrawalInfo])
+	if err := c.call("GetPoolWithdrawals", &RiverpoolRequest{PoolID: poolID, Offset: offset, Limit: limit}, resp); err != nil {
+		return nil, 0, err
+	}
+	return resp.Items, resp.Total, nil
+}
+
+func (c *RiverpoolClient) GetPendingWithdrawals(poolID string) ([]*types.WithdrawalInfo, error) {
+	var resp []*types.WithdrawalInfo
+	err := c.call("GetPendingWithdrawals", &RiverpoolRequest{PoolID: poolID}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) EstimateDeposit(poolID string, amount math.LegacyDec) (*types.DepositEstimate, error) {
+	resp := new(types.DepositEstimate)
+	if err := c.call("EstimateDeposit", &RiverpoolRequest{PoolID: poolID, Amount: amount}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) EstimateWithdrawal(poolID string, shares math.LegacyDec) (*types.WithdrawalEstimate, error) {
+	resp := new(types.WithdrawalEstimate)
+	if err := c.call("EstimateWithdrawal", &RiverpoolRequest{PoolID: poolID, Shares: shares}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) Deposit(poolID, user string, amount math.LegacyDec, inviteCode string) (*types.DepositResult, error) {
+	resp := new(types.DepositResult)
+	if err := c.call("Deposit", &RiverpoolRequest{PoolID: poolID, User: user, Amount: amount, Code: inviteCode}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) RequestWithdrawal(poolID, user string, shares math.LegacyDec) (*types.WithdrawalResult, error) {
+	resp := new(types.WithdrawalResult)
+	if err := c.call("RequestWithdrawal", &RiverpoolRequest{PoolID: poolID, User: user, Shares: shares}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) ClaimWithdrawal(withdrawalID, user string) (*types.ClaimResult, error) {
+	resp := new(types.ClaimResult)
+	if err := c.call("ClaimWithdrawal", &RiverpoolRequest{WithdrawalID: withdrawalID, User: user}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) CancelWithdrawal(withdrawalID, user string) error {
+	return c.call("CancelWithdrawal", &RiverpoolRequest{WithdrawalID: withdrawalID, User: user}, new(Empty))
+}
+
+func (c *RiverpoolClient) GetPoolRevenue(poolID string) (*types.RevenueStats, error) {
+	resp := new(types.RevenueStats)
+	if err := c.call("GetPoolRevenue", &RiverpoolRequest{PoolID: poolID}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) GetRevenueRecords(poolID string, limit int) ([]*types.RevenueRecord, error) {
+	var resp []*types.RevenueRecord
+	err := c.call("GetRevenueRecords", &RiverpoolRequest{PoolID: poolID, Limit: limit}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) GetRevenueBreakdown(poolID string) (*types.RevenueBreakdown, error) {
+	resp := new(types.RevenueBreakdown)
+	if err := c.call("GetRevenueBreakdown", &RiverpoolRequest{PoolID: poolID}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) CreateCommunityPool(owner string, params *types.CommunityPoolParams) (*types.PoolInfo, error) {
+	resp := new(types.PoolInfo)
+	if err := c.call("CreateCommunityPool", &RiverpoolRequest{Owner: owner, Params: params}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) UpdateCommunityPool(poolID, owner string, params *types.CommunityPoolParams) (*types.PoolInfo, error) {
+	resp := new(types.PoolInfo)
+	if err := c.call("UpdateCommunityPool", &RiverpoolRequest{PoolID: poolID, Owner: owner, Params: params}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) GetPoolHolders(poolID string) ([]*types.HolderInfo, error) {
+	var resp []*types.HolderInfo
+	err := c.call("GetPoolHolders", &RiverpoolRequest{PoolID: poolID}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) GetPoolPositions(poolID string) ([]*types.PositionInfo, error) {
+	var resp []*types.PositionInfo
+	err := c.call("GetPoolPositions", &RiverpoolRequest{PoolID: poolID}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) GetPoolTrades(poolID string, limit int) ([]*types.PoolTradeInfo, error) {
+	var resp []*types.PoolTradeInfo
+	err := c.call("GetPoolTrades", &RiverpoolRequest{PoolID: poolID, Limit: limit}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) GetInviteCodes(poolID, owner string) ([]*types.InviteCode, error) {
+	var resp []*types.InviteCode
+	err := c.call("GetInviteCodes", &RiverpoolRequest{PoolID: poolID, Owner: owner}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) GenerateInviteCode(poolID, owner string) (*types.InviteCode, error) {
+	resp := new(types.InviteCode)
+	if err := c.call("GenerateInviteCode", &RiverpoolRequest{PoolID: poolID, Owner: owner}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) RevokeInviteCode(poolID, owner, code string) error {
+	return c.call("RevokeInviteCode", &RiverpoolRequest{PoolID: poolID, Owner: owner, Code: code}, new(Empty))
+}
+
+func (c *RiverpoolClient) GetInviteRedemptions(poolID, owner, code string) ([]*types.InviteRedemption, error) {
+	var resp []*types.InviteRedemption
+	err := c.call("GetInviteRedemptions", &RiverpoolRequest{PoolID: poolID, Owner: owner, Code: code}, &resp)
+	return resp, err
+}
+
+func (c *RiverpoolClient) PlacePoolOrder(poolID, owner, marketID, side string, size, price, leverage math.LegacyDec) (*types.PoolOrderResult, error) {
+	resp := new(types.PoolOrderResult)
+	req := &RiverpoolRequest{PoolID: poolID, Owner: owner, MarketID: marketID, Side: side, Size: size, Price: price, Leverage: leverage}
+	if err := c.call("PlacePoolOrder", req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) ClosePoolPosition(poolID, owner, positionID string) (*types.PoolCloseResult, error) {
+	resp := new(types.PoolCloseResult)
+	if err := c.call("ClosePoolPosition", &RiverpoolRequest{PoolID: poolID, Owner: owner, PositionID: positionID}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *RiverpoolClient) PausePool(poolID, owner string) error {
+	return c.call("PausePool", &RiverpoolRequest{PoolID: poolID, Owner: owner}, new(Empty))
+}
+
+func (c *RiverpoolClient) ResumePool(poolID, owner string) error {
+	return c.call("ResumePool", &RiverpoolRequest{PoolID: poolID, Owner: owner}, new(Empty))
+}
+
+func (c *RiverpoolClient) ClosePool(poolID, owner string) error {
+	return c.call("ClosePool", &RiverpoolRequest{PoolID: poolID, Owner: owner}, new(Empty))
+}
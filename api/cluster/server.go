@@ -0,0 +1,236 @@
+package cluster
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// ServiceName is the fully-qualified gRPC service name of the matcher
+const ServiceName = "perpdex.matcher.v1.Matcher"
+
+// MatcherServer exposes the matcher node's services to stateless API nodes
+type MatcherServer struct {
+	orders     types.OrderService
+	positions  types.PositionService
+	accounts   types.AccountService
+	marketData types.MarketDataService
+	schedules  types.TradingScheduleService
+	riverpool  types.RiverpoolService
+}
+
+// NewMatcherServer creates a new matcher server
+func NewMatcherServer(orders types.OrderService, positions types.PositionService, accounts types.AccountService, marketData types.MarketDataService) *MatcherServer {
+	return &MatcherServer{
+		orders:     orders,
+		positions:  positions,
+		accounts:   accounts,
+		marketData: marketData,
+	}
+}
+
+// WithTradingSchedule serves market trading schedules, so stateless nodes
+// enforce the schedules managed on the matcher
+func (s *MatcherServer) WithTradingSchedule(schedules types.TradingScheduleService) *MatcherServer {
+	s.schedules = schedules
+	return s
+}
+
+// RegisterMatcherServer registers the matcher service on a gRPC server
+func RegisterMatcherServer(registrar grpc.ServiceRegistrar, srv *MatcherServer) {
+	registrar.RegisterService(&matcherServiceDesc, srv)
+}
+
+// NewGRPCServer creates a gRPC server with the matcher service registered,
+// serving only clients that present token
+func NewGRPCServer(srv *MatcherServer, token string, opts ...grpc.ServerOption) (*grpc.Server, error) {
+	if token == "" {
+		return nil, ErrTokenRequired
+	}
+	grpcServer := grpc.NewServer(append(opts, tokenAuth(token))...)
+	RegisterMatcherServer(grpcServer, srv)
+	return grpcServer, nil
+}
+
+var matcherServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: append([]grpc.MethodDesc{
+		unaryMethod("Ping", func(s *MatcherServer, ctx context.Context, req *PingRequest) (*PingResponse, error) {
+			return &PingResponse{Time: time.Now().UnixMilli()}, nil
+		}),
+		unaryMethod("PlaceOrder", func(s *MatcherServer, ctx context.Context, req *types.PlaceOrderRequest) (*types.PlaceOrderResponse, error) {
+			return s.orders.PlaceOrder(ctx, req)
+		}),
+		unaryMethod("CancelOrder", func(s *MatcherServer, ctx context.Context, req *OrderRequest) (*types.CancelOrderResponse, error) {
+			return s.orders.CancelOrder(ctx, req.Trader, req.OrderID)
+		}),
+		unaryMethod("ModifyOrder", func(s *MatcherServer, ctx context.Context, req *ModifyOrderRequest) (*types.ModifyOrderResponse, error) {
+			return s.orders.ModifyOrder(ctx, req.Trader, req.OrderID, req.Modify)
+		}),
+		unaryMethod("GetOrder", func(s *MatcherServer, ctx context.Context, req *OrderRequest) (*types.Order, error) {
+			return s.orders.GetOrder(ctx, req.OrderID)
+		}),
+		unaryMethod("ListOrders", func(s *MatcherServer, ctx context.Context, req *types.ListOrdersRequest) (*types.ListOrdersResponse, error) {
+			return s.orders.ListOrders(ctx, req)
+		}),
+		unaryMethod("GetPositions", func(s *MatcherServer, ctx context.Context, req *TraderRequest) (*PositionsResponse, error) {
+			positions, err := s.positions.GetPositions(ctx, req.Trader)
+			if err != nil {
+				return nil, err
+			}
+			return &PositionsResponse{Positions: positions}, nil
+		}),
+		unaryMethod("GetPosition", func(s *MatcherServer, ctx context.Context, req *TraderRequest) (*types.Position, error) {
+			return s.positions.GetPosition(ctx, req.Trader, req.MarketID)
+		}),
+		unaryMethod("ClosePosition", func(s *MatcherServer, ctx context.Context, req *types.ClosePositionRequest) (*types.ClosePositionResponse, error) {
+			return s.positions.ClosePosition(ctx, req)
+		}),
+		unaryMethod("GetPositionHistory", func(s *MatcherServer, ctx context.Context, req *types.PositionHistoryRequest) (*types.PositionHistoryResponse, error) {
+			return s.positions.GetPositionHistory(ctx, req)
+		}),
+		unaryMethod("GetAccount", func(s *MatcherServer, ctx context.Context, req *TraderRequest) (*types.Account, error) {
+			return s.accounts.GetAccount(ctx, req.Trader)
+		}),
+		unaryMethod("Deposit", func(s *MatcherServer, ctx context.Context, req *types.DepositRequest) (*types.AccountResponse, error) {
+			return s.accounts.Deposit(ctx, req)
+		}),
+		unaryMethod("Withdraw", func(s *MatcherServer, ctx context.Context, req *types.WithdrawRequest) (*types.AccountResponse, error) {
+			return s.accounts.Withdraw(ctx, req)
+		}),
+		unaryMethod("DebitCollateral", func(s *MatcherServer, ctx context.Context, req *CollateralRequest) (*types.AccountResponse, error) {
+			debits, ok := s.accounts.(types.CollateralDebitService)
+			if !ok {
+				return nil, types.NewAPIError(types.ErrCodeNotImplemented, "collateral debits not served by this matcher")
+			}
+			return debits.DebitCollateral(ctx, req.Trader, req.Amount)
+		}),
+		unaryMethod("GetOrderBook", func(s *MatcherServer, ctx context.Context, req *MarketDataRequest) (*types.OrderBookSnapshot, error) {
+			if s.marketData == nil {
+				return nil, types.NewAPIError(types.ErrCodeNotImplemented, "market data not served by this matcher")
+			}
+			return s.marketData.GetOrderBook(ctx, req.MarketID, req.Limit)
+		}),
+		unaryMethod("GetOrderBooks", func(s *MatcherServer, ctx context.Context, req *OrderBooksRequest) (*types.OrderBooks, error) {
+			if s.marketData == nil {
+				return nil, types.NewAPIError(types.ErrCodeNotImplemented, "market data not served by this matcher")
+			}
+			return types.GetOrderBooks(ctx, s.marketData, req.MarketIDs, req.Depth)
+		}),
+		unaryMethod("GetRecentTrades", func(s *MatcherServer, ctx context.Context, req *MarketDataRequest) (*TradesResponse, error) {
+			if s.marketData == nil {
+				return nil, types.NewAPIError(types.ErrCodeNotImplemented, "market data not served by this matcher")
+			}
+			trades, err := s.marketData.GetRecentTrades(ctx, req.MarketID, req.Limit)
+			if err != nil {
+				return nil, err
+			}
+			return &TradesResponse{Trades: trades}, nil
+		}),
+		unaryMethod("GetMarketAnalytics", func(s *MatcherServer, ctx context.Context, req *MarketDataRequest) (*types.MarketAnalytics, error) {
+			if s.marketData == nil {
+				return nil, types.NewAPIError(types.ErrCodeNotImplemented, "market data not served by this matcher")
+			}
+			return s.marketData.GetMarketAnalytics(ctx, req.MarketID)
+		}),
+		unaryMethod("GetTradingSchedule", func(s *MatcherServer, ctx context.Context, req *MarketDataRequest) (*types.TradingSchedule, error) {
+			if s.schedules == nil {
+				return nil, types.NewAPIError(types.ErrCodeNotImplemented, "trading schedules not served by this matcher")
+			}
+			return s.schedules.GetTradingSchedule(ctx, req.MarketID)
+		}),
+		unaryMethod("SetTradingSchedule", func(s *MatcherServer, ctx context.Context, req *types.TradingSchedule) (*types.TradingSchedule, error) {
+			if s.schedules == nil {
+				return nil, types.NewAPIError(types.ErrCodeNotImplemented, "trading schedules not served by this matcher")
+			}
+			return s.schedules.SetTradingSchedule(ctx, req)
+		}),
+		unaryMethod("DeleteTradingSchedule", func(s *MatcherServer, ctx context.Context, req *MarketDataRequest) (*types.TradingSchedule, error) {
+			if s.schedules == nil {
+				return nil, types.NewAPIError(types.ErrCodeNotImplemented, "trading schedules not served by this matcher")
+			}
+			if err := s.schedules.DeleteTradingSchedule(ctx, req.MarketID); err != nil {
+				return nil, err
+			}
+			return s.schedules.GetTradingSchedule(ctx, req.MarketID)
+		}),
+		unaryMethod("GetTradingStatus", func(s *MatcherServer, ctx context.Context, req *MarketDataRequest) (*types.TradingStatus, error) {
+			if s.schedules == nil {
+				return nil, types.NewAPIError(types.ErrCodeNotImplemented, "trading schedules not served by this matcher")
+			}
+			return s.schedules.GetTradingStatus(ctx, req.MarketID)
+		}),
+	}, riverpoolMethods...),
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "perpdex/matcher/v1/matcher",
+}
+
+// unaryMethod builds a gRPC method descriptor for a typed handler.
+// Service errors are returned as gRPC statuses whose message is "<api code>: <message>"
+// so the client can rebuild the API error envelope.
+func unaryMethod[Req any, Resp any](name string, call func(*MatcherServer, context.Context, *Req) (Resp, error)) grpc.MethodDesc {
+	invoke := func(srv interface{}, ctx context.Context, req interface{}) (interface{}, error) {
+		resp, err := call(srv.(*MatcherServer), ctx, req.(*Req))
+		if err != nil {
+			return nil, toStatusError(err)
+		}
+		return resp, nil
+	}
+
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := new(Req)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return invoke(srv, ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + ServiceName + "/" + name,
+			}
+			return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return invoke(srv, ctx, req)
+			})
+		},
+	}
+}
+
+// toStatusError converts a service error into a gRPC status error
+func toStatusError(err error) error {
+	apiErr := types.ToAPIError(err, types.ErrCodeInternal)
+	return status.Error(grpcCodeForHTTP(apiErr.Code.HTTPStatus()), apiErr.Error())
+}
+
+// grpcCodeForHTTP maps HTTP statuses from the API error registry to gRPC codes
+func grpcCodeForHTTP(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusConflict:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusInternalServerError:
+		return codes.Internal
+	default:
+		return codes.InvalidArgument
+	}
+}
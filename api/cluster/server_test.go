@@ -0,0 +1,222 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+const testToken = "test-matcher-token"
+
+// TestStatusErrorRoundTrip tests that API error codes survive the gRPC hop
+func TestStatusErrorRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		grpcCode codes.Code
+		expected types.ErrorCode
+	}{
+		{"api error", types.NewAPIError(types.ErrCodeOrderNotFound, "order not found: 1"), codes.NotFound, types.ErrCodeOrderNotFound},
+		{"classified service error", errors.New("insufficient margin: required 10"), codes.InvalidArgument, types.ErrCodeInsufficientMargin},
+		{"unknown error", errors.New("boom"), codes.Internal, types.ErrCodeInternal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			statusErr := toStatusError(tc.err)
+			if got := status.Code(statusErr); got != tc.grpcCode {
+				t.Errorf("expected gRPC code %s, got %s", tc.grpcCode, got)
+			}
+
+			var apiErr *types.APIError
+			if !errors.As(fromStatusError(statusErr), &apiErr) {
+				t.Fatalf("expected APIError")
+			}
+			if apiErr.Code != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, apiErr.Code)
+			}
+		})
+	}
+}
+
+// TestTransportErrorIsUnavailable tests that connection failures map to service_unavailable
+func TestTransportErrorIsUnavailable(t *testing.T) {
+	err := fromStatusError(status.Error(codes.Unavailable, "connection refused"))
+	if code := types.ErrorCodeOf(err, types.ErrCodeInternal); code != types.ErrCodeServiceUnavailable {
+		t.Errorf("expected %s, got %s", types.ErrCodeServiceUnavailable, code)
+	}
+}
+
+// TestPing tests that a client reaches the matcher over gRPC and that an
+// unreachable matcher fails the ping as service_unavailable
+func TestPing(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	grpcServer, err := NewGRPCServer(NewMatcherServer(nil, nil, nil, nil), testToken)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewMatcherClient(&ClientConfig{MatcherAddr: lis.Addr().String(), Token: testToken, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer client.Close()
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("expected ping to succeed, got %v", err)
+	}
+
+	grpcServer.Stop()
+	err = client.Ping(context.Background())
+	if code := types.ErrorCodeOf(err, types.ErrCodeInternal); code != types.ErrCodeServiceUnavailable {
+		t.Errorf("expected %s after the matcher stopped, got %v", types.ErrCodeServiceUnavailable, err)
+	}
+}
+
+// bookService serves one fixed level per market, one book at a time
+type bookService struct{}
+
+func (bookService) GetOrderBook(_ context.Context, marketID string, _ int) (*types.OrderBookSnapshot, error) {
+	return &types.OrderBookSnapshot{MarketID: marketID, Bids: [][]string{{"100", "1"}}, Asks: [][]string{}, Timestamp: 1}, nil
+}
+
+func (bookService) GetRecentTrades(context.Context, string, int) ([]*types.MarketTrade, error) {
+	return nil, nil
+}
+
+func (bookService) GetMarketAnalytics(context.Context, string) (*types.MarketAnalytics, error) {
+	return nil, nil
+}
+
+// TestGetOrderBooks tests that several books come back in one call, read
+// one by one on a matcher without batched reads and stamped with one time
+func TestGetOrderBooks(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	grpcServer, err := NewGRPCServer(NewMatcherServer(nil, nil, nil, bookService{}), testToken)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewMatcherClient(&ClientConfig{MatcherAddr: lis.Addr().String(), Token: testToken, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	books, err := client.GetOrderBooks(context.Background(), []string{"BTC-USDC", "ETH-USDC"}, 5)
+	if err != nil {
+		t.Fatalf("failed to get books: %v", err)
+	}
+	if books.Depth != 5 || len(books.Books) != 2 || books.Books[0].MarketID != "BTC-USDC" || books.Books[1].MarketID != "ETH-USDC" {
+		t.Fatalf("expected BTC-USDC and ETH-USDC, got %+v", books)
+	}
+	for _, book := range books.Books {
+		if book.Timestamp != books.Timestamp || len(book.Bids) != 1 {
+			t.Errorf("expected %s stamped %d, got %+v", book.MarketID, books.Timestamp, book)
+		}
+	}
+}
+
+// orderService accepts every order as resting
+type orderService struct {
+	types.OrderService
+}
+
+func (orderService) PlaceOrder(_ context.Context, req *types.PlaceOrderRequest) (*types.PlaceOrderResponse, error) {
+	return &types.PlaceOrderResponse{Order: &types.Order{OrderID: "1", Trader: req.Trader, MarketID: req.MarketID, Side: req.Side, Price: req.Price, Quantity: req.Quantity}}, nil
+}
+
+// riverpoolService knows one pool and its deposits
+type riverpoolService struct {
+	types.RiverpoolService
+}
+
+func (riverpoolService) Deposit(poolID, user string, amount math.LegacyDec, inviteCode string) (*types.DepositResult, error) {
+	if poolID != "pool-1" {
+		return nil, types.NewAPIError(types.ErrCodePoolNotFound, "pool not found: "+poolID)
+	}
+	return &types.DepositResult{DepositID: inviteCode, PoolID: poolID, User: user, Amount: amount.String()}, nil
+}
+
+func (riverpoolService) GetPoolDeposits(poolID string, offset, limit int) ([]*types.DepositInfo, int, error) {
+	return []*types.DepositInfo{{PoolID: poolID}}, offset + limit, nil
+}
+
+// TestClientServerCalls tests that order and riverpool calls reach the
+// matcher's services with their arguments and bring back their results and
+// errors, and that calls without the shared token are refused
+func TestClientServerCalls(t *testing.T) {
+	if _, err := NewGRPCServer(NewMatcherServer(nil, nil, nil, nil), ""); !errors.Is(err, ErrTokenRequired) {
+		t.Fatalf("expected ErrTokenRequired for a server, got %v", err)
+	}
+	if _, err := NewMatcherClient(&ClientConfig{MatcherAddr: "127.0.0.1:0"}); !errors.Is(err, ErrTokenRequired) {
+		t.Fatalf("expected ErrTokenRequired for a client, got %v", err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	grpcServer, err := NewGRPCServer(NewMatcherServer(orderService{}, nil, nil, nil).WithRiverpool(riverpoolService{}), testToken)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewMatcherClient(&ClientConfig{MatcherAddr: lis.Addr().String(), Token: testToken, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	placed, err := client.PlaceOrder(context.Background(), &types.PlaceOrderRequest{MarketID: "BTC-USDC", Side: "buy", Price: "50000", Quantity: "0.1", Trader: "alice"})
+	if err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+	if placed.Order.Trader != "alice" || placed.Order.Price != "50000" || placed.Order.Quantity != "0.1" {
+		t.Errorf("expected alice's order echoed back, got %+v", placed.Order)
+	}
+
+	riverpool := client.Riverpool()
+	deposit, err := riverpool.Deposit("pool-1", "alice", math.LegacyMustNewDecFromStr("12.5"), "INVITE")
+	if err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	if deposit.User != "alice" || deposit.Amount != "12.500000000000000000" || deposit.DepositID != "INVITE" {
+		t.Errorf("expected alice's 12.5 deposit with the invite code, got %+v", deposit)
+	}
+	if _, err := riverpool.Deposit("pool-2", "alice", math.LegacyOneDec(), ""); types.ErrorCodeOf(err, types.ErrCodeInternal) != types.ErrCodePoolNotFound {
+		t.Errorf("expected %s, got %v", types.ErrCodePoolNotFound, err)
+	}
+	deposits, total, err := riverpool.GetPoolDeposits("pool-1", 10, 5)
+	if err != nil || total != 15 || len(deposits) != 1 || deposits[0].PoolID != "pool-1" {
+		t.Errorf("expected one pool-1 deposit of 15, got %+v, %d, %v", deposits, total, err)
+	}
+
+	wrong, err := NewMatcherClient(&ClientConfig{MatcherAddr: lis.Addr().String(), Token: "wrong", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer wrong.Close()
+	if err := wrong.Ping(context.Background()); types.ErrorCodeOf(err, types.ErrCodeInternal) != types.ErrCodeUnauthorized {
+		t.Errorf("expected %s for a wrong token, got %v", types.ErrCodeUnauthorized, err)
+	}
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// adminTokenHeader carries the admin token for /v1/admin/* endpoints
+const adminTokenHeader = "X-Admin-Token"
+
+// defaultDrainTimeout is used when Config.DrainTimeout is unset
+const defaultDrainTimeout = 30 * time.Second
+
+// IsDraining returns whether the server is draining
+func (s *Server) IsDraining() bool {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+	return s.draining
+}
+
+// Done returns a channel that is closed once a drain has completed
+func (s *Server) Done() <-chan struct{} {
+	return s.drainDone
+}
+
+// Drain gracefully takes the server out of service for zero-downtime deploys:
+//  1. new orders are rejected and /health reports 503 so the load balancer stops routing here;
+//     everything else is served for DrainGracePeriod while it notices
+//  2. resting orders of cancel-on-disconnect sessions are cancelled (if CancelOnDrain is set)
+//  3. market data broadcasting stops, queued WebSocket messages are flushed and
+//     connections are closed with "going away"
+//  4. the HTTP server shuts down after in-flight requests complete
+//
+// Calling Drain more than once is a no-op.
+func (s *Server) Drain(ctx context.Context) error {
+	s.drainMu.Lock()
+	if s.draining {
+		s.drainMu.Unlock()
+		return nil
+	}
+	s.draining = true
+	s.drainMu.Unlock()
+	defer close(s.drainDone)
+
+	log.Printf("Draining API server...")
+
+	// Keep serving until the load balancer has seen /health fail
+	if grace := s.config.DrainGracePeriod; grace > 0 {
+		log.Printf("Drain: waiting %s for the load balancer to stop routing", grace)
+		select {
+		case <-time.After(grace):
+		case <-ctx.Done():
+		}
+	}
+
+	if s.config.CancelOnDrain {
+		s.cancelSessionOrders(ctx)
+	}
+
+	// Stop producing new market data, then flush what is already queued
+	close(s.stopCh)
+	if err := s.wsServer.GetHub().Drain(ctx); err != nil {
+		log.Printf("WebSocket drain incomplete: %v", err)
+	}
+
+	for name, ns := range s.namespaces {
+		if err := ns.Drain(ctx); err != nil {
+			log.Printf("Namespace %q drain incomplete: %v", name, err)
+		}
+	}
+
+	defer s.stopCluster()
+	s.stopPublicServer(ctx)
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Shutdown(ctx)
+}
+
+// cancelSessionOrders cancels resting orders of WebSocket sessions that
+// connected with cancel_on_disconnect=true
+func (s *Server) cancelSessionOrders(ctx context.Context) {
+	for _, trader := range s.wsServer.GetHub().CancelOnDisconnectUsers() {
+		cancelled := 0
+		req := &types.ListOrdersRequest{Trader: trader}
+		for {
+			resp, err := s.orderService.ListOrders(ctx, req)
+			if err != nil {
+				log.Printf("Drain: failed to list orders for %s: %v", trader, err)
+				break
+			}
+
+			for _, order := range resp.Orders {
+				if !isRestingStatus(order.Status) {
+					continue
+				}
+				if _, err := s.orderService.CancelOrder(ctx, trader, order.OrderID); err != nil {
+					log.Printf("Drain: failed to cancel order %s: %v", order.OrderID, err)
+					continue
+				}
+				cancelled++
+			}
+			if resp.NextCursor == "" {
+				break
+			}
+			req.Cursor = resp.NextCursor
+		}
+		log.Printf("Drain: cancelled %d resting orders for %s", cancelled, trader)
+	}
+}
+
+// isRestingStatus reports whether an order status means the order is still on the book.
+// Services report either "open"/"partially_filled" or the ORDER_STATUS_* enum names.
+func isRestingStatus(status string) bool {
+	status = strings.ToLower(status)
+	return strings.HasSuffix(status, "open") || strings.Contains(status, "partially")
+}
+
+// DrainTimeout returns the configured drain timeout
+func (s *Server) DrainTimeout() time.Duration {
+	if s.config.DrainTimeout > 0 {
+		return s.config.DrainTimeout
+	}
+	return defaultDrainTimeout
+}
+
+// handleDrain handles POST /v1/admin/drain
+func (s *Server) handleDrain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	if s.IsDraining() {
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{"status": "draining"})
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.DrainTimeout())
+		defer cancel()
+		if err := s.Drain(ctx); err != nil {
+			log.Printf("Drain error: %v", err)
+		}
+	}()
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"status":          "draining",
+		"timeout_seconds": int(s.DrainTimeout().Seconds()),
+		"cancel_orders":   s.config.CancelOnDrain,
+	})
+}
+
+// isAdminRequest checks the admin token, or restricts to loopback when no token is configured
+func (s *Server) isAdminRequest(r *http.Request) bool {
+	if s.config.AdminToken != "" {
+		token := r.Header.Get(adminTokenHeader)
+		return subtle.ConstantTimeCompare([]byte(token), []byte(s.config.AdminToken)) == 1
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
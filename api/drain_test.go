@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestDrainGracePeriod tests that a drain keeps serving with /health at 503
+// for the grace period before it closes anything, and that the drain timeout
+// cuts the grace period short
+func TestDrainGracePeriod(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	config.DrainGracePeriod = 300 * time.Millisecond
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	start := time.Now()
+	go s.Drain(context.Background())
+	for !s.IsDraining() {
+		time.Sleep(time.Millisecond)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected /health 503 while draining, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/markets", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected requests served in the grace period, got %d", rec.Code)
+	}
+	select {
+	case <-s.stopCh:
+		t.Fatal("expected broadcasting to continue in the grace period")
+	case <-s.Done():
+		t.Fatal("expected the drain to wait out the grace period")
+	default:
+	}
+
+	select {
+	case <-s.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("drain did not finish")
+	}
+	if elapsed := time.Since(start); elapsed < config.DrainGracePeriod {
+		t.Errorf("expected the drain to take at least %s, took %s", config.DrainGracePeriod, elapsed)
+	}
+
+	// A drain timeout shorter than the grace period ends the wait
+	config = DefaultConfig()
+	config.DrainGracePeriod = time.Minute
+	s, err = NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.Drain(ctx)
+	select {
+	case <-s.Done():
+	default:
+		t.Error("expected the drain to finish at its timeout")
+	}
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// Equity history defaults
+const (
+	DefaultEquitySnapshotInterval = time.Minute
+	DefaultEquityHistoryRetention = 1440 // one day of minute snapshots per trader
+	DefaultEquityHistoryLimit     = 500
+)
+
+// equityHistory keeps the most recent equity snapshots of every account,
+// captured by startEquitySnapshotter, for GET /v1/accounts/{trader}/equity-history
+type equityHistory struct {
+	interval  time.Duration
+	retention int
+	now       func() time.Time
+
+	mu        sync.RWMutex
+	snapshots map[string][]*types.EquitySnapshot // trader -> snapshots, oldest first
+}
+
+// newEquityHistory returns nil, disabling equity snapshots, when
+// Config.EquitySnapshotInterval is negative
+func newEquityHistory(config *Config) *equityHistory {
+	interval := config.EquitySnapshotInterval
+	if interval < 0 {
+		return nil
+	}
+	if interval == 0 {
+		interval = DefaultEquitySnapshotInterval
+	}
+	retention := config.EquityHistoryRetention
+	if retention <= 0 {
+		retention = DefaultEquityHistoryRetention
+	}
+	return &equityHistory{
+		interval:  interval,
+		retention: retention,
+		now:       time.Now,
+		snapshots: make(map[string][]*types.EquitySnapshot),
+	}
+}
+
+// newEquitySnapshot values an account summary: equity is the balance plus
+// the unrealized PnL of every open position
+func newEquitySnapshot(summary *types.AccountSummary, timestamp int64) (*types.EquitySnapshot, error) {
+	balance, err := math.LegacyNewDecFromStr(summary.Account.Balance)
+	if err != nil {
+		return nil, err
+	}
+	pnl := math.LegacyZeroDec()
+	for _, pos := range summary.Positions {
+		upnl, err := math.LegacyNewDecFromStr(pos.UnrealizedPnl)
+		if err != nil {
+			return nil, err
+		}
+		pnl = pnl.Add(upnl)
+	}
+	return &types.EquitySnapshot{
+		Timestamp:     timestamp,
+		Balance:       balance.String(),
+		Equity:        balance.Add(pnl).String(),
+		UnrealizedPnl: pnl.String(),
+		Positions:     len(summary.Positions),
+	}, nil
+}
+
+// record appends a snapshot, dropping the oldest beyond the retention
+func (h *equityHistory) record(trader string, snapshot *types.EquitySnapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list := h.snapshots[trader]
+	if len(list) >= h.retention {
+		list = list[len(list)-h.retention+1:]
+	}
+	h.snapshots[trader] = append(list, snapshot)
+}
+
+// query returns the latest limit snapshots taken within [from, to], oldest
+// first. Zero bounds are open.
+func (h *equityHistory) query(trader string, from, to int64, limit int) []*types.EquitySnapshot {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	list := h.snapshots[trader]
+	end := len(list)
+	for end > 0 && to > 0 && list[end-1].Timestamp > to {
+		end--
+	}
+	start := end
+	for start > 0 && end-start < limit && list[start-1].Timestamp >= from {
+		start--
+	}
+	result := make([]*types.EquitySnapshot, end-start)
+	copy(result, list[start:end])
+	return result
+}
+
+// equitySources returns the services the snapshotter reads accounts from
+func (s *Server) equitySources() (types.TraderLister, types.AccountBatchService, bool) {
+	lister, ok1 := s.accountService.(types.TraderLister)
+	batch, ok2 := s.accountService.(types.AccountBatchService)
+	return lister, batch, ok1 && ok2
+}
+
+// snapshotEquity records one snapshot of every account, querying them in
+// batches of types.MaxBatchQueryTraders
+func (s *Server) snapshotEquity(ctx context.Context, lister types.TraderLister, batch types.AccountBatchService) error {
+	traders, err := lister.ListTraders(ctx)
+	if err != nil {
+		return err
+	}
+	timestamp := s.equityHistory.now().UnixMilli()
+	for start := 0; start < len(traders); start += types.MaxBatchQueryTraders {
+		end := start + types.MaxBatchQueryTraders
+		if end > len(traders) {
+			end = len(traders)
+		}
+		summaries, err := batch.BatchQueryAccounts(ctx, traders[start:end])
+		if err != nil {
+			return err
+		}
+		for _, summary := range summaries {
+			snapshot, err := newEquitySnapshot(summary, timestamp)
+			if err != nil {
+				log.Printf("Equity snapshot skipped for %s: %v", summary.Trader, err)
+				continue
+			}
+			s.equityHistory.record(summary.Trader, snapshot)
+		}
+	}
+	return nil
+}
+
+// startEquitySnapshotter snapshots every account's equity each
+// Config.EquitySnapshotInterval until the server stops
+func (s *Server) startEquitySnapshotter() {
+	if s.equityHistory == nil {
+		return
+	}
+	lister, batch, ok := s.equitySources()
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(s.equityHistory.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+		if err := s.snapshotEquity(context.Background(), lister, batch); err != nil {
+			log.Printf("Equity snapshot failed: %v", err)
+		}
+	}
+}
+
+// handleEquityHistory handles GET /v1/accounts/{trader}/equity-history
+// Query params: from, to (Unix millis, inclusive), limit
+func (s *Server) handleEquityHistory(w http.ResponseWriter, r *http.Request, trader string) {
+	if _, _, ok := s.equitySources(); !ok || s.equityHistory == nil {
+		writeError(w, types.ErrCodeNotImplemented, "Equity history is not available on this server")
+		return
+	}
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+
+	query := r.URL.Query()
+	var from, to int64
+	for name, dst := range map[string]*int64{"from": &from, "to": &to} {
+		if v := query.Get(name); v != "" {
+			ms, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || ms < 0 {
+				writeError(w, types.ErrCodeInvalidRequest, name+" must be a non-negative Unix millisecond timestamp")
+				return
+			}
+			*dst = ms
+		}
+	}
+	limit := DefaultEquityHistoryLimit
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			writeError(w, types.ErrCodeInvalidRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	if limit > s.equityHistory.retention {
+		limit = s.equityHistory.retention
+	}
+
+	writeJSON(w, http.StatusOK, &types.EquityHistoryResponse{
+		Trader:    trader,
+		Interval:  s.equityHistory.interval.Milliseconds(),
+		Snapshots: s.equityHistory.query(trader, from, to, limit),
+	})
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestEquityHistory tests that snapshots value open positions, respect the
+// retention and are filtered by time range on the endpoint
+func TestEquityHistory(t *testing.T) {
+	svc := NewMockService()
+	ctx := context.Background()
+	if _, err := svc.Deposit(ctx, &types.DepositRequest{Trader: "alice", Amount: "1000"}); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	svc.positions["alice:BTC-USDC"] = &types.Position{MarketID: "BTC-USDC", Trader: "alice", Side: "long", UnrealizedPnl: "25.5"}
+
+	s := &Server{
+		accountService: svc,
+		equityHistory:  newEquityHistory(&Config{EquityHistoryRetention: 3}),
+	}
+	lister, batch, ok := s.equitySources()
+	if !ok {
+		t.Fatal("expected the mock service to support equity snapshots")
+	}
+
+	clock := time.UnixMilli(1_700_000_000_000)
+	s.equityHistory.now = func() time.Time { return clock }
+	for i := 0; i < 4; i++ {
+		if err := s.snapshotEquity(ctx, lister, batch); err != nil {
+			t.Fatalf("failed to snapshot: %v", err)
+		}
+		svc.positions["alice:BTC-USDC"].UnrealizedPnl = "-10"
+		clock = clock.Add(time.Minute)
+	}
+
+	get := func(query string) (*httptest.ResponseRecorder, *types.EquityHistoryResponse) {
+		rec := httptest.NewRecorder()
+		s.handleAccountLegacy(rec, httptest.NewRequest(http.MethodGet, "/v1/accounts/alice/equity-history"+query, nil))
+		var resp types.EquityHistoryResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, &resp
+	}
+
+	rec, resp := get("")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if len(resp.Snapshots) != 3 || resp.Interval != time.Minute.Milliseconds() {
+		t.Fatalf("expected 3 retained snapshots at 1m, got %d at %d", len(resp.Snapshots), resp.Interval)
+	}
+	first := resp.Snapshots[0]
+	if first.Timestamp != 1_700_000_060_000 || first.Equity != "990.000000000000000000" || first.Positions != 1 {
+		t.Errorf("unexpected oldest snapshot %+v", first)
+	}
+
+	_, resp = get("?from=1700000120000&to=1700000120000")
+	if len(resp.Snapshots) != 1 || resp.Snapshots[0].Timestamp != 1_700_000_120_000 {
+		t.Errorf("expected one snapshot in range, got %+v", resp.Snapshots)
+	}
+	_, resp = get("?limit=1")
+	if len(resp.Snapshots) != 1 || resp.Snapshots[0].Timestamp != 1_700_000_180_000 {
+		t.Errorf("expected the latest snapshot, got %+v", resp.Snapshots)
+	}
+	if rec, _ := get("?from=yesterday"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid from, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleAccountLegacy(rec, httptest.NewRequest(http.MethodGet, "/v1/accounts/bob/equity-history", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"snapshots":[]`) {
+		t.Errorf("expected empty history for an unknown trader, got %d: %s", rec.Code, rec.Body)
+	}
+}
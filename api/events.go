@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/api/websocket"
+)
+
+// Event log page sizes
+const (
+	DefaultEventsLimit = 500
+	MaxEventsLimit     = 1000
+)
+
+// eventPublishInterval is how often new engine events are pushed to WS subscribers
+const eventPublishInterval = 100 * time.Millisecond
+
+// handleEvents handles GET /v1/events: the engine's global event log from
+// from_seq (inclusive) on, so consumers can catch up after a disconnect and
+// order events across markets
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	events, ok := s.orderService.(types.EventService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "The event log is not available on this server")
+		return
+	}
+
+	query := r.URL.Query()
+	var fromSeq uint64
+	if v := query.Get("from_seq"); v != "" {
+		seq, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, types.ErrCodeInvalidRequest, "from_seq must be a non-negative integer")
+			return
+		}
+		fromSeq = seq
+	}
+	limit := DefaultEventsLimit
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 || n > MaxEventsLimit {
+			writeError(w, types.ErrCodeInvalidRequest, "limit must be between 1 and "+strconv.Itoa(MaxEventsLimit))
+			return
+		}
+		limit = n
+	}
+
+	resp, err := events.GetEvents(r.Context(), fromSeq, limit)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// startEventPublisher pushes new engine trades to the public trades channels
+// and order updates and fills to their owner's orders channel, in sequence
+// order
+func (s *Server) startEventPublisher() {
+	events, ok := s.orderService.(types.EventService)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	head, err := events.GetEvents(ctx, 0, 0)
+	s.recordEventBusPoll(err)
+	if err != nil {
+		log.Printf("Event publisher disabled: %v", err)
+		return
+	}
+	next := head.LastSeq + 1
+
+	ticker := time.NewTicker(eventPublishInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			page, err := events.GetEvents(ctx, next, MaxEventsLimit)
+			s.recordEventBusPoll(err)
+			if err != nil {
+				log.Printf("Event publisher: %v", err)
+				break
+			}
+			for _, event := range page.Events {
+				s.publishEvent(event)
+			}
+			next = page.NextSeq
+			if len(page.Events) < MaxEventsLimit {
+				break
+			}
+		}
+	}
+}
+
+// publishEvent broadcasts one event log entry over WebSocket, derives its L3
+// update and feeds it to order flow surveillance and the market volume
+// history
+func (s *Server) publishEvent(event *types.Event) {
+	s.observeSurveillance(event)
+	s.publishL3(event)
+	switch {
+	case event.Trade != nil:
+		s.wsServer.BroadcastTrade(&websocket.TradeMessage{
+			TradeID:   event.Trade.TradeID,
+			MarketID:  event.Trade.MarketID,
+			Price:     event.Trade.Price,
+			Quantity:  event.Trade.Quantity,
+			Side:      wsSide(event.Trade.Side),
+			Timestamp: event.Trade.Timestamp,
+			Seq:       event.Seq,
+		})
+		s.publishExecutions(event.Trade.TradeID)
+		s.recordMarketVolume(event.Trade)
+		if s.klineStore != nil {
+			if err := s.klineStore.record(event.Trade); err != nil {
+				log.Printf("Kline store: failed to record trade %s: %v", event.Trade.TradeID, err)
+			}
+		}
+	case event.Order != nil:
+		s.wsServer.BroadcastOrder(event.Order.Trader, &websocket.OrderMessage{
+			OrderID:    event.Order.OrderID,
+			MarketID:   event.Order.MarketID,
+			Trader:     event.Order.Trader,
+			Side:       wsSide(event.Order.Side),
+			Type:       event.Order.Type,
+			Price:      event.Order.Price,
+			Size:       event.Order.Quantity,
+			FilledSize: event.Order.FilledQty,
+			Status:     event.Order.Status,
+			Timestamp:  event.Order.UpdatedAt,
+			Seq:        event.Seq,
+		})
+	}
+}
+
+// wsSide maps engine sides ("SIDE_BUY") to the WebSocket form ("buy")
+func wsSide(side string) string {
+	return strings.ToLower(strings.TrimPrefix(side, "SIDE_"))
+}
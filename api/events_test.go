@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"cosmossdk.io/log"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestEventsCatchUp tests that placed orders and their trades are returned
+// from the event log in sequence order, with seq on the REST payloads
+func TestEventsCatchUp(t *testing.T) {
+	svc, err := NewRealService(log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	ctx := context.Background()
+	place := func(trader, side string) *types.PlaceOrderResponse {
+		resp, err := svc.PlaceOrder(ctx, &types.PlaceOrderRequest{
+			MarketID: "BTC-USDC", Trader: trader, Side: side, Type: "limit", Price: "50000", Quantity: "0.1",
+		})
+		if err != nil {
+			t.Fatalf("failed to place order: %v", err)
+		}
+		return resp
+	}
+	maker := place("maker", "sell")
+	taker := place("taker", "buy")
+	if len(taker.Match.Trades) != 1 || taker.Match.Trades[0].Seq <= maker.Order.Seq || taker.Order.Seq <= taker.Match.Trades[0].Seq {
+		t.Fatalf("expected trade sequenced between maker and taker updates, got maker %d, taker %+v", maker.Order.Seq, taker)
+	}
+
+	s := &Server{orderService: svc}
+	get := func(query string) (*httptest.ResponseRecorder, *types.EventsResponse) {
+		rec := httptest.NewRecorder()
+		s.handleEvents(rec, httptest.NewRequest(http.MethodGet, "/v1/events"+query, nil))
+		var resp types.EventsResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, &resp
+	}
+
+	rec, page := get("?from_seq=0&limit=2")
+	if rec.Code != http.StatusOK || len(page.Events) != 2 || page.Events[0].Seq != maker.Order.Seq || page.LastSeq != taker.Order.Seq {
+		t.Fatalf("unexpected first page %d: %s", rec.Code, rec.Body)
+	}
+	rec, page = get("?from_seq=" + strconv.FormatUint(page.NextSeq, 10))
+	if rec.Code != http.StatusOK || len(page.Events) == 0 || page.Events[len(page.Events)-1].Seq != taker.Order.Seq {
+		t.Fatalf("unexpected second page %d: %s", rec.Code, rec.Body)
+	}
+	for _, e := range page.Events {
+		if e.Type == types.EventTypeTrade && (e.Trade.MakerOrderID != maker.Order.OrderID || e.Trade.Seq != e.Seq) {
+			t.Errorf("unexpected trade event %+v", e.Trade)
+		}
+	}
+
+	if rec, _ := get("?from_seq=-1"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for negative from_seq, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	(&Server{orderService: NewMockService()}).handleEvents(rec, httptest.NewRequest(http.MethodGet, "/v1/events", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 without an event log, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"log"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// publishExecutions pushes the taker's and the maker's execution of a trade
+// to their owners' orders channels
+func (s *Server) publishExecutions(tradeID string) {
+	svc, ok := s.orderService.(types.ExecutionService)
+	if !ok {
+		return
+	}
+	executions, err := svc.GetTradeExecutions(context.Background(), tradeID)
+	if err != nil {
+		log.Printf("Event publisher: executions of trade %s: %v", tradeID, err)
+		return
+	}
+	for _, exec := range executions {
+		if exec.Trader == "" {
+			continue
+		}
+		pushed := *exec
+		pushed.Side = wsSide(exec.Side)
+		s.wsServer.BroadcastExecution(exec.Trader, &pushed)
+	}
+}
+
+// GetOrderExecutions lists an order's fills, oldest first
+func (rs *RealService) GetOrderExecutions(ctx context.Context, orderID string) (*types.OrderExecutionsResponse, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	order := rs.obKeeper.GetOrder(rs.sdkCtx, orderID)
+	executions := rs.obKeeper.GetOrderExecutions(rs.sdkCtx, orderID)
+	if order == nil && len(executions) == 0 {
+		return nil, obtypes.ErrOrderNotFound.Wrap(orderID)
+	}
+
+	resp := &types.OrderExecutionsResponse{OrderID: orderID, Executions: make([]*types.Execution, 0, len(executions))}
+	for _, exec := range executions {
+		resp.Executions = append(resp.Executions, convertExecution(exec, order))
+	}
+	return resp, nil
+}
+
+// GetTradeExecutions returns the taker's and the maker's execution of a trade
+func (rs *RealService) GetTradeExecutions(ctx context.Context, tradeID string) ([]*types.Execution, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	executions, err := rs.obKeeper.GetTradeExecutions(rs.sdkCtx, tradeID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]*types.Execution, 0, len(executions))
+	for _, exec := range executions {
+		resp = append(resp, convertExecution(exec, rs.obKeeper.GetOrder(rs.sdkCtx, exec.OrderID)))
+	}
+	return resp, nil
+}
+
+// convertExecution converts a stored execution, taking the remaining quantity
+// from the order's size. An order that no longer exists has nothing left.
+func convertExecution(exec *obtypes.Execution, order *obtypes.Order) *types.Execution {
+	remaining := math.LegacyZeroDec()
+	if order != nil && order.Quantity.GT(exec.CumulativeQty) {
+		remaining = order.Quantity.Sub(exec.CumulativeQty)
+	}
+	return &types.Execution{
+		OrderID:       exec.OrderID,
+		TradeID:       exec.TradeID,
+		MarketID:      exec.MarketID,
+		Trader:        exec.Trader,
+		Side:          exec.Side.String(),
+		Liquidity:     exec.Liquidity,
+		Price:         exec.Price.String(),
+		Quantity:      exec.Quantity.String(),
+		Fee:           exec.Fee.String(),
+		CumulativeQty: exec.CumulativeQty.String(),
+		RemainingQty:  remaining.String(),
+		Timestamp:     exec.Timestamp.UnixMilli(),
+	}
+}
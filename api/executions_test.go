@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestOrderExecutions tests that a partially filled order lists each fill
+// with its cumulative and remaining quantity
+func TestOrderExecutions(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	place := func(trader, side, quantity string) string {
+		t.Helper()
+		body := `{"market_id":"BTC-USDC","side":"` + side + `","type":"limit","price":"50000","quantity":"` + quantity + `","trader":"` + trader + `"}`
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("failed to place order: %d %s", rec.Code, rec.Body)
+		}
+		var resp types.PlaceOrderResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.Order.OrderID
+	}
+
+	orderID := place("exec-maker", "sell", "1")
+	place("exec-taker", "buy", "0.3")
+	place("exec-taker", "buy", "0.5")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/orders/"+orderID+"/executions", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp types.OrderExecutionsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.OrderID != orderID || len(resp.Executions) != 2 {
+		t.Fatalf("expected 2 executions of %s, got %+v", orderID, resp)
+	}
+	for i, want := range []struct{ qty, cumulative, remaining string }{
+		{"0.300000000000000000", "0.300000000000000000", "0.700000000000000000"},
+		{"0.500000000000000000", "0.800000000000000000", "0.200000000000000000"},
+	} {
+		exec := resp.Executions[i]
+		if exec.Quantity != want.qty || exec.CumulativeQty != want.cumulative || exec.RemainingQty != want.remaining {
+			t.Errorf("execution %d: expected %+v, got %+v", i, want, exec)
+		}
+		if exec.Liquidity != "maker" || exec.Side != "SIDE_SELL" || exec.Trader != "exec-maker" {
+			t.Errorf("execution %d: expected a maker sell by exec-maker, got %+v", i, exec)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/orders/missing/executions", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown order, got %d", rec.Code)
+	}
+}
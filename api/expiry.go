@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// orderExpiryInterval is how often the standalone server expires due
+// good-till-date orders; on chain the EndBlocker does it every block
+const orderExpiryInterval = time.Second
+
+// startOrderExpiryScheduler expires good-till-date orders when the order
+// service keeps its own book. The expiries reach WebSocket subscribers as
+// order updates through the event publisher.
+func (s *Server) startOrderExpiryScheduler() {
+	expiry, ok := s.orderService.(types.OrderExpiryService)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(orderExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		expired, err := expiry.ExpireOrders(context.Background())
+		if err != nil {
+			log.Printf("Order expiry: %v", err)
+			continue
+		}
+		if len(expired) > 0 {
+			log.Printf("Order expiry: expired %d GTD orders", len(expired))
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/middleware"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// DefaultFaucetCooldown is the time between faucet grants to the same address or IP
+const DefaultFaucetCooldown = time.Hour
+
+// faucet grants test USDC on dev and testnet deployments. Each address and
+// each client IP can receive one grant per cooldown. The client IP is the
+// connection's, or the one forwarded by a trusted proxy.
+type faucet struct {
+	amount         string
+	cooldown       time.Duration
+	trustedProxies []*net.IPNet
+	now            func() time.Time
+
+	mu     sync.Mutex
+	grants map[string]time.Time // "addr:<trader>" or "ip:<ip>" -> last grant
+}
+
+// newFaucet returns nil, disabling POST /v1/faucet, unless a positive
+// Config.FaucetAmount is set
+func newFaucet(config *Config) *faucet {
+	if config.FaucetAmount == "" {
+		return nil
+	}
+	amount, err := math.LegacyNewDecFromStr(config.FaucetAmount)
+	if err != nil || !amount.IsPositive() {
+		log.Printf("WARNING: invalid faucet amount %q; faucet disabled", config.FaucetAmount)
+		return nil
+	}
+	cooldown := config.FaucetCooldown
+	if cooldown <= 0 {
+		cooldown = DefaultFaucetCooldown
+	}
+	trustedProxies, err := middleware.ParseTrustedProxies(config.FaucetTrustedProxies)
+	if err != nil {
+		log.Printf("WARNING: %v; faucet disabled", err)
+		return nil
+	}
+	return &faucet{
+		amount:         config.FaucetAmount,
+		cooldown:       cooldown,
+		trustedProxies: trustedProxies,
+		now:            time.Now,
+		grants:         make(map[string]time.Time),
+	}
+}
+
+// reserve records a grant for trader and ip, or returns how long to wait
+// if either had one within the cooldown
+func (f *faucet) reserve(trader, ip string) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	keys := []string{"addr:" + trader, "ip:" + ip}
+	var wait time.Duration
+	for _, key := range keys {
+		if last, ok := f.grants[key]; ok {
+			if remaining := last.Add(f.cooldown).Sub(now); remaining > wait {
+				wait = remaining
+			}
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	for key, last := range f.grants {
+		if now.Sub(last) >= f.cooldown {
+			delete(f.grants, key)
+		}
+	}
+	for _, key := range keys {
+		f.grants[key] = now
+	}
+	return 0
+}
+
+// release forgets a reservation whose deposit failed
+func (f *faucet) release(trader, ip string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.grants, "addr:"+trader)
+	delete(f.grants, "ip:"+ip)
+}
+
+// handleFaucet handles POST /v1/faucet, depositing the configured amount of
+// test USDC into the trader's account
+func (s *Server) handleFaucet(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if s.faucet == nil {
+		writeError(w, types.ErrCodeNotFound, "Faucet is not enabled on this deployment")
+		return
+	}
+
+	var req struct {
+		Trader string `json:"trader"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+		return
+	}
+	if req.Trader == "" {
+		req.Trader = r.Header.Get("X-Trader-Address")
+	}
+	if req.Trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+
+	ip := middleware.RemoteClientIP(r, s.faucet.trustedProxies)
+	if wait := s.faucet.reserve(req.Trader, ip); wait > 0 {
+		retryAfter := int(wait.Round(time.Second).Seconds())
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		writeAPIError(w, types.NewAPIError(types.ErrCodeRateLimitExceeded, "Faucet already used by this address or IP, try again later").
+			WithDetail("retry_after", retryAfter))
+		return
+	}
+
+	resp, err := s.accountService.Deposit(r.Context(), &types.DepositRequest{Trader: req.Trader, Amount: s.faucet.amount})
+	if err != nil {
+		s.faucet.release(req.Trader, ip)
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"trader":          req.Trader,
+		"amount":          s.faucet.amount,
+		"account":         resp.Account,
+		"next_request_at": s.faucet.now().Add(s.faucet.cooldown).UnixMilli(),
+	})
+}
+
+// testAccountInitializer sets account balances outright, as the real
+// services do for dev and test accounts
+type testAccountInitializer interface {
+	InitializeTestAccount(trader string, balance string) error
+}
+
+// BootstrapAccounts funds n dev accounts named dev-trader-1 … dev-trader-n
+// with balance each and returns their addresses. cmd/api calls it at startup
+// for --bootstrap-accounts, so local environments and e2e runs start with
+// funded traders. Services that can set a balance outright start each
+// trader at exactly balance; others receive it as a deposit.
+func (s *Server) BootstrapAccounts(ctx context.Context, n int, balance string) ([]string, error) {
+	initializer, exact := s.accountService.(testAccountInitializer)
+	traders := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		trader := fmt.Sprintf("dev-trader-%d", i)
+		var err error
+		if exact {
+			err = initializer.InitializeTestAccount(trader, balance)
+		} else {
+			_, err = s.accountService.Deposit(ctx, &types.DepositRequest{Trader: trader, Amount: balance})
+		}
+		if err != nil {
+			return traders, fmt.Errorf("failed to fund %s: %w", trader, err)
+		}
+		traders = append(traders, trader)
+	}
+	return traders, nil
+}
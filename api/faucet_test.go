@@ -0,0 +1,120 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/middleware"
+)
+
+// TestFaucetCooldown tests that grants are limited per address and per IP
+func TestFaucetCooldown(t *testing.T) {
+	f := newFaucet(&Config{FaucetAmount: "10000", FaucetCooldown: time.Hour})
+	if f == nil {
+		t.Fatal("expected faucet to be enabled")
+	}
+	now := time.Unix(1700000000, 0)
+	f.now = func() time.Time { return now }
+
+	if wait := f.reserve("trader1", "10.0.0.1"); wait != 0 {
+		t.Fatalf("expected first grant, got wait %s", wait)
+	}
+	if wait := f.reserve("trader1", "10.0.0.2"); wait != time.Hour {
+		t.Errorf("expected same address to wait 1h, got %s", wait)
+	}
+	if wait := f.reserve("trader2", "10.0.0.1"); wait != time.Hour {
+		t.Errorf("expected same IP to wait 1h, got %s", wait)
+	}
+
+	// A failed deposit gives the reservation back
+	if wait := f.reserve("trader3", "10.0.0.3"); wait != 0 {
+		t.Fatalf("expected grant, got wait %s", wait)
+	}
+	f.release("trader3", "10.0.0.3")
+	if wait := f.reserve("trader3", "10.0.0.3"); wait != 0 {
+		t.Errorf("expected released reservation to be granted again, got wait %s", wait)
+	}
+
+	now = now.Add(time.Hour)
+	if wait := f.reserve("trader1", "10.0.0.1"); wait != 0 {
+		t.Errorf("expected grant after cooldown, got wait %s", wait)
+	}
+
+	if newFaucet(&Config{}) != nil || newFaucet(&Config{FaucetAmount: "-5"}) != nil {
+		t.Error("expected faucet disabled without a positive amount")
+	}
+}
+
+// TestFaucetClientIP tests that the faucet limits the connection's IP and
+// reads X-Forwarded-For only from trusted proxies
+func TestFaucetClientIP(t *testing.T) {
+	request := func(remoteAddr, forwardedFor string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/v1/faucet", nil)
+		r.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		return r
+	}
+
+	direct := newFaucet(&Config{FaucetAmount: "10000"})
+	if ip := middleware.RemoteClientIP(request("203.0.113.7:5000", "198.51.100.1"), direct.trustedProxies); ip != "203.0.113.7" {
+		t.Errorf("expected a spoofed header ignored without trusted proxies, got %s", ip)
+	}
+
+	proxied := newFaucet(&Config{FaucetAmount: "10000", FaucetTrustedProxies: []string{"10.0.0.0/8", "192.0.2.1"}})
+	testCases := []struct {
+		remoteAddr   string
+		forwardedFor string
+		expected     string
+	}{
+		{"10.1.2.3:5000", "203.0.113.7", "203.0.113.7"},
+		{"10.1.2.3:5000", "198.51.100.1, 203.0.113.7, 192.0.2.1", "203.0.113.7"},
+		{"10.1.2.3:5000", "", "10.1.2.3"},
+		{"203.0.113.9:5000", "198.51.100.1", "203.0.113.9"},
+	}
+	for _, tc := range testCases {
+		if ip := middleware.RemoteClientIP(request(tc.remoteAddr, tc.forwardedFor), proxied.trustedProxies); ip != tc.expected {
+			t.Errorf("%s forwarding %q: expected %s, got %s", tc.remoteAddr, tc.forwardedFor, tc.expected, ip)
+		}
+	}
+
+	if newFaucet(&Config{FaucetAmount: "10000", FaucetTrustedProxies: []string{"not-an-ip"}}) != nil {
+		t.Error("expected faucet disabled with an invalid trusted proxy")
+	}
+}
+
+// TestBootstrapAccountsStandalone tests that bootstrap and the faucet fund
+// accounts of a standalone real server, which has no perpetual keeper
+func TestBootstrapAccountsStandalone(t *testing.T) {
+	config := DefaultConfig()
+	config.FaucetAmount = "500"
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	ctx := context.Background()
+
+	traders, err := s.BootstrapAccounts(ctx, 2, "1000")
+	if err != nil || len(traders) != 2 || traders[1] != "dev-trader-2" {
+		t.Fatalf("expected 2 bootstrapped traders, got %v, %v", traders, err)
+	}
+	for _, trader := range traders {
+		if account, err := s.accountService.GetAccount(ctx, trader); err != nil || account.Balance != "1000.000000000000000000" {
+			t.Errorf("expected %s to start with exactly 1000, got %+v, %v", trader, account, err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	s.handleFaucet(rec, httptest.NewRequest(http.MethodPost, "/v1/faucet", bytes.NewBufferString(`{"trader": "dev-trader-1"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the faucet to fund a standalone account, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if account, _ := s.accountService.GetAccount(ctx, "dev-trader-1"); account.Balance != "1500.000000000000000000" {
+		t.Errorf("expected the faucet to add 500, got %s", account.Balance)
+	}
+}
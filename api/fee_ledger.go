@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// defaultFeeDays is how many days GET /v1/admin/fees/daily covers without from
+const defaultFeeDays = 7
+
+// feeDayLabel formats a fee rollup day as YYYY-MM-DD
+func feeDayLabel(day int64) string {
+	return obtypes.FeeDayStart(day).Format(time.DateOnly)
+}
+
+// fromOBFeeRollup converts a keeper fee rollup to the API response
+func fromOBFeeRollup(r *obtypes.FeeRollup) *types.FeeRollup {
+	rollup := &types.FeeRollup{
+		Day:           feeDayLabel(r.Day),
+		DayStart:      obtypes.FeeDayStart(r.Day).UnixMilli(),
+		MarketID:      r.MarketID,
+		Entries:       r.Entries,
+		MakerFees:     r.MakerFees.String(),
+		TakerFees:     r.TakerFees.String(),
+		TotalFees:     r.Total().String(),
+		InsuranceFund: r.InsuranceFund.String(),
+		RiverPool:     r.RiverPool.String(),
+		Treasury:      r.Treasury.String(),
+		Settled:       r.Settled,
+	}
+	if !r.FeeTokenFees.IsNil() && r.FeeTokenFees.IsPositive() {
+		rollup.FeeTokenFees = r.FeeTokenFees.String()
+	}
+	return rollup
+}
+
+// fromOBFeeBalances converts keeper fee balances to the API response
+func fromOBFeeBalances(b *obtypes.FeeBalances) *types.FeeBalances {
+	balances := &types.FeeBalances{
+		InsuranceFund: b.InsuranceFund.String(),
+		RiverPool:     b.RiverPool.String(),
+		Treasury:      b.Treasury.String(),
+	}
+	if b.SettledDay >= 0 {
+		balances.SettledThrough = feeDayLabel(b.SettledDay)
+	}
+	return balances
+}
+
+// handleAdminFeesDaily handles GET /v1/admin/fees/daily?from=&to=&market_id=,
+// the daily fee rollups between two Unix ms times (default: the last 7 days)
+// and the cumulative settled balances
+func (s *Server) handleAdminFeesDaily(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	fees, ok := s.orderService.(types.FeeLedgerService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Fee ledger requires a keeper-backed service")
+		return
+	}
+
+	query := r.URL.Query()
+	to := time.Now().UnixMilli()
+	from := to - defaultFeeDays*obtypes.FeeDayDuration.Milliseconds()
+	for name, value := range map[string]*int64{"from": &from, "to": &to} {
+		if v := query.Get(name); v != "" {
+			ms, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || ms < 0 {
+				writeError(w, types.ErrCodeInvalidRequest, name+" must be a non-negative Unix ms timestamp")
+				return
+			}
+			*value = ms
+		}
+	}
+	if from > to {
+		writeError(w, types.ErrCodeInvalidRequest, "from must not be after to")
+		return
+	}
+
+	rollups, err := fees.GetDailyFees(r.Context(), from, to, query.Get("market_id"))
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	balances, err := fees.GetFeeBalances(r.Context())
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"days":     rollups,
+		"balances": balances,
+	})
+}
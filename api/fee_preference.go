@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// handleFeePreference handles /v1/account/fee-preference (GET, POST): whether
+// the trader pays fees in the fee token, with the token's current terms
+func (s *Server) handleFeePreference(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+	prefs, ok := s.orderService.(types.FeePreferenceService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Fee preferences require a keeper-backed service")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		pref, err := prefs.GetFeePreference(r.Context(), trader)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"fee_preference": pref})
+
+	case http.MethodPost:
+		var req struct {
+			PayInFeeToken *bool `json:"pay_in_fee_token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		if req.PayInFeeToken == nil {
+			writeError(w, types.ErrCodeMissingField, "pay_in_fee_token is required")
+			return
+		}
+		pref, err := prefs.SetFeePreference(r.Context(), trader, *req.PayInFeeToken)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"fee_preference": pref})
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,107 @@
+package gateway
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Region is one deployment region and the base URLs of its API nodes
+type Region struct {
+	Name  string
+	Nodes []string
+}
+
+// Config contains gateway configuration
+type Config struct {
+	LocalRegion  string   // Region market data is served from
+	Regions      []Region // Every region the gateway can route to
+	OrderRegions []string // Regions that can take order flow, primary first
+
+	ProbeInterval     time.Duration // Time between /ready probes of every node
+	ProbeTimeout      time.Duration // Per-probe timeout
+	FailureThreshold  int           // Consecutive failures before a node is taken out
+	RecoveryThreshold int           // Consecutive good probes before it is put back
+	StickyTTL         time.Duration // How long an idle client stays pinned to its order node
+
+	AdminToken string // Required in X-Admin-Token for /gateway/status; empty allows loopback only
+}
+
+// DefaultConfig returns default gateway configuration
+func DefaultConfig() *Config {
+	return &Config{
+		ProbeInterval:     2 * time.Second,
+		ProbeTimeout:      time.Second,
+		FailureThreshold:  3,
+		RecoveryThreshold: 2,
+		StickyTTL:         30 * time.Minute,
+	}
+}
+
+// ParseRegions parses a region list such as
+// "us-east=http://a:8080,http://b:8080;eu-west=http://c:8080": regions
+// separated by ";", each a name and its comma-separated API node URLs
+func ParseRegions(spec string) ([]Region, error) {
+	var regions []Region
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, nodes, ok := strings.Cut(part, "=")
+		if !ok || name == "" || nodes == "" {
+			return nil, fmt.Errorf("invalid region %q (want name=url,...)", part)
+		}
+		region := Region{Name: strings.TrimSpace(name)}
+		for _, node := range strings.Split(nodes, ",") {
+			if node = strings.TrimSpace(node); node != "" {
+				region.Nodes = append(region.Nodes, node)
+			}
+		}
+		regions = append(regions, region)
+	}
+	return regions, nil
+}
+
+// Validate checks that every region is named once and has valid node URLs,
+// and that the local and order regions are among them
+func (c *Config) Validate() error {
+	if len(c.Regions) == 0 {
+		return fmt.Errorf("no regions configured")
+	}
+	known := make(map[string]bool, len(c.Regions))
+	for _, region := range c.Regions {
+		if region.Name == "" {
+			return fmt.Errorf("region without a name")
+		}
+		if known[region.Name] {
+			return fmt.Errorf("region %s is configured twice", region.Name)
+		}
+		known[region.Name] = true
+		if len(region.Nodes) == 0 {
+			return fmt.Errorf("region %s has no nodes", region.Name)
+		}
+		for _, node := range region.Nodes {
+			u, err := url.Parse(node)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("region %s: invalid node URL %q", region.Name, node)
+			}
+		}
+	}
+	if !known[c.LocalRegion] {
+		return fmt.Errorf("local region %q is not configured", c.LocalRegion)
+	}
+	if len(c.OrderRegions) == 0 {
+		return fmt.Errorf("no order regions configured")
+	}
+	for _, name := range c.OrderRegions {
+		if !known[name] {
+			return fmt.Errorf("order region %q is not configured", name)
+		}
+	}
+	if c.ProbeInterval <= 0 || c.ProbeTimeout <= 0 || c.FailureThreshold <= 0 || c.RecoveryThreshold <= 0 || c.StickyTTL <= 0 {
+		return fmt.Errorf("probe interval, timeout, thresholds and sticky TTL must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,448 @@
+// Package gateway implements the multi-region order gateway. A gateway runs
+// in every region in front of that region's API nodes: it serves market data
+// from the local region, routes order flow to the primary order region with
+// each client pinned to one API node there, fails the order path over to the
+// next order region when the primary's nodes stop answering their readiness
+// probes, and measures each client's round trip time to it.
+package gateway
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// Response headers set on proxied requests
+const (
+	RegionHeader = "X-Gateway-Region" // Region of the API node that served the request
+	RouteHeader  = "X-Gateway-Route"  // "market_data" or "order"
+)
+
+// adminTokenHeader carries the admin token for /gateway/status
+const adminTokenHeader = "X-Admin-Token"
+
+// marketDataPrefixes are the read-only paths served from the local region
+var marketDataPrefixes = []string{
+	"/v1/markets",
+	"/v1/tickers",
+	"/v1/snapshot",
+	"/v1/tv/",
+	"/v1/events",
+	"/v1/l3/",
+	"/health",
+	"/v1/health",
+}
+
+// Gateway is an http.Handler routing requests to regional API nodes
+type Gateway struct {
+	config  *Config
+	regions map[string][]*node
+	nodes   []*node
+	client  *http.Client
+	started time.Time // Monotonic reference for ping tokens
+	next    atomic.Uint64
+
+	mu       sync.Mutex
+	sessions map[string]*session   // client key -> pinned order node
+	clients  map[string]*clientRTT // client key -> measured round trip time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// session pins a client's order flow to one API node
+type session struct {
+	node     *node
+	lastSeen time.Time
+}
+
+// New creates a gateway for a validated configuration. Call Start to begin
+// probing the nodes.
+func New(config *Config) (*Gateway, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	g := &Gateway{
+		config:   config,
+		regions:  make(map[string][]*node, len(config.Regions)),
+		client:   &http.Client{},
+		started:  time.Now(),
+		sessions: make(map[string]*session),
+		clients:  make(map[string]*clientRTT),
+		stopCh:   make(chan struct{}),
+	}
+	for _, region := range config.Regions {
+		for _, rawURL := range region.Nodes {
+			n, err := newNode(region.Name, rawURL)
+			if err != nil {
+				return nil, fmt.Errorf("region %s: %w", region.Name, err)
+			}
+			n.proxy.ErrorHandler = g.proxyErrorHandler(n)
+			g.regions[region.Name] = append(g.regions[region.Name], n)
+			g.nodes = append(g.nodes, n)
+		}
+	}
+	return g, nil
+}
+
+// Start probes every node now and then every ProbeInterval until Stop
+func (g *Gateway) Start() {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		ticker := time.NewTicker(g.config.ProbeInterval)
+		defer ticker.Stop()
+		for {
+			g.probeAll(context.Background())
+			g.prune(time.Now())
+			select {
+			case <-g.stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends probing
+func (g *Gateway) Stop() {
+	g.stopOnce.Do(func() { close(g.stopCh) })
+	g.wg.Wait()
+}
+
+// probeAll probes every node concurrently and waits for the results
+func (g *Gateway) probeAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, n := range g.nodes {
+		wg.Add(1)
+		go func(n *node) {
+			defer wg.Done()
+			n.probe(ctx, g.client, g.config)
+		}(n)
+	}
+	wg.Wait()
+}
+
+// prune forgets sessions and RTT measurements of clients idle for StickyTTL
+func (g *Gateway) prune(now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for key, s := range g.sessions {
+		if now.Sub(s.lastSeen) >= g.config.StickyTTL {
+			delete(g.sessions, key)
+		}
+	}
+	for key, c := range g.clients {
+		if now.Sub(c.lastSeen) >= g.config.StickyTTL {
+			delete(g.clients, key)
+		}
+	}
+}
+
+// ServeHTTP serves the gateway's own endpoints and proxies everything else:
+// market data to the local region, order flow to the active order region
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/gateway/ping":
+		g.handlePing(w, r)
+		return
+	case "/gateway/status":
+		g.handleStatus(w, r)
+		return
+	case "/ready":
+		g.handleReady(w, r)
+		return
+	}
+
+	if isMarketData(r) {
+		n := g.marketDataNode()
+		if n == nil {
+			w.Header().Set("Retry-After", "1")
+			writeAPIError(w, types.NewAPIError(types.ErrCodeServiceUnavailable, "No API node is available for market data"))
+			return
+		}
+		g.forward(w, r, n, "market_data")
+		return
+	}
+
+	n := g.orderNode(clientKey(r), time.Now())
+	if n == nil {
+		w.Header().Set("Retry-After", "1")
+		writeAPIError(w, types.NewAPIError(types.ErrCodeServiceUnavailable, "No order region is available"))
+		return
+	}
+	g.forward(w, r, n, "order")
+}
+
+func (g *Gateway) forward(w http.ResponseWriter, r *http.Request, n *node, route string) {
+	w.Header().Set(RegionHeader, n.region)
+	w.Header().Set(RouteHeader, route)
+	n.proxy.ServeHTTP(w, r)
+}
+
+// proxyErrorHandler answers a request the node failed with 503 and counts
+// the failure towards taking the node out. Requests are not retried on
+// another node: an order may have reached the matcher before the failure.
+func (g *Gateway) proxyErrorHandler(n *node) func(http.ResponseWriter, *http.Request, error) {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		if r.Context().Err() == nil {
+			n.recordFailure(err, g.config)
+		}
+		w.Header().Set("Retry-After", "1")
+		writeAPIError(w, types.NewAPIError(types.ErrCodeServiceUnavailable, "API node unavailable"))
+	}
+}
+
+// isMarketData reports whether the request is read-only market data, which
+// any region can serve. WebSocket connections carry market data and private
+// channels but no order entry, so they stay local too.
+func isMarketData(r *http.Request) bool {
+	if r.URL.Path == "/ws" {
+		return true
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	for _, prefix := range marketDataPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// marketDataNode picks a healthy node of the local region, round robin. When
+// the local region has none it falls back to the region the gateway reaches
+// fastest.
+func (g *Gateway) marketDataNode() *node {
+	if healthy := healthyNodes(g.regions[g.config.LocalRegion]); len(healthy) > 0 {
+		return healthy[g.next.Add(1)%uint64(len(healthy))]
+	}
+
+	var best []*node
+	var bestRTT time.Duration
+	for _, region := range g.config.Regions {
+		healthy := healthyNodes(g.regions[region.Name])
+		if len(healthy) == 0 {
+			continue
+		}
+		if rtt := regionRTT(healthy); best == nil || rtt < bestRTT {
+			best, bestRTT = healthy, rtt
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best[g.next.Add(1)%uint64(len(best))]
+}
+
+// orderRegion returns the first order region with healthy nodes, and those
+// nodes. The primary takes order flow back as soon as it recovers.
+func (g *Gateway) orderRegion() (string, []*node) {
+	for _, name := range g.config.OrderRegions {
+		if healthy := healthyNodes(g.regions[name]); len(healthy) > 0 {
+			return name, healthy
+		}
+	}
+	return "", nil
+}
+
+// orderNode returns the node a client's order flow goes to. A client stays
+// on its node while the node is healthy and in the active order region;
+// otherwise it is pinned anew by rendezvous hashing, so gateways in every
+// region pin the same client to the same node without sharing state.
+func (g *Gateway) orderNode(key string, now time.Time) *node {
+	region, healthy := g.orderRegion()
+	if healthy == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if s, ok := g.sessions[key]; ok && s.node.region == region && s.node.isHealthy() {
+		s.lastSeen = now
+		return s.node
+	}
+	n := rendezvous(key, healthy)
+	g.sessions[key] = &session{node: n, lastSeen: now}
+	return n
+}
+
+// rendezvous picks the node with the highest hash of the key and its URL
+func rendezvous(key string, nodes []*node) *node {
+	var best *node
+	var bestScore uint64
+	for _, n := range nodes {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(n.url.String()))
+		if score := h.Sum64(); best == nil || score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	return best
+}
+
+func healthyNodes(nodes []*node) []*node {
+	healthy := make([]*node, 0, len(nodes))
+	for _, n := range nodes {
+		if n.isHealthy() {
+			healthy = append(healthy, n)
+		}
+	}
+	return healthy
+}
+
+// regionRTT is the lowest round trip time to any of the nodes
+func regionRTT(nodes []*node) time.Duration {
+	var best time.Duration
+	for i, n := range nodes {
+		if rtt := n.roundTrip(); i == 0 || rtt < best {
+			best = rtt
+		}
+	}
+	return best
+}
+
+// clientKey identifies a client for sticky routing: its trader address when
+// it sends one, otherwise its IP address
+func clientKey(r *http.Request) string {
+	if trader := r.Header.Get("X-Trader-Address"); trader != "" {
+		return "trader:" + trader
+	}
+	if trader := r.URL.Query().Get("trader"); trader != "" {
+		return "trader:" + trader
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// ============ Status ============
+
+// Status is the GET /gateway/status response
+type Status struct {
+	Region      string         `json:"region"`
+	OrderRegion string         `json:"order_region,omitempty"`
+	Regions     []RegionStatus `json:"regions"`
+	Sessions    int            `json:"sessions"`
+	Clients     int            `json:"clients"`
+}
+
+// RegionStatus is one region's nodes as the gateway sees them
+type RegionStatus struct {
+	Name  string       `json:"name"`
+	Order bool         `json:"order"`
+	Nodes []NodeStatus `json:"nodes"`
+}
+
+// NodeStatus is one API node's health and round trip time
+type NodeStatus struct {
+	URL       string  `json:"url"`
+	Healthy   bool    `json:"healthy"`
+	RTTMs     float64 `json:"rtt_ms"`
+	LastProbe int64   `json:"last_probe,omitempty"`
+	LastError string  `json:"last_error,omitempty"`
+}
+
+// handleStatus handles GET /gateway/status (admin)
+func (g *Gateway) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, types.NewAPIError(types.ErrCodeMethodNotAllowed, "Method not allowed"))
+		return
+	}
+	if !g.isAdminRequest(r) {
+		writeAPIError(w, types.NewAPIError(types.ErrCodeUnauthorized, "Admin access required"))
+		return
+	}
+	writeJSON(w, http.StatusOK, g.Status())
+}
+
+// Status reports every region's nodes and the active order region
+func (g *Gateway) Status() *Status {
+	orderRegion, _ := g.orderRegion()
+	status := &Status{
+		Region:      g.config.LocalRegion,
+		OrderRegion: orderRegion,
+		Regions:     make([]RegionStatus, 0, len(g.config.Regions)),
+	}
+	orders := make(map[string]bool, len(g.config.OrderRegions))
+	for _, name := range g.config.OrderRegions {
+		orders[name] = true
+	}
+	for _, region := range g.config.Regions {
+		rs := RegionStatus{Name: region.Name, Order: orders[region.Name]}
+		for _, n := range g.regions[region.Name] {
+			rs.Nodes = append(rs.Nodes, n.status())
+		}
+		status.Regions = append(status.Regions, rs)
+	}
+
+	g.mu.Lock()
+	status.Sessions = len(g.sessions)
+	status.Clients = len(g.clients)
+	g.mu.Unlock()
+	return status
+}
+
+// handleReady handles GET /ready: 200 while both market data and the order
+// path have a healthy node
+func (g *Gateway) handleReady(w http.ResponseWriter, r *http.Request) {
+	orderRegion, _ := g.orderRegion()
+	switch {
+	case orderRegion == "":
+		writeAPIError(w, types.NewAPIError(types.ErrCodeServiceUnavailable, "No order region is available"))
+	case g.marketDataNode() == nil:
+		writeAPIError(w, types.NewAPIError(types.ErrCodeServiceUnavailable, "No API node is available for market data"))
+	default:
+		writeJSON(w, http.StatusOK, map[string]string{
+			"status":       "ready",
+			"region":       g.config.LocalRegion,
+			"order_region": orderRegion,
+		})
+	}
+}
+
+// isAdminRequest checks the admin token, or a loopback client when none is set
+func (g *Gateway) isAdminRequest(r *http.Request) bool {
+	if g.config.AdminToken != "" {
+		token := r.Header.Get(adminTokenHeader)
+		return subtle.ConstantTimeCompare([]byte(token), []byte(g.config.AdminToken)) == 1
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeAPIError(w http.ResponseWriter, apiErr *types.APIError) {
+	writeJSON(w, apiErr.Code.HTTPStatus(), types.NewErrorResponse(apiErr))
+}
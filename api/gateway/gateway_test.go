@@ -0,0 +1,225 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// testNode is an API node that answers with its name and can fail /ready
+type testNode struct {
+	name   string
+	server *httptest.Server
+	down   atomic.Bool
+}
+
+func newTestNode(t *testing.T, name string) *testNode {
+	t.Helper()
+	n := &testNode{name: name}
+	n.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ready" && n.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(n.name))
+	}))
+	t.Cleanup(n.server.Close)
+	return n
+}
+
+// TestGatewayRouting tests that market data is served from the local region,
+// that a client's orders stick to one node of the primary region, and that
+// the order path fails over to the next order region and back
+func TestGatewayRouting(t *testing.T) {
+	east1, east2, west := newTestNode(t, "east1"), newTestNode(t, "east2"), newTestNode(t, "west")
+	config := DefaultConfig()
+	config.LocalRegion = "eu-west"
+	config.Regions = []Region{
+		{Name: "us-east", Nodes: []string{east1.server.URL, east2.server.URL}},
+		{Name: "eu-west", Nodes: []string{west.server.URL}},
+	}
+	config.OrderRegions = []string{"us-east", "eu-west"}
+	config.FailureThreshold = 1
+	config.RecoveryThreshold = 1
+	g, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create gateway: %v", err)
+	}
+	nodes := map[string]*testNode{"east1": east1, "east2": east2, "west": west}
+
+	send := func(method, path, trader string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		if trader != "" {
+			req.Header.Set("X-Trader-Address", trader)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(http.MethodGet, "/v1/markets/BTC-USDC/orderbook", "alice")
+	if rec.Body.String() != "west" || rec.Header().Get(RegionHeader) != "eu-west" || rec.Header().Get(RouteHeader) != "market_data" {
+		t.Errorf("expected market data from the local region, got %q from %s", rec.Body.String(), rec.Header().Get(RegionHeader))
+	}
+
+	// alice's orders stay on one primary node
+	pinned := send(http.MethodPost, "/v1/orders", "alice").Body.String()
+	if pinned != "east1" && pinned != "east2" {
+		t.Fatalf("expected orders in the primary region, got %q", pinned)
+	}
+	for i := 0; i < 5; i++ {
+		if got := send(http.MethodPost, "/v1/orders", "alice").Body.String(); got != pinned {
+			t.Fatalf("expected alice to stay on %s, got %s", pinned, got)
+		}
+	}
+	if got := send(http.MethodGet, "/v1/positions", "alice").Body.String(); got != pinned {
+		t.Errorf("expected account reads on alice's order node, got %s", got)
+	}
+
+	// Her node fails its probe: she moves to the other primary node and
+	// stays there when hers recovers
+	nodes[pinned].down.Store(true)
+	g.probeAll(context.Background())
+	moved := send(http.MethodPost, "/v1/orders", "alice").Body.String()
+	if moved == pinned || moved == "west" {
+		t.Fatalf("expected alice on the other primary node, got %s", moved)
+	}
+	nodes[pinned].down.Store(false)
+	g.probeAll(context.Background())
+	if got := send(http.MethodPost, "/v1/orders", "alice").Body.String(); got != moved {
+		t.Errorf("expected alice to stay on %s after recovery, got %s", moved, got)
+	}
+
+	// The whole primary region fails: orders go to eu-west, and back once
+	// the primary recovers
+	east1.down.Store(true)
+	east2.down.Store(true)
+	g.probeAll(context.Background())
+	if rec = send(http.MethodPost, "/v1/orders", "alice"); rec.Body.String() != "west" || rec.Header().Get(RegionHeader) != "eu-west" {
+		t.Errorf("expected orders to fail over to eu-west, got %q", rec.Body.String())
+	}
+	if status := g.Status(); status.OrderRegion != "eu-west" {
+		t.Errorf("expected eu-west to be the order region, got %s", status.OrderRegion)
+	}
+	east1.down.Store(false)
+	east2.down.Store(false)
+	g.probeAll(context.Background())
+	if got := send(http.MethodPost, "/v1/orders", "alice").Body.String(); got != "east1" && got != "east2" {
+		t.Errorf("expected orders back in the primary region, got %s", got)
+	}
+
+	// A node that stops answering is taken out by the failed request itself
+	west.server.Close()
+	if rec = send(http.MethodGet, "/v1/tickers", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 from a dead node, got %d", rec.Code)
+	}
+	if rec = send(http.MethodGet, "/v1/tickers", ""); rec.Header().Get(RegionHeader) != "us-east" {
+		t.Errorf("expected market data from the next region, got %q", rec.Header().Get(RegionHeader))
+	}
+
+	east1.down.Store(true)
+	east2.down.Store(true)
+	g.probeAll(context.Background())
+	rec = send(http.MethodPost, "/v1/orders", "alice")
+	var resp types.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Error != string(types.ErrCodeServiceUnavailable) {
+		t.Errorf("expected service_unavailable without an order region, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec = send(http.MethodGet, "/ready", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected the gateway not to be ready, got %d", rec.Code)
+	}
+}
+
+// TestGatewayPing tests that the ping token round trip yields RTT samples
+// and that status requires the admin token
+func TestGatewayPing(t *testing.T) {
+	node := newTestNode(t, "east")
+	config := DefaultConfig()
+	config.LocalRegion = "us-east"
+	config.Regions = []Region{{Name: "us-east", Nodes: []string{node.server.URL}}}
+	config.OrderRegions = []string{"us-east"}
+	config.AdminToken = "secret"
+	g, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create gateway: %v", err)
+	}
+
+	ping := func(token string) (int, *PingResponse) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/gateway/ping?token="+token, nil)
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		var resp PingResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, &resp
+	}
+
+	_, first := ping("")
+	if first.Token == "" || first.Samples != 0 || first.OrderRegion != "us-east" {
+		t.Fatalf("expected a token without samples, got %+v", first)
+	}
+	code, second := ping(first.Token)
+	if code != http.StatusOK || second.Samples != 1 || second.ClientRTTMs < 0 || second.Token == first.Token {
+		t.Fatalf("expected one RTT sample and a fresh token, got %d %+v", code, second)
+	}
+	if _, third := ping(second.Token); third.Samples != 2 {
+		t.Errorf("expected a second sample, got %+v", third)
+	}
+	for _, token := range []string{"abc", "-1", "999999999999999999"} {
+		if code, _ := ping(token); code != http.StatusBadRequest {
+			t.Errorf("expected token %q to be rejected, got %d", token, code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/gateway/status", nil)
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if rec.Code != types.ErrCodeUnauthorized.HTTPStatus() {
+		t.Errorf("expected unauthorized without the admin token, got %d", rec.Code)
+	}
+	req.Header.Set(adminTokenHeader, "secret")
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	var status Status
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil || status.Clients != 1 || len(status.Regions) != 1 || !status.Regions[0].Order {
+		t.Errorf("expected one measured client in the status, got %d %+v", rec.Code, status)
+	}
+}
+
+// TestParseRegions tests the region list syntax and config validation
+func TestParseRegions(t *testing.T) {
+	regions, err := ParseRegions("us-east=http://a:8080, http://b:8080; eu-west=http://c:8080")
+	if err != nil || len(regions) != 2 || len(regions[0].Nodes) != 2 || regions[1].Name != "eu-west" {
+		t.Fatalf("unexpected regions %+v (err %v)", regions, err)
+	}
+	if _, err := ParseRegions("us-east"); err == nil {
+		t.Errorf("expected a region without nodes to fail")
+	}
+
+	config := DefaultConfig()
+	config.Regions = regions
+	config.LocalRegion = "eu-west"
+	config.OrderRegions = []string{"us-east"}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected a valid config, got %v", err)
+	}
+	for name, mutate := range map[string]func(c *Config){
+		"unknown local region": func(c *Config) { c.LocalRegion = "ap-south" },
+		"unknown order region": func(c *Config) { c.OrderRegions = []string{"ap-south"} },
+		"no order region":      func(c *Config) { c.OrderRegions = nil },
+		"bad node URL":         func(c *Config) { c.Regions = []Region{{Name: "eu-west", Nodes: []string{"c:8080"}}} },
+		"duplicate region":     func(c *Config) { c.Regions = append(c.Regions, c.Regions[0]) },
+	} {
+		c := *config
+		mutate(&c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// node is one upstream API node with its health and the gateway's smoothed
+// round trip time to it
+type node struct {
+	region string
+	url    *url.URL
+	proxy  *httputil.ReverseProxy
+
+	mu        sync.RWMutex
+	healthy   bool
+	failures  int // Consecutive failed probes or proxied requests
+	successes int // Consecutive good probes while out of rotation
+	rtt       time.Duration
+	lastProbe time.Time
+	lastError string
+}
+
+// newNode creates a node that starts in rotation, so the gateway routes
+// before its first probe completes
+func newNode(region, rawURL string) (*node, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &node{
+		region:  region,
+		url:     u,
+		proxy:   httputil.NewSingleHostReverseProxy(u),
+		healthy: true,
+	}, nil
+}
+
+func (n *node) isHealthy() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.healthy
+}
+
+func (n *node) roundTrip() time.Duration {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.rtt
+}
+
+// probe checks the node's /ready endpoint and records the outcome
+func (n *node) probe(ctx context.Context, client *http.Client, config *Config) {
+	ctx, cancel := context.WithTimeout(ctx, config.ProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.url.JoinPath("/ready").String(), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("/ready returned %d", resp.StatusCode)
+		}
+		return nil
+	}()
+	if err != nil {
+		n.recordFailure(err, config)
+		return
+	}
+	n.recordSuccess(time.Since(start), config)
+}
+
+// recordSuccess folds a good probe's round trip time into the node's RTT
+// and puts the node back in rotation after enough of them in a row
+func (n *node) recordSuccess(rtt time.Duration, config *Config) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.lastProbe = time.Now()
+	n.lastError = ""
+	n.failures = 0
+	n.rtt = smoothRTT(n.rtt, rtt)
+	if !n.healthy {
+		n.successes++
+		if n.successes >= config.RecoveryThreshold {
+			n.healthy = true
+			n.successes = 0
+		}
+	}
+}
+
+// recordFailure takes the node out of rotation after enough failures in a
+// row. Failed probes and failed proxied requests both count.
+func (n *node) recordFailure(err error, config *Config) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.lastProbe = time.Now()
+	n.lastError = err.Error()
+	n.successes = 0
+	n.failures++
+	if n.failures >= config.FailureThreshold {
+		n.healthy = false
+	}
+}
+
+// status reports the node for /gateway/status
+func (n *node) status() NodeStatus {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	status := NodeStatus{
+		URL:       n.url.String(),
+		Healthy:   n.healthy,
+		RTTMs:     millis(n.rtt),
+		LastError: n.lastError,
+	}
+	if !n.lastProbe.IsZero() {
+		status.LastProbe = n.lastProbe.UnixMilli()
+	}
+	return status
+}
+
+// smoothRTT is an exponentially weighted moving average giving a new sample
+// a quarter of the weight; the first sample is taken as is
+func smoothRTT(current, sample time.Duration) time.Duration {
+	if current == 0 {
+		return sample
+	}
+	return current + (sample-current)/4
+}
+
+// millis converts a duration to milliseconds with two decimals
+func millis(d time.Duration) float64 {
+	return float64(d/(10*time.Microsecond)) / 100
+}
@@ -0,0 +1,103 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// maxPingTokenAge is the oldest ping token that still yields an RTT sample
+const maxPingTokenAge = 10 * time.Second
+
+// clientRTT is a client's smoothed round trip time to the gateway
+type clientRTT struct {
+	rtt      time.Duration
+	samples  int
+	lastSeen time.Time
+}
+
+// PingResponse is the GET /gateway/ping response. Token is echoed back in
+// the next ping; the time between issuing it and receiving it again is one
+// client round trip.
+type PingResponse struct {
+	Region                  string  `json:"region"`
+	Token                   string  `json:"token"`
+	ClientRTTMs             float64 `json:"client_rtt_ms,omitempty"`
+	Samples                 int     `json:"samples,omitempty"`
+	OrderRegion             string  `json:"order_region,omitempty"`
+	OrderRTTMs              float64 `json:"order_rtt_ms,omitempty"`
+	EstimatedOrderLatencyMs float64 `json:"estimated_order_latency_ms,omitempty"`
+}
+
+// handlePing handles GET /gateway/ping?token=. Without a token it only
+// issues one; with a token it records an RTT sample for the client and
+// reports the client's smoothed RTT, the gateway's RTT to the client's
+// order node and their sum, the latency an order sent through this gateway
+// can expect.
+func (g *Gateway) handlePing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, types.NewAPIError(types.ErrCodeMethodNotAllowed, "Method not allowed"))
+		return
+	}
+	now := time.Now()
+	key := clientKey(r)
+
+	resp := &PingResponse{
+		Region: g.config.LocalRegion,
+		Token:  strconv.FormatInt(int64(now.Sub(g.started)), 10),
+	}
+	var clientRTT time.Duration
+	if token := r.URL.Query().Get("token"); token != "" {
+		sample, ok := g.tokenAge(token, now)
+		if !ok {
+			writeAPIError(w, types.NewAPIError(types.ErrCodeInvalidRequest, "Invalid or expired ping token"))
+			return
+		}
+		c := g.recordClientRTT(key, sample, now)
+		clientRTT = c.rtt
+		resp.ClientRTTMs = millis(c.rtt)
+		resp.Samples = c.samples
+	}
+
+	if n := g.orderNode(key, now); n != nil {
+		resp.OrderRegion = n.region
+		resp.OrderRTTMs = millis(n.roundTrip())
+		if clientRTT > 0 {
+			resp.EstimatedOrderLatencyMs = millis(clientRTT + n.roundTrip())
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// tokenAge returns how long ago a ping token was issued, if it is one of
+// this gateway's and no older than maxPingTokenAge
+func (g *Gateway) tokenAge(token string, now time.Time) (time.Duration, bool) {
+	issued, err := strconv.ParseInt(token, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	age := now.Sub(g.started) - time.Duration(issued)
+	if issued < 0 || age < 0 || age > maxPingTokenAge {
+		return 0, false
+	}
+	return age, true
+}
+
+// recordClientRTT folds an RTT sample into the client's measurement and
+// returns a copy of it
+func (g *Gateway) recordClientRTT(key string, sample time.Duration, now time.Time) clientRTT {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	c, ok := g.clients[key]
+	if !ok {
+		c = &clientRTT{}
+		g.clients[key] = c
+	}
+	c.rtt = smoothRTT(c.rtt, sample)
+	c.samples++
+	c.lastSeen = now
+	return *c
+}
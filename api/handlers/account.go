@@ -3,14 +3,15 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strings"
 
 	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/api/webhook"
 )
 
 // AccountHandler handles account-related HTTP requests
 type AccountHandler struct {
 	service types.AccountService
+	events  types.AccountEventPublisher
 }
 
 // NewAccountHandler creates a new account handler
@@ -18,6 +19,12 @@ func NewAccountHandler(service types.AccountService) *AccountHandler {
 	return &AccountHandler{service: service}
 }
 
+// WithAccountEvents publishes withdrawal_completed events for successful withdrawals
+func (h *AccountHandler) WithAccountEvents(events types.AccountEventPublisher) *AccountHandler {
+	h.events = events
+	return h
+}
+
 // HandleAccount handles /v1/account endpoint (GET for account info)
 func (h *AccountHandler) HandleAccount(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -26,7 +33,7 @@ func (h *AccountHandler) HandleAccount(w http.ResponseWriter, r *http.Request) {
 	case http.MethodOptions:
 		w.WriteHeader(http.StatusOK)
 	default:
-		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
 	}
 }
 
@@ -37,19 +44,19 @@ func (h *AccountHandler) HandleDeposit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if r.Method != http.MethodPost {
-		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	var req types.DepositRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	// Validate required fields
 	if req.Amount == "" {
-		writeError(w, http.StatusBadRequest, "missing_amount", "amount is required")
+		writeError(w, types.ErrCodeMissingField, "amount is required")
 		return
 	}
 
@@ -58,13 +65,13 @@ func (h *AccountHandler) HandleDeposit(w http.ResponseWriter, r *http.Request) {
 		req.Trader = r.Header.Get("X-Trader-Address")
 	}
 	if req.Trader == "" {
-		writeError(w, http.StatusBadRequest, "missing_trader", "trader address is required")
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
 		return
 	}
 
 	resp, err := h.service.Deposit(r.Context(), &req)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "deposit_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -78,19 +85,19 @@ func (h *AccountHandler) HandleWithdraw(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	if r.Method != http.MethodPost {
-		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	var req types.WithdrawRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	// Validate required fields
 	if req.Amount == "" {
-		writeError(w, http.StatusBadRequest, "missing_amount", "amount is required")
+		writeError(w, types.ErrCodeMissingField, "amount is required")
 		return
 	}
 
@@ -99,21 +106,33 @@ func (h *AccountHandler) HandleWithdraw(w http.ResponseWriter, r *http.Request)
 		req.Trader = r.Header.Get("X-Trader-Address")
 	}
 	if req.Trader == "" {
-		writeError(w, http.StatusBadRequest, "missing_trader", "trader address is required")
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
 		return
 	}
 
 	resp, err := h.service.Withdraw(r.Context(), &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, "account_not_found", err.Error())
-		} else if strings.Contains(err.Error(), "insufficient") {
-			writeError(w, http.StatusBadRequest, "insufficient_balance", err.Error())
-		} else {
-			writeError(w, http.StatusBadRequest, "withdraw_failed", err.Error())
-		}
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
+	if h.events != nil {
+		event := webhook.EventWithdrawalCompleted
+		data := map[string]interface{}{"amount": req.Amount}
+		if resp.Account != nil {
+			data["balance"] = resp.Account.Balance
+			data["available_balance"] = resp.Account.AvailableBalance
+		}
+		if w := resp.Withdrawal; w != nil {
+			data["withdrawal_id"] = w.WithdrawalID
+			data["destination"] = w.Destination
+			if w.Status == "pending" {
+				// Tell the trader while the withdrawal can still be cancelled
+				event = webhook.EventWithdrawalRequested
+				data["release_at"] = w.ReleaseAt
+			}
+		}
+		h.events.PublishAccountEvent(req.Trader, event, data)
+	}
 
 	writeJSON(w, http.StatusOK, resp)
 }
@@ -125,13 +144,13 @@ func (h *AccountHandler) getAccount(w http.ResponseWriter, r *http.Request) {
 		trader = r.Header.Get("X-Trader-Address")
 	}
 	if trader == "" {
-		writeError(w, http.StatusBadRequest, "missing_trader", "trader address is required")
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
 		return
 	}
 
 	account, err := h.service.GetAccount(r.Context(), trader)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "get_account_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInternal)
 		return
 	}
 
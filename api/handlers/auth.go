@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/openalpha/perp-dex/api/auth"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// AuthHandler handles session login requests
+type AuthHandler struct {
+	sessions *auth.SessionManager
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(sessions *auth.SessionManager) *AuthHandler {
+	return &AuthHandler{sessions: sessions}
+}
+
+// HandleWSToken handles POST /v1/auth/ws-token.
+// A request carrying X-API-Key logs in with the key; otherwise the body must
+// hold a wallet signature over auth.LoginMessage(trader, timestamp).
+func (h *AuthHandler) HandleWSToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var (
+		token   string
+		session *auth.Session
+		err     error
+	)
+
+	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+		token, session, err = h.sessions.LoginWithAPIKey(apiKey)
+	} else {
+		var req types.WSTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		if req.Trader == "" || req.Signature == "" || req.PubKey == "" || req.Timestamp == 0 {
+			writeError(w, types.ErrCodeMissingField, "trader, timestamp, pub_key and signature are required")
+			return
+		}
+
+		pubKey, decErr := base64.StdEncoding.DecodeString(req.PubKey)
+		if decErr != nil {
+			writeError(w, types.ErrCodeInvalidRequest, "pub_key must be base64")
+			return
+		}
+		signature, decErr := base64.StdEncoding.DecodeString(req.Signature)
+		if decErr != nil {
+			writeError(w, types.ErrCodeInvalidRequest, "signature must be base64")
+			return
+		}
+
+		token, session, err = h.sessions.LoginWithWallet(req.Trader, req.Timestamp, pubKey, signature)
+	}
+	if err != nil {
+		writeServiceError(w, err, types.ErrCodeUnauthenticated)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &types.WSTokenResponse{
+		Token:     token,
+		Trader:    session.Trader,
+		SessionID: session.SessionID,
+		ExpiresAt: session.Expiry().UnixMilli(),
+	})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// Order and trade history page sizes
+const (
+	DefaultHistoryLimit = 100
+	MaxHistoryLimit     = 500
+)
+
+// parseHistoryParams reads the side, from, to, sort, limit and cursor query
+// parameters of the order and trade history endpoints
+func parseHistoryParams(query url.Values) (types.HistoryParams, *types.APIError) {
+	params := types.HistoryParams{
+		Side:   query.Get("side"),
+		Sort:   query.Get("sort"),
+		Limit:  DefaultHistoryLimit,
+		Cursor: query.Get("cursor"),
+	}
+	if params.Side != "" && params.Side != "buy" && params.Side != "sell" {
+		return params, types.NewAPIError(types.ErrCodeInvalidSide, "side must be buy or sell")
+	}
+	if params.Sort != "" && params.Sort != types.SortNewest && params.Sort != types.SortOldest {
+		return params, types.NewAPIError(types.ErrCodeInvalidRequest, "sort must be desc or asc")
+	}
+
+	for _, bound := range []struct {
+		name string
+		dst  *int64
+	}{{"from", &params.From}, {"to", &params.To}} {
+		v := query.Get(bound.name)
+		if v == "" {
+			continue
+		}
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ms < 0 {
+			return params, types.NewAPIError(types.ErrCodeInvalidRequest, bound.name+" must be a Unix time in milliseconds")
+		}
+		*bound.dst = ms
+	}
+	if params.To > 0 && params.From > params.To {
+		return params, types.NewAPIError(types.ErrCodeInvalidRequest, "from must not be after to")
+	}
+
+	if l := query.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			return params, types.NewAPIError(types.ErrCodeInvalidRequest, "limit must be a positive integer")
+		}
+		if limit > MaxHistoryLimit {
+			limit = MaxHistoryLimit
+		}
+		params.Limit = limit
+	}
+	return params, nil
+}
+
+// setNextLink points a Link header at the next page: the request URL with
+// its cursor replaced. Paths are taken as the client sent them, so links
+// keep any namespace prefix.
+func setNextLink(w http.ResponseWriter, r *http.Request, cursor string) {
+	if cursor == "" {
+		return
+	}
+	next, err := url.ParseRequestURI(r.RequestURI)
+	if err != nil {
+		next = &url.URL{Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+	}
+	query := next.Query()
+	query.Set("cursor", cursor)
+	w.Header().Set("Link", "<"+next.Path+"?"+query.Encode()+`>; rel="next"`)
+}
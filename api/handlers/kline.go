@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/api/types"
 	"github.com/openalpha/perp-dex/x/perpetual/keeper"
 )
 
@@ -48,7 +49,7 @@ func (h *KlineHandler) GetKlines(w http.ResponseWriter, r *http.Request) {
 		marketID = r.URL.Query().Get("market_id")
 	}
 	if marketID == "" {
-		http.Error(w, "market_id is required", http.StatusBadRequest)
+		writeError(w, types.ErrCodeMissingField, "market_id is required")
 		return
 	}
 
@@ -126,7 +127,7 @@ func (h *KlineHandler) GetLatestKlines(w http.ResponseWriter, r *http.Request) {
 		marketID = r.URL.Query().Get("market_id")
 	}
 	if marketID == "" {
-		http.Error(w, "market_id is required", http.StatusBadRequest)
+		writeError(w, types.ErrCodeMissingField, "market_id is required")
 		return
 	}
 
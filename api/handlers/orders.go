@@ -1,16 +1,33 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/api/auth"
+	"github.com/openalpha/perp-dex/api/pretrade"
 	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/api/webhook"
+	"github.com/openalpha/perp-dex/pkg/timing"
+	"github.com/openalpha/perp-dex/pkg/validation"
 )
 
 // OrderHandler handles order-related HTTP requests
 type OrderHandler struct {
-	service types.OrderService
+	service  types.OrderService
+	rules    validation.RulesProvider
+	schedule types.TradingScheduleService
+	events   types.AccountEventPublisher
+	intents  *auth.IntentVerifier
+	keys     types.CredentialObserver
+	rejects  types.OrderRejectObserver
+	checks   *pretrade.Checker
 }
 
 // NewOrderHandler creates a new order handler
@@ -18,6 +35,55 @@ func NewOrderHandler(service types.OrderService) *OrderHandler {
 	return &OrderHandler{service: service}
 }
 
+// WithMarketRules enables per-market tick/lot/size checks before orders reach the service.
+// Price deviation is left to the keeper, which has access to the mark price.
+func (h *OrderHandler) WithMarketRules(rules validation.RulesProvider) *OrderHandler {
+	h.rules = rules
+	return h
+}
+
+// WithTradingSchedule rejects new and modified orders while a market's trading
+// schedule halts it. Cancels are always accepted.
+func (h *OrderHandler) WithTradingSchedule(schedule types.TradingScheduleService) *OrderHandler {
+	h.schedule = schedule
+	return h
+}
+
+// WithAccountEvents publishes fill events to the taker and makers of every
+// trade matched by placed or modified orders
+func (h *OrderHandler) WithAccountEvents(events types.AccountEventPublisher) *OrderHandler {
+	h.events = events
+	return h
+}
+
+// WithSignedIntents accepts wallet-signed orders on POST /v1/orders/signed,
+// verified and protected against replay by intents
+func (h *OrderHandler) WithSignedIntents(intents *auth.IntentVerifier) *OrderHandler {
+	h.intents = intents
+	return h
+}
+
+// WithCredentialObserver reports the X-API-Key of every order submission, and
+// the trader it was for, to keys
+func (h *OrderHandler) WithCredentialObserver(keys types.CredentialObserver) *OrderHandler {
+	h.keys = keys
+	return h
+}
+
+// WithRejectObserver reports every rejected order placement and amendment,
+// with its reject reason, to rejects
+func (h *OrderHandler) WithRejectObserver(rejects types.OrderRejectObserver) *OrderHandler {
+	h.rejects = rejects
+	return h
+}
+
+// WithPreTradeChecks rejects new orders past the size, notional, price
+// collar or duplicate limits of checks before they reach the service
+func (h *OrderHandler) WithPreTradeChecks(checks *pretrade.Checker) *OrderHandler {
+	h.checks = checks
+	return h
+}
+
 // HandleOrders handles /v1/orders endpoint (GET for list, POST for create)
 func (h *OrderHandler) HandleOrders(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -28,7 +94,7 @@ func (h *OrderHandler) HandleOrders(w http.ResponseWriter, r *http.Request) {
 	case http.MethodOptions:
 		w.WriteHeader(http.StatusOK)
 	default:
-		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
 	}
 }
 
@@ -38,12 +104,16 @@ func (h *OrderHandler) HandleOrder(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 	prefix := "/v1/orders/"
 	if !strings.HasPrefix(path, prefix) {
-		writeError(w, http.StatusBadRequest, "invalid_path", "Invalid path")
+		writeError(w, types.ErrCodeInvalidPath, "Invalid path")
 		return
 	}
 	orderID := strings.TrimPrefix(path, prefix)
 	if orderID == "" {
-		writeError(w, http.StatusBadRequest, "missing_order_id", "Order ID is required")
+		writeError(w, types.ErrCodeMissingField, "Order ID is required")
+		return
+	}
+	if id, ok := strings.CutSuffix(orderID, "/executions"); ok && id != "" {
+		h.orderExecutions(w, r, id)
 		return
 	}
 
@@ -57,56 +127,275 @@ func (h *OrderHandler) HandleOrder(w http.ResponseWriter, r *http.Request) {
 	case http.MethodOptions:
 		w.WriteHeader(http.StatusOK)
 	default:
-		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
 	}
 }
 
 // placeOrder handles POST /v1/orders
 func (h *OrderHandler) placeOrder(w http.ResponseWriter, r *http.Request) {
+	rec := timing.FromContext(r.Context())
+	start := time.Now()
 	var req types.PlaceOrderRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		h.rejectOrder(w, &types.OrderReject{Trader: r.Header.Get("X-Trader-Address"), Action: types.RejectActionPlace},
+			types.NewAPIError(types.ErrCodeInvalidJSON, "Invalid JSON body"))
+		return
+	}
+	rec.Since(timing.StageDecode, start)
+
+	// Get trader from header or body
+	if req.Trader == "" {
+		req.Trader = r.Header.Get("X-Trader-Address")
+	}
+	h.submitOrder(w, r, &req)
+}
+
+// HandleSignedOrder handles POST /v1/orders/signed: an order signed with the
+// trader's wallet instead of attributed by X-Trader-Address
+func (h *OrderHandler) HandleSignedOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.intents == nil {
+		writeError(w, types.ErrCodeNotImplemented, "Signed orders are not enabled")
+		return
+	}
+
+	rec := timing.FromContext(r.Context())
+	start := time.Now()
+	var req types.SignedOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+		return
+	}
+	if req.Payload == "" || req.PubKey == "" || req.Signature == "" {
+		writeError(w, types.ErrCodeMissingField, "payload, pub_key and signature are required")
+		return
+	}
+	payload, err := base64.StdEncoding.DecodeString(req.Payload)
+	if err != nil {
+		writeError(w, types.ErrCodeInvalidRequest, "payload must be base64")
+		return
+	}
+	pubKey, err := base64.StdEncoding.DecodeString(req.PubKey)
+	if err != nil {
+		writeError(w, types.ErrCodeInvalidRequest, "pub_key must be base64")
+		return
+	}
+	signature, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil {
+		writeError(w, types.ErrCodeInvalidRequest, "signature must be base64")
+		return
+	}
+	var intent types.OrderIntent
+	if err := json.Unmarshal(payload, &intent); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "payload must be an order intent")
 		return
 	}
+	if intent.Trader == "" || intent.Nonce == "" || intent.ExpiresAt == 0 {
+		writeError(w, types.ErrCodeMissingField, "trader, nonce and expires_at are required in the intent")
+		return
+	}
+	rec.Since(timing.StageDecode, start)
+
+	start = time.Now()
+	if err := h.intents.Verify(intent.Trader, intent.Nonce, intent.ExpiresAt, payload, pubKey, signature); err != nil {
+		writeServiceError(w, err, types.ErrCodeUnauthenticated)
+		return
+	}
+	rec.Since(timing.StageValidate, start)
+
+	h.submitOrder(w, r, &intent.PlaceOrderRequest)
+}
+
+// submitOrder validates a decoded order for its trader, places it and writes
+// the response
+func (h *OrderHandler) submitOrder(w http.ResponseWriter, r *http.Request, req *types.PlaceOrderRequest) {
+	rec := timing.FromContext(r.Context())
+	start := time.Now()
+	reject := func(apiErr *types.APIError) {
+		h.rejectOrder(w, &types.OrderReject{Trader: req.Trader, MarketID: req.MarketID, Action: types.RejectActionPlace}, apiErr)
+	}
 
 	// Validate required fields
 	if req.MarketID == "" {
-		writeError(w, http.StatusBadRequest, "missing_market_id", "market_id is required")
+		reject(types.NewAPIError(types.ErrCodeMissingField, "market_id is required"))
 		return
 	}
 	if req.Side == "" {
-		writeError(w, http.StatusBadRequest, "missing_side", "side is required")
+		reject(types.NewAPIError(types.ErrCodeMissingField, "side is required"))
 		return
 	}
 	if req.Type == "" {
-		writeError(w, http.StatusBadRequest, "missing_type", "type is required")
+		reject(types.NewAPIError(types.ErrCodeMissingField, "type is required"))
 		return
 	}
 	if req.Quantity == "" {
-		writeError(w, http.StatusBadRequest, "missing_quantity", "quantity is required")
+		reject(types.NewAPIError(types.ErrCodeMissingField, "quantity is required"))
 		return
 	}
 	if req.Type == "limit" && req.Price == "" {
-		writeError(w, http.StatusBadRequest, "missing_price", "price is required for limit orders")
+		reject(types.NewAPIError(types.ErrCodeMissingField, "price is required for limit orders"))
 		return
 	}
-
-	// Get trader from header or body
 	if req.Trader == "" {
-		req.Trader = r.Header.Get("X-Trader-Address")
+		reject(types.NewAPIError(types.ErrCodeMissingField, "trader address is required"))
+		return
 	}
-	if req.Trader == "" {
-		writeError(w, http.StatusBadRequest, "missing_trader", "trader address is required")
+	if h.keys != nil {
+		h.keys.ObserveCredential(r.Header.Get("X-API-Key"), req.Trader)
+	}
+
+	if err := validateTimeInForce(req); err != nil {
+		reject(err)
+		return
+	}
+	if err := validateMaxSlippage(req); err != nil {
+		reject(err)
+		return
+	}
+	if err := h.validatePlaceOrder(req); err != nil {
+		reject(types.ToAPIError(err, types.ErrCodeInvalidRequest))
+		return
+	}
+	if err := h.checkPreTrade(req); err != nil {
+		reject(types.ToAPIError(err, types.ErrCodeInvalidRequest))
 		return
 	}
+	if err := h.checkTradingAllowed(r, req.MarketID); err != nil {
+		reject(err)
+		return
+	}
+	rec.Since(timing.StageValidate, start)
 
-	resp, err := h.service.PlaceOrder(r.Context(), &req)
+	resp, err := h.service.PlaceOrder(r.Context(), req)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "place_order_failed", err.Error())
+		reject(types.ToAPIError(err, types.ErrCodeInvalidRequest))
 		return
 	}
+	h.publishFills(resp.Order, resp.Match)
+
+	// Encode before writing so the encode time makes the debug header
+	start = time.Now()
+	var body bytes.Buffer
+	_ = json.NewEncoder(&body).Encode(resp)
+	rec.Since(timing.StageEncode, start)
+	if rec.Debug() {
+		w.Header().Set(timing.Header, rec.String())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body.Bytes())
+}
+
+// validateTimeInForce checks that GTD orders are limit orders with an expiry
+// in the future, and that only GTD orders carry an expiry
+func validateTimeInForce(req *types.PlaceOrderRequest) *types.APIError {
+	switch req.TimeInForce {
+	case "", types.TimeInForceGTC:
+		if req.ExpireAt != 0 {
+			return types.NewAPIError(types.ErrCodeInvalidRequest, "expire_at requires time_in_force GTD")
+		}
+	case types.TimeInForceGTD:
+		if req.Type != "limit" {
+			return types.NewAPIError(types.ErrCodeInvalidOrderType, "GTD orders must be limit orders")
+		}
+		if req.ExpireAt == 0 {
+			return types.NewAPIError(types.ErrCodeMissingField, "expire_at is required for GTD orders")
+		}
+		if req.ExpireAt <= time.Now().UnixMilli() {
+			return types.NewAPIError(types.ErrCodeInvalidRequest, "expire_at must be in the future")
+		}
+	default:
+		return types.NewAPIError(types.ErrCodeInvalidRequest, "time_in_force must be GTC or GTD")
+	}
+	return nil
+}
 
-	writeJSON(w, http.StatusCreated, resp)
+// validateMaxSlippage checks that only market orders carry a slippage limit
+// and that it is a fraction of the mark price below 1
+func validateMaxSlippage(req *types.PlaceOrderRequest) *types.APIError {
+	if req.MaxSlippage == "" {
+		return nil
+	}
+	if req.Type != "market" {
+		return types.NewAPIError(types.ErrCodeInvalidRequest, "max_slippage only applies to market orders")
+	}
+	slippage, err := math.LegacyNewDecFromStr(req.MaxSlippage)
+	if err != nil || slippage.IsNegative() || slippage.GTE(math.LegacyOneDec()) {
+		return types.NewAPIError(types.ErrCodeInvalidRequest, "max_slippage must be a decimal in [0, 1)")
+	}
+	return nil
+}
+
+// validatePlaceOrder checks decimal format and, when market rules are configured,
+// tick size, lot size, order size bounds and minimum notional
+func (h *OrderHandler) validatePlaceOrder(req *types.PlaceOrderRequest) error {
+	quantity, err := validation.ParsePositiveDecimal("quantity", req.Quantity)
+	if err != nil {
+		return err
+	}
+
+	// Market orders ignore any supplied price (clients commonly send "0")
+	isMarket := req.Type == "market"
+	var price math.LegacyDec
+	if !isMarket {
+		if price, err = validation.ParsePositiveDecimal("price", req.Price); err != nil {
+			return err
+		}
+	}
+
+	if h.rules == nil {
+		return nil
+	}
+	rules, ok := h.rules.MarketRules(req.MarketID)
+	if !ok {
+		return nil
+	}
+	return validation.ValidateOrder(rules, validation.OrderInput{
+		Price:    price,
+		Quantity: quantity,
+		IsMarket: isMarket,
+	})
+}
+
+// checkPreTrade runs the pre-trade checks, if enabled, on an order that
+// passed validatePlaceOrder
+func (h *OrderHandler) checkPreTrade(req *types.PlaceOrderRequest) error {
+	if h.checks == nil {
+		return nil
+	}
+	quantity, _ := strconv.ParseFloat(req.Quantity, 64)
+	var price float64
+	if req.Type != "market" {
+		price, _ = strconv.ParseFloat(req.Price, 64)
+	}
+	return h.checks.Check(pretrade.Order{
+		Trader:   req.Trader,
+		MarketID: req.MarketID,
+		Side:     req.Side,
+		Type:     req.Type,
+		Price:    price,
+		Quantity: quantity,
+	})
+}
+
+// checkTradingAllowed returns the halt error if the market's trading schedule
+// rejects new orders. Status lookup failures are left to the service.
+func (h *OrderHandler) checkTradingAllowed(r *http.Request, marketID string) *types.APIError {
+	if h.schedule == nil {
+		return nil
+	}
+	status, err := h.schedule.GetTradingStatus(r.Context(), marketID)
+	if err != nil {
+		return nil
+	}
+	return status.Err()
 }
 
 // cancelOrder handles DELETE /v1/orders/{id}
@@ -117,19 +406,13 @@ func (h *OrderHandler) cancelOrder(w http.ResponseWriter, r *http.Request, order
 		trader = r.URL.Query().Get("trader")
 	}
 	if trader == "" {
-		writeError(w, http.StatusBadRequest, "missing_trader", "trader address is required")
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
 		return
 	}
 
 	resp, err := h.service.CancelOrder(r.Context(), trader, orderID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, "order_not_found", err.Error())
-		} else if strings.Contains(err.Error(), "unauthorized") {
-			writeError(w, http.StatusForbidden, "unauthorized", err.Error())
-		} else {
-			writeError(w, http.StatusBadRequest, "cancel_order_failed", err.Error())
-		}
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -138,81 +421,196 @@ func (h *OrderHandler) cancelOrder(w http.ResponseWriter, r *http.Request, order
 
 // modifyOrder handles PUT /v1/orders/{id}
 func (h *OrderHandler) modifyOrder(w http.ResponseWriter, r *http.Request, orderID string) {
+	trader := r.Header.Get("X-Trader-Address")
+	marketID := ""
+	reject := func(apiErr *types.APIError) {
+		h.rejectOrder(w, &types.OrderReject{Trader: trader, MarketID: marketID, OrderID: orderID, Action: types.RejectActionModify}, apiErr)
+	}
+
 	var req types.ModifyOrderRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		reject(types.NewAPIError(types.ErrCodeInvalidJSON, "Invalid JSON body"))
 		return
 	}
 
 	if req.Price == "" && req.Quantity == "" {
-		writeError(w, http.StatusBadRequest, "missing_fields", "at least one of price or quantity is required")
+		reject(types.NewAPIError(types.ErrCodeMissingField, "at least one of price or quantity is required"))
 		return
 	}
 
-	trader := r.Header.Get("X-Trader-Address")
 	if trader == "" {
-		writeError(w, http.StatusBadRequest, "missing_trader", "trader address is required")
+		reject(types.NewAPIError(types.ErrCodeMissingField, "trader address is required"))
 		return
 	}
 
+	// The market is not known here, so only decimal format is checked;
+	// market rules are enforced by the keeper on the amended order.
+	if req.Price != "" {
+		if _, err := validation.ParsePositiveDecimal("price", req.Price); err != nil {
+			reject(types.ToAPIError(err, types.ErrCodeInvalidPrice))
+			return
+		}
+	}
+	if req.Quantity != "" {
+		if _, err := validation.ParsePositiveDecimal("quantity", req.Quantity); err != nil {
+			reject(types.ToAPIError(err, types.ErrCodeInvalidQuantity))
+			return
+		}
+	}
+
+	if h.schedule != nil {
+		order, err := h.service.GetOrder(r.Context(), orderID)
+		if err != nil {
+			writeServiceError(w, err, types.ErrCodeOrderNotFound)
+			return
+		}
+		marketID = order.MarketID
+		if err := h.checkTradingAllowed(r, order.MarketID); err != nil {
+			reject(err)
+			return
+		}
+	}
+
 	resp, err := h.service.ModifyOrder(r.Context(), trader, orderID, &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, "order_not_found", err.Error())
-		} else if strings.Contains(err.Error(), "unauthorized") {
-			writeError(w, http.StatusForbidden, "unauthorized", err.Error())
-		} else {
-			writeError(w, http.StatusBadRequest, "modify_order_failed", err.Error())
-		}
+		reject(types.ToAPIError(err, types.ErrCodeInvalidRequest))
 		return
 	}
+	h.publishFills(resp.Order, resp.Match)
 
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// publishFills sends a fill event for each trade to the taker and, when the
+// service reports it, the maker
+func (h *OrderHandler) publishFills(order *types.Order, match *types.MatchResult) {
+	if h.events == nil || order == nil || match == nil {
+		return
+	}
+	for _, t := range match.Trades {
+		h.events.PublishAccountEvent(order.Trader, webhook.EventFill, map[string]interface{}{
+			"trade_id":  t.TradeID,
+			"order_id":  order.OrderID,
+			"market_id": order.MarketID,
+			"side":      order.Side,
+			"price":     t.Price,
+			"quantity":  t.Quantity,
+			"liquidity": "taker",
+			"timestamp": t.Timestamp,
+		})
+		if t.Maker == "" {
+			continue
+		}
+		makerSide := "sell"
+		if order.Side == "sell" {
+			makerSide = "buy"
+		}
+		h.events.PublishAccountEvent(t.Maker, webhook.EventFill, map[string]interface{}{
+			"trade_id":  t.TradeID,
+			"order_id":  t.MakerOrderID,
+			"market_id": order.MarketID,
+			"side":      makerSide,
+			"price":     t.Price,
+			"quantity":  t.Quantity,
+			"liquidity": "maker",
+			"timestamp": t.Timestamp,
+		})
+	}
+}
+
 // getOrder handles GET /v1/orders/{id}
 func (h *OrderHandler) getOrder(w http.ResponseWriter, r *http.Request, orderID string) {
 	order, err := h.service.GetOrder(r.Context(), orderID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "order_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodeOrderNotFound)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{"order": order})
 }
 
+// orderExecutions handles GET /v1/orders/{id}/executions: the order's fills,
+// oldest first, with its cumulative and remaining quantity after each
+func (h *OrderHandler) orderExecutions(w http.ResponseWriter, r *http.Request, orderID string) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	svc, ok := h.service.(types.ExecutionService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Order executions are not available")
+		return
+	}
+
+	resp, err := svc.GetOrderExecutions(r.Context(), orderID)
+	if err != nil {
+		writeServiceError(w, err, types.ErrCodeOrderNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 // listOrders handles GET /v1/orders
 func (h *OrderHandler) listOrders(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
-
+	params, apiErr := parseHistoryParams(query)
+	if apiErr != nil {
+		writeAPIError(w, apiErr)
+		return
+	}
 	req := &types.ListOrdersRequest{
-		Trader:   query.Get("trader"),
-		MarketID: query.Get("market_id"),
-		Status:   query.Get("status"),
-		Cursor:   query.Get("cursor"),
-	}
-
-	// Parse limit
-	limitStr := query.Get("limit")
-	if limitStr != "" {
-		var limit int
-		if _, err := json.Number(limitStr).Int64(); err == nil {
-			limit = 100 // default
-		}
-		req.Limit = limit
+		Trader:        query.Get("trader"),
+		MarketID:      query.Get("market_id"),
+		Status:        query.Get("status"),
+		HistoryParams: params,
 	}
 
 	// Require trader for listing orders
 	if req.Trader == "" {
 		req.Trader = r.Header.Get("X-Trader-Address")
 	}
+	if req.Trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
 
 	resp, err := h.service.ListOrders(r.Context(), req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "list_orders_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInternal)
 		return
 	}
 
+	setNextLink(w, r, resp.NextCursor)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// TradeHistory handles GET /v1/accounts/{trader}/trades and
+// /v1/markets/{id}/trades for services that implement types.TradeHistoryService
+func (h *OrderHandler) TradeHistory(w http.ResponseWriter, r *http.Request, trader, marketID string) {
+	svc, ok := h.service.(types.TradeHistoryService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Trade history is not available")
+		return
+	}
+
+	query := r.URL.Query()
+	params, apiErr := parseHistoryParams(query)
+	if apiErr != nil {
+		writeAPIError(w, apiErr)
+		return
+	}
+	req := &types.ListTradesRequest{Trader: trader, MarketID: marketID, HistoryParams: params}
+	if req.MarketID == "" {
+		req.MarketID = query.Get("market_id")
+	}
+
+	resp, err := svc.ListTrades(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, err, types.ErrCodeInternal)
+		return
+	}
+
+	setNextLink(w, r, resp.NextCursor)
 	writeJSON(w, http.StatusOK, resp)
 }
 
@@ -224,11 +622,39 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
-func writeError(w http.ResponseWriter, status int, code, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error":   code,
-		"message": message,
-	})
+// writeError writes the unified error envelope for a typed error code
+func writeError(w http.ResponseWriter, code types.ErrorCode, message string) {
+	writeAPIError(w, types.NewAPIError(code, message))
+}
+
+// rejectOrder writes an order placement or amendment rejection tagged with
+// its reject reason, and reports it to the reject observer
+func (h *OrderHandler) rejectOrder(w http.ResponseWriter, reject *types.OrderReject, apiErr *types.APIError) {
+	apiErr.RejectReason = types.RejectReasonOf(apiErr.Code)
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = w.Header().Get(types.RequestIDHeader)
+	}
+	if h.rejects != nil {
+		reject.Code = apiErr.Code
+		reject.Reason = apiErr.RejectReason
+		reject.Message = apiErr.Message
+		reject.RequestID = apiErr.RequestID
+		reject.Timestamp = time.Now().UnixMilli()
+		h.rejects.ObserveOrderReject(reject)
+	}
+	writeAPIError(w, apiErr)
+}
+
+// writeServiceError classifies a service/keeper error and writes it.
+// fallback is used when the error does not map to a known code.
+func writeServiceError(w http.ResponseWriter, err error, fallback types.ErrorCode) {
+	writeAPIError(w, types.ToAPIError(err, fallback))
+}
+
+// writeAPIError writes an APIError, tagging it with the request ID set by middleware
+func writeAPIError(w http.ResponseWriter, apiErr *types.APIError) {
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = w.Header().Get(types.RequestIDHeader)
+	}
+	writeJSON(w, apiErr.Code.HTTPStatus(), types.NewErrorResponse(apiErr))
 }
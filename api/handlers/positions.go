@@ -3,19 +3,29 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/openalpha/perp-dex/api/types"
 )
 
+// Position history page sizes
+const (
+	DefaultPositionHistoryLimit = 50
+	MaxPositionHistoryLimit     = 500
+)
+
 // PositionHandler handles position-related HTTP requests
 type PositionHandler struct {
 	service types.PositionService
+	adl     types.ADLService // nil when the service cannot rank ADL queues
 }
 
-// NewPositionHandler creates a new position handler
+// NewPositionHandler creates a new position handler. ADL indicators are
+// served when the service also implements types.ADLService.
 func NewPositionHandler(service types.PositionService) *PositionHandler {
-	return &PositionHandler{service: service}
+	adl, _ := service.(types.ADLService)
+	return &PositionHandler{service: service, adl: adl}
 }
 
 // HandlePositions handles /v1/positions endpoint (GET for list)
@@ -26,22 +36,22 @@ func (h *PositionHandler) HandlePositions(w http.ResponseWriter, r *http.Request
 	case http.MethodOptions:
 		w.WriteHeader(http.StatusOK)
 	default:
-		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
 	}
 }
 
-// HandlePosition handles /v1/positions/{marketID} endpoint (GET)
+// HandlePosition handles /v1/positions/{marketID} and /v1/positions/{marketID}/adl (GET)
 func (h *PositionHandler) HandlePosition(w http.ResponseWriter, r *http.Request) {
 	// Extract market ID from path
 	path := r.URL.Path
 	prefix := "/v1/positions/"
 	if !strings.HasPrefix(path, prefix) {
-		writeError(w, http.StatusBadRequest, "invalid_path", "Invalid path")
+		writeError(w, types.ErrCodeInvalidPath, "Invalid path")
 		return
 	}
 	marketID := strings.TrimPrefix(path, prefix)
 	if marketID == "" {
-		writeError(w, http.StatusBadRequest, "missing_market_id", "Market ID is required")
+		writeError(w, types.ErrCodeMissingField, "Market ID is required")
 		return
 	}
 
@@ -51,13 +61,20 @@ func (h *PositionHandler) HandlePosition(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	adl := strings.HasSuffix(marketID, "/adl")
+	marketID = strings.TrimSuffix(marketID, "/adl")
+
 	switch r.Method {
 	case http.MethodGet:
+		if adl {
+			h.getADLIndicator(w, r, marketID)
+			return
+		}
 		h.getPosition(w, r, marketID)
 	case http.MethodOptions:
 		w.WriteHeader(http.StatusOK)
 	default:
-		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
 	}
 }
 
@@ -68,7 +85,7 @@ func (h *PositionHandler) HandleClosePosition(w http.ResponseWriter, r *http.Req
 		return
 	}
 	if r.Method != http.MethodPost {
-		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 	h.closePosition(w, r)
@@ -83,7 +100,7 @@ func (h *PositionHandler) listPositions(w http.ResponseWriter, r *http.Request)
 
 	positions, err := h.service.GetPositions(r.Context(), trader)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "list_positions_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInternal)
 		return
 	}
 
@@ -100,34 +117,59 @@ func (h *PositionHandler) getPosition(w http.ResponseWriter, r *http.Request, ma
 		trader = r.Header.Get("X-Trader-Address")
 	}
 	if trader == "" {
-		writeError(w, http.StatusBadRequest, "missing_trader", "trader address is required")
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
 		return
 	}
 
 	position, err := h.service.GetPosition(r.Context(), trader, marketID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, "position_not_found", err.Error())
-		} else {
-			writeError(w, http.StatusInternalServerError, "get_position_failed", err.Error())
-		}
+		writeServiceError(w, err, types.ErrCodeInternal)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{"position": position})
 }
 
+// getADLIndicator handles GET /v1/positions/{marketID}/adl
+func (h *PositionHandler) getADLIndicator(w http.ResponseWriter, r *http.Request, marketID string) {
+	trader := r.URL.Query().Get("trader")
+	if trader == "" {
+		trader = r.Header.Get("X-Trader-Address")
+	}
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+	if h.adl == nil {
+		writeError(w, types.ErrCodeNotImplemented, "ADL indicators are not available from this node")
+		return
+	}
+
+	indicators, err := h.adl.GetADLIndicators(r.Context(), marketID)
+	if err != nil {
+		writeServiceError(w, err, types.ErrCodeInternal)
+		return
+	}
+	for _, indicator := range indicators {
+		if indicator.Trader == trader {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"adl": indicator})
+			return
+		}
+	}
+	writeError(w, types.ErrCodePositionNotFound, "position not found")
+}
+
 // closePosition handles POST /v1/positions/close
 func (h *PositionHandler) closePosition(w http.ResponseWriter, r *http.Request) {
 	var req types.ClosePositionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	// Validate required fields
 	if req.MarketID == "" {
-		writeError(w, http.StatusBadRequest, "missing_market_id", "market_id is required")
+		writeError(w, types.ErrCodeMissingField, "market_id is required")
 		return
 	}
 
@@ -136,17 +178,49 @@ func (h *PositionHandler) closePosition(w http.ResponseWriter, r *http.Request)
 		req.Trader = r.Header.Get("X-Trader-Address")
 	}
 	if req.Trader == "" {
-		writeError(w, http.StatusBadRequest, "missing_trader", "trader address is required")
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
 		return
 	}
 
 	resp, err := h.service.ClosePosition(r.Context(), &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, "position_not_found", err.Error())
-		} else {
-			writeError(w, http.StatusBadRequest, "close_position_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// PositionHistory handles GET /v1/accounts/{trader}/positions/history
+// Query params: market_id (optional filter), limit, cursor
+func (h *PositionHandler) PositionHistory(w http.ResponseWriter, r *http.Request, trader string) {
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+
+	query := r.URL.Query()
+	req := &types.PositionHistoryRequest{
+		Trader:   trader,
+		MarketID: query.Get("market_id"),
+		Limit:    DefaultPositionHistoryLimit,
+		Cursor:   query.Get("cursor"),
+	}
+	if l := query.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			writeError(w, types.ErrCodeInvalidRequest, "limit must be a positive integer")
+			return
 		}
+		if limit > MaxPositionHistoryLimit {
+			limit = MaxPositionHistoryLimit
+		}
+		req.Limit = limit
+	}
+
+	resp, err := h.service.GetPositionHistory(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, err, types.ErrCodeInternal)
 		return
 	}
 
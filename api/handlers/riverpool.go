@@ -7,6 +7,7 @@ import (
 
 	"cosmossdk.io/math"
 	"github.com/gorilla/mux"
+	apitypes "github.com/openalpha/perp-dex/api/types"
 	"github.com/openalpha/perp-dex/x/riverpool/keeper"
 	"github.com/openalpha/perp-dex/x/riverpool/types"
 )
@@ -38,6 +39,7 @@ func (h *RiverpoolHandler) RegisterRoutes(r *mux.Router) {
 	r.HandleFunc("/v1/riverpool/pools/{poolId}/stats", h.GetPoolStats).Methods("GET")
 	r.HandleFunc("/v1/riverpool/pools/{poolId}/nav/history", h.GetNAVHistory).Methods("GET")
 	r.HandleFunc("/v1/riverpool/pools/{poolId}/ddguard", h.GetDDGuardState).Methods("GET")
+	r.HandleFunc("/v1/riverpool/pools/{poolId}/allocation", h.GetPoolAllocation).Methods("GET")
 
 	// User routes
 	r.HandleFunc("/v1/riverpool/user/{user}/deposits", h.GetUserDeposits).Methods("GET")
@@ -170,7 +172,7 @@ func (h *RiverpoolHandler) GetPools(w http.ResponseWriter, r *http.Request) {
 
 	pools, total, err := h.queryServer.Pools(ctx, offset, limit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -194,7 +196,7 @@ func (h *RiverpoolHandler) GetPool(w http.ResponseWriter, r *http.Request) {
 
 	pool, err := h.queryServer.Pool(ctx, poolID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeServiceError(w, err, apitypes.ErrCodeNotFound)
 		return
 	}
 
@@ -210,7 +212,7 @@ func (h *RiverpoolHandler) GetPoolsByType(w http.ResponseWriter, r *http.Request
 
 	pools, err := h.queryServer.PoolsByType(ctx, poolType)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -233,7 +235,7 @@ func (h *RiverpoolHandler) GetPoolStats(w http.ResponseWriter, r *http.Request)
 
 	stats, err := h.queryServer.PoolStats(ctx, poolID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -265,7 +267,7 @@ func (h *RiverpoolHandler) GetNAVHistory(w http.ResponseWriter, r *http.Request)
 
 	history, err := h.queryServer.NAVHistory(ctx, poolID, fromTime, toTime)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -277,6 +279,10 @@ func (h *RiverpoolHandler) GetNAVHistory(w http.ResponseWriter, r *http.Request)
 			"total_value": h.TotalValue.String(),
 			"timestamp":   h.Timestamp,
 		}
+		if !h.AllocatedValue.IsNil() && h.AllocatedValue.IsPositive() {
+			response[i]["cash"] = h.Cash.String()
+			response[i]["allocated_value"] = h.AllocatedValue.String()
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -293,7 +299,7 @@ func (h *RiverpoolHandler) GetDDGuardState(w http.ResponseWriter, r *http.Reques
 
 	state, err := h.queryServer.DDGuardState(ctx, poolID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -310,6 +316,27 @@ func (h *RiverpoolHandler) GetDDGuardState(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+// GetPoolAllocation returns the look-through NAV and allocation strategy of a pool
+func (h *RiverpoolHandler) GetPoolAllocation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	poolID := vars["poolId"]
+
+	lookThrough, err := h.queryServer.LookThroughNAV(ctx, poolID)
+	if err != nil {
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
+		return
+	}
+	// Pools without a strategy report their look-through NAV with no strategy
+	strategy, _ := h.queryServer.AllocationStrategy(ctx, poolID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"look_through": lookThrough,
+		"strategy":     strategy,
+	})
+}
+
 // GetUserDeposits returns all deposits for a user
 func (h *RiverpoolHandler) GetUserDeposits(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -318,7 +345,7 @@ func (h *RiverpoolHandler) GetUserDeposits(w http.ResponseWriter, r *http.Reques
 
 	deposits, totalValue, err := h.queryServer.UserDeposits(ctx, user)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -353,7 +380,7 @@ func (h *RiverpoolHandler) GetUserWithdrawals(w http.ResponseWriter, r *http.Req
 
 	withdrawals, err := h.queryServer.UserWithdrawals(ctx, user)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -390,7 +417,7 @@ func (h *RiverpoolHandler) GetUserPoolBalance(w http.ResponseWriter, r *http.Req
 
 	shares, value, costBasis, unrealizedPnL, pnlPercent, unlockAt, canWithdraw, err := h.queryServer.UserPoolBalance(ctx, poolID, user)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -420,7 +447,7 @@ func (h *RiverpoolHandler) GetPoolDeposits(w http.ResponseWriter, r *http.Reques
 
 	deposits, total, err := h.queryServer.PoolDeposits(ctx, poolID, offset, limit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -453,7 +480,7 @@ func (h *RiverpoolHandler) GetPendingWithdrawals(w http.ResponseWriter, r *http.
 
 	withdrawals, totalPendingShares, totalPendingValue, dailyLimitRemaining, err := h.queryServer.PendingWithdrawals(ctx, poolID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -491,13 +518,13 @@ func (h *RiverpoolHandler) EstimateDeposit(w http.ResponseWriter, r *http.Reques
 
 	amount, err := math.LegacyNewDecFromStr(amountStr)
 	if err != nil {
-		http.Error(w, "Invalid amount", http.StatusBadRequest)
+		writeError(w, apitypes.ErrCodeInvalidQuantity, "Invalid amount")
 		return
 	}
 
 	shares, nav, sharePrice, err := h.queryServer.EstimateDeposit(ctx, poolID, amount)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -518,13 +545,13 @@ func (h *RiverpoolHandler) EstimateWithdrawal(w http.ResponseWriter, r *http.Req
 
 	shares, err := math.LegacyNewDecFromStr(sharesStr)
 	if err != nil {
-		http.Error(w, "Invalid shares", http.StatusBadRequest)
+		writeError(w, apitypes.ErrCodeInvalidQuantity, "Invalid shares")
 		return
 	}
 
 	amount, nav, availableAt, queuePosition, mayBeProrated, err := h.queryServer.EstimateWithdrawal(ctx, poolID, shares)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -552,7 +579,7 @@ func (h *RiverpoolHandler) Deposit(w http.ResponseWriter, r *http.Request) {
 
 	var req DepositRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, apitypes.ErrCodeInvalidJSON, "Invalid request body")
 		return
 	}
 
@@ -565,7 +592,7 @@ func (h *RiverpoolHandler) Deposit(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.msgServer.Deposit(ctx, msg)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, err, apitypes.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -586,7 +613,7 @@ func (h *RiverpoolHandler) RequestWithdrawal(w http.ResponseWriter, r *http.Requ
 
 	var req WithdrawalRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, apitypes.ErrCodeInvalidJSON, "Invalid request body")
 		return
 	}
 
@@ -598,7 +625,7 @@ func (h *RiverpoolHandler) RequestWithdrawal(w http.ResponseWriter, r *http.Requ
 
 	resp, err := h.msgServer.RequestWithdrawal(ctx, msg)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, err, apitypes.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -618,7 +645,7 @@ func (h *RiverpoolHandler) ClaimWithdrawal(w http.ResponseWriter, r *http.Reques
 
 	var req ClaimWithdrawalRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, apitypes.ErrCodeInvalidJSON, "Invalid request body")
 		return
 	}
 
@@ -629,7 +656,7 @@ func (h *RiverpoolHandler) ClaimWithdrawal(w http.ResponseWriter, r *http.Reques
 
 	resp, err := h.msgServer.ClaimWithdrawal(ctx, msg)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, err, apitypes.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -649,7 +676,7 @@ func (h *RiverpoolHandler) CancelWithdrawal(w http.ResponseWriter, r *http.Reque
 
 	var req CancelWithdrawalRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, apitypes.ErrCodeInvalidJSON, "Invalid request body")
 		return
 	}
 
@@ -660,7 +687,7 @@ func (h *RiverpoolHandler) CancelWithdrawal(w http.ResponseWriter, r *http.Reque
 
 	resp, err := h.msgServer.CancelWithdrawal(ctx, msg)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, err, apitypes.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -801,13 +828,13 @@ type CreateCommunityPoolRequest struct {
 func (h *RiverpoolHandler) CreateCommunityPool(w http.ResponseWriter, r *http.Request) {
 	var req CreateCommunityPoolRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		writeError(w, apitypes.ErrCodeInvalidJSON, "invalid request body")
 		return
 	}
 
 	// In standalone API mode, return error
 	// Creating community pools requires sdk.Context and blockchain state
-	http.Error(w, "community pool creation not available in standalone API mode", http.StatusServiceUnavailable)
+	writeError(w, apitypes.ErrCodeServiceUnavailable, "community pool creation not available in standalone API mode")
 }
 
 // PoolHolderResponse represents a holder in API responses
@@ -827,14 +854,14 @@ func (h *RiverpoolHandler) GetPoolHolders(w http.ResponseWriter, r *http.Request
 
 	pool, err := h.queryServer.Pool(ctx, poolID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeServiceError(w, err, apitypes.ErrCodeNotFound)
 		return
 	}
 
 	// Get all deposits for this pool
 	deposits, _, err := h.queryServer.PoolDeposits(ctx, poolID, 0, 1000)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -944,7 +971,7 @@ type DepositOwnerStakeRequest struct {
 func (h *RiverpoolHandler) DepositOwnerStake(w http.ResponseWriter, r *http.Request) {
 	// In standalone API mode, return error
 	// Owner stake operations require sdk.Context and blockchain state
-	http.Error(w, "owner stake deposit not available in standalone API mode", http.StatusServiceUnavailable)
+	writeError(w, apitypes.ErrCodeServiceUnavailable, "owner stake deposit not available in standalone API mode")
 }
 
 // InviteCodeResponse represents an invite code in API responses
@@ -981,7 +1008,7 @@ type GenerateInviteCodeRequest struct {
 // GenerateInviteCode handles POST /v1/riverpool/community/{poolId}/invites
 func (h *RiverpoolHandler) GenerateInviteCode(w http.ResponseWriter, r *http.Request) {
 	// In standalone API mode, return error
-	http.Error(w, "invite code generation not available in standalone API mode", http.StatusServiceUnavailable)
+	writeError(w, apitypes.ErrCodeServiceUnavailable, "invite code generation not available in standalone API mode")
 }
 
 // PoolOwnerRequest represents a request with just owner field
@@ -992,19 +1019,19 @@ type PoolOwnerRequest struct {
 // PausePool handles POST /v1/riverpool/community/{poolId}/pause
 func (h *RiverpoolHandler) PausePool(w http.ResponseWriter, r *http.Request) {
 	// In standalone API mode, return error
-	http.Error(w, "pool pause not available in standalone API mode", http.StatusServiceUnavailable)
+	writeError(w, apitypes.ErrCodeServiceUnavailable, "pool pause not available in standalone API mode")
 }
 
 // ResumePool handles POST /v1/riverpool/community/{poolId}/resume
 // Note: In standalone API mode, pool state modifications require blockchain transactions
 func (h *RiverpoolHandler) ResumePool(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "pool resume not available in standalone API mode", http.StatusServiceUnavailable)
+	writeError(w, apitypes.ErrCodeServiceUnavailable, "pool resume not available in standalone API mode")
 }
 
 // ClosePool handles POST /v1/riverpool/community/{poolId}/close
 // Note: In standalone API mode, pool state modifications require blockchain transactions
 func (h *RiverpoolHandler) ClosePool(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "pool close not available in standalone API mode", http.StatusServiceUnavailable)
+	writeError(w, apitypes.ErrCodeServiceUnavailable, "pool close not available in standalone API mode")
 }
 
 // GetUserOwnedPools handles GET /v1/riverpool/user/{user}/owned-pools
@@ -1016,7 +1043,7 @@ func (h *RiverpoolHandler) GetUserOwnedPools(w http.ResponseWriter, r *http.Requ
 	// Get all community pools and filter by owner
 	pools, _, err := h.queryServer.Pools(ctx, 0, 1000)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, apitypes.ErrCodeInternal)
 		return
 	}
 
@@ -35,7 +35,7 @@ func extractPathParam(path, prefix, suffix string) string {
 func (h *RiverpoolStandaloneHandler) GetPools(w http.ResponseWriter, r *http.Request) {
 	pools, err := h.service.GetPools()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		writeServiceError(w, err, types.ErrCodeInternal)
 		return
 	}
 
@@ -60,7 +60,7 @@ func (h *RiverpoolStandaloneHandler) GetPool(w http.ResponseWriter, r *http.Requ
 
 	pool, err := h.service.GetPool(poolID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -74,7 +74,7 @@ func (h *RiverpoolStandaloneHandler) GetPoolsByType(w http.ResponseWriter, r *ht
 
 	pools, err := h.service.GetPoolsByType(poolType)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		writeServiceError(w, err, types.ErrCodeInternal)
 		return
 	}
 
@@ -95,7 +95,7 @@ func (h *RiverpoolStandaloneHandler) GetPoolStats(w http.ResponseWriter, r *http
 
 	stats, err := h.service.GetPoolStats(poolID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -119,7 +119,7 @@ func (h *RiverpoolStandaloneHandler) GetNAVHistory(w http.ResponseWriter, r *htt
 
 	history, err := h.service.GetNAVHistory(poolID, days)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -140,7 +140,7 @@ func (h *RiverpoolStandaloneHandler) GetDDGuardState(w http.ResponseWriter, r *h
 
 	state, err := h.service.GetDDGuardState(poolID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -160,7 +160,7 @@ func (h *RiverpoolStandaloneHandler) GetUserDeposits(w http.ResponseWriter, r *h
 
 	deposits, err := h.service.GetUserDeposits(user)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		writeServiceError(w, err, types.ErrCodeInternal)
 		return
 	}
 
@@ -181,7 +181,7 @@ func (h *RiverpoolStandaloneHandler) GetUserWithdrawals(w http.ResponseWriter, r
 
 	withdrawals, err := h.service.GetUserWithdrawals(user)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		writeServiceError(w, err, types.ErrCodeInternal)
 		return
 	}
 
@@ -199,7 +199,7 @@ func (h *RiverpoolStandaloneHandler) GetUserPoolBalance(w http.ResponseWriter, r
 	path := strings.TrimPrefix(r.URL.Path, "/v1/riverpool/pools/")
 	parts := strings.Split(path, "/user/")
 	if len(parts) != 2 {
-		writeError(w, http.StatusBadRequest, "invalid_path", "Invalid path format")
+		writeError(w, types.ErrCodeInvalidPath, "Invalid path format")
 		return
 	}
 	poolID := parts[0]
@@ -207,7 +207,7 @@ func (h *RiverpoolStandaloneHandler) GetUserPoolBalance(w http.ResponseWriter, r
 
 	balance, err := h.service.GetUserPoolBalance(poolID, user)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodeNotFound)
 		return
 	}
 
@@ -221,7 +221,7 @@ func (h *RiverpoolStandaloneHandler) GetUserOwnedPools(w http.ResponseWriter, r
 
 	pools, err := h.service.GetUserOwnedPools(user)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		writeServiceError(w, err, types.ErrCodeInternal)
 		return
 	}
 
@@ -255,7 +255,7 @@ func (h *RiverpoolStandaloneHandler) GetPoolDeposits(w http.ResponseWriter, r *h
 
 	deposits, total, err := h.service.GetPoolDeposits(poolID, offset, limit)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -275,7 +275,7 @@ func (h *RiverpoolStandaloneHandler) GetPendingWithdrawals(w http.ResponseWriter
 
 	withdrawals, err := h.service.GetPendingWithdrawals(poolID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -293,19 +293,19 @@ func (h *RiverpoolStandaloneHandler) EstimateDeposit(w http.ResponseWriter, r *h
 
 	amountStr := r.URL.Query().Get("amount")
 	if amountStr == "" {
-		writeError(w, http.StatusBadRequest, "missing_amount", "amount query parameter is required")
+		writeError(w, types.ErrCodeMissingField, "amount query parameter is required")
 		return
 	}
 
 	amount, err := math.LegacyNewDecFromStr(amountStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_amount", "invalid amount format")
+		writeError(w, types.ErrCodeInvalidQuantity, "invalid amount format")
 		return
 	}
 
 	estimate, err := h.service.EstimateDeposit(poolID, amount)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -319,19 +319,19 @@ func (h *RiverpoolStandaloneHandler) EstimateWithdrawal(w http.ResponseWriter, r
 
 	sharesStr := r.URL.Query().Get("shares")
 	if sharesStr == "" {
-		writeError(w, http.StatusBadRequest, "missing_shares", "shares query parameter is required")
+		writeError(w, types.ErrCodeMissingField, "shares query parameter is required")
 		return
 	}
 
 	shares, err := math.LegacyNewDecFromStr(sharesStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_shares", "invalid shares format")
+		writeError(w, types.ErrCodeInvalidQuantity, "invalid shares format")
 		return
 	}
 
 	estimate, err := h.service.EstimateWithdrawal(poolID, shares)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -342,29 +342,30 @@ func (h *RiverpoolStandaloneHandler) EstimateWithdrawal(w http.ResponseWriter, r
 // Deposit handles POST /v1/riverpool/deposit
 func (h *RiverpoolStandaloneHandler) Deposit(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		PoolID string `json:"pool_id"`
-		User   string `json:"user"`
-		Amount string `json:"amount"`
+		PoolID     string `json:"pool_id"`
+		User       string `json:"user"`
+		Amount     string `json:"amount"`
+		InviteCode string `json:"invite_code,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	if req.PoolID == "" || req.User == "" || req.Amount == "" {
-		writeError(w, http.StatusBadRequest, "missing_fields", "pool_id, user, and amount are required")
+		writeError(w, types.ErrCodeMissingField, "pool_id, user, and amount are required")
 		return
 	}
 
 	amount, err := math.LegacyNewDecFromStr(req.Amount)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_amount", "invalid amount format")
+		writeError(w, types.ErrCodeInvalidQuantity, "invalid amount format")
 		return
 	}
 
-	result, err := h.service.Deposit(req.PoolID, req.User, amount)
+	result, err := h.service.Deposit(req.PoolID, req.User, amount, req.InviteCode)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "deposit_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -381,24 +382,24 @@ func (h *RiverpoolStandaloneHandler) RequestWithdrawal(w http.ResponseWriter, r
 		Shares string `json:"shares"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	if req.PoolID == "" || req.User == "" || req.Shares == "" {
-		writeError(w, http.StatusBadRequest, "missing_fields", "pool_id, user, and shares are required")
+		writeError(w, types.ErrCodeMissingField, "pool_id, user, and shares are required")
 		return
 	}
 
 	shares, err := math.LegacyNewDecFromStr(req.Shares)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_shares", "invalid shares format")
+		writeError(w, types.ErrCodeInvalidQuantity, "invalid shares format")
 		return
 	}
 
 	result, err := h.service.RequestWithdrawal(req.PoolID, req.User, shares)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "withdrawal_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -414,18 +415,18 @@ func (h *RiverpoolStandaloneHandler) ClaimWithdrawal(w http.ResponseWriter, r *h
 		User         string `json:"user"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	if req.WithdrawalID == "" || req.User == "" {
-		writeError(w, http.StatusBadRequest, "missing_fields", "withdrawal_id and user are required")
+		writeError(w, types.ErrCodeMissingField, "withdrawal_id and user are required")
 		return
 	}
 
 	result, err := h.service.ClaimWithdrawal(req.WithdrawalID, req.User)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "claim_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -440,17 +441,17 @@ func (h *RiverpoolStandaloneHandler) CancelWithdrawal(w http.ResponseWriter, r *
 		User         string `json:"user"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	if req.WithdrawalID == "" || req.User == "" {
-		writeError(w, http.StatusBadRequest, "missing_fields", "withdrawal_id and user are required")
+		writeError(w, types.ErrCodeMissingField, "withdrawal_id and user are required")
 		return
 	}
 
 	if err := h.service.CancelWithdrawal(req.WithdrawalID, req.User); err != nil {
-		writeError(w, http.StatusBadRequest, "cancel_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -475,7 +476,7 @@ func (h *RiverpoolStandaloneHandler) GetPoolRevenue(w http.ResponseWriter, r *ht
 
 	revenue, err := h.service.GetPoolRevenue(poolID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -496,7 +497,7 @@ func (h *RiverpoolStandaloneHandler) GetRevenueRecords(w http.ResponseWriter, r
 
 	records, err := h.service.GetRevenueRecords(poolID, limit)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -514,7 +515,7 @@ func (h *RiverpoolStandaloneHandler) GetRevenueBreakdown(w http.ResponseWriter,
 
 	breakdown, err := h.service.GetRevenueBreakdown(poolID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -529,18 +530,18 @@ func (h *RiverpoolStandaloneHandler) CreateCommunityPool(w http.ResponseWriter,
 		Params types.CommunityPoolParams `json:"params"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	if req.Owner == "" || req.Params.Name == "" {
-		writeError(w, http.StatusBadRequest, "missing_fields", "owner and params.name are required")
+		writeError(w, types.ErrCodeMissingField, "owner and params.name are required")
 		return
 	}
 
 	pool, err := h.service.CreateCommunityPool(req.Owner, &req.Params)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "create_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -558,7 +559,7 @@ func (h *RiverpoolStandaloneHandler) GetPoolHolders(w http.ResponseWriter, r *ht
 
 	holders, err := h.service.GetPoolHolders(poolID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -579,7 +580,7 @@ func (h *RiverpoolStandaloneHandler) GetPoolPositions(w http.ResponseWriter, r *
 
 	positions, err := h.service.GetPoolPositions(poolID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -607,7 +608,7 @@ func (h *RiverpoolStandaloneHandler) GetPoolTrades(w http.ResponseWriter, r *htt
 
 	trades, err := h.service.GetPoolTrades(poolID, limit)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -625,13 +626,13 @@ func (h *RiverpoolStandaloneHandler) GetInviteCodes(w http.ResponseWriter, r *ht
 	owner := r.Header.Get("X-Owner-Address")
 
 	if owner == "" {
-		writeError(w, http.StatusBadRequest, "missing_owner", "X-Owner-Address header is required")
+		writeError(w, types.ErrCodeMissingField, "X-Owner-Address header is required")
 		return
 	}
 
 	codes, err := h.service.GetInviteCodes(poolID, owner)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "get_codes_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -654,18 +655,18 @@ func (h *RiverpoolStandaloneHandler) GenerateInviteCode(w http.ResponseWriter, r
 		Owner string `json:"owner"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	if req.Owner == "" {
-		writeError(w, http.StatusBadRequest, "missing_owner", "owner is required")
+		writeError(w, types.ErrCodeMissingField, "owner is required")
 		return
 	}
 
 	code, err := h.service.GenerateInviteCode(poolID, req.Owner)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "generate_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -674,6 +675,62 @@ func (h *RiverpoolStandaloneHandler) GenerateInviteCode(w http.ResponseWriter, r
 	json.NewEncoder(w).Encode(code)
 }
 
+// GetInviteRedemptions handles GET /v1/riverpool/community/{poolId}/invites/{code}/redemptions
+func (h *RiverpoolStandaloneHandler) GetInviteRedemptions(w http.ResponseWriter, r *http.Request) {
+	poolID := r.Header.Get("X-Pool-ID")
+	code := r.Header.Get("X-Invite-Code")
+	owner := r.Header.Get("X-Owner-Address")
+
+	if owner == "" {
+		writeError(w, types.ErrCodeMissingField, "X-Owner-Address header is required")
+		return
+	}
+
+	redemptions, err := h.service.GetInviteRedemptions(poolID, owner, code)
+	if err != nil {
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pool_id":     poolID,
+		"code":        code,
+		"redemptions": redemptions,
+		"total":       len(redemptions),
+	})
+}
+
+// RevokeInviteCode handles POST /v1/riverpool/community/{poolId}/invites/{code}/revoke
+func (h *RiverpoolStandaloneHandler) RevokeInviteCode(w http.ResponseWriter, r *http.Request) {
+	poolID := r.Header.Get("X-Pool-ID")
+	code := r.Header.Get("X-Invite-Code")
+
+	var req struct {
+		Owner string `json:"owner"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+		return
+	}
+
+	if req.Owner == "" {
+		writeError(w, types.ErrCodeMissingField, "owner is required")
+		return
+	}
+
+	if err := h.service.RevokeInviteCode(poolID, req.Owner, code); err != nil {
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Invite code revoked successfully",
+	})
+}
+
 // PausePool handles POST /v1/riverpool/community/{poolId}/pause
 func (h *RiverpoolStandaloneHandler) PausePool(w http.ResponseWriter, r *http.Request) {
 	poolID := r.Header.Get("X-Pool-ID")
@@ -685,17 +742,17 @@ func (h *RiverpoolStandaloneHandler) PausePool(w http.ResponseWriter, r *http.Re
 		Owner string `json:"owner"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	if req.Owner == "" {
-		writeError(w, http.StatusBadRequest, "missing_owner", "owner is required")
+		writeError(w, types.ErrCodeMissingField, "owner is required")
 		return
 	}
 
 	if err := h.service.PausePool(poolID, req.Owner); err != nil {
-		writeError(w, http.StatusBadRequest, "pause_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -717,17 +774,17 @@ func (h *RiverpoolStandaloneHandler) ResumePool(w http.ResponseWriter, r *http.R
 		Owner string `json:"owner"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	if req.Owner == "" {
-		writeError(w, http.StatusBadRequest, "missing_owner", "owner is required")
+		writeError(w, types.ErrCodeMissingField, "owner is required")
 		return
 	}
 
 	if err := h.service.ResumePool(poolID, req.Owner); err != nil {
-		writeError(w, http.StatusBadRequest, "resume_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -749,17 +806,17 @@ func (h *RiverpoolStandaloneHandler) ClosePool(w http.ResponseWriter, r *http.Re
 		Owner string `json:"owner"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	if req.Owner == "" {
-		writeError(w, http.StatusBadRequest, "missing_owner", "owner is required")
+		writeError(w, types.ErrCodeMissingField, "owner is required")
 		return
 	}
 
 	if err := h.service.ClosePool(poolID, req.Owner); err != nil {
-		writeError(w, http.StatusBadRequest, "close_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -792,7 +849,7 @@ func (h *RiverpoolStandaloneHandler) GetPoolWithdrawals(w http.ResponseWriter, r
 
 	withdrawals, total, err := h.service.GetPoolWithdrawals(poolID, offset, limit)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "pool_not_found", err.Error())
+		writeServiceError(w, err, types.ErrCodePoolNotFound)
 		return
 	}
 
@@ -815,7 +872,7 @@ func (h *RiverpoolStandaloneHandler) GetUserPools(w http.ResponseWriter, r *http
 
 	deposits, err := h.service.GetUserDeposits(address)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		writeServiceError(w, err, types.ErrCodeInternal)
 		return
 	}
 
@@ -850,18 +907,18 @@ func (h *RiverpoolStandaloneHandler) UpdateCommunityPool(w http.ResponseWriter,
 		Params types.CommunityPoolParams `json:"params"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	if req.Owner == "" {
-		writeError(w, http.StatusBadRequest, "missing_owner", "owner is required")
+		writeError(w, types.ErrCodeMissingField, "owner is required")
 		return
 	}
 
 	pool, err := h.service.UpdateCommunityPool(poolID, req.Owner, &req.Params)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "update_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -885,18 +942,18 @@ func (h *RiverpoolStandaloneHandler) PlacePoolOrder(w http.ResponseWriter, r *ht
 		Leverage string `json:"leverage"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	if req.Owner == "" || req.MarketID == "" || req.Side == "" || req.Size == "" {
-		writeError(w, http.StatusBadRequest, "missing_fields", "owner, market_id, side, and size are required")
+		writeError(w, types.ErrCodeMissingField, "owner, market_id, side, and size are required")
 		return
 	}
 
 	size, err := math.LegacyNewDecFromStr(req.Size)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_size", "invalid size format")
+		writeError(w, types.ErrCodeInvalidQuantity, "invalid size format")
 		return
 	}
 
@@ -904,23 +961,23 @@ func (h *RiverpoolStandaloneHandler) PlacePoolOrder(w http.ResponseWriter, r *ht
 	if req.Price != "" {
 		price, err = math.LegacyNewDecFromStr(req.Price)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_price", "invalid price format")
+			writeError(w, types.ErrCodeInvalidPrice, "invalid price format")
 			return
 		}
 	}
 
-	leverage := math.LegacyNewDec(10) // default
+	var leverage math.LegacyDec // defaults to the pool's max leverage
 	if req.Leverage != "" {
 		leverage, err = math.LegacyNewDecFromStr(req.Leverage)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_leverage", "invalid leverage format")
+			writeError(w, types.ErrCodeInvalidLeverage, "invalid leverage format")
 			return
 		}
 	}
 
 	result, err := h.service.PlacePoolOrder(poolID, req.Owner, req.MarketID, req.Side, size, price, leverage)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "order_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
@@ -941,18 +998,18 @@ func (h *RiverpoolStandaloneHandler) ClosePoolPosition(w http.ResponseWriter, r
 		PositionID string `json:"position_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
 		return
 	}
 
 	if req.Owner == "" || req.PositionID == "" {
-		writeError(w, http.StatusBadRequest, "missing_fields", "owner and position_id are required")
+		writeError(w, types.ErrCodeMissingField, "owner and position_id are required")
 		return
 	}
 
 	result, err := h.service.ClosePoolPosition(poolID, req.Owner, req.PositionID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "close_failed", err.Error())
+		writeServiceError(w, err, types.ErrCodeInvalidRequest)
 		return
 	}
 
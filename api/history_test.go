@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestOrderHistoryLink tests that order and trade listings page through
+// next_cursor and a Link header pointing at the next page
+func TestOrderHistoryLink(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	for _, side := range []string{"sell", "buy", "buy"} {
+		body := `{"market_id":"BTC-USDC","side":"` + side + `","type":"limit","price":"50000","quantity":"0.1","trader":"history-` + side + `"}`
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("failed to place order: %d %s", rec.Code, rec.Body)
+		}
+	}
+
+	get := func(url string, resp interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d: %s", url, rec.Code, rec.Body)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return rec
+	}
+
+	var page types.ListOrdersResponse
+	rec := get("/v1/orders?trader=history-buy&limit=1&sort=asc", &page)
+	link := rec.Header().Get("Link")
+	if len(page.Orders) != 1 || page.NextCursor == "" || !strings.Contains(link, "cursor="+page.NextCursor) || !strings.HasSuffix(link, `>; rel="next"`) {
+		t.Fatalf("expected one order with a next link, got %+v and %q", page, link)
+	}
+	first := page.Orders[0].OrderID
+	next := strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+	// Fresh values for each response: omitted fields would keep the last ones
+	var last types.ListOrdersResponse
+	rec = get(next, &last)
+	if len(last.Orders) != 1 || last.Orders[0].OrderID == first || last.NextCursor != "" || rec.Header().Get("Link") != "" {
+		t.Errorf("expected the last page with the second order, got %+v", last)
+	}
+
+	var accountTrades types.ListTradesResponse
+	get("/v1/accounts/history-sell/trades?side=sell", &accountTrades)
+	if len(accountTrades.Trades) != 1 || accountTrades.Trades[0].Liquidity != "maker" {
+		t.Errorf("expected one maker sell trade, got %+v", accountTrades.Trades)
+	}
+	var marketTrades types.ListTradesResponse
+	get("/v1/markets/BTC-USDC/trades?side=buy&limit=5", &marketTrades)
+	if len(marketTrades.Trades) != 1 || marketTrades.Trades[0].Liquidity != "" {
+		t.Errorf("expected one market trade, got %+v", marketTrades.Trades)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/orders?trader=history-buy&cursor=!", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bad cursor, got %d", rec.Code)
+	}
+}
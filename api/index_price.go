@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// hyperliquidIndexSource is the source name of Hyperliquid oracle prices
+const hyperliquidIndexSource = "hyperliquid"
+
+// newIndexPrice builds an index price response, deriving the basis from mark and index
+func newIndexPrice(marketID string, index, mark math.LegacyDec, sources []*types.IndexSource, timestamp int64) *types.IndexPrice {
+	basis := mark.Sub(index)
+	basisRate := math.LegacyZeroDec()
+	if index.IsPositive() {
+		basisRate = basis.Quo(index)
+	}
+	return &types.IndexPrice{
+		MarketID:   marketID,
+		IndexPrice: index.String(),
+		MarkPrice:  mark.String(),
+		Basis:      basis.String(),
+		BasisRate:  basisRate.String(),
+		Sources:    sources,
+		Timestamp:  timestamp,
+	}
+}
+
+// indexFromTicker builds the index price of a Hyperliquid ticker, whose
+// oracle price is the single constituent of the index
+func indexFromTicker(ticker *TickerData) (*types.IndexPrice, error) {
+	index, err := math.LegacyNewDecFromStr(ticker.IndexPrice)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index price %q: %w", ticker.IndexPrice, err)
+	}
+	mark, err := math.LegacyNewDecFromStr(ticker.MarkPrice)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mark price %q: %w", ticker.MarkPrice, err)
+	}
+
+	sources := []*types.IndexSource{{
+		Source:    hyperliquidIndexSource,
+		Price:     index.String(),
+		Weight:    math.LegacyOneDec().String(),
+		Included:  true,
+		Timestamp: ticker.Timestamp,
+	}}
+	return newIndexPrice(ticker.MarketID, index, mark, sources, ticker.Timestamp), nil
+}
+
+// GetIndexPrice implements types.IndexPriceService from the Hyperliquid oracle price
+func (o *HyperliquidOracle) GetIndexPrice(ctx context.Context, marketID string) (*types.IndexPrice, error) {
+	ticker, err := o.GetTicker(marketID)
+	if err != nil {
+		return nil, err
+	}
+	return indexFromTicker(ticker)
+}
+
+// handleMarketIndex handles GET /v1/markets/{id}/index
+func (s *Server) handleMarketIndex(w http.ResponseWriter, r *http.Request, marketID string) {
+	if s.getMockMarket(marketID) == nil {
+		writeError(w, types.ErrCodeMarketNotFound, "Market not found")
+		return
+	}
+
+	index, err := s.indexService.GetIndexPrice(r.Context(), marketID)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeServiceUnavailable))
+		return
+	}
+	writeJSON(w, http.StatusOK, index)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// Transfer history page sizes
+const (
+	DefaultTransfersLimit = 50
+	MaxTransfersLimit     = 500
+)
+
+// handleInternalTransfer handles POST /v1/account/transfer {"to", "amount"},
+// moving free collateral from the trader to another platform account at once
+func (s *Server) handleInternalTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+	transfers, ok := s.accountService.(types.InternalTransferService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Internal transfers require a keeper-backed service")
+		return
+	}
+
+	var req types.InternalTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+		return
+	}
+	if req.To == "" || req.Amount == "" {
+		writeError(w, types.ErrCodeMissingField, "to and amount are required")
+		return
+	}
+
+	transfer, err := transfers.InternalTransfer(r.Context(), trader, &req)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+		return
+	}
+	writeJSON(w, http.StatusOK, transfer)
+}
+
+// handleTransfers handles GET /v1/account/transfers?limit=, the trader's
+// deposits, withdrawals and internal transfers newest first
+func (s *Server) handleTransfers(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	limit := DefaultTransfersLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 || n > MaxTransfersLimit {
+			writeError(w, types.ErrCodeInvalidRequest, "limit must be between 1 and "+strconv.Itoa(MaxTransfersLimit))
+			return
+		}
+		limit = n
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+	history, ok := s.accountService.(types.TransferHistoryService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Transfer history requires a keeper-backed service")
+		return
+	}
+
+	transfers, err := history.GetTransfers(r.Context(), trader, limit)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"transfers": transfers})
+}
+
+func (rs *RealService) InternalTransfer(ctx context.Context, trader string, req *types.InternalTransferRequest) (*types.Transfer, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.perpKeeper == nil {
+		return nil, types.NewAPIError(types.ErrCodeNotImplemented, "Internal transfers require a keeper-backed service")
+	}
+	amount, err := math.LegacyNewDecFromStr(req.Amount)
+	if err != nil {
+		return nil, types.NewAPIError(types.ErrCodeInvalidRequest, "amount must be a decimal")
+	}
+	transfer, err := rs.perpKeeper.InternalTransfer(rs.clockCtx(), trader, req.To, amount)
+	if err != nil {
+		return nil, err
+	}
+	return fromPerpTransfer(transfer), nil
+}
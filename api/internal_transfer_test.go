@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	"cosmossdk.io/store"
+	"cosmossdk.io/store/metrics"
+	storetypes "cosmossdk.io/store/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/openalpha/perp-dex/api/types"
+	perpkeeper "github.com/openalpha/perp-dex/x/perpetual/keeper"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TestInternalTransferAPI tests that a transfer moves free collateral only
+// and shows up in both traders' transfer history
+func TestInternalTransferAPI(t *testing.T) {
+	storeKey := storetypes.NewKVStoreKey("perpetual")
+	db := dbm.NewMemDB()
+	stateStore := store.NewCommitMultiStore(db, log.NewNopLogger(), metrics.NewNoOpMetrics())
+	stateStore.MountStoreWithDB(storeKey, storetypes.StoreTypeIAVL, db)
+	if err := stateStore.LoadLatestVersion(); err != nil {
+		t.Fatalf("failed to load store: %v", err)
+	}
+	ctx := sdk.NewContext(stateStore, cmtproto.Header{Time: time.Now().UTC()}, false, log.NewNopLogger())
+	pk := perpkeeper.NewKeeper(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), storeKey, nil, "", log.NewNopLogger())
+	pk.InitDefaultMarket(ctx)
+	alice := perptypes.NewAccount("alice")
+	alice.Balance = math.LegacyNewDec(1000)
+	alice.LockedMargin = math.LegacyNewDec(400)
+	pk.SetAccount(ctx, alice)
+
+	rs := NewRealServiceWithKeepers(nil, pk, ctx, log.NewNopLogger())
+	s := &Server{orderService: rs, accountService: rs}
+	transfer := func(body string, v interface{}) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/v1/account/transfer", bytes.NewBufferString(body))
+		req.Header.Set("X-Trader-Address", "alice")
+		rec := httptest.NewRecorder()
+		s.handleInternalTransfer(rec, req)
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+		}
+		return rec.Code
+	}
+
+	var apiErr types.APIError
+	if code := transfer(`{"to":"mm","amount":"700"}`, &apiErr); code != http.StatusBadRequest || apiErr.Code != types.ErrCodeInsufficientMargin {
+		t.Fatalf("expected locked margin to be refused, got %d %+v", code, apiErr)
+	}
+	if code := transfer(`{"to":"mm"}`, &apiErr); code != http.StatusBadRequest || apiErr.Code != types.ErrCodeMissingField {
+		t.Fatalf("expected a missing amount to be refused, got %d %+v", code, apiErr)
+	}
+
+	var out types.Transfer
+	if code := transfer(`{"to":"mm","amount":"250"}`, &out); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if out.Type != "internal_out" || out.Counterparty != "mm" || out.Balance != math.LegacyNewDec(750).String() {
+		t.Errorf("unexpected transfer %+v", out)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/account/transfers?limit=10", nil)
+	req.Header.Set("X-Trader-Address", "mm")
+	rec := httptest.NewRecorder()
+	s.handleTransfers(rec, req)
+	var history struct {
+		Transfers []*types.Transfer `json:"transfers"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+	}
+	if len(history.Transfers) != 1 || history.Transfers[0].Type != "internal_in" || history.Transfers[0].Counterparty != "alice" {
+		t.Fatalf("expected the receiving side in mm's history, got %+v", history.Transfers)
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/pkg/klines"
+)
+
+// Persistent kline defaults
+const (
+	DefaultKlineFlushInterval   = time.Second
+	DefaultKlineCompactInterval = 5 * time.Minute
+)
+
+// klineStore records engine trades as minute candles in Config.Klines and
+// serves charts from it, downsampling and pruning on a schedule per
+// Config.KlineRetention (see pkg/klines)
+type klineStore struct {
+	store           klines.Store
+	recorder        *klines.Recorder
+	compactor       *klines.Compactor
+	compactInterval time.Duration
+	now             func() time.Time
+}
+
+// newKlineStore returns nil, leaving charts to the keeper or the oracle, when
+// no store is configured
+func newKlineStore(config *Config) *klineStore {
+	if config.Klines == nil {
+		return nil
+	}
+	interval := config.KlineCompactInterval
+	if interval <= 0 {
+		interval = DefaultKlineCompactInterval
+	}
+	return &klineStore{
+		store:           config.Klines,
+		recorder:        klines.NewRecorder(config.Klines),
+		compactor:       klines.NewCompactor(config.Klines, config.KlineRetention),
+		compactInterval: interval,
+		now:             time.Now,
+	}
+}
+
+// record adds an engine trade to its minute candle
+func (k *klineStore) record(trade *types.MarketTrade) error {
+	price, err := math.LegacyNewDecFromStr(trade.Price)
+	if err != nil {
+		return err
+	}
+	quantity, err := math.LegacyNewDecFromStr(trade.Quantity)
+	if err != nil {
+		return err
+	}
+	return k.recorder.Record(context.Background(), trade.MarketID, price, quantity, time.UnixMilli(trade.Timestamp))
+}
+
+// GetKlines implements klineProvider
+func (k *klineStore) GetKlines(marketID, interval string, limit int) ([]KlineData, error) {
+	d, err := parseKlineInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+	step := int64(d.Seconds())
+	to := k.now().Unix() + 1
+	candles, err := klines.Query(context.Background(), k.store, marketID, d, to-int64(limit)*step, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+
+	result := make([]KlineData, 0, len(candles))
+	for _, c := range candles {
+		result = append(result, KlineData{
+			Time:   c.Time,
+			Open:   c.Open.MustFloat64(),
+			High:   c.High.MustFloat64(),
+			Low:    c.Low.MustFloat64(),
+			Close:  c.Close.MustFloat64(),
+			Volume: c.Volume.MustFloat64(),
+		})
+	}
+	return result, nil
+}
+
+// parseKlineInterval parses a chart interval such as 5m, 4h or 1d
+func parseKlineInterval(interval string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(interval, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid kline interval %q", interval)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil || d < time.Minute || d%time.Minute != 0 {
+		return 0, fmt.Errorf("invalid kline interval %q", interval)
+	}
+	return d, nil
+}
+
+// startKlineStore flushes recorded candles every second and downsamples and
+// prunes the store every compact interval. Recorded candles are flushed once
+// more on shutdown; the store itself belongs to whoever opened it.
+func (s *Server) startKlineStore() {
+	if s.klineStore == nil {
+		return
+	}
+	ctx := context.Background()
+	flush := time.NewTicker(DefaultKlineFlushInterval)
+	defer flush.Stop()
+	compact := time.NewTicker(s.klineStore.compactInterval)
+	defer compact.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			if err := s.klineStore.recorder.Flush(ctx); err != nil {
+				log.Printf("Kline store: flush failed: %v", err)
+			}
+			return
+		case <-flush.C:
+			if err := s.klineStore.recorder.Flush(ctx); err != nil {
+				log.Printf("Kline store: flush failed: %v", err)
+			}
+		case <-compact.C:
+			markets := make([]string, 0)
+			for _, market := range s.getMockMarkets() {
+				markets = append(markets, market["market_id"].(string))
+			}
+			if err := s.klineStore.compactor.Run(ctx, markets, s.klineStore.now()); err != nil {
+				log.Printf("Kline store: compaction failed: %v", err)
+			}
+		}
+	}
+}
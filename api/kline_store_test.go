@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/pkg/klines"
+)
+
+// TestKlineStore tests that published engine trades are recorded in the
+// configured kline store and charted from it
+func TestKlineStore(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	config.Klines = klines.NewMemoryStore()
+	config.KlineRetention = klines.DefaultPolicy()
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if s.klines != s.klineStore {
+		t.Fatalf("expected charts to come from the kline store")
+	}
+	handler := s.handler()
+
+	for _, order := range []string{
+		`{"market_id":"BTC-USDC","side":"sell","type":"limit","price":"50000","quantity":"0.2","trader":"kline-maker"}`,
+		`{"market_id":"BTC-USDC","side":"buy","type":"limit","price":"50000","quantity":"0.1","trader":"kline-taker"}`,
+		`{"market_id":"BTC-USDC","side":"buy","type":"limit","price":"50000","quantity":"0.1","trader":"kline-taker"}`,
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(order)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("failed to place order: %d %s", rec.Code, rec.Body)
+		}
+	}
+
+	ctx := context.Background()
+	page, err := s.orderService.(types.EventService).GetEvents(ctx, 0, MaxEventsLimit)
+	if err != nil {
+		t.Fatalf("failed to read events: %v", err)
+	}
+	for _, event := range page.Events {
+		s.publishEvent(event)
+	}
+	if err := s.klineStore.recorder.Flush(ctx); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+
+	candles, err := s.klines.GetKlines("BTC-USDC", "5m", 10)
+	if err != nil {
+		t.Fatalf("failed to read klines: %v", err)
+	}
+	if len(candles) != 1 || candles[0].Close != 50000 || candles[0].Volume != 0.2 {
+		t.Fatalf("unexpected candles %+v", candles)
+	}
+	if _, err := s.klines.GetKlines("BTC-USDC", "7x", 10); err == nil {
+		t.Fatalf("expected an invalid interval to be rejected")
+	}
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/auth"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// L3 feed page sizes and retention
+const (
+	DefaultL3Limit = 500
+	MaxL3Limit     = 1000
+
+	// DefaultL3Retention is how many L3 updates are kept for GET /v1/l3/events
+	DefaultL3Retention = 10000
+)
+
+// l3Feed derives the market-by-order feed from the event log. It tracks which
+// orders rest on the book, so an order's first update while resting is an
+// add and later ones are modifies, and keeps the latest updates for replay.
+// Trader identity is never copied into an update.
+type l3Feed struct {
+	mu        sync.RWMutex
+	resting   map[string]bool // order ID -> resting on the book
+	updates   []*types.L3Update
+	retention int
+}
+
+func newL3Feed(retention int) *l3Feed {
+	if retention <= 0 {
+		retention = DefaultL3Retention
+	}
+	return &l3Feed{
+		resting:   make(map[string]bool),
+		retention: retention,
+	}
+}
+
+// apply derives the L3 update of an event log entry, or nil if it does not
+// change the resting book: trades, market orders, and orders that never
+// rested are skipped
+func (f *l3Feed) apply(event *types.Event) *types.L3Update {
+	order := event.Order
+	if order == nil || order.Type == "ORDER_TYPE_MARKET" {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resting := f.resting[order.OrderID]
+	update := &types.L3Update{
+		Seq:       event.Seq,
+		MarketID:  order.MarketID,
+		OrderID:   order.OrderID,
+		Side:      wsSide(order.Side),
+		Price:     order.Price,
+		Size:      "0",
+		Timestamp: order.UpdatedAt,
+	}
+	switch order.Status {
+	case "ORDER_STATUS_OPEN", "ORDER_STATUS_PARTIALLY_FILLED":
+		update.Action = types.L3ActionModify
+		if !resting {
+			update.Action = types.L3ActionAdd
+			f.resting[order.OrderID] = true
+		}
+		update.Size = remainingSize(order)
+	case "ORDER_STATUS_FILLED":
+		if !resting {
+			return nil
+		}
+		update.Action = types.L3ActionFill
+		delete(f.resting, order.OrderID)
+	case "ORDER_STATUS_CANCELLED", "ORDER_STATUS_EXPIRED":
+		if !resting {
+			return nil
+		}
+		update.Action = types.L3ActionCancel
+		delete(f.resting, order.OrderID)
+	default:
+		return nil
+	}
+
+	f.updates = append(f.updates, update)
+	if len(f.updates) >= 2*f.retention {
+		f.updates = append([]*types.L3Update(nil), f.updates[len(f.updates)-f.retention:]...)
+	}
+	return update
+}
+
+// remainingSize returns the order's unfilled quantity
+func remainingSize(order *types.Order) string {
+	quantity, err := math.LegacyNewDecFromStr(order.Quantity)
+	if err != nil {
+		return order.Quantity
+	}
+	if filled, err := math.LegacyNewDecFromStr(order.FilledQty); err == nil {
+		quantity = quantity.Sub(filled)
+	}
+	return quantity.String()
+}
+
+// since returns up to limit retained updates with sequence numbers of at
+// least fromSeq, of one market if marketID is set
+func (f *l3Feed) since(fromSeq uint64, marketID string, limit int) *types.L3UpdatesResponse {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	retained := f.updates
+	if len(retained) > f.retention {
+		retained = retained[len(retained)-f.retention:]
+	}
+	resp := &types.L3UpdatesResponse{
+		Updates: make([]*types.L3Update, 0),
+		NextSeq: fromSeq,
+	}
+	if len(retained) > 0 {
+		resp.FirstSeq = retained[0].Seq
+	}
+	for _, update := range retained {
+		if update.Seq < fromSeq {
+			continue
+		}
+		if len(resp.Updates) == limit {
+			break
+		}
+		resp.NextSeq = update.Seq + 1
+		if marketID != "" && update.MarketID != marketID {
+			continue
+		}
+		resp.Updates = append(resp.Updates, update)
+	}
+	return resp
+}
+
+// publishL3 derives the L3 update of an event log entry and broadcasts it on
+// the market's licensed channel
+func (s *Server) publishL3(event *types.Event) {
+	if update := s.l3.apply(event); update != nil {
+		s.wsServer.BroadcastL3(update)
+	}
+}
+
+// handleL3Events handles GET /v1/l3/events: retained market-by-order updates
+// from from_seq on. It requires a session token granted the L3 data license.
+func (s *Server) handleL3Events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	authz := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authz, "Bearer ") {
+		writeError(w, types.ErrCodeUnauthenticated, "A session token is required (Authorization: Bearer)")
+		return
+	}
+	session, err := s.sessions.Verify(strings.TrimPrefix(authz, "Bearer "))
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidToken))
+		return
+	}
+	if !session.HasPermission(auth.PermissionL3Data) {
+		writeError(w, types.ErrCodeLicenseRequired, "The L3 feed requires a data license")
+		return
+	}
+
+	query := r.URL.Query()
+	var fromSeq uint64
+	if v := query.Get("from_seq"); v != "" {
+		seq, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, types.ErrCodeInvalidRequest, "from_seq must be a non-negative integer")
+			return
+		}
+		fromSeq = seq
+	}
+	limit := DefaultL3Limit
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 || n > MaxL3Limit {
+			writeError(w, types.ErrCodeInvalidRequest, "limit must be between 1 and "+strconv.Itoa(MaxL3Limit))
+			return
+		}
+		limit = n
+	}
+
+	writeJSON(w, http.StatusOK, s.l3.since(fromSeq, query.Get("market_id"), limit))
+}
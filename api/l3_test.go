@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cosmossdk.io/log"
+
+	"github.com/openalpha/perp-dex/api/auth"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestL3Feed tests that a resting order's life is derived from the event log
+// as add, modify on a partial fill, and cancel, that orders which never rest
+// are skipped, and that the feed is served only to licensed sessions
+func TestL3Feed(t *testing.T) {
+	svc, err := NewRealService(log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	ctx := context.Background()
+	place := func(trader, side, quantity string) *types.PlaceOrderResponse {
+		resp, err := svc.PlaceOrder(ctx, &types.PlaceOrderRequest{
+			MarketID: "BTC-USDC", Trader: trader, Side: side, Type: "limit", Price: "50000", Quantity: quantity,
+		})
+		if err != nil {
+			t.Fatalf("failed to place order: %v", err)
+		}
+		return resp
+	}
+	maker := place("l3-maker", "sell", "0.2")
+	place("l3-taker", "buy", "0.1")
+	if _, err := svc.CancelOrder(ctx, "l3-maker", maker.Order.OrderID); err != nil {
+		t.Fatalf("failed to cancel: %v", err)
+	}
+
+	s := &Server{
+		orderService: svc,
+		sessions: newSessionManager(&Config{
+			APIKeys:      map[string]string{"licensed-key": "mm-licensed", "plain-key": "mm-plain"},
+			DataLicenses: []string{"mm-licensed"},
+		}),
+		l3: newL3Feed(0),
+	}
+	page, err := svc.GetEvents(ctx, 0, MaxEventsLimit)
+	if err != nil {
+		t.Fatalf("failed to read events: %v", err)
+	}
+	var actions []string
+	for _, event := range page.Events {
+		if update := s.l3.apply(event); update != nil {
+			if update.OrderID != maker.Order.OrderID {
+				t.Errorf("expected only the resting maker order, got %+v", update)
+			}
+			actions = append(actions, update.Action+"/"+update.Size)
+		}
+	}
+	if got := strings.Join(actions, ","); got != "add/0.200000000000000000,modify/0.100000000000000000,cancel/0" {
+		t.Errorf("unexpected L3 updates %s", got)
+	}
+
+	get := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/l3/events?market_id=BTC-USDC", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		s.handleL3Events(rec, req)
+		return rec
+	}
+	if rec := get(""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a session, got %d", rec.Code)
+	}
+	plain, _, _ := s.sessions.LoginWithAPIKey("plain-key")
+	if rec := get(plain); rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), string(types.ErrCodeLicenseRequired)) {
+		t.Errorf("expected 403 without a data license, got %d %s", rec.Code, rec.Body)
+	}
+
+	licensed, session, _ := s.sessions.LoginWithAPIKey("licensed-key")
+	if !session.HasPermission(auth.PermissionL3Data) {
+		t.Fatal("expected the licensed session to carry the L3 permission")
+	}
+	rec := get(licensed)
+	var resp types.L3UpdatesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK || len(resp.Updates) != 3 {
+		t.Fatalf("unexpected L3 page %d: %s", rec.Code, rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), "l3-maker") {
+		t.Error("expected the L3 feed to carry no trader identity")
+	}
+	if resp.FirstSeq != resp.Updates[0].Seq || resp.NextSeq != resp.Updates[2].Seq+1 {
+		t.Errorf("unexpected page bounds first=%d next=%d", resp.FirstSeq, resp.NextSeq)
+	}
+}
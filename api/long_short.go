@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// longShortInterval is how often long/short ratios are checked for changes to
+// push to stats channel subscribers
+const longShortInterval = 5 * time.Second
+
+// newLongShortRatio builds a market's long/short ratio from its account counts
+// and sizes, valuing both sides at the mark price
+func newLongShortRatio(marketID string, longAccounts, shortAccounts int64, longSize, shortSize, mark math.LegacyDec) *types.LongShortRatio {
+	ratio := func(long, short math.LegacyDec) string {
+		if !short.IsPositive() {
+			return math.LegacyZeroDec().String()
+		}
+		return long.Quo(short).String()
+	}
+	longNotional, shortNotional := longSize.Mul(mark), shortSize.Mul(mark)
+	return &types.LongShortRatio{
+		MarketID:      marketID,
+		LongAccounts:  longAccounts,
+		ShortAccounts: shortAccounts,
+		AccountRatio:  ratio(math.LegacyNewDec(longAccounts), math.LegacyNewDec(shortAccounts)),
+		LongNotional:  longNotional.String(),
+		ShortNotional: shortNotional.String(),
+		NotionalRatio: ratio(longNotional, shortNotional),
+		MarkPrice:     mark.String(),
+		Timestamp:     types.NowMillis(),
+	}
+}
+
+// GetLongShortRatio reads the market's long/short stats the perpetual keeper
+// keeps as positions change
+func (rs *RealService) GetLongShortRatio(ctx context.Context, marketID string) (*types.LongShortRatio, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	zero := math.LegacyZeroDec()
+	if rs.perpKeeper == nil {
+		return newLongShortRatio(marketID, 0, 0, zero, zero, zero), nil
+	}
+
+	stats := rs.perpKeeper.GetLongShortStats(rs.sdkCtx, marketID)
+	mark := zero
+	if priceInfo := rs.perpKeeper.GetPrice(rs.sdkCtx, marketID); priceInfo != nil {
+		mark = priceInfo.MarkPrice
+	}
+	return newLongShortRatio(marketID, stats.LongAccounts, stats.ShortAccounts, stats.LongSize, stats.ShortSize, mark), nil
+}
+
+// GetLongShortRatio implements types.LongShortService over the mock positions
+// at their mark price
+func (ms *MockService) GetLongShortRatio(ctx context.Context, marketID string) (*types.LongShortRatio, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	var longAccounts, shortAccounts int64
+	longSize, shortSize, mark := math.LegacyZeroDec(), math.LegacyZeroDec(), math.LegacyZeroDec()
+	for _, pos := range ms.positions {
+		if pos.MarketID != marketID {
+			continue
+		}
+		size, err := math.LegacyNewDecFromStr(pos.Size)
+		if err != nil || !size.IsPositive() {
+			continue
+		}
+		if price, err := math.LegacyNewDecFromStr(pos.MarkPrice); err == nil {
+			mark = price
+		}
+		if pos.Side == "long" {
+			longAccounts++
+			longSize = longSize.Add(size)
+		} else {
+			shortAccounts++
+			shortSize = shortSize.Add(size)
+		}
+	}
+	return newLongShortRatio(marketID, longAccounts, shortAccounts, longSize, shortSize, mark), nil
+}
+
+// handleMarketLongShortRatio handles GET /v1/markets/{id}/long-short-ratio
+func (s *Server) handleMarketLongShortRatio(w http.ResponseWriter, r *http.Request, marketID string) {
+	if s.getMockMarket(marketID) == nil {
+		writeError(w, types.ErrCodeMarketNotFound, "Market not found")
+		return
+	}
+	ls, ok := s.positionService.(types.LongShortService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Long/short ratios are not available from this node")
+		return
+	}
+
+	ratio, err := ls.GetLongShortRatio(r.Context(), marketID)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeServiceUnavailable))
+		return
+	}
+	writeJSON(w, http.StatusOK, ratio)
+}
+
+// startLongShortBroadcaster pushes each market's long/short ratio to its
+// public stats channel whenever it changes
+func (s *Server) startLongShortBroadcaster() {
+	ls, ok := s.positionService.(types.LongShortService)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(longShortInterval)
+	defer ticker.Stop()
+
+	last := make(map[string]types.LongShortRatio) // market -> last broadcast ratio, without timestamp
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		ctx := context.Background()
+		for _, market := range s.getMockMarkets() {
+			marketID, _ := market["market_id"].(string)
+			ratio, err := ls.GetLongShortRatio(ctx, marketID)
+			if err != nil {
+				continue
+			}
+			key := *ratio
+			key.Timestamp = 0
+			if prev, ok := last[marketID]; ok && prev == key {
+				continue
+			}
+			last[marketID] = key
+			s.wsServer.BroadcastLongShortRatio(ratio)
+		}
+	}
+}
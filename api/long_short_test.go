@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestLongShortRatio tests that the long/short ratio endpoint splits a
+// market's mock positions by account and by notional at the mark price
+func TestLongShortRatio(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	s := NewServer(config)
+	ms := s.positionService.(*MockService)
+	for _, pos := range []*types.Position{
+		{MarketID: "BTC-USDC", Trader: "alice", Side: "long", Size: "1.5", MarkPrice: "50000"},
+		{MarketID: "BTC-USDC", Trader: "bob", Side: "long", Size: "0.5", MarkPrice: "50000"},
+		{MarketID: "BTC-USDC", Trader: "carol", Side: "short", Size: "4", MarkPrice: "50000"},
+		{MarketID: "ETH-USDC", Trader: "carol", Side: "long", Size: "10", MarkPrice: "3000"},
+	} {
+		ms.positions[pos.Trader+":"+pos.MarketID] = pos
+	}
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/markets/BTC-USDC/long-short-ratio", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var ratio types.LongShortRatio
+	if err := json.Unmarshal(rec.Body.Bytes(), &ratio); err != nil {
+		t.Fatalf("invalid ratio %q: %v", rec.Body.String(), err)
+	}
+	is := func(s, want string) bool {
+		v, err := math.LegacyNewDecFromStr(s)
+		return err == nil && v.Equal(math.LegacyMustNewDecFromStr(want))
+	}
+	if ratio.LongAccounts != 2 || ratio.ShortAccounts != 1 || !is(ratio.AccountRatio, "2") {
+		t.Errorf("expected two long accounts to one short, got %+v", ratio)
+	}
+	if !is(ratio.LongNotional, "100000") || !is(ratio.ShortNotional, "200000") || !is(ratio.NotionalRatio, "0.5") {
+		t.Errorf("expected half the short notional long, got %+v", ratio)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/markets/NOPE-USDC/long-short-ratio", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected an unknown market to be rejected, got %d", rec.Code)
+	}
+}
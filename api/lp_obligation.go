@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// toOBLPObligation converts an API LP obligation to the keeper representation.
+// Omitted spread and uptime take the defaults.
+func toOBLPObligation(o *types.LPObligation) (*obtypes.LPObligation, error) {
+	minQuantity, err := math.LegacyNewDecFromStr(o.MinQuantity)
+	if err != nil {
+		return nil, types.NewAPIError(types.ErrCodeInvalidDecimal, "invalid min_quantity "+o.MinQuantity)
+	}
+	obligation := obtypes.NewLPObligation(o.Trader, o.MarketID, minQuantity)
+	if o.MaxSpreadBps != 0 {
+		obligation.MaxSpreadBps = o.MaxSpreadBps
+	}
+	if o.MinUptime != "" {
+		if obligation.MinUptime, err = math.LegacyNewDecFromStr(o.MinUptime); err != nil {
+			return nil, types.NewAPIError(types.ErrCodeInvalidDecimal, "invalid min_uptime "+o.MinUptime)
+		}
+	}
+	return obligation, nil
+}
+
+// fromOBLPObligation converts a keeper LP obligation to the API response
+func fromOBLPObligation(o *obtypes.LPObligation) *types.LPObligation {
+	obligation := &types.LPObligation{
+		Trader:       o.Trader,
+		MarketID:     o.MarketID,
+		MaxSpreadBps: o.MaxSpreadBps,
+		MinQuantity:  o.MinQuantity.String(),
+		MinUptime:    o.MinUptime.String(),
+	}
+	if !o.UpdatedAt.IsZero() {
+		obligation.UpdatedAt = o.UpdatedAt.UnixMilli()
+	}
+	return obligation
+}
+
+// fromOBLPUptime converts a keeper uptime report to the API response
+func fromOBLPUptime(u *obtypes.LPUptime) *types.LPUptime {
+	uptime := &types.LPUptime{
+		MarketID:      u.MarketID,
+		Samples:       u.Samples,
+		QuotedSamples: u.QuotedSamples,
+		Uptime:        u.Uptime().String(),
+		MinUptime:     u.MinUptime.String(),
+		MaxSpreadBps:  u.MaxSpreadBps,
+		Met:           u.Met(),
+	}
+	if !u.LastSampledAt.IsZero() {
+		uptime.LastSampledAt = u.LastSampledAt.UnixMilli()
+	}
+	return uptime
+}
+
+// lpObligationService returns the order service's LP obligation support, or
+// writes 501 if it has none
+func (s *Server) lpObligationService(w http.ResponseWriter) types.LPObligationService {
+	lp, ok := s.orderService.(types.LPObligationService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "LP obligations require a keeper-backed service")
+		return nil
+	}
+	return lp
+}
+
+// handleAdminLPObligations handles /v1/admin/lp/obligations (GET ?trader=,
+// PUT, DELETE ?trader=&market_id=)
+func (s *Server) handleAdminLPObligations(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	lp := s.lpObligationService(w)
+	if lp == nil {
+		return
+	}
+
+	query := r.URL.Query()
+	switch r.Method {
+	case http.MethodGet:
+		obligations, err := lp.ListLPObligations(r.Context(), query.Get("trader"))
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"obligations": obligations})
+
+	case http.MethodPut:
+		var req types.LPObligation
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		if req.Trader == "" || req.MarketID == "" || req.MinQuantity == "" {
+			writeError(w, types.ErrCodeMissingField, "trader, market_id and min_quantity are required")
+			return
+		}
+		if s.getMockMarket(req.MarketID) == nil {
+			writeError(w, types.ErrCodeMarketNotFound, "Market not found")
+			return
+		}
+		obligation, err := lp.SetLPObligation(r.Context(), &req)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"obligation": obligation})
+
+	case http.MethodDelete:
+		trader, marketID := query.Get("trader"), query.Get("market_id")
+		if trader == "" || marketID == "" {
+			writeError(w, types.ErrCodeMissingField, "trader and market_id are required")
+			return
+		}
+		if err := lp.DeleteLPObligation(r.Context(), trader, marketID); err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleLPUptime handles GET /v1/lp/uptime?trader=&epoch=, a maker's uptime
+// report for an epoch (default: the current one)
+func (s *Server) handleLPUptime(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+	epoch := int64(-1)
+	if e := r.URL.Query().Get("epoch"); e != "" {
+		var err error
+		if epoch, err = strconv.ParseInt(e, 10, 64); err != nil || epoch < 0 {
+			writeError(w, types.ErrCodeInvalidRequest, "epoch must be a non-negative integer")
+			return
+		}
+	}
+	lp := s.lpObligationService(w)
+	if lp == nil {
+		return
+	}
+
+	report, err := lp.GetLPUptime(r.Context(), trader, epoch)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Maker points leaderboard sizes
+const (
+	DefaultMakerPointsLimit = 100
+	MaxMakerPointsLimit     = 1000
+)
+
+// makerRebateService returns the order service's maker rebate support, or
+// writes 501 if it has none
+func (s *Server) makerRebateService(w http.ResponseWriter) (types.MakerRebateService, bool) {
+	rebates, ok := s.orderService.(types.MakerRebateService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Maker rebates require a keeper-backed service")
+	}
+	return rebates, ok
+}
+
+// handleAdminMakerRebates handles /v1/admin/maker-rebates (GET, PUT with a
+// types.MakerRebateParamsRequest): the dynamic maker rebate params
+func (s *Server) handleAdminMakerRebates(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	rebates, ok := s.makerRebateService(w)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		params, err := rebates.GetMakerRebateParams(r.Context())
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, params)
+
+	case http.MethodPut:
+		var req types.MakerRebateParamsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		params, err := rebates.SetMakerRebateParams(r.Context(), &req)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusOK, params)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleMakerPoints handles GET /v1/account/maker-points, the trader's maker
+// points and rebates
+func (s *Server) handleMakerPoints(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+	rebates, ok := s.makerRebateService(w)
+	if !ok {
+		return
+	}
+	points, err := rebates.GetMakerPoints(r.Context(), trader)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, points)
+}
+
+// handleMakerPointsLeaderboard handles GET /v1/maker-points?limit=, the
+// traders with the most maker points, each with their profile if visible
+func (s *Server) handleMakerPointsLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	rebates, ok := s.makerRebateService(w)
+	if !ok {
+		return
+	}
+	limit := DefaultMakerPointsLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 || n > MaxMakerPointsLimit {
+			writeError(w, types.ErrCodeInvalidRequest, "limit must be between 1 and "+strconv.Itoa(MaxMakerPointsLimit))
+			return
+		}
+		limit = n
+	}
+	points, err := rebates.ListMakerPoints(r.Context(), limit)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	makers := make([]*types.MakerPoints, len(points))
+	for i, p := range points {
+		cp := *p
+		cp.Profile = traderProfile(s.profiles, p.Trader)
+		makers[i] = &cp
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"makers": makers})
+}
+
+func (rs *RealService) GetMakerRebateParams(ctx context.Context) (*types.MakerRebateParams, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	return fromOBMakerRebateParams(rs.obKeeper.GetMakerRebateParams(rs.sdkCtx)), nil
+}
+
+func (rs *RealService) SetMakerRebateParams(ctx context.Context, req *types.MakerRebateParamsRequest) (*types.MakerRebateParams, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	sdkCtx := rs.clockCtx()
+	params := rs.obKeeper.GetMakerRebateParams(sdkCtx)
+	if req.Enabled != nil {
+		params.Enabled = *req.Enabled
+	}
+	for _, field := range []struct {
+		value *string
+		dst   *math.LegacyDec
+	}{{req.MaxRebateRate, &params.MaxRebateRate}, {req.TightnessWeight, &params.TightnessWeight}} {
+		if field.value == nil {
+			continue
+		}
+		d, err := math.LegacyNewDecFromStr(*field.value)
+		if err != nil {
+			return nil, types.NewAPIError(types.ErrCodeInvalidDecimal, "invalid decimal "+*field.value)
+		}
+		*field.dst = d
+	}
+	if req.MinRestMs != nil {
+		params.MinRestDuration = time.Duration(*req.MinRestMs) * time.Millisecond
+	}
+	if req.FullRestMs != nil {
+		params.FullRestDuration = time.Duration(*req.FullRestMs) * time.Millisecond
+	}
+	if err := rs.obKeeper.SetMakerRebateParams(sdkCtx, params); err != nil {
+		return nil, err
+	}
+	return fromOBMakerRebateParams(params), nil
+}
+
+func (rs *RealService) GetMakerPoints(ctx context.Context, trader string) (*types.MakerPoints, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	return fromOBMakerPoints(rs.obKeeper.GetMakerPoints(rs.sdkCtx, trader)), nil
+}
+
+func (rs *RealService) ListMakerPoints(ctx context.Context, limit int) ([]*types.MakerPoints, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	all := rs.obKeeper.GetAllMakerPoints(rs.sdkCtx)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Points.GT(all[j].Points)
+	})
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	result := make([]*types.MakerPoints, 0, len(all))
+	for _, points := range all {
+		result = append(result, fromOBMakerPoints(points))
+	}
+	return result, nil
+}
+
+// fromOBMakerRebateParams converts keeper maker rebate params to the API type
+func fromOBMakerRebateParams(p *obtypes.MakerRebateParams) *types.MakerRebateParams {
+	params := &types.MakerRebateParams{
+		Enabled:         p.Enabled,
+		MaxRebateRate:   decString(p.MaxRebateRate),
+		TightnessWeight: decString(p.TightnessWeight),
+		MinRestMs:       p.MinRestDuration.Milliseconds(),
+		FullRestMs:      p.FullRestDuration.Milliseconds(),
+	}
+	if !p.UpdatedAt.IsZero() {
+		params.UpdatedAt = p.UpdatedAt.UnixMilli()
+	}
+	return params
+}
+
+// fromOBMakerPoints converts a keeper maker points record to the API type
+func fromOBMakerPoints(p *obtypes.MakerPoints) *types.MakerPoints {
+	points := &types.MakerPoints{
+		Trader:  p.Trader,
+		Points:  decString(p.Points),
+		Rebates: decString(p.Rebates),
+		Fills:   p.Fills,
+	}
+	if !p.UpdatedAt.IsZero() {
+		points.UpdatedAt = p.UpdatedAt.UnixMilli()
+	}
+	return points
+}
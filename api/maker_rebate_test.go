@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestMakerRebates tests that operators can read and change the maker rebate
+// params and that traders read their maker points
+func TestMakerRebates(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	config.AdminToken = "admin-secret"
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	do := func(method, target, body string, header map[string]string, v interface{}) int {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("%s %s: invalid JSON %q: %v", method, target, rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+	admin := map[string]string{adminTokenHeader: config.AdminToken}
+
+	if code := do(http.MethodGet, "/v1/admin/maker-rebates", "", nil, nil); code != http.StatusForbidden {
+		t.Errorf("expected 403 without the admin token, got %d", code)
+	}
+	var params types.MakerRebateParams
+	if code := do(http.MethodGet, "/v1/admin/maker-rebates", "", admin, &params); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if params.Enabled || params.MaxRebateRate != "0.000100000000000000" || params.FullRestMs != 60000 {
+		t.Errorf("expected the disabled defaults, got %+v", params)
+	}
+
+	if code := do(http.MethodPut, "/v1/admin/maker-rebates", `{"enabled":true,"min_rest_ms":500}`, admin, &params); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if !params.Enabled || params.MinRestMs != 500 || params.TightnessWeight != "0.500000000000000000" || params.UpdatedAt == 0 {
+		t.Errorf("expected the changed fields and the rest kept, got %+v", params)
+	}
+	if code := do(http.MethodPut, "/v1/admin/maker-rebates", `{"tightness_weight":"1.5"}`, admin, nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a weight above 1, got %d", code)
+	}
+
+	// A trader reads their own points; the leaderboard is public
+	var points types.MakerPoints
+	req := httptest.NewRequest(http.MethodGet, "/v1/account/maker-points", nil)
+	req.Header.Set("X-Trader-Address", "alice")
+	rec := httptest.NewRecorder()
+	s.handleMakerPoints(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &points); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+	}
+	if points.Trader != "alice" || points.Points != "0.000000000000000000" || points.Fills != 0 {
+		t.Errorf("expected no points yet, got %+v", points)
+	}
+	rec = httptest.NewRecorder()
+	s.handleMakerPointsLeaderboard(rec, httptest.NewRequest(http.MethodGet, "/v1/maker-points?limit=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a zero limit, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,358 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/api/webhook"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// Margin call defaults
+const (
+	DefaultMarginCallInterval = 10 * time.Second
+	DefaultMarginCallRepeat   = 15 * time.Minute
+)
+
+// DefaultMarginCallLevels warn at 80% and 90% of the equity held as
+// maintenance margin; liquidation starts at 100%
+var DefaultMarginCallLevels = []float64{0.8, 0.9}
+
+// MarginCallListener receives every margin call the monitor emits, after the
+// WebSocket and webhook notifications
+type MarginCallListener func(call *types.MarginCall)
+
+// marginCallState is what the monitor remembers about one trader
+type marginCallState struct {
+	optOut       bool
+	level        int // index into levels of the last level notified, -1 below all
+	lastNotified time.Time
+}
+
+// marginCalls warns traders whose maintenance margin approaches their equity.
+// Each level is notified once when crossed upwards and repeated at most once
+// per repeat while the account stays at that level; dropping below a level
+// re-arms it.
+type marginCalls struct {
+	interval time.Duration
+	levels   []math.LegacyDec // ascending
+	repeat   time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	state     map[string]*marginCallState
+	listeners []MarginCallListener
+}
+
+// newMarginCalls returns nil, disabling margin calls, when
+// Config.MarginCallInterval is negative
+func newMarginCalls(config *Config) *marginCalls {
+	interval := config.MarginCallInterval
+	if interval < 0 {
+		return nil
+	}
+	if interval == 0 {
+		interval = DefaultMarginCallInterval
+	}
+	repeat := config.MarginCallRepeat
+	if repeat <= 0 {
+		repeat = DefaultMarginCallRepeat
+	}
+	raw := config.MarginCallLevels
+	if len(raw) == 0 {
+		raw = DefaultMarginCallLevels
+	}
+	levels := make([]math.LegacyDec, 0, len(raw))
+	for _, level := range raw {
+		if level <= 0 {
+			continue
+		}
+		dec, err := math.LegacyNewDecFromStr(strconv.FormatFloat(level, 'f', -1, 64))
+		if err != nil {
+			continue
+		}
+		levels = append(levels, dec)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].LT(levels[j]) })
+
+	return &marginCalls{
+		interval: interval,
+		levels:   levels,
+		repeat:   repeat,
+		now:      time.Now,
+		state:    make(map[string]*marginCallState),
+	}
+}
+
+// AddMarginCallListener registers an in-process consumer of margin calls, for
+// components that act on them beyond notifying the trader
+func (s *Server) AddMarginCallListener(listener MarginCallListener) {
+	if s.marginCalls == nil {
+		return
+	}
+	s.marginCalls.mu.Lock()
+	defer s.marginCalls.mu.Unlock()
+	s.marginCalls.listeners = append(s.marginCalls.listeners, listener)
+}
+
+// marginRatio returns the account's maintenance margin, equity and their
+// ratio. Maintenance margin is each position's notional at the mark price
+// times the market's maintenance margin rate; equity is the balance plus
+// unrealized PnL. The ratio is nil when equity is exhausted.
+func marginRatio(summary *types.AccountSummary) (maintenance, equity math.LegacyDec, ratio *math.LegacyDec, err error) {
+	equity, err = math.LegacyNewDecFromStr(summary.Account.Balance)
+	if err != nil {
+		return
+	}
+	maintenance = math.LegacyZeroDec()
+	configs := perptypes.DefaultMarketConfigs()
+	for _, pos := range summary.Positions {
+		upnl, perr := math.LegacyNewDecFromStr(pos.UnrealizedPnl)
+		if perr != nil {
+			err = perr
+			return
+		}
+		equity = equity.Add(upnl)
+
+		cfg, ok := configs[pos.MarketID]
+		if !ok {
+			continue
+		}
+		size, serr := math.LegacyNewDecFromStr(pos.Size)
+		mark, merr := math.LegacyNewDecFromStr(pos.MarkPrice)
+		if serr != nil || merr != nil {
+			continue
+		}
+		maintenance = maintenance.Add(size.Abs().Mul(mark).Mul(cfg.MaintenanceMarginRate))
+	}
+	if equity.IsPositive() {
+		r := maintenance.Quo(equity)
+		ratio = &r
+	}
+	return
+}
+
+// evaluate decides whether a trader at ratio gets a margin call now. A nil
+// ratio means equity is exhausted and counts as above every level.
+func (m *marginCalls) evaluate(trader string, ratio *math.LegacyDec) (level int, repeat, notify bool) {
+	level = -1
+	for i, threshold := range m.levels {
+		if ratio == nil || ratio.GTE(threshold) {
+			level = i
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.state[trader]
+	if state == nil {
+		if level < 0 {
+			return level, false, false
+		}
+		state = &marginCallState{level: -1}
+		m.state[trader] = state
+	}
+	now := m.now()
+	switch {
+	case level > state.level:
+		repeat = false
+	case level >= 0 && level == state.level && now.Sub(state.lastNotified) >= m.repeat:
+		repeat = true
+	default:
+		// Recovered below the notified level: re-arm without notifying
+		if level < state.level {
+			state.level = level
+		}
+		return level, false, false
+	}
+	state.level = level
+	if state.optOut {
+		return level, repeat, false
+	}
+	state.lastNotified = now
+	return level, repeat, true
+}
+
+// setOptOut records a trader's margin call preference
+func (m *marginCalls) setOptOut(trader string, optOut bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.state[trader]
+	if state == nil {
+		state = &marginCallState{level: -1}
+		m.state[trader] = state
+	}
+	state.optOut = optOut
+}
+
+// settings returns a trader's preference and latest notified level
+func (m *marginCalls) settings(trader string) *types.MarginCallSettings {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	settings := &types.MarginCallSettings{
+		Trader:      trader,
+		Levels:      make([]string, len(m.levels)),
+		RepeatAfter: m.repeat.Milliseconds(),
+	}
+	for i, level := range m.levels {
+		settings.Levels[i] = level.String()
+	}
+	if state := m.state[trader]; state != nil {
+		settings.OptOut = state.optOut
+		if state.level >= 0 {
+			settings.LastLevel = m.levels[state.level].String()
+		}
+		if !state.lastNotified.IsZero() {
+			settings.LastNotified = state.lastNotified.UnixMilli()
+		}
+	}
+	return settings
+}
+
+// checkMarginCalls evaluates every account, querying them in batches of
+// types.MaxBatchQueryTraders, and notifies the ones due a margin call
+func (s *Server) checkMarginCalls(ctx context.Context, lister types.TraderLister, batch types.AccountBatchService) error {
+	traders, err := lister.ListTraders(ctx)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(traders); start += types.MaxBatchQueryTraders {
+		end := start + types.MaxBatchQueryTraders
+		if end > len(traders) {
+			end = len(traders)
+		}
+		summaries, err := batch.BatchQueryAccounts(ctx, traders[start:end])
+		if err != nil {
+			return err
+		}
+		for _, summary := range summaries {
+			if len(summary.Positions) == 0 {
+				zero := math.LegacyZeroDec()
+				s.marginCalls.evaluate(summary.Trader, &zero)
+				continue
+			}
+			maintenance, equity, ratio, err := marginRatio(summary)
+			if err != nil {
+				log.Printf("Margin call check skipped for %s: %v", summary.Trader, err)
+				continue
+			}
+			level, repeat, notify := s.marginCalls.evaluate(summary.Trader, ratio)
+			if !notify {
+				continue
+			}
+
+			call := &types.MarginCall{
+				Trader:            summary.Trader,
+				Level:             s.marginCalls.levels[level].String(),
+				MarginRatio:       "inf",
+				Equity:            equity.String(),
+				MaintenanceMargin: maintenance.String(),
+				Positions:         len(summary.Positions),
+				Repeat:            repeat,
+				Timestamp:         s.marginCalls.now().UnixMilli(),
+			}
+			if ratio != nil {
+				call.MarginRatio = ratio.String()
+			}
+			s.publishMarginCall(call)
+		}
+	}
+	return nil
+}
+
+// publishMarginCall notifies the trader over WebSocket and webhooks, then
+// hands the call to in-process listeners
+func (s *Server) publishMarginCall(call *types.MarginCall) {
+	if s.wsServer != nil {
+		s.wsServer.GetHub().BroadcastMarginCall(call.Trader, call)
+	}
+	if s.webhooks != nil {
+		s.webhooks.PublishAccountEvent(call.Trader, webhook.EventMarginCall, call)
+	}
+
+	s.marginCalls.mu.Lock()
+	listeners := append([]MarginCallListener(nil), s.marginCalls.listeners...)
+	s.marginCalls.mu.Unlock()
+	for _, listener := range listeners {
+		listener(call)
+	}
+}
+
+// startMarginCallMonitor checks every account each Config.MarginCallInterval
+// until the server stops
+func (s *Server) startMarginCallMonitor() {
+	if s.marginCalls == nil {
+		return
+	}
+	lister, batch, ok := s.equitySources()
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(s.marginCalls.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+		if err := s.checkMarginCalls(context.Background(), lister, batch); err != nil {
+			log.Printf("Margin call check failed: %v", err)
+		}
+	}
+}
+
+// handleMarginCalls handles /v1/account/margin-calls (GET settings, POST
+// {"opt_out": bool})
+func (s *Server) handleMarginCalls(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if s.marginCalls == nil {
+		writeError(w, types.ErrCodeNotImplemented, "Margin calls are disabled on this server")
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.marginCalls.settings(trader))
+
+	case http.MethodPost:
+		var req struct {
+			OptOut *bool `json:"opt_out"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		if req.OptOut == nil {
+			writeError(w, types.ErrCodeMissingField, "opt_out is required")
+			return
+		}
+		s.marginCalls.setOptOut(trader, *req.OptOut)
+		writeJSON(w, http.StatusOK, s.marginCalls.settings(trader))
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestMarginCalls tests level crossings, rate-limited repeats, re-arming on
+// recovery and opt-out
+func TestMarginCalls(t *testing.T) {
+	svc := NewMockService()
+	ctx := context.Background()
+	if _, err := svc.Deposit(ctx, &types.DepositRequest{Trader: "alice", Amount: "1000"}); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	// 0.5 BTC at 50000 with 2.5% maintenance: 625 of maintenance margin
+	pos := &types.Position{MarketID: "BTC-USDC", Trader: "alice", Side: "long", Size: "0.5", MarkPrice: "50000", UnrealizedPnl: "0"}
+	svc.positions["alice:BTC-USDC"] = pos
+
+	s := &Server{
+		accountService: svc,
+		marginCalls:    newMarginCalls(&Config{MarginCallRepeat: time.Minute}),
+	}
+	clock := time.UnixMilli(1_700_000_000_000)
+	s.marginCalls.now = func() time.Time { return clock }
+
+	var calls []*types.MarginCall
+	s.AddMarginCallListener(func(call *types.MarginCall) { calls = append(calls, call) })
+	lister, batch, _ := s.equitySources()
+	check := func() {
+		t.Helper()
+		if err := s.checkMarginCalls(ctx, lister, batch); err != nil {
+			t.Fatalf("failed to check margin calls: %v", err)
+		}
+	}
+
+	// 625 / 1000 is below the first level
+	check()
+	if len(calls) != 0 {
+		t.Fatalf("expected no margin call at 62.5%%, got %+v", calls)
+	}
+
+	// 625 / 750 crosses 80%
+	pos.UnrealizedPnl = "-250"
+	check()
+	if len(calls) != 1 || calls[0].Level != "0.800000000000000000" || calls[0].Repeat {
+		t.Fatalf("expected a first call at 0.8, got %+v", calls)
+	}
+
+	// Same level within the repeat interval stays quiet, then repeats
+	check()
+	clock = clock.Add(time.Minute)
+	check()
+	if len(calls) != 2 || !calls[1].Repeat {
+		t.Fatalf("expected one rate-limited repeat, got %d calls", len(calls))
+	}
+
+	// 625 / 680 crosses 90% immediately
+	pos.UnrealizedPnl = "-320"
+	check()
+	if len(calls) != 3 || calls[2].Level != "0.900000000000000000" {
+		t.Fatalf("expected a call at 0.9, got %+v", calls[len(calls)-1])
+	}
+
+	// Recovering re-arms the levels; opted-out traders are not notified
+	pos.UnrealizedPnl = "0"
+	check()
+	s.marginCalls.setOptOut("alice", true)
+	pos.UnrealizedPnl = "-320"
+	check()
+	if len(calls) != 3 {
+		t.Fatalf("expected no call after opting out, got %d calls", len(calls))
+	}
+
+	// Exhausted equity counts as above every level
+	s.marginCalls.setOptOut("alice", false)
+	pos.UnrealizedPnl = "-1000"
+	clock = clock.Add(time.Minute)
+	check()
+	if len(calls) != 4 || calls[3].MarginRatio != "inf" {
+		t.Fatalf("expected a call with an infinite ratio, got %+v", calls[len(calls)-1])
+	}
+}
+
+// TestMarginCallSettingsEndpoint tests the opt-out endpoint
+func TestMarginCallSettingsEndpoint(t *testing.T) {
+	s := &Server{marginCalls: newMarginCalls(&Config{MarginCallLevels: []float64{0.9, 0.75}})}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/account/margin-calls", strings.NewReader(`{"opt_out":true}`))
+	req.Header.Set("X-Trader-Address", "bob")
+	s.handleMarginCalls(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"opt_out":true`) {
+		t.Fatalf("expected opt-out to be saved, got %d: %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), `"levels":["0.750000000000000000","0.900000000000000000"]`) {
+		t.Errorf("expected sorted levels, got %s", rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleMarginCalls(rec, httptest.NewRequest(http.MethodGet, "/v1/account/margin-calls", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without a trader, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/openalpha/perp-dex/api/types"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// startMarginRecheckScheduler re-checks resting order margin when the order
+// service keeps its own books, every params interval in seconds; on chain
+// the EndBlocker does it every interval blocks. Cancellations reach WebSocket
+// subscribers as order updates through the event publisher.
+func (s *Server) startMarginRecheckScheduler() {
+	recheck, ok := s.orderService.(types.MarginRecheckService)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		status, err := recheck.GetMarginRecheck(context.Background())
+		if err != nil || status.Params.Interval == 0 || time.Since(last) < time.Duration(status.Params.Interval)*time.Second {
+			continue
+		}
+		last = time.Now()
+
+		result, err := recheck.RecheckOrderMargin(context.Background())
+		if err != nil {
+			log.Printf("Margin recheck: %v", err)
+			continue
+		}
+		if len(result.Cancelled) > 0 || len(result.Flagged) > 0 {
+			log.Printf("Margin recheck: cancelled %d and flagged %d of %d resting orders",
+				len(result.Cancelled), len(result.Flagged), result.Checked)
+		}
+	}
+}
+
+// handleAdminMarginRecheck handles /v1/admin/margin-recheck (GET, PUT): the
+// resting order margin re-check params with the flagged orders
+func (s *Server) handleAdminMarginRecheck(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	recheck, ok := s.orderService.(types.MarginRecheckService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Margin recheck requires a keeper-backed service")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		status, err := recheck.GetMarginRecheck(r.Context())
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+
+	case http.MethodPut:
+		var req types.MarginRecheckParams
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		status, err := recheck.SetMarginRecheck(r.Context(), &req)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleAdminMarginRecheckRun handles POST /v1/admin/margin-recheck/run,
+// re-checking every resting order now
+func (s *Server) handleAdminMarginRecheckRun(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	recheck, ok := s.orderService.(types.MarginRecheckService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Margin recheck requires a keeper-backed service")
+		return
+	}
+	result, err := recheck.RecheckOrderMargin(r.Context())
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (rs *RealService) GetMarginRecheck(ctx context.Context) (*types.MarginRecheckStatus, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	return rs.marginRecheckStatus(rs.sdkCtx), nil
+}
+
+func (rs *RealService) SetMarginRecheck(ctx context.Context, params *types.MarginRecheckParams) (*types.MarginRecheckStatus, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	sdkCtx := rs.clockCtx()
+	if err := rs.obKeeper.SetMarginRecheckParams(sdkCtx, &obtypes.MarginRecheckParams{
+		Interval: params.Interval,
+		Action:   params.Action,
+	}); err != nil {
+		return nil, err
+	}
+	return rs.marginRecheckStatus(sdkCtx), nil
+}
+
+// RecheckOrderMargin re-checks every resting order's margin at the mark price
+func (rs *RealService) RecheckOrderMargin(ctx context.Context) (*types.MarginRecheckResult, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	result := rs.obKeeper.RecheckOrderMargin(rs.clockCtx())
+	rs.matchEngine.Flush(rs.sdkCtx)
+
+	resp := &types.MarginRecheckResult{
+		Checked:   result.Checked,
+		Cancelled: make([]*types.Order, 0, len(result.Cancelled)),
+		Flagged:   make([]*types.MarginFlag, 0, len(result.Flagged)),
+	}
+	for _, order := range result.Cancelled {
+		resp.Cancelled = append(resp.Cancelled, rs.convertOrder(order))
+	}
+	for _, flag := range result.Flagged {
+		resp.Flagged = append(resp.Flagged, convertMarginFlag(flag))
+	}
+	return resp, nil
+}
+
+// marginRecheckStatus reports the re-check params with the flagged orders
+func (rs *RealService) marginRecheckStatus(sdkCtx sdk.Context) *types.MarginRecheckStatus {
+	params := rs.obKeeper.GetMarginRecheckParams(sdkCtx)
+	status := &types.MarginRecheckStatus{
+		Params:  &types.MarginRecheckParams{Interval: params.Interval, Action: params.Action},
+		Flagged: make([]*types.MarginFlag, 0),
+	}
+	if !params.UpdatedAt.IsZero() {
+		status.Params.UpdatedAt = params.UpdatedAt.UnixMilli()
+	}
+	for _, flag := range rs.obKeeper.GetAllMarginFlags(sdkCtx) {
+		status.Flagged = append(status.Flagged, convertMarginFlag(flag))
+	}
+	return status
+}
+
+func convertMarginFlag(flag *obtypes.MarginFlag) *types.MarginFlag {
+	return &types.MarginFlag{
+		OrderID:      flag.OrderID,
+		Trader:       flag.Trader,
+		MarketID:     flag.MarketID,
+		MarkPrice:    flag.MarkPrice.String(),
+		RemainingQty: flag.RemainingQty.String(),
+		Reason:       flag.Reason,
+		FlaggedAt:    flag.FlaggedAt.UnixMilli(),
+	}
+}
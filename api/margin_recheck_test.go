@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestAdminMarginRecheck tests that operators can read and change the
+// resting order margin re-check and run it on demand
+func TestAdminMarginRecheck(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	config.AdminToken = "admin-secret"
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	admin := func(method, target, body string, v interface{}) int {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(adminTokenHeader, config.AdminToken)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("%s %s: invalid JSON %q: %v", method, target, rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+
+	var status types.MarginRecheckStatus
+	if code := admin(http.MethodGet, "/v1/admin/margin-recheck", "", &status); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if status.Params.Interval != 10 || status.Params.Action != "cancel" || len(status.Flagged) != 0 {
+		t.Errorf("expected the default params and no flags, got %+v", status)
+	}
+
+	if code := admin(http.MethodPut, "/v1/admin/margin-recheck", `{"interval":30,"action":"flag"}`, &status); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if status.Params.Interval != 30 || status.Params.Action != "flag" || status.Params.UpdatedAt == 0 {
+		t.Errorf("expected the new params, got %+v", status.Params)
+	}
+	if code := admin(http.MethodPut, "/v1/admin/margin-recheck", `{"interval":30,"action":"close"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown action, got %d", code)
+	}
+
+	var result types.MarginRecheckResult
+	if code := admin(http.MethodPost, "/v1/admin/margin-recheck/run", "", &result); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(result.Cancelled) != 0 || len(result.Flagged) != 0 {
+		t.Errorf("expected nothing to fail on an empty book, got %+v", result)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/margin-recheck", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without the admin token, got %d", rec.Code)
+	}
+}
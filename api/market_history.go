@@ -0,0 +1,342 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// Market history defaults
+const (
+	DefaultMarketStatsInterval  = time.Minute
+	DefaultMarketStatsRetention = 30 * 24 * time.Hour
+	DefaultMarketHistoryPoints  = 100  // buckets returned when from is unset
+	MaxMarketHistoryPoints      = 1000 // buckets a single request may span
+)
+
+// marketHistoryBuckets are the bucket sizes of GET /v1/markets/{id}/history
+var marketHistoryBuckets = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// marketStatsSample is a market's funding rate and open interest at one point
+type marketStatsSample struct {
+	at           int64 // Unix millis
+	fundingRate  math.LegacyDec
+	openInterest math.LegacyDec
+}
+
+// volumeMinute is the trading volume of a market in one minute
+type volumeMinute struct {
+	start    int64 // Unix millis
+	quantity math.LegacyDec
+	notional math.LegacyDec
+	trades   int
+}
+
+// marketHistory keeps each market's funding rate and open interest, sampled
+// by startMarketStatsSampler, and its traded volume by minute, fed from the
+// event log, for GET /v1/markets/{id}/history
+type marketHistory struct {
+	interval  time.Duration
+	retention time.Duration
+	now       func() time.Time
+
+	mu      sync.RWMutex
+	samples map[string][]*marketStatsSample // market -> samples, oldest first
+	volume  map[string][]*volumeMinute      // market -> minutes with trades, oldest first
+}
+
+// newMarketHistory returns nil, disabling market history, when
+// Config.MarketStatsInterval is negative
+func newMarketHistory(config *Config) *marketHistory {
+	interval := config.MarketStatsInterval
+	if interval < 0 {
+		return nil
+	}
+	if interval == 0 {
+		interval = DefaultMarketStatsInterval
+	}
+	retention := config.MarketStatsRetention
+	if retention <= 0 {
+		retention = DefaultMarketStatsRetention
+	}
+	return &marketHistory{
+		interval:  interval,
+		retention: retention,
+		now:       time.Now,
+		samples:   make(map[string][]*marketStatsSample),
+		volume:    make(map[string][]*volumeMinute),
+	}
+}
+
+// cutoff returns the oldest time still retained, in Unix millis
+func (h *marketHistory) cutoff() int64 {
+	return h.now().Add(-h.retention).UnixMilli()
+}
+
+// recordSample appends a stats sample, dropping those past the retention
+func (h *marketHistory) recordSample(marketID string, sample *marketStatsSample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list := h.samples[marketID]
+	cutoff := h.cutoff()
+	drop := 0
+	for drop < len(list) && list[drop].at < cutoff {
+		drop++
+	}
+	h.samples[marketID] = append(list[drop:], sample)
+}
+
+// recordTrade adds a trade to the volume of its minute, dropping minutes past
+// the retention. Trades normally arrive in order; a late trade is added to
+// its minute if that is still retained.
+func (h *marketHistory) recordTrade(marketID string, price, quantity math.LegacyDec, timestamp int64) {
+	start := timestamp - timestamp%time.Minute.Milliseconds()
+	cutoff := h.cutoff()
+	if start+time.Minute.Milliseconds() <= cutoff {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list := h.volume[marketID]
+	i := sort.Search(len(list), func(i int) bool { return list[i].start >= start })
+	if i == len(list) || list[i].start != start {
+		list = append(list, nil)
+		copy(list[i+1:], list[i:])
+		list[i] = &volumeMinute{start: start, quantity: math.LegacyZeroDec(), notional: math.LegacyZeroDec()}
+	}
+	minute := list[i]
+	minute.quantity = minute.quantity.Add(quantity)
+	minute.notional = minute.notional.Add(price.Mul(quantity))
+	minute.trades++
+
+	drop := 0
+	for drop < len(list) && list[drop].start+time.Minute.Milliseconds() <= cutoff {
+		drop++
+	}
+	h.volume[marketID] = list[drop:]
+}
+
+// query buckets a metric of a market over [from, to), oldest first
+func (h *marketHistory) query(marketID, metric string, bucket time.Duration, from, to int64) []*types.MarketHistoryPoint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	size := bucket.Milliseconds()
+	bucketStart := func(t int64) int64 { return t - t%size }
+	points := make([]*types.MarketHistoryPoint, 0)
+
+	if metric == types.MarketHistoryVolume {
+		var point *types.MarketHistoryPoint
+		var quantity, notional math.LegacyDec
+		flush := func() {
+			if point != nil {
+				point.Value, point.Notional = quantity.String(), notional.String()
+				points = append(points, point)
+			}
+		}
+		for _, minute := range h.volume[marketID] {
+			if minute.start < from || minute.start >= to {
+				continue
+			}
+			if start := bucketStart(minute.start); point == nil || point.Timestamp != start {
+				flush()
+				point = &types.MarketHistoryPoint{Timestamp: start}
+				quantity, notional = math.LegacyZeroDec(), math.LegacyZeroDec()
+			}
+			quantity = quantity.Add(minute.quantity)
+			notional = notional.Add(minute.notional)
+			point.Trades += minute.trades
+		}
+		flush()
+		return points
+	}
+
+	var point *types.MarketHistoryPoint
+	var sum, last math.LegacyDec
+	flush := func() {
+		if point == nil {
+			return
+		}
+		if metric == types.MarketHistoryFunding {
+			point.Value = sum.QuoInt64(int64(point.Samples)).String()
+		} else {
+			point.Value = last.String()
+		}
+		points = append(points, point)
+	}
+	for _, sample := range h.samples[marketID] {
+		if sample.at < from || sample.at >= to {
+			continue
+		}
+		if start := bucketStart(sample.at); point == nil || point.Timestamp != start {
+			flush()
+			point = &types.MarketHistoryPoint{Timestamp: start}
+			sum = math.LegacyZeroDec()
+		}
+		sum = sum.Add(sample.fundingRate)
+		last = sample.openInterest
+		point.Samples++
+	}
+	flush()
+	return points
+}
+
+// recordMarketVolume adds an event log trade to its market's volume history
+func (s *Server) recordMarketVolume(trade *types.MarketTrade) {
+	if s.marketHistory == nil {
+		return
+	}
+	price, err := math.LegacyNewDecFromStr(trade.Price)
+	if err != nil {
+		return
+	}
+	quantity, err := math.LegacyNewDecFromStr(trade.Quantity)
+	if err != nil {
+		return
+	}
+	s.marketHistory.recordTrade(trade.MarketID, price, quantity, trade.Timestamp)
+}
+
+// sampleMarketStats records one funding rate and open interest sample of
+// every market, read as GET /v1/snapshot reads them
+func (s *Server) sampleMarketStats(ctx context.Context) error {
+	var marketIDs []string
+	for _, market := range s.getMockMarkets() {
+		marketIDs = append(marketIDs, market["market_id"].(string))
+	}
+	snapshot, err := s.exchangeSnapshot(ctx, marketIDs, 1)
+	if err != nil {
+		return err
+	}
+	at := s.marketHistory.now().UnixMilli()
+	for _, market := range snapshot.Markets {
+		fundingRate, err := math.LegacyNewDecFromStr(market.FundingRate)
+		if err != nil {
+			log.Printf("Market stats sample skipped for %s: funding rate %q: %v", market.MarketID, market.FundingRate, err)
+			continue
+		}
+		openInterest, err := math.LegacyNewDecFromStr(market.OpenInterest)
+		if err != nil {
+			log.Printf("Market stats sample skipped for %s: open interest %q: %v", market.MarketID, market.OpenInterest, err)
+			continue
+		}
+		s.marketHistory.recordSample(market.MarketID, &marketStatsSample{
+			at:           at,
+			fundingRate:  fundingRate,
+			openInterest: openInterest,
+		})
+	}
+	return nil
+}
+
+// startMarketStatsSampler samples every market's funding rate and open
+// interest each Config.MarketStatsInterval until the server stops
+func (s *Server) startMarketStatsSampler() {
+	if s.marketHistory == nil {
+		return
+	}
+
+	ticker := time.NewTicker(s.marketHistory.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+		if err := s.sampleMarketStats(context.Background()); err != nil {
+			log.Printf("Market stats sample failed: %v", err)
+		}
+	}
+}
+
+// handleMarketHistory handles GET /v1/markets/{id}/history
+// Query params: metric (funding, oi or volume), bucket (default 1h),
+// from, to (Unix millis; to defaults to now, from to DefaultMarketHistoryPoints
+// buckets before it)
+func (s *Server) handleMarketHistory(w http.ResponseWriter, r *http.Request, marketID string) {
+	if s.marketHistory == nil {
+		writeError(w, types.ErrCodeNotImplemented, "Market history is not available on this server")
+		return
+	}
+	if s.getMockMarket(marketID) == nil {
+		writeError(w, types.ErrCodeMarketNotFound, "Market not found")
+		return
+	}
+
+	query := r.URL.Query()
+	metric := query.Get("metric")
+	switch metric {
+	case types.MarketHistoryFunding, types.MarketHistoryOI, types.MarketHistoryVolume:
+	case "":
+		writeError(w, types.ErrCodeMissingField, "metric is required")
+		return
+	default:
+		writeError(w, types.ErrCodeInvalidRequest, "metric must be funding, oi or volume")
+		return
+	}
+	bucketName := query.Get("bucket")
+	if bucketName == "" {
+		bucketName = "1h"
+	}
+	bucket, ok := marketHistoryBuckets[bucketName]
+	if !ok {
+		writeError(w, types.ErrCodeInvalidRequest, "bucket must be one of 1m, 5m, 15m, 1h, 4h, 1d")
+		return
+	}
+
+	var from, to int64
+	for name, dst := range map[string]*int64{"from": &from, "to": &to} {
+		if v := query.Get(name); v != "" {
+			ms, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || ms < 0 {
+				writeError(w, types.ErrCodeInvalidRequest, name+" must be a non-negative Unix millisecond timestamp")
+				return
+			}
+			*dst = ms
+		}
+	}
+	if to == 0 {
+		to = s.marketHistory.now().UnixMilli()
+	}
+	if from == 0 {
+		from = to - DefaultMarketHistoryPoints*bucket.Milliseconds()
+	}
+	if from >= to {
+		writeError(w, types.ErrCodeInvalidRequest, "from must be before to")
+		return
+	}
+	if (to-from)/bucket.Milliseconds() > MaxMarketHistoryPoints {
+		writeError(w, types.ErrCodeInvalidRequest, fmt.Sprintf("at most %d buckets per request; use a larger bucket or a shorter range", MaxMarketHistoryPoints))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &types.MarketHistoryResponse{
+		MarketID: marketID,
+		Metric:   metric,
+		Bucket:   bucketName,
+		From:     from,
+		To:       to,
+		Points:   s.marketHistory.query(marketID, metric, bucket, from, to),
+	})
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestMarketHistory tests that funding is averaged, open interest closed and
+// volume summed per bucket, and that bad queries are rejected
+func TestMarketHistory(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	s.marketHistory.now = func() time.Time { return now }
+	base := now.Add(-2 * time.Hour).UnixMilli()
+	halfHour := (30 * time.Minute).Milliseconds()
+	for i, rate := range []string{"0.0001", "0.0003", "0.0002"} {
+		s.marketHistory.recordSample("BTC-USDC", &marketStatsSample{
+			at:           base + int64(i)*halfHour,
+			fundingRate:  math.LegacyMustNewDecFromStr(rate),
+			openInterest: math.LegacyNewDec(100 + int64(i)*10),
+		})
+	}
+	minute := time.Minute.Milliseconds()
+	s.recordMarketVolume(&types.MarketTrade{MarketID: "BTC-USDC", Price: "50000", Quantity: "1", Timestamp: base + minute})
+	s.recordMarketVolume(&types.MarketTrade{MarketID: "BTC-USDC", Price: "52000", Quantity: "2", Timestamp: base + 61*minute})
+	s.recordMarketVolume(&types.MarketTrade{MarketID: "BTC-USDC", Price: "51000", Quantity: "0.5", Timestamp: base + 59*minute})
+	s.recordMarketVolume(&types.MarketTrade{MarketID: "BTC-USDC", Price: "40000", Quantity: "9", Timestamp: now.Add(-DefaultMarketStatsRetention - time.Hour).UnixMilli()})
+	if minutes := len(s.marketHistory.volume["BTC-USDC"]); minutes != 3 {
+		t.Errorf("expected a trade past the retention to be dropped, got %d minutes", minutes)
+	}
+
+	get := func(query string, v interface{}) int {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/markets/BTC-USDC/history?"+query, nil))
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+	series := func(metric string) []*types.MarketHistoryPoint {
+		t.Helper()
+		var resp types.MarketHistoryResponse
+		if code := get("metric="+metric+"&from="+strconv.FormatInt(base, 10), &resp); code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", metric, code)
+		}
+		if resp.Bucket != "1h" || resp.To != now.UnixMilli() || len(resp.Points) != 2 {
+			t.Fatalf("%s: expected 2 hourly points up to now, got %+v", metric, resp)
+		}
+		if resp.Points[0].Timestamp != base || resp.Points[1].Timestamp != base+time.Hour.Milliseconds() {
+			t.Errorf("%s: unexpected bucket starts %d, %d", metric, resp.Points[0].Timestamp, resp.Points[1].Timestamp)
+		}
+		return resp.Points
+	}
+	expectValue := func(metric, got, want string) {
+		t.Helper()
+		if !math.LegacyMustNewDecFromStr(got).Equal(math.LegacyMustNewDecFromStr(want)) {
+			t.Errorf("%s: expected %s, got %s", metric, want, got)
+		}
+	}
+
+	funding := series(types.MarketHistoryFunding)
+	expectValue("funding", funding[0].Value, "0.0002")
+	expectValue("funding", funding[1].Value, "0.0002")
+	if funding[0].Samples != 2 || funding[1].Samples != 1 {
+		t.Errorf("expected 2 and 1 samples, got %d and %d", funding[0].Samples, funding[1].Samples)
+	}
+
+	oi := series(types.MarketHistoryOI)
+	expectValue("oi", oi[0].Value, "110")
+	expectValue("oi", oi[1].Value, "120")
+
+	volume := series(types.MarketHistoryVolume)
+	expectValue("volume", volume[0].Value, "1.5")
+	expectValue("volume notional", volume[0].Notional, "75500")
+	expectValue("volume", volume[1].Value, "2")
+	if volume[0].Trades != 2 || volume[1].Trades != 1 {
+		t.Errorf("expected 2 and 1 trades, got %d and %d", volume[0].Trades, volume[1].Trades)
+	}
+
+	var apiErr types.APIError
+	testCases := []struct {
+		query string
+		code  types.ErrorCode
+	}{
+		{"", types.ErrCodeMissingField},
+		{"metric=price", types.ErrCodeInvalidRequest},
+		{"metric=oi&bucket=2h", types.ErrCodeInvalidRequest},
+		{"metric=oi&from=2000&to=1000", types.ErrCodeInvalidRequest},
+		{"metric=oi&bucket=1m&from=1&to=" + strconv.FormatInt(now.UnixMilli(), 10), types.ErrCodeInvalidRequest},
+	}
+	for _, tc := range testCases {
+		if code := get(tc.query, &apiErr); code != http.StatusBadRequest || apiErr.Code != tc.code {
+			t.Errorf("%q: expected 400 %s, got %d %s", tc.query, tc.code, code, apiErr.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/markets/NOPE-USDC/history?metric=oi", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown market, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,537 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// AuditRouteAll matches every route without a more specific audit rule
+const AuditRouteAll = "*"
+
+// DefaultAuditSampling audits every request to the trading, account and
+// admin endpoints and one in a hundred of the rest
+const DefaultAuditSampling = "/v1/orders=100;/v1/positions=100;/v1/account=100;/v1/tx=100;/v1/admin=100;*=1"
+
+// Audit log rotation defaults
+const (
+	DefaultAuditLogMaxSize  = 100 << 20 // bytes per file
+	DefaultAuditLogMaxFiles = 10        // rotated files kept besides the live one
+)
+
+// maxAuditBody is the largest request body digested; larger bodies are
+// logged with their size only
+const maxAuditBody = 1 << 20
+
+// auditQueueSize is the number of records buffered for the writer; records
+// beyond it are dropped rather than delaying requests
+const auditQueueSize = 4096
+
+// auditRedacted replaces redacted values before a body is digested
+const auditRedacted = "[REDACTED]"
+
+// auditSensitiveKeys are substrings of field names, lowercased without
+// separators, whose values never reach a digest
+var auditSensitiveKeys = []string{
+	"signature", "secret", "password", "passphrase", "token", "apikey",
+	"privkey", "privatekey", "mnemonic", "seed", "email", "phone", "url", "otp",
+}
+
+// AuditRule sets the share of requests to a route that are audited
+type AuditRule struct {
+	Route   string  // Path prefix or AuditRouteAll
+	Percent float64 // In [0, 100]
+}
+
+// ParseAuditRules parses a sampling spec of semicolon-separated
+// route=percent rules, e.g. "/v1/orders=100;/v1/markets=0.5;*=1". A request
+// is sampled by its longest matching route only; routes matching no rule
+// are not audited.
+func ParseAuditRules(spec string) ([]AuditRule, error) {
+	var rules []AuditRule
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		route, pctStr, ok := strings.Cut(part, "=")
+		if !ok || route == "" {
+			return nil, fmt.Errorf("invalid audit rule %q (want route=percent)", part)
+		}
+		if route != AuditRouteAll && !strings.HasPrefix(route, "/") {
+			return nil, fmt.Errorf("invalid audit route %q (want a path prefix or %q)", route, AuditRouteAll)
+		}
+		pct, err := strconv.ParseFloat(pctStr, 64)
+		if err != nil || pct < 0 || pct > 100 {
+			return nil, fmt.Errorf("audit rule %q has invalid percent %q", part, pctStr)
+		}
+		rules = append(rules, AuditRule{Route: route, Percent: pct})
+	}
+	return rules, nil
+}
+
+// AuditRecord is one audited request. Request bodies are never logged: the
+// digest is the SHA-256 of the body as canonical JSON with sensitive fields
+// replaced, so a body recovered elsewhere can be matched to its request.
+type AuditRecord struct {
+	Time       int64   `json:"time"` // Unix ms the request arrived
+	RequestID  string  `json:"request_id,omitempty"`
+	Method     string  `json:"method"`
+	Route      string  `json:"route"` // path with ID segments replaced by ":id"
+	Trader     string  `json:"trader,omitempty"`
+	Status     int     `json:"status"`
+	LatencyMs  float64 `json:"latency_ms"`
+	BodyBytes  int64   `json:"body_bytes"`
+	BodyDigest string  `json:"body_digest,omitempty"` // empty for empty, non-JSON or oversized bodies
+
+	RedactedFields []string `json:"redacted_fields,omitempty"` // paths of fields replaced before digesting
+	ResponseBytes  int64    `json:"response_bytes"`
+}
+
+// AuditSink receives sampled audit records, one at a time
+type AuditSink interface {
+	WriteAudit(record *AuditRecord) error
+}
+
+// AuditStats counts audit records
+type AuditStats struct {
+	Sampled int64 `json:"sampled"`
+	Written int64 `json:"written"`
+	Dropped int64 `json:"dropped"` // queue full or logger closed
+	Failed  int64 `json:"failed"`  // sink errors
+}
+
+// AuditLogger samples requests and hands their records to a sink from a
+// background writer, so a slow disk never delays a request
+type AuditLogger struct {
+	rules []AuditRule // longest route first
+	sink  AuditSink
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+
+	queue chan *AuditRecord
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	stats AuditStats
+}
+
+// NewAuditLogger starts an audit logger writing sampled records to sink
+func NewAuditLogger(rules []AuditRule, sink AuditSink) *AuditLogger {
+	a := &AuditLogger{
+		rules: append([]AuditRule(nil), rules...),
+		sink:  sink,
+		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		queue: make(chan *AuditRecord, auditQueueSize),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	sort.SliceStable(a.rules, func(i, j int) bool {
+		return auditRouteLen(a.rules[i].Route) > auditRouteLen(a.rules[j].Route)
+	})
+	go a.run()
+	return a
+}
+
+func auditRouteLen(route string) int {
+	if route == AuditRouteAll {
+		return 0
+	}
+	return len(route)
+}
+
+// sampled reports whether a request to path is audited
+func (a *AuditLogger) sampled(path string) bool {
+	for _, rule := range a.rules {
+		if rule.Route != AuditRouteAll && !strings.HasPrefix(path, rule.Route) {
+			continue
+		}
+		if rule.Percent <= 0 {
+			return false
+		}
+		if rule.Percent >= 100 {
+			return true
+		}
+		a.randMu.Lock()
+		defer a.randMu.Unlock()
+		return a.rand.Float64()*100 < rule.Percent
+	}
+	return false
+}
+
+// record queues a record for the writer, dropping it when the queue is full
+// or the logger is closed
+func (a *AuditLogger) record(rec *AuditRecord) {
+	atomic.AddInt64(&a.stats.Sampled, 1)
+	select {
+	case <-a.stop:
+		atomic.AddInt64(&a.stats.Dropped, 1)
+		return
+	default:
+	}
+	select {
+	case a.queue <- rec:
+	default:
+		atomic.AddInt64(&a.stats.Dropped, 1)
+	}
+}
+
+func (a *AuditLogger) run() {
+	defer close(a.done)
+	for {
+		select {
+		case rec := <-a.queue:
+			a.write(rec)
+		case <-a.stop:
+			for {
+				select {
+				case rec := <-a.queue:
+					a.write(rec)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (a *AuditLogger) write(rec *AuditRecord) {
+	if err := a.sink.WriteAudit(rec); err != nil {
+		if atomic.AddInt64(&a.stats.Failed, 1) == 1 {
+			log.Printf("Audit log write failed: %v", err)
+		}
+		return
+	}
+	atomic.AddInt64(&a.stats.Written, 1)
+}
+
+// Close writes the queued records and closes the sink if it is an io.Closer.
+// Records of requests completing afterwards are dropped.
+func (a *AuditLogger) Close() error {
+	var err error
+	a.once.Do(func() {
+		close(a.stop)
+		<-a.done
+		if closer, ok := a.sink.(io.Closer); ok {
+			err = closer.Close()
+		}
+	})
+	return err
+}
+
+// Stats returns the audit record counts
+func (a *AuditLogger) Stats() AuditStats {
+	return AuditStats{
+		Sampled: atomic.LoadInt64(&a.stats.Sampled),
+		Written: atomic.LoadInt64(&a.stats.Written),
+		Dropped: atomic.LoadInt64(&a.stats.Dropped),
+		Failed:  atomic.LoadInt64(&a.stats.Failed),
+	}
+}
+
+// AuditMiddleware records sampled requests with their route, trader, status
+// and latency. It reads the request ID from the response header, so it may
+// wrap RequestIDMiddleware. Health checks and WebSocket upgrades are never
+// audited.
+func AuditMiddleware(a *AuditLogger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isAuditExempt(r) || !a.sampled(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			rec := &AuditRecord{
+				Time:   start.UnixMilli(),
+				Method: r.Method,
+				Route:  auditRoute(r.URL.Path),
+				Trader: r.Header.Get("X-Trader-Address"),
+			}
+			var body map[string]interface{}
+			if r.Body != nil && r.Body != http.NoBody {
+				data, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBody+1))
+				r.Body = readCloser{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+				rec.BodyBytes = int64(len(data))
+				if err == nil && len(data) <= maxAuditBody {
+					rec.BodyDigest, rec.RedactedFields, body = redactedDigest(data)
+				} else if r.ContentLength > rec.BodyBytes {
+					rec.BodyBytes = r.ContentLength
+				}
+			}
+
+			aw := &auditWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(aw, r)
+
+			rec.Status = aw.status
+			rec.ResponseBytes = aw.bytes
+			rec.LatencyMs = durationMs(time.Since(start))
+			rec.RequestID = w.Header().Get(types.RequestIDHeader)
+			if rec.Trader == "" {
+				rec.Trader = getUserFromContext(r.Context())
+			}
+			if trader, ok := body["trader"].(string); ok && rec.Trader == "" {
+				rec.Trader = trader
+			}
+			a.record(rec)
+		})
+	}
+}
+
+// isAuditExempt reports whether the request is never audited
+func isAuditExempt(r *http.Request) bool {
+	return r.URL.Path == "/health" || r.URL.Path == "/v1/health" || r.URL.Path == "/ready" || r.URL.Path == "/live" ||
+		strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// readCloser reads a replayed body and closes the original
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// auditWriter records the status and size of a streamed response
+type auditWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (aw *auditWriter) WriteHeader(status int) {
+	if !aw.wroteHeader {
+		aw.wroteHeader = true
+		aw.status = status
+	}
+	aw.ResponseWriter.WriteHeader(status)
+}
+
+func (aw *auditWriter) Write(p []byte) (int, error) {
+	aw.wroteHeader = true
+	n, err := aw.ResponseWriter.Write(p)
+	aw.bytes += int64(n)
+	return n, err
+}
+
+// Flush streams the response so far
+func (aw *auditWriter) Flush() {
+	if flusher, ok := aw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// auditRoute replaces path segments that look like IDs, addresses or dates,
+// i.e. contain a digit and are either long or have no letters, with ":id",
+// so records group by endpoint and carry no identifiers in the route
+func auditRoute(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		var digit, letter bool
+		for _, c := range segment {
+			switch {
+			case c >= '0' && c <= '9':
+				digit = true
+			case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+				letter = true
+			}
+		}
+		if digit && (len(segment) >= 8 || !letter) {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// redactedDigest hashes a JSON object body with sensitive fields replaced,
+// returning the replaced field paths and the decoded body. Bodies that are
+// not a JSON object are not digested, since their secrets cannot be found.
+func redactedDigest(data []byte) (string, []string, map[string]interface{}) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return "", nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var body map[string]interface{}
+	if err := dec.Decode(&body); err != nil || body == nil {
+		return "", nil, nil
+	}
+
+	fields := make(map[string]struct{})
+	redacted := redactValue(body, "", fields).(map[string]interface{})
+	canonical, err := json.Marshal(redacted)
+	if err != nil {
+		return "", nil, nil
+	}
+	sum := sha256.Sum256(canonical)
+
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return hex.EncodeToString(sum[:]), paths, body
+}
+
+// redactValue copies v with the values of sensitive keys replaced, adding
+// their paths to fields
+func redactValue(v interface{}, path string, fields map[string]struct{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			if isSensitiveKey(key) {
+				out[key] = auditRedacted
+				fields[keyPath] = struct{}{}
+				continue
+			}
+			out[key] = redactValue(value, keyPath, fields)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = redactValue(value, path+"[]", fields)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	if normalized == "sig" || normalized == "ip" {
+		return true
+	}
+	for _, sensitive := range auditSensitiveKeys {
+		if strings.Contains(normalized, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditFile is an AuditSink appending records as JSON lines to a file. Once
+// the file would exceed its size limit it is renamed to path.1, older files
+// shift up to path.N and the oldest beyond the limit is removed.
+type AuditFile struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+// NewAuditFile opens path for appending; non-positive limits use the defaults
+func NewAuditFile(path string, maxSize int64, maxFiles int) (*AuditFile, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultAuditLogMaxSize
+	}
+	if maxFiles <= 0 {
+		maxFiles = DefaultAuditLogMaxFiles
+	}
+	f := &AuditFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *AuditFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// WriteAudit appends a record, rotating the file first if it would grow
+// past its size limit
+func (f *AuditFile) WriteAudit(record *AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return fmt.Errorf("audit log %s is closed", f.path)
+	}
+	if f.size > 0 && f.size+int64(len(line)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := f.file.Write(line)
+	f.size += int64(n)
+	return err
+}
+
+// rotate shifts the rotated files up by one and starts a new live file. A
+// failed rename keeps appending to the live file.
+func (f *AuditFile) rotate() error {
+	f.file.Close()
+	f.file = nil
+	err := f.shift()
+	if openErr := f.open(); openErr != nil {
+		return openErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return nil
+}
+
+func (f *AuditFile) shift() error {
+	for i := f.maxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(f.path, f.path+".1")
+}
+
+// Close syncs and closes the live file
+func (f *AuditFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Sync()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	f.file = nil
+	return err
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memoryAuditSink keeps audit records for inspection
+type memoryAuditSink struct {
+	mu      sync.Mutex
+	records []*AuditRecord
+}
+
+func (m *memoryAuditSink) WriteAudit(record *AuditRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, record)
+	return nil
+}
+
+// TestAuditMiddleware tests sampling, the recorded fields and that
+// sensitive fields never affect the body digest
+func TestAuditMiddleware(t *testing.T) {
+	for _, spec := range []string{"orders=100", "/v1/orders=101", "/v1/orders", "/v1/orders=x"} {
+		if _, err := ParseAuditRules(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+	rules, err := ParseAuditRules("*=0; /v1/orders=100")
+	if err != nil {
+		t.Fatalf("failed to parse rules: %v", err)
+	}
+
+	sink := &memoryAuditSink{}
+	audit := NewAuditLogger(rules, sink)
+	handler := RequestIDMiddleware(AuditMiddleware(audit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	})))
+	serve := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rec
+	}
+
+	order := `{"trader":"alice","market_id":"BTC-USDC","quantity":"1.5","signature":"%s","meta":{"api_key":"k1","webhook_url":"https://x"}}`
+	first := serve("/v1/orders", strings.Replace(order, "%s", "sig-1", 1))
+	if first.Code != http.StatusCreated || !strings.Contains(first.Body.String(), "sig-1") {
+		t.Fatalf("handler did not get the request body: %d %s", first.Code, first.Body)
+	}
+	serve("/v1/orders", strings.Replace(order, "%s", "sig-2", 1))
+	serve("/v1/orders/order-1234567/cancel", `not json`)
+	serve("/v1/markets", `{}`)
+	serve("/health", "")
+	if err := audit.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sink.records) != 3 {
+		t.Fatalf("expected 3 audited requests, got %d", len(sink.records))
+	}
+	rec := sink.records[0]
+	if rec.Method != http.MethodPost || rec.Route != "/v1/orders" || rec.Trader != "alice" || rec.Status != http.StatusCreated ||
+		rec.RequestID != first.Header().Get("X-Request-ID") || rec.BodyDigest == "" || rec.ResponseBytes != int64(first.Body.Len()) {
+		t.Errorf("unexpected audit record %+v", rec)
+	}
+	if got := strings.Join(rec.RedactedFields, ","); got != "meta.api_key,meta.webhook_url,signature" {
+		t.Errorf("unexpected redacted fields %s", got)
+	}
+	if sink.records[1].BodyDigest != rec.BodyDigest {
+		t.Error("body digest depends on a redacted field")
+	}
+	line, _ := json.Marshal(rec)
+	if strings.Contains(string(line), "sig-1") || strings.Contains(string(line), "k1") {
+		t.Errorf("audit record leaks a secret: %s", line)
+	}
+	if cancel := sink.records[2]; cancel.Route != "/v1/orders/:id/cancel" || cancel.BodyDigest != "" || cancel.BodyBytes != 8 {
+		t.Errorf("unexpected audit record for a non-JSON body %+v", cancel)
+	}
+	if stats := audit.Stats(); stats.Sampled != 3 || stats.Written != 3 {
+		t.Errorf("unexpected audit stats %+v", stats)
+	}
+}
+
+// TestAuditFileRotation tests that the audit file rotates by size and keeps
+// at most the configured number of rotated files
+func TestAuditFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	record := &AuditRecord{Method: http.MethodPost, Route: "/v1/orders", Status: http.StatusOK}
+	line, _ := json.Marshal(record)
+
+	file, err := NewAuditFile(path, int64(len(line)+1)*2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 7; i++ {
+		if err := file.WriteAudit(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := func(name string) int {
+		f, err := os.Open(name)
+		if err != nil {
+			return -1
+		}
+		defer f.Close()
+		n := 0
+		for scanner := bufio.NewScanner(f); scanner.Scan(); n++ {
+		}
+		return n
+	}
+	if got := []int{lines(path), lines(path + ".1"), lines(path + ".2"), lines(path + ".3")}; got[0] != 1 || got[1] != 2 || got[2] != 2 || got[3] != -1 {
+		t.Errorf("unexpected lines per file %v", got)
+	}
+}
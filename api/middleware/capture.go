@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// ResponseCapture is an http.ResponseWriter that buffers a handler's response
+// instead of sending it, so a middleware can inspect or rewrite it first
+type ResponseCapture struct {
+	header http.Header
+	Status int
+	Body   bytes.Buffer
+}
+
+// NewResponseCapture returns a capture that collects headers into header and
+// reports 200 unless the handler writes another status
+func NewResponseCapture(header http.Header) *ResponseCapture {
+	return &ResponseCapture{header: header, Status: http.StatusOK}
+}
+
+func (c *ResponseCapture) Header() http.Header         { return c.header }
+func (c *ResponseCapture) WriteHeader(status int)      { c.Status = status }
+func (c *ResponseCapture) Write(p []byte) (int, error) { return c.Body.Write(p) }
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// drainRetryAfterSeconds is the Retry-After hint sent while draining or warming up
+const drainRetryAfterSeconds = "5"
+
+// DrainMiddleware rejects new order flow while the server is draining.
+// Reads and cancels are still served so clients can manage existing orders
+// until the load balancer moves them to another node.
+func DrainMiddleware(isDraining func() bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isDraining() && isNewOrderRequest(r) {
+				w.Header().Set("Connection", "close")
+				w.Header().Set("Retry-After", drainRetryAfterSeconds)
+				writeAPIError(w, types.NewAPIError(types.ErrCodeServiceUnavailable, "Server is draining, new orders are not accepted"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WarmupMiddleware rejects new order flow while the server is rebuilding its
+// books on startup, so no order matches against a partial book. Reads and
+// cancels are served.
+func WarmupMiddleware(isWarmingUp func() bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isWarmingUp() && isNewOrderRequest(r) {
+				w.Header().Set("Retry-After", drainRetryAfterSeconds)
+				writeAPIError(w, types.NewAPIError(types.ErrCodeServiceUnavailable, "Server is replaying resting orders, new orders are not accepted yet"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isNewOrderRequest reports whether the request would add orders to the book
+func isNewOrderRequest(r *http.Request) bool {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/orders":
+		return true
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/v1/orders/"):
+		// Modify is cancel-and-replace
+		return true
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/positions/close":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// FaultInjectedHeader marks responses altered by fault injection so clients
+// can tell injected failures from real ones
+const FaultInjectedHeader = "X-Fault-Injected"
+
+// FaultRouteWebSocket is the rule route for outbound WebSocket messages
+const FaultRouteWebSocket = "ws"
+
+// FaultRouteAll matches every HTTP route without a more specific rule
+const FaultRouteAll = "*"
+
+// FaultRule describes the faults injected into one route. Percentages are in
+// [0, 100] and rolled independently per request or message.
+type FaultRule struct {
+	Route string // Path prefix, FaultRouteAll or FaultRouteWebSocket
+
+	Latency        time.Duration // Delay added before the handler runs
+	LatencyPercent float64
+
+	ErrorStatus  int // 5xx status returned instead of calling the handler
+	ErrorPercent float64
+
+	PartialPercent float64 // Truncate the response body mid-JSON
+	DropPercent    float64 // WebSocket only: silently discard outbound messages
+}
+
+// FaultStats counts injected faults
+type FaultStats struct {
+	Latency  int64 `json:"latency"`
+	Errors   int64 `json:"errors"`
+	Partial  int64 `json:"partial"`
+	WSDrops  int64 `json:"ws_drops"`
+	Requests int64 `json:"requests"`
+}
+
+// FaultInjector injects latency, 5xx errors, truncated responses and dropped
+// WebSocket messages for resilience testing. It must never be enabled in
+// production.
+type FaultInjector struct {
+	rules  []FaultRule // HTTP rules, longest route first
+	wsRule *FaultRule
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+
+	stats FaultStats
+}
+
+// NewFaultInjector creates an injector for the given rules
+func NewFaultInjector(rules []FaultRule) *FaultInjector {
+	f := &FaultInjector{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	for i := range rules {
+		rule := rules[i]
+		if rule.Route == FaultRouteWebSocket {
+			f.wsRule = &rule
+			continue
+		}
+		f.rules = append(f.rules, rule)
+	}
+	// Longest prefix wins; the catch-all sorts last
+	for i := 1; i < len(f.rules); i++ {
+		for j := i; j > 0 && routeLen(f.rules[j].Route) > routeLen(f.rules[j-1].Route); j-- {
+			f.rules[j], f.rules[j-1] = f.rules[j-1], f.rules[j]
+		}
+	}
+	return f
+}
+
+func routeLen(route string) int {
+	if route == FaultRouteAll {
+		return 0
+	}
+	return len(route)
+}
+
+// ParseFaultRules parses a fault spec of semicolon-separated rules:
+//
+//	/v1/orders=latency:250ms@20,error:503@5;/v1/markets=partial@10;ws=drop@2
+//
+// Each rule is a route (path prefix, "*" or "ws") and comma-separated faults
+// of the form kind[:arg]@percent. Kinds are latency (arg: duration), error
+// (arg: 5xx status, default 503), partial and drop (ws only). A request
+// gets the faults of its longest matching route only.
+func ParseFaultRules(spec string) ([]FaultRule, error) {
+	var rules []FaultRule
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		route, faults, ok := strings.Cut(part, "=")
+		if !ok || route == "" || faults == "" {
+			return nil, fmt.Errorf("invalid fault rule %q (want route=kind[:arg]@percent,...)", part)
+		}
+		if route != FaultRouteAll && route != FaultRouteWebSocket && !strings.HasPrefix(route, "/") {
+			return nil, fmt.Errorf("invalid fault route %q (want a path prefix, %q or %q)", route, FaultRouteAll, FaultRouteWebSocket)
+		}
+		rule := FaultRule{Route: route}
+		for _, fault := range strings.Split(faults, ",") {
+			if err := rule.parseFault(strings.TrimSpace(fault)); err != nil {
+				return nil, fmt.Errorf("route %s: %w", route, err)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r *FaultRule) parseFault(fault string) error {
+	kindArg, pctStr, ok := strings.Cut(fault, "@")
+	if !ok {
+		return fmt.Errorf("fault %q is missing @percent", fault)
+	}
+	pct, err := strconv.ParseFloat(pctStr, 64)
+	if err != nil || pct < 0 || pct > 100 {
+		return fmt.Errorf("fault %q has invalid percent %q", fault, pctStr)
+	}
+	kind, arg, _ := strings.Cut(kindArg, ":")
+
+	ws := r.Route == FaultRouteWebSocket
+	if ws != (kind == "drop") {
+		return fmt.Errorf("fault %q is not supported on this route", kind)
+	}
+	switch kind {
+	case "latency":
+		latency, err := time.ParseDuration(arg)
+		if err != nil || latency <= 0 {
+			return fmt.Errorf("latency fault needs a positive duration, got %q", arg)
+		}
+		r.Latency, r.LatencyPercent = latency, pct
+	case "error":
+		r.ErrorStatus = http.StatusServiceUnavailable
+		if arg != "" {
+			status, err := strconv.Atoi(arg)
+			if err != nil || status < 500 || status > 599 {
+				return fmt.Errorf("error fault needs a 5xx status, got %q", arg)
+			}
+			r.ErrorStatus = status
+		}
+		r.ErrorPercent = pct
+	case "partial":
+		r.PartialPercent = pct
+	case "drop":
+		r.DropPercent = pct
+	default:
+		return fmt.Errorf("unknown fault kind %q (want latency, error, partial or drop)", kind)
+	}
+	return nil
+}
+
+// roll reports whether a fault with the given percentage fires
+func (f *FaultInjector) roll(pct float64) bool {
+	if pct <= 0 {
+		return false
+	}
+	f.randMu.Lock()
+	defer f.randMu.Unlock()
+	return f.rand.Float64()*100 < pct
+}
+
+// match returns the rule for path, or nil
+func (f *FaultInjector) match(path string) *FaultRule {
+	for i := range f.rules {
+		if route := f.rules[i].Route; route == FaultRouteAll || strings.HasPrefix(path, route) {
+			return &f.rules[i]
+		}
+	}
+	return nil
+}
+
+// DropWSMessage reports whether an outbound WebSocket message should be
+// discarded; wire it into the hub with Hub.SetFaultDrop
+func (f *FaultInjector) DropWSMessage() bool {
+	if f.wsRule == nil || !f.roll(f.wsRule.DropPercent) {
+		return false
+	}
+	atomic.AddInt64(&f.stats.WSDrops, 1)
+	return true
+}
+
+// Stats returns the number of faults injected so far
+func (f *FaultInjector) Stats() FaultStats {
+	return FaultStats{
+		Latency:  atomic.LoadInt64(&f.stats.Latency),
+		Errors:   atomic.LoadInt64(&f.stats.Errors),
+		Partial:  atomic.LoadInt64(&f.stats.Partial),
+		WSDrops:  atomic.LoadInt64(&f.stats.WSDrops),
+		Requests: atomic.LoadInt64(&f.stats.Requests),
+	}
+}
+
+// FaultMiddleware injects the configured faults into matching routes.
+// Health checks, admin endpoints and WebSocket upgrades are never affected so
+// the server stays observable and controllable during a chaos run.
+func FaultMiddleware(f *FaultInjector) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rule := f.match(r.URL.Path)
+			if rule == nil || isFaultExempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			atomic.AddInt64(&f.stats.Requests, 1)
+
+			if f.roll(rule.LatencyPercent) {
+				atomic.AddInt64(&f.stats.Latency, 1)
+				timer := time.NewTimer(rule.Latency)
+				select {
+				case <-timer.C:
+				case <-r.Context().Done():
+					timer.Stop()
+					return
+				}
+			}
+
+			if f.roll(rule.ErrorPercent) {
+				atomic.AddInt64(&f.stats.Errors, 1)
+				w.Header().Set(FaultInjectedHeader, "error")
+				apiErr := types.NewAPIError(types.ErrCodeServiceUnavailable, "Injected fault")
+				apiErr.RequestID = w.Header().Get(types.RequestIDHeader)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(rule.ErrorStatus)
+				_ = json.NewEncoder(w).Encode(types.NewErrorResponse(apiErr))
+				return
+			}
+
+			if f.roll(rule.PartialPercent) {
+				atomic.AddInt64(&f.stats.Partial, 1)
+				rec := NewResponseCapture(w.Header())
+				next.ServeHTTP(rec, r)
+				w.Header().Set(FaultInjectedHeader, "partial")
+				w.Header().Del("Content-Length")
+				w.WriteHeader(rec.Status)
+				body := rec.Body.Bytes()
+				_, _ = w.Write(body[:len(body)/2])
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isFaultExempt reports whether the request must bypass fault injection
+func isFaultExempt(r *http.Request) bool {
+	return r.URL.Path == "/health" || r.URL.Path == "/ready" || r.URL.Path == "/live" ||
+		strings.HasPrefix(r.URL.Path, "/v1/admin/") ||
+		strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestFaultMiddleware tests rule parsing, longest-prefix matching and each
+// injected fault at 100%
+func TestFaultMiddleware(t *testing.T) {
+	for _, spec := range []string{"orders=error@5", "/v1/orders=error@101", "/v1/orders=latency@5", "/v1/orders=drop@5", "ws=error@5", "/v1/orders=error:404@5"} {
+		if _, err := ParseFaultRules(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+
+	rules, err := ParseFaultRules("*=latency:20ms@100; /v1/orders=error:502@100; /v1/markets=partial@100; ws=drop@100")
+	if err != nil {
+		t.Fatalf("failed to parse rules: %v", err)
+	}
+	f := NewFaultInjector(rules)
+	handler := FaultMiddleware(f)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"markets":["BTC-USDC","ETH-USDC"]}`))
+	}))
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	if rec := serve("/v1/orders"); rec.Code != http.StatusBadGateway || rec.Header().Get(FaultInjectedHeader) != "error" {
+		t.Errorf("expected injected 502, got %d", rec.Code)
+	}
+
+	rec := serve("/v1/markets")
+	var body map[string]interface{}
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &body) == nil {
+		t.Errorf("expected truncated JSON, got %d: %s", rec.Code, rec.Body)
+	}
+
+	start := time.Now()
+	if rec := serve("/v1/positions"); rec.Code != http.StatusOK || time.Since(start) < 20*time.Millisecond {
+		t.Errorf("expected delayed success from catch-all rule, got %d after %s", rec.Code, time.Since(start))
+	}
+	if rec := serve("/health"); rec.Code != http.StatusOK || rec.Header().Get(FaultInjectedHeader) != "" {
+		t.Errorf("expected health checks to bypass faults, got %d", rec.Code)
+	}
+
+	if !f.DropWSMessage() {
+		t.Error("expected WebSocket message to be dropped")
+	}
+	if stats := f.Stats(); stats.Errors != 1 || stats.Partial != 1 || stats.Latency != 1 || stats.WSDrops != 1 {
+		t.Errorf("unexpected fault stats %+v", stats)
+	}
+}
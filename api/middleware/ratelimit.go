@@ -2,17 +2,21 @@ package middleware
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
 )
 
 // RateLimiter implements a token bucket rate limiter
 type RateLimiter struct {
-	// Configuration
-	config *RateLimitConfig
+	// Configuration; replaced as a whole by SetConfig
+	config   *RateLimitConfig
+	configMu sync.RWMutex
 
 	// Buckets by key (IP or user ID)
 	buckets   map[string]*Bucket
@@ -117,6 +121,44 @@ func (rl *RateLimiter) Stop() {
 	rl.cleanupTicker.Stop()
 }
 
+// currentConfig returns the configuration in force. It is never modified
+// once set, so callers may read it without holding configMu.
+func (rl *RateLimiter) currentConfig() *RateLimitConfig {
+	rl.configMu.RLock()
+	defer rl.configMu.RUnlock()
+	return rl.config
+}
+
+// Config returns a copy of the configuration in force
+func (rl *RateLimiter) Config() RateLimitConfig {
+	return *rl.currentConfig()
+}
+
+// SetConfig replaces the configuration at runtime. Buckets are dropped so
+// new rates and bursts apply at once; orders already counted today still
+// count against the new daily limit.
+func (rl *RateLimiter) SetConfig(config RateLimitConfig) {
+	rl.configMu.Lock()
+	rl.config = &config
+	rl.configMu.Unlock()
+
+	rl.bucketsMu.Lock()
+	rl.buckets = make(map[string]*Bucket)
+	rl.bucketsMu.Unlock()
+
+	rl.orderBucketsMu.Lock()
+	rl.orderBuckets = make(map[string]*Bucket)
+	rl.orderBucketsMu.Unlock()
+
+	rl.dailyCountersMu.RLock()
+	for _, counter := range rl.dailyCounters {
+		counter.mu.Lock()
+		counter.limit = config.OrdersPerDay
+		counter.mu.Unlock()
+	}
+	rl.dailyCountersMu.RUnlock()
+}
+
 // cleanupLoop periodically cleans up expired buckets
 func (rl *RateLimiter) cleanupLoop() {
 	for {
@@ -132,7 +174,7 @@ func (rl *RateLimiter) cleanupLoop() {
 // cleanup removes expired buckets
 func (rl *RateLimiter) cleanup() {
 	now := time.Now()
-	threshold := now.Add(-rl.config.BucketTTL)
+	threshold := now.Add(-rl.currentConfig().BucketTTL)
 
 	rl.bucketsMu.Lock()
 	for key, bucket := range rl.buckets {
@@ -201,10 +243,11 @@ func (rl *RateLimiter) getOrderBucket(key string) *Bucket {
 		return bucket
 	}
 
+	config := rl.currentConfig()
 	bucket = &Bucket{
-		tokens:     float64(rl.config.OrderBurst),
-		maxTokens:  float64(rl.config.OrderBurst),
-		refillRate: float64(rl.config.OrdersPerSecond),
+		tokens:     float64(config.OrderBurst),
+		maxTokens:  float64(config.OrderBurst),
+		refillRate: float64(config.OrdersPerSecond),
 		lastUpdate: time.Now(),
 	}
 	rl.orderBuckets[key] = bucket
@@ -243,13 +286,15 @@ func (rl *RateLimiter) getDailyCounter(key string, limit int) *DailyCounter {
 
 // AllowIP checks if a request from an IP is allowed
 func (rl *RateLimiter) AllowIP(ip string) (bool, *RateLimitInfo) {
-	bucket := rl.getBucket("ip:"+ip, float64(rl.config.IPBurst), float64(rl.config.IPRequestsPerSecond))
+	config := rl.currentConfig()
+	bucket := rl.getBucket("ip:"+ip, float64(config.IPBurst), float64(config.IPRequestsPerSecond))
 	return rl.tryConsume(bucket, 1)
 }
 
 // AllowUser checks if a request from a user is allowed
 func (rl *RateLimiter) AllowUser(userID string) (bool, *RateLimitInfo) {
-	bucket := rl.getBucket("user:"+userID, float64(rl.config.UserBurst), float64(rl.config.UserRequestsPerSecond))
+	config := rl.currentConfig()
+	bucket := rl.getBucket("user:"+userID, float64(config.UserBurst), float64(config.UserRequestsPerSecond))
 	return rl.tryConsume(bucket, 1)
 }
 
@@ -263,7 +308,7 @@ func (rl *RateLimiter) AllowOrder(userID string) (bool, *RateLimitInfo) {
 	}
 
 	// Check daily limit
-	counter := rl.getDailyCounter("order:"+userID, rl.config.OrdersPerDay)
+	counter := rl.getDailyCounter("order:"+userID, rl.currentConfig().OrdersPerDay)
 	counter.mu.Lock()
 	defer counter.mu.Unlock()
 
@@ -326,7 +371,7 @@ func (rl *RateLimiter) tryConsume(bucket *Bucket, tokens float64) (bool, *RateLi
 
 	// Not enough tokens, block the bucket
 	bucket.blocked = true
-	bucket.blockedUntil = now.Add(rl.config.IPBlockDuration)
+	bucket.blockedUntil = now.Add(rl.currentConfig().IPBlockDuration)
 
 	retryAfter := int((tokens - bucket.tokens) / bucket.refillRate) + 1
 	return false, &RateLimitInfo{
@@ -361,29 +406,24 @@ func RateLimitMiddleware(rl *RateLimiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Skip rate limiting for health check endpoints (used by monitoring)
-			if r.URL.Path == "/health" || r.URL.Path == "/v1/health" {
+			if r.URL.Path == "/health" || r.URL.Path == "/v1/health" || r.URL.Path == "/ready" || r.URL.Path == "/live" {
 				next.ServeHTTP(w, r)
 				return
 			}
 
 			// Get client IP
-			ip := getClientIP(r)
+			ip := ClientIP(r)
 
 			// Check IP rate limit
 			allowed, info := rl.AllowIP(ip)
 			if !allowed {
-				w.Header().Set("Content-Type", "application/json")
 				w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", info.Limit))
 				w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", info.Remaining))
 				if info.RetryAfter > 0 {
 					w.Header().Set("Retry-After", fmt.Sprintf("%d", info.RetryAfter))
 				}
-				w.WriteHeader(http.StatusTooManyRequests)
-				_ = json.NewEncoder(w).Encode(map[string]interface{}{
-					"error":       "rate_limit_exceeded",
-					"message":     "Too many requests, please slow down",
-					"retry_after": info.RetryAfter,
-				})
+				writeAPIError(w, types.NewAPIError(types.ErrCodeRateLimitExceeded, "Too many requests, please slow down").
+					WithDetail("retry_after", info.RetryAfter))
 				return
 			}
 
@@ -396,18 +436,13 @@ func RateLimitMiddleware(rl *RateLimiter) func(http.Handler) http.Handler {
 			if userID != "" {
 				allowed, userInfo := rl.AllowUser(userID)
 				if !allowed {
-					w.Header().Set("Content-Type", "application/json")
 					w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", userInfo.Limit))
 					w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", userInfo.Remaining))
 					if userInfo.RetryAfter > 0 {
 						w.Header().Set("Retry-After", fmt.Sprintf("%d", userInfo.RetryAfter))
 					}
-					w.WriteHeader(http.StatusTooManyRequests)
-					_ = json.NewEncoder(w).Encode(map[string]interface{}{
-						"error":       "rate_limit_exceeded",
-						"message":     "User rate limit exceeded",
-						"retry_after": userInfo.RetryAfter,
-					})
+					writeAPIError(w, types.NewAPIError(types.ErrCodeRateLimitExceeded, "User rate limit exceeded").
+						WithDetail("retry_after", userInfo.RetryAfter))
 					return
 				}
 			}
@@ -423,30 +458,20 @@ func OrderRateLimitMiddleware(rl *RateLimiter) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			userID := getUserFromContext(r.Context())
 			if userID == "" {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				_ = json.NewEncoder(w).Encode(map[string]string{
-					"error":   "unauthorized",
-					"message": "Authentication required for order submission",
-				})
+				writeAPIError(w, types.NewAPIError(types.ErrCodeUnauthenticated, "Authentication required for order submission"))
 				return
 			}
 
 			allowed, info := rl.AllowOrder(userID)
 			if !allowed {
-				w.Header().Set("Content-Type", "application/json")
 				w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", info.Limit))
 				w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", info.Remaining))
 				if info.RetryAfter > 0 {
 					w.Header().Set("Retry-After", fmt.Sprintf("%d", info.RetryAfter))
 				}
-				w.WriteHeader(http.StatusTooManyRequests)
-				_ = json.NewEncoder(w).Encode(map[string]interface{}{
-					"error":       "order_limit_exceeded",
-					"message":     fmt.Sprintf("Order %s limit exceeded", info.LimitType),
-					"retry_after": info.RetryAfter,
-					"limit_type":  info.LimitType,
-				})
+				writeAPIError(w, types.NewAPIError(types.ErrCodeOrderLimitExceeded, fmt.Sprintf("Order %s limit exceeded", info.LimitType)).
+					WithDetail("retry_after", info.RetryAfter).
+					WithDetail("limit_type", info.LimitType))
 				return
 			}
 
@@ -476,8 +501,8 @@ func getUserFromContext(ctx context.Context) string {
 	return ""
 }
 
-// getClientIP extracts the client IP from the request
-func getClientIP(r *http.Request) string {
+// ClientIP extracts the client IP from the request
+func ClientIP(r *http.Request) string {
 	// Check for forwarded headers
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
 		for i := 0; i < len(xff); i++ {
@@ -502,6 +527,67 @@ func getClientIP(r *http.Request) string {
 	return ip
 }
 
+// ParseTrustedProxies parses the IPs and CIDRs of reverse proxies whose
+// X-Forwarded-For headers can be believed
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		cidr := proxy
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// RemoteClientIP returns the client IP of the connection. Only when the
+// connection comes from a trusted proxy is X-Forwarded-For read, taking the
+// right-most address that is not itself a trusted proxy, since addresses
+// further left were supplied by the client.
+func RemoteClientIP(r *http.Request, trusted []*net.IPNet) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if !isTrustedProxy(ip, trusted) {
+		return ip
+	}
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !isTrustedProxy(hop, trusted) {
+			break
+		}
+	}
+	return ip
+}
+
+func isTrustedProxy(ip string, trusted []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipNet := range trusted {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
 // ============ Statistics ============
 
 // Stats returns rate limiter statistics
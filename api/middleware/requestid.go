@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+const requestIDContextKey contextKey = "request_id"
+
+// maxRequestIDLength bounds client-supplied request IDs
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware assigns every request a correlation ID.
+// A client-supplied X-Request-ID is reused if present, otherwise a new one is generated.
+// The ID is echoed in the response header and included in error envelopes.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(types.RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+
+		w.Header().Set(types.RequestIDHeader, requestID)
+		ctx := context.WithValue(r.Context(), requestIDContextKey, requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetRequestID returns the request ID stored in context, if any
+func GetRequestID(ctx context.Context) string {
+	if requestID, ok := ctx.Value(requestIDContextKey).(string); ok {
+		return requestID
+	}
+	return ""
+}
+
+// writeAPIError writes the unified error envelope from middleware
+func writeAPIError(w http.ResponseWriter, apiErr *types.APIError) {
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = w.Header().Get(types.RequestIDHeader)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.Code.HTTPStatus())
+	_ = json.NewEncoder(w).Encode(types.NewErrorResponse(apiErr))
+}
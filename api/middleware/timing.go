@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/openalpha/perp-dex/pkg/timing"
+)
+
+// StageTotal labels a request's end-to-end time among its stages
+const StageTotal = "total"
+
+// TimingMiddleware attaches a timing.Recorder to every request and hands each
+// recorded stage, plus the total, to observe in milliseconds once the request
+// completes. Handlers add the X-Debug-Timings breakdown to their response when
+// the client sent that header.
+func TimingMiddleware(observe func(stage string, latencyMs float64)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := timing.NewRecorder(r.Header.Get(timing.Header) != "")
+			next.ServeHTTP(w, r.WithContext(timing.WithRecorder(r.Context(), rec)))
+
+			for _, stage := range rec.Stages() {
+				observe(stage.Name, durationMs(stage.Duration))
+			}
+			observe(StageTotal, durationMs(time.Since(start)))
+		})
+	}
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
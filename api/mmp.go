@@ -0,0 +1,173 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// toOBMMPConfig converts an API MMP config to the keeper representation.
+// Omitted window and freeze durations take the defaults.
+func toOBMMPConfig(trader string, c *types.MMPConfig) (*obtypes.MMPConfig, error) {
+	config := obtypes.NewMMPConfig(trader, c.MarketID)
+	config.Enabled = c.Enabled
+	config.MaxFills = c.MaxFills
+	if c.WindowMs != 0 {
+		config.Window = time.Duration(c.WindowMs) * time.Millisecond
+	}
+	if c.FreezeMs != 0 {
+		config.FreezeDuration = time.Duration(c.FreezeMs) * time.Millisecond
+	}
+	for _, limit := range []struct {
+		value string
+		dst   *math.LegacyDec
+	}{{c.MaxDelta, &config.MaxDelta}, {c.MaxVolume, &config.MaxVolume}} {
+		if limit.value == "" {
+			continue
+		}
+		d, err := math.LegacyNewDecFromStr(limit.value)
+		if err != nil {
+			return nil, types.NewAPIError(types.ErrCodeInvalidDecimal, "invalid limit "+limit.value)
+		}
+		*limit.dst = d
+	}
+	return config, nil
+}
+
+// fromOBMMPConfig converts a keeper MMP config to the API response
+func fromOBMMPConfig(c *obtypes.MMPConfig) *types.MMPConfig {
+	config := &types.MMPConfig{
+		MarketID: c.MarketID,
+		Enabled:  c.Enabled,
+		WindowMs: c.Window.Milliseconds(),
+		FreezeMs: c.FreezeDuration.Milliseconds(),
+		MaxFills: c.MaxFills,
+	}
+	if c.MaxDelta.IsPositive() {
+		config.MaxDelta = c.MaxDelta.String()
+	}
+	if c.MaxVolume.IsPositive() {
+		config.MaxVolume = c.MaxVolume.String()
+	}
+	if !c.UpdatedAt.IsZero() {
+		config.UpdatedAt = c.UpdatedAt.UnixMilli()
+	}
+	return config
+}
+
+// mmpService returns the order service's MMP support, or writes 501 if it has none
+func (s *Server) mmpService(w http.ResponseWriter) types.MMPService {
+	mmp, ok := s.orderService.(types.MMPService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Market maker protection requires a keeper-backed service")
+		return nil
+	}
+	return mmp
+}
+
+// handleMMP handles /v1/account/mmp (GET list, POST set, DELETE ?market_id=)
+func (s *Server) handleMMP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+	mmp := s.mmpService(w)
+	if mmp == nil {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		statuses, err := mmp.GetMMP(r.Context(), trader)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"mmp": statuses})
+
+	case http.MethodPost:
+		var req types.MMPConfig
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		if req.MarketID == "" {
+			writeError(w, types.ErrCodeMissingField, "market_id is required")
+			return
+		}
+		if s.getMockMarket(req.MarketID) == nil {
+			writeError(w, types.ErrCodeMarketNotFound, "Market not found")
+			return
+		}
+		status, err := mmp.SetMMP(r.Context(), trader, &req)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"mmp": status})
+
+	case http.MethodDelete:
+		marketID := r.URL.Query().Get("market_id")
+		if marketID == "" {
+			writeError(w, types.ErrCodeMissingField, "market_id is required")
+			return
+		}
+		if err := mmp.DeleteMMP(r.Context(), trader, marketID); err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleMMPReset handles POST /v1/account/mmp/reset, lifting a freeze early
+func (s *Server) handleMMPReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+	var req struct {
+		MarketID string `json:"market_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+		return
+	}
+	if req.MarketID == "" {
+		writeError(w, types.ErrCodeMissingField, "market_id is required")
+		return
+	}
+	mmp := s.mmpService(w)
+	if mmp == nil {
+		return
+	}
+
+	status, err := mmp.ResetMMP(r.Context(), trader, req.MarketID)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"mmp": status})
+}
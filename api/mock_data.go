@@ -1,11 +1,14 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"time"
 
+	"github.com/openalpha/perp-dex/api/types"
 	"github.com/openalpha/perp-dex/api/websocket"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
 )
 
 // Mock data generation for development and testing
@@ -74,6 +77,10 @@ func (s *Server) getMockTicker(marketID string) map[string]interface{} {
 	// Try to get real data from Oracle
 	if s.oracle != nil {
 		ticker, err := s.oracle.GetTicker(marketID)
+		var index *types.IndexPrice
+		if err == nil {
+			index, err = indexFromTicker(ticker)
+		}
 		if err == nil {
 			return map[string]interface{}{
 				"market_id":     ticker.MarketID,
@@ -87,6 +94,9 @@ func (s *Server) getMockTicker(marketID string) map[string]interface{} {
 				"funding_rate":  ticker.FundingRate,
 				"next_funding":  ticker.NextFunding,
 				"open_interest": "0", // Not available from basic Hyperliquid API
+				"basis":         index.Basis,
+				"basis_rate":    index.BasisRate,
+				"index_sources": index.Sources,
 				"timestamp":     ticker.Timestamp,
 			}
 		}
@@ -107,6 +117,9 @@ func (s *Server) getMockTicker(marketID string) map[string]interface{} {
 		"funding_rate":  "0",
 		"next_funding":  time.Now().Add(time.Hour).Unix(),
 		"open_interest": "0",
+		"basis":         "0",
+		"basis_rate":    "0",
+		"index_sources": []*types.IndexSource{},
 		"timestamp":     time.Now().UnixMilli(),
 		"error":         "price_unavailable",
 	}
@@ -134,6 +147,7 @@ func (s *Server) getMockOrderbook(marketID string, depth int) map[string]interfa
 				"market_id": ob.MarketID,
 				"bids":      bids,
 				"asks":      asks,
+				"checksum":  obtypes.DepthChecksum(bids, asks),
 				"timestamp": ob.Timestamp,
 			}
 		}
@@ -179,12 +193,13 @@ func (s *Server) getMockTrades(marketID string, limit int) []map[string]interfac
 	return []map[string]interface{}{}
 }
 
-// getMockKlines returns K-line data from Hyperliquid real-time candlesticks
-// Falls back to empty klines if Oracle is unavailable
+// getMockKlines returns K-line data from the perpetual keeper when one is
+// attached, otherwise from Hyperliquid real-time candlesticks
+// Falls back to empty klines if neither is available
 func (s *Server) getMockKlines(marketID string, interval string, limit int) []map[string]interface{} {
-	// Try to get real data from Oracle
-	if s.oracle != nil {
-		klines, err := s.oracle.GetKlines(marketID, interval, limit)
+	// Try the keeper's kline store, then the Oracle
+	if source := s.klineSource(); source != nil {
+		klines, err := source.GetKlines(marketID, interval, limit)
 		if err == nil {
 			result := make([]map[string]interface{}, len(klines))
 			for i, k := range klines {
@@ -200,7 +215,7 @@ func (s *Server) getMockKlines(marketID string, interval string, limit int) []ma
 			return result
 		}
 		// Log error but continue with fallback
-		fmt.Printf("Oracle GetKlines error for %s: %v\n", marketID, err)
+		fmt.Printf("GetKlines error for %s: %v\n", marketID, err)
 	}
 
 	// Fallback: return empty klines
@@ -267,7 +282,14 @@ func (s *Server) startRealDataBroadcaster() {
 
 	marketIDs := []string{"BTC-USDC", "ETH-USDC", "SOL-USDC"}
 
-	for range ticker.C {
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		openInterest := s.engineOpenInterest(marketIDs)
 		for _, marketID := range marketIDs {
 			// Broadcast ticker (real-time from Hyperliquid)
 			tickerData := s.getMockTicker(marketID)
@@ -276,33 +298,56 @@ func (s *Server) startRealDataBroadcaster() {
 			if _, hasError := tickerData["error"]; hasError {
 				continue
 			}
+			s.observeMarkPrice(marketID, tickerData["mark_price"].(string))
+			oi, ok := openInterest[marketID]
+			if !ok {
+				oi = tickerData["open_interest"].(string)
+			}
 
 			s.wsServer.BroadcastTicker(&websocket.TickerMessage{
-				MarketID:    marketID,
-				MarkPrice:   tickerData["mark_price"].(string),
-				IndexPrice:  tickerData["index_price"].(string),
-				LastPrice:   tickerData["last_price"].(string),
-				High24h:     tickerData["high_24h"].(string),
-				Low24h:      tickerData["low_24h"].(string),
-				Volume24h:   tickerData["volume_24h"].(string),
-				Change24h:   tickerData["change_24h"].(string),
-				FundingRate: tickerData["funding_rate"].(string),
-				NextFunding: tickerData["next_funding"].(int64),
-				Timestamp:   tickerData["timestamp"].(int64),
+				MarketID:     marketID,
+				MarkPrice:    tickerData["mark_price"].(string),
+				IndexPrice:   tickerData["index_price"].(string),
+				LastPrice:    tickerData["last_price"].(string),
+				High24h:      tickerData["high_24h"].(string),
+				Low24h:       tickerData["low_24h"].(string),
+				Volume24h:    tickerData["volume_24h"].(string),
+				Change24h:    tickerData["change_24h"].(string),
+				FundingRate:  tickerData["funding_rate"].(string),
+				NextFunding:  tickerData["next_funding"].(int64),
+				OpenInterest: oi,
+				Basis:        tickerData["basis"].(string),
+				BasisRate:    tickerData["basis_rate"].(string),
+				IndexSources: tickerData["index_sources"].([]*types.IndexSource),
+				Timestamp:    tickerData["timestamp"].(int64),
 			})
 
 			// Broadcast depth every 2 seconds (less frequent to reduce API load)
 			if time.Now().Second()%2 == 0 {
-				orderbookData := s.getMockOrderbook(marketID, 20)
-
-				// Skip if Oracle returned error
-				if _, hasError := orderbookData["error"]; hasError {
-					continue
+				depth := &websocket.DepthMessage{MarketID: marketID}
+				var bids, asks [][]string
+				if s.marketData != nil {
+					// The engine's book, with the checksum computed where it was read
+					book, err := s.marketData.GetOrderBook(context.Background(), marketID, 20)
+					if err != nil {
+						continue
+					}
+					bids, asks = book.Bids, book.Asks
+					depth.Checksum, depth.Timestamp = book.Checksum, book.Timestamp
+				} else {
+					orderbookData := s.getMockOrderbook(marketID, 20)
+
+					// Skip if Oracle returned error
+					if _, hasError := orderbookData["error"]; hasError {
+						continue
+					}
+
+					bids = orderbookData["bids"].([][]string)
+					asks = orderbookData["asks"].([][]string)
+					depth.Checksum = orderbookData["checksum"].(int32)
+					depth.Timestamp = orderbookData["timestamp"].(int64)
 				}
 
-				bids := orderbookData["bids"].([][]string)
-				asks := orderbookData["asks"].([][]string)
-
 				depthBids := make([]websocket.PriceLevel, len(bids))
 				depthAsks := make([]websocket.PriceLevel, len(asks))
 
@@ -313,12 +358,8 @@ func (s *Server) startRealDataBroadcaster() {
 					depthAsks[i] = websocket.PriceLevel{Price: a[0], Quantity: a[1]}
 				}
 
-				s.wsServer.BroadcastDepth(&websocket.DepthMessage{
-					MarketID:  marketID,
-					Bids:      depthBids,
-					Asks:      depthAsks,
-					Timestamp: orderbookData["timestamp"].(int64),
-				})
+				depth.Bids, depth.Asks = depthBids, depthAsks
+				s.wsServer.BroadcastDepth(depth)
 			}
 
 			// Broadcast trade every ~3 seconds (sample from recent trades)
@@ -340,6 +381,28 @@ func (s *Server) startRealDataBroadcaster() {
 	}
 }
 
+// engineOpenInterest returns the open interest of the markets the engine
+// tracks, read in one snapshot; empty when no engine backs the server
+func (s *Server) engineOpenInterest(marketIDs []string) map[string]string {
+	openInterest := make(map[string]string)
+	svc, ok := s.orderService.(types.SnapshotService)
+	if !ok {
+		return openInterest
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	snapshot, err := svc.GetSnapshot(ctx, marketIDs, 1)
+	if err != nil {
+		return openInterest
+	}
+	for _, market := range snapshot.Markets {
+		if market.OpenInterest != "" {
+			openInterest[market.MarketID] = market.OpenInterest
+		}
+	}
+	return openInterest
+}
+
 // startMockDataBroadcaster is an alias for backward compatibility
 // Deprecated: Use startRealDataBroadcaster instead
 func (s *Server) startMockDataBroadcaster() {
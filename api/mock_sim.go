@@ -0,0 +1,373 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/mocksim"
+	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/api/websocket"
+)
+
+// Mock mode market simulation. In mock mode the oracle serves the scripted
+// markets of a mocksim.Simulator instead of Hyperliquid, so tickers, books,
+// trades and candles move offline, and a virtual counterparty fills the mock
+// service's resting orders the simulated market trades through.
+
+// mockCounterpartyInterval is how often resting mock orders are matched
+// against the simulated markets
+const mockCounterpartyInterval = 250 * time.Millisecond
+
+// Virtual counterparty position terms
+const (
+	mockSimLeverage         = 10
+	mockSimMaintenanceRatio = 0.025
+)
+
+// newMockSimulator starts the simulation of config.MockScript, the default
+// script if it is nil. It returns nil, leaving mock mode on Hyperliquid data,
+// if the script is invalid.
+func newMockSimulator(config *Config) *mocksim.Simulator {
+	script := config.MockScript
+	if script == nil {
+		script = mocksim.DefaultScript()
+	}
+	sim, err := mocksim.New(script, time.Now())
+	if err != nil {
+		log.Printf("Mock market simulation disabled: %v", err)
+		return nil
+	}
+	return sim
+}
+
+// simulates reports whether the oracle serves a market from its simulator
+func (o *HyperliquidOracle) simulates(marketID string) bool {
+	return o.sim != nil && o.sim.Has(marketID)
+}
+
+func (o *HyperliquidOracle) simTicker(marketID string) (*TickerData, error) {
+	t, err := o.sim.Ticker(marketID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &TickerData{
+		MarketID:    t.MarketID,
+		MarkPrice:   t.MarkPrice,
+		IndexPrice:  t.IndexPrice,
+		LastPrice:   t.LastPrice,
+		High24h:     t.High24h,
+		Low24h:      t.Low24h,
+		Volume24h:   t.Volume24h,
+		Change24h:   t.Change24h,
+		FundingRate: t.FundingRate,
+		NextFunding: t.NextFunding.Unix(),
+		Timestamp:   t.Timestamp.UnixMilli(),
+	}, nil
+}
+
+func (o *HyperliquidOracle) simOrderbook(marketID string, depth int) (*OrderbookData, error) {
+	book, err := o.sim.Book(marketID, depth, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	levels := func(in []mocksim.Level) []OrderbookLevel {
+		out := make([]OrderbookLevel, len(in))
+		for i, l := range in {
+			out[i] = OrderbookLevel{Price: l.Price, Quantity: l.Quantity}
+		}
+		return out
+	}
+	return &OrderbookData{
+		MarketID:  book.MarketID,
+		Bids:      levels(book.Bids),
+		Asks:      levels(book.Asks),
+		Timestamp: book.Timestamp.UnixMilli(),
+	}, nil
+}
+
+func (o *HyperliquidOracle) simTrades(marketID string, limit int) ([]TradeData, error) {
+	trades, err := o.sim.Trades(marketID, limit, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]TradeData, len(trades))
+	for i, t := range trades {
+		result[i] = TradeData{
+			TradeID:   t.TradeID,
+			MarketID:  t.MarketID,
+			Price:     t.Price,
+			Quantity:  t.Quantity,
+			Side:      t.Side,
+			Timestamp: t.Timestamp.UnixMilli(),
+		}
+	}
+	return result, nil
+}
+
+// simKlineIntervals are the kline intervals GetKlines accepts
+var simKlineIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+func (o *HyperliquidOracle) simKlines(marketID, interval string, limit int) ([]KlineData, error) {
+	duration, ok := simKlineIntervals[interval]
+	if !ok {
+		// Hyperliquid falls back to hourly candles too
+		duration = time.Hour
+	}
+	candles, err := o.sim.Candles(marketID, duration, limit, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	klines := make([]KlineData, len(candles))
+	for i, c := range candles {
+		klines[i] = KlineData{
+			Time:   c.Time.Unix(),
+			Open:   c.Open,
+			High:   c.High,
+			Low:    c.Low,
+			Close:  c.Close,
+			Volume: c.Volume,
+		}
+	}
+	return klines, nil
+}
+
+// ============ Virtual counterparty ============
+
+// startMockCounterparty fills the mock service's resting limit orders as the
+// simulated markets trade through them, pushing order, position and trade
+// updates to WebSocket subscribers
+func (s *Server) startMockCounterparty() {
+	ms, ok := s.orderService.(*MockService)
+	if !ok || ms.sim == nil {
+		return
+	}
+
+	ticker := time.NewTicker(mockCounterpartyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		fills := ms.sim.Match(ms.restingSimOrders(), time.Now())
+		for _, fill := range fills {
+			order, pos := ms.applySimFill(fill)
+			if order == nil {
+				continue
+			}
+			now := types.NowMillis()
+			s.wsServer.BroadcastOrder(order.Trader, &websocket.OrderMessage{
+				OrderID:    order.OrderID,
+				MarketID:   order.MarketID,
+				Trader:     order.Trader,
+				Side:       order.Side,
+				Type:       order.Type,
+				Price:      order.Price,
+				Size:       order.Quantity,
+				FilledSize: order.FilledQty,
+				Status:     order.Status,
+				Timestamp:  now,
+			})
+			s.wsServer.BroadcastTrade(&websocket.TradeMessage{
+				TradeID:   fill.TradeID,
+				MarketID:  fill.MarketID,
+				Price:     ms.sim.FormatPrice(fill.MarketID, fill.Price),
+				Quantity:  ms.sim.FormatSize(fill.MarketID, fill.Quantity),
+				Side:      counterSide(fill.Side),
+				Timestamp: now,
+			})
+			if pos != nil {
+				s.wsServer.BroadcastPosition(order.Trader, &websocket.PositionMessage{
+					Trader:           pos.Trader,
+					MarketID:         pos.MarketID,
+					Side:             pos.Side,
+					Size:             pos.Size,
+					EntryPrice:       pos.EntryPrice,
+					MarkPrice:        pos.MarkPrice,
+					UnrealizedPnL:    pos.UnrealizedPnl,
+					Margin:           pos.Margin,
+					Leverage:         pos.Leverage,
+					LiquidationPrice: pos.LiquidationPrice,
+					Timestamp:        now,
+				})
+			}
+		}
+		ms.markSimPositions()
+	}
+}
+
+// counterSide is the opposite order side, the side the virtual counterparty
+// takes
+func counterSide(side string) string {
+	if side == "buy" {
+		return "sell"
+	}
+	return "buy"
+}
+
+// restingSimOrders lists the open limit orders of simulated markets
+func (ms *MockService) restingSimOrders() []mocksim.RestingOrder {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	var orders []mocksim.RestingOrder
+	for _, order := range ms.orders {
+		if order.Type != "limit" || !ms.sim.Has(order.MarketID) ||
+			(order.Status != "open" && order.Status != "partially_filled") {
+			continue
+		}
+		price, err := strconv.ParseFloat(order.Price, 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		qty, _ := strconv.ParseFloat(order.Quantity, 64)
+		filled, _ := strconv.ParseFloat(order.FilledQty, 64)
+		orders = append(orders, mocksim.RestingOrder{
+			OrderID:   order.OrderID,
+			MarketID:  order.MarketID,
+			Side:      order.Side,
+			Price:     price,
+			Remaining: qty - filled,
+		})
+	}
+	return orders
+}
+
+// applySimFill books a virtual counterparty fill on its order and the
+// trader's position. It returns nil if the order no longer rests, and a nil
+// position if the fill closed it.
+func (ms *MockService) applySimFill(fill mocksim.Fill) (*types.Order, *types.Position) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	order, ok := ms.orders[fill.OrderID]
+	if !ok || (order.Status != "open" && order.Status != "partially_filled") {
+		return nil, nil
+	}
+	qty, _ := strconv.ParseFloat(order.Quantity, 64)
+	filled, _ := strconv.ParseFloat(order.FilledQty, 64)
+	filled = math.Min(filled+fill.Quantity, qty)
+	order.FilledQty = ms.sim.FormatSize(order.MarketID, filled)
+	order.Status = "partially_filled"
+	if order.FilledQty == ms.sim.FormatSize(order.MarketID, qty) {
+		order.Status = "filled"
+	}
+	order.UpdatedAt = types.NowMillis()
+
+	return order, ms.bookSimFillLocked(order.Trader, order.MarketID, order.Side, fill.Price, fill.Quantity)
+}
+
+// bookSimFillLocked nets a fill into the trader's position at the fill price,
+// recording the position's history once it closes. The caller holds ms.mu.
+func (ms *MockService) bookSimFillLocked(trader, marketID, side string, price, qty float64) *types.Position {
+	key := trader + ":" + marketID
+	delta := qty
+	if side == "sell" {
+		delta = -qty
+	}
+
+	var size, entry float64
+	pos := ms.positions[key]
+	if pos != nil {
+		size, _ = strconv.ParseFloat(pos.Size, 64)
+		entry, _ = strconv.ParseFloat(pos.EntryPrice, 64)
+		if pos.Side == "short" {
+			size = -size
+		}
+	}
+	next := size + delta
+	switch {
+	case size == 0 || (next != 0 && (size > 0) != (next > 0)):
+		// Opened, or flipped through zero at the fill price
+		entry = price
+	case math.Abs(next) > math.Abs(size):
+		entry = (entry*math.Abs(size) + price*qty) / math.Abs(next)
+	}
+
+	if ms.sim.FormatSize(marketID, math.Abs(next)) == ms.sim.FormatSize(marketID, 0) {
+		delete(ms.positions, key)
+		pnl := (price - entry) * size
+		ms.closeSeq++
+		ms.closed[trader] = append(ms.closed[trader], &types.ClosedPosition{
+			PositionID:  fmt.Sprintf("pos-%d", ms.closeSeq),
+			MarketID:    marketID,
+			Trader:      trader,
+			Side:        pos.Side,
+			Size:        pos.Size,
+			EntryPrice:  pos.EntryPrice,
+			ExitPrice:   ms.sim.FormatPrice(marketID, price),
+			RealizedPnl: fmt.Sprintf("%.2f", pnl),
+			FeesPaid:    "0.00",
+			FundingPaid: "0.00",
+			NetPnl:      fmt.Sprintf("%.2f", pnl),
+			CloseReason: "closed",
+			ClosedAt:    types.NowMillis(),
+		})
+		return nil
+	}
+
+	if pos == nil {
+		pos = &types.Position{MarketID: marketID, Trader: trader, MarginMode: "isolated"}
+		ms.positions[key] = pos
+	}
+	pos.Side = "long"
+	liquidation := entry * (1 - 1.0/mockSimLeverage + mockSimMaintenanceRatio)
+	if next < 0 {
+		pos.Side = "short"
+		liquidation = entry * (1 + 1.0/mockSimLeverage - mockSimMaintenanceRatio)
+	}
+	pos.Size = ms.sim.FormatSize(marketID, math.Abs(next))
+	pos.EntryPrice = ms.sim.FormatPrice(marketID, entry)
+	pos.Margin = fmt.Sprintf("%.2f", entry*math.Abs(next)/mockSimLeverage)
+	pos.Leverage = strconv.Itoa(mockSimLeverage)
+	pos.LiquidationPrice = ms.sim.FormatPrice(marketID, liquidation)
+	ms.markSimPositionLocked(pos, price)
+	return pos
+}
+
+// markSimPositions marks the positions of simulated markets to their current
+// price
+func (ms *MockService) markSimPositions() {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := time.Now()
+	for _, pos := range ms.positions {
+		if !ms.sim.Has(pos.MarketID) {
+			continue
+		}
+		mark, err := ms.sim.Price(pos.MarketID, now)
+		if err != nil {
+			continue
+		}
+		if price, err := strconv.ParseFloat(mark, 64); err == nil {
+			ms.markSimPositionLocked(pos, price)
+		}
+	}
+}
+
+// markSimPositionLocked sets a position's mark price and unrealized PnL. The
+// caller holds ms.mu.
+func (ms *MockService) markSimPositionLocked(pos *types.Position, mark float64) {
+	size, _ := strconv.ParseFloat(pos.Size, 64)
+	entry, _ := strconv.ParseFloat(pos.EntryPrice, 64)
+	if pos.Side == "short" {
+		size = -size
+	}
+	pos.MarkPrice = ms.sim.FormatPrice(pos.MarketID, mark)
+	pos.UnrealizedPnl = fmt.Sprintf("%.2f", (mark-entry)*size)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/mocksim"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestMockSimulation tests that mock mode serves the scripted market offline
+// and that the virtual counterparty fills a resting order the market trades
+// through, opening a position
+func TestMockSimulation(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	config.MockMode = true
+	config.MockScript = &mocksim.Script{
+		Seed:            1,
+		TickMillis:      250,
+		HistoryHours:    0.5,
+		FillDelayMillis: 500,
+		FillFraction:    1,
+		Markets: []mocksim.MarketScript{{
+			MarketID: "BTC-USDC", StartPrice: 60000, TickSize: "0.1", LotSize: "0.001",
+			Phases:          []mocksim.Phase{{Path: mocksim.PathRandomWalk, Seconds: 600, Volatility: 0.0002}},
+			Book:            mocksim.BookShape{Levels: 10, SpreadBps: 2, StepBps: 1, Size: 1},
+			TradesPerSecond: 2,
+		}},
+	}
+	s := NewServer(config)
+	handler := s.handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/markets/BTC-USDC/ticker", nil))
+	var ticker map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &ticker); err != nil {
+		t.Fatalf("invalid ticker %q: %v", rec.Body.String(), err)
+	}
+	mark, _ := strconv.ParseFloat(ticker["mark_price"].(string), 64)
+	if _, failed := ticker["error"]; failed || mark < 50000 || mark > 70000 {
+		t.Fatalf("expected a simulated ticker near 60000, got %v", ticker)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/markets/BTC-USDC/orderbook?depth=5", nil))
+	var book struct {
+		Bids [][]string `json:"bids"`
+		Asks [][]string `json:"asks"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &book); err != nil || len(book.Bids) != 5 || len(book.Asks) != 5 {
+		t.Fatalf("expected five simulated levels a side, got %s", rec.Body.String())
+	}
+
+	// A bid above the ask fills once the fill delay has passed
+	ms := s.orderService.(*MockService)
+	ctx := context.Background()
+	resp, err := ms.PlaceOrder(ctx, &types.PlaceOrderRequest{
+		MarketID: "BTC-USDC", Side: "buy", Type: "limit", Trader: "demo",
+		Price: strconv.FormatFloat(mark*1.02, 'f', 1, 64), Quantity: "0.5",
+	})
+	if err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+	now := time.Now()
+	if fills := ms.sim.Match(ms.restingSimOrders(), now); len(fills) != 0 {
+		t.Fatalf("expected no fill before the delay, got %+v", fills)
+	}
+	fills := ms.sim.Match(ms.restingSimOrders(), now.Add(ms.sim.FillDelay()))
+	if len(fills) != 1 {
+		t.Fatalf("expected the bid to be filled, got %+v", fills)
+	}
+	order, pos := ms.applySimFill(fills[0])
+	if order.OrderID != resp.Order.OrderID || order.Status != "filled" || order.FilledQty != "0.500" {
+		t.Errorf("expected the order to be filled, got %+v", order)
+	}
+	if pos == nil || pos.Side != "long" || pos.Size != "0.500" || pos.EntryPrice != resp.Order.Price {
+		t.Errorf("expected a long position at the order price, got %+v", pos)
+	}
+
+	// A market sell closes it at the simulated bid
+	closing, err := ms.PlaceOrder(ctx, &types.PlaceOrderRequest{
+		MarketID: "BTC-USDC", Side: "sell", Type: "market", Trader: "demo", Quantity: "0.5",
+	})
+	if err != nil {
+		t.Fatalf("failed to place market order: %v", err)
+	}
+	if price, _ := strconv.ParseFloat(closing.Match.AvgPrice, 64); price < mark*0.9 {
+		t.Errorf("expected the market sell at the simulated bid, got %s", closing.Match.AvgPrice)
+	}
+	if _, err := ms.GetPosition(ctx, "demo", "BTC-USDC"); err == nil {
+		t.Errorf("expected the position to be closed")
+	}
+	if history, _ := ms.GetPositionHistory(ctx, &types.PositionHistoryRequest{Trader: "demo", Limit: 10}); len(history.Positions) != 1 {
+		t.Errorf("expected the closed position in the history, got %+v", history)
+	}
+}
@@ -0,0 +1,250 @@
+// Package mocksim simulates markets for the API's mock mode, so frontends can
+// be demonstrated offline against data that moves like a real exchange's.
+// Each market follows a scripted price path of random walks, trends and
+// scheduled gaps; its order book is regenerated around the price every tick;
+// a synthetic tape of trades builds candles; and a virtual counterparty fills
+// user orders the price has traded through. A run is deterministic for its
+// script's seed and wall clock times.
+package mocksim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// PathKind is how a market's price moves during a phase
+type PathKind string
+
+const (
+	PathRandomWalk PathKind = "random_walk" // Gaussian log returns without drift
+	PathTrend      PathKind = "trend"       // Gaussian log returns around a drift
+	PathFlat       PathKind = "flat"        // Price held; the book and tape still move
+)
+
+// Phase is one leg of a market's price path. A market's phases repeat in
+// order for as long as the simulation runs.
+type Phase struct {
+	Path       PathKind `json:"path"`
+	Seconds    float64  `json:"seconds"`
+	Volatility float64  `json:"volatility"`      // standard deviation of log returns per second
+	Drift      float64  `json:"drift,omitempty"` // log return per second of a trend, e.g. -0.00002
+}
+
+// Gap is a scheduled jump in price. The book's spread widens fivefold at a
+// gap and narrows back over the next half minute.
+type Gap struct {
+	At    float64 `json:"at"`              // seconds after the start
+	Move  float64 `json:"move"`            // relative jump, e.g. -0.05 for a 5% drop
+	Every float64 `json:"every,omitempty"` // repeat period in seconds, 0 for once
+}
+
+// BookShape shapes a market's synthetic order book
+type BookShape struct {
+	Levels    int     `json:"levels"`     // price levels per side
+	SpreadBps float64 `json:"spread_bps"` // best bid to best ask
+	StepBps   float64 `json:"step_bps"`   // between adjacent levels
+	Size      float64 `json:"size"`       // mean size of the best level; deeper levels are larger
+}
+
+// MarketScript scripts one market
+type MarketScript struct {
+	MarketID        string    `json:"market_id"`
+	StartPrice      float64   `json:"start_price"`
+	TickSize        string    `json:"tick_size"`
+	LotSize         string    `json:"lot_size"`
+	Phases          []Phase   `json:"phases"`
+	Gaps            []Gap     `json:"gaps,omitempty"`
+	Book            BookShape `json:"book"`
+	TradesPerSecond float64   `json:"trades_per_second"`
+}
+
+// Script configures a simulation
+type Script struct {
+	Seed int64 `json:"seed"`
+
+	// Simulation step; every tick moves the prices and may print trades
+	TickMillis int64 `json:"tick_ms"`
+
+	// Hours simulated before the start, so charts open with candles
+	HistoryHours float64 `json:"history_hours"`
+
+	// The virtual counterparty fills a user order FillFraction of its
+	// remaining size at a time, once the book has stayed through its price
+	// for FillDelayMillis
+	FillDelayMillis int64   `json:"fill_delay_ms"`
+	FillFraction    float64 `json:"fill_fraction"`
+
+	Markets []MarketScript `json:"markets"`
+}
+
+// Script defaults
+const (
+	DefaultTickMillis      = 250
+	DefaultHistoryHours    = 24
+	DefaultFillDelayMillis = 1000
+	DefaultBookLevels      = 20
+	DefaultSpreadBps       = 2
+	DefaultStepBps         = 1
+)
+
+// DefaultScript returns the script of the mock markets: BTC, ETH and SOL
+// alternating random walks and trends, with a drop and a rally every hour
+func DefaultScript() *Script {
+	phases := func(vol, drift float64) []Phase {
+		return []Phase{
+			{Path: PathRandomWalk, Seconds: 600, Volatility: vol},
+			{Path: PathTrend, Seconds: 300, Volatility: vol * 0.75, Drift: drift},
+			{Path: PathRandomWalk, Seconds: 600, Volatility: vol},
+			{Path: PathTrend, Seconds: 300, Volatility: vol * 0.75, Drift: -drift},
+		}
+	}
+	gaps := func(move float64) []Gap {
+		return []Gap{
+			{At: 900, Move: -move, Every: 3600},
+			{At: 2700, Move: move, Every: 3600},
+		}
+	}
+	script := &Script{
+		Seed: 1,
+		Markets: []MarketScript{
+			{
+				MarketID: "BTC-USDC", StartPrice: 65000, TickSize: "0.1", LotSize: "0.001",
+				Phases: phases(0.00015, 0.00002), Gaps: gaps(0.015),
+				Book: BookShape{Size: 0.5}, TradesPerSecond: 2,
+			},
+			{
+				MarketID: "ETH-USDC", StartPrice: 3500, TickSize: "0.01", LotSize: "0.01",
+				Phases: phases(0.0002, 0.000025), Gaps: gaps(0.02),
+				Book: BookShape{Size: 5}, TradesPerSecond: 1.5,
+			},
+			{
+				MarketID: "SOL-USDC", StartPrice: 150, TickSize: "0.001", LotSize: "0.1",
+				Phases: phases(0.0003, 0.00003), Gaps: gaps(0.03),
+				Book: BookShape{Size: 50}, TradesPerSecond: 1,
+			},
+		},
+	}
+	script.applyDefaults()
+	return script
+}
+
+// ParseScript parses a JSON script, filling in defaults, and validates it
+func ParseScript(data []byte) (*Script, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var script Script
+	if err := dec.Decode(&script); err != nil {
+		return nil, fmt.Errorf("invalid mock script: %w", err)
+	}
+	script.applyDefaults()
+	if err := script.Validate(); err != nil {
+		return nil, err
+	}
+	return &script, nil
+}
+
+// applyDefaults fills in unset fields
+func (s *Script) applyDefaults() {
+	if s.TickMillis == 0 {
+		s.TickMillis = DefaultTickMillis
+	}
+	if s.HistoryHours == 0 {
+		s.HistoryHours = DefaultHistoryHours
+	}
+	if s.FillDelayMillis == 0 {
+		s.FillDelayMillis = DefaultFillDelayMillis
+	}
+	if s.FillFraction == 0 {
+		s.FillFraction = 1
+	}
+	for i := range s.Markets {
+		book := &s.Markets[i].Book
+		if book.Levels == 0 {
+			book.Levels = DefaultBookLevels
+		}
+		if book.SpreadBps == 0 {
+			book.SpreadBps = DefaultSpreadBps
+		}
+		if book.StepBps == 0 {
+			book.StepBps = DefaultStepBps
+		}
+	}
+}
+
+// Validate checks a script with its defaults applied
+func (s *Script) Validate() error {
+	if s.TickMillis <= 0 {
+		return fmt.Errorf("tick_ms must be positive")
+	}
+	if s.HistoryHours < 0 || s.HistoryHours > 24*7 {
+		return fmt.Errorf("history_hours must be between 0 and 168")
+	}
+	if s.FillDelayMillis < 0 {
+		return fmt.Errorf("fill_delay_ms must not be negative")
+	}
+	if s.FillFraction <= 0 || s.FillFraction > 1 {
+		return fmt.Errorf("fill_fraction must be in (0, 1]")
+	}
+	if len(s.Markets) == 0 {
+		return fmt.Errorf("at least one market is required")
+	}
+
+	seen := make(map[string]bool, len(s.Markets))
+	for _, m := range s.Markets {
+		if m.MarketID == "" {
+			return fmt.Errorf("market_id is required")
+		}
+		if seen[m.MarketID] {
+			return fmt.Errorf("duplicate market %s", m.MarketID)
+		}
+		seen[m.MarketID] = true
+		if err := m.validate(); err != nil {
+			return fmt.Errorf("market %s: %w", m.MarketID, err)
+		}
+	}
+	return nil
+}
+
+func (m *MarketScript) validate() error {
+	if m.StartPrice <= 0 {
+		return fmt.Errorf("start_price must be positive")
+	}
+	for name, increment := range map[string]string{"tick_size": m.TickSize, "lot_size": m.LotSize} {
+		if v, err := strconv.ParseFloat(increment, 64); err != nil || v <= 0 {
+			return fmt.Errorf("%s must be a positive decimal", name)
+		}
+	}
+	if len(m.Phases) == 0 {
+		return fmt.Errorf("at least one phase is required")
+	}
+	for i, p := range m.Phases {
+		switch p.Path {
+		case PathRandomWalk, PathTrend, PathFlat:
+		default:
+			return fmt.Errorf("phase %d: unknown path %q (want random_walk, trend or flat)", i, p.Path)
+		}
+		if p.Seconds <= 0 {
+			return fmt.Errorf("phase %d: seconds must be positive", i)
+		}
+		if p.Volatility < 0 {
+			return fmt.Errorf("phase %d: volatility must not be negative", i)
+		}
+	}
+	for i, g := range m.Gaps {
+		if g.At < 0 || g.Every < 0 {
+			return fmt.Errorf("gap %d: at and every must not be negative", i)
+		}
+		if g.Move <= -1 {
+			return fmt.Errorf("gap %d: move must be above -1", i)
+		}
+	}
+	if m.Book.Levels <= 0 || m.Book.SpreadBps <= 0 || m.Book.StepBps <= 0 || m.Book.Size <= 0 {
+		return fmt.Errorf("book levels, spread_bps, step_bps and size must be positive")
+	}
+	if m.TradesPerSecond < 0 {
+		return fmt.Errorf("trades_per_second must not be negative")
+	}
+	return nil
+}
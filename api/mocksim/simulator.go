@@ -0,0 +1,627 @@
+package mocksim
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Simulation limits
+const (
+	maxTrades       = 500           // tape length per market
+	maxCandles      = 7 * 24 * 60   // one-minute candles kept per market
+	maxCatchUp      = 6 * time.Hour // longest stretch simulated tick by tick after a pause
+	gapSpreadFactor = 4             // extra spread right after a gap
+	gapSpreadDecay  = 10.0          // seconds for the extra spread to fall by e
+)
+
+// Level is one price level of a synthetic book
+type Level struct {
+	Price    string
+	Quantity string
+}
+
+// Book is a market's synthetic order book, best levels first
+type Book struct {
+	MarketID  string
+	Bids      []Level
+	Asks      []Level
+	Timestamp time.Time
+}
+
+// Trade is a trade on a market's tape. Side is the taker's side.
+type Trade struct {
+	TradeID   string
+	MarketID  string
+	Price     string
+	Quantity  string
+	Side      string
+	Timestamp time.Time
+}
+
+// Candle is an OHLCV candle; Time opens its interval
+type Candle struct {
+	Time                   time.Time
+	Open, High, Low, Close float64
+	Volume                 float64
+}
+
+// Ticker summarizes a market. Volume24h is notional, Change24h a percentage
+// and FundingRate an hourly rate, as the Hyperliquid oracle reports them.
+type Ticker struct {
+	MarketID    string
+	MarkPrice   string
+	IndexPrice  string
+	LastPrice   string
+	High24h     string
+	Low24h      string
+	Volume24h   string
+	Change24h   string
+	FundingRate string
+	NextFunding time.Time
+	Timestamp   time.Time
+}
+
+// RestingOrder is a user order the virtual counterparty may fill
+type RestingOrder struct {
+	OrderID   string
+	MarketID  string
+	Side      string // buy or sell
+	Price     float64
+	Remaining float64
+}
+
+// Fill is the virtual counterparty's fill of a user order, at the order's
+// price
+type Fill struct {
+	OrderID  string
+	MarketID string
+	Side     string // the user order's side
+	Price    float64
+	Quantity float64
+	TradeID  string
+}
+
+// Simulator runs a script. It advances lazily: every read first simulates the
+// ticks due up to the time of the read, so an idle simulator costs nothing.
+// It is safe for concurrent use.
+type Simulator struct {
+	mu      sync.Mutex
+	script  *Script
+	tick    time.Duration
+	start   time.Time // the script's time zero
+	now     time.Time // the time of the last simulated tick
+	markets map[string]*market
+	ids     []string
+
+	// When each user order was first seen crossed by the book
+	crossedSince map[string]time.Time
+}
+
+// market is one simulated market
+type market struct {
+	script   MarketScript
+	rng      *rand.Rand
+	bookSeed int64
+
+	tickSize, lotSize float64
+	priceDec, sizeDec int
+
+	price    float64 // mid price
+	last     float64 // last trade price
+	step     int64   // ticks simulated
+	lastGap  time.Time
+	tradeSeq int64
+	trades   []Trade  // oldest first
+	candles  []Candle // one-minute candles, oldest first
+}
+
+// New creates a simulator for a validated script whose time zero is start.
+// The script's history is simulated up to start before New returns.
+func New(script *Script, start time.Time) (*Simulator, error) {
+	if err := script.Validate(); err != nil {
+		return nil, err
+	}
+	s := &Simulator{
+		script:       script,
+		tick:         time.Duration(script.TickMillis) * time.Millisecond,
+		start:        start,
+		markets:      make(map[string]*market, len(script.Markets)),
+		crossedSince: make(map[string]time.Time),
+	}
+	history := time.Duration(script.HistoryHours * float64(time.Hour))
+	s.now = start.Add(-history).Truncate(s.tick)
+
+	for _, ms := range script.Markets {
+		h := fnv.New64a()
+		h.Write([]byte(ms.MarketID))
+		seed := script.Seed ^ int64(h.Sum64())
+		m := &market{
+			script:   ms,
+			rng:      rand.New(rand.NewSource(seed)),
+			bookSeed: seed * 31,
+			price:    ms.StartPrice,
+			last:     ms.StartPrice,
+		}
+		m.tickSize, m.priceDec = parseIncrement(ms.TickSize)
+		m.lotSize, m.sizeDec = parseIncrement(ms.LotSize)
+		s.markets[ms.MarketID] = m
+		s.ids = append(s.ids, ms.MarketID)
+	}
+	s.simulate(start)
+	return s, nil
+}
+
+// parseIncrement parses a tick or lot size and counts its decimals
+func parseIncrement(increment string) (float64, int) {
+	v, _ := strconv.ParseFloat(increment, 64)
+	decimals := 0
+	if i := strings.IndexByte(increment, '.'); i >= 0 {
+		decimals = len(strings.TrimRight(increment[i+1:], "0"))
+	}
+	return v, decimals
+}
+
+// Markets lists the simulated markets in script order
+func (s *Simulator) Markets() []string {
+	return append([]string(nil), s.ids...)
+}
+
+// Has reports whether a market is simulated
+func (s *Simulator) Has(marketID string) bool {
+	_, ok := s.markets[marketID]
+	return ok
+}
+
+// FillDelay is how long the book must stay through a user order's price
+// before the virtual counterparty fills it
+func (s *Simulator) FillDelay() time.Duration {
+	return time.Duration(s.script.FillDelayMillis) * time.Millisecond
+}
+
+// LastUpdate returns the time of the last simulated tick
+func (s *Simulator) LastUpdate() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// advance simulates the ticks due up to now. After a pause longer than
+// maxCatchUp the price path resumes where it was, maxCatchUp before now.
+func (s *Simulator) advance(now time.Time) {
+	if now.Sub(s.now) > maxCatchUp {
+		s.now = now.Add(-maxCatchUp).Truncate(s.tick)
+	}
+	s.simulate(now)
+}
+
+// simulate runs every tick due up to now
+func (s *Simulator) simulate(now time.Time) {
+	for next := s.now.Add(s.tick); !next.After(now); next = next.Add(s.tick) {
+		for _, id := range s.ids {
+			s.markets[id].stepTo(next, s.start, s.tick)
+		}
+		s.now = next
+	}
+}
+
+// phaseAt returns the phase of a market's path t seconds after time zero
+func (m *market) phaseAt(t float64) Phase {
+	var cycle float64
+	for _, p := range m.script.Phases {
+		cycle += p.Seconds
+	}
+	offset := math.Mod(t, cycle)
+	if offset < 0 {
+		offset += cycle
+	}
+	for _, p := range m.script.Phases {
+		if offset < p.Seconds {
+			return p
+		}
+		offset -= p.Seconds
+	}
+	return m.script.Phases[len(m.script.Phases)-1]
+}
+
+// gapFires reports whether a gap is scheduled in (from, to], in seconds
+// after time zero. A repeating gap also fires before time zero, so history
+// shows it too.
+func gapFires(g Gap, from, to float64) bool {
+	if g.Every <= 0 {
+		return g.At > from && g.At <= to
+	}
+	// The last occurrence at or before to
+	k := math.Floor((to - g.At) / g.Every)
+	return g.At+k*g.Every > from
+}
+
+// stepTo simulates one tick ending at now
+func (m *market) stepTo(now, start time.Time, tick time.Duration) {
+	dt := tick.Seconds()
+	t := now.Sub(start).Seconds()
+
+	phase := m.phaseAt(t)
+	var r float64
+	switch phase.Path {
+	case PathRandomWalk:
+		r = phase.Volatility * math.Sqrt(dt) * m.rng.NormFloat64()
+	case PathTrend:
+		r = phase.Drift*dt + phase.Volatility*math.Sqrt(dt)*m.rng.NormFloat64()
+	}
+	m.price *= math.Exp(r)
+	for _, g := range m.script.Gaps {
+		if gapFires(g, t-dt, t) {
+			m.price *= 1 + g.Move
+			m.lastGap = now
+		}
+	}
+	// Keep at least a few ticks of price so the book stays two-sided
+	m.price = math.Max(m.price, 10*m.tickSize)
+	m.step++
+	m.candle(now, m.price, 0)
+
+	for n := m.poisson(m.script.TradesPerSecond * dt); n > 0; n-- {
+		side := "buy"
+		bid, ask := m.touch(now)
+		price := ask
+		if m.rng.Intn(2) == 0 {
+			side, price = "sell", bid
+		}
+		size := m.roundLot(m.rng.ExpFloat64() * m.script.Book.Size * 0.2)
+		m.record(side, price, size, now)
+	}
+}
+
+// poisson draws from a Poisson distribution with mean lambda
+func (m *market) poisson(lambda float64) int {
+	if lambda <= 0 {
+		return 0
+	}
+	limit, n, p := math.Exp(-lambda), 0, m.rng.Float64()
+	for p > limit {
+		n++
+		p *= m.rng.Float64()
+	}
+	return n
+}
+
+// record prints a trade on the tape
+func (m *market) record(side string, price, size float64, at time.Time) string {
+	m.tradeSeq++
+	id := fmt.Sprintf("sim-%s-%d", m.script.MarketID, m.tradeSeq)
+	m.trades = append(m.trades, Trade{
+		TradeID:   id,
+		MarketID:  m.script.MarketID,
+		Price:     m.formatPrice(price),
+		Quantity:  m.formatSize(size),
+		Side:      side,
+		Timestamp: at,
+	})
+	if len(m.trades) > maxTrades {
+		m.trades = append(m.trades[:0], m.trades[len(m.trades)-maxTrades:]...)
+	}
+	m.last = price
+	m.candle(at, price, size)
+	return id
+}
+
+// candle adds a price, and a traded size, to the one-minute candle of at
+func (m *market) candle(at time.Time, price, size float64) {
+	open := at.Truncate(time.Minute)
+	if n := len(m.candles); n > 0 && m.candles[n-1].Time.Equal(open) {
+		c := &m.candles[n-1]
+		c.High = math.Max(c.High, price)
+		c.Low = math.Min(c.Low, price)
+		c.Close = price
+		c.Volume += size
+		return
+	}
+	m.candles = append(m.candles, Candle{Time: open, Open: price, High: price, Low: price, Close: price, Volume: size})
+	if len(m.candles) > maxCandles {
+		m.candles = append(m.candles[:0], m.candles[len(m.candles)-maxCandles:]...)
+	}
+}
+
+// halfSpread is half the relative spread at now, widened after a gap
+func (m *market) halfSpread(now time.Time) float64 {
+	spread := m.script.Book.SpreadBps / 1e4
+	if !m.lastGap.IsZero() {
+		since := now.Sub(m.lastGap).Seconds()
+		spread *= 1 + gapSpreadFactor*math.Exp(-since/gapSpreadDecay)
+	}
+	return spread / 2
+}
+
+// touch returns the best bid and ask at now, at least a tick apart
+func (m *market) touch(now time.Time) (bid, ask float64) {
+	half := m.halfSpread(now)
+	bid = math.Floor(m.price*(1-half)/m.tickSize) * m.tickSize
+	ask = math.Ceil(m.price*(1+half)/m.tickSize) * m.tickSize
+	if ask-bid < m.tickSize/2 {
+		ask = bid + m.tickSize
+	}
+	return bid, ask
+}
+
+// roundLot rounds a size down to the lot size, to at least one lot
+func (m *market) roundLot(size float64) float64 {
+	return math.Max(math.Floor(size/m.lotSize)*m.lotSize, m.lotSize)
+}
+
+func (m *market) formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', m.priceDec, 64)
+}
+
+func (m *market) formatSize(size float64) string {
+	return strconv.FormatFloat(size, 'f', m.sizeDec, 64)
+}
+
+// book regenerates the synthetic book of the current tick. The same tick
+// always yields the same book.
+func (m *market) book(now time.Time, depth int) *Book {
+	shape := m.script.Book
+	if depth <= 0 || depth > shape.Levels {
+		depth = shape.Levels
+	}
+	rng := rand.New(rand.NewSource(m.bookSeed + m.step))
+	stepSize := math.Max(math.Round(m.price*shape.StepBps/1e4/m.tickSize), 1) * m.tickSize
+	bid, ask := m.touch(now)
+
+	b := &Book{
+		MarketID:  m.script.MarketID,
+		Bids:      make([]Level, 0, depth),
+		Asks:      make([]Level, 0, depth),
+		Timestamp: now,
+	}
+	size := func(i int) string {
+		return m.formatSize(m.roundLot(shape.Size * (1 + 0.15*float64(i)) * (0.5 + rng.Float64())))
+	}
+	for i := 0; i < depth; i++ {
+		price := bid - float64(i)*stepSize
+		if price <= 0 {
+			break
+		}
+		b.Bids = append(b.Bids, Level{Price: m.formatPrice(price), Quantity: size(i)})
+	}
+	for i := 0; i < depth; i++ {
+		b.Asks = append(b.Asks, Level{Price: m.formatPrice(ask + float64(i)*stepSize), Quantity: size(i)})
+	}
+	return b
+}
+
+// get advances the simulation to now and returns a market
+func (s *Simulator) get(marketID string, now time.Time) (*market, error) {
+	m, ok := s.markets[marketID]
+	if !ok {
+		return nil, fmt.Errorf("unknown market: %s", marketID)
+	}
+	s.advance(now)
+	return m, nil
+}
+
+// Price returns a market's mid price
+func (s *Simulator) Price(marketID string, now time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.get(marketID, now)
+	if err != nil {
+		return "", err
+	}
+	return m.formatPrice(math.Round(m.price/m.tickSize) * m.tickSize), nil
+}
+
+// Book returns up to depth levels a side of a market's synthetic book, all
+// its levels if depth is not positive
+func (s *Simulator) Book(marketID string, depth int, now time.Time) (*Book, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.get(marketID, now)
+	if err != nil {
+		return nil, err
+	}
+	return m.book(now, depth), nil
+}
+
+// Trades returns up to limit of a market's latest trades, newest first
+func (s *Simulator) Trades(marketID string, limit int, now time.Time) ([]Trade, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.get(marketID, now)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > len(m.trades) {
+		limit = len(m.trades)
+	}
+	trades := make([]Trade, 0, limit)
+	for i := len(m.trades) - 1; len(trades) < limit; i-- {
+		trades = append(trades, m.trades[i])
+	}
+	return trades, nil
+}
+
+// Candles returns up to limit of a market's latest candles of an interval,
+// oldest first, aggregated from its one-minute candles. The interval must be
+// a whole number of minutes dividing a day, e.g. 5m or 4h.
+func (s *Simulator) Candles(marketID string, interval time.Duration, limit int, now time.Time) ([]Candle, error) {
+	if interval < time.Minute || interval%time.Minute != 0 || (24*time.Hour)%interval != 0 {
+		return nil, fmt.Errorf("unsupported candle interval %s", interval)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.get(marketID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var candles []Candle
+	for _, c := range m.candles {
+		open := c.Time.Truncate(interval)
+		if n := len(candles); n > 0 && candles[n-1].Time.Equal(open) {
+			agg := &candles[n-1]
+			agg.High = math.Max(agg.High, c.High)
+			agg.Low = math.Min(agg.Low, c.Low)
+			agg.Close = c.Close
+			agg.Volume += c.Volume
+			continue
+		}
+		c.Time = open
+		candles = append(candles, c)
+	}
+	if limit > 0 && len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	return candles, nil
+}
+
+// Ticker summarizes a market over the last 24 hours. The index trails the
+// mark by a tenth of the last hour's move, and the hourly funding rate is an
+// eighth of that basis.
+func (s *Simulator) Ticker(marketID string, now time.Time) (*Ticker, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.get(marketID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	mark := math.Round(m.price/m.tickSize) * m.tickSize
+	high, low, volume := mark, mark, 0.0
+	open24h, open1h := mark, mark
+	for i := len(m.candles) - 1; i >= 0; i-- {
+		c := m.candles[i]
+		if now.Sub(c.Time) > 24*time.Hour {
+			break
+		}
+		high, low = math.Max(high, c.High), math.Min(low, c.Low)
+		volume += c.Volume * c.Close
+		open24h = c.Open
+		if now.Sub(c.Time) <= time.Hour {
+			open1h = c.Open
+		}
+	}
+
+	basis := math.Max(-0.005, math.Min(0.005, (mark/open1h-1)/10))
+	return &Ticker{
+		MarketID:    marketID,
+		MarkPrice:   m.formatPrice(mark),
+		IndexPrice:  m.formatPrice(math.Round(mark/(1+basis)/m.tickSize) * m.tickSize),
+		LastPrice:   m.formatPrice(m.last),
+		High24h:     m.formatPrice(high),
+		Low24h:      m.formatPrice(low),
+		Volume24h:   strconv.FormatFloat(volume, 'f', 2, 64),
+		Change24h:   strconv.FormatFloat((mark/open24h-1)*100, 'f', 2, 64),
+		FundingRate: strconv.FormatFloat(basis/8, 'f', 8, 64),
+		NextFunding: now.Truncate(time.Hour).Add(time.Hour),
+		Timestamp:   now,
+	}, nil
+}
+
+// Match returns the virtual counterparty's fills of the given resting orders
+// at now. An order is filled once the book has stayed through its price, the
+// best ask at or below a buy's price or the best bid at or above a sell's,
+// for the fill delay; each fill takes the script's fill fraction of what
+// remains, rounded to lots. Fills print on the tape. Orders missing from the
+// list are forgotten, so callers pass every order still resting.
+func (s *Simulator) Match(orders []RestingOrder, now time.Time) []Fill {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advance(now)
+
+	resting := make(map[string]bool, len(orders))
+	var fills []Fill
+	for _, o := range orders {
+		m, ok := s.markets[o.MarketID]
+		if !ok || o.Remaining <= 0 {
+			continue
+		}
+		resting[o.OrderID] = true
+
+		bid, ask := m.touch(now)
+		crossed := (o.Side == "buy" && ask <= o.Price) || (o.Side == "sell" && bid >= o.Price)
+		if !crossed {
+			delete(s.crossedSince, o.OrderID)
+			continue
+		}
+		since, seen := s.crossedSince[o.OrderID]
+		if !seen {
+			s.crossedSince[o.OrderID] = now
+			since = now
+		}
+		if now.Sub(since) < s.FillDelay() {
+			continue
+		}
+
+		qty := o.Remaining
+		if s.script.FillFraction < 1 {
+			qty = math.Min(m.roundLot(o.Remaining*s.script.FillFraction), o.Remaining)
+		}
+		takerSide := "sell"
+		if o.Side == "sell" {
+			takerSide = "buy"
+		}
+		fills = append(fills, Fill{
+			OrderID:  o.OrderID,
+			MarketID: o.MarketID,
+			Side:     o.Side,
+			Price:    o.Price,
+			Quantity: qty,
+			TradeID:  m.record(takerSide, o.Price, qty, now),
+		})
+		// The next partial fill waits for another delay
+		s.crossedSince[o.OrderID] = now
+	}
+	for id := range s.crossedSince {
+		if !resting[id] {
+			delete(s.crossedSince, id)
+		}
+	}
+	sort.SliceStable(fills, func(i, j int) bool { return fills[i].OrderID < fills[j].OrderID })
+	return fills
+}
+
+// Taker fills a user's market order against the synthetic book at the best
+// price of the opposite side and prints it on the tape
+func (s *Simulator) Taker(marketID, side string, quantity float64, now time.Time) (*Fill, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.get(marketID, now)
+	if err != nil {
+		return nil, err
+	}
+	bid, ask := m.touch(now)
+	price := ask
+	if side == "sell" {
+		price = bid
+	}
+	return &Fill{
+		MarketID: marketID,
+		Side:     side,
+		Price:    price,
+		Quantity: quantity,
+		TradeID:  m.record(side, price, quantity, now),
+	}, nil
+}
+
+// FormatPrice formats a price with a market's tick decimals
+func (s *Simulator) FormatPrice(marketID string, price float64) string {
+	if m, ok := s.markets[marketID]; ok {
+		return m.formatPrice(price)
+	}
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
+
+// FormatSize formats a size with a market's lot decimals
+func (s *Simulator) FormatSize(marketID string, size float64) string {
+	if m, ok := s.markets[marketID]; ok {
+		return m.formatSize(size)
+	}
+	return strconv.FormatFloat(size, 'f', -1, 64)
+}
@@ -0,0 +1,224 @@
+package mocksim
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// testScript is one market with an hour of history and a 10% drop a minute
+// after the start
+func testScript() *Script {
+	script := &Script{
+		Seed:         7,
+		HistoryHours: 1,
+		Markets: []MarketScript{{
+			MarketID: "BTC-USDC", StartPrice: 50000, TickSize: "0.1", LotSize: "0.001",
+			Phases: []Phase{
+				{Path: PathRandomWalk, Seconds: 300, Volatility: 0.0005},
+				{Path: PathTrend, Seconds: 300, Volatility: 0.0002, Drift: 0.00002},
+			},
+			Gaps:            []Gap{{At: 60, Move: -0.1}},
+			Book:            BookShape{Size: 1},
+			TradesPerSecond: 4,
+		}},
+	}
+	script.applyDefaults()
+	return script
+}
+
+func newTestSimulator(t *testing.T, script *Script) *Simulator {
+	t.Helper()
+	sim, err := New(script, testStart)
+	if err != nil {
+		t.Fatalf("failed to create simulator: %v", err)
+	}
+	return sim
+}
+
+func price(t *testing.T, s string) float64 {
+	t.Helper()
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		t.Fatalf("invalid price %q: %v", s, err)
+	}
+	return v
+}
+
+// TestSimulatorDeterministic tests that a seed replays the same market and
+// that the history fills the charts before the start
+func TestSimulatorDeterministic(t *testing.T) {
+	a, b := newTestSimulator(t, testScript()), newTestSimulator(t, testScript())
+	at := testStart.Add(30 * time.Second)
+
+	pa, _ := a.Price("BTC-USDC", at)
+	pb, _ := b.Price("BTC-USDC", at)
+	if pa != pb {
+		t.Fatalf("expected the same price for the same seed, got %s and %s", pa, pb)
+	}
+	ba, _ := a.Book("BTC-USDC", 5, at)
+	bb, _ := b.Book("BTC-USDC", 5, at)
+	if ba.Bids[4] != bb.Bids[4] || ba.Asks[0] != bb.Asks[0] {
+		t.Errorf("expected the same book for the same seed")
+	}
+
+	other := testScript()
+	other.Seed = 8
+	if pc, _ := newTestSimulator(t, other).Price("BTC-USDC", at); pc == pa {
+		t.Errorf("expected another seed to move the price elsewhere, got %s", pc)
+	}
+
+	candles, err := a.Candles("BTC-USDC", time.Minute, 0, at)
+	if err != nil {
+		t.Fatalf("failed to get candles: %v", err)
+	}
+	if len(candles) != 61 || !candles[0].Time.Equal(testStart.Add(-time.Hour)) {
+		t.Fatalf("expected an hour of one-minute candles and the current one, got %d from %s", len(candles), candles[0].Time)
+	}
+	fives, _ := a.Candles("BTC-USDC", 5*time.Minute, 3, at)
+	if len(fives) != 3 || fives[2].Close != candles[60].Close || fives[1].Volume == 0 {
+		t.Errorf("expected the last three five-minute candles, got %+v", fives)
+	}
+	if _, err := a.Candles("BTC-USDC", 7*time.Minute, 0, at); err == nil {
+		t.Errorf("expected an interval not dividing a day to be rejected")
+	}
+	if _, err := a.Ticker("NOPE-USDC", at); err == nil {
+		t.Errorf("expected an unknown market to be rejected")
+	}
+}
+
+// TestSimulatorBookAndGap tests that the book brackets the price on tick and
+// lot increments and that a gap moves the price and widens the spread
+func TestSimulatorBookAndGap(t *testing.T) {
+	sim := newTestSimulator(t, testScript())
+	spread := func(at time.Time) (float64, float64) {
+		book, err := sim.Book("BTC-USDC", 0, at)
+		if err != nil {
+			t.Fatalf("failed to get book: %v", err)
+		}
+		if len(book.Bids) != DefaultBookLevels || len(book.Asks) != DefaultBookLevels {
+			t.Fatalf("expected %d levels a side, got %d and %d", DefaultBookLevels, len(book.Bids), len(book.Asks))
+		}
+		for i, l := range append(book.Bids, book.Asks...) {
+			p, q := strings.Split(l.Price, "."), strings.Split(l.Quantity, ".")
+			if len(p) != 2 || len(p[1]) != 1 || len(q) != 2 || len(q[1]) != 3 {
+				t.Fatalf("level %d not on tick and lot decimals: %+v", i, l)
+			}
+		}
+		bid, ask := price(t, book.Bids[0].Price), price(t, book.Asks[0].Price)
+		if bid >= ask || price(t, book.Bids[1].Price) >= bid || price(t, book.Asks[1].Price) <= ask {
+			t.Fatalf("expected a sorted book with a spread, got %+v / %+v", book.Bids[:2], book.Asks[:2])
+		}
+		return (bid + ask) / 2, ask - bid
+	}
+
+	midBefore, spreadBefore := spread(testStart.Add(59 * time.Second))
+	midAfter, spreadAfter := spread(testStart.Add(61 * time.Second))
+	if move := midAfter/midBefore - 1; move > -0.09 || move < -0.11 {
+		t.Errorf("expected the gap to drop the price 10%%, got %.4f", move)
+	}
+	if spreadAfter < 3*spreadBefore*0.9 {
+		t.Errorf("expected the spread to widen after the gap, got %v then %v", spreadBefore, spreadAfter)
+	}
+	if _, settled := spread(testStart.Add(2 * time.Minute)); settled > spreadAfter/2 {
+		t.Errorf("expected the spread to narrow after the gap, got %v then %v", spreadAfter, settled)
+	}
+
+	ticker, err := sim.Ticker("BTC-USDC", testStart.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("failed to get ticker: %v", err)
+	}
+	if price(t, ticker.Low24h) > price(t, ticker.MarkPrice) || price(t, ticker.High24h) < midBefore || price(t, ticker.Change24h) >= 0 {
+		t.Errorf("expected the ticker to show the drop, got %+v", ticker)
+	}
+	if !ticker.NextFunding.Equal(testStart.Add(time.Hour)) {
+		t.Errorf("expected funding at the next hour, got %s", ticker.NextFunding)
+	}
+	trades, _ := sim.Trades("BTC-USDC", 10, testStart.Add(2*time.Minute))
+	if len(trades) != 10 || trades[0].Timestamp.Before(trades[9].Timestamp) {
+		t.Errorf("expected ten trades newest first, got %d", len(trades))
+	}
+}
+
+// TestSimulatorMatch tests that the virtual counterparty fills a crossed
+// order after the fill delay, in fractions, and leaves others resting
+func TestSimulatorMatch(t *testing.T) {
+	script := testScript()
+	script.FillFraction = 0.5
+	sim := newTestSimulator(t, script)
+	at := testStart.Add(10 * time.Second)
+	mid := price(t, mustPrice(t, sim, at))
+
+	orders := []RestingOrder{
+		{OrderID: "through", MarketID: "BTC-USDC", Side: "buy", Price: mid * 1.01, Remaining: 1},
+		{OrderID: "away", MarketID: "BTC-USDC", Side: "sell", Price: mid * 1.05, Remaining: 1},
+	}
+	if fills := sim.Match(orders, at); len(fills) != 0 {
+		t.Fatalf("expected no fill before the delay, got %+v", fills)
+	}
+	fills := sim.Match(orders, at.Add(sim.FillDelay()))
+	if len(fills) != 1 || fills[0].OrderID != "through" || fills[0].Quantity != 0.5 || fills[0].Price != orders[0].Price {
+		t.Fatalf("expected half the crossed buy filled at its price, got %+v", fills)
+	}
+	trades, _ := sim.Trades("BTC-USDC", 1, at.Add(sim.FillDelay()))
+	if trades[0].TradeID != fills[0].TradeID || trades[0].Side != "sell" {
+		t.Errorf("expected the fill on the tape as a sell, got %+v", trades[0])
+	}
+
+	// The next fraction waits for another delay
+	orders[0].Remaining = 0.5
+	if fills := sim.Match(orders, at.Add(sim.FillDelay()+time.Millisecond)); len(fills) != 0 {
+		t.Errorf("expected no fill within the next delay, got %+v", fills)
+	}
+	if fills := sim.Match(orders, at.Add(2*sim.FillDelay())); len(fills) != 1 || fills[0].Quantity != 0.25 {
+		t.Errorf("expected a quarter filled, got %+v", fills)
+	}
+
+	taker, err := sim.Taker("BTC-USDC", "sell", 0.1, at.Add(3*sim.FillDelay()))
+	if err != nil || taker.Price >= mid*1.01 {
+		t.Errorf("expected a sell at the best bid, got %+v %v", taker, err)
+	}
+}
+
+func mustPrice(t *testing.T, sim *Simulator, at time.Time) string {
+	t.Helper()
+	p, err := sim.Price("BTC-USDC", at)
+	if err != nil {
+		t.Fatalf("failed to get price: %v", err)
+	}
+	return p
+}
+
+// TestParseScript tests that scripts get defaults and are validated
+func TestParseScript(t *testing.T) {
+	script, err := ParseScript([]byte(`{"seed":3,"markets":[{"market_id":"ETH-USDC","start_price":3000,"tick_size":"0.01","lot_size":"0.01",
+		"phases":[{"path":"flat","seconds":60}],"book":{"size":2}}]}`))
+	if err != nil {
+		t.Fatalf("failed to parse script: %v", err)
+	}
+	if script.TickMillis != DefaultTickMillis || script.FillFraction != 1 || script.Markets[0].Book.Levels != DefaultBookLevels {
+		t.Errorf("expected defaults to be applied, got %+v", script)
+	}
+
+	for name, body := range map[string]string{
+		"unknown field": `{"markets":[],"speed":2}`,
+		"no markets":    `{"markets":[]}`,
+		"unknown path": `{"markets":[{"market_id":"ETH-USDC","start_price":3000,"tick_size":"0.01","lot_size":"0.01",
+			"phases":[{"path":"zigzag","seconds":60}],"book":{"size":2}}]}`,
+		"bad tick": `{"markets":[{"market_id":"ETH-USDC","start_price":3000,"tick_size":"x","lot_size":"0.01",
+			"phases":[{"path":"flat","seconds":60}],"book":{"size":2}}]}`,
+		"wipeout gap": `{"markets":[{"market_id":"ETH-USDC","start_price":3000,"tick_size":"0.01","lot_size":"0.01",
+			"phases":[{"path":"flat","seconds":60}],"gaps":[{"at":10,"move":-1}],"book":{"size":2}}]}`,
+	} {
+		if _, err := ParseScript([]byte(body)); err == nil {
+			t.Errorf("%s: expected the script to be rejected", name)
+		}
+	}
+
+	if err := DefaultScript().Validate(); err != nil {
+		t.Errorf("expected the default script to be valid, got %v", err)
+	}
+}
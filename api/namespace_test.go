@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cosmossdk.io/math"
+)
+
+// TestNamespaceIsolation tests that namespaces keep their own accounts and
+// are selected by path prefix or header
+func TestNamespaceIsolation(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	qa, err := s.AddNamespace("qa")
+	if err != nil {
+		t.Fatalf("failed to add namespace: %v", err)
+	}
+	if _, err := s.AddNamespace("qa"); err == nil {
+		t.Error("expected a duplicate namespace to be rejected")
+	}
+	for _, name := range []string{"", "default", "QA", "a/b"} {
+		if _, err := s.AddNamespace(name); err == nil {
+			t.Errorf("expected namespace %q to be rejected", name)
+		}
+	}
+	if _, err := qa.BootstrapAccounts(context.Background(), 1, "1000"); err != nil {
+		t.Fatalf("failed to bootstrap namespace accounts: %v", err)
+	}
+	handler := s.namespaceHandler(s.handler())
+
+	balance := func(path, namespace string) (int, math.LegacyDec) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Trader-Address", "dev-trader-1")
+		if namespace != "" {
+			req.Header.Set(NamespaceHeader, namespace)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			return rec.Code, math.LegacyDec{}
+		}
+		var resp struct {
+			Account struct {
+				Balance string `json:"balance"`
+			} `json:"account"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode account: %v", err)
+		}
+		return rec.Code, math.LegacyMustNewDecFromStr(resp.Account.Balance)
+	}
+
+	// dev-trader-1 is funded only in qa; the default namespace still gives it
+	// the standalone balance of a trader that never deposited
+	thousand := math.LegacyNewDec(1000)
+	for _, tc := range []struct {
+		name, path, namespace string
+		want                  math.LegacyDec
+	}{
+		{"path prefix", "/ns/qa/v1/account", "", thousand},
+		{"header", "/v1/account", "qa", thousand},
+		{"default", "/v1/account", "", standaloneBalance},
+		{"explicit default", "/ns/default/v1/account", "", standaloneBalance},
+	} {
+		code, got := balance(tc.path, tc.namespace)
+		if code != http.StatusOK || !got.Equal(tc.want) {
+			t.Errorf("%s: expected 200 with balance %s, got %d with %s", tc.name, tc.want, code, got)
+		}
+	}
+
+	if code, _ := balance("/ns/staging/v1/account", ""); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown namespace, got %d", code)
+	}
+}
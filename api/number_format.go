@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/openalpha/perp-dex/api/numfmt"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// numberFormatterKey carries a request's numfmt.Formatter in its context
+type numberFormatterKey struct{}
+
+// numberFormatter returns the formatter rewriting the request's response,
+// nil for the raw format
+func numberFormatter(ctx context.Context) *numfmt.Formatter {
+	f, _ := ctx.Value(numberFormatterKey{}).(*numfmt.Formatter)
+	return f
+}
+
+// marketDecimals returns every market's price decimals, those of its tick
+// size, and size decimals, those of its minimum order size
+func (s *Server) marketDecimals() numfmt.Markets {
+	markets := make(numfmt.Markets)
+	for _, market := range s.getMockMarkets() {
+		id, _ := market["market_id"].(string)
+		tick, _ := market["tick_size"].(string)
+		lot, _ := market["min_order_size"].(string)
+		markets[id] = numfmt.Decimals{Price: numfmt.Places(tick), Size: numfmt.Places(lot)}
+	}
+	return markets
+}
+
+// numberFormatMiddleware rewrites JSON responses in Config.NumberFormat or,
+// when perRequest is set, the format the request names with the
+// X-Number-Format header or ?number_format=. Other responses, including
+// WebSocket upgrades, pass through untouched.
+func (s *Server) numberFormatMiddleware(perRequest bool) func(http.Handler) http.Handler {
+	markets := s.marketDecimals()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			format := s.config.NumberFormat
+			if perRequest {
+				w.Header().Add("Vary", numfmt.Header)
+				requested := r.Header.Get(numfmt.Header)
+				if requested == "" {
+					requested = r.URL.Query().Get(numfmt.QueryParam)
+				}
+				if requested != "" {
+					var err error
+					if format, err = numfmt.ParseFormat(requested); err != nil {
+						writeError(w, types.ErrCodeInvalidRequest, err.Error())
+						return
+					}
+				}
+			}
+			if format == "" || format == numfmt.FormatRaw || r.URL.Path == "/ws" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			numbers := numfmt.NewFormatter(format, markets)
+			fw := &formattingWriter{ResponseWriter: w, numbers: numbers, market: requestMarket(r)}
+			next.ServeHTTP(fw, r.WithContext(context.WithValue(r.Context(), numberFormatterKey{}, numbers)))
+			fw.finish()
+		})
+	}
+}
+
+// requestMarket returns the market a request is about, from its
+// /v1/markets/{id} path or market_id parameter, for payloads that do not
+// name it
+func requestMarket(r *http.Request) string {
+	if path, ok := strings.CutPrefix(r.URL.Path, "/v1/markets/"); ok {
+		id, _, _ := strings.Cut(path, "/")
+		return id
+	}
+	return r.URL.Query().Get("market_id")
+}
+
+// formattingWriter buffers a JSON response so it can be rewritten before it
+// is sent; any other response is streamed as written
+type formattingWriter struct {
+	http.ResponseWriter
+	numbers *numfmt.Formatter
+	market  string
+
+	decided bool
+	status  int
+	buffer  *bytes.Buffer // Set while buffering a JSON body
+}
+
+func (fw *formattingWriter) WriteHeader(status int) {
+	if fw.decided {
+		return
+	}
+	fw.decided = true
+	fw.status = status
+	mediaType, _, _ := mime.ParseMediaType(fw.Header().Get("Content-Type"))
+	if mediaType == "application/json" && status != http.StatusNoContent && status != http.StatusNotModified {
+		fw.buffer = &bytes.Buffer{}
+		return
+	}
+	fw.ResponseWriter.WriteHeader(status)
+}
+
+func (fw *formattingWriter) Write(p []byte) (int, error) {
+	if !fw.decided {
+		fw.WriteHeader(http.StatusOK)
+	}
+	if fw.buffer != nil {
+		return fw.buffer.Write(p)
+	}
+	return fw.ResponseWriter.Write(p)
+}
+
+// Flush streams unbuffered responses
+func (fw *formattingWriter) Flush() {
+	if fw.buffer != nil {
+		return
+	}
+	if flusher, ok := fw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// finish sends a buffered JSON body, rewritten; a body that is not valid
+// JSON is sent as written
+func (fw *formattingWriter) finish() {
+	if fw.buffer == nil {
+		return
+	}
+	body := fw.buffer.Bytes()
+	if formatted, err := fw.numbers.Rewrite(body, fw.market); err == nil {
+		body = formatted
+	}
+	fw.Header().Del("Content-Length")
+	fw.ResponseWriter.WriteHeader(fw.status)
+	fw.ResponseWriter.Write(body)
+}
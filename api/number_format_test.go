@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/numfmt"
+)
+
+// TestNumberFormat tests that JSON responses are rewritten in the format a
+// request names, or the server's, with BTC-USDC prices at its tick size's
+// places and sizes at its minimum order size's, and that other responses
+// pass through
+func TestNumberFormat(t *testing.T) {
+	s := &Server{config: &Config{}}
+	handler := http.NewServeMux()
+	handler.HandleFunc("/v1/markets/BTC-USDC/ticker", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"mark_price": "50000.000000000000000000", "size": "1", "funding_rate": "0.000100"})
+	})
+	handler.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("price 50000.000"))
+	})
+
+	get := func(s *Server, path, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			req.Header.Set(numfmt.Header, header)
+		}
+		rec := httptest.NewRecorder()
+		s.numberFormatMiddleware(true)(handler).ServeHTTP(rec, req)
+		return rec
+	}
+
+	testCases := []struct {
+		name     string
+		path     string
+		header   string
+		code     int
+		expected string
+	}{
+		{"raw by default", "/v1/markets/BTC-USDC/ticker", "", http.StatusOK,
+			`{"funding_rate":"0.000100","mark_price":"50000.000000000000000000","size":"1"}` + "\n"},
+		{"numbers by header", "/v1/markets/BTC-USDC/ticker", "number", http.StatusOK,
+			`{"funding_rate":0.0001,"mark_price":50000.0,"size":1.000}` + "\n"},
+		{"strings by query", "/v1/markets/BTC-USDC/ticker?number_format=string", "", http.StatusOK,
+			`{"funding_rate":"0.0001","mark_price":"50000.0","size":"1.000"}` + "\n"},
+		{"unknown format", "/v1/markets/BTC-USDC/ticker", "float", http.StatusBadRequest, ""},
+		{"not JSON", "/metrics", "number", http.StatusOK, "price 50000.000"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := get(s, tc.path, tc.header)
+			if rec.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, rec.Code, rec.Body)
+			}
+			if tc.expected != "" && rec.Body.String() != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, rec.Body)
+			}
+		})
+	}
+
+	// The server's format applies to requests that ask for none
+	s.config.NumberFormat = numfmt.FormatString
+	if rec := get(s, "/v1/markets/BTC-USDC/ticker", ""); rec.Body.String() != testCases[2].expected {
+		t.Errorf("expected the server's string format, got %s", rec.Body)
+	}
+	if rec := get(s, "/v1/markets/BTC-USDC/ticker", "raw"); rec.Body.String() != testCases[0].expected {
+		t.Errorf("expected a request to opt back into raw, got %s", rec.Body)
+	}
+}
@@ -0,0 +1,128 @@
+package numfmt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// object is a JSON object that keeps its key order, so a rewritten payload
+// differs from the original only in its numbers
+type object struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *object) index(key string) int {
+	for i, k := range o.keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (o *object) get(key string) interface{} {
+	if i := o.index(key); i >= 0 {
+		return o.values[i]
+	}
+	return nil
+}
+
+// parseValue reads one JSON value: *object, []interface{}, string,
+// json.Number, bool or nil
+func parseValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	switch delim {
+	case '{':
+		o := &object{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyTok.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected object key %v", keyTok)
+			}
+			value, err := parseValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			o.keys = append(o.keys, key)
+			o.values = append(o.values, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return o, nil
+	case '[':
+		list := make([]interface{}, 0)
+		for dec.More() {
+			value, err := parseValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("unexpected delimiter %v", delim)
+}
+
+// encodeValue writes a value read by parseValue
+func encodeValue(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case *object:
+		buf.WriteByte('{')
+		for i, key := range v.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeScalar(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := encodeValue(buf, v.values[i]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeValue(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case json.Number:
+		buf.WriteString(v.String())
+	default:
+		return encodeScalar(buf, v)
+	}
+	return nil
+}
+
+func encodeScalar(buf *bytes.Buffer, value interface{}) error {
+	bz, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	buf.Write(bz)
+	return nil
+}
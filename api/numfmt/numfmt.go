@@ -0,0 +1,389 @@
+// Package numfmt rewrites API JSON payloads so decimals are represented one
+// way. Handlers build prices and sizes from several sources, so the same
+// value can reach a client as "50000", 50000 or "50000.000000000000000000".
+// In the string and number formats every decimal is rewritten: prices and
+// sizes to their market's fixed decimal places, other decimals with trailing
+// zeros trimmed, as JSON strings or as JSON numbers respectively.
+package numfmt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Format is a decimal representation
+type Format string
+
+const (
+	FormatRaw    Format = "raw"    // Payloads as the handlers build them
+	FormatString Format = "string" // Decimal strings at fixed places
+	FormatNumber Format = "number" // JSON numbers at fixed places
+)
+
+// Header and QueryParam select a format per request or WebSocket connection
+const (
+	Header     = "X-Number-Format"
+	QueryParam = "number_format"
+)
+
+// ParseFormat parses a format name; empty is FormatRaw
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FormatRaw:
+		return FormatRaw, nil
+	case FormatString:
+		return FormatString, nil
+	case FormatNumber:
+		return FormatNumber, nil
+	}
+	return "", fmt.Errorf("unknown number format %q (want raw, string or number)", s)
+}
+
+// Decimals is a market's price and size decimal places
+type Decimals struct {
+	Price int
+	Size  int
+}
+
+// Markets maps market IDs to their decimal places
+type Markets map[string]Decimals
+
+// Places returns the decimal places of an increment such as a tick size:
+// "0.01" has 2, "5" has 0
+func Places(increment string) int {
+	_, frac, ok := strings.Cut(strings.TrimSpace(increment), ".")
+	if !ok {
+		return 0
+	}
+	return len(strings.TrimRight(frac, "0"))
+}
+
+// Formatter rewrites payloads in one format
+type Formatter struct {
+	format  Format
+	markets Markets
+}
+
+// NewFormatter creates a formatter; markets may be nil, leaving prices and
+// sizes trimmed rather than fixed
+func NewFormatter(format Format, markets Markets) *Formatter {
+	return &Formatter{format: format, markets: markets}
+}
+
+// Format returns the formatter's format
+func (f *Formatter) Format() Format {
+	return f.format
+}
+
+// Rewrite rewrites a JSON payload. Objects keep their key order. An object's
+// market_id, or the nearest enclosing one, or defaultMarket selects the
+// decimal places. Depth checksums next to bids and asks are recomputed over
+// the rewritten levels. FormatRaw returns data as is.
+func (f *Formatter) Rewrite(data []byte, defaultMarket string) ([]byte, error) {
+	if f == nil || f.format == FormatRaw {
+		return data, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	value, err := parseValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err == nil {
+		return nil, fmt.Errorf("trailing data after JSON value")
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+	if err := encodeValue(&buf, f.walk(value, "", defaultMarket)); err != nil {
+		return nil, err
+	}
+	if bytes.HasSuffix(data, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// FormatLevels formats [price, quantity] levels of a market as Rewrite
+// would send them, so a checksum can be computed over what clients receive
+func (f *Formatter) FormatLevels(marketID string, levels [][]string) [][]string {
+	if f == nil || f.format == FormatRaw {
+		return levels
+	}
+	result := make([][]string, len(levels))
+	for i, level := range levels {
+		result[i] = make([]string, len(level))
+		for j, s := range level {
+			result[i][j] = s
+			if j < 2 && isDecimal(s) {
+				result[i][j] = f.decimal(s, levelClass(j), marketID)
+			}
+		}
+	}
+	return result
+}
+
+// ============ Classification ============
+
+type class int
+
+const (
+	classOther class = iota
+	classPrice
+	classSize
+)
+
+var priceKeys = map[string]bool{
+	"price": true, "tick_size": true, "bid": true, "ask": true, "best_bid": true, "best_ask": true,
+	"open": true, "high": true, "low": true, "close": true, "high_24h": true, "low_24h": true,
+}
+
+var sizeKeys = map[string]bool{
+	"size": true, "quantity": true, "qty": true, "min_order_size": true, "max_order_size": true,
+	"filled_size": true, "remaining_size": true, "position_size": true, "lot_size": true, "step_size": true,
+}
+
+// identifierKeys hold numeric-looking strings that are not amounts
+var identifierKeys = map[string]bool{
+	"id": true, "seq": true, "version": true, "code": true, "height": true, "epoch": true, "trader": true,
+}
+
+func keyClass(key string) class {
+	switch {
+	case priceKeys[key] || strings.HasSuffix(key, "_price"):
+		return classPrice
+	case sizeKeys[key] || strings.HasSuffix(key, "_quantity") || strings.HasSuffix(key, "_qty"):
+		return classSize
+	}
+	return classOther
+}
+
+func levelClass(i int) class {
+	if i == 0 {
+		return classPrice
+	}
+	return classSize
+}
+
+func isIdentifier(key string) bool {
+	if identifierKeys[key] || strings.HasSuffix(key, "_id") || strings.HasSuffix(key, "_ids") {
+		return true
+	}
+	for _, part := range []string{"cursor", "token", "nonce", "hash", "signature", "address", "key"} {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDecimal reports whether s is a plain decimal: an optional minus sign,
+// digits and an optional fraction
+func isDecimal(s string) bool {
+	if strings.HasPrefix(s, "-") {
+		s = s[1:]
+	}
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+	if intPart == "" || (hasFrac && frac == "") {
+		return false
+	}
+	for _, part := range []string{intPart, frac} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ============ Rewriting ============
+
+// walk rewrites a value found under key inside market
+func (f *Formatter) walk(value interface{}, key, market string) interface{} {
+	switch v := value.(type) {
+	case *object:
+		if id, ok := v.get("market_id").(string); ok && id != "" {
+			market = id
+		}
+		for i, k := range v.keys {
+			v.values[i] = f.walk(v.values[i], k, market)
+		}
+		f.recomputeChecksum(v)
+		return v
+	case []interface{}:
+		levels := key == "bids" || key == "asks"
+		for i, elem := range v {
+			if pair, ok := elem.([]interface{}); ok && levels {
+				for j := range pair {
+					if j < 2 {
+						pair[j] = f.scalar(pair[j], levelClass(j), market)
+					}
+				}
+				continue
+			}
+			v[i] = f.walk(elem, key, market)
+		}
+		return v
+	case string:
+		if isIdentifier(key) {
+			return v
+		}
+		return f.scalar(v, keyClass(key), market)
+	case json.Number:
+		// Native numbers are counts and timestamps unless the key says
+		// they are a price or size
+		if c := keyClass(key); c != classOther {
+			return f.scalar(v, c, market)
+		}
+		return v
+	}
+	return value
+}
+
+// scalar formats a decimal string or number of a class
+func (f *Formatter) scalar(value interface{}, c class, market string) interface{} {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	default:
+		return value
+	}
+	if !isDecimal(s) {
+		return value
+	}
+	s = f.decimal(s, c, market)
+	if f.format == FormatNumber {
+		return json.Number(s)
+	}
+	return s
+}
+
+// decimal formats a decimal string: prices and sizes of a known market at
+// its places, everything else trimmed
+func (f *Formatter) decimal(s string, c class, market string) string {
+	if d, ok := f.markets[market]; ok && c != classOther {
+		places := d.Price
+		if c == classSize {
+			places = d.Size
+		}
+		return fixed(s, places)
+	}
+	return trim(s)
+}
+
+// recomputeChecksum replaces the checksum of an object with bids and asks by
+// the checksum of its rewritten levels
+func (f *Formatter) recomputeChecksum(o *object) {
+	i := o.index("checksum")
+	if i < 0 {
+		return
+	}
+	bids, okBids := levelStrings(o.get("bids"))
+	asks, okAsks := levelStrings(o.get("asks"))
+	if !okBids || !okAsks {
+		return
+	}
+	o.values[i] = json.Number(strconv.FormatInt(int64(obtypes.DepthChecksum(bids, asks)), 10))
+}
+
+// levelStrings extracts levels sent as [price, quantity] pairs or as
+// objects with price and quantity
+func levelStrings(value interface{}) ([][]string, bool) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	levels := make([][]string, 0, len(list))
+	for _, elem := range list {
+		var price, quantity interface{}
+		switch level := elem.(type) {
+		case []interface{}:
+			if len(level) < 2 {
+				return nil, false
+			}
+			price, quantity = level[0], level[1]
+		case *object:
+			price, quantity = level.get("price"), level.get("quantity")
+		default:
+			return nil, false
+		}
+		p, okP := scalarText(price)
+		q, okQ := scalarText(quantity)
+		if !okP || !okQ {
+			return nil, false
+		}
+		levels = append(levels, []string{p, q})
+	}
+	return levels, true
+}
+
+func scalarText(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	}
+	return "", false
+}
+
+// ============ Decimal strings ============
+
+// trim drops trailing fractional zeros: "1.500" is "1.5", "2.000" is "2"
+func trim(s string) string {
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return normalizeZero(s)
+}
+
+// fixed rounds s half away from zero to places decimals and pads it to them
+func fixed(s string, places int) string {
+	negative := strings.HasPrefix(s, "-")
+	intPart, frac, _ := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+
+	digits, ok := new(big.Int).SetString(intPart+frac, 10)
+	if !ok {
+		return s
+	}
+	if scale := len(frac); scale > places {
+		divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale-places)), nil)
+		quo, rem := new(big.Int).QuoRem(digits, divisor, new(big.Int))
+		if rem.Lsh(rem, 1).Cmp(divisor) >= 0 {
+			quo.Add(quo, big.NewInt(1))
+		}
+		digits = quo
+	} else if scale < places {
+		digits.Mul(digits, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places-scale)), nil))
+	}
+
+	text := digits.String()
+	if places > 0 {
+		if len(text) <= places {
+			text = strings.Repeat("0", places-len(text)+1) + text
+		}
+		text = text[:len(text)-places] + "." + text[len(text)-places:]
+	}
+	if negative {
+		text = "-" + text
+	}
+	return normalizeZero(text)
+}
+
+// normalizeZero drops the sign of a negative zero
+func normalizeZero(s string) string {
+	if strings.HasPrefix(s, "-") && strings.Trim(s, "-0.") == "" {
+		return s[1:]
+	}
+	return s
+}
@@ -0,0 +1,114 @@
+package numfmt
+
+import (
+	"strconv"
+	"testing"
+
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+var testMarkets = Markets{"BTC-USDC": {Price: 1, Size: 3}}
+
+// TestRewrite tests that prices and sizes are fixed to their market's
+// places, other decimals trimmed, identifiers and counts left alone and key
+// order kept
+func TestRewrite(t *testing.T) {
+	payload := `{"order_id":"100","trader":"cosmos1abc","market_id":"BTC-USDC","price":"50000.000000000000000000",` +
+		`"quantity":"1.5","fee":"2.500000000000000000","mark_price":50000.06,"timestamp":1704067200000,` +
+		`"fills":[{"price":"49999.95","quantity":"0.0005"}],"other":{"market_id":"DOGE-USDC","price":"0.123400"}}` + "\n"
+
+	testCases := []struct {
+		format   Format
+		expected string
+	}{
+		{FormatRaw, payload},
+		{FormatString, `{"order_id":"100","trader":"cosmos1abc","market_id":"BTC-USDC","price":"50000.0",` +
+			`"quantity":"1.500","fee":"2.5","mark_price":"50000.1","timestamp":1704067200000,` +
+			`"fills":[{"price":"50000.0","quantity":"0.001"}],"other":{"market_id":"DOGE-USDC","price":"0.1234"}}` + "\n"},
+		{FormatNumber, `{"order_id":"100","trader":"cosmos1abc","market_id":"BTC-USDC","price":50000.0,` +
+			`"quantity":1.500,"fee":2.5,"mark_price":50000.1,"timestamp":1704067200000,` +
+			`"fills":[{"price":50000.0,"quantity":0.001}],"other":{"market_id":"DOGE-USDC","price":0.1234}}` + "\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			got, err := NewFormatter(tc.format, testMarkets).Rewrite([]byte(payload), "")
+			if err != nil {
+				t.Fatalf("failed to rewrite: %v", err)
+			}
+			if string(got) != tc.expected {
+				t.Errorf("expected\n%s\ngot\n%s", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestRewriteDepth tests that book levels are formatted by position, that the
+// request's market applies when the payload names none and that the checksum
+// matches the rewritten levels
+func TestRewriteDepth(t *testing.T) {
+	bids := [][]string{{"49999.900000000000000000", "1.000000000000000000"}}
+	asks := [][]string{{"50000.1", "0.25"}}
+	payload := `{"bids":[["49999.900000000000000000","1.000000000000000000"]],"asks":[["50000.1","0.25"]],"checksum":` +
+		strconv.FormatInt(int64(obtypes.DepthChecksum(bids, asks)), 10) + `}`
+
+	f := NewFormatter(FormatString, testMarkets)
+	got, err := f.Rewrite([]byte(payload), "BTC-USDC")
+	if err != nil {
+		t.Fatalf("failed to rewrite: %v", err)
+	}
+	formattedBids, formattedAsks := f.FormatLevels("BTC-USDC", bids), f.FormatLevels("BTC-USDC", asks)
+	if formattedBids[0][0] != "49999.9" || formattedAsks[0][1] != "0.250" {
+		t.Fatalf("unexpected levels %v %v", formattedBids, formattedAsks)
+	}
+	expected := `{"bids":[["49999.9","1.000"]],"asks":[["50000.1","0.250"]],"checksum":` +
+		strconv.FormatInt(int64(obtypes.DepthChecksum(formattedBids, formattedAsks)), 10) + `}`
+	if string(got) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+
+	// WebSocket depth levels are objects
+	ws := `{"type":"update","channel":"depth:BTC-USDC","data":{"market_id":"BTC-USDC","bids":[{"price":"49999.90","quantity":"1"}],"asks":[],"checksum":1}}`
+	got, err = NewFormatter(FormatNumber, testMarkets).Rewrite([]byte(ws), "")
+	if err != nil {
+		t.Fatalf("failed to rewrite: %v", err)
+	}
+	sum := obtypes.DepthChecksum([][]string{{"49999.9", "1.000"}}, nil)
+	expected = `{"type":"update","channel":"depth:BTC-USDC","data":{"market_id":"BTC-USDC","bids":[{"price":49999.9,"quantity":1.000}],"asks":[],"checksum":` +
+		strconv.FormatUint(uint64(sum), 10) + `}}`
+	if string(got) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+// TestFixed tests rounding half away from zero and padding
+func TestFixed(t *testing.T) {
+	testCases := []struct {
+		value    string
+		places   int
+		expected string
+	}{
+		{"50000", 2, "50000.00"},
+		{"1.005", 2, "1.01"},
+		{"1.004999", 2, "1.00"},
+		{"-1.005", 2, "-1.01"},
+		{"9.96", 1, "10.0"},
+		{"0.0004", 3, "0.000"},
+		{"-0.0004", 3, "0.000"},
+		{"123.45", 0, "123"},
+		{"0.5", 0, "1"},
+	}
+	for _, tc := range testCases {
+		if got := fixed(tc.value, tc.places); got != tc.expected {
+			t.Errorf("fixed(%s, %d): expected %s, got %s", tc.value, tc.places, tc.expected, got)
+		}
+	}
+	if got := trim("-0.000"); got != "0" {
+		t.Errorf("expected a trimmed negative zero to be 0, got %s", got)
+	}
+	if Places("0.010") != 2 || Places("5") != 0 {
+		t.Errorf("unexpected places")
+	}
+	if _, err := ParseFormat("float"); err == nil {
+		t.Errorf("expected an unknown format to fail")
+	}
+}
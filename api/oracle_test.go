@@ -128,3 +128,31 @@ func TestHyperliquidOracle_GetKlines(t *testing.T) {
 		t.Logf("  Latest candle: O=%.2f H=%.2f L=%.2f C=%.2f V=%.2f", k.Open, k.High, k.Low, k.Close, k.Volume)
 	}
 }
+
+func TestIndexFromTicker(t *testing.T) {
+	ticker := &TickerData{
+		MarketID:   "BTC-USDC",
+		MarkPrice:  "50050.0",
+		IndexPrice: "50000.0",
+		Timestamp:  1700000000000,
+	}
+
+	index, err := indexFromTicker(ticker)
+	if err != nil {
+		t.Fatalf("indexFromTicker() error = %v", err)
+	}
+	if index.Basis != "50.000000000000000000" {
+		t.Errorf("basis = %s, want 50", index.Basis)
+	}
+	if index.BasisRate != "0.001000000000000000" {
+		t.Errorf("basis rate = %s, want 0.001", index.BasisRate)
+	}
+	if len(index.Sources) != 1 || index.Sources[0].Source != hyperliquidIndexSource || !index.Sources[0].Included {
+		t.Errorf("sources = %+v, want single included hyperliquid source", index.Sources)
+	}
+
+	ticker.IndexPrice = "n/a"
+	if _, err := indexFromTicker(ticker); err == nil {
+		t.Error("indexFromTicker() expected error for invalid index price")
+	}
+}
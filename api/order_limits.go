@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/metrics"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// recordOrderLimitRejection counts an order placement rejected at a resting
+// order limit
+func recordOrderLimitRejection(marketID string, err error) {
+	switch {
+	case errors.Is(err, obtypes.ErrMarketOrderLimit):
+		metrics.GetCollector().RecordOrderLimitRejection(marketID, obtypes.OrderLimitScopeMarket)
+	case errors.Is(err, obtypes.ErrTraderOrderLimit):
+		metrics.GetCollector().RecordOrderLimitRejection(marketID, obtypes.OrderLimitScopeTrader)
+	}
+}
+
+// handleAdminOrderLimits handles /v1/admin/order-limits (GET, PUT): the caps
+// on resting orders per market and per trader, with each market's count
+func (s *Server) handleAdminOrderLimits(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	limits, ok := s.orderService.(types.OrderLimitsService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Order limits require a keeper-backed service")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		status, err := limits.GetOrderLimits(r.Context())
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+
+	case http.MethodPut:
+		var req types.OrderLimits
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		status, err := limits.SetOrderLimits(r.Context(), &req)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
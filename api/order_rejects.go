@@ -0,0 +1,26 @@
+package api
+
+import (
+	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/metrics"
+)
+
+// rejectMetricMarkets are the markets rejections are labelled with. Market
+// IDs come from the client, so any other value is counted as "other" to keep
+// the metric's cardinality bounded.
+var rejectMetricMarkets = defaultMarketRules()
+
+// ObserveOrderReject counts a rejected order placement or amendment in
+// perpdex_orders_rejections_total and acknowledges it on the trader's orders
+// WebSocket channel
+func (s *Server) ObserveOrderReject(reject *types.OrderReject) {
+	market := "other"
+	if _, ok := rejectMetricMarkets[reject.MarketID]; ok {
+		market = reject.MarketID
+	}
+	metrics.GetCollector().RecordOrderRejection(market, reject.Action, string(reject.Reason), string(reject.Code))
+
+	if reject.Trader != "" && s.wsServer != nil {
+		s.wsServer.BroadcastOrderReject(reject.Trader, reject)
+	}
+}
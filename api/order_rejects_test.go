@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/metrics"
+)
+
+// TestOrderRejectReasons tests that a rejected order carries its reject
+// reason in the response and is counted by reason, with unknown markets
+// folded into "other"
+func TestOrderRejectReasons(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	rejections := func(market, code string) float64 {
+		t.Helper()
+		var m dto.Metric
+		counter := metrics.GetCollector().OrderRejections.WithLabelValues(market, types.RejectActionPlace, string(types.RejectReasonValidation), code)
+		if err := counter.Write(&m); err != nil {
+			t.Fatalf("failed to read counter: %v", err)
+		}
+		return m.GetCounter().GetValue()
+	}
+	place := func(body string) *types.ErrorResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body))
+		req.Header.Set("X-Trader-Address", "reject-trader")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body)
+		}
+		var resp types.ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return &resp
+	}
+
+	before := rejections("BTC-USDC", string(types.ErrCodeInvalidDecimal))
+	resp := place(`{"market_id":"BTC-USDC","side":"buy","type":"limit","price":"49000","quantity":"abc"}`)
+	if resp.Code != types.ErrCodeInvalidDecimal || resp.RejectReason != types.RejectReasonValidation {
+		t.Errorf("expected an invalid_decimal validation reject, got %+v", resp.APIError)
+	}
+	if got := rejections("BTC-USDC", string(types.ErrCodeInvalidDecimal)) - before; got != 1 {
+		t.Errorf("expected 1 rejection counted for BTC-USDC, got %v", got)
+	}
+
+	before = rejections("other", string(types.ErrCodeMissingField))
+	place(`{"market_id":"NOPE-USDC","side":"buy","type":"limit","price":"49000"}`)
+	if got := rejections("other", string(types.ErrCodeMissingField)) - before; got != 1 {
+		t.Errorf("expected an unknown market to be counted as other, got %v", got)
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Multi-market book depths
+const (
+	DefaultOrderBooksDepth = 20
+	MaxOrderBooksDepth     = 100
+)
+
+// handleOrderBooks handles GET /v1/orderbooks?markets=&depth=, the books of
+// several markets, or of every market without ?markets=, in one response
+// with one timestamp
+func (s *Server) handleOrderBooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	depth := DefaultOrderBooksDepth
+	if d := r.URL.Query().Get("depth"); d != "" {
+		n, err := strconv.Atoi(d)
+		if err != nil || n <= 0 || n > MaxOrderBooksDepth {
+			writeError(w, types.ErrCodeInvalidRequest, fmt.Sprintf("depth must be between 1 and %d", MaxOrderBooksDepth))
+			return
+		}
+		depth = n
+	}
+
+	var marketIDs []string
+	if list := r.URL.Query().Get("markets"); list != "" {
+		seen := make(map[string]bool)
+		for _, id := range strings.Split(list, ",") {
+			id = strings.TrimSpace(id)
+			if id == "" || seen[id] {
+				continue
+			}
+			if s.getMockMarket(id) == nil {
+				writeError(w, types.ErrCodeMarketNotFound, "Market not found: "+id)
+				return
+			}
+			seen[id] = true
+			marketIDs = append(marketIDs, id)
+		}
+	} else {
+		for _, market := range s.getMockMarkets() {
+			marketIDs = append(marketIDs, market["market_id"].(string))
+		}
+	}
+
+	books, err := s.orderBooks(r.Context(), marketIDs, depth)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeServiceUnavailable))
+		return
+	}
+	writeJSON(w, http.StatusOK, books)
+}
+
+// orderBooks reads the books from the engine's read model, in one call
+// where it supports that, or the oracle books when no engine backs the
+// server. A market whose oracle book is unavailable gets an empty book, as
+// in the snapshot.
+func (s *Server) orderBooks(ctx context.Context, marketIDs []string, depth int) (*types.OrderBooks, error) {
+	if s.marketData != nil {
+		return types.GetOrderBooks(ctx, s.marketData, marketIDs, depth)
+	}
+
+	books := &types.OrderBooks{
+		Depth:     depth,
+		Books:     make([]*types.OrderBookSnapshot, 0, len(marketIDs)),
+		Timestamp: time.Now().UnixMilli(),
+	}
+	for _, marketID := range marketIDs {
+		book := s.getMockOrderbook(marketID, depth)
+		bids, _ := book["bids"].([][]string)
+		asks, _ := book["asks"].([][]string)
+		books.Books = append(books.Books, &types.OrderBookSnapshot{
+			MarketID:  marketID,
+			Bids:      bids,
+			Asks:      asks,
+			Checksum:  obtypes.DepthChecksum(bids, asks),
+			Timestamp: books.Timestamp,
+		})
+	}
+	return books, nil
+}
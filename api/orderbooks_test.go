@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestOrderBooks tests that several markets' books are returned in one
+// response, in the requested order and with one timestamp
+func TestOrderBooks(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	s.oracle = nil
+	s.marketData = s.orderService.(types.MarketDataService)
+	handler := s.handler()
+
+	orders := []string{
+		`{"market_id":"BTC-USDC","side":"buy","type":"limit","price":"49900","quantity":"0.1","trader":"books-maker"}`,
+		`{"market_id":"BTC-USDC","side":"buy","type":"limit","price":"49800","quantity":"0.1","trader":"books-maker"}`,
+		`{"market_id":"ETH-USDC","side":"sell","type":"limit","price":"3100","quantity":"1","trader":"books-maker"}`,
+	}
+	for _, body := range orders {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("failed to place order: %d %s", rec.Code, rec.Body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/orderbooks?markets=ETH-USDC,BTC-USDC,ETH-USDC&depth=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var books types.OrderBooks
+	if err := json.Unmarshal(rec.Body.Bytes(), &books); err != nil {
+		t.Fatalf("failed to decode books: %v", err)
+	}
+	if books.Depth != 1 || len(books.Books) != 2 || books.Books[0].MarketID != "ETH-USDC" || books.Books[1].MarketID != "BTC-USDC" {
+		t.Fatalf("expected ETH-USDC and BTC-USDC once each, got %+v", books.Books)
+	}
+	for _, book := range books.Books {
+		if book.Timestamp != books.Timestamp || book.Checksum == 0 {
+			t.Errorf("expected %s stamped %d with a checksum, got %+v", book.MarketID, books.Timestamp, book)
+		}
+	}
+	if eth := books.Books[0]; len(eth.Asks) != 1 || len(eth.Bids) != 0 {
+		t.Errorf("expected ETH-USDC's ask only, got %+v", eth)
+	}
+	if btc := books.Books[1]; len(btc.Bids) != 1 || btc.Bids[0][0] != "49900.000000000000000000" {
+		t.Errorf("expected BTC-USDC's best bid only, got %+v", btc)
+	}
+
+	// The checksum endpoint agrees with the engine's book
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/markets/BTC-USDC/orderbook/checksum?depth=1", nil))
+	var checksum struct {
+		Checksum int32 `json:"checksum"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &checksum); err != nil || checksum.Checksum != books.Books[1].Checksum {
+		t.Errorf("expected checksum %d, got %s", books.Books[1].Checksum, rec.Body)
+	}
+
+	for _, url := range []string{"/v1/orderbooks?depth=0", "/v1/orderbooks?depth=101", "/v1/orderbooks?markets=BTC-USDC,DOGE-USDC", "/v1/markets/BTC-USDC/orderbook/checksum?depth=ten"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if rec.Code == http.StatusOK {
+			t.Errorf("GET %s: expected an error, got 200", url)
+		}
+	}
+}
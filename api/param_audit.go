@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// Audit log query page sizes
+const (
+	DefaultParamAuditLimit = 100
+	MaxParamAuditLimit     = 1000
+)
+
+// paramAudit is the append-only log of protocol parameter changes. Entries
+// are kept in memory and, with Config.ParamAuditLog set, appended to that
+// file as JSON lines and reloaded from it on start.
+type paramAudit struct {
+	mu      sync.RWMutex
+	path    string
+	entries []*types.ParamChange // oldest first
+	nextID  int64
+}
+
+// paramAuditQuery filters the audit log; zero filters match every entry
+type paramAuditQuery struct {
+	From  int64 // Unix ms, inclusive
+	To    int64 // Unix ms, inclusive
+	Actor string
+	Param string // prefix, e.g. markets.BTC-USDC
+	Limit int
+}
+
+func newParamAudit(config *Config) *paramAudit {
+	a := &paramAudit{path: config.ParamAuditLog, nextID: 1}
+	if a.path == "" {
+		return a
+	}
+	if err := a.load(); err != nil {
+		log.Printf("Failed to load parameter audit log %s: %v", a.path, err)
+	}
+	return a
+}
+
+// load reads the entries already in the audit log file
+func (a *paramAudit) load() error {
+	file, err := os.Open(a.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var change types.ParamChange
+		if err := json.Unmarshal(scanner.Bytes(), &change); err != nil {
+			return err
+		}
+		a.entries = append(a.entries, &change)
+		if change.ID >= a.nextID {
+			a.nextID = change.ID + 1
+		}
+	}
+	return scanner.Err()
+}
+
+// record appends changes to the log, numbering them. The file is written
+// first so an entry is never served that a restart would lose.
+func (a *paramAudit) record(changes []*types.ParamChange) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i, change := range changes {
+		change.ID = a.nextID + int64(i)
+	}
+	if a.path != "" {
+		if err := a.appendFile(changes); err != nil {
+			return err
+		}
+	}
+	a.nextID += int64(len(changes))
+	a.entries = append(a.entries, changes...)
+	return nil
+}
+
+// appendFile writes changes to the audit log file as JSON lines
+func (a *paramAudit) appendFile(changes []*types.ParamChange) error {
+	file, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, change := range changes {
+		if err := enc.Encode(change); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// query returns the entries matching q, newest first
+func (a *paramAudit) query(q paramAuditQuery) []*types.ParamChange {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	result := make([]*types.ParamChange, 0)
+	for i := len(a.entries) - 1; i >= 0 && len(result) < q.Limit; i-- {
+		change := a.entries[i]
+		if q.To > 0 && change.Time > q.To {
+			continue
+		}
+		if change.Time < q.From {
+			break
+		}
+		if q.Actor != "" && change.Actor != q.Actor {
+			continue
+		}
+		if q.Param != "" && !strings.HasPrefix(change.Param, q.Param) {
+			continue
+		}
+		result = append(result, change)
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/openalpha/perp-dex/api/types"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// handlePortfolio handles GET /v1/account/portfolio: every open position of
+// the trader with notional, leverage and share of equity, exposure per base
+// asset before and after correlation offsets, and the long/short skew
+func (s *Server) handlePortfolio(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+	portfolios, ok := s.orderService.(types.PortfolioService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Portfolio requires a keeper-backed service")
+		return
+	}
+
+	portfolio, err := portfolios.GetPortfolio(r.Context(), trader)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, portfolio)
+}
+
+func (rs *RealService) GetPortfolio(ctx context.Context, trader string) (*types.Portfolio, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.perpKeeper == nil {
+		return nil, types.NewAPIError(types.ErrCodeNotImplemented, "Portfolio requires a keeper-backed service")
+	}
+	pe := rs.perpKeeper.CalculatePortfolioExposure(rs.sdkCtx, trader)
+	if pe == nil {
+		return nil, perptypes.ErrAccountNotFound
+	}
+	return fromPerpPortfolioExposure(pe), nil
+}
+
+// fromPerpPortfolioExposure converts a keeper portfolio exposure to the API response
+func fromPerpPortfolioExposure(pe *perptypes.PortfolioExposure) *types.Portfolio {
+	portfolio := &types.Portfolio{
+		Trader:           pe.Trader,
+		Equity:           pe.Equity.String(),
+		Positions:        make([]*types.PortfolioPosition, 0, len(pe.Positions)),
+		Assets:           make([]*types.PortfolioAssetExposure, 0, len(pe.Assets)),
+		LongNotional:     pe.LongNotional.String(),
+		ShortNotional:    pe.ShortNotional.String(),
+		GrossNotional:    pe.GrossNotional.String(),
+		NetNotional:      pe.NetNotional.String(),
+		AdjustedNotional: pe.AdjustedNotional.String(),
+		Leverage:         pe.Leverage.String(),
+		Skew:             pe.Skew.String(),
+		UpdatedAt:        types.NowMillis(),
+	}
+	for _, pos := range pe.Positions {
+		portfolio.Positions = append(portfolio.Positions, &types.PortfolioPosition{
+			MarketID:      pos.MarketID,
+			BaseAsset:     pos.BaseAsset,
+			Side:          pos.Side.String(),
+			Size:          pos.Size.String(),
+			MarkPrice:     pos.MarkPrice.String(),
+			Notional:      pos.Notional.String(),
+			Margin:        pos.Margin.String(),
+			UnrealizedPnl: pos.UnrealizedPnL.String(),
+			Leverage:      pos.Leverage.String(),
+			EquityShare:   pos.EquityShare.String(),
+		})
+	}
+	for _, asset := range pe.Assets {
+		portfolio.Assets = append(portfolio.Assets, &types.PortfolioAssetExposure{
+			Asset:               asset.Asset,
+			LongNotional:        asset.LongNotional.String(),
+			ShortNotional:       asset.ShortNotional.String(),
+			NetNotional:         asset.NetNotional.String(),
+			AdjustedNetNotional: asset.AdjustedNetNotional.String(),
+		})
+	}
+	return portfolio
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// handlePortfolioMargin handles GET /v1/account/portfolio-margin: the
+// trader's margin under the portfolio margin risk array next to the
+// per-position requirement. With market_id, side (buy or sell) and size, it
+// previews the book after that trade at the mark price.
+func (s *Server) handlePortfolioMargin(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+	portfolio, ok := s.orderService.(types.PortfolioMarginService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Portfolio margin requires a keeper-backed service")
+		return
+	}
+
+	var trade *types.PortfolioTrade
+	query := r.URL.Query()
+	if query.Get("market_id") != "" || query.Get("side") != "" || query.Get("size") != "" {
+		trade = &types.PortfolioTrade{
+			MarketID: query.Get("market_id"),
+			Side:     query.Get("side"),
+			Size:     query.Get("size"),
+		}
+		if trade.MarketID == "" || trade.Side == "" || trade.Size == "" {
+			writeError(w, types.ErrCodeMissingField, "market_id, side and size are required together")
+			return
+		}
+		if trade.Side != "buy" && trade.Side != "sell" {
+			writeError(w, types.ErrCodeInvalidSide, "side must be buy or sell")
+			return
+		}
+	}
+
+	preview, err := portfolio.PreviewPortfolioMargin(r.Context(), trader, trade)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+		return
+	}
+	writeJSON(w, http.StatusOK, preview)
+}
+
+func (rs *RealService) PreviewPortfolioMargin(ctx context.Context, trader string, trade *types.PortfolioTrade) (*types.PortfolioMarginPreview, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.perpKeeper == nil {
+		return nil, types.NewAPIError(types.ErrCodeNotImplemented, "Portfolio margin requires a keeper-backed service")
+	}
+	if trade == nil {
+		pm := rs.perpKeeper.CalculatePortfolioMargin(rs.sdkCtx, trader)
+		if pm == nil {
+			return nil, perptypes.ErrAccountNotFound
+		}
+		return fromPerpPortfolioMargin(pm, nil), nil
+	}
+
+	size, err := math.LegacyNewDecFromStr(trade.Size)
+	if err != nil {
+		return nil, types.NewAPIError(types.ErrCodeInvalidQuantity, "size must be a decimal")
+	}
+	side := perptypes.PositionSideLong
+	if trade.Side == "sell" {
+		side = perptypes.PositionSideShort
+	}
+	pm, err := rs.perpKeeper.PreviewPortfolioMargin(rs.sdkCtx, trader, trade.MarketID, side, size)
+	if err != nil {
+		return nil, err
+	}
+	return fromPerpPortfolioMargin(pm, trade), nil
+}
+
+// fromPerpPortfolioMargin converts a keeper portfolio margin to the API response
+func fromPerpPortfolioMargin(pm *perptypes.PortfolioMargin, trade *types.PortfolioTrade) *types.PortfolioMarginPreview {
+	preview := &types.PortfolioMarginPreview{
+		Trader:            pm.Trader,
+		MarginMode:        pm.Mode.String(),
+		Trade:             trade,
+		Equity:            pm.Equity.String(),
+		Assets:            make([]*types.PortfolioAssetRisk, 0, len(pm.Assets)),
+		ScanRisk:          pm.ScanRisk.String(),
+		OffsetCredit:      pm.OffsetCredit.String(),
+		FloorMargin:       pm.FloorMargin.String(),
+		InitialMargin:     pm.InitialMargin.String(),
+		MaintenanceMargin: pm.MaintenanceMargin.String(),
+		StandardMargin:    pm.StandardMargin.String(),
+		AvailableMargin:   pm.AvailableMargin.String(),
+		Healthy:           pm.IsHealthy,
+	}
+	for _, asset := range pm.Assets {
+		preview.Assets = append(preview.Assets, &types.PortfolioAssetRisk{
+			Asset:         asset.Asset,
+			Shock:         asset.Shock.String(),
+			NetNotional:   asset.NetNotional.String(),
+			GrossNotional: asset.GrossNotional.String(),
+			LossUp:        asset.LossUp.String(),
+			LossDown:      asset.LossDown.String(),
+			ScanRisk:      asset.ScanRisk.String(),
+		})
+	}
+	return preview
+}
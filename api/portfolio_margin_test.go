@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	"cosmossdk.io/store"
+	"cosmossdk.io/store/metrics"
+	storetypes "cosmossdk.io/store/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/openalpha/perp-dex/api/types"
+	perpkeeper "github.com/openalpha/perp-dex/x/perpetual/keeper"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TestPortfolioMarginPreview tests that the preview credits a hedged book
+// with the risk array's offsets and adds a hypothetical trade to it
+func TestPortfolioMarginPreview(t *testing.T) {
+	storeKey := storetypes.NewKVStoreKey("perpetual")
+	db := dbm.NewMemDB()
+	stateStore := store.NewCommitMultiStore(db, log.NewNopLogger(), metrics.NewNoOpMetrics())
+	stateStore.MountStoreWithDB(storeKey, storetypes.StoreTypeIAVL, db)
+	if err := stateStore.LoadLatestVersion(); err != nil {
+		t.Fatalf("failed to load store: %v", err)
+	}
+	ctx := sdk.NewContext(stateStore, cmtproto.Header{Time: time.Now().UTC()}, false, log.NewNopLogger())
+	pk := perpkeeper.NewKeeper(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), storeKey, nil, "", log.NewNopLogger())
+	pk.InitDefaultMarket(ctx)
+	pk.SetMarket(ctx, perptypes.NewMarket("ETH-USDC", "ETH", "USDC"))
+	pk.SetPrice(ctx, perptypes.NewPriceInfo("ETH-USDC", math.LegacyNewDec(2500)))
+	risk := perptypes.DefaultRiskArray()
+	risk.Offsets = []perptypes.CorrelationOffset{{AssetA: "BTC", AssetB: "ETH", Offset: math.LegacyNewDecWithPrec(7, 1)}}
+	if err := pk.SetRiskArray(ctx, risk); err != nil {
+		t.Fatalf("failed to set risk array: %v", err)
+	}
+	if err := pk.Deposit(ctx, "alice", math.LegacyNewDec(10000)); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	pk.SetPosition(ctx, perptypes.NewPosition("alice", "BTC-USDC", perptypes.PositionSideLong, math.LegacyNewDec(1), math.LegacyNewDec(50000), math.LegacyNewDec(2500)))
+
+	s := &Server{orderService: NewRealServiceWithKeepers(nil, pk, ctx, log.NewNopLogger())}
+	get := func(trader, query string, v interface{}) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/v1/account/portfolio-margin"+query, nil)
+		if trader != "" {
+			req.Header.Set("X-Trader-Address", trader)
+		}
+		rec := httptest.NewRecorder()
+		s.handlePortfolioMargin(rec, req)
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+	expect := func(name, got string, want int64) {
+		t.Helper()
+		if !math.LegacyMustNewDecFromStr(got).Equal(math.LegacyNewDec(want)) {
+			t.Errorf("expected %s %d, got %s", name, want, got)
+		}
+	}
+
+	var preview types.PortfolioMarginPreview
+	if code := get("alice", "", &preview); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if preview.MarginMode != "isolated" || len(preview.Assets) != 1 || preview.Trade != nil {
+		t.Fatalf("unexpected preview %+v", preview)
+	}
+	expect("initial margin", preview.InitialMargin, 2500)
+
+	// Shorting 20 ETH hedges the BTC long: 2500 + 2500 - 2 × 1750
+	if code := get("alice", "?market_id=ETH-USDC&side=sell&size=20", &preview); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if preview.Trade == nil || len(preview.Assets) != 2 {
+		t.Fatalf("expected the trade in the preview, got %+v", preview)
+	}
+	expect("offset credit", preview.OffsetCredit, 3500)
+	expect("initial margin", preview.InitialMargin, 1500)
+	expect("standard margin", preview.StandardMargin, 5000)
+
+	var apiErr types.APIError
+	testCases := []struct {
+		trader, query string
+		status        int
+		code          types.ErrorCode
+	}{
+		{"", "", http.StatusBadRequest, types.ErrCodeMissingField},
+		{"bob", "", http.StatusNotFound, types.ErrCodeAccountNotFound},
+		{"alice", "?market_id=ETH-USDC&side=sell", http.StatusBadRequest, types.ErrCodeMissingField},
+		{"alice", "?market_id=ETH-USDC&side=long&size=1", http.StatusBadRequest, types.ErrCodeInvalidSide},
+		{"alice", "?market_id=ETH-USDC&side=buy&size=-1", http.StatusBadRequest, types.ErrCodeInvalidQuantity},
+		{"alice", "?market_id=NOPE-USDC&side=buy&size=1", http.StatusNotFound, types.ErrCodeMarketNotFound},
+	}
+	for _, tc := range testCases {
+		if code := get(tc.trader, tc.query, &apiErr); code != tc.status || apiErr.Code != tc.code {
+			t.Errorf("%s %q: expected %d %s, got %d %s", tc.trader, tc.query, tc.status, tc.code, code, apiErr.Code)
+		}
+	}
+}
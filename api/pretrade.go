@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/openalpha/perp-dex/api/pretrade"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+func newPreTrade(config *Config) *pretrade.Checker {
+	if config.PreTrade != nil {
+		return pretrade.NewChecker(*config.PreTrade)
+	}
+	return pretrade.NewChecker(pretrade.Config{})
+}
+
+// observeMarkPrice feeds a broadcast mark price to the pre-trade price collar
+func (s *Server) observeMarkPrice(marketID, markPrice string) {
+	if price, err := strconv.ParseFloat(markPrice, 64); err == nil && price > 0 {
+		s.preTrade.SetMarkPrice(marketID, price)
+	}
+}
+
+// handleAdminPreTradeLimits handles /v1/admin/pretrade-limits: GET lists every
+// set of limits, with the ones in force for ?trader=&market_id= when given;
+// PUT sets the limits of the body's scope; DELETE ?trader=&market_id=
+// removes a scope's limits
+func (s *Server) handleAdminPreTradeLimits(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	query := r.URL.Query()
+	trader, marketID := query.Get("trader"), query.Get("market_id")
+
+	switch r.Method {
+	case http.MethodGet:
+		resp := map[string]interface{}{"limits": s.preTrade.Limits()}
+		if trader != "" || marketID != "" {
+			resp["effective"] = s.preTrade.EffectiveLimits(trader, marketID)
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodPut:
+		var req pretrade.Limits
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		if req.MarketID != "" && s.getMockMarket(req.MarketID) == nil {
+			writeError(w, types.ErrCodeMarketNotFound, "Market not found")
+			return
+		}
+		limits, err := s.preTrade.SetLimits(req)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusOK, limits)
+
+	case http.MethodDelete:
+		if err := s.preTrade.DeleteLimits(trader, marketID); err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,308 @@
+// Package pretrade rejects fat-finger orders at the gateway, before they
+// reach the order service.
+//
+// Four checks are run against the limits in force for the order's trader and
+// market:
+//   - max order size: the quantity, in the base asset
+//   - max notional: quantity × price, in the quote asset; market orders are
+//     valued at the mark price
+//   - price collar: limit prices more than the collar through the mark price,
+//     i.e. buys above and sells below it, are rejected
+//   - duplicates: an order with the same trader, market, side, type, price and
+//     quantity as one accepted within the duplicate window is rejected
+//
+// Limits can be set for everyone, per market, per trader, and per trader and
+// market; the most specific set applies as a whole. Zero values disable a
+// check. Mark prices are fed in by the caller and ignored once stale, so the
+// collar never blocks trading on an old price. State is kept in memory.
+package pretrade
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openalpha/perp-dex/pkg/validation"
+)
+
+// Order sides and types
+const (
+	SideBuy   = "buy"
+	SideSell  = "sell"
+	TypeLimit = "limit"
+)
+
+const (
+	// DefaultMaxMarkAge is the oldest mark price the collar and market order
+	// notional are checked against
+	DefaultMaxMarkAge = 10 * time.Second
+
+	// sweepInterval is the least time between sweeps of expired duplicate
+	// window entries
+	sweepInterval = time.Second
+)
+
+// Pre-trade check errors. Rejections are returned as a
+// *validation.RuleError wrapping one of the first four.
+var (
+	ErrOrderSizeExceeded  = errors.New("order size above pre-trade limit")
+	ErrNotionalExceeded   = errors.New("order notional above pre-trade limit")
+	ErrPriceOutsideCollar = errors.New("price outside pre-trade collar")
+	ErrDuplicateOrder     = errors.New("duplicate order")
+	ErrInvalidLimits      = errors.New("invalid pre-trade limits")
+	ErrLimitsNotFound     = errors.New("pre-trade limits not found")
+)
+
+// Limits are the pre-trade limits of one scope. An empty Trader and MarketID
+// is the default scope that applies to every order without a more specific
+// set. Zero values disable the check.
+type Limits struct {
+	Trader            string  `json:"trader,omitempty"`
+	MarketID          string  `json:"market_id,omitempty"`
+	MaxOrderSize      float64 `json:"max_order_size,omitempty"`      // base asset
+	MaxNotional       float64 `json:"max_notional,omitempty"`        // quote asset
+	PriceCollarBps    float64 `json:"price_collar_bps,omitempty"`    // e.g. 500 rejects limit prices more than 5% through the mark
+	DuplicateWindowMs int64   `json:"duplicate_window_ms,omitempty"` // identical orders within this window are rejected
+	UpdatedAt         int64   `json:"updated_at,omitempty"`
+}
+
+// Validate checks that no limit is negative
+func (l Limits) Validate() error {
+	if l.MaxOrderSize < 0 || l.MaxNotional < 0 || l.PriceCollarBps < 0 || l.DuplicateWindowMs < 0 {
+		return fmt.Errorf("%w: limits must not be negative", ErrInvalidLimits)
+	}
+	if l.PriceCollarBps >= 10000 {
+		return fmt.Errorf("%w: price_collar_bps must be below 10000", ErrInvalidLimits)
+	}
+	return nil
+}
+
+// Config contains the initial limits. Zero values disable every check.
+type Config struct {
+	Default    Limits        // Limits of the default scope; Trader and MarketID are ignored
+	Overrides  []Limits      // Per trader and/or market limits
+	MaxMarkAge time.Duration // Oldest usable mark price; 0 uses DefaultMaxMarkAge
+}
+
+// Order is an order submission to check
+type Order struct {
+	Trader   string
+	MarketID string
+	Side     string  // SideBuy or SideSell
+	Type     string  // TypeLimit or market
+	Price    float64 // 0 for market orders
+	Quantity float64
+	Time     time.Time
+}
+
+type scope struct {
+	trader   string
+	marketID string
+}
+
+type mark struct {
+	price float64
+	at    time.Time
+}
+
+type orderKey struct {
+	trader   string
+	marketID string
+	side     string
+	typ      string
+	price    float64
+	quantity float64
+}
+
+// Checker runs the pre-trade checks. It is safe for concurrent use.
+type Checker struct {
+	mu         sync.Mutex
+	maxMarkAge time.Duration
+	limits     map[scope]Limits
+	marks      map[string]mark
+	recent     map[orderKey]time.Time // accepted order -> end of its duplicate window
+	lastSweep  time.Time
+	now        func() time.Time
+}
+
+// NewChecker creates a checker with the configured limits
+func NewChecker(cfg Config) *Checker {
+	c := &Checker{
+		maxMarkAge: cfg.MaxMarkAge,
+		limits:     make(map[scope]Limits),
+		marks:      make(map[string]mark),
+		recent:     make(map[orderKey]time.Time),
+		now:        time.Now,
+	}
+	if c.maxMarkAge <= 0 {
+		c.maxMarkAge = DefaultMaxMarkAge
+	}
+	def := cfg.Default
+	def.Trader, def.MarketID = "", ""
+	c.limits[scope{}] = def
+	for _, l := range cfg.Overrides {
+		c.limits[scope{l.Trader, l.MarketID}] = l
+	}
+	return c
+}
+
+// SetMarkPrice records a market's current mark price
+func (c *Checker) SetMarkPrice(marketID string, price float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.marks[marketID] = mark{price: price, at: c.now()}
+}
+
+// Limits returns every set of limits, the default scope first, then by
+// trader and market ID
+func (c *Checker) Limits() []Limits {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	all := make([]Limits, 0, len(c.limits))
+	for _, l := range c.limits {
+		all = append(all, l)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Trader != all[j].Trader {
+			return all[i].Trader < all[j].Trader
+		}
+		return all[i].MarketID < all[j].MarketID
+	})
+	return all
+}
+
+// EffectiveLimits returns the limits that apply to a trader's orders in a
+// market: those of the trader and market, else the trader, else the market,
+// else the default
+func (c *Checker) EffectiveLimits(trader, marketID string) Limits {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.effectiveLimits(trader, marketID)
+}
+
+func (c *Checker) effectiveLimits(trader, marketID string) Limits {
+	for _, s := range []scope{{trader, marketID}, {trader, ""}, {"", marketID}} {
+		if s.trader == "" && s.marketID == "" {
+			continue
+		}
+		if l, ok := c.limits[s]; ok {
+			return l
+		}
+	}
+	return c.limits[scope{}]
+}
+
+// SetLimits validates and saves the limits of their scope, replacing any
+// previous set
+func (c *Checker) SetLimits(l Limits) (Limits, error) {
+	if err := l.Validate(); err != nil {
+		return Limits{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l.UpdatedAt = c.now().UnixMilli()
+	c.limits[scope{l.Trader, l.MarketID}] = l
+	return l, nil
+}
+
+// DeleteLimits removes the limits of a trader and/or market scope, so the
+// next less specific set applies. The default scope cannot be deleted; set it
+// to zero limits instead.
+func (c *Checker) DeleteLimits(trader, marketID string) error {
+	if trader == "" && marketID == "" {
+		return fmt.Errorf("%w: the default limits cannot be deleted", ErrInvalidLimits)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := scope{trader, marketID}
+	if _, ok := c.limits[s]; !ok {
+		return ErrLimitsNotFound
+	}
+	delete(c.limits, s)
+	return nil
+}
+
+// Check runs the pre-trade checks on an order and, if it passes, opens its
+// duplicate window. It returns a *validation.RuleError for a rejection.
+func (c *Checker) Check(o Order) error {
+	if o.Time.IsZero() {
+		o.Time = c.now()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l := c.effectiveLimits(o.Trader, o.MarketID)
+	isLimit := o.Type == TypeLimit
+
+	if l.MaxOrderSize > 0 && o.Quantity > l.MaxOrderSize {
+		return ruleError(ErrOrderSizeExceeded, "quantity", o.Quantity, l.MaxOrderSize)
+	}
+
+	var markPrice float64
+	if m, ok := c.marks[o.MarketID]; ok && m.price > 0 && o.Time.Sub(m.at) <= c.maxMarkAge {
+		markPrice = m.price
+	}
+
+	if l.MaxNotional > 0 {
+		price := o.Price
+		if !isLimit {
+			price = markPrice
+		}
+		if notional := o.Quantity * price; notional > l.MaxNotional {
+			return ruleError(ErrNotionalExceeded, "notional", notional, l.MaxNotional)
+		}
+	}
+
+	if l.PriceCollarBps > 0 && isLimit && markPrice > 0 {
+		band := markPrice * l.PriceCollarBps / 10000
+		if o.Side == SideBuy && o.Price > markPrice+band {
+			return ruleError(ErrPriceOutsideCollar, "price", o.Price, markPrice+band)
+		}
+		if o.Side == SideSell && o.Price < markPrice-band {
+			return ruleError(ErrPriceOutsideCollar, "price", o.Price, markPrice-band)
+		}
+	}
+
+	if l.DuplicateWindowMs > 0 {
+		c.sweep(o.Time)
+		key := orderKey{o.Trader, o.MarketID, o.Side, o.Type, o.Price, o.Quantity}
+		if until, ok := c.recent[key]; ok && o.Time.Before(until) {
+			return &validation.RuleError{
+				Err:   ErrDuplicateOrder,
+				Field: "order",
+				Value: fmt.Sprintf("%s %s %s@%s", o.Side, formatFloat(o.Quantity), o.MarketID, formatFloat(o.Price)),
+				Limit: strconv.FormatInt(l.DuplicateWindowMs, 10) + "ms",
+			}
+		}
+		c.recent[key] = o.Time.Add(time.Duration(l.DuplicateWindowMs) * time.Millisecond)
+	}
+	return nil
+}
+
+// sweep drops duplicate window entries that have ended, at most once per
+// sweepInterval
+func (c *Checker) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < sweepInterval {
+		return
+	}
+	c.lastSweep = now
+	for key, until := range c.recent {
+		if !now.Before(until) {
+			delete(c.recent, key)
+		}
+	}
+}
+
+func ruleError(err error, field string, value, limit float64) *validation.RuleError {
+	return &validation.RuleError{Err: err, Field: field, Value: formatFloat(value), Limit: formatFloat(limit)}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,137 @@
+package pretrade
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openalpha/perp-dex/pkg/validation"
+)
+
+var t0 = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+func newTestChecker(cfg Config) (*Checker, *time.Time) {
+	c := NewChecker(cfg)
+	now := t0
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+// TestCheckLimits tests that size, notional and collar limits reject only
+// the orders past them, and that the collar skips stale marks
+func TestCheckLimits(t *testing.T) {
+	c, now := newTestChecker(Config{Default: Limits{MaxOrderSize: 20, MaxNotional: 500000, PriceCollarBps: 500}})
+	c.SetMarkPrice("BTC-USDC", 50000)
+
+	testCases := []struct {
+		name  string
+		order Order
+		want  error
+	}{
+		{"within limits", Order{Side: SideBuy, Type: TypeLimit, Price: 52000, Quantity: 1}, nil},
+		{"size", Order{Side: SideBuy, Type: TypeLimit, Price: 100, Quantity: 21}, ErrOrderSizeExceeded},
+		{"notional", Order{Side: SideSell, Type: TypeLimit, Price: 50000, Quantity: 10.5}, ErrNotionalExceeded},
+		{"market notional at mark", Order{Side: SideBuy, Type: "market", Quantity: 10.01}, ErrNotionalExceeded},
+		{"buy through collar", Order{Side: SideBuy, Type: TypeLimit, Price: 52501, Quantity: 1}, ErrPriceOutsideCollar},
+		{"sell through collar", Order{Side: SideSell, Type: TypeLimit, Price: 47499, Quantity: 1}, ErrPriceOutsideCollar},
+		{"passive buy below collar", Order{Side: SideBuy, Type: TypeLimit, Price: 40000, Quantity: 1}, nil},
+	}
+	for _, tc := range testCases {
+		tc.order.Trader, tc.order.MarketID = "alice", "BTC-USDC"
+		err := c.Check(tc.order)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	var ruleErr *validation.RuleError
+	if err := c.Check(Order{Trader: "alice", MarketID: "BTC-USDC", Side: SideBuy, Type: TypeLimit, Price: 60000, Quantity: 1}); !errors.As(err, &ruleErr) || ruleErr.Limit != "52500" {
+		t.Errorf("expected the collar limit in the rule error, got %v", err)
+	}
+
+	*now = t0.Add(DefaultMaxMarkAge + time.Second)
+	if err := c.Check(Order{Trader: "alice", MarketID: "BTC-USDC", Side: SideBuy, Type: TypeLimit, Price: 60000, Quantity: 1}); err != nil {
+		t.Errorf("expected a stale mark to skip the collar, got %v", err)
+	}
+}
+
+// TestLimitScopes tests that the most specific limits apply as a whole and
+// that deleting them falls back to the next scope
+func TestLimitScopes(t *testing.T) {
+	c, _ := newTestChecker(Config{Default: Limits{MaxOrderSize: 10}})
+	if _, err := c.SetLimits(Limits{MarketID: "ETH-USDC", MaxOrderSize: 100}); err != nil {
+		t.Fatalf("failed to set limits: %v", err)
+	}
+	if _, err := c.SetLimits(Limits{Trader: "alice", MaxNotional: 1000}); err != nil {
+		t.Fatalf("failed to set limits: %v", err)
+	}
+	if _, err := c.SetLimits(Limits{Trader: "alice", MarketID: "ETH-USDC", MaxOrderSize: 1}); err != nil {
+		t.Fatalf("failed to set limits: %v", err)
+	}
+
+	testCases := []struct {
+		trader, marketID string
+		want             Limits
+	}{
+		{"bob", "BTC-USDC", Limits{MaxOrderSize: 10}},
+		{"bob", "ETH-USDC", Limits{MarketID: "ETH-USDC", MaxOrderSize: 100}},
+		{"alice", "BTC-USDC", Limits{Trader: "alice", MaxNotional: 1000}},
+		{"alice", "ETH-USDC", Limits{Trader: "alice", MarketID: "ETH-USDC", MaxOrderSize: 1}},
+	}
+	for _, tc := range testCases {
+		got := c.EffectiveLimits(tc.trader, tc.marketID)
+		got.UpdatedAt = 0
+		if got != tc.want {
+			t.Errorf("%s %s: expected %+v, got %+v", tc.trader, tc.marketID, tc.want, got)
+		}
+	}
+	if all := c.Limits(); len(all) != 4 || all[0].Trader != "" || all[0].MarketID != "" {
+		t.Errorf("expected the default scope first of 4, got %+v", all)
+	}
+
+	if err := c.DeleteLimits("alice", "ETH-USDC"); err != nil {
+		t.Fatalf("failed to delete limits: %v", err)
+	}
+	if got := c.EffectiveLimits("alice", "ETH-USDC"); got.Trader != "alice" || got.MarketID != "" {
+		t.Errorf("expected alice's limits to apply, got %+v", got)
+	}
+	if err := c.DeleteLimits("alice", "ETH-USDC"); !errors.Is(err, ErrLimitsNotFound) {
+		t.Errorf("expected ErrLimitsNotFound, got %v", err)
+	}
+	if err := c.DeleteLimits("", ""); !errors.Is(err, ErrInvalidLimits) {
+		t.Errorf("expected the default scope to be kept, got %v", err)
+	}
+	if _, err := c.SetLimits(Limits{MaxOrderSize: -1}); !errors.Is(err, ErrInvalidLimits) {
+		t.Errorf("expected negative limits to be rejected, got %v", err)
+	}
+}
+
+// TestDuplicateOrders tests that an identical order is rejected only within
+// the window, and that orders differing in any field are not
+func TestDuplicateOrders(t *testing.T) {
+	c, now := newTestChecker(Config{Default: Limits{DuplicateWindowMs: 500}})
+	order := Order{Trader: "alice", MarketID: "BTC-USDC", Side: SideBuy, Type: TypeLimit, Price: 50000, Quantity: 1}
+
+	if err := c.Check(order); err != nil {
+		t.Fatalf("expected the first order to pass, got %v", err)
+	}
+	*now = t0.Add(499 * time.Millisecond)
+	if err := c.Check(order); !errors.Is(err, ErrDuplicateOrder) {
+		t.Errorf("expected a duplicate within the window, got %v", err)
+	}
+	other := order
+	other.Quantity = 2
+	if err := c.Check(other); err != nil {
+		t.Errorf("expected a different quantity to pass, got %v", err)
+	}
+	other = order
+	other.Trader = "bob"
+	if err := c.Check(other); err != nil {
+		t.Errorf("expected another trader to pass, got %v", err)
+	}
+
+	*now = t0.Add(2 * time.Second)
+	if err := c.Check(order); err != nil {
+		t.Errorf("expected the order to pass after the window, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/pretrade"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestPreTradeChecks tests that orders past the pre-trade limits are rejected
+// before the service with their reject reason, and that admin overrides
+// apply per trader and market
+func TestPreTradeChecks(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	config.AdminToken = "admin-secret"
+	config.PreTrade = &pretrade.Config{Default: pretrade.Limits{MaxOrderSize: 5, DuplicateWindowMs: 60000}}
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	do := func(method, target, body string, admin bool, v interface{}) int {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if admin {
+			req.Header.Set(adminTokenHeader, config.AdminToken)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("%s %s: invalid JSON %q: %v", method, target, rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+	order := func(trader, price, quantity string) string {
+		return `{"market_id":"BTC-USDC","side":"buy","type":"limit","price":"` + price + `","quantity":"` + quantity + `","trader":"` + trader + `"}`
+	}
+
+	var resp types.ErrorResponse
+	if code := do(http.MethodPost, "/v1/orders", order("pt-alice", "40000", "10"), false, &resp); code != http.StatusBadRequest || resp.Code != types.ErrCodePreTradeSize {
+		t.Fatalf("expected 400 pretrade_size_exceeded, got %d %+v", code, resp.APIError)
+	}
+	if resp.RejectReason != types.RejectReasonRiskLimit || resp.Details["limit"] != "5" {
+		t.Errorf("expected a risk_limit reject with the limit, got %+v", resp.APIError)
+	}
+
+	if code := do(http.MethodPost, "/v1/orders", order("pt-alice", "40000", "1"), false, nil); code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	if code := do(http.MethodPost, "/v1/orders", order("pt-alice", "40000", "1"), false, &resp); code != http.StatusConflict || resp.Code != types.ErrCodeDuplicateOrder {
+		t.Errorf("expected 409 duplicate_order, got %d %+v", code, resp.APIError)
+	}
+
+	// A trader override replaces the default limits as a whole
+	if code := do(http.MethodPut, "/v1/admin/pretrade-limits", `{"trader":"pt-whale","max_order_size":100}`, false, nil); code != http.StatusForbidden {
+		t.Errorf("expected 403 without the admin token, got %d", code)
+	}
+	if code := do(http.MethodPut, "/v1/admin/pretrade-limits", `{"trader":"pt-whale","max_order_size":100}`, true, nil); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if code := do(http.MethodPost, "/v1/orders", order("pt-whale", "40000", "10"), false, nil); code != http.StatusCreated {
+		t.Errorf("expected the override to allow the order, got %d", code)
+	}
+	var list struct {
+		Limits    []pretrade.Limits `json:"limits"`
+		Effective *pretrade.Limits  `json:"effective"`
+	}
+	if code := do(http.MethodGet, "/v1/admin/pretrade-limits?trader=pt-whale&market_id=BTC-USDC", "", true, &list); code != http.StatusOK || len(list.Limits) != 2 || list.Effective == nil || list.Effective.MaxOrderSize != 100 {
+		t.Errorf("expected 2 scopes with the override in force, got %d %+v", code, list)
+	}
+	if code := do(http.MethodDelete, "/v1/admin/pretrade-limits?trader=pt-whale", "", true, nil); code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", code)
+	}
+	if code := do(http.MethodDelete, "/v1/admin/pretrade-limits?trader=pt-whale", "", true, &resp); code != http.StatusNotFound || resp.Code != types.ErrCodePreTradeLimitsNotFound {
+		t.Errorf("expected 404 pretrade_limits_not_found, got %d %+v", code, resp.APIError)
+	}
+	if code := do(http.MethodPut, "/v1/admin/pretrade-limits", `{"market_id":"NOPE-USDC","max_order_size":1}`, true, nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown market, got %d", code)
+	}
+
+	// The collar checks limit prices against the broadcast mark
+	if code := do(http.MethodPut, "/v1/admin/pretrade-limits", `{"market_id":"BTC-USDC","price_collar_bps":500}`, true, nil); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	s.observeMarkPrice("BTC-USDC", "50000")
+	if code := do(http.MethodPost, "/v1/orders", order("pt-bob", "60000", "1"), false, &resp); code != http.StatusBadRequest || resp.Code != types.ErrCodePriceCollar || resp.RejectReason != types.RejectReasonPriceBand {
+		t.Errorf("expected 400 price_collar_exceeded, got %d %+v", code, resp.APIError)
+	}
+}
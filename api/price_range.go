@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	obkeeper "github.com/openalpha/perp-dex/x/orderbook/keeper"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// handleAdminMarketOrders handles GET /v1/admin/markets/{id}/orders: the
+// resting orders of one side within a price range, best price first, with
+// their traders, for liquidation and surveillance tooling
+func (s *Server) handleAdminMarketOrders(w http.ResponseWriter, r *http.Request, marketID string) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	svc, ok := s.orderService.(types.PriceRangeOrderService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Price range queries require a keeper-backed service")
+		return
+	}
+
+	query := r.URL.Query()
+	req := &types.PriceRangeOrdersRequest{
+		MarketID: marketID,
+		Side:     query.Get("side"),
+		MinPrice: query.Get("min_price"),
+		MaxPrice: query.Get("max_price"),
+	}
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 || n > obkeeper.MaxPriceRangeLimit {
+			writeError(w, types.ErrCodeInvalidRequest, "limit must be between 1 and "+strconv.Itoa(obkeeper.MaxPriceRangeLimit))
+			return
+		}
+		req.Limit = n
+	}
+
+	resp, err := svc.GetOrdersByPriceRange(r.Context(), req)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetOrdersByPriceRange lists resting orders within a price range from the
+// keeper's price index
+func (rs *RealService) GetOrdersByPriceRange(ctx context.Context, req *types.PriceRangeOrdersRequest) (*types.PriceRangeOrdersResponse, error) {
+	q := obkeeper.PriceRangeQuery{MarketID: req.MarketID, Limit: req.Limit}
+	switch req.Side {
+	case "buy":
+		q.Side = obtypes.SideBuy
+	case "sell":
+		q.Side = obtypes.SideSell
+	default:
+		return nil, obtypes.ErrInvalidSide.Wrapf("side must be buy or sell, got %q", req.Side)
+	}
+	for _, bound := range []struct {
+		value string
+		dec   *math.LegacyDec
+	}{{req.MinPrice, &q.Min}, {req.MaxPrice, &q.Max}} {
+		if bound.value == "" {
+			continue
+		}
+		dec, err := math.LegacyNewDecFromStr(bound.value)
+		if err != nil {
+			return nil, obtypes.ErrInvalidPrice.Wrapf("invalid price bound %q", bound.value)
+		}
+		*bound.dec = dec
+	}
+
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	orders, err := rs.obKeeper.GetOrdersByPriceRange(rs.sdkCtx, q)
+	if err != nil {
+		return nil, err
+	}
+	resp := &types.PriceRangeOrdersResponse{
+		MarketID: req.MarketID,
+		Side:     req.Side,
+		Orders:   make([]*types.Order, 0, len(orders)),
+	}
+	for _, order := range orders {
+		resp.Orders = append(resp.Orders, rs.convertOrder(order))
+	}
+	resp.Total = len(resp.Orders)
+	return resp, nil
+}
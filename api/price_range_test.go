@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestAdminMarketOrders tests that operators can list a side's resting
+// orders within a price range, best price first
+func TestAdminMarketOrders(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	config.AdminToken = "admin-secret"
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	for _, price := range []string{"48000", "49000", "47000"} {
+		body := `{"market_id":"BTC-USDC","side":"buy","type":"limit","price":"` + price + `","quantity":"0.1","trader":"range-bidder"}`
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("failed to place order: %d %s", rec.Code, rec.Body)
+		}
+	}
+
+	admin := func(target string, v interface{}) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set(adminTokenHeader, config.AdminToken)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("GET %s: invalid JSON %q: %v", target, rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+
+	var resp types.PriceRangeOrdersResponse
+	if code := admin("/v1/admin/markets/BTC-USDC/orders?side=buy&min_price=47500&max_price=49000", &resp); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if resp.Total != 2 || resp.Orders[0].Price != "49000.000000000000000000" || resp.Orders[1].Price != "48000.000000000000000000" {
+		t.Fatalf("expected the 49000 and 48000 bids, got %+v", resp.Orders)
+	}
+	if resp.Orders[0].Trader != "range-bidder" {
+		t.Errorf("expected the trader to be listed, got %q", resp.Orders[0].Trader)
+	}
+	if code := admin("/v1/admin/markets/BTC-USDC/orders?side=buy&limit=1", &resp); code != http.StatusOK || resp.Total != 1 {
+		t.Errorf("expected one order, got %d %+v", code, resp)
+	}
+
+	if code := admin("/v1/admin/markets/BTC-USDC/orders?side=both", nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown side, got %d", code)
+	}
+	if code := admin("/v1/admin/markets/BTC-USDC/orders?side=buy&min_price=50000&max_price=49000", nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an empty range, got %d", code)
+	}
+	if code := admin("/v1/admin/markets/NOPE-USDC/orders?side=buy", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown market, got %d", code)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/markets/BTC-USDC/orders?side=buy", nil))
+	if rec.Code != types.ErrCodeUnauthorized.HTTPStatus() {
+		t.Errorf("expected a request without the admin token to be refused, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// Readiness check defaults
+const (
+	// DefaultOracleMaxAge is the oldest oracle price /ready accepts
+	DefaultOracleMaxAge = 30 * time.Second
+
+	// eventBusMaxLag is how long the event publisher may go without a
+	// successful poll before /ready fails
+	eventBusMaxLag = 5 * time.Second
+
+	// readyCheckTimeout bounds each dependency check, so a wedged store lock
+	// or an unreachable matcher fails the probe instead of hanging it
+	readyCheckTimeout = 2 * time.Second
+)
+
+// readyCheck is the result of one dependency check reported by /ready
+type readyCheck struct {
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// readinessChecks returns the dependency checks that apply to this server,
+// by name. Each returns a detail on success.
+func (s *Server) readinessChecks() map[string]func(ctx context.Context) (string, error) {
+	checks := make(map[string]func(ctx context.Context) (string, error))
+
+	if store, ok := s.orderService.(types.StoreHealthService); ok {
+		checks["store"] = func(ctx context.Context) (string, error) {
+			return "", store.CheckStore(ctx)
+		}
+	}
+
+	if maxAge := s.oracleMaxAge(); maxAge > 0 && s.oracle != nil {
+		checks["oracle"] = func(ctx context.Context) (string, error) {
+			updated := s.oracle.LastUpdate()
+			if updated.IsZero() {
+				return "", errors.New("no oracle price fetched yet")
+			}
+			age := time.Since(updated).Truncate(time.Millisecond)
+			if age > maxAge {
+				return "", fmt.Errorf("oracle price is %s old, max %s", age, maxAge)
+			}
+			return fmt.Sprintf("updated %s ago", age), nil
+		}
+	}
+
+	if _, ok := s.orderService.(types.EventService); ok {
+		checks["event_bus"] = func(ctx context.Context) (string, error) {
+			s.probeMu.Lock()
+			polled, err := s.eventBusPolledAt, s.eventBusErr
+			s.probeMu.Unlock()
+			if polled.IsZero() {
+				if err != nil {
+					return "", err
+				}
+				return "", errors.New("event publisher not started")
+			}
+			lag := time.Since(polled).Truncate(time.Millisecond)
+			if lag > eventBusMaxLag {
+				if err != nil {
+					return "", fmt.Errorf("no successful poll for %s: %w", lag, err)
+				}
+				return "", fmt.Errorf("no successful poll for %s", lag)
+			}
+			return fmt.Sprintf("polled %s ago", lag), nil
+		}
+	}
+
+	if s.matcherClient != nil {
+		checks["matcher"] = func(ctx context.Context) (string, error) {
+			start := time.Now()
+			if err := s.matcherClient.Ping(ctx); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s at %s", time.Since(start).Truncate(time.Microsecond), s.config.MatcherAddr), nil
+		}
+	}
+
+	return checks
+}
+
+// oracleMaxAge returns Config.ReadyOracleMaxAge, 0 if the oracle check is
+// disabled
+func (s *Server) oracleMaxAge() time.Duration {
+	switch {
+	case s.config == nil || s.config.ReadyOracleMaxAge == 0:
+		return DefaultOracleMaxAge
+	case s.config.ReadyOracleMaxAge < 0:
+		return 0
+	default:
+		return s.config.ReadyOracleMaxAge
+	}
+}
+
+// runReadinessChecks runs every dependency check concurrently, each bounded
+// by readyCheckTimeout, and reports whether all passed
+func (s *Server) runReadinessChecks(ctx context.Context) (map[string]readyCheck, bool) {
+	checks := s.readinessChecks()
+	type result struct {
+		name  string
+		check readyCheck
+	}
+	results := make(chan result, len(checks))
+	for name, check := range checks {
+		go func(name string, check func(ctx context.Context) (string, error)) {
+			ctx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+			defer cancel()
+
+			done := make(chan readyCheck, 1)
+			go func() {
+				detail, err := check(ctx)
+				if err != nil {
+					done <- readyCheck{Error: err.Error()}
+					return
+				}
+				done <- readyCheck{OK: true, Detail: detail}
+			}()
+			select {
+			case c := <-done:
+				results <- result{name, c}
+			case <-ctx.Done():
+				results <- result{name, readyCheck{Error: "check timed out"}}
+			}
+		}(name, check)
+	}
+
+	out := make(map[string]readyCheck, len(checks))
+	ok := true
+	for range checks {
+		r := <-results
+		out[r.name] = r.check
+		ok = ok && r.check.OK
+	}
+	return out, ok
+}
+
+// recordEventBusPoll records the outcome of an event publisher poll for /ready
+func (s *Server) recordEventBusPoll(err error) {
+	s.probeMu.Lock()
+	defer s.probeMu.Unlock()
+	if err == nil {
+		s.eventBusPolledAt = time.Now()
+	}
+	s.eventBusErr = err
+}
+
+// handleReady handles GET /ready, the readiness probe: 200 once the server is
+// started, any warm replay has completed and every dependency check passes;
+// 503 while starting or replaying, after a failed replay, while draining and
+// while any check fails. A load balancer or Kubernetes readiness probe should
+// route traffic on it.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	s.warmMu.Lock()
+	state, replayed := s.warmState, s.replayedOrders
+	s.warmMu.Unlock()
+	if state == "" {
+		state = "starting"
+	}
+
+	checks, healthy := s.runReadinessChecks(r.Context())
+	if state == warmStateReady && !healthy {
+		state = "not_ready"
+	}
+	if s.IsDraining() {
+		state = "draining"
+	}
+
+	status := http.StatusServiceUnavailable
+	if state == warmStateReady {
+		status = http.StatusOK
+	}
+	resp := map[string]interface{}{
+		"status":    state,
+		"checks":    checks,
+		"timestamp": time.Now().Unix(),
+	}
+	if failed := failedChecks(checks); len(failed) > 0 {
+		resp["failed"] = failed
+	}
+	if s.config.MatchJournal != "" {
+		resp["replayed_orders"] = replayed
+	}
+	writeJSON(w, status, resp)
+}
+
+func failedChecks(checks map[string]readyCheck) []string {
+	var failed []string
+	for name, check := range checks {
+		if !check.OK {
+			failed = append(failed, name)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// handleLive handles GET /live, the liveness probe: 200 for as long as the
+// process serves HTTP. It checks no dependencies, so an outage of the oracle
+// or the matcher takes the node out of rotation through /ready without
+// getting it restarted.
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	resp := map[string]interface{}{
+		"status":    "alive",
+		"timestamp": time.Now().Unix(),
+	}
+	if !s.startedAt.IsZero() {
+		resp["uptime_seconds"] = int64(time.Since(s.startedAt).Seconds())
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ============ Dependency checks ============
+
+// LastUpdate returns when the newest cached price was fetched, or the mock
+// simulation last ticked, zero if neither has happened
+func (o *HyperliquidOracle) LastUpdate() time.Time {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	var latest time.Time
+	if o.sim != nil {
+		latest = o.sim.LastUpdate()
+	}
+	for _, cached := range o.cache {
+		if cached.Timestamp.After(latest) {
+			latest = cached.Timestamp
+		}
+	}
+	return latest
+}
+
+// CheckStore reads the engine's store, failing if the read panics
+func (rs *RealService) CheckStore(ctx context.Context) (err error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("store read failed: %v", r)
+		}
+	}()
+	rs.obKeeper.GetLastEventSeq(rs.sdkCtx)
+	return nil
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+)
+
+// TestReadinessProbe tests that /ready fails while the oracle is stale or the
+// event publisher has not polled, names the failed checks, passes once every
+// dependency is healthy, and that /live stays 200 throughout
+func TestReadinessProbe(t *testing.T) {
+	rs, err := NewRealService(log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	oracle := NewHyperliquidOracle()
+	s := &Server{config: &Config{}, orderService: rs, oracle: oracle}
+	s.setWarmState(warmStateReady, 0)
+
+	ready := func() (int, map[string]interface{}) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.handleReady(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		var body map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to decode /ready: %v", err)
+		}
+		return rec.Code, body
+	}
+	failed := func(body map[string]interface{}) []interface{} {
+		list, _ := body["failed"].([]interface{})
+		return list
+	}
+
+	// No oracle price and no event publisher poll yet
+	code, body := ready()
+	if code != http.StatusServiceUnavailable || body["status"] != "not_ready" || len(failed(body)) != 2 {
+		t.Fatalf("expected 503 with the oracle and event bus failing, got %d %v", code, body)
+	}
+	checks := body["checks"].(map[string]interface{})
+	if store, _ := checks["store"].(map[string]interface{}); store["ok"] != true {
+		t.Errorf("expected the store check to pass, got %v", checks["store"])
+	}
+	if _, ok := checks["matcher"]; ok {
+		t.Error("expected no matcher check without a matcher client")
+	}
+
+	// A stale oracle price still fails
+	s.recordEventBusPoll(nil)
+	oracle.mu.Lock()
+	oracle.cache["BTC-USDC"] = &PriceCache{Price: math.LegacyNewDec(50000), Timestamp: time.Now().Add(-time.Minute)}
+	oracle.mu.Unlock()
+	code, body = ready()
+	if list := failed(body); code != http.StatusServiceUnavailable || len(list) != 1 || list[0] != "oracle" {
+		t.Fatalf("expected 503 with only the oracle failing, got %d %v", code, body)
+	}
+
+	// Disabling the oracle check, or a fresh price, makes the node ready
+	s.config.ReadyOracleMaxAge = -1
+	if code, body = ready(); code != http.StatusOK {
+		t.Errorf("expected 200 with the oracle check disabled, got %d %v", code, body)
+	}
+	s.config.ReadyOracleMaxAge = 0
+	oracle.mu.Lock()
+	oracle.cache["ETH-USDC"] = &PriceCache{Price: math.LegacyNewDec(3000), Timestamp: time.Now()}
+	oracle.mu.Unlock()
+	if code, body = ready(); code != http.StatusOK || body["status"] != warmStateReady {
+		t.Errorf("expected 200 ready, got %d %v", code, body)
+	}
+
+	// A lagging event publisher fails again; /live is unaffected
+	s.probeMu.Lock()
+	s.eventBusPolledAt = time.Now().Add(-time.Minute)
+	s.probeMu.Unlock()
+	if code, body = ready(); code != http.StatusServiceUnavailable || len(failed(body)) != 1 || failed(body)[0] != "event_bus" {
+		t.Errorf("expected 503 with the event bus failing, got %d %v", code, body)
+	}
+	rec := httptest.NewRecorder()
+	s.handleLive(rec, httptest.NewRequest(http.MethodGet, "/live", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected /live 200, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/openalpha/perp-dex/api/profiles"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+func newProfiles(config *Config) *profiles.Registry {
+	registry, err := profiles.NewRegistry(config.ProfileStore)
+	if err != nil {
+		log.Printf("Failed to load trader profiles %s: %v", config.ProfileStore, err)
+	}
+	return registry
+}
+
+// traderProfile returns the profile shown next to a trader's address, or nil
+// when the trader has no visible profile
+func traderProfile(registry *profiles.Registry, address string) *types.TraderProfile {
+	if registry == nil || address == "" {
+		return nil
+	}
+	p, ok := registry.Visible(address)
+	if !ok {
+		return nil
+	}
+	return &types.TraderProfile{Nickname: p.Nickname, AvatarHash: p.AvatarHash}
+}
+
+// handleProfile handles GET /v1/profiles/{address}, a trader's public
+// profile. Traders without one, or whose profile moderators hid, are not
+// found.
+func (s *Server) handleProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	address := strings.TrimPrefix(r.URL.Path, "/v1/profiles/")
+	if address == "" || strings.Contains(address, "/") {
+		writeError(w, types.ErrCodeInvalidPath, "Invalid profile path")
+		return
+	}
+	p, ok := s.profiles.Visible(address)
+	if !ok {
+		writeError(w, types.ErrCodeProfileNotFound, "Profile not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, p.Public())
+}
+
+// handleAccountProfile handles /v1/account/profile: GET the trader's own
+// profile with its moderation state, PUT a profiles.Update to create or
+// replace it, DELETE it
+func (s *Server) handleAccountProfile(w http.ResponseWriter, r *http.Request) {
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		p, err := s.profiles.Get(trader)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, p)
+
+	case http.MethodPut:
+		var req profiles.Update
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		p, err := s.profiles.Set(trader, req)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, p)
+
+	case http.MethodDelete:
+		if err := s.profiles.Delete(trader); err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleAdminProfiles handles GET /v1/admin/profiles?hidden=true|false,
+// every trader profile with its moderation state
+func (s *Server) handleAdminProfiles(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	hidden := r.URL.Query().Get("hidden")
+	list := make([]profiles.Profile, 0)
+	for _, p := range s.profiles.List() {
+		if hidden == "" || (hidden == "true") == p.Hidden {
+			list = append(list, p)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"profiles": list})
+}
+
+// handleAdminProfile handles /v1/admin/profiles/{address}: GET a profile
+// with its moderation state, PUT a profiles.Moderation to hide, lock or
+// clear it. Changes require the X-Admin-Actor header, which is kept with
+// the profile.
+func (s *Server) handleAdminProfile(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	address := strings.TrimPrefix(r.URL.Path, "/v1/admin/profiles/")
+	if address == "" || strings.Contains(address, "/") {
+		writeError(w, types.ErrCodeInvalidPath, "Invalid profile path")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		p, err := s.profiles.Get(address)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, p)
+
+	case http.MethodPut:
+		actor := r.Header.Get(adminActorHeader)
+		if actor == "" {
+			writeError(w, types.ErrCodeMissingField, adminActorHeader+" header is required")
+			return
+		}
+		var req profiles.Moderation
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		p, err := s.profiles.Moderate(address, actor, req)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, p)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// profiledRiverpoolService attaches trader profiles to the community pool
+// owners and holders a riverpool service returns. Results are copied, so
+// the service's own records are never changed.
+type profiledRiverpoolService struct {
+	types.RiverpoolService
+	profiles *profiles.Registry
+}
+
+// withProfiles wraps a riverpool service so its pool and holder lists carry
+// trader profiles
+func withProfiles(svc types.RiverpoolService, registry *profiles.Registry) types.RiverpoolService {
+	if svc == nil || registry == nil {
+		return svc
+	}
+	return &profiledRiverpoolService{RiverpoolService: svc, profiles: registry}
+}
+
+func (p *profiledRiverpoolService) pool(pool *types.PoolInfo) *types.PoolInfo {
+	if pool == nil || pool.Owner == "" {
+		return pool
+	}
+	cp := *pool
+	cp.OwnerProfile = traderProfile(p.profiles, pool.Owner)
+	return &cp
+}
+
+func (p *profiledRiverpoolService) pools(pools []*types.PoolInfo, err error) ([]*types.PoolInfo, error) {
+	if err != nil {
+		return pools, err
+	}
+	out := make([]*types.PoolInfo, len(pools))
+	for i, pool := range pools {
+		out[i] = p.pool(pool)
+	}
+	return out, nil
+}
+
+func (p *profiledRiverpoolService) GetPools() ([]*types.PoolInfo, error) {
+	return p.pools(p.RiverpoolService.GetPools())
+}
+
+func (p *profiledRiverpoolService) GetPoolsByType(poolType string) ([]*types.PoolInfo, error) {
+	return p.pools(p.RiverpoolService.GetPoolsByType(poolType))
+}
+
+func (p *profiledRiverpoolService) GetUserOwnedPools(user string) ([]*types.PoolInfo, error) {
+	return p.pools(p.RiverpoolService.GetUserOwnedPools(user))
+}
+
+func (p *profiledRiverpoolService) GetPool(poolID string) (*types.PoolInfo, error) {
+	pool, err := p.RiverpoolService.GetPool(poolID)
+	if err != nil {
+		return pool, err
+	}
+	return p.pool(pool), nil
+}
+
+func (p *profiledRiverpoolService) CreateCommunityPool(owner string, params *types.CommunityPoolParams) (*types.PoolInfo, error) {
+	pool, err := p.RiverpoolService.CreateCommunityPool(owner, params)
+	if err != nil {
+		return pool, err
+	}
+	return p.pool(pool), nil
+}
+
+func (p *profiledRiverpoolService) UpdateCommunityPool(poolID, owner string, params *types.CommunityPoolParams) (*types.PoolInfo, error) {
+	pool, err := p.RiverpoolService.UpdateCommunityPool(poolID, owner, params)
+	if err != nil {
+		return pool, err
+	}
+	return p.pool(pool), nil
+}
+
+func (p *profiledRiverpoolService) GetPoolHolders(poolID string) ([]*types.HolderInfo, error) {
+	holders, err := p.RiverpoolService.GetPoolHolders(poolID)
+	if err != nil {
+		return holders, err
+	}
+	out := make([]*types.HolderInfo, len(holders))
+	for i, holder := range holders {
+		cp := *holder
+		cp.Profile = traderProfile(p.profiles, holder.User)
+		out[i] = &cp
+	}
+	return out, nil
+}
@@ -0,0 +1,309 @@
+// Package profiles keeps the optional public profiles traders attach to
+// their addresses: a nickname, an avatar image hash and a short bio.
+//
+// Nicknames are unique regardless of case, so one trader cannot pose as
+// another by changing letter case. Moderators can hide a profile, which
+// keeps it from being shown in place of the trader's address, and lock it,
+// which stops the trader from editing or deleting it; they can also clear an
+// offending nickname or bio. A hidden profile still holds its nickname.
+//
+// Profiles are kept in memory and, when a path is configured, saved to a
+// JSON file after every change and loaded from it on start.
+package profiles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Profile field limits
+const (
+	MinNicknameLength = 3
+	MaxNicknameLength = 20
+	MaxBioLength      = 160 // characters
+)
+
+// Profile errors
+var (
+	ErrInvalidProfile  = errors.New("invalid profile")
+	ErrNicknameTaken   = errors.New("nickname taken")
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrProfileLocked   = errors.New("profile locked by a moderator")
+)
+
+var (
+	nicknamePattern   = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	avatarHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`) // sha256, lower case hex
+)
+
+// Profile is a trader's profile and its moderation state
+type Profile struct {
+	Address    string `json:"address"`
+	Nickname   string `json:"nickname,omitempty"`
+	AvatarHash string `json:"avatar_hash,omitempty"`
+	Bio        string `json:"bio,omitempty"`
+
+	Hidden      bool   `json:"hidden,omitempty"` // not shown in place of the address
+	Locked      bool   `json:"locked,omitempty"` // the trader cannot edit or delete it
+	ModeratedBy string `json:"moderated_by,omitempty"`
+	ModeratedAt int64  `json:"moderated_at,omitempty"`
+	Note        string `json:"moderation_note,omitempty"`
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// Visible reports whether the profile is shown in place of its address
+func (p *Profile) Visible() bool {
+	return !p.Hidden && p.Nickname != ""
+}
+
+// Public returns the profile without its moderation state
+func (p Profile) Public() Profile {
+	p.Hidden, p.Locked = false, false
+	p.ModeratedBy, p.ModeratedAt, p.Note = "", 0, ""
+	return p
+}
+
+// Update is a trader's change to their own profile; it replaces every field
+type Update struct {
+	Nickname   string `json:"nickname"`
+	AvatarHash string `json:"avatar_hash,omitempty"`
+	Bio        string `json:"bio,omitempty"`
+}
+
+// Validate checks the fields against the profile limits
+func (u Update) Validate() error {
+	if n := len(u.Nickname); n < MinNicknameLength || n > MaxNicknameLength || !nicknamePattern.MatchString(u.Nickname) {
+		return fmt.Errorf("%w: nickname must be %d to %d letters, digits, '_' or '-'", ErrInvalidProfile, MinNicknameLength, MaxNicknameLength)
+	}
+	if u.AvatarHash != "" && !avatarHashPattern.MatchString(u.AvatarHash) {
+		return fmt.Errorf("%w: avatar_hash must be a lower case hex sha256", ErrInvalidProfile)
+	}
+	if utf8.RuneCountInString(u.Bio) > MaxBioLength || !utf8.ValidString(u.Bio) {
+		return fmt.Errorf("%w: bio must be valid text of at most %d characters", ErrInvalidProfile, MaxBioLength)
+	}
+	return nil
+}
+
+// Moderation is a moderator's change to a profile. Nil flags are left as
+// they are.
+type Moderation struct {
+	Hidden        *bool  `json:"hidden,omitempty"`
+	Locked        *bool  `json:"locked,omitempty"`
+	ClearNickname bool   `json:"clear_nickname,omitempty"` // frees the nickname; the profile is no longer shown
+	ClearBio      bool   `json:"clear_bio,omitempty"`
+	Note          string `json:"note,omitempty"`
+}
+
+// Registry holds the profiles. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	path      string
+	profiles  map[string]*Profile
+	nicknames map[string]string // lower case nickname -> address
+	now       func() time.Time
+}
+
+// NewRegistry creates a registry saved to path, loading the profiles
+// already there; an empty path keeps them in memory only
+func NewRegistry(path string) (*Registry, error) {
+	r := &Registry{
+		path:      path,
+		profiles:  make(map[string]*Profile),
+		nicknames: make(map[string]string),
+		now:       time.Now,
+	}
+	if path == "" {
+		return r, nil
+	}
+	bz, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+	var list []*Profile
+	if err := json.Unmarshal(bz, &list); err != nil {
+		return r, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, p := range list {
+		r.profiles[p.Address] = p
+		if p.Nickname != "" {
+			r.nicknames[strings.ToLower(p.Nickname)] = p.Address
+		}
+	}
+	return r, nil
+}
+
+// Get returns the profile of an address, hidden or not
+func (r *Registry) Get(address string) (Profile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.profiles[address]
+	if !ok {
+		return Profile{}, ErrProfileNotFound
+	}
+	return *p, nil
+}
+
+// Visible returns the profile shown in place of an address, if it has one
+func (r *Registry) Visible(address string) (Profile, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.profiles[address]
+	if !ok || !p.Visible() {
+		return Profile{}, false
+	}
+	return *p, true
+}
+
+// List returns every profile, ordered by address
+func (r *Registry) List() []Profile {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Profile, 0, len(r.profiles))
+	for _, p := range r.profiles {
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Address < list[j].Address })
+	return list
+}
+
+// Set creates or replaces the profile of an address. The nickname must not
+// be held by another address, and a locked profile cannot be changed.
+func (r *Registry) Set(address string, u Update) (Profile, error) {
+	if address == "" {
+		return Profile{}, fmt.Errorf("%w: address is required", ErrInvalidProfile)
+	}
+	if err := u.Validate(); err != nil {
+		return Profile{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now().UnixMilli()
+	p := &Profile{Address: address, CreatedAt: now}
+	if old, ok := r.profiles[address]; ok {
+		if old.Locked {
+			return Profile{}, ErrProfileLocked
+		}
+		cp := *old
+		p = &cp
+	}
+	if holder, ok := r.nicknames[strings.ToLower(u.Nickname)]; ok && holder != address {
+		return Profile{}, ErrNicknameTaken
+	}
+	p.Nickname, p.AvatarHash, p.Bio = u.Nickname, u.AvatarHash, u.Bio
+	p.UpdatedAt = now
+	if err := r.put(address, p); err != nil {
+		return Profile{}, err
+	}
+	return *p, nil
+}
+
+// Delete removes the profile of an address, freeing its nickname. A locked
+// profile cannot be deleted.
+func (r *Registry) Delete(address string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.profiles[address]
+	if !ok {
+		return ErrProfileNotFound
+	}
+	if p.Locked {
+		return ErrProfileLocked
+	}
+	return r.put(address, nil)
+}
+
+// Moderate applies a moderator's change to the profile of an address
+func (r *Registry) Moderate(address, actor string, m Moderation) (Profile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.profiles[address]
+	if !ok {
+		return Profile{}, ErrProfileNotFound
+	}
+	p := *old
+	if m.Hidden != nil {
+		p.Hidden = *m.Hidden
+	}
+	if m.Locked != nil {
+		p.Locked = *m.Locked
+	}
+	if m.ClearNickname {
+		p.Nickname = ""
+	}
+	if m.ClearBio {
+		p.Bio = ""
+	}
+	now := r.now().UnixMilli()
+	p.ModeratedBy, p.ModeratedAt, p.Note = actor, now, m.Note
+	p.UpdatedAt = now
+	if err := r.put(address, &p); err != nil {
+		return Profile{}, err
+	}
+	return p, nil
+}
+
+// put replaces the profile of an address, or removes it when p is nil, and
+// saves the registry. If the save fails the change is undone.
+func (r *Registry) put(address string, p *Profile) error {
+	old := r.profiles[address]
+	r.apply(address, old, p)
+	if err := r.save(); err != nil {
+		r.apply(address, p, old)
+		return err
+	}
+	return nil
+}
+
+func (r *Registry) apply(address string, from, to *Profile) {
+	if from != nil && from.Nickname != "" {
+		delete(r.nicknames, strings.ToLower(from.Nickname))
+	}
+	if to == nil {
+		delete(r.profiles, address)
+		return
+	}
+	r.profiles[address] = to
+	if to.Nickname != "" {
+		r.nicknames[strings.ToLower(to.Nickname)] = address
+	}
+}
+
+// save writes the profiles to a temporary file and renames it over the
+// registry file, so a crash while saving leaves the previous file intact
+func (r *Registry) save() error {
+	if r.path == "" {
+		return nil
+	}
+	list := make([]*Profile, 0, len(r.profiles))
+	for _, p := range r.profiles {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Address < list[j].Address })
+	bz, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, bz, 0o644); err != nil {
+		return fmt.Errorf("failed to save profiles: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("failed to save profiles: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package profiles
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSetProfile tests profile validation and that nicknames are unique
+// regardless of case
+func TestSetProfile(t *testing.T) {
+	r, _ := NewRegistry("")
+
+	invalid := []Update{
+		{Nickname: "ab"},
+		{Nickname: strings.Repeat("a", MaxNicknameLength+1)},
+		{Nickname: "has space"},
+		{Nickname: "alice", AvatarHash: "not-a-hash"},
+		{Nickname: "alice", Bio: strings.Repeat("x", MaxBioLength+1)},
+	}
+	for _, u := range invalid {
+		if _, err := r.Set("alice-addr", u); !errors.Is(err, ErrInvalidProfile) {
+			t.Errorf("%+v: expected ErrInvalidProfile, got %v", u, err)
+		}
+	}
+
+	hash := strings.Repeat("ab", 32)
+	p, err := r.Set("alice-addr", Update{Nickname: "Alice", AvatarHash: hash, Bio: "market maker"})
+	if err != nil {
+		t.Fatalf("failed to set profile: %v", err)
+	}
+	if p.Nickname != "Alice" || p.AvatarHash != hash || p.CreatedAt == 0 {
+		t.Errorf("unexpected profile %+v", p)
+	}
+	if _, err := r.Set("bob-addr", Update{Nickname: "ALICE"}); !errors.Is(err, ErrNicknameTaken) {
+		t.Errorf("expected the nickname to be taken regardless of case, got %v", err)
+	}
+	if _, err := r.Set("alice-addr", Update{Nickname: "alice"}); err != nil {
+		t.Errorf("expected a trader to recase their own nickname, got %v", err)
+	}
+
+	// Renaming frees the old nickname
+	if _, err := r.Set("alice-addr", Update{Nickname: "alice2"}); err != nil {
+		t.Fatalf("failed to rename: %v", err)
+	}
+	if _, err := r.Set("bob-addr", Update{Nickname: "Alice"}); err != nil {
+		t.Errorf("expected the old nickname to be free, got %v", err)
+	}
+	if err := r.Delete("bob-addr"); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if _, err := r.Get("bob-addr"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("expected the deleted profile to be gone, got %v", err)
+	}
+}
+
+// TestModerateProfile tests that hidden profiles are not shown, locked ones
+// cannot be changed, and a cleared nickname is freed
+func TestModerateProfile(t *testing.T) {
+	r, _ := NewRegistry("")
+	if _, err := r.Set("alice-addr", Update{Nickname: "alice", Bio: "offending bio"}); err != nil {
+		t.Fatalf("failed to set profile: %v", err)
+	}
+	if _, ok := r.Visible("alice-addr"); !ok {
+		t.Fatal("expected the profile to be visible")
+	}
+
+	yes := true
+	p, err := r.Moderate("alice-addr", "mod", Moderation{Hidden: &yes, Locked: &yes, ClearBio: true, Note: "spam"})
+	if err != nil {
+		t.Fatalf("failed to moderate: %v", err)
+	}
+	if p.Bio != "" || p.ModeratedBy != "mod" || p.Note != "spam" {
+		t.Errorf("unexpected moderated profile %+v", p)
+	}
+	if _, ok := r.Visible("alice-addr"); ok {
+		t.Error("expected a hidden profile not to be visible")
+	}
+	if _, err := r.Set("alice-addr", Update{Nickname: "alice"}); !errors.Is(err, ErrProfileLocked) {
+		t.Errorf("expected a locked profile to reject edits, got %v", err)
+	}
+	if err := r.Delete("alice-addr"); !errors.Is(err, ErrProfileLocked) {
+		t.Errorf("expected a locked profile to reject deletion, got %v", err)
+	}
+	if _, err := r.Set("bob-addr", Update{Nickname: "alice"}); !errors.Is(err, ErrNicknameTaken) {
+		t.Errorf("expected a hidden profile to keep its nickname, got %v", err)
+	}
+
+	if _, err := r.Moderate("alice-addr", "mod", Moderation{ClearNickname: true}); err != nil {
+		t.Fatalf("failed to clear nickname: %v", err)
+	}
+	if _, err := r.Set("bob-addr", Update{Nickname: "alice"}); err != nil {
+		t.Errorf("expected the cleared nickname to be free, got %v", err)
+	}
+	if _, err := r.Moderate("carol-addr", "mod", Moderation{}); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+}
+
+// TestRegistryPersistence tests that profiles and nickname ownership survive
+// reloading the registry file
+func TestRegistryPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	r, err := NewRegistry(path)
+	if err != nil {
+		t.Fatalf("failed to create registry: %v", err)
+	}
+	if _, err := r.Set("alice-addr", Update{Nickname: "alice"}); err != nil {
+		t.Fatalf("failed to set profile: %v", err)
+	}
+	yes := true
+	if _, err := r.Moderate("alice-addr", "mod", Moderation{Locked: &yes}); err != nil {
+		t.Fatalf("failed to moderate: %v", err)
+	}
+
+	reloaded, err := NewRegistry(path)
+	if err != nil {
+		t.Fatalf("failed to reload registry: %v", err)
+	}
+	p, err := reloaded.Get("alice-addr")
+	if err != nil || p.Nickname != "alice" || !p.Locked {
+		t.Fatalf("expected the locked profile after reload, got %+v, %v", p, err)
+	}
+	if _, err := reloaded.Set("bob-addr", Update{Nickname: "Alice"}); !errors.Is(err, ErrNicknameTaken) {
+		t.Errorf("expected the reloaded nickname to be taken, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestTraderProfiles tests that traders set their own profiles, that
+// visible profiles are shown on community pool owners and holders, and that
+// a profile hidden by a moderator is no longer shown
+func TestTraderProfiles(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	config.AdminToken = "admin-secret"
+	config.ProfileStore = filepath.Join(t.TempDir(), "profiles.json")
+	s := NewServer(config)
+	handler := s.handler()
+
+	do := func(method, target, body string, header map[string]string, v interface{}) int {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("%s %s: invalid JSON %q: %v", method, target, rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+	owner := map[string]string{"X-Trader-Address": "cosmos1holder1"}
+
+	if code := do(http.MethodPut, "/v1/account/profile", `{"nickname":"whale","bio":"gm"}`, owner, nil); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	var errResp types.ErrorResponse
+	if code := do(http.MethodPut, "/v1/account/profile", `{"nickname":"Whale"}`, map[string]string{"X-Trader-Address": "cosmos1holder2"}, &errResp); code != http.StatusConflict || errResp.Code != types.ErrCodeNicknameTaken {
+		t.Errorf("expected 409 nickname_taken, got %d %+v", code, errResp.APIError)
+	}
+
+	pool, err := s.riverpoolService.CreateCommunityPool("cosmos1holder1", &types.CommunityPoolParams{Name: "Whale Fund"})
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	var info types.PoolInfo
+	if code := do(http.MethodGet, "/v1/riverpool/pools/"+pool.PoolID, "", nil, &info); code != http.StatusOK || info.OwnerProfile == nil || info.OwnerProfile.Nickname != "whale" {
+		t.Fatalf("expected the owner's profile, got %d %+v", code, info.OwnerProfile)
+	}
+	var holders struct {
+		Holders []*types.HolderInfo `json:"holders"`
+	}
+	do(http.MethodGet, "/v1/riverpool/pools/"+pool.PoolID+"/holders", "", nil, &holders)
+	if len(holders.Holders) != 2 || holders.Holders[0].Profile == nil || holders.Holders[0].Profile.Nickname != "whale" || holders.Holders[1].Profile != nil {
+		t.Errorf("expected only the first holder's profile, got %+v", holders.Holders)
+	}
+
+	admin := map[string]string{adminTokenHeader: config.AdminToken, adminActorHeader: "mod"}
+	if code := do(http.MethodPut, "/v1/admin/profiles/cosmos1holder1", `{"hidden":true,"locked":true}`, admin, nil); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if code := do(http.MethodGet, "/v1/profiles/cosmos1holder1", "", nil, nil); code != http.StatusNotFound {
+		t.Errorf("expected a hidden profile to be not found, got %d", code)
+	}
+	info = types.PoolInfo{}
+	do(http.MethodGet, "/v1/riverpool/pools/"+pool.PoolID, "", nil, &info)
+	if info.OwnerProfile != nil {
+		t.Errorf("expected a hidden profile not to be shown, got %+v", info.OwnerProfile)
+	}
+	if code := do(http.MethodDelete, "/v1/account/profile", "", owner, &errResp); code != http.StatusForbidden || errResp.Code != types.ErrCodeProfileLocked {
+		t.Errorf("expected 403 profile_locked, got %d %+v", code, errResp.APIError)
+	}
+}
@@ -0,0 +1,557 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/middleware"
+	"github.com/openalpha/perp-dex/api/types"
+	obkeeper "github.com/openalpha/perp-dex/x/orderbook/keeper"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// adminActorHeader names the operator behind a parameter change in the
+// audit log; parameter changes without it are rejected
+const adminActorHeader = "X-Admin-Actor"
+
+// maxFeeRate bounds taker and maker fee rates set at runtime
+var maxFeeRate = math.LegacyNewDecWithPrec(1, 1)
+
+// handleAdminParams handles GET /v1/admin/params: every market's fees, price
+// band and funding params with the node's rate limits
+func (s *Server) handleAdminParams(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	params := &types.ProtocolParams{RateLimits: rateLimitParams(s.rateLimiter.Config())}
+	if markets, ok := s.orderService.(types.ProtocolParamsService); ok {
+		list, err := markets.ListMarketParams(r.Context())
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		params.Markets = list
+		params.Editable = true
+	}
+	writeJSON(w, http.StatusOK, params)
+}
+
+// handleAdminMarketParams handles PUT /v1/admin/params/markets/{market_id}
+// with a types.MarketParamsUpdate, auditing every parameter it changes
+func (s *Server) handleAdminMarketParams(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	if r.Method != http.MethodPut {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	marketID := strings.TrimPrefix(r.URL.Path, "/v1/admin/params/markets/")
+	if marketID == "" || strings.Contains(marketID, "/") {
+		writeError(w, types.ErrCodeInvalidPath, "Invalid market path")
+		return
+	}
+	actor := r.Header.Get(adminActorHeader)
+	if actor == "" {
+		writeError(w, types.ErrCodeMissingField, adminActorHeader+" header is required")
+		return
+	}
+	markets, ok := s.orderService.(types.ProtocolParamsService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Market params can only be changed on a standalone or matcher node")
+		return
+	}
+
+	var req types.MarketParamsUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+		return
+	}
+	before, after, err := markets.UpdateMarketParams(r.Context(), marketID, &req)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+		return
+	}
+	if !s.auditParamChanges(w, r, actor, req.Reason, marketParamChanges(before, after)) {
+		return
+	}
+	writeJSON(w, http.StatusOK, after)
+}
+
+// handleAdminRateLimits handles /v1/admin/params/rate-limits (GET, PUT with a
+// types.RateLimitParamsUpdate): the node's API rate limits, auditing every
+// limit a PUT changes. Limits are per node.
+func (s *Server) handleAdminRateLimits(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, rateLimitParams(s.rateLimiter.Config()))
+
+	case http.MethodPut:
+		actor := r.Header.Get(adminActorHeader)
+		if actor == "" {
+			writeError(w, types.ErrCodeMissingField, adminActorHeader+" header is required")
+			return
+		}
+		var req types.RateLimitParamsUpdate
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+
+		config := s.rateLimiter.Config()
+		before := rateLimitParams(config)
+		for _, field := range []struct {
+			name   string
+			update *int
+			value  *int
+		}{
+			{"ip_requests_per_second", req.IPRequestsPerSecond, &config.IPRequestsPerSecond},
+			{"ip_burst", req.IPBurst, &config.IPBurst},
+			{"user_requests_per_second", req.UserRequestsPerSecond, &config.UserRequestsPerSecond},
+			{"user_burst", req.UserBurst, &config.UserBurst},
+			{"orders_per_second", req.OrdersPerSecond, &config.OrdersPerSecond},
+			{"order_burst", req.OrderBurst, &config.OrderBurst},
+			{"orders_per_day", req.OrdersPerDay, &config.OrdersPerDay},
+		} {
+			if field.update == nil {
+				continue
+			}
+			if *field.update <= 0 {
+				writeError(w, types.ErrCodeInvalidRequest, field.name+" must be positive")
+				return
+			}
+			*field.value = *field.update
+		}
+		s.rateLimiter.SetConfig(config)
+		after := rateLimitParams(config)
+
+		if !s.auditParamChanges(w, r, actor, req.Reason, rateLimitChanges(before, after)) {
+			return
+		}
+		writeJSON(w, http.StatusOK, after)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleAdminParamAudit handles GET /v1/admin/params/audit?from=&to=&actor=&param=&limit=,
+// the parameter changes in [from, to] (Unix ms) newest first
+func (s *Server) handleAdminParamAudit(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	q := paramAuditQuery{
+		Actor: query.Get("actor"),
+		Param: query.Get("param"),
+		Limit: DefaultParamAuditLimit,
+	}
+	for _, bound := range []struct {
+		name  string
+		value *int64
+	}{{"from", &q.From}, {"to", &q.To}} {
+		if v := query.Get(bound.name); v != "" {
+			ms, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || ms < 0 {
+				writeError(w, types.ErrCodeInvalidRequest, bound.name+" must be a Unix timestamp in milliseconds")
+				return
+			}
+			*bound.value = ms
+		}
+	}
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 || n > MaxParamAuditLimit {
+			writeError(w, types.ErrCodeInvalidRequest, "limit must be between 1 and "+strconv.Itoa(MaxParamAuditLimit))
+			return
+		}
+		q.Limit = n
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"changes": s.paramAudit.query(q)})
+}
+
+// auditParamChanges records changes made by actor, writing an error and
+// returning false if the audit log cannot be written
+func (s *Server) auditParamChanges(w http.ResponseWriter, r *http.Request, actor, reason string, changes []*types.ParamChange) bool {
+	if len(changes) == 0 {
+		return true
+	}
+	remoteAddr := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+	now := time.Now().UnixMilli()
+	for _, change := range changes {
+		change.Time = now
+		change.Actor = actor
+		change.RemoteAddr = remoteAddr
+		change.Reason = reason
+	}
+	if err := s.paramAudit.record(changes); err != nil {
+		log.Printf("Failed to audit %d parameter changes by %s: %v", len(changes), actor, err)
+		writeError(w, types.ErrCodeInternal, "Parameters changed but the audit log could not be written")
+		return false
+	}
+	return true
+}
+
+// marketParamChanges lists the parameters that differ between before and after
+func marketParamChanges(before, after *types.MarketParams) []*types.ParamChange {
+	prefix := "markets." + after.MarketID + "."
+	pairs := [][3]string{
+		{"taker_fee_rate", before.TakerFeeRate, after.TakerFeeRate},
+		{"maker_fee_rate", before.MakerFeeRate, after.MakerFeeRate},
+		{"max_price_deviation", before.MaxPriceDeviation, after.MaxPriceDeviation},
+		{"max_slippage", before.MaxSlippage, after.MaxSlippage},
+	}
+	if before.Funding != nil && after.Funding != nil {
+		pairs = append(pairs,
+			[3]string{"funding.interval", strconv.FormatInt(before.Funding.Interval, 10), strconv.FormatInt(after.Funding.Interval, 10)},
+			[3]string{"funding.max_rate", before.Funding.MaxRate, after.Funding.MaxRate},
+			[3]string{"funding.min_rate", before.Funding.MinRate, after.Funding.MinRate},
+			[3]string{"funding.damping_factor", before.Funding.DampingFactor, after.Funding.DampingFactor},
+			[3]string{"funding.interest_rate", before.Funding.InterestRate, after.Funding.InterestRate},
+			[3]string{"funding.divergence_threshold", before.Funding.DivergenceThreshold, after.Funding.DivergenceThreshold},
+			[3]string{"funding.divergence_intervals", strconv.FormatInt(before.Funding.DivergenceIntervals, 10), strconv.FormatInt(after.Funding.DivergenceIntervals, 10)},
+			[3]string{"funding.guard_band_factor", before.Funding.GuardBandFactor, after.Funding.GuardBandFactor},
+			[3]string{"funding.guard_dampening", before.Funding.GuardDampening, after.Funding.GuardDampening},
+		)
+	}
+	return paramChanges(prefix, pairs)
+}
+
+// rateLimitChanges lists the rate limits that differ between before and after
+func rateLimitChanges(before, after *types.RateLimitParams) []*types.ParamChange {
+	pair := func(name string, from, to int) [3]string {
+		return [3]string{name, strconv.Itoa(from), strconv.Itoa(to)}
+	}
+	return paramChanges("rate_limits.", [][3]string{
+		pair("ip_requests_per_second", before.IPRequestsPerSecond, after.IPRequestsPerSecond),
+		pair("ip_burst", before.IPBurst, after.IPBurst),
+		pair("user_requests_per_second", before.UserRequestsPerSecond, after.UserRequestsPerSecond),
+		pair("user_burst", before.UserBurst, after.UserBurst),
+		pair("orders_per_second", before.OrdersPerSecond, after.OrdersPerSecond),
+		pair("order_burst", before.OrderBurst, after.OrderBurst),
+		pair("orders_per_day", before.OrdersPerDay, after.OrdersPerDay),
+	})
+}
+
+// paramChanges turns {name, old, new} triples into audit entries for the
+// ones that changed
+func paramChanges(prefix string, pairs [][3]string) []*types.ParamChange {
+	var changes []*types.ParamChange
+	for _, p := range pairs {
+		if p[1] != p[2] {
+			changes = append(changes, &types.ParamChange{Param: prefix + p[0], Old: p[1], New: p[2]})
+		}
+	}
+	return changes
+}
+
+// rateLimitParams reports a rate limiter config
+func rateLimitParams(config middleware.RateLimitConfig) *types.RateLimitParams {
+	return &types.RateLimitParams{
+		IPRequestsPerSecond:   config.IPRequestsPerSecond,
+		IPBurst:               config.IPBurst,
+		UserRequestsPerSecond: config.UserRequestsPerSecond,
+		UserBurst:             config.UserBurst,
+		OrdersPerSecond:       config.OrdersPerSecond,
+		OrderBurst:            config.OrderBurst,
+		OrdersPerDay:          config.OrdersPerDay,
+	}
+}
+
+// marketParamValues is the decimal form of a market's fees and price band
+type marketParamValues struct {
+	TakerFeeRate      math.LegacyDec
+	MakerFeeRate      math.LegacyDec
+	MaxPriceDeviation math.LegacyDec
+	MaxSlippage       math.LegacyDec
+}
+
+// apply sets the fields update changes and checks the result: fees below
+// 10% with maker rebates no larger than the taker fee, a price deviation
+// within [0, 1] and a slippage within [0, 1). Zero disables either band.
+func (v *marketParamValues) apply(update *types.MarketParamsUpdate) error {
+	for _, field := range []struct {
+		name  string
+		input string
+		value *math.LegacyDec
+	}{
+		{"taker_fee_rate", update.TakerFeeRate, &v.TakerFeeRate},
+		{"maker_fee_rate", update.MakerFeeRate, &v.MakerFeeRate},
+		{"max_price_deviation", update.MaxPriceDeviation, &v.MaxPriceDeviation},
+		{"max_slippage", update.MaxSlippage, &v.MaxSlippage},
+	} {
+		if field.input == "" {
+			continue
+		}
+		d, err := math.LegacyNewDecFromStr(field.input)
+		if err != nil {
+			return types.NewAPIError(types.ErrCodeInvalidRequest, field.name+" must be a decimal")
+		}
+		*field.value = d
+	}
+
+	taker, maker := orZero(v.TakerFeeRate), orZero(v.MakerFeeRate)
+	if taker.IsNegative() || taker.GTE(maxFeeRate) {
+		return types.NewAPIError(types.ErrCodeInvalidRequest, "taker_fee_rate must be within [0, 0.1)")
+	}
+	if maker.GTE(maxFeeRate) || maker.Add(taker).IsNegative() {
+		return types.NewAPIError(types.ErrCodeInvalidRequest, "maker_fee_rate must be below 0.1 with a rebate no larger than the taker fee")
+	}
+	if deviation := orZero(v.MaxPriceDeviation); deviation.IsNegative() || deviation.GT(math.LegacyOneDec()) {
+		return types.NewAPIError(types.ErrCodeInvalidRequest, "max_price_deviation must be within [0, 1]")
+	}
+	if slippage := orZero(v.MaxSlippage); slippage.IsNegative() || slippage.GTE(math.LegacyOneDec()) {
+		return types.NewAPIError(types.ErrCodeInvalidRequest, "max_slippage must be within [0, 1)")
+	}
+	return nil
+}
+
+// params reports the values as a market's params
+func (v *marketParamValues) params(marketID string) *types.MarketParams {
+	return &types.MarketParams{
+		MarketID:          marketID,
+		TakerFeeRate:      decString(v.TakerFeeRate),
+		MakerFeeRate:      decString(v.MakerFeeRate),
+		MaxPriceDeviation: decString(v.MaxPriceDeviation),
+		MaxSlippage:       decString(v.MaxSlippage),
+	}
+}
+
+func orZero(d math.LegacyDec) math.LegacyDec {
+	if d.IsNil() {
+		return math.LegacyZeroDec()
+	}
+	return d
+}
+
+// applyFundingParams sets the fields of config update changes, leaving a zero
+// interval and empty rates unchanged, and validates the result
+func applyFundingParams(config *perptypes.FundingConfig, update *types.FundingParams) error {
+	if update.Interval != 0 {
+		config.Interval = update.Interval
+	}
+	if update.DivergenceIntervals != 0 {
+		config.DivergenceIntervals = update.DivergenceIntervals
+	}
+	for _, field := range []struct {
+		name  string
+		input string
+		value *math.LegacyDec
+	}{
+		{"funding.max_rate", update.MaxRate, &config.MaxRate},
+		{"funding.min_rate", update.MinRate, &config.MinRate},
+		{"funding.damping_factor", update.DampingFactor, &config.DampingFactor},
+		{"funding.interest_rate", update.InterestRate, &config.InterestRate},
+		{"funding.divergence_threshold", update.DivergenceThreshold, &config.DivergenceThreshold},
+		{"funding.guard_band_factor", update.GuardBandFactor, &config.GuardBandFactor},
+		{"funding.guard_dampening", update.GuardDampening, &config.GuardDampening},
+	} {
+		if field.input == "" {
+			continue
+		}
+		d, err := math.LegacyNewDecFromStr(field.input)
+		if err != nil {
+			return types.NewAPIError(types.ErrCodeInvalidRequest, field.name+" must be a decimal")
+		}
+		*field.value = d
+	}
+	return config.Validate()
+}
+
+// fundingParams reports a market's funding config
+func fundingParams(config perptypes.FundingConfig) *types.FundingParams {
+	return &types.FundingParams{
+		Interval:      config.Interval,
+		MaxRate:       decString(config.MaxRate),
+		MinRate:       decString(config.MinRate),
+		DampingFactor: decString(config.DampingFactor),
+		InterestRate:  decString(config.InterestRate),
+
+		DivergenceThreshold: decString(config.DivergenceThreshold),
+		DivergenceIntervals: config.DivergenceIntervals,
+		GuardBandFactor:     decString(config.GuardBandFactor),
+		GuardDampening:      decString(config.GuardDampening),
+	}
+}
+
+func (rs *RealService) ListMarketParams(ctx context.Context) ([]*types.MarketParams, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.perpKeeper == nil {
+		return rs.simplePerp.marketParams(), nil
+	}
+	markets := rs.perpKeeper.GetAllMarkets(rs.sdkCtx)
+	params := make([]*types.MarketParams, 0, len(markets))
+	for _, market := range markets {
+		p := perpMarketValues(market).params(market.MarketID)
+		p.Funding = fundingParams(rs.perpKeeper.GetFundingConfig(rs.sdkCtx, market.MarketID))
+		limits := rs.perpKeeper.GetOrderLimits(rs.sdkCtx, market)
+		p.WarmUp = warmUpParams(limits)
+		p.DivergenceGuard = divergenceGuardStatus(rs.perpKeeper.GetDivergenceGuard(rs.sdkCtx, market.MarketID), limits)
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// divergenceGuardStatus reports a market's divergence guard with the price
+// band in force, nil while the mark tracks the index
+func divergenceGuardStatus(guard *perptypes.DivergenceGuard, limits perptypes.OrderLimits) *types.DivergenceGuardStatus {
+	if guard == nil {
+		return nil
+	}
+	status := &types.DivergenceGuardStatus{
+		Engaged:           guard.Engaged,
+		Divergence:        decString(guard.Divergence),
+		DivergedIntervals: guard.Diverged,
+		MaxPriceDeviation: decString(limits.MaxPriceDeviation),
+		MaxSlippage:       decString(limits.MaxSlippage),
+	}
+	if guard.Engaged {
+		status.EngagedAt = guard.EngagedAt.Unix()
+	}
+	return status
+}
+
+// warmUpParams reports the warm-up limits of a market, nil outside warm-up
+func warmUpParams(limits perptypes.OrderLimits) *types.WarmUpParams {
+	if limits.WarmUpEndsAt == 0 {
+		return nil
+	}
+	return &types.WarmUpParams{
+		PostOnly:          limits.PostOnly,
+		MaxOrderSize:      decString(limits.MaxOrderSize),
+		MaxPriceDeviation: decString(limits.MaxPriceDeviation),
+		EndsAtHeight:      limits.WarmUpEndsAt,
+	}
+}
+
+func (rs *RealService) UpdateMarketParams(ctx context.Context, marketID string, update *types.MarketParamsUpdate) (*types.MarketParams, *types.MarketParams, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.perpKeeper == nil {
+		if update.Funding != nil {
+			return nil, nil, types.NewAPIError(types.ErrCodeNotImplemented, "Funding params require a keeper-backed service")
+		}
+		return rs.simplePerp.updateMarketParams(marketID, update)
+	}
+
+	sdkCtx := rs.clockCtx()
+	market := rs.perpKeeper.GetMarket(sdkCtx, marketID)
+	if market == nil {
+		return nil, nil, types.NewAPIError(types.ErrCodeMarketNotFound, "Market not found")
+	}
+	values := perpMarketValues(market)
+	before := values.params(marketID)
+	funding := rs.perpKeeper.GetFundingConfig(sdkCtx, marketID)
+	before.Funding = fundingParams(funding)
+
+	if err := values.apply(update); err != nil {
+		return nil, nil, err
+	}
+	if update.Funding != nil {
+		if err := applyFundingParams(&funding, update.Funding); err != nil {
+			return nil, nil, err
+		}
+		rs.perpKeeper.SetFundingConfig(sdkCtx, marketID, funding)
+		market.FundingInterval = funding.Interval
+	}
+	market.TakerFeeRate = values.TakerFeeRate
+	market.MakerFeeRate = values.MakerFeeRate
+	market.MaxPriceDeviation = values.MaxPriceDeviation
+	market.MaxSlippage = values.MaxSlippage
+	market.UpdatedAt = sdkCtx.BlockTime()
+	rs.perpKeeper.SetMarket(sdkCtx, market)
+
+	after := values.params(marketID)
+	after.Funding = fundingParams(funding)
+	return before, after, nil
+}
+
+func perpMarketValues(market *perptypes.Market) *marketParamValues {
+	return &marketParamValues{
+		TakerFeeRate:      market.TakerFeeRate,
+		MakerFeeRate:      market.MakerFeeRate,
+		MaxPriceDeviation: market.MaxPriceDeviation,
+		MaxSlippage:       market.MaxSlippage,
+	}
+}
+
+// marketParams reports every market's params sorted by market ID
+func (pk *SimplePerpetualKeeper) marketParams() []*types.MarketParams {
+	pk.mu.RLock()
+	defer pk.mu.RUnlock()
+
+	params := make([]*types.MarketParams, 0, len(pk.markets))
+	for id, market := range pk.markets {
+		params = append(params, obMarketValues(market).params(id))
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].MarketID < params[j].MarketID })
+	return params
+}
+
+// updateMarketParams applies update to a copy of the market and swaps it in,
+// so a market read before the update is never modified
+func (pk *SimplePerpetualKeeper) updateMarketParams(marketID string, update *types.MarketParamsUpdate) (*types.MarketParams, *types.MarketParams, error) {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+
+	market, ok := pk.markets[marketID]
+	if !ok {
+		return nil, nil, types.NewAPIError(types.ErrCodeMarketNotFound, "Market not found")
+	}
+	values := obMarketValues(market)
+	before := values.params(marketID)
+	if err := values.apply(update); err != nil {
+		return nil, nil, err
+	}
+
+	updated := *market
+	updated.TakerFeeRate = values.TakerFeeRate
+	updated.MakerFeeRate = values.MakerFeeRate
+	updated.MaxPriceDeviation = values.MaxPriceDeviation
+	updated.MaxSlippage = values.MaxSlippage
+	pk.markets[marketID] = &updated
+	return before, values.params(marketID), nil
+}
+
+func obMarketValues(market *obkeeper.Market) *marketParamValues {
+	return &marketParamValues{
+		TakerFeeRate:      market.TakerFeeRate,
+		MakerFeeRate:      market.MakerFeeRate,
+		MaxPriceDeviation: market.MaxPriceDeviation,
+		MaxSlippage:       market.MaxSlippage,
+	}
+}
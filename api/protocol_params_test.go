@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestAdminProtocolParams tests that operators can change market and rate
+// limit params at runtime, that every change lands in the audit log with its
+// actor, and that the log survives a restart
+func TestAdminProtocolParams(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	config.AdminToken = "admin-secret"
+	config.ParamAuditLog = filepath.Join(t.TempDir(), "params.jsonl")
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	do := func(method, target, actor, body string, v interface{}) int {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(adminTokenHeader, config.AdminToken)
+		if actor != "" {
+			req.Header.Set(adminActorHeader, actor)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("%s %s: invalid JSON %q: %v", method, target, rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+
+	var params types.ProtocolParams
+	if code := do(http.MethodGet, "/v1/admin/params", "", "", &params); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(params.Markets) != 3 || !params.Editable || params.RateLimits.OrdersPerSecond != 10 {
+		t.Fatalf("expected 3 editable markets and the default rate limits, got %+v", params)
+	}
+
+	if code := do(http.MethodPut, "/v1/admin/params/markets/BTC-USDC", "", `{"taker_fee_rate":"0.0005"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 without an actor, got %d", code)
+	}
+	if code := do(http.MethodPut, "/v1/admin/params/markets/BTC-USDC", "alice", `{"taker_fee_rate":"0.2"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a 20%% fee, got %d", code)
+	}
+	if code := do(http.MethodPut, "/v1/admin/params/markets/BTC-USDC", "alice", `{"funding":{"max_rate":"0.01"}}`, nil); code != http.StatusNotImplemented {
+		t.Errorf("expected 501 for funding without a perpetual keeper, got %d", code)
+	}
+	if code := do(http.MethodPut, "/v1/admin/params/markets/NOPE-USDC", "alice", `{"taker_fee_rate":"0.0005"}`, nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown market, got %d", code)
+	}
+
+	var market types.MarketParams
+	body := `{"taker_fee_rate":"0.0005","max_slippage":"0.02","reason":"fee promo"}`
+	if code := do(http.MethodPut, "/v1/admin/params/markets/BTC-USDC", "alice", body, &market); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if market.TakerFeeRate != "0.000500000000000000" || market.MaxSlippage != "0.020000000000000000" {
+		t.Errorf("expected the new fee and slippage, got %+v", market)
+	}
+	rs := s.orderService.(*RealService)
+	if fee := rs.simplePerp.GetMarket(rs.sdkCtx, "BTC-USDC").TakerFeeRate; fee.String() != market.TakerFeeRate {
+		t.Errorf("expected the orderbook to see the new fee, got %s", fee)
+	}
+
+	var limits types.RateLimitParams
+	if code := do(http.MethodPut, "/v1/admin/params/rate-limits", "bob", `{"orders_per_second":0}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a zero limit, got %d", code)
+	}
+	if code := do(http.MethodPut, "/v1/admin/params/rate-limits", "bob", `{"orders_per_second":5}`, &limits); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if limits.OrdersPerSecond != 5 || s.rateLimiter.Config().OrdersPerSecond != 5 {
+		t.Errorf("expected 5 orders per second in force, got %+v", limits)
+	}
+
+	var audit struct {
+		Changes []*types.ParamChange `json:"changes"`
+	}
+	if code := do(http.MethodGet, "/v1/admin/params/audit", "", "", &audit); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(audit.Changes) != 3 || audit.Changes[0].Param != "rate_limits.orders_per_second" || audit.Changes[0].Old != "10" || audit.Changes[0].New != "5" {
+		t.Fatalf("expected 3 changes newest first, got %+v", audit.Changes)
+	}
+	if change := audit.Changes[2]; change.Param != "markets.BTC-USDC.taker_fee_rate" || change.Actor != "alice" || change.Reason != "fee promo" {
+		t.Errorf("expected alice's fee change, got %+v", change)
+	}
+	if code := do(http.MethodGet, "/v1/admin/params/audit?actor=alice&param=markets.BTC-USDC.", "", "", &audit); code != http.StatusOK || len(audit.Changes) != 2 {
+		t.Errorf("expected alice's 2 changes, got %d %+v", code, audit.Changes)
+	}
+	if code := do(http.MethodGet, "/v1/admin/params/audit?from=32503680000000", "", "", &audit); code != http.StatusOK || len(audit.Changes) != 0 {
+		t.Errorf("expected no changes after 3000, got %d %+v", code, audit.Changes)
+	}
+
+	restarted := newParamAudit(config)
+	if changes := restarted.query(paramAuditQuery{Limit: MaxParamAuditLimit}); len(changes) != 3 || changes[0].ID != 3 {
+		t.Errorf("expected the 3 changes back from the file, got %+v", changes)
+	}
+	if restarted.nextID != 4 {
+		t.Errorf("expected numbering to continue at 4, got %d", restarted.nextID)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/params/audit", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without the admin token, got %d", rec.Code)
+	}
+}
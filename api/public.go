@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/middleware"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// publicCachePolicy is the Cache-Control policy for one public endpoint.
+// MaxAge applies to browsers, SMaxAge to shared caches such as a CDN.
+type publicCachePolicy struct {
+	MaxAge  time.Duration
+	SMaxAge time.Duration
+}
+
+// header renders the policy as a Cache-Control value
+func (p publicCachePolicy) header() string {
+	return fmt.Sprintf("public, max-age=%d, s-maxage=%d", int(p.MaxAge.Seconds()), int(p.SMaxAge.Seconds()))
+}
+
+// publicCachePolicies maps public endpoints to their cache lifetimes. Books
+// change fastest, so browsers always revalidate them and the CDN holds them
+// for a single second.
+var publicCachePolicies = map[string]publicCachePolicy{
+	"markets":          {MaxAge: 30 * time.Second, SMaxAge: 60 * time.Second},
+	"tickers":          {MaxAge: time.Second, SMaxAge: time.Second},
+	"ticker":           {MaxAge: time.Second, SMaxAge: time.Second},
+	"orderbook":        {MaxAge: 0, SMaxAge: time.Second},
+	"trades":           {MaxAge: time.Second, SMaxAge: 2 * time.Second},
+	"klines":           {MaxAge: 5 * time.Second, SMaxAge: 10 * time.Second},
+	"snapshot":         {MaxAge: 0, SMaxAge: time.Second},
+	"orderbooks":       {MaxAge: 0, SMaxAge: time.Second},
+	"history":          {MaxAge: 30 * time.Second, SMaxAge: 60 * time.Second},
+	"long-short-ratio": {MaxAge: 5 * time.Second, SMaxAge: 5 * time.Second},
+}
+
+// DefaultPublicRequestsPerSecond is the per-IP rate limit on the public listener
+// when Config.PublicRequestsPerSecond is unset
+const DefaultPublicRequestsPerSecond = 20
+
+// publicHandler returns the read-only public market data router. It serves
+// markets, tickers, snapshots, order books, trades, klines and the
+// TradingView datafeed without authentication, with Cache-Control/ETag
+// headers so it can sit behind a CDN. Trading and account endpoints are
+// never mounted here.
+func (s *Server) publicHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.Handle("/v1/markets", cachedPublic(publicCachePolicies["markets"], s.handleMarkets))
+	mux.Handle("/v1/tickers", cachedPublic(publicCachePolicies["tickers"], s.handleTickers))
+	mux.Handle("/v1/snapshot", cachedPublic(publicCachePolicies["snapshot"], s.handleSnapshot))
+	mux.Handle("/v1/orderbooks", cachedPublic(publicCachePolicies["orderbooks"], s.handleOrderBooks))
+	mux.HandleFunc("/v1/markets/", s.handlePublicMarket)
+	mux.Handle("/v1/tv/config", cachedPublic(tvCachePolicies["config"], s.handleTVConfig))
+	mux.Handle("/v1/tv/symbols", cachedPublic(tvCachePolicies["symbols"], s.handleTVSymbols))
+	mux.Handle("/v1/tv/history", cachedPublic(tvCachePolicies["history"], s.handleTVHistory))
+
+	// The CDN caches one representation, so the public listener always
+	// uses the configured number format
+	handler := s.numberFormatMiddleware(false)(mux)
+	if !s.config.DisableRateLimit {
+		handler = middleware.RateLimitMiddleware(s.publicRateLimiter())(handler)
+	}
+	return middleware.RequestIDMiddleware(publicHeadersMiddleware(handler))
+}
+
+// handlePublicMarket handles /v1/markets/{id}/{endpoint} on the public
+// router, restricted to endpoints with a cache policy. Paths that are not
+// market data fall through to handleMarket's own 404.
+func (s *Server) handlePublicMarket(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v1/markets/")
+	endpoint := ""
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		endpoint = path[i+1:]
+	}
+
+	policy, ok := publicCachePolicies[endpoint]
+	if !ok {
+		writeError(w, types.ErrCodeNotFound, "Endpoint not found")
+		return
+	}
+	cachedPublic(policy, s.handleMarket).ServeHTTP(w, r)
+}
+
+// publicRateLimiter builds the per-IP limiter for the public listener. It is
+// separate from the private listener's limiter so CDN misses and scrapers
+// cannot starve trading clients.
+func (s *Server) publicRateLimiter() *middleware.RateLimiter {
+	rps := s.config.PublicRequestsPerSecond
+	if rps <= 0 {
+		rps = DefaultPublicRequestsPerSecond
+	}
+	config := middleware.DefaultRateLimitConfig()
+	config.IPRequestsPerSecond = rps
+	config.IPBurst = 2 * rps
+	return middleware.NewRateLimiter(config)
+}
+
+// cachedPublic wraps a GET handler with cache headers and conditional GET
+// support. Successful responses get Cache-Control from policy and a strong
+// ETag over the body; a matching If-None-Match is answered with 304. Errors
+// keep the router's no-store so a CDN never pins them. HEAD is served as GET
+// without the body.
+func cachedPublic(policy publicCachePolicy, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+			return
+		}
+		head := r.Method == http.MethodHead
+		if head {
+			r = r.Clone(r.Context())
+			r.Method = http.MethodGet
+		}
+
+		rec := middleware.NewResponseCapture(make(http.Header))
+		next(rec, r)
+
+		for key, values := range rec.Header() {
+			w.Header()[key] = values
+		}
+		if rec.Status != http.StatusOK {
+			w.WriteHeader(rec.Status)
+			if !head {
+				w.Write(rec.Body.Bytes())
+			}
+			return
+		}
+
+		sum := sha256.Sum256(rec.Body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", policy.header())
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		if !head {
+			w.Write(rec.Body.Bytes())
+		}
+	})
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using
+// the weak comparison RFC 9110 prescribes for conditional GET
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// publicHeadersMiddleware allows any origin to read public market data and
+// marks every response no-store until cachedPublic says otherwise, so rate
+// limit rejections and health checks are never cached by the CDN
+func publicHeadersMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "If-None-Match, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, X-Request-ID")
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// startPublicServer serves the public market data router on
+// Config.PublicListenAddr in the background
+func (s *Server) startPublicServer() {
+	s.publicServer = &http.Server{
+		Addr:         s.config.PublicListenAddr,
+		Handler:      s.publicHandler(),
+		ReadTimeout:  s.config.ReadTimeout,
+		WriteTimeout: s.config.WriteTimeout,
+	}
+	go func() {
+		if err := s.publicServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Public market data server error: %v", err)
+		}
+	}()
+	log.Printf("Public market data listening on %s", s.config.PublicListenAddr)
+}
+
+// stopPublicServer shuts down the public listener if it was started
+func (s *Server) stopPublicServer(ctx context.Context) {
+	if s.publicServer == nil {
+		return
+	}
+	if err := s.publicServer.Shutdown(ctx); err != nil {
+		log.Printf("Public market data server shutdown error: %v", err)
+	}
+}
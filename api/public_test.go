@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestPublicMarketData tests cache headers, conditional GETs, rate limiting
+// and that only market data is reachable on the public router
+func TestPublicMarketData(t *testing.T) {
+	s := &Server{config: &Config{PublicRequestsPerSecond: 5}}
+	handler := s.publicHandler()
+
+	get := func(method, path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get(http.MethodGet, "/v1/markets/BTC-USDC/klines?interval=1m", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=5, s-maxage=10" {
+		t.Errorf("unexpected Cache-Control %q", cc)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	rec = get(http.MethodGet, "/v1/markets/BTC-USDC/klines?interval=1m", "W/"+etag)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("expected empty 304 for matching ETag, got %d with %d bytes", rec.Code, rec.Body.Len())
+	}
+
+	for _, path := range []string{"/v1/markets/BTC-USDC/funding", "/v1/orders", "/v1/account"} {
+		if rec := get(http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+			t.Errorf("expected %s to be unavailable, got %d", path, rec.Code)
+		}
+	}
+	if rec := get(http.MethodPost, "/v1/markets/BTC-USDC/trades", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected POST to be rejected, got %d", rec.Code)
+	}
+
+	// The burst is twice the per-second rate, so the limit hits well within 20 requests
+	limited := false
+	for i := 0; i < 20 && !limited; i++ {
+		rec = get(http.MethodGet, "/v1/markets/BTC-USDC/klines", "")
+		limited = rec.Code == http.StatusTooManyRequests
+	}
+	if !limited || !strings.Contains(rec.Body.String(), "rate_limit_exceeded") {
+		t.Errorf("expected rate limit, got %d: %s", rec.Code, rec.Body)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc == "" || strings.Contains(cc, "public") {
+		t.Errorf("expected rate limited response not to be cacheable, got %q", cc)
+	}
+}
@@ -0,0 +1,316 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Warm replay states reported by /ready
+const (
+	warmStateReplaying = "replaying"
+	warmStateReady     = "ready"
+	warmStateFailed    = "failed"
+)
+
+// journalRecord is one line of the match journal: a snapshot of the resting
+// orders and ID counters, or an engine event applied on top of it
+type journalRecord struct {
+	Snapshot *obtypes.GenesisState `json:"snapshot,omitempty"`
+	Event    *obtypes.Event        `json:"event,omitempty"`
+}
+
+// journalFold folds journal records into the latest state of every order,
+// remembering the order in which each order last started resting
+type journalFold struct {
+	orders       map[string]*obtypes.Order
+	position     map[string]int // order ID -> arrival on the book
+	arrivals     int
+	orderCounter uint64
+	tradeCounter uint64
+	eventSeq     uint64
+}
+
+func newJournalFold() *journalFold {
+	return &journalFold{
+		orders:   make(map[string]*obtypes.Order),
+		position: make(map[string]int),
+	}
+}
+
+// apply folds one record; a snapshot replaces everything folded so far
+func (f *journalFold) apply(rec *journalRecord) {
+	if rec.Snapshot != nil {
+		*f = *newJournalFold()
+		for _, order := range rec.Snapshot.Orders {
+			f.applyOrder(order)
+		}
+		f.orderCounter = max(f.orderCounter, rec.Snapshot.OrderCounter)
+		f.tradeCounter = max(f.tradeCounter, rec.Snapshot.TradeCounter)
+		f.eventSeq = max(f.eventSeq, rec.Snapshot.EventSeq)
+	}
+	if event := rec.Event; event != nil {
+		f.eventSeq = max(f.eventSeq, event.Seq)
+		if event.Order != nil {
+			f.applyOrder(event.Order)
+		}
+		if event.Trade != nil {
+			var n uint64
+			if _, err := fmt.Sscanf(event.Trade.TradeID, "trade-%d", &n); err == nil {
+				f.tradeCounter = max(f.tradeCounter, n)
+			}
+		}
+	}
+}
+
+// applyOrder records an order's latest state. An order that starts resting
+// again under the same ID, as a sticky slot does, queues behind the book.
+func (f *journalFold) applyOrder(order *obtypes.Order) {
+	if prev, ok := f.orders[order.OrderID]; !ok || (!prev.IsActive() && order.IsActive()) {
+		f.position[order.OrderID] = f.arrivals
+		f.arrivals++
+	}
+	f.orders[order.OrderID] = order
+
+	var n uint64
+	if _, err := fmt.Sscanf(order.OrderID, "order-%d", &n); err == nil {
+		f.orderCounter = max(f.orderCounter, n)
+	}
+}
+
+// genesis returns the resting limit orders in arrival order, which keeps
+// time priority within each price level, with the ID counters and event
+// sequence past everything journaled
+func (f *journalFold) genesis() *obtypes.GenesisState {
+	gs := obtypes.DefaultGenesis()
+	for _, order := range f.orders {
+		if order.OrderType == obtypes.OrderTypeLimit && order.IsActive() {
+			gs.Orders = append(gs.Orders, order)
+		}
+	}
+	sort.Slice(gs.Orders, func(i, j int) bool {
+		return f.position[gs.Orders[i].OrderID] < f.position[gs.Orders[j].OrderID]
+	})
+	gs.OrderCounter = f.orderCounter
+	gs.TradeCounter = f.tradeCounter
+	gs.EventSeq = f.eventSeq
+	return gs
+}
+
+// readJournal folds a match journal. A malformed final line is a write torn
+// by a crash and is skipped; a malformed line anywhere else is an error.
+func readJournal(r io.Reader) (*obtypes.GenesisState, error) {
+	fold := newJournalFold()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64<<20)
+
+	var torn error
+	line := 0
+	for scanner.Scan() {
+		line++
+		bz := bytes.TrimSpace(scanner.Bytes())
+		if len(bz) == 0 {
+			continue
+		}
+		if torn != nil {
+			return nil, torn
+		}
+		var rec journalRecord
+		if err := json.Unmarshal(bz, &rec); err != nil {
+			torn = fmt.Errorf("match journal line %d: %w", line, err)
+			continue
+		}
+		fold.apply(&rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read match journal: %w", err)
+	}
+	if torn != nil {
+		log.Printf("Match journal: skipping torn final line: %v", torn)
+	}
+	return fold.genesis(), nil
+}
+
+// ============ RealService replay ============
+
+// restoreOrders rebuilds the books from a snapshot of resting orders, which
+// must be in arrival order, and moves the ID counters and event sequence past
+// it. It is meant for a freshly started service; replayed orders are not
+// margin checked again.
+func (rs *RealService) restoreOrders(gs *obtypes.GenesisState) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return rs.obKeeper.InitGenesis(rs.sdkCtx, gs)
+}
+
+// journalEvents returns up to limit engine events from fromSeq on
+func (rs *RealService) journalEvents(fromSeq uint64, limit int) []*obtypes.Event {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	return rs.obKeeper.GetEvents(rs.sdkCtx, fromSeq, limit)
+}
+
+// ============ Journal writer ============
+
+// matchJournal appends engine events to the match journal file
+type matchJournal struct {
+	file *os.File
+	w    *bufio.Writer
+	next uint64 // sequence number of the next event to append
+}
+
+// createMatchJournal compacts the journal at path down to a snapshot and
+// opens it for appending events after the snapshot's sequence number. The
+// snapshot is written to a temporary file and renamed over the journal, so a
+// crash while compacting leaves the previous journal intact.
+func createMatchJournal(path string, snapshot *obtypes.GenesisState) (*matchJournal, error) {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create match journal: %w", err)
+	}
+	j := &matchJournal{file: file, w: bufio.NewWriter(file), next: snapshot.EventSeq + 1}
+	if err := j.write(&journalRecord{Snapshot: snapshot}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := j.sync(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to replace match journal: %w", err)
+	}
+	return j, nil
+}
+
+func (j *matchJournal) write(rec *journalRecord) error {
+	bz, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := j.w.Write(append(bz, '\n')); err != nil {
+		return fmt.Errorf("failed to write match journal: %w", err)
+	}
+	return nil
+}
+
+func (j *matchJournal) sync() error {
+	if err := j.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write match journal: %w", err)
+	}
+	return j.file.Sync()
+}
+
+// appendFrom appends every engine event not yet journaled and syncs the file
+func (j *matchJournal) appendFrom(rs *RealService) error {
+	for {
+		events := rs.journalEvents(j.next, MaxEventsLimit)
+		for _, event := range events {
+			if err := j.write(&journalRecord{Event: event}); err != nil {
+				return err
+			}
+			j.next = event.Seq + 1
+		}
+		if len(events) < MaxEventsLimit {
+			break
+		}
+	}
+	return j.sync()
+}
+
+// ============ Server warm-up ============
+
+// replayMatchJournal rebuilds the books from Config.MatchJournal and
+// compacts the journal, returning it open for appending
+func (s *Server) replayMatchJournal(rs *RealService) (*matchJournal, int, error) {
+	snapshot := obtypes.DefaultGenesis()
+	file, err := os.Open(s.config.MatchJournal)
+	switch {
+	case err == nil:
+		snapshot, err = readJournal(file)
+		file.Close()
+		if err != nil {
+			return nil, 0, err
+		}
+	case !os.IsNotExist(err):
+		return nil, 0, fmt.Errorf("failed to open match journal: %w", err)
+	}
+
+	if err := rs.restoreOrders(snapshot); err != nil {
+		return nil, 0, fmt.Errorf("failed to restore journaled orders: %w", err)
+	}
+	journal, err := createMatchJournal(s.config.MatchJournal, snapshot)
+	if err != nil {
+		return nil, 0, err
+	}
+	return journal, len(snapshot.Orders), nil
+}
+
+// warmUp replays the match journal, then starts the background workers and
+// marks the server ready. Until it finishes, new orders are rejected and
+// /ready reports 503; if the replay fails the server never becomes ready and
+// warmUp returns false.
+func (s *Server) warmUp(rs *RealService) bool {
+	start := time.Now()
+	journal, replayed, err := s.replayMatchJournal(rs)
+	if err != nil {
+		log.Printf("Warm replay failed, not accepting orders: %v", err)
+		s.setWarmState(warmStateFailed, 0)
+		return false
+	}
+	log.Printf("Warm replay restored %d resting orders from %s in %s", replayed, s.config.MatchJournal, time.Since(start))
+
+	go s.runMatchJournal(journal, rs)
+	s.startBackground()
+	s.setWarmState(warmStateReady, replayed)
+	return true
+}
+
+// runMatchJournal appends engine events to the journal every
+// eventPublishInterval, and once more when the server stops
+func (s *Server) runMatchJournal(journal *matchJournal, rs *RealService) {
+	ticker := time.NewTicker(eventPublishInterval)
+	defer ticker.Stop()
+	defer journal.file.Close()
+
+	for {
+		select {
+		case <-s.stopCh:
+			if err := journal.appendFrom(rs); err != nil {
+				log.Printf("Match journal: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := journal.appendFrom(rs); err != nil {
+				log.Printf("Match journal: %v", err)
+			}
+		}
+	}
+}
+
+func (s *Server) setWarmState(state string, replayed int) {
+	s.warmMu.Lock()
+	defer s.warmMu.Unlock()
+	s.warmState = state
+	s.replayedOrders = replayed
+}
+
+// isWarmingUp reports whether order flow must wait for the warm replay. A
+// server that was never started accepts orders.
+func (s *Server) isWarmingUp() bool {
+	s.warmMu.Lock()
+	defer s.warmMu.Unlock()
+	return s.warmState == warmStateReplaying || s.warmState == warmStateFailed
+}
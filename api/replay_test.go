@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cosmossdk.io/log"
+
+	"github.com/openalpha/perp-dex/api/middleware"
+	"github.com/openalpha/perp-dex/api/types"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestWarmReplay tests that resting orders journaled by one service are
+// rebuilt in another with their remaining size and time priority, that new
+// IDs continue past the journal, that the journal is compacted to a snapshot,
+// and that order flow and /ready wait for the replay
+func TestWarmReplay(t *testing.T) {
+	ctx := context.Background()
+	place := func(svc *RealService, trader, side, price, quantity string) *types.PlaceOrderResponse {
+		t.Helper()
+		resp, err := svc.PlaceOrder(ctx, &types.PlaceOrderRequest{
+			MarketID: "BTC-USDC", Trader: trader, Side: side, Type: "limit", Price: price, Quantity: quantity,
+		})
+		if err != nil {
+			t.Fatalf("failed to place order: %v", err)
+		}
+		return resp
+	}
+
+	before, err := NewRealService(log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	first := place(before, "replay-a", "sell", "50000", "1")
+	second := place(before, "replay-b", "sell", "50000", "1")
+	bid := place(before, "replay-c", "buy", "49000", "1")
+	place(before, "replay-d", "buy", "50000", "0.4")
+	if _, err := before.CancelOrder(ctx, "replay-c", bid.Order.OrderID); err != nil {
+		t.Fatalf("failed to cancel: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "match.journal")
+	journal, err := createMatchJournal(path, obtypes.DefaultGenesis())
+	if err != nil {
+		t.Fatalf("failed to create journal: %v", err)
+	}
+	if err := journal.appendFrom(before); err != nil {
+		t.Fatalf("failed to append to journal: %v", err)
+	}
+	journal.file.Close()
+	if bz, _ := os.ReadFile(path); !strings.HasSuffix(string(bz), "\n") {
+		t.Fatal("expected newline-terminated journal records")
+	}
+
+	after, err := NewRealService(log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	s := &Server{config: &Config{MatchJournal: path}}
+	compacted, replayed, err := s.replayMatchJournal(after)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	compacted.file.Close()
+	if replayed != 2 {
+		t.Fatalf("expected 2 resting orders replayed, got %d", replayed)
+	}
+	if bz, _ := os.ReadFile(path); strings.Count(string(bz), "\n") != 1 || !strings.HasPrefix(string(bz), `{"snapshot":`) {
+		t.Errorf("expected the journal compacted to one snapshot line, got %s", bz)
+	}
+
+	// The partially filled first order keeps its priority and remaining 0.6
+	taker := place(after, "replay-e", "buy", "50000", "1")
+	if taker.Match == nil || len(taker.Match.Trades) != 2 {
+		t.Fatalf("expected two fills against the replayed book, got %+v", taker.Match)
+	}
+	if got := taker.Match.Trades[0]; got.MakerOrderID != first.Order.OrderID || got.Quantity != "0.600000000000000000" {
+		t.Errorf("expected 0.6 filled against %s first, got %+v", first.Order.OrderID, got)
+	}
+	if got := taker.Match.Trades[1]; got.MakerOrderID != second.Order.OrderID {
+		t.Errorf("expected %s filled second, got %+v", second.Order.OrderID, got)
+	}
+	for _, id := range []string{first.Order.OrderID, second.Order.OrderID, bid.Order.OrderID} {
+		if taker.Order.OrderID == id {
+			t.Errorf("expected a new order ID past the journal, got %s", id)
+		}
+	}
+
+	// A torn final line is skipped; a malformed line before others is not
+	journaled, _ := os.ReadFile(path)
+	if _, err := readJournal(strings.NewReader(string(journaled) + `{"event":{"Seq"`)); err != nil {
+		t.Errorf("expected a torn final line to be skipped, got %v", err)
+	}
+	if _, err := readJournal(strings.NewReader("{\n" + string(journaled))); err == nil {
+		t.Error("expected a malformed line before the end to fail the replay")
+	}
+
+	// Order flow and readiness wait for the replay
+	s.setWarmState(warmStateReplaying, 0)
+	rec := httptest.NewRecorder()
+	s.handleReady(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), warmStateReplaying) {
+		t.Errorf("expected 503 replaying, got %d %s", rec.Code, rec.Body)
+	}
+	gated := middleware.WarmupMiddleware(s.isWarmingUp)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	rec = httptest.NewRecorder()
+	gated.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/orders", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected new orders rejected while replaying, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	gated.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/v1/orders/order-1", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected cancels served while replaying, got %d", rec.Code)
+	}
+
+	s.setWarmState(warmStateReady, replayed)
+	rec = httptest.NewRecorder()
+	s.handleReady(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"replayed_orders":2`) {
+		t.Errorf("expected 200 ready, got %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/api/handlers"
+	"github.com/openalpha/perp-dex/api/types"
+	riverpooltypes "github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// TestInviteRedemptions tests that private pool deposits redeem invite codes,
+// that the owner can list redemptions and revoke codes, and the holder cap
+func TestInviteRedemptions(t *testing.T) {
+	svc := NewMockRiverpoolService()
+	s := &Server{riverpoolHandler: handlers.NewRiverpoolStandaloneHandler(svc)}
+
+	pool, err := svc.CreateCommunityPool("owner", &types.CommunityPoolParams{Name: "Alpha", IsPrivate: true, MaxHolders: 2})
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	code, err := svc.GenerateInviteCode(pool.PoolID, "owner")
+	if err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+
+	if _, err := svc.Deposit(pool.PoolID, "alice", math.LegacyNewDec(100), ""); !errors.Is(err, riverpooltypes.ErrInvalidInviteCode) {
+		t.Errorf("expected invite code required, got %v", err)
+	}
+	deposit, err := svc.Deposit(pool.PoolID, "alice", math.LegacyNewDec(100), code.Code)
+	if err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+
+	base := "/v1/riverpool/community/" + pool.PoolID + "/invites/" + code.Code
+	req := httptest.NewRequest(http.MethodGet, base+"/redemptions", nil)
+	req.Header.Set("X-Owner-Address", "owner")
+	rec := httptest.NewRecorder()
+	s.handleRiverpoolCommunityRoutes(rec, req)
+	var resp struct {
+		Redemptions []*types.InviteRedemption `json:"redemptions"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusOK || len(resp.Redemptions) != 1 || resp.Redemptions[0].Address != "alice" || resp.Redemptions[0].DepositID != deposit.DepositID {
+		t.Fatalf("expected alice's redemption, got %d: %s", rec.Code, rec.Body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, base+"/redemptions", nil)
+	req.Header.Set("X-Owner-Address", "mallory")
+	rec = httptest.NewRecorder()
+	s.handleRiverpoolCommunityRoutes(rec, req)
+	if rec.Code == http.StatusOK {
+		t.Errorf("expected non-owner to be refused, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleRiverpoolCommunityRoutes(rec, httptest.NewRequest(http.MethodPost, base+"/revoke", strings.NewReader(`{"owner":"owner"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected revoke to succeed, got %d: %s", rec.Code, rec.Body)
+	}
+	if _, err := svc.Deposit(pool.PoolID, "bob", math.LegacyNewDec(100), code.Code); !errors.Is(err, riverpooltypes.ErrInvalidInviteCode) {
+		t.Errorf("expected revoked code, got %v", err)
+	}
+	if _, err := svc.Deposit(pool.PoolID, "alice", math.LegacyNewDec(50), ""); err != nil {
+		t.Errorf("expected member top-up, got %v", err)
+	}
+
+	// The owner fills the second holder slot
+	if _, err := svc.Deposit(pool.PoolID, "owner", math.LegacyNewDec(100), ""); err != nil {
+		t.Fatalf("failed owner deposit: %v", err)
+	}
+	fresh, _ := svc.GenerateInviteCode(pool.PoolID, "owner")
+	_, err = svc.Deposit(pool.PoolID, "carol", math.LegacyNewDec(100), fresh.Code)
+	if apiErr := types.ToAPIError(err, types.ErrCodeInternal); apiErr.Code != types.ErrCodePoolFull {
+		t.Errorf("expected pool_full, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// tradingDepositRequest is the body of POST /v1/riverpool/deposit/trading-account
+type tradingDepositRequest struct {
+	PoolID     string `json:"pool_id"`
+	User       string `json:"user"`
+	Amount     string `json:"amount"`
+	InviteCode string `json:"invite_code,omitempty"`
+}
+
+// tradingClaimRequest is the body of POST /v1/riverpool/withdrawal/claim/trading-account
+type tradingClaimRequest struct {
+	WithdrawalID string `json:"withdrawal_id"`
+	User         string `json:"user"`
+}
+
+// freeCollateral returns the trading account balance that can move into a
+// pool without weakening open positions: the available balance less
+// unrealized losses on cross-margin positions, which draw on that balance.
+func (s *Server) freeCollateral(r *http.Request, trader string) (math.LegacyDec, error) {
+	account, err := s.accountService.GetAccount(r.Context(), trader)
+	if err != nil {
+		return math.LegacyZeroDec(), err
+	}
+	free, err := math.LegacyNewDecFromStr(account.AvailableBalance)
+	if err != nil {
+		return math.LegacyZeroDec(), err
+	}
+
+	positions, err := s.positionService.GetPositions(r.Context(), trader)
+	if err != nil {
+		return math.LegacyZeroDec(), err
+	}
+	for _, pos := range positions {
+		if pos.MarginMode != "cross" {
+			continue
+		}
+		pnl, err := math.LegacyNewDecFromStr(pos.UnrealizedPnl)
+		if err == nil && pnl.IsNegative() {
+			free = free.Add(pnl)
+		}
+	}
+
+	if free.IsNegative() {
+		return math.LegacyZeroDec(), nil
+	}
+	return free, nil
+}
+
+// handleRiverpoolTradingDeposit moves free collateral from the trader's
+// trading account into a pool. The collateral is debited directly rather
+// than withdrawn, and returned to the trading account if the pool deposit
+// fails.
+func (s *Server) handleRiverpoolTradingDeposit(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req tradingDepositRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+		return
+	}
+	if req.User == "" {
+		req.User = r.Header.Get("X-Trader-Address")
+	}
+	if req.PoolID == "" || req.User == "" || req.Amount == "" {
+		writeError(w, types.ErrCodeMissingField, "pool_id, user, and amount are required")
+		return
+	}
+	amount, err := math.LegacyNewDecFromStr(req.Amount)
+	if err != nil || !amount.IsPositive() {
+		writeError(w, types.ErrCodeInvalidQuantity, "amount must be a positive decimal")
+		return
+	}
+
+	debits, ok := s.accountService.(types.CollateralDebitService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Deposits from the trading account are not supported by this service")
+		return
+	}
+	free, err := s.freeCollateral(r, req.User)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	if free.LT(amount) {
+		writeAPIError(w, types.NewAPIError(types.ErrCodeInsufficientMargin,
+			"amount exceeds free collateral in trading account").
+			WithDetail("free_collateral", free.String()))
+		return
+	}
+
+	if _, err := debits.DebitCollateral(r.Context(), req.User, req.Amount); err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+		return
+	}
+	result, err := s.riverpoolService.Deposit(req.PoolID, req.User, amount, req.InviteCode)
+	if err != nil {
+		if _, refundErr := s.accountService.Deposit(r.Context(), &types.DepositRequest{Trader: req.User, Amount: req.Amount}); refundErr != nil {
+			log.Printf("riverpool: failed to refund %s to trading account of %s: %v", req.Amount, req.User, refundErr)
+		}
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+		return
+	}
+
+	account, _ := s.accountService.GetAccount(r.Context(), req.User)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"deposit": result,
+		"account": account,
+	})
+}
+
+// handleRiverpoolTradingClaim claims a withdrawal and credits the amount to
+// the trader's trading account
+func (s *Server) handleRiverpoolTradingClaim(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req tradingClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+		return
+	}
+	if req.User == "" {
+		req.User = r.Header.Get("X-Trader-Address")
+	}
+	if req.WithdrawalID == "" || req.User == "" {
+		writeError(w, types.ErrCodeMissingField, "withdrawal_id and user are required")
+		return
+	}
+
+	claim, err := s.riverpoolService.ClaimWithdrawal(req.WithdrawalID, req.User)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+		return
+	}
+
+	resp, err := s.accountService.Deposit(r.Context(), &types.DepositRequest{Trader: req.User, Amount: claim.Amount})
+	if err != nil {
+		log.Printf("riverpool: claimed withdrawal %s but failed to credit trading account of %s: %v", req.WithdrawalID, req.User, err)
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal).
+			WithDetail("withdrawal_id", req.WithdrawalID).
+			WithDetail("amount", claim.Amount))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"claim":   claim,
+		"account": resp.Account,
+	})
+}
@@ -5,14 +5,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	clog "cosmossdk.io/log"
+	"github.com/openalpha/perp-dex/api/auth"
+	"github.com/openalpha/perp-dex/api/chaintx"
+	"github.com/openalpha/perp-dex/api/cluster"
 	"github.com/openalpha/perp-dex/api/handlers"
 	"github.com/openalpha/perp-dex/api/middleware"
+	"github.com/openalpha/perp-dex/api/mocksim"
+	"github.com/openalpha/perp-dex/api/numfmt"
+	"github.com/openalpha/perp-dex/api/pretrade"
+	"github.com/openalpha/perp-dex/api/profiles"
+	"github.com/openalpha/perp-dex/api/surveillance"
 	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/api/webhook"
 	"github.com/openalpha/perp-dex/api/websocket"
+	"github.com/openalpha/perp-dex/metrics"
+	"github.com/openalpha/perp-dex/pkg/klines"
+	"github.com/openalpha/perp-dex/pkg/validation"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	"google.golang.org/grpc"
 )
 
 // Server represents the API server
@@ -22,23 +41,108 @@ type Server struct {
 	config     *Config
 	mockMode   bool
 
+	// Public market data listener (see public.go); nil unless Config.PublicListenAddr is set
+	publicServer *http.Server
+
 	// Services
 	orderService     types.OrderService
 	positionService  types.PositionService
 	accountService   types.AccountService
 	riverpoolService types.RiverpoolService
+	invariantService types.InvariantService  // nil when the service has no keepers
+	indexService     types.IndexPriceService // Hyperliquid oracle unless a keeper-backed aggregator is wired
+	scheduleService  types.TradingScheduleService
 
 	// Handlers
 	orderHandler     *handlers.OrderHandler
 	positionHandler  *handlers.PositionHandler
 	accountHandler   *handlers.AccountHandler
 	riverpoolHandler *handlers.RiverpoolStandaloneHandler
+	authHandler      *handlers.AuthHandler
+
+	// WebSocket session tokens
+	sessions *auth.SessionManager
+
+	// Account webhook subscriptions and delivery worker
+	webhooks *webhook.Dispatcher
+
+	// Dev faucet; nil when disabled
+	faucet *faucet
+
+	// Periodic account equity snapshots; nil when disabled
+	equityHistory *equityHistory
+
+	// Market funding, open interest and volume history; nil when disabled
+	marketHistory *marketHistory
+
+	// Margin call monitor; nil when disabled
+	marginCalls *marginCalls
+
+	// Account data export jobs (see account_export.go)
+	accountExports *accountExports
+
+	// Daily account reports; nil when disabled (see account_report.go)
+	accountReports *accountReports
+
+	// Order flow surveillance fed by the event log (see surveillance.go)
+	surveillance *surveillance.Monitor
+
+	// Gateway pre-trade risk checks on order entry (see pretrade.go)
+	preTrade *pretrade.Checker
+
+	// Append-only log of protocol parameter changes (see protocol_params.go)
+	paramAudit *paramAudit
+
+	// Trader nicknames shown in leaderboards and pool lists (see profiles.go)
+	profiles *profiles.Registry
+
+	// Market-by-order feed derived from the event log (see l3.go)
+	l3 *l3Feed
 
 	// Rate limiter
 	rateLimiter *middleware.RateLimiter
 
+	// Chaos testing; nil unless Config.FaultInjection is set
+	faultInjector *middleware.FaultInjector
+
+	// Request audit log, set by Start when Config.AuditLog is
+	auditLog *middleware.AuditLogger
+
 	// Oracle for real-time prices (Hyperliquid)
 	oracle *HyperliquidOracle
+
+	// Shared matcher read model; nil serves market data from the oracle
+	marketData types.MarketDataService
+
+	// Candles behind the TradingView datafeed; nil reads them from the oracle
+	klines klineProvider
+
+	// Persistent kline recording; nil when Config.Klines is unset
+	klineStore *klineStore
+
+	// Cluster mode (see NewServerWithMatcher and Config.MatcherListenAddr)
+	matcherRPC    *grpc.Server
+	matcherClient *cluster.MatcherClient
+
+	// Isolated environments served by this process (see namespace.go); set before Start
+	namespaces map[string]*Server
+
+	// Drain state (see drain.go)
+	drainMu   sync.Mutex
+	draining  bool
+	stopCh    chan struct{} // closed to stop background broadcasters
+	drainDone chan struct{} // closed once a drain has finished
+
+	// Warm replay state behind /ready (see replay.go)
+	warmMu         sync.Mutex
+	warmState      string
+	replayedOrders int
+
+	// Dependency probe state behind /ready and /live (see probes.go)
+	probeMu          sync.Mutex
+	eventBusPolledAt time.Time // last successful event publisher poll
+	eventBusErr      error     // last event publisher poll error
+	startedAt        time.Time
 }
 
 // Config contains server configuration
@@ -49,6 +153,115 @@ type Config struct {
 	WriteTimeout     time.Duration
 	MockMode         bool
 	DisableRateLimit bool // For testing purposes
+
+	// Mock mode market simulation (see mock_sim.go); nil runs mocksim.DefaultScript
+	MockScript *mocksim.Script
+
+	// Drain settings
+	DrainTimeout     time.Duration // Max time to wait for in-flight requests and WS broadcasts
+	DrainGracePeriod time.Duration // Time to keep serving after /health turns 503, so the load balancer stops routing first
+	CancelOnDrain    bool          // Cancel resting orders of cancel-on-disconnect sessions when draining
+	AdminToken       string        // Required X-Admin-Token for /v1/admin/*; empty allows loopback only
+
+	// Cluster settings
+	MatcherListenAddr string // Real mode: expose the matcher over gRPC on this address (e.g. ":9095")
+	MatcherAddr       string // Stateless mode: address of the shared matcher node
+	MatcherToken      string // Shared token every matcher call carries; required in both modes
+
+	// WebSocket session auth
+	SessionSecret string            // HMAC key for session tokens; share across nodes. Empty uses a per-process key
+	SessionTTL    time.Duration     // Session token lifetime; clients refresh in-band
+	APIKeys       map[string]string // API key -> trader address for key-based login
+	DataLicenses  []string          // Traders licensed for the L3 (market-by-order) feed, see l3.go
+
+	// Account webhooks
+	WebhookAllowInsecure bool // Accept http:// and private callback URLs; development only
+
+	// Dev faucet (see faucet.go)
+	FaucetAmount         string        // Test USDC granted by POST /v1/faucet; empty disables the faucet
+	FaucetCooldown       time.Duration // Time between grants to the same address or IP
+	FaucetTrustedProxies []string      // IPs or CIDRs of proxies whose X-Forwarded-For is believed; none limits the connection's IP
+
+	// WebSocket hub limits, liveness and slow consumer policy; nil uses websocket.DefaultHubConfig()
+	WebSocket *websocket.HubConfig
+
+	// Public market data mirror (see public.go)
+	PublicListenAddr        string // Serve read-only, cacheable market data on this address (e.g. ":8081"); empty disables
+	PublicRequestsPerSecond int    // Per-IP rate limit on the public listener; 0 uses the default
+
+	// Account equity history (see equity_history.go)
+	EquitySnapshotInterval time.Duration // Time between equity snapshots of every account; 0 uses the default, negative disables
+	EquityHistoryRetention int           // Snapshots kept per trader; 0 uses the default
+
+	// Market funding, open interest and volume history (see market_history.go)
+	MarketStatsInterval  time.Duration // Time between funding rate and open interest samples of every market; 0 uses the default, negative disables
+	MarketStatsRetention time.Duration // How long samples and volume are kept; 0 uses the default
+
+	// Margin calls (see margin_call.go)
+	MarginCallInterval time.Duration // Time between margin checks of every account; 0 uses the default, negative disables
+	MarginCallLevels   []float64     // Maintenance margin / equity ratios that trigger a warning; empty uses DefaultMarginCallLevels
+	MarginCallRepeat   time.Duration // Minimum time between repeated warnings at the same level; 0 uses the default
+
+	// Chaos testing: latency, 5xx errors, truncated JSON and dropped WebSocket
+	// messages injected per route (see middleware.ParseFaultRules). Never enable in production.
+	FaultInjection []middleware.FaultRule
+
+	// Request audit log for incident forensics (see middleware.AuditMiddleware):
+	// sampled requests are appended to this JSON lines file, rotated by size;
+	// empty disables
+	AuditLog         string
+	AuditLogMaxSize  int64                  // Bytes per file; 0 uses middleware.DefaultAuditLogMaxSize
+	AuditLogMaxFiles int                    // Rotated files kept; 0 uses middleware.DefaultAuditLogMaxFiles
+	AuditSampling    []middleware.AuditRule // nil uses middleware.DefaultAuditSampling
+
+	// Account data exports (see account_export.go)
+	AccountExportTTL time.Duration // How long a finished export can be downloaded; 0 uses the default
+
+	// Daily account reports (see account_report.go)
+	ReportCheckInterval time.Duration // Time between checks for a closed UTC day to report; 0 uses the default, negative disables
+	ReportRetention     int           // Daily reports kept per trader; 0 uses the default
+
+	// Persistent klines (see kline_store.go): real mode records engine trades
+	// into this store and charts from it; nil keeps the keeper or oracle candles
+	Klines               klines.Store
+	KlineRetention       klines.Policy // Zero durations keep candles forever; see klines.DefaultPolicy
+	KlineCompactInterval time.Duration // Time between downsampling and retention runs; 0 uses the default
+
+	// Order flow surveillance thresholds (see surveillance.go); nil uses surveillance.DefaultConfig()
+	Surveillance *surveillance.Config
+
+	// Pre-trade risk limits on order entry (see pretrade.go); nil disables every check until set
+	PreTrade *pretrade.Config
+
+	// L3 updates kept for GET /v1/l3/events; 0 uses DefaultL3Retention
+	L3Retention int
+
+	// Real mode: journal engine events to this file and, on start, rebuild
+	// the books from it before accepting orders (see replay.go); empty disables
+	MatchJournal string
+
+	// Oldest oracle price /ready accepts; 0 uses DefaultOracleMaxAge, negative skips the oracle check
+	ReadyOracleMaxAge time.Duration
+
+	// Append protocol parameter changes to this JSON lines file and reload
+	// them on start (see param_audit.go); empty keeps the audit log in memory
+	ParamAuditLog string
+
+	// Save trader profiles to this JSON file and load them on start (see
+	// profiles.go); empty keeps them in memory
+	ProfileStore string
+
+	// Decimal representation of REST and WebSocket payloads for requests
+	// that ask for none (see number_format.go); empty is numfmt.FormatRaw
+	NumberFormat numfmt.Format
+
+	// Chain node that POST /v1/tx simulates and broadcasts signed
+	// transactions through (see chain_tx.go); nil disables the endpoints
+	ChainTx *chaintx.Client
+
+	// Builds, signs and tracks order transactions for /v1/tx/orders (see
+	// chain_tx.go); nil disables the endpoints
+	TxService *chaintx.TxService
 }
 
 // DefaultConfig returns default configuration
@@ -61,6 +274,7 @@ func DefaultConfig() *Config {
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		MockMode:     false, // Default to REAL mode - use --mock for development
+		DrainTimeout: 30 * time.Second,
 	}
 }
 
@@ -72,6 +286,9 @@ func NewServer(config *Config) *Server {
 
 	wsConfig := websocket.DefaultServerConfig()
 	wsConfig.Port = config.Port
+	if config.WebSocket != nil {
+		wsConfig.HubConfig = config.WebSocket
+	}
 
 	// Create mock service (default for now)
 	mockService := NewMockService()
@@ -82,8 +299,13 @@ func NewServer(config *Config) *Server {
 	// Create rate limiter
 	rateLimiter := middleware.NewRateLimiter(middleware.DefaultRateLimitConfig())
 
-	// Create Hyperliquid Oracle for real-time prices
+	// Create Hyperliquid Oracle for real-time prices; mock mode simulates
+	// its markets instead
 	oracle := NewHyperliquidOracle()
+	if config.MockMode {
+		oracle.sim = newMockSimulator(config)
+		mockService.sim = oracle.sim
+	}
 
 	s := &Server{
 		config:           config,
@@ -95,13 +317,39 @@ func NewServer(config *Config) *Server {
 		riverpoolService: riverpoolService,
 		rateLimiter:      rateLimiter,
 		oracle:           oracle,
+		indexService:     oracle,
+		scheduleService:  newTradingScheduleStore(),
+		sessions:         newSessionManager(config),
+		webhooks:         newWebhookDispatcher(config),
+		faucet:           newFaucet(config),
+		equityHistory:    newEquityHistory(config),
+		marketHistory:    newMarketHistory(config),
+		marginCalls:      newMarginCalls(config),
+		accountExports:   newAccountExports(config),
+		accountReports:   newAccountReports(config),
+		surveillance:     newSurveillance(config),
+		preTrade:         newPreTrade(config),
+		paramAudit:       newParamAudit(config),
+		profiles:         newProfiles(config),
+		l3:               newL3Feed(config.L3Retention),
+		stopCh:           make(chan struct{}),
+		drainDone:        make(chan struct{}),
 	}
 
 	// Create handlers
-	s.orderHandler = handlers.NewOrderHandler(s.orderService)
+	s.orderHandler = handlers.NewOrderHandler(s.orderService).
+		WithMarketRules(defaultMarketRules()).
+		WithTradingSchedule(s.scheduleService).
+		WithAccountEvents(s.webhooks).
+		WithSignedIntents(auth.NewIntentVerifier()).
+		WithCredentialObserver(s.surveillance).
+		WithPreTradeChecks(s.preTrade).
+		WithRejectObserver(s)
 	s.positionHandler = handlers.NewPositionHandler(s.positionService)
-	s.accountHandler = handlers.NewAccountHandler(s.accountService)
-	s.riverpoolHandler = handlers.NewRiverpoolStandaloneHandler(s.riverpoolService)
+	s.accountHandler = handlers.NewAccountHandler(s.accountService).WithAccountEvents(s.webhooks)
+	s.riverpoolHandler = handlers.NewRiverpoolStandaloneHandler(withProfiles(s.riverpoolService, s.profiles))
+	s.authHandler = handlers.NewAuthHandler(s.sessions)
+	s.wsServer.GetHub().SetSessionManager(s.sessions)
 
 	return s
 }
@@ -114,6 +362,9 @@ func NewServerWithServices(config *Config, orderSvc types.OrderService, position
 
 	wsConfig := websocket.DefaultServerConfig()
 	wsConfig.Port = config.Port
+	if config.WebSocket != nil {
+		wsConfig.HubConfig = config.WebSocket
+	}
 
 	// Create rate limiter
 	rateLimiter := middleware.NewRateLimiter(middleware.DefaultRateLimitConfig())
@@ -134,13 +385,40 @@ func NewServerWithServices(config *Config, orderSvc types.OrderService, position
 		riverpoolService: riverpoolService,
 		rateLimiter:      rateLimiter,
 		oracle:           oracle,
+		indexService:     oracle,
+		scheduleService:  newTradingScheduleStore(),
+		sessions:         newSessionManager(config),
+		webhooks:         newWebhookDispatcher(config),
+		faucet:           newFaucet(config),
+		equityHistory:    newEquityHistory(config),
+		marketHistory:    newMarketHistory(config),
+		marginCalls:      newMarginCalls(config),
+		accountExports:   newAccountExports(config),
+		accountReports:   newAccountReports(config),
+		surveillance:     newSurveillance(config),
+		preTrade:         newPreTrade(config),
+		paramAudit:       newParamAudit(config),
+		profiles:         newProfiles(config),
+		l3:               newL3Feed(config.L3Retention),
+		stopCh:           make(chan struct{}),
+		drainDone:        make(chan struct{}),
 	}
+	s.klines = keeperKlines(orderSvc)
 
 	// Create handlers
-	s.orderHandler = handlers.NewOrderHandler(s.orderService)
+	s.orderHandler = handlers.NewOrderHandler(s.orderService).
+		WithMarketRules(defaultMarketRules()).
+		WithTradingSchedule(s.scheduleService).
+		WithAccountEvents(s.webhooks).
+		WithSignedIntents(auth.NewIntentVerifier()).
+		WithCredentialObserver(s.surveillance).
+		WithPreTradeChecks(s.preTrade).
+		WithRejectObserver(s)
 	s.positionHandler = handlers.NewPositionHandler(s.positionService)
-	s.accountHandler = handlers.NewAccountHandler(s.accountService)
-	s.riverpoolHandler = handlers.NewRiverpoolStandaloneHandler(s.riverpoolService)
+	s.accountHandler = handlers.NewAccountHandler(s.accountService).WithAccountEvents(s.webhooks)
+	s.riverpoolHandler = handlers.NewRiverpoolStandaloneHandler(withProfiles(s.riverpoolService, s.profiles))
+	s.authHandler = handlers.NewAuthHandler(s.sessions)
+	s.wsServer.GetHub().SetSessionManager(s.sessions)
 
 	return s
 }
@@ -151,6 +429,12 @@ func NewServerWithRealService(config *Config) (*Server, error) {
 	if config == nil {
 		config = DefaultConfig()
 	}
+	return newRealServer(config, NewHyperliquidOracle())
+}
+
+// newRealServer creates a real-mode server with its own in-memory keepers,
+// reading prices from oracle
+func newRealServer(config *Config, oracle *HyperliquidOracle) (*Server, error) {
 	config.MockMode = false
 
 	// Create real service with in-memory store
@@ -162,15 +446,18 @@ func NewServerWithRealService(config *Config) (*Server, error) {
 
 	wsConfig := websocket.DefaultServerConfig()
 	wsConfig.Port = config.Port
+	if config.WebSocket != nil {
+		wsConfig.HubConfig = config.WebSocket
+	}
 
 	// Create rate limiter
 	rateLimiter := middleware.NewRateLimiter(middleware.DefaultRateLimitConfig())
 
-	// Create riverpool mock service
-	riverpoolService := NewMockRiverpoolService()
-
-	// Create Hyperliquid Oracle for real-time prices
-	oracle := NewHyperliquidOracle()
+	// Create riverpool service backed by the real keeper
+	riverpoolService, err := NewKeeperRiverpoolService(logger, oracle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create riverpool service: %w", err)
+	}
 
 	s := &Server{
 		config:           config,
@@ -179,27 +466,238 @@ func NewServerWithRealService(config *Config) (*Server, error) {
 		orderService:     realService,
 		positionService:  realService,
 		accountService:   realService,
+		invariantService: realService,
 		riverpoolService: riverpoolService,
 		rateLimiter:      rateLimiter,
 		oracle:           oracle,
+		indexService:     oracle,
+		scheduleService:  realService,
+		sessions:         newSessionManager(config),
+		webhooks:         newWebhookDispatcher(config),
+		faucet:           newFaucet(config),
+		equityHistory:    newEquityHistory(config),
+		marketHistory:    newMarketHistory(config),
+		marginCalls:      newMarginCalls(config),
+		accountExports:   newAccountExports(config),
+		accountReports:   newAccountReports(config),
+		surveillance:     newSurveillance(config),
+		preTrade:         newPreTrade(config),
+		paramAudit:       newParamAudit(config),
+		profiles:         newProfiles(config),
+		l3:               newL3Feed(config.L3Retention),
+		stopCh:           make(chan struct{}),
+		drainDone:        make(chan struct{}),
+	}
+
+	s.klines = keeperKlines(realService)
+	if s.klineStore = newKlineStore(config); s.klineStore != nil {
+		s.klines = s.klineStore
+	}
+
+	// Expose the matcher to stateless API nodes; serve the same read model locally
+	if config.MatcherListenAddr != "" {
+		s.marketData = realService
+		matcher := cluster.NewMatcherServer(realService, realService, realService, realService).
+			WithTradingSchedule(realService).
+			WithRiverpool(riverpoolService)
+		if s.matcherRPC, err = cluster.NewGRPCServer(matcher, config.MatcherToken); err != nil {
+			return nil, err
+		}
 	}
 
 	// Create handlers
-	s.orderHandler = handlers.NewOrderHandler(s.orderService)
+	s.orderHandler = handlers.NewOrderHandler(s.orderService).
+		WithMarketRules(defaultMarketRules()).
+		WithTradingSchedule(s.scheduleService).
+		WithAccountEvents(s.webhooks).
+		WithSignedIntents(auth.NewIntentVerifier()).
+		WithCredentialObserver(s.surveillance).
+		WithPreTradeChecks(s.preTrade).
+		WithRejectObserver(s)
 	s.positionHandler = handlers.NewPositionHandler(s.positionService)
-	s.accountHandler = handlers.NewAccountHandler(s.accountService)
-	s.riverpoolHandler = handlers.NewRiverpoolStandaloneHandler(s.riverpoolService)
+	s.accountHandler = handlers.NewAccountHandler(s.accountService).WithAccountEvents(s.webhooks)
+	s.riverpoolHandler = handlers.NewRiverpoolStandaloneHandler(withProfiles(s.riverpoolService, s.profiles))
+	s.authHandler = handlers.NewAuthHandler(s.sessions)
+	s.wsServer.GetHub().SetSessionManager(s.sessions)
+
+	return s, nil
+}
+
+// NewServerWithMatcher creates a stateless API node that routes order flow,
+// account operations, riverpool calls and market data reads to a shared
+// matcher node.
+// Any number of these can run behind a load balancer.
+func NewServerWithMatcher(config *Config) (*Server, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if config.MatcherAddr == "" {
+		return nil, fmt.Errorf("matcher address is required for stateless mode")
+	}
+	config.MockMode = false
+
+	clientConfig := cluster.DefaultClientConfig()
+	clientConfig.MatcherAddr = config.MatcherAddr
+	clientConfig.Token = config.MatcherToken
+	matcherClient, err := cluster.NewMatcherClient(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	wsConfig := websocket.DefaultServerConfig()
+	wsConfig.Port = config.Port
+	if config.WebSocket != nil {
+		wsConfig.HubConfig = config.WebSocket
+	}
+
+	oracle := NewHyperliquidOracle()
+
+	s := &Server{
+		config:           config,
+		wsServer:         websocket.NewServer(wsConfig),
+		mockMode:         false,
+		orderService:     matcherClient,
+		positionService:  matcherClient,
+		accountService:   matcherClient,
+		riverpoolService: matcherClient.Riverpool(),
+		rateLimiter:      middleware.NewRateLimiter(middleware.DefaultRateLimitConfig()),
+		oracle:           oracle,
+		indexService:     oracle,
+		scheduleService:  matcherClient,
+		marketData:       matcherClient,
+		matcherClient:    matcherClient,
+		sessions:         newSessionManager(config),
+		webhooks:         newWebhookDispatcher(config),
+		faucet:           newFaucet(config),
+		equityHistory:    newEquityHistory(config),
+		marketHistory:    newMarketHistory(config),
+		marginCalls:      newMarginCalls(config),
+		accountExports:   newAccountExports(config),
+		accountReports:   newAccountReports(config),
+		surveillance:     newSurveillance(config),
+		preTrade:         newPreTrade(config),
+		paramAudit:       newParamAudit(config),
+		profiles:         newProfiles(config),
+		l3:               newL3Feed(config.L3Retention),
+		stopCh:           make(chan struct{}),
+		drainDone:        make(chan struct{}),
+	}
+
+	// Create handlers
+	s.orderHandler = handlers.NewOrderHandler(s.orderService).
+		WithMarketRules(defaultMarketRules()).
+		WithTradingSchedule(s.scheduleService).
+		WithAccountEvents(s.webhooks).
+		WithSignedIntents(auth.NewIntentVerifier()).
+		WithCredentialObserver(s.surveillance).
+		WithPreTradeChecks(s.preTrade).
+		WithRejectObserver(s)
+	s.positionHandler = handlers.NewPositionHandler(s.positionService)
+	s.accountHandler = handlers.NewAccountHandler(s.accountService).WithAccountEvents(s.webhooks)
+	s.riverpoolHandler = handlers.NewRiverpoolStandaloneHandler(withProfiles(s.riverpoolService, s.profiles))
+	s.authHandler = handlers.NewAuthHandler(s.sessions)
+	s.wsServer.GetHub().SetSessionManager(s.sessions)
 
 	return s, nil
 }
 
 // Start starts the API server
 func (s *Server) Start() error {
+	s.startedAt = time.Now()
+	handler := s.handler()
+	if len(s.namespaces) > 0 {
+		handler = s.namespaceHandler(handler)
+	}
+	if s.config.AuditLog != "" {
+		if err := s.startAuditLog(); err != nil {
+			return err
+		}
+		handler = middleware.AuditMiddleware(s.auditLog)(handler)
+	}
+
+	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
+	s.httpServer = &http.Server{
+		Addr:         addr,
+		Handler:      handler,
+		ReadTimeout:  s.config.ReadTimeout,
+		WriteTimeout: s.config.WriteTimeout,
+	}
+
+	// Start the public market data mirror for CDN fronting
+	if s.config.PublicListenAddr != "" {
+		s.startPublicServer()
+	}
+
+	// Start matcher RPC for stateless API nodes, once any warm replay is done
+	var matcherLis net.Listener
+	if s.matcherRPC != nil {
+		lis, err := net.Listen("tcp", s.config.MatcherListenAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen for matcher RPC: %w", err)
+		}
+		matcherLis = lis
+	}
+	serveMatcher := func() {
+		if matcherLis == nil {
+			return
+		}
+		go func() {
+			if err := s.matcherRPC.Serve(matcherLis); err != nil {
+				log.Printf("Matcher RPC error: %v", err)
+			}
+		}()
+		log.Printf("Matcher RPC listening on %s", s.config.MatcherListenAddr)
+	}
+	if s.matcherClient != nil {
+		log.Printf("Stateless mode: routing orders to matcher at %s", s.config.MatcherAddr)
+	}
+
+	// Rebuild the books from the match journal while the listener serves
+	// /ready; order flow is rejected until the replay completes
+	if s.config.MatchJournal != "" {
+		rs, ok := s.orderService.(*RealService)
+		if !ok {
+			return fmt.Errorf("a match journal requires real mode")
+		}
+		s.setWarmState(warmStateReplaying, 0)
+		go func() {
+			if s.warmUp(rs) {
+				serveMatcher()
+			}
+		}()
+	} else {
+		serveMatcher()
+		s.startBackground()
+		s.setWarmState(warmStateReady, 0)
+	}
+	for name, ns := range s.namespaces {
+		ns.startBackground()
+		log.Printf("Namespace %q served under %s%s/ and %s: %s", name, namespacePathPrefix, name, NamespaceHeader, name)
+	}
+
+	log.Printf("API server starting on %s (mock mode: %v)", addr, s.mockMode)
+	log.Printf("Using Hyperliquid Oracle for real-time prices")
+	log.Printf("New endpoints enabled: /v1/orders, /v1/positions, /v1/account")
+	if s.config.DisableRateLimit {
+		log.Printf("Rate limiting DISABLED (for testing)")
+	} else {
+		log.Printf("Rate limiting enabled: %d req/s per IP", 100)
+	}
+	return s.httpServer.ListenAndServe()
+}
+
+// handler builds the server's routes behind its middleware chain
+func (s *Server) handler() http.Handler {
 	mux := http.NewServeMux()
 
 	// Health check (support both /health and /v1/health for compatibility)
 	mux.HandleFunc("/health", s.handleHealth)
 	mux.HandleFunc("/v1/health", s.handleHealth)
+	mux.HandleFunc("/ready", s.handleReady)
+	mux.HandleFunc("/live", s.handleLive)
+
+	// Prometheus metrics, including per-stage order latency
+	mux.Handle("/metrics", metrics.Handler())
 
 	// Market endpoints (read-only)
 	mux.HandleFunc("/v1/markets", s.handleMarkets)
@@ -207,16 +705,37 @@ func (s *Server) Start() error {
 
 	// Account endpoints (legacy read-only)
 	mux.HandleFunc("/v1/accounts/", s.handleAccountLegacy)
+	mux.HandleFunc("/v1/accounts/batch-query", s.handleAccountBatchQuery)
+	mux.HandleFunc("/v1/portfolio", s.handleWatchPortfolio)
 
 	// Tickers
 	mux.HandleFunc("/v1/tickers", s.handleTickers)
+	mux.HandleFunc("/v1/snapshot", s.handleSnapshot)
+	mux.HandleFunc("/v1/orderbooks", s.handleOrderBooks)
+
+	// TradingView UDF datafeed
+	mux.HandleFunc("/v1/tv/config", s.handleTVConfig)
+	mux.HandleFunc("/v1/tv/symbols", s.handleTVSymbols)
+	mux.HandleFunc("/v1/tv/history", s.handleTVHistory)
 
 	// === NEW ENDPOINTS ===
 
-	// Order endpoints (POST, GET, PUT, DELETE)
-	mux.HandleFunc("/v1/orders", s.orderHandler.HandleOrders)
+	// Order endpoints (POST, GET, PUT, DELETE); placement is timed per stage
+	timed := middleware.TimingMiddleware(metrics.GetCollector().RecordOrderStageLatency)
+	mux.Handle("/v1/orders", timed(http.HandlerFunc(s.orderHandler.HandleOrders)))
+	mux.Handle("/v1/orders/signed", timed(http.HandlerFunc(s.orderHandler.HandleSignedOrder)))
 	mux.HandleFunc("/v1/orders/", s.orderHandler.HandleOrder)
 
+	// Spread instruments and their orders
+	mux.HandleFunc("/v1/spreads", s.handleSpreads)
+	mux.HandleFunc("/v1/spreads/", s.handleSpread)
+	mux.HandleFunc("/v1/spread-orders", s.handleSpreadOrders)
+	mux.HandleFunc("/v1/spread-orders/", s.handleSpreadOrder)
+
+	// Sequenced order and trade events across all markets
+	mux.HandleFunc("/v1/events", s.handleEvents)
+	mux.HandleFunc("/v1/l3/events", s.handleL3Events)
+
 	// Position endpoints (GET, POST close)
 	mux.HandleFunc("/v1/positions", s.positionHandler.HandlePositions)
 	mux.HandleFunc("/v1/positions/close", s.positionHandler.HandleClosePosition)
@@ -226,9 +745,55 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/v1/account", s.accountHandler.HandleAccount)
 	mux.HandleFunc("/v1/account/deposit", s.accountHandler.HandleDeposit)
 	mux.HandleFunc("/v1/account/withdraw", s.accountHandler.HandleWithdraw)
+	mux.HandleFunc("/v1/account/transfer", s.handleInternalTransfer)
+	mux.HandleFunc("/v1/account/transfers", s.handleTransfers)
+	mux.HandleFunc("/v1/account/webhooks", s.handleWebhooks)
+	mux.HandleFunc("/v1/account/webhooks/", s.handleWebhook)
+	mux.HandleFunc("/v1/account/mmp", s.handleMMP)
+	mux.HandleFunc("/v1/account/mmp/reset", s.handleMMPReset)
+	mux.HandleFunc("/v1/account/fee-preference", s.handleFeePreference)
+	mux.HandleFunc("/v1/account/maker-points", s.handleMakerPoints)
+	mux.HandleFunc("/v1/account/margin-calls", s.handleMarginCalls)
+	mux.HandleFunc("/v1/account/portfolio", s.handlePortfolio)
+	mux.HandleFunc("/v1/account/portfolio-margin", s.handlePortfolioMargin)
+	mux.HandleFunc("/v1/account/withdrawal-security", s.handleWithdrawalSecurity)
+	mux.HandleFunc("/v1/account/withdrawal-addresses", s.handleWithdrawalAddresses)
+	mux.HandleFunc("/v1/account/withdrawal-addresses/", s.handleWithdrawalAddress)
+	mux.HandleFunc("/v1/account/withdrawals", s.handleWithdrawals)
+	mux.HandleFunc("/v1/account/withdrawals/", s.handleWithdrawalCancel)
+	mux.HandleFunc("/v1/account/export", s.handleAccountExport)
+	mux.HandleFunc("/v1/account/export/", s.handleAccountExportJob)
+	mux.HandleFunc("/v1/account/reports", s.handleAccountReports)
+	mux.HandleFunc("/v1/account/reports/", s.handleAccountReport)
+	mux.HandleFunc("/v1/account/profile", s.handleAccountProfile)
+
+	// LP quoting obligation uptime
+	mux.HandleFunc("/v1/lp/uptime", s.handleLPUptime)
+
+	// Maker points leaderboard
+	mux.HandleFunc("/v1/maker-points", s.handleMakerPointsLeaderboard)
+
+	// Public trader profiles
+	mux.HandleFunc("/v1/profiles/", s.handleProfile)
+
+	// Protocol treasury balance and ledger
+	mux.HandleFunc("/v1/treasury", s.handleTreasury)
+	mux.HandleFunc("/v1/treasury/history", s.handleTreasuryHistory)
+
+	// Dev faucet
+	mux.HandleFunc("/v1/faucet", s.handleFaucet)
+
+	// Signed chain transactions, simulated before broadcast
+	mux.HandleFunc("/v1/tx", s.handleTx)
+	mux.HandleFunc("/v1/tx/simulate", s.handleTxSimulate)
+	mux.HandleFunc("/v1/tx/orders", s.handleTxOrder)
+	mux.HandleFunc("/v1/tx/orders/cancel", s.handleTxCancel)
+	mux.HandleFunc("/v1/tx/signed", s.handleTxSigned)
+	mux.HandleFunc("/v1/tx/", s.handleTxLookup)
 
 	// WebSocket
 	mux.HandleFunc("/ws", s.wsServer.GetHub().ServeWS)
+	mux.HandleFunc("/v1/auth/ws-token", s.authHandler.HandleWSToken)
 
 	// === RIVERPOOL ENDPOINTS ===
 	// Pool listing and details
@@ -241,6 +806,10 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/v1/riverpool/withdrawal/claim", s.riverpoolHandler.ClaimWithdrawal)
 	mux.HandleFunc("/v1/riverpool/withdrawals/pending", s.riverpoolHandler.GetPendingWithdrawals)
 
+	// One-click transfers between the trading account and pools
+	mux.HandleFunc("/v1/riverpool/deposit/trading-account", s.handleRiverpoolTradingDeposit)
+	mux.HandleFunc("/v1/riverpool/withdrawal/claim/trading-account", s.handleRiverpoolTradingClaim)
+
 	// User-specific endpoints
 	mux.HandleFunc("/v1/riverpool/user/", s.handleRiverpoolUserRoutes)
 
@@ -248,45 +817,140 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/v1/riverpool/community/create", s.riverpoolHandler.CreateCommunityPool)
 	mux.HandleFunc("/v1/riverpool/community/", s.handleRiverpoolCommunityRoutes)
 
-	// Apply middleware chain: CORS -> RateLimit -> Handler
-	var handler http.Handler
+	// Admin endpoints
+	mux.HandleFunc("/v1/admin/drain", s.handleDrain)
+	mux.HandleFunc("/v1/admin/invariants/run", s.handleRunInvariants)
+	mux.HandleFunc("/v1/admin/markets/", s.handleAdminMarket)
+	mux.HandleFunc("/v1/admin/lp/obligations", s.handleAdminLPObligations)
+	mux.HandleFunc("/v1/admin/order-limits", s.handleAdminOrderLimits)
+	mux.HandleFunc("/v1/admin/margin-recheck", s.handleAdminMarginRecheck)
+	mux.HandleFunc("/v1/admin/margin-recheck/run", s.handleAdminMarginRecheckRun)
+	mux.HandleFunc("/v1/admin/pretrade-limits", s.handleAdminPreTradeLimits)
+	mux.HandleFunc("/v1/admin/spreads", s.handleAdminSpreads)
+	mux.HandleFunc("/v1/admin/fees/daily", s.handleAdminFeesDaily)
+	mux.HandleFunc("/v1/admin/maker-rebates", s.handleAdminMakerRebates)
+	mux.HandleFunc("/v1/admin/surveillance/alerts", s.handleAdminSurveillanceAlerts)
+	mux.HandleFunc("/v1/admin/surveillance/alerts/", s.handleAdminSurveillanceAlert)
+	mux.HandleFunc("/v1/admin/surveillance/scores", s.handleAdminSurveillanceScores)
+	mux.HandleFunc("/v1/admin/params", s.handleAdminParams)
+	mux.HandleFunc("/v1/admin/params/markets/", s.handleAdminMarketParams)
+	mux.HandleFunc("/v1/admin/params/rate-limits", s.handleAdminRateLimits)
+	mux.HandleFunc("/v1/admin/params/audit", s.handleAdminParamAudit)
+	mux.HandleFunc("/v1/admin/accounts/", s.handleAdminAccounts)
+	mux.HandleFunc("/v1/admin/profiles", s.handleAdminProfiles)
+	mux.HandleFunc("/v1/admin/profiles/", s.handleAdminProfile)
+
+	// Apply middleware chain: RequestID -> Fault -> CORS -> RateLimit -> Drain -> Warm-up -> Handler
+	var handler http.Handler = middleware.DrainMiddleware(s.IsDraining)(
+		middleware.WarmupMiddleware(s.isWarmingUp)(s.numberFormatMiddleware(true)(mux)),
+	)
 	if s.config.DisableRateLimit {
-		handler = corsMiddleware(mux)
+		handler = corsMiddleware(handler)
 	} else {
 		handler = corsMiddleware(
-			middleware.RateLimitMiddleware(s.rateLimiter)(mux),
+			middleware.RateLimitMiddleware(s.rateLimiter)(handler),
 		)
 	}
-
-	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
-	s.httpServer = &http.Server{
-		Addr:         addr,
-		Handler:      handler,
-		ReadTimeout:  s.config.ReadTimeout,
-		WriteTimeout: s.config.WriteTimeout,
+	if len(s.config.FaultInjection) > 0 {
+		s.faultInjector = middleware.NewFaultInjector(s.config.FaultInjection)
+		s.wsServer.GetHub().SetFaultDrop(s.faultInjector.DropWSMessage)
+		handler = middleware.FaultMiddleware(s.faultInjector)(handler)
 	}
+	return middleware.RequestIDMiddleware(handler)
+}
 
-	// Start WebSocket hub
+// startBackground starts the WebSocket hub, account webhook delivery and the
+// broadcasters feeding WebSocket subscribers
+func (s *Server) startBackground() {
+	// Start WebSocket hub; subscribers it cannot replay to get a snapshot
+	s.wsServer.GetHub().SetSnapshotProvider(s.wsChannelSnapshot)
+	s.wsServer.GetHub().SetNumberFormat(s.config.NumberFormat, s.marketDecimals())
 	go s.wsServer.GetHub().Run()
 
+	// Start account webhook delivery and the position/funding event watcher
+	go s.webhooks.Run(s.stopCh)
+	go s.startAccountEventWatcher()
+
+	// Push ADL queue indicator changes to position owners
+	go s.startADLIndicatorBroadcaster()
+	go s.startLongShortBroadcaster()
+
+	// Snapshot account equity for the equity history endpoint
+	go s.startEquitySnapshotter()
+	go s.startMarketStatsSampler()
+
+	// Warn traders approaching liquidation
+	go s.startMarginCallMonitor()
+
+	// Report each closed UTC day to opted-in accounts
+	go s.startAccountReportScheduler()
+
+	// Push sequenced engine trades and order updates to WS subscribers
+	go s.startEventPublisher()
+
+	// Expire good-till-date orders in standalone mode
+	go s.startOrderExpiryScheduler()
+
+	// Trade resting spread orders against their legs in standalone mode
+	go s.startSpreadMatcher()
+
+	// Re-check resting order margin at the mark price in standalone mode
+	go s.startMarginRecheckScheduler()
+
+	// Pay out timelocked withdrawals in standalone mode
+	go s.startWithdrawalReleaseScheduler()
+
+	// Flush, downsample and prune persistent klines
+	go s.startKlineStore()
+
+	// Fill resting mock orders the simulated markets trade through
+	go s.startMockCounterparty()
+
 	// Start real-time data broadcaster (uses Hyperliquid Oracle)
 	// Now broadcasts real data in all modes
 	go s.startRealDataBroadcaster()
-
-	log.Printf("API server starting on %s (mock mode: %v)", addr, s.mockMode)
-	log.Printf("Using Hyperliquid Oracle for real-time prices")
-	log.Printf("New endpoints enabled: /v1/orders, /v1/positions, /v1/account")
-	if s.config.DisableRateLimit {
-		log.Printf("Rate limiting DISABLED (for testing)")
-	} else {
-		log.Printf("Rate limiting enabled: %d req/s per IP", 100)
-	}
-	return s.httpServer.ListenAndServe()
 }
 
 // Stop gracefully shuts down the server
 func (s *Server) Stop(ctx context.Context) error {
-	return s.httpServer.Shutdown(ctx)
+	defer s.stopCluster()
+	s.stopPublicServer(ctx)
+	err := s.httpServer.Shutdown(ctx)
+	if s.auditLog != nil {
+		if closeErr := s.auditLog.Close(); closeErr != nil {
+			log.Printf("Failed to close audit log: %v", closeErr)
+		}
+	}
+	return err
+}
+
+// startAuditLog opens the request audit log. Namespaces share the process's
+// log; their requests are recorded under their /ns/{name} routes.
+func (s *Server) startAuditLog() error {
+	rules := s.config.AuditSampling
+	if rules == nil {
+		var err error
+		if rules, err = middleware.ParseAuditRules(middleware.DefaultAuditSampling); err != nil {
+			return err
+		}
+	}
+	file, err := middleware.NewAuditFile(s.config.AuditLog, s.config.AuditLogMaxSize, s.config.AuditLogMaxFiles)
+	if err != nil {
+		return err
+	}
+	s.auditLog = middleware.NewAuditLogger(rules, file)
+	log.Printf("Auditing requests to %s", s.config.AuditLog)
+	return nil
+}
+
+// stopCluster stops the matcher RPC server and closes the matcher connection
+func (s *Server) stopCluster() {
+	if s.matcherRPC != nil {
+		s.matcherRPC.GracefulStop()
+	}
+	if s.matcherClient != nil {
+		_ = s.matcherClient.Close()
+	}
 }
 
 // handleHealth handles health check requests
@@ -296,26 +960,53 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if s.mockMode {
 		mode = "mock"
 		modeDescription = "Using mock data for development/testing"
+	} else if s.matcherClient != nil {
+		mode = "stateless"
+		modeDescription = "Routing order flow to shared matcher at " + s.config.MatcherAddr
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	// Report unhealthy while draining so the load balancer stops routing new traffic here
+	if s.IsDraining() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+			"status":    "draining",
+			"timestamp": time.Now().Unix(),
+			"mode":      mode,
+		})
+		return
+	}
+
+	resp := map[string]interface{}{
 		"status":           "healthy",
 		"timestamp":        time.Now().Unix(),
 		"mode":             mode,
 		"mode_description": modeDescription,
 		"mock_mode":        s.mockMode, // Deprecated: use "mode" instead
+		"websocket":        s.wsServer.GetHub().Stats(),
 		"warning":          "This API uses in-memory storage. For production, connect to a running Cosmos chain.",
-	})
+	}
+	if s.faultInjector != nil {
+		resp["fault_injection"] = s.faultInjector.Stats()
+	}
+	if s.auditLog != nil {
+		resp["audit_log"] = s.auditLog.Stats()
+	}
+	if len(s.namespaces) > 0 {
+		resp["namespaces"] = s.Namespaces()
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // handleMarkets handles /v1/markets
 func (s *Server) handleMarkets(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	markets := s.getMockMarkets()
+	for _, market := range markets {
+		market["trading_status"] = s.tradingStatus(r.Context(), market["market_id"].(string))
+	}
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"markets": markets,
 	})
@@ -324,7 +1015,7 @@ func (s *Server) handleMarkets(w http.ResponseWriter, r *http.Request) {
 // handleMarket handles /v1/markets/{id}/* endpoints
 func (s *Server) handleMarket(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
@@ -347,9 +1038,10 @@ func (s *Server) handleMarket(w http.ResponseWriter, r *http.Request) {
 		// Single market
 		market := s.getMockMarket(marketID)
 		if market == nil {
-			writeError(w, http.StatusNotFound, "Market not found")
+			writeError(w, types.ErrCodeMarketNotFound, "Market not found")
 			return
 		}
+		market["trading_status"] = s.tradingStatus(r.Context(), marketID)
 		writeJSON(w, http.StatusOK, market)
 
 	case "ticker":
@@ -361,14 +1053,77 @@ func (s *Server) handleMarket(w http.ResponseWriter, r *http.Request) {
 		if d := r.URL.Query().Get("depth"); d != "" {
 			fmt.Sscanf(d, "%d", &depth)
 		}
+		if s.marketData != nil {
+			orderbook, err := s.marketData.GetOrderBook(r.Context(), marketID, depth)
+			if err != nil {
+				writeAPIError(w, types.ToAPIError(err, types.ErrCodeServiceUnavailable))
+				return
+			}
+			writeJSON(w, http.StatusOK, orderbook)
+			return
+		}
 		orderbook := s.getMockOrderbook(marketID, depth)
 		writeJSON(w, http.StatusOK, orderbook)
 
+	case "orderbook/checksum":
+		depth := obtypes.ChecksumDepth
+		if d := r.URL.Query().Get("depth"); d != "" {
+			n, err := strconv.Atoi(d)
+			if err != nil || n <= 0 {
+				writeError(w, types.ErrCodeInvalidRequest, "depth must be a positive integer")
+				return
+			}
+			depth = n
+		}
+		var bids, asks [][]string
+		if s.marketData != nil {
+			orderbook, err := s.marketData.GetOrderBook(r.Context(), marketID, depth)
+			if err != nil {
+				writeAPIError(w, types.ToAPIError(err, types.ErrCodeServiceUnavailable))
+				return
+			}
+			bids, asks = orderbook.Bids, orderbook.Asks
+		} else {
+			orderbook := s.getMockOrderbook(marketID, depth)
+			if _, hasError := orderbook["error"]; hasError {
+				writeError(w, types.ErrCodeServiceUnavailable, "Orderbook unavailable")
+				return
+			}
+			bids, asks = orderbook["bids"].([][]string), orderbook["asks"].([][]string)
+		}
+		// Checksum the levels as this request receives them
+		if numbers := numberFormatter(r.Context()); numbers != nil {
+			bids, asks = numbers.FormatLevels(marketID, bids), numbers.FormatLevels(marketID, asks)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"market_id":  marketID,
+			"checksum":   obtypes.DepthChecksum(bids, asks),
+			"depth":      depth,
+			"bid_levels": len(bids),
+			"ask_levels": len(asks),
+			"timestamp":  time.Now().UnixMilli(),
+		})
+
 	case "trades":
+		if _, ok := s.orderService.(types.TradeHistoryService); ok {
+			s.orderHandler.TradeHistory(w, r, "", marketID)
+			return
+		}
 		limit := 100
 		if l := r.URL.Query().Get("limit"); l != "" {
 			fmt.Sscanf(l, "%d", &limit)
 		}
+		if s.marketData != nil {
+			trades, err := s.marketData.GetRecentTrades(r.Context(), marketID, limit)
+			if err != nil {
+				writeAPIError(w, types.ToAPIError(err, types.ErrCodeServiceUnavailable))
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"trades": trades,
+			})
+			return
+		}
 		trades := s.getMockTrades(marketID, limit)
 		writeJSON(w, http.StatusOK, map[string]interface{}{
 			"trades": trades,
@@ -390,15 +1145,32 @@ func (s *Server) handleMarket(w http.ResponseWriter, r *http.Request) {
 		funding := s.getMockFunding(marketID)
 		writeJSON(w, http.StatusOK, funding)
 
+	case "history":
+		s.handleMarketHistory(w, r, marketID)
+
+	case "index":
+		s.handleMarketIndex(w, r, marketID)
+
+	case "analytics":
+		s.handleMarketAnalytics(w, r, marketID)
+
+	case "long-short-ratio":
+		s.handleMarketLongShortRatio(w, r, marketID)
+
+	case "schedule":
+		s.handleMarketSchedule(w, r, marketID)
+
 	default:
-		writeError(w, http.StatusNotFound, "Endpoint not found")
+		writeError(w, types.ErrCodeNotFound, "Endpoint not found")
 	}
 }
 
 // handleAccountLegacy handles /v1/accounts/{addr}/* endpoints (legacy read-only)
+// except positions/history, which is served from the position service, and
+// trades/{tradeId}/pnl, which explains a fill
 func (s *Server) handleAccountLegacy(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
@@ -415,6 +1187,11 @@ func (s *Server) handleAccountLegacy(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if tradeID, ok := tradePnLPath(endpoint); ok {
+		s.handleTradePnL(w, r, address, tradeID)
+		return
+	}
+
 	switch endpoint {
 	case "":
 		account := s.getMockAccount(address)
@@ -426,6 +1203,12 @@ func (s *Server) handleAccountLegacy(w http.ResponseWriter, r *http.Request) {
 			"positions": positions,
 		})
 
+	case "positions/history":
+		s.positionHandler.PositionHistory(w, r, address)
+
+	case "equity-history":
+		s.handleEquityHistory(w, r, address)
+
 	case "orders":
 		orders := s.getMockOrders(address)
 		writeJSON(w, http.StatusOK, map[string]interface{}{
@@ -433,20 +1216,24 @@ func (s *Server) handleAccountLegacy(w http.ResponseWriter, r *http.Request) {
 		})
 
 	case "trades":
+		if _, ok := s.orderService.(types.TradeHistoryService); ok {
+			s.orderHandler.TradeHistory(w, r, address, "")
+			return
+		}
 		trades := s.getMockAccountTrades(address)
 		writeJSON(w, http.StatusOK, map[string]interface{}{
 			"trades": trades,
 		})
 
 	default:
-		writeError(w, http.StatusNotFound, "Endpoint not found")
+		writeError(w, types.ErrCodeNotFound, "Endpoint not found")
 	}
 }
 
 // handleTickers handles /v1/tickers
 func (s *Server) handleTickers(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
@@ -462,23 +1249,76 @@ func (s *Server) handleTickers(w http.ResponseWriter, r *http.Request) {
 
 // Helper functions
 
+// newSessionManager creates the WebSocket session manager from config
+func newWebhookDispatcher(config *Config) *webhook.Dispatcher {
+	return webhook.NewDispatcher(webhook.Config{
+		AllowInsecure: config.WebhookAllowInsecure,
+	})
+}
+
+func newSessionManager(config *Config) *auth.SessionManager {
+	licenses := make(auth.StaticPermissions, len(config.DataLicenses))
+	for _, trader := range config.DataLicenses {
+		licenses[trader] = append(licenses[trader], auth.PermissionL3Data)
+	}
+	return auth.NewSessionManager(auth.Config{
+		Secret:      []byte(config.SessionSecret),
+		TTL:         config.SessionTTL,
+		APIKeys:     auth.StaticAPIKeys(config.APIKeys),
+		Permissions: licenses,
+	})
+}
+
+// defaultMarketRules builds order validation rules from the default market configs
+func defaultMarketRules() validation.StaticRules {
+	rules := make(validation.StaticRules)
+	for id, cfg := range perptypes.DefaultMarketConfigs() {
+		rules[id] = validation.MarketRules{
+			MarketID:          id,
+			TickSize:          cfg.TickSize,
+			LotSize:           cfg.LotSize,
+			MinOrderSize:      cfg.MinOrderSize,
+			MaxOrderSize:      cfg.MaxOrderSize,
+			MinNotional:       cfg.MinNotional,
+			MaxPriceDeviation: cfg.MaxPriceDeviation,
+		}
+	}
+	return rules
+}
+
+// keeperKlines returns svc as the kline source when it reads candles from the
+// perpetual keeper, so keeper-backed servers chart their own trades instead
+// of the oracle's
+func keeperKlines(svc interface{}) klineProvider {
+	if rs, ok := svc.(*RealService); ok && rs.HasKlines() {
+		return rs
+	}
+	return nil
+}
+
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(data)
 }
 
-func writeError(w http.ResponseWriter, status int, message string) {
-	writeJSON(w, status, map[string]interface{}{
-		"error": message,
-	})
+func writeError(w http.ResponseWriter, code types.ErrorCode, message string) {
+	writeAPIError(w, types.NewAPIError(code, message))
+}
+
+func writeAPIError(w http.ResponseWriter, apiErr *types.APIError) {
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = w.Header().Get(types.RequestIDHeader)
+	}
+	writeJSON(w, apiErr.Code.HTTPStatus(), types.NewErrorResponse(apiErr))
 }
 
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Trader-Address")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Trader-Address, X-Request-ID, X-Namespace, X-Number-Format")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
@@ -508,7 +1348,7 @@ func (s *Server) handleRiverpoolPoolRoutes(w http.ResponseWriter, r *http.Reques
 	}
 
 	if poolID == "" {
-		writeError(w, http.StatusBadRequest, "Pool ID required")
+		writeError(w, types.ErrCodeMissingField, "Pool ID required")
 		return
 	}
 
@@ -537,7 +1377,7 @@ func (s *Server) handleRiverpoolPoolRoutes(w http.ResponseWriter, r *http.Reques
 	case "revenue":
 		s.riverpoolHandler.GetPoolRevenue(w, r)
 	default:
-		writeError(w, http.StatusNotFound, "Endpoint not found")
+		writeError(w, types.ErrCodeNotFound, "Endpoint not found")
 	}
 }
 
@@ -558,7 +1398,7 @@ func (s *Server) handleRiverpoolUserRoutes(w http.ResponseWriter, r *http.Reques
 	}
 
 	if address == "" {
-		writeError(w, http.StatusBadRequest, "User address required")
+		writeError(w, types.ErrCodeMissingField, "User address required")
 		return
 	}
 
@@ -573,7 +1413,7 @@ func (s *Server) handleRiverpoolUserRoutes(w http.ResponseWriter, r *http.Reques
 	case "pools":
 		s.riverpoolHandler.GetUserPools(w, r)
 	default:
-		writeError(w, http.StatusNotFound, "Endpoint not found")
+		writeError(w, types.ErrCodeNotFound, "Endpoint not found")
 	}
 }
 
@@ -594,18 +1434,33 @@ func (s *Server) handleRiverpoolCommunityRoutes(w http.ResponseWriter, r *http.R
 	}
 
 	if poolID == "" {
-		writeError(w, http.StatusBadRequest, "Pool ID required")
+		writeError(w, types.ErrCodeMissingField, "Pool ID required")
 		return
 	}
 
 	// Set pool ID in request for handler
 	r.Header.Set("X-Pool-ID", poolID)
 
+	// invites/{code}/{redemptions|revoke}
+	if rest, ok := strings.CutPrefix(action, "invites/"); ok {
+		parts := strings.Split(rest, "/")
+		if len(parts) != 2 || parts[0] == "" {
+			writeError(w, types.ErrCodeNotFound, "Action not found")
+			return
+		}
+		r.Header.Set("X-Invite-Code", parts[0])
+		action = "invites/" + parts[1]
+	}
+
 	switch action {
 	case "update":
 		s.riverpoolHandler.UpdateCommunityPool(w, r)
 	case "invite":
 		s.riverpoolHandler.GenerateInviteCode(w, r)
+	case "invites/redemptions":
+		s.riverpoolHandler.GetInviteRedemptions(w, r)
+	case "invites/revoke":
+		s.riverpoolHandler.RevokeInviteCode(w, r)
 	case "order":
 		s.riverpoolHandler.PlacePoolOrder(w, r)
 	case "close":
@@ -617,6 +1472,6 @@ func (s *Server) handleRiverpoolCommunityRoutes(w http.ResponseWriter, r *http.R
 	case "close-pool":
 		s.riverpoolHandler.ClosePool(w, r)
 	default:
-		writeError(w, http.StatusNotFound, "Action not found")
+		writeError(w, types.ErrCodeNotFound, "Action not found")
 	}
 }
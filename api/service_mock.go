@@ -4,9 +4,15 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/mocksim"
 	"github.com/openalpha/perp-dex/api/types"
 )
 
@@ -15,8 +21,15 @@ type MockService struct {
 	orders    map[string]*types.Order
 	positions map[string]*types.Position // key: trader:marketID
 	accounts  map[string]*types.Account
+	closed    map[string][]*types.ClosedPosition // key: trader, oldest first
 	mu        sync.RWMutex
 	orderSeq  int64
+	closeSeq  int64
+
+	// Simulated markets market orders fill against and the virtual
+	// counterparty fills resting orders from (see mock_sim.go); nil fills
+	// market orders at their request price
+	sim *mocksim.Simulator
 }
 
 // NewMockService creates a new mock service
@@ -25,6 +38,7 @@ func NewMockService() *MockService {
 		orders:    make(map[string]*types.Order),
 		positions: make(map[string]*types.Position),
 		accounts:  make(map[string]*types.Account),
+		closed:    make(map[string][]*types.ClosedPosition),
 	}
 	ms.initMockData()
 	return ms
@@ -72,6 +86,9 @@ func (ms *MockService) PlaceOrder(ctx context.Context, req *types.PlaceOrderRequ
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+	if req.TimeInForce == types.TimeInForceGTD {
+		order.TimeInForce, order.ExpireAt = req.TimeInForce, req.ExpireAt
+	}
 
 	ms.orders[orderID] = order
 
@@ -84,9 +101,16 @@ func (ms *MockService) PlaceOrder(ctx context.Context, req *types.PlaceOrderRequ
 	}
 
 	if req.Type == "market" {
-		// Simulate immediate fill
+		// Simulate immediate fill, at the simulated market's touch if any
+		price, tradeID := req.Price, fmt.Sprintf("trade-%d", rand.Intn(100000))
+		if qty, err := strconv.ParseFloat(req.Quantity, 64); err == nil && qty > 0 && ms.sim != nil && ms.sim.Has(req.MarketID) {
+			if fill, err := ms.sim.Taker(req.MarketID, req.Side, qty, time.Now()); err == nil {
+				price, tradeID = ms.sim.FormatPrice(req.MarketID, fill.Price), fill.TradeID
+				ms.bookSimFillLocked(req.Trader, req.MarketID, req.Side, fill.Price, qty)
+			}
+		}
 		match.FilledQty = req.Quantity
-		match.AvgPrice = req.Price
+		match.AvgPrice = price
 		match.RemainingQty = "0.00"
 		order.FilledQty = req.Quantity
 		order.Status = "filled"
@@ -94,8 +118,8 @@ func (ms *MockService) PlaceOrder(ctx context.Context, req *types.PlaceOrderRequ
 
 		// Add mock trade
 		match.Trades = append(match.Trades, types.TradeInfo{
-			TradeID:   fmt.Sprintf("trade-%d", rand.Intn(100000)),
-			Price:     req.Price,
+			TradeID:   tradeID,
+			Price:     price,
 			Quantity:  req.Quantity,
 			Timestamp: now,
 		})
@@ -120,7 +144,7 @@ func (ms *MockService) CancelOrder(ctx context.Context, trader, orderID string)
 		return nil, fmt.Errorf("unauthorized: order belongs to different trader")
 	}
 
-	if order.Status != "open" {
+	if order.Status != "open" && order.Status != "partially_filled" {
 		return nil, fmt.Errorf("order cannot be cancelled: status is %s", order.Status)
 	}
 
@@ -137,63 +161,51 @@ func (ms *MockService) ModifyOrder(ctx context.Context, trader, orderID string,
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	oldOrder, ok := ms.orders[orderID]
+	order, ok := ms.orders[orderID]
 	if !ok {
 		return nil, fmt.Errorf("order not found: %s", orderID)
 	}
 
-	if oldOrder.Trader != trader {
+	if order.Trader != trader {
 		return nil, fmt.Errorf("unauthorized: order belongs to different trader")
 	}
 
-	if oldOrder.Status != "open" {
-		return nil, fmt.Errorf("order cannot be modified: status is %s", oldOrder.Status)
+	if order.Status != "open" && order.Status != "partially_filled" {
+		return nil, fmt.Errorf("order cannot be modified: status is %s", order.Status)
 	}
 
-	// Cancel old order
-	oldOrder.Status = "cancelled"
-	oldOrder.UpdatedAt = types.NowMillis()
-
-	// Create new order with modified values
-	seq := atomic.AddInt64(&ms.orderSeq, 1)
-	newOrderID := fmt.Sprintf("order-%d", seq)
-
-	now := types.NowMillis()
-	price := oldOrder.Price
-	quantity := oldOrder.Quantity
-	if req.Price != "" {
-		price = req.Price
+	// Amend in place; only a quantity reduction at the same price keeps priority
+	retained := true
+	if req.Price != "" && req.Price != order.Price {
+		retained = false
+		order.Price = req.Price
 	}
 	if req.Quantity != "" {
-		quantity = req.Quantity
+		newQty, err := math.LegacyNewDecFromStr(req.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity: %s", req.Quantity)
+		}
+		if oldQty, err := math.LegacyNewDecFromStr(order.Quantity); err == nil && newQty.GT(oldQty) {
+			retained = false
+		}
+		order.Quantity = req.Quantity
 	}
+	order.UpdatedAt = types.NowMillis()
 
-	newOrder := &types.Order{
-		OrderID:   newOrderID,
-		Trader:    oldOrder.Trader,
-		MarketID:  oldOrder.MarketID,
-		Side:      oldOrder.Side,
-		Type:      oldOrder.Type,
-		Price:     price,
-		Quantity:  quantity,
-		FilledQty: "0.00",
-		Status:    "open",
-		CreatedAt: now,
-		UpdatedAt: now,
+	resp := &types.ModifyOrderResponse{
+		OldOrderID:       orderID,
+		Order:            order,
+		PriorityRetained: retained,
 	}
-
-	ms.orders[newOrderID] = newOrder
-
-	return &types.ModifyOrderResponse{
-		OldOrderID: orderID,
-		Order:      newOrder,
-		Match: &types.MatchResult{
+	if !retained {
+		resp.Match = &types.MatchResult{
 			FilledQty:    "0.00",
 			AvgPrice:     "0.00",
-			RemainingQty: quantity,
+			RemainingQty: order.Quantity,
 			Trades:       []types.TradeInfo{},
-		},
-	}, nil
+		}
+	}
+	return resp, nil
 }
 
 func (ms *MockService) GetOrder(ctx context.Context, orderID string) (*types.Order, error) {
@@ -225,21 +237,51 @@ func (ms *MockService) ListOrders(ctx context.Context, req *types.ListOrdersRequ
 		if req.Status != "" && order.Status != req.Status {
 			continue
 		}
+		// Filter by side and creation time
+		if req.Side != "" && order.Side != req.Side {
+			continue
+		}
+		if (req.From > 0 && order.CreatedAt < req.From) || (req.To > 0 && order.CreatedAt > req.To) {
+			continue
+		}
 		orders = append(orders, order)
 	}
 
+	// Newest first unless asked otherwise; the order ID breaks ties
+	oldest := req.Sort == types.SortOldest
+	before := func(a *types.Order, createdAt int64, orderID string) bool {
+		if a.CreatedAt != createdAt {
+			return (a.CreatedAt < createdAt) == oldest
+		}
+		return (a.OrderID < orderID) == oldest
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return before(orders[i], orders[j].CreatedAt, orders[j].OrderID)
+	})
+
+	// Resume after the cursor's position, even if its order is gone
+	if req.Cursor != "" {
+		var createdAt int64
+		var orderID string
+		if _, err := fmt.Sscanf(req.Cursor, "%d-%s", &createdAt, &orderID); err != nil {
+			return nil, types.NewAPIError(types.ErrCodeInvalidRequest, "invalid cursor")
+		}
+		i := 0
+		for i < len(orders) && (orders[i].OrderID == orderID || before(orders[i], createdAt, orderID)) {
+			i++
+		}
+		orders = orders[i:]
+	}
+
 	// Apply limit
 	limit := req.Limit
 	if limit <= 0 || limit > 100 {
 		limit = 100
 	}
+	var nextCursor string
 	if len(orders) > limit {
 		orders = orders[:limit]
-	}
-
-	var nextCursor string
-	if len(orders) > 0 {
-		nextCursor = orders[len(orders)-1].OrderID
+		nextCursor = mockOrderCursor(orders[limit-1])
 	}
 
 	return &types.ListOrdersResponse{
@@ -249,6 +291,11 @@ func (ms *MockService) ListOrders(ctx context.Context, req *types.ListOrdersRequ
 	}, nil
 }
 
+// mockOrderCursor is the cursor of the page after an order
+func mockOrderCursor(order *types.Order) string {
+	return fmt.Sprintf("%d-%s", order.CreatedAt, order.OrderID)
+}
+
 // ============ PositionService Implementation ============
 
 func (ms *MockService) GetPositions(ctx context.Context, trader string) ([]*types.Position, error) {
@@ -305,9 +352,25 @@ func (ms *MockService) ClosePosition(ctx context.Context, req *types.ClosePositi
 		realizedPnl = fmt.Sprintf("%.2f", -30.0)
 	}
 
-	// Remove position if fully closed
+	// Remove position if fully closed and keep its history record
 	if closeSize == pos.Size {
 		delete(ms.positions, key)
+		ms.closeSeq++
+		ms.closed[req.Trader] = append(ms.closed[req.Trader], &types.ClosedPosition{
+			PositionID:  fmt.Sprintf("pos-%d", ms.closeSeq),
+			MarketID:    req.MarketID,
+			Trader:      req.Trader,
+			Side:        pos.Side,
+			Size:        closeSize,
+			EntryPrice:  pos.EntryPrice,
+			ExitPrice:   closePrice,
+			RealizedPnl: realizedPnl,
+			FeesPaid:    "0.00",
+			FundingPaid: "0.00",
+			NetPnl:      realizedPnl,
+			CloseReason: "closed",
+			ClosedAt:    types.NowMillis(),
+		})
 	}
 
 	// Update account
@@ -334,15 +397,65 @@ func (ms *MockService) ClosePosition(ctx context.Context, req *types.ClosePositi
 	}, nil
 }
 
+func (ms *MockService) GetPositionHistory(ctx context.Context, req *types.PositionHistoryRequest) (*types.PositionHistoryResponse, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	resp := &types.PositionHistoryResponse{
+		Positions: []*types.ClosedPosition{},
+		Limit:     req.Limit,
+	}
+	history := ms.closed[req.Trader]
+	start := len(history) - 1
+	if req.Cursor != "" {
+		// The cursor is the last position of the previous page
+		for start >= 0 && history[start].PositionID != req.Cursor {
+			start--
+		}
+		if start < 0 {
+			return nil, types.NewAPIError(types.ErrCodeInvalidRequest, "invalid cursor")
+		}
+		start--
+	}
+	for i := start; i >= 0 && req.Limit > 0; i-- {
+		if req.MarketID != "" && history[i].MarketID != req.MarketID {
+			continue
+		}
+		if len(resp.Positions) == req.Limit {
+			resp.NextCursor = resp.Positions[req.Limit-1].PositionID
+			break
+		}
+		resp.Positions = append(resp.Positions, history[i])
+	}
+	return resp, nil
+}
+
+// GetADLIndicators implements types.ADLService over the mock positions
+func (ms *MockService) GetADLIndicators(ctx context.Context, marketID string) ([]*types.ADLIndicator, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	positions := make([]*types.Position, 0, len(ms.positions))
+	for _, pos := range ms.positions {
+		positions = append(positions, pos)
+	}
+	return adlIndicators(marketID, positions, types.NowMillis()), nil
+}
+
 // ============ AccountService Implementation ============
 
 func (ms *MockService) GetAccount(ctx context.Context, trader string) (*types.Account, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
+	return ms.accountOrDefault(trader), nil
+}
+
+// accountOrDefault returns the stored account, or the empty default for new
+// traders. Callers hold ms.mu.
+func (ms *MockService) accountOrDefault(trader string) *types.Account {
 	account, ok := ms.accounts[trader]
 	if !ok {
-		// Return default account for new traders
 		return &types.Account{
 			Trader:           trader,
 			Balance:          "0.00",
@@ -350,9 +463,68 @@ func (ms *MockService) GetAccount(ctx context.Context, trader string) (*types.Ac
 			AvailableBalance: "0.00",
 			MarginMode:       "isolated",
 			UpdatedAt:        types.NowMillis(),
-		}, nil
+		}
 	}
-	return account, nil
+	return account
+}
+
+func (ms *MockService) BatchQueryAccounts(ctx context.Context, traders []string) ([]*types.AccountSummary, error) {
+	snapshot, err := ms.SnapshotAccounts(ctx, traders)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Accounts, nil
+}
+
+// SnapshotAccounts returns copies of the summaries of many traders read
+// under one lock. Mock mode has no chain, so the height is always 0.
+func (ms *MockService) SnapshotAccounts(ctx context.Context, traders []string) (*types.AccountSnapshot, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	summaries := make([]*types.AccountSummary, 0, len(traders))
+	for _, trader := range traders {
+		account := *ms.accountOrDefault(trader)
+		summary := &types.AccountSummary{
+			Trader:    trader,
+			Account:   &account,
+			Positions: []*types.Position{},
+		}
+		for _, pos := range ms.positions {
+			if pos.Trader == trader {
+				position := *pos
+				summary.Positions = append(summary.Positions, &position)
+			}
+		}
+		for _, order := range ms.orders {
+			if order.Trader == trader && order.Status == "open" {
+				summary.OpenOrders++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return &types.AccountSnapshot{Accounts: summaries}, nil
+}
+
+// ListTraders implements types.TraderLister over the mock accounts and positions
+func (ms *MockService) ListTraders(ctx context.Context) ([]string, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	seen := make(map[string]bool, len(ms.accounts))
+	traders := make([]string, 0, len(ms.accounts))
+	for trader := range ms.accounts {
+		seen[trader] = true
+		traders = append(traders, trader)
+	}
+	for _, pos := range ms.positions {
+		if !seen[pos.Trader] {
+			seen[pos.Trader] = true
+			traders = append(traders, pos.Trader)
+		}
+	}
+	sort.Strings(traders)
+	return traders, nil
 }
 
 func (ms *MockService) Deposit(ctx context.Context, req *types.DepositRequest) (*types.AccountResponse, error) {
@@ -403,3 +575,8 @@ func (ms *MockService) Withdraw(ctx context.Context, req *types.WithdrawRequest)
 
 	return &types.AccountResponse{Account: account}, nil
 }
+
+// DebitCollateral debits like Withdraw, which queues nothing in the mock
+func (ms *MockService) DebitCollateral(ctx context.Context, trader, amount string) (*types.AccountResponse, error) {
+	return ms.Withdraw(ctx, &types.WithdrawRequest{Trader: trader, Amount: amount})
+}
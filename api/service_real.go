@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,10 +19,13 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/pkg/timing"
 	obkeeper "github.com/openalpha/perp-dex/x/orderbook/keeper"
 	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
 	perpkeeper "github.com/openalpha/perp-dex/x/perpetual/keeper"
 	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	treasurykeeper "github.com/openalpha/perp-dex/x/treasury/keeper"
+	treasurytypes "github.com/openalpha/perp-dex/x/treasury/types"
 )
 
 // RealService implements all service interfaces with real orderbook engine
@@ -33,20 +37,34 @@ type RealService struct {
 	sdkCtx      sdk.Context
 	mu          sync.RWMutex
 	logger      log.Logger
+
+	// Trading schedules when no perpetual keeper is attached
+	schedules *tradingScheduleStore
+
+	// Markets served to the orderbook when no perpetual keeper is attached
+	simplePerp *SimplePerpetualKeeper
+
+	treasuryKeeper *treasurykeeper.Keeper // optional
 }
 
 // SimplePerpetualKeeper is a minimal implementation of PerpetualKeeper interface
 // for standalone API server usage (without full chain integration)
 type SimplePerpetualKeeper struct {
-	markets map[string]*obkeeper.Market
-	oracle  *HyperliquidOracle
-	mu      sync.RWMutex
+	markets  map[string]*obkeeper.Market
+	accounts map[string]*perptypes.Account // traders funded by deposits
+	oracle   *HyperliquidOracle
+	mu       sync.RWMutex
 }
 
+// standaloneBalance is what a trader holds in standalone mode before any
+// deposit, like the testing balance of new perpetual keeper accounts
+var standaloneBalance = math.LegacyNewDec(10000)
+
 func NewSimplePerpetualKeeper() *SimplePerpetualKeeper {
 	pk := &SimplePerpetualKeeper{
-		markets: make(map[string]*obkeeper.Market),
-		oracle:  NewHyperliquidOracle(), // Use Hyperliquid Oracle for real-time prices
+		markets:  make(map[string]*obkeeper.Market),
+		accounts: make(map[string]*perptypes.Account),
+		oracle:   NewHyperliquidOracle(), // Use Hyperliquid Oracle for real-time prices
 	}
 	// Initialize default markets
 	pk.initDefaultMarkets()
@@ -69,12 +87,23 @@ func (pk *SimplePerpetualKeeper) initDefaultMarkets() {
 		takerFee, _ := math.LegacyNewDecFromStr(m.takerFee)
 		makerFee, _ := math.LegacyNewDecFromStr(m.makerFee)
 		initMargin, _ := math.LegacyNewDecFromStr(m.initMargin)
-		pk.markets[m.id] = &obkeeper.Market{
+		market := &obkeeper.Market{
 			MarketID:      m.id,
 			TakerFeeRate:  takerFee,
 			MakerFeeRate:  makerFee,
 			InitialMargin: initMargin,
 		}
+		// Order validation rules follow the on-chain market defaults
+		if cfg, ok := perptypes.DefaultMarketConfigs()[m.id]; ok {
+			market.TickSize = cfg.TickSize
+			market.LotSize = cfg.LotSize
+			market.MinOrderSize = cfg.MinOrderSize
+			market.MaxOrderSize = cfg.MaxOrderSize
+			market.MinNotional = cfg.MinNotional
+			market.MaxPriceDeviation = cfg.MaxPriceDeviation
+			market.MaxSlippage = cfg.MaxSlippage
+		}
+		pk.markets[m.id] = market
 	}
 }
 
@@ -96,6 +125,52 @@ func (pk *SimplePerpetualKeeper) GetMarkPrice(ctx sdk.Context, marketID string)
 	return math.LegacyZeroDec(), false
 }
 
+// GetAccount returns a copy of a funded trader's account, or nil for a
+// trader that still holds the standalone balance
+func (pk *SimplePerpetualKeeper) GetAccount(trader string) *perptypes.Account {
+	pk.mu.RLock()
+	defer pk.mu.RUnlock()
+
+	account, ok := pk.accounts[trader]
+	if !ok {
+		return nil
+	}
+	copied := *account
+	return &copied
+}
+
+// Deposit credits a trader's account, opening it with the standalone
+// balance on the first deposit
+func (pk *SimplePerpetualKeeper) Deposit(trader string, amount math.LegacyDec) *perptypes.Account {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+
+	account, ok := pk.accounts[trader]
+	if !ok {
+		account = perptypes.NewAccount(trader)
+		account.Deposit(standaloneBalance)
+		pk.accounts[trader] = account
+	}
+	account.Deposit(amount)
+	account.UpdatedAt = time.Now()
+	copied := *account
+	return &copied
+}
+
+// SetBalance sets a trader's balance outright
+func (pk *SimplePerpetualKeeper) SetBalance(trader string, balance math.LegacyDec) {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+
+	account, ok := pk.accounts[trader]
+	if !ok {
+		account = perptypes.NewAccount(trader)
+		pk.accounts[trader] = account
+	}
+	account.Balance = balance
+	account.UpdatedAt = time.Now()
+}
+
 func (pk *SimplePerpetualKeeper) UpdatePosition(ctx sdk.Context, trader, marketID string, side obtypes.Side, qty, price, fee interface{}) error {
 	// Position updates are handled separately
 	return nil
@@ -143,6 +218,8 @@ func NewRealService(logger log.Logger) (*RealService, error) {
 		matchEngine: matchEngine,
 		sdkCtx:      sdkCtx,
 		logger:      logger,
+		schedules:   newTradingScheduleStore(),
+		simplePerp:  perpKeeper,
 	}, nil
 }
 
@@ -160,6 +237,7 @@ func NewRealServiceWithKeepers(
 		matchEngine: obkeeper.NewMatchingEngineV2(obKeeper),
 		sdkCtx:      sdkCtx,
 		logger:      logger,
+		schedules:   newTradingScheduleStore(),
 	}
 }
 
@@ -209,14 +287,40 @@ func (rs *RealService) PlaceOrder(ctx context.Context, req *types.PlaceOrderRequ
 		orderType = obtypes.OrderTypeMarket
 	}
 
-	// Place order through real Keeper (using internal SDK context, not HTTP context)
-	order, matchResult, err := rs.obKeeper.PlaceOrder(rs.sdkCtx, req.Trader, req.MarketID, side, orderType, price, qty)
+	// Place order through real Keeper (using internal SDK context, not HTTP
+	// context, plus the request's timing recorder)
+	rec := timing.FromContext(ctx)
+	placeCtx := timing.WithRecorder(rs.clockCtx(), rec)
+	maxSlippage, err := parseMaxSlippage(req)
+	if err != nil {
+		return nil, err
+	}
+	var order *obtypes.Order
+	var matchResult *obkeeper.MatchResult
+	switch req.TimeInForce {
+	case "", types.TimeInForceGTC:
+		if maxSlippage.IsNil() {
+			order, matchResult, err = rs.obKeeper.PlaceOrder(placeCtx, req.Trader, req.MarketID, side, orderType, price, qty)
+		} else {
+			order, matchResult, err = rs.obKeeper.PlaceMarketOrder(placeCtx, req.Trader, req.MarketID, side, price, qty, maxSlippage)
+		}
+	case types.TimeInForceGTD:
+		if orderType != obtypes.OrderTypeLimit {
+			return nil, fmt.Errorf("GTD orders must be limit orders")
+		}
+		order, matchResult, err = rs.obKeeper.PlaceOrderWithExpiry(placeCtx, req.Trader, req.MarketID, side, price, qty, time.UnixMilli(req.ExpireAt))
+	default:
+		return nil, fmt.Errorf("invalid time_in_force: %s", req.TimeInForce)
+	}
 	if err != nil {
+		recordOrderLimitRejection(req.MarketID, err)
 		return nil, fmt.Errorf("failed to place order: %w", err)
 	}
 
 	// Flush cache to persist changes
+	start := time.Now()
 	rs.matchEngine.Flush(rs.sdkCtx)
+	rec.Since(timing.StagePersist, start)
 
 	// Convert to API response
 	return rs.convertPlaceOrderResponse(order, matchResult), nil
@@ -245,48 +349,37 @@ func (rs *RealService) ModifyOrder(ctx context.Context, trader, orderID string,
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
 
-	// Get existing order
-	oldOrder := rs.obKeeper.GetOrder(rs.sdkCtx, orderID)
-	if oldOrder == nil {
-		return nil, fmt.Errorf("order not found: %s", orderID)
-	}
-	if oldOrder.Trader != trader {
-		return nil, fmt.Errorf("unauthorized: order belongs to different trader")
-	}
-	if !oldOrder.IsActive() {
-		return nil, fmt.Errorf("order is not active")
-	}
-
-	// Cancel old order (using internal SDK context)
-	_, err := rs.obKeeper.CancelOrder(rs.sdkCtx, trader, orderID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to cancel old order: %w", err)
-	}
-
-	// Create new order with modified values
-	price := oldOrder.Price
-	qty := oldOrder.Quantity
+	// Empty fields are left unchanged (nil Dec)
+	var price, qty math.LegacyDec
+	var err error
 	if req.Price != "" {
-		price, _ = math.LegacyNewDecFromStr(req.Price)
+		if price, err = math.LegacyNewDecFromStr(req.Price); err != nil {
+			return nil, fmt.Errorf("invalid price: %s", req.Price)
+		}
 	}
 	if req.Quantity != "" {
-		qty, _ = math.LegacyNewDecFromStr(req.Quantity)
+		if qty, err = math.LegacyNewDecFromStr(req.Quantity); err != nil {
+			return nil, fmt.Errorf("invalid quantity: %s", req.Quantity)
+		}
 	}
 
-	// Place new order (using internal SDK context)
-	newOrder, matchResult, err := rs.obKeeper.PlaceOrder(rs.sdkCtx, trader, oldOrder.MarketID, oldOrder.Side, oldOrder.OrderType, price, qty)
+	// Amend in place: quantity reductions keep time priority
+	order, matchResult, err := rs.obKeeper.AmendOrder(rs.clockCtx(), trader, orderID, price, qty)
 	if err != nil {
-		return nil, fmt.Errorf("failed to place new order: %w", err)
+		return nil, err
 	}
 
-	// Flush cache
 	rs.matchEngine.Flush(rs.sdkCtx)
 
-	return &types.ModifyOrderResponse{
-		OldOrderID: orderID,
-		Order:      rs.convertOrder(newOrder),
-		Match:      rs.convertMatchResult(matchResult),
-	}, nil
+	resp := &types.ModifyOrderResponse{
+		OldOrderID:       orderID,
+		Order:            rs.convertOrder(order),
+		PriorityRetained: matchResult == nil,
+	}
+	if matchResult != nil {
+		resp.Match = rs.convertMatchResult(matchResult)
+	}
+	return resp, nil
 }
 
 func (rs *RealService) GetOrder(ctx context.Context, orderID string) (*types.Order, error) {
@@ -301,42 +394,33 @@ func (rs *RealService) GetOrder(ctx context.Context, orderID string) (*types.Ord
 }
 
 func (rs *RealService) ListOrders(ctx context.Context, req *types.ListOrdersRequest) (*types.ListOrdersResponse, error) {
+	if req.Trader == "" {
+		return nil, fmt.Errorf("trader is required")
+	}
+	q, err := historyQuery(req.MarketID, req.HistoryParams)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != "" {
+		status, ok := orderStatusFilters[strings.ToLower(req.Status)]
+		if !ok {
+			return nil, types.NewAPIError(types.ErrCodeInvalidRequest, "invalid status: "+req.Status)
+		}
+		q.Status = status
+	}
+
 	rs.mu.RLock()
 	defer rs.mu.RUnlock()
 
-	var orders []*obtypes.Order
-	if req.Trader != "" {
-		orders = rs.obKeeper.GetOrdersByTrader(rs.sdkCtx, req.Trader)
-	} else {
-		orders = rs.obKeeper.GetAllPendingOrders(rs.sdkCtx)
+	orders, nextCursor, err := rs.obKeeper.QueryOrders(rs.sdkCtx, req.Trader, q)
+	if err != nil {
+		return nil, err
 	}
-
-	// Filter and convert
-	var result []*types.Order
+	result := make([]*types.Order, 0, len(orders))
 	for _, order := range orders {
-		if req.MarketID != "" && order.MarketID != req.MarketID {
-			continue
-		}
-		if req.Status != "" && order.Status.String() != req.Status {
-			continue
-		}
 		result = append(result, rs.convertOrder(order))
 	}
 
-	// Apply limit
-	limit := req.Limit
-	if limit <= 0 || limit > 100 {
-		limit = 100
-	}
-	if len(result) > limit {
-		result = result[:limit]
-	}
-
-	var nextCursor string
-	if len(result) > 0 {
-		nextCursor = result[len(result)-1].OrderID
-	}
-
 	return &types.ListOrdersResponse{
 		Orders:     result,
 		NextCursor: nextCursor,
@@ -344,6 +428,155 @@ func (rs *RealService) ListOrders(ctx context.Context, req *types.ListOrdersRequ
 	}, nil
 }
 
+// ============ TradeHistoryService Implementation ============
+
+func (rs *RealService) ListTrades(ctx context.Context, req *types.ListTradesRequest) (*types.ListTradesResponse, error) {
+	if req.Trader == "" && req.MarketID == "" {
+		return nil, types.NewAPIError(types.ErrCodeMissingField, "trader or market_id is required")
+	}
+	q, err := historyQuery(req.MarketID, req.HistoryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	trades, nextCursor, err := rs.obKeeper.QueryTrades(rs.sdkCtx, req.Trader, q)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.MarketTrade, 0, len(trades))
+	for _, t := range trades {
+		trade := convertMarketTrade(t)
+		if req.Trader != "" {
+			trade.TakerOrderID, trade.MakerOrderID = t.TakerOrderID, t.MakerOrderID
+			trade.Liquidity = "maker"
+			if t.Taker == req.Trader {
+				trade.Liquidity = "taker"
+			}
+		}
+		result = append(result, trade)
+	}
+	return &types.ListTradesResponse{Trades: result, NextCursor: nextCursor}, nil
+}
+
+// parseMaxSlippage parses the slippage override of a market order, returning
+// a nil Dec when the request keeps the market's default
+func parseMaxSlippage(req *types.PlaceOrderRequest) (math.LegacyDec, error) {
+	if req.MaxSlippage == "" {
+		return math.LegacyDec{}, nil
+	}
+	if req.Type != "market" {
+		return math.LegacyDec{}, fmt.Errorf("max_slippage only applies to market orders")
+	}
+	maxSlippage, err := math.LegacyNewDecFromStr(req.MaxSlippage)
+	if err != nil {
+		return math.LegacyDec{}, fmt.Errorf("invalid max_slippage: %s", req.MaxSlippage)
+	}
+	return maxSlippage, nil
+}
+
+// orderStatusFilters maps the status filter of order listings, in API or
+// enum form, to keeper statuses
+var orderStatusFilters = map[string]obtypes.OrderStatus{
+	"open":                          obtypes.OrderStatusOpen,
+	"partially_filled":              obtypes.OrderStatusPartiallyFilled,
+	"filled":                        obtypes.OrderStatusFilled,
+	"cancelled":                     obtypes.OrderStatusCancelled,
+	"expired":                       obtypes.OrderStatusExpired,
+	"price_protected":               obtypes.OrderStatusPriceProtected,
+	"order_status_open":             obtypes.OrderStatusOpen,
+	"order_status_partially_filled": obtypes.OrderStatusPartiallyFilled,
+	"order_status_filled":           obtypes.OrderStatusFilled,
+	"order_status_cancelled":        obtypes.OrderStatusCancelled,
+	"order_status_expired":          obtypes.OrderStatusExpired,
+	"order_status_price_protected":  obtypes.OrderStatusPriceProtected,
+}
+
+// historyQuery converts the API history parameters to a keeper query
+func historyQuery(marketID string, params types.HistoryParams) (obkeeper.HistoryQuery, error) {
+	q := obkeeper.HistoryQuery{
+		MarketID: marketID,
+		Oldest:   params.Sort == types.SortOldest,
+		Limit:    params.Limit,
+		Cursor:   params.Cursor,
+	}
+	switch params.Side {
+	case "":
+	case "buy":
+		q.Side = obtypes.SideBuy
+	case "sell":
+		q.Side = obtypes.SideSell
+	default:
+		return q, fmt.Errorf("invalid side: %s", params.Side)
+	}
+	if params.From > 0 {
+		q.From = time.UnixMilli(params.From)
+	}
+	if params.To > 0 {
+		// Inclusive of the whole millisecond
+		q.To = time.UnixMilli(params.To + 1).Add(-time.Nanosecond)
+	}
+	return q, nil
+}
+
+// ============ FundingPaymentService Implementation ============
+
+func (rs *RealService) GetFundingPayments(ctx context.Context, trader string, limit int) ([]*types.FundingPayment, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.perpKeeper == nil {
+		// No funding settlement in standalone mode
+		return []*types.FundingPayment{}, nil
+	}
+
+	payments := rs.perpKeeper.GetFundingPaymentsByTrader(rs.sdkCtx, trader, limit)
+	result := make([]*types.FundingPayment, 0, len(payments))
+	for _, p := range payments {
+		result = append(result, &types.FundingPayment{
+			PaymentID: p.PaymentID,
+			MarketID:  p.MarketID,
+			Amount:    p.Amount.String(),
+			Rate:      p.Rate.String(),
+			Timestamp: p.Timestamp.UnixMilli(),
+		})
+	}
+	return result, nil
+}
+
+// ============ TransferHistoryService Implementation ============
+
+func (rs *RealService) GetTransfers(ctx context.Context, trader string, limit int) ([]*types.Transfer, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.perpKeeper == nil {
+		// No deposits or withdrawals in standalone mode
+		return []*types.Transfer{}, nil
+	}
+
+	transfers := rs.perpKeeper.GetTransfers(rs.sdkCtx, trader, limit)
+	result := make([]*types.Transfer, 0, len(transfers))
+	for _, t := range transfers {
+		result = append(result, fromPerpTransfer(t))
+	}
+	return result, nil
+}
+
+// fromPerpTransfer converts a keeper ledger entry to the API response
+func fromPerpTransfer(t *perptypes.Transfer) *types.Transfer {
+	return &types.Transfer{
+		TransferID:   t.TransferID,
+		Type:         string(t.Kind),
+		Amount:       t.Amount.String(),
+		Balance:      t.Balance.String(),
+		Counterparty: t.Counterparty,
+		Timestamp:    t.Timestamp.UnixMilli(),
+	}
+}
+
 // ============ PositionService Implementation ============
 
 func (rs *RealService) GetPositions(ctx context.Context, trader string) ([]*types.Position, error) {
@@ -391,141 +624,1048 @@ func (rs *RealService) ClosePosition(ctx context.Context, req *types.ClosePositi
 	return nil, fmt.Errorf("not implemented")
 }
 
-// ============ AccountService Implementation ============
+func (rs *RealService) GetPositionHistory(ctx context.Context, req *types.PositionHistoryRequest) (*types.PositionHistoryResponse, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
 
-func (rs *RealService) GetAccount(ctx context.Context, trader string) (*types.Account, error) {
+	resp := &types.PositionHistoryResponse{
+		Positions: []*types.ClosedPosition{},
+		Limit:     req.Limit,
+	}
+	if rs.perpKeeper == nil {
+		// No history in standalone mode
+		return resp, nil
+	}
+
+	closed, next, err := rs.perpKeeper.GetClosedPositions(rs.sdkCtx, req.Trader, req.MarketID, req.Cursor, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range closed {
+		resp.Positions = append(resp.Positions, convertClosedPosition(c))
+	}
+	resp.NextCursor = next
+	return resp, nil
+}
+
+// ============ ADLService Implementation ============
+
+// GetADLIndicators ranks the market's positions at the stored mark price
+func (rs *RealService) GetADLIndicators(ctx context.Context, marketID string) ([]*types.ADLIndicator, error) {
 	rs.mu.RLock()
 	defer rs.mu.RUnlock()
 
 	if rs.perpKeeper == nil {
-		// Return default account for standalone mode
-		return &types.Account{
-			Trader:           trader,
-			Balance:          "10000.00",
-			LockedMargin:     "0.00",
-			AvailableBalance: "10000.00",
-			MarginMode:       "isolated",
-			UpdatedAt:        types.NowMillis(),
-		}, nil
+		return []*types.ADLIndicator{}, nil
 	}
 
-	account := rs.perpKeeper.GetAccount(rs.sdkCtx, trader)
-	if account == nil {
-		return &types.Account{
-			Trader:           trader,
-			Balance:          "0.00",
-			LockedMargin:     "0.00",
-			AvailableBalance: "0.00",
-			MarginMode:       "isolated",
-			UpdatedAt:        types.NowMillis(),
-		}, nil
+	priceInfo := rs.perpKeeper.GetPrice(rs.sdkCtx, marketID)
+	var positions []*types.Position
+	for _, pos := range rs.perpKeeper.GetAllPositions(rs.sdkCtx) {
+		if pos.MarketID != marketID {
+			continue
+		}
+		converted := rs.convertPosition(pos)
+		if priceInfo != nil {
+			converted.MarkPrice = priceInfo.MarkPrice.String()
+			converted.UnrealizedPnl = pos.CalculateUnrealizedPnL(priceInfo.MarkPrice).String()
+		}
+		positions = append(positions, converted)
 	}
-	return rs.convertAccount(account), nil
+	return adlIndicators(marketID, positions, types.NowMillis()), nil
 }
 
-func (rs *RealService) Deposit(ctx context.Context, req *types.DepositRequest) (*types.AccountResponse, error) {
-	rs.mu.Lock()
-	defer rs.mu.Unlock()
+// ============ Kline Implementation ============
 
+// HasKlines reports whether candles are read from the perpetual keeper's
+// kline store
+func (rs *RealService) HasKlines() bool {
+	return rs.perpKeeper != nil
+}
+
+// GetKlines returns the latest limit candles of a market from the perpetual
+// keeper, oldest first
+func (rs *RealService) GetKlines(marketID, interval string, limit int) ([]KlineData, error) {
 	if rs.perpKeeper == nil {
-		return nil, fmt.Errorf("deposit not available in standalone mode")
+		return nil, fmt.Errorf("klines unavailable: no perpetual keeper attached")
 	}
 
-	amount, _ := math.LegacyNewDecFromStr(req.Amount)
-	err := rs.perpKeeper.Deposit(ctx, req.Trader, amount)
-	if err != nil {
-		return nil, err
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	sdkCtx := rs.clockCtx()
+	klines := rs.perpKeeper.GetKlines(sdkCtx, marketID, perpkeeper.KlineInterval(interval), 0, sdkCtx.BlockTime().Unix(), limit)
+	result := make([]KlineData, 0, len(klines))
+	for _, k := range klines {
+		result = append(result, KlineData{
+			Time:   k.Timestamp,
+			Open:   k.Open.MustFloat64(),
+			High:   k.High.MustFloat64(),
+			Low:    k.Low.MustFloat64(),
+			Close:  k.Close.MustFloat64(),
+			Volume: k.Volume.MustFloat64(),
+		})
 	}
+	return result, nil
+}
 
-	account := rs.perpKeeper.GetAccount(rs.sdkCtx, req.Trader)
-	return &types.AccountResponse{Account: rs.convertAccount(account)}, nil
+// ============ InvariantService Implementation ============
+
+// RunInvariants checks the orderbook invariants, and the perpetual ones when a
+// perpetual keeper is attached
+func (rs *RealService) RunInvariants(ctx context.Context) (*types.InvariantReport, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	registry := &invariantRegistry{}
+	obkeeper.RegisterInvariants(registry, rs.obKeeper)
+	if rs.perpKeeper != nil {
+		perpkeeper.RegisterInvariants(registry, rs.perpKeeper)
+	}
+	return registry.run(rs.sdkCtx), nil
 }
 
-func (rs *RealService) Withdraw(ctx context.Context, req *types.WithdrawRequest) (*types.AccountResponse, error) {
-	rs.mu.Lock()
-	defer rs.mu.Unlock()
+// ============ TradingScheduleService Implementation ============
+
+// clockCtx stamps the API context, which has no block time, with the wall
+// clock. Trading schedules and market maker protection windows are evaluated
+// against it.
+func (rs *RealService) clockCtx() sdk.Context {
+	return rs.sdkCtx.WithBlockTime(time.Now().UTC())
+}
 
+func (rs *RealService) GetTradingSchedule(ctx context.Context, marketID string) (*types.TradingSchedule, error) {
 	if rs.perpKeeper == nil {
-		return nil, fmt.Errorf("withdraw not available in standalone mode")
+		return rs.schedules.GetTradingSchedule(ctx, marketID)
 	}
 
-	amount, _ := math.LegacyNewDecFromStr(req.Amount)
-	err := rs.perpKeeper.Withdraw(ctx, req.Trader, amount)
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	return fromPerpSchedule(marketID, rs.perpKeeper.GetTradingSchedule(rs.sdkCtx, marketID)), nil
+}
+
+func (rs *RealService) SetTradingSchedule(ctx context.Context, req *types.TradingSchedule) (*types.TradingSchedule, error) {
+	if rs.perpKeeper == nil {
+		return rs.schedules.SetTradingSchedule(ctx, req)
+	}
+
+	schedule, err := toPerpSchedule(req)
 	if err != nil {
-		return nil, err
+		return nil, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error())
 	}
 
-	account := rs.perpKeeper.GetAccount(rs.sdkCtx, req.Trader)
-	return &types.AccountResponse{Account: rs.convertAccount(account)}, nil
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if err := rs.perpKeeper.SetTradingSchedule(rs.clockCtx(), schedule); err != nil {
+		return nil, err
+	}
+	return fromPerpSchedule(req.MarketID, rs.perpKeeper.GetTradingSchedule(rs.sdkCtx, req.MarketID)), nil
 }
 
-// ============ Conversion Helpers ============
-
-func (rs *RealService) convertOrder(order *obtypes.Order) *types.Order {
-	if order == nil {
-		return nil
+func (rs *RealService) DeleteTradingSchedule(ctx context.Context, marketID string) error {
+	if rs.perpKeeper == nil {
+		return rs.schedules.DeleteTradingSchedule(ctx, marketID)
 	}
-	return &types.Order{
-		OrderID:   order.OrderID,
-		Trader:    order.Trader,
-		MarketID:  order.MarketID,
-		Side:      order.Side.String(),
-		Type:      order.OrderType.String(),
-		Price:     order.Price.String(),
-		Quantity:  order.Quantity.String(),
-		FilledQty: order.FilledQty.String(),
-		Status:    order.Status.String(),
-		CreatedAt: order.CreatedAt.UnixMilli(),
-		UpdatedAt: order.UpdatedAt.UnixMilli(),
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.perpKeeper.DeleteTradingSchedule(rs.sdkCtx, marketID)
+	return nil
+}
+
+func (rs *RealService) GetTradingStatus(ctx context.Context, marketID string) (*types.TradingStatus, error) {
+	if rs.perpKeeper == nil {
+		return rs.schedules.GetTradingStatus(ctx, marketID)
 	}
+
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	return fromPerpStatus(rs.perpKeeper.GetTradingStatus(rs.clockCtx(), marketID)), nil
 }
 
-func (rs *RealService) convertMatchResult(result *obkeeper.MatchResult) *types.MatchResult {
-	if result == nil {
-		return &types.MatchResult{
-			FilledQty:    "0.00",
-			AvgPrice:     "0.00",
-			RemainingQty: "0.00",
-			Trades:       []types.TradeInfo{},
-		}
+// ============ MMPService Implementation ============
+
+func (rs *RealService) GetMMP(ctx context.Context, trader string) ([]*types.MMPStatus, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	sdkCtx := rs.clockCtx()
+	result := make([]*types.MMPStatus, 0)
+	for _, config := range rs.obKeeper.GetMMPConfigsByTrader(sdkCtx, trader) {
+		result = append(result, rs.mmpStatus(sdkCtx, config))
 	}
+	return result, nil
+}
 
-	trades := make([]types.TradeInfo, 0, len(result.Trades))
-	for _, t := range result.Trades {
-		trades = append(trades, types.TradeInfo{
-			TradeID:   t.TradeID,
-			Price:     t.Price.String(),
-			Quantity:  t.Quantity.String(),
-			Timestamp: t.Timestamp.UnixMilli(),
-		})
+func (rs *RealService) SetMMP(ctx context.Context, trader string, req *types.MMPConfig) (*types.MMPStatus, error) {
+	config, err := toOBMMPConfig(trader, req)
+	if err != nil {
+		return nil, err
 	}
 
-	return &types.MatchResult{
-		FilledQty:    result.FilledQty.String(),
-		AvgPrice:     result.AvgPrice.String(),
-		RemainingQty: result.RemainingQty.String(),
-		Trades:       trades,
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	sdkCtx := rs.clockCtx()
+	if err := rs.obKeeper.SetMMPConfig(sdkCtx, config); err != nil {
+		return nil, err
 	}
+	return rs.mmpStatus(sdkCtx, config), nil
 }
 
-func (rs *RealService) convertPlaceOrderResponse(order *obtypes.Order, result *obkeeper.MatchResult) *types.PlaceOrderResponse {
-	return &types.PlaceOrderResponse{
-		Order: rs.convertOrder(order),
-		Match: rs.convertMatchResult(result),
+func (rs *RealService) DeleteMMP(ctx context.Context, trader, marketID string) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.obKeeper.GetMMPConfig(rs.sdkCtx, trader, marketID) == nil {
+		return types.NewAPIError(types.ErrCodeNotFound, "no market maker protection for market "+marketID)
 	}
+	rs.obKeeper.DeleteMMPConfig(rs.sdkCtx, trader, marketID)
+	return nil
 }
 
-func (rs *RealService) convertPosition(pos *perptypes.Position) *types.Position {
-	if pos == nil {
-		return nil
+func (rs *RealService) ResetMMP(ctx context.Context, trader, marketID string) (*types.MMPStatus, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	sdkCtx := rs.clockCtx()
+	config := rs.obKeeper.GetMMPConfig(sdkCtx, trader, marketID)
+	if config == nil {
+		return nil, types.NewAPIError(types.ErrCodeNotFound, "no market maker protection for market "+marketID)
 	}
-	// Calculate mark price and unrealized PnL (using entry price as placeholder for standalone mode)
-	markPrice := pos.EntryPrice // In full mode, this would come from oracle
-	unrealizedPnL := pos.CalculateUnrealizedPnL(markPrice)
+	rs.obKeeper.ResetMMP(sdkCtx, trader, marketID)
+	return rs.mmpStatus(sdkCtx, config), nil
+}
 
-	return &types.Position{
-		MarketID:         pos.MarketID,
-		Trader:           pos.Trader,
+// ============ FeePreferenceService Implementation ============
+
+func (rs *RealService) GetFeePreference(ctx context.Context, trader string) (*types.FeePreference, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	sdkCtx := rs.clockCtx()
+	return rs.feePreference(sdkCtx, rs.obKeeper.GetFeePreference(sdkCtx, trader)), nil
+}
+
+func (rs *RealService) SetFeePreference(ctx context.Context, trader string, payInFeeToken bool) (*types.FeePreference, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	sdkCtx := rs.clockCtx()
+	pref := &obtypes.FeePreference{Trader: trader, PayInFeeToken: payInFeeToken}
+	rs.obKeeper.SetFeePreference(sdkCtx, pref)
+	return rs.feePreference(sdkCtx, pref), nil
+}
+
+// feePreference reports a preference with the current fee token terms
+func (rs *RealService) feePreference(sdkCtx sdk.Context, pref *obtypes.FeePreference) *types.FeePreference {
+	config := rs.obKeeper.GetFeeTokenConfig(sdkCtx)
+	result := &types.FeePreference{
+		PayInFeeToken: pref.PayInFeeToken,
+		FeeToken: &types.FeeToken{
+			Enabled:  config.Enabled,
+			Denom:    config.Denom,
+			Discount: config.Discount.String(),
+		},
+	}
+	if rate, ok := rs.obKeeper.FeeTokenRate(sdkCtx, config); ok {
+		result.FeeToken.Rate = rate.String()
+	}
+	if !pref.UpdatedAt.IsZero() {
+		result.UpdatedAt = pref.UpdatedAt.UnixMilli()
+	}
+	return result
+}
+
+// ============ OrderLimitsService Implementation ============
+
+func (rs *RealService) GetOrderLimits(ctx context.Context) (*types.OrderLimitsStatus, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	return rs.orderLimitsStatus(rs.clockCtx()), nil
+}
+
+func (rs *RealService) SetOrderLimits(ctx context.Context, limits *types.OrderLimits) (*types.OrderLimitsStatus, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	sdkCtx := rs.clockCtx()
+	if err := rs.obKeeper.SetOrderLimits(sdkCtx, &obtypes.OrderLimits{
+		MaxRestingPerMarket: limits.MaxRestingPerMarket,
+		MaxRestingPerTrader: limits.MaxRestingPerTrader,
+	}); err != nil {
+		return nil, err
+	}
+	return rs.orderLimitsStatus(sdkCtx), nil
+}
+
+// orderLimitsStatus reports the order limits with each market's resting count
+func (rs *RealService) orderLimitsStatus(sdkCtx sdk.Context) *types.OrderLimitsStatus {
+	limits := rs.obKeeper.GetOrderLimits(sdkCtx)
+	status := &types.OrderLimitsStatus{
+		Limits: &types.OrderLimits{
+			MaxRestingPerMarket: limits.MaxRestingPerMarket,
+			MaxRestingPerTrader: limits.MaxRestingPerTrader,
+		},
+		Resting: rs.obKeeper.GetRestingOrderCounts(sdkCtx),
+	}
+	if !limits.UpdatedAt.IsZero() {
+		status.Limits.UpdatedAt = limits.UpdatedAt.UnixMilli()
+	}
+	return status
+}
+
+// mmpStatus reports a config with its state at the context's time
+func (rs *RealService) mmpStatus(sdkCtx sdk.Context, config *obtypes.MMPConfig) *types.MMPStatus {
+	state := rs.obKeeper.GetMMPState(sdkCtx, config.Trader, config.MarketID)
+	state.Prune(sdkCtx.BlockTime(), config.Window)
+	fills, delta, volume := state.Totals()
+
+	status := &types.MMPStatus{
+		Config:        fromOBMMPConfig(config),
+		Frozen:        state.IsFrozen(sdkCtx.BlockTime()),
+		TriggerReason: state.TriggerReason,
+		Fills:         fills,
+		Delta:         delta.String(),
+		Volume:        volume.String(),
+	}
+	if status.Frozen {
+		status.FrozenUntil = state.FrozenUntil.UnixMilli()
+	}
+	if !state.TriggeredAt.IsZero() {
+		status.TriggeredAt = state.TriggeredAt.UnixMilli()
+	}
+	return status
+}
+
+// ============ LPObligationService Implementation ============
+
+func (rs *RealService) ListLPObligations(ctx context.Context, trader string) ([]*types.LPObligation, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	sdkCtx := rs.clockCtx()
+	var obligations []*obtypes.LPObligation
+	if trader == "" {
+		obligations = rs.obKeeper.GetAllLPObligations(sdkCtx)
+	} else {
+		obligations = rs.obKeeper.GetLPObligationsByTrader(sdkCtx, trader)
+	}
+	result := make([]*types.LPObligation, 0, len(obligations))
+	for _, obligation := range obligations {
+		result = append(result, fromOBLPObligation(obligation))
+	}
+	return result, nil
+}
+
+func (rs *RealService) SetLPObligation(ctx context.Context, req *types.LPObligation) (*types.LPObligation, error) {
+	obligation, err := toOBLPObligation(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if err := rs.obKeeper.SetLPObligation(rs.clockCtx(), obligation); err != nil {
+		return nil, err
+	}
+	return fromOBLPObligation(obligation), nil
+}
+
+func (rs *RealService) DeleteLPObligation(ctx context.Context, trader, marketID string) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.obKeeper.GetLPObligation(rs.sdkCtx, trader, marketID) == nil {
+		return types.NewAPIError(types.ErrCodeNotFound, "no LP obligation for market "+marketID)
+	}
+	rs.obKeeper.DeleteLPObligation(rs.sdkCtx, trader, marketID)
+	return nil
+}
+
+func (rs *RealService) GetLPUptime(ctx context.Context, trader string, epoch int64) (*types.LPUptimeReport, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	sdkCtx := rs.clockCtx()
+	if epoch < 0 {
+		epoch = obtypes.LPEpoch(sdkCtx.BlockTime())
+	}
+	met, _ := rs.obKeeper.LPObligationMet(sdkCtx, trader, epoch)
+	report := &types.LPUptimeReport{
+		Trader:     trader,
+		Epoch:      epoch,
+		EpochStart: obtypes.LPEpochStart(epoch).UnixMilli(),
+		EpochEnd:   obtypes.LPEpochStart(epoch + 1).UnixMilli(),
+		Markets:    make([]*types.LPUptime, 0),
+		Met:        met,
+		Eligible:   rs.obKeeper.LPObligationEligible(sdkCtx, trader),
+	}
+	for _, uptime := range rs.obKeeper.GetLPUptimeReports(sdkCtx, trader, epoch) {
+		report.Markets = append(report.Markets, fromOBLPUptime(uptime))
+	}
+	return report, nil
+}
+
+// ============ FeeLedgerService Implementation ============
+
+func (rs *RealService) GetDailyFees(ctx context.Context, from, to int64, marketID string) ([]*types.FeeRollup, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	fromDay := obtypes.FeeDay(time.UnixMilli(from))
+	toDay := obtypes.FeeDay(time.UnixMilli(to))
+	rollups := rs.obKeeper.GetFeeRollups(rs.sdkCtx, fromDay, toDay, marketID)
+	result := make([]*types.FeeRollup, 0, len(rollups))
+	for _, rollup := range rollups {
+		result = append(result, fromOBFeeRollup(rollup))
+	}
+	return result, nil
+}
+
+func (rs *RealService) GetFeeBalances(ctx context.Context) (*types.FeeBalances, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	return fromOBFeeBalances(rs.obKeeper.GetFeeBalances(rs.sdkCtx)), nil
+}
+
+// ============ TreasuryService Implementation ============
+
+// SetTreasuryKeeper attaches the treasury module, whose store must be mounted
+// in the service's context
+func (rs *RealService) SetTreasuryKeeper(k *treasurykeeper.Keeper) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.treasuryKeeper = k
+}
+
+func (rs *RealService) GetTreasury(ctx context.Context) (*types.Treasury, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.treasuryKeeper == nil {
+		return nil, types.NewAPIError(types.ErrCodeNotImplemented, "Treasury requires a chain-backed service")
+	}
+	balance := rs.treasuryKeeper.GetBalance(rs.sdkCtx)
+	params := rs.treasuryKeeper.GetParams(rs.sdkCtx)
+	return &types.Treasury{
+		Balance:       balance.Balance.String(),
+		TotalReceived: balance.TotalReceived.String(),
+		TotalSpent:    balance.TotalSpent.String(),
+		Params: types.TreasuryParams{
+			InsuranceFund: params.InsuranceFund.String(),
+			RiverPool:     params.RiverPool.String(),
+			Treasury:      params.Treasury.String(),
+		},
+	}, nil
+}
+
+func (rs *RealService) GetTreasuryHistory(ctx context.Context, kind string, before uint64, limit int) ([]*types.TreasuryEntry, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.treasuryKeeper == nil {
+		return nil, types.NewAPIError(types.ErrCodeNotImplemented, "Treasury requires a chain-backed service")
+	}
+	entries := rs.treasuryKeeper.GetHistory(rs.sdkCtx, treasurytypes.EntryKind(kind), before, limit)
+	result := make([]*types.TreasuryEntry, 0, len(entries))
+	for _, e := range entries {
+		entry := &types.TreasuryEntry{
+			Seq:         e.Seq,
+			Kind:        string(e.Kind),
+			Amount:      e.Amount.String(),
+			Balance:     e.Balance.String(),
+			MarketID:    e.MarketID,
+			Recipient:   e.Recipient,
+			Reason:      e.Reason,
+			BlockHeight: e.BlockHeight,
+			Timestamp:   e.Timestamp.UnixMilli(),
+		}
+		if e.Kind == treasurytypes.EntryFees {
+			entry.Day = feeDayLabel(e.Day)
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// ============ WithdrawalSecurityService Implementation ============
+
+// withdrawalSecurityUnavailable is returned without a perpetual keeper, which
+// holds the balances withdrawals come out of
+func withdrawalSecurityUnavailable() error {
+	return types.NewAPIError(types.ErrCodeNotImplemented, "Withdrawal security requires a keeper-backed service")
+}
+
+func (rs *RealService) GetWithdrawalSecurity(ctx context.Context, trader string) (*types.WithdrawalSecurity, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.perpKeeper == nil {
+		return nil, withdrawalSecurityUnavailable()
+	}
+	return fromPerpWithdrawalSecurity(rs.perpKeeper.GetWithdrawalSecurity(rs.clockCtx(), trader)), nil
+}
+
+func (rs *RealService) SetWithdrawalSecurity(ctx context.Context, trader string, req *types.SetWithdrawalSecurityRequest) (*types.WithdrawalSecurity, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.perpKeeper == nil {
+		return nil, withdrawalSecurityUnavailable()
+	}
+	sdkCtx := rs.clockCtx()
+	current := rs.perpKeeper.GetWithdrawalSecurity(sdkCtx, trader)
+	timelock, allowlist := current.Timelock, current.AllowlistEnabled
+	if current.Pending != nil {
+		// Fields the request leaves out keep their scheduled values
+		timelock, allowlist = current.Pending.Timelock, current.Pending.AllowlistEnabled
+	}
+	if req.TimelockSeconds != nil {
+		timelock = time.Duration(*req.TimelockSeconds) * time.Second
+	}
+	if req.AllowlistEnabled != nil {
+		allowlist = *req.AllowlistEnabled
+	}
+	security, err := rs.perpKeeper.SetWithdrawalSecurity(sdkCtx, trader, timelock, allowlist)
+	if err != nil {
+		return nil, err
+	}
+	return fromPerpWithdrawalSecurity(security), nil
+}
+
+func (rs *RealService) GetWithdrawalAddresses(ctx context.Context, trader string) ([]*types.WithdrawalAddress, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.perpKeeper == nil {
+		return nil, withdrawalSecurityUnavailable()
+	}
+	now := time.Now()
+	addresses := rs.perpKeeper.GetWithdrawalAddresses(rs.sdkCtx, trader)
+	result := make([]*types.WithdrawalAddress, 0, len(addresses))
+	for _, a := range addresses {
+		result = append(result, fromPerpWithdrawalAddress(a, now))
+	}
+	return result, nil
+}
+
+func (rs *RealService) AddWithdrawalAddress(ctx context.Context, trader, address, label string) (*types.WithdrawalAddress, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.perpKeeper == nil {
+		return nil, withdrawalSecurityUnavailable()
+	}
+	sdkCtx := rs.clockCtx()
+	entry, err := rs.perpKeeper.AddWithdrawalAddress(sdkCtx, trader, address, label)
+	if err != nil {
+		return nil, err
+	}
+	return fromPerpWithdrawalAddress(entry, sdkCtx.BlockTime()), nil
+}
+
+func (rs *RealService) RemoveWithdrawalAddress(ctx context.Context, trader, address string) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.perpKeeper == nil {
+		return withdrawalSecurityUnavailable()
+	}
+	return rs.perpKeeper.RemoveWithdrawalAddress(rs.clockCtx(), trader, address)
+}
+
+func (rs *RealService) GetWithdrawals(ctx context.Context, trader string, limit int) ([]*types.Withdrawal, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.perpKeeper == nil {
+		return nil, withdrawalSecurityUnavailable()
+	}
+	now := time.Now()
+	withdrawals := rs.perpKeeper.GetWithdrawals(rs.sdkCtx, trader, limit)
+	result := make([]*types.Withdrawal, 0, len(withdrawals))
+	for _, w := range withdrawals {
+		result = append(result, fromPerpWithdrawal(w, now))
+	}
+	return result, nil
+}
+
+func (rs *RealService) CancelWithdrawal(ctx context.Context, trader, withdrawalID string) (*types.Withdrawal, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.perpKeeper == nil {
+		return nil, withdrawalSecurityUnavailable()
+	}
+	sdkCtx := rs.clockCtx()
+	withdrawal, err := rs.perpKeeper.CancelWithdrawal(sdkCtx, trader, withdrawalID)
+	if err != nil {
+		return nil, err
+	}
+	return fromPerpWithdrawal(withdrawal, sdkCtx.BlockTime()), nil
+}
+
+// ReleaseWithdrawals pays out the withdrawals whose timelock has passed, as
+// the chain's EndBlocker does, for the standalone server's release scheduler
+func (rs *RealService) ReleaseWithdrawals(ctx context.Context) ([]*types.Withdrawal, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.perpKeeper == nil {
+		return nil, nil
+	}
+	sdkCtx := rs.clockCtx()
+	released := rs.perpKeeper.ReleaseWithdrawals(sdkCtx)
+	result := make([]*types.Withdrawal, 0, len(released))
+	for _, w := range released {
+		result = append(result, fromPerpWithdrawal(w, sdkCtx.BlockTime()))
+	}
+	return result, nil
+}
+
+// ============ AccountService Implementation ============
+
+func (rs *RealService) GetAccount(ctx context.Context, trader string) (*types.Account, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.perpKeeper == nil {
+		return rs.accountOrDefault(trader, nil), nil
+	}
+	return rs.accountOrDefault(trader, rs.perpKeeper.GetAccount(rs.sdkCtx, trader)), nil
+}
+
+// BatchQueryAccounts returns the summaries of many traders using one pass
+// over the position and order stores instead of one per trader
+func (rs *RealService) BatchQueryAccounts(ctx context.Context, traders []string) ([]*types.AccountSummary, error) {
+	snapshot, err := rs.SnapshotAccounts(ctx, traders)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Accounts, nil
+}
+
+// SnapshotAccounts returns the summaries of many traders read under one
+// lock, with the block height of the state they were read from
+func (rs *RealService) SnapshotAccounts(ctx context.Context, traders []string) (*types.AccountSnapshot, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	openOrders := rs.obKeeper.CountOpenOrdersByTraders(rs.sdkCtx, traders)
+	var accounts map[string]*perptypes.Account
+	var positions map[string][]*perptypes.Position
+	if rs.perpKeeper != nil {
+		accounts = rs.perpKeeper.GetAccounts(rs.sdkCtx, traders)
+		positions = rs.perpKeeper.GetPositionsByTraders(rs.sdkCtx, traders)
+	}
+
+	summaries := make([]*types.AccountSummary, 0, len(traders))
+	for _, trader := range traders {
+		summary := &types.AccountSummary{
+			Trader:     trader,
+			Account:    rs.accountOrDefault(trader, accounts[trader]),
+			Positions:  []*types.Position{},
+			OpenOrders: openOrders[trader],
+		}
+		for _, pos := range positions[trader] {
+			summary.Positions = append(summary.Positions, rs.convertPosition(pos))
+		}
+		summaries = append(summaries, summary)
+	}
+	return &types.AccountSnapshot{Height: rs.sdkCtx.BlockHeight(), Accounts: summaries}, nil
+}
+
+// ListTraders implements types.TraderLister over the stored perpetual
+// accounts; standalone mode has none
+func (rs *RealService) ListTraders(ctx context.Context) ([]string, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.perpKeeper == nil {
+		return []string{}, nil
+	}
+	accounts := rs.perpKeeper.GetAllAccounts(rs.sdkCtx)
+	traders := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		traders = append(traders, account.Trader)
+	}
+	return traders, nil
+}
+
+// accountOrDefault converts a stored account, or returns the default for a
+// trader without one: funded in standalone mode, empty otherwise
+func (rs *RealService) accountOrDefault(trader string, account *perptypes.Account) *types.Account {
+	if account == nil && rs.simplePerp != nil {
+		account = rs.simplePerp.GetAccount(trader)
+	}
+	if account != nil {
+		return rs.convertAccount(account)
+	}
+	balance := "0.00"
+	if rs.perpKeeper == nil {
+		balance = "10000.00"
+	}
+	return &types.Account{
+		Trader:           trader,
+		Balance:          balance,
+		LockedMargin:     "0.00",
+		AvailableBalance: balance,
+		MarginMode:       "isolated",
+		UpdatedAt:        types.NowMillis(),
+	}
+}
+
+func (rs *RealService) Deposit(ctx context.Context, req *types.DepositRequest) (*types.AccountResponse, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	amount, err := math.LegacyNewDecFromStr(req.Amount)
+	if err != nil || !amount.IsPositive() {
+		return nil, types.NewAPIError(types.ErrCodeInvalidRequest, "amount must be a positive decimal")
+	}
+	if rs.perpKeeper == nil {
+		return &types.AccountResponse{Account: rs.convertAccount(rs.simplePerp.Deposit(req.Trader, amount))}, nil
+	}
+
+	err = rs.perpKeeper.Deposit(rs.clockCtx(), req.Trader, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	account := rs.perpKeeper.GetAccount(rs.sdkCtx, req.Trader)
+	return &types.AccountResponse{Account: rs.convertAccount(account)}, nil
+}
+
+func (rs *RealService) Withdraw(ctx context.Context, req *types.WithdrawRequest) (*types.AccountResponse, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.perpKeeper == nil {
+		return nil, fmt.Errorf("withdraw not available in standalone mode")
+	}
+
+	amount, _ := math.LegacyNewDecFromStr(req.Amount)
+	sdkCtx := rs.clockCtx()
+	withdrawal, err := rs.perpKeeper.RequestWithdrawal(sdkCtx, req.Trader, amount, req.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	account := rs.perpKeeper.GetAccount(rs.sdkCtx, req.Trader)
+	return &types.AccountResponse{
+		Account:    rs.convertAccount(account),
+		Withdrawal: fromPerpWithdrawal(withdrawal, sdkCtx.BlockTime()),
+	}, nil
+}
+
+// InitializeTestAccount sets a trader's balance to exactly balance, for dev
+// and test accounts. Unlike Deposit it adds no testing balance.
+func (rs *RealService) InitializeTestAccount(trader string, balance string) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	balanceDec, err := math.LegacyNewDecFromStr(balance)
+	if err != nil || balanceDec.IsNegative() {
+		return types.NewAPIError(types.ErrCodeInvalidRequest, "balance must be a non-negative decimal")
+	}
+	if rs.perpKeeper == nil {
+		rs.simplePerp.SetBalance(trader, balanceDec)
+		return nil
+	}
+
+	account := rs.perpKeeper.GetAccount(rs.sdkCtx, trader)
+	if account == nil {
+		account = perptypes.NewAccount(trader)
+	}
+	account.Balance = balanceDec
+	rs.perpKeeper.SetAccount(rs.sdkCtx, account)
+	return nil
+}
+
+// DebitCollateral takes amount out of the trader's balance through the
+// keeper's plain debit, not a withdrawal request
+func (rs *RealService) DebitCollateral(ctx context.Context, trader, amount string) (*types.AccountResponse, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.perpKeeper == nil {
+		return nil, fmt.Errorf("debit not available in standalone mode")
+	}
+
+	value, err := math.LegacyNewDecFromStr(amount)
+	if err != nil || !value.IsPositive() {
+		return nil, types.NewAPIError(types.ErrCodeInvalidRequest, "amount must be a positive decimal")
+	}
+	if err := rs.perpKeeper.Withdraw(rs.clockCtx(), trader, value); err != nil {
+		return nil, err
+	}
+
+	account := rs.perpKeeper.GetAccount(rs.sdkCtx, trader)
+	return &types.AccountResponse{Account: rs.convertAccount(account)}, nil
+}
+
+// ============ Conversion Helpers ============
+
+// ============ MarketDataService Implementation ============
+
+func (rs *RealService) GetOrderBook(ctx context.Context, marketID string, depth int) (*types.OrderBookSnapshot, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	snapshot := &types.OrderBookSnapshot{
+		MarketID:  marketID,
+		Bids:      [][]string{},
+		Asks:      [][]string{},
+		Timestamp: time.Now().UnixMilli(),
+	}
+
+	ob := rs.obKeeper.GetOrderBook(rs.sdkCtx, marketID)
+	if ob == nil {
+		return snapshot, nil
+	}
+	snapshot.Bids, snapshot.Asks = ob.Levels(depth)
+	snapshot.Checksum = obtypes.DepthChecksum(snapshot.Bids, snapshot.Asks)
+	return snapshot, nil
+}
+
+// GetOrderBooks reads the books of the given markets under one read lock,
+// so no order lands between them
+func (rs *RealService) GetOrderBooks(ctx context.Context, marketIDs []string, depth int) (*types.OrderBooks, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	books := &types.OrderBooks{
+		Depth:     depth,
+		Books:     make([]*types.OrderBookSnapshot, 0, len(marketIDs)),
+		Timestamp: time.Now().UnixMilli(),
+	}
+	for _, marketID := range marketIDs {
+		book := &types.OrderBookSnapshot{
+			MarketID:  marketID,
+			Bids:      [][]string{},
+			Asks:      [][]string{},
+			Timestamp: books.Timestamp,
+		}
+		if ob := rs.obKeeper.GetOrderBook(rs.sdkCtx, marketID); ob != nil {
+			book.Bids, book.Asks = ob.Levels(depth)
+		}
+		book.Checksum = obtypes.DepthChecksum(book.Bids, book.Asks)
+		books.Books = append(books.Books, book)
+	}
+	return books, nil
+}
+
+// GetSnapshot reads the books, market state and event sequence of the given
+// markets under one read lock, so no order or trade lands between markets.
+// Without a perpetual keeper the price and funding fields are left empty.
+func (rs *RealService) GetSnapshot(ctx context.Context, marketIDs []string, depth int) (*types.ExchangeSnapshot, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	snapshot := &types.ExchangeSnapshot{
+		Seq:       rs.obKeeper.GetLastEventSeq(rs.sdkCtx),
+		Depth:     depth,
+		Markets:   make([]*types.MarketSnapshot, 0, len(marketIDs)),
+		Timestamp: time.Now().UnixMilli(),
+	}
+	for _, marketID := range marketIDs {
+		market := &types.MarketSnapshot{
+			MarketID: marketID,
+			Bids:     [][]string{},
+			Asks:     [][]string{},
+		}
+		if ob := rs.obKeeper.GetOrderBook(rs.sdkCtx, marketID); ob != nil {
+			market.Bids, market.Asks = ob.Levels(depth)
+		}
+		market.Checksum = obtypes.DepthChecksum(market.Bids, market.Asks)
+
+		if rs.perpKeeper != nil {
+			if stats := rs.perpKeeper.GetMarketStats(rs.sdkCtx, marketID); stats != nil {
+				market.OpenInterest = stats.OpenInterest.String()
+				market.FundingRate = stats.FundingRate.String()
+				market.NextFunding = stats.NextFundingTime.Unix()
+				if !stats.MarkPrice.IsNil() {
+					market.MarkPrice = stats.MarkPrice.String()
+					market.IndexPrice = stats.IndexPrice.String()
+					market.LastPrice = stats.LastPrice.String()
+				}
+			}
+		}
+		snapshot.Markets = append(snapshot.Markets, market)
+	}
+	return snapshot, nil
+}
+
+func (rs *RealService) GetRecentTrades(ctx context.Context, marketID string, limit int) ([]*types.MarketTrade, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	trades := rs.obKeeper.GetRecentTrades(rs.sdkCtx, marketID, limit)
+	result := make([]*types.MarketTrade, 0, len(trades))
+	for _, t := range trades {
+		result = append(result, convertMarketTrade(t))
+	}
+	return result, nil
+}
+
+// GetEvents returns a page of the engine's global event log from fromSeq on
+func (rs *RealService) GetEvents(ctx context.Context, fromSeq uint64, limit int) (*types.EventsResponse, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	resp := &types.EventsResponse{
+		Events:  make([]*types.Event, 0),
+		NextSeq: fromSeq,
+		LastSeq: rs.obKeeper.GetLastEventSeq(rs.sdkCtx),
+	}
+	for _, e := range rs.obKeeper.GetEvents(rs.sdkCtx, fromSeq, limit) {
+		event := &types.Event{
+			Seq:       e.Seq,
+			Type:      e.Type,
+			MarketID:  e.MarketID,
+			Timestamp: e.Timestamp.UnixMilli(),
+		}
+		if e.Order != nil {
+			event.Order = rs.convertOrder(e.Order)
+		}
+		if e.Trade != nil {
+			event.Trade = convertMarketTrade(e.Trade)
+			event.Trade.TakerOrderID = e.Trade.TakerOrderID
+			event.Trade.MakerOrderID = e.Trade.MakerOrderID
+		}
+		resp.Events = append(resp.Events, event)
+		resp.NextSeq = e.Seq + 1
+	}
+	return resp, nil
+}
+
+func convertMarketTrade(t *obtypes.Trade) *types.MarketTrade {
+	return &types.MarketTrade{
+		TradeID:   t.TradeID,
+		MarketID:  t.MarketID,
+		Price:     t.Price.String(),
+		Quantity:  t.Quantity.String(),
+		Side:      t.TakerSide.String(),
+		Timestamp: t.Timestamp.UnixMilli(),
+		Seq:       t.Seq,
+	}
+}
+
+// GetMarketAnalytics returns the analytics the keeper tracks, first
+// refreshing the markets written by flushes since the last call
+func (rs *RealService) GetMarketAnalytics(ctx context.Context, marketID string) (*types.MarketAnalytics, error) {
+	rs.mu.RLock()
+	rs.obKeeper.RefreshAnalytics(rs.sdkCtx)
+	rs.mu.RUnlock()
+
+	a := rs.obKeeper.GetBookAnalytics(marketID)
+	if a == nil {
+		a = obkeeper.ComputeBookAnalytics(marketID, nil, nil)
+		a.VolatilityWindow = obkeeper.DefaultVolatilityWindow
+	}
+	return newMarketAnalytics(a), nil
+}
+
+func (rs *RealService) convertOrder(order *obtypes.Order) *types.Order {
+	if order == nil {
+		return nil
+	}
+	o := &types.Order{
+		OrderID:   order.OrderID,
+		Trader:    order.Trader,
+		MarketID:  order.MarketID,
+		Side:      order.Side.String(),
+		Type:      order.OrderType.String(),
+		Price:     order.Price.String(),
+		Quantity:  order.Quantity.String(),
+		FilledQty: order.FilledQty.String(),
+		Status:    order.Status.String(),
+		CreatedAt: order.CreatedAt.UnixMilli(),
+		UpdatedAt: order.UpdatedAt.UnixMilli(),
+		Seq:       order.Seq,
+	}
+	if order.ExpireAt != nil {
+		o.TimeInForce = obtypes.TimeInForceGTD.String()
+		o.ExpireAt = order.ExpireAt.UnixMilli()
+	}
+	if order.HasPriceCap() {
+		o.PriceCap = order.PriceCap.String()
+	}
+	return o
+}
+
+// ExpireOrders expires the good-till-date orders due by now, as the chain's
+// EndBlocker does, for the standalone server's expiry scheduler
+func (rs *RealService) ExpireOrders(ctx context.Context) ([]*types.Order, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	expired := rs.obKeeper.ExpireOrders(rs.clockCtx())
+	if len(expired) == 0 {
+		return nil, nil
+	}
+	rs.matchEngine.Flush(rs.sdkCtx)
+
+	orders := make([]*types.Order, 0, len(expired))
+	for _, order := range expired {
+		orders = append(orders, rs.convertOrder(order))
+	}
+	return orders, nil
+}
+
+func (rs *RealService) convertMatchResult(result *obkeeper.MatchResult) *types.MatchResult {
+	if result == nil {
+		return &types.MatchResult{
+			FilledQty:    "0.00",
+			AvgPrice:     "0.00",
+			RemainingQty: "0.00",
+			Trades:       []types.TradeInfo{},
+		}
+	}
+
+	trades := make([]types.TradeInfo, 0, len(result.Trades))
+	for _, t := range result.Trades {
+		trades = append(trades, types.TradeInfo{
+			TradeID:      t.TradeID,
+			Price:        t.Price.String(),
+			Quantity:     t.Quantity.String(),
+			Timestamp:    t.Timestamp.UnixMilli(),
+			Maker:        t.Maker,
+			MakerOrderID: t.MakerOrderID,
+			Seq:          t.Seq,
+		})
+	}
+
+	return &types.MatchResult{
+		FilledQty:    result.FilledQty.String(),
+		AvgPrice:     result.AvgPrice.String(),
+		RemainingQty: result.RemainingQty.String(),
+		Trades:       trades,
+	}
+}
+
+func (rs *RealService) convertPlaceOrderResponse(order *obtypes.Order, result *obkeeper.MatchResult) *types.PlaceOrderResponse {
+	return &types.PlaceOrderResponse{
+		Order: rs.convertOrder(order),
+		Match: rs.convertMatchResult(result),
+	}
+}
+
+func (rs *RealService) convertPosition(pos *perptypes.Position) *types.Position {
+	if pos == nil {
+		return nil
+	}
+	// Calculate mark price and unrealized PnL (using entry price as placeholder for standalone mode)
+	markPrice := pos.EntryPrice // In full mode, this would come from oracle
+	unrealizedPnL := pos.CalculateUnrealizedPnL(markPrice)
+
+	return &types.Position{
+		MarketID:         pos.MarketID,
+		Trader:           pos.Trader,
 		Side:             pos.Side.String(), // Convert PositionSide to string
 		Size:             pos.Size.String(),
 		EntryPrice:       pos.EntryPrice.String(),
@@ -538,6 +1678,27 @@ func (rs *RealService) convertPosition(pos *perptypes.Position) *types.Position
 	}
 }
 
+// convertClosedPosition converts a closed position record to its API form
+func convertClosedPosition(c *perptypes.ClosedPosition) *types.ClosedPosition {
+	return &types.ClosedPosition{
+		PositionID:      c.PositionID,
+		MarketID:        c.MarketID,
+		Trader:          c.Trader,
+		Side:            c.Side.String(),
+		Size:            c.Size.String(),
+		EntryPrice:      c.EntryPrice.String(),
+		ExitPrice:       c.ExitPrice.String(),
+		RealizedPnl:     c.RealizedPnL.String(),
+		FeesPaid:        c.FeesPaid.String(),
+		FundingPaid:     c.FundingPaid.String(),
+		NetPnl:          c.NetPnL.String(),
+		CloseReason:     string(c.Reason),
+		OpenedAt:        c.OpenedAt.UnixMilli(),
+		ClosedAt:        c.ClosedAt.UnixMilli(),
+		HoldingDuration: int64(c.HoldingDuration().Seconds()),
+	}
+}
+
 func (rs *RealService) convertAccount(account *perptypes.Account) *types.Account {
 	if account == nil {
 		return nil
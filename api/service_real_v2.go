@@ -24,6 +24,7 @@ import (
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
+	"github.com/openalpha/perp-dex/api/mocksim"
 	"github.com/openalpha/perp-dex/api/types"
 	obkeeper "github.com/openalpha/perp-dex/x/orderbook/keeper"
 	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
@@ -67,6 +68,9 @@ type HyperliquidOracle struct {
 	httpClient *http.Client
 	cache      map[string]*PriceCache
 	mu         sync.RWMutex
+
+	// Mock mode: markets served from this simulation instead of Hyperliquid (see mock_sim.go)
+	sim *mocksim.Simulator
 }
 
 type PriceCache struct {
@@ -143,6 +147,14 @@ var assetToHL = map[string]string{
 
 // GetPrice fetches the current price from Hyperliquid
 func (o *HyperliquidOracle) GetPrice(marketID string) (math.LegacyDec, error) {
+	if o.simulates(marketID) {
+		price, err := o.sim.Price(marketID, time.Now())
+		if err != nil {
+			return math.LegacyZeroDec(), err
+		}
+		return math.LegacyNewDecFromStr(price)
+	}
+
 	o.mu.RLock()
 	cached, exists := o.cache[marketID]
 	o.mu.RUnlock()
@@ -250,6 +262,10 @@ func (o *HyperliquidOracle) GetPrice(marketID string) (math.LegacyDec, error) {
 
 // GetTicker fetches complete ticker data from Hyperliquid
 func (o *HyperliquidOracle) GetTicker(marketID string) (*TickerData, error) {
+	if o.simulates(marketID) {
+		return o.simTicker(marketID)
+	}
+
 	hlAsset, ok := assetToHL[marketID]
 	if !ok {
 		return nil, fmt.Errorf("unknown market: %s", marketID)
@@ -359,6 +375,10 @@ func (o *HyperliquidOracle) GetTicker(marketID string) (*TickerData, error) {
 
 // GetOrderbook fetches L2 orderbook from Hyperliquid
 func (o *HyperliquidOracle) GetOrderbook(marketID string, depth int) (*OrderbookData, error) {
+	if o.simulates(marketID) {
+		return o.simOrderbook(marketID, depth)
+	}
+
 	hlAsset, ok := assetToHL[marketID]
 	if !ok {
 		return nil, fmt.Errorf("unknown market: %s", marketID)
@@ -432,6 +452,10 @@ func (o *HyperliquidOracle) GetOrderbook(marketID string, depth int) (*Orderbook
 
 // GetRecentTrades fetches recent trades from Hyperliquid
 func (o *HyperliquidOracle) GetRecentTrades(marketID string, limit int) ([]TradeData, error) {
+	if o.simulates(marketID) {
+		return o.simTrades(marketID, limit)
+	}
+
 	hlAsset, ok := assetToHL[marketID]
 	if !ok {
 		return nil, fmt.Errorf("unknown market: %s", marketID)
@@ -490,6 +514,10 @@ func (o *HyperliquidOracle) GetRecentTrades(marketID string, limit int) ([]Trade
 
 // GetKlines fetches candlestick data from Hyperliquid
 func (o *HyperliquidOracle) GetKlines(marketID, interval string, limit int) ([]KlineData, error) {
+	if o.simulates(marketID) {
+		return o.simKlines(marketID, interval, limit)
+	}
+
 	hlAsset, ok := assetToHL[marketID]
 	if !ok {
 		return nil, fmt.Errorf("unknown market: %s", marketID)
@@ -711,11 +739,23 @@ func (rpk *RealPerpetualKeeper) GetMarket(ctx sdk.Context, marketID string) *obk
 	if market == nil {
 		return nil
 	}
+	limits := rpk.keeper.GetOrderLimits(ctx, market)
 	return &obkeeper.Market{
 		MarketID:      market.MarketID,
 		TakerFeeRate:  market.TakerFeeRate,
 		MakerFeeRate:  market.MakerFeeRate,
 		InitialMargin: market.InitialMarginRate,
+
+		TickSize:          market.TickSize,
+		LotSize:           market.LotSize,
+		MinOrderSize:      market.MinOrderSize,
+		MaxOrderSize:      limits.MaxOrderSize,
+		MinNotional:       market.MinNotional,
+		MaxPriceDeviation: limits.MaxPriceDeviation,
+		MaxSlippage:       limits.MaxSlippage,
+
+		TradingHalt: rpk.keeper.CheckTradingAllowed(ctx, marketID),
+		PostOnly:    limits.PostOnly,
 	}
 }
 
@@ -896,12 +936,31 @@ func (rs *RealServiceV2) InitializeTestAccount(trader string, balance string) er
 	return nil
 }
 
+// InitializeTestAccounts sets the balance of each trader, see InitializeTestAccount
+func (rs *RealServiceV2) InitializeTestAccounts(balance string, traders ...string) error {
+	for _, trader := range traders {
+		if err := rs.InitializeTestAccount(trader, balance); err != nil {
+			return fmt.Errorf("failed to initialize %s: %w", trader, err)
+		}
+	}
+	return nil
+}
+
 // ============ OrderService Implementation ============
 
 func (rs *RealServiceV2) PlaceOrder(ctx context.Context, req *types.PlaceOrderRequest) (*types.PlaceOrderResponse, error) {
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
 
+	// Nothing expires orders outside RealService's scheduler
+	if req.TimeInForce == types.TimeInForceGTD {
+		return nil, fmt.Errorf("GTD orders are not supported")
+	}
+	maxSlippage, err := parseMaxSlippage(req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse price and quantity
 	price, err := math.LegacyNewDecFromStr(req.Price)
 	if err != nil {
@@ -912,6 +971,11 @@ func (rs *RealServiceV2) PlaceOrder(ctx context.Context, req *types.PlaceOrderRe
 		return nil, fmt.Errorf("invalid quantity: %w", err)
 	}
 
+	// Only cancels are accepted while the market's trading schedule halts it
+	if err := rs.perpKeeper.CheckTradingAllowed(rs.sdkCtx, req.MarketID); err != nil {
+		return nil, err
+	}
+
 	// Ensure account exists with balance
 	account := rs.perpKeeper.GetAccount(rs.sdkCtx, req.Trader)
 	if account == nil {
@@ -940,8 +1004,15 @@ func (rs *RealServiceV2) PlaceOrder(ctx context.Context, req *types.PlaceOrderRe
 	}
 
 	// Place order through real Keeper
-	order, matchResult, err := rs.obKeeper.PlaceOrder(rs.sdkCtx, req.Trader, req.MarketID, side, orderType, price, qty)
+	var order *obtypes.Order
+	var matchResult *obkeeper.MatchResult
+	if maxSlippage.IsNil() {
+		order, matchResult, err = rs.obKeeper.PlaceOrder(rs.sdkCtx, req.Trader, req.MarketID, side, orderType, price, qty)
+	} else {
+		order, matchResult, err = rs.obKeeper.PlaceMarketOrder(rs.sdkCtx, req.Trader, req.MarketID, side, price, qty, maxSlippage)
+	}
 	if err != nil {
+		recordOrderLimitRejection(req.MarketID, err)
 		return nil, fmt.Errorf("failed to place order: %w", err)
 	}
 
@@ -972,48 +1043,37 @@ func (rs *RealServiceV2) ModifyOrder(ctx context.Context, trader, orderID string
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
 
-	oldOrder := rs.obKeeper.GetOrder(rs.sdkCtx, orderID)
-	if oldOrder == nil {
-		return nil, fmt.Errorf("order not found: %s", orderID)
-	}
-
-	if oldOrder.Trader != trader {
-		return nil, fmt.Errorf("unauthorized: order belongs to different trader")
-	}
-
-	if !oldOrder.IsActive() {
-		return nil, fmt.Errorf("order is not active")
-	}
-
-	// Cancel old order
-	_, err := rs.obKeeper.CancelOrder(rs.sdkCtx, trader, orderID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to cancel old order: %w", err)
-	}
-
-	// Create new order with modified values
-	price := oldOrder.Price
-	qty := oldOrder.Quantity
+	// Empty fields are left unchanged (nil Dec)
+	var price, qty math.LegacyDec
+	var err error
 	if req.Price != "" {
-		price, _ = math.LegacyNewDecFromStr(req.Price)
+		if price, err = math.LegacyNewDecFromStr(req.Price); err != nil {
+			return nil, fmt.Errorf("invalid price: %s", req.Price)
+		}
 	}
 	if req.Quantity != "" {
-		qty, _ = math.LegacyNewDecFromStr(req.Quantity)
+		if qty, err = math.LegacyNewDecFromStr(req.Quantity); err != nil {
+			return nil, fmt.Errorf("invalid quantity: %s", req.Quantity)
+		}
 	}
 
-	// Place new order
-	newOrder, matchResult, err := rs.obKeeper.PlaceOrder(rs.sdkCtx, trader, oldOrder.MarketID, oldOrder.Side, oldOrder.OrderType, price, qty)
+	// Amend in place: quantity reductions keep time priority
+	order, matchResult, err := rs.obKeeper.AmendOrder(rs.sdkCtx, trader, orderID, price, qty)
 	if err != nil {
-		return nil, fmt.Errorf("failed to place new order: %w", err)
+		return nil, err
 	}
 
 	rs.matchEngine.Flush(rs.sdkCtx)
 
-	return &types.ModifyOrderResponse{
-		OldOrderID: orderID,
-		Order:      rs.convertOrder(newOrder),
-		Match:      rs.convertMatchResult(matchResult),
-	}, nil
+	resp := &types.ModifyOrderResponse{
+		OldOrderID:       orderID,
+		Order:            rs.convertOrder(order),
+		PriorityRetained: matchResult == nil,
+	}
+	if matchResult != nil {
+		resp.Match = rs.convertMatchResult(matchResult)
+	}
+	return resp, nil
 }
 
 func (rs *RealServiceV2) GetOrders(ctx context.Context, trader string) ([]*types.Order, error) {
@@ -1086,6 +1146,69 @@ func (rs *RealServiceV2) ClosePosition(ctx context.Context, trader, marketID str
 	}, nil
 }
 
+func (rs *RealServiceV2) GetPositionHistory(ctx context.Context, req *types.PositionHistoryRequest) (*types.PositionHistoryResponse, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	closed, next, err := rs.perpKeeper.GetClosedPositions(rs.sdkCtx, req.Trader, req.MarketID, req.Cursor, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.ClosedPosition, 0, len(closed))
+	for _, c := range closed {
+		result = append(result, convertClosedPosition(c))
+	}
+	return &types.PositionHistoryResponse{
+		Positions:  result,
+		Limit:      req.Limit,
+		NextCursor: next,
+	}, nil
+}
+
+// ============ InvariantService Implementation ============
+
+// RunInvariants checks the orderbook and perpetual invariants
+func (rs *RealServiceV2) RunInvariants(ctx context.Context) (*types.InvariantReport, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	registry := &invariantRegistry{}
+	obkeeper.RegisterInvariants(registry, rs.obKeeper)
+	perpkeeper.RegisterInvariants(registry, rs.perpKeeper)
+	return registry.run(rs.sdkCtx), nil
+}
+
+// ============ IndexPriceService Implementation ============
+
+// GetIndexPrice returns the index price aggregated from the keeper's oracle sources
+func (rs *RealServiceV2) GetIndexPrice(ctx context.Context, marketID string) (*types.IndexPrice, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	index, err := rs.perpKeeper.GetIndexPrice(rs.sdkCtx, marketID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a stored mark price the basis is zero
+	mark := index.Price
+	if priceInfo := rs.perpKeeper.GetPrice(rs.sdkCtx, marketID); priceInfo != nil {
+		mark = priceInfo.MarkPrice
+	}
+
+	sources := make([]*types.IndexSource, 0, len(index.Components))
+	for _, c := range index.Components {
+		sources = append(sources, &types.IndexSource{
+			Source:    c.SourceID,
+			Price:     c.Price.String(),
+			Weight:    c.Weight.String(),
+			Included:  c.Included,
+			Timestamp: c.Timestamp.UnixMilli(),
+		})
+	}
+	return newIndexPrice(marketID, index.Price, mark, sources, index.Timestamp.UnixMilli()), nil
+}
+
 // ============ AccountService Implementation ============
 
 func (rs *RealServiceV2) GetAccount(ctx context.Context, trader string) (*types.Account, error) {
@@ -1177,10 +1300,12 @@ func (rs *RealServiceV2) convertMatchResult(match *obkeeper.MatchResult) *types.
 	trades := make([]types.TradeInfo, 0, len(match.Trades))
 	for _, t := range match.Trades {
 		trades = append(trades, types.TradeInfo{
-			TradeID:   t.TradeID,
-			Price:     t.Price.String(),
-			Quantity:  t.Quantity.String(),
-			Timestamp: t.Timestamp.UnixMilli(),
+			TradeID:      t.TradeID,
+			Price:        t.Price.String(),
+			Quantity:     t.Quantity.String(),
+			Timestamp:    t.Timestamp.UnixMilli(),
+			Maker:        t.Maker,
+			MakerOrderID: t.MakerOrderID,
 		})
 	}
 	return &types.MatchResult{
@@ -0,0 +1,1003 @@
+package api
+
+// service_riverpool_keeper.go - RiverPool service backed by the real riverpool Keeper
+// Deposits, withdrawals and community pool management run the on-chain logic
+// against an in-memory store, so standalone API nodes behave like the chain.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	"cosmossdk.io/store"
+	"cosmossdk.io/store/metrics"
+	storetypes "cosmossdk.io/store/types"
+	tmproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/openalpha/perp-dex/api/types"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	rpkeeper "github.com/openalpha/perp-dex/x/riverpool/keeper"
+	riverpooltypes "github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// Standalone invite code defaults, matching the mock service
+const (
+	standaloneInviteMaxUses     = 10
+	standaloneInviteExpiresDays = 30
+)
+
+// KeeperRiverpoolService implements types.RiverpoolService with the real
+// riverpool Keeper over an in-memory CommitMultiStore. Every mutation runs in
+// a cache context and is only written when the keeper call succeeds, like a
+// transaction. Pool orders need the orderbook module and are unavailable.
+type KeeperRiverpoolService struct {
+	mu     sync.RWMutex
+	keeper *rpkeeper.Keeper
+	sdkCtx sdk.Context
+	logger log.Logger
+}
+
+// NewKeeperRiverpoolService creates a keeper-backed RiverPool service with the
+// Foundation LP and Main LP initialized. Pool trading accounts price markets
+// with oracle, which may be nil.
+func NewKeeperRiverpoolService(logger log.Logger, oracle *HyperliquidOracle) (*KeeperRiverpoolService, error) {
+	db := dbm.NewMemDB()
+	storeKey := storetypes.NewKVStoreKey(riverpooltypes.StoreKey)
+
+	cms := store.NewCommitMultiStore(db, logger, metrics.NewNoOpMetrics())
+	cms.MountStoreWithDB(storeKey, storetypes.StoreTypeIAVL, db)
+	if err := cms.LoadLatestVersion(); err != nil {
+		return nil, fmt.Errorf("failed to load store: %w", err)
+	}
+
+	cdc := codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+	keeper := rpkeeper.NewKeeper(cdc, storeKey, newStandalonePoolLedger(oracle), nil, nil, "", logger)
+
+	header := tmproto.Header{Height: 1, Time: time.Now()}
+	sdkCtx := sdk.NewContext(cms, header, false, logger)
+	keeper.InitDefaultPools(sdkCtx)
+
+	return &KeeperRiverpoolService{
+		keeper: keeper,
+		sdkCtx: sdkCtx,
+		logger: logger,
+	}, nil
+}
+
+// ctx stamps the service context with the wall clock and a fresh event
+// manager, so events from earlier calls do not accumulate
+func (s *KeeperRiverpoolService) ctx() sdk.Context {
+	return s.sdkCtx.WithBlockTime(time.Now().UTC()).WithEventManager(sdk.NewEventManager())
+}
+
+// exec runs fn in a cache context and writes its changes only if it succeeds
+func (s *KeeperRiverpoolService) exec(fn func(ctx sdk.Context) error) error {
+	cacheCtx, write := s.ctx().CacheContext()
+	if err := fn(cacheCtx); err != nil {
+		return err
+	}
+	write()
+	return nil
+}
+
+// ownedPool returns a pool the caller owns
+func (s *KeeperRiverpoolService) ownedPool(ctx sdk.Context, poolID, owner string) (*riverpooltypes.Pool, error) {
+	pool := s.keeper.GetPool(ctx, poolID)
+	if pool == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+	if pool.Owner != owner {
+		return nil, riverpooltypes.ErrNotPoolOwner
+	}
+	return pool, nil
+}
+
+// ============ Pool queries ============
+
+func (s *KeeperRiverpoolService) GetPools() ([]*types.PoolInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return convertPools(s.keeper.GetAllPools(s.ctx())), nil
+}
+
+func (s *KeeperRiverpoolService) GetPool(poolID string) (*types.PoolInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pool := s.keeper.GetPool(s.ctx(), poolID)
+	if pool == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+	return convertPool(pool), nil
+}
+
+func (s *KeeperRiverpoolService) GetPoolsByType(poolType string) ([]*types.PoolInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return convertPools(s.keeper.GetPoolsByType(s.ctx(), poolType)), nil
+}
+
+func (s *KeeperRiverpoolService) GetPoolStats(poolID string) (*types.PoolStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	pool := s.keeper.GetPool(ctx, poolID)
+	if pool == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+	stats := s.keeper.GetPoolStats(ctx, poolID)
+
+	withdrawn := math.LegacyZeroDec()
+	for _, w := range s.keeper.GetPoolWithdrawals(ctx, poolID) {
+		if w.Status == riverpooltypes.WithdrawalStatusCompleted {
+			withdrawn = withdrawn.Add(w.AmountReceived)
+		}
+	}
+	revenue := math.LegacyZeroDec()
+	if rs := s.keeper.GetPoolRevenueStats(ctx, poolID); rs != nil {
+		revenue = rs.TotalRevenue
+	}
+
+	return &types.PoolStats{
+		PoolID:           poolID,
+		TotalDeposits:    pool.TotalDeposits.String(),
+		TotalWithdrawals: withdrawn.String(),
+		TotalRevenue:     revenue.String(),
+		NAV:              pool.NAV.String(),
+		APY30d:           decString(stats.Return30d),
+		APY7d:            decString(stats.Return7d),
+		MaxDrawdown:      decString(pool.CurrentDrawdown),
+		SharpeRatio:      "0",
+		HolderCount:      int(pool.TotalHolders),
+	}, nil
+}
+
+func (s *KeeperRiverpoolService) GetNAVHistory(poolID string, days int) ([]*types.NAVPoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	if s.keeper.GetPool(ctx, poolID) == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+
+	var from int64
+	if days > 0 {
+		from = time.Now().Unix() - int64(days)*86400
+	}
+	points := []*types.NAVPoint{}
+	for _, h := range s.keeper.GetNAVHistory(ctx, poolID, from, 0) {
+		points = append(points, &types.NAVPoint{Timestamp: h.Timestamp, NAV: h.NAV.String()})
+	}
+	return points, nil
+}
+
+func (s *KeeperRiverpoolService) GetDDGuardState(poolID string) (*types.DDGuardState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	pool := s.keeper.GetPool(ctx, poolID)
+	if pool == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+
+	state := &types.DDGuardState{
+		PoolID:          poolID,
+		Level:           pool.DDGuardLevel,
+		CurrentDrawdown: decString(pool.CurrentDrawdown),
+		HighWaterMark:   decString(pool.HighWaterMark),
+		TriggerHistory:  []types.DDGuardTrigger{},
+	}
+	if dd := s.keeper.GetDDGuardState(ctx, poolID); dd != nil && dd.Level != riverpooltypes.DDGuardLevelNormal {
+		state.TriggerHistory = append(state.TriggerHistory, types.DDGuardTrigger{
+			Timestamp: dd.TriggeredAt,
+			Level:     dd.Level,
+			Drawdown:  decString(dd.DrawdownPercent),
+			Action:    fmt.Sprintf("max exposure %s", decString(dd.MaxExposureLimit)),
+		})
+	}
+	return state, nil
+}
+
+// ============ User queries ============
+
+func (s *KeeperRiverpoolService) GetUserDeposits(user string) ([]*types.DepositInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return convertDeposits(s.keeper.GetUserDeposits(s.ctx(), user)), nil
+}
+
+func (s *KeeperRiverpoolService) GetUserWithdrawals(user string) ([]*types.WithdrawalInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return convertWithdrawals(s.keeper.GetUserWithdrawals(s.ctx(), user)), nil
+}
+
+func (s *KeeperRiverpoolService) GetUserPoolBalance(poolID, user string) (*types.UserBalance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	if s.keeper.GetPool(ctx, poolID) == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+
+	shares, value, costBasis := s.keeper.GetUserPoolBalance(ctx, poolID, user)
+	return &types.UserBalance{
+		PoolID:          poolID,
+		User:            user,
+		Shares:          shares.String(),
+		Value:           value.String(),
+		UnrealizedPnL:   value.Sub(costBasis).String(),
+		DepositedAmount: costBasis.String(),
+	}, nil
+}
+
+func (s *KeeperRiverpoolService) GetUserOwnedPools(user string) ([]*types.PoolInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return convertPools(s.keeper.GetPoolsByOwner(s.ctx(), user)), nil
+}
+
+// ============ Pool deposits/withdrawals ============
+
+func (s *KeeperRiverpoolService) GetPoolDeposits(poolID string, offset, limit int) ([]*types.DepositInfo, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	if s.keeper.GetPool(ctx, poolID) == nil {
+		return nil, 0, riverpooltypes.ErrPoolNotFound
+	}
+
+	deposits := convertDeposits(s.keeper.GetPoolDeposits(ctx, poolID))
+	return paginate(deposits, offset, limit), len(deposits), nil
+}
+
+func (s *KeeperRiverpoolService) GetPoolWithdrawals(poolID string, offset, limit int) ([]*types.WithdrawalInfo, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	if s.keeper.GetPool(ctx, poolID) == nil {
+		return nil, 0, riverpooltypes.ErrPoolNotFound
+	}
+
+	withdrawals := convertWithdrawals(s.keeper.GetPoolWithdrawals(ctx, poolID))
+	return paginate(withdrawals, offset, limit), len(withdrawals), nil
+}
+
+func (s *KeeperRiverpoolService) GetPendingWithdrawals(poolID string) ([]*types.WithdrawalInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	if s.keeper.GetPool(ctx, poolID) == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+
+	return convertWithdrawals(s.keeper.GetPendingWithdrawals(ctx, poolID)), nil
+}
+
+// ============ Estimates ============
+
+func (s *KeeperRiverpoolService) EstimateDeposit(poolID string, amount math.LegacyDec) (*types.DepositEstimate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	pool := s.keeper.GetPool(ctx, poolID)
+	if pool == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+
+	shares, nav := s.keeper.EstimateSharesForDeposit(ctx, poolID, amount)
+	estimate := &types.DepositEstimate{
+		PoolID:          poolID,
+		Amount:          amount.String(),
+		EstimatedShares: shares.String(),
+		CurrentNAV:      nav.String(),
+		MinDeposit:      decString(pool.MinDeposit),
+	}
+	if pool.PoolType == riverpooltypes.PoolTypeFoundation {
+		estimate.PointsReward = riverpooltypes.FoundationPointsPerSeat.String()
+	}
+	return estimate, nil
+}
+
+func (s *KeeperRiverpoolService) EstimateWithdrawal(poolID string, shares math.LegacyDec) (*types.WithdrawalEstimate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	pool := s.keeper.GetPool(ctx, poolID)
+	if pool == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+
+	amount, nav := s.keeper.EstimateAmountForWithdrawal(ctx, poolID, shares)
+	return &types.WithdrawalEstimate{
+		PoolID:          poolID,
+		Shares:          shares.String(),
+		EstimatedAmount: amount.String(),
+		CurrentNAV:      nav.String(),
+		DelayDays:       int(pool.RedemptionDelayDays),
+		DailyLimit:      decString(pool.DailyRedemptionLimit),
+		QueuePosition:   len(s.keeper.GetPendingWithdrawals(ctx, poolID)) + 1,
+	}, nil
+}
+
+// ============ Transactions ============
+
+func (s *KeeperRiverpoolService) Deposit(poolID, user string, amount math.LegacyDec, inviteCode string) (*types.DepositResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deposit *riverpooltypes.Deposit
+	err := s.exec(func(ctx sdk.Context) error {
+		var err error
+		deposit, err = s.keeper.Deposit(ctx, user, poolID, amount, inviteCode)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.DepositResult{
+		DepositID:   deposit.DepositID,
+		PoolID:      deposit.PoolID,
+		User:        deposit.Depositor,
+		Amount:      deposit.Amount.String(),
+		Shares:      deposit.Shares.String(),
+		NAV:         deposit.NAVAtDeposit.String(),
+		LockedUntil: deposit.UnlockAt,
+	}, nil
+}
+
+func (s *KeeperRiverpoolService) RequestWithdrawal(poolID, user string, shares math.LegacyDec) (*types.WithdrawalResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var withdrawal *riverpooltypes.Withdrawal
+	err := s.exec(func(ctx sdk.Context) error {
+		var err error
+		withdrawal, err = s.keeper.RequestWithdrawal(ctx, user, poolID, shares)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.WithdrawalResult{
+		WithdrawalID: withdrawal.WithdrawalID,
+		PoolID:       withdrawal.PoolID,
+		User:         withdrawal.Withdrawer,
+		Shares:       withdrawal.SharesRequested.String(),
+		ClaimableAt:  withdrawal.AvailableAt,
+	}, nil
+}
+
+func (s *KeeperRiverpoolService) ClaimWithdrawal(withdrawalID, user string) (*types.ClaimResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		withdrawal *riverpooltypes.Withdrawal
+		amount     math.LegacyDec
+	)
+	err := s.exec(func(ctx sdk.Context) error {
+		var err error
+		withdrawal, amount, err = s.keeper.ClaimWithdrawal(ctx, user, withdrawalID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.ClaimResult{
+		WithdrawalID: withdrawal.WithdrawalID,
+		Amount:       amount.String(),
+		ClaimedAt:    withdrawal.CompletedAt,
+	}, nil
+}
+
+func (s *KeeperRiverpoolService) CancelWithdrawal(withdrawalID, user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exec(func(ctx sdk.Context) error {
+		_, err := s.keeper.CancelWithdrawal(ctx, user, withdrawalID)
+		return err
+	})
+}
+
+// ============ Revenue ============
+
+func (s *KeeperRiverpoolService) GetPoolRevenue(poolID string) (*types.RevenueStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	if s.keeper.GetPool(ctx, poolID) == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+
+	revenue := &types.RevenueStats{
+		PoolID:             poolID,
+		TotalRevenue:       "0",
+		SpreadRevenue:      "0",
+		FundingRevenue:     "0",
+		LiquidationRevenue: "0",
+		Period:             "all",
+	}
+	if stats := s.keeper.GetPoolRevenueStats(ctx, poolID); stats != nil {
+		revenue.TotalRevenue = stats.TotalRevenue.String()
+		revenue.SpreadRevenue = stats.SpreadRevenue.String()
+		revenue.FundingRevenue = stats.FundingRevenue.String()
+		revenue.LiquidationRevenue = stats.LiquidationProfit.String()
+	}
+	return revenue, nil
+}
+
+func (s *KeeperRiverpoolService) GetRevenueRecords(poolID string, limit int) ([]*types.RevenueRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	if s.keeper.GetPool(ctx, poolID) == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+
+	records := s.keeper.GetPoolRevenueRecords(ctx, poolID, 0, 0)
+	sort.Slice(records, func(i, j int) bool { return records[i].Timestamp > records[j].Timestamp })
+
+	result := []*types.RevenueRecord{}
+	for _, r := range records {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		result = append(result, &types.RevenueRecord{
+			Timestamp: r.Timestamp,
+			Type:      string(r.Source),
+			Amount:    r.Amount.String(),
+			TradeID:   r.PositionID,
+		})
+	}
+	return result, nil
+}
+
+func (s *KeeperRiverpoolService) GetRevenueBreakdown(poolID string) (*types.RevenueBreakdown, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	if s.keeper.GetPool(ctx, poolID) == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+
+	// Same 30-day window the chain reports returns over
+	breakdown := s.keeper.GetPoolRevenueBreakdown(ctx, poolID, 30)
+	total := math.LegacyZeroDec()
+	for _, amount := range breakdown {
+		total = total.Add(amount)
+	}
+	return &types.RevenueBreakdown{
+		PoolID:      poolID,
+		Spread:      breakdown[rpkeeper.RevenueSourceSpread].String(),
+		Funding:     breakdown[rpkeeper.RevenueSourceFunding].String(),
+		Liquidation: breakdown[rpkeeper.RevenueSourceLiquidation].String(),
+		Total:       total.String(),
+	}, nil
+}
+
+// ============ Community Pool ============
+
+func (s *KeeperRiverpoolService) CreateCommunityPool(owner string, params *types.CommunityPoolParams) (*types.PoolInfo, error) {
+	config, err := communityPoolConfig(owner, params)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pool *riverpooltypes.Pool
+	err = s.exec(func(ctx sdk.Context) error {
+		var err error
+		if pool, err = s.keeper.CreateCommunityPool(ctx, config); err != nil {
+			return err
+		}
+		s.keeper.SetPoolStats(ctx, riverpooltypes.NewPoolStats(pool.PoolID))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return convertPool(pool), nil
+}
+
+func (s *KeeperRiverpoolService) UpdateCommunityPool(poolID, owner string, params *types.CommunityPoolParams) (*types.PoolInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pool *riverpooltypes.Pool
+	err := s.exec(func(ctx sdk.Context) error {
+		if err := s.keeper.UpdatePoolSettings(ctx, owner, poolID, params.Name, params.Description); err != nil {
+			return err
+		}
+		if params.MaxHolders != 0 {
+			if err := s.keeper.SetPoolMaxHolders(ctx, owner, poolID, params.MaxHolders); err != nil {
+				return err
+			}
+		}
+		pool = s.keeper.GetPool(ctx, poolID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return convertPool(pool), nil
+}
+
+func (s *KeeperRiverpoolService) GetPoolHolders(poolID string) ([]*types.HolderInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	pool := s.keeper.GetPool(ctx, poolID)
+	if pool == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+
+	byUser := map[string]*types.HolderInfo{}
+	shares := map[string]math.LegacyDec{}
+	var users []string
+	for _, d := range s.keeper.GetPoolDeposits(ctx, poolID) {
+		if _, ok := byUser[d.Depositor]; !ok {
+			byUser[d.Depositor] = &types.HolderInfo{User: d.Depositor, DepositedAt: d.DepositedAt}
+			shares[d.Depositor] = math.LegacyZeroDec()
+			users = append(users, d.Depositor)
+		}
+		shares[d.Depositor] = shares[d.Depositor].Add(d.Shares)
+		if d.DepositedAt < byUser[d.Depositor].DepositedAt {
+			byUser[d.Depositor].DepositedAt = d.DepositedAt
+		}
+	}
+
+	holders := []*types.HolderInfo{}
+	for _, user := range users {
+		if !shares[user].IsPositive() {
+			continue
+		}
+		holder := byUser[user]
+		holder.Shares = shares[user].String()
+		holder.Value = pool.CalculateValueForShares(shares[user]).String()
+		holder.SharePercent = "0"
+		if pool.TotalShares.IsPositive() {
+			holder.SharePercent = shares[user].Quo(pool.TotalShares).MulInt64(100).String()
+		}
+		holders = append(holders, holder)
+	}
+	sort.Slice(holders, func(i, j int) bool { return shares[holders[i].User].GT(shares[holders[j].User]) })
+	return holders, nil
+}
+
+func (s *KeeperRiverpoolService) GetPoolPositions(poolID string) ([]*types.PositionInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	if s.keeper.GetPool(ctx, poolID) == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+
+	positions := []*types.PositionInfo{}
+	for _, p := range s.keeper.GetPoolPositions(ctx, poolID) {
+		positions = append(positions, &types.PositionInfo{
+			PositionID: p.PositionID,
+			MarketID:   p.MarketID,
+			Side:       p.Side,
+			Size:       p.Size.String(),
+			EntryPrice: p.EntryPrice.String(),
+			MarkPrice:  decString(p.CurrentPrice),
+			PnL:        decString(p.UnrealizedPnL),
+			Leverage:   decString(p.Leverage),
+		})
+	}
+	return positions, nil
+}
+
+func (s *KeeperRiverpoolService) GetPoolTrades(poolID string, limit int) ([]*types.PoolTradeInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	if s.keeper.GetPool(ctx, poolID) == nil {
+		return nil, riverpooltypes.ErrPoolNotFound
+	}
+
+	trades := []*types.PoolTradeInfo{}
+	for _, t := range s.keeper.GetPoolTrades(ctx, poolID, limit) {
+		trades = append(trades, &types.PoolTradeInfo{
+			TradeID:    t.TradeID,
+			MarketID:   t.MarketID,
+			Side:       t.Side,
+			Size:       t.Size.String(),
+			Price:      t.Price.String(),
+			Fee:        decString(t.Fee),
+			PnL:        decString(t.PnL),
+			ExecutedAt: t.ExecutedAt,
+		})
+	}
+	return trades, nil
+}
+
+func (s *KeeperRiverpoolService) GetInviteCodes(poolID, owner string) ([]*types.InviteCode, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	if _, err := s.ownedPool(ctx, poolID, owner); err != nil {
+		return nil, err
+	}
+
+	codes := []*types.InviteCode{}
+	for _, code := range s.keeper.GetPoolInviteCodes(ctx, poolID) {
+		codes = append(codes, convertInviteCode(code))
+	}
+	return codes, nil
+}
+
+func (s *KeeperRiverpoolService) GenerateInviteCode(poolID, owner string) (*types.InviteCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var code *rpkeeper.InviteCode
+	err := s.exec(func(ctx sdk.Context) error {
+		if _, err := s.ownedPool(ctx, poolID, owner); err != nil {
+			return err
+		}
+		code = s.keeper.GenerateInviteCode(ctx, poolID, standaloneInviteMaxUses, standaloneInviteExpiresDays)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return convertInviteCode(code), nil
+}
+
+func (s *KeeperRiverpoolService) RevokeInviteCode(poolID, owner, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exec(func(ctx sdk.Context) error {
+		return s.keeper.RevokeInviteCode(ctx, owner, poolID, code)
+	})
+}
+
+func (s *KeeperRiverpoolService) GetInviteRedemptions(poolID, owner, code string) ([]*types.InviteRedemption, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx()
+	if _, err := s.ownedPool(ctx, poolID, owner); err != nil {
+		return nil, err
+	}
+	invite := s.keeper.GetInviteCode(ctx, code)
+	if invite == nil || invite.PoolID != poolID {
+		return nil, fmt.Errorf("invite code not found: %s", code)
+	}
+
+	redemptions := []*types.InviteRedemption{}
+	for _, r := range s.keeper.GetInviteRedemptions(ctx, code) {
+		redemptions = append(redemptions, &types.InviteRedemption{
+			Code:       r.Code,
+			PoolID:     r.PoolID,
+			Address:    r.Address,
+			DepositID:  r.DepositID,
+			Amount:     r.Amount.String(),
+			RedeemedAt: r.RedeemedAt,
+		})
+	}
+	return redemptions, nil
+}
+
+func (s *KeeperRiverpoolService) PlacePoolOrder(poolID, owner, marketID, side string, size, price, leverage math.LegacyDec) (*types.PoolOrderResult, error) {
+	return nil, fmt.Errorf("pool orders not available in standalone mode")
+}
+
+func (s *KeeperRiverpoolService) ClosePoolPosition(poolID, owner, positionID string) (*types.PoolCloseResult, error) {
+	return nil, fmt.Errorf("pool position close not available in standalone mode")
+}
+
+func (s *KeeperRiverpoolService) PausePool(poolID, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exec(func(ctx sdk.Context) error {
+		return s.keeper.PausePool(ctx, owner, poolID)
+	})
+}
+
+func (s *KeeperRiverpoolService) ResumePool(poolID, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exec(func(ctx sdk.Context) error {
+		return s.keeper.ResumePool(ctx, owner, poolID)
+	})
+}
+
+func (s *KeeperRiverpoolService) ClosePool(poolID, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exec(func(ctx sdk.Context) error {
+		return s.keeper.ClosePool(ctx, owner, poolID)
+	})
+}
+
+// ============ Community pool config ============
+
+// communityPoolConfig converts API parameters into a keeper config. Omitted
+// fields take the keeper's minimums: a $10 deposit, a 5% owner stake and no fees.
+func communityPoolConfig(owner string, params *types.CommunityPoolParams) (rpkeeper.CommunityPoolConfig, error) {
+	config := rpkeeper.CommunityPoolConfig{
+		Owner:                owner,
+		Name:                 params.Name,
+		Description:          params.Description,
+		LockPeriodDays:       int64(params.LockPeriodDays),
+		RedemptionDelayDays:  int64(params.RedemptionDelay),
+		DailyRedemptionLimit: riverpooltypes.MainDailyRedemptionLimit,
+		IsPrivate:            params.IsPrivate,
+		MaxHolders:           params.MaxHolders,
+		AllowedMarkets:       params.AllowedMarkets,
+	}
+
+	fields := []struct {
+		name  string
+		value string
+		def   math.LegacyDec
+		dst   *math.LegacyDec
+	}{
+		{"min_deposit", params.MinDeposit, math.LegacyNewDec(10), &config.MinDeposit},
+		{"max_deposit", params.MaxDeposit, math.LegacyZeroDec(), &config.MaxDeposit},
+		{"management_fee", params.ManagementFee, math.LegacyZeroDec(), &config.ManagementFee},
+		{"performance_fee", params.PerformanceFee, math.LegacyZeroDec(), &config.PerformanceFee},
+		{"owner_min_stake", params.OwnerMinStake, math.LegacyMustNewDecFromStr("0.05"), &config.OwnerMinStake},
+		{"max_leverage", params.MaxLeverage, riverpooltypes.DefaultPoolMaxLeverage, &config.MaxLeverage},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			*f.dst = f.def
+			continue
+		}
+		d, err := math.LegacyNewDecFromStr(f.value)
+		if err != nil || d.IsNegative() {
+			return config, types.NewAPIError(types.ErrCodeInvalidPoolParams, fmt.Sprintf("invalid %s: %q", f.name, f.value))
+		}
+		*f.dst = d
+	}
+	return config, nil
+}
+
+// ============ Conversion Helpers ============
+
+func convertPools(pools []*riverpooltypes.Pool) []*types.PoolInfo {
+	result := make([]*types.PoolInfo, 0, len(pools))
+	for _, pool := range pools {
+		result = append(result, convertPool(pool))
+	}
+	return result
+}
+
+func convertPool(pool *riverpooltypes.Pool) *types.PoolInfo {
+	info := &types.PoolInfo{
+		PoolID:               pool.PoolID,
+		PoolType:             pool.PoolType,
+		Name:                 pool.Name,
+		Description:          pool.Description,
+		Status:               pool.Status,
+		TotalDeposits:        decString(pool.TotalDeposits),
+		TotalShares:          decString(pool.TotalShares),
+		LPDenom:              riverpooltypes.LPDenom(pool.PoolID),
+		NAV:                  decString(pool.NAV),
+		HighWaterMark:        decString(pool.HighWaterMark),
+		CurrentDrawdown:      decString(pool.CurrentDrawdown),
+		DDGuardLevel:         pool.DDGuardLevel,
+		MinDeposit:           decString(pool.MinDeposit),
+		MaxDeposit:           decString(pool.MaxDeposit),
+		LockPeriodDays:       pool.LockPeriodDays,
+		RedemptionDelayDays:  pool.RedemptionDelayDays,
+		DailyRedemptionLimit: decString(pool.DailyRedemptionLimit),
+		Owner:                pool.Owner,
+		AllowedMarkets:       pool.AllowedMarkets,
+		IsPrivate:            pool.IsPrivate,
+		MaxHolders:           pool.MaxHolders,
+		TotalHolders:         pool.TotalHolders,
+		CreatedAt:            pool.CreatedAt,
+		UpdatedAt:            pool.UpdatedAt,
+	}
+	if pool.PoolType == riverpooltypes.PoolTypeFoundation {
+		info.SeatsAvailable = riverpooltypes.FoundationSeatCount - pool.GetSeatCount()
+	}
+	if pool.PoolType == riverpooltypes.PoolTypeCommunity {
+		info.MaxLeverage = pool.MaxLeverageOrDefault().String()
+	}
+	return info
+}
+
+func convertDeposits(deposits []*riverpooltypes.Deposit) []*types.DepositInfo {
+	result := make([]*types.DepositInfo, 0, len(deposits))
+	for _, d := range deposits {
+		status := "confirmed"
+		if d.IsLocked() {
+			status = "locked"
+		}
+		result = append(result, &types.DepositInfo{
+			DepositID:    d.DepositID,
+			PoolID:       d.PoolID,
+			User:         d.Depositor,
+			Amount:       d.Amount.String(),
+			Shares:       d.Shares.String(),
+			NAVAtDeposit: d.NAVAtDeposit.String(),
+			Status:       status,
+			LockedUntil:  d.UnlockAt,
+			CreatedAt:    d.DepositedAt,
+		})
+	}
+	return result
+}
+
+func convertWithdrawals(withdrawals []*riverpooltypes.Withdrawal) []*types.WithdrawalInfo {
+	result := make([]*types.WithdrawalInfo, 0, len(withdrawals))
+	for _, w := range withdrawals {
+		info := &types.WithdrawalInfo{
+			WithdrawalID:    w.WithdrawalID,
+			PoolID:          w.PoolID,
+			User:            w.Withdrawer,
+			Shares:          w.SharesRequested.String(),
+			EstimatedAmount: w.SharesRequested.Mul(w.NAVAtRequest).String(),
+			RequestedAt:     w.RequestedAt,
+			ClaimableAt:     w.AvailableAt,
+		}
+		switch w.Status {
+		case riverpooltypes.WithdrawalStatusCompleted:
+			info.Status = "claimed"
+			info.ActualAmount = w.AmountReceived.String()
+			info.ClaimedAt = w.CompletedAt
+		case riverpooltypes.WithdrawalStatusCancelled:
+			info.Status = "cancelled"
+		default:
+			info.Status = "pending"
+			if w.IsReady() {
+				info.Status = "claimable"
+			}
+		}
+		result = append(result, info)
+	}
+	return result
+}
+
+func convertInviteCode(code *rpkeeper.InviteCode) *types.InviteCode {
+	return &types.InviteCode{
+		Code:      code.Code,
+		PoolID:    code.PoolID,
+		MaxUses:   int(code.MaxUses),
+		UsedCount: int(code.UsedCount),
+		ExpiresAt: code.ExpiresAt,
+		CreatedAt: code.CreatedAt,
+		RevokedAt: code.RevokedAt,
+	}
+}
+
+// decString formats a decimal, rendering unset values as zero
+func decString(d math.LegacyDec) string {
+	if d.IsNil() {
+		return "0"
+	}
+	return d.String()
+}
+
+// paginate returns the page of items starting at offset; limit <= 0 returns
+// every remaining item
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// ============ Pool trading account ledger ============
+
+// standalonePoolLedger stands in for the perpetual keeper behind pool trading
+// accounts. It tracks their collateral in memory and prices markets with the
+// oracle; pool orders are not matched in standalone mode, so it holds no
+// positions.
+type standalonePoolLedger struct {
+	mu       sync.RWMutex
+	balances map[string]math.LegacyDec
+	oracle   *HyperliquidOracle
+}
+
+func newStandalonePoolLedger(oracle *HyperliquidOracle) *standalonePoolLedger {
+	return &standalonePoolLedger{
+		balances: make(map[string]math.LegacyDec),
+		oracle:   oracle,
+	}
+}
+
+func (l *standalonePoolLedger) GetPrice(ctx sdk.Context, marketID string) *perptypes.PriceInfo {
+	if l.oracle == nil {
+		return nil
+	}
+	price, err := l.oracle.GetPrice(marketID)
+	if err != nil || !price.IsPositive() {
+		return nil
+	}
+	return perptypes.NewPriceInfo(marketID, price)
+}
+
+func (l *standalonePoolLedger) GetFreeCollateral(ctx sdk.Context, trader string) math.LegacyDec {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if balance, ok := l.balances[trader]; ok {
+		return balance
+	}
+	return math.LegacyZeroDec()
+}
+
+func (l *standalonePoolLedger) Deposit(ctx context.Context, trader string, amount math.LegacyDec) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	balance, ok := l.balances[trader]
+	if !ok {
+		balance = math.LegacyZeroDec()
+	}
+	l.balances[trader] = balance.Add(amount)
+	return nil
+}
+
+func (l *standalonePoolLedger) CreditAccount(ctx context.Context, trader string, amount math.LegacyDec) error {
+	return l.Deposit(ctx, trader, amount)
+}
+
+func (l *standalonePoolLedger) Withdraw(ctx context.Context, trader string, amount math.LegacyDec) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	balance, ok := l.balances[trader]
+	if !ok || balance.LT(amount) {
+		return fmt.Errorf("insufficient balance in trading account %s", trader)
+	}
+	l.balances[trader] = balance.Sub(amount)
+	return nil
+}
+
+func (l *standalonePoolLedger) GetPositionsByTrader(ctx sdk.Context, trader string) []*perptypes.Position {
+	return nil
+}
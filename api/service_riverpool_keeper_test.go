@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/api/handlers"
+	"github.com/openalpha/perp-dex/api/types"
+	riverpooltypes "github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// TestKeeperRiverpoolService tests that deposits, withdrawals and community
+// pool management run through the riverpool keeper in standalone mode
+func TestKeeperRiverpoolService(t *testing.T) {
+	svc, err := NewKeeperRiverpoolService(log.NewNopLogger(), nil)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	s := &Server{riverpoolHandler: handlers.NewRiverpoolStandaloneHandler(svc)}
+
+	pools, _ := svc.GetPools()
+	if len(pools) != 2 {
+		t.Fatalf("expected Foundation LP and Main LP, got %d pools", len(pools))
+	}
+
+	// Main LP enforces its $100 minimum
+	if _, err := svc.Deposit("main-lp", "alice", math.LegacyNewDec(50), ""); !errors.Is(err, riverpooltypes.ErrDepositTooSmall) {
+		t.Errorf("expected deposit too small, got %v", err)
+	}
+	if _, err := svc.Deposit("main-lp", "alice", math.LegacyNewDec(1000), ""); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	balance, _ := svc.GetUserPoolBalance("main-lp", "alice")
+	if balance.Shares != "1000.000000000000000000" {
+		t.Errorf("expected 1000 shares, got %s", balance.Shares)
+	}
+
+	withdrawal, err := svc.RequestWithdrawal("main-lp", "alice", math.LegacyNewDec(400))
+	if err != nil {
+		t.Fatalf("failed to request withdrawal: %v", err)
+	}
+	if _, err := svc.ClaimWithdrawal(withdrawal.WithdrawalID, "alice"); !errors.Is(err, riverpooltypes.ErrWithdrawalNotReady) {
+		t.Errorf("expected T+4 withdrawal not ready, got %v", err)
+	}
+	if err := svc.CancelWithdrawal(withdrawal.WithdrawalID, "bob"); !errors.Is(err, riverpooltypes.ErrUnauthorized) {
+		t.Errorf("expected unauthorized cancel, got %v", err)
+	}
+	if err := svc.CancelWithdrawal(withdrawal.WithdrawalID, "alice"); err != nil {
+		t.Errorf("failed to cancel withdrawal: %v", err)
+	}
+
+	// Invalid community pool parameters are rejected by the keeper
+	if _, err := svc.CreateCommunityPool("owner", &types.CommunityPoolParams{Name: "Alpha", ManagementFee: "0.5"}); !errors.Is(err, riverpooltypes.ErrInvalidManagementFee) {
+		t.Errorf("expected invalid management fee, got %v", err)
+	}
+	pool, err := svc.CreateCommunityPool("owner", &types.CommunityPoolParams{Name: "Alpha", MinDeposit: "100", IsPrivate: true})
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	if pool.MaxLeverage != "10.000000000000000000" || !pool.IsPrivate {
+		t.Errorf("unexpected community pool %+v", pool)
+	}
+
+	code, err := svc.GenerateInviteCode(pool.PoolID, "owner")
+	if err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+	if _, err := svc.Deposit(pool.PoolID, "bob", math.LegacyNewDec(500), ""); !errors.Is(err, riverpooltypes.ErrInvalidInviteCode) {
+		t.Errorf("expected invite code required, got %v", err)
+	}
+	if _, err := svc.Deposit(pool.PoolID, "bob", math.LegacyNewDec(500), code.Code); err != nil {
+		t.Fatalf("failed to deposit with invite code: %v", err)
+	}
+	holders, _ := svc.GetPoolHolders(pool.PoolID)
+	if len(holders) != 1 || holders[0].User != "bob" || holders[0].SharePercent != "100.000000000000000000" {
+		t.Errorf("expected bob as sole holder, got %+v", holders)
+	}
+
+	// Pause and resume through the HTTP routes
+	for _, step := range []struct{ action, status string }{{"pause", "paused"}, {"resume", "active"}} {
+		action, status := step.action, step.status
+		rec := httptest.NewRecorder()
+		s.handleRiverpoolCommunityRoutes(rec, httptest.NewRequest(http.MethodPost, "/v1/riverpool/community/"+pool.PoolID+"/"+action, strings.NewReader(`{"owner":"owner"}`)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected %s to succeed, got %d: %s", action, rec.Code, rec.Body)
+		}
+		if got, _ := svc.GetPool(pool.PoolID); got.Status != status {
+			t.Errorf("expected %s pool after %s, got %s", status, action, got.Status)
+		}
+	}
+
+	if err := svc.ClosePool(pool.PoolID, "owner"); !errors.Is(err, riverpooltypes.ErrPoolHasDeposits) {
+		t.Errorf("expected close to be refused with deposits, got %v", err)
+	}
+	if _, err := svc.PlacePoolOrder(pool.PoolID, "owner", "BTC-USDC", "buy", math.LegacyOneDec(), math.LegacyZeroDec(), math.LegacyOneDec()); types.ToAPIError(err, types.ErrCodeInternal).Code != types.ErrCodeServiceUnavailable {
+		t.Errorf("expected pool orders to be unavailable, got %v", err)
+	}
+}
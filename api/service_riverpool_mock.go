@@ -2,11 +2,13 @@ package api
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"cosmossdk.io/math"
 	"github.com/openalpha/perp-dex/api/types"
+	riverpooltypes "github.com/openalpha/perp-dex/x/riverpool/types"
 )
 
 // MockRiverpoolService implements types.RiverpoolService with mock data
@@ -16,6 +18,9 @@ type MockRiverpoolService struct {
 	deposits    map[string]*types.DepositInfo
 	withdrawals map[string]*types.WithdrawalInfo
 	navHistory  map[string][]*types.NAVPoint
+	inviteCodes map[string]*types.InviteCode
+	redemptions map[string][]*types.InviteRedemption // by code
+	members     map[string]string                    // poolID:address -> redeemed code
 }
 
 // NewMockRiverpoolService creates a new mock RiverPool service
@@ -25,6 +30,9 @@ func NewMockRiverpoolService() *MockRiverpoolService {
 		deposits:    make(map[string]*types.DepositInfo),
 		withdrawals: make(map[string]*types.WithdrawalInfo),
 		navHistory:  make(map[string][]*types.NAVPoint),
+		inviteCodes: make(map[string]*types.InviteCode),
+		redemptions: make(map[string][]*types.InviteRedemption),
+		members:     make(map[string]string),
 	}
 	svc.initMockData()
 	return svc
@@ -339,7 +347,7 @@ func (s *MockRiverpoolService) EstimateWithdrawal(poolID string, shares math.Leg
 	}, nil
 }
 
-func (s *MockRiverpoolService) Deposit(poolID, user string, amount math.LegacyDec) (*types.DepositResult, error) {
+func (s *MockRiverpoolService) Deposit(poolID, user string, amount math.LegacyDec, inviteCode string) (*types.DepositResult, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -348,10 +356,30 @@ func (s *MockRiverpoolService) Deposit(poolID, user string, amount math.LegacyDe
 		return nil, fmt.Errorf("pool not found: %s", poolID)
 	}
 
+	now := time.Now().Unix()
+	isHolder := s.isHolder(poolID, user)
+	_, isMember := s.members[poolID+":"+user]
+
+	// Private pools admit holders, the owner and addresses that already
+	// redeemed a code; anyone else must present a usable code
+	var redeem *types.InviteCode
+	if pool.IsPrivate && !isHolder && !isMember && user != pool.Owner {
+		code, ok := s.inviteCodes[inviteCode]
+		if !ok || code.PoolID != poolID {
+			return nil, fmt.Errorf("%w: invite code required", riverpooltypes.ErrInvalidInviteCode)
+		}
+		if err := checkMockInviteCode(code, now); err != nil {
+			return nil, err
+		}
+		redeem = code
+	}
+	if !isHolder && pool.MaxHolders > 0 && pool.TotalHolders >= pool.MaxHolders {
+		return nil, fmt.Errorf("%w: %d holders", riverpooltypes.ErrPoolHolderLimit, pool.MaxHolders)
+	}
+
 	nav, _ := math.LegacyNewDecFromStr(pool.NAV)
 	shares := amount.Quo(nav)
-	now := time.Now().Unix()
-	depositID := fmt.Sprintf("dep_%d", now)
+	depositID := fmt.Sprintf("dep_%d_%d", now, len(s.deposits))
 
 	deposit := &types.DepositInfo{
 		DepositID:    depositID,
@@ -365,6 +393,21 @@ func (s *MockRiverpoolService) Deposit(poolID, user string, amount math.LegacyDe
 		CreatedAt:    now,
 	}
 	s.deposits[depositID] = deposit
+	if !isHolder {
+		pool.TotalHolders++
+	}
+	if redeem != nil {
+		redeem.UsedCount++
+		s.members[poolID+":"+user] = redeem.Code
+		s.redemptions[redeem.Code] = append(s.redemptions[redeem.Code], &types.InviteRedemption{
+			Code:       redeem.Code,
+			PoolID:     poolID,
+			Address:    user,
+			DepositID:  depositID,
+			Amount:     amount.String(),
+			RedeemedAt: now,
+		})
+	}
 
 	return &types.DepositResult{
 		DepositID:   depositID,
@@ -543,6 +586,10 @@ func (s *MockRiverpoolService) CreateCommunityPool(owner string, params *types.C
 		RedemptionDelayDays: int64(params.RedemptionDelay),
 		DailyRedemptionLimit: "10",
 		Owner:               owner,
+		AllowedMarkets:      params.AllowedMarkets,
+		MaxLeverage:         params.MaxLeverage,
+		IsPrivate:           params.IsPrivate,
+		MaxHolders:          params.MaxHolders,
 		CreatedAt:           now,
 		UpdatedAt:           now,
 	}
@@ -651,10 +698,14 @@ func (s *MockRiverpoolService) GetInviteCodes(poolID, owner string) ([]*types.In
 		return nil, fmt.Errorf("unauthorized: not pool owner")
 	}
 
-	now := time.Now().Unix()
-	return []*types.InviteCode{
-		{Code: "ALPHA123", PoolID: poolID, MaxUses: 10, UsedCount: 3, ExpiresAt: now + 86400*30, CreatedAt: now - 86400*7},
-	}, nil
+	codes := []*types.InviteCode{}
+	for _, code := range s.inviteCodes {
+		if code.PoolID == poolID {
+			codes = append(codes, code)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return codes, nil
 }
 
 func (s *MockRiverpoolService) GenerateInviteCode(poolID, owner string) (*types.InviteCode, error) {
@@ -671,14 +722,82 @@ func (s *MockRiverpoolService) GenerateInviteCode(poolID, owner string) (*types.
 	}
 
 	now := time.Now().Unix()
-	return &types.InviteCode{
-		Code:      fmt.Sprintf("INV%d", now),
+	code := &types.InviteCode{
+		Code:      fmt.Sprintf("INV%d%d", now, len(s.inviteCodes)),
 		PoolID:    poolID,
 		MaxUses:   10,
 		UsedCount: 0,
 		ExpiresAt: now + 86400*30,
 		CreatedAt: now,
-	}, nil
+	}
+	s.inviteCodes[code.Code] = code
+	return code, nil
+}
+
+func (s *MockRiverpoolService) RevokeInviteCode(poolID, owner, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invite, err := s.ownedInviteCode(poolID, owner, code)
+	if err != nil {
+		return err
+	}
+	if invite.RevokedAt == 0 {
+		invite.RevokedAt = time.Now().Unix()
+	}
+	return nil
+}
+
+func (s *MockRiverpoolService) GetInviteRedemptions(poolID, owner, code string) ([]*types.InviteRedemption, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.ownedInviteCode(poolID, owner, code); err != nil {
+		return nil, err
+	}
+	redemptions := append([]*types.InviteRedemption{}, s.redemptions[code]...)
+	return redemptions, nil
+}
+
+// ownedInviteCode returns one of the owner's invite codes for a pool
+func (s *MockRiverpoolService) ownedInviteCode(poolID, owner, code string) (*types.InviteCode, error) {
+	pool, ok := s.pools[poolID]
+	if !ok {
+		return nil, fmt.Errorf("pool not found: %s", poolID)
+	}
+
+	if pool.Owner != owner {
+		return nil, fmt.Errorf("unauthorized: not pool owner")
+	}
+
+	invite, ok := s.inviteCodes[code]
+	if !ok || invite.PoolID != poolID {
+		return nil, fmt.Errorf("invite code not found: %s", code)
+	}
+	return invite, nil
+}
+
+// isHolder reports whether user has deposited into the pool
+func (s *MockRiverpoolService) isHolder(poolID, user string) bool {
+	for _, d := range s.deposits {
+		if d.PoolID == poolID && d.User == user {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMockInviteCode mirrors the keeper's revoked, expired and exhausted checks
+func checkMockInviteCode(code *types.InviteCode, now int64) error {
+	switch {
+	case code.RevokedAt > 0:
+		return fmt.Errorf("%w: revoked", riverpooltypes.ErrInvalidInviteCode)
+	case code.ExpiresAt > 0 && now > code.ExpiresAt:
+		return fmt.Errorf("%w: expired", riverpooltypes.ErrInvalidInviteCode)
+	case code.MaxUses > 0 && code.UsedCount >= code.MaxUses:
+		return fmt.Errorf("%w: exhausted", riverpooltypes.ErrInvalidInviteCode)
+	}
+	return nil
 }
 
 func (s *MockRiverpoolService) PlacePoolOrder(poolID, owner, marketID, side string, size, price, leverage math.LegacyDec) (*types.PoolOrderResult, error) {
@@ -698,6 +817,22 @@ func (s *MockRiverpoolService) PlacePoolOrder(poolID, owner, marketID, side stri
 		return nil, fmt.Errorf("orders only allowed for community pools")
 	}
 
+	// Same market whitelist and leverage cap as the riverpool keeper
+	rules := &riverpooltypes.Pool{AllowedMarkets: pool.AllowedMarkets}
+	if pool.MaxLeverage != "" {
+		maxLeverage, err := math.LegacyNewDecFromStr(pool.MaxLeverage)
+		if err != nil {
+			return nil, err
+		}
+		rules.MaxLeverage = maxLeverage
+	}
+	if leverage.IsNil() {
+		leverage = rules.MaxLeverageOrDefault()
+	}
+	if err := rules.ValidateOrder(marketID, side, size, leverage); err != nil {
+		return nil, err
+	}
+
 	now := time.Now().Unix()
 	return &types.PoolOrderResult{
 		OrderID:   fmt.Sprintf("order_%d", now),
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Snapshot book depths
+const (
+	DefaultSnapshotDepth = 20
+	MaxSnapshotDepth     = 100
+)
+
+// handleSnapshot handles GET /v1/snapshot: every market's ticker, funding
+// rate, open interest and top book levels in one response, stamped with the
+// event sequence the books were read at
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	depth := DefaultSnapshotDepth
+	if d := r.URL.Query().Get("depth"); d != "" {
+		n, err := strconv.Atoi(d)
+		if err != nil || n <= 0 || n > MaxSnapshotDepth {
+			writeError(w, types.ErrCodeInvalidRequest, fmt.Sprintf("depth must be between 1 and %d", MaxSnapshotDepth))
+			return
+		}
+		depth = n
+	}
+
+	var marketIDs []string
+	if list := r.URL.Query().Get("markets"); list != "" {
+		for _, id := range strings.Split(list, ",") {
+			if s.getMockMarket(id) == nil {
+				writeError(w, types.ErrCodeMarketNotFound, "Market not found: "+id)
+				return
+			}
+			marketIDs = append(marketIDs, id)
+		}
+	} else {
+		for _, market := range s.getMockMarkets() {
+			marketIDs = append(marketIDs, market["market_id"].(string))
+		}
+	}
+
+	snapshot, err := s.exchangeSnapshot(r.Context(), marketIDs, depth)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeServiceUnavailable))
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// exchangeSnapshot reads the markets from the engine, or the oracle books
+// when no engine backs the server, and fills in the prices the engine does
+// not track from the oracle's tickers
+func (s *Server) exchangeSnapshot(ctx context.Context, marketIDs []string, depth int) (*types.ExchangeSnapshot, error) {
+	var snapshot *types.ExchangeSnapshot
+	if svc, ok := s.orderService.(types.SnapshotService); ok {
+		var err error
+		if snapshot, err = svc.GetSnapshot(ctx, marketIDs, depth); err != nil {
+			return nil, err
+		}
+	} else {
+		snapshot = s.mockSnapshot(marketIDs, depth)
+	}
+
+	for _, market := range snapshot.Markets {
+		if market.MarkPrice != "" {
+			continue
+		}
+		ticker := s.getMockTicker(market.MarketID)
+		market.MarkPrice, _ = ticker["mark_price"].(string)
+		market.IndexPrice, _ = ticker["index_price"].(string)
+		market.LastPrice, _ = ticker["last_price"].(string)
+		if market.FundingRate == "" {
+			market.FundingRate, _ = ticker["funding_rate"].(string)
+			market.NextFunding, _ = ticker["next_funding"].(int64)
+		}
+		if market.OpenInterest == "" {
+			market.OpenInterest, _ = ticker["open_interest"].(string)
+		}
+	}
+	return snapshot, nil
+}
+
+// mockSnapshot assembles a snapshot from the oracle books when no engine
+// backs the server. The books are fetched one by one and carry no sequence.
+func (s *Server) mockSnapshot(marketIDs []string, depth int) *types.ExchangeSnapshot {
+	snapshot := &types.ExchangeSnapshot{
+		Depth:     depth,
+		Markets:   make([]*types.MarketSnapshot, 0, len(marketIDs)),
+		Timestamp: time.Now().UnixMilli(),
+	}
+	for _, marketID := range marketIDs {
+		book := s.getMockOrderbook(marketID, depth)
+		bids, _ := book["bids"].([][]string)
+		asks, _ := book["asks"].([][]string)
+		snapshot.Markets = append(snapshot.Markets, &types.MarketSnapshot{
+			MarketID: marketID,
+			Bids:     bids,
+			Asks:     asks,
+			Checksum: obtypes.DepthChecksum(bids, asks),
+		})
+	}
+	return snapshot
+}
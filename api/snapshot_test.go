@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestSnapshot tests that the snapshot returns the requested markets' top
+// book levels at the event sequence they were read at
+func TestSnapshot(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	s.oracle = nil // tickers fall back to placeholders instead of calling out
+	handler := s.handler()
+
+	for _, price := range []string{"49900", "49800"} {
+		body := `{"market_id":"BTC-USDC","side":"buy","type":"limit","price":"` + price + `","quantity":"0.1","trader":"snapshot-maker"}`
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("failed to place order: %d %s", rec.Code, rec.Body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/snapshot?markets=BTC-USDC,ETH-USDC&depth=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var snapshot types.ExchangeSnapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+
+	events, _ := s.orderService.(types.EventService).GetEvents(context.Background(), 0, 0)
+	if snapshot.Seq == 0 || snapshot.Seq != events.LastSeq {
+		t.Errorf("expected seq %d, got %d", events.LastSeq, snapshot.Seq)
+	}
+	if len(snapshot.Markets) != 2 || snapshot.Markets[0].MarketID != "BTC-USDC" || snapshot.Markets[1].MarketID != "ETH-USDC" {
+		t.Fatalf("expected BTC-USDC and ETH-USDC, got %+v", snapshot.Markets)
+	}
+	btc := snapshot.Markets[0]
+	if len(btc.Bids) != 1 || btc.Bids[0][0] != "49900.000000000000000000" || len(btc.Asks) != 0 || btc.Checksum == 0 {
+		t.Errorf("expected the best bid only, got %+v", btc)
+	}
+	if btc.MarkPrice == "" || btc.FundingRate == "" {
+		t.Errorf("expected ticker fields to be filled, got %+v", btc)
+	}
+
+	for _, url := range []string{"/v1/snapshot?depth=0", "/v1/snapshot?depth=101", "/v1/snapshot?markets=DOGE-USDC"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if rec.Code == http.StatusOK {
+			t.Errorf("GET %s: expected an error, got 200", url)
+		}
+	}
+}
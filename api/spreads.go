@@ -0,0 +1,384 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// spreadMatchInterval is how often the standalone server trades resting
+// spread orders against the legs' books; on chain the EndBlocker does it
+// every block
+const spreadMatchInterval = time.Second
+
+// startSpreadMatcher matches resting spread orders when the order service
+// keeps its own books. The leg trades reach WebSocket subscribers through the
+// event publisher.
+func (s *Server) startSpreadMatcher() {
+	spreads, ok := s.orderService.(types.SpreadService)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(spreadMatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		if err := spreads.MatchSpreads(context.Background()); err != nil {
+			log.Printf("Spread matcher: %v", err)
+		}
+	}
+}
+
+// spreadService returns the keeper-backed spread service, writing 501 if the
+// order service has none
+func (s *Server) spreadService(w http.ResponseWriter) (types.SpreadService, bool) {
+	spreads, ok := s.orderService.(types.SpreadService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Spreads require a keeper-backed service")
+	}
+	return spreads, ok
+}
+
+// handleSpreads handles GET /v1/spreads, every defined spread
+func (s *Server) handleSpreads(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	spreads, ok := s.spreadService(w)
+	if !ok {
+		return
+	}
+	list, err := spreads.ListSpreads(r.Context())
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"spreads": list})
+}
+
+// handleSpread handles GET /v1/spreads/{spread_id}, the spread with the top of
+// its book and the prices implied by its legs
+func (s *Server) handleSpread(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	spreadID := strings.TrimPrefix(r.URL.Path, "/v1/spreads/")
+	if spreadID == "" || strings.Contains(spreadID, "/") {
+		writeError(w, types.ErrCodeInvalidPath, "Invalid spread path")
+		return
+	}
+	spreads, ok := s.spreadService(w)
+	if !ok {
+		return
+	}
+	quote, err := spreads.GetSpreadQuote(r.Context(), spreadID)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, quote)
+}
+
+// handleAdminSpreads handles POST /v1/admin/spreads, defining a spread between
+// two existing markets
+func (s *Server) handleAdminSpreads(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	spreads, ok := s.spreadService(w)
+	if !ok {
+		return
+	}
+	var req types.CreateSpreadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+		return
+	}
+	spread, err := spreads.CreateSpread(r.Context(), &req)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+		return
+	}
+	writeJSON(w, http.StatusCreated, spread)
+}
+
+// handleSpreadOrders handles /v1/spread-orders (POST place, GET ?trader= list)
+func (s *Server) handleSpreadOrders(w http.ResponseWriter, r *http.Request) {
+	spreads, ok := s.spreadService(w)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var req types.PlaceSpreadOrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		if req.Trader == "" {
+			req.Trader = r.Header.Get("X-Trader-Address")
+		}
+		if req.Trader == "" || req.SpreadID == "" {
+			writeError(w, types.ErrCodeMissingField, "trader and spread_id are required")
+			return
+		}
+		resp, err := spreads.PlaceSpreadOrder(r.Context(), &req)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusCreated, resp)
+
+	case http.MethodGet:
+		trader := webhookTrader(r)
+		if trader == "" {
+			writeError(w, types.ErrCodeMissingField, "trader address is required")
+			return
+		}
+		orders, err := spreads.ListSpreadOrders(r.Context(), trader)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"orders": orders})
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleSpreadOrder handles /v1/spread-orders/{order_id} (GET with its fills,
+// DELETE cancel)
+func (s *Server) handleSpreadOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := strings.TrimPrefix(r.URL.Path, "/v1/spread-orders/")
+	if orderID == "" || strings.Contains(orderID, "/") {
+		writeError(w, types.ErrCodeInvalidPath, "Invalid spread order path")
+		return
+	}
+	spreads, ok := s.spreadService(w)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		resp, err := spreads.GetSpreadOrder(r.Context(), orderID)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodDelete:
+		trader := webhookTrader(r)
+		if trader == "" {
+			writeError(w, types.ErrCodeMissingField, "trader address is required")
+			return
+		}
+		order, err := spreads.CancelSpreadOrder(r.Context(), trader, orderID)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, &types.CancelOrderResponse{Order: order, Cancelled: true})
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// ListSpreads returns every defined spread, ordered by ID
+func (rs *RealService) ListSpreads(ctx context.Context) ([]*types.Spread, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	spreads := rs.obKeeper.GetAllSpreads(rs.sdkCtx)
+	resp := make([]*types.Spread, 0, len(spreads))
+	for _, spread := range spreads {
+		resp = append(resp, convertSpread(spread))
+	}
+	return resp, nil
+}
+
+// GetSpreadQuote returns a spread with its own and its implied top of book
+func (rs *RealService) GetSpreadQuote(ctx context.Context, spreadID string) (*types.SpreadQuote, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	quote, err := rs.obKeeper.GetSpreadQuote(rs.sdkCtx, spreadID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &types.SpreadQuote{Spread: convertSpread(quote.Spread)}
+	if quote.BestBid != nil {
+		resp.BestBid, resp.BestBidQty = quote.BestBid.Price.String(), quote.BestBid.Quantity.String()
+	}
+	if quote.BestAsk != nil {
+		resp.BestAsk, resp.BestAskQty = quote.BestAsk.Price.String(), quote.BestAsk.Quantity.String()
+	}
+	if !quote.ImpliedBid.IsNil() {
+		resp.ImpliedBid, resp.ImpliedBidQty = quote.ImpliedBid.String(), quote.ImpliedBidQty.String()
+	}
+	if !quote.ImpliedAsk.IsNil() {
+		resp.ImpliedAsk, resp.ImpliedAskQty = quote.ImpliedAsk.String(), quote.ImpliedAskQty.String()
+	}
+	return resp, nil
+}
+
+// CreateSpread defines a spread between two existing markets
+func (rs *RealService) CreateSpread(ctx context.Context, req *types.CreateSpreadRequest) (*types.Spread, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	spread := obtypes.NewSpread(req.FrontMarket, req.BackMarket)
+	if req.Ratio != "" {
+		ratio, err := math.LegacyNewDecFromStr(req.Ratio)
+		if err != nil {
+			return nil, obtypes.ErrInvalidSpread.Wrapf("invalid ratio: %s", req.Ratio)
+		}
+		spread.Ratio = ratio
+	}
+	if req.TickSize != "" {
+		tick, err := math.LegacyNewDecFromStr(req.TickSize)
+		if err != nil {
+			return nil, obtypes.ErrInvalidSpread.Wrapf("invalid tick size: %s", req.TickSize)
+		}
+		spread.TickSize = tick
+	}
+	if err := rs.obKeeper.CreateSpread(rs.clockCtx(), spread); err != nil {
+		return nil, err
+	}
+	return convertSpread(spread), nil
+}
+
+// PlaceSpreadOrder places a limit order on a spread, returning its fills
+func (rs *RealService) PlaceSpreadOrder(ctx context.Context, req *types.PlaceSpreadOrderRequest) (*types.SpreadOrderResponse, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if req.Side != "buy" && req.Side != "sell" {
+		return nil, obtypes.ErrInvalidSide.Wrapf("invalid side: %s", req.Side)
+	}
+	side := obtypes.SideBuy
+	if req.Side == "sell" {
+		side = obtypes.SideSell
+	}
+	price, err := math.LegacyNewDecFromStr(req.Price)
+	if err != nil {
+		return nil, obtypes.ErrInvalidPrice.Wrapf("invalid price: %s", req.Price)
+	}
+	qty, err := math.LegacyNewDecFromStr(req.Quantity)
+	if err != nil {
+		return nil, obtypes.ErrInvalidQuantity.Wrapf("invalid quantity: %s", req.Quantity)
+	}
+
+	order, fills, err := rs.obKeeper.PlaceSpreadOrder(rs.clockCtx(), req.Trader, req.SpreadID, side, price, qty)
+	if err != nil {
+		return nil, err
+	}
+	rs.matchEngine.Flush(rs.sdkCtx)
+	return rs.convertSpreadOrder(order, fills), nil
+}
+
+// GetSpreadOrder returns a spread order with all its fills
+func (rs *RealService) GetSpreadOrder(ctx context.Context, orderID string) (*types.SpreadOrderResponse, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	order := rs.obKeeper.GetSpreadOrder(rs.sdkCtx, orderID)
+	if order == nil {
+		return nil, obtypes.ErrOrderNotFound.Wrap(orderID)
+	}
+	return rs.convertSpreadOrder(order, rs.obKeeper.GetSpreadFills(rs.sdkCtx, orderID)), nil
+}
+
+// CancelSpreadOrder cancels a trader's resting spread order
+func (rs *RealService) CancelSpreadOrder(ctx context.Context, trader, orderID string) (*types.Order, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	order, err := rs.obKeeper.CancelSpreadOrder(rs.clockCtx(), trader, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return rs.convertOrder(order), nil
+}
+
+// ListSpreadOrders returns a trader's spread orders
+func (rs *RealService) ListSpreadOrders(ctx context.Context, trader string) ([]*types.Order, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	orders := rs.obKeeper.GetSpreadOrdersByTrader(rs.sdkCtx, trader)
+	resp := make([]*types.Order, 0, len(orders))
+	for _, order := range orders {
+		resp = append(resp, rs.convertOrder(order))
+	}
+	return resp, nil
+}
+
+// MatchSpreads trades resting spread orders against the legs' books, as the
+// chain's EndBlocker does, for the standalone server's spread matcher
+func (rs *RealService) MatchSpreads(ctx context.Context) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.obKeeper.SpreadEndBlocker(rs.clockCtx())
+	return rs.matchEngine.Flush(rs.sdkCtx)
+}
+
+// convertSpreadOrder converts a spread order and its fills
+func (rs *RealService) convertSpreadOrder(order *obtypes.Order, fills []*obtypes.SpreadFill) *types.SpreadOrderResponse {
+	resp := &types.SpreadOrderResponse{Order: rs.convertOrder(order), Fills: make([]*types.SpreadFill, 0, len(fills))}
+	for _, fill := range fills {
+		resp.Fills = append(resp.Fills, &types.SpreadFill{
+			SpreadID:   fill.SpreadID,
+			OrderID:    fill.OrderID,
+			Trader:     fill.Trader,
+			Side:       fill.Side.String(),
+			Price:      fill.Price.String(),
+			Quantity:   fill.Quantity.String(),
+			FrontPrice: fill.FrontPrice.String(),
+			BackPrice:  fill.BackPrice.String(),
+			TradeIDs:   fill.TradeIDs,
+			Implied:    fill.Implied,
+			Timestamp:  fill.Timestamp.UnixMilli(),
+		})
+	}
+	return resp
+}
+
+// convertSpread converts a spread definition
+func convertSpread(spread *obtypes.Spread) *types.Spread {
+	return &types.Spread{
+		SpreadID:    spread.SpreadID,
+		FrontMarket: spread.FrontMarket,
+		BackMarket:  spread.BackMarket,
+		Ratio:       spread.Ratio.String(),
+		TickSize:    spread.TickSize.String(),
+		CreatedAt:   spread.CreatedAt.UnixMilli(),
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestSpreadEndpoints tests that an admin-defined spread is listed and quoted,
+// and that unknown spreads are reported as not found
+func TestSpreadEndpoints(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	config.AdminToken = "admin-secret"
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	do := func(method, target, body string, admin bool, v interface{}) int {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if admin {
+			req.Header.Set(adminTokenHeader, config.AdminToken)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("%s %s: invalid JSON %q: %v", method, target, rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+
+	create := `{"front_market":"BTC-USDC","back_market":"ETH-USDC","tick_size":"0.5"}`
+	if code := do(http.MethodPost, "/v1/admin/spreads", create, false, nil); code != http.StatusForbidden {
+		t.Errorf("expected 403 without the admin token, got %d", code)
+	}
+	var spread types.Spread
+	if code := do(http.MethodPost, "/v1/admin/spreads", create, true, &spread); code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	if spread.SpreadID != "BTC-USDC:ETH-USDC" || spread.Ratio != "1.000000000000000000" || spread.TickSize != "0.500000000000000000" {
+		t.Errorf("unexpected spread %+v", spread)
+	}
+	var apiErr types.APIError
+	if code := do(http.MethodPost, "/v1/admin/spreads", create, true, &apiErr); code != http.StatusConflict || apiErr.Code != types.ErrCodeSpreadExists {
+		t.Errorf("expected 409 spread_exists for a duplicate, got %d %s", code, apiErr.Code)
+	}
+	if code := do(http.MethodPost, "/v1/admin/spreads", `{"front_market":"BTC-USDC","back_market":"BTC-USDC"}`, true, &apiErr); code != http.StatusBadRequest || apiErr.Code != types.ErrCodeInvalidSpread {
+		t.Errorf("expected 400 invalid_spread for identical legs, got %d %s", code, apiErr.Code)
+	}
+
+	var list struct {
+		Spreads []*types.Spread `json:"spreads"`
+	}
+	if code := do(http.MethodGet, "/v1/spreads", "", false, &list); code != http.StatusOK || len(list.Spreads) != 1 {
+		t.Fatalf("expected one spread, got %d %+v", code, list)
+	}
+	var quote types.SpreadQuote
+	if code := do(http.MethodGet, "/v1/spreads/BTC-USDC:ETH-USDC", "", false, &quote); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if quote.Spread == nil || quote.Spread.SpreadID != spread.SpreadID || quote.BestBid != "" || quote.ImpliedAsk != "" {
+		t.Errorf("expected an empty quote for %s, got %+v", spread.SpreadID, quote)
+	}
+
+	if code := do(http.MethodGet, "/v1/spreads/BTC-USDC:SOL-USDC", "", false, &apiErr); code != http.StatusNotFound || apiErr.Code != types.ErrCodeSpreadNotFound {
+		t.Errorf("expected 404 spread_not_found, got %d %s", code, apiErr.Code)
+	}
+	order := `{"spread_id":"BTC-USDC:SOL-USDC","trader":"alice","side":"buy","price":"-5","quantity":"1"}`
+	if code := do(http.MethodPost, "/v1/spread-orders", order, false, &apiErr); code != http.StatusNotFound || apiErr.Code != types.ErrCodeSpreadNotFound {
+		t.Errorf("expected 404 spread_not_found for an order, got %d %s", code, apiErr.Code)
+	}
+	if code := do(http.MethodGet, "/v1/spread-orders/missing", "", false, nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown spread order, got %d", code)
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/surveillance"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// Surveillance list page sizes
+const (
+	DefaultSurveillanceLimit = 100
+	MaxSurveillanceLimit     = 1000
+)
+
+func newSurveillance(config *Config) *surveillance.Monitor {
+	if config.Surveillance != nil {
+		return surveillance.NewMonitor(*config.Surveillance)
+	}
+	return surveillance.NewMonitor(surveillance.DefaultConfig())
+}
+
+// observeSurveillance feeds one event log entry to the surveillance monitor
+func (s *Server) observeSurveillance(event *types.Event) {
+	switch {
+	case event.Trade != nil:
+		price, _ := strconv.ParseFloat(event.Trade.Price, 64)
+		s.surveillance.ObserveTrade(surveillance.Trade{
+			TradeID:      event.Trade.TradeID,
+			MarketID:     event.Trade.MarketID,
+			TakerOrderID: event.Trade.TakerOrderID,
+			MakerOrderID: event.Trade.MakerOrderID,
+			TakerSide:    wsSide(event.Trade.Side),
+			Price:        price,
+			Time:         time.UnixMilli(event.Trade.Timestamp),
+		})
+	case event.Order != nil:
+		price, _ := strconv.ParseFloat(event.Order.Price, 64)
+		filled, _ := strconv.ParseFloat(event.Order.FilledQty, 64)
+		status := event.Order.Status
+		s.surveillance.ObserveOrder(surveillance.Order{
+			OrderID:   event.Order.OrderID,
+			Trader:    event.Order.Trader,
+			MarketID:  event.Order.MarketID,
+			Side:      wsSide(event.Order.Side),
+			Price:     price,
+			FilledQty: filled,
+			Cancelled: status == "ORDER_STATUS_CANCELLED",
+			Closed:    status == "ORDER_STATUS_FILLED" || status == "ORDER_STATUS_CANCELLED" || status == "ORDER_STATUS_EXPIRED" || status == "ORDER_STATUS_PRICE_PROTECTED",
+			Time:      time.UnixMilli(event.Order.UpdatedAt),
+		})
+	}
+}
+
+// surveillanceLimit parses the limit query parameter, or writes the error response
+func surveillanceLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	limit := DefaultSurveillanceLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 || n > MaxSurveillanceLimit {
+			writeError(w, types.ErrCodeInvalidRequest, "limit must be between 1 and "+strconv.Itoa(MaxSurveillanceLimit))
+			return 0, false
+		}
+		limit = n
+	}
+	return limit, true
+}
+
+// handleAdminSurveillanceAlerts handles GET /v1/admin/surveillance/alerts
+// ?status=&kind=&trader=&limit=, the review queue newest first
+func (s *Server) handleAdminSurveillanceAlerts(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	limit, ok := surveillanceLimit(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	alerts := s.surveillance.Alerts(surveillance.AlertFilter{
+		Status: query.Get("status"),
+		Kind:   query.Get("kind"),
+		Trader: query.Get("trader"),
+		Limit:  limit,
+	})
+	writeJSON(w, http.StatusOK, map[string]interface{}{"alerts": alerts})
+}
+
+// handleAdminSurveillanceAlert handles GET /v1/admin/surveillance/alerts/{id}
+// and POST /v1/admin/surveillance/alerts/{id}/review {"resolution", "note"}
+func (s *Server) handleAdminSurveillanceAlert(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	id, review := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v1/admin/surveillance/alerts/"), "/review")
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, types.ErrCodeInvalidPath, "Invalid surveillance alert path")
+		return
+	}
+
+	switch {
+	case !review && r.Method == http.MethodGet:
+		alert, err := s.surveillance.Alert(id)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, alert)
+
+	case review && r.Method == http.MethodPost:
+		var req struct {
+			Resolution string `json:"resolution"`
+			Note       string `json:"note"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		alert, err := s.surveillance.Review(id, req.Resolution, req.Note)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusOK, alert)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleAdminSurveillanceScores handles GET /v1/admin/surveillance/scores
+// ?trader=&limit=: one trader's risk score, or every flagged trader's,
+// highest first
+func (s *Server) handleAdminSurveillanceScores(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if trader := r.URL.Query().Get("trader"); trader != "" {
+		writeJSON(w, http.StatusOK, s.surveillance.Score(trader))
+		return
+	}
+	limit, ok := surveillanceLimit(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"scores": s.surveillance.Scores(limit)})
+}
@@ -0,0 +1,743 @@
+// Package surveillance flags abusive order flow in the engine's event log.
+//
+// Three patterns are detected:
+//   - wash trades: a trade between an account and itself, or between accounts
+//     whose orders were placed with the same API key
+//   - spoofing: orders resting near the touch that are mostly cancelled
+//     unfilled, measured as a cancel-to-fill ratio over a rolling window
+//   - momentum ignition: a burst of aggressive trades in one direction that
+//     moves the price, followed by the same account trading the other way
+//
+// Each finding opens an alert in a review queue; repeats of an open alert are
+// folded into it. Alerts raise the risk scores of the accounts involved until
+// they are dismissed or age out of the score window. State is kept in memory.
+package surveillance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Alert kinds
+const (
+	AlertWashTrade        = "wash_trade"
+	AlertSpoofing         = "spoofing"
+	AlertMomentumIgnition = "momentum_ignition"
+)
+
+// Alert review statuses
+const (
+	StatusOpen      = "open"
+	StatusConfirmed = "confirmed"
+	StatusDismissed = "dismissed"
+)
+
+// Order sides
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
+const (
+	DefaultWindow            = 10 * time.Minute
+	DefaultTouchBps          = 10
+	DefaultSpoofMinCancels   = 20
+	DefaultSpoofCancelRatio  = 10
+	DefaultIgnitionWindow    = 30 * time.Second
+	DefaultIgnitionMoveBps   = 50
+	DefaultIgnitionMinTrades = 3
+	DefaultScoreWindow       = 24 * time.Hour
+	DefaultMaxAlerts         = 10000
+
+	// MaxScore caps a trader's risk score
+	MaxScore = 100
+
+	// maxEvidence bounds the trade and order IDs kept per alert
+	maxEvidence = 20
+
+	// idleOrderRetention is how long an order that is still open is
+	// remembered without updates
+	idleOrderRetention = 24 * time.Hour
+
+	// maxScoredOccurrences bounds how many repeats of one alert add to a score
+	maxScoredOccurrences = 3
+)
+
+// alertWeights is the score each alert occurrence adds, by kind
+var alertWeights = map[string]int{
+	AlertWashTrade:        40,
+	AlertSpoofing:         25,
+	AlertMomentumIgnition: 35,
+}
+
+// Surveillance errors
+var (
+	ErrAlertNotFound     = errors.New("surveillance alert not found")
+	ErrAlertReviewed     = errors.New("surveillance alert already reviewed")
+	ErrInvalidResolution = errors.New("resolution must be confirmed or dismissed")
+)
+
+// Config contains detection thresholds. Zero values take the defaults.
+type Config struct {
+	Window            time.Duration // Rolling window of the cancel-to-fill ratio
+	TouchBps          float64       // Orders within this distance of the last trade price are near the touch
+	SpoofMinCancels   int           // Near-touch cancels in the window before spoofing is considered
+	SpoofCancelRatio  float64       // Near-touch cancels per fill that flag spoofing
+	IgnitionWindow    time.Duration // Span of an aggressive burst, and of the reversal after it
+	IgnitionMoveBps   float64       // Price move of a burst that counts as ignition
+	IgnitionMinTrades int           // Aggressive trades a burst needs
+	ScoreWindow       time.Duration // Alerts last seen within this window count towards scores
+	MaxAlerts         int           // Alerts kept; the oldest are dropped first
+}
+
+// DefaultConfig returns the default thresholds
+func DefaultConfig() Config {
+	return Config{
+		Window:            DefaultWindow,
+		TouchBps:          DefaultTouchBps,
+		SpoofMinCancels:   DefaultSpoofMinCancels,
+		SpoofCancelRatio:  DefaultSpoofCancelRatio,
+		IgnitionWindow:    DefaultIgnitionWindow,
+		IgnitionMoveBps:   DefaultIgnitionMoveBps,
+		IgnitionMinTrades: DefaultIgnitionMinTrades,
+		ScoreWindow:       DefaultScoreWindow,
+		MaxAlerts:         DefaultMaxAlerts,
+	}
+}
+
+// Order is an order update from the event log
+type Order struct {
+	OrderID   string
+	Trader    string
+	MarketID  string
+	Side      string  // SideBuy or SideSell
+	Price     float64 // 0 for market orders
+	FilledQty float64
+	Cancelled bool // the update cancelled the order
+	Closed    bool // the order is filled, cancelled or expired
+	Time      time.Time
+}
+
+// Trade is a trade from the event log
+type Trade struct {
+	TradeID      string
+	MarketID     string
+	TakerOrderID string
+	MakerOrderID string
+	TakerSide    string // SideBuy or SideSell
+	Price        float64
+	Time         time.Time
+}
+
+// Alert is a finding in the review queue
+type Alert struct {
+	ID           string   `json:"alert_id"`
+	Kind         string   `json:"kind"`
+	Trader       string   `json:"trader"`
+	Counterparty string   `json:"counterparty,omitempty"` // wash trades: the other account
+	MarketID     string   `json:"market_id"`
+	Detail       string   `json:"detail"`
+	Evidence     []string `json:"evidence"` // trade or order IDs, oldest first
+	Occurrences  int      `json:"occurrences"`
+	Status       string   `json:"status"`
+	ReviewNote   string   `json:"review_note,omitempty"`
+	CreatedAt    int64    `json:"created_at"`
+	LastSeenAt   int64    `json:"last_seen_at"`
+	ReviewedAt   int64    `json:"reviewed_at,omitempty"`
+}
+
+// involves returns true if the trader is either account of the alert
+func (a *Alert) involves(trader string) bool {
+	return a.Trader == trader || a.Counterparty == trader
+}
+
+// Score is a trader's risk score and the activity behind it
+type Score struct {
+	Trader           string   `json:"trader"`
+	Score            int      `json:"score"` // 0 to MaxScore
+	WashTrades       int      `json:"wash_trades"`
+	Spoofing         int      `json:"spoofing"`
+	MomentumIgnition int      `json:"momentum_ignition"`
+	OpenAlerts       int      `json:"open_alerts"`
+	NearTouchCancels int      `json:"near_touch_cancels"` // in the current window, across markets
+	Fills            int      `json:"fills"`              // in the current window, across markets
+	LinkedAccounts   []string `json:"linked_accounts,omitempty"`
+}
+
+// AlertFilter selects alerts from the review queue. Empty fields match all.
+type AlertFilter struct {
+	Status string
+	Kind   string
+	Trader string
+	Limit  int
+}
+
+type orderState struct {
+	trader    string
+	marketID  string
+	side      string
+	nearTouch bool
+	closed    bool
+	updatedAt time.Time
+}
+
+type flowKey struct {
+	trader   string
+	marketID string
+}
+
+type flowEvent struct {
+	at time.Time
+	id string
+}
+
+// flow is a trader's recent activity in one market
+type flow struct {
+	cancels []flowEvent // near-touch orders cancelled unfilled
+	fills   []flowEvent
+	burst   burst
+}
+
+// burst is a run of aggressive trades in one direction
+type burst struct {
+	side       string
+	start      time.Time
+	last       time.Time
+	startPrice float64
+	moveBps    float64
+	trades     []string
+	ignited    bool
+}
+
+type alertKey struct {
+	kind         string
+	trader       string
+	counterparty string
+	marketID     string
+}
+
+// Monitor consumes order and trade events and maintains alerts and scores
+type Monitor struct {
+	config Config
+	now    func() time.Time
+
+	mu          sync.Mutex
+	orders      map[string]*orderState
+	pending     map[string][]Trade // missing order ID -> trades waiting for it
+	lastPrice   map[string]float64
+	flows       map[flowKey]*flow
+	credentials map[string]map[string]bool // credential fingerprint -> traders
+	traderKeys  map[string]map[string]bool // trader -> credential fingerprints
+	alerts      []*Alert                   // creation order
+	byID        map[string]*Alert
+	open        map[alertKey]*Alert
+	nextID      uint64
+	lastPrune   time.Time
+}
+
+// NewMonitor creates a monitor with the given thresholds
+func NewMonitor(config Config) *Monitor {
+	defaults := DefaultConfig()
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.TouchBps <= 0 {
+		config.TouchBps = defaults.TouchBps
+	}
+	if config.SpoofMinCancels <= 0 {
+		config.SpoofMinCancels = defaults.SpoofMinCancels
+	}
+	if config.SpoofCancelRatio <= 0 {
+		config.SpoofCancelRatio = defaults.SpoofCancelRatio
+	}
+	if config.IgnitionWindow <= 0 {
+		config.IgnitionWindow = defaults.IgnitionWindow
+	}
+	if config.IgnitionMoveBps <= 0 {
+		config.IgnitionMoveBps = defaults.IgnitionMoveBps
+	}
+	if config.IgnitionMinTrades <= 0 {
+		config.IgnitionMinTrades = defaults.IgnitionMinTrades
+	}
+	if config.ScoreWindow <= 0 {
+		config.ScoreWindow = defaults.ScoreWindow
+	}
+	if config.MaxAlerts <= 0 {
+		config.MaxAlerts = defaults.MaxAlerts
+	}
+
+	return &Monitor{
+		config:      config,
+		now:         time.Now,
+		orders:      make(map[string]*orderState),
+		pending:     make(map[string][]Trade),
+		lastPrice:   make(map[string]float64),
+		flows:       make(map[flowKey]*flow),
+		credentials: make(map[string]map[string]bool),
+		traderKeys:  make(map[string]map[string]bool),
+		byID:        make(map[string]*Alert),
+		open:        make(map[alertKey]*Alert),
+	}
+}
+
+// Fingerprint returns the form a credential is stored in, so raw API keys are
+// never kept
+func Fingerprint(credential string) string {
+	sum := sha256.Sum256([]byte(credential))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ObserveCredential records that an order for trader was placed with the
+// credential (an API key). Traders that share a credential are linked, and
+// trades between them are wash trades.
+func (m *Monitor) ObserveCredential(credential, trader string) {
+	if credential == "" || trader == "" {
+		return
+	}
+	fp := Fingerprint(credential)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.credentials[fp] == nil {
+		m.credentials[fp] = make(map[string]bool)
+	}
+	m.credentials[fp][trader] = true
+	if m.traderKeys[trader] == nil {
+		m.traderKeys[trader] = make(map[string]bool)
+	}
+	m.traderKeys[trader][fp] = true
+}
+
+// ObserveOrder processes an order update
+func (m *Monitor) ObserveOrder(o Order) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune(o.Time)
+
+	state, ok := m.orders[o.OrderID]
+	if !ok {
+		state = &orderState{
+			trader:    o.Trader,
+			marketID:  o.MarketID,
+			side:      o.Side,
+			nearTouch: m.nearTouch(o.MarketID, o.Price),
+		}
+		m.orders[o.OrderID] = state
+	}
+	state.updatedAt = o.Time
+
+	if o.Cancelled && !state.closed && state.nearTouch && o.FilledQty == 0 {
+		f := m.flow(state.trader, state.marketID)
+		f.cancels = append(f.cancels, flowEvent{at: o.Time, id: o.OrderID})
+		m.checkSpoofing(state.trader, state.marketID, f, o.Time)
+	}
+	state.closed = state.closed || o.Closed
+
+	// Trades are logged before the taker's order update, so they may be
+	// waiting for this order
+	if trades := m.pending[o.OrderID]; len(trades) > 0 {
+		delete(m.pending, o.OrderID)
+		for _, t := range trades {
+			m.processTrade(t)
+		}
+	}
+}
+
+// ObserveTrade processes a trade
+func (m *Monitor) ObserveTrade(t Trade) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune(t.Time)
+	m.processTrade(t)
+	m.lastPrice[t.MarketID] = t.Price
+}
+
+// processTrade checks a trade once the traders of both its orders are known
+func (m *Monitor) processTrade(t Trade) {
+	for _, id := range []string{t.TakerOrderID, t.MakerOrderID} {
+		if _, ok := m.orders[id]; !ok {
+			m.pending[id] = append(m.pending[id], t)
+			return
+		}
+	}
+	taker := m.orders[t.TakerOrderID].trader
+	maker := m.orders[t.MakerOrderID].trader
+
+	if taker == maker {
+		m.raise(AlertWashTrade, taker, "", t.MarketID, "self-trade", t.TradeID, t.Time)
+	} else if m.linked(taker, maker) {
+		m.raise(AlertWashTrade, taker, maker, t.MarketID, "accounts share an API key", t.TradeID, t.Time)
+	}
+
+	m.recordFill(taker, t.MarketID, t.TakerSide, true, t)
+	m.recordFill(maker, t.MarketID, opposite(t.TakerSide), false, t)
+}
+
+// recordFill counts a fill towards the trader's cancel-to-fill ratio and
+// tracks aggressive bursts for momentum ignition
+func (m *Monitor) recordFill(trader, marketID, side string, aggressive bool, t Trade) {
+	f := m.flow(trader, marketID)
+	f.fills = append(f.fills, flowEvent{at: t.Time, id: t.TradeID})
+
+	b := &f.burst
+	if b.ignited && side == opposite(b.side) && t.Time.Sub(b.last) <= m.config.IgnitionWindow {
+		detail := fmt.Sprintf("%d aggressive %ss moved the price %.0f bps in %s, then %s",
+			len(b.trades), b.side, b.moveBps, b.last.Sub(b.start).Round(time.Millisecond), pastTense(side))
+		m.raise(AlertMomentumIgnition, trader, "", marketID, detail, "", t.Time, append(b.trades, t.TradeID)...)
+		*b = burst{}
+	}
+	if !aggressive {
+		return
+	}
+
+	if b.side != side || t.Time.Sub(b.start) > m.config.IgnitionWindow {
+		*b = burst{side: side, start: t.Time, startPrice: t.Price}
+	}
+	b.last = t.Time
+	b.trades = append(b.trades, t.TradeID)
+	if b.startPrice > 0 {
+		b.moveBps = (t.Price - b.startPrice) / b.startPrice * 10000
+		if side == SideSell {
+			b.moveBps = -b.moveBps
+		}
+	}
+	if len(b.trades) >= m.config.IgnitionMinTrades && b.moveBps >= m.config.IgnitionMoveBps {
+		b.ignited = true
+	}
+}
+
+// checkSpoofing raises an alert once near-touch cancels outnumber fills by
+// the configured ratio, then starts counting afresh
+func (m *Monitor) checkSpoofing(trader, marketID string, f *flow, now time.Time) {
+	m.trimFlow(f, now)
+	cancels, fills := len(f.cancels), len(f.fills)
+	if cancels < m.config.SpoofMinCancels || float64(cancels) < m.config.SpoofCancelRatio*float64(max(fills, 1)) {
+		return
+	}
+
+	evidence := make([]string, 0, cancels)
+	for _, c := range f.cancels {
+		evidence = append(evidence, c.id)
+	}
+	detail := fmt.Sprintf("%d near-touch orders cancelled unfilled against %d fills in %s", cancels, fills, m.config.Window)
+	m.raise(AlertSpoofing, trader, "", marketID, detail, "", now, evidence...)
+	f.cancels = nil
+}
+
+// raise opens an alert, or folds the occurrence into the open alert of the
+// same kind for the same accounts and market
+func (m *Monitor) raise(kind, trader, counterparty, marketID, detail, evidenceID string, at time.Time, evidence ...string) {
+	if evidenceID != "" {
+		evidence = append(evidence, evidenceID)
+	}
+	key := alertKey{kind: kind, trader: trader, counterparty: counterparty, marketID: marketID}
+	alert, ok := m.open[key]
+	if !ok {
+		m.nextID++
+		alert = &Alert{
+			ID:           "sv-" + strconv.FormatUint(m.nextID, 10),
+			Kind:         kind,
+			Trader:       trader,
+			Counterparty: counterparty,
+			MarketID:     marketID,
+			Status:       StatusOpen,
+			CreatedAt:    at.UnixMilli(),
+		}
+		m.open[key] = alert
+		m.byID[alert.ID] = alert
+		m.alerts = append(m.alerts, alert)
+		if len(m.alerts) > m.config.MaxAlerts {
+			m.dropAlert(m.alerts[0])
+		}
+	}
+	alert.Detail = detail
+	alert.Occurrences++
+	alert.LastSeenAt = at.UnixMilli()
+	alert.Evidence = append(alert.Evidence, evidence...)
+	if len(alert.Evidence) > maxEvidence {
+		alert.Evidence = append([]string(nil), alert.Evidence[len(alert.Evidence)-maxEvidence:]...)
+	}
+}
+
+// dropAlert removes an alert from the queue
+func (m *Monitor) dropAlert(alert *Alert) {
+	delete(m.byID, alert.ID)
+	key := alertKey{kind: alert.Kind, trader: alert.Trader, counterparty: alert.Counterparty, marketID: alert.MarketID}
+	if m.open[key] == alert {
+		delete(m.open, key)
+	}
+	for i, a := range m.alerts {
+		if a == alert {
+			m.alerts = append(m.alerts[:i:i], m.alerts[i+1:]...)
+			break
+		}
+	}
+}
+
+// Alerts returns alerts matching the filter, newest first
+func (m *Monitor) Alerts(filter AlertFilter) []*Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]*Alert, 0)
+	for i := len(m.alerts) - 1; i >= 0; i-- {
+		a := m.alerts[i]
+		if (filter.Status != "" && a.Status != filter.Status) ||
+			(filter.Kind != "" && a.Kind != filter.Kind) ||
+			(filter.Trader != "" && !a.involves(filter.Trader)) {
+			continue
+		}
+		result = append(result, copyAlert(a))
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// Alert returns one alert
+func (m *Monitor) Alert(id string) (*Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	alert, ok := m.byID[id]
+	if !ok {
+		return nil, ErrAlertNotFound
+	}
+	return copyAlert(alert), nil
+}
+
+// Review closes an open alert as confirmed or dismissed. Confirmed alerts
+// weigh double in scores; dismissed alerts no longer count. Later
+// occurrences open a new alert.
+func (m *Monitor) Review(id, resolution, note string) (*Alert, error) {
+	if resolution != StatusConfirmed && resolution != StatusDismissed {
+		return nil, ErrInvalidResolution
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	alert, ok := m.byID[id]
+	if !ok {
+		return nil, ErrAlertNotFound
+	}
+	if alert.Status != StatusOpen {
+		return nil, fmt.Errorf("%w: %s", ErrAlertReviewed, alert.Status)
+	}
+	alert.Status = resolution
+	alert.ReviewNote = note
+	alert.ReviewedAt = m.now().UnixMilli()
+	delete(m.open, alertKey{kind: alert.Kind, trader: alert.Trader, counterparty: alert.Counterparty, marketID: alert.MarketID})
+	return copyAlert(alert), nil
+}
+
+// Score returns a trader's risk score. Each alert occurrence in the score
+// window adds its kind's weight, up to three occurrences per alert; confirmed
+// alerts count double and dismissed ones not at all.
+func (m *Monitor) Score(trader string) *Score {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.score(trader, m.now())
+}
+
+// Scores returns the scores of every trader involved in an alert in the
+// score window, highest first
+func (m *Monitor) Scores(limit int) []*Score {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	cutoff := now.Add(-m.config.ScoreWindow).UnixMilli()
+	seen := make(map[string]bool)
+	scores := make([]*Score, 0)
+	for _, a := range m.alerts {
+		if a.LastSeenAt < cutoff {
+			continue
+		}
+		for _, trader := range []string{a.Trader, a.Counterparty} {
+			if trader == "" || seen[trader] {
+				continue
+			}
+			seen[trader] = true
+			scores = append(scores, m.score(trader, now))
+		}
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Trader < scores[j].Trader
+	})
+	if limit > 0 && len(scores) > limit {
+		scores = scores[:limit]
+	}
+	return scores
+}
+
+func (m *Monitor) score(trader string, now time.Time) *Score {
+	score := &Score{Trader: trader}
+	cutoff := now.Add(-m.config.ScoreWindow).UnixMilli()
+	points := 0
+	for _, a := range m.alerts {
+		if !a.involves(trader) || a.LastSeenAt < cutoff {
+			continue
+		}
+		switch a.Kind {
+		case AlertWashTrade:
+			score.WashTrades += a.Occurrences
+		case AlertSpoofing:
+			score.Spoofing += a.Occurrences
+		case AlertMomentumIgnition:
+			score.MomentumIgnition += a.Occurrences
+		}
+		switch a.Status {
+		case StatusOpen:
+			score.OpenAlerts++
+			points += alertWeights[a.Kind] * min(a.Occurrences, maxScoredOccurrences)
+		case StatusConfirmed:
+			points += 2 * alertWeights[a.Kind] * min(a.Occurrences, maxScoredOccurrences)
+		}
+	}
+	score.Score = min(points, MaxScore)
+
+	for key, f := range m.flows {
+		if key.trader != trader {
+			continue
+		}
+		m.trimFlow(f, now)
+		score.NearTouchCancels += len(f.cancels)
+		score.Fills += len(f.fills)
+	}
+	score.LinkedAccounts = m.linkedAccounts(trader)
+	return score
+}
+
+// linked returns true if two traders placed orders with a shared credential
+func (m *Monitor) linked(a, b string) bool {
+	for fp := range m.traderKeys[a] {
+		if m.credentials[fp][b] {
+			return true
+		}
+	}
+	return false
+}
+
+// linkedAccounts returns the other traders sharing a credential with trader, sorted
+func (m *Monitor) linkedAccounts(trader string) []string {
+	seen := make(map[string]bool)
+	for fp := range m.traderKeys[trader] {
+		for other := range m.credentials[fp] {
+			if other != trader {
+				seen[other] = true
+			}
+		}
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+	linked := make([]string, 0, len(seen))
+	for other := range seen {
+		linked = append(linked, other)
+	}
+	sort.Strings(linked)
+	return linked
+}
+
+// nearTouch returns true if a price is within TouchBps of the market's last
+// trade price
+func (m *Monitor) nearTouch(marketID string, price float64) bool {
+	last := m.lastPrice[marketID]
+	if price <= 0 || last <= 0 {
+		return false
+	}
+	return math.Abs(price-last)/last*10000 <= m.config.TouchBps
+}
+
+func (m *Monitor) flow(trader, marketID string) *flow {
+	key := flowKey{trader: trader, marketID: marketID}
+	f, ok := m.flows[key]
+	if !ok {
+		f = &flow{}
+		m.flows[key] = f
+	}
+	return f
+}
+
+// trimFlow drops cancels and fills that have left the window (now-Window, now]
+func (m *Monitor) trimFlow(f *flow, now time.Time) {
+	cutoff := now.Add(-m.config.Window)
+	f.cancels = trimEvents(f.cancels, cutoff)
+	f.fills = trimEvents(f.fills, cutoff)
+}
+
+func trimEvents(events []flowEvent, cutoff time.Time) []flowEvent {
+	i := 0
+	for i < len(events) && !events[i].at.After(cutoff) {
+		i++
+	}
+	if i == 0 {
+		return events
+	}
+	return append(events[:0:0], events[i:]...)
+}
+
+// prune forgets closed orders, trades still waiting for their orders and
+// activity that have left the window. It runs at most every tenth of a window.
+func (m *Monitor) prune(now time.Time) {
+	if now.Sub(m.lastPrune) < m.config.Window/10 {
+		return
+	}
+	m.lastPrune = now
+	cutoff := now.Add(-m.config.Window)
+
+	for id, o := range m.orders {
+		if (o.closed && o.updatedAt.Before(cutoff)) || o.updatedAt.Before(now.Add(-idleOrderRetention)) {
+			delete(m.orders, id)
+		}
+	}
+	for id, trades := range m.pending {
+		kept := trades[:0]
+		for _, t := range trades {
+			if !t.Time.Before(cutoff) {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) == 0 {
+			delete(m.pending, id)
+		} else {
+			m.pending[id] = kept
+		}
+	}
+	for key, f := range m.flows {
+		m.trimFlow(f, now)
+		if len(f.cancels) == 0 && len(f.fills) == 0 && now.Sub(f.burst.last) > m.config.IgnitionWindow {
+			delete(m.flows, key)
+		}
+	}
+}
+
+func copyAlert(a *Alert) *Alert {
+	copied := *a
+	copied.Evidence = append([]string(nil), a.Evidence...)
+	return &copied
+}
+
+func opposite(side string) string {
+	if side == SideBuy {
+		return SideSell
+	}
+	return SideBuy
+}
+
+func pastTense(side string) string {
+	if side == SideBuy {
+		return "bought"
+	}
+	return "sold"
+}
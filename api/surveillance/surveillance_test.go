@@ -0,0 +1,187 @@
+package surveillance
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+// place records a new resting order
+func place(m *Monitor, id, trader, side string, price float64, at time.Time) {
+	m.ObserveOrder(Order{OrderID: id, Trader: trader, MarketID: "BTC-USDC", Side: side, Price: price, Time: at})
+}
+
+// trade records a trade followed by the taker's order update, the order the
+// engine logs them in
+func trade(m *Monitor, id, takerOrder, taker, makerOrder, side string, price float64, at time.Time) {
+	m.ObserveTrade(Trade{TradeID: id, MarketID: "BTC-USDC", TakerOrderID: takerOrder, MakerOrderID: makerOrder, TakerSide: side, Price: price, Time: at})
+	m.ObserveOrder(Order{OrderID: takerOrder, Trader: taker, MarketID: "BTC-USDC", Side: side, FilledQty: 1, Closed: true, Time: at})
+}
+
+// TestWashTrades tests that self-trades and trades between accounts sharing
+// an API key are flagged, and unrelated trades are not
+func TestWashTrades(t *testing.T) {
+	m := NewMonitor(Config{})
+	m.now = func() time.Time { return t0.Add(time.Minute) }
+
+	place(m, "m1", "alice", SideSell, 100, t0)
+	trade(m, "t1", "o1", "alice", "m1", SideBuy, 100, t0)
+
+	m.ObserveCredential("key-1", "frank")
+	m.ObserveCredential("key-1", "gina")
+	m.ObserveCredential("key-2", "henry")
+	place(m, "m2", "gina", SideSell, 100, t0)
+	trade(m, "t2", "o2", "frank", "m2", SideBuy, 100, t0)
+	place(m, "m3", "henry", SideSell, 100, t0)
+	trade(m, "t3", "o3", "frank", "m3", SideBuy, 100, t0)
+
+	alerts := m.Alerts(AlertFilter{Kind: AlertWashTrade})
+	if len(alerts) != 2 {
+		t.Fatalf("expected 2 wash trade alerts, got %+v", alerts)
+	}
+	if a := alerts[1]; a.Trader != "alice" || a.Counterparty != "" || a.Evidence[0] != "t1" {
+		t.Errorf("unexpected self-trade alert %+v", a)
+	}
+	if a := alerts[0]; a.Trader != "frank" || a.Counterparty != "gina" || a.Evidence[0] != "t2" {
+		t.Errorf("unexpected shared key alert %+v", a)
+	}
+
+	// Repeats fold into the open alert
+	place(m, "m4", "alice", SideSell, 100, t0)
+	trade(m, "t4", "o4", "alice", "m4", SideBuy, 100, t0)
+	if alerts := m.Alerts(AlertFilter{Trader: "alice"}); len(alerts) != 1 || alerts[0].Occurrences != 2 {
+		t.Errorf("expected one alert with 2 occurrences, got %+v", alerts)
+	}
+
+	score := m.Score("gina")
+	if score.WashTrades != 1 || score.Score != alertWeights[AlertWashTrade] || len(score.LinkedAccounts) != 1 || score.LinkedAccounts[0] != "frank" {
+		t.Errorf("unexpected score %+v", score)
+	}
+}
+
+// TestSpoofing tests that near-touch orders cancelled unfilled trip the
+// cancel-to-fill ratio, and orders away from the touch do not count
+func TestSpoofing(t *testing.T) {
+	m := NewMonitor(Config{SpoofMinCancels: 3, SpoofCancelRatio: 2})
+	place(m, "m0", "alice", SideSell, 100, t0)
+	trade(m, "t0", "o0", "bob", "m0", SideBuy, 100, t0)
+
+	at := t0
+	cancel := func(id string, price float64) {
+		at = at.Add(time.Second)
+		place(m, id, "carol", SideBuy, price, at)
+		m.ObserveOrder(Order{OrderID: id, Trader: "carol", MarketID: "BTC-USDC", Side: SideBuy, Price: price, Cancelled: true, Closed: true, Time: at})
+	}
+	cancel("far", 95)
+	cancel("c1", 99.95)
+	cancel("c2", 100.05)
+	if alerts := m.Alerts(AlertFilter{Kind: AlertSpoofing}); len(alerts) != 0 {
+		t.Fatalf("expected no alert below the minimum cancels, got %+v", alerts)
+	}
+	cancel("c3", 100)
+
+	alerts := m.Alerts(AlertFilter{Kind: AlertSpoofing})
+	if len(alerts) != 1 || alerts[0].Trader != "carol" || len(alerts[0].Evidence) != 3 {
+		t.Fatalf("expected a spoofing alert over the 3 near-touch cancels, got %+v", alerts)
+	}
+
+	// The count starts afresh after an alert
+	cancel("c4", 100)
+	if alerts := m.Alerts(AlertFilter{Kind: AlertSpoofing}); alerts[0].Occurrences != 1 {
+		t.Errorf("expected the cancel count reset, got %+v", alerts[0])
+	}
+}
+
+// TestMomentumIgnition tests that an aggressive burst that moves the price,
+// followed by a trade the other way, is flagged
+func TestMomentumIgnition(t *testing.T) {
+	m := NewMonitor(Config{IgnitionMinTrades: 3, IgnitionMoveBps: 50})
+	for i, price := range []float64{100, 100.3, 100.6} {
+		at := t0.Add(time.Duration(i) * time.Second)
+		maker := fmt.Sprintf("m%d", i)
+		place(m, maker, "erin", SideSell, price, at)
+		trade(m, fmt.Sprintf("t%d", i), fmt.Sprintf("o%d", i), "dave", maker, SideBuy, price, at)
+	}
+	if alerts := m.Alerts(AlertFilter{Kind: AlertMomentumIgnition}); len(alerts) != 0 {
+		t.Fatalf("expected no alert before the reversal, got %+v", alerts)
+	}
+
+	// dave's resting sell is lifted after the move
+	at := t0.Add(5 * time.Second)
+	place(m, "sell", "dave", SideSell, 100.6, at)
+	trade(m, "t3", "o3", "erin", "sell", SideBuy, 100.6, at)
+
+	alerts := m.Alerts(AlertFilter{Kind: AlertMomentumIgnition})
+	if len(alerts) != 1 || alerts[0].Trader != "dave" || len(alerts[0].Evidence) != 4 {
+		t.Fatalf("expected a momentum ignition alert, got %+v", alerts)
+	}
+
+	// A reversal after the ignition window is not flagged
+	for i, price := range []float64{100, 100.3, 100.6} {
+		at := t0.Add(time.Minute + time.Duration(i)*time.Second)
+		maker := fmt.Sprintf("n%d", i)
+		place(m, maker, "erin", SideSell, price, at)
+		trade(m, fmt.Sprintf("u%d", i), fmt.Sprintf("p%d", i), "ivan", maker, SideBuy, price, at)
+	}
+	place(m, "late", "ivan", SideSell, 100.6, t0.Add(2*time.Minute))
+	trade(m, "u3", "p3", "erin", "late", SideBuy, 100.6, t0.Add(2*time.Minute))
+	if alerts := m.Alerts(AlertFilter{Trader: "ivan"}); len(alerts) != 0 {
+		t.Errorf("expected no alert for a late reversal, got %+v", alerts)
+	}
+}
+
+// TestReview tests the review queue and its effect on scores
+func TestReview(t *testing.T) {
+	m := NewMonitor(Config{})
+	m.now = func() time.Time { return t0.Add(time.Hour) }
+	place(m, "m1", "alice", SideSell, 100, t0)
+	trade(m, "t1", "o1", "alice", "m1", SideBuy, 100, t0)
+	place(m, "m2", "bob", SideSell, 100, t0)
+	trade(m, "t2", "o2", "bob", "m2", SideBuy, 100, t0)
+	alice := m.Alerts(AlertFilter{Trader: "alice"})[0]
+	bob := m.Alerts(AlertFilter{Trader: "bob"})[0]
+
+	if _, err := m.Review(alice.ID, "maybe", ""); !errors.Is(err, ErrInvalidResolution) {
+		t.Fatalf("expected ErrInvalidResolution, got %v", err)
+	}
+	if _, err := m.Review("sv-404", StatusConfirmed, ""); !errors.Is(err, ErrAlertNotFound) {
+		t.Fatalf("expected ErrAlertNotFound, got %v", err)
+	}
+	reviewed, err := m.Review(alice.ID, StatusConfirmed, "same operator")
+	if err != nil {
+		t.Fatalf("failed to review: %v", err)
+	}
+	if reviewed.Status != StatusConfirmed || reviewed.ReviewNote != "same operator" || reviewed.ReviewedAt == 0 {
+		t.Errorf("unexpected reviewed alert %+v", reviewed)
+	}
+	if _, err := m.Review(alice.ID, StatusDismissed, ""); !errors.Is(err, ErrAlertReviewed) {
+		t.Errorf("expected ErrAlertReviewed, got %v", err)
+	}
+	if _, err := m.Review(bob.ID, StatusDismissed, ""); err != nil {
+		t.Fatalf("failed to review: %v", err)
+	}
+	if open := m.Alerts(AlertFilter{Status: StatusOpen}); len(open) != 0 {
+		t.Errorf("expected an empty queue, got %+v", open)
+	}
+
+	scores := m.Scores(0)
+	if len(scores) != 2 || scores[0].Trader != "alice" || scores[0].Score != 2*alertWeights[AlertWashTrade] || scores[1].Score != 0 {
+		t.Errorf("unexpected scores %+v %+v", scores[0], scores[1])
+	}
+
+	// A new occurrence after review opens a new alert
+	place(m, "m3", "alice", SideSell, 100, t0)
+	trade(m, "t3", "o3", "alice", "m3", SideBuy, 100, t0)
+	if open := m.Alerts(AlertFilter{Status: StatusOpen}); len(open) != 1 || open[0].ID == alice.ID {
+		t.Errorf("expected a new open alert, got %+v", open)
+	}
+
+	// Alerts outside the score window no longer count
+	m.now = func() time.Time { return t0.Add(DefaultScoreWindow + time.Second) }
+	if score := m.Score("alice"); score.Score != 0 {
+		t.Errorf("expected alerts to age out of the score, got %+v", score)
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/api/surveillance"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestSurveillanceWashTradeReview tests that a trade between accounts trading
+// with the same API key reaches the admin review queue and the scores
+func TestSurveillanceWashTradeReview(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	config.AdminToken = "admin-secret"
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	for _, order := range []struct{ trader, side string }{{"wash-a", "sell"}, {"wash-b", "buy"}} {
+		body := `{"market_id":"BTC-USDC","side":"` + order.side + `","type":"limit","price":"50000","quantity":"0.1"}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body))
+		req.Header.Set("X-Trader-Address", order.trader)
+		req.Header.Set("X-API-Key", "shared-key")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("failed to place order: %d %s", rec.Code, rec.Body)
+		}
+	}
+	page, err := s.orderService.(types.EventService).GetEvents(context.Background(), 0, MaxEventsLimit)
+	if err != nil {
+		t.Fatalf("failed to read events: %v", err)
+	}
+	for _, event := range page.Events {
+		s.publishEvent(event)
+	}
+
+	admin := func(method, target, body string, v interface{}) int {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(adminTokenHeader, config.AdminToken)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("%s %s: invalid JSON %q: %v", method, target, rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/surveillance/alerts", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without the admin token, got %d", rec.Code)
+	}
+
+	var queue struct {
+		Alerts []*surveillance.Alert `json:"alerts"`
+	}
+	if code := admin(http.MethodGet, "/v1/admin/surveillance/alerts?status=open", "", &queue); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(queue.Alerts) != 1 || queue.Alerts[0].Kind != surveillance.AlertWashTrade ||
+		queue.Alerts[0].Trader != "wash-b" || queue.Alerts[0].Counterparty != "wash-a" {
+		t.Fatalf("expected a wash trade alert between the linked accounts, got %+v", queue.Alerts)
+	}
+
+	var reviewed surveillance.Alert
+	target := "/v1/admin/surveillance/alerts/" + queue.Alerts[0].ID + "/review"
+	if code := admin(http.MethodPost, target, `{"resolution":"confirmed","note":"one desk"}`, &reviewed); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if reviewed.Status != surveillance.StatusConfirmed {
+		t.Errorf("expected the alert confirmed, got %+v", reviewed)
+	}
+	var errResp map[string]interface{}
+	if code := admin(http.MethodPost, target, `{"resolution":"dismissed"}`, &errResp); code != http.StatusConflict {
+		t.Errorf("expected 409 reviewing twice, got %d", code)
+	}
+	if code := admin(http.MethodGet, "/v1/admin/surveillance/alerts/sv-404", "", &errResp); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown alert, got %d", code)
+	}
+
+	var score surveillance.Score
+	if code := admin(http.MethodGet, "/v1/admin/surveillance/scores?trader=wash-a", "", &score); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if score.Score == 0 || score.WashTrades != 1 || len(score.LinkedAccounts) != 1 || score.LinkedAccounts[0] != "wash-b" {
+		t.Errorf("unexpected score %+v", score)
+	}
+}
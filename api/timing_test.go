@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openalpha/perp-dex/pkg/timing"
+)
+
+// TestOrderDebugTimings tests that a placed order reports every stage in
+// X-Debug-Timings when asked, and only when asked
+func TestOrderDebugTimings(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableRateLimit = true
+	s, err := NewServerWithRealService(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := s.handler()
+
+	place := func(debug bool) *httptest.ResponseRecorder {
+		t.Helper()
+		body := `{"market_id":"BTC-USDC","side":"buy","type":"limit","price":"49000","quantity":"0.1"}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body))
+		req.Header.Set("X-Trader-Address", "timing-trader")
+		if debug {
+			req.Header.Set(timing.Header, "1")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+		}
+		return rec
+	}
+
+	got := place(true).Header().Get(timing.Header)
+	stages := []string{timing.StageDecode, timing.StageValidate, timing.StageMargin, timing.StageMatch, timing.StagePersist, timing.StageEncode}
+	for _, stage := range stages {
+		if !strings.Contains(got, stage+";dur=") {
+			t.Errorf("expected stage %s in %q", stage, got)
+		}
+	}
+	if got := place(false).Header().Get(timing.Header); got != "" {
+		t.Errorf("expected no timings unless requested, got %q", got)
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// handleTradePnL handles GET /v1/accounts/{trader}/trades/{tradeId}/pnl: how
+// one fill changed the trader's balance and position
+func (s *Server) handleTradePnL(w http.ResponseWriter, r *http.Request, trader, tradeID string) {
+	explainer, ok := s.orderService.(types.TradePnLService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Trade PnL explanations require a keeper-backed service")
+		return
+	}
+	if trader == "" || tradeID == "" {
+		writeError(w, types.ErrCodeMissingField, "trader and trade ID are required")
+		return
+	}
+	explained, err := explainer.GetTradePnL(r.Context(), trader, tradeID)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, explained)
+}
+
+// tradePnLPath splits trades/{tradeId}/pnl into the trade ID
+func tradePnLPath(endpoint string) (string, bool) {
+	rest, ok := strings.CutPrefix(endpoint, "trades/")
+	if !ok {
+		return "", false
+	}
+	tradeID, ok := strings.CutSuffix(rest, "/pnl")
+	if !ok || strings.Contains(tradeID, "/") {
+		return "", false
+	}
+	return tradeID, true
+}
+
+// GetTradePnL explains a fill from the trade history, the fee ledger and the
+// funding payments settled on the position it traded against
+func (rs *RealService) GetTradePnL(ctx context.Context, trader, tradeID string) (*types.TradePnL, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	explained, err := rs.obKeeper.ExplainTrade(rs.sdkCtx, trader, tradeID)
+	if err != nil {
+		return nil, err
+	}
+	resp := convertTradePnL(explained)
+
+	funding := math.LegacyZeroDec()
+	if rs.perpKeeper != nil && !explained.Before.IsFlat() {
+		payments := rs.perpKeeper.GetFundingPaymentsBetween(rs.sdkCtx, trader, explained.Trade.MarketID,
+			explained.Before.OpenedAt, explained.Trade.Timestamp)
+		for _, p := range payments {
+			funding = funding.Add(p.Amount)
+		}
+		resp.FundingPayments = len(payments)
+	}
+	resp.FundingSinceEntry = funding.String()
+	resp.Summary = tradePnLSummary(explained, funding)
+	return resp, nil
+}
+
+func convertTradePnL(p *obtypes.TradePnL) *types.TradePnL {
+	resp := &types.TradePnL{
+		TradeID:        p.Trade.TradeID,
+		MarketID:       p.Trade.MarketID,
+		Trader:         p.Trader,
+		Role:           p.Role,
+		Side:           p.Side.String(),
+		Price:          p.Trade.Price.String(),
+		Quantity:       p.Trade.Quantity.String(),
+		Timestamp:      p.Trade.Timestamp.UnixMilli(),
+		Fee:            p.Fee.String(),
+		ClosedQuantity: p.ClosedQuantity.String(),
+		RealizedPnL:    p.RealizedPnL.String(),
+		BalanceChange:  p.BalanceChange().String(),
+		Before:         convertTradePosition(p.Before),
+		After:          convertTradePosition(p.After),
+		Fills:          p.Fills,
+	}
+	if entry := p.FeeEntry; entry != nil && entry.PaidInFeeToken() {
+		resp.FeeToken = &types.TradeFeeToken{
+			Denom:       entry.FeeDenom,
+			Amount:      entry.FeeTokenAmount.String(),
+			ReplacedFee: entry.ReplacedFee.String(),
+		}
+	}
+	return resp
+}
+
+func convertTradePosition(p obtypes.TradePosition) types.TradePosition {
+	pos := types.TradePosition{
+		Side:       tradePositionSide(p),
+		Size:       p.Size.Abs().String(),
+		EntryPrice: p.EntryPrice.String(),
+	}
+	if !p.OpenedAt.IsZero() {
+		pos.OpenedAt = p.OpenedAt.UnixMilli()
+	}
+	return pos
+}
+
+func tradePositionSide(p obtypes.TradePosition) string {
+	switch {
+	case p.Size.IsPositive():
+		return "long"
+	case p.Size.IsNegative():
+		return "short"
+	default:
+		return "flat"
+	}
+}
+
+// tradePnLSummary describes the fill in one sentence for support staff
+func tradePnLSummary(p *obtypes.TradePnL, funding math.LegacyDec) string {
+	verb := "Bought"
+	if p.Side == obtypes.SideSell {
+		verb = "Sold"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s at %s as %s", verb, p.Trade.Quantity, p.Trade.MarketID, p.Trade.Price, p.Role)
+	if p.ClosedQuantity.IsPositive() {
+		fmt.Fprintf(&b, ", closing %s against an average entry of %s for %s realized PnL",
+			p.ClosedQuantity, p.Before.EntryPrice, p.RealizedPnL)
+	}
+	switch {
+	case p.Fee.IsNegative():
+		fmt.Fprintf(&b, ", earning a %s rebate", p.Fee.Neg())
+	case p.FeeEntry != nil && p.FeeEntry.PaidInFeeToken():
+		fmt.Fprintf(&b, ", paying the fee in %s %s", p.FeeEntry.FeeTokenAmount, p.FeeEntry.FeeDenom)
+	default:
+		fmt.Fprintf(&b, ", paying a %s fee", p.Fee)
+	}
+	fmt.Fprintf(&b, "; balance change %s. Position %s %s -> %s %s",
+		p.BalanceChange(), tradePositionSide(p.Before), p.Before.Size.Abs(), tradePositionSide(p.After), p.After.Size.Abs())
+	if !funding.IsZero() {
+		fmt.Fprintf(&b, "; %s funding settled since entry", funding)
+	}
+	return b.String() + "."
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cosmossdk.io/log"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestTradePnLEndpoint tests that a reducing fill is explained over the
+// account route with its realized PnL and resulting position, and that an
+// unknown trade is a 404
+func TestTradePnLEndpoint(t *testing.T) {
+	ctx := context.Background()
+	rs, err := NewRealService(log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	place := func(trader, side, price, quantity string) *types.PlaceOrderResponse {
+		t.Helper()
+		resp, err := rs.PlaceOrder(ctx, &types.PlaceOrderRequest{
+			MarketID: "BTC-USDC", Trader: trader, Side: side, Type: "limit", Price: price, Quantity: quantity,
+		})
+		if err != nil {
+			t.Fatalf("failed to place order: %v", err)
+		}
+		return resp
+	}
+	place("pnl-maker", "sell", "50000", "2")
+	place("pnl-trader", "buy", "50000", "2")
+	place("pnl-maker", "buy", "51000", "1")
+	closing := place("pnl-trader", "sell", "51000", "1")
+	if closing.Match == nil || len(closing.Match.Trades) != 1 {
+		t.Fatalf("expected one closing trade, got %+v", closing.Match)
+	}
+	tradeID := closing.Match.Trades[0].TradeID
+
+	s := &Server{config: &Config{}, orderService: rs}
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.handleAccountLegacy(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/v1/accounts/pnl-trader/trades/" + tradeID + "/pnl")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body)
+	}
+	var got types.TradePnL
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Role != "taker" || got.RealizedPnL != "1000.000000000000000000" || got.ClosedQuantity != "1.000000000000000000" {
+		t.Errorf("expected 1000 realized on 1 closed as taker, got %+v", got)
+	}
+	if got.Before.Side != "long" || got.After.Side != "long" || got.After.Size != "1.000000000000000000" ||
+		got.After.EntryPrice != "50000.000000000000000000" || got.FundingSinceEntry != "0.000000000000000000" {
+		t.Errorf("expected long 2 -> long 1 at 50000, got %+v -> %+v", got.Before, got.After)
+	}
+	if got.Summary == "" {
+		t.Error("expected a summary")
+	}
+
+	if rec := get("/v1/accounts/pnl-maker/trades/trade-999/pnl"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown trade, got %d %s", rec.Code, rec.Body)
+	}
+}
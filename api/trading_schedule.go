@@ -0,0 +1,278 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// weekdayNames are the day names accepted in trading hours
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// parseMinuteOfDay parses "HH:MM" into minutes after midnight
+func parseMinuteOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatMinuteOfDay(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// toPerpSchedule converts an API trading schedule to the keeper representation
+func toPerpSchedule(s *types.TradingSchedule) (*perptypes.TradingSchedule, error) {
+	schedule := &perptypes.TradingSchedule{MarketID: s.MarketID}
+	for _, h := range s.TradingHours {
+		open, err := parseMinuteOfDay(h.Open)
+		if err != nil {
+			return nil, err
+		}
+		closeAt, err := parseMinuteOfDay(h.Close)
+		if err != nil {
+			return nil, err
+		}
+		hours := perptypes.TradingHours{Open: open, Close: closeAt}
+		for _, name := range h.Days {
+			day, ok := weekdayNames[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("invalid day %q, expected one of sun, mon, tue, wed, thu, fri, sat", name)
+			}
+			hours.Days = append(hours.Days, day)
+		}
+		schedule.TradingHours = append(schedule.TradingHours, hours)
+	}
+	for _, m := range s.Maintenance {
+		schedule.Maintenance = append(schedule.Maintenance, perptypes.MaintenanceWindow{
+			ID:     m.ID,
+			Start:  time.UnixMilli(m.Start).UTC(),
+			End:    time.UnixMilli(m.End).UTC(),
+			Reason: m.Reason,
+		})
+	}
+	return schedule, nil
+}
+
+// fromPerpSchedule converts a keeper trading schedule to the API response.
+// A nil schedule is reported as an empty one for the market.
+func fromPerpSchedule(marketID string, s *perptypes.TradingSchedule) *types.TradingSchedule {
+	result := &types.TradingSchedule{
+		MarketID:     marketID,
+		TradingHours: []*types.TradingHours{},
+		Maintenance:  []*types.MaintenanceWindow{},
+	}
+	if s == nil {
+		return result
+	}
+	for _, h := range s.TradingHours {
+		hours := &types.TradingHours{Open: formatMinuteOfDay(h.Open), Close: formatMinuteOfDay(h.Close)}
+		for _, day := range h.Days {
+			hours.Days = append(hours.Days, strings.ToLower(day.String()[:3]))
+		}
+		result.TradingHours = append(result.TradingHours, hours)
+	}
+	for i := range s.Maintenance {
+		result.Maintenance = append(result.Maintenance, fromPerpMaintenance(&s.Maintenance[i]))
+	}
+	if !s.UpdatedAt.IsZero() {
+		result.UpdatedAt = s.UpdatedAt.UnixMilli()
+	}
+	return result
+}
+
+func fromPerpMaintenance(m *perptypes.MaintenanceWindow) *types.MaintenanceWindow {
+	return &types.MaintenanceWindow{
+		ID:     m.ID,
+		Start:  m.Start.UnixMilli(),
+		End:    m.End.UnixMilli(),
+		Reason: m.Reason,
+	}
+}
+
+// fromPerpStatus converts a keeper trading status to the API response
+func fromPerpStatus(s *perptypes.TradingStatus) *types.TradingStatus {
+	status := &types.TradingStatus{
+		MarketID: s.MarketID,
+		Status:   types.TradingStatusOpen,
+		Reason:   s.Reason,
+		Message:  s.Message,
+	}
+	switch {
+	case s.Open:
+	case s.Reason == perptypes.TradingReasonMaintenance:
+		status.Status = types.TradingStatusMaintenance
+	default:
+		status.Status = types.TradingStatusClosed
+	}
+	if !s.Until.IsZero() {
+		status.Until = s.Until.UnixMilli()
+	}
+	if s.NextMaintenance != nil {
+		status.NextMaintenance = fromPerpMaintenance(s.NextMaintenance)
+	}
+	return status
+}
+
+// tradingScheduleStore keeps trading schedules in memory for services without
+// a perpetual keeper. Statuses are evaluated against the wall clock.
+type tradingScheduleStore struct {
+	mu        sync.RWMutex
+	schedules map[string]*perptypes.TradingSchedule
+}
+
+func newTradingScheduleStore() *tradingScheduleStore {
+	return &tradingScheduleStore{schedules: make(map[string]*perptypes.TradingSchedule)}
+}
+
+// GetTradingSchedule implements types.TradingScheduleService
+func (st *tradingScheduleStore) GetTradingSchedule(ctx context.Context, marketID string) (*types.TradingSchedule, error) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return fromPerpSchedule(marketID, st.schedules[marketID]), nil
+}
+
+// SetTradingSchedule implements types.TradingScheduleService
+func (st *tradingScheduleStore) SetTradingSchedule(ctx context.Context, req *types.TradingSchedule) (*types.TradingSchedule, error) {
+	schedule, err := toPerpSchedule(req)
+	if err != nil {
+		return nil, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error())
+	}
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	schedule.PruneMaintenance(now)
+	schedule.UpdatedAt = now
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if schedule.IsEmpty() {
+		delete(st.schedules, schedule.MarketID)
+		return fromPerpSchedule(schedule.MarketID, nil), nil
+	}
+	st.schedules[schedule.MarketID] = schedule
+	return fromPerpSchedule(schedule.MarketID, schedule), nil
+}
+
+// DeleteTradingSchedule implements types.TradingScheduleService
+func (st *tradingScheduleStore) DeleteTradingSchedule(ctx context.Context, marketID string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.schedules, marketID)
+	return nil
+}
+
+// GetTradingStatus implements types.TradingScheduleService
+func (st *tradingScheduleStore) GetTradingStatus(ctx context.Context, marketID string) (*types.TradingStatus, error) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	schedule, ok := st.schedules[marketID]
+	if !ok {
+		return &types.TradingStatus{MarketID: marketID, Status: types.TradingStatusOpen}, nil
+	}
+	return fromPerpStatus(schedule.StatusAt(time.Now())), nil
+}
+
+// tradingStatus returns a market's trading status for market responses,
+// or nil if it cannot be determined
+func (s *Server) tradingStatus(ctx context.Context, marketID string) *types.TradingStatus {
+	status, err := s.scheduleService.GetTradingStatus(ctx, marketID)
+	if err != nil {
+		return nil
+	}
+	return status
+}
+
+// handleMarketSchedule handles GET /v1/markets/{id}/schedule
+func (s *Server) handleMarketSchedule(w http.ResponseWriter, r *http.Request, marketID string) {
+	if s.getMockMarket(marketID) == nil {
+		writeError(w, types.ErrCodeMarketNotFound, "Market not found")
+		return
+	}
+
+	schedule, err := s.scheduleService.GetTradingSchedule(r.Context(), marketID)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeServiceUnavailable))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"schedule":       schedule,
+		"trading_status": s.tradingStatus(r.Context(), marketID),
+	})
+}
+
+// handleAdminMarket handles /v1/admin/markets/{id}/schedule (GET, PUT, DELETE)
+// and GET /v1/admin/markets/{id}/orders
+func (s *Server) handleAdminMarket(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, types.ErrCodeUnauthorized, "Admin access required")
+		return
+	}
+
+	marketID, endpoint, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v1/admin/markets/"), "/")
+	if endpoint != "schedule" && endpoint != "orders" {
+		writeError(w, types.ErrCodeNotFound, "Endpoint not found")
+		return
+	}
+	if s.getMockMarket(marketID) == nil {
+		writeError(w, types.ErrCodeMarketNotFound, "Market not found")
+		return
+	}
+	if endpoint == "orders" {
+		s.handleAdminMarketOrders(w, r, marketID)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.handleMarketSchedule(w, r, marketID)
+
+	case http.MethodPut:
+		var req types.TradingSchedule
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		req.MarketID = marketID
+		schedule, err := s.scheduleService.SetTradingSchedule(r.Context(), &req)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"schedule":       schedule,
+			"trading_status": s.tradingStatus(r.Context(), marketID),
+		})
+
+	case http.MethodDelete:
+		if err := s.scheduleService.DeleteTradingSchedule(r.Context(), marketID); err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"market_id": marketID,
+			"deleted":   true,
+		})
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
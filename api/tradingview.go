@@ -0,0 +1,283 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/api/types"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TradingView UDF datafeed (https://www.tradingview.com/charting-library-docs/latest/connecting_data/UDF)
+// served from the same klines as /v1/markets/{id}/klines, so the charting
+// library's UDF adapter can point straight at /v1/tv.
+
+// tvExchange is the exchange name shown in the TradingView symbol header
+const tvExchange = "PerpDEX"
+
+// tvMaxBars caps the candles fetched for one history request
+const tvMaxBars = 5000
+
+// tvResolutions maps TradingView resolutions to kline intervals, in the
+// order advertised by /v1/tv/config
+var tvResolutions = []struct {
+	Resolution string
+	Interval   string
+	Duration   time.Duration
+}{
+	{"1", "1m", time.Minute},
+	{"5", "5m", 5 * time.Minute},
+	{"15", "15m", 15 * time.Minute},
+	{"30", "30m", 30 * time.Minute},
+	{"60", "1h", time.Hour},
+	{"240", "4h", 4 * time.Hour},
+	{"1D", "1d", 24 * time.Hour},
+}
+
+// tvCachePolicies are the public listener cache lifetimes of the UDF endpoints
+var tvCachePolicies = map[string]publicCachePolicy{
+	"config":  {MaxAge: 5 * time.Minute, SMaxAge: 5 * time.Minute},
+	"symbols": {MaxAge: time.Minute, SMaxAge: 5 * time.Minute},
+	"history": publicCachePolicies["klines"],
+}
+
+// klineProvider supplies the latest limit candles of a market, oldest first
+type klineProvider interface {
+	GetKlines(marketID, interval string, limit int) ([]KlineData, error)
+}
+
+// klineSource returns the candles behind the UDF datafeed, falling back to
+// the oracle when none is wired
+func (s *Server) klineSource() klineProvider {
+	if s.klines != nil {
+		return s.klines
+	}
+	if s.oracle != nil {
+		return s.oracle
+	}
+	return nil
+}
+
+// supportedTVResolutions lists the resolutions the datafeed serves
+func supportedTVResolutions() []string {
+	resolutions := make([]string, len(tvResolutions))
+	for i, r := range tvResolutions {
+		resolutions[i] = r.Resolution
+	}
+	return resolutions
+}
+
+// parseTVResolution resolves a TradingView resolution to a kline interval.
+// "D" and "1440" are accepted as aliases of "1D".
+func parseTVResolution(resolution string) (string, time.Duration, bool) {
+	switch resolution {
+	case "D", "1440":
+		resolution = "1D"
+	}
+	for _, r := range tvResolutions {
+		if r.Resolution == resolution {
+			return r.Interval, r.Duration, true
+		}
+	}
+	return "", 0, false
+}
+
+// tvMarketConfig looks up a UDF symbol, which may carry an exchange prefix
+// such as "PerpDEX:BTC-USDC"
+func tvMarketConfig(symbol string) (perptypes.MarketConfig, bool) {
+	if i := strings.LastIndexByte(symbol, ':'); i >= 0 {
+		symbol = symbol[i+1:]
+	}
+	cfg, ok := perptypes.DefaultMarketConfigs()[strings.ToUpper(symbol)]
+	return cfg, ok
+}
+
+// decimalPlaces returns the number of significant fractional digits of d
+func decimalPlaces(d sdkmath.LegacyDec) int {
+	str := strings.TrimRight(d.String(), "0")
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		return len(str) - i - 1
+	}
+	return 0
+}
+
+// handleTVConfig handles GET /v1/tv/config
+func (s *Server) handleTVConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &types.TVConfig{
+		SupportedResolutions: supportedTVResolutions(),
+		Exchanges: []types.TVExchange{
+			{Value: tvExchange, Name: tvExchange, Desc: "PerpDEX perpetual futures"},
+		},
+		SymbolsTypes: []types.TVSymbolType{
+			{Name: "Perpetual", Value: "crypto"},
+		},
+	})
+}
+
+// handleTVSymbols handles GET /v1/tv/symbols?symbol=BTC-USDC
+func (s *Server) handleTVSymbols(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		writeTVError(w, http.StatusBadRequest, "symbol is required")
+		return
+	}
+	cfg, ok := tvMarketConfig(symbol)
+	if !ok {
+		writeTVError(w, http.StatusNotFound, "unknown_symbol")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &types.TVSymbolInfo{
+		Name:                 cfg.MarketID,
+		Ticker:               cfg.MarketID,
+		Description:          fmt.Sprintf("%s / %s Perpetual", cfg.BaseAsset, cfg.QuoteAsset),
+		Type:                 "crypto",
+		Session:              "24x7",
+		Timezone:             "Etc/UTC",
+		Exchange:             tvExchange,
+		ListedExchange:       tvExchange,
+		Format:               "price",
+		MinMov:               1,
+		PriceScale:           int64(math.Pow10(decimalPlaces(cfg.TickSize))),
+		HasIntraday:          true,
+		HasDaily:             true,
+		VolumePrecision:      decimalPlaces(cfg.LotSize),
+		SupportedResolutions: supportedTVResolutions(),
+		DataStatus:           "streaming",
+	})
+}
+
+// handleTVHistory handles GET /v1/tv/history
+// Query params: symbol, resolution, from, to (Unix seconds, to exclusive),
+// countback (optional: return this many bars before to, ignoring from)
+func (s *Server) handleTVHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	cfg, ok := tvMarketConfig(query.Get("symbol"))
+	if !ok {
+		writeTVError(w, http.StatusNotFound, "unknown_symbol")
+		return
+	}
+	interval, duration, ok := parseTVResolution(query.Get("resolution"))
+	if !ok {
+		writeTVError(w, http.StatusBadRequest, "unsupported resolution")
+		return
+	}
+	from, err := strconv.ParseInt(query.Get("from"), 10, 64)
+	if err != nil {
+		writeTVError(w, http.StatusBadRequest, "from must be a Unix timestamp in seconds")
+		return
+	}
+	to, err := strconv.ParseInt(query.Get("to"), 10, 64)
+	if err != nil || to < from {
+		writeTVError(w, http.StatusBadRequest, "to must be a Unix timestamp in seconds not before from")
+		return
+	}
+	countback := 0
+	if c := query.Get("countback"); c != "" {
+		if countback, err = strconv.Atoi(c); err != nil || countback < 0 {
+			writeTVError(w, http.StatusBadRequest, "countback must be a non-negative integer")
+			return
+		}
+	}
+
+	source := s.klineSource()
+	if source == nil {
+		writeTVError(w, http.StatusServiceUnavailable, "klines unavailable")
+		return
+	}
+
+	// Klines are fetched backwards from now, so reach far enough back to
+	// cover from (or countback bars before to)
+	step := int64(duration.Seconds())
+	start := from
+	if countback > 0 {
+		start = to - int64(countback)*step
+	}
+	limit := int((time.Now().Unix()-start)/step) + 1
+	if limit > tvMaxBars {
+		limit = tvMaxBars
+	}
+	if limit < 1 {
+		limit = 1
+	}
+
+	klines, err := source.GetKlines(cfg.MarketID, interval, limit)
+	if err != nil {
+		writeTVError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, tvHistory(klines, from, to, countback))
+}
+
+// tvHistory selects the bars in [from, to), or the last countback bars
+// before to when countback is set, and lays them out column-wise
+func tvHistory(klines []KlineData, from, to int64, countback int) *types.TVHistory {
+	sort.Slice(klines, func(i, j int) bool { return klines[i].Time < klines[j].Time })
+
+	end := sort.Search(len(klines), func(i int) bool { return klines[i].Time >= to })
+	begin := sort.Search(len(klines), func(i int) bool { return klines[i].Time >= from })
+	if countback > 0 {
+		begin = end - countback
+		if begin < 0 {
+			begin = 0
+		}
+	}
+	if begin > end {
+		begin = end
+	}
+
+	bars := klines[begin:end]
+	if len(bars) == 0 {
+		history := &types.TVHistory{Status: types.TVStatusNoData}
+		if begin > 0 {
+			history.NextTime = klines[begin-1].Time
+		}
+		return history
+	}
+
+	history := &types.TVHistory{
+		Status: types.TVStatusOK,
+		Time:   make([]int64, len(bars)),
+		Open:   make([]float64, len(bars)),
+		High:   make([]float64, len(bars)),
+		Low:    make([]float64, len(bars)),
+		Close:  make([]float64, len(bars)),
+		Volume: make([]float64, len(bars)),
+	}
+	for i, k := range bars {
+		history.Time[i] = k.Time
+		history.Open[i] = k.Open
+		history.High[i] = k.High
+		history.Low[i] = k.Low
+		history.Close[i] = k.Close
+		history.Volume[i] = k.Volume
+	}
+	return history
+}
+
+// writeTVError writes an error in the UDF shape the TradingView adapter
+// understands
+func writeTVError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, &types.TVHistory{Status: types.TVStatusError, ErrMsg: message})
+}
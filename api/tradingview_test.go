@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	"cosmossdk.io/store"
+	"cosmossdk.io/store/metrics"
+	storetypes "cosmossdk.io/store/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/openalpha/perp-dex/api/types"
+	perpkeeper "github.com/openalpha/perp-dex/x/perpetual/keeper"
+)
+
+// fakeKlines serves fixed candles regardless of interval and limit
+type fakeKlines []KlineData
+
+func (f fakeKlines) GetKlines(marketID, interval string, limit int) ([]KlineData, error) {
+	return append([]KlineData(nil), f...), nil
+}
+
+// TestTradingViewDatafeed tests the UDF config, symbol resolution and bar
+// selection by range and countback
+func TestTradingViewDatafeed(t *testing.T) {
+	s := &Server{klines: fakeKlines{
+		{Time: 3600, Open: 3, High: 4, Low: 2, Close: 3.5, Volume: 30},
+		{Time: 0, Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: 10},
+		{Time: 1800, Open: 2, High: 3, Low: 1, Close: 2.5, Volume: 20},
+	}}
+
+	get := func(handler http.HandlerFunc, target string, out interface{}) int {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("failed to decode %s: %v", target, err)
+		}
+		return rec.Code
+	}
+
+	var config types.TVConfig
+	if code := get(s.handleTVConfig, "/v1/tv/config", &config); code != http.StatusOK {
+		t.Fatalf("config: expected 200, got %d", code)
+	}
+	if len(config.SupportedResolutions) != len(tvResolutions) || config.SupportedResolutions[0] != "1" {
+		t.Errorf("unexpected resolutions %v", config.SupportedResolutions)
+	}
+
+	var symbol types.TVSymbolInfo
+	if code := get(s.handleTVSymbols, "/v1/tv/symbols?symbol=PerpDEX:btc-usdc", &symbol); code != http.StatusOK {
+		t.Fatalf("symbols: expected 200, got %d", code)
+	}
+	if symbol.Ticker != "BTC-USDC" || symbol.PriceScale != 10 || symbol.VolumePrecision != 4 {
+		t.Errorf("unexpected symbol info %+v", symbol)
+	}
+
+	var unknown types.TVHistory
+	if code := get(s.handleTVSymbols, "/v1/tv/symbols?symbol=DOGE-USDC", &unknown); code != http.StatusNotFound || unknown.Status != types.TVStatusError {
+		t.Errorf("expected unknown_symbol error, got %d %+v", code, unknown)
+	}
+
+	var history types.TVHistory
+	get(s.handleTVHistory, "/v1/tv/history?symbol=BTC-USDC&resolution=30&from=1800&to=3600", &history)
+	if history.Status != types.TVStatusOK || len(history.Time) != 1 || history.Time[0] != 1800 || history.Close[0] != 2.5 {
+		t.Errorf("expected the single bar in [1800, 3600), got %+v", history)
+	}
+
+	history = types.TVHistory{}
+	get(s.handleTVHistory, "/v1/tv/history?symbol=BTC-USDC&resolution=30&from=3000&to=3600&countback=2", &history)
+	if len(history.Time) != 2 || history.Time[0] != 0 || history.Time[1] != 1800 {
+		t.Errorf("expected the 2 bars before 3600, got %+v", history)
+	}
+
+	history = types.TVHistory{}
+	get(s.handleTVHistory, "/v1/tv/history?symbol=BTC-USDC&resolution=30&from=7200&to=9000", &history)
+	if history.Status != types.TVStatusNoData || history.NextTime != 3600 {
+		t.Errorf("expected no_data pointing at 3600, got %+v", history)
+	}
+
+	history = types.TVHistory{}
+	if code := get(s.handleTVHistory, "/v1/tv/history?symbol=BTC-USDC&resolution=7&from=0&to=60", &history); code != http.StatusBadRequest || history.Status != types.TVStatusError {
+		t.Errorf("expected unsupported resolution error, got %d %+v", code, history)
+	}
+}
+
+// TestTradingViewKeeperKlines tests that a keeper-backed server serves the
+// datafeed from the perpetual keeper's kline store
+func TestTradingViewKeeperKlines(t *testing.T) {
+	storeKey := storetypes.NewKVStoreKey("perpetual")
+	db := dbm.NewMemDB()
+	stateStore := store.NewCommitMultiStore(db, log.NewNopLogger(), metrics.NewNoOpMetrics())
+	stateStore.MountStoreWithDB(storeKey, storetypes.StoreTypeIAVL, db)
+	if err := stateStore.LoadLatestVersion(); err != nil {
+		t.Fatalf("failed to load store: %v", err)
+	}
+
+	now := time.Now().UTC()
+	ctx := sdk.NewContext(stateStore, cmtproto.Header{Time: now}, false, log.NewNopLogger())
+	pk := perpkeeper.NewKeeper(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), storeKey, nil, "", log.NewNopLogger())
+	pk.UpdateKline(ctx, "BTC-USDC", math.LegacyNewDec(97000), math.LegacyNewDec(2))
+	pk.UpdateKline(ctx, "BTC-USDC", math.LegacyNewDec(97100), math.LegacyNewDec(1))
+
+	if keeperKlines(NewRealServiceWithKeepers(nil, nil, ctx, log.NewNopLogger())) != nil {
+		t.Fatal("expected no kline source without a perpetual keeper")
+	}
+	s := &Server{klines: keeperKlines(NewRealServiceWithKeepers(nil, pk, ctx, log.NewNopLogger()))}
+	if s.klines == nil {
+		t.Fatal("expected the keeper-backed service to be the kline source")
+	}
+
+	minute := now.Truncate(time.Minute).Unix()
+	rec := httptest.NewRecorder()
+	target := fmt.Sprintf("/v1/tv/history?symbol=BTC-USDC&resolution=1&from=%d&to=%d", minute-3600, minute+60)
+	s.handleTVHistory(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	var history types.TVHistory
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil {
+		t.Fatalf("failed to decode history: %v", err)
+	}
+	if history.Status != types.TVStatusOK || len(history.Time) != 1 {
+		t.Fatalf("expected one keeper bar, got %+v", history)
+	}
+	if history.Time[0] != minute || history.Open[0] != 97000 || history.Close[0] != 97100 || history.Volume[0] != 3 {
+		t.Errorf("unexpected bar %+v", history)
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// Treasury history page sizes
+const (
+	DefaultTreasuryHistoryLimit = 100
+	MaxTreasuryHistoryLimit     = 1000
+)
+
+// treasuryService returns the treasury of a chain-backed service
+func (s *Server) treasuryService(w http.ResponseWriter, r *http.Request) (types.TreasuryService, bool) {
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return nil, false
+	}
+	treasury, ok := s.orderService.(types.TreasuryService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Treasury requires a chain-backed service")
+		return nil, false
+	}
+	return treasury, true
+}
+
+// handleTreasury handles GET /v1/treasury, the treasury balance and the fee
+// distribution parameters
+func (s *Server) handleTreasury(w http.ResponseWriter, r *http.Request) {
+	treasury, ok := s.treasuryService(w, r)
+	if !ok {
+		return
+	}
+	resp, err := treasury.GetTreasury(r.Context())
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleTreasuryHistory handles GET /v1/treasury/history?kind=&before=&limit=,
+// the treasury's fee credits and spends, newest first
+func (s *Server) handleTreasuryHistory(w http.ResponseWriter, r *http.Request) {
+	treasury, ok := s.treasuryService(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	kind := query.Get("kind")
+	if kind != "" && kind != "fees" && kind != "spend" {
+		writeError(w, types.ErrCodeInvalidRequest, "kind must be fees or spend")
+		return
+	}
+	var before uint64
+	if b := query.Get("before"); b != "" {
+		n, err := strconv.ParseUint(b, 10, 64)
+		if err != nil {
+			writeError(w, types.ErrCodeInvalidRequest, "before must be an entry sequence number")
+			return
+		}
+		before = n
+	}
+	limit := DefaultTreasuryHistoryLimit
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 || n > MaxTreasuryHistoryLimit {
+			writeError(w, types.ErrCodeInvalidRequest, "limit must be between 1 and "+strconv.Itoa(MaxTreasuryHistoryLimit))
+			return
+		}
+		limit = n
+	}
+
+	entries, err := treasury.GetTreasuryHistory(r.Context(), kind, before, limit)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": entries,
+	})
+}
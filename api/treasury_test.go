@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	"cosmossdk.io/store"
+	"cosmossdk.io/store/metrics"
+	storetypes "cosmossdk.io/store/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/openalpha/perp-dex/api/types"
+	treasurykeeper "github.com/openalpha/perp-dex/x/treasury/keeper"
+	treasurytypes "github.com/openalpha/perp-dex/x/treasury/types"
+)
+
+// nopMarginAccounts accepts treasury spends without a perpetual keeper
+type nopMarginAccounts struct{}
+
+func (nopMarginAccounts) Deposit(ctx context.Context, trader string, amount math.LegacyDec) error {
+	return nil
+}
+
+// TestTreasuryEndpoints tests the treasury balance and history endpoints of
+// a service with the treasury module attached
+func TestTreasuryEndpoints(t *testing.T) {
+	storeKey := storetypes.NewKVStoreKey(treasurytypes.StoreKey)
+	db := dbm.NewMemDB()
+	stateStore := store.NewCommitMultiStore(db, log.NewNopLogger(), metrics.NewNoOpMetrics())
+	stateStore.MountStoreWithDB(storeKey, storetypes.StoreTypeIAVL, db)
+	if err := stateStore.LoadLatestVersion(); err != nil {
+		t.Fatalf("failed to load store: %v", err)
+	}
+	ctx := sdk.NewContext(stateStore, cmtproto.Header{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, false, log.NewNopLogger())
+	tk := treasurykeeper.NewKeeper(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), storeKey, nopMarginAccounts{}, "", log.NewNopLogger())
+	if err := tk.CreditFees(ctx, "BTC-USDC", 19723, math.LegacyNewDec(90)); err != nil {
+		t.Fatalf("failed to credit fees: %v", err)
+	}
+	if _, err := tk.Spend(ctx, "grantee", math.LegacyNewDec(40), "bug bounty"); err != nil {
+		t.Fatalf("failed to spend: %v", err)
+	}
+
+	rs := NewRealServiceWithKeepers(nil, nil, ctx, log.NewNopLogger())
+	s := &Server{orderService: rs}
+	get := func(handler http.HandlerFunc, target string, v interface{}) int {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("GET %s: invalid JSON %q: %v", target, rec.Body.String(), err)
+		}
+		return rec.Code
+	}
+
+	var errResp map[string]interface{}
+	if code := get(s.handleTreasury, "/v1/treasury", &errResp); code != http.StatusNotImplemented {
+		t.Fatalf("expected 501 without a treasury keeper, got %d", code)
+	}
+	rs.SetTreasuryKeeper(tk)
+
+	var treasury types.Treasury
+	if code := get(s.handleTreasury, "/v1/treasury", &treasury); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if treasury.Balance != math.LegacyNewDec(50).String() || treasury.Params.RiverPool != treasurytypes.DefaultParams().RiverPool.String() {
+		t.Errorf("unexpected treasury %+v", treasury)
+	}
+
+	var history struct {
+		Entries []*types.TreasuryEntry `json:"entries"`
+	}
+	if code := get(s.handleTreasuryHistory, "/v1/treasury/history?kind=fees", &history); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(history.Entries) != 1 || history.Entries[0].Day != "2024-01-01" || history.Entries[0].MarketID != "BTC-USDC" {
+		t.Errorf("unexpected fee history %+v", history.Entries)
+	}
+	if code := get(s.handleTreasuryHistory, "/v1/treasury/history?kind=bogus", &errResp); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown kind, got %d", code)
+	}
+}
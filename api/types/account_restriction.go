@@ -0,0 +1,33 @@
+package types
+
+import "context"
+
+// AccountRestriction is a suspension or freeze of an account. A suspended
+// account may only cancel orders and reduce its positions; a frozen account
+// may only cancel orders, and cannot withdraw or transfer out.
+type AccountRestriction struct {
+	Trader     string `json:"trader"`
+	Level      string `json:"level"` // suspended or frozen
+	ReasonCode string `json:"reason_code"`
+	Reason     string `json:"reason,omitempty"`
+	Actor      string `json:"actor"`
+	CreatedAt  int64  `json:"created_at"`           // Unix ms
+	ExpiresAt  int64  `json:"expires_at,omitempty"` // Unix ms; zero until lifted
+}
+
+// AccountRestrictionRequest suspends or freezes an account. Reason codes are
+// compliance, sanctions, fraud, incident and other.
+type AccountRestrictionRequest struct {
+	Level      string `json:"level"`
+	ReasonCode string `json:"reason_code"`
+	Reason     string `json:"reason,omitempty"`
+	ExpiresAt  int64  `json:"expires_at,omitempty"` // Unix ms; zero until lifted
+}
+
+// AccountRestrictionService suspends, freezes and reinstates accounts
+type AccountRestrictionService interface {
+	ListAccountRestrictions(ctx context.Context) ([]*AccountRestriction, error)
+	GetAccountRestriction(ctx context.Context, trader string) (*AccountRestriction, error)
+	SetAccountRestriction(ctx context.Context, trader, actor string, req *AccountRestrictionRequest) (*AccountRestriction, error)
+	LiftAccountRestriction(ctx context.Context, trader, actor string) (*AccountRestriction, error)
+}
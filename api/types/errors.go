@@ -0,0 +1,527 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/openalpha/perp-dex/api/auth"
+	"github.com/openalpha/perp-dex/api/pretrade"
+	"github.com/openalpha/perp-dex/api/profiles"
+	"github.com/openalpha/perp-dex/api/surveillance"
+	"github.com/openalpha/perp-dex/api/webhook"
+	"github.com/openalpha/perp-dex/pkg/validation"
+	clearinghousetypes "github.com/openalpha/perp-dex/x/clearinghouse/types"
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	riverpooltypes "github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// RequestIDHeader is the header carrying the per-request correlation ID
+const RequestIDHeader = "X-Request-ID"
+
+// ErrorCode is a stable, machine-readable error identifier returned to clients
+type ErrorCode string
+
+// Generic error codes
+const (
+	ErrCodeInvalidRequest     ErrorCode = "invalid_request"
+	ErrCodeInvalidJSON        ErrorCode = "invalid_json"
+	ErrCodeMissingField       ErrorCode = "missing_field"
+	ErrCodeInvalidPath        ErrorCode = "invalid_path"
+	ErrCodeMethodNotAllowed   ErrorCode = "method_not_allowed"
+	ErrCodeNotFound           ErrorCode = "not_found"
+	ErrCodeUnauthenticated    ErrorCode = "unauthenticated"
+	ErrCodeUnauthorized       ErrorCode = "unauthorized"
+	ErrCodeRateLimitExceeded  ErrorCode = "rate_limit_exceeded"
+	ErrCodeOrderLimitExceeded ErrorCode = "order_limit_exceeded"
+	ErrCodeNotImplemented     ErrorCode = "not_implemented"
+	ErrCodeServiceUnavailable ErrorCode = "service_unavailable"
+	ErrCodeInternal           ErrorCode = "internal_error"
+)
+
+// Trading error codes
+const (
+	ErrCodeOrderNotFound       ErrorCode = "order_not_found"
+	ErrCodeOrderNotActive      ErrorCode = "order_not_active"
+	ErrCodeInvalidPrice        ErrorCode = "invalid_price"
+	ErrCodeInvalidQuantity     ErrorCode = "invalid_quantity"
+	ErrCodeInvalidSide         ErrorCode = "invalid_side"
+	ErrCodeInvalidOrderType    ErrorCode = "invalid_order_type"
+	ErrCodeInvalidLeverage     ErrorCode = "invalid_leverage"
+	ErrCodeInsufficientMargin  ErrorCode = "insufficient_margin"
+	ErrCodeInsufficientBalance ErrorCode = "insufficient_balance"
+	ErrCodeReduceOnlyViolation ErrorCode = "reduce_only_violation"
+	ErrCodePostOnlyWouldTake   ErrorCode = "post_only_would_take"
+	ErrCodeOrderNotFilled      ErrorCode = "order_not_filled"
+	ErrCodePositionLimit       ErrorCode = "position_limit_exceeded"
+	ErrCodeMarketNotFound      ErrorCode = "market_not_found"
+	ErrCodeMarketNotActive     ErrorCode = "market_not_active"
+	ErrCodeMarketMaintenance   ErrorCode = "market_maintenance"
+	ErrCodeMarketClosed        ErrorCode = "market_closed"
+	ErrCodeMarketWarmUp        ErrorCode = "market_warm_up"
+	ErrCodePositionNotFound    ErrorCode = "position_not_found"
+	ErrCodeAccountNotFound     ErrorCode = "account_not_found"
+	ErrCodePositionHealthy     ErrorCode = "position_healthy"
+	ErrCodeBatchTooLarge       ErrorCode = "batch_too_large"
+	ErrCodeMarginModeLocked    ErrorCode = "margin_mode_locked"
+	ErrCodeConditionalNotFound ErrorCode = "conditional_order_not_found"
+	ErrCodeInvalidTriggerPrice ErrorCode = "invalid_trigger_price"
+	ErrCodeWebhookNotFound     ErrorCode = "webhook_not_found"
+	ErrCodeInvalidWebhookURL   ErrorCode = "invalid_webhook_url"
+	ErrCodeInvalidWebhookEvent ErrorCode = "invalid_webhook_event"
+	ErrCodeWebhookLimit        ErrorCode = "webhook_limit_exceeded"
+	ErrCodeMMPTriggered        ErrorCode = "mmp_triggered"
+	ErrCodeExportNotFound      ErrorCode = "export_not_found"
+	ErrCodeExportInProgress    ErrorCode = "export_in_progress"
+	ErrCodeExportExpired       ErrorCode = "export_expired"
+	ErrCodeAddressNotAllowed   ErrorCode = "withdrawal_address_not_allowed"
+	ErrCodeNotCancellable      ErrorCode = "withdrawal_not_cancellable"
+	ErrCodeAlertNotFound       ErrorCode = "surveillance_alert_not_found"
+	ErrCodeAlertReviewed       ErrorCode = "surveillance_alert_reviewed"
+	ErrCodeMarketOrderLimit    ErrorCode = "market_order_limit"
+	ErrCodeTraderOrderLimit    ErrorCode = "trader_order_limit"
+	ErrCodeTradeNotFound       ErrorCode = "trade_not_found"
+	ErrCodeSpreadNotFound      ErrorCode = "spread_not_found"
+	ErrCodeInvalidSpread       ErrorCode = "invalid_spread"
+	ErrCodeSpreadExists        ErrorCode = "spread_exists"
+	ErrCodeSpreadLegUnpriced   ErrorCode = "spread_leg_unpriced"
+	ErrCodeAccountSuspended    ErrorCode = "account_suspended"
+	ErrCodeAccountFrozen       ErrorCode = "account_frozen"
+	ErrCodeRestrictionNotFound ErrorCode = "account_restriction_not_found"
+	ErrCodeReportNotFound      ErrorCode = "report_not_found"
+	ErrCodeInvalidProfile      ErrorCode = "invalid_profile"
+	ErrCodeProfileNotFound     ErrorCode = "profile_not_found"
+	ErrCodeNicknameTaken       ErrorCode = "nickname_taken"
+	ErrCodeProfileLocked       ErrorCode = "profile_locked"
+)
+
+// Pre-trade risk check error codes
+const (
+	ErrCodePreTradeSize           ErrorCode = "pretrade_size_exceeded"
+	ErrCodePreTradeNotional       ErrorCode = "pretrade_notional_exceeded"
+	ErrCodePriceCollar            ErrorCode = "price_collar_exceeded"
+	ErrCodeDuplicateOrder         ErrorCode = "duplicate_order"
+	ErrCodePreTradeLimitsNotFound ErrorCode = "pretrade_limits_not_found"
+)
+
+// Order validation error codes
+const (
+	ErrCodeInvalidDecimal     ErrorCode = "invalid_decimal"
+	ErrCodePrecisionExceeded  ErrorCode = "precision_exceeded"
+	ErrCodePriceNotOnTick     ErrorCode = "price_not_on_tick"
+	ErrCodeQuantityNotOnLot   ErrorCode = "quantity_not_on_lot"
+	ErrCodeQuantityTooSmall   ErrorCode = "quantity_below_min"
+	ErrCodeQuantityTooLarge   ErrorCode = "quantity_above_max"
+	ErrCodeNotionalTooSmall   ErrorCode = "notional_below_min"
+	ErrCodePriceDeviationHigh ErrorCode = "price_deviation_exceeded"
+)
+
+// RiverPool error codes
+const (
+	ErrCodePoolNotFound       ErrorCode = "pool_not_found"
+	ErrCodePoolNotActive      ErrorCode = "pool_not_active"
+	ErrCodePoolPaused         ErrorCode = "pool_paused"
+	ErrCodePoolFull           ErrorCode = "pool_full"
+	ErrCodeNotPoolOwner       ErrorCode = "not_pool_owner"
+	ErrCodeDepositTooSmall    ErrorCode = "deposit_too_small"
+	ErrCodeDepositTooLarge    ErrorCode = "deposit_too_large"
+	ErrCodeInsufficientShares ErrorCode = "insufficient_shares"
+	ErrCodeWithdrawalNotFound ErrorCode = "withdrawal_not_found"
+	ErrCodeWithdrawalLocked   ErrorCode = "withdrawal_locked"
+	ErrCodeWithdrawalNotReady ErrorCode = "withdrawal_not_ready"
+	ErrCodeInvalidInviteCode  ErrorCode = "invalid_invite_code"
+	ErrCodeInvalidPoolParams  ErrorCode = "invalid_pool_params"
+	ErrCodeMarketNotAllowed   ErrorCode = "market_not_allowed"
+)
+
+// Chain transaction error codes
+const (
+	ErrCodeTxSimulationFailed ErrorCode = "tx_simulation_failed"
+	ErrCodeInsufficientGas    ErrorCode = "insufficient_gas"
+	ErrCodeTxRejected         ErrorCode = "tx_rejected"
+	ErrCodeTxFailed           ErrorCode = "tx_failed"
+	ErrCodeTxNotFound         ErrorCode = "tx_not_found"
+)
+
+// WebSocket error codes
+const (
+	ErrCodeInvalidMessage    ErrorCode = "invalid_message"
+	ErrCodeUnknownAction     ErrorCode = "unknown_action"
+	ErrCodeInvalidChannel    ErrorCode = "invalid_channel"
+	ErrCodeSubscriptionLimit ErrorCode = "subscription_limit"
+	ErrCodeInvalidAuth       ErrorCode = "invalid_auth"
+	ErrCodeConnectionLimit   ErrorCode = "connection_limit"
+	ErrCodeInvalidToken      ErrorCode = "invalid_token"
+	ErrCodeSessionExpired    ErrorCode = "session_expired"
+	ErrCodeLicenseRequired   ErrorCode = "data_license_required"
+)
+
+// errorCodeStatus maps each error code to its HTTP status.
+// Codes missing from this table are treated as 400 Bad Request.
+var errorCodeStatus = map[ErrorCode]int{
+	ErrCodeMethodNotAllowed:   http.StatusMethodNotAllowed,
+	ErrCodeNotFound:           http.StatusNotFound,
+	ErrCodeUnauthenticated:    http.StatusUnauthorized,
+	ErrCodeUnauthorized:       http.StatusForbidden,
+	ErrCodeRateLimitExceeded:  http.StatusTooManyRequests,
+	ErrCodeOrderLimitExceeded: http.StatusTooManyRequests,
+	ErrCodeNotImplemented:     http.StatusNotImplemented,
+	ErrCodeServiceUnavailable: http.StatusServiceUnavailable,
+	ErrCodeInternal:           http.StatusInternalServerError,
+	ErrCodeConnectionLimit:    http.StatusTooManyRequests,
+	ErrCodeInvalidToken:       http.StatusUnauthorized,
+	ErrCodeSessionExpired:     http.StatusUnauthorized,
+	ErrCodeLicenseRequired:    http.StatusForbidden,
+
+	ErrCodeOrderNotFound:       http.StatusNotFound,
+	ErrCodeMarketNotFound:      http.StatusNotFound,
+	ErrCodePositionNotFound:    http.StatusNotFound,
+	ErrCodeAccountNotFound:     http.StatusNotFound,
+	ErrCodeConditionalNotFound: http.StatusNotFound,
+	ErrCodeOrderNotActive:      http.StatusConflict,
+	ErrCodeMarginModeLocked:    http.StatusConflict,
+	ErrCodeMarketMaintenance:   http.StatusConflict,
+	ErrCodeMarketClosed:        http.StatusConflict,
+	ErrCodeMarketWarmUp:        http.StatusConflict,
+	ErrCodeWebhookNotFound:     http.StatusNotFound,
+	ErrCodeWebhookLimit:        http.StatusConflict,
+	ErrCodeMMPTriggered:        http.StatusConflict,
+	ErrCodeExportNotFound:      http.StatusNotFound,
+	ErrCodeExportInProgress:    http.StatusConflict,
+	ErrCodeExportExpired:       http.StatusGone,
+	ErrCodeAddressNotAllowed:   http.StatusForbidden,
+	ErrCodeNotCancellable:      http.StatusConflict,
+	ErrCodeAlertNotFound:       http.StatusNotFound,
+	ErrCodeAlertReviewed:       http.StatusConflict,
+	ErrCodeMarketOrderLimit:    http.StatusConflict,
+	ErrCodeTraderOrderLimit:    http.StatusConflict,
+	ErrCodeTradeNotFound:       http.StatusNotFound,
+	ErrCodeSpreadNotFound:      http.StatusNotFound,
+	ErrCodeSpreadExists:        http.StatusConflict,
+	ErrCodeSpreadLegUnpriced:   http.StatusConflict,
+	ErrCodeAccountSuspended:    http.StatusForbidden,
+	ErrCodeAccountFrozen:       http.StatusForbidden,
+	ErrCodeRestrictionNotFound: http.StatusNotFound,
+	ErrCodeReportNotFound:      http.StatusNotFound,
+	ErrCodeProfileNotFound:     http.StatusNotFound,
+	ErrCodeNicknameTaken:       http.StatusConflict,
+	ErrCodeProfileLocked:       http.StatusForbidden,
+
+	ErrCodeDuplicateOrder:         http.StatusConflict,
+	ErrCodePreTradeLimitsNotFound: http.StatusNotFound,
+
+	ErrCodePoolNotFound:       http.StatusNotFound,
+	ErrCodeWithdrawalNotFound: http.StatusNotFound,
+	ErrCodeNotPoolOwner:       http.StatusForbidden,
+	ErrCodePoolNotActive:      http.StatusConflict,
+	ErrCodePoolPaused:         http.StatusConflict,
+	ErrCodePoolFull:           http.StatusConflict,
+	ErrCodeMarketNotAllowed:   http.StatusForbidden,
+
+	ErrCodeTxNotFound: http.StatusNotFound,
+}
+
+// HTTPStatus returns the HTTP status associated with the error code
+func (c ErrorCode) HTTPStatus() int {
+	if status, ok := errorCodeStatus[c]; ok {
+		return status
+	}
+	return http.StatusBadRequest
+}
+
+// APIError is the unified error envelope returned by REST handlers and WS error frames
+type APIError struct {
+	Code      ErrorCode              `json:"code"`
+	Message   string                 `json:"message"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+
+	// RejectReason is set on order placement and amendment rejections
+	RejectReason RejectReason `json:"reject_reason,omitempty"`
+}
+
+// NewAPIError creates a new APIError
+func NewAPIError(code ErrorCode, message string) *APIError {
+	return &APIError{Code: code, Message: message}
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+// WithDetail attaches a key/value detail to the error and returns it
+func (e *APIError) WithDetail(key string, value interface{}) *APIError {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+// ErrorResponse is the JSON body written for an APIError.
+// The legacy "error" field mirrors Code for clients that predate the envelope.
+type ErrorResponse struct {
+	Error string `json:"error"`
+	*APIError
+}
+
+// NewErrorResponse wraps an APIError in the response body
+func NewErrorResponse(apiErr *APIError) *ErrorResponse {
+	return &ErrorResponse{Error: string(apiErr.Code), APIError: apiErr}
+}
+
+// keeperErrorCodes maps module sentinel errors to API error codes.
+// Order matters: the first match wins.
+var keeperErrorCodes = []struct {
+	err  error
+	code ErrorCode
+}{
+	// session auth
+	{auth.ErrInvalidToken, ErrCodeInvalidToken},
+	{auth.ErrTokenExpired, ErrCodeSessionExpired},
+	{auth.ErrSessionTooOld, ErrCodeSessionExpired},
+	{auth.ErrInvalidAPIKey, ErrCodeUnauthenticated},
+	{auth.ErrInvalidSignature, ErrCodeUnauthenticated},
+	{auth.ErrLoginExpired, ErrCodeUnauthenticated},
+	{auth.ErrIntentExpired, ErrCodeUnauthenticated},
+	{auth.ErrIntentReplayed, ErrCodeUnauthenticated},
+
+	// account webhooks
+	{webhook.ErrInvalidURL, ErrCodeInvalidWebhookURL},
+	{webhook.ErrInvalidEvent, ErrCodeInvalidWebhookEvent},
+	{webhook.ErrSubscriptionNotFound, ErrCodeWebhookNotFound},
+	{webhook.ErrSubscriptionLimit, ErrCodeWebhookLimit},
+
+	// order flow surveillance
+	{surveillance.ErrAlertNotFound, ErrCodeAlertNotFound},
+	{surveillance.ErrAlertReviewed, ErrCodeAlertReviewed},
+	{surveillance.ErrInvalidResolution, ErrCodeInvalidRequest},
+
+	// pre-trade risk checks
+	{pretrade.ErrOrderSizeExceeded, ErrCodePreTradeSize},
+	{pretrade.ErrNotionalExceeded, ErrCodePreTradeNotional},
+	{pretrade.ErrPriceOutsideCollar, ErrCodePriceCollar},
+	{pretrade.ErrDuplicateOrder, ErrCodeDuplicateOrder},
+	{pretrade.ErrInvalidLimits, ErrCodeInvalidRequest},
+	{pretrade.ErrLimitsNotFound, ErrCodePreTradeLimitsNotFound},
+
+	// trader profiles
+	{profiles.ErrInvalidProfile, ErrCodeInvalidProfile},
+	{profiles.ErrProfileNotFound, ErrCodeProfileNotFound},
+	{profiles.ErrNicknameTaken, ErrCodeNicknameTaken},
+	{profiles.ErrProfileLocked, ErrCodeProfileLocked},
+
+	// order validation
+	{validation.ErrInvalidDecimal, ErrCodeInvalidDecimal},
+	{validation.ErrPrecisionExceeded, ErrCodePrecisionExceeded},
+	{validation.ErrPriceNotOnTick, ErrCodePriceNotOnTick},
+	{validation.ErrQuantityNotOnLot, ErrCodeQuantityNotOnLot},
+	{validation.ErrQuantityTooSmall, ErrCodeQuantityTooSmall},
+	{validation.ErrQuantityTooLarge, ErrCodeQuantityTooLarge},
+	{validation.ErrNotionalTooSmall, ErrCodeNotionalTooSmall},
+	{validation.ErrPriceDeviationHigh, ErrCodePriceDeviationHigh},
+
+	// orderbook
+	{orderbooktypes.ErrOrderNotFound, ErrCodeOrderNotFound},
+	{orderbooktypes.ErrInvalidPrice, ErrCodeInvalidPrice},
+	{orderbooktypes.ErrInvalidQuantity, ErrCodeInvalidQuantity},
+	{orderbooktypes.ErrInvalidSide, ErrCodeInvalidSide},
+	{orderbooktypes.ErrInvalidOrderType, ErrCodeInvalidOrderType},
+	{orderbooktypes.ErrInvalidMarketID, ErrCodeMarketNotFound},
+	{orderbooktypes.ErrUnauthorized, ErrCodeUnauthorized},
+	{orderbooktypes.ErrOrderAlreadyFilled, ErrCodeOrderNotActive},
+	{orderbooktypes.ErrOrderAlreadyCancelled, ErrCodeOrderNotActive},
+	{orderbooktypes.ErrOrderNotActive, ErrCodeOrderNotActive},
+	{orderbooktypes.ErrInsufficientMargin, ErrCodeInsufficientMargin},
+	{orderbooktypes.ErrInvalidTriggerPrice, ErrCodeInvalidTriggerPrice},
+	{orderbooktypes.ErrConditionalOrderNotFound, ErrCodeConditionalNotFound},
+	{orderbooktypes.ErrFOKNotFilled, ErrCodeOrderNotFilled},
+	{orderbooktypes.ErrIOCNoFill, ErrCodeOrderNotFilled},
+	{orderbooktypes.ErrPostOnlyWouldTake, ErrCodePostOnlyWouldTake},
+	{orderbooktypes.ErrWarmUpPostOnly, ErrCodeMarketWarmUp},
+	{orderbooktypes.ErrReduceOnlyIncrease, ErrCodeReduceOnlyViolation},
+	{orderbooktypes.ErrOrderWouldExceedMax, ErrCodePositionLimit},
+	{orderbooktypes.ErrBatchTooLarge, ErrCodeBatchTooLarge},
+	{orderbooktypes.ErrInvalidMMPConfig, ErrCodeInvalidRequest},
+	{orderbooktypes.ErrMMPFrozen, ErrCodeMMPTriggered},
+	{orderbooktypes.ErrInvalidCursor, ErrCodeInvalidRequest},
+	{orderbooktypes.ErrInvalidExpiry, ErrCodeInvalidRequest},
+	{orderbooktypes.ErrInvalidSlippage, ErrCodeInvalidRequest},
+	{orderbooktypes.ErrInvalidMakerRebate, ErrCodeInvalidRequest},
+	{orderbooktypes.ErrMarketOrderLimit, ErrCodeMarketOrderLimit},
+	{orderbooktypes.ErrTraderOrderLimit, ErrCodeTraderOrderLimit},
+	{orderbooktypes.ErrInvalidOrderLimits, ErrCodeInvalidRequest},
+	{orderbooktypes.ErrTradeNotFound, ErrCodeTradeNotFound},
+	{orderbooktypes.ErrSpreadNotFound, ErrCodeSpreadNotFound},
+	{orderbooktypes.ErrInvalidSpread, ErrCodeInvalidSpread},
+	{orderbooktypes.ErrSpreadExists, ErrCodeSpreadExists},
+	{orderbooktypes.ErrSpreadLegUnpriced, ErrCodeSpreadLegUnpriced},
+	{orderbooktypes.ErrInvalidMarginRecheck, ErrCodeInvalidRequest},
+
+	// perpetual
+	{perpetualtypes.ErrInsufficientBalance, ErrCodeInsufficientBalance},
+	{perpetualtypes.ErrWithdrawExceedsBalance, ErrCodeInsufficientBalance},
+	{perpetualtypes.ErrInsufficientMargin, ErrCodeInsufficientMargin},
+	{perpetualtypes.ErrPositionNotFound, ErrCodePositionNotFound},
+	{perpetualtypes.ErrInvalidCursor, ErrCodeInvalidRequest},
+	{perpetualtypes.ErrMarketNotFound, ErrCodeMarketNotFound},
+	{perpetualtypes.ErrInvalidMarketID, ErrCodeMarketNotFound},
+	{perpetualtypes.ErrMarketNotActive, ErrCodeMarketNotActive},
+	{perpetualtypes.ErrMarketPaused, ErrCodeMarketNotActive},
+	{perpetualtypes.ErrMarketMaintenance, ErrCodeMarketMaintenance},
+	{perpetualtypes.ErrOutsideTradingHours, ErrCodeMarketClosed},
+	{perpetualtypes.ErrInvalidTradingSchedule, ErrCodeInvalidRequest},
+	{perpetualtypes.ErrInvalidWarmUp, ErrCodeInvalidRequest},
+	{perpetualtypes.ErrAccountNotFound, ErrCodeAccountNotFound},
+	{perpetualtypes.ErrInvalidQuantity, ErrCodeInvalidQuantity},
+	{perpetualtypes.ErrInvalidPrice, ErrCodeInvalidPrice},
+	{perpetualtypes.ErrInvalidLeverage, ErrCodeInvalidLeverage},
+	{perpetualtypes.ErrUnauthorized, ErrCodeUnauthorized},
+	{perpetualtypes.ErrCannotChangeMarginModeWithPositions, ErrCodeMarginModeLocked},
+	{perpetualtypes.ErrOrderSizeTooSmall, ErrCodeInvalidQuantity},
+	{perpetualtypes.ErrOrderSizeTooLarge, ErrCodeInvalidQuantity},
+	{perpetualtypes.ErrPositionSizeTooLarge, ErrCodePositionLimit},
+	{perpetualtypes.ErrInvalidWithdrawalSecurity, ErrCodeInvalidRequest},
+	{perpetualtypes.ErrInvalidRiskArray, ErrCodeInvalidRequest},
+	{perpetualtypes.ErrWithdrawalDestinationNotAllowed, ErrCodeAddressNotAllowed},
+	{perpetualtypes.ErrWithdrawalNotFound, ErrCodeWithdrawalNotFound},
+	{perpetualtypes.ErrWithdrawalNotCancellable, ErrCodeNotCancellable},
+	{perpetualtypes.ErrInvalidTransfer, ErrCodeInvalidRequest},
+	{perpetualtypes.ErrTransferExceedsFreeCollateral, ErrCodeInsufficientMargin},
+	{perpetualtypes.ErrAccountSuspended, ErrCodeAccountSuspended},
+	{perpetualtypes.ErrAccountFrozen, ErrCodeAccountFrozen},
+	{perpetualtypes.ErrInvalidAccountRestriction, ErrCodeInvalidRequest},
+	{perpetualtypes.ErrAccountRestrictionNotFound, ErrCodeRestrictionNotFound},
+
+	// clearinghouse
+	{clearinghousetypes.ErrPositionHealthy, ErrCodePositionHealthy},
+	{clearinghousetypes.ErrPositionNotFound, ErrCodePositionNotFound},
+
+	// riverpool
+	{riverpooltypes.ErrPoolNotFound, ErrCodePoolNotFound},
+	{riverpooltypes.ErrPoolNotActive, ErrCodePoolNotActive},
+	{riverpooltypes.ErrDDGuardHalt, ErrCodePoolPaused},
+	{riverpooltypes.ErrFoundationPoolFull, ErrCodePoolFull},
+	{riverpooltypes.ErrDepositTooSmall, ErrCodeDepositTooSmall},
+	{riverpooltypes.ErrDepositTooLarge, ErrCodeDepositTooLarge},
+	{riverpooltypes.ErrInsufficientShares, ErrCodeInsufficientShares},
+	{riverpooltypes.ErrWithdrawalNotFound, ErrCodeWithdrawalNotFound},
+	{riverpooltypes.ErrWithdrawalLocked, ErrCodeWithdrawalLocked},
+	{riverpooltypes.ErrWithdrawalNotReady, ErrCodeWithdrawalNotReady},
+	{riverpooltypes.ErrInvalidInviteCode, ErrCodeInvalidInviteCode},
+	{riverpooltypes.ErrNotPoolOwner, ErrCodeNotPoolOwner},
+	{riverpooltypes.ErrUnauthorized, ErrCodeUnauthorized},
+	{riverpooltypes.ErrOwnerStakeTooLow, ErrCodeInvalidPoolParams},
+	{riverpooltypes.ErrInvalidPoolName, ErrCodeInvalidPoolParams},
+	{riverpooltypes.ErrInvalidOwner, ErrCodeInvalidPoolParams},
+	{riverpooltypes.ErrInvalidMinDeposit, ErrCodeInvalidPoolParams},
+	{riverpooltypes.ErrInvalidOwnerStake, ErrCodeInvalidPoolParams},
+	{riverpooltypes.ErrInvalidManagementFee, ErrCodeInvalidPoolParams},
+	{riverpooltypes.ErrInvalidPerformanceFee, ErrCodeInvalidPoolParams},
+	{riverpooltypes.ErrInvalidRedemptionLimit, ErrCodeInvalidPoolParams},
+	{riverpooltypes.ErrInsufficientFreeCollateral, ErrCodeInsufficientMargin},
+	{riverpooltypes.ErrNotCommunityPool, ErrCodeInvalidRequest},
+	{riverpooltypes.ErrInvalidPoolOrder, ErrCodeInvalidRequest},
+	{riverpooltypes.ErrMarketNotAllowed, ErrCodeMarketNotAllowed},
+	{riverpooltypes.ErrPoolLeverageExceeded, ErrCodeInvalidLeverage},
+	{riverpooltypes.ErrInsufficientPoolCapital, ErrCodeInsufficientMargin},
+	{riverpooltypes.ErrPoolHolderLimit, ErrCodePoolFull},
+	{riverpooltypes.ErrInvalidMaxHolders, ErrCodeInvalidPoolParams},
+}
+
+// messageErrorCodes maps message fragments to API error codes for services that
+// return plain fmt.Errorf errors (mock and standalone services).
+// Order matters: more specific fragments come first.
+var messageErrorCodes = []struct {
+	fragment string
+	code     ErrorCode
+}{
+	{"not pool owner", ErrCodeNotPoolOwner},
+	{"unauthorized", ErrCodeUnauthorized},
+	{"pool not found", ErrCodePoolNotFound},
+	{"withdrawal not found", ErrCodeWithdrawalNotFound},
+	{"order not found", ErrCodeOrderNotFound},
+	{"trade not found", ErrCodeTradeNotFound},
+	{"position not found", ErrCodePositionNotFound},
+	{"account not found", ErrCodeAccountNotFound},
+	{"unknown market", ErrCodeMarketNotFound},
+	{"market not found", ErrCodeMarketNotFound},
+	{"pool is paused", ErrCodePoolPaused},
+	{"pool is not active", ErrCodePoolNotActive},
+	{"insufficient margin", ErrCodeInsufficientMargin},
+	{"insufficient shares", ErrCodeInsufficientShares},
+	{"insufficient", ErrCodeInsufficientBalance},
+	{"order is not active", ErrCodeOrderNotActive},
+	{"cannot be cancelled", ErrCodeOrderNotActive},
+	{"cannot be modified", ErrCodeOrderNotActive},
+	{"invalid price", ErrCodeInvalidPrice},
+	{"invalid quantity", ErrCodeInvalidQuantity},
+	{"invalid side", ErrCodeInvalidSide},
+	{"invalid type", ErrCodeInvalidOrderType},
+	{"not available in standalone mode", ErrCodeServiceUnavailable},
+	{"not implemented", ErrCodeNotImplemented},
+	{"not found", ErrCodeNotFound},
+}
+
+// ErrorCodeOf classifies an error into an API error code.
+// Returns fallback when the error is not recognised.
+func ErrorCodeOf(err error, fallback ErrorCode) ErrorCode {
+	if err == nil {
+		return fallback
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+
+	for _, m := range keeperErrorCodes {
+		if errors.Is(err, m.err) {
+			return m.code
+		}
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, m := range messageErrorCodes {
+		if strings.Contains(msg, m.fragment) {
+			return m.code
+		}
+	}
+
+	return fallback
+}
+
+// ToAPIError converts any error into an APIError, classifying keeper errors.
+// Validation rule errors carry the offending field and limit as details, and
+// trading halts carry the machine-readable reason and reopen time.
+// Unrecognised errors are reported with the fallback code.
+func ToAPIError(err error, fallback ErrorCode) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	result := NewAPIError(ErrorCodeOf(err, fallback), err.Error())
+
+	var haltErr *perpetualtypes.TradingHaltError
+	if errors.As(err, &haltErr) {
+		result.WithDetail("reason", haltErr.Reason)
+		if !haltErr.Until.IsZero() {
+			result.WithDetail("until", haltErr.Until.UnixMilli())
+		}
+	}
+
+	var ruleErr *validation.RuleError
+	if errors.As(err, &ruleErr) {
+		result.WithDetail("field", ruleErr.Field)
+		if ruleErr.Value != "" {
+			result.WithDetail("value", ruleErr.Value)
+		}
+		if ruleErr.Limit != "" {
+			result.WithDetail("limit", ruleErr.Limit)
+		}
+	}
+	return result
+}
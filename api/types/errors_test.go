@@ -0,0 +1,89 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	errorsmod "cosmossdk.io/errors"
+	"github.com/openalpha/perp-dex/api/pretrade"
+	"github.com/openalpha/perp-dex/api/webhook"
+	"github.com/openalpha/perp-dex/pkg/validation"
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	riverpooltypes "github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// TestErrorCodeOf tests classification of keeper and service errors
+func TestErrorCodeOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected ErrorCode
+	}{
+		{"orderbook sentinel", orderbooktypes.ErrOrderNotFound, ErrCodeOrderNotFound},
+		{"wrapped perpetual error", errorsmod.Wrap(perpetualtypes.ErrInsufficientMargin, "need 100"), ErrCodeInsufficientMargin},
+		{"fmt wrapped riverpool error", fmt.Errorf("deposit: %w", riverpooltypes.ErrDDGuardHalt), ErrCodePoolPaused},
+		{"service message", errors.New("pool not found: pool-1"), ErrCodePoolNotFound},
+		{"owner before unauthorized", errors.New("unauthorized: not pool owner"), ErrCodeNotPoolOwner},
+		{"wrapped validation rule", fmt.Errorf("failed to place order: %w", &validation.RuleError{Err: validation.ErrPriceNotOnTick, Field: "price"}), ErrCodePriceNotOnTick},
+		{"wrapped trading halt", fmt.Errorf("failed to place order: %w", &perpetualtypes.TradingHaltError{MarketID: "BTC-USDC", Reason: perpetualtypes.TradingReasonMaintenance}), ErrCodeMarketMaintenance},
+		{"riverpool free collateral", riverpooltypes.ErrInsufficientFreeCollateral, ErrCodeInsufficientMargin},
+		{"wrapped mmp freeze", fmt.Errorf("failed to place order: %w", fmt.Errorf("%w until 12:00", orderbooktypes.ErrMMPFrozen)), ErrCodeMMPTriggered},
+		{"wrapped trader order limit", fmt.Errorf("failed to place order: %w", fmt.Errorf("%w: trader has 5 resting orders", orderbooktypes.ErrTraderOrderLimit)), ErrCodeTraderOrderLimit},
+		{"trade not found", orderbooktypes.ErrTradeNotFound.Wrapf("trade-9 for trader %s", "alice"), ErrCodeTradeNotFound},
+		{"spread not found", orderbooktypes.ErrSpreadNotFound.Wrap("BTC-USDC:SOL-USDC"), ErrCodeSpreadNotFound},
+		{"pre-trade collar rule", &validation.RuleError{Err: pretrade.ErrPriceOutsideCollar, Field: "price"}, ErrCodePriceCollar},
+		{"wrapped webhook limit", fmt.Errorf("%w: at most 10 per account", webhook.ErrSubscriptionLimit), ErrCodeWebhookLimit},
+		{"api error passthrough", NewAPIError(ErrCodeMarketNotActive, "halted"), ErrCodeMarketNotActive},
+		{"unknown falls back", errors.New("boom"), ErrCodeInternal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ErrorCodeOf(tc.err, ErrCodeInternal); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestErrorCodeHTTPStatus tests the code to status registry
+func TestErrorCodeHTTPStatus(t *testing.T) {
+	testCases := []struct {
+		code     ErrorCode
+		expected int
+	}{
+		{ErrCodeOrderNotFound, http.StatusNotFound},
+		{ErrCodeUnauthenticated, http.StatusUnauthorized},
+		{ErrCodeNotPoolOwner, http.StatusForbidden},
+		{ErrCodeRateLimitExceeded, http.StatusTooManyRequests},
+		{ErrCodeInternal, http.StatusInternalServerError},
+		{ErrCodeInsufficientMargin, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.code.HTTPStatus(); got != tc.expected {
+			t.Errorf("%s: expected status %d, got %d", tc.code, tc.expected, got)
+		}
+	}
+}
+
+// TestToAPIErrorRuleDetails tests that validation failures expose field and limit
+func TestToAPIErrorRuleDetails(t *testing.T) {
+	err := fmt.Errorf("failed to place order: %w", &validation.RuleError{
+		Err:   validation.ErrQuantityNotOnLot,
+		Field: "quantity",
+		Value: "0.00015",
+		Limit: "0.0001",
+	})
+
+	apiErr := ToAPIError(err, ErrCodeInvalidRequest)
+	if apiErr.Code != ErrCodeQuantityNotOnLot {
+		t.Fatalf("expected %s, got %s", ErrCodeQuantityNotOnLot, apiErr.Code)
+	}
+	if apiErr.Details["field"] != "quantity" || apiErr.Details["limit"] != "0.0001" {
+		t.Errorf("unexpected details: %v", apiErr.Details)
+	}
+}
@@ -0,0 +1,49 @@
+package types
+
+import "context"
+
+// MakerRebateParams configures dynamic maker rebates. A filled maker order
+// scores in [0, 1]: tightness_weight of it from how far the quote closed the
+// spread when it came to rest, the rest from how long it rested, in full
+// after full_rest_ms. Quotes resting under min_rest_ms score nothing. The
+// rebate is notional × max_rebate_rate × score, taken off the maker fee and
+// capped at the fees the trade pays.
+type MakerRebateParams struct {
+	Enabled         bool   `json:"enabled"`
+	MaxRebateRate   string `json:"max_rebate_rate"`
+	TightnessWeight string `json:"tightness_weight"`
+	MinRestMs       int64  `json:"min_rest_ms"`
+	FullRestMs      int64  `json:"full_rest_ms"`
+	UpdatedAt       int64  `json:"updated_at,omitempty"`
+}
+
+// MakerRebateParamsRequest changes the maker rebate params; omitted fields
+// keep their current values
+type MakerRebateParamsRequest struct {
+	Enabled         *bool   `json:"enabled"`
+	MaxRebateRate   *string `json:"max_rebate_rate"`
+	TightnessWeight *string `json:"tightness_weight"`
+	MinRestMs       *int64  `json:"min_rest_ms"`
+	FullRestMs      *int64  `json:"full_rest_ms"`
+}
+
+// MakerPoints is a trader's maker points: the notional of each rebated fill
+// times its score, with the rebates paid
+type MakerPoints struct {
+	Trader    string `json:"trader"`
+	Points    string `json:"points"`
+	Rebates   string `json:"rebates"`
+	Fills     int64  `json:"fills"`
+	UpdatedAt int64  `json:"updated_at,omitempty"`
+
+	Profile *TraderProfile `json:"profile,omitempty"`
+}
+
+// MakerRebateService manages maker rebates and reports maker points
+type MakerRebateService interface {
+	GetMakerRebateParams(ctx context.Context) (*MakerRebateParams, error)
+	SetMakerRebateParams(ctx context.Context, req *MakerRebateParamsRequest) (*MakerRebateParams, error)
+	GetMakerPoints(ctx context.Context, trader string) (*MakerPoints, error)
+	// ListMakerPoints returns up to limit traders' points, most points first
+	ListMakerPoints(ctx context.Context, limit int) ([]*MakerPoints, error)
+}
@@ -0,0 +1,119 @@
+package types
+
+import "context"
+
+// FundingParams is a market's funding settlement config; rates are per
+// interval and the interval is in seconds. The divergence fields configure
+// the market's divergence guard; a zero threshold disables it.
+type FundingParams struct {
+	Interval      int64  `json:"interval"`
+	MaxRate       string `json:"max_rate"`
+	MinRate       string `json:"min_rate"`
+	DampingFactor string `json:"damping_factor"`
+	InterestRate  string `json:"interest_rate"`
+
+	DivergenceThreshold string `json:"divergence_threshold,omitempty"`
+	DivergenceIntervals int64  `json:"divergence_intervals,omitempty"`
+	GuardBandFactor     string `json:"guard_band_factor,omitempty"`
+	GuardDampening      string `json:"guard_dampening,omitempty"`
+}
+
+// WarmUpParams is the listing warm-up stage a new market is in: the order
+// limits in force until the stage ends at EndsAtHeight
+type WarmUpParams struct {
+	PostOnly          bool   `json:"post_only"`
+	MaxOrderSize      string `json:"max_order_size"`
+	MaxPriceDeviation string `json:"max_price_deviation"`
+	EndsAtHeight      int64  `json:"ends_at_height"`
+}
+
+// DivergenceGuardStatus is the state of a market whose mark has diverged
+// from its index: the settlements in a row beyond the threshold and, once
+// engaged, the tightened price band in force
+type DivergenceGuardStatus struct {
+	Engaged           bool   `json:"engaged"`
+	Divergence        string `json:"divergence"`
+	DivergedIntervals int64  `json:"diverged_intervals"`
+	EngagedAt         int64  `json:"engaged_at,omitempty"`
+	MaxPriceDeviation string `json:"max_price_deviation"`
+	MaxSlippage       string `json:"max_slippage"`
+}
+
+// MarketParams is a market's runtime-tunable parameters: fees, the price
+// band around the mark (limit price deviation and default market order
+// slippage) and funding. Funding is nil on services without funding,
+// WarmUp is nil once a market's listing warm-up is over and
+// DivergenceGuard is nil while the mark tracks the index.
+type MarketParams struct {
+	MarketID          string                 `json:"market_id"`
+	TakerFeeRate      string                 `json:"taker_fee_rate"`
+	MakerFeeRate      string                 `json:"maker_fee_rate"`
+	MaxPriceDeviation string                 `json:"max_price_deviation"`
+	MaxSlippage       string                 `json:"max_slippage"`
+	Funding           *FundingParams         `json:"funding,omitempty"`
+	WarmUp            *WarmUpParams          `json:"warm_up,omitempty"`
+	DivergenceGuard   *DivergenceGuardStatus `json:"divergence_guard,omitempty"`
+}
+
+// MarketParamsUpdate changes some of a market's parameters; empty fields
+// and a nil funding are left unchanged
+type MarketParamsUpdate struct {
+	TakerFeeRate      string         `json:"taker_fee_rate,omitempty"`
+	MakerFeeRate      string         `json:"maker_fee_rate,omitempty"`
+	MaxPriceDeviation string         `json:"max_price_deviation,omitempty"`
+	MaxSlippage       string         `json:"max_slippage,omitempty"`
+	Funding           *FundingParams `json:"funding,omitempty"`
+	Reason            string         `json:"reason,omitempty"`
+}
+
+// RateLimitParams is the API rate limits of a node
+type RateLimitParams struct {
+	IPRequestsPerSecond   int `json:"ip_requests_per_second"`
+	IPBurst               int `json:"ip_burst"`
+	UserRequestsPerSecond int `json:"user_requests_per_second"`
+	UserBurst             int `json:"user_burst"`
+	OrdersPerSecond       int `json:"orders_per_second"`
+	OrderBurst            int `json:"order_burst"`
+	OrdersPerDay          int `json:"orders_per_day"`
+}
+
+// RateLimitParamsUpdate changes some of the rate limits; nil fields are left
+// unchanged
+type RateLimitParamsUpdate struct {
+	IPRequestsPerSecond   *int   `json:"ip_requests_per_second,omitempty"`
+	IPBurst               *int   `json:"ip_burst,omitempty"`
+	UserRequestsPerSecond *int   `json:"user_requests_per_second,omitempty"`
+	UserBurst             *int   `json:"user_burst,omitempty"`
+	OrdersPerSecond       *int   `json:"orders_per_second,omitempty"`
+	OrderBurst            *int   `json:"order_burst,omitempty"`
+	OrdersPerDay          *int   `json:"orders_per_day,omitempty"`
+	Reason                string `json:"reason,omitempty"`
+}
+
+// ProtocolParams is every runtime-tunable parameter of a node
+type ProtocolParams struct {
+	Markets    []*MarketParams  `json:"markets,omitempty"`
+	RateLimits *RateLimitParams `json:"rate_limits"`
+	Editable   bool             `json:"editable"` // false on nodes that cannot change market params
+}
+
+// ParamChange is one audit log entry: a parameter, e.g.
+// markets.BTC-USDC.taker_fee_rate or rate_limits.orders_per_second, set from
+// Old to New by Actor
+type ParamChange struct {
+	ID         int64  `json:"id"`
+	Time       int64  `json:"time"` // Unix ms
+	Actor      string `json:"actor"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	Param      string `json:"param"`
+	Old        string `json:"old"`
+	New        string `json:"new"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// ProtocolParamsService reads and changes market parameters at runtime
+type ProtocolParamsService interface {
+	ListMarketParams(ctx context.Context) ([]*MarketParams, error)
+	// UpdateMarketParams applies update and returns the params before and after
+	UpdateMarketParams(ctx context.Context, marketID string, update *MarketParamsUpdate) (before, after *MarketParams, err error)
+}
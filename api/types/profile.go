@@ -0,0 +1,10 @@
+package types
+
+// TraderProfile is the public profile shown next to a trader's address in
+// leaderboards and community pool owner and holder lists. Only traders with
+// a profile moderators have not hidden get one; the bio is served by
+// GET /v1/profiles/{address}.
+type TraderProfile struct {
+	Nickname   string `json:"nickname"`
+	AvatarHash string `json:"avatar_hash,omitempty"`
+}
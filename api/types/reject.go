@@ -0,0 +1,101 @@
+package types
+
+// RejectReason groups the error codes an order can be rejected with into a
+// few operator-facing causes, so rejection spikes can be attributed at a
+// glance. The error code stays the precise, client-facing identifier.
+type RejectReason string
+
+// Order rejection reasons
+const (
+	RejectReasonMargin       RejectReason = "margin"        // not enough margin or balance
+	RejectReasonPriceBand    RejectReason = "price_band"    // price too far from the mark
+	RejectReasonRiskLimit    RejectReason = "risk_limit"    // position, resting order or MMP limits
+	RejectReasonMarketHalted RejectReason = "market_halted" // market paused, in maintenance or closed
+	RejectReasonValidation   RejectReason = "validation"    // malformed or off-rule order parameters
+	RejectReasonExecution    RejectReason = "execution"     // post-only, IOC or FOK conditions not met
+	RejectReasonRestricted   RejectReason = "restricted"    // account suspended or frozen
+	RejectReasonOther        RejectReason = "other"
+)
+
+// rejectReasons maps order error codes to their rejection reason.
+// Codes missing from this table are reported as RejectReasonOther.
+var rejectReasons = map[ErrorCode]RejectReason{
+	ErrCodeInsufficientMargin:  RejectReasonMargin,
+	ErrCodeInsufficientBalance: RejectReasonMargin,
+
+	ErrCodePriceDeviationHigh: RejectReasonPriceBand,
+	ErrCodePriceCollar:        RejectReasonPriceBand,
+
+	ErrCodePositionLimit:       RejectReasonRiskLimit,
+	ErrCodeReduceOnlyViolation: RejectReasonRiskLimit,
+	ErrCodeMMPTriggered:        RejectReasonRiskLimit,
+	ErrCodeMarketOrderLimit:    RejectReasonRiskLimit,
+	ErrCodeTraderOrderLimit:    RejectReasonRiskLimit,
+	ErrCodeInvalidLeverage:     RejectReasonRiskLimit,
+	ErrCodePreTradeSize:        RejectReasonRiskLimit,
+	ErrCodePreTradeNotional:    RejectReasonRiskLimit,
+	ErrCodeDuplicateOrder:      RejectReasonRiskLimit,
+
+	ErrCodeMarketNotActive:   RejectReasonMarketHalted,
+	ErrCodeMarketMaintenance: RejectReasonMarketHalted,
+	ErrCodeMarketClosed:      RejectReasonMarketHalted,
+
+	ErrCodeInvalidRequest:      RejectReasonValidation,
+	ErrCodeInvalidJSON:         RejectReasonValidation,
+	ErrCodeMissingField:        RejectReasonValidation,
+	ErrCodeInvalidPrice:        RejectReasonValidation,
+	ErrCodeInvalidQuantity:     RejectReasonValidation,
+	ErrCodeInvalidSide:         RejectReasonValidation,
+	ErrCodeInvalidOrderType:    RejectReasonValidation,
+	ErrCodeInvalidTriggerPrice: RejectReasonValidation,
+	ErrCodeMarketNotFound:      RejectReasonValidation,
+	ErrCodeInvalidDecimal:      RejectReasonValidation,
+	ErrCodePrecisionExceeded:   RejectReasonValidation,
+	ErrCodePriceNotOnTick:      RejectReasonValidation,
+	ErrCodeQuantityNotOnLot:    RejectReasonValidation,
+	ErrCodeQuantityTooSmall:    RejectReasonValidation,
+	ErrCodeQuantityTooLarge:    RejectReasonValidation,
+	ErrCodeNotionalTooSmall:    RejectReasonValidation,
+
+	ErrCodePostOnlyWouldTake: RejectReasonExecution,
+	ErrCodeOrderNotFilled:    RejectReasonExecution,
+	ErrCodeMarketWarmUp:      RejectReasonExecution,
+
+	ErrCodeAccountSuspended: RejectReasonRestricted,
+	ErrCodeAccountFrozen:    RejectReasonRestricted,
+}
+
+// RejectReasonOf returns the rejection reason for an order error code
+func RejectReasonOf(code ErrorCode) RejectReason {
+	if reason, ok := rejectReasons[code]; ok {
+		return reason
+	}
+	return RejectReasonOther
+}
+
+// OrderReject describes an order placement or amendment that was rejected.
+// It is pushed to the trader's orders WebSocket channel as an
+// "order_reject" message.
+type OrderReject struct {
+	Trader    string       `json:"trader"`
+	MarketID  string       `json:"market_id,omitempty"`
+	OrderID   string       `json:"order_id,omitempty"` // set when an amendment was rejected
+	Action    string       `json:"action"`             // "place" or "modify"
+	Code      ErrorCode    `json:"code"`
+	Reason    RejectReason `json:"reason"`
+	Message   string       `json:"message"`
+	RequestID string       `json:"request_id,omitempty"`
+	Timestamp int64        `json:"timestamp"`
+}
+
+// Order reject actions
+const (
+	RejectActionPlace  = "place"
+	RejectActionModify = "modify"
+)
+
+// OrderRejectObserver is told about every rejected order placement or
+// amendment. Observing must not block the request.
+type OrderRejectObserver interface {
+	ObserveOrderReject(reject *OrderReject)
+}
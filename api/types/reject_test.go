@@ -0,0 +1,27 @@
+package types
+
+import "testing"
+
+// TestRejectReasonOf tests that order error codes are grouped into their
+// reject reasons, with unlisted codes reported as other
+func TestRejectReasonOf(t *testing.T) {
+	testCases := []struct {
+		code ErrorCode
+		want RejectReason
+	}{
+		{ErrCodeInsufficientMargin, RejectReasonMargin},
+		{ErrCodePriceDeviationHigh, RejectReasonPriceBand},
+		{ErrCodeTraderOrderLimit, RejectReasonRiskLimit},
+		{ErrCodeDuplicateOrder, RejectReasonRiskLimit},
+		{ErrCodeMarketMaintenance, RejectReasonMarketHalted},
+		{ErrCodePriceNotOnTick, RejectReasonValidation},
+		{ErrCodePostOnlyWouldTake, RejectReasonExecution},
+		{ErrCodeAccountFrozen, RejectReasonRestricted},
+		{ErrCodeInternal, RejectReasonOther},
+	}
+	for _, tc := range testCases {
+		if got := RejectReasonOf(tc.code); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.code, tc.want, got)
+		}
+	}
+}
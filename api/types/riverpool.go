@@ -30,7 +30,7 @@ type RiverpoolService interface {
 	EstimateWithdrawal(poolID string, shares math.LegacyDec) (*WithdrawalEstimate, error)
 
 	// Transactions
-	Deposit(poolID, user string, amount math.LegacyDec) (*DepositResult, error)
+	Deposit(poolID, user string, amount math.LegacyDec, inviteCode string) (*DepositResult, error)
 	RequestWithdrawal(poolID, user string, shares math.LegacyDec) (*WithdrawalResult, error)
 	ClaimWithdrawal(withdrawalID, user string) (*ClaimResult, error)
 	CancelWithdrawal(withdrawalID, user string) error
@@ -48,6 +48,8 @@ type RiverpoolService interface {
 	GetPoolTrades(poolID string, limit int) ([]*PoolTradeInfo, error)
 	GetInviteCodes(poolID, owner string) ([]*InviteCode, error)
 	GenerateInviteCode(poolID, owner string) (*InviteCode, error)
+	RevokeInviteCode(poolID, owner, code string) error
+	GetInviteRedemptions(poolID, owner, code string) ([]*InviteRedemption, error)
 	PlacePoolOrder(poolID, owner, marketID, side string, size, price, leverage math.LegacyDec) (*PoolOrderResult, error)
 	ClosePoolPosition(poolID, owner, positionID string) (*PoolCloseResult, error)
 	PausePool(poolID, owner string) error
@@ -65,6 +67,7 @@ type PoolInfo struct {
 	Status              string `json:"status"` // "active", "paused", "closed"
 	TotalDeposits       string `json:"total_deposits"`
 	TotalShares         string `json:"total_shares"`
+	LPDenom             string `json:"lp_denom,omitempty"` // bank denom of the pool's share coins
 	NAV                 string `json:"nav"`
 	HighWaterMark       string `json:"high_water_mark"`
 	CurrentDrawdown     string `json:"current_drawdown"`
@@ -76,6 +79,12 @@ type PoolInfo struct {
 	DailyRedemptionLimit string `json:"daily_redemption_limit"`
 	SeatsAvailable      int64  `json:"seats_available,omitempty"`
 	Owner               string `json:"owner,omitempty"` // Community pool only
+	OwnerProfile        *TraderProfile `json:"owner_profile,omitempty"` // Community pool only, when the owner has a visible profile
+	AllowedMarkets      []string `json:"allowed_markets,omitempty"` // Community pool only, empty allows every market
+	MaxLeverage         string   `json:"max_leverage,omitempty"`    // Community pool only
+	IsPrivate           bool     `json:"is_private,omitempty"`      // Community pool only, deposits need an invite code
+	MaxHolders          int64    `json:"max_holders,omitempty"`     // Community pool only, 0 = unlimited
+	TotalHolders        int64    `json:"total_holders"`
 	CreatedAt           int64  `json:"created_at"`
 	UpdatedAt           int64  `json:"updated_at"`
 }
@@ -225,6 +234,9 @@ type CommunityPoolParams struct {
 	RedemptionDelay  int    `json:"redemption_delay_days"`
 	OwnerMinStake    string `json:"owner_min_stake"`   // e.g., "0.05" for 5%
 	IsPrivate        bool   `json:"is_private"`
+	MaxLeverage      string   `json:"max_leverage,omitempty"`    // e.g., "5"; defaults to 10
+	AllowedMarkets   []string `json:"allowed_markets,omitempty"` // empty allows every market
+	MaxHolders       int64    `json:"max_holders,omitempty"`     // 0 = unlimited
 }
 
 type HolderInfo struct {
@@ -233,6 +245,7 @@ type HolderInfo struct {
 	SharePercent   string `json:"share_percent"`
 	Value          string `json:"value"`
 	DepositedAt    int64  `json:"deposited_at"`
+	Profile        *TraderProfile `json:"profile,omitempty"`
 }
 
 type PositionInfo struct {
@@ -264,6 +277,17 @@ type InviteCode struct {
 	UsedCount  int    `json:"used_count"`
 	ExpiresAt  int64  `json:"expires_at"`
 	CreatedAt  int64  `json:"created_at"`
+	RevokedAt  int64  `json:"revoked_at,omitempty"`
+}
+
+// InviteRedemption records the first deposit an address made with an invite code
+type InviteRedemption struct {
+	Code       string `json:"code"`
+	PoolID     string `json:"pool_id"`
+	Address    string `json:"address"`
+	DepositID  string `json:"deposit_id"`
+	Amount     string `json:"amount"`
+	RedeemedAt int64  `json:"redeemed_at"`
 }
 
 type PoolOrderResult struct {
@@ -18,6 +18,14 @@ type Order struct {
 	Status    string `json:"status"`
 	CreatedAt int64  `json:"created_at"`
 	UpdatedAt int64  `json:"updated_at"`
+	Seq       uint64 `json:"seq,omitempty"` // global event sequence number of the latest update
+
+	TimeInForce string `json:"time_in_force,omitempty"`
+	ExpireAt    int64  `json:"expire_at,omitempty"` // Unix ms, GTD orders only
+
+	// PriceCap is the worst price a market order fills at under slippage
+	// protection; the remainder beyond it ends as ORDER_STATUS_PRICE_PROTECTED
+	PriceCap string `json:"price_cap,omitempty"`
 }
 
 // MatchResult represents matching result in API response
@@ -30,10 +38,13 @@ type MatchResult struct {
 
 // TradeInfo represents a trade in match result
 type TradeInfo struct {
-	TradeID   string `json:"trade_id"`
-	Price     string `json:"price"`
-	Quantity  string `json:"quantity"`
-	Timestamp int64  `json:"timestamp"`
+	TradeID      string `json:"trade_id"`
+	Price        string `json:"price"`
+	Quantity     string `json:"quantity"`
+	Timestamp    int64  `json:"timestamp"`
+	Maker        string `json:"maker,omitempty"`
+	MakerOrderID string `json:"maker_order_id,omitempty"`
+	Seq          uint64 `json:"seq,omitempty"`
 }
 
 // Position represents a position in the API response
@@ -69,6 +80,39 @@ type PlaceOrderRequest struct {
 	Price    string `json:"price"`
 	Quantity string `json:"quantity"`
 	Trader   string `json:"trader"`
+
+	// TimeInForce is GTC (default) or GTD; GTD limit orders rest until
+	// ExpireAt (Unix ms) at the latest
+	TimeInForce string `json:"time_in_force,omitempty"`
+	ExpireAt    int64  `json:"expire_at,omitempty"`
+
+	// MaxSlippage caps how far from the mark price a market order fills,
+	// e.g. "0.02" = 2%, overriding the market's default; "0" disables it
+	MaxSlippage string `json:"max_slippage,omitempty"`
+}
+
+// Time in force values of PlaceOrderRequest
+const (
+	TimeInForceGTC = "GTC"
+	TimeInForceGTD = "GTD"
+)
+
+// OrderIntent is an order a trader signs with their wallet: the order fields
+// plus a nonce, unique per trader, and an expiry
+type OrderIntent struct {
+	PlaceOrderRequest
+	Nonce     string `json:"nonce"`
+	ExpiresAt int64  `json:"expires_at"` // Unix ms
+}
+
+// SignedOrderRequest submits a wallet-signed order. Payload is the base64
+// JSON of an OrderIntent; Signature is the base64 ADR-036 secp256k1 signature
+// of the payload bytes by the intent's trader. The order is placed for the
+// signer, whatever X-Trader-Address says.
+type SignedOrderRequest struct {
+	Payload   string `json:"payload"`
+	PubKey    string `json:"pub_key"` // base64 compressed secp256k1 public key
+	Signature string `json:"signature"`
 }
 
 // PlaceOrderResponse represents the response after placing an order
@@ -83,17 +127,39 @@ type CancelOrderResponse struct {
 	Cancelled bool   `json:"cancelled"`
 }
 
-// ModifyOrderRequest represents the request to modify an order
+// ModifyOrderRequest represents the request to modify an order.
+// Quantity is the new total size, including any filled part.
 type ModifyOrderRequest struct {
 	Price    string `json:"price,omitempty"`
 	Quantity string `json:"quantity,omitempty"`
 }
 
-// ModifyOrderResponse represents the response after modifying an order
+// ModifyOrderResponse represents the response after modifying an order.
+// Orders are amended in place, so OldOrderID equals Order.OrderID.
+// PriorityRetained is true when only the quantity was reduced and the order
+// kept its place in the price level queue.
 type ModifyOrderResponse struct {
-	OldOrderID string       `json:"old_order_id"`
-	Order      *Order       `json:"order"`
-	Match      *MatchResult `json:"match,omitempty"`
+	OldOrderID       string       `json:"old_order_id"`
+	Order            *Order       `json:"order"`
+	Match            *MatchResult `json:"match,omitempty"`
+	PriorityRetained bool         `json:"priority_retained"`
+}
+
+// Sort orders of history queries
+const (
+	SortNewest = "desc"
+	SortOldest = "asc"
+)
+
+// HistoryParams are the filter, sort and paging parameters shared by the
+// order and trade history queries
+type HistoryParams struct {
+	Side   string `json:"side,omitempty"` // "buy" | "sell"
+	From   int64  `json:"from,omitempty"` // Unix ms, inclusive
+	To     int64  `json:"to,omitempty"`   // Unix ms, inclusive
+	Sort   string `json:"sort,omitempty"` // "desc" (newest first, default) | "asc"
+	Limit  int    `json:"limit,omitempty"`
+	Cursor string `json:"cursor,omitempty"` // next_cursor of the previous page
 }
 
 // ListOrdersRequest represents the request to list orders
@@ -101,17 +167,32 @@ type ListOrdersRequest struct {
 	Trader   string `json:"trader"`
 	MarketID string `json:"market_id,omitempty"`
 	Status   string `json:"status,omitempty"`
-	Limit    int    `json:"limit,omitempty"`
-	Cursor   string `json:"cursor,omitempty"`
+	HistoryParams
 }
 
-// ListOrdersResponse represents the response for listing orders
+// ListOrdersResponse represents a page of orders by creation time. Total is
+// the number of orders in the page.
 type ListOrdersResponse struct {
 	Orders     []*Order `json:"orders"`
 	NextCursor string   `json:"next_cursor,omitempty"`
 	Total      int      `json:"total"`
 }
 
+// ListTradesRequest represents the request to list a trader's or a market's
+// trades. For a trader, Side is the trader's side in the trade; for a market,
+// the taker's.
+type ListTradesRequest struct {
+	Trader   string `json:"trader,omitempty"`
+	MarketID string `json:"market_id,omitempty"`
+	HistoryParams
+}
+
+// ListTradesResponse represents a page of trades by time
+type ListTradesResponse struct {
+	Trades     []*MarketTrade `json:"trades"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
 // ClosePositionRequest represents the request to close a position
 type ClosePositionRequest struct {
 	Trader   string `json:"trader"`
@@ -129,21 +210,101 @@ type ClosePositionResponse struct {
 	Account     *Account `json:"account"`
 }
 
+// ClosedPosition represents a fully closed position in the API response
+type ClosedPosition struct {
+	PositionID      string `json:"position_id"`
+	MarketID        string `json:"market_id"`
+	Trader          string `json:"trader"`
+	Side            string `json:"side"`
+	Size            string `json:"size"`
+	EntryPrice      string `json:"entry_price"`
+	ExitPrice       string `json:"exit_price"`
+	RealizedPnl     string `json:"realized_pnl"`
+	FeesPaid        string `json:"fees_paid"`
+	FundingPaid     string `json:"funding_paid"`
+	NetPnl          string `json:"net_pnl"`
+	CloseReason     string `json:"close_reason"`
+	OpenedAt        int64  `json:"opened_at"`
+	ClosedAt        int64  `json:"closed_at"`
+	HoldingDuration int64  `json:"holding_duration"` // seconds
+}
+
+// PositionHistoryRequest represents the request to list closed positions
+type PositionHistoryRequest struct {
+	Trader   string `json:"trader"`
+	MarketID string `json:"market_id,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+	Cursor   string `json:"cursor,omitempty"` // next_cursor of the previous page
+}
+
+// PositionHistoryResponse represents a page of closed positions, newest first
+type PositionHistoryResponse struct {
+	Positions  []*ClosedPosition `json:"positions"`
+	Limit      int               `json:"limit"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
 // DepositRequest represents the request to deposit funds
 type DepositRequest struct {
 	Trader string `json:"trader"`
 	Amount string `json:"amount"`
 }
 
-// WithdrawRequest represents the request to withdraw funds
+// WithdrawRequest represents the request to withdraw funds. Destination
+// defaults to the trader's own address.
 type WithdrawRequest struct {
-	Trader string `json:"trader"`
-	Amount string `json:"amount"`
+	Trader      string `json:"trader"`
+	Amount      string `json:"amount"`
+	Destination string `json:"destination,omitempty"`
+}
+
+// CreateWebhookRequest registers an account webhook.
+// An empty event list subscribes to every event.
+type CreateWebhookRequest struct {
+	Trader string   `json:"trader"`
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+}
+
+// WSTokenRequest is a wallet login for a WebSocket session token.
+// Signature is the base64 secp256k1 signature of auth.LoginMessage(trader, timestamp).
+// API key logins send only the X-API-Key header.
+type WSTokenRequest struct {
+	Trader    string `json:"trader"`
+	Timestamp int64  `json:"timestamp"` // unix seconds
+	PubKey    string `json:"pub_key"`   // base64 compressed secp256k1 public key
+	Signature string `json:"signature"`
+}
+
+// WSTokenResponse carries a short-lived WebSocket session token
+type WSTokenResponse struct {
+	Token     string `json:"token"`
+	Trader    string `json:"trader"`
+	SessionID string `json:"session_id"`
+	ExpiresAt int64  `json:"expires_at"` // unix milliseconds
 }
 
-// AccountResponse represents the response for account operations
+// InvariantResult is the outcome of a single keeper invariant
+type InvariantResult struct {
+	Module  string `json:"module"`
+	Route   string `json:"route"`
+	Broken  bool   `json:"broken"`
+	Message string `json:"message,omitempty"`
+}
+
+// InvariantReport is the response of an on-demand invariant run
+type InvariantReport struct {
+	Results   []*InvariantResult `json:"results"`
+	Broken    int                `json:"broken"`
+	CheckedAt int64              `json:"checked_at"`
+}
+
+// AccountResponse represents the response for account operations. A
+// withdrawal carries its request, pending until release_at if the account
+// has a withdrawal timelock.
 type AccountResponse struct {
-	Account *Account `json:"account"`
+	Account    *Account    `json:"account"`
+	Withdrawal *Withdrawal `json:"withdrawal,omitempty"`
 }
 
 // OrderService defines the interface for order operations
@@ -155,11 +316,549 @@ type OrderService interface {
 	ListOrders(ctx context.Context, req *ListOrdersRequest) (*ListOrdersResponse, error)
 }
 
+// TradeHistoryService lists trade history with filters and cursor pagination
+type TradeHistoryService interface {
+	ListTrades(ctx context.Context, req *ListTradesRequest) (*ListTradesResponse, error)
+}
+
 // PositionService defines the interface for position operations
 type PositionService interface {
 	GetPositions(ctx context.Context, trader string) ([]*Position, error)
 	GetPosition(ctx context.Context, trader, marketID string) (*Position, error)
 	ClosePosition(ctx context.Context, req *ClosePositionRequest) (*ClosePositionResponse, error)
+	GetPositionHistory(ctx context.Context, req *PositionHistoryRequest) (*PositionHistoryResponse, error)
+}
+
+// MMPConfig is a trader's market maker protection for one market. Fills of
+// the trader's resting orders are counted over a rolling window; reaching any
+// limit cancels the trader's orders in the market and blocks new limit orders
+// for the freeze period. Zero or omitted limits are not checked.
+type MMPConfig struct {
+	MarketID  string `json:"market_id"`
+	Enabled   bool   `json:"enabled"`
+	WindowMs  int64  `json:"window_ms"`
+	FreezeMs  int64  `json:"freeze_ms"`
+	MaxFills  int64  `json:"max_fills,omitempty"`
+	MaxDelta  string `json:"max_delta,omitempty"`  // net filled quantity, buys minus sells
+	MaxVolume string `json:"max_volume,omitempty"` // gross filled quantity
+	UpdatedAt int64  `json:"updated_at,omitempty"`
+}
+
+// MMPStatus is a market maker protection config with its current window
+type MMPStatus struct {
+	Config        *MMPConfig `json:"config"`
+	Frozen        bool       `json:"frozen"`
+	FrozenUntil   int64      `json:"frozen_until,omitempty"`
+	TriggeredAt   int64      `json:"triggered_at,omitempty"`
+	TriggerReason string     `json:"trigger_reason,omitempty"` // fills | delta | volume
+	Fills         int64      `json:"fills"`
+	Delta         string     `json:"delta"`
+	Volume        string     `json:"volume"`
+}
+
+// MMPService manages market maker protection enforced by the matching engine
+type MMPService interface {
+	GetMMP(ctx context.Context, trader string) ([]*MMPStatus, error)
+	SetMMP(ctx context.Context, trader string, config *MMPConfig) (*MMPStatus, error)
+	DeleteMMP(ctx context.Context, trader, marketID string) error
+	ResetMMP(ctx context.Context, trader, marketID string) (*MMPStatus, error)
+}
+
+// FeeToken is the alternative token fees can be paid in. A fee paid in it is
+// the USDC fee less Discount, converted at Rate (the USDC value of one base
+// unit of Denom) and rounded up to a whole base unit.
+type FeeToken struct {
+	Enabled  bool   `json:"enabled"`
+	Denom    string `json:"denom,omitempty"`
+	Discount string `json:"discount"`
+	Rate     string `json:"rate,omitempty"` // empty while the token has no price
+}
+
+// FeePreference is a trader's choice of fee currency. While PayInFeeToken is
+// set and the fee token is enabled, fees are charged in the token whenever
+// the trader's balance covers them, and in USDC otherwise.
+type FeePreference struct {
+	PayInFeeToken bool      `json:"pay_in_fee_token"`
+	FeeToken      *FeeToken `json:"fee_token"`
+	UpdatedAt     int64     `json:"updated_at,omitempty"`
+}
+
+// FeePreferenceService manages traders' fee currency preferences
+type FeePreferenceService interface {
+	GetFeePreference(ctx context.Context, trader string) (*FeePreference, error)
+	SetFeePreference(ctx context.Context, trader string, payInFeeToken bool) (*FeePreference, error)
+}
+
+// OrderLimits caps how many limit orders may rest on the books, per market
+// and per trader across markets. Orders past a cap are rejected with
+// market_order_limit or trader_order_limit; resting orders are never evicted.
+// Zero caps are not checked.
+type OrderLimits struct {
+	MaxRestingPerMarket int64 `json:"max_resting_per_market"`
+	MaxRestingPerTrader int64 `json:"max_resting_per_trader"`
+	UpdatedAt           int64 `json:"updated_at,omitempty"`
+}
+
+// OrderLimitsStatus is the order limits with each market's resting order count
+type OrderLimitsStatus struct {
+	Limits  *OrderLimits     `json:"limits"`
+	Resting map[string]int64 `json:"resting"` // market ID -> resting orders
+}
+
+// OrderLimitsService manages the resting order limits at runtime
+type OrderLimitsService interface {
+	GetOrderLimits(ctx context.Context) (*OrderLimitsStatus, error)
+	SetOrderLimits(ctx context.Context, limits *OrderLimits) (*OrderLimitsStatus, error)
+}
+
+// PriceRangeOrdersRequest selects the resting orders of a market side within
+// a price range
+type PriceRangeOrdersRequest struct {
+	MarketID string `json:"market_id"`
+	Side     string `json:"side"`                // buy or sell
+	MinPrice string `json:"min_price,omitempty"` // inclusive, empty for none
+	MaxPrice string `json:"max_price,omitempty"` // inclusive, empty for none
+	Limit    int    `json:"limit,omitempty"`
+}
+
+// PriceRangeOrdersResponse lists resting orders best price first: bids from
+// the highest price, asks from the lowest, oldest first within a price. Total
+// is the number of orders in the response.
+type PriceRangeOrdersResponse struct {
+	MarketID string   `json:"market_id"`
+	Side     string   `json:"side"`
+	Orders   []*Order `json:"orders"`
+	Total    int      `json:"total"`
+}
+
+// PriceRangeOrderService queries resting orders by price from the keeper's
+// price index, for liquidation and surveillance tooling
+type PriceRangeOrderService interface {
+	GetOrdersByPriceRange(ctx context.Context, req *PriceRangeOrdersRequest) (*PriceRangeOrdersResponse, error)
+}
+
+// MarginRecheckParams configures the periodic margin re-check of resting
+// orders at the mark price. Every Interval blocks (seconds on a standalone
+// node; zero disables it) the orders their owners can no longer cover are
+// cancelled or flagged, as Action says.
+type MarginRecheckParams struct {
+	Interval  int64  `json:"interval"`
+	Action    string `json:"action"` // cancel or flag
+	UpdatedAt int64  `json:"updated_at,omitempty"`
+}
+
+// MarginFlag is a resting order that failed its last margin re-check
+type MarginFlag struct {
+	OrderID      string `json:"order_id"`
+	Trader       string `json:"trader"`
+	MarketID     string `json:"market_id"`
+	MarkPrice    string `json:"mark_price"`
+	RemainingQty string `json:"remaining_qty"`
+	Reason       string `json:"reason"`
+	FlaggedAt    int64  `json:"flagged_at"`
+}
+
+// MarginRecheckStatus is the re-check params with the flagged orders
+type MarginRecheckStatus struct {
+	Params  *MarginRecheckParams `json:"params"`
+	Flagged []*MarginFlag        `json:"flagged"`
+}
+
+// MarginRecheckResult is what one re-check did
+type MarginRecheckResult struct {
+	Checked   int           `json:"checked"`
+	Cancelled []*Order      `json:"cancelled"`
+	Flagged   []*MarginFlag `json:"flagged"` // newly flagged orders
+}
+
+// MarginRecheckService manages the resting order margin re-check
+type MarginRecheckService interface {
+	GetMarginRecheck(ctx context.Context) (*MarginRecheckStatus, error)
+	SetMarginRecheck(ctx context.Context, params *MarginRecheckParams) (*MarginRecheckStatus, error)
+	// RecheckOrderMargin re-checks every resting order now
+	RecheckOrderMargin(ctx context.Context) (*MarginRecheckResult, error)
+}
+
+// PortfolioTrade is a hypothetical trade, filled at the mark price, to
+// preview portfolio margin with
+type PortfolioTrade struct {
+	MarketID string `json:"market_id"`
+	Side     string `json:"side"` // buy or sell
+	Size     string `json:"size"`
+}
+
+// PortfolioAssetRisk is the stress result of one base asset of a book
+type PortfolioAssetRisk struct {
+	Asset         string `json:"asset"`
+	Shock         string `json:"shock"`
+	NetNotional   string `json:"net_notional"` // long positive, short negative
+	GrossNotional string `json:"gross_notional"`
+	LossUp        string `json:"loss_up"`
+	LossDown      string `json:"loss_down"`
+	ScanRisk      string `json:"scan_risk"`
+}
+
+// PortfolioMarginPreview is a trader's margin under the portfolio margin risk
+// array, whatever their margin mode, next to the per-position requirement
+type PortfolioMarginPreview struct {
+	Trader            string                `json:"trader"`
+	MarginMode        string                `json:"margin_mode"`
+	Trade             *PortfolioTrade       `json:"trade,omitempty"`
+	Equity            string                `json:"equity"`
+	Assets            []*PortfolioAssetRisk `json:"assets"`
+	ScanRisk          string                `json:"scan_risk"`
+	OffsetCredit      string                `json:"offset_credit"`
+	FloorMargin       string                `json:"floor_margin"`
+	InitialMargin     string                `json:"initial_margin"`
+	MaintenanceMargin string                `json:"maintenance_margin"`
+	StandardMargin    string                `json:"standard_margin"`
+	AvailableMargin   string                `json:"available_margin"`
+	Healthy           bool                  `json:"healthy"`
+}
+
+// PortfolioMarginService previews margin under the keeper's risk array
+type PortfolioMarginService interface {
+	// PreviewPortfolioMargin stresses the trader's book, with trade added
+	// when it is not nil
+	PreviewPortfolioMargin(ctx context.Context, trader string, trade *PortfolioTrade) (*PortfolioMarginPreview, error)
+}
+
+// PortfolioPosition is an open position valued at the mark price with its
+// leverage and share of the account's equity
+type PortfolioPosition struct {
+	MarketID      string `json:"market_id"`
+	BaseAsset     string `json:"base_asset"`
+	Side          string `json:"side"`
+	Size          string `json:"size"`
+	MarkPrice     string `json:"mark_price"`
+	Notional      string `json:"notional"`
+	Margin        string `json:"margin"`
+	UnrealizedPnl string `json:"unrealized_pnl"`
+	Leverage      string `json:"leverage"`     // notional / margin
+	EquityShare   string `json:"equity_share"` // notional / equity
+}
+
+// PortfolioAssetExposure is a trader's exposure to one base asset, with the
+// net notional left after correlation offsets against other assets
+type PortfolioAssetExposure struct {
+	Asset               string `json:"asset"`
+	LongNotional        string `json:"long_notional"`
+	ShortNotional       string `json:"short_notional"`
+	NetNotional         string `json:"net_notional"`
+	AdjustedNetNotional string `json:"adjusted_net_notional"`
+}
+
+// Portfolio is every open position of a trader with account-wide risk
+// metrics, for risk dashboards
+type Portfolio struct {
+	Trader           string                    `json:"trader"`
+	Equity           string                    `json:"equity"`
+	Positions        []*PortfolioPosition      `json:"positions"`
+	Assets           []*PortfolioAssetExposure `json:"assets"`
+	LongNotional     string                    `json:"long_notional"`
+	ShortNotional    string                    `json:"short_notional"`
+	GrossNotional    string                    `json:"gross_notional"`
+	NetNotional      string                    `json:"net_notional"`
+	AdjustedNotional string                    `json:"adjusted_notional"`
+	Leverage         string                    `json:"leverage"` // gross notional / equity
+	Skew             string                    `json:"skew"`     // net / gross notional, -1 to 1
+	UpdatedAt        int64                     `json:"updated_at"`
+}
+
+// PortfolioService aggregates a trader's positions into portfolio risk metrics
+type PortfolioService interface {
+	GetPortfolio(ctx context.Context, trader string) (*Portfolio, error)
+}
+
+// WithdrawalSecurity is an account's withdrawal protection. A weakening
+// change, a shorter timelock or disabling the allowlist, is pending until the
+// current timelock has passed.
+type WithdrawalSecurity struct {
+	Trader           string                    `json:"trader"`
+	TimelockSeconds  int64                     `json:"timelock_seconds"`
+	AllowlistEnabled bool                      `json:"allowlist_enabled"`
+	Pending          *WithdrawalSecurityChange `json:"pending,omitempty"`
+	UpdatedAt        int64                     `json:"updated_at,omitempty"`
+}
+
+// WithdrawalSecurityChange is a scheduled weakening of withdrawal protection
+type WithdrawalSecurityChange struct {
+	TimelockSeconds  int64 `json:"timelock_seconds"`
+	AllowlistEnabled bool  `json:"allowlist_enabled"`
+	EffectiveAt      int64 `json:"effective_at"`
+}
+
+// SetWithdrawalSecurityRequest changes an account's withdrawal protection
+type SetWithdrawalSecurityRequest struct {
+	TimelockSeconds  *int64 `json:"timelock_seconds"`
+	AllowlistEnabled *bool  `json:"allowlist_enabled"`
+}
+
+// WithdrawalAddress is an allowlisted withdrawal destination, usable from active_at
+type WithdrawalAddress struct {
+	Address  string `json:"address"`
+	Label    string `json:"label,omitempty"`
+	AddedAt  int64  `json:"added_at"`
+	ActiveAt int64  `json:"active_at"`
+	Active   bool   `json:"active"`
+}
+
+// Withdrawal is a margin withdrawal request. A pending withdrawal is paid
+// out at release_at and can be cancelled until then.
+type Withdrawal struct {
+	WithdrawalID string `json:"withdrawal_id"`
+	Trader       string `json:"trader"`
+	Amount       string `json:"amount"`
+	Destination  string `json:"destination"`
+	Status       string `json:"status"` // pending | completed | cancelled
+	RequestedAt  int64  `json:"requested_at"`
+	ReleaseAt    int64  `json:"release_at"`
+	ProcessedAt  int64  `json:"processed_at,omitempty"`
+	Cancellable  bool   `json:"cancellable"`
+}
+
+// WithdrawalSecurityService manages withdrawal timelocks, destination
+// allowlists and pending withdrawals held by the perpetual keeper
+type WithdrawalSecurityService interface {
+	GetWithdrawalSecurity(ctx context.Context, trader string) (*WithdrawalSecurity, error)
+	SetWithdrawalSecurity(ctx context.Context, trader string, req *SetWithdrawalSecurityRequest) (*WithdrawalSecurity, error)
+	GetWithdrawalAddresses(ctx context.Context, trader string) ([]*WithdrawalAddress, error)
+	AddWithdrawalAddress(ctx context.Context, trader, address, label string) (*WithdrawalAddress, error)
+	RemoveWithdrawalAddress(ctx context.Context, trader, address string) error
+	GetWithdrawals(ctx context.Context, trader string, limit int) ([]*Withdrawal, error)
+	CancelWithdrawal(ctx context.Context, trader, withdrawalID string) (*Withdrawal, error)
+	// ReleaseWithdrawals pays out the withdrawals whose timelock has passed,
+	// as the chain's EndBlocker does, for the standalone server's scheduler
+	ReleaseWithdrawals(ctx context.Context) ([]*Withdrawal, error)
+}
+
+// LPObligation is a designated market maker's quoting obligation in one
+// market: at least min_quantity on each side within max_spread_bps of the mid
+// price, for at least min_uptime of each epoch
+type LPObligation struct {
+	Trader       string `json:"trader"`
+	MarketID     string `json:"market_id"`
+	MaxSpreadBps int64  `json:"max_spread_bps"`
+	MinQuantity  string `json:"min_quantity"`
+	MinUptime    string `json:"min_uptime"` // fraction, e.g. "0.9"
+	UpdatedAt    int64  `json:"updated_at,omitempty"`
+}
+
+// LPUptime is a maker's compliance with one obligation over one epoch,
+// sampled once per block
+type LPUptime struct {
+	MarketID      string `json:"market_id"`
+	Samples       int64  `json:"samples"`
+	QuotedSamples int64  `json:"quoted_samples"`
+	Uptime        string `json:"uptime"`
+	MinUptime     string `json:"min_uptime"`
+	MaxSpreadBps  int64  `json:"max_spread_bps"`
+	Met           bool   `json:"met"`
+	LastSampledAt int64  `json:"last_sampled_at,omitempty"`
+}
+
+// LPUptimeReport is a maker's uptime in every market for one epoch. Eligible
+// is whether the maker currently qualifies for Foundation LP seats and points,
+// judged on the last completed epoch.
+type LPUptimeReport struct {
+	Trader     string      `json:"trader"`
+	Epoch      int64       `json:"epoch"`
+	EpochStart int64       `json:"epoch_start"`
+	EpochEnd   int64       `json:"epoch_end"`
+	Markets    []*LPUptime `json:"markets"`
+	Met        bool        `json:"met"`
+	Eligible   bool        `json:"eligible"`
+}
+
+// LPObligationService manages LP quoting obligations tracked by the orderbook
+// keeper. A negative epoch selects the current one.
+type LPObligationService interface {
+	ListLPObligations(ctx context.Context, trader string) ([]*LPObligation, error)
+	SetLPObligation(ctx context.Context, obligation *LPObligation) (*LPObligation, error)
+	DeleteLPObligation(ctx context.Context, trader, marketID string) error
+	GetLPUptime(ctx context.Context, trader string, epoch int64) (*LPUptimeReport, error)
+}
+
+// FeeRollup is one market's trading fees over one UTC day and how they were
+// split. Settled is set once the day has ended and the shares were credited.
+type FeeRollup struct {
+	Day           string `json:"day"` // YYYY-MM-DD
+	DayStart      int64  `json:"day_start"`
+	MarketID      string `json:"market_id"`
+	Entries       int64  `json:"entries"`
+	MakerFees     string `json:"maker_fees"`
+	TakerFees     string `json:"taker_fees"`
+	TotalFees     string `json:"total_fees"`
+	InsuranceFund string `json:"insurance_fund"`
+	RiverPool     string `json:"riverpool"`
+	Treasury      string `json:"treasury"`
+	Settled       bool   `json:"settled"`
+	FeeTokenFees  string `json:"fee_token_fees,omitempty"` // fees paid in the fee token, in its base units
+}
+
+// FeeBalances are the cumulative settled fee shares per destination
+type FeeBalances struct {
+	InsuranceFund  string `json:"insurance_fund"`
+	RiverPool      string `json:"riverpool"`
+	Treasury       string `json:"treasury"`
+	SettledThrough string `json:"settled_through,omitempty"` // last settled day, YYYY-MM-DD
+}
+
+// FeeLedgerService reports the trading fee ledger kept by the orderbook
+// keeper. Days are Unix milliseconds, truncated to the UTC day.
+type FeeLedgerService interface {
+	GetDailyFees(ctx context.Context, from, to int64, marketID string) ([]*FeeRollup, error)
+	GetFeeBalances(ctx context.Context) (*FeeBalances, error)
+}
+
+// TreasuryParams are the governance-set shares of every trading fee
+type TreasuryParams struct {
+	InsuranceFund string `json:"insurance_fund"`
+	RiverPool     string `json:"riverpool"`
+	Treasury      string `json:"treasury"`
+}
+
+// Treasury is the protocol treasury's balance and fee distribution parameters
+type Treasury struct {
+	Balance       string         `json:"balance"`
+	TotalReceived string         `json:"total_received"`
+	TotalSpent    string         `json:"total_spent"`
+	Params        TreasuryParams `json:"params"`
+}
+
+// TreasuryEntry is one credit to or debit from the treasury: the treasury
+// share of a market's fees for a day, or a governance-approved spend
+type TreasuryEntry struct {
+	Seq         uint64 `json:"seq"`
+	Kind        string `json:"kind"` // fees or spend
+	Amount      string `json:"amount"`
+	Balance     string `json:"balance"`             // treasury balance after the entry
+	MarketID    string `json:"market_id,omitempty"` // fees only
+	Day         string `json:"day,omitempty"`       // fees only, YYYY-MM-DD
+	Recipient   string `json:"recipient,omitempty"` // spends only
+	Reason      string `json:"reason,omitempty"`    // spends only
+	BlockHeight int64  `json:"block_height"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// TreasuryService reports the protocol treasury kept by the treasury module.
+// History is newest first; before pages back from a sequence number.
+type TreasuryService interface {
+	GetTreasury(ctx context.Context) (*Treasury, error)
+	GetTreasuryHistory(ctx context.Context, kind string, before uint64, limit int) ([]*TreasuryEntry, error)
+}
+
+// ADLIndicator is a position's place in its market's auto-deleveraging queue.
+// Lights run from 1 to 5, and 5-light positions are deleveraged first.
+// Positions without profit are not in the queue and show 1 light.
+type ADLIndicator struct {
+	MarketID  string `json:"market_id"`
+	Trader    string `json:"trader"`
+	Side      string `json:"side"`
+	Lights    int    `json:"lights"`
+	InQueue   bool   `json:"in_queue"`
+	Rank      int    `json:"rank,omitempty"` // 1 = first to be deleveraged
+	QueueSize int    `json:"queue_size"`     // profitable positions on the same side
+	Score     string `json:"score"`          // PnL% x effective leverage
+	Timestamp int64  `json:"timestamp"`
+}
+
+// ADLService ranks the open positions of a market in its ADL queues
+type ADLService interface {
+	GetADLIndicators(ctx context.Context, marketID string) ([]*ADLIndicator, error)
+}
+
+// LongShortRatio is the split of a market's open positions between longs and
+// shorts, by account and by notional at the mark price. Ratios are long over
+// short and zero while the market has no short.
+type LongShortRatio struct {
+	MarketID      string `json:"market_id"`
+	LongAccounts  int64  `json:"long_accounts"`
+	ShortAccounts int64  `json:"short_accounts"`
+	AccountRatio  string `json:"account_ratio"`
+	LongNotional  string `json:"long_notional"`
+	ShortNotional string `json:"short_notional"`
+	NotionalRatio string `json:"notional_ratio"`
+	MarkPrice     string `json:"mark_price"`
+	Timestamp     int64  `json:"timestamp"`
+}
+
+// LongShortService reports the long/short ratio of a market
+type LongShortService interface {
+	GetLongShortRatio(ctx context.Context, marketID string) (*LongShortRatio, error)
+}
+
+// InvariantService runs the keeper invariants asserted by the crisis module on-chain
+type InvariantService interface {
+	RunInvariants(ctx context.Context) (*InvariantReport, error)
+}
+
+// TradingHours is a recurring daily trading session in UTC
+type TradingHours struct {
+	Days  []string `json:"days,omitempty"` // "mon".."sun"; empty means every day
+	Open  string   `json:"open"`           // "HH:MM"
+	Close string   `json:"close"`          // "HH:MM"; before open for sessions past midnight
+}
+
+// MaintenanceWindow is a one-off period during which a market only accepts cancels
+type MaintenanceWindow struct {
+	ID     string `json:"id"`
+	Start  int64  `json:"start"` // Unix millis
+	End    int64  `json:"end"`   // Unix millis
+	Reason string `json:"reason,omitempty"`
+}
+
+// TradingSchedule restricts when a market accepts new orders
+type TradingSchedule struct {
+	MarketID     string               `json:"market_id"`
+	TradingHours []*TradingHours      `json:"trading_hours"` // Empty trades around the clock
+	Maintenance  []*MaintenanceWindow `json:"maintenance"`
+	UpdatedAt    int64                `json:"updated_at"`
+}
+
+// Trading status values
+const (
+	TradingStatusOpen        = "open"
+	TradingStatusMaintenance = "maintenance"
+	TradingStatusClosed      = "closed"
+)
+
+// TradingStatus reports whether a market currently accepts new orders
+type TradingStatus struct {
+	MarketID        string             `json:"market_id"`
+	Status          string             `json:"status"`           // TradingStatus* value
+	Reason          string             `json:"reason,omitempty"` // "maintenance" or "outside_trading_hours"
+	Message         string             `json:"message,omitempty"`
+	Until           int64              `json:"until,omitempty"` // Unix millis the market reopens at
+	NextMaintenance *MaintenanceWindow `json:"next_maintenance,omitempty"`
+}
+
+// Err returns the error new orders are rejected with, or nil if the market is open
+func (s *TradingStatus) Err() *APIError {
+	if s.Status == TradingStatusOpen {
+		return nil
+	}
+	code := ErrCodeMarketClosed
+	if s.Status == TradingStatusMaintenance {
+		code = ErrCodeMarketMaintenance
+	}
+	message := "Market " + s.MarketID + " only accepts cancels"
+	if s.Message != "" {
+		message += ": " + s.Message
+	}
+	err := NewAPIError(code, message).WithDetail("reason", s.Reason)
+	if s.Until > 0 {
+		err.WithDetail("until", s.Until)
+	}
+	return err
+}
+
+// TradingScheduleService manages market trading schedules
+type TradingScheduleService interface {
+	GetTradingSchedule(ctx context.Context, marketID string) (*TradingSchedule, error)
+	SetTradingSchedule(ctx context.Context, schedule *TradingSchedule) (*TradingSchedule, error)
+	DeleteTradingSchedule(ctx context.Context, marketID string) error
+	GetTradingStatus(ctx context.Context, marketID string) (*TradingStatus, error)
 }
 
 // AccountService defines the interface for account operations
@@ -169,6 +868,752 @@ type AccountService interface {
 	Withdraw(ctx context.Context, req *WithdrawRequest) (*AccountResponse, error)
 }
 
+// MaxBatchQueryTraders caps the traders in one POST /v1/accounts/batch-query
+const MaxBatchQueryTraders = 500
+
+// AccountBatchQueryRequest lists the traders to query in one call
+type AccountBatchQueryRequest struct {
+	Traders []string `json:"traders"`
+}
+
+// AccountSummary is one trader's balances, positions and open order count
+type AccountSummary struct {
+	Trader     string      `json:"trader"`
+	Account    *Account    `json:"account"`
+	Positions  []*Position `json:"positions"`
+	OpenOrders int         `json:"open_orders"`
+}
+
+// AccountBatchService returns the summaries of many traders at once, in the
+// order requested
+type AccountBatchService interface {
+	BatchQueryAccounts(ctx context.Context, traders []string) ([]*AccountSummary, error)
+}
+
+// AccountSnapshot is the summaries of several traders read from one view of
+// state, so sums across them are taken at one height
+type AccountSnapshot struct {
+	Height   int64 // block height of the state read; 0 without a chain
+	Accounts []*AccountSummary
+}
+
+// AccountSnapshotService returns the summaries of many traders from one
+// consistent view of state, in the order requested
+type AccountSnapshotService interface {
+	SnapshotAccounts(ctx context.Context, traders []string) (*AccountSnapshot, error)
+}
+
+// MaxPortfolioAddresses caps the addresses in one GET /v1/portfolio
+const MaxPortfolioAddresses = 50
+
+// PortfolioMarket is the combined position of several addresses in one
+// market; long and short positions of different addresses offset in NetSize
+type PortfolioMarket struct {
+	MarketID      string `json:"market_id"`
+	LongSize      string `json:"long_size"`
+	ShortSize     string `json:"short_size"`
+	NetSize       string `json:"net_size"` // long - short
+	Margin        string `json:"margin"`
+	UnrealizedPnl string `json:"unrealized_pnl"`
+}
+
+// WatchPortfolio is the combined balances, positions and PnL of several
+// addresses, such as a trader's hot and cold accounts, read at one height.
+// Equity is the balance plus the unrealized PnL of open positions.
+type WatchPortfolio struct {
+	Addresses        []string           `json:"addresses"`
+	Height           int64              `json:"height"`
+	Balance          string             `json:"balance"`
+	LockedMargin     string             `json:"locked_margin"`
+	AvailableBalance string             `json:"available_balance"`
+	UnrealizedPnl    string             `json:"unrealized_pnl"`
+	Equity           string             `json:"equity"`
+	OpenOrders       int                `json:"open_orders"`
+	Markets          []*PortfolioMarket `json:"markets"`
+	Accounts         []*AccountSummary  `json:"accounts"`
+	Timestamp        int64              `json:"timestamp"`
+}
+
+// TraderLister lists the traders that hold an account, for jobs that
+// cover every account
+type TraderLister interface {
+	ListTraders(ctx context.Context) ([]string, error)
+}
+
+// EquitySnapshot is a trader's balance and equity at one point in time.
+// Equity is the balance plus the unrealized PnL of open positions.
+type EquitySnapshot struct {
+	Timestamp     int64  `json:"timestamp"` // Unix millis
+	Balance       string `json:"balance"`
+	Equity        string `json:"equity"`
+	UnrealizedPnl string `json:"unrealized_pnl"`
+	Positions     int    `json:"positions"`
+}
+
+// EquityHistoryResponse lists a trader's equity snapshots, oldest first
+type EquityHistoryResponse struct {
+	Trader    string            `json:"trader"`
+	Interval  int64             `json:"interval"` // Snapshot interval in millis
+	Snapshots []*EquitySnapshot `json:"snapshots"`
+}
+
+// Market history metrics
+const (
+	MarketHistoryFunding = "funding"
+	MarketHistoryOI      = "oi"
+	MarketHistoryVolume  = "volume"
+)
+
+// MarketHistoryPoint is one time bucket of a market history series. Value is
+// the average funding rate, the last open interest or the traded base
+// quantity of the bucket, by metric.
+type MarketHistoryPoint struct {
+	Timestamp int64  `json:"timestamp"` // Bucket start, Unix millis
+	Value     string `json:"value"`
+	Notional  string `json:"notional,omitempty"` // volume: traded quote amount
+	Trades    int    `json:"trades,omitempty"`   // volume: number of trades
+	Samples   int    `json:"samples,omitempty"`  // funding, oi: stats samples in the bucket
+}
+
+// MarketHistoryResponse is a bucketed market history series, oldest first.
+// Buckets without data are omitted.
+type MarketHistoryResponse struct {
+	MarketID string                `json:"market_id"`
+	Metric   string                `json:"metric"`
+	Bucket   string                `json:"bucket"`
+	From     int64                 `json:"from"` // Unix millis, inclusive
+	To       int64                 `json:"to"`   // Unix millis, exclusive
+	Points   []*MarketHistoryPoint `json:"points"`
+}
+
+// FundingPayment is a funding settlement applied to a trader's position
+type FundingPayment struct {
+	PaymentID string `json:"payment_id"`
+	MarketID  string `json:"market_id"`
+	Amount    string `json:"amount"` // positive = received, negative = paid
+	Rate      string `json:"rate"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// TradePosition is a trader's position in a market just before or after a
+// fill
+type TradePosition struct {
+	Side       string `json:"side"` // long, short or flat
+	Size       string `json:"size"`
+	EntryPrice string `json:"entry_price"`         // average entry price, 0 when flat
+	OpenedAt   int64  `json:"opened_at,omitempty"` // fill that opened the position
+}
+
+// TradeFeeToken is the part of a fee paid in the fee token instead of USDC
+type TradeFeeToken struct {
+	Denom       string `json:"denom"`
+	Amount      string `json:"amount"`
+	ReplacedFee string `json:"replaced_fee"` // USDC fee the token payment replaced
+}
+
+// TradePnL explains one fill's effect on a trader's balance and position
+type TradePnL struct {
+	TradeID        string         `json:"trade_id"`
+	MarketID       string         `json:"market_id"`
+	Trader         string         `json:"trader"`
+	Role           string         `json:"role"` // maker or taker
+	Side           string         `json:"side"` // the trader's side: SIDE_BUY or SIDE_SELL
+	Price          string         `json:"price"`
+	Quantity       string         `json:"quantity"`
+	Timestamp      int64          `json:"timestamp"`
+	Fee            string         `json:"fee"` // USDC charged, negative for a rebate
+	FeeToken       *TradeFeeToken `json:"fee_token,omitempty"`
+	ClosedQuantity string         `json:"closed_quantity"`
+	RealizedPnL    string         `json:"realized_pnl"`   // vs the average entry price
+	BalanceChange  string         `json:"balance_change"` // realized_pnl - fee
+
+	// Funding settled on the position the fill traded against, from its
+	// opening fill to this one; positive = received
+	FundingSinceEntry string `json:"funding_since_entry"`
+	FundingPayments   int    `json:"funding_payments"`
+
+	Before  TradePosition `json:"position_before"`
+	After   TradePosition `json:"position_after"`
+	Fills   int           `json:"fills"` // fills folded to rebuild the position
+	Summary string        `json:"summary"`
+}
+
+// TradePnLService explains trades from the trade, fee and funding records
+type TradePnLService interface {
+	GetTradePnL(ctx context.Context, trader, tradeID string) (*TradePnL, error)
+}
+
+// Execution is one fill of an order, with the order's fill totals after it.
+// It is pushed to the trader's orders WebSocket channel as an "execution"
+// message.
+type Execution struct {
+	OrderID       string `json:"order_id"`
+	TradeID       string `json:"trade_id"`
+	MarketID      string `json:"market_id"`
+	Trader        string `json:"trader"`
+	Side          string `json:"side"`      // the order's side: SIDE_BUY or SIDE_SELL
+	Liquidity     string `json:"liquidity"` // maker or taker
+	Price         string `json:"price"`
+	Quantity      string `json:"quantity"`
+	Fee           string `json:"fee"` // USDC charged, negative for a rebate
+	CumulativeQty string `json:"cumulative_qty"`
+	RemainingQty  string `json:"remaining_qty"`
+	Timestamp     int64  `json:"timestamp"`
+}
+
+// OrderExecutionsResponse lists an order's fills, oldest first
+type OrderExecutionsResponse struct {
+	OrderID    string       `json:"order_id"`
+	Executions []*Execution `json:"executions"`
+}
+
+// ExecutionService serves per-fill execution reports
+type ExecutionService interface {
+	GetOrderExecutions(ctx context.Context, orderID string) (*OrderExecutionsResponse, error)
+	// GetTradeExecutions returns the taker's and the maker's execution of a trade
+	GetTradeExecutions(ctx context.Context, tradeID string) ([]*Execution, error)
+}
+
+// Spread is a synthetic instrument on the price difference of two markets:
+// buying it buys the front market and sells ratio times the quantity in the
+// back market. Its price is front - ratio × back and may be negative.
+type Spread struct {
+	SpreadID    string `json:"spread_id"` // "<front>:<back>"
+	FrontMarket string `json:"front_market"`
+	BackMarket  string `json:"back_market"`
+	Ratio       string `json:"ratio"`
+	TickSize    string `json:"tick_size"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// SpreadQuote is the top of a spread's own book and the prices implied by its
+// legs' books. Empty prices mean that side has no liquidity.
+type SpreadQuote struct {
+	Spread        *Spread `json:"spread"`
+	BestBid       string  `json:"best_bid,omitempty"`
+	BestBidQty    string  `json:"best_bid_qty,omitempty"`
+	BestAsk       string  `json:"best_ask,omitempty"`
+	BestAskQty    string  `json:"best_ask_qty,omitempty"`
+	ImpliedBid    string  `json:"implied_bid,omitempty"`
+	ImpliedBidQty string  `json:"implied_bid_qty,omitempty"`
+	ImpliedAsk    string  `json:"implied_ask,omitempty"`
+	ImpliedAskQty string  `json:"implied_ask_qty,omitempty"`
+}
+
+// CreateSpreadRequest defines a spread; ratio defaults to 1 and tick_size to
+// no tick check
+type CreateSpreadRequest struct {
+	FrontMarket string `json:"front_market"`
+	BackMarket  string `json:"back_market"`
+	Ratio       string `json:"ratio,omitempty"`
+	TickSize    string `json:"tick_size,omitempty"`
+}
+
+// PlaceSpreadOrderRequest places a limit order on a spread
+type PlaceSpreadOrderRequest struct {
+	SpreadID string `json:"spread_id"`
+	Trader   string `json:"trader"`
+	Side     string `json:"side"` // buy or sell
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// SpreadFill is one execution of a spread order and the trades it was booked
+// as in the leg markets. An implied fill traded against the legs' books.
+type SpreadFill struct {
+	SpreadID   string   `json:"spread_id"`
+	OrderID    string   `json:"order_id"`
+	Trader     string   `json:"trader"`
+	Side       string   `json:"side"`
+	Price      string   `json:"price"`
+	Quantity   string   `json:"quantity"`
+	FrontPrice string   `json:"front_price"`
+	BackPrice  string   `json:"back_price"`
+	TradeIDs   []string `json:"trade_ids"`
+	Implied    bool     `json:"implied"`
+	Timestamp  int64    `json:"timestamp"`
+}
+
+// SpreadOrderResponse is a spread order with its fills, oldest first
+type SpreadOrderResponse struct {
+	Order *Order        `json:"order"`
+	Fills []*SpreadFill `json:"fills"`
+}
+
+// SpreadService serves spread instruments and their orders
+type SpreadService interface {
+	ListSpreads(ctx context.Context) ([]*Spread, error)
+	GetSpreadQuote(ctx context.Context, spreadID string) (*SpreadQuote, error)
+	CreateSpread(ctx context.Context, req *CreateSpreadRequest) (*Spread, error)
+	PlaceSpreadOrder(ctx context.Context, req *PlaceSpreadOrderRequest) (*SpreadOrderResponse, error)
+	GetSpreadOrder(ctx context.Context, orderID string) (*SpreadOrderResponse, error)
+	CancelSpreadOrder(ctx context.Context, trader, orderID string) (*Order, error)
+	ListSpreadOrders(ctx context.Context, trader string) ([]*Order, error)
+	// MatchSpreads trades resting spread orders against the legs' books, as
+	// the chain's EndBlocker does every block
+	MatchSpreads(ctx context.Context) error
+}
+
+// FundingPaymentService lists a trader's settled funding payments, newest first
+type FundingPaymentService interface {
+	GetFundingPayments(ctx context.Context, trader string, limit int) ([]*FundingPayment, error)
+}
+
+// Transfer is a margin deposit into or withdrawal from a trader's account
+type Transfer struct {
+	TransferID   string `json:"transfer_id"`
+	Type         string `json:"type"` // "deposit" | "withdraw" | "internal_out" | "internal_in"
+	Amount       string `json:"amount"`
+	Balance      string `json:"balance"`                // account balance after the transfer
+	Counterparty string `json:"counterparty,omitempty"` // other account of an internal transfer
+	Timestamp    int64  `json:"timestamp"`
+}
+
+// TransferHistoryService lists a trader's margin transfers, newest first
+type TransferHistoryService interface {
+	GetTransfers(ctx context.Context, trader string, limit int) ([]*Transfer, error)
+}
+
+// InternalTransferRequest moves free collateral to another platform account
+type InternalTransferRequest struct {
+	To     string `json:"to"`
+	Amount string `json:"amount"`
+}
+
+// InternalTransferService moves free collateral between platform accounts
+// at once. The returned transfer is the sender's side of it.
+type InternalTransferService interface {
+	InternalTransfer(ctx context.Context, trader string, req *InternalTransferRequest) (*Transfer, error)
+}
+
+// CollateralDebitService takes free collateral out of a trading account at
+// once, for moves into another platform ledger such as a pool. Unlike
+// Withdraw nothing is paid out, so the withdrawal timelock and allowlist do
+// not apply.
+type CollateralDebitService interface {
+	DebitCollateral(ctx context.Context, trader, amount string) (*AccountResponse, error)
+}
+
+// Account export statuses
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+)
+
+// AccountExport is an asynchronous export of a trader's account data as a
+// zip of JSON and CSV files. DownloadURL is set once the export completes
+// and stops working at ExpiresAt.
+type AccountExport struct {
+	ExportID    string         `json:"export_id"`
+	Trader      string         `json:"trader"`
+	Status      string         `json:"status"`
+	Error       string         `json:"error,omitempty"`
+	Records     map[string]int `json:"records,omitempty"` // dataset -> records exported
+	Size        int            `json:"size,omitempty"`    // zip size in bytes
+	DownloadURL string         `json:"download_url,omitempty"`
+	CreatedAt   int64          `json:"created_at"`
+	CompletedAt int64          `json:"completed_at,omitempty"`
+	ExpiresAt   int64          `json:"expires_at,omitempty"`
+}
+
+// AccountEventPublisher notifies a trader's webhook subscriptions of account events
+// (see package webhook for event names). Publishing never blocks on delivery.
+type AccountEventPublisher interface {
+	PublishAccountEvent(trader, event string, data interface{})
+}
+
+// CredentialObserver is told which trader each order was placed for with an
+// API key, so accounts operated with a shared key can be linked
+type CredentialObserver interface {
+	ObserveCredential(credential, trader string)
+}
+
+// OrderBookSnapshot represents aggregated book depth; each level is [price, quantity].
+// Checksum is the CRC32 of the returned levels (see orderbook types.DepthChecksum).
+type OrderBookSnapshot struct {
+	MarketID  string     `json:"market_id"`
+	Bids      [][]string `json:"bids"`
+	Asks      [][]string `json:"asks"`
+	Checksum  int32      `json:"checksum"`
+	Timestamp int64      `json:"timestamp"`
+}
+
+// MarketSnapshot is one market's ticker, funding, open interest and top
+// book levels in an exchange snapshot
+type MarketSnapshot struct {
+	MarketID     string     `json:"market_id"`
+	MarkPrice    string     `json:"mark_price"`
+	IndexPrice   string     `json:"index_price"`
+	LastPrice    string     `json:"last_price"`
+	FundingRate  string     `json:"funding_rate"`
+	NextFunding  int64      `json:"next_funding"` // Unix seconds, as in tickers
+	OpenInterest string     `json:"open_interest"`
+	Bids         [][]string `json:"bids"`
+	Asks         [][]string `json:"asks"`
+	Checksum     int32      `json:"checksum"`
+}
+
+// ExchangeSnapshot is every market's state read at one point: the books and
+// market state as of event Seq, so a client can apply the event log or the
+// WebSocket streams from Seq+1 on
+type ExchangeSnapshot struct {
+	Seq       uint64            `json:"seq"`
+	Depth     int               `json:"depth"`
+	Markets   []*MarketSnapshot `json:"markets"`
+	Timestamp int64             `json:"timestamp"`
+}
+
+// SnapshotService is implemented by services that can read all markets
+// under one lock. Price fields left empty are filled from the price oracle.
+type SnapshotService interface {
+	GetSnapshot(ctx context.Context, marketIDs []string, depth int) (*ExchangeSnapshot, error)
+}
+
+// MarketTrade represents a public trade in a market
+type MarketTrade struct {
+	TradeID   string `json:"trade_id"`
+	MarketID  string `json:"market_id"`
+	Price     string `json:"price"`
+	Quantity  string `json:"quantity"`
+	Side      string `json:"side"`
+	Timestamp int64  `json:"timestamp"`
+	Seq       uint64 `json:"seq,omitempty"`
+
+	// Set in the event log and trader trade history only
+	TakerOrderID string `json:"taker_order_id,omitempty"`
+	MakerOrderID string `json:"maker_order_id,omitempty"`
+
+	// Set in trader trade history only: "taker" or "maker"
+	Liquidity string `json:"liquidity,omitempty"`
+}
+
+// DepthBand is the resting quantity within a distance of the mid price
+type DepthBand struct {
+	Bps       int64  `json:"bps"`
+	BidDepth  string `json:"bid_depth"`
+	AskDepth  string `json:"ask_depth"`
+	Imbalance string `json:"imbalance"` // (bid - ask) / (bid + ask)
+}
+
+// MarketAnalytics represents the liquidity metrics of a market's order book
+type MarketAnalytics struct {
+	MarketID          string       `json:"market_id"`
+	BestBid           string       `json:"best_bid"`
+	BestAsk           string       `json:"best_ask"`
+	MidPrice          string       `json:"mid_price"`
+	Spread            string       `json:"spread"`
+	SpreadBps         string       `json:"spread_bps"`
+	Imbalance         string       `json:"imbalance"` // Top-of-book quantity imbalance
+	Depth             []*DepthBand `json:"depth"`
+	Volatility        string       `json:"volatility"` // RMS of trade-to-trade returns
+	VolatilitySamples int          `json:"volatility_samples"`
+	VolatilityWindow  int          `json:"volatility_window"`
+	Timestamp         int64        `json:"timestamp"`
+}
+
+// IndexSource is a constituent of a market's index price
+type IndexSource struct {
+	Source    string `json:"source"`
+	Price     string `json:"price"`
+	Weight    string `json:"weight"`   // Share of the index (0-1), "0" if excluded
+	Included  bool   `json:"included"` // False when filtered as an outlier
+	Timestamp int64  `json:"timestamp"`
+}
+
+// IndexPrice is the aggregated index price of a market with its basis
+type IndexPrice struct {
+	MarketID   string         `json:"market_id"`
+	IndexPrice string         `json:"index_price"`
+	MarkPrice  string         `json:"mark_price"`
+	Basis      string         `json:"basis"`      // mark - index
+	BasisRate  string         `json:"basis_rate"` // basis / index
+	Sources    []*IndexSource `json:"sources"`
+	Timestamp  int64          `json:"timestamp"`
+}
+
+// IndexPriceService provides the aggregated index price of a market
+type IndexPriceService interface {
+	GetIndexPrice(ctx context.Context, marketID string) (*IndexPrice, error)
+}
+
+// MarketDataService defines the interface for reading market data from the matching engine
+type MarketDataService interface {
+	GetOrderBook(ctx context.Context, marketID string, depth int) (*OrderBookSnapshot, error)
+	GetRecentTrades(ctx context.Context, marketID string, limit int) ([]*MarketTrade, error)
+	GetMarketAnalytics(ctx context.Context, marketID string) (*MarketAnalytics, error)
+}
+
+// OrderBooks is several markets' books in one response. Every book carries
+// the shared timestamp they were read at.
+type OrderBooks struct {
+	Depth     int                  `json:"depth"`
+	Books     []*OrderBookSnapshot `json:"books"`
+	Timestamp int64                `json:"timestamp"`
+}
+
+// OrderBooksService is implemented by market data services that can read
+// several books in one call, under one lock or in one round trip
+type OrderBooksService interface {
+	GetOrderBooks(ctx context.Context, marketIDs []string, depth int) (*OrderBooks, error)
+}
+
+// GetOrderBooks reads several markets' books from svc in one call when it
+// supports it, or one by one otherwise, stamping them all with one time
+func GetOrderBooks(ctx context.Context, svc MarketDataService, marketIDs []string, depth int) (*OrderBooks, error) {
+	if batch, ok := svc.(OrderBooksService); ok {
+		return batch.GetOrderBooks(ctx, marketIDs, depth)
+	}
+	books := &OrderBooks{
+		Depth:     depth,
+		Books:     make([]*OrderBookSnapshot, 0, len(marketIDs)),
+		Timestamp: time.Now().UnixMilli(),
+	}
+	for _, marketID := range marketIDs {
+		book, err := svc.GetOrderBook(ctx, marketID, depth)
+		if err != nil {
+			return nil, err
+		}
+		cp := *book
+		cp.Timestamp = books.Timestamp
+		books.Books = append(books.Books, &cp)
+	}
+	return books, nil
+}
+
+// Event types in the global event log
+const (
+	EventTypeOrder = "order"
+	EventTypeTrade = "trade"
+)
+
+// Event is an order update or trade from the engine's global event log.
+// Sequence numbers are strictly increasing across all markets.
+type Event struct {
+	Seq       uint64       `json:"seq"`
+	Type      string       `json:"type"` // EventTypeOrder or EventTypeTrade
+	MarketID  string       `json:"market_id"`
+	Order     *Order       `json:"order,omitempty"` // order state after the update
+	Trade     *MarketTrade `json:"trade,omitempty"`
+	Timestamp int64        `json:"timestamp"`
+}
+
+// EventsResponse is a page of the event log. NextSeq is the from_seq of the
+// next page; LastSeq is the latest sequence number assigned so far.
+type EventsResponse struct {
+	Events  []*Event `json:"events"`
+	NextSeq uint64   `json:"next_seq"`
+	LastSeq uint64   `json:"last_seq"`
+}
+
+// L3 (market-by-order) update actions
+const (
+	L3ActionAdd    = "add"    // the order rests on the book
+	L3ActionModify = "modify" // a resting order's price or remaining size changed
+	L3ActionCancel = "cancel" // a resting order was cancelled or expired
+	L3ActionFill   = "fill"   // a resting order was filled in full
+)
+
+// L3Update is a change to one resting order in the market-by-order feed. It
+// carries no trader identity. Seq is the event log sequence number of the
+// order update it was derived from.
+type L3Update struct {
+	Seq       uint64 `json:"seq"`
+	MarketID  string `json:"market_id"`
+	Action    string `json:"action"` // L3ActionAdd, L3ActionModify, L3ActionCancel or L3ActionFill
+	OrderID   string `json:"order_id"`
+	Side      string `json:"side"` // "buy" or "sell"
+	Price     string `json:"price"`
+	Size      string `json:"size"` // remaining size on the book; "0" once removed
+	Timestamp int64  `json:"timestamp"`
+}
+
+// L3UpdatesResponse is a page of the L3 feed. FirstSeq is the oldest update
+// still retained; a consumer whose from_seq is older has missed updates and
+// must rebuild from an order book snapshot.
+type L3UpdatesResponse struct {
+	Updates  []*L3Update `json:"updates"`
+	NextSeq  uint64      `json:"next_seq"`
+	FirstSeq uint64      `json:"first_seq"`
+}
+
+// EventService is implemented by services backed by the sequenced engine
+type EventService interface {
+	GetEvents(ctx context.Context, fromSeq uint64, limit int) (*EventsResponse, error)
+}
+
+// StoreHealthService is implemented by services backed by a local state
+// store, which /ready checks is readable
+type StoreHealthService interface {
+	CheckStore(ctx context.Context) error
+}
+
+// OrderExpiryService is implemented by services that expire good-till-date
+// orders themselves, outside a chain's EndBlocker
+type OrderExpiryService interface {
+	ExpireOrders(ctx context.Context) ([]*Order, error)
+}
+
+// MarginCall warns a trader that maintenance margin has reached Level of
+// their equity. A ratio of 1 or more is liquidatable.
+type MarginCall struct {
+	Trader            string `json:"trader"`
+	Level             string `json:"level"`        // threshold crossed, as a fraction of equity
+	MarginRatio       string `json:"margin_ratio"` // maintenance margin / equity
+	Equity            string `json:"equity"`
+	MaintenanceMargin string `json:"maintenance_margin"`
+	Positions         int    `json:"positions"`
+	Repeat            bool   `json:"repeat"` // true when re-sent at a level already notified
+	Timestamp         int64  `json:"timestamp"`
+}
+
+// MarginCallSettings is a trader's margin call preference and latest state
+type MarginCallSettings struct {
+	Trader       string   `json:"trader"`
+	OptOut       bool     `json:"opt_out"`
+	Levels       []string `json:"levels"`
+	RepeatAfter  int64    `json:"repeat_after"`            // millis between repeats at the same level
+	LastLevel    string   `json:"last_level,omitempty"`    // last level notified while still at or above it
+	LastNotified int64    `json:"last_notified,omitempty"` // Unix millis
+}
+
+// TradingView UDF datafeed statuses (see /v1/tv/history)
+const (
+	TVStatusOK     = "ok"
+	TVStatusNoData = "no_data"
+	TVStatusError  = "error"
+)
+
+// TVConfig is the TradingView UDF datafeed configuration
+type TVConfig struct {
+	SupportedResolutions   []string       `json:"supported_resolutions"`
+	SupportsGroupRequest   bool           `json:"supports_group_request"`
+	SupportsMarks          bool           `json:"supports_marks"`
+	SupportsSearch         bool           `json:"supports_search"`
+	SupportsTimescaleMarks bool           `json:"supports_timescale_marks"`
+	SupportsTime           bool           `json:"supports_time"`
+	Exchanges              []TVExchange   `json:"exchanges"`
+	SymbolsTypes           []TVSymbolType `json:"symbols_types"`
+}
+
+// TVExchange is an exchange filter in the TradingView symbol search
+type TVExchange struct {
+	Value string `json:"value"`
+	Name  string `json:"name"`
+	Desc  string `json:"desc"`
+}
+
+// TVSymbolType is a symbol type filter in the TradingView symbol search
+type TVSymbolType struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// TVSymbolInfo describes one market in TradingView UDF terms. PriceScale is
+// 10^decimals of the tick size, so MinMov/PriceScale is the minimum price move.
+type TVSymbolInfo struct {
+	Name                 string   `json:"name"`
+	Ticker               string   `json:"ticker"`
+	Description          string   `json:"description"`
+	Type                 string   `json:"type"`
+	Session              string   `json:"session"`
+	Timezone             string   `json:"timezone"`
+	Exchange             string   `json:"exchange"`
+	ListedExchange       string   `json:"listed_exchange"`
+	Format               string   `json:"format"`
+	MinMov               int      `json:"minmov"`
+	PriceScale           int64    `json:"pricescale"`
+	HasIntraday          bool     `json:"has_intraday"`
+	HasDaily             bool     `json:"has_daily"`
+	HasNoVolume          bool     `json:"has_no_volume"`
+	VolumePrecision      int      `json:"volume_precision"`
+	SupportedResolutions []string `json:"supported_resolutions"`
+	DataStatus           string   `json:"data_status"`
+}
+
+// TVHistory is a TradingView UDF bars response. Bars are column-oriented and
+// oldest first; times are Unix seconds. NextTime is only set with no_data,
+// pointing at the latest bar before the requested range.
+type TVHistory struct {
+	Status   string    `json:"s"`
+	ErrMsg   string    `json:"errmsg,omitempty"`
+	Time     []int64   `json:"t,omitempty"`
+	Open     []float64 `json:"o,omitempty"`
+	High     []float64 `json:"h,omitempty"`
+	Low      []float64 `json:"l,omitempty"`
+	Close    []float64 `json:"c,omitempty"`
+	Volume   []float64 `json:"v,omitempty"`
+	NextTime int64     `json:"nextTime,omitempty"`
+}
+
+// MarketActivity is a trader's trading and funding in one market over a
+// period
+type MarketActivity struct {
+	MarketID    string `json:"market_id"`
+	Trades      int    `json:"trades"`
+	Volume      string `json:"volume"` // traded notional, price × quantity
+	Fees        string `json:"fees"`   // USDC charged, net of rebates
+	RealizedPnl string `json:"realized_pnl"`
+	Funding     string `json:"funding"` // positive = received
+}
+
+// AccountActivity is a trader's activity over a period, per market
+type AccountActivity struct {
+	Trader  string            `json:"trader"`
+	From    int64             `json:"from"` // Unix millis, inclusive
+	To      int64             `json:"to"`   // Unix millis, exclusive
+	Markets []*MarketActivity `json:"markets"`
+}
+
+// AccountActivityService summarizes a trader's fills and funding payments
+// within [from, to)
+type AccountActivityService interface {
+	GetAccountActivity(ctx context.Context, trader string, from, to time.Time) (*AccountActivity, error)
+}
+
+// ReportPosition is an open position in an account report, valued at the
+// mark price
+type ReportPosition struct {
+	MarketID      string `json:"market_id"`
+	Side          string `json:"side"`
+	Size          string `json:"size"`
+	EntryPrice    string `json:"entry_price"`
+	MarkPrice     string `json:"mark_price"`
+	Notional      string `json:"notional"`
+	UnrealizedPnl string `json:"unrealized_pnl"`
+}
+
+// AccountReport is a trader's daily account summary. Activity covers the UTC
+// day [from, to); balance, equity and positions are as of generation.
+type AccountReport struct {
+	Trader        string            `json:"trader"`
+	Date          string            `json:"date"` // YYYY-MM-DD, UTC
+	From          int64             `json:"from"`
+	To            int64             `json:"to"`
+	GeneratedAt   int64             `json:"generated_at"`
+	Trades        int               `json:"trades"`
+	Volume        string            `json:"volume"`
+	Fees          string            `json:"fees"`
+	RealizedPnl   string            `json:"realized_pnl"`
+	Funding       string            `json:"funding"`
+	NetPnl        string            `json:"net_pnl"` // realized_pnl + funding - fees
+	Balance       string            `json:"balance"`
+	Equity        string            `json:"equity"`
+	UnrealizedPnl string            `json:"unrealized_pnl"`
+	Markets       []*MarketActivity `json:"markets"`
+	TopPositions  []*ReportPosition `json:"top_positions"` // largest notional first
+}
+
+// AccountReportSettings is a trader's daily report preference and the
+// reports kept for download
+type AccountReportSettings struct {
+	Trader  string   `json:"trader"`
+	Enabled bool     `json:"enabled"`
+	Webhook bool     `json:"webhook"` // subscribed to the daily_report webhook event
+	Reports []string `json:"reports"` // dates, newest first
+}
+
 // Helper function to get current timestamp in milliseconds
 func NowMillis() int64 {
 	return time.Now().UnixMilli()
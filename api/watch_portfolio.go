@@ -0,0 +1,156 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// handleWatchPortfolio handles GET /v1/portfolio?addresses=a,b,c: the
+// balances, positions and unrealized PnL of up to types.MaxPortfolioAddresses
+// addresses summed into one watch-only view. Every address is read from the
+// same snapshot, so the sums are taken at one height. Duplicate addresses
+// are counted once.
+func (s *Server) handleWatchPortfolio(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	snapshots, ok := s.accountService.(types.AccountSnapshotService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Portfolio aggregation is not supported by this service")
+		return
+	}
+
+	var addresses []string
+	seen := make(map[string]bool)
+	for _, address := range strings.Split(r.URL.Query().Get("addresses"), ",") {
+		if address = strings.TrimSpace(address); address == "" || seen[address] {
+			continue
+		}
+		seen[address] = true
+		addresses = append(addresses, address)
+	}
+	if len(addresses) == 0 {
+		writeError(w, types.ErrCodeMissingField, "addresses is required")
+		return
+	}
+	if len(addresses) > types.MaxPortfolioAddresses {
+		writeAPIError(w, types.NewAPIError(types.ErrCodeBatchTooLarge, "Too many addresses in one portfolio").
+			WithDetail("max", types.MaxPortfolioAddresses))
+		return
+	}
+
+	snapshot, err := snapshots.SnapshotAccounts(r.Context(), addresses)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	portfolio, err := newWatchPortfolio(addresses, snapshot)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, portfolio)
+}
+
+// portfolioMarketTotals accumulates the positions of several addresses in
+// one market
+type portfolioMarketTotals struct {
+	long, short, margin, pnl math.LegacyDec
+}
+
+// newWatchPortfolio sums the accounts of a snapshot
+func newWatchPortfolio(addresses []string, snapshot *types.AccountSnapshot) (*types.WatchPortfolio, error) {
+	balance, locked, available, pnl := math.LegacyZeroDec(), math.LegacyZeroDec(), math.LegacyZeroDec(), math.LegacyZeroDec()
+	markets := make(map[string]*portfolioMarketTotals)
+	openOrders := 0
+
+	for _, summary := range snapshot.Accounts {
+		accountBalance, err := math.LegacyNewDecFromStr(summary.Account.Balance)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balance of %s: %w", summary.Trader, err)
+		}
+		accountLocked, err := math.LegacyNewDecFromStr(summary.Account.LockedMargin)
+		if err != nil {
+			return nil, fmt.Errorf("invalid locked margin of %s: %w", summary.Trader, err)
+		}
+		accountAvailable, err := math.LegacyNewDecFromStr(summary.Account.AvailableBalance)
+		if err != nil {
+			return nil, fmt.Errorf("invalid available balance of %s: %w", summary.Trader, err)
+		}
+		balance = balance.Add(accountBalance)
+		locked = locked.Add(accountLocked)
+		available = available.Add(accountAvailable)
+		openOrders += summary.OpenOrders
+
+		for _, pos := range summary.Positions {
+			size, err := math.LegacyNewDecFromStr(pos.Size)
+			if err != nil {
+				return nil, fmt.Errorf("invalid size of %s in %s: %w", summary.Trader, pos.MarketID, err)
+			}
+			margin, err := math.LegacyNewDecFromStr(pos.Margin)
+			if err != nil {
+				return nil, fmt.Errorf("invalid margin of %s in %s: %w", summary.Trader, pos.MarketID, err)
+			}
+			upnl, err := math.LegacyNewDecFromStr(pos.UnrealizedPnl)
+			if err != nil {
+				return nil, fmt.Errorf("invalid unrealized PnL of %s in %s: %w", summary.Trader, pos.MarketID, err)
+			}
+
+			totals, ok := markets[pos.MarketID]
+			if !ok {
+				totals = &portfolioMarketTotals{
+					long: math.LegacyZeroDec(), short: math.LegacyZeroDec(),
+					margin: math.LegacyZeroDec(), pnl: math.LegacyZeroDec(),
+				}
+				markets[pos.MarketID] = totals
+			}
+			if pos.Side == "short" {
+				totals.short = totals.short.Add(size)
+			} else {
+				totals.long = totals.long.Add(size)
+			}
+			totals.margin = totals.margin.Add(margin)
+			totals.pnl = totals.pnl.Add(upnl)
+			pnl = pnl.Add(upnl)
+		}
+	}
+
+	portfolio := &types.WatchPortfolio{
+		Addresses:        addresses,
+		Height:           snapshot.Height,
+		Balance:          balance.String(),
+		LockedMargin:     locked.String(),
+		AvailableBalance: available.String(),
+		UnrealizedPnl:    pnl.String(),
+		Equity:           balance.Add(pnl).String(),
+		OpenOrders:       openOrders,
+		Markets:          make([]*types.PortfolioMarket, 0, len(markets)),
+		Accounts:         snapshot.Accounts,
+		Timestamp:        types.NowMillis(),
+	}
+	for marketID, totals := range markets {
+		portfolio.Markets = append(portfolio.Markets, &types.PortfolioMarket{
+			MarketID:      marketID,
+			LongSize:      totals.long.String(),
+			ShortSize:     totals.short.String(),
+			NetSize:       totals.long.Sub(totals.short).String(),
+			Margin:        totals.margin.String(),
+			UnrealizedPnl: totals.pnl.String(),
+		})
+	}
+	sort.Slice(portfolio.Markets, func(i, j int) bool {
+		return portfolio.Markets[i].MarketID < portfolio.Markets[j].MarketID
+	})
+	return portfolio, nil
+}
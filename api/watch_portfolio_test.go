@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// TestWatchPortfolio tests that balances, positions and PnL of several
+// addresses are summed once per address, with offsetting positions netted
+func TestWatchPortfolio(t *testing.T) {
+	svc := NewMockService()
+	ctx := context.Background()
+	for trader, amount := range map[string]string{"hot": "500", "cold": "300"} {
+		if _, err := svc.Deposit(ctx, &types.DepositRequest{Trader: trader, Amount: amount}); err != nil {
+			t.Fatalf("failed to deposit: %v", err)
+		}
+	}
+	svc.positions["hot:BTC-USDC"] = &types.Position{MarketID: "BTC-USDC", Trader: "hot", Side: "long", Size: "1", Margin: "100", UnrealizedPnl: "10"}
+	svc.positions["cold:BTC-USDC"] = &types.Position{MarketID: "BTC-USDC", Trader: "cold", Side: "short", Size: "0.4", Margin: "40", UnrealizedPnl: "-4"}
+	svc.positions["cold:ETH-USDC"] = &types.Position{MarketID: "ETH-USDC", Trader: "cold", Side: "long", Size: "2", Margin: "50", UnrealizedPnl: "6"}
+	s := &Server{accountService: svc}
+
+	query := func(addresses string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.handleWatchPortfolio(rec, httptest.NewRequest(http.MethodGet, "/v1/portfolio?addresses="+addresses, nil))
+		return rec
+	}
+
+	rec := query("hot,%20cold,hot,")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var portfolio types.WatchPortfolio
+	if err := json.Unmarshal(rec.Body.Bytes(), &portfolio); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(portfolio.Addresses) != 2 || len(portfolio.Accounts) != 2 {
+		t.Fatalf("expected hot and cold once each, got %v", portfolio.Addresses)
+	}
+	decEqual := func(field, got, want string) {
+		t.Helper()
+		if d, err := math.LegacyNewDecFromStr(got); err != nil || !d.Equal(math.LegacyMustNewDecFromStr(want)) {
+			t.Errorf("expected %s %s, got %s", field, want, got)
+		}
+	}
+	decEqual("balance", portfolio.Balance, "800")
+	decEqual("unrealized PnL", portfolio.UnrealizedPnl, "12")
+	decEqual("equity", portfolio.Equity, "812")
+	if len(portfolio.Markets) != 2 || portfolio.Markets[0].MarketID != "BTC-USDC" {
+		t.Fatalf("expected BTC then ETH, got %+v", portfolio.Markets)
+	}
+	decEqual("BTC net size", portfolio.Markets[0].NetSize, "0.6")
+	decEqual("BTC margin", portfolio.Markets[0].Margin, "140")
+	decEqual("ETH long size", portfolio.Markets[1].LongSize, "2")
+
+	if rec := query(""); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without addresses, got %d", rec.Code)
+	}
+	addresses := make([]string, types.MaxPortfolioAddresses+1)
+	for i := range addresses {
+		addresses[i] = strings.Repeat("a", i+1)
+	}
+	if rec := query(strings.Join(addresses, ",")); rec.Code == http.StatusOK {
+		t.Errorf("expected too many addresses to be rejected")
+	}
+}
@@ -0,0 +1,683 @@
+// Package webhook delivers account events to trader-registered HTTPS callbacks.
+//
+// Each delivery is a JSON Event POSTed to the subscription URL and signed with
+// the subscription secret. The X-PerpDEX-Signature header is
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">". Failed deliveries
+// are retried with exponential backoff until MaxAttempts is reached.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Account events a subscription can receive
+const (
+	EventFill                = "fill"
+	EventLiquidationWarning  = "liquidation_warning"
+	EventMarginCall          = "margin_call"
+	EventFundingPayment      = "funding_payment"
+	EventWithdrawalRequested = "withdrawal_requested"
+	EventWithdrawalCompleted = "withdrawal_completed"
+	EventDailyReport         = "daily_report"
+)
+
+// Events lists every supported event
+var Events = []string{EventFill, EventLiquidationWarning, EventMarginCall, EventFundingPayment, EventWithdrawalRequested, EventWithdrawalCompleted, EventDailyReport}
+
+// Delivery request headers
+const (
+	HeaderSignature = "X-PerpDEX-Signature"
+	HeaderEvent     = "X-PerpDEX-Event"
+	HeaderDelivery  = "X-PerpDEX-Delivery"
+)
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed" // retries exhausted
+)
+
+const (
+	DefaultMaxAttempts      = 8
+	DefaultBaseBackoff      = 5 * time.Second
+	DefaultMaxBackoff       = 30 * time.Minute
+	DefaultRequestTimeout   = 10 * time.Second
+	DefaultWorkers          = 4
+	DefaultMaxSubscriptions = 10  // per trader
+	DefaultDeliveryHistory  = 100 // finished deliveries kept per subscription
+
+	// pollInterval bounds how late a retry can be picked up
+	pollInterval = time.Second
+)
+
+// Webhook errors
+var (
+	ErrInvalidURL           = errors.New("invalid webhook URL")
+	ErrInvalidEvent         = errors.New("unknown webhook event")
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrSubscriptionLimit    = errors.New("webhook subscription limit reached")
+)
+
+// Subscription is a trader's registered callback
+type Subscription struct {
+	ID        string   `json:"id"`
+	Trader    string   `json:"trader"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	CreatedAt int64    `json:"created_at"`
+
+	secret string
+}
+
+// wants returns true if the subscription receives the event
+func (s *Subscription) wants(event string) bool {
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is the JSON body of a delivery. ID is shared by the deliveries of the
+// same event to different subscriptions, so receivers can deduplicate.
+type Event struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	Trader    string      `json:"trader"`
+	Timestamp int64       `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// Delivery tracks the attempts to deliver one event to one subscription
+type Delivery struct {
+	ID             string `json:"id"`
+	SubscriptionID string `json:"subscription_id"`
+	EventID        string `json:"event_id"`
+	Event          string `json:"event"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	ResponseStatus int    `json:"response_status,omitempty"` // HTTP status of the last attempt
+	LastError      string `json:"last_error,omitempty"`
+	CreatedAt      int64  `json:"created_at"`
+	NextAttemptAt  int64  `json:"next_attempt_at,omitempty"`
+	DeliveredAt    int64  `json:"delivered_at,omitempty"`
+
+	body     []byte
+	inFlight bool
+}
+
+// Config contains dispatcher configuration
+type Config struct {
+	MaxAttempts      int           // Attempts per delivery, including the first
+	BaseBackoff      time.Duration // Delay before the first retry; doubles each attempt
+	MaxBackoff       time.Duration // Cap on the retry delay
+	RequestTimeout   time.Duration // Timeout of a single delivery request
+	Workers          int           // Concurrent delivery requests
+	MaxSubscriptions int           // Subscriptions per trader
+	DeliveryHistory  int           // Finished deliveries kept per subscription
+	AllowInsecure    bool          // Accept http:// and private addresses; development only
+	HTTPClient       *http.Client  // Its transport must be an *http.Transport for addresses to be checked at dial time
+}
+
+// Dispatcher stores subscriptions and delivers events to them in the background
+type Dispatcher struct {
+	config Config
+	client *http.Client
+	now    func() time.Time
+
+	mu            sync.Mutex
+	subscriptions map[string]*Subscription
+	byTrader      map[string][]string // trader -> subscription IDs in creation order
+	deliveries    map[string][]*Delivery
+	wake          chan struct{}
+}
+
+// NewDispatcher creates a new Dispatcher. Call Run to start delivering.
+func NewDispatcher(config Config) *Dispatcher {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = DefaultBaseBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.RequestTimeout <= 0 {
+		config.RequestTimeout = DefaultRequestTimeout
+	}
+	if config.Workers <= 0 {
+		config.Workers = DefaultWorkers
+	}
+	if config.MaxSubscriptions <= 0 {
+		config.MaxSubscriptions = DefaultMaxSubscriptions
+	}
+	if config.DeliveryHistory <= 0 {
+		config.DeliveryHistory = DefaultDeliveryHistory
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{}
+	}
+	// Redirects are treated as failures rather than followed to an unchecked host
+	c := *client
+	c.Timeout = config.RequestTimeout
+	c.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	if !config.AllowInsecure {
+		c.Transport = publicTransport(c.Transport)
+	}
+
+	return &Dispatcher{
+		config:        config,
+		client:        &c,
+		now:           time.Now,
+		subscriptions: make(map[string]*Subscription),
+		byTrader:      make(map[string][]string),
+		deliveries:    make(map[string][]*Delivery),
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// Sign returns the signature header value for a body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(signature(secret, timestamp, body)))
+}
+
+// Verify checks a signature header against the body. Receivers should also
+// reject timestamps too far from their own clock to prevent replays.
+func Verify(secret, header string, body []byte) (time.Time, bool) {
+	var (
+		timestamp int64
+		sig       []byte
+		err       error
+	)
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp, err = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			sig, err = hex.DecodeString(value)
+		}
+		if err != nil {
+			return time.Time{}, false
+		}
+	}
+	if timestamp == 0 || sig == nil {
+		return time.Time{}, false
+	}
+	return time.Unix(timestamp, 0), hmac.Equal(sig, signature(secret, timestamp, body))
+}
+
+func signature(secret string, timestamp int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// Subscribe registers a callback URL for a trader and returns the subscription
+// with its signing secret. The secret is only ever returned here. An empty
+// event list subscribes to every event.
+func (d *Dispatcher) Subscribe(trader, rawURL string, events []string) (*Subscription, string, error) {
+	if err := d.validateURL(rawURL); err != nil {
+		return nil, "", err
+	}
+	events, err := normalizeEvents(events)
+	if err != nil {
+		return nil, "", err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.byTrader[trader]) >= d.config.MaxSubscriptions {
+		return nil, "", fmt.Errorf("%w: at most %d per account", ErrSubscriptionLimit, d.config.MaxSubscriptions)
+	}
+
+	sub := &Subscription{
+		ID:        "wh_" + randomHex(12),
+		Trader:    trader,
+		URL:       rawURL,
+		Events:    events,
+		CreatedAt: d.now().UnixMilli(),
+		secret:    "whsec_" + randomHex(32),
+	}
+	d.subscriptions[sub.ID] = sub
+	d.byTrader[trader] = append(d.byTrader[trader], sub.ID)
+	copied := *sub
+	return &copied, sub.secret, nil
+}
+
+// Subscriptions returns a trader's subscriptions in creation order
+func (d *Dispatcher) Subscriptions(trader string) []*Subscription {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	result := make([]*Subscription, 0, len(d.byTrader[trader]))
+	for _, id := range d.byTrader[trader] {
+		copied := *d.subscriptions[id]
+		result = append(result, &copied)
+	}
+	return result
+}
+
+// Subscription returns one of a trader's subscriptions
+func (d *Dispatcher) Subscription(trader, id string) (*Subscription, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	sub, err := d.lookup(trader, id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *sub
+	return &copied, nil
+}
+
+// Unsubscribe removes a subscription and drops its pending deliveries
+func (d *Dispatcher) Unsubscribe(trader, id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, err := d.lookup(trader, id); err != nil {
+		return err
+	}
+	delete(d.subscriptions, id)
+	delete(d.deliveries, id)
+	ids := d.byTrader[trader]
+	for i, subID := range ids {
+		if subID == id {
+			d.byTrader[trader] = append(ids[:i:i], ids[i+1:]...)
+			break
+		}
+	}
+	if len(d.byTrader[trader]) == 0 {
+		delete(d.byTrader, trader)
+	}
+	return nil
+}
+
+// Deliveries returns a subscription's deliveries, newest first, optionally
+// filtered by status
+func (d *Dispatcher) Deliveries(trader, id, status string, limit int) ([]*Delivery, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, err := d.lookup(trader, id); err != nil {
+		return nil, err
+	}
+
+	deliveries := d.deliveries[id]
+	result := make([]*Delivery, 0, len(deliveries))
+	for i := len(deliveries) - 1; i >= 0; i-- {
+		if status != "" && deliveries[i].Status != status {
+			continue
+		}
+		copied := *deliveries[i]
+		result = append(result, &copied)
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// HasSubscribers returns true if any of the trader's subscriptions receive the event
+func (d *Dispatcher) HasSubscribers(trader, event string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, id := range d.byTrader[trader] {
+		if d.subscriptions[id].wants(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Traders returns the traders subscribed to the event
+func (d *Dispatcher) Traders(event string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var traders []string
+	for trader, ids := range d.byTrader {
+		for _, id := range ids {
+			if d.subscriptions[id].wants(event) {
+				traders = append(traders, trader)
+				break
+			}
+		}
+	}
+	sort.Strings(traders)
+	return traders
+}
+
+// PublishAccountEvent queues the event for every subscription of the trader
+// that receives it. It never blocks on delivery.
+func (d *Dispatcher) PublishAccountEvent(trader, event string, data interface{}) {
+	now := d.now()
+	eventID := "evt_" + randomHex(12)
+	body, err := json.Marshal(&Event{
+		ID:        eventID,
+		Event:     event,
+		Trader:    trader,
+		Timestamp: now.UnixMilli(),
+		Data:      data,
+	})
+	if err != nil {
+		return
+	}
+
+	d.mu.Lock()
+	queued := false
+	for _, id := range d.byTrader[trader] {
+		if !d.subscriptions[id].wants(event) {
+			continue
+		}
+		d.deliveries[id] = append(d.deliveries[id], &Delivery{
+			ID:             "whd_" + randomHex(12),
+			SubscriptionID: id,
+			EventID:        eventID,
+			Event:          event,
+			Status:         StatusPending,
+			CreatedAt:      now.UnixMilli(),
+			NextAttemptAt:  now.UnixMilli(),
+			body:           body,
+		})
+		d.trimHistory(id)
+		queued = true
+	}
+	d.mu.Unlock()
+
+	if queued {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run delivers due events until stop is closed
+func (d *Dispatcher) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sem := make(chan struct{}, d.config.Workers)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+
+		for _, delivery := range d.due() {
+			select {
+			case sem <- struct{}{}:
+			case <-stop:
+				d.release(delivery)
+				return
+			}
+			wg.Add(1)
+			go func(delivery *Delivery) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				d.attempt(ctx, delivery)
+			}(delivery)
+		}
+	}
+}
+
+// due claims the pending deliveries whose next attempt has come
+func (d *Dispatcher) due() []*Delivery {
+	now := d.now().UnixMilli()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var result []*Delivery
+	for _, deliveries := range d.deliveries {
+		for _, delivery := range deliveries {
+			if delivery.Status == StatusPending && !delivery.inFlight && delivery.NextAttemptAt <= now {
+				delivery.inFlight = true
+				result = append(result, delivery)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].NextAttemptAt < result[j].NextAttemptAt })
+	return result
+}
+
+func (d *Dispatcher) release(delivery *Delivery) {
+	d.mu.Lock()
+	delivery.inFlight = false
+	d.mu.Unlock()
+}
+
+// attempt sends one delivery request and records the outcome
+func (d *Dispatcher) attempt(ctx context.Context, delivery *Delivery) {
+	d.mu.Lock()
+	sub, ok := d.subscriptions[delivery.SubscriptionID]
+	if !ok {
+		d.mu.Unlock()
+		return
+	}
+	target, secret := sub.URL, sub.secret
+	d.mu.Unlock()
+
+	statusCode, err := d.send(ctx, target, secret, delivery)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delivery.inFlight = false
+	delivery.Attempts++
+	delivery.ResponseStatus = statusCode
+	now := d.now()
+	if err == nil {
+		delivery.Status = StatusDelivered
+		delivery.LastError = ""
+		delivery.NextAttemptAt = 0
+		delivery.DeliveredAt = now.UnixMilli()
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.config.MaxAttempts {
+		delivery.Status = StatusFailed
+		delivery.NextAttemptAt = 0
+		return
+	}
+	delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts)).UnixMilli()
+}
+
+// send POSTs the signed event; any non-2xx response is an error
+func (d *Dispatcher) send(ctx context.Context, target, secret string, delivery *Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(delivery.body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PerpDEX-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderSignature, Sign(secret, d.now().Unix(), delivery.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// trimHistory drops the oldest finished deliveries beyond the history limit
+func (d *Dispatcher) trimHistory(id string) {
+	deliveries := d.deliveries[id]
+	excess := len(deliveries) - d.config.DeliveryHistory
+	if excess <= 0 {
+		return
+	}
+	kept := deliveries[:0]
+	for _, delivery := range deliveries {
+		if excess > 0 && delivery.Status != StatusPending {
+			excess--
+			continue
+		}
+		kept = append(kept, delivery)
+	}
+	d.deliveries[id] = kept
+}
+
+// lookup returns a subscription owned by the trader. Other traders'
+// subscriptions are reported as not found.
+func (d *Dispatcher) lookup(trader, id string) (*Subscription, error) {
+	sub, ok := d.subscriptions[id]
+	if !ok || sub.Trader != trader {
+		return nil, ErrSubscriptionNotFound
+	}
+	return sub, nil
+}
+
+// validateURL requires an absolute https URL that does not point at a
+// loopback or private address
+func (d *Dispatcher) validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%w: must be an absolute URL", ErrInvalidURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials are not allowed in the URL", ErrInvalidURL)
+	}
+	if d.config.AllowInsecure {
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return fmt.Errorf("%w: scheme must be http or https", ErrInvalidURL)
+		}
+		return nil
+	}
+
+	if u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be https", ErrInvalidURL)
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("%w: host must be public", ErrInvalidURL)
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return fmt.Errorf("%w: host must be public", ErrInvalidURL)
+	}
+	return nil
+}
+
+// isPublicIP reports whether ip is neither loopback, private (RFC 1918 and
+// fc00::/7), link-local, which includes the 169.254.169.254 metadata
+// service, nor unspecified
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsUnspecified()
+}
+
+// publicTransport returns a copy of rt that refuses to connect to non-public
+// addresses. validateURL only sees the host name, which may resolve to a
+// private address at delivery time; the dialer checks the address actually
+// connected to. Proxies are disabled, since a proxy would resolve the host
+// itself. A transport that is not an *http.Transport is returned unchanged.
+func publicTransport(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		return rt
+	}
+	transport := base.Clone()
+	transport.Proxy = nil
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkDialAddress,
+	}
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// checkDialAddress is a net.Dialer Control hook rejecting connections to
+// non-public addresses
+func checkDialAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s is not a public address", ErrInvalidURL, host)
+	}
+	return nil
+}
+
+// normalizeEvents validates and deduplicates an event list; empty means all events
+func normalizeEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return append([]string(nil), Events...), nil
+	}
+	seen := make(map[string]bool, len(events))
+	result := make([]string, 0, len(events))
+	for _, event := range events {
+		if !isEvent(event) {
+			return nil, fmt.Errorf("%w %q, expected one of %s", ErrInvalidEvent, event, strings.Join(Events, ", "))
+		}
+		if !seen[event] {
+			seen[event] = true
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func isEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
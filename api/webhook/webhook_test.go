@@ -0,0 +1,184 @@
+package webhook
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForStatus polls a subscription until its latest delivery reaches status
+func waitForStatus(t *testing.T, d *Dispatcher, trader, id, status string) *Delivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		deliveries, err := d.Deliveries(trader, id, "", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deliveries) == 1 && deliveries[0].Status == status {
+			return deliveries[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("delivery did not reach status %s", status)
+	return nil
+}
+
+// TestSignAndVerify tests the signature header round trip
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event":"fill"}`)
+	header := Sign("whsec_test", 1700000000, body)
+
+	ts, ok := Verify("whsec_test", header, body)
+	if !ok || ts.Unix() != 1700000000 {
+		t.Fatalf("expected valid signature at 1700000000, got %v %v", ok, ts)
+	}
+	if _, ok := Verify("whsec_other", header, body); ok {
+		t.Error("expected signature with wrong secret to fail")
+	}
+	if _, ok := Verify("whsec_test", header, []byte(`{"event":"funding_payment"}`)); ok {
+		t.Error("expected signature over modified body to fail")
+	}
+	if _, ok := Verify("whsec_test", "v1=00", body); ok {
+		t.Error("expected header without timestamp to fail")
+	}
+}
+
+// TestSubscribeValidation tests URL, event and ownership checks
+func TestSubscribeValidation(t *testing.T) {
+	d := NewDispatcher(Config{MaxSubscriptions: 1})
+
+	for _, u := range []string{"http://example.com/hook", "https://localhost/hook", "https://10.0.0.1/hook", "/hook"} {
+		if _, _, err := d.Subscribe("trader1", u, nil); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("%s: expected ErrInvalidURL, got %v", u, err)
+		}
+	}
+	if _, _, err := d.Subscribe("trader1", "https://example.com/hook", []string{"trade"}); !errors.Is(err, ErrInvalidEvent) {
+		t.Errorf("expected ErrInvalidEvent, got %v", err)
+	}
+
+	sub, secret, err := d.Subscribe("trader1", "https://example.com/hook", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret == "" || len(sub.Events) != len(Events) {
+		t.Errorf("expected secret and all events, got %q %v", secret, sub.Events)
+	}
+	if _, _, err := d.Subscribe("trader1", "https://example.com/other", nil); !errors.Is(err, ErrSubscriptionLimit) {
+		t.Errorf("expected ErrSubscriptionLimit, got %v", err)
+	}
+
+	if _, err := d.Subscription("trader2", sub.ID); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("expected other trader's subscription to be hidden, got %v", err)
+	}
+	if err := d.Unsubscribe("trader1", sub.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Subscriptions("trader1")) != 0 {
+		t.Error("expected no subscriptions after unsubscribe")
+	}
+}
+
+// TestDialAddressCheck tests that the dispatcher's transport refuses to
+// connect to loopback, private and metadata addresses whatever the host name
+// resolved to
+func TestDialAddressCheck(t *testing.T) {
+	for address, public := range map[string]bool{
+		"127.0.0.1:443":      false,
+		"10.1.2.3:443":       false,
+		"172.16.0.1:443":     false,
+		"192.168.1.1:443":    false,
+		"169.254.169.254:80": false,
+		"[::1]:443":          false,
+		"[fd00::1]:443":      false,
+		"0.0.0.0:443":        false,
+		"93.184.216.34:443":  true,
+		"[2606:4700::1]:443": true,
+	} {
+		if err := checkDialAddress("tcp", address, nil); (err == nil) != public {
+			t.Errorf("%s: expected public=%v, got %v", address, public, err)
+		}
+	}
+
+	var hits int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer srv.Close()
+
+	d := NewDispatcher(Config{HTTPClient: srv.Client()})
+	if _, err := d.client.Get(srv.URL); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("expected the loopback connection refused, got %v", err)
+	}
+	insecure := NewDispatcher(Config{HTTPClient: srv.Client(), AllowInsecure: true})
+	resp, err := insecure.client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("expected AllowInsecure to connect, got %v", err)
+	}
+	resp.Body.Close()
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("expected one request to reach the server, got %d", hits)
+	}
+}
+
+// TestDeliveryAndRetry tests signed delivery, retry after failure and giving up
+func TestDeliveryAndRetry(t *testing.T) {
+	var calls, failFirst atomic.Int32
+	failFirst.Store(1)
+	var secret string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if _, ok := Verify(secret, r.Header.Get(HeaderSignature), body); !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/down" || failFirst.CompareAndSwap(1, 0) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	d := NewDispatcher(Config{
+		MaxAttempts:   3,
+		BaseBackoff:   time.Millisecond,
+		AllowInsecure: true, // httptest listens on loopback
+		HTTPClient:    srv.Client(),
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	go d.Run(stop)
+
+	sub, s, err := d.Subscribe("trader1", srv.URL+"/ok", []string{EventFill})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret = s
+
+	d.PublishAccountEvent("trader1", EventWithdrawalCompleted, nil) // not subscribed
+	d.PublishAccountEvent("trader1", EventFill, map[string]string{"trade_id": "t1"})
+	delivered := waitForStatus(t, d, "trader1", sub.ID, StatusDelivered)
+	if delivered.Attempts != 2 || delivered.ResponseStatus != http.StatusNoContent {
+		t.Errorf("expected delivery on second attempt, got %+v", delivered)
+	}
+	if all, _ := d.Deliveries("trader1", sub.ID, "", 0); len(all) != 1 {
+		t.Errorf("expected only the fill to be queued, got %d deliveries", len(all))
+	}
+
+	down, s, err := d.Subscribe("trader2", srv.URL+"/down", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret = s
+	d.PublishAccountEvent("trader2", EventFundingPayment, nil)
+	failed := waitForStatus(t, d, "trader2", down.ID, StatusFailed)
+	if failed.Attempts != 3 || failed.LastError == "" {
+		t.Errorf("expected 3 failed attempts, got %+v", failed)
+	}
+}
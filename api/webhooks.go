@@ -0,0 +1,250 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/api/webhook"
+)
+
+const (
+	// accountEventWatchInterval is how often positions and funding payments of
+	// webhook subscribers are checked
+	accountEventWatchInterval = 10 * time.Second
+
+	// fundingPaymentLookback is the number of recent payments compared between checks
+	fundingPaymentLookback = 50
+
+	// maxDeliveriesLimit caps the deliveries returned per request
+	maxDeliveriesLimit = 100
+)
+
+// liquidationWarningDistance is how close the mark price may get to the
+// liquidation price, as a fraction of the mark price, before a warning is sent
+var liquidationWarningDistance = math.LegacyNewDecWithPrec(5, 2)
+
+// webhookTrader returns the trader the request acts for
+func webhookTrader(r *http.Request) string {
+	if trader := r.Header.Get("X-Trader-Address"); trader != "" {
+		return trader
+	}
+	return r.URL.Query().Get("trader")
+}
+
+// handleWebhooks handles /v1/account/webhooks (GET list, POST create)
+func (s *Server) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		trader := webhookTrader(r)
+		if trader == "" {
+			writeError(w, types.ErrCodeMissingField, "trader address is required")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"webhooks": s.webhooks.Subscriptions(trader),
+		})
+
+	case http.MethodPost:
+		var req types.CreateWebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		if req.URL == "" {
+			writeError(w, types.ErrCodeMissingField, "url is required")
+			return
+		}
+		if req.Trader == "" {
+			req.Trader = webhookTrader(r)
+		}
+		if req.Trader == "" {
+			writeError(w, types.ErrCodeMissingField, "trader address is required")
+			return
+		}
+
+		sub, secret, err := s.webhooks.Subscribe(req.Trader, req.URL, req.Events)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]interface{}{
+			"webhook": sub,
+			"secret":  secret,
+		})
+
+	case http.MethodOptions:
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleWebhook handles /v1/account/webhooks/{id} (GET, DELETE) and
+// GET /v1/account/webhooks/{id}/deliveries
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	id, endpoint, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v1/account/webhooks/"), "/")
+	if id == "" {
+		writeError(w, types.ErrCodeMissingField, "Webhook ID is required")
+		return
+	}
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return
+	}
+
+	switch {
+	case endpoint == "" && r.Method == http.MethodGet:
+		sub, err := s.webhooks.Subscription(trader, id)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"webhook": sub})
+
+	case endpoint == "" && r.Method == http.MethodDelete:
+		if err := s.webhooks.Unsubscribe(trader, id); err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":      id,
+			"deleted": true,
+		})
+
+	case endpoint == "deliveries" && r.Method == http.MethodGet:
+		status := r.URL.Query().Get("status")
+		switch status {
+		case "", webhook.StatusPending, webhook.StatusDelivered, webhook.StatusFailed:
+		default:
+			writeError(w, types.ErrCodeInvalidRequest, "status must be one of pending, delivered, failed")
+			return
+		}
+		limit := 50
+		if l := r.URL.Query().Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+				limit = parsed
+			}
+		}
+		if limit > maxDeliveriesLimit {
+			limit = maxDeliveriesLimit
+		}
+
+		deliveries, err := s.webhooks.Deliveries(trader, id, status, limit)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"webhook_id": id,
+			"deliveries": deliveries,
+		})
+
+	case endpoint == "" || endpoint == "deliveries":
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+
+	default:
+		writeError(w, types.ErrCodeNotFound, "Endpoint not found")
+	}
+}
+
+// startAccountEventWatcher publishes liquidation warnings and funding payments
+// to webhook subscribers. Fills and withdrawals are published by the handlers.
+func (s *Server) startAccountEventWatcher() {
+	ticker := time.NewTicker(accountEventWatchInterval)
+	defer ticker.Stop()
+
+	funding, _ := s.positionService.(types.FundingPaymentService)
+	warned := make(map[string]bool)                  // trader/market pairs already warned
+	seenPayments := make(map[string]map[string]bool) // trader -> recent payment IDs
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		ctx := context.Background()
+		for _, trader := range s.webhooks.Traders(webhook.EventLiquidationWarning) {
+			s.checkLiquidationWarnings(ctx, trader, warned)
+		}
+		if funding == nil {
+			continue
+		}
+		for _, trader := range s.webhooks.Traders(webhook.EventFundingPayment) {
+			seenPayments[trader] = s.publishFundingPayments(ctx, funding, trader, seenPayments[trader])
+		}
+	}
+}
+
+// checkLiquidationWarnings warns once per position when its mark price comes
+// within liquidationWarningDistance of the liquidation price. The warning is
+// re-armed once the position moves back out of range.
+func (s *Server) checkLiquidationWarnings(ctx context.Context, trader string, warned map[string]bool) {
+	positions, err := s.positionService.GetPositions(ctx, trader)
+	if err != nil {
+		return
+	}
+	for _, pos := range positions {
+		key := trader + "/" + pos.MarketID
+		mark, err := math.LegacyNewDecFromStr(pos.MarkPrice)
+		if err != nil || !mark.IsPositive() {
+			continue
+		}
+		liq, err := math.LegacyNewDecFromStr(pos.LiquidationPrice)
+		if err != nil || !liq.IsPositive() {
+			continue
+		}
+
+		distance := mark.Sub(liq).Abs().Quo(mark)
+		if distance.GT(liquidationWarningDistance) {
+			delete(warned, key)
+			continue
+		}
+		if warned[key] {
+			continue
+		}
+		warned[key] = true
+		s.webhooks.PublishAccountEvent(trader, webhook.EventLiquidationWarning, map[string]interface{}{
+			"market_id":         pos.MarketID,
+			"side":              pos.Side,
+			"size":              pos.Size,
+			"mark_price":        pos.MarkPrice,
+			"liquidation_price": pos.LiquidationPrice,
+			"margin":            pos.Margin,
+			"distance":          distance.String(),
+		})
+	}
+}
+
+// publishFundingPayments publishes payments not seen in the previous check and
+// returns the IDs seen in this one. The first check only records the backlog.
+func (s *Server) publishFundingPayments(ctx context.Context, funding types.FundingPaymentService, trader string, seen map[string]bool) map[string]bool {
+	payments, err := funding.GetFundingPayments(ctx, trader, fundingPaymentLookback)
+	if err != nil {
+		return seen
+	}
+	current := make(map[string]bool, len(payments))
+	for i := len(payments) - 1; i >= 0; i-- {
+		p := payments[i]
+		current[p.PaymentID] = true
+		if seen != nil && !seen[p.PaymentID] {
+			s.webhooks.PublishAccountEvent(trader, webhook.EventFundingPayment, p)
+		}
+	}
+	return current
+}
@@ -0,0 +1,366 @@
+package websocket
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Subscription formats. Clients opt into binary frames per channel by
+// subscribing with {"action":"subscribe","channel":"depth:BTC-USDC","format":"binary"}.
+const (
+	FormatJSON   = "json"
+	FormatBinary = "binary"
+)
+
+// Binary frame layout (version BinaryVersion). All integers are varints as
+// written by encoding/binary; signed values use zigzag encoding.
+//
+//	byte     frame type (binaryFrameDepth or binaryFrameTrade)
+//	depth:   string market_id, varint timestamp, int32 checksum (little endian),
+//	         uvarint bid count, bids, uvarint ask count, asks
+//	level:   decimal price, decimal quantity
+//	trade:   string market_id, string trade_id, decimal price, decimal quantity,
+//	         byte side (0 buy, 1 sell), varint timestamp, uvarint seq (0 if unsequenced)
+//	string:  uvarint length, bytes
+//	decimal: byte scale (digits after the point), varint mantissa
+//
+// Within one side of a depth frame, a price whose scale matches the previous
+// level's price has binaryDeltaFlag set in its scale byte and carries the
+// difference from that price instead of the full mantissa. Decimals keep
+// their scale, so decoded strings match the JSON ones byte for byte and the
+// depth checksum can be verified the same way.
+//
+// Version 2 appended seq to trade frames and moved them to a new frame type,
+// so a version 1 decoder rejects them rather than misreading them. Version 1
+// trade frames (binaryFrameTradeV1) still decode, with seq 0.
+const (
+	BinaryVersion = 2
+
+	binaryFrameDepth   byte = 0x01
+	binaryFrameTradeV1 byte = 0x02
+	binaryFrameTrade   byte = 0x03
+
+	binaryDeltaFlag byte = 0x80
+
+	// maxDecimalScale keeps mantissas of 18 fractional digits within int64
+	maxDecimalScale = 18
+)
+
+// errNotEncodable is returned for messages or values without a binary form;
+// the hub falls back to JSON for them
+var errNotEncodable = errors.New("message has no binary encoding")
+
+// supportsBinary reports whether a channel can be subscribed to in binary format
+func supportsBinary(channel string) bool {
+	return strings.HasPrefix(channel, "depth:") || strings.HasPrefix(channel, "trades:")
+}
+
+// EncodeBinary encodes a depth or trade message as a binary frame
+func EncodeBinary(msg *WSMessage) ([]byte, error) {
+	var w binaryWriter
+	switch data := msg.Data.(type) {
+	case *DepthMessage:
+		w.buf = make([]byte, 0, 32+16*(len(data.Bids)+len(data.Asks)))
+		w.buf = append(w.buf, binaryFrameDepth)
+		w.string(data.MarketID)
+		w.varint(data.Timestamp)
+		w.buf = binary.LittleEndian.AppendUint32(w.buf, uint32(data.Checksum))
+		w.levels(data.Bids)
+		w.levels(data.Asks)
+	case *TradeMessage:
+		w.buf = append(w.buf, binaryFrameTrade)
+		w.string(data.MarketID)
+		w.string(data.TradeID)
+		w.decimal(data.Price, nil)
+		w.decimal(data.Quantity, nil)
+		switch data.Side {
+		case "buy":
+			w.buf = append(w.buf, 0)
+		case "sell":
+			w.buf = append(w.buf, 1)
+		default:
+			return nil, fmt.Errorf("%w: side %q", errNotEncodable, data.Side)
+		}
+		w.varint(data.Timestamp)
+		w.buf = binary.AppendUvarint(w.buf, data.Seq)
+	default:
+		return nil, fmt.Errorf("%w: %T", errNotEncodable, msg.Data)
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+	return w.buf, nil
+}
+
+// DecodeBinary decodes a binary frame into the message the JSON channel
+// would have carried
+func DecodeBinary(frame []byte) (*WSMessage, error) {
+	if len(frame) == 0 {
+		return nil, errors.New("empty binary frame")
+	}
+	r := binaryReader{buf: frame[1:]}
+	switch frame[0] {
+	case binaryFrameDepth:
+		depth := &DepthMessage{MarketID: r.string(), Timestamp: r.varint(), Checksum: int32(r.uint32())}
+		depth.Bids = r.levels()
+		depth.Asks = r.levels()
+		if err := r.done(); err != nil {
+			return nil, err
+		}
+		return &WSMessage{Type: "depth", Channel: "depth:" + depth.MarketID, Data: depth}, nil
+	case binaryFrameTrade, binaryFrameTradeV1:
+		trade := &TradeMessage{MarketID: r.string(), TradeID: r.string()}
+		trade.Price, _ = r.decimal(nil)
+		trade.Quantity, _ = r.decimal(nil)
+		switch r.byte() {
+		case 0:
+			trade.Side = "buy"
+		case 1:
+			trade.Side = "sell"
+		default:
+			r.fail("invalid trade side")
+		}
+		trade.Timestamp = r.varint()
+		if frame[0] == binaryFrameTrade {
+			trade.Seq = r.uvarint()
+		}
+		if err := r.done(); err != nil {
+			return nil, err
+		}
+		return &WSMessage{Type: "trade", Channel: "trades:" + trade.MarketID, Data: trade}, nil
+	default:
+		return nil, fmt.Errorf("unknown binary frame type 0x%02x", frame[0])
+	}
+}
+
+// scaledDecimal is a decimal string as mantissa * 10^-scale
+type scaledDecimal struct {
+	mantissa int64
+	scale    byte
+}
+
+// parseDecimal parses a plain decimal string. Only canonical forms are
+// accepted (no exponent, no leading zeros or "+", no trailing point) so
+// that formatDecimal reproduces the input exactly.
+func parseDecimal(s string) (scaledDecimal, error) {
+	digits := strings.TrimPrefix(s, "-")
+	negative := len(digits) != len(s)
+
+	intPart, fracPart, hasPoint := strings.Cut(digits, ".")
+	if !isDigits(intPart) || (len(intPart) > 1 && intPart[0] == '0') ||
+		(hasPoint && !isDigits(fracPart)) || len(fracPart) > maxDecimalScale {
+		return scaledDecimal{}, fmt.Errorf("%w: decimal %q", errNotEncodable, s)
+	}
+
+	var mantissa uint64
+	for _, part := range [2]string{intPart, fracPart} {
+		for i := 0; i < len(part); i++ {
+			if mantissa > (math.MaxInt64-9)/10 {
+				return scaledDecimal{}, fmt.Errorf("%w: decimal %q", errNotEncodable, s)
+			}
+			mantissa = mantissa*10 + uint64(part[i]-'0')
+		}
+	}
+	if negative && mantissa == 0 {
+		return scaledDecimal{}, fmt.Errorf("%w: decimal %q", errNotEncodable, s)
+	}
+	d := scaledDecimal{mantissa: int64(mantissa), scale: byte(len(fracPart))}
+	if negative {
+		d.mantissa = -d.mantissa
+	}
+	return d, nil
+}
+
+// formatDecimal is the inverse of parseDecimal
+func formatDecimal(d scaledDecimal) string {
+	abs := uint64(d.mantissa)
+	if d.mantissa < 0 {
+		abs = -abs
+	}
+	digits := strconv.FormatUint(abs, 10)
+	if scale := int(d.scale); scale > 0 {
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if d.mantissa < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// binaryWriter appends frame fields, keeping the first error
+type binaryWriter struct {
+	buf []byte
+	err error
+}
+
+func (w *binaryWriter) string(s string) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+func (w *binaryWriter) varint(v int64) {
+	w.buf = binary.AppendVarint(w.buf, v)
+}
+
+// decimal writes s, delta-coded against prev when the scales match.
+// It returns the parsed value for use as the next prev.
+func (w *binaryWriter) decimal(s string, prev *scaledDecimal) scaledDecimal {
+	d, err := parseDecimal(s)
+	if err != nil {
+		if w.err == nil {
+			w.err = err
+		}
+		return d
+	}
+	if prev != nil && prev.scale == d.scale && (prev.mantissa >= 0) == (d.mantissa >= 0) {
+		w.buf = append(w.buf, d.scale|binaryDeltaFlag)
+		w.varint(d.mantissa - prev.mantissa)
+		return d
+	}
+	w.buf = append(w.buf, d.scale)
+	w.varint(d.mantissa)
+	return d
+}
+
+func (w *binaryWriter) levels(levels []PriceLevel) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(levels)))
+	var price scaledDecimal
+	for i, level := range levels {
+		if i == 0 {
+			price = w.decimal(level.Price, nil)
+		} else {
+			price = w.decimal(level.Price, &price)
+		}
+		w.decimal(level.Quantity, nil)
+	}
+}
+
+// binaryReader consumes frame fields, keeping the first error
+type binaryReader struct {
+	buf []byte
+	err error
+}
+
+func (r *binaryReader) fail(msg string) {
+	if r.err == nil {
+		r.err = errors.New("malformed binary frame: " + msg)
+	}
+	r.buf = nil
+}
+
+func (r *binaryReader) done() error {
+	if r.err == nil && len(r.buf) != 0 {
+		r.fail("trailing bytes")
+	}
+	return r.err
+}
+
+func (r *binaryReader) byte() byte {
+	if len(r.buf) == 0 {
+		r.fail("truncated")
+		return 0
+	}
+	b := r.buf[0]
+	r.buf = r.buf[1:]
+	return b
+}
+
+func (r *binaryReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.fail("bad uvarint")
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *binaryReader) varint() int64 {
+	v, n := binary.Varint(r.buf)
+	if n <= 0 {
+		r.fail("bad varint")
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *binaryReader) uint32() uint32 {
+	if len(r.buf) < 4 {
+		r.fail("truncated")
+		return 0
+	}
+	v := binary.LittleEndian.Uint32(r.buf)
+	r.buf = r.buf[4:]
+	return v
+}
+
+func (r *binaryReader) string() string {
+	n := r.uvarint()
+	if n > uint64(len(r.buf)) {
+		r.fail("truncated string")
+		return ""
+	}
+	s := string(r.buf[:n])
+	r.buf = r.buf[n:]
+	return s
+}
+
+// decimal reads a decimal, resolving deltas against prev
+func (r *binaryReader) decimal(prev *scaledDecimal) (string, scaledDecimal) {
+	scale := r.byte()
+	d := scaledDecimal{scale: scale &^ binaryDeltaFlag, mantissa: r.varint()}
+	if scale&binaryDeltaFlag != 0 {
+		if prev == nil {
+			r.fail("delta without a previous price")
+			return "", d
+		}
+		d.mantissa += prev.mantissa
+	}
+	if d.scale > maxDecimalScale {
+		r.fail("decimal scale out of range")
+		return "", d
+	}
+	return formatDecimal(d), d
+}
+
+func (r *binaryReader) levels() []PriceLevel {
+	n := r.uvarint()
+	// Every level takes at least four bytes
+	if n > uint64(len(r.buf))/4 {
+		r.fail("level count exceeds frame")
+		return nil
+	}
+	levels := make([]PriceLevel, 0, n)
+	var price string
+	var prev scaledDecimal
+	for i := uint64(0); i < n && r.err == nil; i++ {
+		if i == 0 {
+			price, prev = r.decimal(nil)
+		} else {
+			price, prev = r.decimal(&prev)
+		}
+		quantity, _ := r.decimal(nil)
+		levels = append(levels, PriceLevel{Price: price, Quantity: quantity})
+	}
+	return levels
+}
@@ -0,0 +1,157 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// benchDepth builds a depth message with the given number of levels per side
+func benchDepth(levels int) *WSMessage {
+	depth := &DepthMessage{MarketID: "BTC-USDC", Timestamp: 1737455123456}
+	for i := 0; i < levels; i++ {
+		depth.Bids = append(depth.Bids, PriceLevel{Price: fmt.Sprintf("%d.%d", 50000-i, i%10), Quantity: fmt.Sprintf("0.%03d", 100+i*7)})
+		depth.Asks = append(depth.Asks, PriceLevel{Price: fmt.Sprintf("%d.%d", 50001+i, i%10), Quantity: fmt.Sprintf("%d.25", 1+i)})
+	}
+	depth.Checksum = depth.ComputeChecksum()
+	return &WSMessage{Type: "depth", Channel: "depth:BTC-USDC", Data: depth}
+}
+
+// TestBinaryRoundTrip tests that decoded frames match the JSON payloads,
+// including decimal strings and the depth checksum
+func TestBinaryRoundTrip(t *testing.T) {
+	trade := &WSMessage{Type: "trade", Channel: "trades:ETH-USDC", Data: &TradeMessage{
+		TradeID: "t-42", MarketID: "ETH-USDC", Price: "3000.50", Quantity: "0.000001", Side: "sell", Timestamp: 1737455123000, Seq: 7,
+	}}
+	depth := benchDepth(20)
+	depth.Data.(*DepthMessage).Bids[3].Price = "49997" // scale change mid-side
+	depth.Data.(*DepthMessage).Bids[4] = PriceLevel{Price: "49996.12", Quantity: "0"}
+	depth.Data.(*DepthMessage).Checksum = depth.Data.(*DepthMessage).ComputeChecksum()
+	empty := &WSMessage{Type: "depth", Channel: "depth:SOL-USDC", Data: &DepthMessage{
+		MarketID: "SOL-USDC", Bids: []PriceLevel{}, Asks: []PriceLevel{},
+	}}
+
+	for _, msg := range []*WSMessage{trade, depth, empty} {
+		frame, err := EncodeBinary(msg)
+		if err != nil {
+			t.Fatalf("failed to encode %s: %v", msg.Channel, err)
+		}
+		decoded, err := DecodeBinary(frame)
+		if err != nil {
+			t.Fatalf("failed to decode %s: %v", msg.Channel, err)
+		}
+		want, _ := json.Marshal(msg)
+		got, _ := json.Marshal(decoded)
+		if string(got) != string(want) {
+			t.Errorf("round trip mismatch\nwant %s\ngot  %s", want, got)
+		}
+		if d, ok := decoded.Data.(*DepthMessage); ok && d.ComputeChecksum() != d.Checksum {
+			t.Errorf("decoded depth fails its checksum")
+		}
+	}
+
+	for _, bad := range []string{"1e5", "007", ".5", "5.", "-0", "+1", "1.0000000000000000001", "99999999999999999999"} {
+		msg := &WSMessage{Data: &TradeMessage{Price: bad, Quantity: "1", Side: "buy"}}
+		if _, err := EncodeBinary(msg); !errors.Is(err, errNotEncodable) {
+			t.Errorf("expected %q to be rejected, got %v", bad, err)
+		}
+	}
+
+	frame, _ := EncodeBinary(depth)
+	if _, err := DecodeBinary(frame[:len(frame)-1]); err == nil {
+		t.Error("expected truncated frame to be rejected")
+	}
+
+	// A version 1 trade frame has no seq
+	frame, _ = EncodeBinary(trade)
+	if frame[0] != binaryFrameTrade {
+		t.Fatalf("expected trade frame type %#x, got %#x", binaryFrameTrade, frame[0])
+	}
+	frame[0] = binaryFrameTradeV1
+	decoded, err := DecodeBinary(frame[:len(frame)-1])
+	if err != nil || decoded.Data.(*TradeMessage).Seq != 0 || decoded.Data.(*TradeMessage).TradeID != "t-42" {
+		t.Errorf("expected the version 1 trade decoded without seq, got %+v (%v)", decoded, err)
+	}
+}
+
+// TestBinarySubscription tests that binary is negotiated per channel and
+// that other subscribers of the same channel still receive JSON
+func TestBinarySubscription(t *testing.T) {
+	hub, url := startTestHub(t, DefaultHubConfig())
+
+	dial := func(format string) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		if err := conn.WriteJSON(ClientMessage{Action: "subscribe", Channel: "trades:BTC-USDC", Format: format}); err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+		var msg WSMessage
+		if err := conn.ReadJSON(&msg); err != nil || msg.Type != "subscribed" {
+			t.Fatalf("expected subscribed, got %+v (%v)", msg, err)
+		}
+		if details, _ := msg.Data.(map[string]interface{}); format == FormatBinary && details["version"] != float64(BinaryVersion) {
+			t.Fatalf("expected binary version %d acknowledged, got %+v", BinaryVersion, msg.Data)
+		}
+		return conn
+	}
+	binaryConn := dial(FormatBinary)
+	jsonConn := dial("")
+
+	waitFor(t, func() bool { return hub.GetChannelClientCount("trades:BTC-USDC") == 2 }, "expected both subscriptions")
+	hub.BroadcastTrade("BTC-USDC", &TradeMessage{TradeID: "t-1", MarketID: "BTC-USDC", Price: "50000.5", Quantity: "0.1", Side: "buy", Timestamp: 1})
+
+	_ = binaryConn.SetReadDeadline(time.Now().Add(time.Second))
+	kind, frame, err := binaryConn.ReadMessage()
+	if err != nil || kind != websocket.BinaryMessage {
+		t.Fatalf("expected a binary frame, got type %d (%v)", kind, err)
+	}
+	decoded, err := DecodeBinary(frame)
+	if err != nil || decoded.Data.(*TradeMessage).Price != "50000.5" {
+		t.Fatalf("unexpected binary trade %+v (%v)", decoded, err)
+	}
+
+	_ = jsonConn.SetReadDeadline(time.Now().Add(time.Second))
+	if kind, _, err := jsonConn.ReadMessage(); err != nil || kind != websocket.TextMessage {
+		t.Fatalf("expected a JSON frame, got type %d (%v)", kind, err)
+	}
+
+	// Binary is refused on channels without a binary encoding
+	if err := binaryConn.WriteJSON(ClientMessage{Action: "subscribe", Channel: "ticker:BTC-USDC", Format: FormatBinary}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	var reply WSMessage
+	if err := binaryConn.ReadJSON(&reply); err != nil || reply.Type != "error" {
+		t.Errorf("expected an error for binary ticker, got %+v (%v)", reply, err)
+	}
+}
+
+// BenchmarkDepthEncode compares JSON and binary encoding of a 20-level depth
+// update; bytes/msg reports the frame size
+func BenchmarkDepthEncode(b *testing.B) {
+	msg := benchDepth(20)
+
+	b.Run("json", func(b *testing.B) {
+		var size int
+		for i := 0; i < b.N; i++ {
+			data, _ := json.Marshal(msg)
+			size = len(data)
+		}
+		b.ReportMetric(float64(size), "bytes/msg")
+	})
+
+	b.Run("binary", func(b *testing.B) {
+		var size int
+		for i := 0; i < b.N; i++ {
+			frame, _ := EncodeBinary(msg)
+			size = len(frame)
+		}
+		b.ReportMetric(float64(size), "bytes/msg")
+	})
+}
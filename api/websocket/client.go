@@ -2,31 +2,49 @@ package websocket
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/openalpha/perp-dex/api/auth"
+	"github.com/openalpha/perp-dex/api/numfmt"
+	"github.com/openalpha/perp-dex/api/types"
 )
 
 const (
 	// Time allowed to write a message to the peer
 	writeWait = 10 * time.Second
 
-	// Time allowed to read the next pong message from the peer
-	pongWait = 60 * time.Second
+	// Default time allowed to read the next pong message from the peer
+	defaultPongTimeout = 60 * time.Second
 
-	// Send pings to peer with this period (must be less than pongWait)
-	pingPeriod = (pongWait * 9) / 10
+	// Default ping period (must be less than the pong timeout)
+	defaultPingInterval = (defaultPongTimeout * 9) / 10
 
 	// Maximum message size allowed from peer
 	maxMessageSize = 4096
 
-	// Size of the send buffer
-	sendBufferSize = 256
+	// Default size of the per-connection send queue
+	defaultSendQueueSize = 256
 )
 
+// privateChannelPrefixes are channels scoped to a single user; the channel name
+// is the prefix followed by the user ID and requires a live session
+var privateChannelPrefixes = []string{"positions:", "orders:", "riverpool:withdrawals:", "riverpool:deposits:"}
+
+// licensedChannelPrefixes are market data channels that require a live
+// session holding a data license
+var licensedChannelPrefixes = map[string]auth.Permission{
+	"l3:": auth.PermissionL3Data,
+}
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
@@ -36,21 +54,60 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// outboundMessage is a queued frame. Binary frames are written as their own
+// WebSocket message and never batched with JSON. A conflated message is a
+// placeholder for its channel's latest message, taken when it is written.
+type outboundMessage struct {
+	data     []byte
+	binary   bool
+	conflate string
+}
+
+// tickerSubscription is a ticker channel's throttle and its last push
+type tickerSubscription struct {
+	throttle time.Duration
+	lastSent time.Time
+	version  uint64 // of the market's ticker last pushed
+}
+
 // Client represents a WebSocket client connection
 type Client struct {
 	hub  *Hub
 	conn *websocket.Conn
-	send chan []byte
+	send chan outboundMessage
+
+	// sendMu guards sends against the hub closing send on unregister
+	sendMu     sync.Mutex
+	sendClosed bool
+	evictOnce  sync.Once
 
 	// Client identification
-	id       string
-	userID   string // Empty for anonymous clients
-	ip       string
+	id string
+	ip string
+
+	// Session auth; userID is empty for anonymous clients
+	userID  string
+	session *auth.Session
+	authMu  sync.RWMutex
+
+	// cancelOnDisconnect requests resting orders be cancelled when the session ends
+	cancelOnDisconnect bool
+
+	// numbers rewrites JSON messages in the connection's number format; nil
+	// sends them as built
+	numbers *numfmt.Formatter
 
-	// Subscriptions
+	// Subscriptions; binary holds channels subscribed with FormatBinary and
+	// tickers the throttle of each ticker channel
 	subscriptions map[string]bool
+	binary        map[string]bool
+	tickers       map[string]*tickerSubscription
 	subMu         sync.RWMutex
 
+	// Latest unwritten message of each conflated channel, see sendConflated
+	conflated  map[string][]byte
+	conflateMu sync.Mutex
+
 	// Rate limiting
 	messageCount int
 	lastReset    time.Time
@@ -63,9 +120,12 @@ type Client struct {
 
 // ClientMessage represents a message from a client
 type ClientMessage struct {
-	Action  string          `json:"action"`  // "subscribe", "unsubscribe", "ping"
-	Channel string          `json:"channel"` // Channel to subscribe/unsubscribe
-	Data    json.RawMessage `json:"data,omitempty"`
+	Action     string          `json:"action"`                // "subscribe", "unsubscribe", "ping"
+	Channel    string          `json:"channel"`               // Channel to subscribe/unsubscribe
+	Format     string          `json:"format,omitempty"`      // Subscribe only: FormatJSON (default) or FormatBinary
+	FromSeq    uint64          `json:"from_seq,omitempty"`    // Subscribe only: replay buffered messages from this sequence number
+	ThrottleMs int64           `json:"throttle_ms,omitempty"` // Subscribe to a ticker channel only: push at most one update per this many ms
+	Data       json.RawMessage `json:"data,omitempty"`
 }
 
 // NewClient creates a new Client
@@ -73,11 +133,14 @@ func NewClient(hub *Hub, conn *websocket.Conn, id, userID, ip string) *Client {
 	return &Client{
 		hub:           hub,
 		conn:          conn,
-		send:          make(chan []byte, sendBufferSize),
+		send:          make(chan outboundMessage, hub.config.SendQueueSize),
 		id:            id,
 		userID:        userID,
 		ip:            ip,
 		subscriptions: make(map[string]bool),
+		binary:        make(map[string]bool),
+		tickers:       make(map[string]*tickerSubscription),
+		conflated:     make(map[string][]byte),
 		connectedAt:   time.Now(),
 		lastReset:     time.Now(),
 	}
@@ -90,34 +153,42 @@ func (c *Client) readPump() {
 		c.conn.Close()
 	}()
 
+	pongTimeout := c.hub.config.PongTimeout
 	c.conn.SetReadLimit(maxMessageSize)
-	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
 	c.conn.SetPongHandler(func(string) error {
-		_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		_ = c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
 		return nil
 	})
 
 	for {
 		_, message, err := c.conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			// A missed read deadline means no pong or message arrived in time
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				c.hub.recordEviction(EvictPongTimeout)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("websocket error: %v", err)
 			}
 			break
 		}
 
+		// Any message shows the peer is alive
+		_ = c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
 		c.lastMessageAt = time.Now()
+		c.checkSession()
 
 		// Rate limiting check
 		if !c.checkRateLimit() {
-			c.sendError("rate_limit_exceeded", "Too many messages, please slow down")
+			c.sendError(types.ErrCodeRateLimitExceeded, "Too many messages, please slow down")
 			continue
 		}
 
 		// Parse and handle message
 		var msg ClientMessage
 		if err := json.Unmarshal(message, &msg); err != nil {
-			c.sendError("invalid_message", "Failed to parse message")
+			c.sendError(types.ErrCodeInvalidMessage, "Failed to parse message")
 			continue
 		}
 
@@ -127,7 +198,7 @@ func (c *Client) readPump() {
 
 // writePump pumps messages from the hub to the WebSocket connection
 func (c *Client) writePump() {
-	ticker := time.NewTicker(pingPeriod)
+	ticker := time.NewTicker(c.hub.config.PingInterval)
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
@@ -142,25 +213,12 @@ func (c *Client) writePump() {
 				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
-
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				return
-			}
-			_, _ = w.Write(message)
-
-			// Add queued messages to the current WebSocket message
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				_, _ = w.Write([]byte{'\n'})
-				_, _ = w.Write(<-c.send)
-			}
-
-			if err := w.Close(); err != nil {
+			if err := c.writeMessage(message); err != nil {
 				return
 			}
 
 		case <-ticker.C:
+			c.checkSession()
 			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
@@ -169,49 +227,148 @@ func (c *Client) writePump() {
 	}
 }
 
+// writeMessage writes message and, if it is JSON, any JSON queued behind it
+// as one newline-separated WebSocket message. A binary frame met while
+// batching ends the batch and is written as its own message.
+func (c *Client) writeMessage(message outboundMessage) error {
+	if message.conflate != "" {
+		var ok bool
+		if message, ok = c.takeConflated(message.conflate); !ok {
+			return nil
+		}
+	}
+	if message.binary {
+		return c.conn.WriteMessage(websocket.BinaryMessage, message.data)
+	}
+
+	w, err := c.conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+	_, _ = w.Write(message.data)
+
+	// Add queued messages to the current WebSocket message. Senders
+	// may drop queued messages concurrently, so never block here.
+	n := len(c.send)
+	for i := 0; i < n; i++ {
+		select {
+		case queued, ok := <-c.send:
+			if !ok {
+				return w.Close()
+			}
+			if queued.conflate != "" {
+				if queued, ok = c.takeConflated(queued.conflate); !ok {
+					continue
+				}
+			}
+			if queued.binary {
+				if err := w.Close(); err != nil {
+					return err
+				}
+				return c.writeMessage(queued)
+			}
+			_, _ = w.Write([]byte{'\n'})
+			_, _ = w.Write(queued.data)
+		default:
+			return w.Close()
+		}
+	}
+	return w.Close()
+}
+
 // handleMessage handles incoming messages from the client
 func (c *Client) handleMessage(msg *ClientMessage) {
 	switch msg.Action {
 	case "subscribe":
-		c.handleSubscribe(msg.Channel)
+		c.handleSubscribe(msg.Channel, msg.Format, msg.FromSeq, msg.ThrottleMs)
 	case "unsubscribe":
 		c.handleUnsubscribe(msg.Channel)
 	case "ping":
 		c.handlePing()
 	case "auth":
 		c.handleAuth(msg.Data)
+	case "refresh":
+		c.handleRefresh()
 	default:
-		c.sendError("unknown_action", "Unknown action: "+msg.Action)
+		c.sendError(types.ErrCodeUnknownAction, "Unknown action: "+msg.Action)
 	}
 }
 
-// handleSubscribe handles a subscription request
-func (c *Client) handleSubscribe(channel string) {
+// handleSubscribe handles a subscription request. Re-subscribing with a
+// different format switches the channel's format. A non-zero fromSeq asks
+// for the channel's buffered messages from that sequence number on. On
+// ticker channels a non-zero throttleMs sets how often updates are pushed,
+// the hub's ticker interval when zero; re-subscribing changes it.
+func (c *Client) handleSubscribe(channel, format string, fromSeq uint64, throttleMs int64) {
 	if channel == "" {
-		c.sendError("invalid_channel", "Channel cannot be empty")
+		c.sendError(types.ErrCodeInvalidChannel, "Channel cannot be empty")
 		return
 	}
-
-	// Check subscription limit
-	c.subMu.Lock()
-	if len(c.subscriptions) >= c.hub.config.MaxSubscriptions {
-		c.subMu.Unlock()
-		c.sendError("subscription_limit", "Maximum subscription limit reached")
+	switch format {
+	case "", FormatJSON:
+		format = FormatJSON
+	case FormatBinary:
+		if !supportsBinary(channel) {
+			c.sendError(types.ErrCodeInvalidChannel, "Binary format is only available on depth and trades channels")
+			return
+		}
+	default:
+		c.sendError(types.ErrCodeInvalidMessage, "Unknown format: "+format)
 		return
 	}
-	c.subscriptions[channel] = true
-	c.subMu.Unlock()
+	throttle := c.hub.config.TickerInterval
+	if throttleMs != 0 {
+		if !isTickerChannel(channel) {
+			c.sendError(types.ErrCodeInvalidMessage, "throttle_ms is only available on ticker channels")
+			return
+		}
+		throttle = time.Duration(throttleMs) * time.Millisecond
+		if throttle < c.hub.config.TickerInterval || throttle > c.hub.config.MaxTickerThrottle {
+			c.sendError(types.ErrCodeInvalidMessage, fmt.Sprintf("throttle_ms must be between %d and %d",
+				c.hub.config.TickerInterval.Milliseconds(), c.hub.config.MaxTickerThrottle.Milliseconds()))
+			return
+		}
+	}
 
 	// Validate channel access
 	if !c.canAccessChannel(channel) {
-		c.sendError("unauthorized", "Not authorized to access channel: "+channel)
+		c.sendError(types.ErrCodeUnauthorized, "Not authorized to access channel: "+channel)
+		return
+	}
+
+	// Check subscription limit; re-subscribing to a channel does not count
+	c.subMu.Lock()
+	limit := c.hub.config.MaxSubscriptions
+	if limit > 0 && !c.subscriptions[channel] && len(c.subscriptions) >= limit {
+		c.subMu.Unlock()
+		atomic.AddInt64(&c.hub.subscriptionsRejected, 1)
+		c.sendError(types.ErrCodeSubscriptionLimit, fmt.Sprintf("Maximum of %d subscriptions per connection reached", limit))
 		return
 	}
+	c.subscriptions[channel] = true
+	if format == FormatBinary {
+		c.binary[channel] = true
+	} else {
+		delete(c.binary, channel)
+	}
+	if isTickerChannel(channel) {
+		if sub, ok := c.tickers[channel]; ok {
+			sub.throttle = throttle
+		} else {
+			c.tickers[channel] = &tickerSubscription{throttle: throttle}
+		}
+	} else {
+		throttle = 0
+	}
+	c.subMu.Unlock()
 
 	c.hub.subscribe <- &SubscriptionRequest{
-		Client:  c,
-		Channel: channel,
-		Action:  "subscribe",
+		Client:   c,
+		Channel:  channel,
+		Action:   "subscribe",
+		Format:   format,
+		FromSeq:  fromSeq,
+		Throttle: throttle,
 	}
 }
 
@@ -219,7 +376,10 @@ func (c *Client) handleSubscribe(channel string) {
 func (c *Client) handleUnsubscribe(channel string) {
 	c.subMu.Lock()
 	delete(c.subscriptions, channel)
+	delete(c.binary, channel)
+	delete(c.tickers, channel)
 	c.subMu.Unlock()
+	c.takeConflated(channel)
 
 	c.hub.unsubscribe <- &SubscriptionRequest{
 		Client:  c,
@@ -237,56 +397,178 @@ func (c *Client) handlePing() {
 		},
 	}
 	data, _ := json.Marshal(response)
-	c.send <- data
+	c.Send(data)
 }
 
-// handleAuth handles an authentication request
+// handleAuth handles an authentication request carrying a session token
 func (c *Client) handleAuth(data json.RawMessage) {
-	// Parse auth data
 	var authData struct {
 		Token string `json:"token"`
 	}
-	if err := json.Unmarshal(data, &authData); err != nil {
-		c.sendError("invalid_auth", "Invalid auth data")
+	if err := json.Unmarshal(data, &authData); err != nil || authData.Token == "" {
+		c.sendError(types.ErrCodeInvalidAuth, "Invalid auth data")
 		return
 	}
 
-	// TODO: Validate token and extract user ID
-	// For now, just acknowledge
-	if authData.Token != "" {
-		c.userID = "authenticated_user" // Replace with actual user ID from token
+	session, err := c.hub.verifySession(authData.Token)
+	if err != nil {
+		c.sendError(types.ErrorCodeOf(err, types.ErrCodeInvalidToken), err.Error())
+		return
 	}
 
-	response := &WSMessage{
-		Type: "authenticated",
-		Data: map[string]interface{}{
-			"user_id": c.userID,
-		},
+	c.setSession(session)
+	c.sendSession("authenticated", "", session)
+}
+
+// handleRefresh extends the current session and returns a fresh token in-band
+func (c *Client) handleRefresh() {
+	session := c.currentSession()
+	if session == nil {
+		c.sendError(types.ErrCodeUnauthenticated, "Not authenticated")
+		return
+	}
+
+	token, refreshed, err := c.hub.refreshSession(session)
+	if err != nil {
+		c.sendError(types.ErrorCodeOf(err, types.ErrCodeSessionExpired), err.Error())
+		return
+	}
+
+	c.setSession(refreshed)
+	c.sendSession("token_refreshed", token, refreshed)
+}
+
+// sendSession sends a session state message; token is omitted when empty
+func (c *Client) sendSession(msgType, token string, session *auth.Session) {
+	data := map[string]interface{}{
+		"user_id":    session.Trader,
+		"session_id": session.SessionID,
+		"expires_at": session.Expiry().UnixMilli(),
+	}
+	if token != "" {
+		data["token"] = token
+	}
+
+	response, _ := json.Marshal(&WSMessage{Type: msgType, Data: data})
+	c.Send(response)
+}
+
+// setSession binds the client to a verified session. Switching to another
+// user drops the previous user's private subscriptions, and licensed channels
+// the new session holds no license for are dropped.
+func (c *Client) setSession(session *auth.Session) {
+	c.authMu.Lock()
+	switched := c.userID != "" && c.userID != session.Trader
+	c.userID = session.Trader
+	c.session = session
+	c.authMu.Unlock()
+
+	if switched {
+		c.dropPrivateSubscriptions()
+	}
+	c.dropSubscriptions(func(channel string) bool {
+		perm, licensed := channelPermission(channel)
+		return licensed && !session.HasPermission(perm)
+	})
+}
+
+// currentSession returns the client's session, or nil if anonymous
+func (c *Client) currentSession() *auth.Session {
+	c.authMu.RLock()
+	defer c.authMu.RUnlock()
+	return c.session
+}
+
+// sessionActive returns true if the client holds an unexpired session
+func (c *Client) sessionActive() bool {
+	c.authMu.RLock()
+	defer c.authMu.RUnlock()
+	return c.session != nil && time.Now().Unix() < c.session.ExpiresAt
+}
+
+// hasPermission returns true if the client holds an unexpired session
+// granted p
+func (c *Client) hasPermission(p auth.Permission) bool {
+	c.authMu.RLock()
+	defer c.authMu.RUnlock()
+	return c.session != nil && time.Now().Unix() < c.session.ExpiresAt && c.session.HasPermission(p)
+}
+
+// checkSession ends an expired session and drops its private and licensed
+// subscriptions.
+// It may run on the write pump, so the notice is sent without blocking.
+func (c *Client) checkSession() {
+	c.authMu.Lock()
+	if c.session == nil || time.Now().Unix() < c.session.ExpiresAt {
+		c.authMu.Unlock()
+		return
+	}
+	c.session = nil
+	c.userID = ""
+	c.authMu.Unlock()
+
+	c.dropSubscriptions(func(channel string) bool {
+		_, licensed := channelPermission(channel)
+		return licensed || isPrivateChannel(channel)
+	})
+
+	notice, _ := json.Marshal(&WSMessage{
+		Type: "error",
+		Data: types.NewAPIError(types.ErrCodeSessionExpired, "Session expired, authenticate again to resume private channels"),
+	})
+	c.Send(notice)
+}
+
+// dropPrivateSubscriptions unsubscribes the client from all private channels
+func (c *Client) dropPrivateSubscriptions() {
+	c.dropSubscriptions(isPrivateChannel)
+}
+
+// dropSubscriptions unsubscribes the client from the channels drop matches
+func (c *Client) dropSubscriptions(drop func(channel string) bool) {
+	c.subMu.Lock()
+	dropped := make([]string, 0)
+	for channel := range c.subscriptions {
+		if drop(channel) {
+			dropped = append(dropped, channel)
+			delete(c.subscriptions, channel)
+			delete(c.binary, channel)
+		}
+	}
+	c.subMu.Unlock()
+
+	for _, channel := range dropped {
+		c.hub.unsubscribe <- &SubscriptionRequest{
+			Client:  c,
+			Channel: channel,
+			Action:  "unsubscribe",
+		}
 	}
-	data, _ = json.Marshal(response)
-	c.send <- data
 }
 
 // canAccessChannel checks if the client can access a channel
 func (c *Client) canAccessChannel(channel string) bool {
 	// Public channels
-	publicPrefixes := []string{"ticker:", "depth:", "trades:"}
+	publicPrefixes := []string{"ticker:", "depth:", "trades:", "stats:"}
 	for _, prefix := range publicPrefixes {
 		if len(channel) >= len(prefix) && channel[:len(prefix)] == prefix {
 			return true
 		}
 	}
 
-	// Private channels require authentication
-	privatePrefixes := []string{"positions:", "orders:"}
-	for _, prefix := range privatePrefixes {
+	// Licensed channels require a live session holding the license
+	if perm, licensed := channelPermission(channel); licensed {
+		return c.hasPermission(perm)
+	}
+
+	// Private channels require a live session
+	for _, prefix := range privateChannelPrefixes {
 		if len(channel) >= len(prefix) && channel[:len(prefix)] == prefix {
-			// Check if user is authenticated
-			if c.userID == "" {
+			if !c.sessionActive() {
 				return false
 			}
 			// Check if channel belongs to user
-			expectedChannel := prefix + c.userID
+			expectedChannel := prefix + c.GetUserID()
 			return channel == expectedChannel
 		}
 	}
@@ -294,6 +576,31 @@ func (c *Client) canAccessChannel(channel string) bool {
 	return false
 }
 
+// isTickerChannel returns true for per-market ticker channels
+func isTickerChannel(channel string) bool {
+	return strings.HasPrefix(channel, "ticker:")
+}
+
+// isPrivateChannel returns true for user-scoped channels
+func isPrivateChannel(channel string) bool {
+	for _, prefix := range privateChannelPrefixes {
+		if len(channel) >= len(prefix) && channel[:len(prefix)] == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// channelPermission returns the data license a licensed channel requires
+func channelPermission(channel string) (auth.Permission, bool) {
+	for prefix, perm := range licensedChannelPrefixes {
+		if len(channel) >= len(prefix) && channel[:len(prefix)] == prefix {
+			return perm, true
+		}
+	}
+	return "", false
+}
+
 // checkRateLimit checks if the client is within rate limits
 func (c *Client) checkRateLimit() bool {
 	c.rateMu.Lock()
@@ -309,26 +616,146 @@ func (c *Client) checkRateLimit() bool {
 	return c.messageCount <= c.hub.config.MessageRateLimit
 }
 
-// sendError sends an error message to the client
-func (c *Client) sendError(code, message string) {
+// sendError sends an error frame to the client using the API error envelope
+func (c *Client) sendError(code types.ErrorCode, message string) {
 	response := &WSMessage{
 		Type: "error",
-		Data: map[string]string{
-			"code":    code,
-			"message": message,
-		},
+		Data: types.NewAPIError(code, message),
 	}
 	data, _ := json.Marshal(response)
-	c.send <- data
+	c.Send(data)
 }
 
-// Send sends a message to the client
+// Send queues a JSON message for the client without blocking. When the queue
+// is full the hub's slow consumer policy either drops the oldest queued
+// message or evicts the client. Messages to unregistered clients are discarded.
 func (c *Client) Send(message []byte) {
-	select {
-	case c.send <- message:
-	default:
-		// Buffer is full, message dropped
+	c.enqueue(outboundMessage{data: c.formatJSON(message)})
+}
+
+// formatJSON rewrites a JSON message in the client's number format, or
+// returns it as is
+func (c *Client) formatJSON(message []byte) []byte {
+	if c.numbers == nil {
+		return message
+	}
+	if formatted, err := c.numbers.Rewrite(message, ""); err == nil {
+		return formatted
 	}
+	return message
+}
+
+// SendBinary queues a binary frame for the client, see Send
+func (c *Client) SendBinary(frame []byte) {
+	c.enqueue(outboundMessage{data: frame, binary: true})
+}
+
+// wantsBinary reports whether the client subscribed to channel in binary format
+func (c *Client) wantsBinary(channel string) bool {
+	c.subMu.RLock()
+	defer c.subMu.RUnlock()
+	return c.binary[channel]
+}
+
+// tickerDue reports whether the ticker of version is pushed on channel at
+// now: the client has not been sent it and the channel's throttle has
+// elapsed since the last push, give or take slack for the hub's tick jitter.
+// A due push is recorded as sent.
+func (c *Client) tickerDue(channel string, version uint64, now time.Time, slack time.Duration) bool {
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+
+	sub, ok := c.tickers[channel]
+	if !ok || version <= sub.version || now.Sub(sub.lastSent) < sub.throttle-slack {
+		return false
+	}
+	sub.version = version
+	sub.lastSent = now
+	return true
+}
+
+// sendConflated queues a JSON message that supersedes any message of the
+// same channel still waiting in the queue: the waiting one is replaced in
+// place, so a slow consumer is sent only the channel's latest message.
+func (c *Client) sendConflated(channel string, message []byte) {
+	c.conflateMu.Lock()
+	_, waiting := c.conflated[channel]
+	c.conflated[channel] = message
+	c.conflateMu.Unlock()
+	if waiting {
+		return
+	}
+
+	if !c.enqueue(outboundMessage{conflate: channel}) {
+		c.takeConflated(channel)
+	}
+}
+
+// takeConflated removes and returns the latest conflated message of channel
+func (c *Client) takeConflated(channel string) (outboundMessage, bool) {
+	c.conflateMu.Lock()
+	defer c.conflateMu.Unlock()
+
+	data, ok := c.conflated[channel]
+	delete(c.conflated, channel)
+	return outboundMessage{data: data}, ok
+}
+
+// enqueue queues message and reports whether it was queued
+func (c *Client) enqueue(message outboundMessage) bool {
+	if drop := c.hub.faultDrop; drop != nil && drop() {
+		return false
+	}
+
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if c.sendClosed {
+		return false
+	}
+	for {
+		select {
+		case c.send <- message:
+			return true
+		default:
+		}
+
+		if c.hub.config.SlowConsumerPolicy == SlowConsumerDisconnect {
+			// The close handshake may block, so don't hold up the sender
+			go c.evict(EvictSlowConsumer)
+			return false
+		}
+		select {
+		case dropped := <-c.send:
+			atomic.AddInt64(&c.hub.droppedMessages, 1)
+			if dropped.conflate != "" {
+				c.takeConflated(dropped.conflate)
+			}
+		default:
+		}
+	}
+}
+
+// closeSend closes the send queue; called by the hub on unregister
+func (c *Client) closeSend() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if !c.sendClosed {
+		c.sendClosed = true
+		close(c.send)
+	}
+}
+
+// evict closes the connection with reason as the close frame text. The read
+// pump then fails and unregisters the client.
+func (c *Client) evict(reason string) {
+	c.evictOnce.Do(func() {
+		c.hub.recordEviction(reason)
+		closeMsg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason)
+		_ = c.conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(writeWait))
+		c.conn.Close()
+	})
 }
 
 // GetID returns the client ID
@@ -338,6 +765,8 @@ func (c *Client) GetID() string {
 
 // GetUserID returns the user ID
 func (c *Client) GetUserID() string {
+	c.authMu.RLock()
+	defer c.authMu.RUnlock()
 	return c.userID
 }
 
@@ -348,7 +777,7 @@ func (c *Client) GetIP() string {
 
 // IsAuthenticated returns whether the client is authenticated
 func (c *Client) IsAuthenticated() bool {
-	return c.userID != ""
+	return c.sessionActive()
 }
 
 // GetSubscriptions returns the client's subscriptions
@@ -1,11 +1,30 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/openalpha/perp-dex/api/auth"
+	"github.com/openalpha/perp-dex/api/numfmt"
+	"github.com/openalpha/perp-dex/api/types"
+	obtypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// drainPollInterval is how often Drain checks for pending outbound messages
+const drainPollInterval = 10 * time.Millisecond
+
+const (
+	// Default time between ticker pushes, and the shortest ticker throttle
+	defaultTickerInterval = 100 * time.Millisecond
+
+	// Default longest ticker throttle a subscription may ask for
+	defaultMaxTickerThrottle = time.Second
 )
 
 // Hub maintains the set of active clients and broadcasts messages
@@ -32,45 +51,165 @@ type Hub struct {
 	tickerBuffer  map[string]*TickerMessage
 	depthBuffer   map[string]*DepthMessage
 
+	// Ticker versions, bumped on every update so each subscriber is pushed
+	// a ticker at most once
+	tickerVersions map[string]uint64
+
 	// Mutex for thread-safe operations
 	mu sync.RWMutex
 
+	// Draining rejects new connections while pending broadcasts are flushed
+	draining bool
+
+	// Session tokens gating private channels; nil leaves them unavailable
+	sessions *auth.SessionManager
+
+	// Fault injection: outbound messages are discarded while it returns true
+	faultDrop func() bool
+
+	// Number format of connections that ask for none, and the markets'
+	// decimal places; set before the hub serves connections
+	numberFormat  numfmt.Format
+	numberMarkets numfmt.Markets
+
+	// Replay of sequenced messages on subscribe. replayMu is taken before mu
+	// and held while a sequenced message is recorded and its subscribers
+	// read, so a new subscriber gets each message either replayed or live.
+	replayMu    sync.Mutex
+	replay      map[string]*replayBuffer
+	replayStart uint64 // first sequence number broadcast
+	snapshot    SnapshotFunc
+
 	// Configuration
 	config *HubConfig
+
+	// Eviction metrics, updated atomically
+	pongTimeoutEvictions  int64
+	slowConsumerEvictions int64
+	droppedMessages       int64
+	subscriptionsRejected int64
 }
 
 // HubConfig contains hub configuration
 type HubConfig struct {
-	// Update intervals
-	TickerInterval time.Duration // Default: 100ms
-	DepthInterval  time.Duration // Default: 100ms
-	TradesBuffer   int           // Number of trades to buffer
+	// Update intervals. TickerInterval is also the shortest and default
+	// throttle of ticker subscriptions, MaxTickerThrottle the longest.
+	TickerInterval    time.Duration // Default: 100ms
+	MaxTickerThrottle time.Duration // Default: 1s
+	DepthInterval     time.Duration // Default: 100ms
+	TradesBuffer      int           // Number of trades to buffer
 
 	// Connection limits
 	MaxClientsPerIP    int
-	MaxSubscriptions   int
+	MaxSubscriptions   int // Per connection; 0 means unlimited
 
 	// Rate limiting
 	MessageRateLimit   int // Messages per second per client
+
+	// Liveness: the server pings every PingInterval and evicts connections
+	// that send neither a pong nor a message within PongTimeout
+	PingInterval       time.Duration // Default: 54s
+	PongTimeout        time.Duration // Default: 60s
+
+	// Slow consumers
+	SendQueueSize      int                // Outbound messages buffered per connection
+	SlowConsumerPolicy SlowConsumerPolicy // What to do when the queue is full
+
+	// Replay: sequenced messages kept per channel for subscribers resuming
+	// from a sequence number; 0 disables replay
+	ReplayBuffer int
+}
+
+// SlowConsumerPolicy decides what happens when a connection's send queue is full
+type SlowConsumerPolicy string
+
+const (
+	// SlowConsumerDropOldest discards the oldest queued message to make room
+	SlowConsumerDropOldest SlowConsumerPolicy = "drop_oldest"
+	// SlowConsumerDisconnect closes the connection; the client resubscribes
+	// and recovers from a fresh snapshot
+	SlowConsumerDisconnect SlowConsumerPolicy = "disconnect"
+)
+
+// ParseSlowConsumerPolicy parses a policy name
+func ParseSlowConsumerPolicy(s string) (SlowConsumerPolicy, error) {
+	switch policy := SlowConsumerPolicy(s); policy {
+	case SlowConsumerDropOldest, SlowConsumerDisconnect:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown slow consumer policy %q (want %s or %s)", s, SlowConsumerDropOldest, SlowConsumerDisconnect)
 }
 
+// Eviction reasons, also sent as the close frame reason
+const (
+	EvictPongTimeout  = "pong timeout"
+	EvictSlowConsumer = "slow consumer"
+)
+
 // DefaultHubConfig returns default hub configuration
 func DefaultHubConfig() *HubConfig {
 	return &HubConfig{
-		TickerInterval:     100 * time.Millisecond,
+		TickerInterval:     defaultTickerInterval,
+		MaxTickerThrottle:  defaultMaxTickerThrottle,
 		DepthInterval:      100 * time.Millisecond,
 		TradesBuffer:       100,
 		MaxClientsPerIP:    10,
 		MaxSubscriptions:   50,
 		MessageRateLimit:   100,
+		PingInterval:       defaultPingInterval,
+		PongTimeout:        defaultPongTimeout,
+		SendQueueSize:      defaultSendQueueSize,
+		SlowConsumerPolicy: SlowConsumerDropOldest,
+		ReplayBuffer:       DefaultReplayBuffer,
 	}
 }
 
+// withDefaults returns a copy of c with unset ticker, liveness and queue
+// settings filled in
+func (c HubConfig) withDefaults() *HubConfig {
+	if c.TickerInterval <= 0 {
+		c.TickerInterval = defaultTickerInterval
+	}
+	if c.MaxTickerThrottle <= 0 {
+		c.MaxTickerThrottle = defaultMaxTickerThrottle
+	}
+	if c.MaxTickerThrottle < c.TickerInterval {
+		c.MaxTickerThrottle = c.TickerInterval
+	}
+	if c.PongTimeout <= 0 {
+		c.PongTimeout = defaultPongTimeout
+	}
+	if c.PingInterval <= 0 || c.PingInterval >= c.PongTimeout {
+		c.PingInterval = (c.PongTimeout * 9) / 10
+	}
+	if c.SendQueueSize <= 0 {
+		c.SendQueueSize = defaultSendQueueSize
+	}
+	if c.SlowConsumerPolicy == "" {
+		c.SlowConsumerPolicy = SlowConsumerDropOldest
+	}
+	return &c
+}
+
+// HubStats reports connection counts and eviction metrics
+type HubStats struct {
+	Clients               int   `json:"clients"`
+	Channels              int   `json:"channels"`
+	PongTimeoutEvictions  int64 `json:"pong_timeout_evictions"`
+	SlowConsumerEvictions int64 `json:"slow_consumer_evictions"`
+	DroppedMessages       int64 `json:"dropped_messages"`
+	SubscriptionsRejected int64 `json:"subscriptions_rejected"`
+}
+
 // SubscriptionRequest represents a subscription request
 type SubscriptionRequest struct {
 	Client  *Client
 	Channel string
 	Action  string // "subscribe" or "unsubscribe"
+	Format  string // FormatJSON or FormatBinary; subscribe only
+	FromSeq uint64 // Replay from this sequence number; subscribe only, 0 for none
+	// Time between ticker pushes; ticker subscribe only
+	Throttle time.Duration
 }
 
 // NewHub creates a new Hub
@@ -80,17 +219,19 @@ func NewHub(config *HubConfig) *Hub {
 	}
 
 	return &Hub{
-		clients:       make(map[*Client]bool),
-		channels:      make(map[string]map[*Client]bool),
-		subscriptions: make(map[string]map[*Client]bool),
-		broadcast:     make(chan []byte, 256),
-		register:      make(chan *Client),
-		unregister:    make(chan *Client),
-		subscribe:     make(chan *SubscriptionRequest, 256),
-		unsubscribe:   make(chan *SubscriptionRequest, 256),
-		tickerBuffer:  make(map[string]*TickerMessage),
-		depthBuffer:   make(map[string]*DepthMessage),
-		config:        config,
+		clients:        make(map[*Client]bool),
+		channels:       make(map[string]map[*Client]bool),
+		subscriptions:  make(map[string]map[*Client]bool),
+		broadcast:      make(chan []byte, 256),
+		register:       make(chan *Client),
+		unregister:     make(chan *Client),
+		subscribe:      make(chan *SubscriptionRequest, 256),
+		unsubscribe:    make(chan *SubscriptionRequest, 256),
+		tickerBuffer:   make(map[string]*TickerMessage),
+		depthBuffer:    make(map[string]*DepthMessage),
+		tickerVersions: make(map[string]uint64),
+		replay:         make(map[string]*replayBuffer),
+		config:         config.withDefaults(),
 	}
 }
 
@@ -158,12 +299,18 @@ func (h *Hub) unregisterClient(client *Client) {
 			delete(h.subscriptions[topic], client)
 		}
 
-		close(client.send)
+		client.closeSend()
 	}
 }
 
-// handleSubscription handles a subscription request
+// handleSubscription handles a subscription request. With FromSeq the
+// buffered messages from that sequence number on follow the confirmation,
+// or a snapshot if some of them are no longer buffered.
 func (h *Hub) handleSubscription(req *SubscriptionRequest) {
+	if req.FromSeq > 0 {
+		h.replayMu.Lock()
+		defer h.replayMu.Unlock()
+	}
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -175,14 +322,47 @@ func (h *Hub) handleSubscription(req *SubscriptionRequest) {
 	}
 	h.channels[channel][client] = true
 
-	// Send subscription confirmation
+	// Send subscription confirmation, echoing a negotiated binary format
+	// and the outcome of a replay request
 	confirmation := &WSMessage{
 		Type:    "subscribed",
 		Channel: channel,
 		Data:    nil,
 	}
+	details := make(map[string]interface{})
+	if req.Format == FormatBinary {
+		details["format"] = FormatBinary
+		details["version"] = BinaryVersion
+	}
+	if req.Throttle > 0 {
+		details["throttle_ms"] = req.Throttle.Milliseconds()
+	}
+	var replayed []*WSMessage
+	gap := false
+	if req.FromSeq > 0 {
+		var ok bool
+		replayed, ok = h.replaySince(channel, req.FromSeq)
+		gap = !ok
+		details["from_seq"] = req.FromSeq
+		if gap {
+			details["replay_gap"] = true
+			details["snapshot"] = h.snapshot != nil
+		} else {
+			details["replayed"] = len(replayed)
+		}
+	}
+	if len(details) > 0 {
+		confirmation.Data = details
+	}
 	data, _ := json.Marshal(confirmation)
-	client.send <- data
+	client.Send(data)
+
+	for _, msg := range replayed {
+		h.sendMessage(client, channel, msg)
+	}
+	if gap && h.snapshot != nil {
+		go h.sendSnapshot(client, channel, h.snapshot)
+	}
 }
 
 // handleUnsubscription handles an unsubscription request
@@ -207,7 +387,7 @@ func (h *Hub) handleUnsubscription(req *SubscriptionRequest) {
 		Data:    nil,
 	}
 	data, _ := json.Marshal(confirmation)
-	client.send <- data
+	client.Send(data)
 }
 
 // broadcastMessage sends a message to all clients in a channel
@@ -216,77 +396,153 @@ func (h *Hub) broadcastMessage(message []byte) {
 	defer h.mu.RUnlock()
 
 	for client := range h.clients {
-		select {
-		case client.send <- message:
-		default:
-			// Client buffer is full, skip
-		}
+		client.Send(message)
 	}
 }
 
-// BroadcastToChannel sends a message to all clients subscribed to a channel
+// BroadcastToChannel sends a message to all clients subscribed to a channel.
+// Sequenced messages are also buffered for replay, even without subscribers.
 func (h *Hub) BroadcastToChannel(channel string, message interface{}) {
-	h.mu.RLock()
-	clients, ok := h.channels[channel]
-	if !ok {
-		h.mu.RUnlock()
-		return
+	var clientList []*Client
+	if msg, ok := message.(*WSMessage); ok && messageSeq(msg) > 0 {
+		h.replayMu.Lock()
+		h.recordReplay(channel, msg)
+		clientList = h.channelClients(channel)
+		h.replayMu.Unlock()
+	} else {
+		clientList = h.channelClients(channel)
 	}
-
-	// Make a copy of clients to avoid holding lock during send
-	clientList := make([]*Client, 0, len(clients))
-	for client := range clients {
-		clientList = append(clientList, client)
-	}
-	h.mu.RUnlock()
-
-	data, err := json.Marshal(message)
-	if err != nil {
+	if len(clientList) == 0 {
 		return
 	}
 
+	// JSON and binary encodings are each built once, on first use, and the
+	// JSON once per number format; messages without a binary form go out as
+	// JSON to every client
+	var data, frame []byte
+	encoded := false
+	var formatted map[numfmt.Format][]byte
+
+	private := isPrivateChannel(channel)
+	perm, licensed := channelPermission(channel)
 	for _, client := range clientList {
-		select {
-		case client.send <- data:
-		default:
-			// Client buffer is full, skip
+		// Never deliver private data past session expiry, or licensed data
+		// once the license has lapsed
+		if private && !client.sessionActive() {
+			continue
+		}
+		if licensed && !client.hasPermission(perm) {
+			continue
+		}
+		if client.wantsBinary(channel) {
+			if !encoded {
+				encoded = true
+				if msg, ok := message.(*WSMessage); ok {
+					frame, _ = EncodeBinary(msg)
+				}
+			}
+			if frame != nil {
+				client.SendBinary(frame)
+				continue
+			}
+		}
+		if data == nil {
+			var err error
+			if data, err = json.Marshal(message); err != nil {
+				return
+			}
+		}
+		if client.numbers == nil {
+			client.Send(data)
+			continue
 		}
+		format := client.numbers.Format()
+		out, ok := formatted[format]
+		if !ok {
+			if formatted == nil {
+				formatted = make(map[numfmt.Format][]byte)
+			}
+			out = client.formatJSON(data)
+			formatted[format] = out
+		}
+		client.enqueue(outboundMessage{data: out})
 	}
 }
 
+// channelClients returns a copy of a channel's subscribers, so messages are
+// sent without holding the lock
+func (h *Hub) channelClients(channel string) []*Client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	clients := h.channels[channel]
+	clientList := make([]*Client, 0, len(clients))
+	for client := range clients {
+		clientList = append(clientList, client)
+	}
+	return clientList
+}
+
 // ============ Channel-specific broadcasts ============
 
-// UpdateTicker updates the ticker buffer for a market
+// UpdateTicker updates the ticker buffer for a market. Subscribers are
+// pushed the latest ticker on the hub's next tick past their throttle.
 func (h *Hub) UpdateTicker(marketID string, ticker *TickerMessage) {
 	h.mu.Lock()
 	h.tickerBuffer[marketID] = ticker
+	h.tickerVersions[marketID]++
 	h.mu.Unlock()
 }
 
-// UpdateDepth updates the depth buffer for a market
+// UpdateDepth updates the depth buffer for a market. The depth carries the
+// checksum its source computed over the levels, which the hub sends as is.
 func (h *Hub) UpdateDepth(marketID string, depth *DepthMessage) {
 	h.mu.Lock()
 	h.depthBuffer[marketID] = depth
 	h.mu.Unlock()
 }
 
-// broadcastTickers broadcasts all ticker updates
+// broadcastTickers pushes each market's latest ticker to the subscribers
+// that have not been sent it and whose throttle has elapsed. Pushes are
+// conflated: a ticker a slow consumer has yet to write is replaced by the
+// newer one, so it always catches up to the latest values.
 func (h *Hub) broadcastTickers() {
+	now := time.Now()
 	h.mu.RLock()
 	tickers := make(map[string]*TickerMessage)
+	versions := make(map[string]uint64)
 	for k, v := range h.tickerBuffer {
 		tickers[k] = v
+		versions[k] = h.tickerVersions[k]
 	}
 	h.mu.RUnlock()
 
 	for marketID, ticker := range tickers {
 		channel := "ticker:" + marketID
-		msg := &WSMessage{
-			Type:    "ticker",
-			Channel: channel,
-			Data:    ticker,
+		var data []byte
+		var formatted map[numfmt.Format][]byte
+		for _, client := range h.channelClients(channel) {
+			if !client.tickerDue(channel, versions[marketID], now, h.config.TickerInterval/2) {
+				continue
+			}
+			if data == nil {
+				var err error
+				if data, err = json.Marshal(&WSMessage{Type: "ticker", Channel: channel, Data: ticker}); err != nil {
+					break
+				}
+				formatted = make(map[numfmt.Format][]byte)
+			}
+			out := data
+			if client.numbers != nil {
+				format := client.numbers.Format()
+				var ok bool
+				if out, ok = formatted[format]; !ok {
+					out = client.formatJSON(data)
+					formatted[format] = out
+				}
+			}
+			client.sendConflated(channel, out)
 		}
-		h.BroadcastToChannel(channel, msg)
 	}
 }
 
@@ -321,6 +577,30 @@ func (h *Hub) BroadcastTrade(marketID string, trade *TradeMessage) {
 	h.BroadcastToChannel(channel, msg)
 }
 
+// BroadcastL3 sends a market-by-order update to the market's licensed L3
+// channel
+func (h *Hub) BroadcastL3(update *types.L3Update) {
+	channel := "l3:" + update.MarketID
+	msg := &WSMessage{
+		Type:    "l3",
+		Channel: channel,
+		Data:    update,
+	}
+	h.BroadcastToChannel(channel, msg)
+}
+
+// BroadcastLongShortRatio sends a market's long/short ratio to its public
+// stats channel
+func (h *Hub) BroadcastLongShortRatio(ratio *types.LongShortRatio) {
+	channel := "stats:" + ratio.MarketID
+	msg := &WSMessage{
+		Type:    "long_short_ratio",
+		Channel: channel,
+		Data:    ratio,
+	}
+	h.BroadcastToChannel(channel, msg)
+}
+
 // BroadcastPosition broadcasts a position update to a specific user
 func (h *Hub) BroadcastPosition(userID string, position *PositionMessage) {
 	channel := "positions:" + userID
@@ -332,6 +612,54 @@ func (h *Hub) BroadcastPosition(userID string, position *PositionMessage) {
 	h.BroadcastToChannel(channel, msg)
 }
 
+// BroadcastADLIndicator sends a change in a position's ADL queue indicator to
+// its owner on the positions channel
+func (h *Hub) BroadcastADLIndicator(userID string, indicator *types.ADLIndicator) {
+	channel := "positions:" + userID
+	msg := &WSMessage{
+		Type:    "adl",
+		Channel: channel,
+		Data:    indicator,
+	}
+	h.BroadcastToChannel(channel, msg)
+}
+
+// BroadcastMarginCall sends a margin call warning to the account owner on
+// the positions channel
+func (h *Hub) BroadcastMarginCall(userID string, call *types.MarginCall) {
+	channel := "positions:" + userID
+	msg := &WSMessage{
+		Type:    "margin_call",
+		Channel: channel,
+		Data:    call,
+	}
+	h.BroadcastToChannel(channel, msg)
+}
+
+// BroadcastOrderReject sends a rejected order placement or amendment to the
+// trader on the orders channel
+func (h *Hub) BroadcastOrderReject(userID string, reject *types.OrderReject) {
+	channel := "orders:" + userID
+	msg := &WSMessage{
+		Type:    "order_reject",
+		Channel: channel,
+		Data:    reject,
+	}
+	h.BroadcastToChannel(channel, msg)
+}
+
+// BroadcastExecution sends one fill of an order to the trader on the orders
+// channel
+func (h *Hub) BroadcastExecution(userID string, exec *types.Execution) {
+	channel := "orders:" + userID
+	msg := &WSMessage{
+		Type:    "execution",
+		Channel: channel,
+		Data:    exec,
+	}
+	h.BroadcastToChannel(channel, msg)
+}
+
 // BroadcastOrder broadcasts an order update to a specific user
 func (h *Hub) BroadcastOrder(userID string, order *OrderMessage) {
 	channel := "orders:" + userID
@@ -431,17 +759,21 @@ type WSMessage struct {
 
 // TickerMessage represents a ticker update
 type TickerMessage struct {
-	MarketID    string `json:"market_id"`
-	MarkPrice   string `json:"mark_price"`
-	IndexPrice  string `json:"index_price"`
-	LastPrice   string `json:"last_price"`
-	High24h     string `json:"high_24h"`
-	Low24h      string `json:"low_24h"`
-	Volume24h   string `json:"volume_24h"`
-	Change24h   string `json:"change_24h"`
-	FundingRate string `json:"funding_rate"`
-	NextFunding int64  `json:"next_funding"`
-	Timestamp   int64  `json:"timestamp"`
+	MarketID     string               `json:"market_id"`
+	MarkPrice    string               `json:"mark_price"`
+	IndexPrice   string               `json:"index_price"`
+	LastPrice    string               `json:"last_price"`
+	High24h      string               `json:"high_24h"`
+	Low24h       string               `json:"low_24h"`
+	Volume24h    string               `json:"volume_24h"`
+	Change24h    string               `json:"change_24h"`
+	FundingRate  string               `json:"funding_rate"`
+	NextFunding  int64                `json:"next_funding"`
+	OpenInterest string               `json:"open_interest"`
+	Basis        string               `json:"basis"`      // mark - index
+	BasisRate    string               `json:"basis_rate"` // basis / index
+	IndexSources []*types.IndexSource `json:"index_sources,omitempty"`
+	Timestamp    int64                `json:"timestamp"`
 }
 
 // DepthMessage represents orderbook depth
@@ -449,9 +781,24 @@ type DepthMessage struct {
 	MarketID  string       `json:"market_id"`
 	Bids      []PriceLevel `json:"bids"`
 	Asks      []PriceLevel `json:"asks"`
+	Checksum  int32        `json:"checksum"` // CRC32 of the top levels, see ComputeChecksum
 	Timestamp int64        `json:"timestamp"`
 }
 
+// ComputeChecksum returns the CRC32 checksum of the message's top levels,
+// using the same scheme as the REST order book endpoint
+func (d *DepthMessage) ComputeChecksum() int32 {
+	return obtypes.DepthChecksum(levelStrings(d.Bids), levelStrings(d.Asks))
+}
+
+func levelStrings(levels []PriceLevel) [][]string {
+	result := make([][]string, len(levels))
+	for i, level := range levels {
+		result[i] = []string{level.Price, level.Quantity}
+	}
+	return result
+}
+
 // PriceLevel represents a price level in the orderbook
 type PriceLevel struct {
 	Price    string `json:"price"`
@@ -466,6 +813,7 @@ type TradeMessage struct {
 	Quantity  string `json:"quantity"`
 	Side      string `json:"side"` // "buy" or "sell"
 	Timestamp int64  `json:"timestamp"`
+	Seq       uint64 `json:"seq,omitempty"` // global event sequence number
 }
 
 // PositionMessage represents a position update
@@ -495,6 +843,7 @@ type OrderMessage struct {
 	FilledSize string `json:"filled_size"`
 	Status     string `json:"status"`
 	Timestamp  int64  `json:"timestamp"`
+	Seq        uint64 `json:"seq,omitempty"` // global event sequence number
 }
 
 // ============ RiverPool Message Types ============
@@ -597,8 +946,146 @@ func (h *Hub) GetChannelClientCount(channel string) int {
 	return 0
 }
 
+// Stats returns connection counts and eviction metrics
+func (h *Hub) Stats() HubStats {
+	h.mu.RLock()
+	clients, channels := len(h.clients), len(h.channels)
+	h.mu.RUnlock()
+
+	return HubStats{
+		Clients:               clients,
+		Channels:              channels,
+		PongTimeoutEvictions:  atomic.LoadInt64(&h.pongTimeoutEvictions),
+		SlowConsumerEvictions: atomic.LoadInt64(&h.slowConsumerEvictions),
+		DroppedMessages:       atomic.LoadInt64(&h.droppedMessages),
+		SubscriptionsRejected: atomic.LoadInt64(&h.subscriptionsRejected),
+	}
+}
+
+// recordEviction counts a connection evicted for reason
+func (h *Hub) recordEviction(reason string) {
+	switch reason {
+	case EvictPongTimeout:
+		atomic.AddInt64(&h.pongTimeoutEvictions, 1)
+	case EvictSlowConsumer:
+		atomic.AddInt64(&h.slowConsumerEvictions, 1)
+	}
+}
+
+// IsDraining returns whether the hub is draining
+func (h *Hub) IsDraining() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.draining
+}
+
+// CancelOnDisconnectUsers returns the users of connected sessions that
+// requested their resting orders be cancelled when the session ends
+func (h *Hub) CancelOnDisconnectUsers() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	users := make([]string, 0)
+	for client := range h.clients {
+		userID := client.GetUserID()
+		if client.cancelOnDisconnect && userID != "" && !seen[userID] {
+			seen[userID] = true
+			users = append(users, userID)
+		}
+	}
+	return users
+}
+
+// Drain stops accepting new connections, waits until queued broadcasts have been
+// written to every client (or ctx expires), then closes all connections with a
+// "going away" close frame so clients reconnect elsewhere.
+func (h *Hub) Drain(ctx context.Context) error {
+	h.mu.Lock()
+	h.draining = true
+	h.mu.Unlock()
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	var err error
+wait:
+	for h.pendingMessages() > 0 {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break wait
+		case <-ticker.C:
+		}
+	}
+
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server draining")
+	for _, client := range clients {
+		_ = client.conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(writeWait))
+		client.conn.Close()
+	}
+
+	return err
+}
+
+// pendingMessages returns the number of queued hub broadcasts and unsent client messages
+func (h *Hub) pendingMessages() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	pending := len(h.broadcast)
+	for client := range h.clients {
+		pending += len(client.send)
+	}
+	return pending
+}
+
 // ServeWS handles WebSocket upgrade requests
 func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
+	if h.IsDraining() {
+		apiErr := types.NewAPIError(types.ErrCodeServiceUnavailable, "Server is draining, reconnect to another node")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Connection", "close")
+		w.WriteHeader(apiErr.Code.HTTPStatus())
+		_ = json.NewEncoder(w).Encode(types.NewErrorResponse(apiErr))
+		return
+	}
+
+	// A session token may be passed in the handshake; without one the
+	// connection is anonymous and limited to public channels
+	var session *auth.Session
+	if token := sessionTokenFromRequest(r); token != "" {
+		var err error
+		if session, err = h.verifySession(token); err != nil {
+			apiErr := types.ToAPIError(err, types.ErrCodeInvalidToken)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(apiErr.Code.HTTPStatus())
+			_ = json.NewEncoder(w).Encode(types.NewErrorResponse(apiErr))
+			return
+		}
+	}
+
+	// Decimals are sent in the format named by number_format or the
+	// X-Number-Format header, else the hub's
+	format := h.numberFormat
+	if requested := numberFormatFromRequest(r); requested != "" {
+		var err error
+		if format, err = numfmt.ParseFormat(requested); err != nil {
+			apiErr := types.NewAPIError(types.ErrCodeInvalidRequest, err.Error())
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(apiErr.Code.HTTPStatus())
+			_ = json.NewEncoder(w).Encode(types.NewErrorResponse(apiErr))
+			return
+		}
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
@@ -609,10 +1096,16 @@ func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
 		clientID = generateID()
 	}
 
-	userID := r.URL.Query().Get("user_id")
 	ip := getClientIPFromRequest(r)
 
-	client := NewClient(h, conn, clientID, userID, ip)
+	client := NewClient(h, conn, clientID, "", ip)
+	if session != nil {
+		client.setSession(session)
+	}
+	if format != numfmt.FormatRaw {
+		client.numbers = numfmt.NewFormatter(format, h.numberMarkets)
+	}
+	client.cancelOnDisconnect = r.URL.Query().Get("cancel_on_disconnect") == "true"
 
 	h.register <- client
 
@@ -620,6 +1113,72 @@ func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
 	go client.readPump()
 }
 
+// SetNumberFormat sets the number format of connections that ask for none
+// and the markets' decimal places. Call it before the hub serves connections.
+func (h *Hub) SetNumberFormat(format numfmt.Format, markets numfmt.Markets) {
+	h.numberFormat = format
+	h.numberMarkets = markets
+}
+
+// numberFormatFromRequest returns the number format a handshake asks for
+func numberFormatFromRequest(r *http.Request) string {
+	if format := r.URL.Query().Get(numfmt.QueryParam); format != "" {
+		return format
+	}
+	return r.Header.Get(numfmt.Header)
+}
+
+// SetSessionManager enables session authentication for private channels
+func (h *Hub) SetSessionManager(sessions *auth.SessionManager) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sessions = sessions
+}
+
+// SetFaultDrop installs a resilience-testing hook that silently discards
+// outbound messages for which drop returns true. Call it before Run.
+func (h *Hub) SetFaultDrop(drop func() bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.faultDrop = drop
+}
+
+// verifySession validates a session token
+func (h *Hub) verifySession(token string) (*auth.Session, error) {
+	h.mu.RLock()
+	sessions := h.sessions
+	h.mu.RUnlock()
+
+	if sessions == nil {
+		return nil, types.NewAPIError(types.ErrCodeNotImplemented, "Session authentication is not enabled")
+	}
+	return sessions.Verify(token)
+}
+
+// refreshSession extends a session and issues a fresh token for it
+func (h *Hub) refreshSession(session *auth.Session) (string, *auth.Session, error) {
+	h.mu.RLock()
+	sessions := h.sessions
+	h.mu.RUnlock()
+
+	if sessions == nil {
+		return "", nil, types.NewAPIError(types.ErrCodeNotImplemented, "Session authentication is not enabled")
+	}
+	return sessions.Refresh(session)
+}
+
+// sessionTokenFromRequest reads a handshake token from the "token" query
+// parameter or an "Authorization: Bearer" header
+func sessionTokenFromRequest(r *http.Request) string {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token
+	}
+	if authz := r.Header.Get("Authorization"); len(authz) > 7 && authz[:7] == "Bearer " {
+		return authz[7:]
+	}
+	return ""
+}
+
 // Helper function to get client IP
 func getClientIPFromRequest(r *http.Request) string {
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
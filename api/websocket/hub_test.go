@@ -0,0 +1,326 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/openalpha/perp-dex/api/auth"
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// startTestHub runs a hub behind an httptest server and returns its ws:// URL
+func startTestHub(t *testing.T, config *HubConfig) (*Hub, string) {
+	t.Helper()
+	hub := NewHub(config)
+	go hub.Run()
+	srv := httptest.NewServer(http.HandlerFunc(hub.ServeWS))
+	t.Cleanup(srv.Close)
+	return hub, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+// waitFor polls cond until it holds or a second passes
+func waitFor(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestSubscriptionLimit tests that distinct channels beyond the limit are
+// rejected while re-subscribing is allowed
+func TestSubscriptionLimit(t *testing.T) {
+	config := DefaultHubConfig()
+	config.MaxSubscriptions = 2
+	hub, url := startTestHub(t, config)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	var replies []string
+	for _, channel := range []string{"ticker:BTC-USDC", "ticker:ETH-USDC", "ticker:BTC-USDC", "ticker:SOL-USDC"} {
+		if err := conn.WriteJSON(ClientMessage{Action: "subscribe", Channel: channel}); err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+		var msg WSMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("failed to read reply: %v", err)
+		}
+		replies = append(replies, msg.Type)
+	}
+
+	if got := strings.Join(replies, ","); got != "subscribed,subscribed,subscribed,error" {
+		t.Errorf("unexpected replies %s", got)
+	}
+	if stats := hub.Stats(); stats.SubscriptionsRejected != 1 {
+		t.Errorf("expected 1 rejected subscription, got %d", stats.SubscriptionsRejected)
+	}
+}
+
+// TestPongTimeoutEviction tests that a peer that never answers pings is evicted
+func TestPongTimeoutEviction(t *testing.T) {
+	config := DefaultHubConfig()
+	config.PongTimeout = 150 * time.Millisecond
+	hub, url := startTestHub(t, config)
+
+	// The client never reads, so it never answers the server's pings
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	waitFor(t, func() bool { return hub.Stats().PongTimeoutEvictions == 1 }, "expected a pong timeout eviction")
+	waitFor(t, func() bool { return hub.GetClientCount() == 0 }, "expected evicted client to be unregistered")
+}
+
+// TestSlowConsumerPolicies tests both policies on a client whose write pump
+// is not draining its queue
+func TestSlowConsumerPolicies(t *testing.T) {
+	t.Run("drop_oldest", func(t *testing.T) {
+		config := DefaultHubConfig()
+		config.SendQueueSize = 2
+		hub := NewHub(config)
+		client := NewClient(hub, nil, "c1", "", "127.0.0.1")
+
+		for i := 1; i <= 4; i++ {
+			data, _ := json.Marshal(i)
+			client.Send(data)
+		}
+		if first, second := string((<-client.send).data), string((<-client.send).data); first != "3" || second != "4" {
+			t.Errorf("expected the newest messages 3 and 4 to be kept, got %s and %s", first, second)
+		}
+		if stats := hub.Stats(); stats.DroppedMessages != 2 {
+			t.Errorf("expected 2 dropped messages, got %d", stats.DroppedMessages)
+		}
+
+		// Sends after unregister are discarded rather than panicking
+		client.closeSend()
+		client.Send([]byte("late"))
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		config := DefaultHubConfig()
+		config.SendQueueSize = 1
+		config.SlowConsumerPolicy = SlowConsumerDisconnect
+		hub := NewHub(config)
+
+		serverConn := make(chan *websocket.Conn, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			serverConn <- conn
+		}))
+		defer srv.Close()
+
+		peer, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		defer peer.Close()
+		client := NewClient(hub, <-serverConn, "c1", "", "127.0.0.1")
+
+		client.Send([]byte(`"first"`))
+		client.Send([]byte(`"second"`))
+
+		_, _, err = peer.ReadMessage()
+		if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+			t.Fatalf("expected a policy violation close, got %v", err)
+		}
+		if stats := hub.Stats(); stats.SlowConsumerEvictions != 1 {
+			t.Errorf("expected 1 slow consumer eviction, got %d", stats.SlowConsumerEvictions)
+		}
+	})
+}
+
+// TestL3ChannelLicense tests that the L3 channel is only available to, and
+// only delivered to, sessions holding the L3 data license
+func TestL3ChannelLicense(t *testing.T) {
+	hub, url := startTestHub(t, nil)
+	sessions := auth.NewSessionManager(auth.Config{
+		APIKeys:     auth.StaticAPIKeys{"licensed": "mm-licensed", "plain": "mm-plain"},
+		Permissions: auth.StaticPermissions{"mm-licensed": {auth.PermissionL3Data}},
+	})
+	hub.SetSessionManager(sessions)
+
+	subscribe := func(apiKey string) (*websocket.Conn, string) {
+		token, _, err := sessions.LoginWithAPIKey(apiKey)
+		if err != nil {
+			t.Fatalf("failed to log in: %v", err)
+		}
+		conn, _, err := websocket.DefaultDialer.Dial(url+"?token="+token, nil)
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		if err := conn.WriteJSON(ClientMessage{Action: "subscribe", Channel: "l3:BTC-USDC"}); err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+		var reply WSMessage
+		if err := conn.ReadJSON(&reply); err != nil {
+			t.Fatalf("failed to read reply: %v", err)
+		}
+		return conn, reply.Type
+	}
+
+	if _, reply := subscribe("plain"); reply != "error" {
+		t.Errorf("expected an unlicensed subscription to be rejected, got %s", reply)
+	}
+	conn, reply := subscribe("licensed")
+	if reply != "subscribed" {
+		t.Fatalf("expected the licensed subscription, got %s", reply)
+	}
+	waitFor(t, func() bool { return hub.GetChannelClientCount("l3:BTC-USDC") == 1 }, "expected the L3 subscriber")
+
+	hub.BroadcastL3(&types.L3Update{Seq: 7, MarketID: "BTC-USDC", Action: types.L3ActionAdd, OrderID: "order-1"})
+	var msg WSMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "l3" || msg.Channel != "l3:BTC-USDC" {
+		t.Fatalf("expected the L3 update, got %+v (%v)", msg, err)
+	}
+}
+
+// TestTickerThrottle tests that ticker subscriptions are pushed each update
+// once, no more often than their throttle, and that a push still queued is
+// replaced by the newer ticker
+func TestTickerThrottle(t *testing.T) {
+	hub := NewHub(&HubConfig{TickerInterval: 10 * time.Millisecond, MaxTickerThrottle: time.Second})
+	client := NewClient(hub, nil, "c1", "", "127.0.0.1")
+
+	// next returns the type and, for tickers, the last price of the next queued message
+	next := func() (string, string) {
+		t.Helper()
+		var queued outboundMessage
+		select {
+		case queued = <-client.send:
+		default:
+			t.Fatal("expected a queued message")
+		}
+		if queued.conflate != "" {
+			var ok bool
+			if queued, ok = client.takeConflated(queued.conflate); !ok {
+				t.Fatal("expected the conflated message")
+			}
+		}
+		var msg struct {
+			Type string        `json:"type"`
+			Data TickerMessage `json:"data"`
+		}
+		if err := json.Unmarshal(queued.data, &msg); err != nil {
+			t.Fatalf("failed to decode %s: %v", queued.data, err)
+		}
+		return msg.Type, msg.Data.LastPrice
+	}
+	subscribe := func(channel string, throttleMs int64) string {
+		t.Helper()
+		client.handleSubscribe(channel, "", 0, throttleMs)
+		select {
+		case req := <-hub.subscribe:
+			hub.handleSubscription(req)
+		default:
+		}
+		reply, _ := next()
+		return reply
+	}
+	update := func(lastPrice string) {
+		for _, marketID := range []string{"BTC-USDC", "ETH-USDC"} {
+			hub.UpdateTicker(marketID, &TickerMessage{MarketID: marketID, LastPrice: lastPrice})
+		}
+	}
+
+	if reply := subscribe("depth:BTC-USDC", 100); reply != "error" {
+		t.Errorf("expected throttle_ms on a depth channel to be rejected, got %s", reply)
+	}
+	if reply := subscribe("ticker:BTC-USDC", 5000); reply != "error" {
+		t.Errorf("expected a throttle above the maximum to be rejected, got %s", reply)
+	}
+	if reply := subscribe("ticker:BTC-USDC", 200); reply != "subscribed" {
+		t.Fatalf("expected the throttled subscription, got %s", reply)
+	}
+	if reply := subscribe("ticker:ETH-USDC", 0); reply != "subscribed" {
+		t.Fatalf("expected the default subscription, got %s", reply)
+	}
+
+	update("1")
+	hub.broadcastTickers()
+	hub.broadcastTickers()
+	if n := len(client.send); n != 2 {
+		t.Fatalf("expected one push per market, got %d", n)
+	}
+
+	// ETH-USDC is due again and its unwritten push is replaced; BTC-USDC
+	// is still within its throttle
+	update("2")
+	time.Sleep(20 * time.Millisecond)
+	hub.broadcastTickers()
+	if n := len(client.send); n != 2 {
+		t.Fatalf("expected the queued pushes to be conflated, got %d", n)
+	}
+	prices := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		if msgType, price := next(); msgType == "ticker" {
+			prices[price] = true
+		}
+	}
+	if !prices["1"] || !prices["2"] {
+		t.Errorf("expected the throttled 1 and the conflated 2, got %v", prices)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	hub.broadcastTickers()
+	if n := len(client.send); n != 1 {
+		t.Fatalf("expected only the throttled market's pending update, got %d", n)
+	}
+	if _, price := next(); price != "2" {
+		t.Errorf("expected the latest BTC-USDC ticker, got %s", price)
+	}
+}
+
+// TestRefreshWithoutSessionManager tests that a refresh on a hub whose
+// session authentication has been turned off is rejected instead of panicking
+func TestRefreshWithoutSessionManager(t *testing.T) {
+	sessions := auth.NewSessionManager(auth.Config{APIKeys: auth.StaticAPIKeys{"key": "mm-1"}})
+	_, session, err := sessions.LoginWithAPIKey("key")
+	if err != nil {
+		t.Fatalf("failed to log in: %v", err)
+	}
+
+	hub := NewHub(&HubConfig{})
+	client := NewClient(hub, nil, "c1", "", "127.0.0.1")
+	client.setSession(session)
+
+	refresh := func() WSMessage {
+		t.Helper()
+		client.handleRefresh()
+		var msg WSMessage
+		select {
+		case queued := <-client.send:
+			if err := json.Unmarshal(queued.data, &msg); err != nil {
+				t.Fatalf("failed to decode %s: %v", queued.data, err)
+			}
+		default:
+			t.Fatal("expected a reply")
+		}
+		return msg
+	}
+
+	if msg := refresh(); msg.Type != "error" {
+		t.Errorf("expected the refresh rejected, got %+v", msg)
+	}
+	hub.SetSessionManager(sessions)
+	if msg := refresh(); msg.Type != "token_refreshed" {
+		t.Errorf("expected a refreshed token, got %+v", msg)
+	}
+}
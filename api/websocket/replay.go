@@ -0,0 +1,122 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// DefaultReplayBuffer is how many sequenced messages the hub keeps per
+// channel for replay on subscribe
+const DefaultReplayBuffer = 1000
+
+// SnapshotFunc returns the current state of a channel, e.g. the recent
+// trades of a trades channel, for a subscriber whose replay gap is too
+// large; ok is false when the channel has no snapshot
+type SnapshotFunc func(channel string) (data interface{}, ok bool)
+
+// replayBuffer keeps the latest sequenced messages of one channel, oldest
+// first. Every message of the channel with a sequence number of at least
+// from is in it.
+type replayBuffer struct {
+	messages []*WSMessage
+	from     uint64
+}
+
+// messageSeq returns the global event sequence number a message carries, or
+// 0 if it is not sequenced
+func messageSeq(msg *WSMessage) uint64 {
+	switch data := msg.Data.(type) {
+	case *TradeMessage:
+		return data.Seq
+	case *OrderMessage:
+		return data.Seq
+	case *types.L3Update:
+		return data.Seq
+	}
+	return 0
+}
+
+// recordReplay buffers a sequenced message of channel, evicting the oldest
+// beyond the replay buffer size. The caller holds replayMu.
+func (h *Hub) recordReplay(channel string, msg *WSMessage) {
+	size := h.config.ReplayBuffer
+	seq := messageSeq(msg)
+	if size <= 0 || seq == 0 {
+		return
+	}
+	// Messages are broadcast in sequence order, so every message from the
+	// first one the hub saw on is buffered until evicted
+	if h.replayStart == 0 {
+		h.replayStart = seq
+	}
+
+	buf := h.replay[channel]
+	if buf == nil {
+		buf = &replayBuffer{from: h.replayStart}
+		h.replay[channel] = buf
+	}
+	buf.messages = append(buf.messages, msg)
+	if over := len(buf.messages) - size; over > 0 {
+		buf.from = messageSeq(buf.messages[over-1]) + 1
+		buf.messages = buf.messages[over:]
+	}
+}
+
+// replaySince returns the buffered messages of channel with sequence numbers
+// of at least fromSeq. It returns false when some of them may be missing:
+// evicted, or broadcast before the hub started. The caller holds replayMu.
+func (h *Hub) replaySince(channel string, fromSeq uint64) ([]*WSMessage, bool) {
+	if h.config.ReplayBuffer <= 0 || h.replayStart == 0 {
+		return nil, false
+	}
+	buf := h.replay[channel]
+	if buf == nil {
+		return nil, fromSeq >= h.replayStart
+	}
+	if fromSeq < buf.from {
+		return nil, false
+	}
+	i := sort.Search(len(buf.messages), func(i int) bool {
+		return messageSeq(buf.messages[i]) >= fromSeq
+	})
+	return append([]*WSMessage(nil), buf.messages[i:]...), true
+}
+
+// sendMessage sends one channel message to a client in the format it
+// subscribed with
+func (h *Hub) sendMessage(client *Client, channel string, msg *WSMessage) {
+	if client.wantsBinary(channel) {
+		if frame, err := EncodeBinary(msg); err == nil {
+			client.SendBinary(frame)
+			return
+		}
+	}
+	if data, err := json.Marshal(msg); err == nil {
+		client.Send(data)
+	}
+}
+
+// sendSnapshot sends a channel's snapshot to a subscriber that could not be
+// caught up by replay. It runs off the hub loop, so live messages may
+// arrive first; their sequence numbers tell them apart.
+func (h *Hub) sendSnapshot(client *Client, channel string, snapshot SnapshotFunc) {
+	data, ok := snapshot(channel)
+	if !ok {
+		return
+	}
+	h.sendMessage(client, channel, &WSMessage{
+		Type:    "snapshot",
+		Channel: channel,
+		Data:    data,
+	})
+}
+
+// SetSnapshotProvider sets the snapshots sent to subscribers whose replay
+// gap is too large. Call it before Run.
+func (h *Hub) SetSnapshotProvider(snapshot SnapshotFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.snapshot = snapshot
+}
@@ -0,0 +1,98 @@
+package websocket
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// readMessages reads JSON messages until n arrive, splitting frames that
+// batch several messages
+func readMessages(t *testing.T, conn *websocket.Conn, n int) []WSMessage {
+	t.Helper()
+	var msgs []WSMessage
+	for len(msgs) < n {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, frame, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("failed to read after %d messages: %v", len(msgs), err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(frame)), "\n") {
+			var msg WSMessage
+			if err := json.Unmarshal([]byte(line), &msg); err != nil {
+				t.Fatalf("failed to decode %q: %v", line, err)
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+// TestReplayFromSeq tests that a subscriber is caught up from the buffer
+// exactly once, and sent a snapshot when the buffer no longer reaches back
+func TestReplayFromSeq(t *testing.T) {
+	config := DefaultHubConfig()
+	config.ReplayBuffer = 3
+	hub, url := startTestHub(t, config)
+	hub.SetSnapshotProvider(func(channel string) (interface{}, bool) {
+		return map[string]string{"channel": channel}, channel == "trades:BTC-USDC"
+	})
+
+	// Seq 1 and 2 are evicted; 3, 4 and 5 remain
+	for seq := uint64(1); seq <= 5; seq++ {
+		hub.BroadcastTrade("BTC-USDC", &TradeMessage{TradeID: "t", MarketID: "BTC-USDC", Seq: seq})
+	}
+
+	dial := func(channel string, fromSeq uint64) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		if err := conn.WriteJSON(ClientMessage{Action: "subscribe", Channel: channel, FromSeq: fromSeq}); err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+		return conn
+	}
+	seqOf := func(msg WSMessage) uint64 {
+		data, _ := msg.Data.(map[string]interface{})
+		seq, _ := data["seq"].(float64)
+		return uint64(seq)
+	}
+
+	conn := dial("trades:BTC-USDC", 4)
+	msgs := readMessages(t, conn, 3)
+	details, _ := msgs[0].Data.(map[string]interface{})
+	if msgs[0].Type != "subscribed" || details["replayed"] != float64(2) {
+		t.Fatalf("expected a confirmation with 2 replayed, got %+v", msgs[0])
+	}
+	if seqOf(msgs[1]) != 4 || seqOf(msgs[2]) != 5 {
+		t.Fatalf("expected seq 4 and 5 replayed, got %+v", msgs[1:])
+	}
+	hub.BroadcastTrade("BTC-USDC", &TradeMessage{TradeID: "t", MarketID: "BTC-USDC", Seq: 6})
+	if live := readMessages(t, conn, 1); seqOf(live[0]) != 6 {
+		t.Errorf("expected live seq 6 after the replay, got %+v", live[0])
+	}
+
+	// Seq 2 was evicted, so the subscriber gets a snapshot instead
+	conn = dial("trades:BTC-USDC", 2)
+	msgs = readMessages(t, conn, 2)
+	details, _ = msgs[0].Data.(map[string]interface{})
+	if details["replay_gap"] != true || details["snapshot"] != true {
+		t.Fatalf("expected a replay gap with a snapshot, got %+v", msgs[0])
+	}
+	if msgs[1].Type != "snapshot" {
+		t.Errorf("expected a snapshot, got %+v", msgs[1])
+	}
+
+	// A channel with nothing buffered replays nothing from a seq the hub saw
+	conn = dial("trades:ETH-USDC", 4)
+	msgs = readMessages(t, conn, 1)
+	details, _ = msgs[0].Data.(map[string]interface{})
+	if details["replayed"] != float64(0) {
+		t.Errorf("expected an empty replay, got %+v", msgs[0])
+	}
+}
@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,6 +10,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/openalpha/perp-dex/api/auth"
+	"github.com/openalpha/perp-dex/api/types"
 )
 
 // Server represents the WebSocket server
@@ -124,10 +127,26 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 	// Check IP connection limit
 	if !s.checkIPLimit(ip) {
-		http.Error(w, "Too many connections from this IP", http.StatusTooManyRequests)
+		apiErr := types.NewAPIError(types.ErrCodeConnectionLimit, "Too many connections from this IP")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(apiErr.Code.HTTPStatus())
+		_ = json.NewEncoder(w).Encode(types.NewErrorResponse(apiErr))
 		return
 	}
 
+	// Verify an optional handshake session token before upgrading
+	var session *auth.Session
+	if token := sessionTokenFromRequest(r); token != "" {
+		var err error
+		if session, err = s.hub.verifySession(token); err != nil {
+			apiErr := types.ToAPIError(err, types.ErrCodeInvalidToken)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(apiErr.Code.HTTPStatus())
+			_ = json.NewEncoder(w).Encode(types.NewErrorResponse(apiErr))
+			return
+		}
+	}
+
 	// Upgrade to WebSocket
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -137,9 +156,10 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 	// Create client
 	clientID := uuid.New().String()
-	userID := r.URL.Query().Get("user_id") // Optional: from query param or auth header
-
-	client := NewClient(s.hub, conn, clientID, userID, ip)
+	client := NewClient(s.hub, conn, clientID, "", ip)
+	if session != nil {
+		client.setSession(session)
+	}
 
 	// Register client
 	s.registerConnection(client)
@@ -164,19 +184,24 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // handleStats handles stats requests
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	hubStats := s.hub.Stats()
+
 	s.metricsMu.RLock()
 	stats := map[string]interface{}{
-		"total_connections":  s.totalConnections,
-		"active_connections": s.activeConnections,
-		"total_messages":     s.totalMessages,
-		"channels":           s.hub.GetChannelCount(),
+		"total_connections":       s.totalConnections,
+		"active_connections":      s.activeConnections,
+		"total_messages":          s.totalMessages,
+		"channels":                hubStats.Channels,
+		"pong_timeout_evictions":  hubStats.PongTimeoutEvictions,
+		"slow_consumer_evictions": hubStats.SlowConsumerEvictions,
+		"dropped_messages":        hubStats.DroppedMessages,
+		"subscriptions_rejected":  hubStats.SubscriptionsRejected,
 	}
 	s.metricsMu.RUnlock()
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, `{"total_connections":%d,"active_connections":%d,"total_messages":%d,"channels":%d}`,
-		stats["total_connections"], stats["active_connections"], stats["total_messages"], stats["channels"])
+	_ = json.NewEncoder(w).Encode(stats)
 }
 
 // registerConnection registers a new connection
@@ -249,16 +274,41 @@ func (s *Server) BroadcastDepth(depth *DepthMessage) {
 	s.hub.UpdateDepth(depth.MarketID, depth)
 }
 
+// BroadcastL3 broadcasts a market-by-order update to licensed subscribers
+func (s *Server) BroadcastL3(update *types.L3Update) {
+	s.hub.BroadcastL3(update)
+}
+
 // BroadcastTrade broadcasts a trade
 func (s *Server) BroadcastTrade(trade *TradeMessage) {
 	s.hub.BroadcastTrade(trade.MarketID, trade)
 }
 
+// BroadcastLongShortRatio broadcasts a market's long/short ratio
+func (s *Server) BroadcastLongShortRatio(ratio *types.LongShortRatio) {
+	s.hub.BroadcastLongShortRatio(ratio)
+}
+
 // BroadcastPosition broadcasts a position update to a user
 func (s *Server) BroadcastPosition(userID string, position *PositionMessage) {
 	s.hub.BroadcastPosition(userID, position)
 }
 
+// BroadcastADLIndicator broadcasts an ADL indicator change to a user
+func (s *Server) BroadcastADLIndicator(userID string, indicator *types.ADLIndicator) {
+	s.hub.BroadcastADLIndicator(userID, indicator)
+}
+
+// BroadcastOrderReject broadcasts an order rejection to a user
+func (s *Server) BroadcastOrderReject(userID string, reject *types.OrderReject) {
+	s.hub.BroadcastOrderReject(userID, reject)
+}
+
+// BroadcastExecution broadcasts an order fill to a user
+func (s *Server) BroadcastExecution(userID string, exec *types.Execution) {
+	s.hub.BroadcastExecution(userID, exec)
+}
+
 // BroadcastOrder broadcasts an order update to a user
 func (s *Server) BroadcastOrder(userID string, order *OrderMessage) {
 	s.hub.BroadcastOrder(userID, order)
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/api/webhook"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// Withdrawal list page sizes
+const (
+	DefaultWithdrawalsLimit = 50
+	MaxWithdrawalsLimit     = 500
+)
+
+// withdrawalReleaseInterval is how often the standalone server pays out
+// withdrawals whose timelock has passed; on chain the EndBlocker does it
+// every block
+const withdrawalReleaseInterval = time.Second
+
+// fromPerpWithdrawalSecurity converts a keeper withdrawal protection to the API response
+func fromPerpWithdrawalSecurity(s *perptypes.WithdrawalSecurity) *types.WithdrawalSecurity {
+	security := &types.WithdrawalSecurity{
+		Trader:           s.Trader,
+		TimelockSeconds:  int64(s.Timelock / time.Second),
+		AllowlistEnabled: s.AllowlistEnabled,
+	}
+	if s.Pending != nil {
+		security.Pending = &types.WithdrawalSecurityChange{
+			TimelockSeconds:  int64(s.Pending.Timelock / time.Second),
+			AllowlistEnabled: s.Pending.AllowlistEnabled,
+			EffectiveAt:      s.Pending.EffectiveAt.UnixMilli(),
+		}
+	}
+	if !s.UpdatedAt.IsZero() {
+		security.UpdatedAt = s.UpdatedAt.UnixMilli()
+	}
+	return security
+}
+
+// fromPerpWithdrawalAddress converts a keeper allowlist entry to the API response
+func fromPerpWithdrawalAddress(a *perptypes.WithdrawalAddress, now time.Time) *types.WithdrawalAddress {
+	return &types.WithdrawalAddress{
+		Address:  a.Address,
+		Label:    a.Label,
+		AddedAt:  a.AddedAt.UnixMilli(),
+		ActiveAt: a.ActiveAt.UnixMilli(),
+		Active:   a.IsActive(now),
+	}
+}
+
+// fromPerpWithdrawal converts a keeper withdrawal to the API response
+func fromPerpWithdrawal(w *perptypes.Withdrawal, now time.Time) *types.Withdrawal {
+	withdrawal := &types.Withdrawal{
+		WithdrawalID: w.WithdrawalID,
+		Trader:       w.Trader,
+		Amount:       w.Amount.String(),
+		Destination:  w.Destination,
+		Status:       string(w.Status),
+		RequestedAt:  w.RequestedAt.UnixMilli(),
+		ReleaseAt:    w.ReleaseAt.UnixMilli(),
+		Cancellable:  w.IsCancellable(now),
+	}
+	if !w.ProcessedAt.IsZero() {
+		withdrawal.ProcessedAt = w.ProcessedAt.UnixMilli()
+	}
+	return withdrawal
+}
+
+// withdrawalSecurityService returns the order service's withdrawal security
+// support and the requesting trader, or writes the error response
+func (s *Server) withdrawalSecurityService(w http.ResponseWriter, r *http.Request) (types.WithdrawalSecurityService, string, bool) {
+	trader := webhookTrader(r)
+	if trader == "" {
+		writeError(w, types.ErrCodeMissingField, "trader address is required")
+		return nil, "", false
+	}
+	security, ok := s.orderService.(types.WithdrawalSecurityService)
+	if !ok {
+		writeError(w, types.ErrCodeNotImplemented, "Withdrawal security requires a keeper-backed service")
+		return nil, "", false
+	}
+	return security, trader, true
+}
+
+// handleWithdrawalSecurity handles /v1/account/withdrawal-security (GET, POST
+// {"timelock_seconds", "allowlist_enabled"})
+func (s *Server) handleWithdrawalSecurity(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	security, trader, ok := s.withdrawalSecurityService(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		resp, err := security.GetWithdrawalSecurity(r.Context(), trader)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodPost:
+		var req types.SetWithdrawalSecurityRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		if req.TimelockSeconds == nil && req.AllowlistEnabled == nil {
+			writeError(w, types.ErrCodeMissingField, "timelock_seconds or allowlist_enabled is required")
+			return
+		}
+		resp, err := security.SetWithdrawalSecurity(r.Context(), trader, &req)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleWithdrawalAddresses handles /v1/account/withdrawal-addresses (GET
+// list, POST {"address", "label"})
+func (s *Server) handleWithdrawalAddresses(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	security, trader, ok := s.withdrawalSecurityService(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		addresses, err := security.GetWithdrawalAddresses(r.Context(), trader)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"addresses": addresses})
+
+	case http.MethodPost:
+		var req struct {
+			Address string `json:"address"`
+			Label   string `json:"label"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, types.ErrCodeInvalidJSON, "Invalid JSON body")
+			return
+		}
+		if req.Address == "" {
+			writeError(w, types.ErrCodeMissingField, "address is required")
+			return
+		}
+		address, err := security.AddWithdrawalAddress(r.Context(), trader, req.Address, req.Label)
+		if err != nil {
+			writeAPIError(w, types.ToAPIError(err, types.ErrCodeInvalidRequest))
+			return
+		}
+		writeJSON(w, http.StatusCreated, address)
+
+	default:
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleWithdrawalAddress handles DELETE /v1/account/withdrawal-addresses/{address}
+func (s *Server) handleWithdrawalAddress(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	address := strings.TrimPrefix(r.URL.Path, "/v1/account/withdrawal-addresses/")
+	if address == "" || strings.Contains(address, "/") {
+		writeError(w, types.ErrCodeInvalidPath, "Invalid withdrawal address path")
+		return
+	}
+	security, trader, ok := s.withdrawalSecurityService(w, r)
+	if !ok {
+		return
+	}
+
+	if err := security.RemoveWithdrawalAddress(r.Context(), trader, address); err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleWithdrawals handles GET /v1/account/withdrawals?limit=, the trader's
+// withdrawals newest first
+func (s *Server) handleWithdrawals(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	limit := DefaultWithdrawalsLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 || n > MaxWithdrawalsLimit {
+			writeError(w, types.ErrCodeInvalidRequest, "limit must be between 1 and "+strconv.Itoa(MaxWithdrawalsLimit))
+			return
+		}
+		limit = n
+	}
+	security, trader, ok := s.withdrawalSecurityService(w, r)
+	if !ok {
+		return
+	}
+
+	withdrawals, err := security.GetWithdrawals(r.Context(), trader, limit)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"withdrawals": withdrawals})
+}
+
+// handleWithdrawalCancel handles POST /v1/account/withdrawals/{id}/cancel,
+// aborting a pending withdrawal before its release
+func (s *Server) handleWithdrawalCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, types.ErrCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v1/account/withdrawals/"), "/cancel")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		writeError(w, types.ErrCodeInvalidPath, "Expected /v1/account/withdrawals/{id}/cancel")
+		return
+	}
+	security, trader, ok := s.withdrawalSecurityService(w, r)
+	if !ok {
+		return
+	}
+
+	withdrawal, err := security.CancelWithdrawal(r.Context(), trader, id)
+	if err != nil {
+		writeAPIError(w, types.ToAPIError(err, types.ErrCodeInternal))
+		return
+	}
+	writeJSON(w, http.StatusOK, withdrawal)
+}
+
+// startWithdrawalReleaseScheduler pays out timelocked withdrawals when the
+// order service keeps its own ledger, and notifies the traders' webhooks
+func (s *Server) startWithdrawalReleaseScheduler() {
+	security, ok := s.orderService.(types.WithdrawalSecurityService)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(withdrawalReleaseInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		released, err := security.ReleaseWithdrawals(context.Background())
+		if err != nil {
+			log.Printf("Withdrawal release: %v", err)
+			continue
+		}
+		if s.webhooks == nil {
+			continue
+		}
+		for _, withdrawal := range released {
+			s.webhooks.PublishAccountEvent(withdrawal.Trader, webhook.EventWithdrawalCompleted, map[string]interface{}{
+				"amount":        withdrawal.Amount,
+				"withdrawal_id": withdrawal.WithdrawalID,
+				"destination":   withdrawal.Destination,
+			})
+		}
+	}
+}
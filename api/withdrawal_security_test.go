@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	"cosmossdk.io/store"
+	"cosmossdk.io/store/metrics"
+	storetypes "cosmossdk.io/store/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/openalpha/perp-dex/api/types"
+	perpkeeper "github.com/openalpha/perp-dex/x/perpetual/keeper"
+	perptypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TestWithdrawalSecurityEndpoints tests that a timelocked withdrawal through
+// a keeper-backed service is held and can be cancelled over the API, that
+// destinations outside the allowlist are refused, and that a pool deposit
+// from the trading account is a debit that neither applies
+func TestWithdrawalSecurityEndpoints(t *testing.T) {
+	storeKey := storetypes.NewKVStoreKey("perpetual")
+	db := dbm.NewMemDB()
+	stateStore := store.NewCommitMultiStore(db, log.NewNopLogger(), metrics.NewNoOpMetrics())
+	stateStore.MountStoreWithDB(storeKey, storetypes.StoreTypeIAVL, db)
+	if err := stateStore.LoadLatestVersion(); err != nil {
+		t.Fatalf("failed to load store: %v", err)
+	}
+	ctx := sdk.NewContext(stateStore, cmtproto.Header{Time: time.Now().UTC()}, false, log.NewNopLogger())
+	pk := perpkeeper.NewKeeper(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), storeKey, nil, "", log.NewNopLogger())
+	pk.SetAccount(ctx, perptypes.NewAccount("alice"))
+	if err := pk.Deposit(ctx, "alice", math.LegacyNewDec(1000)); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+
+	rs := NewRealServiceWithKeepers(nil, pk, ctx, log.NewNopLogger())
+	s := &Server{orderService: rs}
+	call := func(handler http.HandlerFunc, method, target, body string, v interface{}) int {
+		t.Helper()
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("X-Trader-Address", "alice")
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("%s %s: invalid JSON %q: %v", method, target, rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+
+	var security types.WithdrawalSecurity
+	if code := call(s.handleWithdrawalSecurity, http.MethodPost, "/v1/account/withdrawal-security", `{"timelock_seconds": 3600}`, &security); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if security.TimelockSeconds != 3600 || security.AllowlistEnabled {
+		t.Fatalf("unexpected security %+v", security)
+	}
+
+	resp, err := rs.Withdraw(context.Background(), &types.WithdrawRequest{Trader: "alice", Amount: "250"})
+	if err != nil {
+		t.Fatalf("failed to withdraw: %v", err)
+	}
+	if resp.Withdrawal == nil || resp.Withdrawal.Status != "pending" || !resp.Withdrawal.Cancellable || resp.Account.Balance != math.LegacyNewDec(750).String() {
+		t.Fatalf("expected a cancellable pending withdrawal, got %+v %+v", resp.Withdrawal, resp.Account)
+	}
+
+	var cancelled types.Withdrawal
+	target := "/v1/account/withdrawals/" + resp.Withdrawal.WithdrawalID + "/cancel"
+	if code := call(s.handleWithdrawalCancel, http.MethodPost, target, "", &cancelled); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if cancelled.Status != "cancelled" || !pk.GetAccount(ctx, "alice").Balance.Equal(math.LegacyNewDec(1000)) {
+		t.Fatalf("expected the withdrawal cancelled and refunded, got %+v", cancelled)
+	}
+	var errResp map[string]interface{}
+	if code := call(s.handleWithdrawalCancel, http.MethodPost, target, "", &errResp); code != http.StatusConflict {
+		t.Errorf("expected 409 cancelling twice, got %d", code)
+	}
+
+	if code := call(s.handleWithdrawalSecurity, http.MethodPost, "/v1/account/withdrawal-security", `{"allowlist_enabled": true}`, &security); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	var address types.WithdrawalAddress
+	if code := call(s.handleWithdrawalAddresses, http.MethodPost, "/v1/account/withdrawal-addresses", `{"address": "cold"}`, &address); code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	if address.Active {
+		t.Errorf("expected a new address to wait out the timelock, got %+v", address)
+	}
+	if _, err := rs.Withdraw(context.Background(), &types.WithdrawRequest{Trader: "alice", Amount: "1", Destination: "cold"}); types.ToAPIError(err, types.ErrCodeInternal).Code != types.ErrCodeAddressNotAllowed {
+		t.Errorf("expected withdrawal_address_not_allowed, got %v", err)
+	}
+
+	var list struct {
+		Withdrawals []*types.Withdrawal `json:"withdrawals"`
+	}
+	if code := call(s.handleWithdrawals, http.MethodGet, "/v1/account/withdrawals", "", &list); code != http.StatusOK || len(list.Withdrawals) != 1 {
+		t.Errorf("expected one withdrawal listed, got %d %+v", code, list.Withdrawals)
+	}
+
+	s.accountService, s.positionService, s.riverpoolService = rs, rs, NewMockRiverpoolService()
+	if code := call(s.handleRiverpoolTradingDeposit, http.MethodPost, "/v1/riverpool/deposit/trading-account", `{"pool_id": "main-lp", "amount": "400"}`, nil); code != http.StatusCreated {
+		t.Fatalf("expected 201 depositing into the pool, got %d", code)
+	}
+	if balance := pk.GetAccount(ctx, "alice").Balance; !balance.Equal(math.LegacyNewDec(600)) {
+		t.Errorf("expected 400 debited at once, got balance %s", balance)
+	}
+	// A failed pool deposit is refunded and queues nothing either
+	var errBody map[string]interface{}
+	if code := call(s.handleRiverpoolTradingDeposit, http.MethodPost, "/v1/riverpool/deposit/trading-account", `{"pool_id": "missing", "amount": "100"}`, &errBody); code == http.StatusCreated {
+		t.Fatal("expected the deposit into a missing pool to fail")
+	}
+	if balance := pk.GetAccount(ctx, "alice").Balance; !balance.Equal(math.LegacyNewDec(600)) {
+		t.Errorf("expected the failed deposit refunded, got balance %s", balance)
+	}
+	if code := call(s.handleWithdrawals, http.MethodGet, "/v1/account/withdrawals", "", &list); code != http.StatusOK || len(list.Withdrawals) != 1 {
+		t.Errorf("expected no withdrawal queued by the pool deposits, got %d %+v", code, list.Withdrawals)
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// WebSocket snapshot sizes and timeout
+const (
+	wsSnapshotTrades  = 100
+	wsSnapshotOrders  = 500
+	wsSnapshotTimeout = 5 * time.Second
+)
+
+// wsChannelSnapshot returns the snapshot the WebSocket hub sends a subscriber
+// it cannot catch up by replay: a market's recent trades or a trader's
+// resting orders. Both carry the sequence number of each entry.
+func (s *Server) wsChannelSnapshot(channel string) (interface{}, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), wsSnapshotTimeout)
+	defer cancel()
+
+	switch {
+	case strings.HasPrefix(channel, "trades:"):
+		if s.marketData == nil {
+			return nil, false
+		}
+		trades, err := s.marketData.GetRecentTrades(ctx, strings.TrimPrefix(channel, "trades:"), wsSnapshotTrades)
+		if err != nil {
+			return nil, false
+		}
+		return map[string]interface{}{"trades": trades}, true
+
+	case strings.HasPrefix(channel, "orders:"):
+		if s.orderService == nil {
+			return nil, false
+		}
+		trader := strings.TrimPrefix(channel, "orders:")
+		orders := make([]*types.Order, 0)
+		for _, status := range []string{"open", "partially_filled"} {
+			resp, err := s.orderService.ListOrders(ctx, &types.ListOrdersRequest{
+				Trader:        trader,
+				Status:        status,
+				HistoryParams: types.HistoryParams{Limit: wsSnapshotOrders},
+			})
+			if err != nil {
+				return nil, false
+			}
+			orders = append(orders, resp.Orders...)
+		}
+		return map[string]interface{}{"orders": orders}, true
+	}
+	return nil, false
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -23,6 +24,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec/address"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	"github.com/cosmos/cosmos-sdk/runtime"
+	"github.com/cosmos/cosmos-sdk/server"
 	"github.com/cosmos/cosmos-sdk/server/api"
 	"github.com/cosmos/cosmos-sdk/server/config"
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
@@ -38,10 +40,13 @@ import (
 	"github.com/cosmos/cosmos-sdk/x/consensus"
 	consensusparamkeeper "github.com/cosmos/cosmos-sdk/x/consensus/keeper"
 	consensusparamtypes "github.com/cosmos/cosmos-sdk/x/consensus/types"
+	crisiskeeper "github.com/cosmos/cosmos-sdk/x/crisis/keeper"
+	crisistypes "github.com/cosmos/cosmos-sdk/x/crisis/types"
 	"github.com/cosmos/cosmos-sdk/x/genutil"
 	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	gogoprotograpc "github.com/cosmos/gogoproto/grpc"
+	"github.com/spf13/cast"
 
 	clearinghousekeeper "github.com/openalpha/perp-dex/x/clearinghouse/keeper"
 	orderbookkeeper "github.com/openalpha/perp-dex/x/orderbook/keeper"
@@ -49,6 +54,8 @@ import (
 	perpetualkeeper "github.com/openalpha/perp-dex/x/perpetual/keeper"
 	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
 	riverpoolkeeper "github.com/openalpha/perp-dex/x/riverpool/keeper"
+	treasurykeeper "github.com/openalpha/perp-dex/x/treasury/keeper"
+	treasurytypes "github.com/openalpha/perp-dex/x/treasury/types"
 )
 
 const (
@@ -95,15 +102,21 @@ type App struct {
 	ConsensusParamsKeeper consensusparamkeeper.Keeper
 	AccountKeeper         authkeeper.AccountKeeper
 	BankKeeper            bankkeeper.BaseKeeper
+	CrisisKeeper          *crisiskeeper.Keeper
 
 	// Custom module keepers
 	OrderbookKeeper     *orderbookkeeper.Keeper
 	PerpetualKeeper     *perpetualkeeper.Keeper
 	ClearinghouseKeeper *clearinghousekeeper.Keeper
 	RiverpoolKeeper     *riverpoolkeeper.Keeper
+	TreasuryKeeper      *treasurykeeper.Keeper
 
 	// Module Manager
 	BasicModuleManager module.BasicManager
+
+	// ModuleManager runs the custom modules' store migrations
+	ModuleManager *module.Manager
+	configurator  module.Configurator
 }
 
 // NewApp returns a new App instance
@@ -134,7 +147,9 @@ func NewApp(
 		"perpetual",
 		"clearinghouse",
 		"riverpool",
+		treasurytypes.StoreKey,
 		consensusparamtypes.StoreKey,
+		crisistypes.StoreKey,
 	)
 	tkeys := storetypes.NewTransientStoreKeys()
 	memKeys := storetypes.NewMemoryStoreKeys()
@@ -223,17 +238,64 @@ func NewApp(
 		appCodec,
 		keys["riverpool"],
 		app.PerpetualKeeper,
+		newRiverpoolOrderbookAdapter(app.OrderbookKeeper),
 		app.BankKeeper,
 		"", // authority
 		logger,
 	)
+	app.RiverpoolKeeper.SetLPObligationKeeper(app.OrderbookKeeper)
+	// Pool share coins move the shares they represent and keep their lock
+	// and redemption rules when transferred
+	app.BankKeeper.AppendSendRestriction(app.RiverpoolKeeper.LPSendRestriction)
+	app.OrderbookKeeper.SetFeeRevenueSink(app.RiverpoolKeeper)
+
+	// Register the modules that react to fills, position changes and
+	// liquidations; new consumers are added here rather than in the
+	// matching engine or the clearinghouse
+	app.OrderbookKeeper.SetHooks(app.RiverpoolKeeper.Hooks())
+	app.ClearinghouseKeeper.SetHooks(app.RiverpoolKeeper.Hooks())
+
+	// Initialize treasury keeper; it sets the fee split and receives the
+	// treasury share of settled fees
+	app.TreasuryKeeper = treasurykeeper.NewKeeper(
+		appCodec,
+		keys[treasurytypes.StoreKey],
+		app.PerpetualKeeper,
+		"", // authority
+		logger,
+	)
+	app.OrderbookKeeper.SetFeeTreasury(app.TreasuryKeeper)
+	// Fees traders opt to pay in the fee token go to the fee collector
+	app.OrderbookKeeper.SetFeeTokenBank(app.BankKeeper, authtypes.FeeCollectorName)
+	app.OrderbookKeeper.SetStickySlots(true)
+
+	// Initialize crisis keeper; invariants are asserted every --inv-check-period
+	// blocks. The root command builds a temporary app without options.
+	var invCheckPeriod uint
+	if appOpts != nil {
+		invCheckPeriod = cast.ToUint(appOpts.Get(server.FlagInvCheckPeriod))
+	}
+	app.CrisisKeeper = crisiskeeper.NewKeeper(
+		appCodec,
+		runtime.NewKVStoreService(keys[crisistypes.StoreKey]),
+		invCheckPeriod,
+		app.BankKeeper,
+		authtypes.FeeCollectorName,
+		"", // authority - empty for MVP
+		addrCodec,
+	)
+	orderbookkeeper.RegisterInvariants(app.CrisisKeeper, app.OrderbookKeeper)
+	perpetualkeeper.RegisterInvariants(app.CrisisKeeper, app.PerpetualKeeper)
 
 	// Register message types with the interface registry
 	orderbooktypes.RegisterInterfaces(interfaceRegistry)
 	perpetualtypes.RegisterInterfaces(interfaceRegistry)
+	treasurytypes.RegisterInterfaces(interfaceRegistry)
+	crisistypes.RegisterInterfaces(interfaceRegistry)
 
-	// Register MsgServer for custom modules with the message service router
-	orderbooktypes.RegisterMsgServer(bApp.MsgServiceRouter(), orderbookkeeper.NewMsgServerImpl(app.OrderbookKeeper))
+	// Register MsgServers and store migrations for custom modules
+	app.setupModuleManager()
+	crisistypes.RegisterMsgServer(bApp.MsgServiceRouter(), app.CrisisKeeper)
 
 	// Register QueryServers for SDK modules
 	authtypes.RegisterQueryServer(bApp.GRPCQueryRouter(), authkeeper.NewQueryServer(app.AccountKeeper))
@@ -263,6 +325,10 @@ func (app *App) Name() string { return app.BaseApp.Name() }
 
 // BeginBlocker executes begin block logic
 func (app *App) BeginBlocker(ctx sdk.Context) (sdk.BeginBlock, error) {
+	// Migrate the custom module stores when a new binary bumps their versions
+	if err := app.RunMigrations(ctx); err != nil {
+		return sdk.BeginBlock{}, fmt.Errorf("failed to migrate module stores: %w", err)
+	}
 	return sdk.BeginBlock{}, nil
 }
 
@@ -273,7 +339,7 @@ func (app *App) EndBlocker(ctx sdk.Context) (sdk.EndBlock, error) {
 	totalStart := time.Now()
 
 	// Track individual operation timings
-	var oracleDuration, matchingDuration, liquidationDuration, fundingDuration, conditionalDuration, riverpoolDuration time.Duration
+	var oracleDuration, matchingDuration, liquidationDuration, fundingDuration, conditionalDuration, lpObligationDuration, feeLedgerDuration, riverpoolDuration time.Duration
 
 	// ===========================================
 	// Phase 1: Oracle Price Updates
@@ -287,6 +353,10 @@ func (app *App) EndBlocker(ctx sdk.Context) (sdk.EndBlock, error) {
 	// Phase 2: Order Matching (Optimized)
 	// ===========================================
 	matchingStart := time.Now()
+	// Slots freed by cancels are only reused by replaces within the block's txs
+	app.OrderbookKeeper.ClearStickySlots(ctx)
+	// Good-till-date orders past their expiry leave the book before matching
+	app.OrderbookKeeper.ExpireOrders(ctx)
 	matchingResult, matchErr := app.OrderbookKeeper.ParallelEndBlockerV2(ctx)
 	if matchErr != nil {
 		logger.Error("parallel matching v2 failed", "error", matchErr)
@@ -316,6 +386,11 @@ func (app *App) EndBlocker(ctx sdk.Context) (sdk.EndBlock, error) {
 	app.PerpetualKeeper.FundingEndBlocker(ctx)
 	fundingDuration = time.Since(fundingStart)
 
+	// Lift expired account restrictions, then pay out withdrawals whose
+	// timelock has passed
+	app.PerpetualKeeper.AccountRestrictionEndBlocker(ctx)
+	app.PerpetualKeeper.WithdrawalEndBlocker(ctx)
+
 	// ===========================================
 	// Phase 5: Conditional Orders
 	// ===========================================
@@ -323,15 +398,44 @@ func (app *App) EndBlocker(ctx sdk.Context) (sdk.EndBlock, error) {
 	app.OrderbookKeeper.ConditionalOrderEndBlocker(ctx)
 	conditionalDuration = time.Since(conditionalStart)
 
+	// Trade resting spread orders against the leg books left by this block
+	app.OrderbookKeeper.SpreadEndBlocker(ctx)
+
+	// Cancel or flag resting orders their owners can no longer cover at mark
+	app.OrderbookKeeper.MarginRecheckEndBlocker(ctx)
+
+	// Sample LP quoting obligations against the books left by this block
+	lpObligationStart := time.Now()
+	app.OrderbookKeeper.LPObligationEndBlocker(ctx)
+	lpObligationDuration = time.Since(lpObligationStart)
+
+	// Drop event log entries that fell out of the retention window
+	app.OrderbookKeeper.EventLogEndBlocker(ctx)
+
 	// ===========================================
 	// Phase 6: RiverPool Processing
 	// ===========================================
+	// Settle ended fee days first so the riverpool fee share is in this block's NAV
+	feeLedgerStart := time.Now()
+	app.OrderbookKeeper.FeeLedgerEndBlocker(ctx)
+	feeLedgerDuration = time.Since(feeLedgerStart)
+
 	riverpoolStart := time.Now()
 	if err := app.RiverpoolKeeper.EndBlocker(ctx); err != nil {
 		logger.Error("riverpool endblock failed", "error", err)
 	}
 	riverpoolDuration = time.Since(riverpoolStart)
 
+	// ===========================================
+	// Phase 7: Invariant Checks
+	// ===========================================
+	if period := app.CrisisKeeper.InvCheckPeriod(); period != 0 && blockHeight%int64(period) == 0 {
+		app.CrisisKeeper.AssertInvariants(ctx)
+	}
+
+	// The block's order book and trade writes are final; reverted txs left none
+	app.OrderbookKeeper.RefreshAnalytics(ctx)
+
 	// ===========================================
 	// Performance Logging
 	// ===========================================
@@ -346,6 +450,8 @@ func (app *App) EndBlocker(ctx sdk.Context) (sdk.EndBlock, error) {
 		"liquidation_ms", liquidationDuration.Milliseconds(),
 		"funding_ms", fundingDuration.Milliseconds(),
 		"conditional_ms", conditionalDuration.Milliseconds(),
+		"lp_obligation_ms", lpObligationDuration.Milliseconds(),
+		"fee_ledger_ms", feeLedgerDuration.Milliseconds(),
 		"riverpool_ms", riverpoolDuration.Milliseconds(),
 	)
 
@@ -460,11 +566,16 @@ func (app *App) InitChainer(ctx sdk.Context, req *abci.RequestInitChain) (*abci.
 		return nil, err
 	}
 
-	// Initialize default market
-	app.PerpetualKeeper.InitDefaultMarket(ctx)
+	// Import custom module state; an empty genesis starts with the default
+	// market and the Foundation LP and Main LP pools
+	if err := app.initCustomGenesis(ctx, genesisState); err != nil {
+		return nil, err
+	}
+	// A new chain's stores start at the current module versions
+	app.setModuleVersions(ctx, app.ModuleManager.GetVersionMap())
 
-	// Initialize default RiverPool pools (Foundation LP and Main LP)
-	app.RiverpoolKeeper.InitDefaultPools(ctx)
+	// Initialize crisis params (constant fee for MsgVerifyInvariant)
+	app.CrisisKeeper.InitGenesis(ctx, crisistypes.DefaultGenesisState())
 
 	// If validators are provided in request, use them
 	if len(req.Validators) > 0 {
@@ -13,6 +13,7 @@ import (
 
 	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
 	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	treasurytypes "github.com/openalpha/perp-dex/x/treasury/types"
 )
 
 // EncodingConfig specifies the concrete encoding types to use
@@ -74,6 +75,7 @@ func MakeEncodingConfig() EncodingConfig {
 	// Register custom module interfaces
 	orderbooktypes.RegisterInterfaces(interfaceRegistry)
 	perpetualtypes.RegisterInterfaces(interfaceRegistry)
+	treasurytypes.RegisterInterfaces(interfaceRegistry)
 
 	return EncodingConfig{
 		InterfaceRegistry: interfaceRegistry,
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	riverpooltypes "github.com/openalpha/perp-dex/x/riverpool/types"
+	treasurytypes "github.com/openalpha/perp-dex/x/treasury/types"
+)
+
+// Genesis keys of the custom modules
+const (
+	perpetualGenesisKey = "perpetual"
+	orderbookGenesisKey = "orderbook"
+	riverpoolGenesisKey = "riverpool"
+	treasuryGenesisKey  = "treasury"
+)
+
+// initCustomGenesis imports the custom module states. A module missing from
+// the genesis file starts from its default state.
+func (app *App) initCustomGenesis(ctx sdk.Context, genesisState map[string]json.RawMessage) error {
+	perpGenesis := perpetualtypes.DefaultGenesis()
+	if err := unmarshalModuleGenesis(genesisState, perpetualGenesisKey, perpGenesis); err != nil {
+		return err
+	}
+	if err := app.PerpetualKeeper.InitGenesis(ctx, perpGenesis); err != nil {
+		return fmt.Errorf("%s genesis: %w", perpetualGenesisKey, err)
+	}
+
+	obGenesis := orderbooktypes.DefaultGenesis()
+	if err := unmarshalModuleGenesis(genesisState, orderbookGenesisKey, obGenesis); err != nil {
+		return err
+	}
+	if err := app.OrderbookKeeper.InitGenesis(ctx, obGenesis); err != nil {
+		return fmt.Errorf("%s genesis: %w", orderbookGenesisKey, err)
+	}
+
+	rpGenesis := riverpooltypes.DefaultGenesis()
+	if err := unmarshalModuleGenesis(genesisState, riverpoolGenesisKey, rpGenesis); err != nil {
+		return err
+	}
+	if err := app.RiverpoolKeeper.InitGenesis(ctx, rpGenesis); err != nil {
+		return fmt.Errorf("%s genesis: %w", riverpoolGenesisKey, err)
+	}
+
+	treasuryGenesis := treasurytypes.DefaultGenesis()
+	if err := unmarshalModuleGenesis(genesisState, treasuryGenesisKey, treasuryGenesis); err != nil {
+		return err
+	}
+	if err := app.TreasuryKeeper.InitGenesis(ctx, treasuryGenesis); err != nil {
+		return fmt.Errorf("%s genesis: %w", treasuryGenesisKey, err)
+	}
+	return nil
+}
+
+func unmarshalModuleGenesis(genesisState map[string]json.RawMessage, key string, dst interface{}) error {
+	bz, ok := genesisState[key]
+	if !ok || len(bz) == 0 || string(bz) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(bz, dst); err != nil {
+		return fmt.Errorf("failed to unmarshal %s genesis state: %w", key, err)
+	}
+	return nil
+}
+
+// ExportAppStateAndValidators exports the custom module states at the latest
+// height, so they can seed a new chain after an upgrade or testnet reset.
+// Only the perpetual, orderbook, riverpool and treasury states are exported:
+// InitChainer does not import auth or bank state, so accounts and bank
+// balances, including riverpool LP coins, do not carry over and the new
+// genesis must provide them. Clearinghouse state is not exported either.
+// Validators are not exported; the new genesis takes them from its staking or
+// genutil state as usual.
+func (app *App) ExportAppStateAndValidators(forZeroHeight bool, jailAllowedAddrs, modulesToExport []string) (servertypes.ExportedApp, error) {
+	ctx := app.NewContextLegacy(true, cmtproto.Header{Height: app.LastBlockHeight()})
+
+	// Start the new chain at the next height unless it restarts from zero
+	height := app.LastBlockHeight() + 1
+	if forZeroHeight {
+		height = 0
+	}
+
+	exports := map[string]func() interface{}{
+		perpetualGenesisKey: func() interface{} { return app.PerpetualKeeper.ExportGenesis(ctx) },
+		orderbookGenesisKey: func() interface{} { return app.OrderbookKeeper.ExportGenesis(ctx) },
+		riverpoolGenesisKey: func() interface{} { return app.RiverpoolKeeper.ExportGenesis(ctx) },
+		treasuryGenesisKey:  func() interface{} { return app.TreasuryKeeper.ExportGenesis(ctx) },
+	}
+
+	genesisState := make(map[string]json.RawMessage, len(exports))
+	for key, export := range exports {
+		if !exportModule(modulesToExport, key) {
+			continue
+		}
+		bz, err := json.Marshal(export())
+		if err != nil {
+			return servertypes.ExportedApp{}, fmt.Errorf("failed to export %s genesis state: %w", key, err)
+		}
+		genesisState[key] = bz
+	}
+
+	appState, err := json.MarshalIndent(genesisState, "", "  ")
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+
+	return servertypes.ExportedApp{
+		AppState:        appState,
+		Height:          height,
+		ConsensusParams: app.GetConsensusParams(ctx),
+	}, nil
+}
+
+// exportModule reports whether a module is selected by --modules-to-export;
+// an empty selection exports every module
+func exportModule(modulesToExport []string, key string) bool {
+	if len(modulesToExport) == 0 {
+		return true
+	}
+	for _, m := range modulesToExport {
+		if m == key {
+			return true
+		}
+	}
+	return false
+}
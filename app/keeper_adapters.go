@@ -9,6 +9,8 @@ import (
 	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
 	perpetualkeeper "github.com/openalpha/perp-dex/x/perpetual/keeper"
 	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	riverpoolkeeper "github.com/openalpha/perp-dex/x/riverpool/keeper"
+	riverpooltypes "github.com/openalpha/perp-dex/x/riverpool/types"
 )
 
 type orderbookPerpetualAdapter struct {
@@ -29,11 +31,27 @@ func (a orderbookPerpetualAdapter) GetMarket(ctx sdk.Context, marketID string) *
 		return nil
 	}
 
+	priceDecimals, sizeDecimals := market.Precision()
+	limits := a.keeper.GetOrderLimits(ctx, market)
 	return &orderbookkeeper.Market{
 		MarketID:      market.MarketID,
 		TakerFeeRate:  market.TakerFeeRate,
 		MakerFeeRate:  market.MakerFeeRate,
 		InitialMargin: market.InitialMarginRate,
+
+		TickSize:          market.TickSize,
+		LotSize:           market.LotSize,
+		MinOrderSize:      market.MinOrderSize,
+		MaxOrderSize:      limits.MaxOrderSize,
+		MinNotional:       market.MinNotional,
+		MaxPriceDeviation: limits.MaxPriceDeviation,
+		MaxSlippage:       limits.MaxSlippage,
+
+		PriceDecimals: priceDecimals,
+		SizeDecimals:  sizeDecimals,
+
+		TradingHalt: a.keeper.CheckTradingAllowed(ctx, marketID),
+		PostOnly:    limits.PostOnly,
 	}
 }
 
@@ -99,6 +117,54 @@ func (a orderbookPerpetualAdapter) CheckMarginRequirement(ctx sdk.Context, trade
 	return a.keeper.CheckMarginRequirement(ctx, trader, marketID, positionSide, qtyDec, priceDec)
 }
 
+type riverpoolOrderbookAdapter struct {
+	keeper *orderbookkeeper.Keeper
+}
+
+func newRiverpoolOrderbookAdapter(keeper *orderbookkeeper.Keeper) riverpoolkeeper.OrderbookKeeper {
+	return riverpoolOrderbookAdapter{keeper: keeper}
+}
+
+func (a riverpoolOrderbookAdapter) PlaceOrder(ctx sdk.Context, trader, marketID string, isBuy bool, price, quantity math.LegacyDec) (*riverpooltypes.OrderFill, error) {
+	if a.keeper == nil {
+		return nil, fmt.Errorf("orderbook keeper not set")
+	}
+
+	side := orderbooktypes.SideSell
+	if isBuy {
+		side = orderbooktypes.SideBuy
+	}
+	orderType := orderbooktypes.OrderTypeLimit
+	if price.IsZero() {
+		orderType = orderbooktypes.OrderTypeMarket
+	}
+
+	order, result, err := a.keeper.PlaceOrder(ctx, trader, marketID, side, orderType, price, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	fill := &riverpooltypes.OrderFill{
+		OrderID:   order.OrderID,
+		Status:    order.Status.String(),
+		FilledQty: result.FilledQty,
+		AvgPrice:  result.AvgPrice,
+	}
+	for _, trade := range result.Trades {
+		fee := trade.TakerFee
+		if trade.Taker != trader {
+			fee = trade.MakerFee
+		}
+		fill.Trades = append(fill.Trades, riverpooltypes.TradeFill{
+			TradeID:  trade.TradeID,
+			Price:    trade.Price,
+			Quantity: trade.Quantity,
+			Fee:      fee,
+		})
+	}
+	return fill, nil
+}
+
 func parseLegacyDec(value interface{}) (math.LegacyDec, error) {
 	switch v := value.(type) {
 	case math.LegacyDec:
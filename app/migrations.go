@@ -0,0 +1,85 @@
+package app
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+
+	"github.com/openalpha/perp-dex/x/orderbook"
+	"github.com/openalpha/perp-dex/x/perpetual"
+	"github.com/openalpha/perp-dex/x/riverpool"
+	"github.com/openalpha/perp-dex/x/treasury"
+)
+
+// ModuleVersionKey is where each custom module's store records the consensus
+// version its state was last migrated to. It takes the place of the version
+// map x/upgrade keeps, which the app does not include.
+var ModuleVersionKey = []byte{0xFF}
+
+// setupModuleManager builds the module manager of the custom modules and
+// registers their services and store migrations
+func (app *App) setupModuleManager() {
+	app.ModuleManager = module.NewManager(
+		orderbook.NewAppModule(app.OrderbookKeeper),
+		perpetual.NewAppModule(app.PerpetualKeeper),
+		riverpool.NewAppModule(app.RiverpoolKeeper),
+		treasury.NewAppModule(app.TreasuryKeeper),
+	)
+	app.configurator = module.NewConfigurator(app.appCodec, app.MsgServiceRouter(), app.GRPCQueryRouter())
+	if err := app.ModuleManager.RegisterServices(app.configurator); err != nil {
+		panic(fmt.Errorf("failed to register module services: %w", err))
+	}
+}
+
+// RunMigrations migrates each custom module's store from the version it
+// records to the module's ConsensusVersion and records the new versions. It
+// runs at the start of every block and does nothing unless a new binary
+// bumped a version. A store that records no version predates version
+// tracking, when no migration ever ran, so it is migrated from version 1.
+func (app *App) RunMigrations(ctx sdk.Context) error {
+	fromVM := app.ModuleVersions(ctx)
+	current := true
+	for name, version := range app.ModuleManager.GetVersionMap() {
+		if fromVM[name] != version {
+			current = false
+		}
+	}
+	if current {
+		return nil
+	}
+
+	toVM, err := app.ModuleManager.RunMigrations(ctx, app.configurator, fromVM)
+	if err != nil {
+		return err
+	}
+	for name, version := range toVM {
+		if fromVM[name] != version {
+			app.Logger().Info("migrated module store", "module", name, "from", fromVM[name], "to", version)
+		}
+	}
+	app.setModuleVersions(ctx, toVM)
+	return nil
+}
+
+// ModuleVersions returns the consensus version each custom module's store
+// records
+func (app *App) ModuleVersions(ctx sdk.Context) module.VersionMap {
+	vm := make(module.VersionMap)
+	for _, name := range app.ModuleManager.ModuleNames() {
+		vm[name] = 1
+		if bz := ctx.KVStore(app.keys[name]).Get(ModuleVersionKey); len(bz) == 8 {
+			vm[name] = binary.BigEndian.Uint64(bz)
+		}
+	}
+	return vm
+}
+
+func (app *App) setModuleVersions(ctx sdk.Context, vm module.VersionMap) {
+	for name, version := range vm {
+		bz := make([]byte, 8)
+		binary.BigEndian.PutUint64(bz, version)
+		ctx.KVStore(app.keys[name]).Set(ModuleVersionKey, bz)
+	}
+}
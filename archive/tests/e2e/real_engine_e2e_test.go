@@ -79,9 +79,7 @@ func TestRealEngineE2E_OrderMatching(t *testing.T) {
 	ctx := context.Background()
 
 	// Initialize two traders with balance
-	err = service.InitializeTestAccount("buyer", "100000")
-	require.NoError(t, err)
-	err = service.InitializeTestAccount("seller", "100000")
+	err = service.InitializeTestAccounts("100000", "buyer", "seller")
 	require.NoError(t, err)
 
 	// Place buy order
@@ -122,9 +120,7 @@ func TestRealEngineE2E_PositionManagement(t *testing.T) {
 	ctx := context.Background()
 
 	// Initialize traders
-	err = service.InitializeTestAccount("long-trader", "100000")
-	require.NoError(t, err)
-	err = service.InitializeTestAccount("short-trader", "100000")
+	err = service.InitializeTestAccounts("100000", "long-trader", "short-trader")
 	require.NoError(t, err)
 
 	// Create matching orders to generate positions
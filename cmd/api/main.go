@@ -2,14 +2,28 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
 	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
 	"github.com/openalpha/perp-dex/api"
+	"github.com/openalpha/perp-dex/api/chaintx"
+	"github.com/openalpha/perp-dex/api/middleware"
+	"github.com/openalpha/perp-dex/api/mocksim"
+	"github.com/openalpha/perp-dex/api/numfmt"
+	"github.com/openalpha/perp-dex/api/websocket"
+	"github.com/openalpha/perp-dex/pkg/klines"
 )
 
 func main() {
@@ -17,25 +31,218 @@ func main() {
 	host := flag.String("host", "0.0.0.0", "Server host")
 	port := flag.Int("port", 8080, "Server port")
 	mockMode := flag.Bool("mock", false, "Enable mock data mode (default: false for real mode)")
+	mockScript := flag.String("mock-script", "", "Mock mode: JSON script of the simulated markets' price paths, books and fills (default: built-in BTC, ETH and SOL script)")
 	realMode := flag.Bool("real", false, "Enable real orderbook engine mode (uses MatchingEngineV2)")
 	noRateLimit := flag.Bool("no-rate-limit", false, "Disable rate limiting (for E2E testing)")
+	drainTimeout := flag.Duration("drain-timeout", 30*time.Second, "Max time to drain in-flight requests and WebSocket broadcasts on shutdown")
+	drainGracePeriod := flag.Duration("drain-grace-period", 5*time.Second, "Time to keep serving after /health reports draining, before connections are closed (shorter than -drain-timeout)")
+	cancelOnDrain := flag.Bool("cancel-on-drain", false, "Cancel resting orders of cancel-on-disconnect WebSocket sessions when draining")
+	matcherListen := flag.String("matcher-listen", "", "Real mode: expose the matcher over gRPC for stateless API nodes (e.g. :9095)")
+	matcherAddr := flag.String("matcher-addr", "", "Run as a stateless API node backed by the matcher at this address")
+	sessionTTL := flag.Duration("session-ttl", 15*time.Minute, "Lifetime of WebSocket session tokens before an in-band refresh is required")
+	faucetAmount := flag.String("faucet-amount", "", "Dev/testnet only: enable POST /v1/faucet granting this much test USDC")
+	faucetCooldown := flag.Duration("faucet-cooldown", api.DefaultFaucetCooldown, "Time between faucet grants to the same address or IP")
+	faucetTrustedProxies := flag.String("faucet-trusted-proxies", "", "Comma-separated IPs or CIDRs of reverse proxies whose X-Forwarded-For the faucet believes")
+	bootstrapAccounts := flag.Int("bootstrap-accounts", 0, "Dev/testnet only: fund this many accounts (dev-trader-1..N) at startup")
+	bootstrapBalance := flag.String("bootstrap-balance", "100000", "Test USDC balance of each bootstrapped account")
+	wsDefaults := websocket.DefaultHubConfig()
+	wsMaxSubs := flag.Int("ws-max-subscriptions", wsDefaults.MaxSubscriptions, "Max WebSocket channel subscriptions per connection (0 = unlimited)")
+	wsSendQueue := flag.Int("ws-send-queue", wsDefaults.SendQueueSize, "Outbound messages buffered per WebSocket connection")
+	wsSlowConsumer := flag.String("ws-slow-consumer", string(wsDefaults.SlowConsumerPolicy), "When a connection's send queue is full: drop_oldest or disconnect")
+	wsPongTimeout := flag.Duration("ws-pong-timeout", wsDefaults.PongTimeout, "Evict WebSocket connections silent for this long; pings are sent at 90% of it")
+	wsReplayBuffer := flag.Int("ws-replay-buffer", wsDefaults.ReplayBuffer, "Sequenced WebSocket messages kept per channel for subscribers resuming with from_seq (0 disables replay)")
+	wsTickerInterval := flag.Duration("ws-ticker-interval", wsDefaults.TickerInterval, "Time between WebSocket ticker pushes; also the shortest and default throttle_ms of ticker subscriptions")
+	wsTickerMaxThrottle := flag.Duration("ws-ticker-max-throttle", wsDefaults.MaxTickerThrottle, "Longest throttle_ms a WebSocket ticker subscription may ask for")
+	publicListen := flag.String("public-listen", "", "Serve read-only, CDN-cacheable market data on this address (e.g. :8081)")
+	publicRPS := flag.Int("public-rps", api.DefaultPublicRequestsPerSecond, "Per-IP request rate limit on the public market data listener")
+	equityInterval := flag.Duration("equity-interval", api.DefaultEquitySnapshotInterval, "Time between account equity snapshots for /v1/accounts/{trader}/equity-history; negative disables")
+	equityRetention := flag.Int("equity-retention", api.DefaultEquityHistoryRetention, "Equity snapshots kept per trader")
+	marketStatsInterval := flag.Duration("market-stats-interval", api.DefaultMarketStatsInterval, "Time between funding rate and open interest samples for /v1/markets/{id}/history; negative disables")
+	marketStatsRetention := flag.Duration("market-stats-retention", api.DefaultMarketStatsRetention, "How long market funding, open interest and volume history is kept")
+	marginCallInterval := flag.Duration("margin-call-interval", api.DefaultMarginCallInterval, "Time between margin call checks of every account; negative disables")
+	marginCallLevels := flag.String("margin-call-levels", "0.8,0.9", "Maintenance margin / equity ratios that trigger a margin call warning")
+	marginCallRepeat := flag.Duration("margin-call-repeat", api.DefaultMarginCallRepeat, "Minimum time between repeated margin calls at the same level")
+	namespaces := flag.String("namespaces", "", "Standalone mode only: comma-separated isolated environments served under /ns/{name}/ or with an X-Namespace header, e.g. \"qa1,qa2,demo\"")
+	exportTTL := flag.Duration("export-ttl", api.DefaultAccountExportTTL, "How long a finished account export (POST /v1/account/export) can be downloaded")
+	reportInterval := flag.Duration("report-interval", api.DefaultReportCheckInterval, "Time between checks for a closed UTC day to send daily account reports for; negative disables")
+	reportRetention := flag.Int("report-retention", api.DefaultReportRetention, "Daily account reports kept per trader for /v1/account/reports/{date}")
+	klineStore := flag.String("kline-store", "", "Real mode: persist candles from engine trades in this store: memory, pebble:<dir> or clickhouse:<dsn>")
+	klineMinuteRetention := flag.Duration("kline-minute-retention", klines.DefaultMinuteRetention, "How long minute candles are kept in the kline store (0 = forever)")
+	klineHourRetention := flag.Duration("kline-hour-retention", klines.DefaultHourRetention, "How long hourly candles are kept in the kline store (0 = forever)")
+	klineDayRetention := flag.Duration("kline-day-retention", 0, "How long daily candles are kept in the kline store (0 = forever)")
+	klineCompactInterval := flag.Duration("kline-compact-interval", api.DefaultKlineCompactInterval, "Time between kline downsampling and retention runs")
+	matchJournal := flag.String("match-journal", "", "Real mode: journal engine events to this file and replay resting orders from it on startup before accepting orders")
+	readyOracleMaxAge := flag.Duration("ready-oracle-max-age", api.DefaultOracleMaxAge, "Oldest oracle price /ready accepts before taking the node out of rotation (negative disables the check)")
+	l3Retention := flag.Int("l3-retention", api.DefaultL3Retention, "L3 (market-by-order) updates kept for GET /v1/l3/events")
+	paramAuditLog := flag.String("param-audit-log", "", "Append protocol parameter changes made through /v1/admin/params to this JSON lines file (empty keeps them in memory)")
+	profileStore := flag.String("profile-store", "", "Save trader profiles to this JSON file and load them on start (empty keeps them in memory)")
+	numberFormat := flag.String("number-format", string(numfmt.FormatRaw), "Decimals in REST and WebSocket payloads of clients that ask for none: raw, string (fixed places per market) or number")
+	faultInject := flag.String("fault-inject", "", "Chaos testing only: inject faults per route, e.g. \"/v1/orders=latency:250ms@20,error:503@5;/v1/markets=partial@10;ws=drop@2\"")
+	auditLog := flag.String("audit-log", "", "Append sampled API requests (method, route, trader, status, latency, redacted body digest) to this JSON lines file for incident forensics")
+	auditLogMaxSize := flag.Int64("audit-log-max-size", middleware.DefaultAuditLogMaxSize>>20, "Size in MB at which the audit log is rotated")
+	auditLogMaxFiles := flag.Int("audit-log-max-files", middleware.DefaultAuditLogMaxFiles, "Rotated audit log files kept")
+	auditSampling := flag.String("audit-sampling", middleware.DefaultAuditSampling, "Percent of requests audited per route, e.g. \"/v1/orders=100;/v1/markets=1;*=0\"")
+	chainGRPC := flag.String("chain-grpc", "", "gRPC address of a perpdexd node (e.g. localhost:9090): enables POST /v1/tx, which simulates signed transactions before broadcasting them")
+	gasAdjustment := flag.Float64("gas-adjustment", chaintx.DefaultGasAdjustment, "Factor simulated gas is multiplied by for the gas limit recommended by /v1/tx/simulate")
+	chainID := flag.String("chain-id", "perpdex-1", "Chain ID that /v1/tx/orders signs transactions for")
+	gasPrice := flag.String("gas-price", "0usdc", "Gas price of transactions built by /v1/tx/orders, e.g. 0.001usdc")
 	flag.Parse()
+	if *matchJournal != "" && !*realMode {
+		log.Fatalf("-match-journal requires -real")
+	}
+
+	slowConsumer, err := websocket.ParseSlowConsumerPolicy(*wsSlowConsumer)
+	if err != nil {
+		log.Fatalf("Invalid -ws-slow-consumer: %v", err)
+	}
+	levels, err := parseMarginCallLevels(*marginCallLevels)
+	if err != nil {
+		log.Fatalf("Invalid -margin-call-levels: %v", err)
+	}
+	numbers, err := numfmt.ParseFormat(*numberFormat)
+	if err != nil {
+		log.Fatalf("Invalid -number-format: %v", err)
+	}
+	faultRules, err := middleware.ParseFaultRules(*faultInject)
+	if err != nil {
+		log.Fatalf("Invalid -fault-inject: %v", err)
+	}
+	auditRules, err := middleware.ParseAuditRules(*auditSampling)
+	if err != nil {
+		log.Fatalf("Invalid -audit-sampling: %v", err)
+	}
+	var script *mocksim.Script
+	if *mockScript != "" {
+		if !*mockMode || *realMode {
+			log.Fatalf("-mock-script requires -mock")
+		}
+		data, err := os.ReadFile(*mockScript)
+		if err != nil {
+			log.Fatalf("Invalid -mock-script: %v", err)
+		}
+		if script, err = mocksim.ParseScript(data); err != nil {
+			log.Fatalf("Invalid -mock-script: %v", err)
+		}
+	}
+	var (
+		chainTx   *chaintx.Client
+		txService *chaintx.TxService
+	)
+	if *chainGRPC != "" {
+		if *gasAdjustment < 1 {
+			log.Fatalf("Invalid -gas-adjustment: %v is below 1", *gasAdjustment)
+		}
+		chainConfig := chaintx.DefaultConfig()
+		chainConfig.GRPCAddr = *chainGRPC
+		chainConfig.GasAdjustment = *gasAdjustment
+		if chainTx, err = chaintx.NewClient(chainConfig); err != nil {
+			log.Fatalf("Invalid -chain-grpc: %v", err)
+		}
+		defer chainTx.Close()
+
+		price, err := sdk.ParseDecCoin(*gasPrice)
+		if err != nil {
+			log.Fatalf("Invalid -gas-price: %v", err)
+		}
+		txConfig := chaintx.TxServiceConfig{ChainID: *chainID, GasPrice: price}
+		if hotKey := os.Getenv("PERPDEX_TX_HOT_KEY"); hotKey != "" {
+			key, err := hex.DecodeString(hotKey)
+			if err != nil || len(key) != secp256k1.PrivKeySize {
+				log.Fatalf("Invalid PERPDEX_TX_HOT_KEY: expected a hex-encoded %d-byte secp256k1 private key", secp256k1.PrivKeySize)
+			}
+			txConfig.HotKey = &secp256k1.PrivKey{Key: key}
+		}
+		txService = chaintx.NewTxService(chainTx, txConfig)
+	}
+	var candleStore klines.Store
+	if *klineStore != "" {
+		if candleStore, err = klines.Open(*klineStore); err != nil {
+			log.Fatalf("Invalid -kline-store: %v", err)
+		}
+		defer candleStore.Close()
+	}
+
+	wsConfig := websocket.DefaultHubConfig()
+	wsConfig.MaxSubscriptions = *wsMaxSubs
+	wsConfig.SendQueueSize = *wsSendQueue
+	wsConfig.SlowConsumerPolicy = slowConsumer
+	wsConfig.PongTimeout = *wsPongTimeout
+	wsConfig.PingInterval = (*wsPongTimeout * 9) / 10
+	wsConfig.ReplayBuffer = *wsReplayBuffer
+	wsConfig.TickerInterval = *wsTickerInterval
+	wsConfig.MaxTickerThrottle = *wsTickerMaxThrottle
 
 	// Create configuration
 	config := &api.Config{
-		Host:             *host,
-		Port:             *port,
-		ReadTimeout:      30 * time.Second,
-		WriteTimeout:     30 * time.Second,
-		MockMode:         *mockMode && !*realMode,
-		DisableRateLimit: *noRateLimit,
+		Host:                    *host,
+		Port:                    *port,
+		ReadTimeout:             30 * time.Second,
+		WriteTimeout:            30 * time.Second,
+		MockMode:                *mockMode && !*realMode,
+		MockScript:              script,
+		DisableRateLimit:        *noRateLimit,
+		DrainTimeout:            *drainTimeout,
+		DrainGracePeriod:        *drainGracePeriod,
+		CancelOnDrain:           *cancelOnDrain,
+		AdminToken:              os.Getenv("PERPDEX_ADMIN_TOKEN"),
+		MatcherListenAddr:       *matcherListen,
+		MatcherAddr:             *matcherAddr,
+		MatcherToken:            os.Getenv("PERPDEX_MATCHER_TOKEN"),
+		SessionSecret:           os.Getenv("PERPDEX_SESSION_SECRET"),
+		SessionTTL:              *sessionTTL,
+		APIKeys:                 parseAPIKeys(os.Getenv("PERPDEX_API_KEYS")),
+		DataLicenses:            parseDataLicenses(os.Getenv("PERPDEX_DATA_LICENSES")),
+		FaucetAmount:            *faucetAmount,
+		FaucetCooldown:          *faucetCooldown,
+		FaucetTrustedProxies:    parseList(*faucetTrustedProxies),
+		WebSocket:               wsConfig,
+		PublicListenAddr:        *publicListen,
+		PublicRequestsPerSecond: *publicRPS,
+		EquitySnapshotInterval:  *equityInterval,
+		EquityHistoryRetention:  *equityRetention,
+		MarketStatsInterval:     *marketStatsInterval,
+		MarketStatsRetention:    *marketStatsRetention,
+		MarginCallInterval:      *marginCallInterval,
+		MarginCallLevels:        levels,
+		MarginCallRepeat:        *marginCallRepeat,
+		FaultInjection:          faultRules,
+		AccountExportTTL:        *exportTTL,
+		ReportCheckInterval:     *reportInterval,
+		ReportRetention:         *reportRetention,
+		Klines:                  candleStore,
+		KlineRetention: klines.Policy{
+			Minute: *klineMinuteRetention,
+			Hour:   *klineHourRetention,
+			Day:    *klineDayRetention,
+		},
+		KlineCompactInterval: *klineCompactInterval,
+		L3Retention:          *l3Retention,
+		MatchJournal:         *matchJournal,
+		ReadyOracleMaxAge:    *readyOracleMaxAge,
+		ParamAuditLog:        *paramAuditLog,
+		ProfileStore:         *profileStore,
+		AuditLog:             *auditLog,
+		AuditLogMaxSize:      *auditLogMaxSize << 20,
+		AuditLogMaxFiles:     *auditLogMaxFiles,
+		AuditSampling:        auditRules,
+		NumberFormat:         numbers,
+		ChainTx:              chainTx,
+		TxService:            txService,
+	}
+	if config.SessionSecret == "" && (*matcherListen != "" || *matcherAddr != "") {
+		log.Println("WARNING: PERPDEX_SESSION_SECRET is not set; WebSocket session tokens will only be valid on this node")
 	}
 
 	var server *api.Server
-	var err error
 
 	// Create server based on mode
-	if *realMode {
+	if *matcherAddr != "" {
+		log.Printf("Initializing stateless API node (matcher: %s)...", *matcherAddr)
+		server, err = api.NewServerWithMatcher(config)
+		if err != nil {
+			log.Fatalf("Failed to connect to matcher: %v", err)
+		}
+	} else if *realMode {
 		log.Println("Initializing with REAL orderbook engine (MatchingEngineV2)...")
 		server, err = api.NewServerWithRealService(config)
 		if err != nil {
@@ -46,16 +253,40 @@ func main() {
 		server = api.NewServer(config)
 	}
 
+	environments := []*api.Server{server}
+	for _, name := range strings.Split(*namespaces, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		ns, err := server.AddNamespace(name)
+		if err != nil {
+			log.Fatalf("Failed to create namespace: %v", err)
+		}
+		environments = append(environments, ns)
+	}
+
+	if *bootstrapAccounts > 0 {
+		for _, env := range environments {
+			traders, err := env.BootstrapAccounts(context.Background(), *bootstrapAccounts, *bootstrapBalance)
+			if err != nil {
+				log.Fatalf("Failed to bootstrap accounts: %v", err)
+			}
+			log.Printf("Bootstrapped %d accounts with %s USDC each: %s", len(traders), *bootstrapBalance, strings.Join(traders, ", "))
+		}
+	}
+
 	// Start server in goroutine
 	go func() {
-		if err := server.Start(); err != nil {
+		if err := server.Start(); err != nil && err != http.ErrServerClosed {
 			log.Printf("Server error: %v", err)
 		}
 	}()
 
 	engineMode := "mock"
 	storageWarning := ""
-	if *realMode {
+	if *matcherAddr != "" {
+		engineMode = "STATELESS (matcher " + *matcherAddr + ")"
+	} else if *realMode {
 		engineMode = "REAL (MatchingEngineV2)"
 		storageWarning = "\n⚠️  WARNING: Using in-memory storage. Data will be lost on restart.\n   For production, ensure connection to a running Cosmos chain."
 	}
@@ -67,25 +298,103 @@ func main() {
 	log.Printf("║  Mode:      %s", engineMode)
 	log.Printf("║  WebSocket: ws://%s:%d/ws", *host, *port)
 	log.Printf("║  Health:    http://%s:%d/health", *host, *port)
+	if *matchJournal != "" {
+		log.Printf("║  Ready:     http://%s:%d/ready (after replaying %s)", *host, *port, *matchJournal)
+	} else {
+		log.Printf("║  Ready:     http://%s:%d/ready", *host, *port)
+	}
+	log.Printf("║  Live:      http://%s:%d/live", *host, *port)
+	if *faucetAmount != "" {
+		log.Printf("║  Faucet:    POST http://%s:%d/v1/faucet (%s USDC)", *host, *port, *faucetAmount)
+	}
+	if *chainGRPC != "" {
+		log.Printf("║  Chain tx:  POST http://%s:%d/v1/tx (node %s, gas x%.2f)", *host, *port, *chainGRPC, *gasAdjustment)
+		if hot := txService.HotAddress(); hot != "" {
+			log.Printf("║  Hot key:   %s signs /v1/tx/orders for admin requests", hot)
+		}
+	}
+	for _, name := range server.Namespaces() {
+		log.Printf("║  Namespace: http://%s:%d/ns/%s/", *host, *port, name)
+	}
 	log.Printf("╚══════════════════════════════════════════════════════════════╝")
+	if len(faultRules) > 0 {
+		log.Printf("⚠️  WARNING: Fault injection enabled (%s). Responses will be delayed, failed or truncated on purpose.", *faultInject)
+	}
 	if storageWarning != "" {
 		log.Print(storageWarning)
 	}
 
-	// Wait for interrupt signal
+	// Wait for interrupt signal or an admin-triggered drain (POST /v1/admin/drain)
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
 
-	log.Println("Shutting down server...")
+	select {
+	case sig := <-quit:
+		log.Printf("Received %s, draining server...", sig)
 
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+		// Drain: stop new orders, flush WebSocket broadcasts, finish in-flight requests
+		ctx, cancel := context.WithTimeout(context.Background(), server.DrainTimeout())
+		defer cancel()
 
-	if err := server.Stop(ctx); err != nil {
-		log.Printf("Server shutdown error: %v", err)
+		if err := server.Drain(ctx); err != nil {
+			log.Printf("Server drain error: %v", err)
+		}
+
+		// A drain may already be running from the admin endpoint
+		select {
+		case <-server.Done():
+		case <-ctx.Done():
+		}
+	case <-server.Done():
+		log.Println("Drain completed")
 	}
 
 	log.Println("Server exited")
 }
+
+// parseAPIKeys parses "key=trader,key=trader" into an API key map
+// parseMarginCallLevels parses a comma-separated list of ratios in (0, 1]
+func parseMarginCallLevels(raw string) ([]float64, error) {
+	var levels []float64
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		level, err := strconv.ParseFloat(field, 64)
+		if err != nil || level <= 0 || level > 1 {
+			return nil, fmt.Errorf("level %q must be a ratio in (0, 1]", field)
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+func parseAPIKeys(raw string) map[string]string {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		key, trader, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" || trader == "" {
+			continue
+		}
+		keys[key] = trader
+	}
+	return keys
+}
+
+// parseDataLicenses parses "trader,trader" into the traders licensed for the
+// L3 feed
+func parseDataLicenses(raw string) []string {
+	return parseList(raw)
+}
+
+// parseList parses a comma-separated list, skipping empty entries
+func parseList(raw string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,80 @@
+// Command benchreport compares two `go test -bench` outputs benchstat-style
+// and exits 1 if any benchmark regressed, so a keeper change can be checked
+// against a baseline:
+//
+//	go test -run '^$' -bench Keeper -benchmem -count 10 ./x/orderbook/keeper/ > old.txt
+//	# apply the change
+//	go test -run '^$' -bench Keeper -benchmem -count 10 ./x/orderbook/keeper/ > new.txt
+//	benchreport -threshold 5 old.txt new.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/openalpha/perp-dex/pkg/benchreport"
+)
+
+func main() {
+	defaults := benchreport.DefaultOptions()
+	alpha := flag.Float64("alpha", defaults.Alpha, "p-value below which a difference is significant")
+	threshold := flag.Float64("threshold", defaults.Threshold*100, "Percent a significant change must worsen a metric by to fail")
+	units := flag.String("units", "", "Comma separated units that can fail, e.g. ns/op,allocs/op (default all)")
+	format := flag.String("format", "text", "Report format: text or markdown")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] old.txt new.txt\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	oldSet, err := parseFile(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", flag.Arg(0), err)
+	}
+	newSet, err := parseFile(flag.Arg(1))
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", flag.Arg(1), err)
+	}
+
+	opts := benchreport.Options{Alpha: *alpha, Threshold: *threshold / 100}
+	if *units != "" {
+		opts.Units = strings.Split(*units, ",")
+	}
+	rows := benchreport.Compare(oldSet, newSet, opts)
+	if len(rows) == 0 {
+		log.Fatal("No benchmarks in common")
+	}
+
+	switch *format {
+	case "text":
+		err = benchreport.WriteText(os.Stdout, rows)
+	case "markdown":
+		err = benchreport.WriteMarkdown(os.Stdout, rows)
+	default:
+		log.Fatalf("Unknown format %q", *format)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	if regressions := benchreport.Regressions(rows); len(regressions) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d regression(s) worse than %.1f%% at p < %.2f\n", len(regressions), *threshold, *alpha)
+		os.Exit(1)
+	}
+}
+
+func parseFile(path string) (*benchreport.Set, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return benchreport.Parse(f)
+}
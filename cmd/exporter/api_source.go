@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	wsapi "github.com/openalpha/perp-dex/api/websocket"
+	"github.com/openalpha/perp-dex/pkg/dataexport"
+)
+
+// apiSource subscribes to the public channels of the API server WebSocket:
+// trades:{market}, depth:{market} and ticker:{market}. Funding rows are
+// taken from ticker updates and only written when the rate or the next
+// funding time changes.
+type apiSource struct {
+	url           string
+	markets       []string
+	depthInterval time.Duration
+	depthLevels   int
+
+	lastDepth   map[string]time.Time
+	lastFunding map[string]string
+}
+
+func newAPISource(url string, markets []string, depthInterval time.Duration, depthLevels int) *apiSource {
+	return &apiSource{
+		url:           url,
+		markets:       markets,
+		depthInterval: depthInterval,
+		depthLevels:   depthLevels,
+		lastDepth:     make(map[string]time.Time),
+		lastFunding:   make(map[string]string),
+	}
+}
+
+// apiMessage is a server message with its payload left undecoded
+type apiMessage struct {
+	Type    string          `json:"type"`
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func (s *apiSource) Run(ctx context.Context, sink *sink) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, s.url, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	for _, market := range s.markets {
+		for channel, w := range map[string]*dataexport.RotatingWriter{
+			"trades:" + market: sink.trades,
+			"depth:" + market:  sink.orderbook,
+			"ticker:" + market: sink.funding,
+		} {
+			if w == nil {
+				continue
+			}
+			req := wsapi.ClientMessage{Action: "subscribe", Channel: channel}
+			if err := conn.WriteJSON(req); err != nil {
+				return fmt.Errorf("failed to subscribe to %s: %w", channel, err)
+			}
+		}
+	}
+	log.Printf("Connected to %s, subscribed to %d markets", s.url, len(s.markets))
+
+	for {
+		var msg apiMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+		if err := s.handle(sink, &msg); err != nil {
+			log.Printf("Skipping %s message: %v", msg.Type, err)
+		}
+	}
+}
+
+func (s *apiSource) handle(sink *sink, msg *apiMessage) error {
+	switch msg.Type {
+	case "trade":
+		var trade wsapi.TradeMessage
+		if err := json.Unmarshal(msg.Data, &trade); err != nil {
+			return err
+		}
+		sink.write(sink.trades, dataexport.Row{
+			trade.Timestamp, trade.MarketID, trade.TradeID, trade.Price, trade.Quantity, trade.Side, int64(0),
+		})
+
+	case "depth":
+		var depth wsapi.DepthMessage
+		if err := json.Unmarshal(msg.Data, &depth); err != nil {
+			return err
+		}
+		// Depth is pushed several times per second; keep one snapshot per interval
+		ts := time.UnixMilli(depth.Timestamp)
+		if ts.Sub(s.lastDepth[depth.MarketID]) < s.depthInterval {
+			return nil
+		}
+		s.lastDepth[depth.MarketID] = ts
+		s.writeLevels(sink, &depth, "buy", depth.Bids)
+		s.writeLevels(sink, &depth, "sell", depth.Asks)
+
+	case "ticker":
+		var ticker wsapi.TickerMessage
+		if err := json.Unmarshal(msg.Data, &ticker); err != nil {
+			return err
+		}
+		key := fmt.Sprintf("%s/%d", ticker.FundingRate, ticker.NextFunding)
+		if s.lastFunding[ticker.MarketID] == key {
+			return nil
+		}
+		s.lastFunding[ticker.MarketID] = key
+		sink.write(sink.funding, dataexport.Row{
+			ticker.Timestamp, ticker.MarketID, ticker.FundingRate, ticker.MarkPrice, ticker.IndexPrice,
+			ticker.NextFunding * 1000, int64(0),
+		})
+	}
+	return nil
+}
+
+func (s *apiSource) writeLevels(sink *sink, depth *wsapi.DepthMessage, side string, levels []wsapi.PriceLevel) {
+	for i, level := range levels {
+		if i >= s.depthLevels {
+			break
+		}
+		sink.write(sink.orderbook, dataexport.Row{
+			depth.Timestamp, depth.MarketID, side, int64(i), level.Price, level.Quantity, int64(depth.Checksum),
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Command exporter streams trades, order book snapshots and funding rates
+// from the API WebSocket or a chain node into rotating CSV or Parquet files
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/openalpha/perp-dex/pkg/dataexport"
+)
+
+func main() {
+	// Command line flags
+	sourceKind := flag.String("source", "api", "Event source: api (API server WebSocket) or node (CometBFT RPC WebSocket)")
+	url := flag.String("url", "", "Source WebSocket URL (default ws://localhost:8080/ws for api, ws://localhost:26657/websocket for node)")
+	markets := flag.String("markets", "BTC-USDC,ETH-USDC,SOL-USDC", "Comma separated markets to export; the node source exports all markets when empty")
+	datasets := flag.String("datasets", "trades,orderbook,funding", "Comma separated datasets to export")
+	outDir := flag.String("out", "./data", "Output directory; each dataset is written to its own subdirectory")
+	format := flag.String("format", "csv", "Output format: csv or parquet")
+	rotate := flag.Duration("rotate", time.Hour, "Start a new file at every interval boundary (0 disables)")
+	maxRows := flag.Int("max-rows", 0, "Start a new file after this many rows (0 disables)")
+	rowGroupSize := flag.Int("row-group-size", dataexport.DefaultRowGroupSize, "Rows per Parquet row group")
+	depthInterval := flag.Duration("depth-interval", time.Second, "Api source: minimum time between order book snapshots of a market")
+	depthLevels := flag.Int("depth-levels", 20, "Api source: price levels per side kept in each order book snapshot")
+	flag.Parse()
+
+	fileFormat, err := dataexport.ParseFormat(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	config := dataexport.RotateConfig{
+		Dir:          *outDir,
+		Format:       fileFormat,
+		Interval:     *rotate,
+		MaxRows:      *maxRows,
+		RowGroupSize: *rowGroupSize,
+	}
+	sink, err := newSink(config, splitList(*datasets))
+	if err != nil {
+		log.Fatalf("Failed to create output: %v", err)
+	}
+
+	var src source
+	switch *sourceKind {
+	case "api":
+		if *url == "" {
+			*url = "ws://localhost:8080/ws"
+		}
+		src = newAPISource(*url, splitList(*markets), *depthInterval, *depthLevels)
+	case "node":
+		if *url == "" {
+			*url = "ws://localhost:26657/websocket"
+		}
+		if sink.orderbook != nil {
+			log.Println("WARNING: the node source emits no order book snapshots; use -source api to export them")
+		}
+		src = newNodeSource(*url, splitList(*markets))
+	default:
+		log.Fatalf("Unknown source %q (expected api or node)", *sourceKind)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	log.Printf("Exporting %s from %s (%s) to %s as %s", *datasets, *url, *sourceKind, *outDir, fileFormat)
+
+	go sink.rotateLoop(ctx)
+	run(ctx, src, sink)
+
+	if err := sink.Close(); err != nil {
+		log.Fatalf("Failed to complete output files: %v", err)
+	}
+	log.Println("Exporter stopped")
+}
+
+// source streams rows into sink until the connection fails or ctx is done
+type source interface {
+	Run(ctx context.Context, sink *sink) error
+}
+
+// run keeps the source connected, reconnecting with backoff
+func run(ctx context.Context, src source, sink *sink) {
+	backoff := time.Second
+	for {
+		start := time.Now()
+		err := src.Run(ctx, sink)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) > time.Minute {
+			backoff = time.Second
+		}
+		log.Printf("Source disconnected: %v; reconnecting in %s", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// sink holds one rotating writer per enabled dataset; a nil writer means
+// the dataset is disabled
+type sink struct {
+	trades    *dataexport.RotatingWriter
+	orderbook *dataexport.RotatingWriter
+	funding   *dataexport.RotatingWriter
+}
+
+func newSink(config dataexport.RotateConfig, datasets []string) (*sink, error) {
+	s := &sink{}
+	for _, name := range datasets {
+		var err error
+		switch name {
+		case dataexport.TradesSchema.Name:
+			s.trades, err = dataexport.NewRotatingWriter(config, dataexport.TradesSchema)
+		case dataexport.OrderbookSchema.Name:
+			s.orderbook, err = dataexport.NewRotatingWriter(config, dataexport.OrderbookSchema)
+		case dataexport.FundingSchema.Name:
+			s.funding, err = dataexport.NewRotatingWriter(config, dataexport.FundingSchema)
+		default:
+			err = fmt.Errorf("unknown dataset %q (expected trades, orderbook or funding)", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *sink) writers() []*dataexport.RotatingWriter {
+	var writers []*dataexport.RotatingWriter
+	for _, w := range []*dataexport.RotatingWriter{s.trades, s.orderbook, s.funding} {
+		if w != nil {
+			writers = append(writers, w)
+		}
+	}
+	return writers
+}
+
+// write appends a row to w, logging failures so one bad row does not stop
+// the export
+func (s *sink) write(w *dataexport.RotatingWriter, row dataexport.Row) {
+	if w == nil {
+		return
+	}
+	if err := w.Write(row); err != nil {
+		log.Printf("Failed to write row: %v", err)
+	}
+}
+
+// rotateLoop completes files whose interval ended while no rows arrived
+func (s *sink) rotateLoop(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, w := range s.writers() {
+				if err := w.RotateIfDue(); err != nil {
+					log.Printf("Failed to rotate file: %v", err)
+				}
+			}
+		}
+	}
+}
+
+func (s *sink) Close() error {
+	var firstErr error
+	for _, w := range s.writers() {
+		if err := w.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,87 @@
+// Command gateway runs the multi-region order gateway in front of a region's
+// API nodes: market data is served locally, order flow goes to the primary
+// order region with sticky sessions and fails over when it goes down
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/openalpha/perp-dex/api/gateway"
+)
+
+func main() {
+	defaults := gateway.DefaultConfig()
+
+	// Command line flags
+	listen := flag.String("listen", ":8000", "Address the gateway listens on")
+	region := flag.String("region", "", "Region this gateway runs in; its API nodes serve market data")
+	regions := flag.String("regions", "", "Regions and their API nodes, e.g. \"us-east=http://a:8080,http://b:8080;eu-west=http://c:8080\"")
+	orderRegions := flag.String("order-regions", "", "Comma-separated regions that can take order flow, primary first (default: the first region)")
+	probeInterval := flag.Duration("probe-interval", defaults.ProbeInterval, "Time between /ready probes of every API node")
+	probeTimeout := flag.Duration("probe-timeout", defaults.ProbeTimeout, "Per-probe timeout")
+	failureThreshold := flag.Int("failure-threshold", defaults.FailureThreshold, "Consecutive failed probes or requests before a node is taken out")
+	recoveryThreshold := flag.Int("recovery-threshold", defaults.RecoveryThreshold, "Consecutive good probes before a node is put back")
+	stickyTTL := flag.Duration("sticky-ttl", defaults.StickyTTL, "How long an idle client stays pinned to its order node")
+	flag.Parse()
+
+	parsed, err := gateway.ParseRegions(*regions)
+	if err != nil {
+		log.Fatalf("Invalid -regions: %v", err)
+	}
+	config := &gateway.Config{
+		LocalRegion:       *region,
+		Regions:           parsed,
+		ProbeInterval:     *probeInterval,
+		ProbeTimeout:      *probeTimeout,
+		FailureThreshold:  *failureThreshold,
+		RecoveryThreshold: *recoveryThreshold,
+		StickyTTL:         *stickyTTL,
+		AdminToken:        os.Getenv("PERPDEX_ADMIN_TOKEN"),
+	}
+	for _, name := range strings.Split(*orderRegions, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			config.OrderRegions = append(config.OrderRegions, name)
+		}
+	}
+	if len(config.OrderRegions) == 0 && len(parsed) > 0 {
+		config.OrderRegions = []string{parsed[0].Name}
+	}
+
+	gw, err := gateway.New(config)
+	if err != nil {
+		log.Fatalf("Invalid gateway configuration: %v", err)
+	}
+	gw.Start()
+	defer gw.Stop()
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           gw,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Gateway error: %v", err)
+		}
+	}()
+	log.Printf("Gateway for %s listening on %s (order regions: %s)", *region, *listen, strings.Join(config.OrderRegions, ", "))
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Shutting down gateway...")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Gateway shutdown error: %v", err)
+	}
+}
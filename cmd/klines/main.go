@@ -0,0 +1,167 @@
+// Command klines maintains a persistent kline store: backfill rebuilds
+// minute candles from the trade journal (the API event log) and compact
+// downsamples them and applies the retention policy
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+	"github.com/openalpha/perp-dex/pkg/klines"
+)
+
+func main() {
+	// Command line flags
+	storeSpec := flag.String("store", "", "Kline store: pebble:<dir> or clickhouse:<dsn>. A pebble store must not be open in a running API server")
+	apiURL := flag.String("api", "http://localhost:8080", "Backfill: API server whose event log is the trade journal")
+	fromSeq := flag.Uint64("from-seq", 0, "Backfill: first event sequence number to read")
+	markets := flag.String("markets", "BTC-USDC,ETH-USDC,SOL-USDC", "Compact: comma separated markets to downsample and prune")
+	minuteRetention := flag.Duration("minute-retention", klines.DefaultMinuteRetention, "How long minute candles are kept (0 = forever)")
+	hourRetention := flag.Duration("hour-retention", klines.DefaultHourRetention, "How long hourly candles are kept (0 = forever)")
+	dayRetention := flag.Duration("day-retention", 0, "How long daily candles are kept (0 = forever)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] backfill|compact\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *storeSpec == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	store, err := klines.Open(*storeSpec)
+	if err != nil {
+		log.Fatalf("Failed to open kline store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	policy := klines.Policy{Minute: *minuteRetention, Hour: *hourRetention, Day: *dayRetention}
+	marketIDs := splitList(*markets)
+
+	switch flag.Arg(0) {
+	case "backfill":
+		traded, err := backfill(ctx, store, *apiURL, *fromSeq)
+		if err != nil {
+			log.Fatalf("Backfill failed: %v", err)
+		}
+		// Downsample what was backfilled, along with the configured markets
+		for _, marketID := range traded {
+			if !contains(marketIDs, marketID) {
+				marketIDs = append(marketIDs, marketID)
+			}
+		}
+		fallthrough
+	case "compact":
+		if err := klines.NewCompactor(store, policy).Run(ctx, marketIDs, time.Now()); err != nil {
+			log.Fatalf("Compaction failed: %v", err)
+		}
+		log.Printf("Compacted %s", strings.Join(marketIDs, ", "))
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// backfill pages through the event log from fromSeq and rebuilds the minute
+// candles of every trade in it, returning the markets traded
+func backfill(ctx context.Context, store klines.Store, apiURL string, fromSeq uint64) ([]string, error) {
+	b := klines.NewBackfiller(store)
+	traded := make([]string, 0)
+	next := fromSeq
+	for {
+		page, err := fetchEvents(ctx, apiURL, next)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range page.Events {
+			if event.Trade == nil {
+				continue
+			}
+			trade, err := journalTrade(event.Trade)
+			if err != nil {
+				return nil, fmt.Errorf("event %d: %w", event.Seq, err)
+			}
+			if err := b.Add(ctx, trade); err != nil {
+				return nil, err
+			}
+			if !contains(traded, trade.MarketID) {
+				traded = append(traded, trade.MarketID)
+			}
+		}
+		next = page.NextSeq
+		if len(page.Events) == 0 || next > page.LastSeq {
+			break
+		}
+	}
+
+	written, err := b.Flush(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Backfilled %d minute candles from events %d to %d", written, fromSeq, next-1)
+	return traded, nil
+}
+
+// fetchEvents reads one page of GET /v1/events
+func fetchEvents(ctx context.Context, apiURL string, fromSeq uint64) (*types.EventsResponse, error) {
+	url := fmt.Sprintf("%s/v1/events?from_seq=%d&limit=1000", strings.TrimSuffix(apiURL, "/"), fromSeq)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	var page types.EventsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("GET %s: %w", url, err)
+	}
+	return &page, nil
+}
+
+func journalTrade(t *types.MarketTrade) (klines.Trade, error) {
+	price, err := math.LegacyNewDecFromStr(t.Price)
+	if err != nil {
+		return klines.Trade{}, fmt.Errorf("invalid price %q: %w", t.Price, err)
+	}
+	quantity, err := math.LegacyNewDecFromStr(t.Quantity)
+	if err != nil {
+		return klines.Trade{}, fmt.Errorf("invalid quantity %q: %w", t.Quantity, err)
+	}
+	return klines.Trade{MarketID: t.MarketID, Price: price, Quantity: quantity, Time: time.UnixMilli(t.Timestamp)}, nil
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
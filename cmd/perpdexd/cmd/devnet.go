@@ -0,0 +1,372 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"cosmossdk.io/math"
+	cmtcfg "github.com/cometbft/cometbft/config"
+	cmttypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/input"
+	"github.com/cosmos/cosmos-sdk/codec"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/server"
+	serverconfig "github.com/cosmos/cosmos-sdk/server/config"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	"github.com/spf13/cobra"
+
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	riverpooltypes "github.com/openalpha/perp-dex/x/riverpool/types"
+	treasurytypes "github.com/openalpha/perp-dex/x/treasury/types"
+)
+
+// Devnet flags
+const (
+	flagTraders        = "traders"
+	flagTraderBalance  = "trader-balance"
+	flagTraderMargin   = "trader-margin"
+	flagMarkets        = "markets"
+	flagBlockTime      = "block-time"
+	flagOverwrite      = "overwrite"
+	devnetAccountsFile = "devnet-accounts.json"
+)
+
+// Devnet defaults, matching scripts/init-chain.sh
+const (
+	defaultDevnetChainID   = "perpdex-1"
+	defaultValidatorCoins  = "1000000000000usdc,100000000000stake"
+	defaultTraderBalance   = "10000000000usdc"
+	defaultTraderMargin    = "10000"
+	defaultDevnetBlockTime = 200 * time.Millisecond
+	devnetValidatorPower   = 100
+)
+
+// devnetMarkPrices are the initial mark prices of the markets a devnet can
+// list
+var devnetMarkPrices = map[string]int64{
+	"BTC-USDC": 50000,
+	"ETH-USDC": 3000,
+	"SOL-USDC": 100,
+	"ARB-USDC": 1,
+}
+
+// DevnetAccount is a key created by init-devnet
+type DevnetAccount struct {
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Mnemonic string `json:"mnemonic"`
+}
+
+// InitDevnetCmd returns the command that bootstraps a single-node devnet
+func InitDevnetCmd(basicManager module.BasicManager, defaultNodeHome string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init-devnet [moniker]",
+		Short: "Initialize a single-node devnet with funded trading accounts",
+		Long: `Initialize a single-node devnet in --home in one step: the node and validator
+keys, a validator key and --traders trader keys in the keyring, and a genesis
+with their bank balances, the default markets at their initial mark prices,
+trading accounts pre-funded with --trader-margin USDC, the Foundation LP and
+Main LP pools, and a --block-time commit timeout.
+
+The keys and their mnemonics are written to devnet-accounts.json in --home.
+If --home already holds a genesis file you are asked before it is replaced,
+unless --overwrite is set.
+
+Examples:
+  perpdexd init-devnet
+  perpdexd init-devnet --home .perpdex-test --traders 5 --block-time 500ms
+  perpdexd init-devnet --markets BTC-USDC,ETH-USDC --overwrite && perpdexd start`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+			config := server.GetServerContextFromCmd(cmd).Config
+
+			home, _ := cmd.Flags().GetString(flags.FlagHome)
+			chainID, _ := cmd.Flags().GetString(flags.FlagChainID)
+			keyringBackend, _ := cmd.Flags().GetString(flags.FlagKeyringBackend)
+			traders, _ := cmd.Flags().GetInt(flagTraders)
+			traderBalance, _ := cmd.Flags().GetString(flagTraderBalance)
+			traderMargin, _ := cmd.Flags().GetString(flagTraderMargin)
+			markets, _ := cmd.Flags().GetStringSlice(flagMarkets)
+			blockTime, _ := cmd.Flags().GetDuration(flagBlockTime)
+			overwrite, _ := cmd.Flags().GetBool(flagOverwrite)
+
+			moniker := "devnet"
+			if len(args) > 0 {
+				moniker = args[0]
+			}
+			if traders < 1 {
+				return fmt.Errorf("--%s must be at least 1", flagTraders)
+			}
+			if blockTime <= 0 {
+				return fmt.Errorf("--%s must be positive", flagBlockTime)
+			}
+			traderCoins, err := sdk.ParseCoinsNormalized(traderBalance)
+			if err != nil {
+				return fmt.Errorf("invalid --%s: %w", flagTraderBalance, err)
+			}
+			validatorCoins, err := sdk.ParseCoinsNormalized(defaultValidatorCoins)
+			if err != nil {
+				return err
+			}
+			margin, err := math.LegacyNewDecFromStr(traderMargin)
+			if err != nil || margin.IsNegative() {
+				return fmt.Errorf("invalid --%s: %q", flagTraderMargin, traderMargin)
+			}
+
+			config.SetRoot(home)
+			config.Moniker = moniker
+			config.Consensus.TimeoutCommit = blockTime
+
+			genFile := config.GenesisFile()
+			if _, err := os.Stat(genFile); err == nil && !overwrite {
+				ok, err := input.GetConfirmation(
+					fmt.Sprintf("%s already exists; replace the devnet and its keys?", genFile),
+					bufio.NewReader(cmd.InOrStdin()), cmd.ErrOrStderr())
+				if err != nil || !ok {
+					return fmt.Errorf("genesis file already exists: %s (use --%s to replace it)", genFile, flagOverwrite)
+				}
+				overwrite = true
+			}
+			if overwrite {
+				for _, dir := range []string{"config", "data", "keyring-" + keyringBackend} {
+					if err := os.RemoveAll(filepath.Join(home, dir)); err != nil {
+						return err
+					}
+				}
+			}
+			if err := os.MkdirAll(filepath.Join(home, "config"), 0o755); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Join(home, "data"), 0o755); err != nil {
+				return err
+			}
+
+			// Node and validator keys, and a config.toml with the short
+			// commit timeout
+			nodeID, valPubKey, err := genutil.InitializeNodeValidatorFiles(config)
+			if err != nil {
+				return err
+			}
+			cmtcfg.WriteConfigFile(filepath.Join(home, "config", "config.toml"), config)
+
+			appConfig := serverconfig.DefaultConfig()
+			appConfig.MinGasPrices = "0usdc"
+			appConfig.API.Enable = true
+			serverconfig.WriteConfigFile(filepath.Join(home, "config", "app.toml"), appConfig)
+
+			// Keyring accounts
+			kr, err := keyring.New(sdk.KeyringServiceName(), keyringBackend, home, cmd.InOrStdin(), clientCtx.Codec)
+			if err != nil {
+				return err
+			}
+			names := []string{"validator"}
+			for i := 1; i <= traders; i++ {
+				names = append(names, fmt.Sprintf("trader%d", i))
+			}
+			accounts := make([]DevnetAccount, 0, len(names))
+			balances := make([]banktypes.Balance, 0, len(names))
+			for i, name := range names {
+				record, mnemonic, err := kr.NewMnemonic(name, keyring.English, sdk.FullFundraiserPath, keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+				if err != nil {
+					return fmt.Errorf("failed to create key %s: %w", name, err)
+				}
+				addr, err := record.GetAddress()
+				if err != nil {
+					return err
+				}
+				accounts = append(accounts, DevnetAccount{Name: name, Address: addr.String(), Mnemonic: mnemonic})
+
+				coins := traderCoins
+				if i == 0 {
+					coins = validatorCoins
+				}
+				balances = append(balances, banktypes.Balance{Address: addr.String(), Coins: coins})
+			}
+
+			traderAddrs := make([]string, 0, traders)
+			for _, account := range accounts[1:] {
+				traderAddrs = append(traderAddrs, account.Address)
+			}
+			appState, err := DevnetAppState(clientCtx.Codec, basicManager, balances, markets, traderAddrs, margin)
+			if err != nil {
+				return err
+			}
+			appStateJSON, err := json.MarshalIndent(appState, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			// The validator is listed in the genesis doc, which the app's
+			// InitChainer accepts without a gentx
+			cmtPubKey, err := cryptocodec.ToCmtPubKeyInterface(valPubKey)
+			if err != nil {
+				return err
+			}
+			appGenesis := genutiltypes.NewAppGenesisWithVersion(chainID, appStateJSON)
+			appGenesis.Consensus.Validators = []cmttypes.GenesisValidator{{
+				Address: cmtPubKey.Address(),
+				PubKey:  cmtPubKey,
+				Power:   devnetValidatorPower,
+				Name:    moniker,
+			}}
+			if err := appGenesis.ValidateAndComplete(); err != nil {
+				return err
+			}
+			if err := genutil.ExportGenesisFile(appGenesis, genFile); err != nil {
+				return err
+			}
+
+			accountsJSON, err := json.MarshalIndent(accounts, "", "  ")
+			if err != nil {
+				return err
+			}
+			accountsFile := filepath.Join(home, devnetAccountsFile)
+			if err := os.WriteFile(accountsFile, accountsJSON, 0o600); err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Initialized devnet %s in %s\n", chainID, home)
+			fmt.Fprintf(out, "  node ID:    %s\n", nodeID)
+			fmt.Fprintf(out, "  block time: %s\n", blockTime)
+			fmt.Fprintf(out, "  markets:    %v\n", markets)
+			for _, account := range accounts {
+				fmt.Fprintf(out, "  %-10s  %s\n", account.Name, account.Address)
+			}
+			fmt.Fprintf(out, "Mnemonics are in %s\n", accountsFile)
+			fmt.Fprintf(out, "Start the node with: perpdexd start --home %s\n", home)
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flags.FlagHome, defaultNodeHome, "The devnet home directory")
+	cmd.Flags().String(flags.FlagChainID, defaultDevnetChainID, "The devnet chain ID")
+	cmd.Flags().String(flags.FlagKeyringBackend, keyring.BackendTest, "Keyring backend for the devnet keys")
+	cmd.Flags().Int(flagTraders, 3, "Number of funded trader accounts")
+	cmd.Flags().String(flagTraderBalance, defaultTraderBalance, "Bank balance of each trader")
+	cmd.Flags().String(flagTraderMargin, defaultTraderMargin, "USDC margin pre-funded in each trader's trading account")
+	cmd.Flags().StringSlice(flagMarkets, devnetMarkets(), "Markets to list at genesis")
+	cmd.Flags().Duration(flagBlockTime, defaultDevnetBlockTime, "Commit timeout, which sets the block time")
+	cmd.Flags().Bool(flagOverwrite, false, "Replace an existing devnet in --home without asking")
+	return cmd
+}
+
+// devnetMarkets returns the markets a devnet can list, sorted
+func devnetMarkets() []string {
+	markets := make([]string, 0, len(devnetMarkPrices))
+	for marketID := range devnetMarkPrices {
+		markets = append(markets, marketID)
+	}
+	sort.Strings(markets)
+	return markets
+}
+
+// DevnetAppState returns the default genesis app state with the bank
+// balances and their auth accounts, the markets at their devnet mark prices,
+// the traders' trading accounts funded with margin, and the default pools
+func DevnetAppState(
+	cdc codec.Codec,
+	basicManager module.BasicManager,
+	balances []banktypes.Balance,
+	markets []string,
+	traders []string,
+	margin math.LegacyDec,
+) (map[string]json.RawMessage, error) {
+	appState := basicManager.DefaultGenesis(cdc)
+
+	genAccounts := make(authtypes.GenesisAccounts, 0, len(balances))
+	supply := sdk.NewCoins()
+	for _, balance := range balances {
+		addr, err := sdk.AccAddressFromBech32(balance.Address)
+		if err != nil {
+			return nil, err
+		}
+		genAccounts = append(genAccounts, authtypes.NewBaseAccount(addr, nil, 0, 0))
+		supply = supply.Add(balance.Coins...)
+	}
+	authGenState := authtypes.GetGenesisStateFromAppState(cdc, appState)
+	packed, err := authtypes.PackAccounts(genAccounts)
+	if err != nil {
+		return nil, err
+	}
+	authGenState.Accounts = packed
+	if appState[authtypes.ModuleName], err = cdc.MarshalJSON(&authGenState); err != nil {
+		return nil, err
+	}
+
+	bankGenState := banktypes.GetGenesisStateFromAppState(cdc, appState)
+	bankGenState.Balances = banktypes.SanitizeGenesisBalances(balances)
+	bankGenState.Supply = supply
+	if appState[banktypes.ModuleName], err = cdc.MarshalJSON(bankGenState); err != nil {
+		return nil, err
+	}
+
+	perpGenesis, err := devnetPerpetualGenesis(markets, traders, margin)
+	if err != nil {
+		return nil, err
+	}
+	customGenesis := map[string]interface{}{
+		"perpetual": perpGenesis,
+		"orderbook": orderbooktypes.DefaultGenesis(),
+		"riverpool": devnetRiverpoolGenesis(),
+		"treasury":  treasurytypes.DefaultGenesis(),
+	}
+	for key, gs := range customGenesis {
+		if appState[key], err = json.Marshal(gs); err != nil {
+			return nil, err
+		}
+	}
+	return appState, nil
+}
+
+// devnetPerpetualGenesis lists the markets at their devnet mark prices and
+// funds each trader's trading account with margin
+func devnetPerpetualGenesis(markets, traders []string, margin math.LegacyDec) (*perpetualtypes.GenesisState, error) {
+	if len(markets) == 0 {
+		return nil, fmt.Errorf("no markets given")
+	}
+	configs := perpetualtypes.DefaultMarketConfigs()
+
+	gs := perpetualtypes.DefaultGenesis()
+	for _, marketID := range markets {
+		config, ok := configs[marketID]
+		price, priced := devnetMarkPrices[marketID]
+		if !ok || !priced {
+			return nil, fmt.Errorf("unknown devnet market %q (use one of %v)", marketID, devnetMarkets())
+		}
+		gs.Markets = append(gs.Markets, perpetualtypes.NewMarketWithConfig(config))
+		gs.Prices = append(gs.Prices, perpetualtypes.NewPriceInfo(marketID, math.LegacyNewDec(price)))
+	}
+	for _, trader := range traders {
+		account := perpetualtypes.NewAccount(trader)
+		account.Balance = margin
+		gs.Accounts = append(gs.Accounts, account)
+	}
+	return gs, gs.Validate()
+}
+
+// devnetRiverpoolGenesis lists the Foundation LP and Main LP pools
+func devnetRiverpoolGenesis() *riverpooltypes.GenesisState {
+	gs := riverpooltypes.DefaultGenesis()
+	for _, pool := range []*riverpooltypes.Pool{riverpooltypes.NewFoundationPool(), riverpooltypes.NewMainPool()} {
+		gs.Pools = append(gs.Pools, pool)
+		gs.PoolStats = append(gs.PoolStats, riverpooltypes.NewPoolStats(pool.PoolID))
+	}
+	return gs
+}
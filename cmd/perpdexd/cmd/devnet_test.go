@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+)
+
+// TestDevnetGenesis tests that the devnet lists the requested markets, funds
+// every trader and creates the default pools
+func TestDevnetGenesis(t *testing.T) {
+	margin := math.LegacyNewDec(10000)
+	gs, err := devnetPerpetualGenesis([]string{"BTC-USDC", "ETH-USDC"}, []string{"trader1", "trader2"}, margin)
+	if err != nil {
+		t.Fatalf("failed to build perpetual genesis: %v", err)
+	}
+	if len(gs.Markets) != 2 || len(gs.Prices) != 2 {
+		t.Fatalf("expected 2 markets with prices, got %d markets and %d prices", len(gs.Markets), len(gs.Prices))
+	}
+	if !gs.Prices[0].MarkPrice.Equal(math.LegacyNewDec(50000)) {
+		t.Errorf("expected BTC-USDC at 50000, got %s", gs.Prices[0].MarkPrice)
+	}
+	if len(gs.Accounts) != 2 {
+		t.Fatalf("expected 2 trading accounts, got %d", len(gs.Accounts))
+	}
+	for _, account := range gs.Accounts {
+		if !account.Balance.Equal(margin) {
+			t.Errorf("expected %s funded with %s, got %s", account.Trader, margin, account.Balance)
+		}
+	}
+
+	if _, err := devnetPerpetualGenesis([]string{"DOGE-USDC"}, nil, margin); err == nil {
+		t.Error("expected an unknown market to be rejected")
+	}
+	if _, err := devnetPerpetualGenesis(nil, nil, margin); err == nil {
+		t.Error("expected a devnet without markets to be rejected")
+	}
+
+	pools := devnetRiverpoolGenesis()
+	if err := pools.Validate(); err != nil {
+		t.Fatalf("invalid riverpool genesis: %v", err)
+	}
+	if len(pools.Pools) != 2 || pools.Pools[0].PoolID != "foundation-lp" || pools.Pools[1].PoolID != "main-lp" {
+		t.Errorf("expected the Foundation LP and Main LP pools, got %v", pools.Pools)
+	}
+}
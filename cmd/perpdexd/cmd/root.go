@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"errors"
 	"io"
 	"os"
 	"time"
@@ -97,6 +96,7 @@ Inspired by Hyperliquid architecture for high-performance trading.`,
 func initRootCmd(rootCmd *cobra.Command, encodingConfig app.EncodingConfig, basicManager module.BasicManager) {
 	rootCmd.AddCommand(
 		genutilcli.InitCmd(basicManager, app.DefaultNodeHome),
+		InitDevnetCmd(basicManager, app.DefaultNodeHome),
 		debug.Cmd(),
 		confixcmd.ConfigCommand(),
 		pruning.Cmd(newApp, app.DefaultNodeHome),
@@ -199,7 +199,7 @@ func appExport(
 		}
 	}
 
-	return servertypes.ExportedApp{}, errors.New("export not implemented")
+	return perpdexApp.ExportAppStateAndValidators(forZeroHeight, jailAllowedAddrs, modulesToExport)
 }
 
 // initSDKConfig initializes the SDK config
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/api/types"
+)
+
+// apiClient places and cancels the seeded quotes through the REST API
+type apiClient struct {
+	baseURL string
+	trader  string
+	apiKey  string
+	client  *http.Client
+}
+
+func newAPIClient(baseURL, trader, apiKey string) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		trader:  trader,
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// do sends a request as the seeding trader and decodes a 2xx response into out
+func (c *apiClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		bz, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(bz)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Trader-Address", c.trader)
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("%s %s: %s %s", method, path, resp.Status, apiErr.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// rules reads a market's tick, lot and minimum order size. Markets that
+// report no lot size are quoted in multiples of the minimum order size.
+func (c *apiClient) rules(ctx context.Context, marketID string) (marketRules, error) {
+	var market struct {
+		TickSize     string `json:"tick_size"`
+		LotSize      string `json:"lot_size"`
+		MinOrderSize string `json:"min_order_size"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v1/markets/"+url.PathEscape(marketID), nil, &market); err != nil {
+		return marketRules{}, err
+	}
+
+	var rules marketRules
+	var err error
+	if rules.TickSize, err = math.LegacyNewDecFromStr(market.TickSize); err != nil {
+		return marketRules{}, fmt.Errorf("%s: invalid tick size %q", marketID, market.TickSize)
+	}
+	if market.MinOrderSize != "" {
+		if rules.MinOrderSize, err = math.LegacyNewDecFromStr(market.MinOrderSize); err != nil {
+			return marketRules{}, fmt.Errorf("%s: invalid min order size %q", marketID, market.MinOrderSize)
+		}
+	}
+	rules.LotSize = rules.MinOrderSize
+	if market.LotSize != "" {
+		if rules.LotSize, err = math.LegacyNewDecFromStr(market.LotSize); err != nil {
+			return marketRules{}, fmt.Errorf("%s: invalid lot size %q", marketID, market.LotSize)
+		}
+	}
+	return rules, nil
+}
+
+// oracleMid returns a market's index price, or its mark price when the
+// node has no index; zero when it has neither
+func (c *apiClient) oracleMid(ctx context.Context, marketID string) (math.LegacyDec, error) {
+	var ticker struct {
+		MarkPrice  string `json:"mark_price"`
+		IndexPrice string `json:"index_price"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v1/markets/"+url.PathEscape(marketID)+"/ticker", nil, &ticker); err != nil {
+		return math.LegacyZeroDec(), err
+	}
+	for _, price := range []string{ticker.IndexPrice, ticker.MarkPrice} {
+		if d, err := math.LegacyNewDecFromStr(price); err == nil && d.IsPositive() {
+			return d, nil
+		}
+	}
+	return math.LegacyZeroDec(), nil
+}
+
+// deposit credits the seeding trader with margin for its quotes
+func (c *apiClient) deposit(ctx context.Context, amount string) error {
+	return c.do(ctx, http.MethodPost, "/v1/account/deposit", &types.DepositRequest{Trader: c.trader, Amount: amount}, nil)
+}
+
+// place rests a GTC limit order and returns its ID
+func (c *apiClient) place(ctx context.Context, marketID string, q quote) (string, error) {
+	req := &types.PlaceOrderRequest{
+		MarketID: marketID,
+		Side:     q.Side,
+		Type:     "limit",
+		Price:    q.Price.String(),
+		Quantity: q.Quantity.String(),
+		Trader:   c.trader,
+	}
+	var resp types.PlaceOrderResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/orders", req, &resp); err != nil {
+		return "", err
+	}
+	if resp.Order == nil {
+		return "", fmt.Errorf("POST /v1/orders: no order in the response")
+	}
+	return resp.Order.OrderID, nil
+}
+
+// cancel cancels one of the seeding trader's orders
+func (c *apiClient) cancel(ctx context.Context, orderID string) error {
+	return c.do(ctx, http.MethodDelete, "/v1/orders/"+url.PathEscape(orderID), nil, nil)
+}
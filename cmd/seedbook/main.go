@@ -0,0 +1,270 @@
+// Command seedbook populates a local API server's markets with resting
+// liquidity shaped from a reference exchange's live L2 book, or a saved
+// snapshot of one, and optionally keeps the quotes refreshed around the
+// oracle mid, so UI and algo testing does not start with empty books
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+func main() {
+	// Command line flags
+	apiURL := flag.String("api", "http://localhost:8080", "API server to seed")
+	markets := flag.String("markets", "BTC-USDC,ETH-USDC,SOL-USDC", "Comma separated markets to seed")
+	referenceURL := flag.String("reference", DefaultReferenceURL, "Hyperliquid info endpoint the books are shaped from")
+	coins := flag.String("coins", "", "Reference coin per market, e.g. \"BTC-USDC=BTC,ETH-USDC=ETH\" (default: the market's base asset)")
+	snapshotPath := flag.String("snapshot", "", "Shape the books from a snapshot saved with -save instead of the reference exchange")
+	savePath := flag.String("save", "", "Save the reference books to this file for later -snapshot runs")
+	levels := flag.Int("levels", 20, "Most levels quoted per side")
+	sizeScale := flag.String("size-scale", "1", "Multiplier of the reference sizes")
+	maxLevelSize := flag.String("max-level-size", "0", "Largest size quoted at one level (0 = no cap)")
+	trader := flag.String("trader", "seedbook", "Trader the quotes are placed for")
+	apiKey := flag.String("api-key", "", "X-API-Key sent with every request, if the server requires one")
+	deposit := flag.String("deposit", "1000000", "Margin deposited for the trader before quoting (0 = none)")
+	refresh := flag.Duration("refresh", 0, "How often quotes are checked against the oracle mid (0 = seed once and exit)")
+	requoteMove := flag.String("requote-move", "0.001", "Relative mid move that replaces the quotes, e.g. 0.001 = 10 bps")
+	maxQuoteAge := flag.Duration("max-quote-age", time.Minute, "Quotes older than this are replaced, restoring filled liquidity (0 = never)")
+	keep := flag.Bool("keep", false, "Leave the quotes resting when a refreshing run exits")
+	flag.Parse()
+
+	opts := shapeOptions{Levels: *levels}
+	var err error
+	if opts.SizeScale, err = math.LegacyNewDecFromStr(*sizeScale); err != nil || !opts.SizeScale.IsPositive() {
+		log.Fatalf("Invalid -size-scale %q", *sizeScale)
+	}
+	if opts.MaxLevelSize, err = math.LegacyNewDecFromStr(*maxLevelSize); err != nil || opts.MaxLevelSize.IsNegative() {
+		log.Fatalf("Invalid -max-level-size %q", *maxLevelSize)
+	}
+	move, err := math.LegacyNewDecFromStr(*requoteMove)
+	if err != nil || move.IsNegative() {
+		log.Fatalf("Invalid -requote-move %q", *requoteMove)
+	}
+	coinMap, err := parseCoins(*coins)
+	if err != nil {
+		log.Fatalf("Invalid -coins: %v", err)
+	}
+	marketIDs := splitList(*markets)
+	if len(marketIDs) == 0 {
+		log.Fatal("No markets to seed")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var source referenceSource = newHyperliquidSource(*referenceURL, coinMap)
+	if *snapshotPath != "" {
+		snapshot, err := loadSnapshot(*snapshotPath)
+		if err != nil {
+			log.Fatalf("Failed to load snapshot: %v", err)
+		}
+		source = &snapshotSource{snapshot: snapshot}
+	}
+	if *savePath != "" {
+		snapshot := &Snapshot{Source: *referenceURL, Time: time.Now().UnixMilli(), Books: make(map[string]*Book)}
+		if *snapshotPath != "" {
+			snapshot.Source = *snapshotPath
+		}
+		for _, marketID := range marketIDs {
+			book, err := source.Book(ctx, marketID)
+			if err != nil {
+				log.Fatalf("Failed to read the %s reference book: %v", marketID, err)
+			}
+			snapshot.Books[marketID] = book
+		}
+		if err := saveSnapshot(*savePath, snapshot); err != nil {
+			log.Fatalf("Failed to save snapshot: %v", err)
+		}
+		log.Printf("Saved %d reference books to %s", len(snapshot.Books), *savePath)
+	}
+
+	client := newAPIClient(*apiURL, *trader, *apiKey)
+	if *deposit != "0" && *deposit != "" {
+		// Standalone nodes take orders without deposits
+		if err := client.deposit(ctx, *deposit); err != nil {
+			log.Printf("Failed to deposit margin, quoting without: %v", err)
+		}
+	}
+
+	s := newSeeder(client, source, opts, move, *maxQuoteAge)
+	for _, marketID := range marketIDs {
+		if err := s.requote(ctx, marketID); err != nil {
+			log.Fatalf("Failed to seed %s: %v", marketID, err)
+		}
+	}
+	if *refresh <= 0 {
+		return
+	}
+
+	log.Printf("Refreshing quotes every %s", *refresh)
+	ticker := time.NewTicker(*refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if !*keep {
+				// The run's context is done; give the cancels their own
+				cleanup, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				s.cancelAll(cleanup)
+				cancel()
+			}
+			return
+		case <-ticker.C:
+			for _, marketID := range marketIDs {
+				if err := s.refresh(ctx, marketID); err != nil {
+					log.Printf("Failed to refresh %s: %v", marketID, err)
+				}
+			}
+		}
+	}
+}
+
+// seeder keeps each market's quotes and the mid they were shaped around
+type seeder struct {
+	client      *apiClient
+	source      referenceSource
+	opts        shapeOptions
+	requoteMove math.LegacyDec
+	maxQuoteAge time.Duration
+	markets     map[string]*marketQuotes
+}
+
+type marketQuotes struct {
+	rules    marketRules
+	mid      math.LegacyDec
+	orderIDs []string
+	quotedAt time.Time
+}
+
+func newSeeder(client *apiClient, source referenceSource, opts shapeOptions, requoteMove math.LegacyDec, maxQuoteAge time.Duration) *seeder {
+	return &seeder{
+		client:      client,
+		source:      source,
+		opts:        opts,
+		requoteMove: requoteMove,
+		maxQuoteAge: maxQuoteAge,
+		markets:     make(map[string]*marketQuotes),
+	}
+}
+
+// mid returns the oracle mid of a market, or the reference book's own mid
+// on a node without prices
+func (s *seeder) mid(ctx context.Context, marketID string, book *Book) (math.LegacyDec, error) {
+	mid, err := s.client.oracleMid(ctx, marketID)
+	if err != nil || mid.IsPositive() {
+		return mid, err
+	}
+	return bookMid(book)
+}
+
+// requote replaces a market's quotes with a fresh shape of its reference
+// book around the current mid. The old quotes are cancelled first, so the
+// new ones never trade against them.
+func (s *seeder) requote(ctx context.Context, marketID string) error {
+	m, ok := s.markets[marketID]
+	if !ok {
+		rules, err := s.client.rules(ctx, marketID)
+		if err != nil {
+			return err
+		}
+		m = &marketQuotes{rules: rules}
+		s.markets[marketID] = m
+	}
+
+	book, err := s.source.Book(ctx, marketID)
+	if err != nil {
+		return err
+	}
+	mid, err := s.mid(ctx, marketID, book)
+	if err != nil {
+		return err
+	}
+	quotes, err := shape(book, mid, m.rules, s.opts)
+	if err != nil {
+		return err
+	}
+
+	s.cancel(ctx, marketID, m)
+	for _, q := range quotes {
+		orderID, err := s.client.place(ctx, marketID, q)
+		if err != nil {
+			log.Printf("Failed to quote %s %s %s @ %s: %v", marketID, q.Side, q.Quantity, q.Price, err)
+			continue
+		}
+		m.orderIDs = append(m.orderIDs, orderID)
+	}
+	m.mid = mid
+	m.quotedAt = time.Now()
+	log.Printf("Quoted %s: %d orders around %s", marketID, len(m.orderIDs), mid)
+	return nil
+}
+
+// refresh requotes a market once its oracle mid has moved by the requote
+// threshold or its quotes have reached the maximum age
+func (s *seeder) refresh(ctx context.Context, marketID string) error {
+	m, ok := s.markets[marketID]
+	if !ok || !m.mid.IsPositive() {
+		return s.requote(ctx, marketID)
+	}
+	if s.maxQuoteAge > 0 && time.Since(m.quotedAt) >= s.maxQuoteAge {
+		return s.requote(ctx, marketID)
+	}
+	mid, err := s.client.oracleMid(ctx, marketID)
+	if err != nil {
+		return err
+	}
+	if mid.IsPositive() && mid.Sub(m.mid).Abs().Quo(m.mid).GTE(s.requoteMove) {
+		return s.requote(ctx, marketID)
+	}
+	return nil
+}
+
+// cancel cancels a market's quotes; ones already filled or gone are dropped
+func (s *seeder) cancel(ctx context.Context, marketID string, m *marketQuotes) {
+	for _, orderID := range m.orderIDs {
+		if err := s.client.cancel(ctx, orderID); err != nil {
+			log.Printf("Failed to cancel %s order %s: %v", marketID, orderID, err)
+		}
+	}
+	m.orderIDs = nil
+}
+
+// cancelAll cancels the quotes of every market
+func (s *seeder) cancelAll(ctx context.Context) {
+	for marketID, m := range s.markets {
+		s.cancel(ctx, marketID, m)
+	}
+	log.Printf("Cancelled the quotes of %d markets", len(s.markets))
+}
+
+// parseCoins parses "MARKET=COIN,..." pairs
+func parseCoins(spec string) (map[string]string, error) {
+	coins := make(map[string]string)
+	for _, pair := range splitList(spec) {
+		marketID, coin, ok := strings.Cut(pair, "=")
+		if !ok || marketID == "" || coin == "" {
+			return nil, fmt.Errorf("expected MARKET=COIN, got %q", pair)
+		}
+		coins[strings.TrimSpace(marketID)] = strings.TrimSpace(coin)
+	}
+	return coins, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
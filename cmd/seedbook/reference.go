@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultReferenceURL is Hyperliquid's info endpoint, whose l2Book request
+// returns up to 20 aggregated levels per side
+const DefaultReferenceURL = "https://api.hyperliquid.xyz/info"
+
+// Level is one price level of a reference book
+type Level struct {
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// Book is a reference L2 book, best level first on each side
+type Book struct {
+	Bids []Level `json:"bids"`
+	Asks []Level `json:"asks"`
+}
+
+// Snapshot is a set of reference books saved with -save and replayed with
+// -snapshot, so a book can be seeded offline
+type Snapshot struct {
+	Source string           `json:"source"`
+	Time   int64            `json:"time"`  // Unix ms
+	Books  map[string]*Book `json:"books"` // by our market ID
+}
+
+// referenceSource returns the reference book of a market
+type referenceSource interface {
+	Book(ctx context.Context, marketID string) (*Book, error)
+}
+
+// hyperliquidSource reads live books from Hyperliquid
+type hyperliquidSource struct {
+	url    string
+	coins  map[string]string // market ID -> coin
+	client *http.Client
+}
+
+func newHyperliquidSource(url string, coins map[string]string) *hyperliquidSource {
+	return &hyperliquidSource{url: url, coins: coins, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// coin returns the Hyperliquid coin of a market: the configured one, or
+// the market's base asset
+func (s *hyperliquidSource) coin(marketID string) string {
+	if coin, ok := s.coins[marketID]; ok {
+		return coin
+	}
+	base, _, _ := strings.Cut(marketID, "-")
+	return base
+}
+
+func (s *hyperliquidSource) Book(ctx context.Context, marketID string) (*Book, error) {
+	coin := s.coin(marketID)
+	body, _ := json.Marshal(map[string]string{"type": "l2Book", "coin": coin})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, strings.NewReader(string(body)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("l2Book %s: %s", coin, resp.Status)
+	}
+
+	var result struct {
+		Levels [][]struct {
+			Px string `json:"px"`
+			Sz string `json:"sz"`
+		} `json:"levels"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("l2Book %s: %w", coin, err)
+	}
+	if len(result.Levels) != 2 {
+		return nil, fmt.Errorf("l2Book %s: expected bids and asks, got %d sides", coin, len(result.Levels))
+	}
+	book := &Book{}
+	for _, level := range result.Levels[0] {
+		book.Bids = append(book.Bids, Level{Price: level.Px, Quantity: level.Sz})
+	}
+	for _, level := range result.Levels[1] {
+		book.Asks = append(book.Asks, Level{Price: level.Px, Quantity: level.Sz})
+	}
+	return book, nil
+}
+
+// snapshotSource replays saved books
+type snapshotSource struct {
+	snapshot *Snapshot
+}
+
+func (s *snapshotSource) Book(_ context.Context, marketID string) (*Book, error) {
+	book, ok := s.snapshot.Books[marketID]
+	if !ok {
+		return nil, fmt.Errorf("no %s book in the snapshot", marketID)
+	}
+	return book, nil
+}
+
+func loadSnapshot(path string) (*Snapshot, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(bz, &snapshot); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &snapshot, nil
+}
+
+func saveSnapshot(path string, snapshot *Snapshot) error {
+	bz, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bz, 0o644)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+
+	"cosmossdk.io/math"
+)
+
+// marketRules are the order rules quotes are rounded to
+type marketRules struct {
+	TickSize     math.LegacyDec
+	LotSize      math.LegacyDec
+	MinOrderSize math.LegacyDec
+}
+
+// shapeOptions control how a reference book is mapped onto a market
+type shapeOptions struct {
+	Levels       int            // most levels quoted per side
+	SizeScale    math.LegacyDec // multiplier of reference sizes
+	MaxLevelSize math.LegacyDec // cap on a level's size, zero for none
+}
+
+// quote is one resting order to place
+type quote struct {
+	Side     string
+	Price    math.LegacyDec
+	Quantity math.LegacyDec
+}
+
+// parseSide parses a side of a reference book, best level first
+func parseSide(levels []Level) ([][2]math.LegacyDec, error) {
+	parsed := make([][2]math.LegacyDec, 0, len(levels))
+	for _, level := range levels {
+		price, err := math.LegacyNewDecFromStr(level.Price)
+		if err != nil || !price.IsPositive() {
+			return nil, fmt.Errorf("invalid price %q", level.Price)
+		}
+		quantity, err := math.LegacyNewDecFromStr(level.Quantity)
+		if err != nil || quantity.IsNegative() {
+			return nil, fmt.Errorf("invalid quantity %q", level.Quantity)
+		}
+		parsed = append(parsed, [2]math.LegacyDec{price, quantity})
+	}
+	return parsed, nil
+}
+
+// bookMid returns the midpoint of a book's best bid and ask
+func bookMid(book *Book) (math.LegacyDec, error) {
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return math.LegacyZeroDec(), fmt.Errorf("reference book has an empty side")
+	}
+	top, err := parseSide([]Level{book.Bids[0], book.Asks[0]})
+	if err != nil {
+		return math.LegacyZeroDec(), err
+	}
+	return top[0][0].Add(top[1][0]).QuoInt64(2), nil
+}
+
+// shape maps a reference book onto a market around mid. Each reference
+// level keeps its distance from the reference mid in relative terms and its
+// size, scaled; prices are rounded away from mid to the tick, sizes down to
+// the lot, and levels that round to the same price are merged. Quotes never
+// reach mid, so the seeded book is never crossed.
+func shape(book *Book, mid math.LegacyDec, rules marketRules, opts shapeOptions) ([]quote, error) {
+	bids, err := parseSide(book.Bids)
+	if err != nil {
+		return nil, fmt.Errorf("bids: %w", err)
+	}
+	asks, err := parseSide(book.Asks)
+	if err != nil {
+		return nil, fmt.Errorf("asks: %w", err)
+	}
+	if len(bids) == 0 || len(asks) == 0 {
+		return nil, fmt.Errorf("reference book has an empty side")
+	}
+	if !bids[0][0].LT(asks[0][0]) {
+		return nil, fmt.Errorf("reference book is crossed")
+	}
+	if !mid.IsPositive() {
+		return nil, fmt.Errorf("mid must be positive")
+	}
+	if rules.TickSize.IsNil() || !rules.TickSize.IsPositive() {
+		return nil, fmt.Errorf("tick size must be positive")
+	}
+	refMid := bids[0][0].Add(asks[0][0]).QuoInt64(2)
+
+	// The best prices each side can take without reaching mid
+	bestBid := floorTo(mid, rules.TickSize)
+	if bestBid.GTE(mid) {
+		bestBid = bestBid.Sub(rules.TickSize)
+	}
+	bestAsk := ceilTo(mid, rules.TickSize)
+	if bestAsk.LTE(mid) {
+		bestAsk = bestAsk.Add(rules.TickSize)
+	}
+
+	quotes := shapeSide("buy", bids, func(ratio math.LegacyDec) math.LegacyDec {
+		return math.LegacyMinDec(floorTo(mid.Mul(ratio), rules.TickSize), bestBid)
+	}, refMid, rules, opts)
+	quotes = append(quotes, shapeSide("sell", asks, func(ratio math.LegacyDec) math.LegacyDec {
+		return math.LegacyMaxDec(ceilTo(mid.Mul(ratio), rules.TickSize), bestAsk)
+	}, refMid, rules, opts)...)
+	return quotes, nil
+}
+
+func shapeSide(side string, levels [][2]math.LegacyDec, price func(ratio math.LegacyDec) math.LegacyDec, refMid math.LegacyDec, rules marketRules, opts shapeOptions) []quote {
+	var quotes []quote
+	for i, level := range levels {
+		if opts.Levels > 0 && i >= opts.Levels {
+			break
+		}
+		p := price(level[0].Quo(refMid))
+		if !p.IsPositive() {
+			break
+		}
+		quantity := level[1]
+		if !opts.SizeScale.IsNil() {
+			quantity = quantity.Mul(opts.SizeScale)
+		}
+		if n := len(quotes); n > 0 && quotes[n-1].Price.Equal(p) {
+			quotes[n-1].Quantity = quotes[n-1].Quantity.Add(quantity)
+			continue
+		}
+		quotes = append(quotes, quote{Side: side, Price: p, Quantity: quantity})
+	}
+
+	kept := quotes[:0]
+	for _, q := range quotes {
+		if !opts.MaxLevelSize.IsNil() && opts.MaxLevelSize.IsPositive() {
+			q.Quantity = math.LegacyMinDec(q.Quantity, opts.MaxLevelSize)
+		}
+		q.Quantity = floorTo(q.Quantity, rules.LotSize)
+		if q.Quantity.IsPositive() && (rules.MinOrderSize.IsNil() || q.Quantity.GTE(rules.MinOrderSize)) {
+			kept = append(kept, q)
+		}
+	}
+	return kept
+}
+
+// floorTo rounds x down to a multiple of step; a missing step leaves x as is
+func floorTo(x, step math.LegacyDec) math.LegacyDec {
+	if step.IsNil() || !step.IsPositive() {
+		return x
+	}
+	return x.Quo(step).TruncateDec().Mul(step)
+}
+
+// ceilTo rounds x up to a multiple of step; a missing step leaves x as is
+func ceilTo(x, step math.LegacyDec) math.LegacyDec {
+	if step.IsNil() || !step.IsPositive() {
+		return x
+	}
+	return x.Quo(step).Ceil().Mul(step)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+)
+
+func dec(s string) math.LegacyDec {
+	return math.LegacyMustNewDecFromStr(s)
+}
+
+// TestShape tests that reference levels keep their relative distance from
+// mid and their scaled size, rounded to the market's rules, and that the
+// shaped book is never crossed
+func TestShape(t *testing.T) {
+	// Reference mid 100; the first two bids round to the same tick
+	book := &Book{
+		Bids: []Level{{"99.9011", "2"}, {"99.9001", "1"}, {"99", "0.004"}, {"98", "5"}},
+		Asks: []Level{{"100.0989", "3"}, {"101", "1"}, {"102", "50"}},
+	}
+	rules := marketRules{TickSize: dec("1"), LotSize: dec("0.01"), MinOrderSize: dec("0.01")}
+	opts := shapeOptions{Levels: 3, SizeScale: dec("0.5"), MaxLevelSize: dec("10")}
+
+	quotes, err := shape(book, dec("50000"), rules, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ side, price, quantity string }{
+		{"buy", "49950", "1.5"}, // first two levels merged at the same tick
+		{"sell", "50050", "1.5"},
+		{"sell", "50500", "0.5"},
+		{"sell", "51000", "10"}, // capped
+	}
+	if len(quotes) != len(want) {
+		t.Fatalf("expected %d quotes, got %+v", len(want), quotes)
+	}
+	for i, w := range want {
+		if q := quotes[i]; q.Side != w.side || !q.Price.Equal(dec(w.price)) || !q.Quantity.Equal(dec(w.quantity)) {
+			t.Errorf("quote %d: expected %s %s @ %s, got %s %s @ %s", i, w.side, w.quantity, w.price, q.Side, q.Quantity, q.Price)
+		}
+	}
+
+	// A tick wider than the reference spread still leaves mid untouched
+	quotes, err = shape(book, dec("100.5"), marketRules{TickSize: dec("1")}, shapeOptions{Levels: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 2 || !quotes[0].Price.Equal(dec("100")) || !quotes[1].Price.Equal(dec("101")) {
+		t.Errorf("expected a 100/101 book around 100.5, got %+v", quotes)
+	}
+	quotes, err = shape(book, dec("100"), marketRules{TickSize: dec("1")}, shapeOptions{Levels: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 2 || !quotes[0].Price.Equal(dec("99")) || !quotes[1].Price.Equal(dec("101")) {
+		t.Errorf("expected a 99/101 book around 100, got %+v", quotes)
+	}
+
+	crossed := &Book{Bids: []Level{{"101", "1"}}, Asks: []Level{{"100", "1"}}}
+	if _, err := shape(crossed, dec("100"), rules, opts); err == nil {
+		t.Error("expected a crossed reference book to be rejected")
+	}
+}
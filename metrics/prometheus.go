@@ -25,6 +25,9 @@ type Collector struct {
 	OrdersActive         *prometheus.GaugeVec
 	OrderFillRate        *prometheus.HistogramVec
 	OrderLatency         *prometheus.HistogramVec
+	OrderStageLatency    *prometheus.HistogramVec
+	OrderLimitRejections *prometheus.CounterVec
+	OrderRejections      *prometheus.CounterVec
 
 	// Matching engine metrics
 	MatchingLatency      *prometheus.HistogramVec
@@ -143,6 +146,37 @@ func newCollector() *Collector {
 		[]string{"market_id", "type"},
 	)
 
+	c.OrderStageLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "perpdex",
+			Subsystem: "orders",
+			Name:      "stage_latency_ms",
+			Help:      "Order placement latency per stage in milliseconds",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 25, 50, 100},
+		},
+		[]string{"stage"},
+	)
+
+	c.OrderLimitRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "perpdex",
+			Subsystem: "orders",
+			Name:      "limit_rejections_total",
+			Help:      "Orders rejected at a resting order limit",
+		},
+		[]string{"market_id", "scope"},
+	)
+
+	c.OrderRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "perpdex",
+			Subsystem: "orders",
+			Name:      "rejections_total",
+			Help:      "Order placements and amendments rejected, by reason and error code",
+		},
+		[]string{"market_id", "action", "reason", "code"},
+	)
+
 	// Matching engine metrics
 	c.MatchingLatency = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -559,6 +593,9 @@ func (c *Collector) registerAll() {
 	prometheus.MustRegister(c.OrdersActive)
 	prometheus.MustRegister(c.OrderFillRate)
 	prometheus.MustRegister(c.OrderLatency)
+	prometheus.MustRegister(c.OrderStageLatency)
+	prometheus.MustRegister(c.OrderLimitRejections)
+	prometheus.MustRegister(c.OrderRejections)
 
 	// Matching engine metrics
 	prometheus.MustRegister(c.MatchingLatency)
@@ -634,6 +671,23 @@ func (c *Collector) RecordOrderLatency(marketID, orderType string, latencyMs flo
 	c.OrderLatency.WithLabelValues(marketID, orderType).Observe(latencyMs)
 }
 
+// RecordOrderStageLatency records the time an order spent in one stage of
+// placement: decode, validate, margin, match, persist, encode or total
+func (c *Collector) RecordOrderStageLatency(stage string, latencyMs float64) {
+	c.OrderStageLatency.WithLabelValues(stage).Observe(latencyMs)
+}
+
+// RecordOrderLimitRejection records an order rejected at the market or
+// trader resting order limit
+func (c *Collector) RecordOrderLimitRejection(marketID, scope string) {
+	c.OrderLimitRejections.WithLabelValues(marketID, scope).Inc()
+}
+
+// RecordOrderRejection records a rejected order placement or amendment
+func (c *Collector) RecordOrderRejection(marketID, action, reason, code string) {
+	c.OrderRejections.WithLabelValues(marketID, action, reason, code).Inc()
+}
+
 // RecordTrade records a trade event
 func (c *Collector) RecordTrade(marketID string, volume, value float64) {
 	c.TradesTotal.WithLabelValues(marketID).Inc()
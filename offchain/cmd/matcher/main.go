@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -12,6 +13,8 @@ import (
 	"time"
 
 	"cosmossdk.io/math"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/openalpha/perp-dex/api/chaintx"
 	"github.com/openalpha/perp-dex/offchain/matcher"
 	"github.com/openalpha/perp-dex/x/orderbook/types"
 )
@@ -24,6 +27,26 @@ type Config struct {
 	ChainRPCURL   string        `json:"chain_rpc_url"`
 	SubmitterType string        `json:"submitter_type"` // "mock" or "batch"
 	Demo          bool          `json:"demo"`           // run demo mode
+
+	MerkleSettlement bool   `json:"merkle_settlement"` // commit a Merkle root per batch
+	Operator         string `json:"operator"`          // settlement operator address
+	ChainGRPCAddr    string `json:"chain_grpc_addr"`   // node gRPC address batch commitments are broadcast to
+	ChainID          string `json:"chain_id"`
+
+	// Primary/standby failover; a standby follows ReplicationPrimary and
+	// takes over after FailoverTimeout without hearing from it
+	Role               string        `json:"role"` // "primary" or "standby"
+	Epoch              uint64        `json:"epoch"`
+	ReplicationListen  string        `json:"replication_listen"`
+	ReplicationPrimary string        `json:"replication_primary"`
+	ReplicationToken   string        `json:"replication_token"`
+	HeartbeatInterval  time.Duration `json:"heartbeat_interval"`
+	FailoverTimeout    time.Duration `json:"failover_timeout"`
+	FenceFile          string        `json:"fence_file"` // epoch fence shared by both instances
+
+	// Scenario turns demo mode into a synthetic soak run with self-checks
+	Scenario       bool                    `json:"scenario"`
+	ScenarioConfig *matcher.ScenarioConfig `json:"scenario_config"`
 }
 
 // DefaultConfig returns the default configuration
@@ -33,8 +56,12 @@ func DefaultConfig() *Config {
 		BatchInterval: 500 * time.Millisecond,
 		WebSocketURL:  "ws://localhost:26657/websocket",
 		ChainRPCURL:   "http://localhost:26657",
+		ChainGRPCAddr: "localhost:9090",
+		ChainID:       "perpdex-1",
 		SubmitterType: "mock",
 		Demo:          false,
+
+		ScenarioConfig: matcher.DefaultScenarioConfig(),
 	}
 }
 
@@ -70,8 +97,33 @@ func main() {
 	wsURL := flag.String("ws", "", "WebSocket URL")
 	submitterType := flag.String("submitter", "", "Submitter type (mock or batch)")
 	demo := flag.Bool("demo", false, "Run demo mode with sample orders")
+	merkleSettlement := flag.Bool("merkle-settlement", false, "Commit a Merkle root per batch instead of every trade")
+	operator := flag.String("operator", "", "Settlement operator address for batch commitments")
+	chainGRPC := flag.String("chain-grpc", "", "Chain node gRPC address batch commitments are broadcast to")
+	chainID := flag.String("chain-id", "", "Chain ID batch commitments are signed for")
+	role := flag.String("role", "", "Replication role (primary or standby)")
+	epoch := flag.Uint64("epoch", 0, "Fencing epoch a primary starts with")
+	replicationListen := flag.String("replication-listen", "", "Address to serve the replication stream to standbys on")
+	replicationPrimary := flag.String("replication-primary", "", "Primary's replication address for a standby to follow")
+	replicationToken := flag.String("replication-token", "", "Shared secret between primary and standby")
+	heartbeatInterval := flag.Duration("heartbeat-interval", 0, "Heartbeat interval on an idle replication stream")
+	failoverTimeout := flag.Duration("failover-timeout", 0, "Primary silence after which a standby takes over")
+	fenceFile := flag.String("fence-file", "", "File on shared storage holding the fencing epoch")
+	scenario := flag.Bool("scenario", false, "Run demo mode as a synthetic soak scenario with periodic self-checks")
+	scenarioDuration := flag.Duration("scenario-duration", 0, "Scenario run time (0 runs until stopped)")
+	scenarioRate := flag.Float64("scenario-rate", 0, "Scenario mean arrivals per second")
+	scenarioVolatility := flag.Float64("scenario-volatility", 0, "Scenario mid price volatility per √second")
+	scenarioCancelRate := flag.Float64("scenario-cancel-rate", 0, "Share of scenario arrivals that cancel a resting order")
+	scenarioMarketRatio := flag.Float64("scenario-market-ratio", 0, "Share of scenario arrivals that are market orders")
+	scenarioCheckInterval := flag.Duration("scenario-check-interval", 0, "Time between scenario self-checks")
+	scenarioSeed := flag.Int64("scenario-seed", 0, "Scenario random seed (0 picks one from the clock)")
 	flag.Parse()
 
+	// Scenario flags override the config file only when given, since zero
+	// is a meaningful rate
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
 	// Load configuration
 	config, err := LoadConfig(*configPath)
 	if err != nil {
@@ -97,6 +149,83 @@ func main() {
 	if *demo {
 		config.Demo = true
 	}
+	if *merkleSettlement {
+		config.MerkleSettlement = true
+	}
+	if *operator != "" {
+		config.Operator = *operator
+	}
+	if *chainGRPC != "" {
+		config.ChainGRPCAddr = *chainGRPC
+	}
+	if *chainID != "" {
+		config.ChainID = *chainID
+	}
+	if *role != "" {
+		config.Role = *role
+	}
+	if *epoch > 0 {
+		config.Epoch = *epoch
+	}
+	if *replicationListen != "" {
+		config.ReplicationListen = *replicationListen
+	}
+	if *replicationPrimary != "" {
+		config.ReplicationPrimary = *replicationPrimary
+	}
+	if *replicationToken != "" {
+		config.ReplicationToken = *replicationToken
+	}
+	if *heartbeatInterval > 0 {
+		config.HeartbeatInterval = *heartbeatInterval
+	}
+	if *failoverTimeout > 0 {
+		config.FailoverTimeout = *failoverTimeout
+	}
+	if *fenceFile != "" {
+		config.FenceFile = *fenceFile
+	}
+	matcherRole, err := matcher.ParseRole(config.Role)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	if *scenario {
+		config.Demo = true
+		config.Scenario = true
+	}
+	if config.ScenarioConfig == nil {
+		config.ScenarioConfig = matcher.DefaultScenarioConfig()
+	}
+	if setFlags["scenario-duration"] {
+		config.ScenarioConfig.Duration = *scenarioDuration
+	}
+	if setFlags["scenario-rate"] {
+		config.ScenarioConfig.ArrivalRate = *scenarioRate
+	}
+	if setFlags["scenario-volatility"] {
+		config.ScenarioConfig.Volatility = *scenarioVolatility
+	}
+	if setFlags["scenario-cancel-rate"] {
+		config.ScenarioConfig.CancelRate = *scenarioCancelRate
+	}
+	if setFlags["scenario-market-ratio"] {
+		config.ScenarioConfig.MarketOrderRatio = *scenarioMarketRatio
+	}
+	if setFlags["scenario-check-interval"] {
+		config.ScenarioConfig.CheckInterval = *scenarioCheckInterval
+	}
+	if setFlags["scenario-seed"] {
+		config.ScenarioConfig.Seed = *scenarioSeed
+	}
+	if config.Scenario {
+		if err := config.ScenarioConfig.Validate(); err != nil {
+			log.Fatalf("Invalid scenario: %v", err)
+		}
+		// The mock submitter retains and logs every trade
+		if config.SubmitterType == "mock" {
+			config.SubmitterType = "discard"
+		}
+	}
 
 	// Print configuration
 	log.Println("=== PerpDEX Offchain Matcher ===")
@@ -105,15 +234,58 @@ func main() {
 	log.Printf("Chain RPC: %s", config.ChainRPCURL)
 	log.Printf("WebSocket: %s", config.WebSocketURL)
 	log.Printf("Submitter: %s", config.SubmitterType)
+	log.Printf("Merkle Settlement: %v", config.MerkleSettlement)
+	log.Printf("Role: %s", matcherRole)
+	if config.ReplicationPrimary != "" {
+		log.Printf("Replication Primary: %s", config.ReplicationPrimary)
+	}
+	if config.ReplicationListen != "" {
+		log.Printf("Replication Listen: %s", config.ReplicationListen)
+	}
+	if config.Scenario {
+		log.Printf("Scenario: %s for %v, check every %v", config.ScenarioConfig.MarketID,
+			config.ScenarioConfig.Duration, config.ScenarioConfig.CheckInterval)
+	}
 	log.Println("================================")
 
+	// Batch commitments are signed with the operator key from
+	// PERPDEX_OPERATOR_KEY and broadcast through the node's gRPC server
+	var broadcaster matcher.CommitBroadcaster
+	if config.MerkleSettlement && config.SubmitterType == "batch" {
+		operatorKey := os.Getenv("PERPDEX_OPERATOR_KEY")
+		key, err := hex.DecodeString(operatorKey)
+		if err != nil || len(key) != secp256k1.PrivKeySize {
+			log.Fatalf("Invalid PERPDEX_OPERATOR_KEY: expected a hex-encoded %d-byte secp256k1 private key", secp256k1.PrivKeySize)
+		}
+		chainConfig := chaintx.DefaultConfig()
+		chainConfig.GRPCAddr = config.ChainGRPCAddr
+		chainTx, err := chaintx.NewClient(chainConfig)
+		if err != nil {
+			log.Fatalf("Invalid chain gRPC address: %v", err)
+		}
+		defer chainTx.Close()
+
+		txService := chaintx.NewTxService(chainTx, chaintx.TxServiceConfig{
+			ChainID: config.ChainID,
+			HotKey:  &secp256k1.PrivKey{Key: key},
+		})
+		if config.Operator == "" {
+			config.Operator = txService.HotAddress()
+		} else if config.Operator != txService.HotAddress() {
+			log.Fatalf("PERPDEX_OPERATOR_KEY signs for %s, not the operator %s", txService.HotAddress(), config.Operator)
+		}
+		broadcaster = txService
+	}
+
 	// Create submitter
 	factory := matcher.NewSubmitterFactory()
 	submitter := factory.Create(config.SubmitterType, &matcher.BatchSubmitterConfig{
 		RPCURL:        config.ChainRPCURL,
+		Operator:      config.Operator,
 		BatchSize:     config.BatchSize,
 		RetryAttempts: 3,
 		RetryDelay:    time.Second,
+		Broadcaster:   broadcaster,
 	})
 
 	// Create matcher
@@ -122,8 +294,21 @@ func main() {
 		BatchInterval: config.BatchInterval,
 		WebSocketURL:  config.WebSocketURL,
 		ChainRPCURL:   config.ChainRPCURL,
+
+		MerkleSettlement: config.MerkleSettlement,
+
+		Role:               matcherRole,
+		Epoch:              config.Epoch,
+		ReplicationListen:  config.ReplicationListen,
+		ReplicationPrimary: config.ReplicationPrimary,
+		ReplicationToken:   config.ReplicationToken,
+		HeartbeatInterval:  config.HeartbeatInterval,
+		FailoverTimeout:    config.FailoverTimeout,
 	}
 	m := matcher.NewOffchainMatcher(matcherConfig, submitter)
+	if config.FenceFile != "" {
+		m.SetFence(matcher.NewFileFence(config.FenceFile))
+	}
 
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -134,11 +319,32 @@ func main() {
 		log.Fatalf("Failed to start matcher: %v", err)
 	}
 
-	// Run demo if requested
-	if config.Demo {
+	// Run demo if requested. A scenario is stopped before the matcher so
+	// its final self-check can still drain the event queue.
+	var scenarioDone chan *matcher.ScenarioReport
+	scenarioCtx, stopScenario := context.WithCancel(ctx)
+	defer stopScenario()
+	if config.Demo && config.Scenario {
+		scenarioDone = make(chan *matcher.ScenarioReport, 1)
+		go func() {
+			report, err := matcher.RunScenario(scenarioCtx, m, config.ScenarioConfig)
+			if err != nil {
+				log.Printf("Scenario failed to start: %v", err)
+			}
+			scenarioDone <- report
+		}()
+	} else if config.Demo {
 		go runDemo(m)
 	}
 
+	stop := func() {
+		cancel()
+		if err := m.Stop(); err != nil {
+			log.Printf("Error stopping matcher: %v", err)
+		}
+		log.Println("Matcher stopped")
+	}
+
 	// Wait for interrupt signal
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -153,20 +359,43 @@ func main() {
 		select {
 		case sig := <-sigCh:
 			log.Printf("Received signal: %v", sig)
-			cancel()
-			if err := m.Stop(); err != nil {
-				log.Printf("Error stopping matcher: %v", err)
+			if scenarioDone != nil {
+				stopScenario()
+				report := <-scenarioDone
+				stop()
+				exitScenario(report)
 			}
-			log.Println("Matcher stopped")
+			stop()
 			return
+		case report := <-scenarioDone:
+			stop()
+			exitScenario(report)
 		case <-statsTicker.C:
 			stats := m.GetStats()
-			log.Printf("Stats: Orders=%d, OrderBooks=%d, PendingTrades=%d, CacheSize=%d",
-				stats.OrderCount, stats.OrderBookCount, stats.PendingTrades, stats.CacheSize)
+			log.Printf("Stats: Role=%s, Epoch=%d, Orders=%d, OrderBooks=%d, Trades=%d, PendingTrades=%d, CacheSize=%d, Batches=%d, Standbys=%d",
+				stats.Role, stats.Epoch, stats.OrderCount, stats.OrderBookCount, stats.TotalTrades, stats.PendingTrades, stats.CacheSize, stats.CommittedBatches, stats.Standbys)
 		}
 	}
 }
 
+// exitScenario logs a scenario report and exits non-zero if any self-check
+// failed
+func exitScenario(report *matcher.ScenarioReport) {
+	if report == nil {
+		os.Exit(1)
+	}
+	log.Println("=== Scenario Report ===")
+	log.Printf("Seed: %d, Elapsed: %v", report.Seed, report.Elapsed.Round(time.Second))
+	log.Printf("Orders: limit=%d, market=%d, cancels=%d, trades=%d",
+		report.LimitOrders, report.MarketOrders, report.Cancels, report.Trades)
+	log.Printf("Self-checks: %d run, %d failed", report.Checks, report.FailedChecks)
+	if report.FailedChecks > 0 {
+		log.Printf("Last failure: %s", report.LastFailure)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
 // runDemo runs a demonstration with sample orders
 func runDemo(m *matcher.OffchainMatcher) {
 	log.Println("Starting demo mode...")
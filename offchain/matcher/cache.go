@@ -146,6 +146,34 @@ func (b *TradeBuffer) Flush() []*types.Trade {
 	return trades
 }
 
+// Take removes and returns the trades with the given IDs in that order;
+// IDs not in the buffer are skipped
+func (b *TradeBuffer) Take(tradeIDs []string) []*types.Trade {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	byID := make(map[string]*types.Trade, len(b.trades))
+	for _, trade := range b.trades {
+		byID[trade.TradeID] = trade
+	}
+	taken := make([]*types.Trade, 0, len(tradeIDs))
+	for _, id := range tradeIDs {
+		if trade, ok := byID[id]; ok {
+			taken = append(taken, trade)
+			delete(byID, id)
+		}
+	}
+
+	kept := make([]*types.Trade, 0, len(b.trades))
+	for _, trade := range b.trades {
+		if _, ok := byID[trade.TradeID]; ok {
+			kept = append(kept, trade)
+		}
+	}
+	b.trades = kept
+	return taken
+}
+
 // FlushBatch returns up to maxSize trades and removes them from the buffer
 func (b *TradeBuffer) FlushBatch() []*types.Trade {
 	b.mu.Lock()
@@ -0,0 +1,144 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrFenced is returned when a higher epoch has claimed the right to submit
+// batches, i.e. this instance has been failed over
+var ErrFenced = errors.New("matcher fenced by a newer epoch")
+
+// Fence grants the right to submit batches to the chain to the instance
+// holding the highest epoch. A standby claims the next epoch when it takes
+// over, so a primary that lost contact but is still running stops submitting
+// once it next checks the fence.
+type Fence interface {
+	// Acquire claims the fence for epoch; it fails with ErrFenced when a
+	// higher epoch has already been claimed. Claiming the current epoch
+	// again lets a primary restart.
+	Acquire(ctx context.Context, epoch uint64) error
+
+	// Validate fails with ErrFenced once an epoch higher than epoch has
+	// been claimed
+	Validate(ctx context.Context, epoch uint64) error
+}
+
+// MemoryFence is a Fence shared by instances in one process
+type MemoryFence struct {
+	mu    sync.Mutex
+	epoch uint64
+}
+
+// NewMemoryFence creates a new in-process fence
+func NewMemoryFence() *MemoryFence {
+	return &MemoryFence{}
+}
+
+// Acquire claims the fence for epoch
+func (f *MemoryFence) Acquire(ctx context.Context, epoch uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if epoch < f.epoch {
+		return fmt.Errorf("%w: epoch %d already claimed, got %d", ErrFenced, f.epoch, epoch)
+	}
+	f.epoch = epoch
+	return nil
+}
+
+// Validate checks that no higher epoch has been claimed
+func (f *MemoryFence) Validate(ctx context.Context, epoch uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.epoch > epoch {
+		return fmt.Errorf("%w: epoch %d superseded by %d", ErrFenced, epoch, f.epoch)
+	}
+	return nil
+}
+
+// FileFence is a Fence kept in a file on storage both instances can reach,
+// holding the highest claimed epoch. Claims are written to a temporary file
+// and renamed into place, so a reader never sees a partial epoch. The check
+// and the submission that follows it are not atomic; with Merkle settlement
+// the chain's batch sequence rejects a batch ID committed twice.
+type FileFence struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileFence creates a fence stored at path
+func NewFileFence(path string) *FileFence {
+	return &FileFence{path: path}
+}
+
+// read returns the highest claimed epoch, 0 when none has been claimed
+func (f *FileFence) read() (uint64, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read fence: %w", err)
+	}
+	epoch, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid fence %s: %w", f.path, err)
+	}
+	return epoch, nil
+}
+
+// Acquire claims the fence for epoch
+func (f *FileFence) Acquire(ctx context.Context, epoch uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current, err := f.read()
+	if err != nil {
+		return err
+	}
+	if epoch < current {
+		return fmt.Errorf("%w: epoch %d already claimed, got %d", ErrFenced, current, epoch)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to claim fence: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(strconv.FormatUint(epoch, 10) + "\n"); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to claim fence: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to claim fence: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to claim fence: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to claim fence: %w", err)
+	}
+	return nil
+}
+
+// Validate checks that no higher epoch has been claimed
+func (f *FileFence) Validate(ctx context.Context, epoch uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current, err := f.read()
+	if err != nil {
+		return err
+	}
+	if current > epoch {
+		return fmt.Errorf("%w: epoch %d superseded by %d", ErrFenced, epoch, current)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package matcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sort"
@@ -18,6 +19,20 @@ type Config struct {
 	BatchInterval time.Duration // Time interval for batch submission
 	WebSocketURL  string        // WebSocket URL for event listening
 	ChainRPCURL   string        // Chain RPC URL for submission
+
+	// MerkleSettlement commits a Merkle root per batch instead of every trade
+	MerkleSettlement bool
+	BatchHistorySize int // Committed batches retained for dispute proofs
+
+	// Primary/standby replication; see replication.go
+	Role               Role
+	Epoch              uint64        // Fencing epoch a primary starts with
+	ReplicationListen  string        // Address the primary serves standbys on; empty disables
+	ReplicationPrimary string        // Primary's replication address a standby follows
+	ReplicationToken   string        // Shared secret standbys present to the primary
+	HeartbeatInterval  time.Duration // Heartbeats on an idle replication stream
+	FailoverTimeout    time.Duration // Silence after which a standby takes over
+	AckTimeout         time.Duration // Longest wait for standbys to acknowledge a submission
 }
 
 // DefaultConfig returns the default matcher configuration
@@ -27,19 +42,44 @@ func DefaultConfig() *Config {
 		BatchInterval: 500 * time.Millisecond,
 		WebSocketURL:  "ws://localhost:26657/websocket",
 		ChainRPCURL:   "http://localhost:26657",
+
+		MerkleSettlement: false,
+		BatchHistorySize: 1000,
+
+		Role:              RolePrimary,
+		Epoch:             1,
+		HeartbeatInterval: 500 * time.Millisecond,
+		FailoverTimeout:   3 * time.Second,
+		AckTimeout:        time.Second,
 	}
 }
 
 // OffchainMatcher is the main offchain matching engine
 type OffchainMatcher struct {
-	config     *Config
-	cache      *OrderCache
+	config      *Config
+	cache       *OrderCache
 	tradeBuffer *TradeBuffer
-	submitter  TxSubmitter
+	submitter   TxSubmitter
+
+	// Merkle settlement state
+	history     *BatchHistory
+	nextBatchID uint64
+
+	// Replication state
+	role            Role
+	epoch           uint64
+	fence           Fence
+	replSeq         uint64 // sequence of the last replication entry
+	standbys        map[*replicationSubscriber]struct{}
+	inFlight        *pendingSubmission // submission whose outcome is not known yet
+	synced          bool               // standby: a snapshot has been applied
+	lastHeard       time.Time          // standby: last entry from the primary
+	replicationAddr string
 
 	// Internal state
 	orderBooks map[string]*types.OrderBook // marketID -> orderBook
 	orders     map[string]*types.Order     // orderID -> order
+	tradeCount uint64                      // trades matched since start
 	mu         sync.RWMutex
 
 	// Event channel for simulated WebSocket events
@@ -56,6 +96,9 @@ type Event struct {
 	Order     *types.Order
 	MarketID  string
 	Timestamp time.Time
+
+	// Done is closed once a sync event is handled
+	Done chan struct{}
 }
 
 // EventType represents the type of chain event
@@ -65,6 +108,7 @@ const (
 	EventTypeNewOrder EventType = iota
 	EventTypeCancelOrder
 	EventTypeMarketUpdate
+	EventTypeSync
 )
 
 func (e EventType) String() string {
@@ -75,6 +119,8 @@ func (e EventType) String() string {
 		return "cancel_order"
 	case EventTypeMarketUpdate:
 		return "market_update"
+	case EventTypeSync:
+		return "sync"
 	default:
 		return "unknown"
 	}
@@ -88,12 +134,31 @@ func NewOffchainMatcher(config *Config, submitter TxSubmitter) *OffchainMatcher
 	if submitter == nil {
 		submitter = NewMockSubmitter()
 	}
+	defaults := DefaultConfig()
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = defaults.HeartbeatInterval
+	}
+	if config.FailoverTimeout <= 0 {
+		config.FailoverTimeout = defaults.FailoverTimeout
+	}
+	if config.AckTimeout <= 0 {
+		config.AckTimeout = defaults.AckTimeout
+	}
+	if config.Epoch == 0 {
+		config.Epoch = defaults.Epoch
+	}
 
 	return &OffchainMatcher{
 		config:      config,
 		cache:       NewOrderCache(),
 		tradeBuffer: NewTradeBuffer(config.BatchSize),
 		submitter:   submitter,
+		history:     NewBatchHistory(config.BatchHistorySize),
+		nextBatchID: 1,
+		role:        config.Role,
+		epoch:       config.Epoch,
+		fence:       NewMemoryFence(),
+		standbys:    make(map[*replicationSubscriber]struct{}),
 		orderBooks:  make(map[string]*types.OrderBook),
 		orders:      make(map[string]*types.Order),
 		eventCh:     make(chan Event, 1000),
@@ -101,9 +166,33 @@ func NewOffchainMatcher(config *Config, submitter TxSubmitter) *OffchainMatcher
 	}
 }
 
-// Start starts the offchain matcher
+// SetFence sets the fence guarding batch submission; instances of a pair
+// must share it. Must be called before Start.
+func (m *OffchainMatcher) SetFence(fence Fence) {
+	m.fence = fence
+}
+
+// Start starts the offchain matcher. A primary claims the fence for its
+// epoch and serves standbys; a standby follows the primary until it takes
+// over.
 func (m *OffchainMatcher) Start(ctx context.Context) error {
-	log.Println("Starting offchain matcher...")
+	log.Printf("Starting offchain matcher as %s...", m.role)
+
+	switch m.role {
+	case RolePrimary:
+		if err := m.fence.Acquire(ctx, m.epoch); err != nil {
+			return fmt.Errorf("cannot start as primary: %w", err)
+		}
+		if err := m.listenReplication(ctx); err != nil {
+			return err
+		}
+	case RoleStandby:
+		if m.config.ReplicationPrimary == "" {
+			return fmt.Errorf("a standby needs the primary's replication address")
+		}
+		m.wg.Add(1)
+		go m.followPrimary(ctx)
+	}
 
 	// Start event listener
 	m.wg.Add(1)
@@ -166,21 +255,117 @@ func (m *OffchainMatcher) batchLoop(ctx context.Context) {
 	}
 }
 
-// submitPendingTrades submits pending trades to the chain
+// submitPendingTrades submits pending trades to the chain. Only a primary
+// still holding the fence submits; a submission left in flight by a failover
+// goes first, unchanged.
 func (m *OffchainMatcher) submitPendingTrades(ctx context.Context) {
+	m.mu.RLock()
+	role, epoch, recovered, nextBatchID := m.role, m.epoch, m.inFlight, m.nextBatchID
+	m.mu.RUnlock()
+	if role != RolePrimary {
+		return
+	}
+	if err := m.fence.Validate(ctx, epoch); err != nil {
+		m.demote(ctx, err)
+		return
+	}
+
+	if recovered != nil {
+		if !m.submit(ctx, recovered, true) {
+			return
+		}
+		m.mu.RLock()
+		nextBatchID = m.nextBatchID
+		m.mu.RUnlock()
+	}
+
 	trades := m.tradeBuffer.Flush()
 	if len(trades) == 0 {
 		return
 	}
+	submission := &pendingSubmission{Trades: trades}
+	if m.config.MerkleSettlement {
+		submission.BatchID = nextBatchID
+	}
+	m.submit(ctx, submission, false)
+}
+
+// submit hands a submission to the submitter, replicating it before and its
+// outcome after, so a standby taking over knows which trades may already be
+// on chain. Merkle batches are retained for proofs, and batch IDs only
+// advance on a successful commit so the chain sees no gaps. A recovered
+// batch the chain reports out of sequence was committed before the failover.
+func (m *OffchainMatcher) submit(ctx context.Context, submission *pendingSubmission, recovered bool) bool {
+	m.mu.Lock()
+	m.inFlight = submission
+	entry := &ReplicationEntry{Type: ReplicationSubmit, TradeIDs: submission.tradeIDs(), BatchID: submission.BatchID}
+	m.publish(entry)
+	m.mu.Unlock()
+	m.awaitStandbys(entry.Seq)
+
+	var batch *SettlementBatch
+	var err error
+	if m.config.MerkleSettlement {
+		batch = NewSettlementBatch(submission.BatchID, submission.Trades)
+		log.Printf("Committing batch %d (%d trades, root %s)...", batch.BatchID, len(batch.Trades), batch.RootHex())
+		err = m.submitter.CommitBatch(ctx, batch)
+		if err != nil && recovered && errors.Is(err, types.ErrInvalidBatchSequence) {
+			log.Printf("Batch %d was committed before the failover", batch.BatchID)
+			err = nil
+		}
+	} else {
+		log.Printf("Submitting %d trades to chain...", len(submission.Trades))
+		err = m.submitter.SubmitTrades(ctx, submission.Trades)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := &ReplicationEntry{Type: ReplicationSubmitResult, BatchID: submission.BatchID}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	m.publish(result)
 
-	log.Printf("Submitting %d trades to chain...", len(trades))
-	if err := m.submitter.SubmitTrades(ctx, trades); err != nil {
-		log.Printf("Error submitting trades: %v", err)
-		// Re-add trades to buffer for retry
-		for _, trade := range trades {
-			m.tradeBuffer.Add(trade)
+	if err != nil {
+		if batch != nil {
+			log.Printf("Error committing batch %d: %v", batch.BatchID, err)
+		} else {
+			log.Printf("Error submitting trades: %v", err)
 		}
+		// A recovered submission is retried as it was; others go back to
+		// the buffer for retry
+		if !recovered {
+			m.inFlight = nil
+			m.tradeBuffer.AddBatch(submission.Trades)
+		}
+		return false
+	}
+
+	m.inFlight = nil
+	if batch != nil {
+		m.history.Add(batch)
+		m.nextBatchID = batch.BatchID + 1
+	}
+	return true
+}
+
+// GetTradeProof returns the batch ID and inclusion proof for a committed trade
+func (m *OffchainMatcher) GetTradeProof(tradeID string) (uint64, *types.MerkleProof, error) {
+	batch := m.history.FindTrade(tradeID)
+	if batch == nil {
+		return 0, nil, fmt.Errorf("trade not found in committed batches: %s", tradeID)
+	}
+
+	proof, err := batch.Proof(tradeID)
+	if err != nil {
+		return 0, nil, err
 	}
+	return batch.BatchID, proof, nil
+}
+
+// GetBatch returns a committed settlement batch by ID
+func (m *OffchainMatcher) GetBatch(batchID uint64) *SettlementBatch {
+	return m.history.Get(batchID)
 }
 
 // handleEvent handles an incoming event
@@ -190,16 +375,39 @@ func (m *OffchainMatcher) handleEvent(event Event) error {
 		return m.handleNewOrder(event.Order)
 	case EventTypeCancelOrder:
 		return m.handleCancelOrder(event.Order.OrderID)
+	case EventTypeSync:
+		close(event.Done)
+		return nil
 	default:
 		return fmt.Errorf("unknown event type: %v", event.Type)
 	}
 }
 
-// handleNewOrder processes a new order
+// handleNewOrder processes a new order and replicates it with its trades
 func (m *OffchainMatcher) handleNewOrder(order *types.Order) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.role != RolePrimary {
+		return fmt.Errorf("%w: order %s not accepted", ErrStandby, order.OrderID)
+	}
+
+	intake := *order
+	trades := m.executeOrder(order)
+
+	// Add trades to buffer
+	for _, trade := range trades {
+		m.tradeBuffer.Add(trade)
+	}
+	m.tradeCount += uint64(len(trades))
+
+	m.publish(&ReplicationEntry{Type: ReplicationOrder, Order: &intake, Trades: trades})
+	return nil
+}
+
+// executeOrder matches an order and rests its remainder, returning its
+// trades. Must be called with m.mu held.
+func (m *OffchainMatcher) executeOrder(order *types.Order) []*types.Trade {
 	// Store order in cache
 	m.cache.Set(order)
 	m.orders[order.OrderID] = order
@@ -210,24 +418,36 @@ func (m *OffchainMatcher) handleNewOrder(order *types.Order) error {
 	// Match the order
 	trades, remainingQty := m.matchOrder(order, orderBook)
 
-	// Add trades to buffer
-	for _, trade := range trades {
-		m.tradeBuffer.Add(trade)
-	}
-
 	// If remaining quantity, add to order book (limit orders only)
 	if remainingQty.IsPositive() && order.OrderType == types.OrderTypeLimit {
 		orderBook.AddOrder(order)
+	} else {
+		// Filled takers and market order remainders never rest
+		m.cache.Delete(order.OrderID)
+		delete(m.orders, order.OrderID)
 	}
 
-	return nil
+	return trades
 }
 
-// handleCancelOrder cancels an order
+// handleCancelOrder cancels an order and replicates the cancel
 func (m *OffchainMatcher) handleCancelOrder(orderID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.role != RolePrimary {
+		return fmt.Errorf("%w: cancel of %s not accepted", ErrStandby, orderID)
+	}
+	if err := m.cancelOrder(orderID); err != nil {
+		return err
+	}
+	m.publish(&ReplicationEntry{Type: ReplicationCancel, OrderID: orderID})
+	return nil
+}
+
+// cancelOrder removes an active order from its book. Must be called with
+// m.mu held.
+func (m *OffchainMatcher) cancelOrder(orderID string) error {
 	order, exists := m.orders[orderID]
 	if !exists {
 		return fmt.Errorf("order not found: %s", orderID)
@@ -316,8 +536,8 @@ func (m *OffchainMatcher) matchOrder(order *types.Order, orderBook *types.OrderB
 			matchPrice := level.Price // Maker's price
 
 			// Calculate fees (using default rates for now)
-			takerFee := m.calculateFee(matchQty, matchPrice, math.LegacyNewDecWithPrec(5, 4))  // 0.05%
-			makerFee := m.calculateFee(matchQty, matchPrice, math.LegacyNewDecWithPrec(2, 4))  // 0.02%
+			takerFee := m.calculateFee(matchQty, matchPrice, math.LegacyNewDecWithPrec(5, 4)) // 0.05%
+			makerFee := m.calculateFee(matchQty, matchPrice, math.LegacyNewDecWithPrec(2, 4)) // 0.02%
 
 			// Create trade
 			tradeID := m.generateTradeID()
@@ -418,20 +638,34 @@ func (m *OffchainMatcher) SubmitOrder(order *types.Order) {
 	}
 }
 
-// CancelOrder cancels an order in the matcher
+// CancelOrder cancels an order in the matcher. The order is looked up when
+// the cancel is handled, so an order whose new-order event is still queued
+// is cancelled too
 func (m *OffchainMatcher) CancelOrder(orderID string) {
-	order, exists := m.orders[orderID]
-	if !exists {
-		return
-	}
 	m.eventCh <- Event{
 		Type:      EventTypeCancelOrder,
-		Order:     order,
-		MarketID:  order.MarketID,
+		Order:     &types.Order{OrderID: orderID},
 		Timestamp: time.Now(),
 	}
 }
 
+// Sync blocks until every event submitted before it has been handled
+func (m *OffchainMatcher) Sync(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case m.eventCh <- Event{Type: EventTypeSync, Timestamp: time.Now(), Done: done}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // GetOrderBook returns a copy of the order book for a market
 func (m *OffchainMatcher) GetOrderBook(marketID string) *types.OrderBook {
 	m.mu.RLock()
@@ -472,10 +706,17 @@ func (m *OffchainMatcher) GetOrder(orderID string) *types.Order {
 
 // Stats returns matcher statistics
 type Stats struct {
-	OrderCount      int
-	OrderBookCount  int
-	PendingTrades   int
-	CacheSize       int
+	OrderCount       int
+	OrderBookCount   int
+	PendingTrades    int
+	CacheSize        int
+	CommittedBatches int
+	TotalTrades      uint64
+
+	Role           Role
+	Epoch          uint64
+	ReplicationSeq uint64 // last replication entry published or applied
+	Standbys       int    // connected standbys
 }
 
 // GetStats returns current matcher statistics
@@ -484,9 +725,15 @@ func (m *OffchainMatcher) GetStats() Stats {
 	defer m.mu.RUnlock()
 
 	return Stats{
-		OrderCount:     len(m.orders),
-		OrderBookCount: len(m.orderBooks),
-		PendingTrades:  m.tradeBuffer.Len(),
-		CacheSize:      m.cache.Len(),
+		OrderCount:       len(m.orders),
+		OrderBookCount:   len(m.orderBooks),
+		PendingTrades:    m.tradeBuffer.Len(),
+		CacheSize:        m.cache.Len(),
+		CommittedBatches: m.history.Len(),
+		TotalTrades:      m.tradeCount,
+		Role:             m.role,
+		Epoch:            m.epoch,
+		ReplicationSeq:   m.replSeq,
+		Standbys:         len(m.standbys),
 	}
 }
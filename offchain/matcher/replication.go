@@ -0,0 +1,623 @@
+package matcher
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Role is the part an instance plays in a primary/standby pair
+type Role int
+
+const (
+	// RolePrimary takes orders, matches and submits batches to the chain
+	RolePrimary Role = iota
+	// RoleStandby replays the primary's replication stream and takes over
+	// when the primary stops answering
+	RoleStandby
+)
+
+func (r Role) String() string {
+	switch r {
+	case RolePrimary:
+		return "primary"
+	case RoleStandby:
+		return "standby"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseRole parses "primary" or "standby"
+func ParseRole(s string) (Role, error) {
+	switch strings.ToLower(s) {
+	case "primary", "":
+		return RolePrimary, nil
+	case "standby":
+		return RoleStandby, nil
+	default:
+		return 0, fmt.Errorf("unknown matcher role %q", s)
+	}
+}
+
+// ErrStandby is returned for orders sent to a standby instead of the primary
+var ErrStandby = errors.New("matcher is a standby")
+
+// ReplicationEntryType is the kind of a replication stream entry
+type ReplicationEntryType string
+
+const (
+	// ReplicationSnapshot carries the primary's full state; it is the first
+	// entry on every connection
+	ReplicationSnapshot ReplicationEntryType = "snapshot"
+	// ReplicationOrder is an order as it arrived and the trades it matched
+	ReplicationOrder ReplicationEntryType = "order"
+	// ReplicationCancel is a cancelled order
+	ReplicationCancel ReplicationEntryType = "cancel"
+	// ReplicationSubmit is trades handed to the submitter; the primary waits
+	// for standbys to acknowledge it before submitting
+	ReplicationSubmit ReplicationEntryType = "submit"
+	// ReplicationSubmitResult is the outcome of the last submission
+	ReplicationSubmitResult ReplicationEntryType = "submit_result"
+	// ReplicationHeartbeat is sent while the stream is idle
+	ReplicationHeartbeat ReplicationEntryType = "heartbeat"
+)
+
+// ReplicationEntry is one step of the primary's state sent to its standbys.
+// Every entry but a heartbeat advances Seq by one.
+type ReplicationEntry struct {
+	Type  ReplicationEntryType `json:"type"`
+	Seq   uint64               `json:"seq"`
+	Epoch uint64               `json:"epoch"`
+
+	Order    *types.Order          `json:"order,omitempty"`    // order: the order before matching
+	OrderID  string                `json:"order_id,omitempty"` // cancel
+	Trades   []*types.Trade        `json:"trades,omitempty"`   // order: trades it matched
+	TradeIDs []string              `json:"trade_ids,omitempty"`
+	BatchID  uint64                `json:"batch_id,omitempty"` // submit: Merkle batch ID, 0 for plain trades
+	Error    string                `json:"error,omitempty"`    // submit_result: empty on success
+	Snapshot *ReplicationStateDump `json:"snapshot,omitempty"`
+}
+
+// ReplicationStateDump is the state a standby starts replaying from
+type ReplicationStateDump struct {
+	Orders        []*types.Order     `json:"orders"` // resting orders in book priority
+	PendingTrades []*types.Trade     `json:"pending_trades"`
+	InFlight      *pendingSubmission `json:"in_flight,omitempty"`
+	NextBatchID   uint64             `json:"next_batch_id"`
+	TradeCount    uint64             `json:"trade_count"`
+}
+
+// pendingSubmission is trades handed to the submitter whose outcome is not
+// known yet
+type pendingSubmission struct {
+	BatchID uint64         `json:"batch_id,omitempty"` // Merkle batch ID; 0 for a plain trade submission
+	Trades  []*types.Trade `json:"trades"`
+}
+
+func (p *pendingSubmission) tradeIDs() []string {
+	ids := make([]string, len(p.Trades))
+	for i, trade := range p.Trades {
+		ids[i] = trade.TradeID
+	}
+	return ids
+}
+
+// replicationHello opens a standby connection
+type replicationHello struct {
+	Token string `json:"token"`
+}
+
+// replicationAck acknowledges a submit entry
+type replicationAck struct {
+	Seq uint64 `json:"seq"`
+}
+
+// replicationBuffer is the number of entries a standby may fall behind
+// before it is disconnected and resynced from a snapshot
+const replicationBuffer = 8192
+
+// replicationSubscriber is a connected standby
+type replicationSubscriber struct {
+	ch    chan *ReplicationEntry
+	acked atomic.Uint64
+	ackCh chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+func (s *replicationSubscriber) close() {
+	s.once.Do(func() { close(s.done) })
+}
+
+func (s *replicationSubscriber) ack(seq uint64) {
+	s.acked.Store(seq)
+	select {
+	case s.ackCh <- struct{}{}:
+	default:
+	}
+}
+
+// publish sends an entry to every standby, dropping standbys that fell too
+// far behind. Must be called with m.mu held.
+func (m *OffchainMatcher) publish(entry *ReplicationEntry) {
+	m.replSeq++
+	entry.Seq = m.replSeq
+	entry.Epoch = m.epoch
+	for sub := range m.standbys {
+		select {
+		case sub.ch <- entry:
+		default:
+			log.Printf("Standby fell %d entries behind, disconnecting it", replicationBuffer)
+			sub.close()
+			delete(m.standbys, sub)
+		}
+	}
+}
+
+// subscribeReplication registers a standby, queueing a snapshot of the
+// current state as its first entry
+func (m *OffchainMatcher) subscribeReplication() *replicationSubscriber {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub := &replicationSubscriber{
+		ch:    make(chan *ReplicationEntry, replicationBuffer),
+		ackCh: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	sub.ch <- &ReplicationEntry{Type: ReplicationSnapshot, Seq: m.replSeq, Epoch: m.epoch, Snapshot: m.dumpState()}
+	m.standbys[sub] = struct{}{}
+	return sub
+}
+
+func (m *OffchainMatcher) unsubscribeReplication(sub *replicationSubscriber) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sub.close()
+	delete(m.standbys, sub)
+}
+
+// dumpState copies the matcher state. Must be called with m.mu held.
+func (m *OffchainMatcher) dumpState() *ReplicationStateDump {
+	marketIDs := make([]string, 0, len(m.orderBooks))
+	for id := range m.orderBooks {
+		marketIDs = append(marketIDs, id)
+	}
+	sort.Strings(marketIDs)
+
+	dump := &ReplicationStateDump{
+		Orders:        make([]*types.Order, 0, len(m.orders)),
+		PendingTrades: m.tradeBuffer.Peek(),
+		NextBatchID:   m.nextBatchID,
+		TradeCount:    m.tradeCount,
+	}
+	for _, id := range marketIDs {
+		book := m.orderBooks[id]
+		for _, levels := range [][]*types.PriceLevel{book.Bids, book.Asks} {
+			for _, level := range levels {
+				for _, orderID := range level.OrderIDs {
+					if order, ok := m.orders[orderID]; ok && order.IsActive() {
+						copied := *order
+						dump.Orders = append(dump.Orders, &copied)
+					}
+				}
+			}
+		}
+	}
+	if m.inFlight != nil {
+		dump.InFlight = &pendingSubmission{BatchID: m.inFlight.BatchID, Trades: append([]*types.Trade{}, m.inFlight.Trades...)}
+	}
+	return dump
+}
+
+// restoreState replaces the matcher state with a snapshot. Must be called
+// with m.mu held.
+func (m *OffchainMatcher) restoreState(dump *ReplicationStateDump) {
+	m.orderBooks = make(map[string]*types.OrderBook)
+	m.orders = make(map[string]*types.Order)
+	m.cache.Clear()
+	for _, order := range dump.Orders {
+		m.cache.Set(order)
+		m.orders[order.OrderID] = order
+		m.getOrCreateOrderBook(order.MarketID).AddOrder(order)
+	}
+	m.tradeBuffer.Clear()
+	m.tradeBuffer.AddBatch(dump.PendingTrades)
+	m.inFlight = dump.InFlight
+	m.nextBatchID = dump.NextBatchID
+	m.tradeCount = dump.TradeCount
+}
+
+// applyReplication replays one entry from the primary. Entries must arrive
+// in sequence; a gap means the stream must be resynced from a snapshot.
+func (m *OffchainMatcher) applyReplication(entry *ReplicationEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.role != RoleStandby {
+		return ErrStandby
+	}
+	if entry.Epoch < m.epoch {
+		return fmt.Errorf("%w: primary epoch %d is behind %d", ErrFenced, entry.Epoch, m.epoch)
+	}
+	m.epoch = entry.Epoch
+
+	switch entry.Type {
+	case ReplicationHeartbeat:
+		return nil
+	case ReplicationSnapshot:
+		if entry.Snapshot == nil {
+			return fmt.Errorf("snapshot entry %d has no state", entry.Seq)
+		}
+		m.restoreState(entry.Snapshot)
+		m.replSeq = entry.Seq
+		m.synced = true
+		return nil
+	}
+
+	if !m.synced || entry.Seq != m.replSeq+1 {
+		return fmt.Errorf("replication gap: expected entry %d, got %d", m.replSeq+1, entry.Seq)
+	}
+	m.replSeq = entry.Seq
+
+	switch entry.Type {
+	case ReplicationOrder:
+		if entry.Order == nil {
+			return fmt.Errorf("order entry %d has no order", entry.Seq)
+		}
+		// Replaying the intake keeps the book identical; the primary's
+		// trades, not the replay's, are the ones to settle
+		m.executeOrder(entry.Order)
+		m.tradeBuffer.AddBatch(entry.Trades)
+		m.tradeCount += uint64(len(entry.Trades))
+	case ReplicationCancel:
+		if err := m.cancelOrder(entry.OrderID); err != nil {
+			log.Printf("Replicated cancel of %s: %v", entry.OrderID, err)
+		}
+	case ReplicationSubmit:
+		if m.inFlight != nil {
+			m.tradeBuffer.AddBatch(m.inFlight.Trades)
+		}
+		m.inFlight = &pendingSubmission{BatchID: entry.BatchID, Trades: m.tradeBuffer.Take(entry.TradeIDs)}
+	case ReplicationSubmitResult:
+		if m.inFlight == nil {
+			return nil
+		}
+		if entry.Error != "" {
+			m.tradeBuffer.AddBatch(m.inFlight.Trades)
+		} else if m.inFlight.BatchID > 0 {
+			m.history.Add(NewSettlementBatch(m.inFlight.BatchID, m.inFlight.Trades))
+			m.nextBatchID = m.inFlight.BatchID + 1
+		}
+		m.inFlight = nil
+	default:
+		return fmt.Errorf("unknown replication entry type %q", entry.Type)
+	}
+	return nil
+}
+
+// awaitStandbys waits until every connected standby acknowledged entry seq,
+// for at most the configured ack timeout. A standby that does not answer in
+// time is unhealthy and may not take over with the submission in flight.
+func (m *OffchainMatcher) awaitStandbys(seq uint64) {
+	m.mu.RLock()
+	subs := make([]*replicationSubscriber, 0, len(m.standbys))
+	for sub := range m.standbys {
+		subs = append(subs, sub)
+	}
+	m.mu.RUnlock()
+	if len(subs) == 0 {
+		return
+	}
+
+	timeout := time.NewTimer(m.config.AckTimeout)
+	defer timeout.Stop()
+	for _, sub := range subs {
+	wait:
+		for sub.acked.Load() < seq {
+			select {
+			case <-sub.ackCh:
+			case <-sub.done:
+				break wait
+			case <-timeout.C:
+				log.Printf("Standby did not acknowledge entry %d within %v", seq, m.config.AckTimeout)
+				return
+			}
+		}
+	}
+}
+
+// serveReplication accepts standby connections until the matcher stops
+func (m *OffchainMatcher) serveReplication(ctx context.Context, listener net.Listener) {
+	defer m.wg.Done()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-m.stopCh:
+		}
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.stopCh:
+				return
+			default:
+			}
+			log.Printf("Replication accept failed: %v", err)
+			time.Sleep(m.config.HeartbeatInterval)
+			continue
+		}
+		m.wg.Add(1)
+		go m.serveStandby(ctx, conn)
+	}
+}
+
+// serveStandby streams entries to one standby, sending heartbeats while
+// idle and reading its acknowledgements
+func (m *OffchainMatcher) serveStandby(ctx context.Context, conn net.Conn) {
+	defer m.wg.Done()
+	defer conn.Close()
+
+	dec := json.NewDecoder(conn)
+	var hello replicationHello
+	conn.SetReadDeadline(time.Now().Add(m.config.FailoverTimeout))
+	if err := dec.Decode(&hello); err != nil {
+		log.Printf("Standby %s sent no hello: %v", conn.RemoteAddr(), err)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(hello.Token), []byte(m.config.ReplicationToken)) != 1 {
+		log.Printf("Standby %s rejected: invalid replication token", conn.RemoteAddr())
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	if m.Role() != RolePrimary {
+		log.Printf("Standby %s rejected: not the primary", conn.RemoteAddr())
+		return
+	}
+
+	sub := m.subscribeReplication()
+	defer m.unsubscribeReplication(sub)
+	log.Printf("Standby %s connected", conn.RemoteAddr())
+
+	go func() {
+		defer sub.close()
+		for {
+			var ack replicationAck
+			if err := dec.Decode(&ack); err != nil {
+				return
+			}
+			sub.ack(ack.Seq)
+		}
+	}()
+
+	enc := json.NewEncoder(conn)
+	send := func(entry *ReplicationEntry) bool {
+		conn.SetWriteDeadline(time.Now().Add(m.config.FailoverTimeout))
+		if err := enc.Encode(entry); err != nil {
+			log.Printf("Replication to %s failed: %v", conn.RemoteAddr(), err)
+			return false
+		}
+		return true
+	}
+
+	heartbeat := time.NewTicker(m.config.HeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopCh:
+			return
+		case <-sub.done:
+			return
+		case entry := <-sub.ch:
+			if !send(entry) {
+				return
+			}
+		case <-heartbeat.C:
+			m.mu.RLock()
+			entry := &ReplicationEntry{Type: ReplicationHeartbeat, Seq: m.replSeq, Epoch: m.epoch}
+			m.mu.RUnlock()
+			if !send(entry) {
+				return
+			}
+		}
+	}
+}
+
+// followPrimary replays the primary's stream, reconnecting on errors, and
+// takes over once the primary has not been heard from for the failover
+// timeout. A standby that never received a snapshot does not take over.
+func (m *OffchainMatcher) followPrimary(ctx context.Context) {
+	defer m.wg.Done()
+
+	for {
+		err := m.replicateFrom(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopCh:
+			return
+		default:
+		}
+		log.Printf("Replication from %s interrupted: %v", m.config.ReplicationPrimary, err)
+
+		if m.primaryLost() {
+			if err := m.promote(ctx); err != nil {
+				log.Printf("Failover failed: %v", err)
+			} else {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopCh:
+			return
+		case <-time.After(m.config.HeartbeatInterval):
+		}
+	}
+}
+
+// replicateFrom replays one connection to the primary until it fails
+func (m *OffchainMatcher) replicateFrom(ctx context.Context) error {
+	conn, err := net.DialTimeout("tcp", m.config.ReplicationPrimary, m.config.FailoverTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	closed := make(chan struct{})
+	defer close(closed)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-m.stopCh:
+		case <-closed:
+		}
+		conn.Close()
+	}()
+
+	enc := json.NewEncoder(conn)
+	conn.SetWriteDeadline(time.Now().Add(m.config.FailoverTimeout))
+	if err := enc.Encode(replicationHello{Token: m.config.ReplicationToken}); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(conn)
+	for {
+		conn.SetReadDeadline(time.Now().Add(m.config.FailoverTimeout))
+		var entry ReplicationEntry
+		if err := dec.Decode(&entry); err != nil {
+			return err
+		}
+		m.heard()
+		if err := m.applyReplication(&entry); err != nil {
+			return err
+		}
+		if entry.Type == ReplicationSubmit {
+			conn.SetWriteDeadline(time.Now().Add(m.config.FailoverTimeout))
+			if err := enc.Encode(replicationAck{Seq: entry.Seq}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (m *OffchainMatcher) heard() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastHeard = time.Now()
+}
+
+// primaryLost reports whether a synced standby has not heard from the
+// primary for the failover timeout
+func (m *OffchainMatcher) primaryLost() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.synced && time.Since(m.lastHeard) >= m.config.FailoverTimeout
+}
+
+// promote makes a standby the primary under the next epoch. Claiming the
+// fence first stops the old primary from submitting once it next checks it.
+// Trades in flight at the failover are resubmitted unchanged before any new
+// ones.
+func (m *OffchainMatcher) promote(ctx context.Context) error {
+	m.mu.RLock()
+	epoch := m.epoch + 1
+	m.mu.RUnlock()
+
+	if err := m.fence.Acquire(ctx, epoch); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.role = RolePrimary
+	m.epoch = epoch
+	m.mu.Unlock()
+	log.Printf("Promoted to primary at epoch %d", epoch)
+
+	// A primary without a standby still matches and submits
+	if err := m.listenReplication(ctx); err != nil {
+		log.Printf("Replication not served: %v", err)
+	}
+	return nil
+}
+
+// demote turns a fenced primary into a standby: it stops submitting, drops
+// its standbys and, when it knows a peer, follows it to resync
+func (m *OffchainMatcher) demote(ctx context.Context, err error) {
+	m.mu.Lock()
+	if m.role != RolePrimary {
+		m.mu.Unlock()
+		return
+	}
+	m.role = RoleStandby
+	m.synced = false
+	for sub := range m.standbys {
+		sub.close()
+		delete(m.standbys, sub)
+	}
+	m.mu.Unlock()
+	log.Printf("Stepping down to standby: %v", err)
+
+	if m.config.ReplicationPrimary != "" {
+		m.wg.Add(1)
+		go m.followPrimary(ctx)
+	}
+}
+
+// listenReplication starts serving standbys when a replication address is
+// configured and not already served
+func (m *OffchainMatcher) listenReplication(ctx context.Context) error {
+	if m.config.ReplicationListen == "" || m.ReplicationAddr() != "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", m.config.ReplicationListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen for standbys: %w", err)
+	}
+
+	m.mu.Lock()
+	m.replicationAddr = listener.Addr().String()
+	m.mu.Unlock()
+	log.Printf("Serving replication on %s", listener.Addr())
+
+	m.wg.Add(1)
+	go m.serveReplication(ctx, listener)
+	return nil
+}
+
+// ReplicationAddr returns the address standbys connect to, empty when the
+// matcher is not serving replication
+func (m *OffchainMatcher) ReplicationAddr() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.replicationAddr
+}
+
+// Role returns the matcher's current role
+func (m *OffchainMatcher) Role() Role {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.role
+}
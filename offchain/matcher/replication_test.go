@@ -0,0 +1,181 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+func replicationTestConfig() *Config {
+	config := DefaultConfig()
+	config.ReplicationToken = "secret"
+	config.HeartbeatInterval = 20 * time.Millisecond
+	config.FailoverTimeout = 300 * time.Millisecond
+	config.AckTimeout = 300 * time.Millisecond
+	return config
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReplicationFailover(t *testing.T) {
+	ctx := context.Background()
+	fence := NewMemoryFence()
+	limit := func(id string, side types.Side, price, qty int64) *types.Order {
+		return types.NewOrder(id, "trader-"+id, "BTC-USDT-PERP", side, types.OrderTypeLimit, math.LegacyNewDec(price), math.LegacyNewDec(qty))
+	}
+
+	// The primary never gets a batch through, standing in for one that
+	// crashes before its trades reach the chain
+	primaryConfig := replicationTestConfig()
+	primaryConfig.BatchInterval = time.Hour
+	primaryConfig.ReplicationListen = "127.0.0.1:0"
+	primarySubmitter := NewMockSubmitter()
+	primarySubmitter.SetSimulateFailure(true)
+	primary := NewOffchainMatcher(primaryConfig, primarySubmitter)
+	primary.SetFence(fence)
+	if err := primary.Start(ctx); err != nil {
+		t.Fatalf("start primary: %v", err)
+	}
+
+	primary.SubmitOrder(limit("ask-1", types.SideSell, 100, 2))
+	primary.SubmitOrder(limit("bid-1", types.SideBuy, 100, 1))
+	primary.SubmitOrder(limit("bid-2", types.SideBuy, 99, 1))
+	if err := primary.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	standbyConfig := replicationTestConfig()
+	standbyConfig.Role = RoleStandby
+	standbyConfig.BatchInterval = 20 * time.Millisecond
+	standbyConfig.ReplicationPrimary = primary.ReplicationAddr()
+	standbySubmitter := NewMockSubmitter()
+	standby := NewOffchainMatcher(standbyConfig, standbySubmitter)
+	standby.SetFence(fence)
+	if err := standby.Start(ctx); err != nil {
+		t.Fatalf("start standby: %v", err)
+	}
+	defer standby.Stop()
+
+	// Entries after the snapshot are replayed too
+	primary.SubmitOrder(limit("bid-3", types.SideBuy, 98, 1))
+	primary.CancelOrder("bid-2")
+	if err := primary.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := primary.GetStats()
+	waitFor(t, "standby to catch up", func() bool {
+		return standby.GetStats().ReplicationSeq == want.ReplicationSeq
+	})
+
+	got := standby.GetStats()
+	if got.Role != RoleStandby || got.OrderCount != want.OrderCount || got.PendingTrades != 1 || got.TotalTrades != 1 {
+		t.Fatalf("standby stats %+v, primary %+v", got, want)
+	}
+	if standbys := primary.GetStats().Standbys; standbys != 1 {
+		t.Fatalf("primary reports %d standbys, want 1", standbys)
+	}
+	if standby.GetOrder("bid-2") != nil {
+		t.Fatal("cancelled order still on the standby")
+	}
+	if ask := standby.GetOrder("ask-1"); ask == nil || !ask.RemainingQty().Equal(math.LegacyOneDec()) {
+		t.Fatalf("standby resting ask %+v, want 1 remaining", ask)
+	}
+	tradeID := primary.tradeBuffer.Peek()[0].TradeID
+
+	// A standby takes neither orders nor batches
+	standby.SubmitOrder(limit("bid-4", types.SideBuy, 100, 1))
+	if err := standby.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if standby.GetOrder("bid-4") != nil || len(standbySubmitter.GetSubmittedTrades()) != 0 {
+		t.Fatal("standby acted as a primary")
+	}
+
+	primary.Stop()
+	waitFor(t, "standby to take over", func() bool {
+		return standby.Role() == RolePrimary && len(standbySubmitter.GetSubmittedTrades()) == 1
+	})
+
+	if submitted := standbySubmitter.GetSubmittedTrades()[0].TradeID; submitted != tradeID {
+		t.Fatalf("new primary submitted %s, want the old primary's %s", submitted, tradeID)
+	}
+	if epoch := standby.GetStats().Epoch; epoch != 2 {
+		t.Fatalf("new primary epoch %d, want 2", epoch)
+	}
+	if err := fence.Validate(ctx, 1); !errors.Is(err, ErrFenced) {
+		t.Fatalf("old epoch not fenced: %v", err)
+	}
+
+	// The old primary cannot come back under its epoch
+	restarted := NewOffchainMatcher(replicationTestConfig(), NewMockSubmitter())
+	restarted.SetFence(fence)
+	if err := restarted.Start(ctx); !errors.Is(err, ErrFenced) {
+		t.Fatalf("restart at a fenced epoch: %v", err)
+	}
+}
+
+func TestReplicationRejectsInvalidToken(t *testing.T) {
+	ctx := context.Background()
+	primaryConfig := replicationTestConfig()
+	primaryConfig.ReplicationListen = "127.0.0.1:0"
+	primary := NewOffchainMatcher(primaryConfig, nil)
+	if err := primary.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer primary.Stop()
+
+	standbyConfig := replicationTestConfig()
+	standbyConfig.Role = RoleStandby
+	standbyConfig.ReplicationPrimary = primary.ReplicationAddr()
+	standbyConfig.ReplicationToken = "wrong"
+	standby := NewOffchainMatcher(standbyConfig, nil)
+	if err := standby.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer standby.Stop()
+
+	// Never synced, the standby does not take over however long it waits
+	time.Sleep(3 * standbyConfig.FailoverTimeout)
+	if standby.Role() != RoleStandby || primary.GetStats().Standbys != 0 {
+		t.Fatal("standby with an invalid token was served")
+	}
+}
+
+func TestFileFence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "fence")
+	a, b := NewFileFence(path), NewFileFence(path)
+
+	if err := a.Acquire(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Acquire(ctx, 1); err != nil {
+		t.Fatalf("reacquiring the current epoch: %v", err)
+	}
+	if err := b.Acquire(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Validate(ctx, 1); !errors.Is(err, ErrFenced) {
+		t.Fatalf("epoch 1 not fenced: %v", err)
+	}
+	if err := a.Acquire(ctx, 1); !errors.Is(err, ErrFenced) {
+		t.Fatalf("claimed a superseded epoch: %v", err)
+	}
+	if err := b.Validate(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,457 @@
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// ScenarioConfig configures the synthetic order flow of a soak scenario.
+// Arrivals are a Poisson process; each arrival is a cancel of a random
+// resting order, a market order, or a limit order priced around a mid that
+// follows a geometric random walk.
+type ScenarioConfig struct {
+	MarketID      string        `json:"market_id"`
+	Duration      time.Duration `json:"duration"`       // 0 runs until stopped
+	ArrivalRate   float64       `json:"arrival_rate"`   // mean arrivals per second
+	StartPrice    float64       `json:"start_price"`    // initial mid price
+	Volatility    float64       `json:"volatility"`     // stdev of log mid returns per √second
+	TickSize      float64       `json:"tick_size"`      // price increment
+	DepthTicks    int           `json:"depth_ticks"`    // limit orders rest up to this many ticks from the mid
+	CheckInterval time.Duration `json:"check_interval"` // time between self-checks
+	Seed          int64         `json:"seed"`           // 0 picks a seed from the clock
+
+	MarketOrderRatio float64 `json:"market_order_ratio"` // share of arrivals that are market orders
+	CancelRate       float64 `json:"cancel_rate"`        // share of arrivals that cancel a resting order
+	MinQuantity      float64 `json:"min_quantity"`
+	MaxQuantity      float64 `json:"max_quantity"`
+}
+
+// DefaultScenarioConfig returns the default scenario configuration
+func DefaultScenarioConfig() *ScenarioConfig {
+	return &ScenarioConfig{
+		MarketID:      "BTC-USDT-PERP",
+		ArrivalRate:   50,
+		StartPrice:    50000,
+		Volatility:    0.0005,
+		TickSize:      0.5,
+		DepthTicks:    40,
+		CheckInterval: 30 * time.Second,
+
+		MarketOrderRatio: 0.05,
+		CancelRate:       0.3,
+		MinQuantity:      0.01,
+		MaxQuantity:      2,
+	}
+}
+
+// scenarioLot is the quantity increment of generated orders
+const scenarioLot = 0.001
+
+// Validate checks the scenario configuration
+func (c *ScenarioConfig) Validate() error {
+	switch {
+	case c.MarketID == "":
+		return fmt.Errorf("scenario market ID is required")
+	case c.ArrivalRate <= 0:
+		return fmt.Errorf("scenario arrival rate must be positive")
+	case c.StartPrice <= 0 || c.TickSize <= 0 || c.StartPrice < c.TickSize:
+		return fmt.Errorf("scenario start price and tick size must be positive, with the price at least one tick")
+	case c.Volatility < 0:
+		return fmt.Errorf("scenario volatility must not be negative")
+	case c.DepthTicks <= 0:
+		return fmt.Errorf("scenario depth must be at least one tick")
+	case c.CheckInterval <= 0:
+		return fmt.Errorf("scenario check interval must be positive")
+	case c.MarketOrderRatio < 0 || c.CancelRate < 0 || c.MarketOrderRatio+c.CancelRate > 1:
+		return fmt.Errorf("scenario market order ratio and cancel rate must be non-negative and sum to at most 1")
+	case c.MinQuantity < scenarioLot || c.MaxQuantity < c.MinQuantity:
+		return fmt.Errorf("scenario quantities must be at least %g with min <= max", scenarioLot)
+	}
+	if _, err := sdkmath.LegacyNewDecFromStr(strconv.FormatFloat(c.TickSize, 'f', -1, 64)); err != nil {
+		return fmt.Errorf("invalid scenario tick size: %w", err)
+	}
+	return nil
+}
+
+// ScenarioReport summarizes a scenario run
+type ScenarioReport struct {
+	Seed         int64
+	Elapsed      time.Duration
+	LimitOrders  uint64
+	MarketOrders uint64
+	Cancels      uint64
+	Trades       uint64
+	Checks       int
+	FailedChecks int
+	LastFailure  string
+}
+
+// RunScenario feeds synthetic order flow into a running matcher until the
+// configured duration elapses or ctx is cancelled. Every check interval it
+// waits for the matcher to drain its queue and compares the book against a
+// shadow reference book fed the same flow; a final check runs on exit. The
+// matcher must not receive orders for the scenario market from elsewhere.
+func RunScenario(ctx context.Context, m *OffchainMatcher, config *ScenarioConfig) (*ScenarioReport, error) {
+	if config == nil {
+		config = DefaultScenarioConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	log.Printf("Starting scenario on %s: %.1f arrivals/s, volatility %g, cancel rate %.2f, market ratio %.2f, seed %d",
+		config.MarketID, config.ArrivalRate, config.Volatility, config.CancelRate, config.MarketOrderRatio, seed)
+
+	gen := newScenarioGenerator(config, seed)
+	shadow := newShadowBook()
+	report := &ScenarioReport{Seed: seed}
+	startTrades := m.GetStats().TotalTrades
+
+	start := time.Now()
+	next := start
+	nextCheck := start.Add(config.CheckInterval)
+	var deadline <-chan time.Time
+	if config.Duration > 0 {
+		timer := time.NewTimer(config.Duration)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	check := func(ctx context.Context) {
+		report.Checks++
+		issues := checkScenarioBook(ctx, m, shadow, config.MarketID, startTrades)
+		if len(issues) == 0 {
+			log.Printf("Scenario check %d passed: %d resting orders, %d trades, mid %.2f",
+				report.Checks, len(shadow.resting), shadow.trades, gen.mid())
+			return
+		}
+		report.FailedChecks++
+		report.LastFailure = strings.Join(issues, "; ")
+		log.Printf("Scenario check %d FAILED (%d issues): %s", report.Checks, len(issues), report.LastFailure)
+	}
+
+	for {
+		// Exponential inter-arrival times; the schedule is absolute so a
+		// backed-up event queue is caught up rather than lowering the rate
+		dt := gen.rng.ExpFloat64() / config.ArrivalRate
+		next = next.Add(time.Duration(dt * float64(time.Second)))
+		select {
+		case <-ctx.Done():
+			finalCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			check(finalCtx)
+			cancel()
+			report.Elapsed = time.Since(start)
+			return report, nil
+		case <-deadline:
+			check(ctx)
+			report.Elapsed = time.Since(start)
+			return report, nil
+		case <-time.After(time.Until(next)):
+		}
+
+		gen.step(dt)
+		switch order, cancelID := gen.arrival(shadow); {
+		case cancelID != "":
+			shadow.cancel(cancelID)
+			m.CancelOrder(cancelID)
+			report.Cancels++
+		case order.OrderType == types.OrderTypeMarket:
+			shadow.submit(order)
+			m.SubmitOrder(order)
+			report.MarketOrders++
+		default:
+			shadow.submit(order)
+			m.SubmitOrder(order)
+			report.LimitOrders++
+		}
+		report.Trades = shadow.trades
+
+		if time.Now().After(nextCheck) {
+			check(ctx)
+			// Resume the schedule from now rather than bursting the arrivals
+			// the check held up
+			next = time.Now()
+			nextCheck = next.Add(config.CheckInterval)
+		}
+	}
+}
+
+// scenarioGenerator draws the synthetic flow
+type scenarioGenerator struct {
+	config   *ScenarioConfig
+	rng      *rand.Rand
+	tick     sdkmath.LegacyDec
+	logPrice float64
+	seq      uint64
+}
+
+func newScenarioGenerator(config *ScenarioConfig, seed int64) *scenarioGenerator {
+	tick, _ := sdkmath.LegacyNewDecFromStr(strconv.FormatFloat(config.TickSize, 'f', -1, 64))
+	return &scenarioGenerator{
+		config:   config,
+		rng:      rand.New(rand.NewSource(seed)),
+		tick:     tick,
+		logPrice: math.Log(config.StartPrice),
+	}
+}
+
+// step advances the mid's random walk by dt seconds
+func (g *scenarioGenerator) step(dt float64) {
+	g.logPrice += g.config.Volatility * math.Sqrt(dt) * g.rng.NormFloat64()
+}
+
+func (g *scenarioGenerator) mid() float64 {
+	return math.Exp(g.logPrice)
+}
+
+// arrival draws the next event: a cancel of a resting order, or a new order
+func (g *scenarioGenerator) arrival(shadow *shadowBook) (*types.Order, string) {
+	u := g.rng.Float64()
+	if u < g.config.CancelRate && len(shadow.resting) > 0 {
+		return nil, shadow.resting[g.rng.Intn(len(shadow.resting))].id
+	}
+
+	g.seq++
+	side := types.SideBuy
+	if g.rng.Intn(2) == 0 {
+		side = types.SideSell
+	}
+	minLots := int64(math.Round(g.config.MinQuantity / scenarioLot))
+	maxLots := int64(math.Round(g.config.MaxQuantity / scenarioLot))
+	quantity := sdkmath.LegacyNewDecWithPrec(minLots+g.rng.Int63n(maxLots-minLots+1), 3)
+	orderID := fmt.Sprintf("scn-%d", g.seq)
+	trader := fmt.Sprintf("scn-trader-%d", g.rng.Intn(50))
+
+	if u < g.config.CancelRate+g.config.MarketOrderRatio {
+		return types.NewOrder(orderID, trader, g.config.MarketID, side, types.OrderTypeMarket,
+			sdkmath.LegacyZeroDec(), quantity), ""
+	}
+
+	// Mostly passive, with a fifth of the depth crossing the mid
+	depth := g.config.DepthTicks
+	offset := int64(g.rng.Intn(depth+1) - depth/5)
+	ticks := int64(math.Round(g.mid() / g.config.TickSize))
+	if side == types.SideBuy {
+		ticks -= offset
+	} else {
+		ticks += offset
+	}
+	if ticks < 1 {
+		ticks = 1
+	}
+	price := sdkmath.LegacyNewDec(ticks).Mul(g.tick)
+	return types.NewOrder(orderID, trader, g.config.MarketID, side, types.OrderTypeLimit, price, quantity), ""
+}
+
+// shadowOrder is the reference book's copy of a resting order; the matcher
+// mutates the orders it is given, so the shadow never shares them
+type shadowOrder struct {
+	id        string
+	side      types.Side
+	price     sdkmath.LegacyDec
+	remaining sdkmath.LegacyDec
+}
+
+// shadowBook is a deliberately naive reference implementation of
+// price-time matching: resting orders are kept in arrival order and every
+// fill scans them all for the best price, taking the earliest on ties
+type shadowBook struct {
+	resting []*shadowOrder
+	trades  uint64
+}
+
+func newShadowBook() *shadowBook {
+	return &shadowBook{resting: make([]*shadowOrder, 0)}
+}
+
+// submit matches an order at the makers' prices and rests a limit remainder
+func (b *shadowBook) submit(order *types.Order) {
+	remaining := order.Quantity
+	for remaining.IsPositive() {
+		best := -1
+		for i, maker := range b.resting {
+			if maker.side == order.Side {
+				continue
+			}
+			if order.OrderType == types.OrderTypeLimit {
+				if order.Side == types.SideBuy && maker.price.GT(order.Price) {
+					continue
+				}
+				if order.Side == types.SideSell && maker.price.LT(order.Price) {
+					continue
+				}
+			}
+			if best < 0 ||
+				(order.Side == types.SideBuy && maker.price.LT(b.resting[best].price)) ||
+				(order.Side == types.SideSell && maker.price.GT(b.resting[best].price)) {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		maker := b.resting[best]
+		qty := sdkmath.LegacyMinDec(remaining, maker.remaining)
+		remaining = remaining.Sub(qty)
+		maker.remaining = maker.remaining.Sub(qty)
+		b.trades++
+		if maker.remaining.IsZero() {
+			b.resting = append(b.resting[:best], b.resting[best+1:]...)
+		}
+	}
+
+	if remaining.IsPositive() && order.OrderType == types.OrderTypeLimit {
+		b.resting = append(b.resting, &shadowOrder{
+			id:        order.OrderID,
+			side:      order.Side,
+			price:     order.Price,
+			remaining: remaining,
+		})
+	}
+}
+
+// cancel removes a resting order
+func (b *shadowBook) cancel(orderID string) {
+	for i, order := range b.resting {
+		if order.id == orderID {
+			b.resting = append(b.resting[:i], b.resting[i+1:]...)
+			return
+		}
+	}
+}
+
+// levels aggregates one side into price levels in book order, with order IDs
+// in time priority
+func (b *shadowBook) levels(side types.Side) []*types.PriceLevel {
+	levels := make([]*types.PriceLevel, 0)
+	for _, order := range b.resting {
+		if order.side != side {
+			continue
+		}
+		var level *types.PriceLevel
+		for _, pl := range levels {
+			if pl.Price.Equal(order.price) {
+				level = pl
+				break
+			}
+		}
+		if level == nil {
+			level = types.NewPriceLevel(order.price)
+			levels = append(levels, level)
+		}
+		level.AddOrder(order.id, order.remaining)
+	}
+	for i := 1; i < len(levels); i++ {
+		for j := i; j > 0; j-- {
+			better := levels[j].Price.LT(levels[j-1].Price)
+			if side == types.SideBuy {
+				better = levels[j].Price.GT(levels[j-1].Price)
+			}
+			if !better {
+				break
+			}
+			levels[j], levels[j-1] = levels[j-1], levels[j]
+		}
+	}
+	return levels
+}
+
+// checkScenarioBook waits for the matcher to handle everything submitted so
+// far, then checks the book's own invariants and compares it level by level
+// with the shadow book
+func checkScenarioBook(ctx context.Context, m *OffchainMatcher, shadow *shadowBook, marketID string, startTrades uint64) []string {
+	if err := m.Sync(ctx); err != nil {
+		return []string{fmt.Sprintf("matcher did not drain its queue: %v", err)}
+	}
+
+	issues := make([]string, 0)
+	report := func(format string, args ...interface{}) {
+		if len(issues) < 10 {
+			issues = append(issues, fmt.Sprintf(format, args...))
+		}
+	}
+
+	book := m.GetOrderBook(marketID)
+	if book == nil {
+		book = types.NewOrderBook(marketID)
+	}
+
+	// Book invariants: sorted, uncrossed, no empty levels, level quantity
+	// equal to its orders' remaining quantity, each order listed once
+	seen := make(map[string]bool)
+	for _, side := range []struct {
+		name   string
+		side   types.Side
+		levels []*types.PriceLevel
+	}{{"bid", types.SideBuy, book.Bids}, {"ask", types.SideSell, book.Asks}} {
+		for i, level := range side.levels {
+			if i > 0 && ((side.side == types.SideBuy && !level.Price.LT(side.levels[i-1].Price)) ||
+				(side.side == types.SideSell && !level.Price.GT(side.levels[i-1].Price))) {
+				report("%s level %s out of order", side.name, level.Price)
+			}
+			if level.IsEmpty() || !level.Quantity.IsPositive() {
+				report("empty %s level %s (quantity %s)", side.name, level.Price, level.Quantity)
+			}
+			sum := sdkmath.LegacyZeroDec()
+			for _, id := range level.OrderIDs {
+				if seen[id] {
+					report("order %s listed twice", id)
+				}
+				seen[id] = true
+				order := m.GetOrder(id)
+				if order == nil {
+					report("%s level %s lists unknown order %s", side.name, level.Price, id)
+					continue
+				}
+				if order.Side != side.side || !order.Price.Equal(level.Price) {
+					report("order %s (%s @ %s) rests at %s level %s", id, order.Side, order.Price, side.name, level.Price)
+				}
+				sum = sum.Add(order.RemainingQty())
+			}
+			if !sum.Equal(level.Quantity) {
+				report("%s level %s quantity %s, orders sum to %s", side.name, level.Price, level.Quantity, sum)
+			}
+		}
+	}
+	if len(book.Bids) > 0 && len(book.Asks) > 0 && book.Bids[0].Price.GTE(book.Asks[0].Price) {
+		report("book crossed: bid %s >= ask %s", book.Bids[0].Price, book.Asks[0].Price)
+	}
+
+	// Reference comparison
+	compareScenarioLevels("bid", book.Bids, shadow.levels(types.SideBuy), report)
+	compareScenarioLevels("ask", book.Asks, shadow.levels(types.SideSell), report)
+	stats := m.GetStats()
+	if trades := stats.TotalTrades - startTrades; trades != shadow.trades {
+		report("matcher made %d trades, reference %d", trades, shadow.trades)
+	}
+	return issues
+}
+
+func compareScenarioLevels(name string, got, want []*types.PriceLevel, report func(string, ...interface{})) {
+	if len(got) != len(want) {
+		report("%d %s levels, reference %d", len(got), name, len(want))
+	}
+	for i := 0; i < len(got) && i < len(want); i++ {
+		g, w := got[i], want[i]
+		if !g.Price.Equal(w.Price) || !g.Quantity.Equal(w.Quantity) {
+			report("%s level %d is %s @ %s, reference %s @ %s", name, i, g.Quantity, g.Price, w.Quantity, w.Price)
+			continue
+		}
+		if strings.Join(g.OrderIDs, ",") != strings.Join(w.OrderIDs, ",") {
+			report("%s level %s queue %v, reference %v", name, g.Price, g.OrderIDs, w.OrderIDs)
+		}
+	}
+}
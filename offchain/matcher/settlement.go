@@ -0,0 +1,133 @@
+package matcher
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// SettlementBatch is a batch of matched trades committed on-chain by its Merkle root
+type SettlementBatch struct {
+	BatchID   uint64
+	Root      []byte
+	Trades    []*types.Trade
+	CreatedAt time.Time
+
+	leaves [][]byte
+	index  map[string]int // tradeID -> leaf index
+}
+
+// NewSettlementBatch builds the Merkle tree for a batch of trades
+func NewSettlementBatch(batchID uint64, trades []*types.Trade) *SettlementBatch {
+	leaves := types.TradeLeaves(trades)
+	index := make(map[string]int, len(trades))
+	for i, trade := range trades {
+		index[trade.TradeID] = i
+	}
+
+	return &SettlementBatch{
+		BatchID:   batchID,
+		Root:      types.MerkleRoot(leaves),
+		Trades:    trades,
+		CreatedAt: time.Now(),
+		leaves:    leaves,
+		index:     index,
+	}
+}
+
+// RootHex returns the hex-encoded Merkle root
+func (b *SettlementBatch) RootHex() string {
+	return hex.EncodeToString(b.Root)
+}
+
+// Proof returns the inclusion proof for a trade in the batch
+func (b *SettlementBatch) Proof(tradeID string) (*types.MerkleProof, error) {
+	i, exists := b.index[tradeID]
+	if !exists {
+		return nil, fmt.Errorf("trade %s not in batch %d", tradeID, b.BatchID)
+	}
+	return types.BuildMerkleProof(b.leaves, i)
+}
+
+// Msg returns the on-chain commitment message for the batch
+func (b *SettlementBatch) Msg(submitter string) *types.MsgCommitTradeBatch {
+	return &types.MsgCommitTradeBatch{
+		Submitter:  submitter,
+		BatchId:    b.BatchID,
+		MerkleRoot: b.RootHex(),
+		TradeCount: uint64(len(b.Trades)),
+	}
+}
+
+// BatchHistory retains recently committed batches so proofs can be served
+// to counterparties for the length of the on-chain dispute window
+type BatchHistory struct {
+	batches map[uint64]*SettlementBatch
+	tradeTo map[string]uint64 // tradeID -> batchID
+	order   []uint64
+	maxSize int
+	mu      sync.RWMutex
+}
+
+// NewBatchHistory creates a batch history holding up to maxSize batches
+func NewBatchHistory(maxSize int) *BatchHistory {
+	if maxSize <= 0 {
+		maxSize = 1000
+	}
+	return &BatchHistory{
+		batches: make(map[uint64]*SettlementBatch),
+		tradeTo: make(map[string]uint64),
+		order:   make([]uint64, 0, maxSize),
+		maxSize: maxSize,
+	}
+}
+
+// Add records a committed batch, evicting the oldest batch when full
+func (h *BatchHistory) Add(batch *SettlementBatch) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.order) >= h.maxSize {
+		oldest := h.batches[h.order[0]]
+		for _, trade := range oldest.Trades {
+			delete(h.tradeTo, trade.TradeID)
+		}
+		delete(h.batches, h.order[0])
+		h.order = h.order[1:]
+	}
+
+	h.batches[batch.BatchID] = batch
+	h.order = append(h.order, batch.BatchID)
+	for _, trade := range batch.Trades {
+		h.tradeTo[trade.TradeID] = batch.BatchID
+	}
+}
+
+// Get returns a batch by ID
+func (h *BatchHistory) Get(batchID uint64) *SettlementBatch {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.batches[batchID]
+}
+
+// FindTrade returns the batch containing a trade
+func (h *BatchHistory) FindTrade(tradeID string) *SettlementBatch {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	batchID, exists := h.tradeTo[tradeID]
+	if !exists {
+		return nil
+	}
+	return h.batches[batchID]
+}
+
+// Len returns the number of retained batches
+func (h *BatchHistory) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.order)
+}
@@ -3,6 +3,7 @@ package matcher
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -16,6 +17,9 @@ type TxSubmitter interface {
 	// SubmitTrades submits a batch of trades to the chain
 	SubmitTrades(ctx context.Context, trades []*types.Trade) error
 
+	// CommitBatch commits the Merkle root of a settlement batch to the chain
+	CommitBatch(ctx context.Context, batch *SettlementBatch) error
+
 	// SubmitOrderUpdate submits an order status update to the chain
 	SubmitOrderUpdate(ctx context.Context, order *types.Order) error
 
@@ -38,6 +42,7 @@ type MockSubmitter struct {
 	mu              sync.Mutex
 	trades          []*types.Trade
 	orders          []*types.Order
+	batches         []*SettlementBatch
 	status          SubmitterStatus
 	simulateFailure bool
 }
@@ -76,6 +81,26 @@ func (s *MockSubmitter) SubmitTrades(ctx context.Context, trades []*types.Trade)
 	return nil
 }
 
+// CommitBatch commits a batch root (mock implementation)
+func (s *MockSubmitter) CommitBatch(ctx context.Context, batch *SettlementBatch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.simulateFailure {
+		s.status.FailedSubmissions++
+		s.status.LastError = "simulated failure"
+		return fmt.Errorf("simulated failure")
+	}
+
+	s.batches = append(s.batches, batch)
+	s.status.TotalSubmissions++
+	s.status.LastSubmitTime = time.Now()
+
+	log.Printf("[MockSubmitter] Committed batch %d: %d trades, root %s", batch.BatchID, len(batch.Trades), batch.RootHex())
+
+	return nil
+}
+
 // SubmitOrderUpdate submits an order update (mock implementation)
 func (s *MockSubmitter) SubmitOrderUpdate(ctx context.Context, order *types.Order) error {
 	s.mu.Lock()
@@ -121,6 +146,15 @@ func (s *MockSubmitter) GetSubmittedOrders() []*types.Order {
 	return result
 }
 
+// GetCommittedBatches returns all committed batches (for testing)
+func (s *MockSubmitter) GetCommittedBatches() []*SettlementBatch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]*SettlementBatch, len(s.batches))
+	copy(result, s.batches)
+	return result
+}
+
 // SetSimulateFailure enables or disables failure simulation
 func (s *MockSubmitter) SetSimulateFailure(fail bool) {
 	s.mu.Lock()
@@ -134,14 +168,82 @@ func (s *MockSubmitter) Clear() {
 	defer s.mu.Unlock()
 	s.trades = make([]*types.Trade, 0)
 	s.orders = make([]*types.Order, 0)
+	s.batches = nil
+}
+
+// DiscardSubmitter accepts and drops everything, keeping only counts, for
+// soak runs where retaining or logging every trade would grow without bound
+type DiscardSubmitter struct {
+	mu     sync.Mutex
+	status SubmitterStatus
+	trades int64
+}
+
+// NewDiscardSubmitter creates a new discard submitter
+func NewDiscardSubmitter() *DiscardSubmitter {
+	return &DiscardSubmitter{
+		status: SubmitterStatus{
+			Connected: true,
+		},
+	}
+}
+
+// SubmitTrades counts and drops trades
+func (s *DiscardSubmitter) SubmitTrades(ctx context.Context, trades []*types.Trade) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trades += int64(len(trades))
+	s.status.TotalSubmissions++
+	s.status.LastSubmitTime = time.Now()
+	return nil
+}
+
+// CommitBatch counts and drops a batch
+func (s *DiscardSubmitter) CommitBatch(ctx context.Context, batch *SettlementBatch) error {
+	return s.SubmitTrades(ctx, batch.Trades)
+}
+
+// SubmitOrderUpdate drops an order update
+func (s *DiscardSubmitter) SubmitOrderUpdate(ctx context.Context, order *types.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.TotalSubmissions++
+	s.status.LastSubmitTime = time.Now()
+	return nil
+}
+
+// GetStatus returns the discard submitter status
+func (s *DiscardSubmitter) GetStatus() SubmitterStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// DiscardedTrades returns the number of trades dropped so far
+func (s *DiscardSubmitter) DiscardedTrades() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.trades
+}
+
+// ErrNoBroadcaster is returned when a batch commitment cannot be sent
+// because no broadcaster is configured
+var ErrNoBroadcaster = errors.New("no commit broadcaster configured")
+
+// CommitBroadcaster signs a batch commitment with the settlement operator's
+// key, broadcasts it and waits until it is included in a block
+type CommitBroadcaster interface {
+	BroadcastCommit(ctx context.Context, msg *types.MsgCommitTradeBatch) error
 }
 
 // BatchSubmitter submits trades in batches to the chain
 type BatchSubmitter struct {
 	rpcURL        string
+	operator      string
 	batchSize     int
 	retryAttempts int
 	retryDelay    time.Duration
+	broadcaster   CommitBroadcaster
 
 	mu     sync.Mutex
 	status SubmitterStatus
@@ -150,9 +252,11 @@ type BatchSubmitter struct {
 // BatchSubmitterConfig holds configuration for BatchSubmitter
 type BatchSubmitterConfig struct {
 	RPCURL        string
+	Operator      string // Settlement operator address signing batch commitments
 	BatchSize     int
 	RetryAttempts int
 	RetryDelay    time.Duration
+	Broadcaster   CommitBroadcaster // Sends batch commitments; nil fails every commit
 }
 
 // DefaultBatchSubmitterConfig returns default configuration
@@ -173,9 +277,11 @@ func NewBatchSubmitter(config *BatchSubmitterConfig) *BatchSubmitter {
 
 	return &BatchSubmitter{
 		rpcURL:        config.RPCURL,
+		operator:      config.Operator,
 		batchSize:     config.BatchSize,
 		retryAttempts: config.RetryAttempts,
 		retryDelay:    config.RetryDelay,
+		broadcaster:   config.Broadcaster,
 		status: SubmitterStatus{
 			Connected: true,
 		},
@@ -266,6 +372,47 @@ func (s *BatchSubmitter) submitBatch(ctx context.Context, batch []*types.Trade)
 	return nil
 }
 
+// CommitBatch commits a settlement batch root with retry logic
+func (s *BatchSubmitter) CommitBatch(ctx context.Context, batch *SettlementBatch) error {
+	msg := batch.Msg(s.operator)
+	if err := msg.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid batch commitment: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < s.retryAttempts; attempt++ {
+		if lastErr = s.broadcastCommit(ctx, msg); lastErr == nil {
+			s.mu.Lock()
+			s.status.TotalSubmissions++
+			s.status.LastSubmitTime = time.Now()
+			s.mu.Unlock()
+			return nil
+		}
+		log.Printf("Batch commit attempt %d failed: %v", attempt+1, lastErr)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.retryDelay):
+		}
+	}
+
+	s.mu.Lock()
+	s.status.FailedSubmissions++
+	s.status.LastError = lastErr.Error()
+	s.mu.Unlock()
+	return fmt.Errorf("all retry attempts failed: %w", lastErr)
+}
+
+// broadcastCommit broadcasts a single batch commitment
+func (s *BatchSubmitter) broadcastCommit(ctx context.Context, msg *types.MsgCommitTradeBatch) error {
+	if s.broadcaster == nil {
+		return ErrNoBroadcaster
+	}
+	log.Printf("[BatchSubmitter] Committing batch %d (%d trades), root %s", msg.BatchId, msg.TradeCount, msg.MerkleRoot)
+	return s.broadcaster.BroadcastCommit(ctx, msg)
+}
+
 // encodeTrades encodes trades for submission
 func (s *BatchSubmitter) encodeTrades(trades []*types.Trade) string {
 	// In production, this would properly encode the trades
@@ -327,6 +474,8 @@ func (f *SubmitterFactory) Create(submitterType string, config *BatchSubmitterCo
 		return NewMockSubmitter()
 	case "batch":
 		return NewBatchSubmitter(config)
+	case "discard":
+		return NewDiscardSubmitter()
 	default:
 		return NewMockSubmitter()
 	}
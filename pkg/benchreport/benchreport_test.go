@@ -0,0 +1,139 @@
+package benchreport
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// benchOutput renders `go test -bench` output with one line per sample
+func benchOutput(pkg string, samples map[string][]float64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "goos: linux\ngoarch: amd64\npkg: %s\n", pkg)
+	for _, name := range []string{"KeeperPlaceOrder/rest/depth=100-8", "KeeperCancelOrder/depth=100-8"} {
+		for _, ns := range samples[name] {
+			fmt.Fprintf(&b, "Benchmark%s \t   10000\t %.0f ns/op\t  2048 B/op\t      31 allocs/op\n", name, ns)
+		}
+	}
+	b.WriteString("PASS\nok  \t" + pkg + "\t12.345s\n")
+	return b.String()
+}
+
+// TestParse tests that repeated result lines become samples per unit
+func TestParse(t *testing.T) {
+	out := benchOutput("example.com/keeper", map[string][]float64{
+		"KeeperPlaceOrder/rest/depth=100-8": {1000, 1010, 990},
+	})
+	set, err := Parse(strings.NewReader(out + "BenchmarkBroken 10 1.2.3 ns/op\n"))
+	if err == nil {
+		t.Fatal("expected an invalid value to fail")
+	}
+
+	set, err = Parse(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	bench := set.Get("example.com/keeper", "KeeperPlaceOrder/rest/depth=100-8")
+	if len(set.Benchmarks) != 1 || bench == nil {
+		t.Fatalf("expected one benchmark, got %+v", set.Benchmarks)
+	}
+	if units := bench.Units(); len(units) != 3 || units[0] != "ns/op" || units[2] != "allocs/op" {
+		t.Errorf("expected ns/op, B/op and allocs/op, got %v", units)
+	}
+	if got := bench.Samples["ns/op"]; len(got) != 3 || got[1] != 1010 {
+		t.Errorf("expected 3 ns/op samples, got %v", got)
+	}
+}
+
+// TestSummarize tests that outliers are dropped before the mean
+func TestSummarize(t *testing.T) {
+	s := Summarize([]float64{100, 102, 98, 101, 99, 500})
+	if s.N() != 5 || s.Mean != 100 || s.Diff != 0.02 {
+		t.Errorf("expected 5 samples around 100 ± 2%%, got %d at %v ± %v", s.N(), s.Mean, s.Diff)
+	}
+}
+
+// TestMannWhitneyU tests the p-value of separated, overlapping and identical
+// samples
+func TestMannWhitneyU(t *testing.T) {
+	a := []float64{10, 11, 12, 13, 14, 15, 16, 17, 18, 19}
+	b := []float64{20, 21, 22, 23, 24, 25, 26, 27, 28, 29}
+	// Exact two-sided p for fully separated samples of 10 is 1.08e-5
+	if p := MannWhitneyU(a, b); p > 0.001 {
+		t.Errorf("expected separated samples to differ, got p=%v", p)
+	}
+	if p := MannWhitneyU(a, a); math.Abs(p-1) > 1e-9 {
+		t.Errorf("expected p=1 for identical samples, got %v", p)
+	}
+	if p := MannWhitneyU([]float64{5, 5, 5}, []float64{5, 5, 5}); p != 1 {
+		t.Errorf("expected p=1 for all ties, got %v", p)
+	}
+	if p := MannWhitneyU(a, []float64{10.5, 12.5, 14.5, 16.5, 18.5, 11.5, 13.5, 15.5, 17.5, 19.5}); p < 0.05 {
+		t.Errorf("expected interleaved samples not to differ, got p=%v", p)
+	}
+}
+
+// TestCompare tests that only significant changes past the threshold in a
+// gated unit are regressions
+func TestCompare(t *testing.T) {
+	oldOut := benchOutput("example.com/keeper", map[string][]float64{
+		"KeeperPlaceOrder/rest/depth=100-8": {1000, 1010, 990, 1005, 995, 1002, 998, 1001},
+		"KeeperCancelOrder/depth=100-8":     {500, 505, 495, 502, 498, 501, 499, 500},
+	})
+	newOut := benchOutput("example.com/keeper", map[string][]float64{
+		// 20% slower
+		"KeeperPlaceOrder/rest/depth=100-8": {1200, 1210, 1190, 1205, 1195, 1202, 1198, 1201},
+		// Noise
+		"KeeperCancelOrder/depth=100-8": {501, 499, 503, 497, 500, 502, 498, 500},
+	})
+	oldSet, err := Parse(strings.NewReader(oldOut))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	newSet, err := Parse(strings.NewReader(newOut))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	rows := Compare(oldSet, newSet, DefaultOptions())
+	if len(rows) != 6 {
+		t.Fatalf("expected 2 benchmarks × 3 units, got %d rows", len(rows))
+	}
+	regressions := Regressions(rows)
+	if len(regressions) != 1 || regressions[0].Name != "KeeperPlaceOrder/rest/depth=100-8" || regressions[0].Unit != "ns/op" {
+		t.Fatalf("expected the PlaceOrder slowdown only, got %+v", regressions)
+	}
+	if math.Abs(regressions[0].Delta-0.2) > 1e-3 {
+		t.Errorf("expected +20%%, got %v", regressions[0].Delta)
+	}
+
+	opts := DefaultOptions()
+	opts.Threshold = 0.25
+	if got := Regressions(Compare(oldSet, newSet, opts)); len(got) != 0 {
+		t.Errorf("expected no regression past 25%%, got %d", len(got))
+	}
+	opts = DefaultOptions()
+	opts.Units = []string{"allocs/op"}
+	if got := Regressions(Compare(oldSet, newSet, opts)); len(got) != 0 {
+		t.Errorf("expected ns/op not to be gated, got %d", len(got))
+	}
+
+	var text bytes.Buffer
+	if err := WriteText(&text, rows); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	for _, want := range []string{"old ns/op", "1µs ± 1%", "1.2µs ± 1%", "+20.00%", "REGRESSION", "~ (p="} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("expected %q in report:\n%s", want, text.String())
+		}
+	}
+	var md bytes.Buffer
+	if err := WriteMarkdown(&md, rows); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	if lines := strings.Count(md.String(), "\n"); lines != 8 {
+		t.Errorf("expected a header, separator and 6 rows, got %d lines", lines)
+	}
+}
@@ -0,0 +1,194 @@
+package benchreport
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Options sets when a difference is reported as a regression
+type Options struct {
+	// Alpha is the p-value below which a difference is significant
+	Alpha float64
+	// Threshold is the fraction a significant change must worsen a metric
+	// by to be a regression, e.g. 0.05
+	Threshold float64
+	// Units restricts regressions to these units; empty checks all of them
+	Units []string
+}
+
+// DefaultOptions flags significant slowdowns or growth of 5% in any unit
+func DefaultOptions() Options {
+	return Options{Alpha: 0.05, Threshold: 0.05}
+}
+
+// Row compares one unit of one benchmark across two runs
+type Row struct {
+	Pkg         string
+	Name        string
+	Unit        string
+	Old         Summary
+	New         Summary
+	Delta       float64 // (new - old) / old
+	P           float64
+	Significant bool
+	Regression  bool
+}
+
+// Compare compares every benchmark and unit present in both runs, in the
+// order of the new run
+func Compare(oldSet, newSet *Set, opts Options) []*Row {
+	rows := make([]*Row, 0)
+	for _, bench := range newSet.Benchmarks {
+		old := oldSet.Get(bench.Pkg, bench.Name)
+		if old == nil {
+			continue
+		}
+		for _, unit := range bench.Units() {
+			oldSamples, ok := old.Samples[unit]
+			if !ok {
+				continue
+			}
+			row := &Row{
+				Pkg:  bench.Pkg,
+				Name: bench.Name,
+				Unit: unit,
+				Old:  Summarize(oldSamples),
+				New:  Summarize(bench.Samples[unit]),
+			}
+			if row.Old.Mean != 0 {
+				row.Delta = (row.New.Mean - row.Old.Mean) / row.Old.Mean
+			}
+			row.P = MannWhitneyU(row.Old.Values, row.New.Values)
+			row.Significant = row.P < opts.Alpha
+			worse := row.Delta
+			if higherIsBetter(unit) {
+				worse = -worse
+			}
+			row.Regression = row.Significant && worse > opts.Threshold && gated(unit, opts.Units)
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Regressions returns the rows flagged as regressions
+func Regressions(rows []*Row) []*Row {
+	regressions := make([]*Row, 0)
+	for _, row := range rows {
+		if row.Regression {
+			regressions = append(regressions, row)
+		}
+	}
+	return regressions
+}
+
+// higherIsBetter reports whether a larger value of unit is an improvement,
+// as with throughputs such as MB/s
+func higherIsBetter(unit string) bool {
+	return strings.HasSuffix(unit, "/s")
+}
+
+func gated(unit string, units []string) bool {
+	if len(units) == 0 {
+		return true
+	}
+	for _, u := range units {
+		if u == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText writes rows as benchstat-style tables, one per unit, marking
+// regressions. Changes that are not significant show as ~.
+func WriteText(w io.Writer, rows []*Row) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, unit := range rowUnits(rows) {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "name\told %s\tnew %s\tdelta\t\n", unit, unit)
+		for _, row := range rows {
+			if row.Unit != unit {
+				continue
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", row.Name, formatSummary(row.Old, unit), formatSummary(row.New, unit), formatDelta(row), verdict(row))
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteMarkdown writes rows as a Markdown table, for pull request comments
+func WriteMarkdown(w io.Writer, rows []*Row) error {
+	if _, err := fmt.Fprintln(w, "| Benchmark | Unit | Old | New | Delta | |\n|---|---|---|---|---|---|"); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintf(w, "| `%s` | %s | %s | %s | %s | %s |\n", row.Name, row.Unit, formatSummary(row.Old, row.Unit), formatSummary(row.New, row.Unit), formatDelta(row), verdict(row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rowUnits(rows []*Row) []string {
+	units := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		if !seen[row.Unit] {
+			seen[row.Unit] = true
+			units = append(units, row.Unit)
+		}
+	}
+	return units
+}
+
+func verdict(row *Row) string {
+	if row.Regression {
+		return "REGRESSION"
+	}
+	return ""
+}
+
+func formatDelta(row *Row) string {
+	if !row.Significant {
+		return fmt.Sprintf("~ (p=%.3f n=%d+%d)", row.P, row.Old.N(), row.New.N())
+	}
+	return fmt.Sprintf("%+.2f%% (p=%.3f n=%d+%d)", row.Delta*100, row.P, row.Old.N(), row.New.N())
+}
+
+func formatSummary(s Summary, unit string) string {
+	return fmt.Sprintf("%s ± %.0f%%", formatValue(s.Mean, unit), s.Diff*100)
+}
+
+type scale struct {
+	factor float64
+	suffix string
+}
+
+var (
+	timeScales = []scale{{1e9, "s"}, {1e6, "ms"}, {1e3, "µs"}, {1, "ns"}}
+	byteScales = []scale{{1 << 30, "GB"}, {1 << 20, "MB"}, {1 << 10, "kB"}, {1, "B"}}
+)
+
+// formatValue scales durations and byte counts to a readable unit
+func formatValue(v float64, unit string) string {
+	var scales []scale
+	switch unit {
+	case "ns/op":
+		scales = timeScales
+	case "B/op":
+		scales = byteScales
+	default:
+		return fmt.Sprintf("%.4g", v)
+	}
+	for _, s := range scales {
+		if v >= s.factor || s.factor == 1 {
+			return fmt.Sprintf("%.3g%s", v/s.factor, s.suffix)
+		}
+	}
+	return fmt.Sprintf("%.4g", v)
+}
@@ -0,0 +1,93 @@
+// Package benchreport compares two sets of `go test -bench` results the way
+// benchstat does and flags performance regressions. Each benchmark should be
+// run several times (-count 10) so differences can be told from noise.
+package benchreport
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Benchmark holds every sample of one benchmark, by unit
+type Benchmark struct {
+	Pkg     string
+	Name    string // without the Benchmark prefix, e.g. KeeperCancelOrder/depth=100-8
+	Samples map[string][]float64
+	units   []string
+}
+
+// Units returns the benchmark's units in the order they were first reported
+func (b *Benchmark) Units() []string {
+	return b.units
+}
+
+func (b *Benchmark) key() string {
+	return b.Pkg + "." + b.Name
+}
+
+// Set is the benchmarks of one run, in the order they first appear
+type Set struct {
+	Benchmarks []*Benchmark
+	byKey      map[string]*Benchmark
+}
+
+// Get returns a benchmark by package and name
+func (s *Set) Get(pkg, name string) *Benchmark {
+	return s.byKey[pkg+"."+name]
+}
+
+// Parse reads `go test -bench` output. Result lines from repeated runs of a
+// benchmark are collected as its samples; other lines are ignored except
+// "pkg:", which sets the package of the results after it.
+func Parse(r io.Reader) (*Set, error) {
+	set := &Set{byKey: make(map[string]*Benchmark)}
+	pkg := ""
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(text, "pkg:"); ok {
+			pkg = strings.TrimSpace(rest)
+			continue
+		}
+		if !strings.HasPrefix(text, "Benchmark") {
+			continue
+		}
+		fields := strings.Fields(text)
+		// Name, iterations, then value and unit pairs
+		if len(fields) < 4 || len(fields)%2 != 0 {
+			continue
+		}
+		if _, err := strconv.ParseInt(fields[1], 10, 64); err != nil {
+			continue
+		}
+
+		name := strings.TrimPrefix(fields[0], "Benchmark")
+		bench := set.byKey[pkg+"."+name]
+		if bench == nil {
+			bench = &Benchmark{Pkg: pkg, Name: name, Samples: make(map[string][]float64)}
+			set.byKey[bench.key()] = bench
+			set.Benchmarks = append(set.Benchmarks, bench)
+		}
+		for i := 2; i < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid value %q: %w", line, fields[i], err)
+			}
+			unit := fields[i+1]
+			if _, ok := bench.Samples[unit]; !ok {
+				bench.units = append(bench.units, unit)
+			}
+			bench.Samples[unit] = append(bench.Samples[unit], value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
@@ -0,0 +1,113 @@
+package benchreport
+
+import (
+	"math"
+	"sort"
+)
+
+// Summary describes one benchmark's samples of one unit after outliers are
+// removed, as benchstat reports them: the mean and the largest deviation
+// from it as a fraction of the mean
+type Summary struct {
+	Values []float64 // samples kept, sorted
+	Mean   float64
+	Diff   float64
+}
+
+// N returns the number of samples kept
+func (s Summary) N() int {
+	return len(s.Values)
+}
+
+// Summarize drops samples more than 1.5 interquartile ranges outside the
+// quartiles and summarizes the rest
+func Summarize(samples []float64) Summary {
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	if len(sorted) == 0 {
+		return Summary{}
+	}
+
+	q1, q3 := quantile(sorted, 0.25), quantile(sorted, 0.75)
+	lo, hi := q1-1.5*(q3-q1), q3+1.5*(q3-q1)
+	kept := make([]float64, 0, len(sorted))
+	for _, v := range sorted {
+		if v >= lo && v <= hi {
+			kept = append(kept, v)
+		}
+	}
+
+	sum := 0.0
+	for _, v := range kept {
+		sum += v
+	}
+	summary := Summary{Values: kept, Mean: sum / float64(len(kept))}
+	if summary.Mean != 0 {
+		for _, v := range kept {
+			summary.Diff = math.Max(summary.Diff, math.Abs(v-summary.Mean)/math.Abs(summary.Mean))
+		}
+	}
+	return summary
+}
+
+// quantile interpolates the q quantile of sorted samples
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// MannWhitneyU returns the two-sided p-value of the Mann-Whitney U test that
+// a and b come from the same distribution, the test benchstat uses. It uses
+// the normal approximation with tie and continuity corrections, which is
+// close to the exact test from about 8 samples a side.
+func MannWhitneyU(a, b []float64) float64 {
+	n1, n2 := float64(len(a)), float64(len(b))
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	type sample struct {
+		value float64
+		fromA bool
+	}
+	all := make([]sample, 0, len(a)+len(b))
+	for _, v := range a {
+		all = append(all, sample{v, true})
+	}
+	for _, v := range b {
+		all = append(all, sample{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// Tied samples share the average of their ranks
+	rankSumA, tieTerm := 0.0, 0.0
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromA {
+				rankSumA += rank
+			}
+		}
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+
+	n := n1 + n2
+	u := rankSumA - n1*(n1+1)/2
+	mean := n1 * n2 / 2
+	variance := n1 * n2 / 12 * ((n + 1) - tieTerm/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := math.Max(math.Abs(u-mean)-0.5, 0) / math.Sqrt(variance)
+	return math.Erfc(z / math.Sqrt2)
+}
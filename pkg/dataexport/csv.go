@@ -0,0 +1,39 @@
+package dataexport
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// csvWriter writes a header line followed by one line per row
+type csvWriter struct {
+	schema *Schema
+	w      *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, schema *Schema) (*csvWriter, error) {
+	cw := &csvWriter{schema: schema, w: csv.NewWriter(w)}
+	if err := cw.w.Write(schema.Header()); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) WriteRow(row Row) error {
+	record := make([]string, len(row))
+	for i, v := range row {
+		switch v := v.(type) {
+		case string:
+			record[i] = v
+		case int64:
+			record[i] = strconv.FormatInt(v, 10)
+		}
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
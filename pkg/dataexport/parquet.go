@@ -0,0 +1,330 @@
+package dataexport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strconv"
+)
+
+// The Parquet writer below implements the subset of the format needed for
+// flat datasets: REQUIRED columns, one PLAIN encoded uncompressed data page
+// per column chunk, and the footer in Thrift compact encoding. It uses no
+// feature beyond Parquet 1.0, which every reader supports; TestParquetRoundTrip
+// reads files back with a decoder written from the format spec.
+//
+// Format reference: https://github.com/apache/parquet-format
+
+const parquetMagic = "PAR1"
+
+// SchemaVersionKey is the Parquet key/value metadata entry holding the
+// schema version
+const SchemaVersionKey = "perpdex.schema_version"
+
+// Parquet physical, converted and encoding enum values
+const (
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMillis = 9
+
+	parquetRepetitionRequired = 0
+	parquetEncodingPlain      = 0
+	parquetEncodingRLE        = 3
+	parquetCodecUncompressed  = 0
+	parquetPageTypeData       = 0
+)
+
+// DefaultRowGroupSize is the number of rows buffered per Parquet row group
+const DefaultRowGroupSize = 10000
+
+type parquetColumnChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+type parquetRowGroup struct {
+	chunks  []parquetColumnChunk
+	size    int64
+	numRows int64
+}
+
+// parquetWriter buffers rows column by column and writes a row group every
+// rowGroupSize rows. The footer is written on Close, so a file is only
+// readable once it has been closed.
+type parquetWriter struct {
+	schema       *Schema
+	w            io.Writer
+	offset       int64
+	rowGroupSize int
+
+	columns   []bytes.Buffer
+	rows      int
+	rowGroups []parquetRowGroup
+}
+
+func newParquetWriter(w io.Writer, schema *Schema, rowGroupSize int) (*parquetWriter, error) {
+	if rowGroupSize <= 0 {
+		rowGroupSize = DefaultRowGroupSize
+	}
+	pw := &parquetWriter{
+		schema:       schema,
+		w:            w,
+		rowGroupSize: rowGroupSize,
+		columns:      make([]bytes.Buffer, len(schema.Columns)),
+	}
+	if err := pw.write([]byte(parquetMagic)); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+func (pw *parquetWriter) write(b []byte) error {
+	n, err := pw.w.Write(b)
+	pw.offset += int64(n)
+	return err
+}
+
+func (pw *parquetWriter) WriteRow(row Row) error {
+	for i, v := range row {
+		buf := &pw.columns[i]
+		switch v := v.(type) {
+		case string:
+			var n [4]byte
+			binary.LittleEndian.PutUint32(n[:], uint32(len(v)))
+			buf.Write(n[:])
+			buf.WriteString(v)
+		case int64:
+			var n [8]byte
+			binary.LittleEndian.PutUint64(n[:], uint64(v))
+			buf.Write(n[:])
+		}
+	}
+	pw.rows++
+	if pw.rows >= pw.rowGroupSize {
+		return pw.flushRowGroup()
+	}
+	return nil
+}
+
+// flushRowGroup writes the buffered rows as one row group
+func (pw *parquetWriter) flushRowGroup() error {
+	if pw.rows == 0 {
+		return nil
+	}
+	rg := parquetRowGroup{numRows: int64(pw.rows)}
+	for i := range pw.columns {
+		data := pw.columns[i].Bytes()
+		header := encodePageHeader(len(data), pw.rows)
+
+		chunk := parquetColumnChunk{offset: pw.offset, numValues: int64(pw.rows)}
+		if err := pw.write(header); err != nil {
+			return err
+		}
+		if err := pw.write(data); err != nil {
+			return err
+		}
+		chunk.size = pw.offset - chunk.offset
+		rg.size += chunk.size
+		rg.chunks = append(rg.chunks, chunk)
+		pw.columns[i].Reset()
+	}
+	pw.rowGroups = append(pw.rowGroups, rg)
+	pw.rows = 0
+	return nil
+}
+
+func (pw *parquetWriter) Close() error {
+	if err := pw.flushRowGroup(); err != nil {
+		return err
+	}
+	footer := pw.encodeFileMetaData()
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(footer)))
+	if err := pw.write(footer); err != nil {
+		return err
+	}
+	if err := pw.write(n[:]); err != nil {
+		return err
+	}
+	return pw.write([]byte(parquetMagic))
+}
+
+// encodePageHeader encodes the PageHeader of a PLAIN data page
+func encodePageHeader(size, numValues int) []byte {
+	t := &thriftWriter{}
+	t.i32(1, parquetPageTypeData)
+	t.i32(2, int32(size))
+	t.i32(3, int32(size))
+	t.structField(5, func() {
+		t.i32(1, int32(numValues))
+		t.i32(2, parquetEncodingPlain)
+		t.i32(3, parquetEncodingRLE)
+		t.i32(4, parquetEncodingRLE)
+	})
+	t.stop()
+	return t.buf.Bytes()
+}
+
+// encodeFileMetaData encodes the FileMetaData footer
+func (pw *parquetWriter) encodeFileMetaData() []byte {
+	var numRows int64
+	for _, rg := range pw.rowGroups {
+		numRows += rg.numRows
+	}
+	columns := pw.schema.Columns
+
+	t := &thriftWriter{}
+	t.i32(1, 1)
+	t.structList(2, len(columns)+1, func(i int) {
+		if i == 0 {
+			t.binary(4, pw.schema.Name)
+			t.i32(5, int32(len(columns)))
+			return
+		}
+		col := columns[i-1]
+		t.i32(1, parquetPhysicalType(col.Type))
+		t.i32(3, parquetRepetitionRequired)
+		t.binary(4, col.Name)
+		switch col.Type {
+		case ColumnString:
+			t.i32(6, parquetConvertedUTF8)
+		case ColumnTimestamp:
+			t.i32(6, parquetConvertedTimestampMillis)
+		}
+	})
+	t.i64(3, numRows)
+	t.structList(4, len(pw.rowGroups), func(i int) {
+		rg := pw.rowGroups[i]
+		t.structList(1, len(rg.chunks), func(j int) {
+			chunk := rg.chunks[j]
+			t.i64(2, chunk.offset)
+			t.structField(3, func() {
+				t.i32(1, parquetPhysicalType(columns[j].Type))
+				t.listBegin(2, thriftI32, 1)
+				t.varint(parquetEncodingPlain)
+				t.listBegin(3, thriftBinary, 1)
+				t.rawBinary(columns[j].Name)
+				t.i32(4, parquetCodecUncompressed)
+				t.i64(5, chunk.numValues)
+				t.i64(6, chunk.size)
+				t.i64(7, chunk.size)
+				t.i64(9, chunk.offset)
+			})
+		})
+		t.i64(2, rg.size)
+		t.i64(3, rg.numRows)
+	})
+	t.structList(5, 1, func(int) {
+		t.binary(1, SchemaVersionKey)
+		t.binary(2, strconv.Itoa(pw.schema.Version))
+	})
+	t.binary(6, "perpdex exporter")
+	t.stop()
+	return t.buf.Bytes()
+}
+
+func parquetPhysicalType(t ColumnType) int32 {
+	if t == ColumnString {
+		return parquetTypeByteArray
+	}
+	return parquetTypeInt64
+}
+
+// Thrift compact protocol type IDs
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs in the Thrift compact protocol. Fields must
+// be written in increasing ID order within each struct.
+type thriftWriter struct {
+	buf     bytes.Buffer
+	lastID  int16
+	idStack []int16
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	t.lastID = id
+}
+
+// varint writes a zigzag encoded integer
+func (t *thriftWriter) varint(v int64) {
+	t.uvarint(uint64(v<<1) ^ uint64(v>>63))
+}
+
+func (t *thriftWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	t.buf.Write(b[:n])
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.rawBinary(s)
+}
+
+func (t *thriftWriter) rawBinary(s string) {
+	t.uvarint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) listBegin(id int16, elemType byte, n int) {
+	t.fieldHeader(id, thriftList)
+	if n < 15 {
+		t.buf.WriteByte(byte(n)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xF0 | elemType)
+		t.uvarint(uint64(n))
+	}
+}
+
+// structField writes a nested struct whose fields are written by fn
+func (t *thriftWriter) structField(id int16, fn func()) {
+	t.fieldHeader(id, thriftStruct)
+	t.nested(fn)
+}
+
+// structList writes a list of n structs; fn writes the fields of element i
+func (t *thriftWriter) structList(id int16, n int, fn func(i int)) {
+	t.listBegin(id, thriftStruct, n)
+	for i := 0; i < n; i++ {
+		t.nested(func() { fn(i) })
+	}
+}
+
+func (t *thriftWriter) nested(fn func()) {
+	t.idStack = append(t.idStack, t.lastID)
+	t.lastID = 0
+	fn()
+	t.stop()
+	t.lastID = t.idStack[len(t.idStack)-1]
+	t.idStack = t.idStack[:len(t.idStack)-1]
+}
+
+// stop ends the current struct
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
@@ -0,0 +1,207 @@
+package dataexport
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Format is the file format of an export
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+)
+
+// ParseFormat parses a format name
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatCSV, FormatParquet:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q (expected csv or parquet)", s)
+}
+
+// RotateConfig configures file rotation
+type RotateConfig struct {
+	Dir    string
+	Format Format
+
+	// Interval closes the current file at each interval boundary (UTC),
+	// e.g. every full hour for 1h. Zero disables time based rotation.
+	Interval time.Duration
+
+	// MaxRows closes the current file after this many rows. Zero disables
+	// size based rotation.
+	MaxRows int
+
+	// RowGroupSize is the number of rows per Parquet row group
+	RowGroupSize int
+}
+
+type rowWriter interface {
+	WriteRow(row Row) error
+	Close() error
+}
+
+// RotatingWriter writes the rows of one dataset to a sequence of files in
+// <Dir>/<schema name>/, named <name>-v<version>-<opened at>-<seq>.<format>.
+// A file is written under a .tmp suffix and renamed when it is complete, so
+// consumers never see a partially written file.
+//
+// A Parquet file only gets its footer when it is completed, so the .tmp file
+// left by a crash is unreadable and its rows are lost; a CSV one keeps the
+// rows flushed before the crash. Interval and MaxRows bound how many rows a
+// crash can lose.
+type RotatingWriter struct {
+	mu     sync.Mutex
+	config RotateConfig
+	schema *Schema
+	dir    string
+	now    func() time.Time
+
+	file     *os.File
+	buf      *bufio.Writer
+	w        rowWriter
+	path     string
+	rows     int
+	deadline time.Time
+	seq      int
+}
+
+// NewRotatingWriter creates the dataset directory and writes the schema
+// description next to the data files. No data file is created until the
+// first row is written.
+func NewRotatingWriter(config RotateConfig, schema *Schema) (*RotatingWriter, error) {
+	if _, err := ParseFormat(string(config.Format)); err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(config.Dir, schema.Name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	bz, err := schema.JSON()
+	if err != nil {
+		return nil, err
+	}
+	schemaPath := filepath.Join(dir, fmt.Sprintf("schema-v%d.json", schema.Version))
+	if err := os.WriteFile(schemaPath, bz, 0o644); err != nil {
+		return nil, err
+	}
+	return &RotatingWriter{config: config, schema: schema, dir: dir, now: time.Now}, nil
+}
+
+// Write appends a row, rotating the current file first if it is due
+func (rw *RotatingWriter) Write(row Row) error {
+	if err := rw.schema.Validate(row); err != nil {
+		return err
+	}
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	now := rw.now()
+	if rw.w != nil && rw.due(now) {
+		if err := rw.closeFile(); err != nil {
+			return err
+		}
+	}
+	if rw.w == nil {
+		if err := rw.openFile(now); err != nil {
+			return err
+		}
+	}
+
+	if err := rw.w.WriteRow(row); err != nil {
+		return err
+	}
+	rw.rows++
+	if rw.config.MaxRows > 0 && rw.rows >= rw.config.MaxRows {
+		return rw.closeFile()
+	}
+	return nil
+}
+
+// RotateIfDue closes the current file if its interval has ended. Call it
+// periodically so files are completed even when no rows arrive.
+func (rw *RotatingWriter) RotateIfDue() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.w != nil && rw.due(rw.now()) {
+		return rw.closeFile()
+	}
+	return nil
+}
+
+// Close completes the current file
+func (rw *RotatingWriter) Close() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.w == nil {
+		return nil
+	}
+	return rw.closeFile()
+}
+
+func (rw *RotatingWriter) due(now time.Time) bool {
+	return rw.config.Interval > 0 && !now.Before(rw.deadline)
+}
+
+func (rw *RotatingWriter) openFile(now time.Time) error {
+	now = now.UTC()
+	name := fmt.Sprintf("%s-v%d-%s-%04d.%s",
+		rw.schema.Name, rw.schema.Version, now.Format("20060102T150405Z"), rw.seq, rw.config.Format)
+	path := filepath.Join(rw.dir, name)
+
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(file)
+
+	var w rowWriter
+	switch rw.config.Format {
+	case FormatCSV:
+		w, err = newCSVWriter(buf, rw.schema)
+	case FormatParquet:
+		w, err = newParquetWriter(buf, rw.schema, rw.config.RowGroupSize)
+	}
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	rw.file, rw.buf, rw.w, rw.path = file, buf, w, path
+	rw.rows = 0
+	rw.seq++
+	if rw.config.Interval > 0 {
+		rw.deadline = now.Truncate(rw.config.Interval).Add(rw.config.Interval)
+	}
+	return nil
+}
+
+func (rw *RotatingWriter) closeFile() error {
+	file, buf, w, path := rw.file, rw.buf, rw.w, rw.path
+	rw.file, rw.buf, rw.w, rw.path = nil, nil, nil, ""
+
+	err := w.Close()
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to complete %s: %w", path, err)
+	}
+	return os.Rename(path+".tmp", path)
+}
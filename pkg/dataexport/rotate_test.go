@@ -0,0 +1,104 @@
+package dataexport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func tradeRow(id string) Row {
+	return Row{int64(1700000000000), "BTC-USDC", id, "50000", "0.1", "buy", int64(0)}
+}
+
+// TestRotatingWriterCSV tests interval and row count rotation
+func TestRotatingWriterCSV(t *testing.T) {
+	dir := t.TempDir()
+	rw, err := NewRotatingWriter(RotateConfig{Dir: dir, Format: FormatCSV, Interval: time.Hour, MaxRows: 2}, TradesSchema)
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	now := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+	rw.now = func() time.Time { return now }
+
+	// Two rows fill the first file, the third starts a new one
+	for _, id := range []string{"t1", "t2", "t3"} {
+		if err := rw.Write(tradeRow(id)); err != nil {
+			t.Fatalf("failed to write row: %v", err)
+		}
+	}
+	// The hour ends with the third file still open
+	now = now.Add(30 * time.Minute)
+	if err := rw.RotateIfDue(); err != nil {
+		t.Fatalf("failed to rotate: %v", err)
+	}
+	if err := rw.Write(tradeRow("t4")); err != nil {
+		t.Fatalf("failed to write row: %v", err)
+	}
+	if err := rw.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "trades", "trades-v1-*.csv"))
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %v", files)
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(dir, "trades", "*.tmp")); len(tmp) != 0 {
+		t.Errorf("expected no temporary files, got %v", tmp)
+	}
+	if !strings.Contains(files[2], "20260101T110000Z") {
+		t.Errorf("expected last file opened at 11:00, got %s", files[2])
+	}
+
+	bz, _ := os.ReadFile(files[0])
+	want := "timestamp,market_id,trade_id,price,quantity,side,height\n" +
+		"1700000000000,BTC-USDC,t1,50000,0.1,buy,0\n" +
+		"1700000000000,BTC-USDC,t2,50000,0.1,buy,0\n"
+	if string(bz) != want {
+		t.Errorf("unexpected csv content:\n%s", bz)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "trades", "schema-v1.json")); err != nil {
+		t.Errorf("expected schema description: %v", err)
+	}
+	if err := rw.Write(Row{"BTC-USDC"}); err == nil {
+		t.Error("expected a row not matching the schema to be rejected")
+	}
+}
+
+// TestParquetWriterLayout tests the file layout and footer of a Parquet file
+func TestParquetWriterLayout(t *testing.T) {
+	var buf bytes.Buffer
+	pw, err := newParquetWriter(&buf, TradesSchema, 2)
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	for _, id := range []string{"t1", "t2", "t3"} {
+		if err := pw.WriteRow(tradeRow(id)); err != nil {
+			t.Fatalf("failed to write row: %v", err)
+		}
+	}
+	if err := pw.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	bz := buf.Bytes()
+	if !bytes.HasPrefix(bz, []byte(parquetMagic)) || !bytes.HasSuffix(bz, []byte(parquetMagic)) {
+		t.Fatal("expected PAR1 magic at both ends")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(bz[len(bz)-8:]))
+	footerStart := len(bz) - 8 - footerLen
+	if footerStart != int(pw.offset)-8-footerLen || footerStart <= len(parquetMagic) {
+		t.Fatalf("unexpected footer length %d", footerLen)
+	}
+	if len(pw.rowGroups) != 2 || pw.rowGroups[0].numRows != 2 || pw.rowGroups[1].numRows != 1 {
+		t.Errorf("expected row groups of 2 and 1 rows, got %+v", pw.rowGroups)
+	}
+	footer := bz[footerStart : len(bz)-8]
+	if !bytes.Contains(footer, []byte(SchemaVersionKey)) {
+		t.Error("expected schema version in footer metadata")
+	}
+}
@@ -0,0 +1,121 @@
+// Package dataexport writes market data records to rotating CSV or Parquet
+// files with a versioned schema, for building research datasets
+package dataexport
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ColumnType is the type of a schema column
+type ColumnType string
+
+const (
+	// ColumnString holds text and decimal values. Decimals stay strings so
+	// no precision is lost; cast them when loading the dataset.
+	ColumnString ColumnType = "string"
+	// ColumnInt64 holds integer values
+	ColumnInt64 ColumnType = "int64"
+	// ColumnTimestamp holds Unix milliseconds
+	ColumnTimestamp ColumnType = "timestamp_ms"
+)
+
+// Column is a named, typed schema column
+type Column struct {
+	Name string     `json:"name"`
+	Type ColumnType `json:"type"`
+}
+
+// Schema describes the rows of one dataset. Version is bumped whenever
+// columns are added, removed or change meaning, and is part of every file
+// name so files of different versions are never mixed.
+type Schema struct {
+	Name    string   `json:"name"`
+	Version int      `json:"version"`
+	Columns []Column `json:"columns"`
+}
+
+// Datasets exported by cmd/exporter
+var (
+	TradesSchema = &Schema{
+		Name:    "trades",
+		Version: 1,
+		Columns: []Column{
+			{"timestamp", ColumnTimestamp},
+			{"market_id", ColumnString},
+			{"trade_id", ColumnString},
+			{"price", ColumnString},
+			{"quantity", ColumnString},
+			{"side", ColumnString},
+			{"height", ColumnInt64},
+		},
+	}
+
+	// OrderbookSchema stores one row per price level of a snapshot; rows of
+	// the same snapshot share market_id and timestamp
+	OrderbookSchema = &Schema{
+		Name:    "orderbook",
+		Version: 1,
+		Columns: []Column{
+			{"timestamp", ColumnTimestamp},
+			{"market_id", ColumnString},
+			{"side", ColumnString},
+			{"level", ColumnInt64},
+			{"price", ColumnString},
+			{"quantity", ColumnString},
+			{"checksum", ColumnInt64},
+		},
+	}
+
+	FundingSchema = &Schema{
+		Name:    "funding",
+		Version: 1,
+		Columns: []Column{
+			{"timestamp", ColumnTimestamp},
+			{"market_id", ColumnString},
+			{"funding_rate", ColumnString},
+			{"mark_price", ColumnString},
+			{"index_price", ColumnString},
+			{"next_funding", ColumnTimestamp},
+			{"height", ColumnInt64},
+		},
+	}
+)
+
+// Row is one record. Values follow the schema's column order: a string for
+// ColumnString and an int64 for ColumnInt64 and ColumnTimestamp.
+type Row []interface{}
+
+// Validate checks that row matches the schema's columns
+func (s *Schema) Validate(row Row) error {
+	if len(row) != len(s.Columns) {
+		return fmt.Errorf("%s v%d: expected %d values, got %d", s.Name, s.Version, len(s.Columns), len(row))
+	}
+	for i, col := range s.Columns {
+		var ok bool
+		switch col.Type {
+		case ColumnString:
+			_, ok = row[i].(string)
+		case ColumnInt64, ColumnTimestamp:
+			_, ok = row[i].(int64)
+		}
+		if !ok {
+			return fmt.Errorf("%s v%d: column %s expects %s, got %T", s.Name, s.Version, col.Name, col.Type, row[i])
+		}
+	}
+	return nil
+}
+
+// Header returns the column names
+func (s *Schema) Header() []string {
+	names := make([]string, len(s.Columns))
+	for i, col := range s.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// JSON returns the schema description written next to the data files
+func (s *Schema) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
@@ -0,0 +1,63 @@
+package klines
+
+import (
+	"context"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// Trade is one trade of the trade journal
+type Trade struct {
+	MarketID string
+	Price    math.LegacyDec
+	Quantity math.LegacyDec
+	Time     time.Time
+}
+
+// Backfiller rebuilds minute candles from the trade journal. Unlike the
+// Recorder it replaces the stored candle of each minute it covers rather
+// than adding to it, so a backfill can be run again over the same trades.
+type Backfiller struct {
+	store   Store
+	open    map[string]*Candle // market -> minute candle being built
+	written int
+}
+
+// NewBackfiller creates a backfiller writing to store
+func NewBackfiller(store Store) *Backfiller {
+	return &Backfiller{store: store, open: make(map[string]*Candle)}
+}
+
+// Add adds a trade. Trades of a market must come oldest first; its minute
+// candle is written once a trade of a later minute arrives.
+func (b *Backfiller) Add(ctx context.Context, trade Trade) error {
+	start := Minute.Start(trade.Time)
+	candle := b.open[trade.MarketID]
+	if candle != nil && candle.Time == start {
+		candle.AddTrade(trade.Price, trade.Quantity)
+		return nil
+	}
+	if candle != nil {
+		if err := b.store.Put(ctx, candle); err != nil {
+			return err
+		}
+		b.written++
+	}
+	b.open[trade.MarketID] = NewCandle(trade.MarketID, Minute, start, trade.Price, trade.Quantity)
+	return nil
+}
+
+// Flush writes the candles still being built and returns how many candles
+// the backfill has written. The last minute of each market is complete only
+// if the journal holds all its trades.
+func (b *Backfiller) Flush(ctx context.Context) (int, error) {
+	for marketID, candle := range b.open {
+		if err := b.store.Put(ctx, candle); err != nil {
+			return b.written, err
+		}
+		b.written++
+		delete(b.open, marketID)
+	}
+	return b.written, nil
+}
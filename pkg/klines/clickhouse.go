@@ -0,0 +1,131 @@
+package klines
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// clickHouseSchema creates the candle table. ReplacingMergeTree keeps the
+// row with the latest version per market, interval and start, so Put can
+// insert an updated candle without a read; queries use FINAL to see only
+// that row. Decimals are stored as strings to keep 18 decimal places.
+const clickHouseSchema = `CREATE TABLE IF NOT EXISTS klines (
+	market_id LowCardinality(String),
+	interval LowCardinality(String),
+	time DateTime,
+	open String,
+	high String,
+	low String,
+	close String,
+	volume String,
+	turnover String,
+	trades Int64,
+	version UInt64
+) ENGINE = ReplacingMergeTree(version)
+ORDER BY (market_id, interval, time)`
+
+// ClickHouseStore keeps candles in an external ClickHouse table, for
+// deployments that query candles from analytics tools too
+type ClickHouseStore struct {
+	db *sql.DB
+}
+
+// OpenClickHouse connects to ClickHouse through the named database/sql
+// driver and creates the candle table if needed
+func OpenClickHouse(driver, dsn string) (*ClickHouseStore, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open clickhouse kline store (is the %q driver linked in?): %w", driver, err)
+	}
+	if _, err := db.Exec(clickHouseSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create clickhouse kline table: %w", err)
+	}
+	return &ClickHouseStore{db: db}, nil
+}
+
+// Put implements Store
+func (c *ClickHouseStore) Put(ctx context.Context, candles ...*Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO klines
+		(market_id, interval, time, open, high, low, close, volume, turnover, trades, version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	version := uint64(time.Now().UnixNano())
+	for _, k := range candles {
+		if _, err := stmt.ExecContext(ctx, k.MarketID, string(k.Interval), time.Unix(k.Time, 0).UTC(),
+			k.Open.String(), k.High.String(), k.Low.String(), k.Close.String(),
+			k.Volume.String(), k.Turnover.String(), k.Trades, version); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Range implements Store
+func (c *ClickHouseStore) Range(ctx context.Context, marketID string, interval Interval, from, to int64) ([]*Candle, error) {
+	rows, err := c.db.QueryContext(ctx, `SELECT time, open, high, low, close, volume, turnover, trades
+		FROM klines FINAL
+		WHERE market_id = ? AND interval = ? AND time >= ? AND time < ?
+		ORDER BY time`,
+		marketID, string(interval), time.Unix(from, 0).UTC(), time.Unix(to, 0).UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*Candle, 0)
+	for rows.Next() {
+		var (
+			start                                   time.Time
+			open, high, low, last, volume, turnover string
+			trades                                  int64
+		)
+		if err := rows.Scan(&start, &open, &high, &low, &last, &volume, &turnover, &trades); err != nil {
+			return nil, err
+		}
+		candle := &Candle{MarketID: marketID, Interval: interval, Time: start.Unix(), Trades: trades}
+		for _, field := range []struct {
+			dst *math.LegacyDec
+			src string
+		}{
+			{&candle.Open, open}, {&candle.High, high}, {&candle.Low, low}, {&candle.Close, last},
+			{&candle.Volume, volume}, {&candle.Turnover, turnover},
+		} {
+			if *field.dst, err = math.LegacyNewDecFromStr(field.src); err != nil {
+				return nil, fmt.Errorf("corrupt candle %s %s %d: %w", marketID, interval, candle.Time, err)
+			}
+		}
+		result = append(result, candle)
+	}
+	return result, rows.Err()
+}
+
+// DeleteBefore implements Store. ClickHouse applies the delete as an
+// asynchronous mutation.
+func (c *ClickHouseStore) DeleteBefore(ctx context.Context, marketID string, interval Interval, before int64) error {
+	_, err := c.db.ExecContext(ctx, `ALTER TABLE klines DELETE WHERE market_id = ? AND interval = ? AND time < ?`,
+		marketID, string(interval), time.Unix(before, 0).UTC())
+	return err
+}
+
+// Close implements Store
+func (c *ClickHouseStore) Close() error {
+	return c.db.Close()
+}
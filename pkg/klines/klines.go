@@ -0,0 +1,138 @@
+// Package klines persists candles outside the chain state: minute candles
+// built from trades, downsampled to hourly and daily candles, each kept for
+// its own retention period in a pluggable store (in memory, embedded Pebble
+// or ClickHouse)
+package klines
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// Interval is a stored candle interval. Other chart intervals are merged
+// from these on read.
+type Interval string
+
+const (
+	Minute Interval = "1m"
+	Hour   Interval = "1h"
+	Day    Interval = "1d"
+)
+
+// Duration returns the length of one candle
+func (i Interval) Duration() time.Duration {
+	switch i {
+	case Hour:
+		return time.Hour
+	case Day:
+		return 24 * time.Hour
+	default:
+		return time.Minute
+	}
+}
+
+// Start returns the start (Unix seconds) of the candle containing t
+func (i Interval) Start(t time.Time) int64 {
+	return t.Truncate(i.Duration()).Unix()
+}
+
+// ParseInterval parses a stored interval name
+func ParseInterval(s string) (Interval, error) {
+	switch i := Interval(s); i {
+	case Minute, Hour, Day:
+		return i, nil
+	}
+	return "", fmt.Errorf("unknown kline interval %q (expected 1m, 1h or 1d)", s)
+}
+
+// Candle is one OHLCV candle of a market
+type Candle struct {
+	MarketID string         `json:"market_id"`
+	Interval Interval       `json:"interval"`
+	Time     int64          `json:"time"` // start of the candle, Unix seconds
+	Open     math.LegacyDec `json:"open"`
+	High     math.LegacyDec `json:"high"`
+	Low      math.LegacyDec `json:"low"`
+	Close    math.LegacyDec `json:"close"`
+	Volume   math.LegacyDec `json:"volume"`   // base asset
+	Turnover math.LegacyDec `json:"turnover"` // quote asset
+	Trades   int64          `json:"trades"`
+}
+
+// NewCandle opens a candle with its first trade
+func NewCandle(marketID string, interval Interval, start int64, price, quantity math.LegacyDec) *Candle {
+	return &Candle{
+		MarketID: marketID,
+		Interval: interval,
+		Time:     start,
+		Open:     price,
+		High:     price,
+		Low:      price,
+		Close:    price,
+		Volume:   quantity,
+		Turnover: price.Mul(quantity),
+		Trades:   1,
+	}
+}
+
+// AddTrade adds a later trade to the candle
+func (c *Candle) AddTrade(price, quantity math.LegacyDec) {
+	if price.GT(c.High) {
+		c.High = price
+	}
+	if price.LT(c.Low) {
+		c.Low = price
+	}
+	c.Close = price
+	c.Volume = c.Volume.Add(quantity)
+	c.Turnover = c.Turnover.Add(price.Mul(quantity))
+	c.Trades++
+}
+
+// Merge combines consecutive candles, oldest first, into one candle of
+// interval starting at start
+func Merge(candles []*Candle, interval Interval, start int64) *Candle {
+	first := candles[0]
+	merged := &Candle{
+		MarketID: first.MarketID,
+		Interval: interval,
+		Time:     start,
+		Open:     first.Open,
+		High:     first.High,
+		Low:      first.Low,
+		Close:    candles[len(candles)-1].Close,
+		Volume:   math.LegacyZeroDec(),
+		Turnover: math.LegacyZeroDec(),
+	}
+	for _, c := range candles {
+		if c.High.GT(merged.High) {
+			merged.High = c.High
+		}
+		if c.Low.LT(merged.Low) {
+			merged.Low = c.Low
+		}
+		merged.Volume = merged.Volume.Add(c.Volume)
+		merged.Turnover = merged.Turnover.Add(c.Turnover)
+		merged.Trades += c.Trades
+	}
+	return merged
+}
+
+// Resample groups candles, oldest first, into candles of duration d (a
+// multiple of their own), each starting at a multiple of d
+func Resample(candles []*Candle, d time.Duration, interval Interval) []*Candle {
+	step := int64(d.Seconds())
+	result := make([]*Candle, 0)
+	for i := 0; i < len(candles); {
+		start := candles[i].Time - candles[i].Time%step
+		j := i
+		for j < len(candles) && candles[j].Time < start+step {
+			j++
+		}
+		result = append(result, Merge(candles[i:j], interval, start))
+		i = j
+	}
+	return result
+}
@@ -0,0 +1,132 @@
+package klines
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+func dec(s string) math.LegacyDec {
+	return math.LegacyMustNewDecFromStr(s)
+}
+
+// TestRecorderCompaction tests minute recording, downsampling to hours and
+// days, reads across the compacted and open spans, and retention
+func TestRecorderCompaction(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	rec := NewRecorder(store)
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Two trades in 00:00, one in 00:01 and one in 01:30
+	trades := []struct {
+		at         time.Time
+		price, qty string
+	}{
+		{day.Add(10 * time.Second), "100", "1"},
+		{day.Add(50 * time.Second), "110", "2"},
+		{day.Add(70 * time.Second), "90", "1"},
+		{day.Add(90 * time.Minute), "120", "1"},
+	}
+	for _, tr := range trades {
+		if err := rec.Record(ctx, "BTC-USDC", dec(tr.price), dec(tr.qty), tr.at); err != nil {
+			t.Fatalf("failed to record: %v", err)
+		}
+	}
+	if err := rec.Flush(ctx); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	minutes, _ := store.Range(ctx, "BTC-USDC", Minute, 0, day.Add(24*time.Hour).Unix())
+	if len(minutes) != 3 || minutes[0].Trades != 2 || !minutes[0].Close.Equal(dec("110")) || !minutes[0].Volume.Equal(dec("3")) {
+		t.Fatalf("unexpected minute candles %+v", minutes)
+	}
+
+	// A restarted recorder adds to the stored minute instead of replacing it
+	rec = NewRecorder(store)
+	if err := rec.Record(ctx, "BTC-USDC", dec("80"), dec("1"), day.Add(20*time.Second)); err != nil {
+		t.Fatalf("failed to record: %v", err)
+	}
+	if err := rec.Flush(ctx); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	minutes, _ = store.Range(ctx, "BTC-USDC", Minute, day.Unix(), day.Unix()+60)
+	if minutes[0].Trades != 3 || !minutes[0].Low.Equal(dec("80")) {
+		t.Fatalf("expected the stored minute to be extended, got %+v", minutes[0])
+	}
+
+	// At 02:10 hours 00:00 and 01:00 are closed; the day is not
+	now := day.Add(130 * time.Minute)
+	compactor := NewCompactor(store, Policy{Minute: time.Hour, Hour: 0, Day: 0})
+	if err := compactor.Run(ctx, []string{"BTC-USDC"}, now); err != nil {
+		t.Fatalf("failed to compact: %v", err)
+	}
+	hours, _ := store.Range(ctx, "BTC-USDC", Hour, 0, now.Unix())
+	if len(hours) != 2 || hours[0].Trades != 4 || hours[1].Time != day.Add(time.Hour).Unix() {
+		t.Fatalf("expected hours 00:00 and 01:00, got %+v", hours)
+	}
+	if days, _ := store.Range(ctx, "BTC-USDC", Day, 0, now.Unix()); len(days) != 0 {
+		t.Fatalf("expected no daily candle before the day closes, got %+v", days)
+	}
+	// Minutes before 01:10 are past retention
+	if minutes, _ = store.Range(ctx, "BTC-USDC", Minute, 0, now.Unix()); len(minutes) != 1 {
+		t.Fatalf("expected 1 minute candle left, got %+v", minutes)
+	}
+
+	// A daily read merges the stored hour and the minutes after it
+	candles, err := Query(ctx, store, "BTC-USDC", 24*time.Hour, day.Unix(), now.Unix())
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+	if len(candles) != 1 || candles[0].Time != day.Unix() || candles[0].Trades != 5 || candles[0].Interval != Day {
+		t.Fatalf("unexpected daily candles %+v", candles)
+	}
+
+	// 4h candles are merged from hours
+	if Base(4*time.Hour) != Hour || Base(15*time.Minute) != Minute {
+		t.Fatalf("unexpected base intervals")
+	}
+	candles, _ = Query(ctx, store, "BTC-USDC", 4*time.Hour, day.Unix(), now.Unix())
+	if len(candles) != 1 || candles[0].Interval != "4h" || !candles[0].Close.Equal(dec("120")) {
+		t.Fatalf("unexpected 4h candles %+v", candles)
+	}
+}
+
+// TestBackfill tests that a backfill replaces the minutes it covers and can
+// be run again
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	trades := []Trade{
+		{"BTC-USDC", dec("100"), dec("1"), start},
+		{"ETH-USDC", dec("10"), dec("5"), start.Add(5 * time.Second)},
+		{"BTC-USDC", dec("105"), dec("1"), start.Add(30 * time.Second)},
+		{"BTC-USDC", dec("95"), dec("2"), start.Add(2 * time.Minute)},
+	}
+
+	for run := 0; run < 2; run++ {
+		b := NewBackfiller(store)
+		for _, trade := range trades {
+			if err := b.Add(ctx, trade); err != nil {
+				t.Fatalf("failed to add trade: %v", err)
+			}
+		}
+		written, err := b.Flush(ctx)
+		if err != nil {
+			t.Fatalf("failed to flush: %v", err)
+		}
+		if written != 3 {
+			t.Fatalf("expected 3 candles written, got %d", written)
+		}
+	}
+
+	btc, _ := store.Range(ctx, "BTC-USDC", Minute, 0, start.Add(time.Hour).Unix())
+	if len(btc) != 2 || btc[0].Trades != 2 || !btc[0].High.Equal(dec("105")) || !btc[0].Turnover.Equal(dec("205")) {
+		t.Fatalf("unexpected BTC candles %+v", btc)
+	}
+	if eth, _ := store.Range(ctx, "ETH-USDC", Minute, 0, start.Add(time.Hour).Unix()); len(eth) != 1 || eth[0].Trades != 1 {
+		t.Fatalf("unexpected ETH candles %+v", eth)
+	}
+}
@@ -0,0 +1,89 @@
+package klines
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// PebbleStore keeps candles in an embedded Pebble database, under
+// market/interval/start keys with the start big-endian so a market's
+// candles of one interval sort by time
+type PebbleStore struct {
+	db *pebble.DB
+}
+
+// OpenPebble opens or creates a Pebble kline store in dir
+func OpenPebble(dir string) (*PebbleStore, error) {
+	db, err := pebble.Open(dir, &pebble.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pebble kline store: %w", err)
+	}
+	return &PebbleStore{db: db}, nil
+}
+
+// pebblePrefix is the key prefix of a market's candles of one interval
+func pebblePrefix(marketID string, interval Interval) []byte {
+	return []byte(marketID + "/" + string(interval) + "/")
+}
+
+func pebbleKey(marketID string, interval Interval, start int64) []byte {
+	return binary.BigEndian.AppendUint64(pebblePrefix(marketID, interval), uint64(start))
+}
+
+// Put implements Store
+func (p *PebbleStore) Put(ctx context.Context, candles ...*Candle) error {
+	batch := p.db.NewBatch()
+	defer batch.Close()
+	for _, c := range candles {
+		bz, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		if err := batch.Set(pebbleKey(c.MarketID, c.Interval, c.Time), bz, nil); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+// Range implements Store
+func (p *PebbleStore) Range(ctx context.Context, marketID string, interval Interval, from, to int64) ([]*Candle, error) {
+	if from < 0 {
+		from = 0
+	}
+	iter, err := p.db.NewIter(&pebble.IterOptions{
+		LowerBound: pebbleKey(marketID, interval, from),
+		UpperBound: pebbleKey(marketID, interval, to),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	result := make([]*Candle, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		var c Candle
+		if err := json.Unmarshal(iter.Value(), &c); err != nil {
+			return nil, fmt.Errorf("corrupt candle %x: %w", iter.Key(), err)
+		}
+		result = append(result, &c)
+	}
+	return result, iter.Error()
+}
+
+// DeleteBefore implements Store
+func (p *PebbleStore) DeleteBefore(ctx context.Context, marketID string, interval Interval, before int64) error {
+	if before <= 0 {
+		return nil
+	}
+	return p.db.DeleteRange(pebblePrefix(marketID, interval), pebbleKey(marketID, interval, before), pebble.Sync)
+}
+
+// Close implements Store
+func (p *PebbleStore) Close() error {
+	return p.db.Close()
+}
@@ -0,0 +1,79 @@
+package klines
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Base returns the stored interval a chart interval of duration d is merged
+// from: the coarsest one that divides it
+func Base(d time.Duration) Interval {
+	for _, interval := range []Interval{Day, Hour} {
+		if d%interval.Duration() == 0 {
+			return interval
+		}
+	}
+	return Minute
+}
+
+// Query returns a market's candles of duration d starting in [from, to)
+// (Unix seconds), oldest first. They are merged from the stored interval
+// Base picks; the span the compactor has not downsampled yet, such as the
+// current hour, is filled in from the next finer interval.
+func Query(ctx context.Context, store Store, marketID string, d time.Duration, from, to int64) ([]*Candle, error) {
+	interval := Base(d)
+	step := int64(d.Seconds())
+	from -= from % step
+	candles, err := load(ctx, store, marketID, interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if d == interval.Duration() {
+		return candles, nil
+	}
+	return Resample(candles, d, Interval(formatDuration(d))), nil
+}
+
+// load reads the stored candles of interval and fills the span after the
+// last of them from the finer interval
+func load(ctx context.Context, store Store, marketID string, interval Interval, from, to int64) ([]*Candle, error) {
+	candles, err := store.Range(ctx, marketID, interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var finer Interval
+	switch interval {
+	case Day:
+		finer = Hour
+	case Hour:
+		finer = Minute
+	default:
+		return candles, nil
+	}
+
+	tail := from
+	if len(candles) > 0 {
+		tail = candles[len(candles)-1].Time + int64(interval.Duration().Seconds())
+	}
+	if tail >= to {
+		return candles, nil
+	}
+	rest, err := load(ctx, store, marketID, finer, tail, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(candles, Resample(rest, interval.Duration(), interval)...), nil
+}
+
+// formatDuration names a chart interval the way the API does: 5m, 4h, 1d
+func formatDuration(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return strconv.FormatInt(int64(d/(24*time.Hour)), 10) + "d"
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	default:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	}
+}
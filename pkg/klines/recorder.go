@@ -0,0 +1,96 @@
+package klines
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// Recorder builds minute candles from trades as they happen. Updated candles
+// are held in memory until Flush writes them, so a busy market costs one
+// store write per flush rather than one per trade.
+type Recorder struct {
+	store Store
+
+	mu    sync.Mutex
+	open  map[string]*Candle // market -> latest minute candle
+	dirty map[string]*Candle // market/start -> candle changed since the last flush
+}
+
+// NewRecorder creates a recorder writing to store
+func NewRecorder(store Store) *Recorder {
+	return &Recorder{
+		store: store,
+		open:  make(map[string]*Candle),
+		dirty: make(map[string]*Candle),
+	}
+}
+
+// Record adds a trade to the minute candle it falls in. A trade for a minute
+// not in memory, after a restart or arriving late, is added to the stored
+// candle of that minute if there is one.
+func (r *Recorder) Record(ctx context.Context, marketID string, price, quantity math.LegacyDec, at time.Time) error {
+	start := Minute.Start(at)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	candle := r.open[marketID]
+	if candle == nil || candle.Time != start {
+		candle = r.dirty[dirtyKey(marketID, start)]
+	}
+	if candle == nil {
+		stored, err := r.store.Range(ctx, marketID, Minute, start, start+1)
+		if err != nil {
+			return err
+		}
+		if len(stored) > 0 {
+			candle = stored[0]
+		}
+	}
+
+	if candle == nil {
+		candle = NewCandle(marketID, Minute, start, price, quantity)
+	} else {
+		candle.AddTrade(price, quantity)
+	}
+	if open := r.open[marketID]; open == nil || start >= open.Time {
+		r.open[marketID] = candle
+	}
+	r.dirty[dirtyKey(marketID, start)] = candle
+	return nil
+}
+
+// Flush writes the candles changed since the last flush
+func (r *Recorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.dirty) == 0 {
+		return nil
+	}
+	candles := make([]*Candle, 0, len(r.dirty))
+	for _, c := range r.dirty {
+		candles = append(candles, c)
+	}
+	if err := r.store.Put(ctx, candles...); err != nil {
+		return err
+	}
+	r.dirty = make(map[string]*Candle)
+	return nil
+}
+
+// Markets returns the markets a trade has been recorded for
+func (r *Recorder) Markets() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	markets := make([]string, 0, len(r.open))
+	for marketID := range r.open {
+		markets = append(markets, marketID)
+	}
+	return markets
+}
+
+func dirtyKey(marketID string, start int64) string {
+	return memoryKey(marketID, Minute) + "/" + time.Unix(start, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,104 @@
+package klines
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Policy sets how long candles of each stored interval are kept. Zero keeps
+// them forever.
+type Policy struct {
+	Minute time.Duration
+	Hour   time.Duration
+	Day    time.Duration
+}
+
+// Default retention periods
+const (
+	DefaultMinuteRetention = 7 * 24 * time.Hour
+	DefaultHourRetention   = 365 * 24 * time.Hour
+)
+
+// DefaultPolicy keeps minute candles a week, hourly candles a year and
+// daily candles forever
+func DefaultPolicy() Policy {
+	return Policy{Minute: DefaultMinuteRetention, Hour: DefaultHourRetention}
+}
+
+// Retention returns how long candles of interval are kept
+func (p Policy) Retention(interval Interval) time.Duration {
+	switch interval {
+	case Hour:
+		return p.Hour
+	case Day:
+		return p.Day
+	default:
+		return p.Minute
+	}
+}
+
+// Compactor downsamples closed minute candles into hourly candles and closed
+// hourly candles into daily ones, then deletes candles past their retention
+type Compactor struct {
+	store  Store
+	policy Policy
+
+	mu   sync.Mutex
+	done map[string]int64 // market/target interval -> start of the first candle not yet built
+}
+
+// NewCompactor creates a compactor over store
+func NewCompactor(store Store, policy Policy) *Compactor {
+	return &Compactor{store: store, policy: policy, done: make(map[string]int64)}
+}
+
+// Run compacts the candles of the given markets as of now. The first run
+// after a start rebuilds every closed candle from all stored candles of the
+// finer interval, so backfilled candles are downsampled before retention
+// drops them; later runs only build the candles closed since.
+func (c *Compactor) Run(ctx context.Context, markets []string, now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, marketID := range markets {
+		if err := c.downsample(ctx, marketID, Minute, Hour, now); err != nil {
+			return err
+		}
+		if err := c.downsample(ctx, marketID, Hour, Day, now); err != nil {
+			return err
+		}
+		for _, interval := range []Interval{Minute, Hour, Day} {
+			keep := c.policy.Retention(interval)
+			if keep <= 0 {
+				continue
+			}
+			if err := c.store.DeleteBefore(ctx, marketID, interval, now.Add(-keep).Unix()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// downsample builds the closed target candles of a market from its source
+// candles
+func (c *Compactor) downsample(ctx context.Context, marketID string, source, target Interval, now time.Time) error {
+	end := target.Start(now)
+	key := memoryKey(marketID, target)
+	from := c.done[key]
+	if from >= end {
+		return nil
+	}
+
+	candles, err := c.store.Range(ctx, marketID, source, from, end)
+	if err != nil {
+		return err
+	}
+	if len(candles) > 0 {
+		if err := c.store.Put(ctx, Resample(candles, target.Duration(), target)...); err != nil {
+			return err
+		}
+	}
+	c.done[key] = end
+	return nil
+}
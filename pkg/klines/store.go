@@ -0,0 +1,111 @@
+package klines
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store persists candles by market, interval and start time
+type Store interface {
+	// Put writes candles, replacing any stored with the same market,
+	// interval and start time
+	Put(ctx context.Context, candles ...*Candle) error
+
+	// Range returns the candles starting in [from, to) (Unix seconds),
+	// oldest first
+	Range(ctx context.Context, marketID string, interval Interval, from, to int64) ([]*Candle, error)
+
+	// DeleteBefore removes the candles starting before the given time
+	DeleteBefore(ctx context.Context, marketID string, interval Interval, before int64) error
+
+	Close() error
+}
+
+// Open opens the store a spec names: "memory", "pebble:<dir>" or
+// "clickhouse:<dsn>". ClickHouse needs a database/sql driver registered as
+// "clickhouse", such as github.com/ClickHouse/clickhouse-go/v2, linked into
+// the binary.
+func Open(spec string) (Store, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "memory":
+		return NewMemoryStore(), nil
+	case "pebble":
+		if arg == "" {
+			return nil, fmt.Errorf("pebble kline store needs a directory, e.g. pebble:/var/lib/perpdex/klines")
+		}
+		return OpenPebble(arg)
+	case "clickhouse":
+		if arg == "" {
+			return nil, fmt.Errorf("clickhouse kline store needs a DSN, e.g. clickhouse:clickhouse://localhost:9000/perpdex")
+		}
+		return OpenClickHouse("clickhouse", arg)
+	}
+	return nil, fmt.Errorf("unknown kline store %q (expected memory, pebble:<dir> or clickhouse:<dsn>)", spec)
+}
+
+// MemoryStore keeps candles in memory, for tests and single process setups
+// that do not need them across restarts
+type MemoryStore struct {
+	mu      sync.RWMutex
+	candles map[string]map[int64]*Candle // market/interval -> start -> candle
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{candles: make(map[string]map[int64]*Candle)}
+}
+
+func memoryKey(marketID string, interval Interval) string {
+	return marketID + "/" + string(interval)
+}
+
+// Put implements Store
+func (m *MemoryStore) Put(ctx context.Context, candles ...*Candle) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range candles {
+		key := memoryKey(c.MarketID, c.Interval)
+		if m.candles[key] == nil {
+			m.candles[key] = make(map[int64]*Candle)
+		}
+		stored := *c
+		m.candles[key][c.Time] = &stored
+	}
+	return nil
+}
+
+// Range implements Store
+func (m *MemoryStore) Range(ctx context.Context, marketID string, interval Interval, from, to int64) ([]*Candle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*Candle, 0)
+	for start, c := range m.candles[memoryKey(marketID, interval)] {
+		if start >= from && start < to {
+			stored := *c
+			result = append(result, &stored)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Time < result[j].Time })
+	return result, nil
+}
+
+// DeleteBefore implements Store
+func (m *MemoryStore) DeleteBefore(ctx context.Context, marketID string, interval Interval, before int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for start := range m.candles[memoryKey(marketID, interval)] {
+		if start < before {
+			delete(m.candles[memoryKey(marketID, interval)], start)
+		}
+	}
+	return nil
+}
+
+// Close implements Store
+func (m *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,108 @@
+// Package timing captures how long each stage of a request takes, from the
+// API handler down to the keepers, to attribute order latency
+package timing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Header requests a breakdown when sent with any value, and carries it in the
+// response, in Server-Timing syntax: "decode;dur=0.021, match;dur=0.153".
+// Durations are milliseconds.
+const Header = "X-Debug-Timings"
+
+// Order placement stages
+const (
+	StageDecode   = "decode"   // JSON request decode
+	StageValidate = "validate" // field, market rule, schedule and MMP checks
+	StageMargin   = "margin"   // margin requirement check
+	StageMatch    = "match"    // matching and resting the remainder
+	StagePersist  = "persist"  // flushing engine state to the store
+	StageEncode   = "encode"   // JSON response encode
+)
+
+// Stage is the time spent in one stage of a request
+type Stage struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Recorder accumulates stage durations for one request. A nil Recorder
+// ignores everything, so callers need not check whether timing is on.
+type Recorder struct {
+	mu     sync.Mutex
+	debug  bool
+	stages []Stage
+}
+
+// NewRecorder creates a recorder; debug is whether the client asked for the
+// breakdown in the response
+func NewRecorder(debug bool) *Recorder {
+	return &Recorder{debug: debug}
+}
+
+type contextKey struct{}
+
+// WithRecorder returns a context carrying r
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the context's recorder, or nil
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(contextKey{}).(*Recorder)
+	return r
+}
+
+// Add adds d to a stage, in order of first appearance
+func (r *Recorder) Add(name string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.stages {
+		if r.stages[i].Name == name {
+			r.stages[i].Duration += d
+			return
+		}
+	}
+	r.stages = append(r.stages, Stage{Name: name, Duration: d})
+}
+
+// Since adds the time elapsed since start to a stage
+func (r *Recorder) Since(name string, start time.Time) {
+	if r == nil {
+		return
+	}
+	r.Add(name, time.Since(start))
+}
+
+// Debug reports whether the client asked for the breakdown
+func (r *Recorder) Debug() bool {
+	return r != nil && r.debug
+}
+
+// Stages returns the recorded stages in order
+func (r *Recorder) Stages() []Stage {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Stage(nil), r.stages...)
+}
+
+// String formats the stages for the response header
+func (r *Recorder) String() string {
+	stages := r.Stages()
+	parts := make([]string, len(stages))
+	for i, stage := range stages {
+		parts[i] = fmt.Sprintf("%s;dur=%.3f", stage.Name, float64(stage.Duration)/float64(time.Millisecond))
+	}
+	return strings.Join(parts, ", ")
+}
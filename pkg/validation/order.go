@@ -0,0 +1,185 @@
+// Package validation provides order validation rules shared by the API layer and keepers
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"cosmossdk.io/math"
+)
+
+// MaxDecimals is the maximum number of fractional digits accepted for any decimal input
+const MaxDecimals = math.LegacyPrecision
+
+// Rule violation errors
+var (
+	ErrInvalidDecimal     = errors.New("invalid decimal")
+	ErrPrecisionExceeded  = errors.New("decimal precision exceeded")
+	ErrNonPositive        = errors.New("value must be positive")
+	ErrPriceNotOnTick     = errors.New("price is not a multiple of tick size")
+	ErrQuantityNotOnLot   = errors.New("quantity is not a multiple of lot size")
+	ErrQuantityTooSmall   = errors.New("quantity below minimum order size")
+	ErrQuantityTooLarge   = errors.New("quantity above maximum order size")
+	ErrNotionalTooSmall   = errors.New("order notional below minimum")
+	ErrPriceDeviationHigh = errors.New("price deviates too far from reference price")
+)
+
+// RuleError describes which field violated which rule.
+// It unwraps to one of the rule violation errors above.
+type RuleError struct {
+	Err   error
+	Field string
+	Value string
+	Limit string
+}
+
+// Error implements the error interface
+func (e *RuleError) Error() string {
+	if e.Limit == "" {
+		return fmt.Sprintf("%s: %s=%s", e.Err, e.Field, e.Value)
+	}
+	return fmt.Sprintf("%s: %s=%s (limit %s)", e.Err, e.Field, e.Value, e.Limit)
+}
+
+// Unwrap returns the underlying rule violation
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+func newRuleError(err error, field string, value, limit math.LegacyDec) *RuleError {
+	re := &RuleError{Err: err, Field: field, Value: value.String()}
+	if !limit.IsNil() {
+		re.Limit = limit.String()
+	}
+	return re
+}
+
+// MarketRules contains the per-market order constraints.
+// Nil or zero values disable the corresponding check.
+type MarketRules struct {
+	MarketID          string
+	TickSize          math.LegacyDec // minimum price increment
+	LotSize           math.LegacyDec // minimum quantity increment
+	MinOrderSize      math.LegacyDec // minimum quantity
+	MaxOrderSize      math.LegacyDec // maximum quantity
+	MinNotional       math.LegacyDec // minimum price × quantity in quote asset
+	MaxPriceDeviation math.LegacyDec // max |price - reference| / reference, e.g. 0.1 = 10%
+}
+
+// RulesProvider looks up market rules by market ID
+type RulesProvider interface {
+	MarketRules(marketID string) (MarketRules, bool)
+}
+
+// StaticRules is a RulesProvider backed by a fixed map
+type StaticRules map[string]MarketRules
+
+// MarketRules implements RulesProvider
+func (s StaticRules) MarketRules(marketID string) (MarketRules, bool) {
+	rules, ok := s[marketID]
+	return rules, ok
+}
+
+// OrderInput is the subset of an order needed for validation
+type OrderInput struct {
+	Price    math.LegacyDec // ignored for market orders
+	Quantity math.LegacyDec
+	IsMarket bool
+	// RefPrice is the mark/index price used for deviation and market order notional checks.
+	// A nil or zero RefPrice skips those checks.
+	RefPrice math.LegacyDec
+}
+
+// ParseDecimal parses a plain decimal string ("123.45") and rejects
+// signs, exponents, whitespace and more than MaxDecimals fractional digits.
+func ParseDecimal(field, s string) (math.LegacyDec, error) {
+	if s == "" || strings.TrimSpace(s) != s || strings.ContainsAny(s, "eE+-") {
+		return math.LegacyDec{}, &RuleError{Err: ErrInvalidDecimal, Field: field, Value: s}
+	}
+	if dot := strings.IndexByte(s, '.'); dot >= 0 && len(s)-dot-1 > MaxDecimals {
+		return math.LegacyDec{}, &RuleError{Err: ErrPrecisionExceeded, Field: field, Value: s, Limit: fmt.Sprintf("%d decimals", MaxDecimals)}
+	}
+
+	d, err := math.LegacyNewDecFromStr(s)
+	if err != nil {
+		return math.LegacyDec{}, &RuleError{Err: ErrInvalidDecimal, Field: field, Value: s}
+	}
+	return d, nil
+}
+
+// ParsePositiveDecimal parses a decimal and requires it to be greater than zero
+func ParsePositiveDecimal(field, s string) (math.LegacyDec, error) {
+	d, err := ParseDecimal(field, s)
+	if err != nil {
+		return d, err
+	}
+	if !d.IsPositive() {
+		return d, &RuleError{Err: ErrNonPositive, Field: field, Value: s}
+	}
+	return d, nil
+}
+
+// ValidateOrder checks an order against the market rules
+func ValidateOrder(rules MarketRules, in OrderInput) error {
+	if in.Quantity.IsNil() || !in.Quantity.IsPositive() {
+		return &RuleError{Err: ErrNonPositive, Field: "quantity", Value: decString(in.Quantity)}
+	}
+
+	if !in.IsMarket {
+		if in.Price.IsNil() || !in.Price.IsPositive() {
+			return &RuleError{Err: ErrNonPositive, Field: "price", Value: decString(in.Price)}
+		}
+		if enabled(rules.TickSize) && !isMultiple(in.Price, rules.TickSize) {
+			return newRuleError(ErrPriceNotOnTick, "price", in.Price, rules.TickSize)
+		}
+	}
+
+	if enabled(rules.LotSize) && !isMultiple(in.Quantity, rules.LotSize) {
+		return newRuleError(ErrQuantityNotOnLot, "quantity", in.Quantity, rules.LotSize)
+	}
+	if enabled(rules.MinOrderSize) && in.Quantity.LT(rules.MinOrderSize) {
+		return newRuleError(ErrQuantityTooSmall, "quantity", in.Quantity, rules.MinOrderSize)
+	}
+	if enabled(rules.MaxOrderSize) && in.Quantity.GT(rules.MaxOrderSize) {
+		return newRuleError(ErrQuantityTooLarge, "quantity", in.Quantity, rules.MaxOrderSize)
+	}
+
+	// Market orders are priced at the reference price for notional purposes
+	notionalPrice := in.Price
+	if in.IsMarket {
+		notionalPrice = in.RefPrice
+	}
+	if enabled(rules.MinNotional) && enabled(notionalPrice) {
+		notional := notionalPrice.Mul(in.Quantity)
+		if notional.LT(rules.MinNotional) {
+			return newRuleError(ErrNotionalTooSmall, "notional", notional, rules.MinNotional)
+		}
+	}
+
+	if !in.IsMarket && enabled(rules.MaxPriceDeviation) && enabled(in.RefPrice) {
+		deviation := in.Price.Sub(in.RefPrice).Abs().Quo(in.RefPrice)
+		if deviation.GT(rules.MaxPriceDeviation) {
+			return newRuleError(ErrPriceDeviationHigh, "price", in.Price, rules.MaxPriceDeviation)
+		}
+	}
+
+	return nil
+}
+
+// enabled reports whether a rule value is set
+func enabled(d math.LegacyDec) bool {
+	return !d.IsNil() && d.IsPositive()
+}
+
+// isMultiple reports whether value is an exact multiple of step
+func isMultiple(value, step math.LegacyDec) bool {
+	return value.Quo(step).TruncateDec().Mul(step).Equal(value)
+}
+
+func decString(d math.LegacyDec) string {
+	if d.IsNil() {
+		return ""
+	}
+	return d.String()
+}
@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+)
+
+func testRules() MarketRules {
+	return MarketRules{
+		MarketID:          "BTC-USDC",
+		TickSize:          math.LegacyNewDecWithPrec(1, 1), // 0.1
+		LotSize:           math.LegacyNewDecWithPrec(1, 4), // 0.0001
+		MinOrderSize:      math.LegacyNewDecWithPrec(1, 4),
+		MaxOrderSize:      math.LegacyNewDec(100),
+		MinNotional:       math.LegacyNewDec(10),
+		MaxPriceDeviation: math.LegacyNewDecWithPrec(1, 1), // 10%
+	}
+}
+
+// TestParseDecimal tests strict decimal parsing
+func TestParseDecimal(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected error
+	}{
+		{"50000.5", nil},
+		{"0.0001", nil},
+		{"", ErrInvalidDecimal},
+		{"1e3", ErrInvalidDecimal},
+		{"-1", ErrInvalidDecimal},
+		{" 1", ErrInvalidDecimal},
+		{"abc", ErrInvalidDecimal},
+		{"0.0000000000000000001", ErrPrecisionExceeded},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseDecimal("price", tc.input)
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.input, tc.expected, err)
+		}
+	}
+
+	if _, err := ParsePositiveDecimal("quantity", "0"); !errors.Is(err, ErrNonPositive) {
+		t.Errorf("expected ErrNonPositive for zero, got %v", err)
+	}
+}
+
+// TestValidateOrder tests market rule enforcement
+func TestValidateOrder(t *testing.T) {
+	ref := math.LegacyNewDec(50000)
+
+	testCases := []struct {
+		name     string
+		input    OrderInput
+		expected error
+	}{
+		{"valid limit", OrderInput{Price: math.LegacyMustNewDecFromStr("50000.1"), Quantity: math.LegacyMustNewDecFromStr("0.01"), RefPrice: ref}, nil},
+		{"off tick", OrderInput{Price: math.LegacyMustNewDecFromStr("50000.15"), Quantity: math.LegacyMustNewDecFromStr("0.01"), RefPrice: ref}, ErrPriceNotOnTick},
+		{"off lot", OrderInput{Price: ref, Quantity: math.LegacyMustNewDecFromStr("0.00015"), RefPrice: ref}, ErrQuantityNotOnLot},
+		{"too large", OrderInput{Price: ref, Quantity: math.LegacyNewDec(101), RefPrice: ref}, ErrQuantityTooLarge},
+		{"below min notional", OrderInput{Price: ref, Quantity: math.LegacyMustNewDecFromStr("0.0001"), RefPrice: ref}, ErrNotionalTooSmall},
+		{"price deviation", OrderInput{Price: math.LegacyNewDec(60000), Quantity: math.LegacyMustNewDecFromStr("0.01"), RefPrice: ref}, ErrPriceDeviationHigh},
+		{"no reference skips deviation", OrderInput{Price: math.LegacyNewDec(60000), Quantity: math.LegacyMustNewDecFromStr("0.01")}, nil},
+		{"market order ignores price", OrderInput{Price: math.LegacyNewDec(1_000_000_000), Quantity: math.LegacyMustNewDecFromStr("0.01"), IsMarket: true, RefPrice: ref}, nil},
+		{"market order notional uses reference", OrderInput{Quantity: math.LegacyMustNewDecFromStr("0.0001"), IsMarket: true, RefPrice: ref}, ErrNotionalTooSmall},
+		{"zero quantity", OrderInput{Price: ref, Quantity: math.LegacyZeroDec()}, ErrNonPositive},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateOrder(testRules(), tc.input)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+
+	// Empty rules disable all market checks
+	if err := ValidateOrder(MarketRules{}, OrderInput{Price: math.LegacyMustNewDecFromStr("1.23456"), Quantity: math.LegacyMustNewDecFromStr("0.000001")}); err != nil {
+		t.Errorf("expected no error with empty rules, got %v", err)
+	}
+}
@@ -254,7 +254,7 @@ run_loadtest() {
     local loadtest_output="${REPORTS_DIR}/loadtest_results.txt"
 
     if [[ -f "${PROJECT_ROOT}/tests/loadtest/main.go" ]]; then
-        go run ./tests/loadtest/main.go \
+        go run ./tests/loadtest \
             -c 100 \
             -d 60s 2>&1 | tee "${loadtest_output}" | tee -a "${LOG_FILE}"
         log OK "Load tests completed"
@@ -31,27 +31,33 @@ func DefaultChainConfig() *ChainConfig {
 	// Find project root by looking for go.mod
 	binaryPath := findBinaryPath()
 	homeDir := findHomeDir()
+	chainID := "perpdex-1" // perpdexd init-devnet's default
+	if env := os.Getenv("PERPDEX_CHAIN_ID"); env != "" {
+		chainID = env
+	}
 
 	return &ChainConfig{
 		RPCURL:         "http://localhost:26657",
 		APIURL:         "http://localhost:1317",
 		GRPCAddr:       "localhost:9090",
-		ChainID:        "perpdex-1", // Match the running chain
+		ChainID:        chainID,
 		HomeDir:        homeDir,
 		BinaryPath:     binaryPath,
 		KeyringBackend: "test",
 	}
 }
 
-// findHomeDir finds the chain home directory (returns absolute path)
+// findHomeDir finds the chain home directory (returns absolute path):
+// PERPDEX_HOME if set, else a .perpdex-test created by
+// "perpdexd init-devnet --home .perpdex-test" in or above the working directory
 func findHomeDir() string {
-	// Try absolute path first (most reliable)
-	absPath := "/Users/vvedition/Desktop/dex mvp/perp-dex_副本/.perpdex-test"
-	if _, err := os.Stat(absPath + "/keyring-test"); err == nil {
-		return absPath
+	if env := os.Getenv("PERPDEX_HOME"); env != "" {
+		if abs, err := filepath.Abs(env); err == nil {
+			return abs
+		}
+		return env
 	}
 
-	// Fall back to relative paths
 	relativePaths := []string{
 		".perpdex-test",
 		"../.perpdex-test",
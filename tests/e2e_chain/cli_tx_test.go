@@ -0,0 +1,90 @@
+package e2e_chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// generateOnly runs a perpdexd tx command with --generate-only and returns the
+// @type of each message in the unsigned transaction
+func generateOnly(t *testing.T, config *ChainConfig, args ...string) []map[string]interface{} {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	args = append(args,
+		"--from", "validator",
+		"--home", config.HomeDir,
+		"--chain-id", config.ChainID,
+		"--keyring-backend", config.KeyringBackend,
+		"--generate-only",
+	)
+	cmd := exec.CommandContext(ctx, config.BinaryPath, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if strings.Contains(stderr.String(), "key not found") {
+			t.Skipf("validator key not available: %s", stderr.String())
+		}
+		t.Fatalf("perpdexd %s: %v - %s", strings.Join(args, " "), err, stderr.String())
+	}
+
+	var unsigned struct {
+		Body struct {
+			Messages []map[string]interface{} `json:"messages"`
+		} `json:"body"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &unsigned), "unsigned tx: %s", stdout.String())
+	return unsigned.Body.Messages
+}
+
+// TestCLI_TxGenerateOnly tests that the trading tx commands build the
+// expected unsigned transactions offline, with flags and batch files
+func TestCLI_TxGenerateOnly(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping CLI test in short mode")
+	}
+	config := DefaultChainConfig()
+	if _, err := os.Stat(config.BinaryPath); err != nil {
+		t.Skipf("perpdexd binary not built: %v", err)
+	}
+
+	msgs := generateOnly(t, config, "tx", "orderbook", "place-order", "BTC-USDC", "buy", "limit", "50000", "0.1")
+	require.Len(t, msgs, 1)
+	require.Equal(t, "/perpdex.orderbook.v1.MsgPlaceOrder", msgs[0]["@type"])
+
+	msgs = generateOnly(t, config, "tx", "orderbook", "place-order",
+		"--market", "ETH-USDC", "--side", "sell", "--type", "market", "--quantity", "2")
+	require.Len(t, msgs, 1)
+	require.Equal(t, "ETH-USDC", msgs[0]["market_id"])
+
+	dir := t.TempDir()
+	ordersFile := filepath.Join(dir, "orders.json")
+	require.NoError(t, os.WriteFile(ordersFile, []byte(`[
+		{"market_id": "BTC-USDC", "side": "buy", "type": "limit", "price": "49000", "quantity": "0.1"},
+		{"market_id": "BTC-USDC", "side": "buy", "type": "limit", "price": "48000", "quantity": "0.2"},
+		{"market_id": "ETH-USDC", "side": "sell", "type": "market", "quantity": "1"}
+	]`), 0o644))
+	msgs = generateOnly(t, config, "tx", "orderbook", "place-order", "--file", ordersFile)
+	require.Len(t, msgs, 3)
+
+	idsFile := filepath.Join(dir, "ids.json")
+	require.NoError(t, os.WriteFile(idsFile, []byte(`["order-3", "order-4"]`), 0o644))
+	msgs = generateOnly(t, config, "tx", "orderbook", "cancel-order", "order-1", "order-2", "--file", idsFile)
+	require.Len(t, msgs, 4)
+	require.Equal(t, "/perpdex.orderbook.v1.MsgCancelOrder", msgs[0]["@type"])
+
+	msgs = generateOnly(t, config, "tx", "perpetual", "close-position", "BTC-USDC", "--side", "long", "--size", "0.5")
+	require.Len(t, msgs, 1)
+	require.Equal(t, "/perpdex.orderbook.v1.MsgPlaceOrder", msgs[0]["@type"])
+}
@@ -58,7 +58,7 @@ func TestMsgServer_PlaceOrder_RealChain(t *testing.T) {
 			t.Skipf("CLI command not available: %v", err)
 		}
 		if strings.Contains(errStr, "key not found") {
-			t.Skipf("Test key not found - run: perpdexd keys add validator --home .perpdex-test --keyring-backend test\nError: %v", err)
+			t.Skipf("Test key not found - run: perpdexd init-devnet --home .perpdex-test\nError: %v", err)
 		}
 		if strings.Contains(errStr, "insufficient funds") {
 			t.Skipf("Insufficient funds - fund the test account first\nError: %v", err)
@@ -78,10 +78,10 @@ func TestMsgServer_PlaceOrder_RealChain(t *testing.T) {
 			t.Skip("Test account has insufficient funds - run: perpdexd tx bank send ... to fund")
 		}
 		if strings.Contains(result.Error, "account not found") {
-			t.Skip("Test account not initialized - run: perpdexd keys add validator --home .perpdex-test --keyring-backend test")
+			t.Skip("Test account not initialized - run: perpdexd init-devnet --home .perpdex-test")
 		}
 		if strings.Contains(result.Error, "key not found") {
-			t.Skip("Test key not found - run: perpdexd keys add validator --home .perpdex-test --keyring-backend test")
+			t.Skip("Test key not found - run: perpdexd init-devnet --home .perpdex-test")
 		}
 	}
 
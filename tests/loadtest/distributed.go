@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Distributed mode: one coordinator and N worker processes, usually on
+// separate hosts so the run is not limited by one machine's sockets.
+//
+//	loadtest -mode coordinator -workers 3 -rps 30000 -d 60s -url http://api:8080
+//	loadtest -mode worker -coordinator http://coordinator:7070   (on each host)
+//
+// Workers register with POST /register, which blocks until all N workers
+// have joined. Each receives its share of the target RPS and a common start
+// time. When done, a worker posts its results, including the latency
+// histogram, to POST /results; the coordinator merges them into one report.
+
+// startDelay gives workers time to receive their assignment before the run starts
+const startDelay = 3 * time.Second
+
+// Assignment is a worker's share of a distributed run
+type Assignment struct {
+	Config  Config    `json:"config"`
+	StartAt time.Time `json:"start_at"`
+}
+
+// WorkerResults is what a worker reports back
+type WorkerResults struct {
+	WorkerID string   `json:"worker_id"`
+	Results  *Results `json:"results"`
+}
+
+// WorkerSummary is one worker's line in a merged report
+type WorkerSummary struct {
+	WorkerID          string  `json:"worker_id"`
+	TargetRPS         int     `json:"target_rps"`
+	TotalRequests     int64   `json:"total_requests"`
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	P99Ms             float64 `json:"p99_ms"`
+}
+
+type coordinator struct {
+	config  *Config
+	workers int
+
+	mu          sync.Mutex
+	registered  []string
+	assignments map[string]*Assignment
+	ready       chan struct{} // closed once all workers registered
+	results     map[string]*Results
+	done        chan struct{} // closed once all workers reported
+}
+
+// shardRPS splits total into n shares that differ by at most one
+func shardRPS(total, n int) []int {
+	shares := make([]int, n)
+	for i := range shares {
+		shares[i] = total / n
+		if i < total%n {
+			shares[i]++
+		}
+	}
+	return shares
+}
+
+func runCoordinator(config *Config, listen string, workers int, outputFile string) error {
+	if workers < 1 {
+		return fmt.Errorf("at least one worker is required")
+	}
+	c := &coordinator{
+		config:      config,
+		workers:     workers,
+		assignments: make(map[string]*Assignment),
+		ready:       make(chan struct{}),
+		results:     make(map[string]*Results),
+		done:        make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/register", c.handleRegister)
+	mux.HandleFunc("/results", c.handleResults)
+	server := &http.Server{Addr: listen, Handler: mux}
+
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- server.ListenAndServe() }()
+	defer server.Shutdown(context.Background())
+
+	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
+	fmt.Println("║        PerpDEX API Load Test - Distributed Coordinator       ║")
+	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+	fmt.Printf("Waiting for %d workers on %s...\n", workers, listen)
+
+	select {
+	case <-c.ready:
+	case err := <-serverErr:
+		return err
+	}
+	fmt.Printf("All workers registered, starting in %s\n", startDelay)
+
+	// Give up on workers that never report, e.g. because they crashed
+	deadline := startDelay + config.RampUp + config.Duration + time.Minute
+	select {
+	case <-c.done:
+	case <-time.After(deadline):
+		fmt.Println("Timed out waiting for worker results; reporting the workers that finished")
+	case err := <-serverErr:
+		return err
+	}
+
+	c.mu.Lock()
+	merged := mergeResults(c.results, c.assignments)
+	c.mu.Unlock()
+	if merged.TotalRequests == 0 {
+		return fmt.Errorf("no worker reported results")
+	}
+
+	printResults(merged, config.Concurrency*len(merged.Workers))
+
+	if outputFile != "" {
+		report := *config
+		report.Concurrency = config.Concurrency * len(merged.Workers)
+		if err := saveReport(outputFile, &report, merged); err != nil {
+			fmt.Printf("Failed to save report: %v\n", err)
+		} else {
+			fmt.Printf("\nReport saved to: %s\n", outputFile)
+		}
+	}
+	return nil
+}
+
+// handleRegister assigns the worker a shard and blocks until every worker
+// has registered, so all of them start at the same time
+func (c *coordinator) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		WorkerID string `json:"worker_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.WorkerID == "" {
+		http.Error(w, "worker_id is required", http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	if _, ok := c.assignments[req.WorkerID]; !ok {
+		if len(c.registered) == c.workers {
+			c.mu.Unlock()
+			http.Error(w, "all worker slots are taken", http.StatusConflict)
+			return
+		}
+		c.registered = append(c.registered, req.WorkerID)
+		fmt.Printf("  Worker %s registered (%d/%d)\n", req.WorkerID, len(c.registered), c.workers)
+		if len(c.registered) == c.workers {
+			c.assign()
+			close(c.ready)
+		}
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.ready:
+	case <-r.Context().Done():
+		return
+	}
+
+	c.mu.Lock()
+	assignment := c.assignments[req.WorkerID]
+	c.mu.Unlock()
+	writeJSON(w, assignment)
+}
+
+// assign builds every worker's assignment; called with mu held
+func (c *coordinator) assign() {
+	startAt := time.Now().Add(startDelay)
+	shares := shardRPS(c.config.TargetRPS, c.workers)
+	for i, id := range c.registered {
+		config := *c.config
+		config.TargetRPS = shares[i]
+		config.WorkerID = fmt.Sprintf("w%d", i)
+		c.assignments[id] = &Assignment{Config: config, StartAt: startAt}
+	}
+}
+
+func (c *coordinator) handleResults(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req WorkerResults
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Results == nil || req.Results.Latency == nil {
+		http.Error(w, "invalid results", http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.assignments[req.WorkerID]; !ok {
+		http.Error(w, "unknown worker", http.StatusNotFound)
+		return
+	}
+	if _, ok := c.results[req.WorkerID]; !ok {
+		c.results[req.WorkerID] = req.Results
+		fmt.Printf("  Worker %s reported %d requests (%d/%d)\n",
+			req.WorkerID, req.Results.TotalRequests, len(c.results), c.workers)
+		if len(c.results) == c.workers {
+			close(c.done)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// mergeResults adds up worker results. Throughput is measured over the
+// union of the workers' run windows.
+func mergeResults(results map[string]*Results, assignments map[string]*Assignment) *Results {
+	ids := make([]string, 0, len(results))
+	for id := range results {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	merged := newResults()
+	for i, id := range ids {
+		r := results[id]
+		merged.TotalRequests += r.TotalRequests
+		merged.SuccessRequests += r.SuccessRequests
+		merged.FailedRequests += r.FailedRequests
+		merged.TotalLatency += r.TotalLatency
+		merged.Latency.Merge(r.Latency)
+		for code, count := range r.StatusCodes {
+			merged.StatusCodes[code] += count
+		}
+		for errType, count := range r.Errors {
+			merged.Errors[errType] += count
+		}
+		if i == 0 || r.StartTime.Before(merged.StartTime) {
+			merged.StartTime = r.StartTime
+		}
+		if r.EndTime.After(merged.EndTime) {
+			merged.EndTime = r.EndTime
+		}
+
+		summary := WorkerSummary{
+			WorkerID:          id,
+			TotalRequests:     r.TotalRequests,
+			RequestsPerSecond: r.RequestsPerSecond,
+			P99Ms:             r.getPercentile(0.99),
+		}
+		if a := assignments[id]; a != nil {
+			summary.TargetRPS = a.Config.TargetRPS
+		}
+		merged.Workers = append(merged.Workers, summary)
+	}
+	if len(ids) > 0 {
+		merged.calculateMetrics()
+	}
+	return merged
+}
+
+func runWorker(coordinatorURL, workerID string) error {
+	if workerID == "" {
+		host, _ := os.Hostname()
+		workerID = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+
+	fmt.Printf("Worker %s registering with %s...\n", workerID, coordinatorURL)
+	var assignment Assignment
+	if err := registerWorker(coordinatorURL, workerID, &assignment); err != nil {
+		return err
+	}
+	fmt.Printf("Assigned %d RPS, starting at %s\n\n", assignment.Config.TargetRPS, assignment.StartAt.Format(time.RFC3339))
+	time.Sleep(time.Until(assignment.StartAt))
+
+	tester := NewLoadTester(&assignment.Config)
+	if err := tester.Run(); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(WorkerResults{WorkerID: workerID, Results: tester.results})
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(coordinatorURL+"/results", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to report results: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("coordinator rejected results: %s", resp.Status)
+	}
+	fmt.Println("Results reported to coordinator")
+	return nil
+}
+
+// registerWorker retries until the coordinator is up, then waits for the
+// other workers to register
+func registerWorker(coordinatorURL, workerID string, assignment *Assignment) error {
+	body, _ := json.Marshal(map[string]string{"worker_id": workerID})
+	for attempt := 0; ; attempt++ {
+		resp, err := http.Post(coordinatorURL+"/register", "application/json", bytes.NewReader(body))
+		if err != nil {
+			if attempt >= 30 {
+				return fmt.Errorf("coordinator unreachable: %w", err)
+			}
+			time.Sleep(2 * time.Second)
+			continue
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("registration rejected: %s", resp.Status)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(assignment); err != nil {
+			return err
+		}
+		if assignment.Config.BaseURL == "" {
+			return errors.New("coordinator sent an empty assignment")
+		}
+		return nil
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
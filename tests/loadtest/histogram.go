@@ -0,0 +1,115 @@
+package main
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// Latency histogram with log-linear buckets: values below 128µs are exact,
+// larger values are grouped into 64 buckets per power of two (under 1.6%
+// relative error). Histograms from several workers merge by adding counts,
+// so percentiles of a distributed run are computed over all requests.
+const (
+	histExactLimit = 128
+	histSubBuckets = 64
+)
+
+// Histogram counts latencies in microseconds. The zero value is not ready
+// to use; create one with NewHistogram.
+type Histogram struct {
+	Counts map[int]int64 `json:"counts"` // bucket index -> count
+	Total  int64         `json:"total"`
+	Min    int64         `json:"min_us"`
+	Max    int64         `json:"max_us"`
+}
+
+func NewHistogram() *Histogram {
+	return &Histogram{Counts: make(map[int]int64)}
+}
+
+func histBucket(us int64) int {
+	if us < histExactLimit {
+		if us < 0 {
+			return 0
+		}
+		return int(us)
+	}
+	exp := bits.Len64(uint64(us)) - 1 // us is in [2^exp, 2^(exp+1))
+	shift := exp - 6
+	sub := int(us>>shift) - histSubBuckets
+	return histExactLimit + (exp-7)*histSubBuckets + sub
+}
+
+// histBucketValue returns the midpoint of a bucket's range
+func histBucketValue(bucket int) int64 {
+	if bucket < histExactLimit {
+		return int64(bucket)
+	}
+	exp := (bucket-histExactLimit)/histSubBuckets + 7
+	sub := (bucket-histExactLimit)%histSubBuckets + histSubBuckets
+	shift := exp - 6
+	low := int64(sub) << shift
+	return low + (int64(1)<<shift)/2
+}
+
+// Record adds one latency
+func (h *Histogram) Record(us int64) {
+	h.Counts[histBucket(us)]++
+	if h.Total == 0 || us < h.Min {
+		h.Min = us
+	}
+	if us > h.Max {
+		h.Max = us
+	}
+	h.Total++
+}
+
+// Merge adds the counts of other
+func (h *Histogram) Merge(other *Histogram) {
+	if other == nil || other.Total == 0 {
+		return
+	}
+	for bucket, count := range other.Counts {
+		h.Counts[bucket] += count
+	}
+	if h.Total == 0 || other.Min < h.Min {
+		h.Min = other.Min
+	}
+	if other.Max > h.Max {
+		h.Max = other.Max
+	}
+	h.Total += other.Total
+}
+
+// Percentile returns the latency in microseconds at quantile p (0-1)
+func (h *Histogram) Percentile(p float64) int64 {
+	if h.Total == 0 {
+		return 0
+	}
+	buckets := make([]int, 0, len(h.Counts))
+	for bucket := range h.Counts {
+		buckets = append(buckets, bucket)
+	}
+	sort.Ints(buckets)
+
+	rank := int64(float64(h.Total) * p)
+	if rank >= h.Total-1 {
+		return h.Max
+	}
+	var seen int64
+	for _, bucket := range buckets {
+		seen += h.Counts[bucket]
+		if seen > rank {
+			value := histBucketValue(bucket)
+			// Bucket midpoints can fall outside the observed range
+			if value < h.Min {
+				return h.Min
+			}
+			if value > h.Max {
+				return h.Max
+			}
+			return value
+		}
+	}
+	return h.Max
+}
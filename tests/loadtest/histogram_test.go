@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// TestHistogramPercentiles tests percentile accuracy and that merged worker
+// histograms match a histogram of all samples
+func TestHistogramPercentiles(t *testing.T) {
+	all := NewHistogram()
+	workers := []*Histogram{NewHistogram(), NewHistogram(), NewHistogram()}
+	for us := int64(1); us <= 100000; us++ {
+		all.Record(us)
+		workers[us%3].Record(us)
+	}
+
+	// Workers send their histograms to the coordinator as JSON
+	merged := NewHistogram()
+	for _, h := range workers {
+		bz, _ := json.Marshal(h)
+		var decoded Histogram
+		if err := json.Unmarshal(bz, &decoded); err != nil {
+			t.Fatalf("failed to decode histogram: %v", err)
+		}
+		merged.Merge(&decoded)
+	}
+
+	if merged.Total != all.Total || merged.Min != 1 || merged.Max != 100000 {
+		t.Fatalf("unexpected merged totals: %d samples, min %d, max %d", merged.Total, merged.Min, merged.Max)
+	}
+	for _, p := range []float64{0.5, 0.9, 0.99, 0.999} {
+		got := merged.Percentile(p)
+		if got != all.Percentile(p) {
+			t.Errorf("p%v: merged %d != single %d", p*100, got, all.Percentile(p))
+		}
+		want := p * 100000
+		if math.Abs(float64(got)-want)/want > 0.016 {
+			t.Errorf("p%v: got %d, want about %.0f", p*100, got, want)
+		}
+	}
+	if got := merged.Percentile(1); got != 100000 {
+		t.Errorf("expected p100 to be the max, got %d", got)
+	}
+}
+
+// TestShardRPS tests that shares add up and differ by at most one
+func TestShardRPS(t *testing.T) {
+	shares := shardRPS(1000, 3)
+	if shares[0] != 334 || shares[1] != 333 || shares[2] != 333 {
+		t.Errorf("unexpected shares %v", shares)
+	}
+	if shares := shardRPS(0, 2); shares[0] != 0 || shares[1] != 0 {
+		t.Errorf("expected unpaced workers, got %v", shares)
+	}
+}
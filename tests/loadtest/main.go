@@ -9,7 +9,6 @@ import (
 	"math/rand"
 	"net/http"
 	"os"
-	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,25 +22,35 @@ type Config struct {
 	RampUp      time.Duration
 	Markets     []string
 	TraderCount int
+	TargetRPS   int    // Paces requests at this rate across all workers; 0 sends as fast as workers can
+	WorkerID    string // Prefixes trader addresses so workers of a distributed run do not collide
 }
 
-// Test results
+// Test results. Results are sent from workers to the coordinator as JSON
+// and merged there, see distributed.go.
 type Results struct {
 	TotalRequests     int64
 	SuccessRequests   int64
 	FailedRequests    int64
 	TotalLatency      int64 // microseconds
-	MinLatency        int64
-	MaxLatency        int64
-	Latencies         []int64
+	Latency           *Histogram
 	StatusCodes       map[int]int64
 	Errors            map[string]int64
 	StartTime         time.Time
 	EndTime           time.Time
 	RequestsPerSecond float64
+	Workers           []WorkerSummary // per worker breakdown of a merged distributed run
 	mu                sync.Mutex
 }
 
+func newResults() *Results {
+	return &Results{
+		Latency:     NewHistogram(),
+		StatusCodes: make(map[int]int64),
+		Errors:      make(map[string]int64),
+	}
+}
+
 // Order request
 type PlaceOrderRequest struct {
 	MarketID string `json:"market_id"`
@@ -59,17 +68,13 @@ type LoadTester struct {
 	client  *http.Client
 	wg      sync.WaitGroup
 	stopCh  chan struct{}
+	tokens  chan struct{} // request permits when TargetRPS is set
 }
 
 func NewLoadTester(config *Config) *LoadTester {
 	return &LoadTester{
-		config: config,
-		results: &Results{
-			MinLatency:  int64(^uint64(0) >> 1), // Max int64
-			StatusCodes: make(map[int]int64),
-			Errors:      make(map[string]int64),
-			Latencies:   make([]int64, 0),
-		},
+		config:  config,
+		results: newResults(),
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 			Transport: &http.Transport{
@@ -82,7 +87,7 @@ func NewLoadTester(config *Config) *LoadTester {
 	}
 }
 
-func (lt *LoadTester) Run() {
+func (lt *LoadTester) Run() error {
 	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
 	fmt.Println("║           PerpDEX API Load Test - Order Placement            ║")
 	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
@@ -96,6 +101,9 @@ func (lt *LoadTester) Run() {
 	fmt.Printf("  Ramp-up:      %v\n", lt.config.RampUp)
 	fmt.Printf("  Markets:      %v\n", lt.config.Markets)
 	fmt.Printf("  Traders:      %d\n", lt.config.TraderCount)
+	if lt.config.TargetRPS > 0 {
+		fmt.Printf("  Target RPS:   %d\n", lt.config.TargetRPS)
+	}
 	fmt.Println()
 
 	// Check server health first
@@ -104,7 +112,7 @@ func (lt *LoadTester) Run() {
 		fmt.Printf("FAILED: %v\n", err)
 		fmt.Println("\nPlease ensure the API server is running:")
 		fmt.Println("  cd cmd/api && go run main.go")
-		return
+		return err
 	}
 	fmt.Println("OK")
 	fmt.Println()
@@ -112,6 +120,10 @@ func (lt *LoadTester) Run() {
 	// Start test
 	fmt.Println("Starting load test...")
 	lt.results.StartTime = time.Now()
+	if lt.config.TargetRPS > 0 {
+		lt.tokens = make(chan struct{}, lt.config.TargetRPS)
+		go lt.pace()
+	}
 
 	// Ramp-up workers
 	workersPerInterval := lt.config.Concurrency / 10
@@ -158,7 +170,34 @@ func (lt *LoadTester) Run() {
 	lt.calculateMetrics()
 
 	// Print results
-	lt.printResults()
+	printResults(lt.results, lt.config.Concurrency)
+	return nil
+}
+
+// pace issues TargetRPS request permits per second in 10ms batches. Permits
+// the workers cannot use are dropped, so a saturated run reports the rate it
+// actually reached instead of catching up later.
+func (lt *LoadTester) pace() {
+	const tick = 10 * time.Millisecond
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	perTick := float64(lt.config.TargetRPS) * tick.Seconds()
+	var owed float64
+	for {
+		select {
+		case <-lt.stopCh:
+			return
+		case <-ticker.C:
+			owed += perTick
+			for ; owed >= 1; owed-- {
+				select {
+				case lt.tokens <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}
 }
 
 func (lt *LoadTester) checkHealth() error {
@@ -179,10 +218,20 @@ func (lt *LoadTester) worker(id int) {
 
 	traders := make([]string, lt.config.TraderCount)
 	for i := range traders {
-		traders[i] = fmt.Sprintf("perpdex1test%d%04d", id, i)
+		traders[i] = fmt.Sprintf("perpdex1test%s%d%04d", lt.config.WorkerID, id, i)
 	}
 
 	for {
+		if lt.tokens != nil {
+			select {
+			case <-lt.stopCh:
+				return
+			case <-lt.tokens:
+				lt.placeOrder(traders[rand.Intn(len(traders))])
+			}
+			continue
+		}
+
 		select {
 		case <-lt.stopCh:
 			return
@@ -277,15 +326,7 @@ func (lt *LoadTester) recordLatency(latency int64, success bool, statusCode int)
 	}
 
 	lt.results.mu.Lock()
-	lt.results.Latencies = append(lt.results.Latencies, latency)
-
-	if latency < lt.results.MinLatency {
-		lt.results.MinLatency = latency
-	}
-	if latency > lt.results.MaxLatency {
-		lt.results.MaxLatency = latency
-	}
-
+	lt.results.Latency.Record(latency)
 	lt.results.StatusCodes[statusCode]++
 	lt.results.mu.Unlock()
 }
@@ -318,27 +359,19 @@ func (lt *LoadTester) reportProgress() {
 }
 
 func (lt *LoadTester) calculateMetrics() {
-	elapsed := lt.results.EndTime.Sub(lt.results.StartTime).Seconds()
-	lt.results.RequestsPerSecond = float64(lt.results.TotalRequests) / elapsed
+	lt.results.calculateMetrics()
+}
 
-	// Sort latencies for percentile calculation
-	sort.Slice(lt.results.Latencies, func(i, j int) bool {
-		return lt.results.Latencies[i] < lt.results.Latencies[j]
-	})
+func (r *Results) calculateMetrics() {
+	elapsed := r.EndTime.Sub(r.StartTime).Seconds()
+	r.RequestsPerSecond = float64(r.TotalRequests) / elapsed
 }
 
-func (lt *LoadTester) getPercentile(p float64) float64 {
-	if len(lt.results.Latencies) == 0 {
-		return 0
-	}
-	index := int(float64(len(lt.results.Latencies)) * p)
-	if index >= len(lt.results.Latencies) {
-		index = len(lt.results.Latencies) - 1
-	}
-	return float64(lt.results.Latencies[index]) / 1000 // Convert to ms
+func (r *Results) getPercentile(p float64) float64 {
+	return float64(r.Latency.Percentile(p)) / 1000 // Convert to ms
 }
 
-func (lt *LoadTester) printResults() {
+func printResults(results *Results, concurrency int) {
 	fmt.Println()
 	fmt.Println()
 	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
@@ -346,48 +379,56 @@ func (lt *LoadTester) printResults() {
 	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
 	fmt.Println()
 
-	elapsed := lt.results.EndTime.Sub(lt.results.StartTime)
+	elapsed := results.EndTime.Sub(results.StartTime)
 	avgLatency := float64(0)
-	if lt.results.TotalRequests > 0 {
-		avgLatency = float64(lt.results.TotalLatency) / float64(lt.results.TotalRequests) / 1000
+	if results.TotalRequests > 0 {
+		avgLatency = float64(results.TotalLatency) / float64(results.TotalRequests) / 1000
 	}
 
 	successRate := float64(0)
-	if lt.results.TotalRequests > 0 {
-		successRate = float64(lt.results.SuccessRequests) / float64(lt.results.TotalRequests) * 100
+	if results.TotalRequests > 0 {
+		successRate = float64(results.SuccessRequests) / float64(results.TotalRequests) * 100
 	}
 
 	fmt.Printf("Test Duration:        %v\n", elapsed.Round(time.Millisecond))
-	fmt.Printf("Concurrency:          %d workers\n", lt.config.Concurrency)
+	fmt.Printf("Concurrency:          %d workers\n", concurrency)
 	fmt.Println()
 
 	fmt.Println("── Request Statistics ─────────────────────────────────────────")
-	fmt.Printf("  Total Requests:     %d\n", lt.results.TotalRequests)
-	fmt.Printf("  Successful:         %d (%.2f%%)\n", lt.results.SuccessRequests, successRate)
-	fmt.Printf("  Failed:             %d (%.2f%%)\n", lt.results.FailedRequests, 100-successRate)
-	fmt.Printf("  Requests/Second:    %.2f\n", lt.results.RequestsPerSecond)
+	fmt.Printf("  Total Requests:     %d\n", results.TotalRequests)
+	fmt.Printf("  Successful:         %d (%.2f%%)\n", results.SuccessRequests, successRate)
+	fmt.Printf("  Failed:             %d (%.2f%%)\n", results.FailedRequests, 100-successRate)
+	fmt.Printf("  Requests/Second:    %.2f\n", results.RequestsPerSecond)
 	fmt.Println()
 
 	fmt.Println("── Latency Statistics (ms) ────────────────────────────────────")
-	fmt.Printf("  Min:                %.2f ms\n", float64(lt.results.MinLatency)/1000)
-	fmt.Printf("  Max:                %.2f ms\n", float64(lt.results.MaxLatency)/1000)
+	fmt.Printf("  Min:                %.2f ms\n", float64(results.Latency.Min)/1000)
+	fmt.Printf("  Max:                %.2f ms\n", float64(results.Latency.Max)/1000)
 	fmt.Printf("  Average:            %.2f ms\n", avgLatency)
-	fmt.Printf("  P50 (Median):       %.2f ms\n", lt.getPercentile(0.50))
-	fmt.Printf("  P90:                %.2f ms\n", lt.getPercentile(0.90))
-	fmt.Printf("  P95:                %.2f ms\n", lt.getPercentile(0.95))
-	fmt.Printf("  P99:                %.2f ms\n", lt.getPercentile(0.99))
+	fmt.Printf("  P50 (Median):       %.2f ms\n", results.getPercentile(0.50))
+	fmt.Printf("  P90:                %.2f ms\n", results.getPercentile(0.90))
+	fmt.Printf("  P95:                %.2f ms\n", results.getPercentile(0.95))
+	fmt.Printf("  P99:                %.2f ms\n", results.getPercentile(0.99))
 	fmt.Println()
 
+	if len(results.Workers) > 0 {
+		fmt.Println("── Workers ────────────────────────────────────────────────────")
+		for _, w := range results.Workers {
+			fmt.Printf("  %-20s %d requests, %.2f req/s, P99 %.2f ms\n", w.WorkerID, w.TotalRequests, w.RequestsPerSecond, w.P99Ms)
+		}
+		fmt.Println()
+	}
+
 	fmt.Println("── Status Code Distribution ───────────────────────────────────")
-	for code, count := range lt.results.StatusCodes {
-		percentage := float64(count) / float64(lt.results.TotalRequests) * 100
+	for code, count := range results.StatusCodes {
+		percentage := float64(count) / float64(results.TotalRequests) * 100
 		fmt.Printf("  HTTP %d:             %d (%.2f%%)\n", code, count, percentage)
 	}
 	fmt.Println()
 
-	if len(lt.results.Errors) > 0 {
+	if len(results.Errors) > 0 {
 		fmt.Println("── Error Distribution ─────────────────────────────────────────")
-		for errType, count := range lt.results.Errors {
+		for errType, count := range results.Errors {
 			fmt.Printf("  %s: %d\n", errType, count)
 		}
 		fmt.Println()
@@ -415,11 +456,11 @@ func (lt *LoadTester) printResults() {
 		fmt.Println("  ❌ High latency: >200ms average")
 	}
 
-	if lt.results.RequestsPerSecond > 1000 {
+	if results.RequestsPerSecond > 1000 {
 		fmt.Println("  ✅ High throughput: >1000 req/s")
-	} else if lt.results.RequestsPerSecond > 500 {
+	} else if results.RequestsPerSecond > 500 {
 		fmt.Println("  ✅ Good throughput: >500 req/s")
-	} else if lt.results.RequestsPerSecond > 100 {
+	} else if results.RequestsPerSecond > 100 {
 		fmt.Println("  ⚠️  Moderate throughput: >100 req/s")
 	} else {
 		fmt.Println("  ❌ Low throughput: <100 req/s")
@@ -430,51 +471,59 @@ func (lt *LoadTester) printResults() {
 }
 
 func (lt *LoadTester) SaveReport(filename string) error {
+	return saveReport(filename, lt.config, lt.results)
+}
+
+func saveReport(filename string, config *Config, results *Results) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	elapsed := lt.results.EndTime.Sub(lt.results.StartTime)
+	elapsed := results.EndTime.Sub(results.StartTime)
 	avgLatency := float64(0)
-	if lt.results.TotalRequests > 0 {
-		avgLatency = float64(lt.results.TotalLatency) / float64(lt.results.TotalRequests) / 1000
+	if results.TotalRequests > 0 {
+		avgLatency = float64(results.TotalLatency) / float64(results.TotalRequests) / 1000
 	}
 	successRate := float64(0)
-	if lt.results.TotalRequests > 0 {
-		successRate = float64(lt.results.SuccessRequests) / float64(lt.results.TotalRequests) * 100
+	if results.TotalRequests > 0 {
+		successRate = float64(results.SuccessRequests) / float64(results.TotalRequests) * 100
 	}
 
 	report := map[string]interface{}{
 		"test_config": map[string]interface{}{
-			"base_url":     lt.config.BaseURL,
-			"concurrency":  lt.config.Concurrency,
-			"duration":     lt.config.Duration.String(),
-			"markets":      lt.config.Markets,
-			"trader_count": lt.config.TraderCount,
+			"base_url":     config.BaseURL,
+			"concurrency":  config.Concurrency,
+			"duration":     config.Duration.String(),
+			"markets":      config.Markets,
+			"trader_count": config.TraderCount,
+			"target_rps":   config.TargetRPS,
 		},
 		"summary": map[string]interface{}{
 			"test_duration":      elapsed.String(),
-			"total_requests":     lt.results.TotalRequests,
-			"success_requests":   lt.results.SuccessRequests,
-			"failed_requests":    lt.results.FailedRequests,
+			"total_requests":     results.TotalRequests,
+			"success_requests":   results.SuccessRequests,
+			"failed_requests":    results.FailedRequests,
 			"success_rate":       fmt.Sprintf("%.2f%%", successRate),
-			"requests_per_second": lt.results.RequestsPerSecond,
+			"requests_per_second": results.RequestsPerSecond,
 		},
 		"latency": map[string]interface{}{
-			"min_ms": float64(lt.results.MinLatency) / 1000,
-			"max_ms": float64(lt.results.MaxLatency) / 1000,
+			"min_ms": float64(results.Latency.Min) / 1000,
+			"max_ms": float64(results.Latency.Max) / 1000,
 			"avg_ms": avgLatency,
-			"p50_ms": lt.getPercentile(0.50),
-			"p90_ms": lt.getPercentile(0.90),
-			"p95_ms": lt.getPercentile(0.95),
-			"p99_ms": lt.getPercentile(0.99),
+			"p50_ms": results.getPercentile(0.50),
+			"p90_ms": results.getPercentile(0.90),
+			"p95_ms": results.getPercentile(0.95),
+			"p99_ms": results.getPercentile(0.99),
 		},
-		"status_codes": lt.results.StatusCodes,
-		"errors":       lt.results.Errors,
+		"status_codes": results.StatusCodes,
+		"errors":       results.Errors,
 		"timestamp":    time.Now().Format(time.RFC3339),
 	}
+	if len(results.Workers) > 0 {
+		report["workers"] = results.Workers
+	}
 
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
@@ -494,6 +543,12 @@ func main() {
 	rampUp := flag.Duration("ramp", 5*time.Second, "Ramp-up time")
 	outputFile := flag.String("o", "", "Output JSON report file")
 	realistic := flag.Bool("realistic", false, "Run realistic test suite")
+	rps := flag.Int("rps", 0, "Target requests per second (0 = as fast as the workers can send); coordinator mode shards it across processes")
+	mode := flag.String("mode", "standalone", "standalone, coordinator or worker")
+	listen := flag.String("listen", ":7070", "Coordinator mode: control plane listen address")
+	processes := flag.Int("workers", 2, "Coordinator mode: number of worker processes to wait for")
+	coordinatorURL := flag.String("coordinator", "http://localhost:7070", "Worker mode: coordinator control plane URL")
+	workerID := flag.String("id", "", "Worker mode: worker ID (default hostname-pid)")
 	flag.Parse()
 
 	if *realistic {
@@ -508,10 +563,32 @@ func main() {
 		RampUp:      *rampUp,
 		Markets:     []string{"BTC-USDC", "ETH-USDC", "SOL-USDC"},
 		TraderCount: 100,
+		TargetRPS:   *rps,
+	}
+
+	switch *mode {
+	case "standalone":
+	case "coordinator":
+		if err := runCoordinator(config, *listen, *processes, *outputFile); err != nil {
+			fmt.Printf("Coordinator failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case "worker":
+		if err := runWorker(*coordinatorURL, *workerID); err != nil {
+			fmt.Printf("Worker failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	default:
+		fmt.Printf("Unknown mode %q (expected standalone, coordinator or worker)\n", *mode)
+		os.Exit(2)
 	}
 
 	tester := NewLoadTester(config)
-	tester.Run()
+	if err := tester.Run(); err != nil {
+		os.Exit(1)
+	}
 
 	if *outputFile != "" {
 		if err := tester.SaveReport(*outputFile); err != nil {
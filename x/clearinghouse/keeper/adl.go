@@ -4,7 +4,6 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
-	"sort"
 
 	"cosmossdk.io/math"
 	storetypes "cosmossdk.io/store/types"
@@ -46,7 +45,8 @@ func (k *Keeper) GetADLConfig(ctx sdk.Context) types.ADLConfig {
 
 // ============ ADL Queue Management ============
 
-// BuildADLQueue builds the ADL queue for a market and side
+// BuildADLQueue builds the ADL queue for a market and side, ranked by ADL
+// score (see types.ADLScore)
 func (k *Keeper) BuildADLQueue(ctx sdk.Context, marketID, side string) *types.ADLQueue {
 	queue := types.NewADLQueue(marketID, side)
 
@@ -63,6 +63,7 @@ func (k *Keeper) BuildADLQueue(ctx sdk.Context, marketID, side string) *types.AD
 	if side == "short" {
 		targetSide = perpetualtypes.PositionSideShort
 	}
+	priorityByPnL := k.GetADLConfig(ctx).PriorityByPnL
 
 	// Filter and calculate PnL for each position
 	// CRITICAL: Only include profitable positions for ADL (positive PnL)
@@ -82,9 +83,9 @@ func (k *Keeper) BuildADLQueue(ctx sdk.Context, marketID, side string) *types.AD
 			continue
 		}
 
-		pnlPercent := math.LegacyZeroDec()
-		if pos.Margin.IsPositive() {
-			pnlPercent = pnl.Quo(pos.Margin)
+		score, pnlPercent, leverage := types.ADLScore(pnl, pos.Margin, pos.Size.Mul(markPrice))
+		if !priorityByPnL {
+			score = leverage
 		}
 
 		adlPos := &types.ADLPosition{
@@ -95,117 +96,114 @@ func (k *Keeper) BuildADLQueue(ctx sdk.Context, marketID, side string) *types.AD
 			EntryPrice:    pos.EntryPrice,
 			UnrealizedPnL: pnl,
 			PnLPercent:    pnlPercent,
+			Leverage:      leverage,
+			Score:         score,
 		}
 
 		queue.Positions = append(queue.Positions, adlPos)
 		queue.TotalSize = queue.TotalSize.Add(pos.Size)
 	}
 
-	// Sort by PnL percentage (highest profit first - they get deleveraged first)
-	sort.Slice(queue.Positions, func(i, j int) bool {
-		return queue.Positions[i].PnLPercent.GT(queue.Positions[j].PnLPercent)
-	})
-
-	// Assign rankings
-	for i, pos := range queue.Positions {
-		pos.ADLRanking = i + 1
-	}
+	// Highest score first - they get deleveraged first
+	types.RankADLPositions(queue.Positions)
+	queue.LastUpdated = ctx.BlockTime()
 
 	return queue
 }
 
 // ============ ADL Execution ============
 
-// ExecuteADL executes Auto-Deleveraging to cover a deficit
-func (k *Keeper) ExecuteADL(ctx sdk.Context, marketID string, deficit math.LegacyDec, reason types.ADLTriggerReason) (*types.ADLResult, error) {
+// ExecuteADL covers a deficit left by a bankrupt position on bankruptSide by
+// deleveraging profitable positions on the opposite side, in queue order
+func (k *Keeper) ExecuteADL(ctx sdk.Context, marketID, bankruptSide string, deficit math.LegacyDec, reason types.ADLTriggerReason) (*types.ADLResult, error) {
 	logger := k.Logger()
 	config := k.GetADLConfig(ctx)
 
 	if !config.Enabled {
-		return nil, fmt.Errorf("ADL is disabled")
+		return nil, types.ErrADLDisabled
 	}
 
 	result := &types.ADLResult{
 		Success:          false,
+		TotalDeleveraged: math.LegacyZeroDec(),
 		DeficitCovered:   math.LegacyZeroDec(),
 		RemainingDeficit: deficit,
+		Fills:            make([]*types.ADLFill, 0),
 		Errors:           make([]string, 0),
 	}
 
-	// Determine which side to deleverage (opposite of losing side)
-	// If longs are being liquidated with deficit, deleverage profitable shorts
-	// If shorts are being liquidated with deficit, deleverage profitable longs
-	sides := []string{"long", "short"}
+	priceInfo := k.perpetualKeeper.GetPrice(ctx, marketID)
+	if priceInfo == nil {
+		return nil, fmt.Errorf("no price for market %s", marketID)
+	}
+	markPrice := priceInfo.MarkPrice
+
+	// The bankrupt position's loss is the opposing side's profit: if a long is
+	// bankrupt, profitable shorts are deleveraged, and vice versa
+	side := types.OppositeSide(bankruptSide)
+	queue := k.BuildADLQueue(ctx, marketID, side)
 
-	for _, side := range sides {
-		if result.RemainingDeficit.IsZero() {
+	// Deleverage positions starting from the top of the queue
+	for _, adlPos := range queue.Positions {
+		if !result.RemainingDeficit.IsPositive() {
 			break
 		}
 
-		queue := k.BuildADLQueue(ctx, marketID, side)
-		if len(queue.Positions) == 0 {
+		// Calculate how much to deleverage
+		maxDeleverage := adlPos.Size.Mul(config.MaxDeleverageRatio)
+		if maxDeleverage.LT(config.MinPositionForADL) {
 			continue
 		}
 
-		// Deleverage positions starting from most profitable
-		for _, adlPos := range queue.Positions {
-			if result.RemainingDeficit.IsZero() {
-				break
-			}
-
-			// Calculate how much to deleverage
-			maxDeleverage := adlPos.Size.Mul(config.MaxDeleverageRatio)
-			if maxDeleverage.LT(config.MinPositionForADL) {
-				continue
-			}
-
-			// Deleverage enough to cover remaining deficit
-			priceInfo := k.perpetualKeeper.GetPrice(ctx, marketID)
-			if priceInfo == nil {
-				continue
-			}
-
-			// Calculate notional value
-			notional := adlPos.Size.Mul(priceInfo.MarkPrice)
-			deficitRatio := result.RemainingDeficit.Quo(notional)
-			deleverageQty := adlPos.Size.Mul(deficitRatio)
-
-			// Cap at max deleverage ratio
-			if deleverageQty.GT(maxDeleverage) {
-				deleverageQty = maxDeleverage
-			}
-
-			// Execute deleverage
-			coveredAmount, err := k.deleveragePosition(ctx, adlPos, deleverageQty, priceInfo.MarkPrice)
-			if err != nil {
-				result.Errors = append(result.Errors, fmt.Sprintf("failed to deleverage %s: %v", adlPos.Trader, err))
-				continue
-			}
-
-			result.PositionsAffected++
-			result.TotalDeleveraged = result.TotalDeleveraged.Add(deleverageQty)
-			result.DeficitCovered = result.DeficitCovered.Add(coveredAmount)
-			result.RemainingDeficit = result.RemainingDeficit.Sub(coveredAmount)
-
-			logger.Info("position deleveraged",
-				"trader", adlPos.Trader,
-				"market_id", marketID,
-				"side", side,
-				"quantity", deleverageQty.String(),
-				"covered", coveredAmount.String(),
-			)
+		// Deleverage just enough for its realized profit to cover the remaining deficit
+		pnlPerUnit := adlPos.UnrealizedPnL.Quo(adlPos.Size)
+		deleverageQty := math.LegacyMinDec(result.RemainingDeficit.Quo(pnlPerUnit), maxDeleverage)
+
+		fill, err := k.deleveragePosition(ctx, adlPos, deleverageQty, markPrice, result.RemainingDeficit)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to deleverage %s: %v", adlPos.Trader, err))
+			continue
 		}
+
+		result.PositionsAffected++
+		result.Fills = append(result.Fills, fill)
+		result.TotalDeleveraged = result.TotalDeleveraged.Add(fill.Quantity)
+		result.DeficitCovered = result.DeficitCovered.Add(fill.Covered)
+		result.RemainingDeficit = result.RemainingDeficit.Sub(fill.Covered)
+
+		logger.Info("position deleveraged",
+			"trader", adlPos.Trader,
+			"market_id", marketID,
+			"side", side,
+			"ranking", adlPos.ADLRanking,
+			"quantity", fill.Quantity.String(),
+			"covered", fill.Covered.String(),
+		)
+
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				"adl_deleverage",
+				sdk.NewAttribute("trader", adlPos.Trader),
+				sdk.NewAttribute("market_id", marketID),
+				sdk.NewAttribute("side", side),
+				sdk.NewAttribute("quantity", fill.Quantity.String()),
+				sdk.NewAttribute("price", fill.Price.String()),
+				sdk.NewAttribute("covered", fill.Covered.String()),
+			),
+		)
 	}
 
 	// Record ADL event
-	result.EventID = k.recordADLEvent(ctx, marketID, reason, deficit, result)
+	result.EventID = k.recordADLEvent(ctx, marketID, bankruptSide, reason, deficit, result)
 	result.Success = result.DeficitCovered.IsPositive()
 
 	// Emit event
 	ctx.EventManager().EmitEvent(
 		sdk.NewEvent(
 			"adl_executed",
+			sdk.NewAttribute("event_id", result.EventID),
 			sdk.NewAttribute("market_id", marketID),
+			sdk.NewAttribute("bankrupt_side", bankruptSide),
 			sdk.NewAttribute("reason", reason.String()),
 			sdk.NewAttribute("deficit", deficit.String()),
 			sdk.NewAttribute("covered", result.DeficitCovered.String()),
@@ -216,13 +214,16 @@ func (k *Keeper) ExecuteADL(ctx sdk.Context, marketID string, deficit math.Legac
 	return result, nil
 }
 
-// deleveragePosition deleverages a single position
-func (k *Keeper) deleveragePosition(ctx sdk.Context, adlPos *types.ADLPosition, quantity, markPrice math.LegacyDec) (math.LegacyDec, error) {
+// deleveragePosition closes quantity of a position at the mark price. Up to
+// maxCover of the realized profit is withheld to cover the deficit; the rest
+// is credited to the trader along with the released margin.
+func (k *Keeper) deleveragePosition(ctx sdk.Context, adlPos *types.ADLPosition, quantity, markPrice, maxCover math.LegacyDec) (*types.ADLFill, error) {
 	// Get the position
 	position := k.perpetualKeeper.GetPosition(ctx, adlPos.Trader, adlPos.MarketID)
 	if position == nil {
-		return math.LegacyZeroDec(), fmt.Errorf("position not found")
+		return nil, types.ErrPositionNotFound
 	}
+	quantity = math.LegacyMinDec(quantity, position.Size)
 
 	// Calculate PnL for deleveraged portion
 	pnlPerUnit := markPrice.Sub(position.EntryPrice)
@@ -231,46 +232,52 @@ func (k *Keeper) deleveragePosition(ctx sdk.Context, adlPos *types.ADLPosition,
 	}
 	totalPnL := pnlPerUnit.Mul(quantity)
 
-	// Reduce position size
-	position.ReduceSize(quantity)
+	// CRITICAL: Only positive PnL can cover deficit. BuildADLQueue only ranks
+	// profitable positions; this guards against a price move in between.
+	covered := math.LegacyZeroDec()
+	if totalPnL.IsPositive() {
+		covered = math.LegacyMinDec(totalPnL, maxCover)
+	}
 
 	// Calculate margin to release
-	marginRatio := quantity.Quo(adlPos.Size)
-	marginRelease := position.Margin.Mul(marginRatio)
+	marginRelease := position.Margin.Mul(quantity.Quo(position.Size))
+
+	// Reduce position size
+	position.RecordClose(quantity, markPrice, totalPnL)
+	position.ReduceSize(quantity)
+	position.Margin = position.Margin.Sub(marginRelease)
 
 	// Update account
 	account := k.perpetualKeeper.GetAccount(ctx, adlPos.Trader)
 	if account != nil {
-		// Release margin and apply PnL
-		account.Balance = account.Balance.Add(marginRelease).Add(totalPnL)
+		// Release margin and apply the PnL left after covering the deficit
+		account.Balance = account.Balance.Add(marginRelease).Add(totalPnL.Sub(covered))
 		account.UnlockMargin(marginRelease)
 		k.perpetualKeeper.SetAccount(ctx, account)
 	}
 
 	// Save or delete position
 	if position.Size.IsZero() {
+		k.perpetualKeeper.RecordClosedPosition(ctx, position, perpetualtypes.CloseReasonADL)
 		k.perpetualKeeper.DeletePosition(ctx, adlPos.Trader, adlPos.MarketID)
 	} else {
 		k.perpetualKeeper.SetPosition(ctx, position)
 	}
-
-	// Return the deficit coverage (realized profit from forced closure)
-	// CRITICAL: Only positive PnL can cover deficit
-	// With the BuildADLQueue fix, we should only get profitable positions
-	// This is a defensive check in case of edge cases
-	if totalPnL.IsPositive() {
-		return totalPnL, nil
-	}
-
-	// If we reach here with non-positive PnL, something went wrong
-	// Log warning but don't return error to avoid breaking the ADL loop
-	// The position was already reduced, so we return 0 coverage
-	return math.LegacyZeroDec(), nil
+	k.afterPositionChanged(ctx, adlPos.Trader, adlPos.MarketID)
+
+	return &types.ADLFill{
+		Trader:     adlPos.Trader,
+		Side:       adlPos.Side,
+		Quantity:   quantity,
+		Price:      markPrice,
+		Covered:    covered,
+		ADLRanking: adlPos.ADLRanking,
+	}, nil
 }
 
 // ============ ADL Event Recording ============
 
-func (k *Keeper) recordADLEvent(ctx sdk.Context, marketID string, reason types.ADLTriggerReason, deficit math.LegacyDec, result *types.ADLResult) string {
+func (k *Keeper) recordADLEvent(ctx sdk.Context, marketID, bankruptSide string, reason types.ADLTriggerReason, deficit math.LegacyDec, result *types.ADLResult) string {
 	eventID := k.generateADLEventID(ctx)
 
 	fund := k.GetGlobalInsuranceFund(ctx)
@@ -283,6 +290,9 @@ func (k *Keeper) recordADLEvent(ctx sdk.Context, marketID string, reason types.A
 		TotalDeficit:         deficit,
 		PositionsAffected:    result.PositionsAffected,
 		TotalDeleveraged:     result.TotalDeleveraged,
+		DeficitCovered:       result.DeficitCovered,
+		BankruptSide:         bankruptSide,
+		Fills:                result.Fills,
 		Timestamp:            ctx.BlockTime(),
 	}
 
@@ -348,3 +358,33 @@ func (k *Keeper) GetADLRankings(ctx sdk.Context, marketID string, limit int) map
 
 	return rankings
 }
+
+// GetADLIndicator returns the ADL queue position of a trader's position in a
+// market, or nil if the trader has no position there
+func (k *Keeper) GetADLIndicator(ctx sdk.Context, trader, marketID string) *types.ADLIndicator {
+	position := k.perpetualKeeper.GetPosition(ctx, trader, marketID)
+	if position == nil {
+		return nil
+	}
+
+	side := position.Side.String()
+	queue := k.BuildADLQueue(ctx, marketID, side)
+	indicator := &types.ADLIndicator{
+		Trader:    trader,
+		MarketID:  marketID,
+		Side:      side,
+		QueueSize: len(queue.Positions),
+		Lights:    types.ADLLights(0, len(queue.Positions)),
+		Score:     math.LegacyZeroDec(),
+	}
+	for _, adlPos := range queue.Positions {
+		if adlPos.Trader == trader {
+			indicator.InQueue = true
+			indicator.ADLRanking = adlPos.ADLRanking
+			indicator.Lights = adlPos.Lights
+			indicator.Score = adlPos.Score
+			break
+		}
+	}
+	return indicator
+}
@@ -0,0 +1,235 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/clearinghouse/types"
+	perpkeeper "github.com/openalpha/perp-dex/x/perpetual/keeper"
+	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TestADLScore tests that profit and leverage both raise the ADL score
+func TestADLScore(t *testing.T) {
+	// 100 PnL on 1000 margin, 11000 notional: 10% x 10x leverage
+	score, pnlPercent, leverage := types.ADLScore(math.LegacyNewDec(100), math.LegacyNewDec(1000), math.LegacyNewDec(11000))
+	if !pnlPercent.Equal(math.LegacyNewDecWithPrec(1, 1)) {
+		t.Errorf("pnlPercent = %s, expected 0.1", pnlPercent)
+	}
+	if !leverage.Equal(math.LegacyNewDec(10)) {
+		t.Errorf("leverage = %s, expected 10", leverage)
+	}
+	if !score.Equal(math.LegacyOneDec()) {
+		t.Errorf("score = %s, expected 1", score)
+	}
+
+	// Losing positions are never ranked
+	score, _, _ = types.ADLScore(math.LegacyNewDec(-100), math.LegacyNewDec(1000), math.LegacyNewDec(11000))
+	if !score.IsZero() {
+		t.Errorf("score of losing position = %s, expected 0", score)
+	}
+}
+
+// TestADLLights tests the quintile mapping of queue rankings to indicator lights
+func TestADLLights(t *testing.T) {
+	tests := []struct {
+		ranking, size, expected int
+	}{
+		{1, 10, 5},
+		{2, 10, 5},
+		{3, 10, 4},
+		{10, 10, 1},
+		{1, 1, 5},
+		{2, 3, 4},
+		{3, 3, 2},
+		{0, 10, 1}, // not in queue
+	}
+	for _, tt := range tests {
+		if got := types.ADLLights(tt.ranking, tt.size); got != tt.expected {
+			t.Errorf("ADLLights(%d, %d) = %d, expected %d", tt.ranking, tt.size, got, tt.expected)
+		}
+	}
+}
+
+// TestRankADLPositions tests that the queue is ordered by score, then PnL
+func TestRankADLPositions(t *testing.T) {
+	positions := []*types.ADLPosition{
+		{Trader: "low", Score: math.LegacyNewDec(1), UnrealizedPnL: math.LegacyNewDec(500)},
+		{Trader: "high", Score: math.LegacyNewDec(3), UnrealizedPnL: math.LegacyNewDec(10)},
+		{Trader: "tie", Score: math.LegacyNewDec(1), UnrealizedPnL: math.LegacyNewDec(900)},
+	}
+	types.RankADLPositions(positions)
+
+	expected := []string{"high", "tie", "low"}
+	for i, pos := range positions {
+		if pos.Trader != expected[i] {
+			t.Errorf("position %d = %s, expected %s", i, pos.Trader, expected[i])
+		}
+		if pos.ADLRanking != i+1 {
+			t.Errorf("%s ranking = %d, expected %d", pos.Trader, pos.ADLRanking, i+1)
+		}
+	}
+	if positions[0].Lights != 5 || positions[2].Lights != 2 {
+		t.Errorf("lights = %d/%d, expected 5/2", positions[0].Lights, positions[2].Lights)
+	}
+}
+
+// setupADLPositions opens two profitable shorts ahead of a losing one in
+// BTC-USDC at a mark price of 48000: bob 2 @ 50000 (score 3.84) ranks ahead of
+// carol 1 @ 49000 (score 0.48), and dave 1 @ 47000 is never deleveraged
+func setupADLPositions(t *testing.T, ctx sdk.Context, pk *perpkeeper.Keeper) {
+	t.Helper()
+	dec := math.LegacyMustNewDecFromStr
+	for _, p := range []struct {
+		trader, size, entry string
+	}{
+		{"bob", "2", "50000"},
+		{"carol", "1", "49000"},
+		{"dave", "1", "47000"},
+	} {
+		pk.SetPosition(ctx, perpetualtypes.NewPosition(p.trader, "BTC-USDC", perpetualtypes.PositionSideShort, dec(p.size), dec(p.entry), dec("10000")))
+		account := perpetualtypes.NewAccount(p.trader)
+		account.LockedMargin = dec("10000")
+		pk.SetAccount(ctx, account)
+	}
+	pk.SetPrice(ctx, perpetualtypes.NewPriceInfo("BTC-USDC", dec("48000")))
+}
+
+// TestExecuteADL tests that a deficit is covered by deleveraging profitable
+// positions on the opposite side in queue order, each by at most half its
+// size, and that the profit covering the deficit is withheld from the trader
+func TestExecuteADL(t *testing.T) {
+	k, pk, ctx := setupHookedKeeper(t)
+	setupADLPositions(t, ctx, pk)
+	dec := math.LegacyMustNewDecFromStr
+
+	// Bob closes his capped 1 BTC for 2000, carol 0.5 BTC for 500
+	result, err := k.ExecuteADL(ctx, "BTC-USDC", "long", dec("3000"), types.ADLTriggerLargeDeficit)
+	if err != nil {
+		t.Fatalf("failed to execute ADL: %v", err)
+	}
+	if result.PositionsAffected != 2 || !result.DeficitCovered.Equal(dec("2500")) || !result.RemainingDeficit.Equal(dec("500")) {
+		t.Fatalf("expected 2500 covered by 2 positions and 500 left, got %+v", result)
+	}
+	for i, expected := range []struct {
+		trader, quantity, covered string
+	}{
+		{"bob", "1", "2000"},
+		{"carol", "0.5", "500"},
+	} {
+		fill := result.Fills[i]
+		if fill.Trader != expected.trader || !fill.Quantity.Equal(dec(expected.quantity)) || !fill.Covered.Equal(dec(expected.covered)) || fill.ADLRanking != i+1 {
+			t.Errorf("fill %d: expected %s %s covering %s, got %+v", i, expected.trader, expected.quantity, expected.covered, fill)
+		}
+	}
+
+	// The released margin is credited; the realized profit is not
+	for _, expected := range []struct {
+		trader, size, balance, locked string
+	}{
+		{"bob", "1", "5000", "5000"},
+		{"carol", "0.5", "5000", "5000"},
+		{"dave", "1", "0", "10000"},
+	} {
+		position := pk.GetPosition(ctx, expected.trader, "BTC-USDC")
+		account := pk.GetAccount(ctx, expected.trader)
+		if !position.Size.Equal(dec(expected.size)) || !position.Margin.Equal(dec(expected.locked)) {
+			t.Errorf("%s: expected size %s with margin %s, got %s with %s", expected.trader, expected.size, expected.locked, position.Size, position.Margin)
+		}
+		if !account.Balance.Equal(dec(expected.balance)) || !account.LockedMargin.Equal(dec(expected.locked)) {
+			t.Errorf("%s: expected balance %s and locked margin %s, got %s and %s", expected.trader, expected.balance, expected.locked, account.Balance, account.LockedMargin)
+		}
+	}
+
+	events := k.GetADLEvents(ctx, "BTC-USDC", 10)
+	if len(events) != 1 || events[0].EventID != result.EventID || events[0].BankruptSide != "long" || !events[0].DeficitCovered.Equal(dec("2500")) {
+		t.Errorf("expected the ADL event recorded, got %+v", events)
+	}
+
+	// A bankrupt short deleverages longs, and there are none
+	result, err = k.ExecuteADL(ctx, "BTC-USDC", "short", dec("100"), types.ADLTriggerLargeDeficit)
+	if err != nil || result.Success || result.PositionsAffected != 0 {
+		t.Errorf("expected nothing deleveraged, got %+v, %v", result, err)
+	}
+
+	config := types.DefaultADLConfig()
+	config.Enabled = false
+	k.SetADLConfig(ctx, config)
+	if _, err := k.ExecuteADL(ctx, "BTC-USDC", "long", dec("100"), types.ADLTriggerLargeDeficit); !errors.Is(err, types.ErrADLDisabled) {
+		t.Errorf("expected ErrADLDisabled, got %v", err)
+	}
+}
+
+// TestDeleveragePositionWithheldCoverage tests that only the part of the
+// realized profit needed for the deficit is withheld, and that a position
+// whose profit has turned into a loss covers nothing
+func TestDeleveragePositionWithheldCoverage(t *testing.T) {
+	k, pk, ctx := setupHookedKeeper(t)
+	setupADLPositions(t, ctx, pk)
+	dec := math.LegacyMustNewDecFromStr
+
+	// Carol realizes 1000 on her whole position; 300 of it covers the deficit
+	adlPos := &types.ADLPosition{Trader: "carol", MarketID: "BTC-USDC", Side: "short", ADLRanking: 2}
+	fill, err := k.deleveragePosition(ctx, adlPos, dec("1"), dec("48000"), dec("300"))
+	if err != nil {
+		t.Fatalf("failed to deleverage: %v", err)
+	}
+	if !fill.Covered.Equal(dec("300")) || !fill.Quantity.Equal(dec("1")) {
+		t.Errorf("expected 1 BTC closed covering 300, got %+v", fill)
+	}
+	if account := pk.GetAccount(ctx, "carol"); !account.Balance.Equal(dec("10700")) || !account.LockedMargin.IsZero() {
+		t.Errorf("expected the margin and 700 of profit credited, got balance %s, locked %s", account.Balance, account.LockedMargin)
+	}
+	if pk.GetPosition(ctx, "carol", "BTC-USDC") != nil {
+		t.Error("expected the position to be closed")
+	}
+	if history, _, _ := pk.GetClosedPositions(ctx, "carol", "", "", 10); len(history) != 1 || history[0].Reason != perpetualtypes.CloseReasonADL {
+		t.Errorf("expected the position recorded as closed by ADL, got %+v", history)
+	}
+
+	// Dave's short is at a loss at 48000: the loss is realized and nothing covered
+	adlPos = &types.ADLPosition{Trader: "dave", MarketID: "BTC-USDC", Side: "short"}
+	if fill, err = k.deleveragePosition(ctx, adlPos, dec("0.5"), dec("48000"), dec("300")); err != nil {
+		t.Fatalf("failed to deleverage: %v", err)
+	}
+	if !fill.Covered.IsZero() {
+		t.Errorf("expected nothing covered, got %s", fill.Covered)
+	}
+	if account := pk.GetAccount(ctx, "dave"); !account.Balance.Equal(dec("4500")) || !account.LockedMargin.Equal(dec("5000")) {
+		t.Errorf("expected 5000 margin less the 500 loss credited, got balance %s, locked %s", account.Balance, account.LockedMargin)
+	}
+
+	adlPos = &types.ADLPosition{Trader: "erin", MarketID: "BTC-USDC", Side: "short"}
+	if _, err := k.deleveragePosition(ctx, adlPos, dec("1"), dec("48000"), dec("300")); !errors.Is(err, types.ErrPositionNotFound) {
+		t.Errorf("expected ErrPositionNotFound, got %v", err)
+	}
+}
+
+// TestLiquidationV2TriggersADL tests that a backstop liquidation losing more
+// than the position's margin, with an empty insurance fund, deleverages the
+// opposite side for the rest
+func TestLiquidationV2TriggersADL(t *testing.T) {
+	k, pk, ctx := setupHookedKeeper(t)
+	setupADLPositions(t, ctx, pk)
+	dec := math.LegacyMustNewDecFromStr
+
+	// Alice's long loses 2000 on 1000 of margin
+	pk.SetPosition(ctx, perpetualtypes.NewPosition("alice", "BTC-USDC", perpetualtypes.PositionSideLong, dec("1"), dec("50000"), dec("1000")))
+	result, err := NewLiquidationEngineV2(k).ProcessLiquidation(ctx, "alice", "BTC-USDC", "")
+	if err != nil || !result.Success || result.Tier != types.TierBackstopLiquidation {
+		t.Fatalf("expected a backstop liquidation, got %+v, %v", result, err)
+	}
+
+	events := k.GetADLEvents(ctx, "BTC-USDC", 10)
+	if len(events) != 1 || events[0].BankruptSide != "long" || !events[0].TotalDeficit.Equal(dec("1000")) || !events[0].DeficitCovered.Equal(dec("1000")) {
+		t.Fatalf("expected ADL to cover the 1000 deficit, got %+v", events)
+	}
+	if fills := events[0].Fills; len(fills) != 1 || fills[0].Trader != "bob" || !fills[0].Quantity.Equal(dec("0.5")) {
+		t.Errorf("expected bob deleveraged by 0.5, got %+v", fills)
+	}
+	if position := pk.GetPosition(ctx, "bob", "BTC-USDC"); !position.Size.Equal(dec("1.5")) {
+		t.Errorf("expected bob's position reduced to 1.5, got %s", position.Size)
+	}
+}
@@ -0,0 +1,40 @@
+package keeper
+
+import (
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// SetHooks registers the modules that react to settled position changes and
+// liquidations. It may only be called once, while the app is wired; pass
+// every hook in one call.
+func (k *Keeper) SetHooks(hooks ...orderbooktypes.TradeHooks) {
+	if k.hooks != nil {
+		panic("cannot set clearinghouse trade hooks twice")
+	}
+	k.hooks = orderbooktypes.NewMultiTradeHooks(hooks...)
+}
+
+// afterPositionChanged calls the AfterPositionChanged hooks for a trader's
+// position in a market
+func (k *Keeper) afterPositionChanged(ctx sdk.Context, trader, marketID string) {
+	if k.hooks == nil {
+		return
+	}
+	if err := k.hooks.AfterPositionChanged(ctx, trader, marketID); err != nil {
+		k.Logger().Error("position hook failed", "trader", trader, "market_id", marketID, "error", err)
+	}
+}
+
+// afterLiquidation calls the AfterLiquidation hooks, then the
+// AfterPositionChanged hooks for the liquidated position
+func (k *Keeper) afterLiquidation(ctx sdk.Context, trader, marketID string, size, price math.LegacyDec) {
+	if k.hooks == nil {
+		return
+	}
+	if err := k.hooks.AfterLiquidation(ctx, trader, marketID, size, price); err != nil {
+		k.Logger().Error("liquidation hook failed", "trader", trader, "market_id", marketID, "error", err)
+	}
+	k.afterPositionChanged(ctx, trader, marketID)
+}
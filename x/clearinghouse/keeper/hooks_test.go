@@ -0,0 +1,85 @@
+package keeper
+
+import (
+	"testing"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	"cosmossdk.io/store"
+	"cosmossdk.io/store/metrics"
+	storetypes "cosmossdk.io/store/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+	perpkeeper "github.com/openalpha/perp-dex/x/perpetual/keeper"
+	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// recordingHooks records the position changes and liquidations it is told about
+type recordingHooks struct {
+	positions    []string
+	liquidations []string
+}
+
+func (h *recordingHooks) AfterTradeExecuted(ctx sdk.Context, trade *orderbooktypes.Trade) error {
+	return nil
+}
+
+func (h *recordingHooks) AfterPositionChanged(ctx sdk.Context, trader, marketID string) error {
+	h.positions = append(h.positions, trader+"/"+marketID)
+	return nil
+}
+
+func (h *recordingHooks) AfterLiquidation(ctx sdk.Context, trader, marketID string, size, price math.LegacyDec) error {
+	h.liquidations = append(h.liquidations, trader+"/"+marketID+"/"+size.String()+"@"+price.String())
+	return nil
+}
+
+// setupHookedKeeper returns a clearinghouse keeper over a store-backed
+// perpetual keeper
+func setupHookedKeeper(t *testing.T) (*Keeper, *perpkeeper.Keeper, sdk.Context) {
+	t.Helper()
+
+	perpKey := storetypes.NewKVStoreKey("perpetual")
+	chKey := storetypes.NewKVStoreKey("clearinghouse")
+	db := dbm.NewMemDB()
+	stateStore := store.NewCommitMultiStore(db, log.NewNopLogger(), metrics.NewNoOpMetrics())
+	stateStore.MountStoreWithDB(perpKey, storetypes.StoreTypeIAVL, db)
+	stateStore.MountStoreWithDB(chKey, storetypes.StoreTypeIAVL, db)
+	if err := stateStore.LoadLatestVersion(); err != nil {
+		t.Fatalf("failed to load store: %v", err)
+	}
+	ctx := sdk.NewContext(stateStore, cmtproto.Header{}, false, log.NewNopLogger())
+
+	cdc := codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+	pk := perpkeeper.NewKeeper(cdc, perpKey, nil, "", log.NewNopLogger())
+	return NewKeeper(cdc, chKey, pk, nil, log.NewNopLogger()), pk, ctx
+}
+
+// TestLiquidationHooks tests that a liquidation reaches the trade hooks after
+// the position has been closed
+func TestLiquidationHooks(t *testing.T) {
+	k, pk, ctx := setupHookedKeeper(t)
+	hooks := &recordingHooks{}
+	k.SetHooks(hooks)
+
+	dec := math.LegacyMustNewDecFromStr
+	position := perpetualtypes.NewPosition("alice", "BTC-USDC", perpetualtypes.PositionSideLong, dec("1"), dec("50000"), dec("5000"))
+	pk.SetPosition(ctx, position)
+	if _, err := NewLiquidationEngine(k).ExecuteLiquidation(ctx, position, dec("48000")); err != nil {
+		t.Fatalf("failed to liquidate: %v", err)
+	}
+
+	if pk.GetPosition(ctx, "alice", "BTC-USDC") != nil {
+		t.Fatal("expected the position to be closed")
+	}
+	if want := "alice/BTC-USDC/" + dec("1").String() + "@" + dec("48000").String(); len(hooks.liquidations) != 1 || hooks.liquidations[0] != want {
+		t.Errorf("expected liquidation %s, got %v", want, hooks.liquidations)
+	}
+	if len(hooks.positions) != 1 || hooks.positions[0] != "alice/BTC-USDC" {
+		t.Errorf("expected the position change, got %v", hooks.positions)
+	}
+}
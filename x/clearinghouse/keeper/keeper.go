@@ -4,6 +4,9 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"cosmossdk.io/log"
 	"cosmossdk.io/math"
@@ -11,6 +14,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/openalpha/perp-dex/x/clearinghouse/types"
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
 	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
 )
 
@@ -18,6 +22,10 @@ import (
 var (
 	LiquidationKeyPrefix  = []byte{0x01}
 	LiquidationCounterKey = []byte{0x02}
+
+	// LiquidationStateKeyPrefix keeps the state of positions being
+	// liquidated in steps, keyed by "trader:market"
+	LiquidationStateKeyPrefix = []byte{0x30}
 )
 
 // PerpetualKeeper defines the expected interface for the perpetual module
@@ -31,6 +39,7 @@ type PerpetualKeeper interface {
 	SetAccount(ctx sdk.Context, account *perpetualtypes.Account)
 	SetPosition(ctx sdk.Context, position *perpetualtypes.Position)
 	DeletePosition(ctx sdk.Context, trader, marketID string)
+	RecordClosedPosition(ctx sdk.Context, position *perpetualtypes.Position, reason perpetualtypes.CloseReason) *perpetualtypes.ClosedPosition
 }
 
 // OrderbookKeeper defines the expected interface for the orderbook module
@@ -45,6 +54,7 @@ type Keeper struct {
 	storeKey        storetypes.StoreKey
 	perpetualKeeper PerpetualKeeper
 	orderbookKeeper OrderbookKeeper
+	hooks           orderbooktypes.TradeHooks // optional
 	logger          log.Logger
 }
 
@@ -119,6 +129,100 @@ func (k *Keeper) GetAllLiquidations(ctx sdk.Context, limit int) []*types.Liquida
 	return liquidations
 }
 
+// GetLiquidationHistory returns the liquidation steps recorded for a
+// position, oldest first
+func (k *Keeper) GetLiquidationHistory(ctx sdk.Context, trader, marketID string) []*types.Liquidation {
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, LiquidationKeyPrefix)
+	defer iterator.Close()
+
+	var history []*types.Liquidation
+	for ; iterator.Valid(); iterator.Next() {
+		var liquidation types.Liquidation
+		if err := json.Unmarshal(iterator.Value(), &liquidation); err != nil {
+			continue
+		}
+		if liquidation.Trader == trader && liquidation.MarketID == marketID {
+			history = append(history, &liquidation)
+		}
+	}
+	// IDs sort as strings in the store ("liq-10" before "liq-9")
+	sort.Slice(history, func(i, j int) bool {
+		return liquidationSeq(history[i].LiquidationID) < liquidationSeq(history[j].LiquidationID)
+	})
+	return history
+}
+
+// liquidationSeq returns the counter value of a generated liquidation ID
+func liquidationSeq(liquidationID string) uint64 {
+	n, _ := strconv.ParseUint(strings.TrimPrefix(liquidationID, "liq-"), 10, 64)
+	return n
+}
+
+// liquidationStateKey returns the key of a position's liquidation state
+func liquidationStateKey(trader, marketID string) string {
+	return fmt.Sprintf("%s:%s", trader, marketID)
+}
+
+// GetLiquidationState returns the state of a position being liquidated in
+// steps, or nil
+func (k *Keeper) GetLiquidationState(ctx sdk.Context, trader, marketID string) *types.LiquidationState {
+	return k.getLiquidationState(ctx, liquidationStateKey(trader, marketID))
+}
+
+func (k *Keeper) getLiquidationState(ctx sdk.Context, stateKey string) *types.LiquidationState {
+	store := k.GetStore(ctx)
+	bz := store.Get(append(LiquidationStateKeyPrefix, []byte(stateKey)...))
+	if bz == nil {
+		return nil
+	}
+	var state types.LiquidationState
+	if err := json.Unmarshal(bz, &state); err != nil {
+		k.Logger().Error("failed to unmarshal liquidation state", "key", stateKey, "error", err)
+		return nil
+	}
+	return &state
+}
+
+// SetLiquidationState saves the state of a position being liquidated in steps
+func (k *Keeper) SetLiquidationState(ctx sdk.Context, state *types.LiquidationState) {
+	bz, err := json.Marshal(state)
+	if err != nil {
+		k.Logger().Error("failed to marshal liquidation state", "key", state.PositionID, "error", err)
+		return
+	}
+	store := k.GetStore(ctx)
+	store.Set(append(LiquidationStateKeyPrefix, []byte(state.PositionID)...), bz)
+}
+
+// DeleteLiquidationState removes a position's liquidation state
+func (k *Keeper) DeleteLiquidationState(ctx sdk.Context, trader, marketID string) {
+	k.deleteLiquidationState(ctx, liquidationStateKey(trader, marketID))
+}
+
+func (k *Keeper) deleteLiquidationState(ctx sdk.Context, stateKey string) {
+	store := k.GetStore(ctx)
+	store.Delete(append(LiquidationStateKeyPrefix, []byte(stateKey)...))
+}
+
+// GetAllLiquidationStates returns the state of every position being
+// liquidated in steps
+func (k *Keeper) GetAllLiquidationStates(ctx sdk.Context) []*types.LiquidationState {
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, LiquidationStateKeyPrefix)
+	defer iterator.Close()
+
+	var states []*types.LiquidationState
+	for ; iterator.Valid(); iterator.Next() {
+		var state types.LiquidationState
+		if err := json.Unmarshal(iterator.Value(), &state); err != nil {
+			continue
+		}
+		states = append(states, &state)
+	}
+	return states
+}
+
 // generateLiquidationID generates a unique liquidation ID
 func (k *Keeper) generateLiquidationID(ctx sdk.Context) string {
 	store := k.GetStore(ctx)
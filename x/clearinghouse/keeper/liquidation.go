@@ -1,6 +1,10 @@
 package keeper
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"cosmossdk.io/math"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/openalpha/perp-dex/x/clearinghouse/types"
@@ -17,11 +21,17 @@ type LiquidationStats struct {
 // LiquidationEngine handles the liquidation process
 type LiquidationEngine struct {
 	keeper *Keeper
+	config types.LiquidationConfig
 }
 
 // NewLiquidationEngine creates a new liquidation engine
 func NewLiquidationEngine(keeper *Keeper) *LiquidationEngine {
-	return &LiquidationEngine{keeper: keeper}
+	return NewLiquidationEngineWithConfig(keeper, types.DefaultLiquidationConfig())
+}
+
+// NewLiquidationEngineWithConfig creates a new liquidation engine with custom config
+func NewLiquidationEngineWithConfig(keeper *Keeper, config types.LiquidationConfig) *LiquidationEngine {
+	return &LiquidationEngine{keeper: keeper, config: config}
 }
 
 // LiquidationResult contains the result of a liquidation
@@ -32,13 +42,13 @@ type LiquidationResult struct {
 	PenaltyPaid       math.LegacyDec
 	LiquidatorReward  math.LegacyDec // Liquidator reward (30% of penalty)
 	InsuranceFundFee  math.LegacyDec // Insurance fund share (70% of penalty)
+	IsPartial         bool
+	RemainingSize     math.LegacyDec // Size left open by a partial liquidation
+	CooldownEndTime   time.Time      // No further partial liquidation before this
 	Success           bool
 	Error             error
 }
 
-// Liquidator reward rate (30% of penalty)
-var LiquidatorRewardRate = math.LegacyNewDecWithPrec(3, 1) // 0.3 = 30%
-
 // CheckAndLiquidate checks if a position should be liquidated and executes if needed
 func (le *LiquidationEngine) CheckAndLiquidate(ctx sdk.Context, trader, marketID string) (*LiquidationResult, error) {
 	// Get position
@@ -61,7 +71,41 @@ func (le *LiquidationEngine) CheckAndLiquidate(ctx sdk.Context, trader, marketID
 	}
 
 	// Execute liquidation
-	return le.ExecuteLiquidation(ctx, position, markPrice)
+	return le.Liquidate(ctx, position, markPrice, "")
+}
+
+// Liquidate liquidates an unhealthy position. Positions above
+// LargePositionThreshold are closed in steps: each step closes only the size
+// needed to restore their margin above maintenance plus
+// PartialLiquidationBuffer, and starts a cooldown in which the position is
+// not liquidated again unless it falls below the backstop threshold. Other
+// positions, and positions no partial close can restore, are closed in full.
+func (le *LiquidationEngine) Liquidate(
+	ctx sdk.Context,
+	position *perpetualtypes.Position,
+	markPrice math.LegacyDec,
+	liquidator string,
+) (*LiquidationResult, error) {
+	maintenanceMargin := position.Size.Mul(markPrice).Mul(le.config.MinMaintenanceMarginRate)
+	equity := position.Margin.Add(position.CalculateUnrealizedPnL(markPrice))
+	backstop := equity.LT(maintenanceMargin.Mul(le.config.BackstopThreshold))
+
+	state := le.keeper.GetLiquidationState(ctx, position.Trader, position.MarketID)
+	if state != nil && !backstop && !state.CanLiquidate(ctx.BlockTime()) {
+		return nil, fmt.Errorf("%w: until %s", types.ErrLiquidationCooldown, state.CooldownEndTime.UTC().Format(time.RFC3339))
+	}
+
+	closeSize := position.Size
+	if !backstop && position.Size.Mul(markPrice).GT(le.config.LargePositionThreshold) {
+		closeSize = le.config.PartialLiquidationSize(position.Size, equity, markPrice)
+		if closeSize.IsZero() {
+			return nil, types.ErrPositionHealthy
+		}
+	}
+	if closeSize.GTE(position.Size) {
+		return le.ExecuteLiquidationWithReward(ctx, position, markPrice, liquidator)
+	}
+	return le.executePartialLiquidation(ctx, position, markPrice, closeSize, state, liquidator)
 }
 
 // ExecuteLiquidation executes the liquidation of an unhealthy position
@@ -83,24 +127,11 @@ func (le *LiquidationEngine) ExecuteLiquidationWithReward(
 	liquidator string,
 ) (*LiquidationResult, error) {
 	// Calculate liquidation penalty (1% of notional value)
-	penaltyRate := math.LegacyNewDecWithPrec(1, 2) // 1%
 	notionalValue := position.Size.Mul(markPrice)
-	penalty := notionalValue.Mul(penaltyRate)
-
-	// Calculate liquidator reward and insurance fund share
-	// Liquidator gets 30% of penalty, insurance fund gets 70%
-	liquidatorReward := penalty.Mul(LiquidatorRewardRate)        // 30%
-	insuranceFundShare := penalty.Sub(liquidatorReward)          // 70%
+	penalty := notionalValue.Mul(le.config.LiquidationPenaltyRate)
 
 	// Calculate margin deficit (using 2.5% maintenance margin rate)
-	maintenanceMarginRate := math.LegacyNewDecWithPrec(25, 3) // 2.5% (updated from 5%)
-	maintenanceMargin := position.Size.Mul(markPrice).Mul(maintenanceMarginRate)
-	unrealizedPnL := position.CalculateUnrealizedPnL(markPrice)
-	equity := position.Margin.Add(unrealizedPnL)
-	marginDeficit := maintenanceMargin.Sub(equity)
-	if marginDeficit.IsNegative() {
-		marginDeficit = math.LegacyZeroDec()
-	}
+	marginDeficit := le.marginDeficit(position, markPrice)
 
 	// Generate liquidation ID
 	liquidationID := le.keeper.generateLiquidationID(ctx)
@@ -117,6 +148,13 @@ func (le *LiquidationEngine) ExecuteLiquidationWithReward(
 		marginDeficit,
 		penalty,
 	)
+	liquidation.Timestamp = ctx.BlockTime()
+	liquidation.Step = 1
+	liquidation.RemainingSize = math.LegacyZeroDec()
+	if state := le.keeper.GetLiquidationState(ctx, position.Trader, position.MarketID); state != nil {
+		liquidation.Step = state.LiquidationCount + 1
+		le.keeper.DeleteLiquidationState(ctx, position.Trader, position.MarketID)
+	}
 
 	// Close the position at mark price
 	// In production, this would create a market order to close the position
@@ -146,71 +184,25 @@ func (le *LiquidationEngine) ExecuteLiquidationWithReward(
 		le.keeper.perpetualKeeper.SetAccount(ctx, account)
 	}
 
-	// Distribute liquidator reward
-	if liquidator != "" && liquidatorReward.IsPositive() {
-		liquidatorAccount := le.keeper.perpetualKeeper.GetOrCreateAccount(ctx, liquidator)
-		liquidatorAccount.Balance = liquidatorAccount.Balance.Add(liquidatorReward)
-		le.keeper.perpetualKeeper.SetAccount(ctx, liquidatorAccount)
-
-		le.keeper.Logger().Info("Liquidator reward distributed",
-			"liquidator", liquidator,
-			"reward", liquidatorReward.String(),
-		)
-	} else {
-		// If no liquidator specified, entire penalty goes to insurance fund
-		insuranceFundShare = penalty
-		liquidatorReward = math.LegacyZeroDec()
-	}
-
-	// Transfer to insurance fund
-	if insuranceFundShare.IsPositive() {
-		if err := le.keeper.DepositToInsuranceFund(ctx, GlobalFundID, insuranceFundShare,
-			types.InsuranceEventLiquidationPenalty, liquidationID); err != nil {
-			le.keeper.Logger().Error("Failed to deposit to insurance fund",
-				"amount", insuranceFundShare.String(),
-				"error", err,
-			)
-		}
-	}
+	// Distribute the penalty between the liquidator and the insurance fund
+	liquidatorReward, insuranceFundShare := le.distributePenalty(ctx, liquidationID, penalty, liquidator)
 
 	// Check for bankruptcy (loss exceeds margin - socialized loss scenario)
 	totalLoss := realizedPnL.Add(penalty)
 	if totalLoss.IsNegative() && totalLoss.Abs().GT(position.Margin) {
-		deficit := totalLoss.Abs().Sub(position.Margin)
-
-		// Try to cover with insurance fund
-		covered, remaining, _ := le.keeper.CoverDeficit(ctx, position.MarketID, deficit, liquidationID)
-
-		le.keeper.Logger().Info("Bankruptcy detected during liquidation",
-			"trader", position.Trader,
-			"market_id", position.MarketID,
-			"deficit", deficit.String(),
-			"covered_by_insurance", covered.String(),
-			"remaining", remaining.String(),
-		)
-
-		// If insurance fund cannot cover, trigger ADL
-		if remaining.IsPositive() && le.keeper.ShouldTriggerADL(ctx, remaining) {
-			adlResult, adlErr := le.keeper.ExecuteADL(ctx, position.MarketID, remaining, types.ADLTriggerLargeDeficit)
-			if adlErr != nil {
-				le.keeper.Logger().Error("ADL execution failed",
-					"error", adlErr,
-				)
-			} else if adlResult != nil {
-				le.keeper.Logger().Info("ADL executed",
-					"positions_affected", adlResult.PositionsAffected,
-					"deficit_covered", adlResult.DeficitCovered.String(),
-				)
-			}
-		}
+		le.keeper.coverBankruptcy(ctx, position, totalLoss.Abs().Sub(position.Margin), liquidationID)
 	}
 
-	// Delete the position
+	// Record history and delete the position
+	position.RecordClose(position.Size, markPrice, realizedPnL)
+	position.AddFee(penalty)
+	le.keeper.perpetualKeeper.RecordClosedPosition(ctx, position, perpetualtypes.CloseReasonLiquidation)
 	le.keeper.perpetualKeeper.DeletePosition(ctx, position.Trader, position.MarketID)
 
 	// Mark liquidation as executed
 	liquidation.Status = types.LiquidationStatusExecuted
 	le.keeper.SetLiquidation(ctx, liquidation)
+	le.keeper.afterLiquidation(ctx, position.Trader, position.MarketID, position.Size, markPrice)
 
 	// Emit liquidation event
 	ctx.EventManager().EmitEvent(
@@ -227,6 +219,8 @@ func (le *LiquidationEngine) ExecuteLiquidationWithReward(
 			sdk.NewAttribute("liquidator", liquidator),
 			sdk.NewAttribute("liquidator_reward", liquidatorReward.String()),
 			sdk.NewAttribute("insurance_fund_share", insuranceFundShare.String()),
+			sdk.NewAttribute("partial", "false"),
+			sdk.NewAttribute("step", fmt.Sprint(liquidation.Step)),
 		),
 	)
 
@@ -246,10 +240,227 @@ func (le *LiquidationEngine) ExecuteLiquidationWithReward(
 		PenaltyPaid:      penalty,
 		LiquidatorReward: liquidatorReward,
 		InsuranceFundFee: insuranceFundShare,
+		RemainingSize:    math.LegacyZeroDec(),
 		Success:          true,
 	}, nil
 }
 
+// executePartialLiquidation closes part of a position at the mark price. The
+// PnL realized on the closed size and the penalty are settled through the
+// account balance and taken from the position's margin, which stays locked
+// for the rest of the position, so the close lowers the maintenance margin
+// without releasing the equity backing it.
+func (le *LiquidationEngine) executePartialLiquidation(
+	ctx sdk.Context,
+	position *perpetualtypes.Position,
+	markPrice, closeSize math.LegacyDec,
+	state *types.LiquidationState,
+	liquidator string,
+) (*LiquidationResult, error) {
+	penalty := closeSize.Mul(markPrice).Mul(le.config.LiquidationPenaltyRate)
+	marginDeficit := le.marginDeficit(position, markPrice)
+	liquidationID := le.keeper.generateLiquidationID(ctx)
+
+	priceDiff := markPrice.Sub(position.EntryPrice)
+	if position.Side == perpetualtypes.PositionSideShort {
+		priceDiff = priceDiff.Neg()
+	}
+	realizedPnL := closeSize.Mul(priceDiff)
+
+	marginChange := realizedPnL.Sub(penalty)
+	position.Margin = position.Margin.Add(marginChange)
+	account := le.keeper.perpetualKeeper.GetAccount(ctx, position.Trader)
+	if account != nil {
+		account.Balance = account.Balance.Add(marginChange)
+		account.LockedMargin = account.LockedMargin.Add(marginChange)
+		if account.LockedMargin.IsNegative() {
+			account.LockedMargin = math.LegacyZeroDec()
+		}
+		le.keeper.perpetualKeeper.SetAccount(ctx, account)
+	}
+
+	position.ReduceSize(closeSize)
+	position.RecordClose(closeSize, markPrice, realizedPnL)
+	position.AddFee(penalty)
+	le.keeper.perpetualKeeper.SetPosition(ctx, position)
+
+	liquidatorReward, insuranceFundShare := le.distributePenalty(ctx, liquidationID, penalty, liquidator)
+
+	// Track the steps and hold off the next one until the cooldown ends
+	if state == nil {
+		state = types.NewLiquidationState(liquidationStateKey(position.Trader, position.MarketID),
+			position.Trader, position.MarketID, position.Size.Add(closeSize))
+	}
+	state.UpdateAfterLiquidation(closeSize, penalty, types.TierPartialLiquidation, ctx.BlockTime())
+	state.RemainingSize = position.Size
+	state.StartCooldown(ctx.BlockTime(), le.config.CooldownPeriod)
+	le.keeper.SetLiquidationState(ctx, state)
+
+	liquidation := types.NewLiquidation(
+		liquidationID,
+		position.Trader,
+		position.MarketID,
+		closeSize,
+		position.EntryPrice,
+		markPrice,
+		position.LiquidationPrice,
+		marginDeficit,
+		penalty,
+	)
+	liquidation.Status = types.LiquidationStatusExecuted
+	liquidation.Timestamp = ctx.BlockTime()
+	liquidation.Partial = true
+	liquidation.Step = state.LiquidationCount
+	liquidation.RemainingSize = position.Size
+	le.keeper.SetLiquidation(ctx, liquidation)
+	le.keeper.afterLiquidation(ctx, position.Trader, position.MarketID, closeSize, markPrice)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"liquidation",
+			sdk.NewAttribute("liquidation_id", liquidationID),
+			sdk.NewAttribute("trader", position.Trader),
+			sdk.NewAttribute("market_id", position.MarketID),
+			sdk.NewAttribute("position_size", closeSize.String()),
+			sdk.NewAttribute("entry_price", position.EntryPrice.String()),
+			sdk.NewAttribute("mark_price", markPrice.String()),
+			sdk.NewAttribute("realized_pnl", realizedPnL.String()),
+			sdk.NewAttribute("penalty", penalty.String()),
+			sdk.NewAttribute("liquidator", liquidator),
+			sdk.NewAttribute("liquidator_reward", liquidatorReward.String()),
+			sdk.NewAttribute("insurance_fund_share", insuranceFundShare.String()),
+			sdk.NewAttribute("partial", "true"),
+			sdk.NewAttribute("step", fmt.Sprint(liquidation.Step)),
+			sdk.NewAttribute("remaining_size", position.Size.String()),
+			sdk.NewAttribute("cooldown_end", state.CooldownEndTime.UTC().Format(time.RFC3339)),
+		),
+	)
+
+	le.keeper.Logger().Info("Position partially liquidated",
+		"trader", position.Trader,
+		"market", position.MarketID,
+		"step", liquidation.Step,
+		"size", closeSize.String(),
+		"remaining_size", position.Size.String(),
+		"mark_price", markPrice.String(),
+	)
+
+	return &LiquidationResult{
+		LiquidationID:    liquidationID,
+		LiquidatedSize:   closeSize,
+		LiquidationPrice: markPrice,
+		PenaltyPaid:      penalty,
+		LiquidatorReward: liquidatorReward,
+		InsuranceFundFee: insuranceFundShare,
+		IsPartial:        true,
+		RemainingSize:    position.Size,
+		CooldownEndTime:  state.CooldownEndTime,
+		Success:          true,
+	}, nil
+}
+
+// coverBankruptcy covers the loss a liquidated position leaves beyond its
+// margin from the insurance funds, and by ADL against the opposite side when
+// the funds fall short
+func (k *Keeper) coverBankruptcy(ctx sdk.Context, position *perpetualtypes.Position, deficit math.LegacyDec, liquidationID string) {
+	covered, remaining, _ := k.CoverDeficit(ctx, position.MarketID, deficit, liquidationID)
+
+	k.Logger().Info("Bankruptcy detected during liquidation",
+		"trader", position.Trader,
+		"market_id", position.MarketID,
+		"deficit", deficit.String(),
+		"covered_by_insurance", covered.String(),
+		"remaining", remaining.String(),
+	)
+
+	// If insurance fund cannot cover, trigger ADL
+	if remaining.IsPositive() && k.ShouldTriggerADL(ctx, remaining) {
+		adlResult, adlErr := k.ExecuteADL(ctx, position.MarketID, position.Side.String(), remaining, types.ADLTriggerLargeDeficit)
+		if adlErr != nil {
+			k.Logger().Error("ADL execution failed",
+				"error", adlErr,
+			)
+		} else if adlResult != nil {
+			k.Logger().Info("ADL executed",
+				"positions_affected", adlResult.PositionsAffected,
+				"deficit_covered", adlResult.DeficitCovered.String(),
+			)
+		}
+	}
+}
+
+// marginDeficit returns how far a position's equity is below its
+// maintenance margin, or zero
+func (le *LiquidationEngine) marginDeficit(position *perpetualtypes.Position, markPrice math.LegacyDec) math.LegacyDec {
+	maintenanceMargin := position.Size.Mul(markPrice).Mul(le.config.MinMaintenanceMarginRate)
+	equity := position.Margin.Add(position.CalculateUnrealizedPnL(markPrice))
+	deficit := maintenanceMargin.Sub(equity)
+	if deficit.IsNegative() {
+		return math.LegacyZeroDec()
+	}
+	return deficit
+}
+
+// distributePenalty pays the liquidator its share of a liquidation penalty
+// and deposits the rest in the insurance fund. Without a liquidator the
+// whole penalty goes to the fund.
+func (le *LiquidationEngine) distributePenalty(
+	ctx sdk.Context,
+	liquidationID string,
+	penalty math.LegacyDec,
+	liquidator string,
+) (liquidatorReward, insuranceFundShare math.LegacyDec) {
+	// Liquidator gets 30% of penalty, insurance fund gets 70%
+	liquidatorReward = penalty.Mul(le.config.LiquidatorRewardRate)
+	insuranceFundShare = penalty.Sub(liquidatorReward)
+
+	if liquidator != "" && liquidatorReward.IsPositive() {
+		liquidatorAccount := le.keeper.perpetualKeeper.GetOrCreateAccount(ctx, liquidator)
+		liquidatorAccount.Balance = liquidatorAccount.Balance.Add(liquidatorReward)
+		le.keeper.perpetualKeeper.SetAccount(ctx, liquidatorAccount)
+
+		le.keeper.Logger().Info("Liquidator reward distributed",
+			"liquidator", liquidator,
+			"reward", liquidatorReward.String(),
+		)
+	} else {
+		// If no liquidator specified, entire penalty goes to insurance fund
+		insuranceFundShare = penalty
+		liquidatorReward = math.LegacyZeroDec()
+	}
+
+	// Transfer to insurance fund
+	if insuranceFundShare.IsPositive() {
+		if err := le.keeper.DepositToInsuranceFund(ctx, GlobalFundID, insuranceFundShare,
+			types.InsuranceEventLiquidationPenalty, liquidationID); err != nil {
+			le.keeper.Logger().Error("Failed to deposit to insurance fund",
+				"amount", insuranceFundShare.String(),
+				"error", err,
+			)
+		}
+	}
+	return liquidatorReward, insuranceFundShare
+}
+
+// pruneLiquidationStates drops the step state of positions whose cooldown
+// has ended and that are healthy again or closed, so their next liquidation
+// starts over at step 1
+func (le *LiquidationEngine) pruneLiquidationStates(ctx sdk.Context) {
+	for _, state := range le.keeper.GetAllLiquidationStates(ctx) {
+		if !state.CanLiquidate(ctx.BlockTime()) {
+			continue
+		}
+		position := le.keeper.perpetualKeeper.GetPosition(ctx, state.Trader, state.MarketID)
+		if position != nil {
+			priceInfo := le.keeper.perpetualKeeper.GetPrice(ctx, state.MarketID)
+			if priceInfo == nil || !position.IsHealthy(priceInfo.MarkPrice) {
+				continue
+			}
+		}
+		le.keeper.deleteLiquidationState(ctx, state.PositionID)
+	}
+}
+
 // EndBlockLiquidations checks all positions and liquidates unhealthy ones
 // Called at the end of each block
 // Returns statistics about liquidations performed
@@ -259,6 +470,8 @@ func (le *LiquidationEngine) EndBlockLiquidations(ctx sdk.Context) LiquidationSt
 		TotalPenalties: math.LegacyZeroDec(),
 	}
 
+	le.pruneLiquidationStates(ctx)
+
 	// Get all unhealthy positions
 	unhealthyPositions := le.keeper.GetUnhealthyPositions(ctx)
 
@@ -273,8 +486,17 @@ func (le *LiquidationEngine) EndBlockLiquidations(ctx sdk.Context) LiquidationSt
 			continue
 		}
 
+		// GetUnhealthyPositions screens at a wider margin than the engine
+		// liquidates at
+		if position.IsHealthy(priceInfo.MarkPrice) {
+			continue
+		}
+
 		// Execute liquidation
-		result, err := le.ExecuteLiquidation(ctx, position, priceInfo.MarkPrice)
+		result, err := le.Liquidate(ctx, position, priceInfo.MarkPrice, "")
+		if errors.Is(err, types.ErrLiquidationCooldown) || errors.Is(err, types.ErrPositionHealthy) {
+			continue
+		}
 		if err != nil {
 			le.keeper.Logger().Error("Failed to liquidate position",
 				"trader", health.Trader,
@@ -328,7 +550,7 @@ func (le *LiquidationEngine) TriggerLiquidation(
 	}
 
 	// Execute liquidation with reward to the liquidator
-	result, err := le.ExecuteLiquidationWithReward(ctx, position, markPrice, liquidator)
+	result, err := le.Liquidate(ctx, position, markPrice, liquidator)
 	if err != nil {
 		return nil, err
 	}
@@ -359,7 +581,7 @@ func (le *LiquidationEngine) CheckAndLiquidateAll(ctx sdk.Context, trader string
 
 		// Check if position is healthy
 		if !position.IsHealthy(markPrice) {
-			result, err := le.ExecuteLiquidation(ctx, position, markPrice)
+			result, err := le.Liquidate(ctx, position, markPrice, "")
 			if err != nil {
 				le.keeper.Logger().Error("Failed to liquidate position in cascade",
 					"trader", trader,
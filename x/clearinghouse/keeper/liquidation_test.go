@@ -0,0 +1,124 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/clearinghouse/types"
+	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TestPartialLiquidation tests that a large position is closed in steps that
+// each restore its margin, that a cooldown holds off the next step unless the
+// position reaches the backstop threshold, and that every step is recorded
+func TestPartialLiquidation(t *testing.T) {
+	k, pk, ctx := setupHookedKeeper(t)
+	engine := NewLiquidationEngine(k)
+	dec := math.LegacyMustNewDecFromStr
+	start := time.Unix(1700000000, 0).UTC()
+	ctx = ctx.WithBlockTime(start)
+
+	// 10 BTC at 48000 with 2.45% margin: just below the 2.5% maintenance
+	position := perpetualtypes.NewPosition("alice", "BTC-USDC", perpetualtypes.PositionSideLong, dec("10"), dec("50000"), dec("31760"))
+	pk.SetPosition(ctx, position)
+	account := perpetualtypes.NewAccount("alice")
+	account.Balance = dec("40000")
+	account.LockedMargin = position.Margin
+	pk.SetAccount(ctx, account)
+	pk.SetPrice(ctx, perpetualtypes.NewPriceInfo("BTC-USDC", dec("48000")))
+	fundBefore := k.GetGlobalInsuranceFund(ctx).Balance
+
+	stats := engine.EndBlockLiquidations(ctx)
+	position = pk.GetPosition(ctx, "alice", "BTC-USDC")
+	if stats.LiquidationsCount != 1 || position == nil {
+		t.Fatalf("expected one partial liquidation, got %d (position %v)", stats.LiquidationsCount, position)
+	}
+	if !position.Size.Equal(dec("10").Sub(dec("1.714285714285714286"))) {
+		t.Errorf("expected 1.714285714285714286 closed, %s left", position.Size)
+	}
+	if !position.IsHealthy(dec("48000")) || position.CalculateMarginRatio(dec("48000")).LT(dec("0.0275")) {
+		t.Errorf("expected the margin restored above 2.75%%, got %s", position.CalculateMarginRatio(dec("48000")))
+	}
+	if account = pk.GetAccount(ctx, "alice"); !account.LockedMargin.Equal(position.Margin) {
+		t.Errorf("expected locked margin %s to follow the position's %s", account.LockedMargin, position.Margin)
+	}
+	// The realized loss and the penalty leave the balance, not just the
+	// locked margin, and the penalty is what the insurance fund receives
+	closed := dec("1.714285714285714286")
+	penalty := closed.Mul(dec("48000")).Mul(dec("0.01"))
+	if expected := dec("40000").Sub(closed.Mul(dec("2000"))).Sub(penalty); !account.Balance.Equal(expected) {
+		t.Errorf("expected balance %s, got %s", expected, account.Balance)
+	}
+	if !account.AvailableBalance().Equal(dec("40000").Sub(dec("31760"))) {
+		t.Errorf("expected available balance unchanged at 8240, got %s", account.AvailableBalance())
+	}
+	if paid := k.GetGlobalInsuranceFund(ctx).Balance.Sub(fundBefore); !paid.Equal(penalty) {
+		t.Errorf("expected the insurance fund to receive %s, got %s", penalty, paid)
+	}
+
+	// Still in cooldown when the price falls further
+	pk.SetPrice(ctx, perpetualtypes.NewPriceInfo("BTC-USDC", dec("47800")))
+	if _, err := engine.TriggerLiquidation(ctx, "bob", "alice", "BTC-USDC"); !errors.Is(err, types.ErrLiquidationCooldown) {
+		t.Fatalf("expected ErrLiquidationCooldown, got %v", err)
+	}
+	if stats := engine.EndBlockLiquidations(ctx.WithBlockTime(start.Add(29 * time.Second))); stats.LiquidationsCount != 0 {
+		t.Fatalf("expected no liquidation in cooldown, got %d", stats.LiquidationsCount)
+	}
+
+	ctx = ctx.WithBlockTime(start.Add(31 * time.Second))
+	before := pk.GetOrCreateAccount(ctx, "bob").Balance
+	result, err := engine.TriggerLiquidation(ctx, "bob", "alice", "BTC-USDC")
+	if err != nil || !result.IsPartial || !result.CooldownEndTime.Equal(start.Add(61*time.Second)) {
+		t.Fatalf("expected a second partial liquidation, got %+v, %v", result, err)
+	}
+	if paid := pk.GetAccount(ctx, "bob").Balance.Sub(before); !paid.Equal(result.LiquidatorReward) {
+		t.Errorf("expected the liquidator paid %s, got %s", result.LiquidatorReward, paid)
+	}
+
+	// The backstop threshold overrides the cooldown
+	pk.SetPrice(ctx, perpetualtypes.NewPriceInfo("BTC-USDC", dec("46000")))
+	if stats := engine.EndBlockLiquidations(ctx); stats.LiquidationsCount != 1 {
+		t.Fatalf("expected a backstop liquidation, got %d", stats.LiquidationsCount)
+	}
+	if pk.GetPosition(ctx, "alice", "BTC-USDC") != nil {
+		t.Fatal("expected the position to be closed")
+	}
+	if k.GetLiquidationState(ctx, "alice", "BTC-USDC") != nil {
+		t.Error("expected the liquidation state to be removed")
+	}
+
+	history := k.GetLiquidationHistory(ctx, "alice", "BTC-USDC")
+	if len(history) != 3 {
+		t.Fatalf("expected 3 steps, got %d", len(history))
+	}
+	times := []time.Time{start, ctx.BlockTime(), ctx.BlockTime()}
+	for i, step := range history {
+		if step.Step != i+1 || step.Partial != (i < 2) || !step.Timestamp.Equal(times[i]) {
+			t.Errorf("step %d: got %+v", i+1, step)
+		}
+	}
+	if !history[2].RemainingSize.IsZero() || !history[1].RemainingSize.Equal(history[2].PositionSize) {
+		t.Errorf("expected the last step to close the %s left, got %+v", history[1].RemainingSize, history[2])
+	}
+}
+
+// TestLiquidationSmallPosition tests that positions below the large position
+// threshold are still closed in full
+func TestLiquidationSmallPosition(t *testing.T) {
+	k, pk, ctx := setupHookedKeeper(t)
+	dec := math.LegacyMustNewDecFromStr
+
+	position := perpetualtypes.NewPosition("alice", "BTC-USDC", perpetualtypes.PositionSideLong, dec("1"), dec("50000"), dec("3176"))
+	pk.SetPosition(ctx, position)
+	pk.SetPrice(ctx, perpetualtypes.NewPriceInfo("BTC-USDC", dec("48000")))
+
+	result, err := NewLiquidationEngine(k).CheckAndLiquidate(ctx, "alice", "BTC-USDC")
+	if err != nil || result.IsPartial || !result.LiquidatedSize.Equal(dec("1")) {
+		t.Fatalf("expected a full liquidation, got %+v, %v", result, err)
+	}
+	if pk.GetPosition(ctx, "alice", "BTC-USDC") != nil {
+		t.Error("expected the position to be closed")
+	}
+}
@@ -1,20 +1,15 @@
 package keeper
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"cosmossdk.io/math"
-	storetypes "cosmossdk.io/store/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/openalpha/perp-dex/x/clearinghouse/types"
 	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
 )
 
-// Store key prefix for liquidation states - CRITICAL: Persist to chain storage
-var LiquidationStateKeyPrefix = []byte{0x30}
-
 // LiquidationEngineV2 implements the three-tier liquidation mechanism
 // aligned with Hyperliquid's liquidation system
 type LiquidationEngineV2 struct {
@@ -53,14 +48,7 @@ func (le *LiquidationEngineV2) GetConfig() types.LiquidationConfig {
 
 // saveLiquidationState persists a liquidation state to chain storage
 func (le *LiquidationEngineV2) saveLiquidationState(ctx sdk.Context, state *types.LiquidationState) {
-	store := le.keeper.GetStore(ctx)
-	key := append(LiquidationStateKeyPrefix, []byte(state.PositionID)...)
-	bz, err := json.Marshal(state)
-	if err != nil {
-		le.keeper.Logger().Error("failed to marshal liquidation state", "key", state.PositionID, "error", err)
-		return
-	}
-	store.Set(key, bz)
+	le.keeper.SetLiquidationState(ctx, state)
 	// Also keep in memory cache for fast access
 	le.liquidationStates[state.PositionID] = state
 }
@@ -73,45 +61,27 @@ func (le *LiquidationEngineV2) loadLiquidationState(ctx sdk.Context, stateKey st
 	}
 
 	// Load from chain storage
-	store := le.keeper.GetStore(ctx)
-	key := append(LiquidationStateKeyPrefix, []byte(stateKey)...)
-	bz := store.Get(key)
-	if bz == nil {
-		return nil
-	}
-
-	var state types.LiquidationState
-	if err := json.Unmarshal(bz, &state); err != nil {
-		le.keeper.Logger().Error("failed to unmarshal liquidation state", "key", stateKey, "error", err)
+	state := le.keeper.getLiquidationState(ctx, stateKey)
+	if state == nil {
 		return nil
 	}
 
 	// Cache in memory
-	le.liquidationStates[stateKey] = &state
-	return &state
+	le.liquidationStates[stateKey] = state
+	return state
 }
 
 // deleteLiquidationState removes a liquidation state from both store and cache
 func (le *LiquidationEngineV2) deleteLiquidationState(ctx sdk.Context, stateKey string) {
-	store := le.keeper.GetStore(ctx)
-	key := append(LiquidationStateKeyPrefix, []byte(stateKey)...)
-	store.Delete(key)
+	le.keeper.deleteLiquidationState(ctx, stateKey)
 	delete(le.liquidationStates, stateKey)
 }
 
 // loadAllLiquidationStates loads all liquidation states from chain storage into memory
 // Called during initialization to restore state after node restart
 func (le *LiquidationEngineV2) loadAllLiquidationStates(ctx sdk.Context) {
-	store := le.keeper.GetStore(ctx)
-	iterator := storetypes.KVStorePrefixIterator(store, LiquidationStateKeyPrefix)
-	defer iterator.Close()
-
-	for ; iterator.Valid(); iterator.Next() {
-		var state types.LiquidationState
-		if err := json.Unmarshal(iterator.Value(), &state); err != nil {
-			continue
-		}
-		le.liquidationStates[state.PositionID] = &state
+	for _, state := range le.keeper.GetAllLiquidationStates(ctx) {
+		le.liquidationStates[state.PositionID] = state
 	}
 
 	le.keeper.Logger().Info("loaded liquidation states from storage",
@@ -277,6 +247,12 @@ func (le *LiquidationEngineV2) executeMarketOrderLiquidation(
 		le.keeper.perpetualKeeper.SetAccount(ctx, account)
 	}
 
+	// A loss beyond the released margin is covered by the insurance funds,
+	// then by ADL
+	if deficit := position.Margin.Add(realizedPnL).Neg(); deficit.IsPositive() {
+		le.keeper.coverBankruptcy(ctx, position, deficit, liquidationID)
+	}
+
 	// Distribute liquidator reward
 	if liquidator != "" && liquidatorReward.IsPositive() {
 		liquidatorAccount := le.keeper.perpetualKeeper.GetOrCreateAccount(ctx, liquidator)
@@ -302,11 +278,15 @@ func (le *LiquidationEngineV2) executeMarketOrderLiquidation(
 	liquidation.Status = types.LiquidationStatusExecuted
 	le.keeper.SetLiquidation(ctx, liquidation)
 
-	// Delete the position
+	// Record history and delete the position
+	position.RecordClose(liquidatedSize, health.MarkPrice, realizedPnL)
+	position.AddFee(penalty)
+	le.keeper.perpetualKeeper.RecordClosedPosition(ctx, position, perpetualtypes.CloseReasonLiquidation)
 	le.keeper.perpetualKeeper.DeletePosition(ctx, position.Trader, position.MarketID)
+	le.keeper.afterLiquidation(ctx, position.Trader, position.MarketID, liquidatedSize, health.MarkPrice)
 
 	// Update state
-	state.UpdateAfterLiquidation(liquidatedSize, penalty, types.TierMarketOrder)
+	state.UpdateAfterLiquidation(liquidatedSize, penalty, types.TierMarketOrder, ctx.BlockTime())
 
 	// Clean up state if fully liquidated (with persistence)
 	if state.IsFullyLiquidated() {
@@ -394,6 +374,11 @@ func (le *LiquidationEngineV2) executePartialLiquidation(
 		le.keeper.perpetualKeeper.SetAccount(ctx, account)
 	}
 
+	// Cover any loss beyond the released margin, as in tier 1
+	if deficit := marginToRelease.Add(realizedPnL).Neg(); deficit.IsPositive() {
+		le.keeper.coverBankruptcy(ctx, position, deficit, liquidationID)
+	}
+
 	// Distribute liquidator reward
 	if liquidator != "" && liquidatorReward.IsPositive() {
 		liquidatorAccount := le.keeper.perpetualKeeper.GetOrCreateAccount(ctx, liquidator)
@@ -423,10 +408,11 @@ func (le *LiquidationEngineV2) executePartialLiquidation(
 	)
 	liquidation.Status = types.LiquidationStatusExecuted
 	le.keeper.SetLiquidation(ctx, liquidation)
+	le.keeper.afterLiquidation(ctx, position.Trader, position.MarketID, liquidatedSize, health.MarkPrice)
 
 	// Update state and start cooldown
-	state.UpdateAfterLiquidation(liquidatedSize, penalty, types.TierPartialLiquidation)
-	state.StartCooldown(le.config.CooldownPeriod)
+	state.UpdateAfterLiquidation(liquidatedSize, penalty, types.TierPartialLiquidation, ctx.BlockTime())
+	state.StartCooldown(ctx.BlockTime(), le.config.CooldownPeriod)
 
 	// CRITICAL FIX: Persist state after cooldown starts
 	le.saveLiquidationState(ctx, state)
@@ -512,6 +498,11 @@ func (le *LiquidationEngineV2) executeBackstopLiquidation(
 		le.keeper.perpetualKeeper.SetAccount(ctx, account)
 	}
 
+	// Cover any loss beyond the released margin, as in tier 1
+	if deficit := position.Margin.Add(realizedPnL).Neg(); deficit.IsPositive() {
+		le.keeper.coverBankruptcy(ctx, position, deficit, liquidationID)
+	}
+
 	// TODO: Transfer position to Liquidator Vault
 	// This would involve:
 	// 1. Creating a position in the Vault's account
@@ -533,11 +524,15 @@ func (le *LiquidationEngineV2) executeBackstopLiquidation(
 	liquidation.Status = types.LiquidationStatusExecuted
 	le.keeper.SetLiquidation(ctx, liquidation)
 
-	// Delete the position
+	// Record history and delete the position
+	position.RecordClose(liquidatedSize, health.MarkPrice, realizedPnL)
+	position.AddFee(penalty)
+	le.keeper.perpetualKeeper.RecordClosedPosition(ctx, position, perpetualtypes.CloseReasonLiquidation)
 	le.keeper.perpetualKeeper.DeletePosition(ctx, position.Trader, position.MarketID)
+	le.keeper.afterLiquidation(ctx, position.Trader, position.MarketID, liquidatedSize, health.MarkPrice)
 
 	// Update state
-	state.UpdateAfterLiquidation(liquidatedSize, penalty, types.TierBackstopLiquidation)
+	state.UpdateAfterLiquidation(liquidatedSize, penalty, types.TierBackstopLiquidation, ctx.BlockTime())
 	state.IsBackstopTriggered = true
 
 	// CRITICAL FIX: Clean up state with persistence
@@ -78,13 +78,13 @@ func TestLiquidationState(t *testing.T) {
 	}
 
 	// Test cooldown
-	state.StartCooldown(30 * time.Second)
+	now := time.Now()
+	state.StartCooldown(now, 30*time.Second)
 	if !state.IsInCooldown {
 		t.Error("State should be in cooldown after StartCooldown")
 	}
 
 	// Test CanLiquidate during cooldown
-	now := time.Now()
 	if state.CanLiquidate(now) {
 		t.Error("Should not be able to liquidate during cooldown")
 	}
@@ -104,7 +104,7 @@ func TestLiquidationState(t *testing.T) {
 	// Test UpdateAfterLiquidation
 	liquidatedSize := math.LegacyNewDec(2)
 	penalty := math.LegacyNewDec(100)
-	state.UpdateAfterLiquidation(liquidatedSize, penalty, types.TierPartialLiquidation)
+	state.UpdateAfterLiquidation(liquidatedSize, penalty, types.TierPartialLiquidation, now)
 
 	expectedRemaining := math.LegacyNewDec(8)
 	if !state.RemainingSize.Equal(expectedRemaining) {
@@ -118,6 +118,10 @@ func TestLiquidationState(t *testing.T) {
 	if state.LiquidationCount != 1 {
 		t.Errorf("LiquidationCount = %v, expected 1", state.LiquidationCount)
 	}
+
+	if !state.LastLiquidationTime.Equal(now) {
+		t.Errorf("LastLiquidationTime = %v, expected %v", state.LastLiquidationTime, now)
+	}
 }
 
 // TestPositionHealthV2 tests the position health assessment
@@ -269,6 +273,40 @@ func TestPartialLiquidationCalculation(t *testing.T) {
 	}
 }
 
+// TestPartialLiquidationSize tests that a partial liquidation closes only the
+// size that restores the margin buffer, within the per-step cap
+func TestPartialLiquidationSize(t *testing.T) {
+	config := types.DefaultLiquidationConfig()
+	dec := math.LegacyMustNewDecFromStr
+	size, mark := dec("10"), dec("48000")
+
+	tests := []struct {
+		name     string
+		equity   math.LegacyDec
+		expected math.LegacyDec
+	}{
+		// 2.45% of notional: (13200 - 11760) / (48000 * (2.75% - 1%))
+		{"restores buffer", dec("11760"), dec("1.714285714285714286")},
+		{"capped at rate", dec("10000"), dec("2")},
+		{"above buffer", dec("13200"), math.LegacyZeroDec()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := config.PartialLiquidationSize(size, tt.equity, mark)
+			if !got.Equal(tt.expected) {
+				t.Errorf("PartialLiquidationSize = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+
+	// A buffer the penalty outweighs cannot be restored by a partial close
+	config.PartialLiquidationBuffer = math.LegacyZeroDec()
+	config.LiquidationPenaltyRate = dec("0.03")
+	if got := config.PartialLiquidationSize(size, dec("11760"), mark); !got.Equal(size) {
+		t.Errorf("PartialLiquidationSize = %v, expected the whole size", got)
+	}
+}
+
 // TestBackstopThreshold tests the backstop threshold calculation
 func TestBackstopThreshold(t *testing.T) {
 	config := types.DefaultLiquidationConfig()
@@ -290,7 +328,7 @@ func TestLiquidationStateFullyLiquidated(t *testing.T) {
 	}
 
 	// Liquidate fully
-	state.UpdateAfterLiquidation(positionSize, math.LegacyNewDec(100), types.TierMarketOrder)
+	state.UpdateAfterLiquidation(positionSize, math.LegacyNewDec(100), types.TierMarketOrder, time.Now())
 
 	if !state.IsFullyLiquidated() {
 		t.Error("State should be fully liquidated after liquidating full size")
@@ -303,10 +341,10 @@ func TestCooldownMechanism(t *testing.T) {
 	config := types.DefaultLiquidationConfig()
 
 	// Start cooldown
-	state.StartCooldown(config.CooldownPeriod)
+	now := time.Now()
+	state.StartCooldown(now, config.CooldownPeriod)
 
 	// Should not be able to liquidate immediately
-	now := time.Now()
 	if state.CanLiquidate(now) {
 		t.Error("Should not be able to liquidate immediately after starting cooldown")
 	}
@@ -408,6 +446,7 @@ func BenchmarkLiquidationStateUpdate(b *testing.B) {
 			math.LegacyNewDec(1),
 			math.LegacyNewDec(100),
 			types.TierPartialLiquidation,
+			time.Time{},
 		)
 	}
 }
@@ -101,6 +101,8 @@ func (se *SettlementEngine) SettleTrades(ctx sdk.Context, trades []*orderbooktyp
 		// BOTH succeeded - commit atomically
 		write()
 		summary.TotalTrades++
+		se.keeper.afterPositionChanged(ctx, trade.Taker, trade.MarketID)
+		se.keeper.afterPositionChanged(ctx, trade.Maker, trade.MarketID)
 
 		// Update per-account tracking
 		for _, accountID := range []string{trade.Taker, trade.Maker} {
@@ -242,6 +244,7 @@ func (se *SettlementEngine) applyTrade(
 			position.AddSize(qty, price)
 			position.Margin = position.Margin.Add(requiredMargin)
 		}
+		position.AddFee(fee)
 
 		account.LockMargin(requiredMargin)
 		se.keeper.perpetualKeeper.SetPosition(ctx, position)
@@ -250,6 +253,8 @@ func (se *SettlementEngine) applyTrade(
 			realizedPnL = calculateRealizedPnL(position, qty, price)
 			releasedMargin := position.Margin.Mul(qty).Quo(position.Size)
 
+			position.AddFee(fee)
+			position.RecordClose(qty, price, realizedPnL)
 			position.ReduceSize(qty)
 			position.Margin = position.Margin.Sub(releasedMargin)
 			account.UnlockMargin(releasedMargin)
@@ -257,6 +262,7 @@ func (se *SettlementEngine) applyTrade(
 			marginChange = releasedMargin.Neg()
 
 			if position.Size.IsZero() {
+				se.keeper.perpetualKeeper.RecordClosedPosition(ctx, position, perpetualtypes.CloseReasonClosed)
 				se.keeper.perpetualKeeper.DeletePosition(ctx, trader, marketID)
 			} else {
 				se.keeper.perpetualKeeper.SetPosition(ctx, position)
@@ -267,6 +273,11 @@ func (se *SettlementEngine) applyTrade(
 			account.Balance = account.Balance.Add(realizedPnL)
 			marginChange = position.Margin.Neg()
 
+			// Split the fee between the closed position and the flipped remainder
+			closingFee := fee.Mul(position.Size).Quo(qty)
+			position.AddFee(closingFee)
+			position.RecordClose(position.Size, price, realizedPnL)
+			se.keeper.perpetualKeeper.RecordClosedPosition(ctx, position, perpetualtypes.CloseReasonClosed)
 			se.keeper.perpetualKeeper.DeletePosition(ctx, trader, marketID)
 
 			remainingQty := qty.Sub(position.Size)
@@ -274,6 +285,7 @@ func (se *SettlementEngine) applyTrade(
 				requiredMargin := calculateInitialMargin(remainingQty, price)
 				marginChange = marginChange.Add(requiredMargin)
 				newPosition := perpetualtypes.NewPosition(trader, marketID, positionSide, remainingQty, price, requiredMargin)
+				newPosition.AddFee(fee.Sub(closingFee))
 				account.LockMargin(requiredMargin)
 				se.keeper.perpetualKeeper.SetPosition(ctx, newPosition)
 			}
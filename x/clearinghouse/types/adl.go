@@ -1,6 +1,7 @@
 package types
 
 import (
+	"sort"
 	"time"
 
 	"cosmossdk.io/math"
@@ -15,9 +16,22 @@ type ADLEvent struct {
 	TotalDeficit      math.LegacyDec
 	PositionsAffected int
 	TotalDeleveraged  math.LegacyDec
+	DeficitCovered    math.LegacyDec
+	BankruptSide      string     // side of the position whose loss triggered ADL
+	Fills             []*ADLFill // deleveraged positions, in queue order
 	Timestamp         time.Time
 }
 
+// ADLFill records one position reduced by ADL
+type ADLFill struct {
+	Trader     string
+	Side       string
+	Quantity   math.LegacyDec
+	Price      math.LegacyDec
+	Covered    math.LegacyDec // profit withheld to cover the deficit
+	ADLRanking int
+}
+
 // ADLTriggerReason represents why ADL was triggered
 type ADLTriggerReason int
 
@@ -49,10 +63,67 @@ type ADLPosition struct {
 	EntryPrice      math.LegacyDec
 	UnrealizedPnL   math.LegacyDec
 	PnLPercent      math.LegacyDec // PnL as percentage of margin
+	Leverage        math.LegacyDec // Effective leverage: notional / (margin + PnL)
+	Score           math.LegacyDec // Queue priority, see ADLScore
 	ADLRanking      int            // 1 = highest priority for ADL
+	Lights          int            // ADL indicator, see ADLLights
 	DeleverageQty   math.LegacyDec // Quantity to deleverage
 }
 
+// ADLIndicatorLevels is the number of lights on the ADL indicator
+const ADLIndicatorLevels = 5
+
+// ADLScore returns the queue priority of a profitable position: PnL as a
+// fraction of margin times effective leverage, so highly profitable and highly
+// leveraged positions are deleveraged first. Positions without profit score zero.
+func ADLScore(pnl, margin, notional math.LegacyDec) (score, pnlPercent, leverage math.LegacyDec) {
+	zero := math.LegacyZeroDec()
+	if !pnl.IsPositive() || !margin.IsPositive() {
+		return zero, zero, zero
+	}
+	pnlPercent = pnl.Quo(margin)
+	leverage = notional.Quo(margin.Add(pnl))
+	return pnlPercent.Mul(leverage), pnlPercent, leverage
+}
+
+// ADLLights maps a queue ranking (1 = first) to the 1-5 light indicator by
+// quintile: the top 20% of the queue shows 5 lights, the bottom 20% shows 1.
+// Positions outside the queue (ranking 0) show 1 light.
+func ADLLights(ranking, queueSize int) int {
+	if ranking <= 0 || queueSize <= 0 || ranking > queueSize {
+		return 1
+	}
+	return ADLIndicatorLevels - (ranking-1)*ADLIndicatorLevels/queueSize
+}
+
+// RankADLPositions sorts positions by score, highest first, and assigns their
+// rankings and indicator lights. Ties go to the larger PnL, then the trader
+// address, so the order is deterministic.
+func RankADLPositions(positions []*ADLPosition) {
+	sort.SliceStable(positions, func(i, j int) bool {
+		a, b := positions[i], positions[j]
+		if !a.Score.Equal(b.Score) {
+			return a.Score.GT(b.Score)
+		}
+		if !a.UnrealizedPnL.Equal(b.UnrealizedPnL) {
+			return a.UnrealizedPnL.GT(b.UnrealizedPnL)
+		}
+		return a.Trader < b.Trader
+	})
+	for i, pos := range positions {
+		pos.ADLRanking = i + 1
+		pos.Lights = ADLLights(pos.ADLRanking, len(positions))
+	}
+}
+
+// OppositeSide returns the side that is deleveraged when a position on side goes bankrupt
+func OppositeSide(side string) string {
+	if side == "long" {
+		return "short"
+	}
+	return "long"
+}
+
 // ADLQueue represents the queue of positions for ADL
 type ADLQueue struct {
 	MarketID    string
@@ -78,7 +149,7 @@ type ADLConfig struct {
 	Enabled             bool           // Whether ADL is enabled
 	MaxDeleverageRatio  math.LegacyDec // Max percentage of position to deleverage at once
 	MinPositionForADL   math.LegacyDec // Minimum position size for ADL
-	PriorityByPnL       bool           // If true, prioritize by PnL; if false, by leverage
+	PriorityByPnL       bool           // If true, prioritize by PnL% x leverage; if false, by leverage alone
 }
 
 // DefaultADLConfig returns default ADL configuration
@@ -91,6 +162,18 @@ func DefaultADLConfig() ADLConfig {
 	}
 }
 
+// ADLIndicator is a position's place in its market's ADL queue
+type ADLIndicator struct {
+	Trader     string
+	MarketID   string
+	Side       string
+	InQueue    bool // false for positions without profit, which are never deleveraged
+	ADLRanking int
+	QueueSize  int
+	Lights     int
+	Score      math.LegacyDec
+}
+
 // ADLResult represents the result of an ADL operation
 type ADLResult struct {
 	Success           bool
@@ -99,5 +182,6 @@ type ADLResult struct {
 	TotalDeleveraged  math.LegacyDec
 	DeficitCovered    math.LegacyDec
 	RemainingDeficit  math.LegacyDec
+	Fills             []*ADLFill
 	Errors            []string
 }
@@ -11,4 +11,6 @@ var (
 	ErrLiquidationFailed     = errors.Register("clearinghouse", 3, "liquidation failed")
 	ErrLiquidationNotFound   = errors.Register("clearinghouse", 4, "liquidation not found")
 	ErrInvalidLiquidator     = errors.Register("clearinghouse", 5, "invalid liquidator")
+	ErrADLDisabled           = errors.Register("clearinghouse", 6, "ADL is disabled")
+	ErrLiquidationCooldown   = errors.Register("clearinghouse", 7, "position is in liquidation cooldown")
 )
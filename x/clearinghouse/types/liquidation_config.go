@@ -44,10 +44,15 @@ type LiquidationConfig struct {
 	// Default: 100,000 USDC (Hyperliquid standard)
 	LargePositionThreshold math.LegacyDec
 
-	// PartialLiquidationRate - Percentage of position to liquidate initially for large positions
+	// PartialLiquidationRate - Largest fraction of a large position one partial liquidation closes
 	// Default: 20% (0.2)
 	PartialLiquidationRate math.LegacyDec
 
+	// PartialLiquidationBuffer - Margin a partial liquidation restores above maintenance,
+	// as a fraction of the maintenance margin
+	// Default: 10% (equity back to 1.1x maintenance margin)
+	PartialLiquidationBuffer math.LegacyDec
+
 	// CooldownPeriod - Time to wait after partial liquidation before next liquidation
 	// Default: 30 seconds
 	CooldownPeriod time.Duration
@@ -83,6 +88,7 @@ func DefaultLiquidationConfig() LiquidationConfig {
 	return LiquidationConfig{
 		LargePositionThreshold:   math.LegacyNewDec(100000),                     // $100,000
 		PartialLiquidationRate:   math.LegacyNewDecWithPrec(20, 2),              // 20%
+		PartialLiquidationBuffer: math.LegacyNewDecWithPrec(10, 2),              // 10%
 		CooldownPeriod:           30 * time.Second,                              // 30 seconds
 		BackstopThreshold:        math.LegacyNewDecWithPrec(6667, 4),            // 2/3 = 66.67%
 		LiquidationPenaltyRate:   math.LegacyNewDecWithPrec(1, 2),               // 1%
@@ -93,6 +99,40 @@ func DefaultLiquidationConfig() LiquidationConfig {
 	}
 }
 
+// PartialLiquidationSize returns the size of a position to close so that its
+// equity, less the penalty on the closed size, covers the maintenance margin
+// of the rest plus PartialLiquidationBuffer. Zero means the position already
+// has that margin. One step closes at most PartialLiquidationRate of the
+// position; the whole size is returned when no partial close restores it.
+func (c LiquidationConfig) PartialLiquidationSize(size, equity, markPrice math.LegacyDec) math.LegacyDec {
+	if !size.IsPositive() || !markPrice.IsPositive() {
+		return math.LegacyZeroDec()
+	}
+
+	// The PnL realized on the closed size stays in the position's margin, so
+	// closing q lowers equity by the penalty only while the maintenance margin
+	// falls with the size:
+	//   equity - q*mark*penalty >= (size-q)*mark*maintenance*(1+buffer)
+	targetRate := c.MinMaintenanceMarginRate.Mul(math.LegacyOneDec().Add(c.PartialLiquidationBuffer))
+	shortfall := size.Mul(markPrice).Mul(targetRate).Sub(equity)
+	if !shortfall.IsPositive() {
+		return math.LegacyZeroDec()
+	}
+	relief := markPrice.Mul(targetRate.Sub(c.LiquidationPenaltyRate))
+	if !relief.IsPositive() {
+		return size
+	}
+
+	closeSize := shortfall.QuoRoundUp(relief)
+	if closeSize.GTE(size) {
+		return size
+	}
+	if maxSize := size.Mul(c.PartialLiquidationRate); closeSize.GT(maxSize) {
+		return maxSize
+	}
+	return closeSize
+}
+
 // LiquidationState represents the current state of a position's liquidation
 type LiquidationState struct {
 	// PositionID is the unique identifier for the position
@@ -158,10 +198,10 @@ func (s *LiquidationState) CanLiquidate(currentTime time.Time) bool {
 	return currentTime.After(s.CooldownEndTime)
 }
 
-// StartCooldown starts the cooldown period
-func (s *LiquidationState) StartCooldown(cooldownDuration time.Duration) {
+// StartCooldown starts the cooldown period at the given (block) time
+func (s *LiquidationState) StartCooldown(now time.Time, cooldownDuration time.Duration) {
 	s.IsInCooldown = true
-	s.CooldownEndTime = time.Now().Add(cooldownDuration)
+	s.CooldownEndTime = now.Add(cooldownDuration)
 }
 
 // EndCooldown ends the cooldown period
@@ -170,15 +210,17 @@ func (s *LiquidationState) EndCooldown() {
 	s.CooldownEndTime = time.Time{}
 }
 
-// UpdateAfterLiquidation updates the state after a liquidation
+// UpdateAfterLiquidation updates the state after a liquidation at the given
+// (block) time
 func (s *LiquidationState) UpdateAfterLiquidation(
 	liquidatedSize, penalty math.LegacyDec,
 	tier LiquidationTier,
+	now time.Time,
 ) {
 	s.TotalLiquidated = s.TotalLiquidated.Add(liquidatedSize)
 	s.RemainingSize = s.RemainingSize.Sub(liquidatedSize)
 	s.TotalPenaltyPaid = s.TotalPenaltyPaid.Add(penalty)
-	s.LastLiquidationTime = time.Now()
+	s.LastLiquidationTime = now
 	s.LiquidationCount++
 	s.CurrentTier = tier
 }
@@ -42,6 +42,11 @@ type Liquidation struct {
 	Penalty          math.LegacyDec // liquidation penalty
 	Status           LiquidationStatus
 	Timestamp        time.Time
+
+	// Partial liquidations close a position in steps; each step is recorded
+	Partial       bool           // the position was only partly closed
+	Step          int            // 1 for the first step since the position was last healthy
+	RemainingSize math.LegacyDec // position size left open after this step
 }
 
 // NewLiquidation creates a new liquidation record
@@ -1,19 +1,35 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
-	"strconv"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"cosmossdk.io/math"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	"github.com/openalpha/perp-dex/x/orderbook/types"
 )
 
+// Order construction flags
+const (
+	FlagMarket   = "market"
+	FlagSide     = "side"
+	FlagType     = "type"
+	FlagPrice    = "price"
+	FlagQuantity = "quantity"
+	FlagFile     = "file"
+)
+
+// MaxBatchSize is the most orders placed or cancelled in one transaction
+const MaxBatchSize = 100
+
 // GetTxCmd returns the transaction commands for the orderbook module
 func GetTxCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -32,99 +48,227 @@ func GetTxCmd() *cobra.Command {
 	return cmd
 }
 
+// OrderInput is one order as given on the command line or in a batch file
+type OrderInput struct {
+	MarketID string `json:"market_id"`
+	Side     string `json:"side"`
+	Type     string `json:"type"`
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// Msg validates the order and builds its MsgPlaceOrder. Type defaults to
+// limit; a market order's price defaults to 0.
+func (o OrderInput) Msg(trader string) (*types.MsgPlaceOrder, error) {
+	if o.MarketID == "" {
+		return nil, fmt.Errorf("market is required")
+	}
+
+	side, err := ParseSide(o.Side)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderType types.OrderType
+	switch strings.ToLower(o.Type) {
+	case "", "limit":
+		orderType = types.OrderTypeLimit
+	case "market":
+		orderType = types.OrderTypeMarket
+	default:
+		return nil, fmt.Errorf("invalid order type: %s (use 'limit' or 'market')", o.Type)
+	}
+
+	quantity, err := math.LegacyNewDecFromStr(o.Quantity)
+	if err != nil || !quantity.IsPositive() {
+		return nil, fmt.Errorf("invalid quantity: %q", o.Quantity)
+	}
+	price := o.Price
+	if price == "" && orderType == types.OrderTypeMarket {
+		price = "0"
+	}
+	priceDec, err := math.LegacyNewDecFromStr(price)
+	if err != nil || priceDec.IsNegative() || (orderType == types.OrderTypeLimit && priceDec.IsZero()) {
+		return nil, fmt.Errorf("invalid price: %q", o.Price)
+	}
+
+	msg := &types.MsgPlaceOrder{
+		Trader:    trader,
+		MarketId:  o.MarketID,
+		Side:      side,
+		OrderType: orderType,
+		Price:     priceDec.String(),
+		Quantity:  quantity.String(),
+	}
+	return msg, msg.ValidateBasic()
+}
+
+// ParseSide parses "buy" or "sell"
+func ParseSide(side string) (types.Side, error) {
+	switch strings.ToLower(side) {
+	case "buy":
+		return types.SideBuy, nil
+	case "sell":
+		return types.SideSell, nil
+	default:
+		return types.SideUnspecified, fmt.Errorf("invalid side: %s (use 'buy' or 'sell')", side)
+	}
+}
+
+// ReadOrdersFile reads a batch file holding a JSON array of orders
+func ReadOrdersFile(path string) ([]OrderInput, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var orders []OrderInput
+	if err := json.Unmarshal(bz, &orders); err != nil {
+		return nil, fmt.Errorf("invalid orders file %s: %w", path, err)
+	}
+	if len(orders) == 0 || len(orders) > MaxBatchSize {
+		return nil, fmt.Errorf("orders file %s must hold 1 to %d orders, got %d", path, MaxBatchSize, len(orders))
+	}
+	return orders, nil
+}
+
+// ReadOrderIDsFile reads a batch file holding a JSON array of order IDs
+func ReadOrderIDsFile(path string) ([]string, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var orderIDs []string
+	if err := json.Unmarshal(bz, &orderIDs); err != nil {
+		return nil, fmt.Errorf("invalid order IDs file %s: %w", path, err)
+	}
+	return orderIDs, nil
+}
+
 // CmdPlaceOrder returns the command to place an order
 func CmdPlaceOrder() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "place-order [market-id] [side] [type] [price] [quantity]",
-		Short: "Place a new order",
-		Long: `Place a new order in the orderbook.
+		Short: "Place a new order, or a batch of orders from a JSON file",
+		Long: `Place a new order in the orderbook. The order is given either as arguments
+or with the --market, --side, --type, --price and --quantity flags. With
+--file, every order in a JSON array of {"market_id", "side", "type", "price",
+"quantity"} objects is placed in one transaction (at most 100).
+
+Use --generate-only with --from set to an address to write the unsigned
+transaction for offline signing with "perpdexd tx sign".
 
 Examples:
   perpdexd tx orderbook place-order BTC-USDC buy limit 50000 0.1 --from alice
-  perpdexd tx orderbook place-order BTC-USDC sell market 0 0.5 --from bob`,
-		Args: cobra.ExactArgs(5),
+  perpdexd tx orderbook place-order --market BTC-USDC --side sell --type market --quantity 0.5 --from bob
+  perpdexd tx orderbook place-order --file orders.json --from alice
+  perpdexd tx orderbook place-order BTC-USDC buy limit 50000 0.1 --from cosmos1... --generate-only > unsigned.json`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 && len(args) != 5 {
+				return fmt.Errorf("accepts 0 or 5 arg(s), received %d", len(args))
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			clientCtx, err := client.GetClientTxContext(cmd)
 			if err != nil {
 				return err
 			}
+			trader := clientCtx.GetFromAddress().String()
 
-			marketID := args[0]
-			sideStr := strings.ToLower(args[1])
-			orderTypeStr := strings.ToLower(args[2])
-			priceStr := args[3]
-			quantityStr := args[4]
-
-			// Parse side
-			var side types.Side
-			switch sideStr {
-			case "buy":
-				side = types.SideBuy
-			case "sell":
-				side = types.SideSell
+			file, _ := cmd.Flags().GetString(FlagFile)
+			var orders []OrderInput
+			switch {
+			case file != "" && len(args) > 0:
+				return fmt.Errorf("--file cannot be combined with order arguments")
+			case file != "":
+				if orders, err = ReadOrdersFile(file); err != nil {
+					return err
+				}
+			case len(args) == 5:
+				orders = []OrderInput{{MarketID: args[0], Side: args[1], Type: args[2], Price: args[3], Quantity: args[4]}}
 			default:
-				return fmt.Errorf("invalid side: %s (use 'buy' or 'sell')", sideStr)
+				order := OrderInput{}
+				order.MarketID, _ = cmd.Flags().GetString(FlagMarket)
+				order.Side, _ = cmd.Flags().GetString(FlagSide)
+				order.Type, _ = cmd.Flags().GetString(FlagType)
+				order.Price, _ = cmd.Flags().GetString(FlagPrice)
+				order.Quantity, _ = cmd.Flags().GetString(FlagQuantity)
+				orders = []OrderInput{order}
 			}
 
-			// Parse order type
-			var orderType types.OrderType
-			switch orderTypeStr {
-			case "limit":
-				orderType = types.OrderTypeLimit
-			case "market":
-				orderType = types.OrderTypeMarket
-			default:
-				return fmt.Errorf("invalid order type: %s (use 'limit' or 'market')", orderTypeStr)
+			msgs := make([]sdk.Msg, 0, len(orders))
+			for i, order := range orders {
+				msg, err := order.Msg(trader)
+				if err != nil {
+					if len(orders) > 1 {
+						return fmt.Errorf("order %d: %w", i, err)
+					}
+					return err
+				}
+				msgs = append(msgs, msg)
 			}
 
-			price, err := strconv.ParseFloat(priceStr, 64)
-			if err != nil {
-				return fmt.Errorf("invalid price: %v", err)
-			}
-
-			quantity, err := strconv.ParseFloat(quantityStr, 64)
-			if err != nil {
-				return fmt.Errorf("invalid quantity: %v", err)
-			}
-
-			msg := &types.MsgPlaceOrder{
-				Trader:    clientCtx.GetFromAddress().String(),
-				MarketId:  marketID,
-				Side:      side,
-				OrderType: orderType,
-				Price:     fmt.Sprintf("%f", price),
-				Quantity:  fmt.Sprintf("%f", quantity),
-			}
-
-			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg)
+			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msgs...)
 		},
 	}
 
+	cmd.Flags().String(FlagMarket, "", "Market ID, e.g. BTC-USDC")
+	cmd.Flags().String(FlagSide, "", "Order side: buy or sell")
+	cmd.Flags().String(FlagType, "limit", "Order type: limit or market")
+	cmd.Flags().String(FlagPrice, "", "Limit price (ignored for market orders)")
+	cmd.Flags().String(FlagQuantity, "", "Order quantity")
+	cmd.Flags().String(FlagFile, "", "JSON file with an array of orders to place in one transaction")
 	flags.AddTxFlagsToCmd(cmd)
 	return cmd
 }
 
-// CmdCancelOrder returns the command to cancel an order
+// CmdCancelOrder returns the command to cancel orders
 func CmdCancelOrder() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "cancel-order [order-id]",
-		Short: "Cancel an existing order",
-		Args:  cobra.ExactArgs(1),
+		Use:   "cancel-order [order-id]...",
+		Short: "Cancel one or more existing orders",
+		Long: `Cancel orders by ID. Several IDs, or --file with a JSON array of IDs, cancel
+every order in one transaction (at most 100).
+
+Examples:
+  perpdexd tx orderbook cancel-order order-1 --from alice
+  perpdexd tx orderbook cancel-order order-1 order-2 order-3 --from alice
+  perpdexd tx orderbook cancel-order --file order-ids.json --from alice --generate-only`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			clientCtx, err := client.GetClientTxContext(cmd)
 			if err != nil {
 				return err
 			}
 
-			msg := &types.MsgCancelOrder{
-				Trader:  clientCtx.GetFromAddress().String(),
-				OrderId: args[0],
+			orderIDs := args
+			if file, _ := cmd.Flags().GetString(FlagFile); file != "" {
+				fromFile, err := ReadOrderIDsFile(file)
+				if err != nil {
+					return err
+				}
+				orderIDs = append(orderIDs, fromFile...)
+			}
+			if len(orderIDs) == 0 || len(orderIDs) > MaxBatchSize {
+				return fmt.Errorf("give 1 to %d order IDs, got %d", MaxBatchSize, len(orderIDs))
+			}
+
+			msgs := make([]sdk.Msg, 0, len(orderIDs))
+			for _, orderID := range orderIDs {
+				msg := &types.MsgCancelOrder{
+					Trader:  clientCtx.GetFromAddress().String(),
+					OrderId: orderID,
+				}
+				if err := msg.ValidateBasic(); err != nil {
+					return err
+				}
+				msgs = append(msgs, msg)
 			}
 
-			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg)
+			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msgs...)
 		},
 	}
 
+	cmd.Flags().String(FlagFile, "", "JSON file with an array of order IDs to cancel in one transaction")
 	flags.AddTxFlagsToCmd(cmd)
 	return cmd
 }
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestOrderInputMsg tests order construction from arguments, flags and
+// batch file entries
+func TestOrderInputMsg(t *testing.T) {
+	msg, err := OrderInput{MarketID: "BTC-USDC", Side: "BUY", Price: "50000.5", Quantity: "0.1"}.Msg("alice")
+	if err != nil {
+		t.Fatalf("failed to build order: %v", err)
+	}
+	if msg.Side != types.SideBuy || msg.OrderType != types.OrderTypeLimit || msg.Price != "50000.500000000000000000" || msg.Quantity != "0.100000000000000000" {
+		t.Errorf("unexpected limit order %+v", msg)
+	}
+
+	msg, err = OrderInput{MarketID: "BTC-USDC", Side: "sell", Type: "market", Quantity: "1"}.Msg("alice")
+	if err != nil || msg.OrderType != types.OrderTypeMarket || msg.Price != "0.000000000000000000" {
+		t.Errorf("expected a market order at price 0, got %+v (err %v)", msg, err)
+	}
+
+	for name, order := range map[string]OrderInput{
+		"no market":      {Side: "buy", Price: "1", Quantity: "1"},
+		"bad side":       {MarketID: "BTC-USDC", Side: "long", Price: "1", Quantity: "1"},
+		"bad type":       {MarketID: "BTC-USDC", Side: "buy", Type: "stop", Price: "1", Quantity: "1"},
+		"zero quantity":  {MarketID: "BTC-USDC", Side: "buy", Price: "1", Quantity: "0"},
+		"no limit price": {MarketID: "BTC-USDC", Side: "buy", Quantity: "1"},
+		"negative price": {MarketID: "BTC-USDC", Side: "buy", Price: "-1", Quantity: "1"},
+	} {
+		if _, err := order.Msg("alice"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// TestReadOrdersFile tests reading batch files and the batch size limit
+func TestReadOrdersFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	orders, err := ReadOrdersFile(write("orders.json", `[{"market_id":"BTC-USDC","side":"buy","type":"limit","price":"1","quantity":"2"},{"market_id":"ETH-USDC","side":"sell","type":"market","quantity":"3"}]`))
+	if err != nil || len(orders) != 2 || orders[1].MarketID != "ETH-USDC" || orders[1].Type != "market" {
+		t.Fatalf("unexpected orders %+v (err %v)", orders, err)
+	}
+	if _, err := ReadOrdersFile(write("empty.json", `[]`)); err == nil {
+		t.Error("expected an error for an empty batch")
+	}
+	if _, err := ReadOrdersFile(write("object.json", `{"market_id":"BTC-USDC"}`)); err == nil {
+		t.Error("expected an error for a non-array file")
+	}
+
+	ids, err := ReadOrderIDsFile(write("ids.json", `["order-1","order-2"]`))
+	if err != nil || len(ids) != 2 || ids[1] != "order-2" {
+		t.Errorf("unexpected order IDs %v (err %v)", ids, err)
+	}
+}
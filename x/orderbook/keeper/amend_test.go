@@ -0,0 +1,87 @@
+package keeper
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestAmendOrderPriority tests that a size reduction keeps queue position
+// while price changes and size increases re-queue the order
+func TestAmendOrderPriority(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	price := math.LegacyNewDec(100)
+
+	first, _, err := k.PlaceOrder(ctx, "alice", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, price, math.LegacyNewDec(5))
+	if err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+	second, _, err := k.PlaceOrder(ctx, "bob", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, price, math.LegacyNewDec(5))
+	if err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+
+	queue := func() []string {
+		return k.GetOrderBook(ctx, "BTC-USDC").BestBid().OrderIDs
+	}
+
+	// Reduce: stays first, level quantity drops
+	amended, result, err := k.AmendOrder(ctx, "alice", first.OrderID, math.LegacyDec{}, math.LegacyNewDec(3))
+	if err != nil {
+		t.Fatalf("failed to reduce order: %v", err)
+	}
+	if result != nil || amended.OrderID != first.OrderID {
+		t.Errorf("expected in-place amend of %s, got %s (result %v)", first.OrderID, amended.OrderID, result)
+	}
+	if q := queue(); q[0] != first.OrderID {
+		t.Errorf("expected reduced order to keep priority, queue %v", q)
+	}
+	if level := k.GetOrderBook(ctx, "BTC-USDC").BestBid(); !level.Quantity.Equal(math.LegacyNewDec(8)) {
+		t.Errorf("expected level quantity 8, got %s", level.Quantity)
+	}
+	if msg, broken := BookQuantityInvariant(k)(ctx); broken {
+		t.Fatalf("expected invariant to hold: %s", msg)
+	}
+
+	// A sell fills the reduced order first
+	_, match, err := k.PlaceOrder(ctx, "carol", "BTC-USDC", types.SideSell, types.OrderTypeLimit, price, math.LegacyNewDec(1))
+	if err != nil {
+		t.Fatalf("failed to place sell: %v", err)
+	}
+	if len(match.Trades) != 1 || match.Trades[0].Maker != "alice" {
+		t.Fatalf("expected alice to be filled first, got %+v", match.Trades)
+	}
+
+	// Increase: keeps its fill but moves behind bob
+	if _, result, err = k.AmendOrder(ctx, "alice", first.OrderID, math.LegacyDec{}, math.LegacyNewDec(4)); err != nil {
+		t.Fatalf("failed to increase order: %v", err)
+	}
+	if result == nil {
+		t.Error("expected a size increase to re-queue")
+	}
+	if q := queue(); q[0] != second.OrderID || q[1] != first.OrderID {
+		t.Errorf("expected increased order to lose priority, queue %v", q)
+	}
+	if order := k.GetOrder(ctx, first.OrderID); !order.FilledQty.Equal(math.LegacyOneDec()) || !order.RemainingQty().Equal(math.LegacyNewDec(3)) {
+		t.Errorf("expected 1 filled and 3 remaining, got %s and %s", order.FilledQty, order.RemainingQty())
+	}
+
+	// Price change: moves to the new level
+	if _, _, err = k.AmendOrder(ctx, "bob", second.OrderID, math.LegacyNewDec(101), math.LegacyDec{}); err != nil {
+		t.Fatalf("failed to reprice order: %v", err)
+	}
+	if level := k.GetOrderBook(ctx, "BTC-USDC").BestBid(); !level.Price.Equal(math.LegacyNewDec(101)) || level.OrderIDs[0] != second.OrderID {
+		t.Errorf("expected bob alone at 101, got %s %v", level.Price, level.OrderIDs)
+	}
+	if msg, broken := BookQuantityInvariant(k)(ctx); broken {
+		t.Fatalf("expected invariant to hold: %s", msg)
+	}
+
+	if _, _, err = k.AmendOrder(ctx, "alice", first.OrderID, math.LegacyDec{}, math.LegacyOneDec()); err == nil {
+		t.Error("expected quantity at or below the filled quantity to be rejected")
+	}
+	if _, _, err = k.AmendOrder(ctx, "bob", first.OrderID, math.LegacyDec{}, math.LegacyNewDec(2)); err == nil {
+		t.Error("expected amend by another trader to be rejected")
+	}
+}
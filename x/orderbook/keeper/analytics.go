@@ -0,0 +1,344 @@
+package keeper
+
+import (
+	"bytes"
+	"slices"
+	"sync"
+	"time"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// DefaultVolatilityWindow is the number of trade-to-trade returns realized
+// volatility is computed over
+const DefaultVolatilityWindow = 100
+
+// AnalyticsBandsBps are the distances from the mid price, in basis points,
+// that depth is reported within
+var AnalyticsBandsBps = []int64{10, 25, 50}
+
+var bpsDenominator = math.LegacyNewDec(10000)
+
+// DepthBand is the resting quantity within a distance of the mid price
+type DepthBand struct {
+	Bps       int64
+	BidQty    math.LegacyDec
+	AskQty    math.LegacyDec
+	Imbalance math.LegacyDec // (bid - ask) / (bid + ask), zero when the band is empty
+}
+
+// BookAnalytics is a snapshot of a market's liquidity metrics
+type BookAnalytics struct {
+	MarketID  string
+	BestBid   math.LegacyDec
+	BestAsk   math.LegacyDec
+	MidPrice  math.LegacyDec
+	Spread    math.LegacyDec
+	SpreadBps math.LegacyDec
+	Imbalance math.LegacyDec // Top-of-book quantity imbalance
+	Bands     []DepthBand    // One per AnalyticsBandsBps entry
+
+	Volatility        math.LegacyDec // RMS of trade-to-trade returns over the window
+	VolatilitySamples int
+	VolatilityWindow  int
+
+	UpdatedAt time.Time
+}
+
+// ComputeBookAnalytics computes the book metrics of a market from its levels,
+// ordered best price first. Only levels within the widest band are visited.
+func ComputeBookAnalytics(marketID string, bids, asks []*types.PriceLevel) *BookAnalytics {
+	zero := math.LegacyZeroDec()
+	a := &BookAnalytics{
+		MarketID:   marketID,
+		BestBid:    zero,
+		BestAsk:    zero,
+		MidPrice:   zero,
+		Spread:     zero,
+		SpreadBps:  zero,
+		Imbalance:  zero,
+		Bands:      make([]DepthBand, len(AnalyticsBandsBps)),
+		Volatility: zero,
+		UpdatedAt:  time.Now(),
+	}
+	for i, bps := range AnalyticsBandsBps {
+		a.Bands[i] = DepthBand{Bps: bps, BidQty: zero, AskQty: zero, Imbalance: zero}
+	}
+
+	if len(bids) > 0 {
+		a.BestBid = bids[0].Price
+	}
+	if len(asks) > 0 {
+		a.BestAsk = asks[0].Price
+	}
+	// Mid, spread and bands need both sides
+	if len(bids) == 0 || len(asks) == 0 {
+		return a
+	}
+
+	a.MidPrice = a.BestBid.Add(a.BestAsk).QuoInt64(2)
+	a.Spread = a.BestAsk.Sub(a.BestBid)
+	if a.MidPrice.IsPositive() {
+		a.SpreadBps = a.Spread.Quo(a.MidPrice).Mul(bpsDenominator)
+	}
+	a.Imbalance = imbalance(bids[0].Quantity, asks[0].Quantity)
+
+	for i := range a.Bands {
+		band := &a.Bands[i]
+		offset := a.MidPrice.MulInt64(band.Bps).Quo(bpsDenominator)
+		floor, ceiling := a.MidPrice.Sub(offset), a.MidPrice.Add(offset)
+		band.BidQty = depthWithin(bids, func(price math.LegacyDec) bool { return price.GTE(floor) })
+		band.AskQty = depthWithin(asks, func(price math.LegacyDec) bool { return price.LTE(ceiling) })
+		band.Imbalance = imbalance(band.BidQty, band.AskQty)
+	}
+	return a
+}
+
+// depthWithin sums level quantities from the top of one side while within holds
+func depthWithin(levels []*types.PriceLevel, within func(price math.LegacyDec) bool) math.LegacyDec {
+	total := math.LegacyZeroDec()
+	for _, level := range levels {
+		if !within(level.Price) {
+			break
+		}
+		total = total.Add(level.Quantity)
+	}
+	return total
+}
+
+// imbalance returns (bid - ask) / (bid + ask), or zero if both are zero
+func imbalance(bid, ask math.LegacyDec) math.LegacyDec {
+	total := bid.Add(ask)
+	if !total.IsPositive() {
+		return math.LegacyZeroDec()
+	}
+	return bid.Sub(ask).Quo(total)
+}
+
+// VolatilityWindow tracks realized volatility over a rolling window of
+// trade-to-trade returns, in O(1) per trade
+type VolatilityWindow struct {
+	size      int
+	lastPrice math.LegacyDec
+	squares   []math.LegacyDec // Ring buffer of squared returns
+	next      int              // Oldest entry once the buffer is full
+	sum       math.LegacyDec
+}
+
+// NewVolatilityWindow creates a volatility window over size returns
+func NewVolatilityWindow(size int) *VolatilityWindow {
+	if size <= 0 {
+		size = DefaultVolatilityWindow
+	}
+	return &VolatilityWindow{
+		size:    size,
+		squares: make([]math.LegacyDec, 0, size),
+		sum:     math.LegacyZeroDec(),
+	}
+}
+
+// Add records a trade price
+func (w *VolatilityWindow) Add(price math.LegacyDec) {
+	if !price.IsPositive() {
+		return
+	}
+	if w.lastPrice.IsNil() {
+		w.lastPrice = price
+		return
+	}
+
+	ret := price.Quo(w.lastPrice).Sub(math.LegacyOneDec())
+	square := ret.Mul(ret)
+	w.lastPrice = price
+
+	if len(w.squares) < w.size {
+		w.squares = append(w.squares, square)
+	} else {
+		w.sum = w.sum.Sub(w.squares[w.next])
+		w.squares[w.next] = square
+		w.next = (w.next + 1) % w.size
+	}
+	w.sum = w.sum.Add(square)
+}
+
+// Value returns the root mean square of the returns in the window
+func (w *VolatilityWindow) Value() math.LegacyDec {
+	if len(w.squares) == 0 {
+		return math.LegacyZeroDec()
+	}
+	vol, err := w.sum.QuoInt64(int64(len(w.squares))).ApproxSqrt()
+	if err != nil {
+		return math.LegacyZeroDec()
+	}
+	return vol
+}
+
+// Samples returns the number of returns in the window
+func (w *VolatilityWindow) Samples() int {
+	return len(w.squares)
+}
+
+// Size returns the maximum number of returns in the window
+func (w *VolatilityWindow) Size() int {
+	return w.size
+}
+
+// analyticsTracker keeps the latest analytics of each market, so reads are
+// O(1). Order book and trade writes only mark their market dirty; the
+// analytics are recomputed from the store by RefreshAnalytics once the writes
+// are final, so writes that are later reverted never reach them.
+type analyticsTracker struct {
+	mu         sync.RWMutex
+	window     int
+	snapshots  map[string]*BookAnalytics
+	volatility map[string]*VolatilityWindow
+	dirty      map[string]bool
+	cursors    map[string][]byte // market -> market trade index key of the last trade recorded
+}
+
+func newAnalyticsTracker(window int) *analyticsTracker {
+	return &analyticsTracker{
+		window:     window,
+		snapshots:  make(map[string]*BookAnalytics),
+		volatility: make(map[string]*VolatilityWindow),
+		dirty:      make(map[string]bool),
+		cursors:    make(map[string][]byte),
+	}
+}
+
+// markDirty notes that a market's book or trades were written
+func (t *analyticsTracker) markDirty(marketID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dirty[marketID] = true
+}
+
+// takeDirty returns the markets written since the last call
+func (t *analyticsTracker) takeDirty() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	markets := make([]string, 0, len(t.dirty))
+	for marketID := range t.dirty {
+		markets = append(markets, marketID)
+	}
+	t.dirty = make(map[string]bool)
+	return markets
+}
+
+func (t *analyticsTracker) cursor(marketID string) []byte {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.cursors[marketID]
+}
+
+func (t *analyticsTracker) setCursor(marketID string, key []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cursors[marketID] = key
+}
+
+// updateBook recomputes the book metrics of a market
+func (t *analyticsTracker) updateBook(ob *types.OrderBook) {
+	a := ComputeBookAnalytics(ob.MarketID, ob.Bids, ob.Asks)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.setVolatility(a, t.volatility[ob.MarketID])
+	t.snapshots[ob.MarketID] = a
+}
+
+// recordTrade adds a trade price to the market's volatility window
+func (t *analyticsTracker) recordTrade(trade *types.Trade) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w, ok := t.volatility[trade.MarketID]
+	if !ok {
+		w = NewVolatilityWindow(t.window)
+		t.volatility[trade.MarketID] = w
+	}
+	w.Add(trade.Price)
+
+	// Snapshots are shared with readers, so replace rather than modify
+	var a BookAnalytics
+	if prev, ok := t.snapshots[trade.MarketID]; ok {
+		a = *prev
+	} else {
+		a = *ComputeBookAnalytics(trade.MarketID, nil, nil)
+	}
+	t.setVolatility(&a, w)
+	a.UpdatedAt = trade.Timestamp
+	t.snapshots[trade.MarketID] = &a
+}
+
+func (t *analyticsTracker) setVolatility(a *BookAnalytics, w *VolatilityWindow) {
+	a.VolatilityWindow = t.window
+	if w != nil {
+		a.Volatility = w.Value()
+		a.VolatilitySamples = w.Samples()
+	}
+}
+
+// get returns the latest analytics of a market, or nil if none were recorded
+func (t *analyticsTracker) get(marketID string) *BookAnalytics {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.snapshots[marketID]
+}
+
+// RefreshAnalytics recomputes the analytics of the markets whose order book or
+// trades were written since the last refresh, from what the store now holds.
+// Call it once those writes are final: at the end of the block, or after the
+// standalone engine flushes. A write reverted with its transaction is not in
+// the store, so it never reaches the analytics.
+func (k *Keeper) RefreshAnalytics(ctx sdk.Context) {
+	for _, marketID := range k.analytics.takeDirty() {
+		if ob := k.GetOrderBook(ctx, marketID); ob != nil {
+			k.analytics.updateBook(ob)
+		}
+		for _, trade := range k.newMarketTrades(ctx, marketID) {
+			k.analytics.recordTrade(trade)
+		}
+	}
+}
+
+// newMarketTrades returns a market's trades after the last one recorded,
+// oldest first. On the first refresh after the keeper is created, only the
+// trades that fill the volatility window are returned.
+func (k *Keeper) newMarketTrades(ctx sdk.Context, marketID string) []*types.Trade {
+	store := k.GetStore(ctx)
+	prefix := historyPrefix(TradeByMarketPrefix, marketID)
+	end := storetypes.PrefixEndBytes(prefix)
+
+	var keys [][]byte
+	if cursor := k.analytics.cursor(marketID); cursor != nil {
+		iterator := store.Iterator(append(bytes.Clone(cursor), 0x00), end)
+		for ; iterator.Valid(); iterator.Next() {
+			keys = append(keys, bytes.Clone(iterator.Key()))
+		}
+		iterator.Close()
+	} else {
+		// A window of n returns takes n+1 prices
+		iterator := store.ReverseIterator(prefix, end)
+		for ; iterator.Valid() && len(keys) <= k.analytics.window; iterator.Next() {
+			keys = append(keys, bytes.Clone(iterator.Key()))
+		}
+		iterator.Close()
+		slices.Reverse(keys)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	trades := make([]*types.Trade, 0, len(keys))
+	for _, key := range keys {
+		if trade := k.GetTrade(ctx, string(key[len(prefix)+8:])); trade != nil {
+			trades = append(trades, trade)
+		}
+	}
+	k.analytics.setCursor(marketID, keys[len(keys)-1])
+	return trades
+}
@@ -0,0 +1,130 @@
+package keeper
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+func testLevel(price, qty int64) *types.PriceLevel {
+	return &types.PriceLevel{Price: math.LegacyNewDec(price), Quantity: math.LegacyNewDec(qty)}
+}
+
+// TestComputeBookAnalytics tests spread, imbalance and depth bands around the mid
+func TestComputeBookAnalytics(t *testing.T) {
+	// Mid 10000: 10bps = ±10, 25bps = ±25, 50bps = ±50
+	bids := []*types.PriceLevel{testLevel(9995, 3), testLevel(9980, 2), testLevel(9940, 5)}
+	asks := []*types.PriceLevel{testLevel(10005, 1), testLevel(10020, 4), testLevel(10100, 7)}
+
+	a := ComputeBookAnalytics("BTC-USDC", bids, asks)
+	if !a.MidPrice.Equal(math.LegacyNewDec(10000)) {
+		t.Errorf("expected mid 10000, got %s", a.MidPrice)
+	}
+	if !a.SpreadBps.Equal(math.LegacyNewDec(10)) {
+		t.Errorf("expected spread 10bps, got %s", a.SpreadBps)
+	}
+	// (3 - 1) / 4
+	if !a.Imbalance.Equal(math.LegacyNewDecWithPrec(5, 1)) {
+		t.Errorf("expected top imbalance 0.5, got %s", a.Imbalance)
+	}
+
+	expected := []struct{ bid, ask int64 }{{3, 1}, {5, 5}, {5, 5}}
+	for i, band := range a.Bands {
+		if !band.BidQty.Equal(math.LegacyNewDec(expected[i].bid)) || !band.AskQty.Equal(math.LegacyNewDec(expected[i].ask)) {
+			t.Errorf("band %dbps: expected %d/%d, got %s/%s", band.Bps, expected[i].bid, expected[i].ask, band.BidQty, band.AskQty)
+		}
+	}
+
+	// One-sided books have no mid
+	a = ComputeBookAnalytics("BTC-USDC", bids, nil)
+	if !a.MidPrice.IsZero() || !a.BestBid.Equal(math.LegacyNewDec(9995)) {
+		t.Errorf("expected zero mid and best bid 9995, got %s and %s", a.MidPrice, a.BestBid)
+	}
+}
+
+// TestVolatilityWindow tests that returns roll off the window
+func TestVolatilityWindow(t *testing.T) {
+	w := NewVolatilityWindow(2)
+	for _, price := range []int64{100, 110, 99, 99} {
+		w.Add(math.LegacyNewDec(price))
+	}
+	// Returns 0.1, -0.1, 0; only the last two remain: sqrt((0.01 + 0) / 2)
+	if w.Samples() != 2 {
+		t.Fatalf("expected 2 samples, got %d", w.Samples())
+	}
+	expected, _ := math.LegacyNewDecWithPrec(5, 3).ApproxSqrt()
+	if !w.Value().Sub(expected).Abs().LT(math.LegacyNewDecWithPrec(1, 12)) {
+		t.Errorf("expected volatility %s, got %s", expected, w.Value())
+	}
+}
+
+// TestKeeperBookAnalytics tests that analytics follow order book and trade
+// writes once they are refreshed, and that reverted writes never reach them
+func TestKeeperBookAnalytics(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	if k.GetBookAnalytics("BTC-USDC") != nil {
+		t.Fatal("expected no analytics before any write")
+	}
+
+	ob := types.NewOrderBook("BTC-USDC")
+	ob.Bids = []*types.PriceLevel{testLevel(9995, 1)}
+	ob.Asks = []*types.PriceLevel{testLevel(10005, 1)}
+	k.SetOrderBook(ctx, ob)
+
+	for i, price := range []int64{10000, 10100} {
+		k.SetTrade(ctx, &types.Trade{
+			TradeID:   fmt.Sprintf("trade-%d", i),
+			MarketID:  "BTC-USDC",
+			Price:     math.LegacyNewDec(price),
+			Quantity:  math.LegacyOneDec(),
+			Timestamp: time.Now(),
+		})
+	}
+
+	if k.GetBookAnalytics("BTC-USDC") != nil {
+		t.Fatal("expected no analytics before a refresh")
+	}
+	k.RefreshAnalytics(ctx)
+
+	a := k.GetBookAnalytics("BTC-USDC")
+	if a == nil || !a.MidPrice.Equal(math.LegacyNewDec(10000)) {
+		t.Fatalf("expected analytics with mid 10000, got %+v", a)
+	}
+	if a.VolatilitySamples != 1 || !a.Volatility.Sub(math.LegacyNewDecWithPrec(1, 2)).Abs().LT(math.LegacyNewDecWithPrec(1, 12)) {
+		t.Errorf("expected volatility 0.01 over 1 sample, got %s over %d", a.Volatility, a.VolatilitySamples)
+	}
+
+	// A transaction that writes a book and a trade, then fails
+	reverted, _ := ctx.CacheContext()
+	wide := types.NewOrderBook("BTC-USDC")
+	wide.Bids = []*types.PriceLevel{testLevel(9000, 1)}
+	wide.Asks = []*types.PriceLevel{testLevel(11000, 1)}
+	k.SetOrderBook(reverted, wide)
+	k.SetTrade(reverted, &types.Trade{
+		TradeID:   "trade-reverted",
+		MarketID:  "BTC-USDC",
+		Price:     math.LegacyNewDec(5000),
+		Quantity:  math.LegacyOneDec(),
+		Timestamp: time.Now(),
+	})
+	k.RefreshAnalytics(ctx)
+	if a := k.GetBookAnalytics("BTC-USDC"); !a.MidPrice.Equal(math.LegacyNewDec(10000)) || a.VolatilitySamples != 1 {
+		t.Errorf("expected the reverted writes ignored, got mid %s over %d samples", a.MidPrice, a.VolatilitySamples)
+	}
+
+	// Only trades after the last one recorded are added
+	k.SetTrade(ctx, &types.Trade{
+		TradeID:   "trade-2",
+		MarketID:  "BTC-USDC",
+		Price:     math.LegacyNewDec(10100),
+		Quantity:  math.LegacyOneDec(),
+		Timestamp: time.Now(),
+	})
+	k.RefreshAnalytics(ctx)
+	if a := k.GetBookAnalytics("BTC-USDC"); a.VolatilitySamples != 2 {
+		t.Errorf("expected 2 samples, got %d", a.VolatilitySamples)
+	}
+}
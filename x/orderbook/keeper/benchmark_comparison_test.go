@@ -437,6 +437,60 @@ func BenchmarkMixedOps_ART(b *testing.B) {
 	}
 }
 
+// ============================================================================
+// Allocation Benchmarks (price-level pooling off = before, on = after)
+// ============================================================================
+
+// levelChurnOrders returns 100 orders on distinct price levels, so adding and
+// removing them creates and frees a level each time
+func levelChurnOrders(marketID string) []*types.Order {
+	orders := make([]*types.Order, 100)
+	for j := range orders {
+		side := types.SideBuy
+		if j%2 == 0 {
+			side = types.SideSell
+		}
+		orders[j] = types.NewOrder(fmt.Sprintf("churn-%d", j), "trader", marketID, side, types.OrderTypeLimit,
+			math.LegacyNewDec(int64(50000+j)), math.LegacyOneDec())
+	}
+	return orders
+}
+
+// benchmarkLevelChurn measures allocs/op of a level creation and removal
+// burst with pooling on or off
+func benchmarkLevelChurn(b *testing.B, ob OrderBookEngine, pooled bool) {
+	defer func(prev bool) { priceLevelPooling = prev }(priceLevelPooling)
+	priceLevelPooling = pooled
+	orders := levelChurnOrders(ob.GetMarketID())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, order := range orders {
+			ob.AddOrder(order)
+		}
+		for _, order := range orders {
+			ob.RemoveOrder(order)
+		}
+	}
+}
+
+func BenchmarkLevelChurn_SkipList_Unpooled(b *testing.B) {
+	benchmarkLevelChurn(b, NewOrderBookV2("BTC-USD"), false)
+}
+
+func BenchmarkLevelChurn_SkipList_Pooled(b *testing.B) {
+	benchmarkLevelChurn(b, NewOrderBookV2("BTC-USD"), true)
+}
+
+func BenchmarkLevelChurn_BTree_Unpooled(b *testing.B) {
+	benchmarkLevelChurn(b, NewOrderBookBTree("BTC-USD"), false)
+}
+
+func BenchmarkLevelChurn_BTree_Pooled(b *testing.B) {
+	benchmarkLevelChurn(b, NewOrderBookBTree("BTC-USD"), true)
+}
+
 // ============================================================================
 // Correctness Tests
 // ============================================================================
@@ -579,3 +633,37 @@ func TestOrderBookInterface(t *testing.T) {
 		})
 	}
 }
+
+// TestPooledPriceLevelReuse verifies a recycled level carries nothing over
+// from the price it was last used at
+func TestPooledPriceLevelReuse(t *testing.T) {
+	marketID := "BTC-USD"
+	engines := []struct {
+		name   string
+		engine OrderBookEngine
+	}{
+		{"SkipList", NewOrderBookV2(marketID)},
+		{"BTree", NewOrderBookBTree(marketID)},
+	}
+
+	for _, e := range engines {
+		t.Run(e.name, func(t *testing.T) {
+			for i := int64(0); i < 10; i++ {
+				price := math.LegacyNewDec(50000 + i)
+				order := types.NewOrder(fmt.Sprintf("reuse-%d", i), "trader", marketID, types.SideBuy,
+					types.OrderTypeLimit, price, math.LegacyNewDec(i+1))
+				e.engine.AddOrder(order)
+
+				level := e.engine.GetPriceLevel(price, types.SideBuy)
+				if level == nil || len(level.Orders) != 1 || !level.Price.Equal(price) ||
+					!level.Quantity.Equal(math.LegacyNewDec(i+1)) {
+					t.Fatalf("round %d: unexpected level %+v", i, level)
+				}
+				if fixed, ok := level.FixedPrice(0); !ok || fixed != 50000+i {
+					t.Fatalf("round %d: stale fixed price %d", i, fixed)
+				}
+				e.engine.RemoveOrder(order)
+			}
+		})
+	}
+}
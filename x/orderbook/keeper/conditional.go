@@ -263,6 +263,15 @@ func (k *Keeper) ConditionalOrderEndBlocker(ctx sdk.Context) {
 			continue
 		}
 
+		// Triggers wait until the market reopens rather than firing into a halted book
+		if market := k.perpetualKeeper.GetMarket(ctx, marketID); market != nil && market.TradingHalt != nil {
+			k.Logger().Debug("conditional orders deferred while trading is halted",
+				"market_id", marketID,
+				"reason", market.TradingHalt.Error(),
+			)
+			continue
+		}
+
 		triggeredOrders := k.CheckAndTriggerConditionalOrders(ctx, marketID, markPrice)
 		for _, order := range triggeredOrders {
 			triggeredCount++
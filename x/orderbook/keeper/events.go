@@ -0,0 +1,139 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Global event log store keys
+var (
+	EventKeyPrefix = []byte{0x07} // seq -> Event
+	EventSeqKey    = []byte{0x08}
+)
+
+const (
+	// EventRetention is the number of latest events the log keeps. Older
+	// events are pruned at the end of each block; consumers further behind
+	// resync from a snapshot.
+	EventRetention uint64 = 100_000
+
+	// maxEventsPrunedPerBlock bounds the deletes of one block, so a log that
+	// outgrew the window shrinks back over several blocks
+	maxEventsPrunedPerBlock = 5_000
+)
+
+func eventKey(seq uint64) []byte {
+	key := make([]byte, len(EventKeyPrefix)+8)
+	copy(key, EventKeyPrefix)
+	binary.BigEndian.PutUint64(key[len(EventKeyPrefix):], seq)
+	return key
+}
+
+// nextEventSeq allocates the next global event sequence number
+func (k *Keeper) nextEventSeq(ctx sdk.Context) uint64 {
+	seq := k.getCounter(ctx, EventSeqKey) + 1
+	k.setCounter(ctx, EventSeqKey, seq)
+	return seq
+}
+
+// GetLastEventSeq returns the sequence number of the latest event, 0 if none
+func (k *Keeper) GetLastEventSeq(ctx sdk.Context) uint64 {
+	return k.getCounter(ctx, EventSeqKey)
+}
+
+func (k *Keeper) appendEvent(ctx sdk.Context, event *types.Event) {
+	bz, _ := json.Marshal(event)
+	k.GetStore(ctx).Set(eventKey(event.Seq), bz)
+}
+
+// sequenceOrder stamps an order update with the next sequence number and
+// appends the order's new state to the event log. Engines call it after each
+// change clients should see (placed, filled, amended, cancelled), before the
+// order is saved.
+func (k *Keeper) sequenceOrder(ctx sdk.Context, order *types.Order) {
+	order.Seq = k.nextEventSeq(ctx)
+	k.appendEvent(ctx, &types.Event{
+		Seq:       order.Seq,
+		Type:      types.EventTypeOrder,
+		MarketID:  order.MarketID,
+		Order:     order,
+		Timestamp: order.UpdatedAt,
+	})
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"order_update",
+			sdk.NewAttribute("seq", strconv.FormatUint(order.Seq, 10)),
+			sdk.NewAttribute("order_id", order.OrderID),
+			sdk.NewAttribute("market_id", order.MarketID),
+			sdk.NewAttribute("trader", order.Trader),
+			sdk.NewAttribute("status", order.Status.String()),
+			sdk.NewAttribute("filled_qty", order.FilledQty.String()),
+		),
+	)
+}
+
+// saveOrderUpdate sequences an order update and saves the order
+func (k *Keeper) saveOrderUpdate(ctx sdk.Context, order *types.Order) {
+	k.sequenceOrder(ctx, order)
+	k.SetOrder(ctx, order)
+}
+
+// sequenceTrade stamps a new trade with the next sequence number and the
+// block time, so it falls on the same fee ledger day as its fees, and
+// appends it to the event log
+func (k *Keeper) sequenceTrade(ctx sdk.Context, trade *types.Trade) {
+	trade.Seq = k.nextEventSeq(ctx)
+	if !ctx.BlockTime().IsZero() {
+		trade.Timestamp = ctx.BlockTime()
+	}
+	k.appendEvent(ctx, &types.Event{
+		Seq:       trade.Seq,
+		Type:      types.EventTypeTrade,
+		MarketID:  trade.MarketID,
+		Trade:     trade,
+		Timestamp: trade.Timestamp,
+	})
+}
+
+// GetEvents returns up to limit events with sequence numbers of at least
+// fromSeq, in sequence order
+func (k *Keeper) GetEvents(ctx sdk.Context, fromSeq uint64, limit int) []*types.Event {
+	iterator := k.GetStore(ctx).Iterator(eventKey(fromSeq), storetypes.PrefixEndBytes(EventKeyPrefix))
+	defer iterator.Close()
+
+	events := make([]*types.Event, 0)
+	for ; iterator.Valid() && len(events) < limit; iterator.Next() {
+		var event types.Event
+		if err := json.Unmarshal(iterator.Value(), &event); err != nil {
+			continue
+		}
+		events = append(events, &event)
+	}
+	return events
+}
+
+// EventLogEndBlocker prunes events older than the latest EventRetention
+func (k *Keeper) EventLogEndBlocker(ctx sdk.Context) {
+	last := k.GetLastEventSeq(ctx)
+	if last <= EventRetention {
+		return
+	}
+
+	store := k.GetStore(ctx)
+	iterator := store.Iterator(eventKey(0), eventKey(last-EventRetention+1))
+	var expired [][]byte
+	for ; iterator.Valid() && len(expired) < maxEventsPrunedPerBlock; iterator.Next() {
+		expired = append(expired, append([]byte(nil), iterator.Key()...))
+	}
+	iterator.Close()
+
+	for _, key := range expired {
+		store.Delete(key)
+	}
+}
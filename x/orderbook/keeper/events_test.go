@@ -0,0 +1,94 @@
+package keeper
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestEventSequencing tests that order updates and trades across markets get
+// strictly increasing sequence numbers and can be read back from any point
+func TestEventSequencing(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+
+	place := func(trader, marketID string, side types.Side, price int64) *types.Order {
+		order, _, err := k.PlaceOrder(ctx, trader, marketID, side, types.OrderTypeLimit, math.LegacyNewDec(price), math.LegacyOneDec())
+		if err != nil {
+			t.Fatalf("failed to place order: %v", err)
+		}
+		return order
+	}
+	maker := place("maker", "BTC-USDC", types.SideSell, 50000) // 1
+	eth := place("maker", "ETH-USDC", types.SideSell, 3000)    // 2
+	taker := place("taker", "BTC-USDC", types.SideBuy, 50000)  // 3 trade, 4 maker filled, 5 taker filled
+	if _, err := k.CancelOrder(ctx, "maker", eth.OrderID); err != nil {
+		t.Fatalf("failed to cancel order: %v", err)
+	}
+
+	events := k.GetEvents(ctx, 0, 100)
+	want := []struct {
+		kind    string
+		orderID string
+		status  types.OrderStatus
+	}{
+		{types.EventTypeOrder, maker.OrderID, types.OrderStatusOpen},
+		{types.EventTypeOrder, eth.OrderID, types.OrderStatusOpen},
+		{types.EventTypeTrade, "", 0},
+		{types.EventTypeOrder, maker.OrderID, types.OrderStatusFilled},
+		{types.EventTypeOrder, taker.OrderID, types.OrderStatusFilled},
+		{types.EventTypeOrder, eth.OrderID, types.OrderStatusCancelled},
+	}
+	if len(events) != len(want) || k.GetLastEventSeq(ctx) != uint64(len(want)) {
+		t.Fatalf("expected %d events, got %d (last seq %d)", len(want), len(events), k.GetLastEventSeq(ctx))
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Seq != uint64(i+1) || e.Type != w.kind {
+			t.Errorf("event %d: expected %s #%d, got %s #%d", i, w.kind, i+1, e.Type, e.Seq)
+			continue
+		}
+		if w.kind == types.EventTypeTrade {
+			if e.Trade == nil || e.Trade.Seq != e.Seq || e.Trade.MakerOrderID != maker.OrderID {
+				t.Errorf("event %d: unexpected trade %+v", i, e.Trade)
+			}
+			continue
+		}
+		if e.Order == nil || e.Order.OrderID != w.orderID || e.Order.Status != w.status || e.Order.Seq != e.Seq {
+			t.Errorf("event %d: expected %s %s, got %+v", i, w.orderID, w.status, e.Order)
+		}
+	}
+	if stored := k.GetOrder(ctx, taker.OrderID); stored.Seq != 5 {
+		t.Errorf("expected stored order to carry its latest seq 5, got %d", stored.Seq)
+	}
+
+	// Catch up from the middle of the log
+	if tail := k.GetEvents(ctx, 4, 2); len(tail) != 2 || tail[0].Seq != 4 || tail[1].Seq != 5 {
+		t.Errorf("expected events 4 and 5, got %d events", len(tail))
+	}
+}
+
+// TestEventLogPruning tests that the end blocker keeps only the latest
+// EventRetention events, pruning at most maxEventsPrunedPerBlock per block
+func TestEventLogPruning(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+
+	total := EventRetention + maxEventsPrunedPerBlock + 10
+	for seq := uint64(1); seq <= total; seq++ {
+		k.appendEvent(ctx, &types.Event{Seq: k.nextEventSeq(ctx), Type: types.EventTypeOrder, MarketID: "BTC-USDC"})
+	}
+
+	k.EventLogEndBlocker(ctx)
+	if first := k.GetEvents(ctx, 0, 1); len(first) != 1 || first[0].Seq != maxEventsPrunedPerBlock+1 {
+		t.Fatalf("expected one block to prune %d events, got %+v", maxEventsPrunedPerBlock, first)
+	}
+
+	k.EventLogEndBlocker(ctx)
+	first := k.GetEvents(ctx, 0, 1)
+	if len(first) != 1 || first[0].Seq != total-EventRetention+1 {
+		t.Fatalf("expected the log to start at %d, got %+v", total-EventRetention+1, first)
+	}
+	if tail := k.GetEvents(ctx, total, 10); len(tail) != 1 || k.GetLastEventSeq(ctx) != total {
+		t.Errorf("expected the latest event kept, got %d events (last seq %d)", len(tail), k.GetLastEventSeq(ctx))
+	}
+}
@@ -0,0 +1,111 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// ExecutionKeyPrefix stores each order's fills in fill order:
+// orderID/n -> Execution, n counting from 1
+var ExecutionKeyPrefix = []byte{0x84}
+
+// executionPrefix is the prefix of one order's executions
+func executionPrefix(orderID string) []byte {
+	return historyPrefix(ExecutionKeyPrefix, orderID)
+}
+
+// recordExecutions stores the taker's and the maker's execution of a trade,
+// each with its order's cumulative filled quantity. A trade stored again is
+// not recorded twice.
+func (k *Keeper) recordExecutions(ctx sdk.Context, trade *types.Trade) {
+	taker, maker := types.NewExecutions(trade)
+	for _, exec := range []*types.Execution{taker, maker} {
+		if exec.OrderID == "" {
+			continue
+		}
+		last, n := k.lastExecution(ctx, exec.OrderID)
+		if last != nil && last.TradeID == exec.TradeID {
+			continue
+		}
+		exec.CumulativeQty = exec.Quantity
+		if last != nil {
+			exec.CumulativeQty = last.CumulativeQty.Add(exec.Quantity)
+		}
+		bz, _ := json.Marshal(exec)
+		k.GetStore(ctx).Set(binary.BigEndian.AppendUint64(executionPrefix(exec.OrderID), n+1), bz)
+	}
+}
+
+// lastExecution returns an order's latest execution and the number of
+// executions recorded for it
+func (k *Keeper) lastExecution(ctx sdk.Context, orderID string) (*types.Execution, uint64) {
+	prefix := executionPrefix(orderID)
+	iterator := storetypes.KVStoreReversePrefixIterator(k.GetStore(ctx), prefix)
+	defer iterator.Close()
+	if !iterator.Valid() {
+		return nil, 0
+	}
+	var exec types.Execution
+	if err := json.Unmarshal(iterator.Value(), &exec); err != nil {
+		return nil, binary.BigEndian.Uint64(iterator.Key()[len(prefix):])
+	}
+	return &exec, binary.BigEndian.Uint64(iterator.Key()[len(prefix):])
+}
+
+// GetOrderExecutions returns an order's fills in the order they happened
+func (k *Keeper) GetOrderExecutions(ctx sdk.Context, orderID string) []*types.Execution {
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), executionPrefix(orderID))
+	defer iterator.Close()
+
+	executions := make([]*types.Execution, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var exec types.Execution
+		if err := json.Unmarshal(iterator.Value(), &exec); err != nil {
+			continue
+		}
+		executions = append(executions, &exec)
+	}
+	return executions
+}
+
+// GetTradeExecutions returns the taker's and the maker's execution of a
+// trade, as recorded when it was stored. Returns ErrTradeNotFound if the
+// trade does not exist.
+func (k *Keeper) GetTradeExecutions(ctx sdk.Context, tradeID string) ([]*types.Execution, error) {
+	trade := k.GetTrade(ctx, tradeID)
+	if trade == nil {
+		return nil, types.ErrTradeNotFound.Wrap(tradeID)
+	}
+
+	executions := make([]*types.Execution, 0, 2)
+	for _, orderID := range []string{trade.TakerOrderID, trade.MakerOrderID} {
+		if exec := k.findExecution(ctx, orderID, tradeID); exec != nil {
+			executions = append(executions, exec)
+		}
+	}
+	return executions, nil
+}
+
+// findExecution scans an order's executions, latest first, for a trade's
+func (k *Keeper) findExecution(ctx sdk.Context, orderID, tradeID string) *types.Execution {
+	if orderID == "" {
+		return nil
+	}
+	iterator := storetypes.KVStoreReversePrefixIterator(k.GetStore(ctx), executionPrefix(orderID))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var exec types.Execution
+		if err := json.Unmarshal(iterator.Value(), &exec); err != nil {
+			continue
+		}
+		if exec.TradeID == tradeID {
+			return &exec
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestOrderExecutions tests that each fill of a resting order is recorded
+// with the order's cumulative filled quantity, and that a trade's executions
+// are found for both of its orders
+func TestOrderExecutions(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	maker, taker := "alice", "bob"
+
+	resting, _, err := k.PlaceOrder(ctx, maker, "BTC-USDC", types.SideSell, types.OrderTypeLimit,
+		math.LegacyNewDec(50000), math.LegacyNewDec(3))
+	if err != nil {
+		t.Fatalf("failed to place maker order: %v", err)
+	}
+	trades := make([]*types.Trade, 0, 2)
+	for _, qty := range []math.LegacyDec{math.LegacyOneDec(), math.LegacyNewDecWithPrec(15, 1)} {
+		_, result, err := k.PlaceOrder(ctx, taker, "BTC-USDC", types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(50000), qty)
+		if err != nil || len(result.Trades) != 1 {
+			t.Fatalf("expected one trade, got %v (err %v)", result, err)
+		}
+		trades = append(trades, result.Trades[0])
+	}
+
+	executions := k.GetOrderExecutions(ctx, resting.OrderID)
+	if len(executions) != 2 {
+		t.Fatalf("expected 2 executions, got %d", len(executions))
+	}
+	for i, want := range []math.LegacyDec{math.LegacyOneDec(), math.LegacyNewDecWithPrec(25, 1)} {
+		exec := executions[i]
+		if exec.TradeID != trades[i].TradeID || exec.Liquidity != types.FeeRoleMaker || exec.Side != types.SideSell {
+			t.Errorf("execution %d: expected a maker sell of %s, got %+v", i, trades[i].TradeID, exec)
+		}
+		if !exec.CumulativeQty.Equal(want) {
+			t.Errorf("execution %d: expected cumulative %s, got %s", i, want, exec.CumulativeQty)
+		}
+		if fee := trades[i].MakerFee; !fee.IsNil() && !exec.Fee.Equal(fee) {
+			t.Errorf("execution %d: expected fee %s, got %s", i, fee, exec.Fee)
+		}
+	}
+
+	both, err := k.GetTradeExecutions(ctx, trades[1].TradeID)
+	if err != nil || len(both) != 2 {
+		t.Fatalf("expected the taker's and maker's executions, got %v (err %v)", both, err)
+	}
+	if both[0].Trader != taker || both[0].Liquidity != types.FeeRoleTaker || !both[0].CumulativeQty.Equal(math.LegacyNewDecWithPrec(15, 1)) {
+		t.Errorf("unexpected taker execution %+v", both[0])
+	}
+	if both[1].Trader != maker || !both[1].CumulativeQty.Equal(math.LegacyNewDecWithPrec(25, 1)) {
+		t.Errorf("unexpected maker execution %+v", both[1])
+	}
+
+	// Storing a trade again does not record it twice
+	k.SetTrade(ctx, trades[1])
+	if got := len(k.GetOrderExecutions(ctx, resting.OrderID)); got != 2 {
+		t.Errorf("expected 2 executions after re-storing a trade, got %d", got)
+	}
+	if _, err := k.GetTradeExecutions(ctx, "trade-missing"); !errors.Is(err, types.ErrTradeNotFound) {
+		t.Errorf("expected trade not found, got %v", err)
+	}
+}
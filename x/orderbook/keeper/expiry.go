@@ -0,0 +1,90 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"strconv"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// OrderExpiryKeyPrefix indexes resting good-till-date orders by expiry:
+// expireAt/orderID -> empty. Entries of orders that were filled or cancelled
+// first are dropped when their expiry comes round.
+var OrderExpiryKeyPrefix = []byte{0x0B}
+
+// expiryKey is expireAt/orderID, so entries sort by expiry
+func expiryKey(expireAt time.Time, orderID string) []byte {
+	key := binary.BigEndian.AppendUint64(append([]byte{}, OrderExpiryKeyPrefix...), uint64(expireAt.UnixNano()))
+	return append(key, orderID...)
+}
+
+// indexExpiry schedules the expiry of a good-till-date order left resting
+func (k *Keeper) indexExpiry(ctx sdk.Context, order *types.Order) {
+	if order.ExpireAt == nil || !order.IsActive() {
+		return
+	}
+	k.GetStore(ctx).Set(expiryKey(*order.ExpireAt, order.OrderID), []byte{})
+}
+
+// ExpireOrders takes every good-till-date order whose expiry is at or before
+// the block time off the book and marks it expired, and returns the expired
+// orders. Each emits an order_expired event rather than a cancel.
+func (k *Keeper) ExpireOrders(ctx sdk.Context) []*types.Order {
+	store := k.GetStore(ctx)
+	end := expiryKey(ctx.BlockTime().Add(time.Nanosecond), "")
+	iterator := store.Iterator(OrderExpiryKeyPrefix, end)
+	var keys [][]byte
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+
+	expired := make([]*types.Order, 0)
+	books := make(map[string]*types.OrderBook)
+	for _, key := range keys {
+		store.Delete(key)
+		expireAt := int64(binary.BigEndian.Uint64(key[len(OrderExpiryKeyPrefix):]))
+		order := k.GetOrder(ctx, string(key[len(OrderExpiryKeyPrefix)+8:]))
+		// A reused order ID may carry another expiry, or none
+		if order == nil || !order.IsActive() || order.ExpireAt == nil || order.ExpireAt.UnixNano() != expireAt {
+			continue
+		}
+
+		ob, ok := books[order.MarketID]
+		if !ok {
+			ob = k.GetOrderBook(ctx, order.MarketID)
+			books[order.MarketID] = ob
+		}
+		if ob != nil {
+			ob.RemoveOrder(order)
+		}
+		order.Expire()
+		k.saveOrderUpdate(ctx, order)
+		k.emitExpiryEvent(ctx, order)
+		expired = append(expired, order)
+	}
+	for _, ob := range books {
+		if ob != nil {
+			k.SetOrderBook(ctx, ob)
+		}
+	}
+	return expired
+}
+
+// emitExpiryEvent emits an order_expired event, which clients can tell apart
+// from the cancel of a user
+func (k *Keeper) emitExpiryEvent(ctx sdk.Context, order *types.Order) {
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"order_expired",
+			sdk.NewAttribute("seq", strconv.FormatUint(order.Seq, 10)),
+			sdk.NewAttribute("order_id", order.OrderID),
+			sdk.NewAttribute("market_id", order.MarketID),
+			sdk.NewAttribute("trader", order.Trader),
+			sdk.NewAttribute("expire_at", order.ExpireAt.UTC().Format(time.RFC3339Nano)),
+			sdk.NewAttribute("remaining_qty", order.RemainingQty().String()),
+		),
+	)
+}
@@ -0,0 +1,86 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestExpireOrders tests that good-till-date orders leave the book as expired
+// once the block time reaches their expiry, with an order_expired event, and
+// that filled or cancelled orders are left alone
+func TestExpireOrders(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	base := time.Unix(1_700_000_000, 0).UTC()
+	ctx = ctx.WithBlockTime(base)
+
+	gtd := func(side types.Side, price int64, ttl time.Duration) *types.Order {
+		t.Helper()
+		order, _, err := k.PlaceOrderWithExpiry(ctx, "alice", "BTC-USDC", side,
+			math.LegacyNewDec(price), math.LegacyOneDec(), base.Add(ttl))
+		if err != nil {
+			t.Fatalf("failed to place GTD order: %v", err)
+		}
+		return order
+	}
+
+	if _, _, err := k.PlaceOrderWithExpiry(ctx, "alice", "BTC-USDC", types.SideBuy,
+		math.LegacyNewDec(49000), math.LegacyOneDec(), base); !errors.Is(err, types.ErrInvalidExpiry) {
+		t.Fatalf("expected ErrInvalidExpiry for an expiry at the block time, got %v", err)
+	}
+
+	soon := gtd(types.SideBuy, 49000, time.Minute)
+	later := gtd(types.SideBuy, 48000, time.Hour)
+	cancelled := gtd(types.SideBuy, 47000, time.Minute)
+	filled := gtd(types.SideSell, 51000, time.Minute)
+	if _, err := k.CancelOrder(ctx, "alice", cancelled.OrderID); err != nil {
+		t.Fatalf("failed to cancel order: %v", err)
+	}
+	if _, _, err := k.PlaceOrder(ctx, "bob", "BTC-USDC", types.SideBuy, types.OrderTypeLimit,
+		math.LegacyNewDec(51000), math.LegacyOneDec()); err != nil {
+		t.Fatalf("failed to place taker order: %v", err)
+	}
+
+	if expired := k.ExpireOrders(ctx.WithBlockTime(base.Add(time.Minute - time.Nanosecond))); len(expired) != 0 {
+		t.Fatalf("expected nothing to expire before the expiry, got %d orders", len(expired))
+	}
+
+	ctx = ctx.WithBlockTime(base.Add(time.Minute)).WithEventManager(sdk.NewEventManager())
+	expired := k.ExpireOrders(ctx)
+	if len(expired) != 1 || expired[0].OrderID != soon.OrderID {
+		t.Fatalf("expected only %s to expire, got %v", soon.OrderID, expired)
+	}
+	if order := k.GetOrder(ctx, soon.OrderID); order.Status != types.OrderStatusExpired {
+		t.Errorf("expected expired status, got %s", order.Status)
+	}
+	for _, id := range []string{cancelled.OrderID, filled.OrderID} {
+		if order := k.GetOrder(ctx, id); order.Status == types.OrderStatusExpired {
+			t.Errorf("expected %s to keep its final status, got %s", id, order.Status)
+		}
+	}
+	if bid := k.GetOrderBook(ctx, "BTC-USDC").BestBid(); bid == nil || !bid.Price.Equal(later.Price) {
+		t.Errorf("expected the later GTD order to be the best bid, got %+v", bid)
+	}
+
+	var events int
+	for _, event := range ctx.EventManager().Events() {
+		if event.Type == "order_expired" {
+			events++
+		}
+	}
+	if events != 1 {
+		t.Errorf("expected one order_expired event, got %d", events)
+	}
+
+	// Each expiry runs once
+	if again := k.ExpireOrders(ctx); len(again) != 0 {
+		t.Errorf("expected no further expiries, got %d", len(again))
+	}
+	if msg, broken := BookQuantityInvariant(k)(ctx); broken {
+		t.Fatalf("expected invariant to hold: %s", msg)
+	}
+}
@@ -0,0 +1,314 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Store key prefixes for the fee ledger
+var (
+	FeeEntryKeyPrefix  = []byte{0x70} // marketID/day/tradeID/role -> FeeEntry
+	FeeRollupKeyPrefix = []byte{0x71} // day/marketID -> FeeRollup
+	FeeSplitKey        = []byte{0x72}
+	FeeBalancesKey     = []byte{0x73}
+)
+
+// FeeRevenueSink receives the riverpool share of a market's fees for each
+// settled day
+type FeeRevenueSink interface {
+	RecordFeeRevenue(ctx sdk.Context, marketID string, amount math.LegacyDec) error
+}
+
+// SetFeeRevenueSink sets where the riverpool share of fees is credited
+func (k *Keeper) SetFeeRevenueSink(sink FeeRevenueSink) {
+	k.feeRevenueSink = sink
+}
+
+// FeeTreasury is the protocol treasury: it owns the governance-set fee split
+// and receives the treasury share of a market's fees for each settled day
+type FeeTreasury interface {
+	FeeSplit(ctx sdk.Context) (insuranceFund, riverPool, treasury math.LegacyDec)
+	CreditFees(ctx sdk.Context, marketID string, day int64, amount math.LegacyDec) error
+}
+
+// SetFeeTreasury attaches the protocol treasury. Its split then replaces the
+// one set with SetFeeSplit.
+func (k *Keeper) SetFeeTreasury(treasury FeeTreasury) {
+	k.feeTreasury = treasury
+}
+
+func feeEntryKey(marketID string, day int64, tradeID, role string) []byte {
+	key := append(append([]byte{}, FeeEntryKeyPrefix...), marketID...)
+	key = binary.BigEndian.AppendUint64(append(key, '/'), uint64(day))
+	return append(append(append(key, tradeID...), '/'), role...)
+}
+
+// feeRollupKey puts the big-endian day first so rollups sort by day
+func feeRollupKey(day int64, marketID string) []byte {
+	key := binary.BigEndian.AppendUint64(append([]byte{}, FeeRollupKeyPrefix...), uint64(day))
+	return append(key, marketID...)
+}
+
+// ============ Fee split ============
+
+// GetFeeSplit returns the treasury's fee split if one is attached, otherwise
+// the stored split or the default if none is set
+func (k *Keeper) GetFeeSplit(ctx sdk.Context) *types.FeeSplit {
+	if k.feeTreasury != nil {
+		insuranceFund, riverPool, treasury := k.feeTreasury.FeeSplit(ctx)
+		return &types.FeeSplit{InsuranceFund: insuranceFund, RiverPool: riverPool, Treasury: treasury}
+	}
+	bz := k.GetStore(ctx).Get(FeeSplitKey)
+	if bz == nil {
+		return types.DefaultFeeSplit()
+	}
+	var split types.FeeSplit
+	if err := json.Unmarshal(bz, &split); err != nil {
+		return types.DefaultFeeSplit()
+	}
+	return &split
+}
+
+// SetFeeSplit validates and saves the fee split for fees charged from now on
+func (k *Keeper) SetFeeSplit(ctx sdk.Context, split *types.FeeSplit) error {
+	if err := split.Validate(); err != nil {
+		return err
+	}
+	bz, _ := json.Marshal(split)
+	k.GetStore(ctx).Set(FeeSplitKey, bz)
+	return nil
+}
+
+// ============ Ledger ============
+
+// recordTradeFees records the taker and maker fees of a new trade in the
+// ledger and the market's rollup for the day. A fee the trader pays in the
+// fee token is charged here and zeroed on the trade, so only the remaining
+// USDC fees reach the positions and the split. A maker fee turned negative
+// by a maker rebate is recorded as it is, so the rebate comes out of the
+// day's split.
+func (k *Keeper) recordTradeFees(ctx sdk.Context, trade *types.Trade) {
+	charges := []struct {
+		role, trader string
+		fee          *math.LegacyDec
+	}{
+		{types.FeeRoleTaker, trade.Taker, &trade.TakerFee},
+		{types.FeeRoleMaker, trade.Maker, &trade.MakerFee},
+	}
+
+	var split *types.FeeSplit
+	var rollup *types.FeeRollup
+	var feeToken *types.FeeTokenConfig
+	day := types.FeeDay(ctx.BlockTime())
+	store := k.GetStore(ctx)
+	for _, charge := range charges {
+		fee := *charge.fee
+		if fee.IsNil() || fee.IsZero() {
+			continue
+		}
+		if split == nil {
+			split = k.GetFeeSplit(ctx)
+			rollup = k.GetFeeRollup(ctx, day, trade.MarketID)
+			feeToken = k.GetFeeTokenConfig(ctx)
+		}
+
+		entry := &types.FeeEntry{
+			TradeID:   trade.TradeID,
+			MarketID:  trade.MarketID,
+			Trader:    charge.trader,
+			Role:      charge.role,
+			Amount:    fee,
+			Timestamp: ctx.BlockTime(),
+		}
+		// A rebate is paid in USDC
+		if fee.IsPositive() {
+			if amount, ok := k.chargeFeeInKind(ctx, feeToken, charge.trader, fee); ok {
+				*charge.fee = math.LegacyZeroDec()
+				entry.Amount = math.LegacyZeroDec()
+				entry.FeeDenom = feeToken.Denom
+				entry.FeeTokenAmount = amount
+				entry.ReplacedFee = fee
+			}
+		}
+		entry.InsuranceFund, entry.RiverPool, entry.Treasury = split.Apply(entry.Amount)
+		bz, _ := json.Marshal(entry)
+		store.Set(feeEntryKey(trade.MarketID, day, trade.TradeID, charge.role), bz)
+		rollup.Add(entry)
+	}
+	if rollup != nil {
+		k.setFeeRollup(ctx, rollup)
+	}
+}
+
+// GetFeeEntries returns a market's fee entries for a day
+func (k *Keeper) GetFeeEntries(ctx sdk.Context, marketID string, day int64) []*types.FeeEntry {
+	prefix := feeEntryKey(marketID, day, "", "")
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), prefix[:len(prefix)-1])
+	defer iterator.Close()
+
+	entries := make([]*types.FeeEntry, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var entry types.FeeEntry
+		if err := json.Unmarshal(iterator.Value(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	return entries
+}
+
+// GetFeeEntry returns the fee entry of one side of a trade, nil if none was
+// recorded
+func (k *Keeper) GetFeeEntry(ctx sdk.Context, marketID string, day int64, tradeID, role string) *types.FeeEntry {
+	bz := k.GetStore(ctx).Get(feeEntryKey(marketID, day, tradeID, role))
+	if bz == nil {
+		return nil
+	}
+	var entry types.FeeEntry
+	if err := json.Unmarshal(bz, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// ============ Rollups ============
+
+// GetFeeRollup returns a market's rollup for a day, empty if nothing was charged
+func (k *Keeper) GetFeeRollup(ctx sdk.Context, day int64, marketID string) *types.FeeRollup {
+	bz := k.GetStore(ctx).Get(feeRollupKey(day, marketID))
+	if bz == nil {
+		return types.NewFeeRollup(day, marketID)
+	}
+	var rollup types.FeeRollup
+	if err := json.Unmarshal(bz, &rollup); err != nil {
+		return types.NewFeeRollup(day, marketID)
+	}
+	return &rollup
+}
+
+func (k *Keeper) setFeeRollup(ctx sdk.Context, rollup *types.FeeRollup) {
+	bz, _ := json.Marshal(rollup)
+	k.GetStore(ctx).Set(feeRollupKey(rollup.Day, rollup.MarketID), bz)
+}
+
+// GetFeeRollups returns the rollups of days fromDay to toDay inclusive, by day
+// then market, optionally for one market
+func (k *Keeper) GetFeeRollups(ctx sdk.Context, fromDay, toDay int64, marketID string) []*types.FeeRollup {
+	rollups := make([]*types.FeeRollup, 0)
+	if fromDay > toDay {
+		return rollups
+	}
+	iterator := k.GetStore(ctx).Iterator(feeRollupKey(fromDay, ""), feeRollupKey(toDay+1, ""))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var rollup types.FeeRollup
+		if err := json.Unmarshal(iterator.Value(), &rollup); err != nil {
+			continue
+		}
+		if marketID != "" && rollup.MarketID != marketID {
+			continue
+		}
+		rollups = append(rollups, &rollup)
+	}
+	return rollups
+}
+
+// GetAllFeeRollups returns every rollup, by day then market
+func (k *Keeper) GetAllFeeRollups(ctx sdk.Context) []*types.FeeRollup {
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), FeeRollupKeyPrefix)
+	defer iterator.Close()
+
+	rollups := make([]*types.FeeRollup, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var rollup types.FeeRollup
+		if err := json.Unmarshal(iterator.Value(), &rollup); err != nil {
+			continue
+		}
+		rollups = append(rollups, &rollup)
+	}
+	return rollups
+}
+
+// ============ Settlement ============
+
+// GetFeeBalances returns the cumulative settled fee shares
+func (k *Keeper) GetFeeBalances(ctx sdk.Context) *types.FeeBalances {
+	bz := k.GetStore(ctx).Get(FeeBalancesKey)
+	if bz == nil {
+		return types.NewFeeBalances()
+	}
+	var balances types.FeeBalances
+	if err := json.Unmarshal(bz, &balances); err != nil {
+		return types.NewFeeBalances()
+	}
+	return &balances
+}
+
+func (k *Keeper) setFeeBalances(ctx sdk.Context, balances *types.FeeBalances) {
+	bz, _ := json.Marshal(balances)
+	k.GetStore(ctx).Set(FeeBalancesKey, bz)
+}
+
+// FeeLedgerEndBlocker settles every day that has ended since the last
+// settlement. Each market's riverpool share goes to the fee revenue sink and
+// its treasury share to the fee treasury, and all shares are added to the
+// protocol fee balances. A share the pools cannot take, for example with no
+// active pool, is credited to the treasury.
+func (k *Keeper) FeeLedgerEndBlocker(ctx sdk.Context) {
+	balances := k.GetFeeBalances(ctx)
+	lastDay := types.FeeDay(ctx.BlockTime()) - 1
+	if balances.SettledDay >= lastDay {
+		return
+	}
+
+	for _, rollup := range k.GetFeeRollups(ctx, balances.SettledDay+1, lastDay, "") {
+		if rollup.Settled {
+			continue
+		}
+		credit := *rollup
+		if k.feeRevenueSink != nil && rollup.RiverPool.IsPositive() {
+			cacheCtx, write := ctx.CacheContext()
+			if err := k.feeRevenueSink.RecordFeeRevenue(cacheCtx, rollup.MarketID, rollup.RiverPool); err != nil {
+				k.Logger().Error("failed to credit fee revenue to riverpools, crediting treasury",
+					"day", rollup.Day, "market_id", rollup.MarketID, "amount", rollup.RiverPool, "error", err)
+				credit.Treasury = credit.Treasury.Add(credit.RiverPool)
+				credit.RiverPool = math.LegacyZeroDec()
+			} else {
+				write()
+			}
+		}
+		if k.feeTreasury != nil && credit.Treasury.IsPositive() {
+			cacheCtx, write := ctx.CacheContext()
+			if err := k.feeTreasury.CreditFees(cacheCtx, rollup.MarketID, rollup.Day, credit.Treasury); err != nil {
+				k.Logger().Error("failed to credit fees to the treasury",
+					"day", rollup.Day, "market_id", rollup.MarketID, "amount", credit.Treasury, "error", err)
+			} else {
+				write()
+			}
+		}
+		balances.Credit(&credit)
+
+		rollup.Settled = true
+		k.setFeeRollup(ctx, rollup)
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				"fee_rollup_settled",
+				sdk.NewAttribute("day", math.NewInt(rollup.Day).String()),
+				sdk.NewAttribute("market_id", rollup.MarketID),
+				sdk.NewAttribute("total", rollup.Total().String()),
+				sdk.NewAttribute("insurance_fund", credit.InsuranceFund.String()),
+				sdk.NewAttribute("riverpool", credit.RiverPool.String()),
+				sdk.NewAttribute("treasury", credit.Treasury.String()),
+			),
+		)
+	}
+
+	balances.SettledDay = lastDay
+	k.setFeeBalances(ctx, balances)
+}
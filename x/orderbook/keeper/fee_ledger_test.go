@@ -0,0 +1,192 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// mockFeeRevenueSink records the riverpool fee credits, failing those for
+// failMarket
+type mockFeeRevenueSink struct {
+	credits    map[string]math.LegacyDec
+	failMarket string
+}
+
+func (m *mockFeeRevenueSink) RecordFeeRevenue(ctx sdk.Context, marketID string, amount math.LegacyDec) error {
+	if marketID == m.failMarket {
+		return errors.New("no active pools")
+	}
+	m.credits[marketID] = amount
+	return nil
+}
+
+// TestFeeLedger tests that trades record their fees and daily rollup, and
+// that ended days settle into the riverpools and the protocol fee balances
+func TestFeeLedger(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	sink := &mockFeeRevenueSink{credits: make(map[string]math.LegacyDec)}
+	k.SetFeeRevenueSink(sink)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx = ctx.WithBlockTime(start)
+
+	trade := func(marketID string) *types.Trade {
+		t.Helper()
+		if _, _, err := k.PlaceOrder(ctx, "maker", marketID, types.SideSell, types.OrderTypeLimit,
+			math.LegacyNewDec(50000), math.LegacyOneDec()); err != nil {
+			t.Fatalf("failed to place maker order: %v", err)
+		}
+		_, result, err := k.PlaceOrder(ctx, "taker", marketID, types.SideBuy, types.OrderTypeLimit,
+			math.LegacyNewDec(50000), math.LegacyOneDec())
+		if err != nil || len(result.Trades) != 1 {
+			t.Fatalf("expected one trade, got %v (err %v)", result, err)
+		}
+		return result.Trades[0]
+	}
+	btc := trade("BTC-USDC")
+	trade("BTC-USDC")
+	trade("ETH-USDC")
+
+	day := types.FeeDay(start)
+	entries := k.GetFeeEntries(ctx, "BTC-USDC", day)
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 BTC fee entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if !entry.InsuranceFund.Add(entry.RiverPool).Add(entry.Treasury).Equal(entry.Amount) {
+			t.Errorf("entry %s/%s split does not add up to %s", entry.TradeID, entry.Role, entry.Amount)
+		}
+	}
+
+	rollup := k.GetFeeRollup(ctx, day, "BTC-USDC")
+	if rollup.Entries != 4 || !rollup.TakerFees.Equal(btc.TakerFee.MulInt64(2)) || !rollup.MakerFees.Equal(btc.MakerFee.MulInt64(2)) {
+		t.Fatalf("unexpected rollup %+v", rollup)
+	}
+	wantRiverPool := rollup.Total().Mul(types.DefaultFeeSplit().RiverPool)
+	if !rollup.RiverPool.Equal(wantRiverPool) {
+		t.Errorf("expected riverpool share %s, got %s", wantRiverPool, rollup.RiverPool)
+	}
+
+	// Nothing settles until the day has ended
+	k.FeeLedgerEndBlocker(ctx)
+	if len(sink.credits) != 0 || k.GetFeeRollup(ctx, day, "BTC-USDC").Settled {
+		t.Fatal("expected the current day not to settle")
+	}
+
+	// The ETH share cannot be credited to the pools and goes to the treasury
+	sink.failMarket = "ETH-USDC"
+	ctx = ctx.WithBlockTime(types.FeeDayStart(day + 1))
+	k.FeeLedgerEndBlocker(ctx)
+
+	if !sink.credits["BTC-USDC"].Equal(rollup.RiverPool) {
+		t.Errorf("expected BTC riverpool credit %s, got %s", rollup.RiverPool, sink.credits["BTC-USDC"])
+	}
+	if _, ok := sink.credits["ETH-USDC"]; ok {
+		t.Error("expected no ETH riverpool credit")
+	}
+	eth := k.GetFeeRollup(ctx, day, "ETH-USDC")
+	if !k.GetFeeRollup(ctx, day, "BTC-USDC").Settled || !eth.Settled {
+		t.Fatal("expected both rollups to be settled")
+	}
+	balances := k.GetFeeBalances(ctx)
+	if balances.SettledDay != day {
+		t.Errorf("expected settled through day %d, got %d", day, balances.SettledDay)
+	}
+	if !balances.RiverPool.Equal(rollup.RiverPool) {
+		t.Errorf("expected riverpool balance %s, got %s", rollup.RiverPool, balances.RiverPool)
+	}
+	if want := rollup.Treasury.Add(eth.Treasury).Add(eth.RiverPool); !balances.Treasury.Equal(want) {
+		t.Errorf("expected treasury balance %s, got %s", want, balances.Treasury)
+	}
+	if want := rollup.InsuranceFund.Add(eth.InsuranceFund); !balances.InsuranceFund.Equal(want) {
+		t.Errorf("expected insurance fund balance %s, got %s", want, balances.InsuranceFund)
+	}
+
+	// Settling again is a no-op
+	k.FeeLedgerEndBlocker(ctx)
+	if !k.GetFeeBalances(ctx).Treasury.Equal(balances.Treasury) {
+		t.Error("expected a settled day not to be credited twice")
+	}
+}
+
+// TestFeeSplit tests fee split validation and that the treasury takes the
+// rounding remainder
+func TestFeeSplit(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	invalid := &types.FeeSplit{
+		InsuranceFund: math.LegacyNewDecWithPrec(5, 1),
+		RiverPool:     math.LegacyNewDecWithPrec(5, 1),
+		Treasury:      math.LegacyNewDecWithPrec(1, 1),
+	}
+	if err := k.SetFeeSplit(ctx, invalid); !errors.Is(err, types.ErrInvalidFeeSplit) {
+		t.Fatalf("expected ErrInvalidFeeSplit, got %v", err)
+	}
+
+	third := math.LegacyOneDec().QuoInt64(3)
+	split := &types.FeeSplit{InsuranceFund: third, RiverPool: third, Treasury: math.LegacyOneDec().Sub(third).Sub(third)}
+	if err := k.SetFeeSplit(ctx, split); err != nil {
+		t.Fatalf("failed to set fee split: %v", err)
+	}
+	fee := math.LegacyNewDec(1)
+	insuranceFund, riverPool, treasury := k.GetFeeSplit(ctx).Apply(fee)
+	if !insuranceFund.Add(riverPool).Add(treasury).Equal(fee) {
+		t.Errorf("expected split to add up to %s, got %s + %s + %s", fee, insuranceFund, riverPool, treasury)
+	}
+}
+
+// mockFeeTreasury sets the fee split and records the treasury fee credits
+type mockFeeTreasury struct {
+	split   *types.FeeSplit
+	credits map[string]math.LegacyDec
+}
+
+func (m *mockFeeTreasury) FeeSplit(ctx sdk.Context) (insuranceFund, riverPool, treasury math.LegacyDec) {
+	return m.split.InsuranceFund, m.split.RiverPool, m.split.Treasury
+}
+
+func (m *mockFeeTreasury) CreditFees(ctx sdk.Context, marketID string, day int64, amount math.LegacyDec) error {
+	m.credits[marketID] = amount
+	return nil
+}
+
+// TestFeeLedgerTreasury tests that an attached treasury sets the split and
+// receives the treasury share when a day settles
+func TestFeeLedgerTreasury(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	treasury := &mockFeeTreasury{
+		split: &types.FeeSplit{
+			InsuranceFund: math.LegacyZeroDec(),
+			RiverPool:     math.LegacyNewDecWithPrec(4, 1),
+			Treasury:      math.LegacyNewDecWithPrec(6, 1),
+		},
+		credits: make(map[string]math.LegacyDec),
+	}
+	k.SetFeeTreasury(treasury)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx = ctx.WithBlockTime(start)
+
+	if _, _, err := k.PlaceOrder(ctx, "maker", "BTC-USDC", types.SideSell, types.OrderTypeLimit,
+		math.LegacyNewDec(50000), math.LegacyOneDec()); err != nil {
+		t.Fatalf("failed to place maker order: %v", err)
+	}
+	if _, _, err := k.PlaceOrder(ctx, "taker", "BTC-USDC", types.SideBuy, types.OrderTypeLimit,
+		math.LegacyNewDec(50000), math.LegacyOneDec()); err != nil {
+		t.Fatalf("failed to place taker order: %v", err)
+	}
+
+	day := types.FeeDay(start)
+	rollup := k.GetFeeRollup(ctx, day, "BTC-USDC")
+	if want := rollup.Total().Mul(treasury.split.Treasury); !rollup.Treasury.Equal(want) || !rollup.InsuranceFund.IsZero() {
+		t.Fatalf("expected the treasury split to apply, got %+v", rollup)
+	}
+
+	ctx = ctx.WithBlockTime(types.FeeDayStart(day + 1))
+	k.FeeLedgerEndBlocker(ctx)
+	if !treasury.credits["BTC-USDC"].Equal(rollup.Treasury) {
+		t.Errorf("expected treasury credit %s, got %s", rollup.Treasury, treasury.credits["BTC-USDC"])
+	}
+}
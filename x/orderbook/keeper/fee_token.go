@@ -0,0 +1,170 @@
+package keeper
+
+import (
+	"context"
+	"encoding/json"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Store key prefixes for fees paid in the fee token
+var (
+	FeeTokenConfigKey      = []byte{0x74}
+	FeePreferenceKeyPrefix = []byte{0x75} // trader -> FeePreference
+)
+
+// FeeTokenBank holds traders' fee token balances; it is the bank module
+type FeeTokenBank interface {
+	GetBalance(ctx context.Context, addr sdk.AccAddress, denom string) sdk.Coin
+	SendCoinsFromAccountToModule(ctx context.Context, senderAddr sdk.AccAddress, recipientModule string, amt sdk.Coins) error
+}
+
+// SetFeeTokenBank attaches the bank fee token payments are made through, to
+// the collector module account. Without one every fee is charged in USDC.
+func (k *Keeper) SetFeeTokenBank(bank FeeTokenBank, collector string) {
+	k.feeTokenBank = bank
+	k.feeTokenCollector = collector
+}
+
+// FeeTokenRateSource prices the fee token: the USDC value of one base unit of
+// a denom, false if it has no price
+type FeeTokenRateSource interface {
+	FeeTokenRate(ctx sdk.Context, denom string) (math.LegacyDec, bool)
+}
+
+// SetFeeTokenRateSource attaches a conversion rate source, for example an
+// oracle. Its rate then replaces the one in the fee token config.
+func (k *Keeper) SetFeeTokenRateSource(source FeeTokenRateSource) {
+	k.feeTokenRates = source
+}
+
+// ============ Config ============
+
+// GetFeeTokenConfig returns the fee token config, the default if none is set
+func (k *Keeper) GetFeeTokenConfig(ctx sdk.Context) *types.FeeTokenConfig {
+	bz := k.GetStore(ctx).Get(FeeTokenConfigKey)
+	if bz == nil {
+		return types.DefaultFeeTokenConfig()
+	}
+	var config types.FeeTokenConfig
+	if err := json.Unmarshal(bz, &config); err != nil {
+		return types.DefaultFeeTokenConfig()
+	}
+	return &config
+}
+
+// SetFeeTokenConfig validates and saves the fee token config for fees charged
+// from now on
+func (k *Keeper) SetFeeTokenConfig(ctx sdk.Context, config *types.FeeTokenConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	bz, _ := json.Marshal(config)
+	k.GetStore(ctx).Set(FeeTokenConfigKey, bz)
+	return nil
+}
+
+// FeeTokenRate returns the USDC value of one base unit of the fee token: the
+// rate source's if one is attached, otherwise the configured rate. It is false
+// while the token has no positive rate.
+func (k *Keeper) FeeTokenRate(ctx sdk.Context, config *types.FeeTokenConfig) (math.LegacyDec, bool) {
+	if k.feeTokenRates != nil {
+		rate, ok := k.feeTokenRates.FeeTokenRate(ctx, config.Denom)
+		return rate, ok && !rate.IsNil() && rate.IsPositive()
+	}
+	return config.Rate, config.Rate.IsPositive()
+}
+
+// ============ Preferences ============
+
+func feePreferenceKey(trader string) []byte {
+	return append(append([]byte{}, FeePreferenceKeyPrefix...), trader...)
+}
+
+// SetFeePreference saves a trader's fee currency preference
+func (k *Keeper) SetFeePreference(ctx sdk.Context, pref *types.FeePreference) {
+	pref.UpdatedAt = ctx.BlockTime()
+	k.setFeePreference(ctx, pref)
+}
+
+func (k *Keeper) setFeePreference(ctx sdk.Context, pref *types.FeePreference) {
+	bz, _ := json.Marshal(pref)
+	k.GetStore(ctx).Set(feePreferenceKey(pref.Trader), bz)
+}
+
+// GetFeePreference returns a trader's fee currency preference, paying in USDC
+// if none is set
+func (k *Keeper) GetFeePreference(ctx sdk.Context, trader string) *types.FeePreference {
+	bz := k.GetStore(ctx).Get(feePreferenceKey(trader))
+	if bz == nil {
+		return &types.FeePreference{Trader: trader}
+	}
+	var pref types.FeePreference
+	if err := json.Unmarshal(bz, &pref); err != nil {
+		return &types.FeePreference{Trader: trader}
+	}
+	return &pref
+}
+
+// GetAllFeePreferences returns every trader's fee currency preference
+func (k *Keeper) GetAllFeePreferences(ctx sdk.Context) []*types.FeePreference {
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), FeePreferenceKeyPrefix)
+	defer iterator.Close()
+
+	prefs := make([]*types.FeePreference, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var pref types.FeePreference
+		if err := json.Unmarshal(iterator.Value(), &pref); err != nil {
+			continue
+		}
+		prefs = append(prefs, &pref)
+	}
+	return prefs
+}
+
+// ============ Charging ============
+
+// chargeFeeInKind charges a trader's USDC fee in the fee token if the trader
+// opted in, the token is enabled and priced, and the trader's balance covers
+// the discounted amount. It returns the token amount paid, false if the fee
+// is to be charged in USDC.
+func (k *Keeper) chargeFeeInKind(ctx sdk.Context, config *types.FeeTokenConfig, trader string, fee math.LegacyDec) (math.Int, bool) {
+	if k.feeTokenBank == nil || !config.Enabled || !k.GetFeePreference(ctx, trader).PayInFeeToken {
+		return math.Int{}, false
+	}
+	rate, ok := k.FeeTokenRate(ctx, config)
+	if !ok {
+		return math.Int{}, false
+	}
+	addr, err := sdk.AccAddressFromBech32(trader)
+	if err != nil {
+		return math.Int{}, false
+	}
+	amount := config.FeeTokenAmount(fee, rate)
+	if !amount.IsPositive() || k.feeTokenBank.GetBalance(ctx, addr, config.Denom).Amount.LT(amount) {
+		return math.Int{}, false
+	}
+
+	cacheCtx, write := ctx.CacheContext()
+	coins := sdk.NewCoins(sdk.NewCoin(config.Denom, amount))
+	if err := k.feeTokenBank.SendCoinsFromAccountToModule(cacheCtx, addr, k.feeTokenCollector, coins); err != nil {
+		k.Logger().Error("failed to charge fee in fee token, charging USDC",
+			"trader", trader, "amount", coins, "error", err)
+		return math.Int{}, false
+	}
+	write()
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"fee_paid_in_kind",
+			sdk.NewAttribute("trader", trader),
+			sdk.NewAttribute("fee", fee.String()),
+			sdk.NewAttribute("amount", coins.String()),
+			sdk.NewAttribute("rate", rate.String()),
+		),
+	)
+	return amount, true
+}
@@ -0,0 +1,129 @@
+package keeper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// mockFeeTokenBank holds fee token balances by address and module
+type mockFeeTokenBank struct {
+	balances map[string]sdk.Coins
+}
+
+func (m *mockFeeTokenBank) GetBalance(ctx context.Context, addr sdk.AccAddress, denom string) sdk.Coin {
+	return sdk.NewCoin(denom, m.balances[addr.String()].AmountOf(denom))
+}
+
+func (m *mockFeeTokenBank) SendCoinsFromAccountToModule(ctx context.Context, senderAddr sdk.AccAddress, recipientModule string, amt sdk.Coins) error {
+	balance, negative := m.balances[senderAddr.String()].SafeSub(amt...)
+	if negative {
+		return fmt.Errorf("insufficient funds")
+	}
+	m.balances[senderAddr.String()] = balance
+	m.balances[recipientModule] = m.balances[recipientModule].Add(amt...)
+	return nil
+}
+
+type fixedFeeTokenRate math.LegacyDec
+
+func (r fixedFeeTokenRate) FeeTokenRate(ctx sdk.Context, denom string) (math.LegacyDec, bool) {
+	return math.LegacyDec(r), true
+}
+
+// TestFeeInKind tests that an opted-in trader's fee is charged in the fee
+// token at a discount and zeroed on the trade, that a trader who cannot cover
+// it or has not opted in pays USDC, and that a rate source replaces the
+// configured rate
+func TestFeeInKind(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	ctx = ctx.WithBlockTime(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	taker := sdk.AccAddress([]byte("fee-token-taker_____")).String()
+	maker := sdk.AccAddress([]byte("fee-token-maker_____")).String()
+	bank := &mockFeeTokenBank{balances: map[string]sdk.Coins{
+		taker: sdk.NewCoins(sdk.NewInt64Coin("uperp", 10000)),
+		maker: sdk.NewCoins(sdk.NewInt64Coin("uperp", 100)),
+	}}
+	k.SetFeeTokenBank(bank, "fee_collector")
+
+	if err := k.SetFeeTokenConfig(ctx, &types.FeeTokenConfig{Enabled: true, Denom: "uperp", Discount: math.LegacyOneDec(), Rate: math.LegacyOneDec()}); err == nil {
+		t.Fatal("expected a 100% discount to be rejected")
+	}
+	if err := k.SetFeeTokenConfig(ctx, &types.FeeTokenConfig{
+		Enabled:  true,
+		Denom:    "uperp",
+		Discount: math.LegacyNewDecWithPrec(25, 2),
+		Rate:     math.LegacyNewDecWithPrec(1, 3), // 0.001 USDC per uperp
+	}); err != nil {
+		t.Fatalf("failed to set fee token config: %v", err)
+	}
+	k.SetFeePreference(ctx, &types.FeePreference{Trader: taker, PayInFeeToken: true})
+	k.SetFeePreference(ctx, &types.FeePreference{Trader: maker, PayInFeeToken: true})
+
+	trade := func() *types.Trade {
+		t.Helper()
+		if _, _, err := k.PlaceOrder(ctx, maker, "BTC-USDC", types.SideSell, types.OrderTypeLimit,
+			math.LegacyNewDec(50000), math.LegacyOneDec()); err != nil {
+			t.Fatalf("failed to place maker order: %v", err)
+		}
+		_, result, err := k.PlaceOrder(ctx, taker, "BTC-USDC", types.SideBuy, types.OrderTypeLimit,
+			math.LegacyNewDec(50000), math.LegacyOneDec())
+		if err != nil || len(result.Trades) != 1 {
+			t.Fatalf("expected one trade, got %v (err %v)", result, err)
+		}
+		return result.Trades[0]
+	}
+
+	// The 5 USDC taker fee is paid with 5 × 0.75 / 0.001 = 3750 uperp; the
+	// maker's 100 uperp cannot cover its 1875, so it pays 2.5 USDC
+	first := trade()
+	if !first.TakerFee.IsZero() || !first.MakerFee.Equal(math.LegacyMustNewDecFromStr("2.5")) {
+		t.Fatalf("unexpected fees taker=%s maker=%s", first.TakerFee, first.MakerFee)
+	}
+	if got := bank.balances[taker].AmountOf("uperp"); !got.Equal(math.NewInt(6250)) {
+		t.Errorf("expected the taker to keep 6250 uperp, got %s", got)
+	}
+	if got := bank.balances["fee_collector"].AmountOf("uperp"); !got.Equal(math.NewInt(3750)) {
+		t.Errorf("expected 3750 uperp collected, got %s", got)
+	}
+
+	day := types.FeeDay(ctx.BlockTime())
+	for _, entry := range k.GetFeeEntries(ctx, "BTC-USDC", day) {
+		switch entry.Role {
+		case types.FeeRoleTaker:
+			if !entry.PaidInFeeToken() || !entry.FeeTokenAmount.Equal(math.NewInt(3750)) ||
+				!entry.Amount.IsZero() || !entry.ReplacedFee.Equal(math.LegacyNewDec(5)) || !entry.RiverPool.IsZero() {
+				t.Errorf("unexpected taker entry %+v", entry)
+			}
+		case types.FeeRoleMaker:
+			if entry.PaidInFeeToken() || !entry.Amount.Equal(first.MakerFee) {
+				t.Errorf("unexpected maker entry %+v", entry)
+			}
+		}
+	}
+	rollup := k.GetFeeRollup(ctx, day, "BTC-USDC")
+	if !rollup.TakerFees.IsZero() || !rollup.FeeTokenFees.Equal(math.NewInt(3750)) {
+		t.Errorf("unexpected rollup %+v", rollup)
+	}
+
+	// A rate source replaces the configured rate
+	k.SetFeeTokenRateSource(fixedFeeTokenRate(math.LegacyNewDecWithPrec(2, 3)))
+	trade()
+	if got := bank.balances[taker].AmountOf("uperp"); !got.Equal(math.NewInt(4375)) {
+		t.Errorf("expected 1875 uperp charged at the source's rate, leaving 4375, got %s", got)
+	}
+
+	// Opting out charges USDC again
+	k.SetFeePreference(ctx, &types.FeePreference{Trader: taker})
+	if third := trade(); !third.TakerFee.Equal(math.LegacyNewDec(5)) {
+		t.Errorf("expected a 5 USDC taker fee after opting out, got %s", third.TakerFee)
+	}
+	if got := bank.balances[taker].AmountOf("uperp"); !got.Equal(math.NewInt(4375)) {
+		t.Errorf("expected no uperp charged after opting out, got %s", got)
+	}
+}
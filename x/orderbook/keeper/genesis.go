@@ -0,0 +1,193 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// InitGenesis imports orderbook state. Each order book is rebuilt by adding its
+// orders in the exported sequence, which preserves price-time priority.
+func (k *Keeper) InitGenesis(ctx sdk.Context, gs *types.GenesisState) error {
+	if err := gs.Validate(); err != nil {
+		return err
+	}
+
+	books := make(map[string]*types.OrderBook)
+	marketIDs := make([]string, 0)
+	for _, order := range gs.Orders {
+		k.SetOrder(ctx, order)
+		k.indexExpiry(ctx, order)
+
+		ob, ok := books[order.MarketID]
+		if !ok {
+			ob = types.NewOrderBook(order.MarketID)
+			books[order.MarketID] = ob
+			marketIDs = append(marketIDs, order.MarketID)
+		}
+		ob.AddOrder(order)
+	}
+	for _, marketID := range marketIDs {
+		k.SetOrderBook(ctx, books[marketID])
+	}
+
+	k.setCounter(ctx, OrderCounterKey, gs.OrderCounter)
+	k.setCounter(ctx, TradeCounterKey, gs.TradeCounter)
+	k.setCounter(ctx, EventSeqKey, gs.EventSeq)
+
+	for _, config := range gs.MMPConfigs {
+		k.setMMPConfig(ctx, config)
+	}
+	for _, obligation := range gs.LPObligations {
+		k.setLPObligation(ctx, obligation)
+	}
+	if gs.FeeSplit != nil {
+		if err := k.SetFeeSplit(ctx, gs.FeeSplit); err != nil {
+			return err
+		}
+	}
+	for _, rollup := range gs.FeeRollups {
+		k.setFeeRollup(ctx, rollup)
+	}
+	if gs.FeeBalances != nil {
+		k.setFeeBalances(ctx, gs.FeeBalances)
+	}
+	if gs.FeeTokenConfig != nil {
+		if err := k.SetFeeTokenConfig(ctx, gs.FeeTokenConfig); err != nil {
+			return err
+		}
+	}
+	for _, pref := range gs.FeePreferences {
+		k.setFeePreference(ctx, pref)
+	}
+	if gs.OrderLimits != nil {
+		k.setOrderLimits(ctx, gs.OrderLimits)
+	}
+	if gs.MarginRecheck != nil {
+		k.setMarginRecheckParams(ctx, gs.MarginRecheck)
+	}
+	if gs.MakerRebate != nil {
+		k.setMakerRebateParams(ctx, gs.MakerRebate)
+	}
+	for _, points := range gs.MakerPoints {
+		k.setMakerPoints(ctx, points)
+	}
+
+	for _, spread := range gs.Spreads {
+		k.setSpread(ctx, spread)
+	}
+	spreadBooks := make(map[string]*types.OrderBook)
+	for _, order := range gs.SpreadOrders {
+		k.SetSpreadOrder(ctx, order)
+		ob, ok := spreadBooks[order.MarketID]
+		if !ok {
+			ob = types.NewOrderBook(order.MarketID)
+			spreadBooks[order.MarketID] = ob
+		}
+		ob.AddOrder(order)
+	}
+	for _, spread := range gs.Spreads {
+		if ob, ok := spreadBooks[spread.SpreadID]; ok {
+			k.setSpreadBook(ctx, ob)
+		}
+	}
+
+	if gs.SettlementOperator != "" {
+		k.SetSettlementOperator(ctx, gs.SettlementOperator)
+	}
+	if gs.LastSettlementBatchID > 0 {
+		k.setLastSettlementBatchID(ctx, gs.LastSettlementBatchID)
+	}
+	return nil
+}
+
+// ExportGenesis exports the resting orders of every book, the ID counters, the
+// event sequence, MMP configs, LP obligations, the fee split, daily fee
+// rollups and settled fee balances, the fee token config and traders' fee
+// preferences, the resting order limits, the maker rebate params and maker
+// points, spreads with their resting orders, and the settlement operator and
+// last settlement batch ID. Resting order counts are rebuilt as the orders
+// are imported. Trade history, the event log, per-trade fee entries, LP
+// uptime reports, spread fills, settlement batches and transient state (MMP
+// windows, analytics) are not exported.
+func (k *Keeper) ExportGenesis(ctx sdk.Context) *types.GenesisState {
+	gs := types.DefaultGenesis()
+
+	for _, ob := range k.GetAllOrderBooks(ctx) {
+		for _, levels := range [][]*types.PriceLevel{ob.Bids, ob.Asks} {
+			for _, level := range levels {
+				for _, orderID := range level.OrderIDs {
+					order := k.GetOrder(ctx, orderID)
+					if order == nil || !order.IsActive() {
+						continue
+					}
+					gs.Orders = append(gs.Orders, order)
+				}
+			}
+		}
+	}
+
+	gs.OrderCounter = k.getCounter(ctx, OrderCounterKey)
+	gs.TradeCounter = k.getCounter(ctx, TradeCounterKey)
+	gs.EventSeq = k.getCounter(ctx, EventSeqKey)
+	gs.MMPConfigs = k.GetAllMMPConfigs(ctx)
+	gs.LPObligations = k.GetAllLPObligations(ctx)
+	if bz := k.GetStore(ctx).Get(FeeSplitKey); bz != nil {
+		var split types.FeeSplit
+		if err := json.Unmarshal(bz, &split); err == nil {
+			gs.FeeSplit = &split
+		}
+	}
+	gs.FeeRollups = k.GetAllFeeRollups(ctx)
+	if bz := k.GetStore(ctx).Get(FeeBalancesKey); bz != nil {
+		gs.FeeBalances = k.GetFeeBalances(ctx)
+	}
+	if bz := k.GetStore(ctx).Get(FeeTokenConfigKey); bz != nil {
+		gs.FeeTokenConfig = k.GetFeeTokenConfig(ctx)
+	}
+	gs.FeePreferences = k.GetAllFeePreferences(ctx)
+	if bz := k.GetStore(ctx).Get(OrderLimitsKey); bz != nil {
+		gs.OrderLimits = k.GetOrderLimits(ctx)
+	}
+	if bz := k.GetStore(ctx).Get(MarginRecheckParamsKey); bz != nil {
+		gs.MarginRecheck = k.GetMarginRecheckParams(ctx)
+	}
+	if bz := k.GetStore(ctx).Get(MakerRebateParamsKey); bz != nil {
+		gs.MakerRebate = k.GetMakerRebateParams(ctx)
+	}
+	gs.MakerPoints = k.GetAllMakerPoints(ctx)
+
+	gs.Spreads = k.GetAllSpreads(ctx)
+	for _, spread := range gs.Spreads {
+		ob := k.GetSpreadBook(ctx, spread.SpreadID)
+		for _, levels := range [][]*types.PriceLevel{ob.Bids, ob.Asks} {
+			for _, level := range levels {
+				for _, orderID := range level.OrderIDs {
+					if order := k.GetSpreadOrder(ctx, orderID); order != nil && order.IsActive() {
+						gs.SpreadOrders = append(gs.SpreadOrders, order)
+					}
+				}
+			}
+		}
+	}
+
+	gs.SettlementOperator = k.GetSettlementOperator(ctx)
+	gs.LastSettlementBatchID = k.GetLastSettlementBatchID(ctx)
+	return gs
+}
+
+func (k *Keeper) getCounter(ctx sdk.Context, key []byte) uint64 {
+	bz := k.GetStore(ctx).Get(key)
+	if bz == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(bz)
+}
+
+func (k *Keeper) setCounter(ctx sdk.Context, key []byte, counter uint64) {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, counter)
+	k.GetStore(ctx).Set(key, bz)
+}
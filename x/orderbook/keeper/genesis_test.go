@@ -0,0 +1,68 @@
+package keeper
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestGenesisRoundTrip tests that exported books import with the same
+// price-time priority and that IDs continue from the exported counters
+func TestGenesisRoundTrip(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+
+	for _, o := range []struct {
+		trader string
+		side   types.Side
+		price  int64
+	}{
+		{"trader1", types.SideBuy, 49900},
+		{"trader2", types.SideBuy, 50000},
+		{"trader3", types.SideBuy, 49900},
+		{"trader1", types.SideSell, 50100},
+		{"trader2", types.SideSell, 50200},
+	} {
+		if _, _, err := k.PlaceOrder(ctx, o.trader, "BTC-USDC", o.side, types.OrderTypeLimit,
+			math.LegacyNewDec(o.price), math.LegacyOneDec()); err != nil {
+			t.Fatalf("failed to place order: %v", err)
+		}
+	}
+	mmp := types.NewMMPConfig("trader1", "BTC-USDC")
+	mmp.MaxFills = 5
+	if err := k.SetMMPConfig(ctx, mmp); err != nil {
+		t.Fatalf("failed to set mmp config: %v", err)
+	}
+
+	exported := k.ExportGenesis(ctx)
+	if len(exported.Orders) != 5 || exported.OrderCounter != 5 {
+		t.Fatalf("expected 5 orders and counter 5, got %d and %d", len(exported.Orders), exported.OrderCounter)
+	}
+
+	k2, ctx2 := setupBenchKeeper(t)
+	if err := k2.InitGenesis(ctx2, exported); err != nil {
+		t.Fatalf("failed to import genesis: %v", err)
+	}
+
+	want, _ := json.Marshal(k.GetOrderBook(ctx, "BTC-USDC"))
+	got, _ := json.Marshal(k2.GetOrderBook(ctx2, "BTC-USDC"))
+	if string(want) != string(got) {
+		t.Errorf("order book mismatch after import:\nwant %s\ngot  %s", want, got)
+	}
+	if k2.GetMMPConfig(ctx2, "trader1", "BTC-USDC") == nil {
+		t.Error("expected mmp config to be imported")
+	}
+
+	order, _, err := k2.PlaceOrder(ctx2, "trader3", "BTC-USDC", types.SideBuy, types.OrderTypeLimit,
+		math.LegacyNewDec(49000), math.LegacyOneDec())
+	if err != nil {
+		t.Fatalf("failed to place order after import: %v", err)
+	}
+	if order.OrderID != "order-6" {
+		t.Errorf("expected order-6 after import, got %s", order.OrderID)
+	}
+	if order.Seq != exported.EventSeq+1 {
+		t.Errorf("expected event sequence to continue at %d, got %d", exported.EventSeq+1, order.Seq)
+	}
+}
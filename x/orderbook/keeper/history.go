@@ -0,0 +1,192 @@
+package keeper
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"time"
+
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// OrderByTraderPrefix indexes orders by trader and creation time:
+// trader/createdAt/orderID -> empty. Trades are indexed the same way under
+// TradeByTraderPrefix (for taker and maker) and TradeByMarketPrefix.
+var OrderByTraderPrefix = []byte{0x0A}
+
+// History page sizes
+const (
+	DefaultHistoryLimit = 100
+	MaxHistoryLimit     = 500
+)
+
+// HistoryQuery filters and pages an order or trade history scan
+type HistoryQuery struct {
+	MarketID string
+	Side     types.Side        // SideUnspecified matches both sides
+	Status   types.OrderStatus // orders only; OrderStatusUnspecified matches all
+	From, To time.Time         // inclusive bounds, zero for none
+	Oldest   bool              // oldest first; newest first by default
+	Limit    int
+	Cursor   string // NextCursor of the previous page
+}
+
+// limit returns the page size, defaulted and capped
+func (q HistoryQuery) limit() int {
+	if q.Limit <= 0 {
+		return DefaultHistoryLimit
+	}
+	if q.Limit > MaxHistoryLimit {
+		return MaxHistoryLimit
+	}
+	return q.Limit
+}
+
+// historyPrefix is the index prefix of one trader's or market's entries
+func historyPrefix(prefix []byte, owner string) []byte {
+	return append(append(append([]byte{}, prefix...), owner...), '/')
+}
+
+// historyKey is owner/time/id, so an owner's entries sort by time, then ID
+func historyKey(prefix []byte, owner string, t time.Time, id string) []byte {
+	key := binary.BigEndian.AppendUint64(historyPrefix(prefix, owner), uint64(t.UnixNano()))
+	return append(key, id...)
+}
+
+// orderHistoryKey is the trader index key of an order
+func orderHistoryKey(order *types.Order) []byte {
+	return historyKey(OrderByTraderPrefix, order.Trader, order.CreatedAt, order.OrderID)
+}
+
+// indexTrade adds a trade to the trader index of both sides and to the
+// market index
+func (k *Keeper) indexTrade(ctx sdk.Context, trade *types.Trade) {
+	store := k.GetStore(ctx)
+	store.Set(historyKey(TradeByTraderPrefix, trade.Taker, trade.Timestamp, trade.TradeID), []byte{})
+	if trade.Maker != trade.Taker {
+		store.Set(historyKey(TradeByTraderPrefix, trade.Maker, trade.Timestamp, trade.TradeID), []byte{})
+	}
+	store.Set(historyKey(TradeByMarketPrefix, trade.MarketID, trade.Timestamp, trade.TradeID), []byte{})
+}
+
+// QueryOrders returns a page of a trader's orders matching q, by creation
+// time, and the cursor of the next page, empty on the last page. It scans the
+// trader's index over q's time range, so cost grows with the trader's orders
+// in the range, not with the whole order store.
+func (k *Keeper) QueryOrders(ctx sdk.Context, trader string, q HistoryQuery) ([]*types.Order, string, error) {
+	return scanHistory(k, ctx, historyPrefix(OrderByTraderPrefix, trader), q, func(id string, createdAt int64) (*types.Order, bool) {
+		order := k.GetOrder(ctx, id)
+		// An order ID reused by a cancel-replace leaves no stale entry, but
+		// skip one rather than report the order under the wrong time
+		if order == nil || order.CreatedAt.UnixNano() != createdAt {
+			return nil, false
+		}
+		if q.MarketID != "" && order.MarketID != q.MarketID {
+			return nil, false
+		}
+		if q.Side != types.SideUnspecified && order.Side != q.Side {
+			return nil, false
+		}
+		if q.Status != types.OrderStatusUnspecified && order.Status != q.Status {
+			return nil, false
+		}
+		return order, true
+	})
+}
+
+// QueryTrades returns a page of trades matching q, by time, and the cursor of
+// the next page. With a trader it scans the trader's trades as taker or maker,
+// and Side is the trader's side; otherwise it scans q.MarketID's trades, and
+// Side is the taker's side.
+func (k *Keeper) QueryTrades(ctx sdk.Context, trader string, q HistoryQuery) ([]*types.Trade, string, error) {
+	prefix := historyPrefix(TradeByTraderPrefix, trader)
+	if trader == "" {
+		if q.MarketID == "" {
+			return nil, "", types.ErrInvalidMarketID.Wrap("market or trader is required")
+		}
+		prefix = historyPrefix(TradeByMarketPrefix, q.MarketID)
+	}
+
+	return scanHistory(k, ctx, prefix, q, func(id string, _ int64) (*types.Trade, bool) {
+		trade := k.GetTrade(ctx, id)
+		if trade == nil {
+			return nil, false
+		}
+		if q.MarketID != "" && trade.MarketID != q.MarketID {
+			return nil, false
+		}
+		if q.Side != types.SideUnspecified {
+			side := trade.TakerSide
+			if trader != "" && trade.Taker != trader {
+				side = side.Opposite()
+			}
+			if side != q.Side {
+				return nil, false
+			}
+		}
+		return trade, true
+	})
+}
+
+// scanHistory walks a history index from the cursor through q's time range,
+// loading entries until it has a page, then looks for one more match to tell
+// whether a next page exists
+func scanHistory[T any](k *Keeper, ctx sdk.Context, prefix []byte, q HistoryQuery, load func(id string, at int64) (T, bool)) ([]T, string, error) {
+	start, end := prefix, storetypes.PrefixEndBytes(prefix)
+	if !q.From.IsZero() {
+		start = binary.BigEndian.AppendUint64(bytes.Clone(prefix), uint64(q.From.UnixNano()))
+	}
+	if !q.To.IsZero() {
+		end = binary.BigEndian.AppendUint64(bytes.Clone(prefix), uint64(q.To.UnixNano())+1)
+	}
+	if q.Cursor != "" {
+		pos, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+		if err != nil || len(pos) < 8 {
+			return nil, "", types.ErrInvalidCursor
+		}
+		key := append(bytes.Clone(prefix), pos...)
+		if q.Oldest {
+			// The smallest key after the cursor's
+			if key = append(key, 0x00); bytes.Compare(key, start) > 0 {
+				start = key
+			}
+		} else if bytes.Compare(key, end) < 0 {
+			end = key
+		}
+	}
+
+	page := make([]T, 0)
+	if bytes.Compare(start, end) >= 0 {
+		return page, "", nil
+	}
+
+	store := k.GetStore(ctx)
+	var iterator storetypes.Iterator
+	if q.Oldest {
+		iterator = store.Iterator(start, end)
+	} else {
+		iterator = store.ReverseIterator(start, end)
+	}
+	defer iterator.Close()
+
+	limit := q.limit()
+	var last []byte
+	for ; iterator.Valid(); iterator.Next() {
+		pos := iterator.Key()[len(prefix):]
+		if len(pos) < 8 {
+			continue
+		}
+		item, ok := load(string(pos[8:]), int64(binary.BigEndian.Uint64(pos)))
+		if !ok {
+			continue
+		}
+		if len(page) == limit {
+			return page, base64.RawURLEncoding.EncodeToString(last), nil
+		}
+		page = append(page, item)
+		last = bytes.Clone(pos)
+	}
+	return page, "", nil
+}
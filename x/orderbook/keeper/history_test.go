@@ -0,0 +1,139 @@
+package keeper
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestQueryOrdersPaging tests that a trader's orders page by creation time in
+// either direction with filters applied during the index scan
+func TestQueryOrdersPaging(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	base := time.Unix(1_700_000_000, 0)
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		side := types.SideBuy
+		if i%2 == 1 {
+			side = types.SideSell
+		}
+		order := types.NewOrder(fmt.Sprintf("order-%d", i+8), "alice", "BTC-USDC", side, types.OrderTypeLimit,
+			math.LegacyNewDec(50000), math.LegacyOneDec())
+		order.CreatedAt = base.Add(time.Duration(i) * time.Second)
+		k.SetOrder(ctx, order)
+		ids = append(ids, order.OrderID)
+	}
+	other := types.NewOrder("order-99", "bob", "BTC-USDC", types.SideBuy, types.OrderTypeLimit,
+		math.LegacyNewDec(50000), math.LegacyOneDec())
+	other.CreatedAt = base.Add(2 * time.Second)
+	k.SetOrder(ctx, other)
+
+	collect := func(q HistoryQuery) []string {
+		t.Helper()
+		var got []string
+		for pages := 0; ; pages++ {
+			orders, next, err := k.QueryOrders(ctx, "alice", q)
+			if err != nil {
+				t.Fatalf("failed to query orders: %v", err)
+			}
+			if pages > 10 {
+				t.Fatal("expected paging to end")
+			}
+			for _, o := range orders {
+				got = append(got, o.OrderID)
+			}
+			if next == "" {
+				return got
+			}
+			q.Cursor = next
+		}
+	}
+	expect := func(name string, got []string, want ...string) {
+		t.Helper()
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s: expected %v, got %v", name, want, got)
+		}
+	}
+
+	// order-10 sorts before order-8 by ID but was created later
+	expect("newest first", collect(HistoryQuery{Limit: 2}), ids[4], ids[3], ids[2], ids[1], ids[0])
+	expect("oldest first", collect(HistoryQuery{Limit: 2, Oldest: true}), ids...)
+	expect("side", collect(HistoryQuery{Limit: 1, Side: types.SideSell}), ids[3], ids[1])
+	expect("time range", collect(HistoryQuery{Limit: 1, From: base.Add(time.Second), To: base.Add(3 * time.Second)}), ids[3], ids[2], ids[1])
+
+	k.DeleteOrder(ctx, ids[2])
+	expect("deleted", collect(HistoryQuery{Oldest: true}), ids[0], ids[1], ids[3], ids[4])
+
+	cancelled := k.GetOrder(ctx, ids[4])
+	cancelled.Status = types.OrderStatusCancelled
+	k.SetOrder(ctx, cancelled)
+	expect("status", collect(HistoryQuery{Status: types.OrderStatusCancelled}), ids[4])
+
+	// A full last page has no next cursor
+	if _, next, _ := k.QueryOrders(ctx, "alice", HistoryQuery{Limit: 4}); next != "" {
+		t.Errorf("expected no cursor after the last page, got %q", next)
+	}
+	if _, _, err := k.QueryOrders(ctx, "alice", HistoryQuery{Cursor: "!"}); !errors.Is(err, types.ErrInvalidCursor) {
+		t.Errorf("expected invalid cursor error, got %v", err)
+	}
+}
+
+// TestQueryTrades tests trade history by trader, with the trader's own side,
+// and by market
+func TestQueryTrades(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	base := time.Unix(1_700_000_000, 0)
+
+	for i, tc := range []struct {
+		market, taker, maker string
+		takerSide            types.Side
+	}{
+		{"BTC-USDC", "alice", "bob", types.SideBuy},
+		{"ETH-USDC", "bob", "alice", types.SideBuy},
+		{"BTC-USDC", "carol", "bob", types.SideSell},
+	} {
+		k.SetTrade(ctx, &types.Trade{
+			TradeID: fmt.Sprintf("trade-%d", i+1), MarketID: tc.market, Taker: tc.taker, Maker: tc.maker,
+			TakerSide: tc.takerSide, Price: math.LegacyNewDec(100), Quantity: math.LegacyOneDec(),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	ids := func(trader string, q HistoryQuery) string {
+		t.Helper()
+		trades, _, err := k.QueryTrades(ctx, trader, q)
+		if err != nil {
+			t.Fatalf("failed to query trades: %v", err)
+		}
+		var got []string
+		for _, trade := range trades {
+			got = append(got, trade.TradeID)
+		}
+		return fmt.Sprint(got)
+	}
+
+	for _, tc := range []struct {
+		name, trader string
+		q            HistoryQuery
+		want         string
+	}{
+		{"trader", "alice", HistoryQuery{}, "[trade-2 trade-1]"},
+		{"trader market", "bob", HistoryQuery{MarketID: "BTC-USDC", Oldest: true}, "[trade-1 trade-3]"},
+		{"trader side as maker", "alice", HistoryQuery{Side: types.SideSell}, "[trade-2]"},
+		{"market", "", HistoryQuery{MarketID: "BTC-USDC"}, "[trade-3 trade-1]"},
+		{"market taker side", "", HistoryQuery{MarketID: "BTC-USDC", Side: types.SideSell}, "[trade-3]"},
+	} {
+		if got := ids(tc.trader, tc.q); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+
+	if _, _, err := k.QueryTrades(ctx, "", HistoryQuery{}); err == nil {
+		t.Error("expected a query without trader or market to be rejected")
+	}
+}
@@ -0,0 +1,36 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// SetHooks registers the modules that react to fills. It may only be called
+// once, while the app is wired; pass every hook in one call.
+func (k *Keeper) SetHooks(hooks ...types.TradeHooks) {
+	if k.hooks != nil {
+		panic("cannot set orderbook trade hooks twice")
+	}
+	k.hooks = types.NewMultiTradeHooks(hooks...)
+}
+
+// afterTradeExecuted calls the AfterTradeExecuted hooks for a new trade
+func (k *Keeper) afterTradeExecuted(ctx sdk.Context, trade *types.Trade) {
+	if k.hooks == nil {
+		return
+	}
+	if err := k.hooks.AfterTradeExecuted(ctx, trade); err != nil {
+		k.Logger().Error("trade hook failed", "trade_id", trade.TradeID, "error", err)
+	}
+}
+
+// afterPositionChanged calls the AfterPositionChanged hooks for a trader's
+// position in a market
+func (k *Keeper) afterPositionChanged(ctx sdk.Context, trader, marketID string) {
+	if k.hooks == nil {
+		return
+	}
+	if err := k.hooks.AfterPositionChanged(ctx, trader, marketID); err != nil {
+		k.Logger().Error("position hook failed", "trader", trader, "market_id", marketID, "error", err)
+	}
+}
@@ -0,0 +1,91 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// recordingHooks records the hook calls and writes a marker under key to the
+// store, then fails with err or panics if set
+type recordingHooks struct {
+	k         *Keeper
+	key       string
+	err       error
+	panics    bool
+	trades    []string
+	positions []string
+}
+
+func (h *recordingHooks) call(ctx sdk.Context) error {
+	h.k.GetStore(ctx).Set([]byte(h.key), []byte{1})
+	if h.panics {
+		panic("hook bug")
+	}
+	return h.err
+}
+
+func (h *recordingHooks) AfterTradeExecuted(ctx sdk.Context, trade *types.Trade) error {
+	h.trades = append(h.trades, trade.TradeID)
+	return h.call(ctx)
+}
+
+func (h *recordingHooks) AfterPositionChanged(ctx sdk.Context, trader, marketID string) error {
+	h.positions = append(h.positions, trader+"/"+marketID)
+	return nil
+}
+
+func (h *recordingHooks) AfterLiquidation(ctx sdk.Context, trader, marketID string, size, price math.LegacyDec) error {
+	return nil
+}
+
+// TestTradeHooks tests that fills reach every registered hook, and that a
+// failing or panicking hook neither affects the others nor the trade
+func TestTradeHooks(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	ok := &recordingHooks{k: k, key: "hook/ok"}
+	failing := &recordingHooks{k: k, key: "hook/failing", err: errors.New("points ledger unavailable")}
+	panicking := &recordingHooks{k: k, key: "hook/panicking", panics: true}
+	k.SetHooks(failing, nil, panicking, ok)
+
+	if _, _, err := k.PlaceOrder(ctx, "maker", "BTC-USDC", types.SideSell, types.OrderTypeLimit,
+		math.LegacyNewDec(50000), math.LegacyOneDec()); err != nil {
+		t.Fatalf("failed to place maker order: %v", err)
+	}
+	_, result, err := k.PlaceOrder(ctx, "taker", "BTC-USDC", types.SideBuy, types.OrderTypeLimit,
+		math.LegacyNewDec(50000), math.LegacyOneDec())
+	if err != nil || len(result.Trades) != 1 {
+		t.Fatalf("expected one trade, got %v (err %v)", result, err)
+	}
+
+	tradeID := result.Trades[0].TradeID
+	for _, h := range []*recordingHooks{ok, failing, panicking} {
+		if len(h.trades) != 1 || h.trades[0] != tradeID {
+			t.Errorf("%s: expected trade %s, got %v", h.key, tradeID, h.trades)
+		}
+	}
+	if len(ok.positions) != 2 || ok.positions[0] != "taker/BTC-USDC" || ok.positions[1] != "maker/BTC-USDC" {
+		t.Errorf("expected taker and maker position changes, got %v", ok.positions)
+	}
+
+	store := k.GetStore(ctx)
+	if !store.Has([]byte("hook/ok")) {
+		t.Error("expected the successful hook's write to be committed")
+	}
+	if store.Has([]byte("hook/failing")) || store.Has([]byte("hook/panicking")) {
+		t.Error("expected the failed hooks' writes to be discarded")
+	}
+	if k.GetTrade(ctx, tradeID) == nil {
+		t.Error("expected the trade to be kept")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected setting hooks twice to panic")
+		}
+	}()
+	k.SetHooks(ok)
+}
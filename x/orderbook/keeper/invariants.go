@@ -0,0 +1,64 @@
+package keeper
+
+import (
+	"fmt"
+	"strings"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// invariantModule is the module name invariants are registered under
+const invariantModule = "orderbook"
+
+// RegisterInvariants registers the orderbook module invariants
+func RegisterInvariants(ir sdk.InvariantRegistry, k *Keeper) {
+	ir.RegisterRoute(invariantModule, "book-quantity", BookQuantityInvariant(k))
+}
+
+// AllInvariants runs all invariants of the orderbook module
+func AllInvariants(k *Keeper) sdk.Invariant {
+	return BookQuantityInvariant(k)
+}
+
+// BookQuantityInvariant checks that every price level's quantity equals the sum
+// of the remaining quantity of the orders resting on it, and that each of those
+// orders is active on the level's side and price
+func BookQuantityInvariant(k *Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		var broken []string
+		for _, ob := range k.GetAllOrderBooks(ctx) {
+			broken = append(broken, checkBookLevels(ctx, k, ob.MarketID, types.SideBuy, ob.Bids)...)
+			broken = append(broken, checkBookLevels(ctx, k, ob.MarketID, types.SideSell, ob.Asks)...)
+		}
+
+		return sdk.FormatInvariant(invariantModule, "book-quantity",
+			fmt.Sprintf("%d price levels not matching their open orders\n%s",
+				len(broken), strings.Join(broken, ""))), len(broken) > 0
+	}
+}
+
+func checkBookLevels(ctx sdk.Context, k *Keeper, marketID string, side types.Side, levels []*types.PriceLevel) []string {
+	var broken []string
+	for _, level := range levels {
+		remaining := math.LegacyZeroDec()
+		for _, orderID := range level.OrderIDs {
+			order := k.GetOrder(ctx, orderID)
+			switch {
+			case order == nil:
+				broken = append(broken, fmt.Sprintf("\t%s %s@%s: order %s not found\n", marketID, side, level.Price, orderID))
+			case !order.IsActive() || order.Side != side || !order.Price.Equal(level.Price):
+				broken = append(broken, fmt.Sprintf("\t%s %s@%s: order %s is %s %s@%s\n",
+					marketID, side, level.Price, orderID, order.Status, order.Side, order.Price))
+			default:
+				remaining = remaining.Add(order.RemainingQty())
+			}
+		}
+		if !level.Quantity.Equal(remaining) {
+			broken = append(broken, fmt.Sprintf("\t%s %s@%s: level quantity %s, open orders %s\n",
+				marketID, side, level.Price, level.Quantity, remaining))
+		}
+	}
+	return broken
+}
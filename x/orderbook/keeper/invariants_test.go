@@ -0,0 +1,71 @@
+package keeper
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestBookQuantityInvariant tests the invariant over randomly built and partially filled books
+func TestBookQuantityInvariant(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	for round := 0; round < 20; round++ {
+		k, ctx := setupBenchKeeper(t)
+		ob := types.NewOrderBook("BTC-USDC")
+
+		for i := 0; i < 50; i++ {
+			side := types.SideBuy
+			price := math.LegacyNewDec(int64(49000 + r.Intn(10)*100))
+			if r.Intn(2) == 0 {
+				side = types.SideSell
+				price = math.LegacyNewDec(int64(51000 + r.Intn(10)*100))
+			}
+			order := types.NewOrder(fmt.Sprintf("order-%d-%d", round, i), "cosmos1trader", "BTC-USDC",
+				side, types.OrderTypeLimit, price, math.LegacyNewDecWithPrec(int64(1+r.Intn(100)), 2))
+
+			// Partially fill some orders before they rest on the book
+			if r.Intn(3) == 0 {
+				_ = order.Fill(order.Quantity.QuoInt64(2))
+			}
+			k.SetOrder(ctx, order)
+			ob.AddOrder(order)
+		}
+		k.SetOrderBook(ctx, ob)
+
+		if msg, broken := BookQuantityInvariant(k)(ctx); broken {
+			t.Fatalf("round %d: expected invariant to hold: %s", round, msg)
+		}
+	}
+}
+
+// TestBookQuantityInvariantBroken tests that stale levels and orders are reported
+func TestBookQuantityInvariantBroken(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	ob := types.NewOrderBook("BTC-USDC")
+
+	order := types.NewOrder("order-1", "cosmos1trader", "BTC-USDC",
+		types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(50000), math.LegacyNewDec(1))
+	k.SetOrder(ctx, order)
+	ob.AddOrder(order)
+	k.SetOrderBook(ctx, ob)
+
+	// Fill the order without updating the book
+	_ = order.Fill(math.LegacyNewDecWithPrec(5, 1))
+	k.SetOrder(ctx, order)
+	if _, broken := BookQuantityInvariant(k)(ctx); !broken {
+		t.Error("expected invariant to break on level quantity mismatch")
+	}
+
+	// Cancelled orders must not rest on the book
+	order.Cancel()
+	k.SetOrder(ctx, order)
+	ob.Bids[0].Quantity = order.RemainingQty()
+	k.SetOrderBook(ctx, ob)
+	if _, broken := BookQuantityInvariant(k)(ctx); !broken {
+		t.Error("expected invariant to break on cancelled resting order")
+	}
+}
@@ -5,12 +5,16 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
 	"cosmossdk.io/log"
 	"cosmossdk.io/math"
 	storetypes "cosmossdk.io/store/types"
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/pkg/timing"
+	"github.com/openalpha/perp-dex/pkg/validation"
 	"github.com/openalpha/perp-dex/x/orderbook/types"
 )
 
@@ -37,6 +41,42 @@ type Market struct {
 	TakerFeeRate  math.LegacyDec
 	MakerFeeRate  math.LegacyDec
 	InitialMargin math.LegacyDec
+
+	// Order validation rules; nil or zero disables the check
+	TickSize          math.LegacyDec
+	LotSize           math.LegacyDec
+	MinOrderSize      math.LegacyDec
+	MaxOrderSize      math.LegacyDec
+	MinNotional       math.LegacyDec
+	MaxPriceDeviation math.LegacyDec
+
+	// Default price protection of market orders: the furthest from the mark
+	// price they fill, e.g. 0.05 = 5%; nil or zero walks the book
+	MaxSlippage math.LegacyDec
+
+	// Fixed-point decimals for the integer matching fast path; both zero disables it
+	PriceDecimals uint32
+	SizeDecimals  uint32
+
+	// Non-nil while the market's trading schedule rejects new orders; cancels are still accepted
+	TradingHalt error
+
+	// Set during a new listing's post-only warm-up: market orders and limit
+	// orders that would trade are rejected
+	PostOnly bool
+}
+
+// ValidationRules returns the market's order validation rules
+func (m *Market) ValidationRules() validation.MarketRules {
+	return validation.MarketRules{
+		MarketID:          m.MarketID,
+		TickSize:          m.TickSize,
+		LotSize:           m.LotSize,
+		MinOrderSize:      m.MinOrderSize,
+		MaxOrderSize:      m.MaxOrderSize,
+		MinNotional:       m.MinNotional,
+		MaxPriceDeviation: m.MaxPriceDeviation,
+	}
 }
 
 // Keeper manages the orderbook state
@@ -48,6 +88,15 @@ type Keeper struct {
 	parallelConfig    ParallelConfig
 	parallelMatcher   *ParallelMatcher
 	parallelMatcherV2 *ParallelMatcherV2
+	analytics         *analyticsTracker
+	feeRevenueSink    FeeRevenueSink     // optional
+	feeTreasury       FeeTreasury        // optional
+	feeTokenBank      FeeTokenBank       // optional
+	feeTokenCollector string             // module account fee token payments go to
+	feeTokenRates     FeeTokenRateSource // optional
+	hooks             types.TradeHooks   // optional
+	stickySlots       bool               // reuse cancelled order slots within a block
+	directWrites      bool               // write requests straight to the store, see UnitOfWork
 }
 
 // NewKeeper creates a new orderbook keeper
@@ -63,6 +112,7 @@ func NewKeeper(
 		perpetualKeeper: perpetualKeeper,
 		logger:          logger.With("module", "x/orderbook"),
 		parallelConfig:  DefaultParallelConfig(),
+		analytics:       newAnalyticsTracker(DefaultVolatilityWindow),
 	}
 	k.parallelMatcher = NewParallelMatcher(k, k.parallelConfig)
 	k.parallelMatcherV2 = NewParallelMatcherV2(k, k.parallelConfig)
@@ -83,6 +133,7 @@ func NewKeeperWithConfig(
 		perpetualKeeper: perpetualKeeper,
 		logger:          logger.With("module", "x/orderbook"),
 		parallelConfig:  parallelConfig,
+		analytics:       newAnalyticsTracker(DefaultVolatilityWindow),
 	}
 	k.parallelMatcher = NewParallelMatcher(k, parallelConfig)
 	k.parallelMatcherV2 = NewParallelMatcherV2(k, parallelConfig)
@@ -99,12 +150,16 @@ func (k *Keeper) GetStore(ctx sdk.Context) storetypes.KVStore {
 	return ctx.KVStore(k.storeKey)
 }
 
-// SetOrder saves an order to the store
+// SetOrder saves an order to the store, indexes it under its trader and,
+// while it is active, by price and counts it against the resting order limits
 func (k *Keeper) SetOrder(ctx sdk.Context, order *types.Order) {
 	store := k.GetStore(ctx)
 	key := append(OrderKeyPrefix, []byte(order.OrderID)...)
 	bz, _ := json.Marshal(order)
 	store.Set(key, bz)
+	store.Set(orderHistoryKey(order), []byte{})
+	k.indexOrderPrice(ctx, order)
+	k.trackResting(ctx, order, order.IsActive())
 }
 
 // GetOrder retrieves an order from the store
@@ -122,9 +177,15 @@ func (k *Keeper) GetOrder(ctx sdk.Context, orderID string) *types.Order {
 	return &order
 }
 
-// DeleteOrder removes an order from the store
+// DeleteOrder removes an order and its trader and price index entries from
+// the store
 func (k *Keeper) DeleteOrder(ctx sdk.Context, orderID string) {
 	store := k.GetStore(ctx)
+	if order := k.GetOrder(ctx, orderID); order != nil {
+		store.Delete(orderHistoryKey(order))
+		k.trackResting(ctx, order, false)
+	}
+	k.unindexOrderPrice(ctx, orderID)
 	key := append(OrderKeyPrefix, []byte(orderID)...)
 	store.Delete(key)
 }
@@ -148,12 +209,40 @@ func (k *Keeper) GetOrdersByTrader(ctx sdk.Context, trader string) []*types.Orde
 	return orders
 }
 
+// CountOpenOrdersByTraders counts the active orders of several traders in a
+// single pass over the order store
+func (k *Keeper) CountOpenOrdersByTraders(ctx sdk.Context, traders []string) map[string]int {
+	counts := make(map[string]int, len(traders))
+	for _, trader := range traders {
+		counts[trader] = 0
+	}
+
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, OrderKeyPrefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var order types.Order
+		if err := json.Unmarshal(iterator.Value(), &order); err != nil {
+			continue
+		}
+		if _, ok := counts[order.Trader]; ok && order.IsActive() {
+			counts[order.Trader]++
+		}
+	}
+	return counts
+}
+
 // SetOrderBook saves an order book to the store
 func (k *Keeper) SetOrderBook(ctx sdk.Context, ob *types.OrderBook) {
 	store := k.GetStore(ctx)
 	key := append(OrderBookKeyPrefix, []byte(ob.MarketID)...)
 	bz, _ := json.Marshal(ob)
 	store.Set(key, bz)
+
+	if !ctx.IsCheckTx() {
+		k.analytics.markDirty(ob.MarketID)
+	}
 }
 
 // GetOrderBook retrieves an order book from the store
@@ -171,12 +260,58 @@ func (k *Keeper) GetOrderBook(ctx sdk.Context, marketID string) *types.OrderBook
 	return &ob
 }
 
-// SetTrade saves a trade to the store
+// GetAllOrderBooks retrieves all order books from the store
+func (k *Keeper) GetAllOrderBooks(ctx sdk.Context) []*types.OrderBook {
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, OrderBookKeyPrefix)
+	defer iterator.Close()
+
+	var books []*types.OrderBook
+	for ; iterator.Valid(); iterator.Next() {
+		var ob types.OrderBook
+		if err := json.Unmarshal(iterator.Value(), &ob); err != nil {
+			continue
+		}
+		books = append(books, &ob)
+	}
+	return books
+}
+
+// SetTrade saves a trade to the store, indexes it under both traders and its
+// market, and records it as an execution of both orders
 func (k *Keeper) SetTrade(ctx sdk.Context, trade *types.Trade) {
 	store := k.GetStore(ctx)
 	key := append(TradeKeyPrefix, []byte(trade.TradeID)...)
 	bz, _ := json.Marshal(trade)
 	store.Set(key, bz)
+	k.indexTrade(ctx, trade)
+	k.recordExecutions(ctx, trade)
+
+	if !ctx.IsCheckTx() {
+		k.analytics.markDirty(trade.MarketID)
+	}
+}
+
+// GetTrade retrieves a trade from the store
+func (k *Keeper) GetTrade(ctx sdk.Context, tradeID string) *types.Trade {
+	store := k.GetStore(ctx)
+	key := append(TradeKeyPrefix, []byte(tradeID)...)
+	bz := store.Get(key)
+	if bz == nil {
+		return nil
+	}
+	var trade types.Trade
+	if err := json.Unmarshal(bz, &trade); err != nil {
+		return nil
+	}
+	return &trade
+}
+
+// GetBookAnalytics returns the liquidity analytics of a market as of the last
+// RefreshAnalytics. Returns nil if neither its order book nor a trade has been
+// refreshed since the keeper was created.
+func (k *Keeper) GetBookAnalytics(marketID string) *BookAnalytics {
+	return k.analytics.get(marketID)
 }
 
 // GetRecentTrades returns recent trades for a market
@@ -239,6 +374,7 @@ func (k *Keeper) emitTradeEvent(ctx sdk.Context, trade *types.Trade) {
 	ctx.EventManager().EmitEvent(
 		sdk.NewEvent(
 			"trade",
+			sdk.NewAttribute("seq", strconv.FormatUint(trade.Seq, 10)),
 			sdk.NewAttribute("trade_id", trade.TradeID),
 			sdk.NewAttribute("market_id", trade.MarketID),
 			sdk.NewAttribute("taker", trade.Taker),
@@ -252,18 +388,82 @@ func (k *Keeper) emitTradeEvent(ctx sdk.Context, trade *types.Trade) {
 
 // PlaceOrder handles placing a new order
 func (k *Keeper) PlaceOrder(ctx context.Context, trader, marketID string, side types.Side, orderType types.OrderType, price, quantity math.LegacyDec) (*types.Order, *MatchResult, error) {
-	sdkCtx := sdk.UnwrapSDKContext(ctx)
+	return k.placeOrder(ctx, trader, marketID, side, orderType, price, quantity, nil, math.LegacyDec{})
+}
+
+// PlaceOrderWithExpiry places a good-till-date limit order. Whatever rests
+// after matching is cancelled as expired by the first ExpireOrders run at or
+// after expireAt, which must be later than the block time.
+func (k *Keeper) PlaceOrderWithExpiry(ctx context.Context, trader, marketID string, side types.Side, price, quantity math.LegacyDec, expireAt time.Time) (*types.Order, *MatchResult, error) {
+	if !expireAt.After(sdk.UnwrapSDKContext(ctx).BlockTime()) {
+		return nil, nil, types.ErrInvalidExpiry.Wrapf("expiry %s is not after the block time", expireAt.UTC().Format(time.RFC3339))
+	}
+	return k.placeOrder(ctx, trader, marketID, side, types.OrderTypeLimit, price, quantity, &expireAt, math.LegacyDec{})
+}
 
-	// Generate order ID
-	orderID := k.generateOrderID(sdkCtx)
+// placeOrder validates, margins and matches a new order, scheduling the
+// expiry of a good-till-date remainder and capping the price of a market
+// order at maxSlippage, or the market's default when nil
+func (k *Keeper) placeOrder(ctx context.Context, trader, marketID string, side types.Side, orderType types.OrderType, price, quantity math.LegacyDec, expireAt *time.Time, maxSlippage math.LegacyDec) (*types.Order, *MatchResult, error) {
+	// The order's writes are committed together once it has been placed
+	uow := k.BeginUnitOfWork(sdk.UnwrapSDKContext(ctx))
+	sdkCtx := uow.Context()
+
+	// A limit order replacing one the trader cancelled on the same side in
+	// this block takes over its slot, saving a new order key and ID
+	orderID, sticky := "", false
+	if k.stickySlots && orderType == types.OrderTypeLimit {
+		orderID, sticky = k.stickySlot(sdkCtx, trader, marketID, side)
+	}
+	var replaced *types.Order
+	if sticky {
+		replaced = k.GetOrder(sdkCtx, orderID)
+	}
+	if !sticky {
+		orderID = k.generateOrderID(sdkCtx)
+	}
 
 	// Create order
 	order := types.NewOrder(orderID, trader, marketID, side, orderType, price, quantity)
+	order.ExpireAt = expireAt
+
+	// Stage timings for the API's latency breakdown; a no-op on chain
+	rec := timing.FromContext(ctx)
+	start := time.Now()
+
+	// Enforce per-market tick/lot size, size bounds, min notional and price band
+	if err := k.validateOrder(sdkCtx, marketID, side, orderType, price, quantity); err != nil {
+		return nil, nil, err
+	}
+
+	// Reject new quotes while the trader's market maker protection is tripped
+	if err := k.checkMMP(sdkCtx, trader, marketID, orderType); err != nil {
+		return nil, nil, err
+	}
+
+	// Reject new limit orders while the market or trader is at its resting cap
+	if err := k.checkOrderLimits(sdkCtx, trader, marketID, orderType); err != nil {
+		return nil, nil, err
+	}
+	rec.Since(timing.StageValidate, start)
+	start = time.Now()
+
+	// A capped market order is margined at its cap, the worst price it
+	// can fill at
+	marginPrice := price
+	if orderType == types.OrderTypeMarket {
+		order.PriceCap = k.marketPriceCap(sdkCtx, marketID, side, maxSlippage)
+		if order.HasPriceCap() {
+			marginPrice = order.PriceCap
+		}
+	}
 
 	// Check margin requirement via perpetualKeeper (REAL margin validation)
-	if err := k.perpetualKeeper.CheckMarginRequirement(sdkCtx, trader, marketID, side, quantity, price); err != nil {
+	if err := k.perpetualKeeper.CheckMarginRequirement(sdkCtx, trader, marketID, side, quantity, marginPrice); err != nil {
 		return nil, nil, fmt.Errorf("insufficient margin: %w", err)
 	}
+	rec.Since(timing.StageMargin, start)
+	start = time.Now()
 
 	// Process order through matching engine
 	engine := NewMatchingEngine(k)
@@ -271,13 +471,56 @@ func (k *Keeper) PlaceOrder(ctx context.Context, trader, marketID string, side t
 	if err != nil {
 		return nil, nil, err
 	}
+	rec.Since(timing.StageMatch, start)
+	k.indexExpiry(sdkCtx, order)
+	if sticky {
+		k.claimStickySlot(sdkCtx, order)
+		// The cancelled order's history entry goes with it
+		if replaced != nil && !replaced.CreatedAt.Equal(order.CreatedAt) {
+			k.GetStore(sdkCtx).Delete(orderHistoryKey(replaced))
+		}
+	}
 
+	uow.Commit()
 	return order, result, nil
 }
 
+// validateOrder checks an order against the market's trading schedule, warm-up and validation rules.
+// Markets unknown to the perpetual keeper are not validated here.
+func (k *Keeper) validateOrder(ctx sdk.Context, marketID string, side types.Side, orderType types.OrderType, price, quantity math.LegacyDec) error {
+	market := k.perpetualKeeper.GetMarket(ctx, marketID)
+	if market == nil {
+		return nil
+	}
+	if market.TradingHalt != nil {
+		return market.TradingHalt
+	}
+	if market.PostOnly {
+		if orderType == types.OrderTypeMarket {
+			return types.ErrWarmUpPostOnly.Wrap("market orders are not accepted")
+		}
+		if k.CheckPostOnly(ctx, &types.Order{MarketID: marketID, Side: side, Price: price}) {
+			return types.ErrWarmUpPostOnly.Wrapf("%s order at %s would trade", side, price)
+		}
+	}
+
+	refPrice, ok := k.perpetualKeeper.GetMarkPrice(ctx, marketID)
+	if !ok {
+		refPrice = math.LegacyDec{}
+	}
+
+	return validation.ValidateOrder(market.ValidationRules(), validation.OrderInput{
+		Price:    price,
+		Quantity: quantity,
+		IsMarket: orderType == types.OrderTypeMarket,
+		RefPrice: refPrice,
+	})
+}
+
 // CancelOrder handles order cancellation
 func (k *Keeper) CancelOrder(ctx context.Context, trader, orderID string) (*types.Order, error) {
-	sdkCtx := sdk.UnwrapSDKContext(ctx)
+	uow := k.BeginUnitOfWork(sdk.UnwrapSDKContext(ctx))
+	sdkCtx := uow.Context()
 
 	order := k.GetOrder(sdkCtx, orderID)
 	if order == nil {
@@ -289,7 +532,71 @@ func (k *Keeper) CancelOrder(ctx context.Context, trader, orderID string) (*type
 	}
 
 	engine := NewMatchingEngine(k)
-	return engine.CancelOrder(sdkCtx, orderID)
+	cancelled, err := engine.CancelOrder(sdkCtx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if k.stickySlots {
+		k.releaseStickySlot(sdkCtx, cancelled)
+	}
+	uow.Commit()
+	return cancelled, nil
+}
+
+// AmendOrder modifies a resting limit order without changing its ID. A nil
+// price or quantity leaves that field unchanged; quantity is the new total
+// size including any filled part. Reducing the quantity keeps the order's
+// time priority and returns a nil MatchResult; a price change or a size
+// increase re-queues the order behind others at its price.
+func (k *Keeper) AmendOrder(ctx context.Context, trader, orderID string, price, quantity math.LegacyDec) (*types.Order, *MatchResult, error) {
+	uow := k.BeginUnitOfWork(sdk.UnwrapSDKContext(ctx))
+	sdkCtx := uow.Context()
+
+	order := k.GetOrder(sdkCtx, orderID)
+	if order == nil {
+		return nil, nil, fmt.Errorf("order not found: %s", orderID)
+	}
+	if order.Trader != trader {
+		return nil, nil, fmt.Errorf("unauthorized: order belongs to different trader")
+	}
+	if !order.IsActive() {
+		return nil, nil, fmt.Errorf("order is not active: %s", orderID)
+	}
+	if order.OrderType != types.OrderTypeLimit {
+		return nil, nil, fmt.Errorf("only limit orders can be amended")
+	}
+
+	if price.IsNil() {
+		price = order.Price
+	}
+	if quantity.IsNil() {
+		quantity = order.Quantity
+	}
+	if !quantity.GT(order.FilledQty) {
+		return nil, nil, fmt.Errorf("quantity %s must exceed filled quantity %s", quantity, order.FilledQty)
+	}
+
+	if err := k.validateOrder(sdkCtx, order.MarketID, order.Side, order.OrderType, price, quantity); err != nil {
+		return nil, nil, err
+	}
+
+	// A re-queued order is a new quote, so it is checked like one
+	if !price.Equal(order.Price) || quantity.GT(order.Quantity) {
+		if err := k.checkMMP(sdkCtx, trader, order.MarketID, order.OrderType); err != nil {
+			return nil, nil, err
+		}
+		if err := k.perpetualKeeper.CheckMarginRequirement(sdkCtx, trader, order.MarketID, order.Side, quantity.Sub(order.FilledQty), price); err != nil {
+			return nil, nil, fmt.Errorf("insufficient margin: %w", err)
+		}
+	}
+
+	engine := NewMatchingEngine(k)
+	result, err := engine.AmendOrder(sdkCtx, order, price, quantity)
+	if err != nil {
+		return nil, nil, err
+	}
+	uow.Commit()
+	return order, result, nil
 }
 
 // GetParallelConfig returns the current parallel matching configuration
@@ -385,13 +692,6 @@ func (k *Keeper) ParallelEndBlocker(ctx sdk.Context) error {
 		"errors", len(result.Errors),
 	)
 
-	// Save trades
-	for _, marketResult := range result.Results {
-		for _, trade := range marketResult.Trades {
-			k.SetTrade(ctx, trade)
-		}
-	}
-
 	// Handle any errors
 	if len(result.Errors) > 0 {
 		for _, e := range result.Errors {
@@ -492,11 +792,8 @@ func (k *Keeper) SequentialEndBlocker(ctx sdk.Context) error {
 			continue
 		}
 
-		if result != nil && len(result.Trades) > 0 {
+		if result != nil {
 			totalTrades += len(result.Trades)
-			for _, trade := range result.Trades {
-				k.SetTrade(ctx, trade)
-			}
 		}
 	}
 
@@ -0,0 +1,185 @@
+package keeper
+
+import (
+	"fmt"
+	"testing"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Keeper benchmarks go through the keeper's entry points, store included, so
+// they measure what a transaction or block pays. Compare runs with
+// cmd/benchreport:
+//
+//	go test -run '^$' -bench 'Keeper' -benchmem -count 10 ./x/orderbook/keeper/ > new.txt
+//	go run ./cmd/benchreport old.txt new.txt
+
+// benchBookDepths are the resting orders per side a book is seeded with
+var benchBookDepths = []int{0, 100, 1000, 5000}
+
+// seedBenchBook rests depth bids below 50000 and depth asks above it, one
+// price level per 10 orders
+func seedBenchBook(tb testing.TB, k *Keeper, ctx sdk.Context, marketID string, depth int) {
+	tb.Helper()
+	for i := 0; i < depth; i++ {
+		offset := math.LegacyNewDec(int64(1 + i/10))
+		qty := math.LegacyNewDec(1)
+		if _, _, err := k.PlaceOrder(ctx, fmt.Sprintf("maker-%d", i%50), marketID, types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(50000).Sub(offset), qty); err != nil {
+			tb.Fatalf("failed to seed bid: %v", err)
+		}
+		if _, _, err := k.PlaceOrder(ctx, fmt.Sprintf("maker-%d", i%50), marketID, types.SideSell, types.OrderTypeLimit, math.LegacyNewDec(50000).Add(offset), qty); err != nil {
+			tb.Fatalf("failed to seed ask: %v", err)
+		}
+	}
+}
+
+// BenchmarkKeeperPlaceOrder benchmarks placing an order that rests behind
+// the top of book, and one that crosses it and fills against a single maker
+func BenchmarkKeeperPlaceOrder(b *testing.B) {
+	for _, depth := range benchBookDepths {
+		b.Run(fmt.Sprintf("rest/depth=%d", depth), func(b *testing.B) {
+			k, ctx := setupBenchKeeper(b)
+			seedBenchBook(b, k, ctx, "BTC-USDC", depth)
+			price := math.LegacyNewDec(49000)
+			qty := math.LegacyNewDec(1)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := k.PlaceOrder(ctx, "taker", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, price, qty); err != nil {
+					b.Fatalf("failed to place order: %v", err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("cross/depth=%d", depth), func(b *testing.B) {
+			k, ctx := setupBenchKeeper(b)
+			seedBenchBook(b, k, ctx, "BTC-USDC", depth)
+			price := math.LegacyNewDec(50000)
+			qty := math.LegacyNewDec(1)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Replace the maker the previous iteration filled
+				b.StopTimer()
+				if _, _, err := k.PlaceOrder(ctx, "maker", "BTC-USDC", types.SideSell, types.OrderTypeLimit, price, qty); err != nil {
+					b.Fatalf("failed to place maker: %v", err)
+				}
+				b.StartTimer()
+				if _, _, err := k.PlaceOrder(ctx, "taker", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, price, qty); err != nil {
+					b.Fatalf("failed to place taker: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkKeeperCancelOrder benchmarks cancelling a resting order from a
+// book of each depth
+func BenchmarkKeeperCancelOrder(b *testing.B) {
+	for _, depth := range benchBookDepths {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			k, ctx := setupBenchKeeper(b)
+			seedBenchBook(b, k, ctx, "BTC-USDC", depth)
+			orderIDs := make([]string, b.N)
+			for i := range orderIDs {
+				order, _, err := k.PlaceOrder(ctx, "canceller", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(49000), math.LegacyNewDec(1))
+				if err != nil {
+					b.Fatalf("failed to place order: %v", err)
+				}
+				orderIDs[i] = order.OrderID
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := k.CancelOrder(ctx, "canceller", orderIDs[i]); err != nil {
+					b.Fatalf("failed to cancel order: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// runOrderbookEndBlock runs the orderbook keeper's share of the block end,
+// in the order app.EndBlocker runs it
+func runOrderbookEndBlock(k *Keeper, ctx sdk.Context) {
+	k.ClearStickySlots(ctx)
+	k.ExpireOrders(ctx)
+	_, _ = k.ParallelEndBlockerV2(ctx)
+	k.ConditionalOrderEndBlocker(ctx)
+	k.SpreadEndBlocker(ctx)
+	k.MarginRecheckEndBlocker(ctx)
+	k.LPObligationEndBlocker(ctx)
+	k.FeeLedgerEndBlocker(ctx)
+}
+
+// BenchmarkKeeperEndBlocker benchmarks the orderbook end block over markets
+// each holding a 100-deep book on both sides
+func BenchmarkKeeperEndBlocker(b *testing.B) {
+	for _, markets := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("markets=%d", markets), func(b *testing.B) {
+			k, ctx := setupBenchKeeper(b)
+			for m := 0; m < markets; m++ {
+				seedBenchBook(b, k, ctx, fmt.Sprintf("MKT%d-USDC", m), 100)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				runOrderbookEndBlock(k, ctx)
+			}
+		})
+	}
+}
+
+// storeListener is the part of the benchmark's commit multistore that
+// records the writes reaching the IAVL store
+type storeListener interface {
+	AddListeners(keys []storetypes.StoreKey)
+	PopStateCache() []*storetypes.StoreKVPair
+}
+
+// BenchmarkKeeperUnitOfWork benchmarks placing orders with each request's
+// writes staged in a unit of work against writing them as they are made,
+// reporting the writes that reach the IAVL store per order
+func BenchmarkKeeperUnitOfWork(b *testing.B) {
+	for _, mode := range []string{"direct", "unit"} {
+		for _, op := range []string{"rest", "cross"} {
+			b.Run(fmt.Sprintf("%s/%s", mode, op), func(b *testing.B) {
+				k, ctx := setupBenchKeeper(b)
+				k.SetUnitOfWork(mode == "unit")
+				seedBenchBook(b, k, ctx, "BTC-USDC", 100)
+				listener := ctx.MultiStore().(storeListener)
+				listener.AddListeners([]storetypes.StoreKey{k.storeKey})
+				price := math.LegacyNewDec(49000)
+				if op == "cross" {
+					price = math.LegacyNewDec(50000)
+				}
+				qty := math.LegacyNewDec(1)
+
+				writes := 0
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if op == "cross" {
+						b.StopTimer()
+						if _, _, err := k.PlaceOrder(ctx, "maker", "BTC-USDC", types.SideSell, types.OrderTypeLimit, price, qty); err != nil {
+							b.Fatalf("failed to place maker: %v", err)
+						}
+						listener.PopStateCache()
+						b.StartTimer()
+					}
+					if _, _, err := k.PlaceOrder(ctx, "taker", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, price, qty); err != nil {
+						b.Fatalf("failed to place order: %v", err)
+					}
+					writes += len(listener.PopStateCache())
+				}
+				b.ReportMetric(float64(writes)/float64(b.N), "writes/op")
+			})
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Store key prefixes for liquidity provider quoting obligations
+var (
+	LPObligationKeyPrefix = []byte{0x60}
+	LPUptimeKeyPrefix     = []byte{0x61} // trader/marketID/epoch -> LPUptime
+)
+
+// lpUptimeKey appends the big-endian epoch so a market's reports sort by epoch
+func lpUptimeKey(trader, marketID string, epoch int64) []byte {
+	key := append(mmpKey(LPUptimeKeyPrefix, trader, marketID), '/')
+	return binary.BigEndian.AppendUint64(key, uint64(epoch))
+}
+
+// ============ Obligations ============
+
+// SetLPObligation validates and saves a designated maker's obligation in a market
+func (k *Keeper) SetLPObligation(ctx sdk.Context, obligation *types.LPObligation) error {
+	if err := obligation.Validate(); err != nil {
+		return err
+	}
+	obligation.UpdatedAt = ctx.BlockTime()
+	k.setLPObligation(ctx, obligation)
+	return nil
+}
+
+func (k *Keeper) setLPObligation(ctx sdk.Context, obligation *types.LPObligation) {
+	store := k.GetStore(ctx)
+	bz, _ := json.Marshal(obligation)
+	store.Set(mmpKey(LPObligationKeyPrefix, obligation.Trader, obligation.MarketID), bz)
+}
+
+// GetLPObligation returns a maker's obligation in a market, or nil if none is set
+func (k *Keeper) GetLPObligation(ctx sdk.Context, trader, marketID string) *types.LPObligation {
+	store := k.GetStore(ctx)
+	bz := store.Get(mmpKey(LPObligationKeyPrefix, trader, marketID))
+	if bz == nil {
+		return nil
+	}
+	var obligation types.LPObligation
+	if err := json.Unmarshal(bz, &obligation); err != nil {
+		return nil
+	}
+	return &obligation
+}
+
+// GetLPObligationsByTrader returns a maker's obligations across markets
+func (k *Keeper) GetLPObligationsByTrader(ctx sdk.Context, trader string) []*types.LPObligation {
+	return k.iterateLPObligations(ctx, mmpKey(LPObligationKeyPrefix, trader, ""))
+}
+
+// GetAllLPObligations returns every designated maker's obligations
+func (k *Keeper) GetAllLPObligations(ctx sdk.Context) []*types.LPObligation {
+	return k.iterateLPObligations(ctx, LPObligationKeyPrefix)
+}
+
+func (k *Keeper) iterateLPObligations(ctx sdk.Context, prefix []byte) []*types.LPObligation {
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, prefix)
+	defer iterator.Close()
+
+	obligations := make([]*types.LPObligation, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var obligation types.LPObligation
+		if err := json.Unmarshal(iterator.Value(), &obligation); err != nil {
+			continue
+		}
+		obligations = append(obligations, &obligation)
+	}
+	return obligations
+}
+
+// DeleteLPObligation stops tracking a maker in a market. Past uptime reports
+// are kept.
+func (k *Keeper) DeleteLPObligation(ctx sdk.Context, trader, marketID string) {
+	k.GetStore(ctx).Delete(mmpKey(LPObligationKeyPrefix, trader, marketID))
+}
+
+// ============ Uptime ============
+
+// GetLPUptime returns a maker's uptime in a market for an epoch, empty if the
+// epoch was not sampled
+func (k *Keeper) GetLPUptime(ctx sdk.Context, trader, marketID string, epoch int64) *types.LPUptime {
+	store := k.GetStore(ctx)
+	bz := store.Get(lpUptimeKey(trader, marketID, epoch))
+	if bz == nil {
+		return types.NewLPUptime(trader, marketID, epoch)
+	}
+	var uptime types.LPUptime
+	if err := json.Unmarshal(bz, &uptime); err != nil {
+		return types.NewLPUptime(trader, marketID, epoch)
+	}
+	return &uptime
+}
+
+func (k *Keeper) setLPUptime(ctx sdk.Context, uptime *types.LPUptime) {
+	store := k.GetStore(ctx)
+	bz, _ := json.Marshal(uptime)
+	store.Set(lpUptimeKey(uptime.Trader, uptime.MarketID, uptime.Epoch), bz)
+}
+
+// GetLPUptimeReports returns a maker's uptime for every market it was sampled
+// in during an epoch
+func (k *Keeper) GetLPUptimeReports(ctx sdk.Context, trader string, epoch int64) []*types.LPUptime {
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, mmpKey(LPUptimeKeyPrefix, trader, ""))
+	defer iterator.Close()
+
+	reports := make([]*types.LPUptime, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var uptime types.LPUptime
+		if err := json.Unmarshal(iterator.Value(), &uptime); err != nil {
+			continue
+		}
+		if uptime.Epoch == epoch {
+			reports = append(reports, &uptime)
+		}
+	}
+	return reports
+}
+
+// LPObligationMet reports whether a maker met every current obligation in an
+// epoch. designated is false for traders without obligations, whose
+// eligibility the obligations do not affect.
+func (k *Keeper) LPObligationMet(ctx sdk.Context, trader string, epoch int64) (met, designated bool) {
+	obligations := k.GetLPObligationsByTrader(ctx, trader)
+	if len(obligations) == 0 {
+		return false, false
+	}
+	for _, obligation := range obligations {
+		if !k.GetLPUptime(ctx, trader, obligation.MarketID, epoch).Met() {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+// LPObligationEligible reports whether a trader may hold Foundation LP seats
+// and earn LP points. Traders without obligations always may; designated
+// makers must have met each obligation over the last completed epoch, unless
+// it was set too recently to have been in force for all of that epoch.
+func (k *Keeper) LPObligationEligible(ctx sdk.Context, trader string) bool {
+	previous := types.LPEpoch(ctx.BlockTime()) - 1
+	for _, obligation := range k.GetLPObligationsByTrader(ctx, trader) {
+		if types.LPEpoch(obligation.UpdatedAt) >= previous {
+			continue
+		}
+		if !k.GetLPUptime(ctx, trader, obligation.MarketID, previous).Met() {
+			return false
+		}
+	}
+	return true
+}
+
+// LPObligationEndBlocker samples every obligation against the stored order
+// books and adds the result to the current epoch's uptime
+func (k *Keeper) LPObligationEndBlocker(ctx sdk.Context) {
+	obligations := k.GetAllLPObligations(ctx)
+	if len(obligations) == 0 {
+		return
+	}
+
+	now := ctx.BlockTime()
+	epoch := types.LPEpoch(now)
+	books := make(map[string]*types.OrderBook)
+	for _, obligation := range obligations {
+		ob, ok := books[obligation.MarketID]
+		if !ok {
+			ob = k.GetOrderBook(ctx, obligation.MarketID)
+			books[obligation.MarketID] = ob
+		}
+
+		uptime := k.GetLPUptime(ctx, obligation.Trader, obligation.MarketID, epoch)
+		uptime.Record(obligation, k.lpQuoting(ctx, ob, obligation), now)
+		k.setLPUptime(ctx, uptime)
+	}
+}
+
+// lpQuoting reports whether the maker rests at least MinQuantity on both
+// sides of the book within MaxSpreadBps of the mid price
+func (k *Keeper) lpQuoting(ctx sdk.Context, ob *types.OrderBook, obligation *types.LPObligation) bool {
+	if ob == nil || len(ob.Bids) == 0 || len(ob.Asks) == 0 {
+		return false
+	}
+
+	mid := ob.Bids[0].Price.Add(ob.Asks[0].Price).QuoInt64(2)
+	offset := mid.MulInt64(obligation.MaxSpreadBps).Quo(bpsDenominator)
+	floor, ceiling := mid.Sub(offset), mid.Add(offset)
+
+	bid := k.makerDepthWithin(ctx, ob.Bids, obligation.Trader, func(price math.LegacyDec) bool { return price.GTE(floor) })
+	ask := k.makerDepthWithin(ctx, ob.Asks, obligation.Trader, func(price math.LegacyDec) bool { return price.LTE(ceiling) })
+	return bid.GTE(obligation.MinQuantity) && ask.GTE(obligation.MinQuantity)
+}
+
+// makerDepthWithin sums one trader's resting quantity from the top of one side
+// while within holds
+func (k *Keeper) makerDepthWithin(ctx sdk.Context, levels []*types.PriceLevel, trader string, within func(price math.LegacyDec) bool) math.LegacyDec {
+	total := math.LegacyZeroDec()
+	for _, level := range levels {
+		if !within(level.Price) {
+			break
+		}
+		for _, orderID := range level.OrderIDs {
+			if order := k.GetOrder(ctx, orderID); order != nil && order.Trader == trader && order.IsActive() {
+				total = total.Add(order.RemainingQty())
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,107 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestLPObligationUptime tests that each block samples whether the maker
+// quotes both sides within the spread, and that the epoch report reflects it
+func TestLPObligationUptime(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx = ctx.WithBlockTime(start)
+
+	place := func(trader string, side types.Side, price int64, qty string) *types.Order {
+		t.Helper()
+		order, _, err := k.PlaceOrder(ctx, trader, "BTC-USDC", side, types.OrderTypeLimit,
+			math.LegacyNewDec(price), math.LegacyMustNewDecFromStr(qty))
+		if err != nil {
+			t.Fatalf("failed to place order: %v", err)
+		}
+		return order
+	}
+
+	obligation := types.NewLPObligation("maker", "BTC-USDC", math.LegacyOneDec())
+	obligation.MaxSpreadBps = 20 // 100 around a 50000 mid
+	obligation.MinUptime = math.LegacyNewDecWithPrec(5, 1)
+	if err := k.SetLPObligation(ctx, obligation); err != nil {
+		t.Fatalf("failed to set obligation: %v", err)
+	}
+
+	sample := func() {
+		k.LPObligationEndBlocker(ctx)
+		ctx = ctx.WithBlockTime(ctx.BlockTime().Add(time.Second))
+	}
+
+	// One-sided: not quoting
+	place("maker", types.SideBuy, 49950, "1")
+	place("other", types.SideSell, 50050, "5")
+	sample()
+
+	// Ask too wide: mid 50000, ceiling 50100
+	wide := place("maker", types.SideSell, 50200, "1")
+	sample()
+
+	// Two-sided within the spread, split across levels
+	if _, err := k.CancelOrder(ctx, "maker", wide.OrderID); err != nil {
+		t.Fatalf("failed to cancel order: %v", err)
+	}
+	place("maker", types.SideSell, 50060, "0.5")
+	place("maker", types.SideSell, 50080, "0.5")
+	sample()
+	sample()
+
+	epoch := types.LPEpoch(start)
+	uptime := k.GetLPUptime(ctx, "maker", "BTC-USDC", epoch)
+	if uptime.Samples != 4 || uptime.QuotedSamples != 2 {
+		t.Fatalf("expected 2 of 4 samples quoted, got %d of %d", uptime.QuotedSamples, uptime.Samples)
+	}
+	if !uptime.Uptime().Equal(math.LegacyNewDecWithPrec(5, 1)) || !uptime.Met() {
+		t.Errorf("expected 50%% uptime to meet the obligation, got %s", uptime.Uptime())
+	}
+	if met, designated := k.LPObligationMet(ctx, "maker", epoch); !met || !designated {
+		t.Errorf("expected designated maker to meet the obligation, got met=%v designated=%v", met, designated)
+	}
+	if _, designated := k.LPObligationMet(ctx, "other", epoch); designated {
+		t.Error("expected a trader without obligations not to be designated")
+	}
+
+	// The next epoch starts from zero and is missed until sampled
+	next := epoch + 1
+	if met, _ := k.LPObligationMet(ctx, "maker", next); met {
+		t.Error("expected an unsampled epoch not to be met")
+	}
+	ctx = ctx.WithBlockTime(types.LPEpochStart(next))
+	sample()
+	if reports := k.GetLPUptimeReports(ctx, "maker", next); len(reports) != 1 || reports[0].Samples != 1 {
+		t.Errorf("expected one fresh report in the next epoch, got %+v", reports)
+	}
+}
+
+// TestLPObligationValidate tests obligation term validation
+func TestLPObligationValidate(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+
+	testCases := []struct {
+		name   string
+		mutate func(o *types.LPObligation)
+	}{
+		{"zero spread", func(o *types.LPObligation) { o.MaxSpreadBps = 0 }},
+		{"zero quantity", func(o *types.LPObligation) { o.MinQuantity = math.LegacyZeroDec() }},
+		{"uptime above one", func(o *types.LPObligation) { o.MinUptime = math.LegacyNewDec(2) }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := types.NewLPObligation("maker", "BTC-USDC", math.LegacyOneDec())
+			tc.mutate(o)
+			if err := k.SetLPObligation(ctx, o); !errors.Is(err, types.ErrInvalidLPObligation) {
+				t.Errorf("expected ErrInvalidLPObligation, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package keeper
+
+import (
+	"encoding/json"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Store keys for dynamic maker rebates and the maker points program
+var (
+	MakerRebateParamsKey = []byte{0x76}
+	MakerPointsKeyPrefix = []byte{0x77} // trader -> MakerPoints
+)
+
+func makerPointsKey(trader string) []byte {
+	return append(append([]byte{}, MakerPointsKeyPrefix...), trader...)
+}
+
+// ============ Params ============
+
+// GetMakerRebateParams returns the maker rebate params, the default if none
+// are set
+func (k *Keeper) GetMakerRebateParams(ctx sdk.Context) *types.MakerRebateParams {
+	bz := k.GetStore(ctx).Get(MakerRebateParamsKey)
+	if bz == nil {
+		return types.DefaultMakerRebateParams()
+	}
+	var params types.MakerRebateParams
+	if err := json.Unmarshal(bz, &params); err != nil {
+		return types.DefaultMakerRebateParams()
+	}
+	return &params
+}
+
+// SetMakerRebateParams validates and saves the maker rebate params for fills
+// from now on
+func (k *Keeper) SetMakerRebateParams(ctx sdk.Context, params *types.MakerRebateParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	params.UpdatedAt = ctx.BlockTime()
+	k.setMakerRebateParams(ctx, params)
+	return nil
+}
+
+func (k *Keeper) setMakerRebateParams(ctx sdk.Context, params *types.MakerRebateParams) {
+	bz, _ := json.Marshal(params)
+	k.GetStore(ctx).Set(MakerRebateParamsKey, bz)
+}
+
+// ============ Quotes ============
+
+// recordQuoteContext stamps an order about to rest with the best bid and ask
+// it meets, zero for an empty side
+func recordQuoteContext(ctx sdk.Context, order *types.Order, bestBid, bestAsk math.LegacyDec) {
+	order.Quote = &types.QuoteContext{
+		BestBid:  bestBid,
+		BestAsk:  bestAsk,
+		RestedAt: ctx.BlockTime(),
+	}
+}
+
+// ============ Rebates ============
+
+// applyMakerRebate scores a new trade's maker order against the quote context
+// it rested with, takes the rebate off the trade's maker fee and adds the
+// fill to the maker's points. It must run before the trade's fees are
+// recorded, so the ledger sees the rebated maker fee.
+func (k *Keeper) applyMakerRebate(ctx sdk.Context, trade *types.Trade, makerOrder *types.Order) {
+	params := k.GetMakerRebateParams(ctx)
+	if !params.Enabled || trade.Maker == trade.Taker {
+		return
+	}
+	score := params.Score(makerOrder, ctx.BlockTime())
+	if !score.IsPositive() {
+		return
+	}
+
+	notional := trade.Quantity.Mul(trade.Price)
+	rebate := notional.Mul(params.MaxRebateRate).Mul(score)
+	if collected := trade.TakerFee.Add(trade.MakerFee); rebate.GT(collected) {
+		rebate = math.LegacyMaxDec(collected, math.LegacyZeroDec())
+	}
+	trade.MakerRebate = rebate
+	trade.MakerFee = trade.MakerFee.Sub(rebate)
+
+	earned := notional.Mul(score)
+	points := k.GetMakerPoints(ctx, trade.Maker)
+	points.Points = points.Points.Add(earned)
+	points.Rebates = points.Rebates.Add(rebate)
+	points.Fills++
+	points.UpdatedAt = ctx.BlockTime()
+	k.setMakerPoints(ctx, points)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"maker_rebate",
+			sdk.NewAttribute("trade_id", trade.TradeID),
+			sdk.NewAttribute("market_id", trade.MarketID),
+			sdk.NewAttribute("maker", trade.Maker),
+			sdk.NewAttribute("score", score.String()),
+			sdk.NewAttribute("rebate", rebate.String()),
+			sdk.NewAttribute("maker_fee", trade.MakerFee.String()),
+			sdk.NewAttribute("points", earned.String()),
+		),
+	)
+}
+
+// ============ Points ============
+
+// GetMakerPoints returns a trader's maker points, empty if they have none
+func (k *Keeper) GetMakerPoints(ctx sdk.Context, trader string) *types.MakerPoints {
+	bz := k.GetStore(ctx).Get(makerPointsKey(trader))
+	if bz == nil {
+		return types.NewMakerPoints(trader)
+	}
+	var points types.MakerPoints
+	if err := json.Unmarshal(bz, &points); err != nil {
+		return types.NewMakerPoints(trader)
+	}
+	return &points
+}
+
+func (k *Keeper) setMakerPoints(ctx sdk.Context, points *types.MakerPoints) {
+	bz, _ := json.Marshal(points)
+	k.GetStore(ctx).Set(makerPointsKey(points.Trader), bz)
+}
+
+// GetAllMakerPoints returns every trader's maker points, ordered by trader
+func (k *Keeper) GetAllMakerPoints(ctx sdk.Context) []*types.MakerPoints {
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), MakerPointsKeyPrefix)
+	defer iterator.Close()
+
+	all := make([]*types.MakerPoints, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var points types.MakerPoints
+		if err := json.Unmarshal(iterator.Value(), &points); err != nil {
+			continue
+		}
+		all = append(all, &points)
+	}
+	return all
+}
@@ -0,0 +1,107 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestMakerRebate tests that a maker's rebate scales with how far its quote
+// closed the spread and how long it rested, that a flash quote earns
+// nothing, that a rebate never exceeds the trade's fees and that rebates
+// reach the fee ledger and the maker's points
+func TestMakerRebate(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx = ctx.WithBlockTime(start)
+
+	params := types.DefaultMakerRebateParams()
+	params.Enabled = true
+	params.MaxRebateRate = math.LegacyNewDecWithPrec(4, 5) // 0.4 bp
+	params.FullRestDuration = time.Minute
+	params.TightnessWeight = math.LegacyZeroDec()
+	params.MinRestDuration = -time.Second
+	if err := k.SetMakerRebateParams(ctx, params); !errors.Is(err, types.ErrInvalidMakerRebate) {
+		t.Fatalf("expected ErrInvalidMakerRebate, got %v", err)
+	}
+	params.MinRestDuration = time.Second
+	params.TightnessWeight = math.LegacyNewDecWithPrec(5, 1)
+	if err := k.SetMakerRebateParams(ctx, params); err != nil {
+		t.Fatalf("failed to set params: %v", err)
+	}
+
+	place := func(trader string, side types.Side, price int64) *types.Order {
+		t.Helper()
+		order, _, err := k.PlaceOrder(ctx, trader, "BTC-USDC", side, types.OrderTypeLimit, math.LegacyNewDec(price), math.LegacyOneDec())
+		if err != nil {
+			t.Fatalf("failed to place order: %v", err)
+		}
+		return order
+	}
+	take := func() *types.Trade {
+		t.Helper()
+		_, result, err := k.PlaceOrder(ctx, "taker", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(50000), math.LegacyOneDec())
+		if err != nil || len(result.Trades) != 1 {
+			t.Fatalf("expected one trade, got %v (err %v)", result, err)
+		}
+		return result.Trades[0]
+	}
+
+	// A 49900/50100 book: alice's ask at 50000 closes half the spread
+	place("mm", types.SideBuy, 49900)
+	place("mm", types.SideSell, 50100)
+	quoted := place("alice", types.SideSell, 50000)
+	if q := k.GetOrder(ctx, quoted.OrderID).Quote; q == nil || !q.BestBid.Equal(math.LegacyNewDec(49900)) || !q.BestAsk.Equal(math.LegacyNewDec(50100)) || !q.RestedAt.Equal(start) {
+		t.Fatalf("expected the resting order's quote context, got %+v", q)
+	}
+
+	// Half the rest score after 30s: 0.5 × 0.5 + 0.5 × 0.5 = 0.5 of 0.4 bp
+	// on 50000 is a rebate of 1 off the 2.5 maker fee
+	ctx = ctx.WithBlockTime(start.Add(30 * time.Second))
+	trade := take()
+	if !trade.MakerRebate.Equal(math.LegacyOneDec()) || !trade.MakerFee.Equal(math.LegacyNewDecWithPrec(15, 1)) {
+		t.Errorf("expected a rebate of 1 leaving a 1.5 maker fee, got %s and %s", trade.MakerRebate, trade.MakerFee)
+	}
+	points := k.GetMakerPoints(ctx, "alice")
+	if !points.Points.Equal(math.LegacyNewDec(25000)) || !points.Rebates.Equal(math.LegacyOneDec()) || points.Fills != 1 {
+		t.Errorf("expected 25000 points from one rebated fill, got %+v", points)
+	}
+
+	// A quote filled in the block it rested in earns nothing
+	place("alice", types.SideSell, 50000)
+	if trade = take(); trade.MakerRebate.IsPositive() || !trade.MakerFee.Equal(math.LegacyNewDecWithPrec(25, 1)) {
+		t.Errorf("expected no rebate for a flash quote, got %s", trade.MakerRebate)
+	}
+
+	// A full score would pay 500: the rebate stops at the 7.5 the trade
+	// pays, leaving a maker fee of -5 in the ledger
+	params.MaxRebateRate = math.LegacyNewDecWithPrec(1, 2)
+	if err := k.SetMakerRebateParams(ctx, params); err != nil {
+		t.Fatalf("failed to set params: %v", err)
+	}
+	place("alice", types.SideSell, 50000)
+	ctx = ctx.WithBlockTime(start.Add(2 * time.Minute))
+	trade = take()
+	if !trade.MakerRebate.Equal(math.LegacyNewDecWithPrec(75, 1)) || !trade.MakerFee.Equal(math.LegacyNewDec(-5)) {
+		t.Errorf("expected the rebate capped at 7.5, got %s and maker fee %s", trade.MakerRebate, trade.MakerFee)
+	}
+	entry := k.GetFeeEntry(ctx, "BTC-USDC", types.FeeDay(ctx.BlockTime()), trade.TradeID, types.FeeRoleMaker)
+	if entry == nil || !entry.Amount.Equal(math.LegacyNewDec(-5)) {
+		t.Errorf("expected the rebate in the fee ledger, got %+v", entry)
+	}
+
+	exported := k.ExportGenesis(ctx)
+	if exported.MakerRebate == nil || len(exported.MakerPoints) != 1 {
+		t.Fatalf("expected the params and alice's points exported, got %+v %+v", exported.MakerRebate, exported.MakerPoints)
+	}
+	imported, ctx2 := setupBenchKeeper(t)
+	if err := imported.InitGenesis(ctx2, exported); err != nil {
+		t.Fatalf("failed to import genesis: %v", err)
+	}
+	if got := imported.GetMakerPoints(ctx2, "alice"); got.Fills != 2 || !got.Rebates.Equal(math.LegacyNewDecWithPrec(85, 1)) {
+		t.Errorf("expected alice's points back, got %+v", got)
+	}
+}
@@ -0,0 +1,199 @@
+package keeper
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Store keys for the resting order margin re-check
+var (
+	MarginRecheckParamsKey = []byte{0x89}
+	MarginFlagKeyPrefix    = []byte{0x8A} // order ID -> MarginFlag
+)
+
+func marginFlagKey(orderID string) []byte {
+	return append(append([]byte{}, MarginFlagKeyPrefix...), orderID...)
+}
+
+// ============ Config ============
+
+// GetMarginRecheckParams returns the margin re-check params, the default if
+// none are set
+func (k *Keeper) GetMarginRecheckParams(ctx sdk.Context) *types.MarginRecheckParams {
+	bz := k.GetStore(ctx).Get(MarginRecheckParamsKey)
+	if bz == nil {
+		return types.DefaultMarginRecheckParams()
+	}
+	var params types.MarginRecheckParams
+	if err := json.Unmarshal(bz, &params); err != nil {
+		return types.DefaultMarginRecheckParams()
+	}
+	return &params
+}
+
+// SetMarginRecheckParams validates and saves the margin re-check params
+func (k *Keeper) SetMarginRecheckParams(ctx sdk.Context, params *types.MarginRecheckParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	params.UpdatedAt = ctx.BlockTime()
+	k.setMarginRecheckParams(ctx, params)
+	return nil
+}
+
+func (k *Keeper) setMarginRecheckParams(ctx sdk.Context, params *types.MarginRecheckParams) {
+	bz, _ := json.Marshal(params)
+	k.GetStore(ctx).Set(MarginRecheckParamsKey, bz)
+}
+
+// ============ Flags ============
+
+// GetMarginFlag returns an order's margin flag, or nil if it is not flagged
+func (k *Keeper) GetMarginFlag(ctx sdk.Context, orderID string) *types.MarginFlag {
+	bz := k.GetStore(ctx).Get(marginFlagKey(orderID))
+	if bz == nil {
+		return nil
+	}
+	var flag types.MarginFlag
+	if err := json.Unmarshal(bz, &flag); err != nil {
+		return nil
+	}
+	return &flag
+}
+
+// GetAllMarginFlags returns every flagged order's flag, ordered by order ID
+func (k *Keeper) GetAllMarginFlags(ctx sdk.Context) []*types.MarginFlag {
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), MarginFlagKeyPrefix)
+	defer iterator.Close()
+
+	flags := make([]*types.MarginFlag, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var flag types.MarginFlag
+		if err := json.Unmarshal(iterator.Value(), &flag); err != nil {
+			continue
+		}
+		flags = append(flags, &flag)
+	}
+	return flags
+}
+
+func (k *Keeper) setMarginFlag(ctx sdk.Context, flag *types.MarginFlag) {
+	bz, _ := json.Marshal(flag)
+	k.GetStore(ctx).Set(marginFlagKey(flag.OrderID), bz)
+}
+
+// ============ Re-check ============
+
+// MarginRecheckEndBlocker re-checks resting order margin every Interval
+// blocks
+func (k *Keeper) MarginRecheckEndBlocker(ctx sdk.Context) {
+	params := k.GetMarginRecheckParams(ctx)
+	if params.Interval == 0 || ctx.BlockHeight()%params.Interval != 0 {
+		return
+	}
+	k.RecheckOrderMargin(ctx)
+}
+
+// RecheckOrderMargin checks that the owner of every resting order could
+// still place its remaining quantity at the market's mark price. Orders that
+// fail are cancelled or flagged, as the params say, with an
+// order_margin_cancelled or order_margin_flagged event; an order is only
+// flagged once until a re-check passes. Markets without a mark price are
+// skipped.
+func (k *Keeper) RecheckOrderMargin(ctx sdk.Context) *types.MarginRecheckResult {
+	params := k.GetMarginRecheckParams(ctx)
+	result := &types.MarginRecheckResult{
+		Cancelled: make([]*types.Order, 0),
+		Flagged:   make([]*types.MarginFlag, 0),
+	}
+
+	// Flags of orders that have left the book since the last re-check
+	for _, flag := range k.GetAllMarginFlags(ctx) {
+		if order := k.GetOrder(ctx, flag.OrderID); order == nil || !order.IsActive() {
+			k.GetStore(ctx).Delete(marginFlagKey(flag.OrderID))
+		}
+	}
+
+	for _, ob := range k.GetAllOrderBooks(ctx) {
+		mark, ok := k.perpetualKeeper.GetMarkPrice(ctx, ob.MarketID)
+		if !ok || !mark.IsPositive() {
+			continue
+		}
+
+		var uncovered []*types.Order
+		reasons := make(map[string]string)
+		for _, levels := range [][]*types.PriceLevel{ob.Bids, ob.Asks} {
+			for _, level := range levels {
+				for _, orderID := range level.OrderIDs {
+					order := k.GetOrder(ctx, orderID)
+					if order == nil || !order.IsActive() {
+						continue
+					}
+					result.Checked++
+
+					err := k.perpetualKeeper.CheckMarginRequirement(ctx, order.Trader, order.MarketID, order.Side, order.RemainingQty(), mark)
+					if err == nil {
+						k.GetStore(ctx).Delete(marginFlagKey(order.OrderID))
+						continue
+					}
+					if params.Action == types.MarginRecheckActionCancel {
+						uncovered = append(uncovered, order)
+						reasons[order.OrderID] = err.Error()
+						continue
+					}
+					if k.GetMarginFlag(ctx, order.OrderID) != nil {
+						continue
+					}
+					flag := &types.MarginFlag{
+						OrderID:      order.OrderID,
+						Trader:       order.Trader,
+						MarketID:     order.MarketID,
+						MarkPrice:    mark,
+						RemainingQty: order.RemainingQty(),
+						Reason:       err.Error(),
+						FlaggedAt:    ctx.BlockTime(),
+					}
+					k.setMarginFlag(ctx, flag)
+					k.emitMarginRecheckEvent(ctx, "order_margin_flagged", order, mark, flag.Reason)
+					result.Flagged = append(result.Flagged, flag)
+				}
+			}
+		}
+
+		if len(uncovered) == 0 {
+			continue
+		}
+		for _, order := range uncovered {
+			ob.RemoveOrder(order)
+			order.Cancel()
+			k.saveOrderUpdate(ctx, order)
+			k.GetStore(ctx).Delete(marginFlagKey(order.OrderID))
+			k.emitMarginRecheckEvent(ctx, "order_margin_cancelled", order, mark, reasons[order.OrderID])
+			result.Cancelled = append(result.Cancelled, order)
+		}
+		k.SetOrderBook(ctx, ob)
+	}
+	return result
+}
+
+// emitMarginRecheckEvent emits an event for an order that failed a margin
+// re-check
+func (k *Keeper) emitMarginRecheckEvent(ctx sdk.Context, eventType string, order *types.Order, mark math.LegacyDec, reason string) {
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			eventType,
+			sdk.NewAttribute("seq", strconv.FormatUint(order.Seq, 10)),
+			sdk.NewAttribute("order_id", order.OrderID),
+			sdk.NewAttribute("market_id", order.MarketID),
+			sdk.NewAttribute("trader", order.Trader),
+			sdk.NewAttribute("mark_price", mark.String()),
+			sdk.NewAttribute("remaining_qty", order.RemainingQty().String()),
+			sdk.NewAttribute("reason", reason),
+		),
+	)
+}
@@ -0,0 +1,112 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+var errMockMargin = errors.New("mock: notional exceeds budget")
+
+// mockMarginPerpetualKeeper serves bench markets at a settable mark price and
+// covers orders up to a notional budget per trader
+type mockMarginPerpetualKeeper struct {
+	mockBenchPerpetualKeeper
+	mark    math.LegacyDec
+	budgets map[string]math.LegacyDec
+}
+
+func (m *mockMarginPerpetualKeeper) GetMarkPrice(ctx sdk.Context, marketID string) (math.LegacyDec, bool) {
+	return m.mark, true
+}
+
+func (m *mockMarginPerpetualKeeper) CheckMarginRequirement(ctx sdk.Context, trader, marketID string, side types.Side, qty, price interface{}) error {
+	if qty.(math.LegacyDec).Mul(price.(math.LegacyDec)).GT(m.budgets[trader]) {
+		return errMockMargin
+	}
+	return nil
+}
+
+// TestRecheckOrderMargin tests that a mark price move cancels or flags only
+// the resting orders their owners can no longer cover
+func TestRecheckOrderMargin(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	perp := &mockMarginPerpetualKeeper{
+		mark:    math.LegacyNewDec(50000),
+		budgets: map[string]math.LegacyDec{"alice": math.LegacyNewDec(60000), "bob": math.LegacyNewDec(60000)},
+	}
+	k.perpetualKeeper = perp
+
+	aliceOrder, _, err := k.PlaceOrder(ctx, "alice", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(49000), math.LegacyOneDec())
+	if err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+	bobOrder, _, err := k.PlaceOrder(ctx, "bob", "BTC-USDC", types.SideSell, types.OrderTypeLimit, math.LegacyNewDec(51000), math.LegacyNewDecWithPrec(5, 1))
+	if err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+
+	if result := k.RecheckOrderMargin(ctx); result.Checked != 2 || len(result.Cancelled) != 0 {
+		t.Fatalf("expected both orders to pass at the placement mark, got %+v", result)
+	}
+
+	// At 70000, alice's 1 BTC is past her budget; bob's 0.5 is not
+	perp.mark = math.LegacyNewDec(70000)
+	if err := k.SetMarginRecheckParams(ctx, &types.MarginRecheckParams{Interval: 1, Action: types.MarginRecheckActionFlag}); err != nil {
+		t.Fatalf("failed to set params: %v", err)
+	}
+	result := k.RecheckOrderMargin(ctx)
+	if len(result.Flagged) != 1 || result.Flagged[0].OrderID != aliceOrder.OrderID || len(result.Cancelled) != 0 {
+		t.Fatalf("expected alice's order to be flagged, got %+v", result)
+	}
+	if !k.GetOrder(ctx, aliceOrder.OrderID).IsActive() {
+		t.Error("expected a flagged order to stay on the book")
+	}
+	if result := k.RecheckOrderMargin(ctx); len(result.Flagged) != 0 || len(k.GetAllMarginFlags(ctx)) != 1 {
+		t.Errorf("expected an order to be flagged only once, got %+v", result)
+	}
+
+	// The flag clears once the order is covered again
+	perp.mark = math.LegacyNewDec(50000)
+	k.RecheckOrderMargin(ctx)
+	if flag := k.GetMarginFlag(ctx, aliceOrder.OrderID); flag != nil {
+		t.Errorf("expected the flag to clear, got %+v", flag)
+	}
+
+	// In cancel mode the EndBlocker takes the uncovered order off the book
+	perp.mark = math.LegacyNewDec(70000)
+	if err := k.SetMarginRecheckParams(ctx, &types.MarginRecheckParams{Interval: 5, Action: types.MarginRecheckActionCancel}); err != nil {
+		t.Fatalf("failed to set params: %v", err)
+	}
+	k.MarginRecheckEndBlocker(ctx.WithBlockHeight(7))
+	if !k.GetOrder(ctx, aliceOrder.OrderID).IsActive() {
+		t.Fatal("expected no re-check off the interval")
+	}
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	k.MarginRecheckEndBlocker(ctx.WithBlockHeight(10))
+	if order := k.GetOrder(ctx, aliceOrder.OrderID); order.Status != types.OrderStatusCancelled {
+		t.Errorf("expected alice's order to be cancelled, got %s", order.Status)
+	}
+	if !k.GetOrder(ctx, bobOrder.OrderID).IsActive() {
+		t.Error("expected bob's covered order to stay")
+	}
+	if bid := k.GetOrderBook(ctx, "BTC-USDC").BestBid(); bid != nil {
+		t.Errorf("expected the cancelled order to leave the book, got %+v", bid)
+	}
+	var cancelled int
+	for _, event := range ctx.EventManager().Events() {
+		if event.Type == "order_margin_cancelled" {
+			cancelled++
+		}
+	}
+	if cancelled != 1 {
+		t.Errorf("expected one order_margin_cancelled event, got %d", cancelled)
+	}
+
+	if err := k.SetMarginRecheckParams(ctx, &types.MarginRecheckParams{Interval: 1, Action: "close"}); !errors.Is(err, types.ErrInvalidMarginRecheck) {
+		t.Errorf("expected an unknown action to be rejected, got %v", err)
+	}
+}
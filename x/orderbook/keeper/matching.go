@@ -35,6 +35,8 @@ type MatchResult struct {
 	FilledQty    math.LegacyDec
 	AvgPrice     math.LegacyDec
 	RemainingQty math.LegacyDec
+	MMPTriggered []string // makers whose market maker protection tripped during the match
+	PriceCapped  bool     // a market order stopped at its price cap with the book beyond it
 }
 
 // Match attempts to match an incoming order against the order book
@@ -63,6 +65,9 @@ func (me *MatchingEngine) Match(ctx sdk.Context, order *types.Order) (*MatchResu
 	// Track total value for average price calculation
 	totalValue := math.LegacyZeroDec()
 
+	// Makers whose protection tripped are not filled further; ProcessOrder cancels their quotes
+	tripped := make(map[string]bool)
+
 	// Match against each price level
 	for _, level := range oppositeLevels {
 		if result.RemainingQty.IsZero() {
@@ -71,17 +76,19 @@ func (me *MatchingEngine) Match(ctx sdk.Context, order *types.Order) (*MatchResu
 
 		// Check price compatibility
 		if !me.isPriceCompatible(order, level.Price) {
+			result.PriceCapped = order.OrderType == types.OrderTypeMarket
 			break
 		}
 
-		// Match against orders at this price level (FIFO)
-		for _, makerOrderID := range level.OrderIDs {
+		// Match against orders at this price level (FIFO). Filled makers are
+		// removed from the level as we go, so iterate over a copy of the IDs.
+		for _, makerOrderID := range append([]string(nil), level.OrderIDs...) {
 			if result.RemainingQty.IsZero() {
 				break
 			}
 
 			makerOrder := me.keeper.GetOrder(ctx, makerOrderID)
-			if makerOrder == nil || !makerOrder.IsActive() {
+			if makerOrder == nil || !makerOrder.IsActive() || tripped[makerOrder.Trader] {
 				continue
 			}
 
@@ -97,6 +104,9 @@ func (me *MatchingEngine) Match(ctx sdk.Context, order *types.Order) (*MatchResu
 			// Create trade
 			tradeID := me.keeper.generateTradeID(ctx)
 			trade := types.NewTrade(tradeID, order.MarketID, order, makerOrder, matchPrice, matchQty, takerFee, makerFee)
+			me.keeper.sequenceTrade(ctx, trade)
+			me.keeper.applyMakerRebate(ctx, trade, makerOrder)
+			me.keeper.recordTradeFees(ctx, trade)
 			result.Trades = append(result.Trades, trade)
 
 			// Update quantities
@@ -109,12 +119,16 @@ func (me *MatchingEngine) Match(ctx sdk.Context, order *types.Order) (*MatchResu
 
 			// Update positions for both traders (CRITICAL: creates real positions)
 			// Taker: order.Side determines position direction (buy=long, sell=short)
-			if err := me.keeper.perpetualKeeper.UpdatePosition(ctx, order.Trader, order.MarketID, order.Side, matchQty, matchPrice, takerFee); err != nil {
+			if err := me.keeper.perpetualKeeper.UpdatePosition(ctx, order.Trader, order.MarketID, order.Side, matchQty, matchPrice, trade.TakerFee); err != nil {
 				me.keeper.Logger().Error("failed to update taker position", "trader", order.Trader, "error", err)
+			} else {
+				me.keeper.afterPositionChanged(ctx, order.Trader, order.MarketID)
 			}
 			// Maker: makerOrder.Side determines position direction
-			if err := me.keeper.perpetualKeeper.UpdatePosition(ctx, makerOrder.Trader, makerOrder.MarketID, makerOrder.Side, matchQty, matchPrice, makerFee); err != nil {
+			if err := me.keeper.perpetualKeeper.UpdatePosition(ctx, makerOrder.Trader, makerOrder.MarketID, makerOrder.Side, matchQty, matchPrice, trade.MakerFee); err != nil {
 				me.keeper.Logger().Error("failed to update maker position", "trader", makerOrder.Trader, "error", err)
+			} else {
+				me.keeper.afterPositionChanged(ctx, makerOrder.Trader, makerOrder.MarketID)
 			}
 
 			// Update tracking
@@ -123,7 +137,7 @@ func (me *MatchingEngine) Match(ctx sdk.Context, order *types.Order) (*MatchResu
 			totalValue = totalValue.Add(matchQty.Mul(matchPrice))
 
 			// Save updated maker order
-			me.keeper.SetOrder(ctx, makerOrder)
+			me.keeper.saveOrderUpdate(ctx, makerOrder)
 
 			// Update order book
 			level.Quantity = level.Quantity.Sub(matchQty)
@@ -131,8 +145,16 @@ func (me *MatchingEngine) Match(ctx sdk.Context, order *types.Order) (*MatchResu
 				level.RemoveOrder(makerOrderID, math.LegacyZeroDec())
 			}
 
-			// Emit trade event
+			// Store the trade, emit its event and notify the trade hooks
+			me.keeper.SetTrade(ctx, trade)
 			me.keeper.emitTradeEvent(ctx, trade)
+			me.keeper.afterTradeExecuted(ctx, trade)
+
+			// Count the fill against the maker's market maker protection
+			if me.keeper.recordMMPFill(ctx, trade, makerOrder.Side) {
+				tripped[makerOrder.Trader] = true
+				result.MMPTriggered = append(result.MMPTriggered, makerOrder.Trader)
+			}
 		}
 	}
 
@@ -150,9 +172,9 @@ func (me *MatchingEngine) Match(ctx sdk.Context, order *types.Order) (*MatchResu
 
 // isPriceCompatible checks if the order can match at the given price
 func (me *MatchingEngine) isPriceCompatible(order *types.Order, levelPrice math.LegacyDec) bool {
-	// Market orders match at any price
+	// Market orders match at any price up to their slippage cap
 	if order.OrderType == types.OrderTypeMarket {
-		return true
+		return order.WithinPriceCap(levelPrice)
 	}
 
 	// Limit orders: buy must be >= ask, sell must be <= bid
@@ -206,20 +228,50 @@ func (me *MatchingEngine) ProcessOrder(ctx sdk.Context, order *types.Order) (*Ma
 		if orderBook == nil {
 			orderBook = types.NewOrderBook(order.MarketID)
 		}
+		bestBid, bestAsk := math.LegacyZeroDec(), math.LegacyZeroDec()
+		if level := orderBook.BestBid(); level != nil {
+			bestBid = level.Price
+		}
+		if level := orderBook.BestAsk(); level != nil {
+			bestAsk = level.Price
+		}
+		recordQuoteContext(ctx, order, bestBid, bestAsk)
 		orderBook.AddOrder(order)
 		me.keeper.SetOrderBook(ctx, orderBook)
-		me.keeper.SetOrder(ctx, order)
 	} else if order.IsActive() && order.OrderType == types.OrderTypeMarket {
-		// Market order with unfilled quantity - cancel the rest
-		order.Cancel()
+		// Market order with unfilled quantity - cancel the rest, marking
+		// a stop at the price cap apart from running out of liquidity
+		if result.PriceCapped {
+			order.CancelAtPriceCap()
+		} else {
+			order.Cancel()
+		}
 	}
 
 	// Save the taker order
-	me.keeper.SetOrder(ctx, order)
+	me.keeper.saveOrderUpdate(ctx, order)
+
+	// Pull the remaining quotes of makers whose protection tripped
+	for _, trader := range result.MMPTriggered {
+		me.cancelMMPOrders(ctx, trader, order.MarketID)
+	}
 
 	return result, nil
 }
 
+// cancelMMPOrders cancels a trader's active orders in a market after their
+// market maker protection tripped
+func (me *MatchingEngine) cancelMMPOrders(ctx sdk.Context, trader, marketID string) {
+	for _, o := range me.keeper.GetOrdersByTrader(ctx, trader) {
+		if o.MarketID != marketID || !o.IsActive() {
+			continue
+		}
+		if _, err := me.CancelOrder(ctx, o.OrderID); err != nil {
+			me.keeper.Logger().Error("failed to cancel order after MMP trigger", "order_id", o.OrderID, "error", err)
+		}
+	}
+}
+
 // CancelOrder cancels an order and removes it from the order book
 func (me *MatchingEngine) CancelOrder(ctx sdk.Context, orderID string) (*types.Order, error) {
 	order := me.keeper.GetOrder(ctx, orderID)
@@ -240,11 +292,41 @@ func (me *MatchingEngine) CancelOrder(ctx sdk.Context, orderID string) (*types.O
 
 	// Cancel the order
 	order.Cancel()
-	me.keeper.SetOrder(ctx, order)
+	me.keeper.saveOrderUpdate(ctx, order)
 
 	return order, nil
 }
 
+// AmendOrder changes the price and total quantity of a resting limit order.
+// Reducing the quantity at the same price updates the order where it sits in
+// its price level queue, so it keeps its time priority, and returns a nil
+// result. Any other change takes the order off the book and processes it
+// again: it may match at the new price and rests at the back of the queue.
+func (me *MatchingEngine) AmendOrder(ctx sdk.Context, order *types.Order, price, quantity math.LegacyDec) (*MatchResult, error) {
+	orderBook := me.keeper.GetOrderBook(ctx, order.MarketID)
+	if orderBook == nil {
+		orderBook = types.NewOrderBook(order.MarketID)
+	}
+
+	if price.Equal(order.Price) && quantity.LTE(order.Quantity) {
+		orderBook.ReduceOrder(order, order.Quantity.Sub(quantity))
+		me.keeper.SetOrderBook(ctx, orderBook)
+
+		order.Quantity = quantity
+		order.UpdatedAt = time.Now()
+		me.keeper.saveOrderUpdate(ctx, order)
+		return nil, nil
+	}
+
+	orderBook.RemoveOrder(order)
+	me.keeper.SetOrderBook(ctx, orderBook)
+
+	order.Price = price
+	order.Quantity = quantity
+	order.UpdatedAt = time.Now()
+	return me.ProcessOrder(ctx, order)
+}
+
 // ProcessPendingOrders processes all pending orders and returns performance statistics
 // This is the optimized entry point for EndBlocker order matching
 func (me *MatchingEngine) ProcessPendingOrders(ctx sdk.Context) MatchingStats {
@@ -291,13 +373,10 @@ func (me *MatchingEngine) ProcessPendingOrders(ctx sdk.Context) MatchingStats {
 		if result != nil {
 			stats.TradesExecuted += len(result.Trades)
 
-			// Calculate volume from trades
+			// Calculate volume from trades; Match has stored them
 			for _, trade := range result.Trades {
 				tradeValue := trade.Quantity.Mul(trade.Price)
 				stats.TotalVolume = stats.TotalVolume.Add(tradeValue)
-
-				// Save trade to store
-				me.keeper.SetTrade(ctx, trade)
 			}
 		}
 	}
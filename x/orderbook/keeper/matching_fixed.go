@@ -0,0 +1,101 @@
+package keeper
+
+import (
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// fixedMatch is the integer state of one MatchingEngineV2.Match call. Prices
+// and sizes are int64 at the market's decimals and the notional is a Uint128
+// at their sum, which LegacyDec holds exactly, so results are identical to the
+// Dec path. Dec values are only built at the boundary: trade quantities and
+// fees, and the match result once matching ends.
+type fixedMatch struct {
+	precision types.Precision
+	limit     int64 // taker limit price, or the price cap of a market order
+	unbounded bool  // an uncapped market order, which ignores limit
+	remaining int64
+	filled    int64
+	notional  types.Uint128 // Σ price × size
+}
+
+// newFixedMatch returns the fixed-point state for matching order in market,
+// or nil if the market has no precision or the order is not representable
+func newFixedMatch(market *Market, order *types.Order) *fixedMatch {
+	precision := types.Precision{PriceDecimals: market.PriceDecimals, SizeDecimals: market.SizeDecimals}
+	if precision == (types.Precision{}) || !precision.Valid() {
+		return nil
+	}
+	remaining, ok := types.ToFixed(order.RemainingQty(), precision.SizeDecimals)
+	if !ok || remaining < 0 {
+		return nil
+	}
+	fm := &fixedMatch{precision: precision, remaining: remaining}
+	limit := order.Price
+	if order.OrderType == types.OrderTypeMarket {
+		if !order.HasPriceCap() {
+			fm.unbounded = true
+			return fm
+		}
+		limit = order.PriceCap
+	}
+	if fm.limit, ok = types.ToFixed(limit, precision.PriceDecimals); !ok {
+		return nil
+	}
+	return fm
+}
+
+// priceCompatible mirrors isPriceCompatible on a fixed-point level price
+func (fm *fixedMatch) priceCompatible(order *types.Order, levelPrice int64) bool {
+	if fm.unbounded {
+		return true
+	}
+	if order.Side == types.SideBuy {
+		return fm.limit >= levelPrice
+	}
+	return fm.limit <= levelPrice
+}
+
+// matchQty returns the quantity to fill against a maker. ok is false if the
+// maker's remaining quantity is not representable.
+func (fm *fixedMatch) matchQty(maker *types.Order) (int64, bool) {
+	makerQty, ok := types.ToFixed(maker.RemainingQty(), fm.precision.SizeDecimals)
+	if !ok || makerQty < 0 {
+		return 0, false
+	}
+	return min(fm.remaining, makerQty), true
+}
+
+// record adds a fill and returns its notional as a Dec. ok is false, with the
+// state unchanged, for a negative price or if the running notional would
+// overflow.
+func (fm *fixedMatch) record(price, qty int64) (math.LegacyDec, bool) {
+	if price < 0 {
+		return math.LegacyDec{}, false
+	}
+	value := types.MulUint64(uint64(price), uint64(qty))
+	notional, ok := fm.notional.Add(value)
+	if !ok {
+		return math.LegacyDec{}, false
+	}
+	fm.notional = notional
+	fm.remaining -= qty
+	fm.filled += qty
+	return value.ToDec(fm.notionalDecimals()), true
+}
+
+// sizeDec converts a fixed-point size to a Dec
+func (fm *fixedMatch) sizeDec(qty int64) math.LegacyDec {
+	return types.FromFixed(qty, fm.precision.SizeDecimals)
+}
+
+func (fm *fixedMatch) notionalDecimals() uint32 {
+	return fm.precision.PriceDecimals + fm.precision.SizeDecimals
+}
+
+// flush writes the fixed-point state into the Dec tracking of the match
+func (fm *fixedMatch) flush(result *MatchResultV2, totalValue *math.LegacyDec) {
+	result.FilledQty = fm.sizeDec(fm.filled)
+	result.RemainingQty = fm.sizeDec(fm.remaining)
+	*totalValue = fm.notional.ToDec(fm.notionalDecimals())
+}
@@ -0,0 +1,191 @@
+package keeper
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// mockPrecisionPerpetualKeeper serves bench markets with matching decimals
+type mockPrecisionPerpetualKeeper struct {
+	mockBenchPerpetualKeeper
+	priceDecimals, sizeDecimals uint32
+}
+
+func (m *mockPrecisionPerpetualKeeper) GetMarket(ctx sdk.Context, marketID string) *Market {
+	market := m.mockBenchPerpetualKeeper.GetMarket(ctx, marketID)
+	market.PriceDecimals, market.SizeDecimals = m.priceDecimals, m.sizeDecimals
+	return market
+}
+
+// setupPrecisionKeeper sets up a bench keeper whose markets match in fixed
+// point at the given decimals; zero decimals match on Dec
+func setupPrecisionKeeper(tb testing.TB, priceDecimals, sizeDecimals uint32) (*Keeper, sdk.Context) {
+	tb.Helper()
+	k, ctx := setupBenchKeeper(tb)
+	k.perpetualKeeper = &mockPrecisionPerpetualKeeper{priceDecimals: priceDecimals, sizeDecimals: sizeDecimals}
+	return k, ctx
+}
+
+// generatePrecisionOrders generates a reproducible crossing order flow at 2
+// price and 3 size decimals, with every oddEvery-th order carrying a 6-decimal
+// size (0 for none)
+func generatePrecisionOrders(seed int64, n, oddEvery int) []*types.Order {
+	r := rand.New(rand.NewSource(seed))
+	orders := make([]*types.Order, n)
+	for i := range orders {
+		side := types.SideBuy
+		if r.Intn(2) == 0 {
+			side = types.SideSell
+		}
+		orderType := types.OrderTypeLimit
+		if r.Intn(10) == 0 {
+			orderType = types.OrderTypeMarket
+		}
+		price := math.LegacyNewDecWithPrec(4995000+r.Int63n(10000), 2)
+		quantity := math.LegacyNewDecWithPrec(1+r.Int63n(5000), 3)
+		if oddEvery > 0 && i%oddEvery == oddEvery-1 {
+			quantity = math.LegacyNewDecWithPrec(1+r.Int63n(5000000), 6)
+		}
+		orders[i] = types.NewOrder(fmt.Sprintf("order-%d", i), fmt.Sprintf("trader-%d", i%7),
+			"BTC-USDC", side, orderType, price, quantity)
+	}
+	return orders
+}
+
+// cloneOrder returns an independent copy of an unfilled order
+func cloneOrder(o *types.Order) *types.Order {
+	return types.NewOrder(o.OrderID, o.Trader, o.MarketID, o.Side, o.OrderType, o.Price, o.Quantity)
+}
+
+// TestFixedMatchEqualsDec tests that fixed-point matching produces exactly the
+// trades and results of Dec matching, including when an order the precision
+// cannot represent forces a fallback mid-match
+func TestFixedMatchEqualsDec(t *testing.T) {
+	testCases := []struct {
+		name     string
+		oddEvery int
+	}{
+		{"representable", 0},
+		{"fallback", 9},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 5; seed++ {
+				decKeeper, decCtx := setupPrecisionKeeper(t, 0, 0)
+				fixedKeeper, fixedCtx := setupPrecisionKeeper(t, 2, 4)
+				decEngine := NewMatchingEngineV2(decKeeper)
+				fixedEngine := NewMatchingEngineV2(fixedKeeper)
+
+				for i, order := range generatePrecisionOrders(seed, 400, tc.oddEvery) {
+					want, err := decEngine.ProcessOrderOptimized(decCtx, cloneOrder(order))
+					if err != nil {
+						t.Fatalf("seed %d order %d: dec match failed: %v", seed, i, err)
+					}
+					got, err := fixedEngine.ProcessOrderOptimized(fixedCtx, cloneOrder(order))
+					if err != nil {
+						t.Fatalf("seed %d order %d: fixed match failed: %v", seed, i, err)
+					}
+					assertSameMatch(t, fmt.Sprintf("seed %d order %d", seed, i), want, got)
+				}
+			}
+		})
+	}
+}
+
+func assertSameMatch(t *testing.T, label string, want, got *MatchResultV2) {
+	t.Helper()
+	if len(got.Trades) != len(want.Trades) {
+		t.Fatalf("%s: expected %d trades, got %d", label, len(want.Trades), len(got.Trades))
+	}
+	for i, w := range want.Trades {
+		g := got.Trades[i]
+		if g.MakerOrderID != w.MakerOrderID || !g.Price.Equal(w.Price) || !g.Quantity.Equal(w.Quantity) ||
+			!g.TakerFee.Equal(w.TakerFee) || !g.MakerFee.Equal(w.MakerFee) {
+			t.Fatalf("%s: trade %d differs: expected %s %s@%s fees %s/%s, got %s %s@%s fees %s/%s", label, i,
+				w.MakerOrderID, w.Quantity, w.Price, w.TakerFee, w.MakerFee,
+				g.MakerOrderID, g.Quantity, g.Price, g.TakerFee, g.MakerFee)
+		}
+	}
+	if !got.FilledQty.Equal(want.FilledQty) || !got.RemainingQty.Equal(want.RemainingQty) || !got.AvgPrice.Equal(want.AvgPrice) {
+		t.Fatalf("%s: expected filled %s remaining %s avg %s, got %s %s %s", label,
+			want.FilledQty, want.RemainingQty, want.AvgPrice, got.FilledQty, got.RemainingQty, got.AvgPrice)
+	}
+}
+
+// TestNewFixedMatch tests when the fast path applies
+func TestNewFixedMatch(t *testing.T) {
+	limit := types.NewOrder("o1", "trader", "BTC-USDC", types.SideBuy, types.OrderTypeLimit,
+		math.LegacyMustNewDecFromStr("50000.25"), math.LegacyMustNewDecFromStr("1.5"))
+
+	testCases := []struct {
+		name   string
+		market Market
+		order  *types.Order
+		ok     bool
+	}{
+		{"representable", Market{PriceDecimals: 2, SizeDecimals: 4}, limit, true},
+		{"no precision", Market{}, limit, false},
+		{"beyond Dec precision", Market{PriceDecimals: 10, SizeDecimals: 9}, limit, false},
+		{"price not representable", Market{PriceDecimals: 1, SizeDecimals: 4}, limit, false},
+		{"size not representable", Market{PriceDecimals: 2, SizeDecimals: 0}, limit, false},
+		{"market order ignores price", Market{PriceDecimals: 0, SizeDecimals: 1},
+			types.NewOrder("o2", "trader", "BTC-USDC", types.SideBuy, types.OrderTypeMarket,
+				math.LegacyZeroDec(), math.LegacyMustNewDecFromStr("1.5")), true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := newFixedMatch(&tc.market, tc.order) != nil; got != tc.ok {
+				t.Errorf("expected fast path %v, got %v", tc.ok, got)
+			}
+		})
+	}
+}
+
+// benchmarkMatchSweep benchmarks one market order sweeping a 50-level book
+// of 4 makers per level
+func benchmarkMatchSweep(b *testing.B, priceDecimals, sizeDecimals uint32) {
+	k, ctx := setupPrecisionKeeper(b, priceDecimals, sizeDecimals)
+	makers := make([]*types.Order, 0, 200)
+	total := math.LegacyZeroDec()
+	for level := int64(0); level < 50; level++ {
+		for i := int64(0); i < 4; i++ {
+			qty := math.LegacyNewDecWithPrec(1000+level*10+i, 3)
+			makers = append(makers, types.NewOrder(fmt.Sprintf("maker-%d-%d", level, i), fmt.Sprintf("maker-%d", i),
+				"BTC-USDC", types.SideSell, types.OrderTypeLimit, math.LegacyNewDecWithPrec(5000000+level*25, 2), qty))
+			total = total.Add(qty)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		engine := NewMatchingEngineV2(k)
+		ob := engine.GetOrderBookV2(ctx, "BTC-USDC")
+		for _, maker := range makers {
+			ob.AddOrder(cloneOrder(maker))
+		}
+		taker := types.NewOrder(fmt.Sprintf("taker-%d", n), "taker", "BTC-USDC", types.SideBuy,
+			types.OrderTypeMarket, math.LegacyZeroDec(), total)
+		b.StartTimer()
+
+		if _, err := engine.Match(ctx, taker); err != nil {
+			b.Fatalf("match failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkMatchSweepDec benchmarks matching on LegacyDec arithmetic
+func BenchmarkMatchSweepDec(b *testing.B) {
+	benchmarkMatchSweep(b, 0, 0)
+}
+
+// BenchmarkMatchSweepFixed benchmarks matching on the fixed-point fast path
+func BenchmarkMatchSweepFixed(b *testing.B) {
+	benchmarkMatchSweep(b, 2, 3)
+}
@@ -0,0 +1,696 @@
+package keeper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Matching engine fuzzing
+//
+// FuzzMatchingEngine decodes random bytes into order sequences and replays them
+// against MatchingEngineV2 and the V1 MatchingEngine, each next to a reference
+// book, checking after every operation that:
+//   - trades follow price-time priority (best price first, FIFO within a level)
+//   - the book is never crossed after matching
+//   - filled quantity is conserved between the taker and its makers
+//   - every level's quantity equals the remaining quantity of its orders
+//
+// Failing sequences are minimized before being reported. Set
+// MATCHING_FUZZ_WRITE_FIXTURES=1 to also write them to testdata/matching, where
+// TestMatchingFixtures replays them on every run:
+//
+//	MATCHING_FUZZ_WRITE_FIXTURES=1 go test ./x/orderbook/keeper -run '^$' -fuzz FuzzMatchingEngine
+
+const (
+	fuzzMarketID     = "BTC-USDC"
+	fuzzTrader       = "cosmos1fuzz"
+	fuzzBasePrice    = 100
+	fuzzPriceTicks   = 16
+	fuzzMaxQty       = 16
+	fuzzMaxOps       = 200
+	fuzzFixtureDir   = "testdata/matching"
+	fuzzFixturesEnv  = "MATCHING_FUZZ_WRITE_FIXTURES"
+	matchingOpLimit  = "limit"
+	matchingOpMarket = "market"
+	matchingOpCancel = "cancel"
+)
+
+// matchingOp is a single replayable operation. Quantities are in tenths.
+type matchingOp struct {
+	Op    string `json:"op"`
+	ID    int    `json:"id"`
+	Side  string `json:"side,omitempty"`
+	Price int64  `json:"price,omitempty"`
+	Qty   int64  `json:"qty,omitempty"`
+}
+
+// matchingFixture is a replayable operation sequence stored under testdata/matching
+type matchingFixture struct {
+	Name  string       `json:"name"`
+	Error string       `json:"error,omitempty"`
+	Ops   []matchingOp `json:"ops"`
+}
+
+// decodeMatchingOps turns fuzz input into operations, three bytes per operation
+func decodeMatchingOps(data []byte) []matchingOp {
+	ops := make([]matchingOp, 0, len(data)/3)
+	nextID := 0
+	for len(data) >= 3 && len(ops) < fuzzMaxOps {
+		b0, b1, b2 := data[0], data[1], data[2]
+		data = data[3:]
+
+		side := "buy"
+		if b0&0x80 != 0 {
+			side = "sell"
+		}
+		qty := 1 + int64(b2%fuzzMaxQty)
+
+		switch b0 % 8 {
+		case 6, 7:
+			if nextID == 0 {
+				continue
+			}
+			ops = append(ops, matchingOp{Op: matchingOpCancel, ID: 1 + int(b1)%nextID})
+		case 5:
+			nextID++
+			ops = append(ops, matchingOp{Op: matchingOpMarket, ID: nextID, Side: side, Qty: qty})
+		default:
+			nextID++
+			price := fuzzBasePrice - fuzzPriceTicks/2 + int64(b1%fuzzPriceTicks)
+			ops = append(ops, matchingOp{Op: matchingOpLimit, ID: nextID, Side: side, Price: price, Qty: qty})
+		}
+	}
+	return ops
+}
+
+// modelOrder is a resting order in the reference book
+type modelOrder struct {
+	id        string
+	price     math.LegacyDec
+	remaining math.LegacyDec
+}
+
+// modelFill is a fill the reference book expects the engine to produce
+type modelFill struct {
+	makerID string
+	price   math.LegacyDec
+	qty     math.LegacyDec
+}
+
+// matchingModel is a straightforward reference book. Each side is kept as a
+// single slice in priority order, so price-time priority is simply slice order.
+type matchingModel struct {
+	bids []*modelOrder
+	asks []*modelOrder
+}
+
+func (m *matchingModel) side(side types.Side) *[]*modelOrder {
+	if side == types.SideBuy {
+		return &m.bids
+	}
+	return &m.asks
+}
+
+// match fills an incoming order against the opposite side and returns the fills
+func (m *matchingModel) match(side types.Side, limit *math.LegacyDec, qty math.LegacyDec) []modelFill {
+	opposite := m.side(types.SideSell)
+	if side == types.SideSell {
+		opposite = m.side(types.SideBuy)
+	}
+
+	var fills []modelFill
+	remaining := qty
+	for len(*opposite) > 0 && remaining.IsPositive() {
+		maker := (*opposite)[0]
+		if limit != nil {
+			if side == types.SideBuy && limit.LT(maker.price) {
+				break
+			}
+			if side == types.SideSell && limit.GT(maker.price) {
+				break
+			}
+		}
+
+		fillQty := math.LegacyMinDec(remaining, maker.remaining)
+		fills = append(fills, modelFill{makerID: maker.id, price: maker.price, qty: fillQty})
+		remaining = remaining.Sub(fillQty)
+		maker.remaining = maker.remaining.Sub(fillQty)
+		if maker.remaining.IsZero() {
+			*opposite = (*opposite)[1:]
+		}
+	}
+	return fills
+}
+
+// rest adds an order behind every order at the same or a better price
+func (m *matchingModel) rest(side types.Side, order *modelOrder) {
+	orders := m.side(side)
+	i := sort.Search(len(*orders), func(i int) bool {
+		if side == types.SideBuy {
+			return (*orders)[i].price.LT(order.price)
+		}
+		return (*orders)[i].price.GT(order.price)
+	})
+	*orders = append(*orders, nil)
+	copy((*orders)[i+1:], (*orders)[i:])
+	(*orders)[i] = order
+}
+
+// remove removes a resting order, returning false if it is not on the book
+func (m *matchingModel) remove(id string) bool {
+	for _, orders := range []*[]*modelOrder{&m.bids, &m.asks} {
+		for i, o := range *orders {
+			if o.id == id {
+				*orders = append((*orders)[:i], (*orders)[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fuzzResult is what an engine reports for a processed order
+type fuzzResult struct {
+	trades    []*types.Trade
+	filled    math.LegacyDec
+	remaining math.LegacyDec
+}
+
+// fuzzLevel is a price level of an engine's book with its orders in FIFO order
+type fuzzLevel struct {
+	price    math.LegacyDec
+	quantity math.LegacyDec
+	orders   []*types.Order
+}
+
+// fuzzEngine adapts a matching engine to the harness
+type fuzzEngine interface {
+	process(ctx sdk.Context, order *types.Order) (*fuzzResult, error)
+	cancel(ctx sdk.Context, orderID string) (*types.Order, error)
+	// order returns the engine's current copy of an order
+	order(ctx sdk.Context, orderID string) *types.Order
+	// book returns the levels of each side, best price first
+	book(ctx sdk.Context) (bids, asks []fuzzLevel)
+	flush(ctx sdk.Context) error
+}
+
+// fuzzEngineV2 runs the harness on MatchingEngineV2 and its order book cache
+type fuzzEngineV2 struct {
+	keeper *Keeper
+	engine *MatchingEngineV2
+}
+
+func (e *fuzzEngineV2) process(ctx sdk.Context, order *types.Order) (*fuzzResult, error) {
+	result, err := e.engine.ProcessOrderOptimized(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	return &fuzzResult{trades: result.Trades, filled: result.FilledQty, remaining: result.RemainingQty}, nil
+}
+
+func (e *fuzzEngineV2) cancel(ctx sdk.Context, orderID string) (*types.Order, error) {
+	return e.engine.CancelOrderOptimized(ctx, orderID)
+}
+
+func (e *fuzzEngineV2) order(ctx sdk.Context, orderID string) *types.Order {
+	return e.engine.GetCache().GetOrder(ctx, e.keeper, orderID)
+}
+
+func (e *fuzzEngineV2) book(ctx sdk.Context) (bids, asks []fuzzLevel) {
+	ob := e.engine.GetOrderBookV2(ctx, fuzzMarketID)
+	ob.IterateBids(func(level *PriceLevelV2) bool {
+		bids = append(bids, fuzzLevel{price: level.Price, quantity: level.Quantity, orders: level.Orders})
+		return true
+	})
+	ob.IterateAsks(func(level *PriceLevelV2) bool {
+		asks = append(asks, fuzzLevel{price: level.Price, quantity: level.Quantity, orders: level.Orders})
+		return true
+	})
+	return bids, asks
+}
+
+func (e *fuzzEngineV2) flush(ctx sdk.Context) error {
+	return e.engine.Flush(ctx)
+}
+
+// fuzzEngineV1 runs the harness on the store-backed MatchingEngine that keeper
+// PlaceOrder and AmendOrder use
+type fuzzEngineV1 struct {
+	keeper *Keeper
+	engine *MatchingEngine
+}
+
+func (e *fuzzEngineV1) process(ctx sdk.Context, order *types.Order) (*fuzzResult, error) {
+	result, err := e.engine.ProcessOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	return &fuzzResult{trades: result.Trades, filled: result.FilledQty, remaining: result.RemainingQty}, nil
+}
+
+func (e *fuzzEngineV1) cancel(ctx sdk.Context, orderID string) (*types.Order, error) {
+	return e.engine.CancelOrder(ctx, orderID)
+}
+
+func (e *fuzzEngineV1) order(ctx sdk.Context, orderID string) *types.Order {
+	return e.keeper.GetOrder(ctx, orderID)
+}
+
+func (e *fuzzEngineV1) book(ctx sdk.Context) (bids, asks []fuzzLevel) {
+	ob := e.keeper.GetOrderBook(ctx, fuzzMarketID)
+	if ob == nil {
+		return nil, nil
+	}
+	levels := func(side []*types.PriceLevel) []fuzzLevel {
+		out := make([]fuzzLevel, 0, len(side))
+		for _, level := range side {
+			orders := make([]*types.Order, 0, len(level.OrderIDs))
+			for _, id := range level.OrderIDs {
+				orders = append(orders, e.keeper.GetOrder(ctx, id))
+			}
+			out = append(out, fuzzLevel{price: level.Price, quantity: level.Quantity, orders: orders})
+		}
+		return out
+	}
+	return levels(ob.Bids), levels(ob.Asks)
+}
+
+func (e *fuzzEngineV1) flush(sdk.Context) error {
+	return nil
+}
+
+// matchingEngines are the engines every sequence is replayed against
+var matchingEngines = []struct {
+	name string
+	new  func(k *Keeper) fuzzEngine
+}{
+	{"v2", func(k *Keeper) fuzzEngine { return &fuzzEngineV2{keeper: k, engine: NewMatchingEngineV2(k)} }},
+	{"v1", func(k *Keeper) fuzzEngine { return &fuzzEngineV1{keeper: k, engine: NewMatchingEngine(k)} }},
+}
+
+// matchingHarness replays operations against an engine and the reference book
+type matchingHarness struct {
+	keeper *Keeper
+	ctx    sdk.Context
+	engine fuzzEngine
+	model  *matchingModel
+	placed map[string]bool
+}
+
+func newMatchingHarness(tb testing.TB, newEngine func(k *Keeper) fuzzEngine) *matchingHarness {
+	k, ctx := setupBenchKeeper(tb)
+	return &matchingHarness{
+		keeper: k,
+		ctx:    ctx,
+		engine: newEngine(k),
+		model:  &matchingModel{},
+		placed: make(map[string]bool),
+	}
+}
+
+func fuzzOrderID(id int) string {
+	return fmt.Sprintf("fuzz-%d", id)
+}
+
+// run replays ops, returning the first invariant violation
+func (h *matchingHarness) run(ops []matchingOp) error {
+	for i, op := range ops {
+		if err := h.apply(op); err != nil {
+			return fmt.Errorf("op %d (%s %d): %w", i, op.Op, op.ID, err)
+		}
+	}
+
+	// The flushed book must also satisfy the keeper invariant
+	if err := h.engine.flush(h.ctx); err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+	if msg, broken := BookQuantityInvariant(h.keeper)(h.ctx); broken {
+		return fmt.Errorf("flushed book: %s", msg)
+	}
+	return nil
+}
+
+func (h *matchingHarness) apply(op matchingOp) error {
+	switch op.Op {
+	case matchingOpLimit, matchingOpMarket:
+		return h.place(op)
+	case matchingOpCancel:
+		return h.cancel(op)
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+}
+
+func (h *matchingHarness) place(op matchingOp) error {
+	id := fuzzOrderID(op.ID)
+	if h.placed[id] {
+		return fmt.Errorf("order %s placed twice", id)
+	}
+	if op.Qty <= 0 {
+		return fmt.Errorf("invalid quantity %d", op.Qty)
+	}
+
+	side := types.SideBuy
+	if op.Side == "sell" {
+		side = types.SideSell
+	}
+	qty := math.LegacyNewDecWithPrec(op.Qty, 1)
+
+	var order *types.Order
+	var limit *math.LegacyDec
+	if op.Op == matchingOpLimit {
+		price := math.LegacyNewDec(op.Price)
+		limit = &price
+		order = types.NewOrder(id, fuzzTrader, fuzzMarketID, side, types.OrderTypeLimit, price, qty)
+	} else {
+		order = types.NewOrder(id, fuzzTrader, fuzzMarketID, side, types.OrderTypeMarket, math.LegacyZeroDec(), qty)
+	}
+	h.placed[id] = true
+
+	// Snapshot resting orders to check what the makers were filled
+	makersBefore := make(map[string]math.LegacyDec)
+	for _, o := range append(append([]*modelOrder{}, h.model.bids...), h.model.asks...) {
+		makersBefore[o.id] = h.engine.order(h.ctx, o.id).FilledQty
+	}
+
+	expected := h.model.match(side, limit, qty)
+
+	result, err := h.engine.process(h.ctx, order)
+	if err != nil {
+		return fmt.Errorf("process order: %w", err)
+	}
+
+	if err := checkPriceTimePriority(order, result.trades, expected); err != nil {
+		return err
+	}
+	if err := h.checkConservation(order, result, makersBefore); err != nil {
+		return err
+	}
+
+	remaining := qty
+	for _, fill := range expected {
+		remaining = remaining.Sub(fill.qty)
+	}
+	switch {
+	case remaining.IsZero():
+		if order.Status != types.OrderStatusFilled {
+			return fmt.Errorf("fully matched taker has status %s", order.Status)
+		}
+	case op.Op == matchingOpLimit:
+		h.model.rest(side, &modelOrder{id: id, price: *limit, remaining: remaining})
+	default:
+		if order.Status != types.OrderStatusCancelled {
+			return fmt.Errorf("unfilled market order has status %s", order.Status)
+		}
+	}
+
+	return h.checkBook()
+}
+
+func (h *matchingHarness) cancel(op matchingOp) error {
+	id := fuzzOrderID(op.ID)
+	resting := h.model.remove(id)
+
+	order, err := h.engine.cancel(h.ctx, id)
+	switch {
+	case resting && err != nil:
+		return fmt.Errorf("cancel resting order: %w", err)
+	case !resting && err == nil:
+		return fmt.Errorf("cancelled order %s that is not resting", id)
+	case resting && order.Status != types.OrderStatusCancelled:
+		return fmt.Errorf("cancelled order has status %s", order.Status)
+	}
+
+	return h.checkBook()
+}
+
+// checkPriceTimePriority compares the engine trades against the reference fills
+func checkPriceTimePriority(taker *types.Order, trades []*types.Trade, expected []modelFill) error {
+	if len(trades) != len(expected) {
+		return fmt.Errorf("expected %d trades, got %d", len(expected), len(trades))
+	}
+	for i, trade := range trades {
+		want := expected[i]
+		if trade.MakerOrderID != want.makerID || !trade.Price.Equal(want.price) || !trade.Quantity.Equal(want.qty) {
+			return fmt.Errorf("trade %d: expected %s %s@%s, got %s %s@%s",
+				i, want.makerID, want.qty, want.price, trade.MakerOrderID, trade.Quantity, trade.Price)
+		}
+		if trade.TakerOrderID != taker.OrderID || trade.TakerSide != taker.Side {
+			return fmt.Errorf("trade %d: taker %s %s, expected %s %s",
+				i, trade.TakerOrderID, trade.TakerSide, taker.OrderID, taker.Side)
+		}
+		if taker.OrderType == types.OrderTypeLimit {
+			if (taker.Side == types.SideBuy && trade.Price.GT(taker.Price)) ||
+				(taker.Side == types.SideSell && trade.Price.LT(taker.Price)) {
+				return fmt.Errorf("trade %d: price %s through limit %s", i, trade.Price, taker.Price)
+			}
+		}
+	}
+	return nil
+}
+
+// checkConservation checks that the taker filled exactly what its makers filled
+func (h *matchingHarness) checkConservation(taker *types.Order, result *fuzzResult, makersBefore map[string]math.LegacyDec) error {
+	traded := math.LegacyZeroDec()
+	byMaker := make(map[string]math.LegacyDec)
+	for _, trade := range result.trades {
+		traded = traded.Add(trade.Quantity)
+		sum, ok := byMaker[trade.MakerOrderID]
+		if !ok {
+			sum = math.LegacyZeroDec()
+		}
+		byMaker[trade.MakerOrderID] = sum.Add(trade.Quantity)
+	}
+
+	if !taker.FilledQty.Equal(traded) || !result.filled.Equal(traded) {
+		return fmt.Errorf("taker filled %s (result %s), trades total %s", taker.FilledQty, result.filled, traded)
+	}
+	if !result.remaining.Equal(taker.RemainingQty()) {
+		return fmt.Errorf("result remaining %s, taker remaining %s", result.remaining, taker.RemainingQty())
+	}
+
+	makersFilled := math.LegacyZeroDec()
+	for id, before := range makersBefore {
+		delta := h.engine.order(h.ctx, id).FilledQty.Sub(before)
+		want, ok := byMaker[id]
+		if !ok {
+			want = math.LegacyZeroDec()
+		}
+		if !delta.Equal(want) {
+			return fmt.Errorf("maker %s filled %s, trades total %s", id, delta, want)
+		}
+		makersFilled = makersFilled.Add(delta)
+	}
+	if !makersFilled.Equal(traded) {
+		return fmt.Errorf("makers filled %s, taker filled %s", makersFilled, traded)
+	}
+	return nil
+}
+
+// checkBook compares the engine book to the reference book level by level
+func (h *matchingHarness) checkBook() error {
+	engineBids, engineAsks := h.engine.book(h.ctx)
+
+	if len(engineBids) > 0 && len(engineAsks) > 0 && engineBids[0].price.GTE(engineAsks[0].price) {
+		return fmt.Errorf("crossed book: bid %s >= ask %s", engineBids[0].price, engineAsks[0].price)
+	}
+
+	bids, asks := h.model.bids, h.model.asks
+	for _, level := range engineBids {
+		if err := checkModelLevel(types.SideBuy, level, &bids); err != nil {
+			return err
+		}
+	}
+	for _, level := range engineAsks {
+		if err := checkModelLevel(types.SideSell, level, &asks); err != nil {
+			return err
+		}
+	}
+
+	for _, rest := range [][]*modelOrder{bids, asks} {
+		if len(rest) > 0 {
+			return fmt.Errorf("order %s@%s missing from book", rest[0].id, rest[0].price)
+		}
+	}
+	return nil
+}
+
+// checkModelLevel consumes the reference orders at the level's price from the
+// front of a copy of the reference side and checks them against the level in FIFO order
+func checkModelLevel(side types.Side, level fuzzLevel, model *[]*modelOrder) error {
+	if len(level.orders) == 0 {
+		return fmt.Errorf("%s@%s: empty level on book", side, level.price)
+	}
+
+	remaining := math.LegacyZeroDec()
+	for i, order := range level.orders {
+		if len(*model) == 0 {
+			return fmt.Errorf("%s@%s: unexpected order at position %d", side, level.price, i)
+		}
+		want := (*model)[0]
+		*model = (*model)[1:]
+
+		if order == nil {
+			return fmt.Errorf("%s@%s: position %d holds a missing order, expected %s", side, level.price, i, want.id)
+		}
+		if order.OrderID != want.id || !want.price.Equal(level.price) {
+			return fmt.Errorf("%s@%s: position %d holds %s, expected %s@%s",
+				side, level.price, i, order.OrderID, want.id, want.price)
+		}
+		if !order.IsActive() || !order.RemainingQty().Equal(want.remaining) {
+			return fmt.Errorf("%s@%s: order %s is %s with %s remaining, expected %s",
+				side, level.price, order.OrderID, order.Status, order.RemainingQty(), want.remaining)
+		}
+		remaining = remaining.Add(order.RemainingQty())
+	}
+
+	if !level.quantity.Equal(remaining) {
+		return fmt.Errorf("%s@%s: level quantity %s, orders %s", side, level.price, level.quantity, remaining)
+	}
+	return nil
+}
+
+// runMatchingOps replays ops on a fresh keeper for each engine
+func runMatchingOps(tb testing.TB, ops []matchingOp) error {
+	for _, e := range matchingEngines {
+		if err := newMatchingHarness(tb, e.new).run(ops); err != nil {
+			return fmt.Errorf("%s: %w", e.name, err)
+		}
+	}
+	return nil
+}
+
+// minimizeMatchingOps removes chunks and then single operations for as long as
+// the sequence keeps failing. Cancels of orders that are no longer placed
+// simply become no-ops, so every subsequence stays replayable.
+func minimizeMatchingOps(tb testing.TB, ops []matchingOp) []matchingOp {
+	fails := func(candidate []matchingOp) bool {
+		return runMatchingOps(tb, candidate) != nil
+	}
+
+	for chunk := len(ops) / 2; chunk >= 1; chunk /= 2 {
+		for start := 0; start+chunk <= len(ops); {
+			candidate := append(append([]matchingOp{}, ops[:start]...), ops[start+chunk:]...)
+			if fails(candidate) {
+				ops = candidate
+			} else {
+				start += chunk
+			}
+		}
+	}
+	return ops
+}
+
+// reportMatchingFailure minimizes a failing sequence and fails the test with it,
+// writing it to testdata/matching when fixture writing is enabled
+func reportMatchingFailure(t *testing.T, ops []matchingOp, err error) {
+	t.Helper()
+
+	minimized := minimizeMatchingOps(t, ops)
+	if minErr := runMatchingOps(t, minimized); minErr != nil {
+		err = minErr
+	}
+
+	bz, marshalErr := json.MarshalIndent(minimized, "", "  ")
+	if marshalErr != nil {
+		t.Fatalf("marshal minimized ops: %v", marshalErr)
+	}
+
+	if os.Getenv(fuzzFixturesEnv) != "" {
+		sum := sha256.Sum256(bz)
+		name := "fuzz-" + hex.EncodeToString(sum[:6])
+		if path, writeErr := writeMatchingFixture(name, err, minimized); writeErr != nil {
+			t.Errorf("write fixture: %v", writeErr)
+		} else {
+			t.Logf("wrote fixture %s", path)
+		}
+	}
+
+	t.Fatalf("%v\nminimized from %d to %d ops:\n%s", err, len(ops), len(minimized), bz)
+}
+
+func writeMatchingFixture(name string, failure error, ops []matchingOp) (string, error) {
+	if err := os.MkdirAll(fuzzFixtureDir, 0o755); err != nil {
+		return "", err
+	}
+	bz, err := json.MarshalIndent(matchingFixture{Name: name, Error: failure.Error(), Ops: ops}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(fuzzFixtureDir, name+".json")
+	return path, os.WriteFile(path, append(bz, '\n'), 0o644)
+}
+
+// FuzzMatchingEngine checks matching invariants over random order sequences
+func FuzzMatchingEngine(f *testing.F) {
+	// Resting bids and asks, then a crossing limit and a sweeping market order
+	f.Add([]byte{0x00, 0x04, 0x03, 0x80, 0x0c, 0x05, 0x00, 0x04, 0x07, 0x80, 0x03, 0x0f, 0x05, 0x00, 0x0f})
+	// Several orders on one level, partially filled and then cancelled
+	f.Add([]byte{0x80, 0x08, 0x02, 0x80, 0x08, 0x02, 0x80, 0x08, 0x02, 0x00, 0x08, 0x04, 0x06, 0x01, 0x00, 0x07, 0x02, 0x00})
+	// Cancel before anything is placed and a market order into an empty book
+	f.Add([]byte{0x06, 0x00, 0x00, 0x05, 0x00, 0x03, 0x85, 0x00, 0x03})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := decodeMatchingOps(data)
+		if err := runMatchingOps(t, ops); err != nil {
+			reportMatchingFailure(t, ops, err)
+		}
+	})
+}
+
+// TestMatchingFixtures replays the stored operation sequences
+func TestMatchingFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(fuzzFixtureDir, "*.json"))
+	if err != nil {
+		t.Fatalf("glob fixtures: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no fixtures in %s", fuzzFixtureDir)
+	}
+
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".json"), func(t *testing.T) {
+			bz, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read fixture: %v", err)
+			}
+			var fixture matchingFixture
+			if err := json.Unmarshal(bz, &fixture); err != nil {
+				t.Fatalf("decode fixture: %v", err)
+			}
+			if err := runMatchingOps(t, fixture.Ops); err != nil {
+				t.Fatalf("%s: %v", fixture.Name, err)
+			}
+		})
+	}
+}
+
+// TestMinimizeMatchingOps tests that minimization keeps a failing sequence failing
+func TestMinimizeMatchingOps(t *testing.T) {
+	ops := []matchingOp{
+		{Op: matchingOpLimit, ID: 1, Side: "buy", Price: 99, Qty: 5},
+		{Op: matchingOpLimit, ID: 2, Side: "sell", Price: 101, Qty: 5},
+		{Op: matchingOpCancel, ID: 1},
+		{Op: matchingOpLimit, ID: 3, Side: "buy", Price: 100, Qty: 0},
+		{Op: matchingOpMarket, ID: 4, Side: "buy", Qty: 2},
+	}
+	if runMatchingOps(t, ops) == nil {
+		t.Fatal("expected zero-quantity order to fail")
+	}
+
+	minimized := minimizeMatchingOps(t, ops)
+	if len(minimized) != 1 || minimized[0].ID != 3 {
+		t.Errorf("expected only the invalid order to remain, got %+v", minimized)
+	}
+}
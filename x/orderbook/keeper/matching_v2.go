@@ -181,6 +181,8 @@ type MatchResultV2 struct {
 	FilledQty            math.LegacyDec
 	AvgPrice             math.LegacyDec
 	RemainingQty         math.LegacyDec
+	MMPTriggered         []string // makers whose market maker protection tripped during the match
+	PriceCapped          bool     // a market order stopped at its price cap with the book beyond it
 }
 
 // ToMatchResult converts to standard MatchResult
@@ -190,12 +192,23 @@ func (r *MatchResultV2) ToMatchResult() *MatchResult {
 		FilledQty:    r.FilledQty,
 		AvgPrice:     r.AvgPrice,
 		RemainingQty: r.RemainingQty,
+		MMPTriggered: r.MMPTriggered,
+		PriceCapped:  r.PriceCapped,
 	}
 }
 
 // Match attempts to match an incoming order against the order book
 // CRITICAL FIX: Uses write lock to prevent concurrent modification during matching
+//
+// Markets with price and size decimals match on int64 fixed-point values,
+// converting to Dec only for trades and the result; a value the precision
+// cannot represent switches the rest of the match to Dec arithmetic.
 func (me *MatchingEngineV2) Match(ctx sdk.Context, order *types.Order) (*MatchResultV2, error) {
+	market := me.keeper.perpetualKeeper.GetMarket(ctx, order.MarketID)
+	if market == nil {
+		return nil, fmt.Errorf("market not found: %s", order.MarketID)
+	}
+
 	orderBook := me.cache.GetOrderBook(ctx, me.keeper, order.MarketID)
 
 	result := &MatchResultV2{
@@ -209,6 +222,21 @@ func (me *MatchingEngineV2) Match(ctx sdk.Context, order *types.Order) (*MatchRe
 	// Track total value for average price calculation
 	totalValue := math.LegacyZeroDec()
 
+	// Integer fast path; nil once matching falls back to Dec
+	fixed := newFixedMatch(market, order)
+	toDec := func() {
+		if fixed != nil {
+			fixed.flush(result, &totalValue)
+			fixed = nil
+		}
+	}
+	remainingZero := func() bool {
+		if fixed != nil {
+			return fixed.remaining == 0
+		}
+		return result.RemainingQty.IsZero()
+	}
+
 	// CRITICAL: Acquire write lock for the entire matching operation
 	// This prevents concurrent modification during iteration
 	orderBook.Lock()
@@ -225,14 +253,31 @@ func (me *MatchingEngineV2) Match(ctx sdk.Context, order *types.Order) (*MatchRe
 	// Levels to update after matching
 	levelsToRemove := make([]*PriceLevelV2, 0)
 
+	// Makers whose protection tripped are not filled further; ProcessOrderOptimized cancels their quotes
+	tripped := make(map[string]bool)
+
 	// Match against price levels
 	iterateFunc(func(level *PriceLevelV2) bool {
-		if result.RemainingQty.IsZero() {
+		if remainingZero() {
 			return false // Stop iteration
 		}
 
 		// Check price compatibility
-		if !me.isPriceCompatible(order, level.Price) {
+		var levelPrice int64
+		if fixed != nil {
+			var ok bool
+			if levelPrice, ok = level.FixedPrice(fixed.precision.PriceDecimals); !ok {
+				toDec()
+			}
+		}
+		var compatible bool
+		if fixed != nil {
+			compatible = fixed.priceCompatible(order, levelPrice)
+		} else {
+			compatible = me.isPriceCompatible(order, level.Price)
+		}
+		if !compatible {
+			result.PriceCapped = order.OrderType == types.OrderTypeMarket
 			return false // Stop - no more compatible prices
 		}
 
@@ -240,7 +285,7 @@ func (me *MatchingEngineV2) Match(ctx sdk.Context, order *types.Order) (*MatchRe
 		ordersToRemove := make([]string, 0)
 
 		for _, makerOrder := range level.Orders {
-			if result.RemainingQty.IsZero() {
+			if remainingZero() {
 				break
 			}
 
@@ -248,19 +293,39 @@ func (me *MatchingEngineV2) Match(ctx sdk.Context, order *types.Order) (*MatchRe
 				ordersToRemove = append(ordersToRemove, makerOrder.OrderID)
 				continue
 			}
+			if tripped[makerOrder.Trader] {
+				continue
+			}
 
-			// Calculate match quantity
-			matchQty := math.LegacyMinDec(result.RemainingQty, makerOrder.RemainingQty())
+			// Calculate match quantity and notional
 			matchPrice := level.Price
+			var matchQty, notional math.LegacyDec
+			if fixed != nil {
+				qty, ok := fixed.matchQty(makerOrder)
+				if ok {
+					if notional, ok = fixed.record(levelPrice, qty); ok {
+						matchQty = fixed.sizeDec(qty)
+					}
+				}
+				if !ok {
+					toDec()
+				}
+			}
+			if fixed == nil {
+				matchQty = math.LegacyMinDec(result.RemainingQty, makerOrder.RemainingQty())
+				notional = matchQty.Mul(matchPrice)
+			}
 
 			// Calculate fees
-			market := me.keeper.perpetualKeeper.GetMarket(ctx, order.MarketID)
-			takerFee := me.calculateFee(matchQty, matchPrice, market.TakerFeeRate)
-			makerFee := me.calculateFee(matchQty, matchPrice, market.MakerFeeRate)
+			takerFee := me.feeOn(notional, market.TakerFeeRate)
+			makerFee := me.feeOn(notional, market.MakerFeeRate)
 
 			// Create trade
 			tradeID := me.keeper.generateTradeID(ctx)
 			trade := types.NewTrade(tradeID, order.MarketID, order, makerOrder, matchPrice, matchQty, takerFee, makerFee)
+			me.keeper.sequenceTrade(ctx, trade)
+			me.keeper.applyMakerRebate(ctx, trade, makerOrder)
+			me.keeper.recordTradeFees(ctx, trade)
 			result.Trades = append(result.Trades, trade)
 			result.TradesWithSettlement = append(result.TradesWithSettlement, types.NewTradeWithSettlement(trade))
 			me.cache.AddTrade(trade)
@@ -273,12 +338,15 @@ func (me *MatchingEngineV2) Match(ctx sdk.Context, order *types.Order) (*MatchRe
 				return false
 			}
 
-			// Update tracking
-			result.FilledQty = result.FilledQty.Add(matchQty)
-			result.RemainingQty = result.RemainingQty.Sub(matchQty)
-			totalValue = totalValue.Add(matchQty.Mul(matchPrice))
+			// Update tracking; the fast path recorded the fill already
+			if fixed == nil {
+				result.FilledQty = result.FilledQty.Add(matchQty)
+				result.RemainingQty = result.RemainingQty.Sub(matchQty)
+				totalValue = totalValue.Add(notional)
+			}
 
 			// Mark order as dirty
+			me.keeper.sequenceOrder(ctx, makerOrder)
 			me.cache.SetOrder(makerOrder)
 
 			// Track filled orders for removal
@@ -286,8 +354,15 @@ func (me *MatchingEngineV2) Match(ctx sdk.Context, order *types.Order) (*MatchRe
 				ordersToRemove = append(ordersToRemove, makerOrder.OrderID)
 			}
 
-			// Emit trade event
+			// Emit trade event and notify the trade hooks
 			me.keeper.emitTradeEvent(ctx, trade)
+			me.keeper.afterTradeExecuted(ctx, trade)
+
+			// Count the fill against the maker's market maker protection
+			if me.keeper.recordMMPFill(ctx, trade, makerOrder.Side) {
+				tripped[makerOrder.Trader] = true
+				result.MMPTriggered = append(result.MMPTriggered, makerOrder.Trader)
+			}
 		}
 
 		// Remove filled orders from level
@@ -304,6 +379,7 @@ func (me *MatchingEngineV2) Match(ctx sdk.Context, order *types.Order) (*MatchRe
 
 		return true // Continue iteration
 	})
+	toDec()
 
 	// Remove empty levels (use unsafe since we hold the lock)
 	for _, level := range levelsToRemove {
@@ -327,9 +403,9 @@ func (me *MatchingEngineV2) Match(ctx sdk.Context, order *types.Order) (*MatchRe
 
 // isPriceCompatible checks if the order can match at the given price
 func (me *MatchingEngineV2) isPriceCompatible(order *types.Order, levelPrice math.LegacyDec) bool {
-	// Market orders match at any price
+	// Market orders match at any price up to their slippage cap
 	if order.OrderType == types.OrderTypeMarket {
-		return true
+		return order.WithinPriceCap(levelPrice)
 	}
 
 	// Limit orders: buy must be >= ask, sell must be <= bid
@@ -339,12 +415,12 @@ func (me *MatchingEngineV2) isPriceCompatible(order *types.Order, levelPrice mat
 	return order.Price.LTE(levelPrice)
 }
 
-// calculateFee calculates the trading fee
-func (me *MatchingEngineV2) calculateFee(qty, price, feeRate math.LegacyDec) math.LegacyDec {
+// feeOn calculates the trading fee on a fill's notional
+func (me *MatchingEngineV2) feeOn(notional, feeRate math.LegacyDec) math.LegacyDec {
 	if feeRate.IsZero() {
 		return math.LegacyZeroDec()
 	}
-	return qty.Mul(price).Mul(feeRate)
+	return notional.Mul(feeRate)
 }
 
 // ProcessOrderOptimized is the optimized entry point for order processing
@@ -358,19 +434,61 @@ func (me *MatchingEngineV2) ProcessOrderOptimized(ctx sdk.Context, order *types.
 	// If there's remaining quantity and it's a limit order, add to book
 	if result.RemainingQty.IsPositive() && order.OrderType == types.OrderTypeLimit {
 		orderBook := me.cache.GetOrderBook(ctx, me.keeper, order.MarketID)
+		bestBid, bestAsk := math.LegacyZeroDec(), math.LegacyZeroDec()
+		bidLevel, askLevel := orderBook.GetBestLevels()
+		if bidLevel != nil {
+			bestBid = bidLevel.Price
+		}
+		if askLevel != nil {
+			bestAsk = askLevel.Price
+		}
+		recordQuoteContext(ctx, order, bestBid, bestAsk)
 		orderBook.AddOrder(order)
 		me.cache.MarkOrderBookDirty(order.MarketID)
 	} else if order.IsActive() && order.OrderType == types.OrderTypeMarket {
 		// Market order with unfilled quantity - cancel the rest
-		order.Cancel()
+		if result.PriceCapped {
+			order.CancelAtPriceCap()
+		} else {
+			order.Cancel()
+		}
 	}
 
 	// Save the taker order
+	me.keeper.sequenceOrder(ctx, order)
 	me.cache.SetOrder(order)
 
+	// Pull the remaining quotes of makers whose protection tripped
+	for _, trader := range result.MMPTriggered {
+		me.cancelMMPOrders(ctx, trader, order.MarketID)
+	}
+
 	return result, nil
 }
 
+// cancelMMPOrders cancels a trader's resting orders in a market after their
+// market maker protection tripped
+func (me *MatchingEngineV2) cancelMMPOrders(ctx sdk.Context, trader, marketID string) {
+	var orderIDs []string
+	collect := func(level *PriceLevelV2) bool {
+		for _, o := range level.Orders {
+			if o.Trader == trader && o.IsActive() {
+				orderIDs = append(orderIDs, o.OrderID)
+			}
+		}
+		return true
+	}
+	orderBook := me.cache.GetOrderBook(ctx, me.keeper, marketID)
+	orderBook.IterateBids(collect)
+	orderBook.IterateAsks(collect)
+
+	for _, orderID := range orderIDs {
+		if _, err := me.CancelOrderOptimized(ctx, orderID); err != nil {
+			me.keeper.Logger().Error("failed to cancel order after MMP trigger", "order_id", orderID, "error", err)
+		}
+	}
+}
+
 // CancelOrderOptimized cancels an order with cache support
 func (me *MatchingEngineV2) CancelOrderOptimized(ctx sdk.Context, orderID string) (*types.Order, error) {
 	order := me.cache.GetOrder(ctx, me.keeper, orderID)
@@ -389,6 +507,7 @@ func (me *MatchingEngineV2) CancelOrderOptimized(ctx sdk.Context, orderID string
 
 	// Cancel the order
 	order.Cancel()
+	me.keeper.sequenceOrder(ctx, order)
 	me.cache.SetOrder(order)
 
 	return order, nil
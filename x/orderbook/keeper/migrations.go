@@ -0,0 +1,37 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Migrator runs in-place store migrations for the orderbook module
+type Migrator struct {
+	keeper *Keeper
+}
+
+// NewMigrator returns a new Migrator
+func NewMigrator(keeper *Keeper) Migrator {
+	return Migrator{keeper: keeper}
+}
+
+// Migrate1to2 migrates the store from consensus version 1 to 2. Version 2
+// adds genesis import and export; the store layout is unchanged.
+func (m Migrator) Migrate1to2(ctx sdk.Context) error {
+	return nil
+}
+
+// Migrate2to3 migrates the store from consensus version 2 to 3. Version 3
+// counts resting orders per market and trader for the open order limits, so
+// the counts are built from the orders already resting.
+func (m Migrator) Migrate2to3(ctx sdk.Context) error {
+	m.keeper.rebuildRestingCounts(ctx)
+	return nil
+}
+
+// Migrate3to4 migrates the store from consensus version 3 to 4. Version 4
+// indexes resting orders by price for range queries, so the index is built
+// from the orders already resting.
+func (m Migrator) Migrate3to4(ctx sdk.Context) error {
+	m.keeper.rebuildPriceIndex(ctx)
+	return nil
+}
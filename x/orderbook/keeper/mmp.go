@@ -0,0 +1,183 @@
+package keeper
+
+import (
+	"encoding/json"
+	"fmt"
+
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Store key prefixes for market maker protection
+var (
+	MMPConfigKeyPrefix = []byte{0x50}
+	MMPStateKeyPrefix  = []byte{0x51}
+)
+
+// mmpKey is trader/marketID, so a trader's entries share a prefix
+func mmpKey(prefix []byte, trader, marketID string) []byte {
+	return append(append([]byte{}, prefix...), []byte(trader+"/"+marketID)...)
+}
+
+// ============ MMP Configuration ============
+
+// SetMMPConfig validates and saves a trader's MMP config for a market
+func (k *Keeper) SetMMPConfig(ctx sdk.Context, config *types.MMPConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	config.UpdatedAt = ctx.BlockTime()
+	k.setMMPConfig(ctx, config)
+	return nil
+}
+
+func (k *Keeper) setMMPConfig(ctx sdk.Context, config *types.MMPConfig) {
+	store := k.GetStore(ctx)
+	bz, _ := json.Marshal(config)
+	store.Set(mmpKey(MMPConfigKeyPrefix, config.Trader, config.MarketID), bz)
+}
+
+// GetMMPConfig returns a trader's MMP config for a market, or nil if none is set
+func (k *Keeper) GetMMPConfig(ctx sdk.Context, trader, marketID string) *types.MMPConfig {
+	store := k.GetStore(ctx)
+	bz := store.Get(mmpKey(MMPConfigKeyPrefix, trader, marketID))
+	if bz == nil {
+		return nil
+	}
+	var config types.MMPConfig
+	if err := json.Unmarshal(bz, &config); err != nil {
+		return nil
+	}
+	return &config
+}
+
+// GetMMPConfigsByTrader returns a trader's MMP configs across markets
+func (k *Keeper) GetMMPConfigsByTrader(ctx sdk.Context, trader string) []*types.MMPConfig {
+	return k.iterateMMPConfigs(ctx, mmpKey(MMPConfigKeyPrefix, trader, ""))
+}
+
+// GetAllMMPConfigs returns every trader's MMP configs
+func (k *Keeper) GetAllMMPConfigs(ctx sdk.Context) []*types.MMPConfig {
+	return k.iterateMMPConfigs(ctx, MMPConfigKeyPrefix)
+}
+
+func (k *Keeper) iterateMMPConfigs(ctx sdk.Context, prefix []byte) []*types.MMPConfig {
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, prefix)
+	defer iterator.Close()
+
+	configs := make([]*types.MMPConfig, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var config types.MMPConfig
+		if err := json.Unmarshal(iterator.Value(), &config); err != nil {
+			continue
+		}
+		configs = append(configs, &config)
+	}
+	return configs
+}
+
+// DeleteMMPConfig removes a trader's MMP config and state for a market
+func (k *Keeper) DeleteMMPConfig(ctx sdk.Context, trader, marketID string) {
+	store := k.GetStore(ctx)
+	store.Delete(mmpKey(MMPConfigKeyPrefix, trader, marketID))
+	store.Delete(mmpKey(MMPStateKeyPrefix, trader, marketID))
+}
+
+// ============ MMP State ============
+
+// GetMMPState returns a trader's MMP state for a market, empty if none is stored
+func (k *Keeper) GetMMPState(ctx sdk.Context, trader, marketID string) *types.MMPState {
+	store := k.GetStore(ctx)
+	bz := store.Get(mmpKey(MMPStateKeyPrefix, trader, marketID))
+	if bz == nil {
+		return types.NewMMPState(trader, marketID)
+	}
+	var state types.MMPState
+	if err := json.Unmarshal(bz, &state); err != nil {
+		return types.NewMMPState(trader, marketID)
+	}
+	return &state
+}
+
+func (k *Keeper) setMMPState(ctx sdk.Context, state *types.MMPState) {
+	store := k.GetStore(ctx)
+	bz, _ := json.Marshal(state)
+	store.Set(mmpKey(MMPStateKeyPrefix, state.Trader, state.MarketID), bz)
+}
+
+// ResetMMP lifts a trader's MMP freeze in a market so quoting can resume
+func (k *Keeper) ResetMMP(ctx sdk.Context, trader, marketID string) *types.MMPState {
+	state := k.GetMMPState(ctx, trader, marketID)
+	state.Reset()
+	k.setMMPState(ctx, state)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"mmp_reset",
+			sdk.NewAttribute("trader", trader),
+			sdk.NewAttribute("market_id", marketID),
+		),
+	)
+	return state
+}
+
+// ============ MMP Enforcement ============
+
+// checkMMP rejects new limit orders from a trader whose protection has tripped
+// in the market. Market orders are accepted so the trader can still hedge.
+func (k *Keeper) checkMMP(ctx sdk.Context, trader, marketID string, orderType types.OrderType) error {
+	if orderType != types.OrderTypeLimit {
+		return nil
+	}
+	config := k.GetMMPConfig(ctx, trader, marketID)
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	state := k.GetMMPState(ctx, trader, marketID)
+	if state.IsFrozen(ctx.BlockTime()) {
+		return fmt.Errorf("%w until %s (%s limit)", types.ErrMMPFrozen,
+			state.FrozenUntil.UTC().Format("2006-01-02T15:04:05.000Z"), state.TriggerReason)
+	}
+	return nil
+}
+
+// recordMMPFill counts a fill of the maker's resting order against the maker's
+// protection and reports whether it tripped
+func (k *Keeper) recordMMPFill(ctx sdk.Context, trade *types.Trade, makerSide types.Side) bool {
+	config := k.GetMMPConfig(ctx, trade.Maker, trade.MarketID)
+	if config == nil || !config.Enabled {
+		return false
+	}
+
+	quantity := trade.Quantity
+	if makerSide == types.SideSell {
+		quantity = quantity.Neg()
+	}
+
+	state := k.GetMMPState(ctx, trade.Maker, trade.MarketID)
+	reason := state.Record(config, quantity, ctx.BlockTime())
+	k.setMMPState(ctx, state)
+	if reason == "" {
+		return false
+	}
+
+	k.Logger().Info("market maker protection triggered",
+		"trader", trade.Maker,
+		"market_id", trade.MarketID,
+		"reason", reason,
+		"frozen_until", state.FrozenUntil,
+	)
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"mmp_triggered",
+			sdk.NewAttribute("trader", trade.Maker),
+			sdk.NewAttribute("market_id", trade.MarketID),
+			sdk.NewAttribute("reason", reason),
+			sdk.NewAttribute("trade_id", trade.TradeID),
+			sdk.NewAttribute("frozen_until", fmt.Sprintf("%d", state.FrozenUntil.UnixMilli())),
+		),
+	)
+	return true
+}
@@ -62,7 +62,8 @@ func (m *msgServer) PlaceOrderBatch(ctx context.Context, msg *types.MsgPlaceOrde
 
 	wg.Wait()
 
-	// Sequential state writes for correctness
+	// Sequential state writes for correctness, committed once for the batch
+	uow := m.Keeper.BeginUnitOfWork(sdkCtx)
 	successCount := 0
 	for i, order := range msg.Orders {
 		if results[i].Success {
@@ -98,7 +99,7 @@ func (m *msgServer) PlaceOrderBatch(ctx context.Context, msg *types.MsgPlaceOrde
 
 			// Process the order through the matching engine
 			placedOrder, matchResult, err := m.Keeper.PlaceOrder(
-				sdkCtx,
+				uow.Context(),
 				msg.Trader,
 				order.MarketId,
 				side,
@@ -121,6 +122,8 @@ func (m *msgServer) PlaceOrderBatch(ctx context.Context, msg *types.MsgPlaceOrde
 		}
 	}
 
+	uow.Commit()
+
 	// Emit batch event
 	sdkCtx.EventManager().EmitEvent(
 		sdk.NewEvent(
@@ -146,6 +149,8 @@ func (m *msgServer) CancelOrderBatch(ctx context.Context, msg *types.MsgCancelOr
 
 	results := make([]*types.CancelResult, len(msg.OrderIds))
 
+	// The cancels are committed once for the batch
+	uow := m.Keeper.BeginUnitOfWork(sdkCtx)
 	for i, orderID := range msg.OrderIds {
 		result := &types.CancelResult{
 			OrderId: orderID,
@@ -153,7 +158,7 @@ func (m *msgServer) CancelOrderBatch(ctx context.Context, msg *types.MsgCancelOr
 		}
 
 		// Try to cancel the order
-		_, err := m.Keeper.CancelOrder(sdkCtx, msg.Trader, orderID)
+		_, err := m.Keeper.CancelOrder(uow.Context(), msg.Trader, orderID)
 		if err != nil {
 			result.Error = err.Error()
 		} else {
@@ -163,6 +168,7 @@ func (m *msgServer) CancelOrderBatch(ctx context.Context, msg *types.MsgCancelOr
 
 		results[i] = result
 	}
+	uow.Commit()
 
 	// Emit batch cancel event
 	sdkCtx.EventManager().EmitEvent(
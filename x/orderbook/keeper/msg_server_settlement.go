@@ -0,0 +1,54 @@
+package keeper
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// CommitTradeBatch handles a Merkle root commitment from the off-chain matcher.
+// One commitment replaces a settlement transaction per trade.
+func (m *msgServer) CommitTradeBatch(ctx context.Context, msg *types.MsgCommitTradeBatch) (*types.MsgCommitTradeBatchResponse, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
+	root, err := msg.Root()
+	if err != nil {
+		return nil, err
+	}
+
+	batch, err := m.Keeper.CommitTradeBatch(sdkCtx, msg.Submitter, msg.BatchId, root, msg.TradeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.MsgCommitTradeBatchResponse{BatchId: batch.BatchID}, nil
+}
+
+// DisputeTrade handles a counterparty challenge of a trade in a committed batch
+func (m *msgServer) DisputeTrade(ctx context.Context, msg *types.MsgDisputeTrade) (*types.MsgDisputeTradeResponse, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
+	trade, err := msg.Trade.ToTrade()
+	if err != nil {
+		return nil, err
+	}
+
+	reason, err := m.Keeper.DisputeTrade(sdkCtx, msg.BatchId, trade, msg.Proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.MsgDisputeTradeResponse{
+		Upheld: true,
+		Reason: reason,
+	}, nil
+}
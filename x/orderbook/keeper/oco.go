@@ -217,7 +217,7 @@ func (k *Keeper) PlaceOCO(
 
 	// Save component orders
 	k.SetConditionalOrder(ctx, stopOrder)
-	k.SetOrder(ctx, limitOrder)
+	k.saveOrderUpdate(ctx, limitOrder)
 
 	// Emit event
 	ctx.EventManager().EmitEvent(
@@ -266,7 +266,7 @@ func (k *Keeper) CancelOCO(ctx sdk.Context, trader, ocoID string) error {
 		k.SetConditionalOrder(ctx, oco.StopOrder)
 	}
 	if oco.LimitOrder != nil {
-		k.SetOrder(ctx, oco.LimitOrder)
+		k.saveOrderUpdate(ctx, oco.LimitOrder)
 	}
 
 	ctx.EventManager().EmitEvent(
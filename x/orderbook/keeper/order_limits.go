@@ -0,0 +1,180 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Store key prefixes for resting order limits. An active order has a marker
+// under RestingOrderKeyPrefix while it is counted, so each order is counted
+// once however often it is saved.
+var (
+	RestingOrderKeyPrefix    = []byte{0x80} // order ID -> counted marker
+	RestingMarketCountPrefix = []byte{0x81} // market ID -> resting order count
+	RestingTraderCountPrefix = []byte{0x82} // trader -> resting order count
+	OrderLimitsKey           = []byte{0x83}
+)
+
+func restingOrderKey(orderID string) []byte {
+	return append(append([]byte{}, RestingOrderKeyPrefix...), orderID...)
+}
+
+func restingMarketCountKey(marketID string) []byte {
+	return append(append([]byte{}, RestingMarketCountPrefix...), marketID...)
+}
+
+func restingTraderCountKey(trader string) []byte {
+	return append(append([]byte{}, RestingTraderCountPrefix...), trader...)
+}
+
+// ============ Config ============
+
+// GetOrderLimits returns the resting order limits, the default if none are set
+func (k *Keeper) GetOrderLimits(ctx sdk.Context) *types.OrderLimits {
+	bz := k.GetStore(ctx).Get(OrderLimitsKey)
+	if bz == nil {
+		return types.DefaultOrderLimits()
+	}
+	var limits types.OrderLimits
+	if err := json.Unmarshal(bz, &limits); err != nil {
+		return types.DefaultOrderLimits()
+	}
+	return &limits
+}
+
+// SetOrderLimits validates and saves the resting order limits. They apply to
+// orders placed from now on; orders already resting past a lowered cap stay.
+func (k *Keeper) SetOrderLimits(ctx sdk.Context, limits *types.OrderLimits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+	limits.UpdatedAt = ctx.BlockTime()
+	k.setOrderLimits(ctx, limits)
+	return nil
+}
+
+func (k *Keeper) setOrderLimits(ctx sdk.Context, limits *types.OrderLimits) {
+	bz, _ := json.Marshal(limits)
+	k.GetStore(ctx).Set(OrderLimitsKey, bz)
+}
+
+// ============ Counts ============
+
+// GetRestingOrderCount returns how many orders rest in a market
+func (k *Keeper) GetRestingOrderCount(ctx sdk.Context, marketID string) int64 {
+	return k.getRestingCount(ctx, restingMarketCountKey(marketID))
+}
+
+// GetTraderRestingOrderCount returns how many orders a trader has resting
+// across all markets
+func (k *Keeper) GetTraderRestingOrderCount(ctx sdk.Context, trader string) int64 {
+	return k.getRestingCount(ctx, restingTraderCountKey(trader))
+}
+
+// GetRestingOrderCounts returns the resting order count of every market with
+// resting orders
+func (k *Keeper) GetRestingOrderCounts(ctx sdk.Context) map[string]int64 {
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), RestingMarketCountPrefix)
+	defer iterator.Close()
+
+	counts := make(map[string]int64)
+	for ; iterator.Valid(); iterator.Next() {
+		counts[string(iterator.Key()[len(RestingMarketCountPrefix):])] = int64(binary.BigEndian.Uint64(iterator.Value()))
+	}
+	return counts
+}
+
+func (k *Keeper) getRestingCount(ctx sdk.Context, key []byte) int64 {
+	bz := k.GetStore(ctx).Get(key)
+	if bz == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(bz))
+}
+
+func (k *Keeper) addRestingCount(ctx sdk.Context, key []byte, delta int64) {
+	store := k.GetStore(ctx)
+	count := k.getRestingCount(ctx, key) + delta
+	if count <= 0 {
+		store.Delete(key)
+		return
+	}
+	store.Set(key, binary.BigEndian.AppendUint64(nil, uint64(count)))
+}
+
+// trackResting counts an order saved as active and uncounts one saved as
+// inactive or deleted
+func (k *Keeper) trackResting(ctx sdk.Context, order *types.Order, resting bool) {
+	store := k.GetStore(ctx)
+	key := restingOrderKey(order.OrderID)
+	counted := store.Has(key)
+	switch {
+	case resting && !counted:
+		store.Set(key, []byte{})
+		k.addRestingCount(ctx, restingMarketCountKey(order.MarketID), 1)
+		k.addRestingCount(ctx, restingTraderCountKey(order.Trader), 1)
+	case !resting && counted:
+		store.Delete(key)
+		k.addRestingCount(ctx, restingMarketCountKey(order.MarketID), -1)
+		k.addRestingCount(ctx, restingTraderCountKey(order.Trader), -1)
+	}
+}
+
+// rebuildRestingCounts recounts every active order, replacing the counts
+func (k *Keeper) rebuildRestingCounts(ctx sdk.Context) {
+	store := k.GetStore(ctx)
+	for _, prefix := range [][]byte{RestingOrderKeyPrefix, RestingMarketCountPrefix, RestingTraderCountPrefix} {
+		iterator := storetypes.KVStorePrefixIterator(store, prefix)
+		var keys [][]byte
+		for ; iterator.Valid(); iterator.Next() {
+			keys = append(keys, iterator.Key())
+		}
+		iterator.Close()
+		for _, key := range keys {
+			store.Delete(key)
+		}
+	}
+
+	var active []*types.Order
+	iterator := storetypes.KVStorePrefixIterator(store, OrderKeyPrefix)
+	for ; iterator.Valid(); iterator.Next() {
+		var order types.Order
+		if err := json.Unmarshal(iterator.Value(), &order); err != nil || !order.IsActive() {
+			continue
+		}
+		active = append(active, &order)
+	}
+	iterator.Close()
+	for _, order := range active {
+		k.trackResting(ctx, order, true)
+	}
+}
+
+// ============ Enforcement ============
+
+// checkOrderLimits rejects a new limit order while its market or its trader
+// is at the resting order cap. Market orders never rest and are accepted.
+func (k *Keeper) checkOrderLimits(ctx sdk.Context, trader, marketID string, orderType types.OrderType) error {
+	if orderType != types.OrderTypeLimit {
+		return nil
+	}
+	limits := k.GetOrderLimits(ctx)
+	if limits.MaxRestingPerMarket > 0 {
+		if count := k.GetRestingOrderCount(ctx, marketID); count >= limits.MaxRestingPerMarket {
+			return fmt.Errorf("%w: %s has %d resting orders (limit %d)",
+				types.ErrMarketOrderLimit, marketID, count, limits.MaxRestingPerMarket)
+		}
+	}
+	if limits.MaxRestingPerTrader > 0 {
+		if count := k.GetTraderRestingOrderCount(ctx, trader); count >= limits.MaxRestingPerTrader {
+			return fmt.Errorf("%w: trader has %d resting orders (limit %d)",
+				types.ErrTraderOrderLimit, count, limits.MaxRestingPerTrader)
+		}
+	}
+	return nil
+}
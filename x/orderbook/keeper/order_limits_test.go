@@ -0,0 +1,80 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestOrderLimits tests that resting orders are counted as they rest, fill
+// and cancel, that a limit order past the market or trader cap is rejected
+// while market orders are accepted, and that the migration rebuilds the counts
+func TestOrderLimits(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	place := func(trader string, side types.Side, orderType types.OrderType, price int64) (*types.Order, error) {
+		order, _, err := k.PlaceOrder(ctx, trader, "BTC-USDC", side, orderType, math.LegacyNewDec(price), math.LegacyOneDec())
+		return order, err
+	}
+
+	if err := k.SetOrderLimits(ctx, &types.OrderLimits{MaxRestingPerMarket: -1}); !errors.Is(err, types.ErrInvalidOrderLimits) {
+		t.Fatalf("expected a negative cap to be rejected, got %v", err)
+	}
+	if err := k.SetOrderLimits(ctx, &types.OrderLimits{MaxRestingPerMarket: 3, MaxRestingPerTrader: 2}); err != nil {
+		t.Fatalf("failed to set order limits: %v", err)
+	}
+
+	first, err := place("alice", types.SideBuy, types.OrderTypeLimit, 49000)
+	if err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+	if _, err := place("alice", types.SideBuy, types.OrderTypeLimit, 48000); err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+	if _, err := place("alice", types.SideBuy, types.OrderTypeLimit, 47000); !errors.Is(err, types.ErrTraderOrderLimit) {
+		t.Fatalf("expected the trader cap to reject a third order, got %v", err)
+	}
+	if _, err := place("bob", types.SideBuy, types.OrderTypeLimit, 47000); err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+	if _, err := place("carol", types.SideBuy, types.OrderTypeLimit, 46000); !errors.Is(err, types.ErrMarketOrderLimit) {
+		t.Fatalf("expected the market cap to reject a fourth order, got %v", err)
+	}
+	if got := k.GetRestingOrderCount(ctx, "BTC-USDC"); got != 3 {
+		t.Fatalf("expected 3 resting orders, got %d", got)
+	}
+
+	// A market order never rests and is accepted at the cap; filling alice's
+	// best bid frees a slot in the market and for her
+	if _, err := place("carol", types.SideSell, types.OrderTypeMarket, 0); err != nil {
+		t.Fatalf("expected a market order to be accepted at the cap, got %v", err)
+	}
+	if got := k.GetTraderRestingOrderCount(ctx, "alice"); got != 1 {
+		t.Fatalf("expected alice to have 1 resting order after a fill, got %d", got)
+	}
+	if _, err := place("carol", types.SideBuy, types.OrderTypeLimit, 46000); err != nil {
+		t.Fatalf("expected a freed slot to accept an order, got %v", err)
+	}
+	if got := k.GetOrder(ctx, first.OrderID); got.IsActive() {
+		t.Fatalf("expected alice's best bid to be filled, got %s", got.Status)
+	}
+
+	// Cancelling frees a slot too
+	bobOrders := k.GetOrdersByTrader(ctx, "bob")
+	if _, err := k.CancelOrder(ctx, "bob", bobOrders[0].OrderID); err != nil {
+		t.Fatalf("failed to cancel: %v", err)
+	}
+	if got := k.GetRestingOrderCounts(ctx)["BTC-USDC"]; got != 2 {
+		t.Fatalf("expected 2 resting orders after a cancel, got %d", got)
+	}
+
+	// The migration recounts the same orders
+	k.GetStore(ctx).Delete(restingTraderCountKey("alice"))
+	if err := NewMigrator(k).Migrate2to3(ctx); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	if market, alice := k.GetRestingOrderCount(ctx, "BTC-USDC"), k.GetTraderRestingOrderCount(ctx, "alice"); market != 2 || alice != 1 {
+		t.Errorf("expected rebuilt counts 2 and 1, got %d and %d", market, alice)
+	}
+}
@@ -32,6 +32,27 @@ func (a *priceLevelItem) Less(b btree.Item) bool {
 	return a.price.LT(b.(*priceLevelItem).price)
 }
 
+// priceLevelItems recycles btree wrappers and the probes used for lookups,
+// which would otherwise be heap-allocated on every call
+var priceLevelItems = sync.Pool{
+	New: func() interface{} {
+		return &priceLevelItem{}
+	},
+}
+
+// acquireItem returns a wrapper for level at price
+func acquireItem(price math.LegacyDec, level *PriceLevelV2) *priceLevelItem {
+	item := priceLevelItems.Get().(*priceLevelItem)
+	item.price, item.level = price, level
+	return item
+}
+
+// releaseItem returns a wrapper that is no longer in a tree to the pool
+func releaseItem(item *priceLevelItem) {
+	*item = priceLevelItem{}
+	priceLevelItems.Put(item)
+}
+
 // ============================================================================
 // BTree Side - one side of the order book (bids or asks)
 // ============================================================================
@@ -50,7 +71,9 @@ func newBTreeSide(desc bool) *btreeSide {
 
 // Get returns the price level at the given price, or nil if not found
 func (s *btreeSide) Get(price math.LegacyDec) *PriceLevelV2 {
-	item := s.tree.Get(&priceLevelItem{price: price})
+	probe := acquireItem(price, nil)
+	item := s.tree.Get(probe)
+	releaseItem(probe)
 	if item == nil {
 		return nil
 	}
@@ -59,25 +82,31 @@ func (s *btreeSide) Get(price math.LegacyDec) *PriceLevelV2 {
 
 // Set adds or updates a price level
 func (s *btreeSide) Set(price math.LegacyDec, level *PriceLevelV2) {
-	s.tree.ReplaceOrInsert(&priceLevelItem{
-		price: price,
-		level: level,
-	})
+	if old := s.tree.ReplaceOrInsert(acquireItem(price, level)); old != nil {
+		releaseItem(old.(*priceLevelItem))
+	}
 }
 
 // GetOrCreate returns the existing price level or creates a new one
 func (s *btreeSide) GetOrCreate(price math.LegacyDec) *PriceLevelV2 {
 	level := s.Get(price)
 	if level == nil {
-		level = NewPriceLevelV2(price)
+		level = acquirePriceLevel(price)
 		s.Set(price, level)
 	}
 	return level
 }
 
-// Remove removes a price level
+// Remove removes a price level and recycles it
 func (s *btreeSide) Remove(price math.LegacyDec) {
-	s.tree.Delete(&priceLevelItem{price: price})
+	probe := acquireItem(price, nil)
+	deleted := s.tree.Delete(probe)
+	releaseItem(probe)
+	if deleted != nil {
+		item := deleted.(*priceLevelItem)
+		releasePriceLevel(item.level)
+		releaseItem(item)
+	}
 }
 
 // Best returns the best price level
@@ -120,8 +149,9 @@ func (s *btreeSide) Iterate(fn func(*PriceLevelV2) bool) {
 
 // IterateRange iterates over price levels within a range
 func (s *btreeSide) IterateRange(minPrice, maxPrice math.LegacyDec, fn func(*PriceLevelV2) bool) {
-	minItem := &priceLevelItem{price: minPrice}
-	maxItem := &priceLevelItem{price: maxPrice}
+	minItem, maxItem := acquireItem(minPrice, nil), acquireItem(maxPrice, nil)
+	defer releaseItem(minItem)
+	defer releaseItem(maxItem)
 
 	if s.desc {
 		// For bids, iterate from max to min (descending)
@@ -355,7 +385,7 @@ func FromOrderBookToBTree(ob *types.OrderBook, orders map[string]*types.Order) *
 
 	// Convert bids
 	for _, pl := range ob.Bids {
-		level := NewPriceLevelV2(pl.Price)
+		level := acquirePriceLevel(pl.Price)
 		for _, orderID := range pl.OrderIDs {
 			if order, ok := orders[orderID]; ok {
 				level.Orders = append(level.Orders, order)
@@ -364,12 +394,14 @@ func FromOrderBookToBTree(ob *types.OrderBook, orders map[string]*types.Order) *
 		level.Quantity = pl.Quantity
 		if !level.IsEmpty() {
 			result.Bids.Set(pl.Price, level)
+		} else {
+			releasePriceLevel(level)
 		}
 	}
 
 	// Convert asks
 	for _, pl := range ob.Asks {
-		level := NewPriceLevelV2(pl.Price)
+		level := acquirePriceLevel(pl.Price)
 		for _, orderID := range pl.OrderIDs {
 			if order, ok := orders[orderID]; ok {
 				level.Orders = append(level.Orders, order)
@@ -378,6 +410,8 @@ func FromOrderBookToBTree(ob *types.OrderBook, orders map[string]*types.Order) *
 		level.Quantity = pl.Quantity
 		if !level.IsEmpty() {
 			result.Asks.Set(pl.Price, level)
+		} else {
+			releasePriceLevel(level)
 		}
 	}
 
@@ -13,6 +13,26 @@ type PriceLevelV2 struct {
 	Price    math.LegacyDec
 	Quantity math.LegacyDec
 	Orders   []*types.Order // Orders in FIFO order
+
+	fixed fixedPriceCache // Price in fixed point, converted on first match
+}
+
+// fixedPriceCache memoizes a level's fixed-point price for one precision
+type fixedPriceCache struct {
+	set      bool
+	decimals uint32
+	value    int64
+	ok       bool
+}
+
+// FixedPrice returns the level price as an integer with decimals decimals.
+// ok is false if the price has more decimals or does not fit an int64.
+func (pl *PriceLevelV2) FixedPrice(decimals uint32) (int64, bool) {
+	if !pl.fixed.set || pl.fixed.decimals != decimals {
+		value, ok := types.ToFixed(pl.Price, decimals)
+		pl.fixed = fixedPriceCache{set: true, decimals: decimals, value: value, ok: ok}
+	}
+	return pl.fixed.value, pl.fixed.ok
 }
 
 // NewPriceLevelV2 creates a new price level
@@ -24,6 +44,32 @@ func NewPriceLevelV2(price math.LegacyDec) *PriceLevelV2 {
 	}
 }
 
+// priceLevelPooling recycles price levels emptied out of OrderBookV2 and
+// OrderBookBTree through GlobalPools. Benchmarks switch it off to measure the
+// allocating baseline.
+var priceLevelPooling = true
+
+// acquirePriceLevel returns an empty level at price, reusing a pooled level
+// and its preallocated order array when pooling is on
+func acquirePriceLevel(price math.LegacyDec) *PriceLevelV2 {
+	if !priceLevelPooling {
+		return NewPriceLevelV2(price)
+	}
+	pl := GlobalPools.GetPriceLevel()
+	pl.Price = price
+	pl.Quantity = math.LegacyZeroDec()
+	pl.fixed = fixedPriceCache{}
+	return pl
+}
+
+// releasePriceLevel returns a level removed from its book to the pool. Levels
+// obtained from a book are only valid until the book next removes them.
+func releasePriceLevel(pl *PriceLevelV2) {
+	if priceLevelPooling && pl != nil {
+		GlobalPools.PutPriceLevel(pl)
+	}
+}
+
 // AddOrder adds an order to the price level (FIFO)
 func (pl *PriceLevelV2) AddOrder(order *types.Order) {
 	pl.Orders = append(pl.Orders, order)
@@ -156,7 +202,7 @@ func (ob *OrderBookV2) AddOrder(order *types.Order) {
 	if elem != nil {
 		level = elem.Value.(*PriceLevelV2)
 	} else {
-		level = NewPriceLevelV2(order.Price)
+		level = acquirePriceLevel(order.Price)
 		list.Set(order.Price, level)
 	}
 
@@ -186,6 +232,7 @@ func (ob *OrderBookV2) RemoveOrder(order *types.Order) *types.Order {
 	// Remove empty price level
 	if level.IsEmpty() {
 		list.Remove(order.Price)
+		releasePriceLevel(level)
 	}
 
 	return removed
@@ -214,6 +261,7 @@ func (ob *OrderBookV2) RemoveOrderByID(orderID string, side types.Side, price ma
 
 	if level.IsEmpty() {
 		list.Remove(price)
+		releasePriceLevel(level)
 	}
 
 	return removed
@@ -370,7 +418,7 @@ func FromOrderBook(ob *types.OrderBook, orders map[string]*types.Order) *OrderBo
 
 	// Convert bids
 	for _, pl := range ob.Bids {
-		level := NewPriceLevelV2(pl.Price)
+		level := acquirePriceLevel(pl.Price)
 		for _, orderID := range pl.OrderIDs {
 			if order, ok := orders[orderID]; ok {
 				level.Orders = append(level.Orders, order)
@@ -379,12 +427,14 @@ func FromOrderBook(ob *types.OrderBook, orders map[string]*types.Order) *OrderBo
 		level.Quantity = pl.Quantity
 		if !level.IsEmpty() {
 			result.Bids.Set(pl.Price, level)
+		} else {
+			releasePriceLevel(level)
 		}
 	}
 
 	// Convert asks
 	for _, pl := range ob.Asks {
-		level := NewPriceLevelV2(pl.Price)
+		level := acquirePriceLevel(pl.Price)
 		for _, orderID := range pl.OrderIDs {
 			if order, ok := orders[orderID]; ok {
 				level.Orders = append(level.Orders, order)
@@ -393,6 +443,8 @@ func FromOrderBook(ob *types.OrderBook, orders map[string]*types.Order) *OrderBo
 		level.Quantity = pl.Quantity
 		if !level.IsEmpty() {
 			result.Asks.Set(pl.Price, level)
+		} else {
+			releasePriceLevel(level)
 		}
 	}
 
@@ -445,10 +497,14 @@ func (ob *OrderBookV2) IterateAsksUnsafe(fn func(level *PriceLevelV2) bool) {
 
 // RemoveUnsafe removes a price level without acquiring lock (caller must hold lock)
 func (ob *OrderBookV2) RemoveUnsafe(price math.LegacyDec, side types.Side) {
+	var elem *skiplist.Element
 	if side == types.SideBuy {
-		ob.Bids.Remove(price)
+		elem = ob.Bids.Remove(price)
 	} else {
-		ob.Asks.Remove(price)
+		elem = ob.Asks.Remove(price)
+	}
+	if elem != nil {
+		releasePriceLevel(elem.Value.(*PriceLevelV2))
 	}
 }
 
@@ -219,7 +219,9 @@ func (p *ObjectPools) PutPriceLevel(pl *PriceLevelV2) {
 	if pl == nil {
 		return
 	}
+	clear(pl.Orders) // drop order references so pooled levels do not retain them
 	pl.Orders = pl.Orders[:0]
+	pl.fixed = fixedPriceCache{}
 	p.priceLevels.Put(pl)
 }
 
@@ -0,0 +1,211 @@
+package keeper
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Store key prefixes of the price index. A resting limit order is indexed
+// under marketID/side/price/createdAt/orderID, so the orders of a market side
+// within a price range are one range scan, in price-time order. Bid prices are
+// stored complemented, so bids also scan from the best price. Its index key
+// is kept under OrderPriceKeyPrefix, so an order whose price or status
+// changes has its old entry replaced however often it is saved.
+var (
+	OrderByPriceKeyPrefix = []byte{0x8B} // marketID/side/price/createdAt/orderID -> empty
+	OrderPriceKeyPrefix   = []byte{0x8C} // order ID -> its OrderByPriceKeyPrefix key
+)
+
+// priceKeyLen is the width of an encoded price: a LegacyDec scaled to an
+// integer is at most 316 bits
+const priceKeyLen = 40
+
+// Price range query page sizes
+const (
+	DefaultPriceRangeLimit = 100
+	MaxPriceRangeLimit     = 1000
+)
+
+// PriceRangeQuery selects the resting orders of one market side by price
+type PriceRangeQuery struct {
+	MarketID string
+	Side     types.Side
+	Min, Max math.LegacyDec // inclusive bounds, nil for none
+	Limit    int
+}
+
+// limit returns the page size, defaulted and capped
+func (q PriceRangeQuery) limit() int {
+	if q.Limit <= 0 {
+		return DefaultPriceRangeLimit
+	}
+	if q.Limit > MaxPriceRangeLimit {
+		return MaxPriceRangeLimit
+	}
+	return q.Limit
+}
+
+// Validate checks the side and bounds
+func (q PriceRangeQuery) Validate() error {
+	if q.MarketID == "" {
+		return types.ErrInvalidMarketID.Wrap("market is required")
+	}
+	if q.Side != types.SideBuy && q.Side != types.SideSell {
+		return types.ErrInvalidSide.Wrap("side must be buy or sell")
+	}
+	for _, bound := range []math.LegacyDec{q.Min, q.Max} {
+		if !bound.IsNil() && bound.IsNegative() {
+			return types.ErrInvalidPrice.Wrapf("price bound %s is negative", bound)
+		}
+	}
+	if !q.Min.IsNil() && !q.Max.IsNil() && q.Min.GT(q.Max) {
+		return types.ErrInvalidPrice.Wrapf("min price %s is above max price %s", q.Min, q.Max)
+	}
+	return nil
+}
+
+// priceSidePrefix is the index prefix of one market side's entries
+func priceSidePrefix(marketID string, side types.Side) []byte {
+	key := append(append(append([]byte{}, OrderByPriceKeyPrefix...), marketID...), '/')
+	return append(key, byte(side))
+}
+
+// appendPrice appends a non-negative price as a fixed-width big-endian
+// integer, so ask prices sort from the lowest; bid prices are complemented to
+// sort from the highest
+func appendPrice(key []byte, side types.Side, price math.LegacyDec) []byte {
+	bz := price.BigInt().FillBytes(make([]byte, priceKeyLen))
+	if side == types.SideBuy {
+		for i := range bz {
+			bz[i] = ^bz[i]
+		}
+	}
+	return append(key, bz...)
+}
+
+// orderPriceKey is the price index key of an order, nil if it does not rest
+// on the book
+func orderPriceKey(order *types.Order) []byte {
+	if !order.IsActive() || order.OrderType == types.OrderTypeMarket ||
+		order.Price.IsNil() || !order.Price.IsPositive() {
+		return nil
+	}
+	key := appendPrice(priceSidePrefix(order.MarketID, order.Side), order.Side, order.Price)
+	key = binary.BigEndian.AppendUint64(key, uint64(order.CreatedAt.UnixNano()))
+	return append(key, order.OrderID...)
+}
+
+func orderPriceRefKey(orderID string) []byte {
+	return append(append([]byte{}, OrderPriceKeyPrefix...), orderID...)
+}
+
+// indexOrderPrice moves an order's price index entry to its current price and
+// status, dropping it once the order no longer rests
+func (k *Keeper) indexOrderPrice(ctx sdk.Context, order *types.Order) {
+	store := k.GetStore(ctx)
+	refKey := orderPriceRefKey(order.OrderID)
+	old := store.Get(refKey)
+	key := orderPriceKey(order)
+	if old != nil && bytes.Equal(old, key) {
+		return
+	}
+	if old != nil {
+		store.Delete(old)
+		store.Delete(refKey)
+	}
+	if key != nil {
+		store.Set(key, []byte{})
+		store.Set(refKey, key)
+	}
+}
+
+// unindexOrderPrice drops an order's price index entry
+func (k *Keeper) unindexOrderPrice(ctx sdk.Context, orderID string) {
+	store := k.GetStore(ctx)
+	refKey := orderPriceRefKey(orderID)
+	if old := store.Get(refKey); old != nil {
+		store.Delete(old)
+		store.Delete(refKey)
+	}
+}
+
+// IterateOrdersByPrice calls cb with the resting orders of a market side
+// within [min, max], best price first: bids from the highest price, asks from
+// the lowest, and by creation time within a price. Nil bounds are open and
+// bounds must not be negative.
+// Iteration stops when cb returns true. It scans only the index entries in
+// the range, however many orders the market or the store holds.
+func (k *Keeper) IterateOrdersByPrice(ctx sdk.Context, marketID string, side types.Side, min, max math.LegacyDec, cb func(order *types.Order) (stop bool)) {
+	// Scan from the best bound: the highest price of bids, the lowest of asks
+	first, last := min, max
+	if side == types.SideBuy {
+		first, last = max, min
+	}
+	prefix := priceSidePrefix(marketID, side)
+	start, end := prefix, storetypes.PrefixEndBytes(prefix)
+	if !first.IsNil() {
+		start = appendPrice(append([]byte{}, prefix...), side, first)
+	}
+	if !last.IsNil() {
+		end = storetypes.PrefixEndBytes(appendPrice(append([]byte{}, prefix...), side, last))
+	}
+
+	iterator := k.GetStore(ctx).Iterator(start, end)
+	defer iterator.Close()
+
+	entryStart := len(prefix) + priceKeyLen + 8
+	for ; iterator.Valid(); iterator.Next() {
+		key := iterator.Key()
+		if len(key) <= entryStart {
+			continue
+		}
+		order := k.GetOrder(ctx, string(key[entryStart:]))
+		if order == nil || !order.IsActive() {
+			continue
+		}
+		if cb(order) {
+			return
+		}
+	}
+}
+
+// GetOrdersByPriceRange returns up to q's limit of the resting orders of a
+// market side within q's price range, best price first, as
+// IterateOrdersByPrice visits them
+func (k *Keeper) GetOrdersByPriceRange(ctx sdk.Context, q PriceRangeQuery) ([]*types.Order, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	limit := q.limit()
+	orders := make([]*types.Order, 0)
+	k.IterateOrdersByPrice(ctx, q.MarketID, q.Side, q.Min, q.Max, func(order *types.Order) bool {
+		orders = append(orders, order)
+		return len(orders) >= limit
+	})
+	return orders, nil
+}
+
+// rebuildPriceIndex indexes every resting order by price, replacing the index
+func (k *Keeper) rebuildPriceIndex(ctx sdk.Context) {
+	store := k.GetStore(ctx)
+	for _, prefix := range [][]byte{OrderByPriceKeyPrefix, OrderPriceKeyPrefix} {
+		iterator := storetypes.KVStorePrefixIterator(store, prefix)
+		var keys [][]byte
+		for ; iterator.Valid(); iterator.Next() {
+			keys = append(keys, iterator.Key())
+		}
+		iterator.Close()
+		for _, key := range keys {
+			store.Delete(key)
+		}
+	}
+
+	for _, order := range k.GetAllPendingOrders(ctx) {
+		k.indexOrderPrice(ctx, order)
+	}
+}
@@ -0,0 +1,94 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestOrdersByPriceRange tests that a price range query returns a side's
+// resting orders within the bounds best price first, that fills, amends and
+// cancels move or drop index entries, and that the migration rebuilds them
+func TestOrdersByPriceRange(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	place := func(side types.Side, orderType types.OrderType, price int64) *types.Order {
+		t.Helper()
+		order, _, err := k.PlaceOrder(ctx, "alice", "BTC-USDC", side, orderType, math.LegacyNewDec(price), math.LegacyOneDec())
+		if err != nil {
+			t.Fatalf("failed to place order: %v", err)
+		}
+		return order
+	}
+	query := func(side types.Side, min, max math.LegacyDec, limit int) []*types.Order {
+		t.Helper()
+		orders, err := k.GetOrdersByPriceRange(ctx, PriceRangeQuery{MarketID: "BTC-USDC", Side: side, Min: min, Max: max, Limit: limit})
+		if err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		return orders
+	}
+	ids := func(orders []*types.Order) []string {
+		result := make([]string, len(orders))
+		for i, order := range orders {
+			result[i] = order.OrderID
+		}
+		return result
+	}
+	expect := func(got []*types.Order, want ...*types.Order) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("expected %d orders, got %v", len(want), ids(got))
+		}
+		for i := range want {
+			if got[i].OrderID != want[i].OrderID {
+				t.Fatalf("expected %v, got %v", ids(want), ids(got))
+			}
+		}
+	}
+
+	bid49 := place(types.SideBuy, types.OrderTypeLimit, 49000)
+	bid48a := place(types.SideBuy, types.OrderTypeLimit, 48000)
+	bid48b := place(types.SideBuy, types.OrderTypeLimit, 48000)
+	bid47 := place(types.SideBuy, types.OrderTypeLimit, 47000)
+	ask51 := place(types.SideSell, types.OrderTypeLimit, 51000)
+	ask52 := place(types.SideSell, types.OrderTypeLimit, 52000)
+	k.SetOrder(ctx, types.NewOrder("other-market", "bob", "ETH-USDC", types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(48000), math.LegacyOneDec()))
+
+	// Bids from the highest price, asks from the lowest, bounds inclusive
+	expect(query(types.SideBuy, math.LegacyNewDec(47500), math.LegacyNewDec(49000), 0), bid49, bid48a, bid48b)
+	expect(query(types.SideBuy, math.LegacyDec{}, math.LegacyNewDec(48000), 0), bid48a, bid48b, bid47)
+	expect(query(types.SideSell, math.LegacyDec{}, math.LegacyDec{}, 0), ask51, ask52)
+	expect(query(types.SideSell, math.LegacyNewDec(51001), math.LegacyNewDec(51999), 0))
+	expect(query(types.SideBuy, math.LegacyDec{}, math.LegacyDec{}, 2), bid49, bid48a)
+
+	// A filled bid leaves the index, an amended one moves and a cancelled
+	// one is dropped
+	place(types.SideSell, types.OrderTypeMarket, 0)
+	if _, _, err := k.AmendOrder(ctx, "alice", bid47.OrderID, math.LegacyNewDec(46000), math.LegacyOneDec()); err != nil {
+		t.Fatalf("failed to amend: %v", err)
+	}
+	if _, err := k.CancelOrder(ctx, "alice", bid48a.OrderID); err != nil {
+		t.Fatalf("failed to cancel: %v", err)
+	}
+	expect(query(types.SideBuy, math.LegacyDec{}, math.LegacyDec{}, 0), bid48b, k.GetOrder(ctx, bid47.OrderID))
+	expect(query(types.SideBuy, math.LegacyNewDec(47000), math.LegacyNewDec(49000), 0), bid48b)
+
+	if _, err := k.GetOrdersByPriceRange(ctx, PriceRangeQuery{MarketID: "BTC-USDC"}); !errors.Is(err, types.ErrInvalidSide) {
+		t.Errorf("expected a missing side to be rejected, got %v", err)
+	}
+	if _, err := k.GetOrdersByPriceRange(ctx, PriceRangeQuery{MarketID: "BTC-USDC", Side: types.SideBuy, Min: math.LegacyNewDec(2), Max: math.LegacyOneDec()}); !errors.Is(err, types.ErrInvalidPrice) {
+		t.Errorf("expected an empty range to be rejected, got %v", err)
+	}
+
+	// The migration indexes the same orders
+	k.unindexOrderPrice(ctx, bid48b.OrderID)
+	if err := NewMigrator(k).Migrate3to4(ctx); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	expect(query(types.SideBuy, math.LegacyDec{}, math.LegacyDec{}, 0), bid48b, k.GetOrder(ctx, bid47.OrderID))
+	if bid49 := k.GetOrder(ctx, bid49.OrderID); bid49.IsActive() {
+		t.Errorf("expected the best bid to be filled, got %s", bid49.Status)
+	}
+}
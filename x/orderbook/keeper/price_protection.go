@@ -0,0 +1,52 @@
+package keeper
+
+import (
+	"context"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// PlaceMarketOrder places a market order that fills no further than
+// maxSlippage from the mark price, overriding the market's default; a nil
+// maxSlippage keeps the default and zero disables the protection. The
+// remainder the book cannot fill within the cap is cancelled with status
+// OrderStatusPriceProtected. As with PlaceOrder, price only margins an order
+// left uncapped.
+func (k *Keeper) PlaceMarketOrder(ctx context.Context, trader, marketID string, side types.Side, price, quantity, maxSlippage math.LegacyDec) (*types.Order, *MatchResult, error) {
+	if !maxSlippage.IsNil() && (maxSlippage.IsNegative() || maxSlippage.GTE(math.LegacyOneDec())) {
+		return nil, nil, types.ErrInvalidSlippage.Wrapf("max slippage %s must be in [0, 1)", maxSlippage)
+	}
+	return k.placeOrder(ctx, trader, marketID, side, types.OrderTypeMarket, price, quantity, nil, maxSlippage)
+}
+
+// marketPriceCap returns the price cap of a market order, maxSlippage or the
+// market's default from the mark price rounded inward to the tick size. It
+// returns a nil Dec, leaving the order uncapped, when the slippage is zero
+// or there is no mark price to protect against.
+func (k *Keeper) marketPriceCap(ctx sdk.Context, marketID string, side types.Side, maxSlippage math.LegacyDec) math.LegacyDec {
+	market := k.perpetualKeeper.GetMarket(ctx, marketID)
+	if maxSlippage.IsNil() && market != nil {
+		maxSlippage = market.MaxSlippage
+	}
+	if maxSlippage.IsNil() || !maxSlippage.IsPositive() {
+		return math.LegacyDec{}
+	}
+	mark, ok := k.perpetualKeeper.GetMarkPrice(ctx, marketID)
+	if !ok || mark.IsNil() || !mark.IsPositive() {
+		return math.LegacyDec{}
+	}
+
+	priceCap := types.SlippagePriceCap(side, mark, maxSlippage)
+	if market != nil && !market.TickSize.IsNil() && market.TickSize.IsPositive() {
+		ticks := priceCap.Quo(market.TickSize)
+		if side == types.SideBuy {
+			ticks = ticks.TruncateDec()
+		} else {
+			ticks = ticks.Ceil()
+		}
+		priceCap = ticks.Mul(market.TickSize)
+	}
+	return priceCap
+}
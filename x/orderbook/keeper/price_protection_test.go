@@ -0,0 +1,113 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// mockSlippagePerpetualKeeper serves bench markets with a default max
+// slippage and a tick size, recording the price orders are margined at
+type mockSlippagePerpetualKeeper struct {
+	mockBenchPerpetualKeeper
+	maxSlippage math.LegacyDec
+	marginPrice math.LegacyDec
+}
+
+func (m *mockSlippagePerpetualKeeper) GetMarket(ctx sdk.Context, marketID string) *Market {
+	market := m.mockBenchPerpetualKeeper.GetMarket(ctx, marketID)
+	market.MaxSlippage = m.maxSlippage
+	market.TickSize = math.LegacyOneDec()
+	return market
+}
+
+func (m *mockSlippagePerpetualKeeper) CheckMarginRequirement(ctx sdk.Context, trader, marketID string, side types.Side, qty, price interface{}) error {
+	m.marginPrice = price.(math.LegacyDec)
+	return nil
+}
+
+// TestMarketOrderPriceProtection tests that a market order fills up to its
+// cap at the market's default or its own slippage from the mark price, and
+// that a remainder left by the cap ends price protected rather than cancelled
+func TestMarketOrderPriceProtection(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	perp := &mockSlippagePerpetualKeeper{maxSlippage: math.LegacyNewDecWithPrec(5, 2)}
+	k.perpetualKeeper = perp
+
+	for _, price := range []int64{50000, 51000, 53000} {
+		if _, _, err := k.PlaceOrder(ctx, "maker", "BTC-USDC", types.SideSell, types.OrderTypeLimit, math.LegacyNewDec(price), math.LegacyOneDec()); err != nil {
+			t.Fatalf("failed to place ask: %v", err)
+		}
+	}
+
+	// 5% above the 50000 mark caps the buy at 52500, short of the 53000 ask
+	order, result, err := k.PlaceOrder(ctx, "taker", "BTC-USDC", types.SideBuy, types.OrderTypeMarket, math.LegacyNewDec(1000000000), math.LegacyNewDec(3))
+	if err != nil {
+		t.Fatalf("failed to place market order: %v", err)
+	}
+	if !order.PriceCap.Equal(math.LegacyNewDec(52500)) || !perp.marginPrice.Equal(order.PriceCap) {
+		t.Fatalf("expected a 52500 cap the order is margined at, got cap %s margin %s", order.PriceCap, perp.marginPrice)
+	}
+	if !result.FilledQty.Equal(math.LegacyNewDec(2)) || !result.PriceCapped {
+		t.Fatalf("expected 2 filled before the cap, got %s (capped %v)", result.FilledQty, result.PriceCapped)
+	}
+	if stored := k.GetOrder(ctx, order.OrderID); stored.Status != types.OrderStatusPriceProtected {
+		t.Fatalf("expected the remainder to be price protected, got %s", stored.Status)
+	}
+
+	// A 10% override reaches the 53000 ask; running out of book is a plain cancel
+	order, result, err = k.PlaceMarketOrder(ctx, "taker", "BTC-USDC", types.SideBuy, math.LegacyZeroDec(), math.LegacyNewDec(2), math.LegacyNewDecWithPrec(1, 1))
+	if err != nil {
+		t.Fatalf("failed to place market order: %v", err)
+	}
+	if !result.FilledQty.Equal(math.LegacyOneDec()) || result.PriceCapped || order.Status != types.OrderStatusCancelled {
+		t.Fatalf("expected 1 filled and the rest cancelled, got %s (capped %v, %s)", result.FilledQty, result.PriceCapped, order.Status)
+	}
+
+	if _, _, err := k.PlaceMarketOrder(ctx, "taker", "BTC-USDC", types.SideBuy, math.LegacyZeroDec(), math.LegacyOneDec(), math.LegacyOneDec()); !errors.Is(err, types.ErrInvalidSlippage) {
+		t.Fatalf("expected a 100%% slippage to be rejected, got %v", err)
+	}
+
+	// Caps round inward to the tick: never past the slippage
+	tiny := math.LegacyNewDecWithPrec(1, 5)
+	if got := k.marketPriceCap(ctx, "BTC-USDC", types.SideBuy, tiny); !got.Equal(math.LegacyNewDec(50000)) {
+		t.Errorf("expected 50000.5 to round down to 50000, got %s", got)
+	}
+	if got := k.marketPriceCap(ctx, "BTC-USDC", types.SideSell, tiny); !got.Equal(math.LegacyNewDec(50000)) {
+		t.Errorf("expected 49999.5 to round up to 50000, got %s", got)
+	}
+	if got := k.marketPriceCap(ctx, "BTC-USDC", types.SideSell, math.LegacyZeroDec()); !got.IsNil() {
+		t.Errorf("expected zero slippage to leave the order uncapped, got %s", got)
+	}
+}
+
+// TestFixedMatchPriceCap tests that the fixed-point fast path stops a capped
+// market order at the same level as Dec matching
+func TestFixedMatchPriceCap(t *testing.T) {
+	for _, decimals := range []uint32{0, 2} {
+		k, ctx := setupPrecisionKeeper(t, decimals, decimals*2)
+		engine := NewMatchingEngineV2(k)
+		for i, price := range []string{"50000.25", "50100.50"} {
+			maker := types.NewOrder("ask-"+price, "maker", "BTC-USDC", types.SideSell, types.OrderTypeLimit,
+				math.LegacyMustNewDecFromStr(price), math.LegacyOneDec())
+			if _, err := engine.ProcessOrderOptimized(ctx, maker); err != nil {
+				t.Fatalf("failed to place ask %d: %v", i, err)
+			}
+		}
+
+		taker := types.NewOrder("taker", "taker", "BTC-USDC", types.SideBuy, types.OrderTypeMarket,
+			math.LegacyZeroDec(), math.LegacyNewDec(2))
+		taker.PriceCap = math.LegacyMustNewDecFromStr("50050.00")
+		result, err := engine.ProcessOrderOptimized(ctx, taker)
+		if err != nil {
+			t.Fatalf("decimals %d: match failed: %v", decimals, err)
+		}
+		if !result.FilledQty.Equal(math.LegacyOneDec()) || !result.PriceCapped || taker.Status != types.OrderStatusPriceProtected {
+			t.Errorf("decimals %d: expected 1 filled and the rest price protected, got %s (capped %v, %s)",
+				decimals, result.FilledQty, result.PriceCapped, taker.Status)
+		}
+	}
+}
@@ -348,7 +348,7 @@ func (m *ScaleOrderManager) CreateScaleOrder(
 	for _, order := range subOrders {
 		// Add to order book through keeper
 		// In production, this would call the matching engine
-		m.keeper.SetOrder(ctx, order)
+		m.keeper.saveOrderUpdate(ctx, order)
 	}
 
 	// Store scale order
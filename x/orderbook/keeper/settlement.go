@@ -0,0 +1,237 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Store key prefixes for Merkle settlement batches
+var (
+	SettlementBatchKeyPrefix  = []byte{0x40}
+	SettlementBatchCounterKey = []byte{0x41}
+	SettlementOperatorKey     = []byte{0x42}
+)
+
+// SettlementDisputeWindow is the number of blocks after a commit during which
+// trades in the batch can be disputed
+const SettlementDisputeWindow int64 = 600
+
+// ============ Settlement Batch Storage ============
+
+// SetSettlementOperator sets the address allowed to commit settlement batches
+func (k *Keeper) SetSettlementOperator(ctx sdk.Context, operator string) {
+	k.GetStore(ctx).Set(SettlementOperatorKey, []byte(operator))
+}
+
+// GetSettlementOperator returns the address allowed to commit settlement batches
+func (k *Keeper) GetSettlementOperator(ctx sdk.Context) string {
+	return string(k.GetStore(ctx).Get(SettlementOperatorKey))
+}
+
+// SetSettlementBatch saves a settlement batch
+func (k *Keeper) SetSettlementBatch(ctx sdk.Context, batch *types.SettlementBatch) {
+	store := k.GetStore(ctx)
+	bz, _ := json.Marshal(batch)
+	store.Set(settlementBatchKey(batch.BatchID), bz)
+}
+
+// GetSettlementBatch retrieves a settlement batch by ID
+func (k *Keeper) GetSettlementBatch(ctx sdk.Context, batchID uint64) *types.SettlementBatch {
+	store := k.GetStore(ctx)
+	bz := store.Get(settlementBatchKey(batchID))
+	if bz == nil {
+		return nil
+	}
+
+	var batch types.SettlementBatch
+	if err := json.Unmarshal(bz, &batch); err != nil {
+		return nil
+	}
+	return &batch
+}
+
+// GetLastSettlementBatchID returns the ID of the most recently committed batch
+func (k *Keeper) GetLastSettlementBatchID(ctx sdk.Context) uint64 {
+	bz := k.GetStore(ctx).Get(SettlementBatchCounterKey)
+	if bz == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(bz)
+}
+
+func (k *Keeper) setLastSettlementBatchID(ctx sdk.Context, batchID uint64) {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, batchID)
+	k.GetStore(ctx).Set(SettlementBatchCounterKey, bz)
+}
+
+func settlementBatchKey(batchID uint64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, batchID)
+	return append(append([]byte{}, SettlementBatchKeyPrefix...), bz...)
+}
+
+// ============ Commit and Dispute ============
+
+// CommitTradeBatch records the Merkle root of a batch of off-chain matched trades.
+// Batch IDs must be strictly sequential so a batch cannot be skipped or replayed.
+func (k *Keeper) CommitTradeBatch(ctx sdk.Context, submitter string, batchID uint64, root []byte, tradeCount uint64) (*types.SettlementBatch, error) {
+	operator := k.GetSettlementOperator(ctx)
+	if operator == "" {
+		return nil, types.ErrSettlementOperatorNotSet
+	}
+	if submitter != operator {
+		return nil, types.ErrUnauthorized.Wrap("only the settlement operator can commit batches")
+	}
+
+	expected := k.GetLastSettlementBatchID(ctx) + 1
+	if batchID != expected {
+		return nil, types.ErrInvalidBatchSequence.Wrapf("expected batch %d, got %d", expected, batchID)
+	}
+	if len(root) != 32 {
+		return nil, types.ErrInvalidMerkleRoot
+	}
+
+	batch := types.NewSettlementBatch(batchID, submitter, root, tradeCount, ctx.BlockHeight(), ctx.BlockTime())
+	k.SetSettlementBatch(ctx, batch)
+	k.setLastSettlementBatchID(ctx, batchID)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"settlement_batch_committed",
+			sdk.NewAttribute("batch_id", fmt.Sprintf("%d", batchID)),
+			sdk.NewAttribute("merkle_root", hex.EncodeToString(root)),
+			sdk.NewAttribute("trade_count", fmt.Sprintf("%d", tradeCount)),
+			sdk.NewAttribute("submitter", submitter),
+		),
+	)
+
+	k.Logger().Info("settlement batch committed",
+		"batch_id", batchID,
+		"trade_count", tradeCount,
+	)
+
+	return batch, nil
+}
+
+// DisputeTrade verifies that a trade is included in a committed batch and checks
+// it against the on-chain orders it claims to match. If the trade is invalid the
+// batch is rejected and the reason returned; a valid trade returns ErrDisputeRejected.
+func (k *Keeper) DisputeTrade(ctx sdk.Context, batchID uint64, trade *types.Trade, proof *types.MerkleProof) (string, error) {
+	batch := k.GetSettlementBatch(ctx, batchID)
+	if batch == nil {
+		return "", types.ErrSettlementBatchNotFound.Wrapf("batch %d", batchID)
+	}
+	if batch.IsRejected() {
+		return "", types.ErrSettlementBatchRejected
+	}
+	if ctx.BlockHeight() > batch.CommitHeight+SettlementDisputeWindow {
+		return "", types.ErrDisputeWindowClosed.Wrapf("batch %d committed at height %d", batchID, batch.CommitHeight)
+	}
+
+	if proof.TotalLeaves != batch.TradeCount {
+		return "", types.ErrInvalidMerkleProof.Wrapf("proof covers %d trades, batch has %d", proof.TotalLeaves, batch.TradeCount)
+	}
+	if !types.VerifyMerkleProof(batch.MerkleRoot, types.TradeLeafHash(trade), proof) {
+		return "", types.ErrInvalidMerkleProof.Wrapf("trade %s not included in batch %d", trade.TradeID, batchID)
+	}
+
+	reason := k.checkSettledTrade(ctx, trade)
+	if reason == "" {
+		return "", types.ErrDisputeRejected.Wrapf("trade %s", trade.TradeID)
+	}
+
+	batch.Reject(trade.TradeID, reason)
+	k.SetSettlementBatch(ctx, batch)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"settlement_batch_rejected",
+			sdk.NewAttribute("batch_id", fmt.Sprintf("%d", batchID)),
+			sdk.NewAttribute("trade_id", trade.TradeID),
+			sdk.NewAttribute("reason", reason),
+		),
+	)
+
+	k.Logger().Info("settlement batch rejected",
+		"batch_id", batchID,
+		"trade_id", trade.TradeID,
+		"reason", reason,
+	)
+
+	return reason, nil
+}
+
+// IsSettlementBatchFinal returns true once a batch can no longer be disputed
+func (k *Keeper) IsSettlementBatchFinal(ctx sdk.Context, batch *types.SettlementBatch) bool {
+	return !batch.IsRejected() && ctx.BlockHeight() > batch.CommitHeight+SettlementDisputeWindow
+}
+
+// checkSettledTrade checks a revealed trade, and the orders it matched where
+// they are on chain. Orders matched off-chain are never stored on chain, so a
+// missing order is not a reason to reject the trade; only a trade that
+// contradicts itself or an on-chain order is invalid. It returns an empty
+// string if the trade is valid, otherwise the reason it is not.
+func (k *Keeper) checkSettledTrade(ctx sdk.Context, trade *types.Trade) string {
+	if trade.Price.IsNil() || !trade.Price.IsPositive() {
+		return "non-positive price"
+	}
+	if trade.Quantity.IsNil() || !trade.Quantity.IsPositive() {
+		return "non-positive quantity"
+	}
+	if trade.TakerFee.IsNil() || trade.TakerFee.IsNegative() || trade.MakerFee.IsNil() || trade.MakerFee.IsNegative() {
+		return "negative fee"
+	}
+	if trade.TakerSide != types.SideBuy && trade.TakerSide != types.SideSell {
+		return "invalid taker side"
+	}
+
+	if taker := k.GetOrder(ctx, trade.TakerOrderID); taker != nil {
+		if taker.Trader != trade.Taker {
+			return "trader does not match order owner"
+		}
+		if taker.MarketID != trade.MarketID {
+			return "market does not match order"
+		}
+		if taker.Side != trade.TakerSide {
+			return "order sides do not cross"
+		}
+		if taker.OrderType == types.OrderTypeLimit {
+			if taker.Side == types.SideBuy && trade.Price.GT(taker.Price) {
+				return "price above taker limit price"
+			}
+			if taker.Side == types.SideSell && trade.Price.LT(taker.Price) {
+				return "price below taker limit price"
+			}
+		}
+		if trade.Quantity.GT(taker.Quantity) {
+			return "quantity exceeds order size"
+		}
+	}
+
+	if maker := k.GetOrder(ctx, trade.MakerOrderID); maker != nil {
+		if maker.Trader != trade.Maker {
+			return "trader does not match order owner"
+		}
+		if maker.MarketID != trade.MarketID {
+			return "market does not match order"
+		}
+		if maker.Side == trade.TakerSide {
+			return "order sides do not cross"
+		}
+		// Trades execute at the resting maker's price
+		if !trade.Price.Equal(maker.Price) {
+			return "price differs from maker limit price"
+		}
+		if trade.Quantity.GT(maker.Quantity) {
+			return "quantity exceeds order size"
+		}
+	}
+
+	return ""
+}
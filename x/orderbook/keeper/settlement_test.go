@@ -0,0 +1,137 @@
+package keeper
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestSettlementCommitAndDispute tests that only the genesis operator commits
+// batches in sequence, that a dispute of a valid off-chain trade is rejected,
+// and that a trade contradicting itself or an on-chain order rejects its batch
+func TestSettlementCommitAndDispute(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	ctx = ctx.WithBlockHeight(10)
+	srv := NewMsgServerImpl(k)
+	operator := sdk.AccAddress("settlement-operator-").String()
+
+	trade := func(id, makerOrderID string, price int64, makerFee string) *types.Trade {
+		return &types.Trade{
+			TradeID:      id,
+			MarketID:     "BTC-USDC",
+			TakerOrderID: "offchain-taker-" + id,
+			MakerOrderID: makerOrderID,
+			Taker:        "alice",
+			Maker:        "bob",
+			TakerSide:    types.SideBuy,
+			Price:        math.LegacyNewDec(price),
+			Quantity:     math.LegacyOneDec(),
+			TakerFee:     math.LegacyMustNewDecFromStr("0.5"),
+			MakerFee:     math.LegacyMustNewDecFromStr(makerFee),
+			Timestamp:    time.Unix(1700000000, 0).UTC(),
+		}
+	}
+	commit := func(submitter string, batchID uint64, trades ...*types.Trade) error {
+		root := types.MerkleRoot(types.TradeLeaves(trades))
+		_, err := srv.CommitTradeBatch(ctx, &types.MsgCommitTradeBatch{
+			Submitter:  submitter,
+			BatchId:    batchID,
+			MerkleRoot: hex.EncodeToString(root),
+			TradeCount: uint64(len(trades)),
+		})
+		return err
+	}
+	dispute := func(batchID uint64, trades []*types.Trade, index int) (*types.MsgDisputeTradeResponse, error) {
+		proof, err := types.BuildMerkleProof(types.TradeLeaves(trades), index)
+		if err != nil {
+			t.Fatalf("failed to build proof: %v", err)
+		}
+		return srv.DisputeTrade(ctx, &types.MsgDisputeTrade{
+			Challenger: trades[index].Taker,
+			BatchId:    batchID,
+			Trade:      types.NewSettledTrade(trades[index]),
+			Proof:      proof,
+		})
+	}
+
+	valid := []*types.Trade{trade("t1", "offchain-maker-1", 50000, "0.1"), trade("t2", "offchain-maker-2", 50010, "0.1")}
+	if err := commit(operator, 1, valid...); !errors.Is(err, types.ErrSettlementOperatorNotSet) {
+		t.Fatalf("expected ErrSettlementOperatorNotSet, got %v", err)
+	}
+	gs := types.DefaultGenesis()
+	gs.SettlementOperator = operator
+	if err := k.InitGenesis(ctx, gs); err != nil {
+		t.Fatalf("failed to init genesis: %v", err)
+	}
+	if err := commit(sdk.AccAddress("someone-else--------").String(), 1, valid...); !errors.Is(err, types.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if err := commit(operator, 2, valid...); !errors.Is(err, types.ErrInvalidBatchSequence) {
+		t.Fatalf("expected ErrInvalidBatchSequence, got %v", err)
+	}
+	if err := commit(operator, 1, valid...); err != nil {
+		t.Fatalf("failed to commit batch: %v", err)
+	}
+
+	// Orders matched off-chain are not on chain, which does not void the batch
+	if _, err := dispute(1, valid, 1); !errors.Is(err, types.ErrDisputeRejected) {
+		t.Fatalf("expected a valid trade's dispute to be rejected, got %v", err)
+	}
+	forged := *valid[0]
+	forged.Quantity = math.LegacyNewDec(2)
+	proof, _ := types.BuildMerkleProof(types.TradeLeaves(valid), 0)
+	if _, err := srv.DisputeTrade(ctx, &types.MsgDisputeTrade{Challenger: "alice", BatchId: 1, Trade: types.NewSettledTrade(&forged), Proof: proof}); !errors.Is(err, types.ErrInvalidMerkleProof) {
+		t.Fatalf("expected a trade outside the batch to fail its proof, got %v", err)
+	}
+	if batch := k.GetSettlementBatch(ctx, 1); batch.IsRejected() {
+		t.Fatalf("expected batch 1 to stand, got %+v", batch)
+	}
+
+	// A negative fee is invalid whatever the orders
+	negative := []*types.Trade{valid[0], trade("t3", "offchain-maker-3", 50000, "-0.1")}
+	if err := commit(operator, 2, negative...); err != nil {
+		t.Fatalf("failed to commit batch: %v", err)
+	}
+	resp, err := dispute(2, negative, 1)
+	if err != nil || !resp.Upheld || resp.Reason != "negative fee" {
+		t.Fatalf("expected the dispute upheld for a negative fee, got %+v, %v", resp, err)
+	}
+	if batch := k.GetSettlementBatch(ctx, 2); !batch.IsRejected() || batch.DisputedTradeID != "t3" {
+		t.Fatalf("expected batch 2 rejected for t3, got %+v", batch)
+	}
+	if _, err := dispute(2, negative, 0); !errors.Is(err, types.ErrSettlementBatchRejected) {
+		t.Fatalf("expected ErrSettlementBatchRejected, got %v", err)
+	}
+
+	// A trade is checked against the maker order where it is on chain
+	maker, _, err := k.PlaceOrder(ctx, "bob", "BTC-USDC", types.SideSell, types.OrderTypeLimit, math.LegacyNewDec(50100), math.LegacyOneDec())
+	if err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+	offPrice := []*types.Trade{trade("t4", maker.OrderID, 50000, "0.1")}
+	if err := commit(operator, 3, offPrice...); err != nil {
+		t.Fatalf("failed to commit batch: %v", err)
+	}
+	if resp, err := dispute(3, offPrice, 0); err != nil || resp.Reason != "price differs from maker limit price" {
+		t.Fatalf("expected the dispute upheld for the maker price, got %+v, %v", resp, err)
+	}
+
+	// Batch 1 cannot be disputed once its window closes
+	ctx = ctx.WithBlockHeight(10 + SettlementDisputeWindow + 1)
+	if _, err := dispute(1, valid, 0); !errors.Is(err, types.ErrDisputeWindowClosed) {
+		t.Fatalf("expected ErrDisputeWindowClosed, got %v", err)
+	}
+	if !k.IsSettlementBatchFinal(ctx, k.GetSettlementBatch(ctx, 1)) {
+		t.Error("expected batch 1 to be final")
+	}
+
+	exported := k.ExportGenesis(ctx)
+	if exported.SettlementOperator != operator || exported.LastSettlementBatchID != 3 {
+		t.Errorf("expected the operator and batch 3 exported, got %q and %d", exported.SettlementOperator, exported.LastSettlementBatchID)
+	}
+}
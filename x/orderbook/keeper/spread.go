@@ -0,0 +1,545 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// Store key prefixes for spread instruments. Spread orders are kept apart
+// from outright orders so leg markets' books, limits and listings never see
+// them; their MarketID is the spread ID.
+var (
+	SpreadKeyPrefix      = []byte{0x85} // spreadID -> Spread
+	SpreadOrderKeyPrefix = []byte{0x86} // orderID -> Order
+	SpreadBookKeyPrefix  = []byte{0x87} // spreadID -> OrderBook
+	SpreadFillKeyPrefix  = []byte{0x88} // orderID/n -> SpreadFill, n counting from 1
+)
+
+// ============ Definitions ============
+
+// CreateSpread validates and saves a new spread between two markets known to
+// the perpetual keeper
+func (k *Keeper) CreateSpread(ctx sdk.Context, spread *types.Spread) error {
+	if err := spread.Validate(); err != nil {
+		return err
+	}
+	for _, marketID := range []string{spread.FrontMarket, spread.BackMarket} {
+		if k.perpetualKeeper.GetMarket(ctx, marketID) == nil {
+			return types.ErrInvalidSpread.Wrapf("unknown market %s", marketID)
+		}
+	}
+	if k.GetSpread(ctx, spread.SpreadID) != nil {
+		return types.ErrSpreadExists.Wrap(spread.SpreadID)
+	}
+	spread.CreatedAt = ctx.BlockTime()
+	k.setSpread(ctx, spread)
+	return nil
+}
+
+func (k *Keeper) setSpread(ctx sdk.Context, spread *types.Spread) {
+	bz, _ := json.Marshal(spread)
+	k.GetStore(ctx).Set(append(SpreadKeyPrefix, []byte(spread.SpreadID)...), bz)
+}
+
+// GetSpread returns a spread, or nil if it is not defined
+func (k *Keeper) GetSpread(ctx sdk.Context, spreadID string) *types.Spread {
+	bz := k.GetStore(ctx).Get(append(SpreadKeyPrefix, []byte(spreadID)...))
+	if bz == nil {
+		return nil
+	}
+	var spread types.Spread
+	if err := json.Unmarshal(bz, &spread); err != nil {
+		return nil
+	}
+	return &spread
+}
+
+// GetAllSpreads returns every defined spread, ordered by ID
+func (k *Keeper) GetAllSpreads(ctx sdk.Context) []*types.Spread {
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), SpreadKeyPrefix)
+	defer iterator.Close()
+
+	spreads := make([]*types.Spread, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var spread types.Spread
+		if err := json.Unmarshal(iterator.Value(), &spread); err != nil {
+			continue
+		}
+		spreads = append(spreads, &spread)
+	}
+	return spreads
+}
+
+// ============ Orders and books ============
+
+// SetSpreadOrder saves a spread order
+func (k *Keeper) SetSpreadOrder(ctx sdk.Context, order *types.Order) {
+	bz, _ := json.Marshal(order)
+	k.GetStore(ctx).Set(append(SpreadOrderKeyPrefix, []byte(order.OrderID)...), bz)
+}
+
+// GetSpreadOrder returns a spread order, or nil if it does not exist
+func (k *Keeper) GetSpreadOrder(ctx sdk.Context, orderID string) *types.Order {
+	bz := k.GetStore(ctx).Get(append(SpreadOrderKeyPrefix, []byte(orderID)...))
+	if bz == nil {
+		return nil
+	}
+	var order types.Order
+	if err := json.Unmarshal(bz, &order); err != nil {
+		return nil
+	}
+	return &order
+}
+
+// GetSpreadOrdersByTrader returns a trader's spread orders
+func (k *Keeper) GetSpreadOrdersByTrader(ctx sdk.Context, trader string) []*types.Order {
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), SpreadOrderKeyPrefix)
+	defer iterator.Close()
+
+	orders := make([]*types.Order, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var order types.Order
+		if err := json.Unmarshal(iterator.Value(), &order); err != nil || order.Trader != trader {
+			continue
+		}
+		orders = append(orders, &order)
+	}
+	return orders
+}
+
+// GetSpreadBook returns a spread's order book, empty if nothing rests on it
+func (k *Keeper) GetSpreadBook(ctx sdk.Context, spreadID string) *types.OrderBook {
+	bz := k.GetStore(ctx).Get(append(SpreadBookKeyPrefix, []byte(spreadID)...))
+	if bz == nil {
+		return types.NewOrderBook(spreadID)
+	}
+	var ob types.OrderBook
+	if err := json.Unmarshal(bz, &ob); err != nil {
+		return types.NewOrderBook(spreadID)
+	}
+	return &ob
+}
+
+func (k *Keeper) setSpreadBook(ctx sdk.Context, ob *types.OrderBook) {
+	bz, _ := json.Marshal(ob)
+	k.GetStore(ctx).Set(append(SpreadBookKeyPrefix, []byte(ob.MarketID)...), bz)
+}
+
+// bestSpreadOrder returns the first active order on one side of a spread
+// book in price-time priority, or nil if that side is empty
+func (k *Keeper) bestSpreadOrder(ctx sdk.Context, ob *types.OrderBook, side types.Side) *types.Order {
+	levels := ob.Asks
+	if side == types.SideBuy {
+		levels = ob.Bids
+	}
+	for _, level := range levels {
+		for _, orderID := range level.OrderIDs {
+			if order := k.GetSpreadOrder(ctx, orderID); order != nil && order.IsActive() {
+				return order
+			}
+		}
+	}
+	return nil
+}
+
+// ============ Fills ============
+
+// recordSpreadFill appends a fill to its spread order's fills
+func (k *Keeper) recordSpreadFill(ctx sdk.Context, fill *types.SpreadFill) {
+	prefix := historyPrefix(SpreadFillKeyPrefix, fill.OrderID)
+	store := k.GetStore(ctx)
+
+	var n uint64
+	iterator := storetypes.KVStoreReversePrefixIterator(store, prefix)
+	if iterator.Valid() {
+		n = binary.BigEndian.Uint64(iterator.Key()[len(prefix):])
+	}
+	iterator.Close()
+
+	bz, _ := json.Marshal(fill)
+	store.Set(binary.BigEndian.AppendUint64(prefix, n+1), bz)
+}
+
+// GetSpreadFills returns a spread order's fills in the order they happened
+func (k *Keeper) GetSpreadFills(ctx sdk.Context, orderID string) []*types.SpreadFill {
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), historyPrefix(SpreadFillKeyPrefix, orderID))
+	defer iterator.Close()
+
+	fills := make([]*types.SpreadFill, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var fill types.SpreadFill
+		if err := json.Unmarshal(iterator.Value(), &fill); err != nil {
+			continue
+		}
+		fills = append(fills, &fill)
+	}
+	return fills
+}
+
+// ============ Placement and matching ============
+
+// PlaceSpreadOrder places a limit order on a spread. The order first trades
+// against the better of the spread's resting orders and the price implied by
+// its legs' books, resting orders winning ties, and every fill is booked as a
+// trade in each leg market. Whatever is left rests on the spread's book.
+// Margin is checked for both legs at their mark prices.
+func (k *Keeper) PlaceSpreadOrder(ctx sdk.Context, trader, spreadID string, side types.Side, price, quantity math.LegacyDec) (*types.Order, []*types.SpreadFill, error) {
+	spread := k.GetSpread(ctx, spreadID)
+	if spread == nil {
+		return nil, nil, types.ErrSpreadNotFound.Wrap(spreadID)
+	}
+	if side != types.SideBuy && side != types.SideSell {
+		return nil, nil, types.ErrInvalidSide
+	}
+	if price.IsNil() || !spread.OnTick(price) {
+		return nil, nil, types.ErrInvalidPrice.Wrapf("spread price %s is not a multiple of tick size %s", price, spread.TickSize)
+	}
+	if quantity.IsNil() || !quantity.IsPositive() {
+		return nil, nil, types.ErrInvalidQuantity.Wrap("quantity must be positive")
+	}
+
+	frontSide, backSide := types.LegSides(side)
+	legs := []struct {
+		marketID string
+		side     types.Side
+		qty      math.LegacyDec
+	}{
+		{spread.FrontMarket, frontSide, quantity},
+		{spread.BackMarket, backSide, quantity.Mul(spread.Ratio)},
+	}
+	for _, leg := range legs {
+		market := k.perpetualKeeper.GetMarket(ctx, leg.marketID)
+		if market == nil {
+			return nil, nil, types.ErrInvalidSpread.Wrapf("unknown market %s", leg.marketID)
+		}
+		if market.TradingHalt != nil {
+			return nil, nil, market.TradingHalt
+		}
+		mark, ok := k.perpetualKeeper.GetMarkPrice(ctx, leg.marketID)
+		if !ok {
+			return nil, nil, types.ErrSpreadLegUnpriced.Wrap(leg.marketID)
+		}
+		if err := k.perpetualKeeper.CheckMarginRequirement(ctx, trader, leg.marketID, leg.side, leg.qty, mark); err != nil {
+			return nil, nil, fmt.Errorf("insufficient margin: %w", err)
+		}
+	}
+
+	order := types.NewOrder(k.generateOrderID(ctx), trader, spreadID, side, types.OrderTypeLimit, price, quantity)
+	fills := k.matchSpreadOrder(ctx, spread, order)
+	if order.IsActive() {
+		ob := k.GetSpreadBook(ctx, spreadID)
+		ob.AddOrder(order)
+		k.setSpreadBook(ctx, ob)
+	}
+	k.SetSpreadOrder(ctx, order)
+	return order, fills, nil
+}
+
+// CancelSpreadOrder cancels a trader's resting spread order
+func (k *Keeper) CancelSpreadOrder(ctx sdk.Context, trader, orderID string) (*types.Order, error) {
+	order := k.GetSpreadOrder(ctx, orderID)
+	if order == nil {
+		return nil, types.ErrOrderNotFound.Wrap(orderID)
+	}
+	if order.Trader != trader {
+		return nil, types.ErrUnauthorized.Wrap("order belongs to different trader")
+	}
+	if !order.IsActive() {
+		return nil, types.ErrOrderNotActive.Wrap(orderID)
+	}
+
+	ob := k.GetSpreadBook(ctx, order.MarketID)
+	ob.RemoveOrder(order)
+	k.setSpreadBook(ctx, ob)
+	order.Cancel()
+	k.SetSpreadOrder(ctx, order)
+	return order, nil
+}
+
+// matchSpreadOrder fills an incoming spread order until it is filled or
+// neither the best resting spread order nor the legs' implied price crosses
+// its limit
+func (k *Keeper) matchSpreadOrder(ctx sdk.Context, spread *types.Spread, order *types.Order) []*types.SpreadFill {
+	fills := make([]*types.SpreadFill, 0)
+	for order.RemainingQty().IsPositive() {
+		ob := k.GetSpreadBook(ctx, spread.SpreadID)
+		maker := k.bestSpreadOrder(ctx, ob, order.Side.Opposite())
+		if maker != nil && !spreadCrosses(order, maker.Price) {
+			maker = nil
+		}
+		implied, ok := k.impliedSpreadQuote(ctx, spread, order.Side)
+		if ok && !spreadCrosses(order, implied.price) {
+			ok = false
+		}
+
+		var fill *types.SpreadFill
+		switch {
+		case maker != nil && (!ok || !spreadImproves(order.Side, implied.price, maker.Price)):
+			fill = k.fillSpreadDirect(ctx, spread, ob, order, maker)
+		case ok:
+			fill = k.fillSpreadImplied(ctx, spread, order, implied, math.LegacyMinDec(order.RemainingQty(), implied.qty))
+		}
+		if fill == nil {
+			break
+		}
+		fills = append(fills, fill)
+	}
+	return fills
+}
+
+// SpreadEndBlocker matches every spread's resting orders against the prices
+// implied by its legs' books, so spread orders trade as the leg markets move.
+// A resting order filled this way takes liquidity in both legs.
+func (k *Keeper) SpreadEndBlocker(ctx sdk.Context) {
+	for _, spread := range k.GetAllSpreads(ctx) {
+		for _, side := range []types.Side{types.SideBuy, types.SideSell} {
+			k.matchRestingSpreadOrders(ctx, spread, side)
+		}
+	}
+}
+
+// matchRestingSpreadOrders fills one side of a spread's book against the
+// legs, best order first, until the implied price no longer crosses
+func (k *Keeper) matchRestingSpreadOrders(ctx sdk.Context, spread *types.Spread, side types.Side) {
+	for {
+		ob := k.GetSpreadBook(ctx, spread.SpreadID)
+		order := k.bestSpreadOrder(ctx, ob, side)
+		if order == nil {
+			return
+		}
+		implied, ok := k.impliedSpreadQuote(ctx, spread, side)
+		if !ok || !spreadCrosses(order, implied.price) {
+			return
+		}
+		fill := k.fillSpreadImplied(ctx, spread, order, implied, math.LegacyMinDec(order.RemainingQty(), implied.qty))
+		if fill == nil {
+			return
+		}
+		ob.ReduceOrder(order, fill.Quantity)
+		if order.IsFilled() {
+			ob.RemoveOrder(order)
+		}
+		k.setSpreadBook(ctx, ob)
+		k.SetSpreadOrder(ctx, order)
+	}
+}
+
+// spreadCrosses reports whether a spread order's limit allows trading at price
+func spreadCrosses(order *types.Order, price math.LegacyDec) bool {
+	if order.Side == types.SideBuy {
+		return price.LTE(order.Price)
+	}
+	return price.GTE(order.Price)
+}
+
+// spreadImproves reports whether price a is strictly better than b for an
+// order on side
+func spreadImproves(side types.Side, a, b math.LegacyDec) bool {
+	if side == types.SideBuy {
+		return a.LT(b)
+	}
+	return a.GT(b)
+}
+
+// fillSpreadDirect fills an incoming spread order against a resting one at
+// the resting order's price, recording the fill for both and returning the
+// incoming order's. The back leg is booked at its mark price and the front
+// leg at the price that makes up the spread. Returns nil if the
+// back leg has no mark price or the front leg price would not be positive.
+func (k *Keeper) fillSpreadDirect(ctx sdk.Context, spread *types.Spread, ob *types.OrderBook, taker, maker *types.Order) *types.SpreadFill {
+	back, ok := k.perpetualKeeper.GetMarkPrice(ctx, spread.BackMarket)
+	if !ok {
+		return nil
+	}
+	front := maker.Price.Add(spread.Ratio.Mul(back))
+	if !front.IsPositive() || !back.IsPositive() {
+		return nil
+	}
+	qty := math.LegacyMinDec(taker.RemainingQty(), maker.RemainingQty())
+
+	frontSide, backSide := types.LegSides(taker.Side)
+	tradeIDs := []string{
+		k.bookSpreadLeg(ctx, spread.FrontMarket, frontSide, taker, maker, front, qty).TradeID,
+		k.bookSpreadLeg(ctx, spread.BackMarket, backSide, taker, maker, back, qty.Mul(spread.Ratio)).TradeID,
+	}
+
+	ob.ReduceOrder(maker, qty)
+	if err := maker.Fill(qty); err != nil {
+		return nil
+	}
+	if maker.IsFilled() {
+		ob.RemoveOrder(maker)
+	}
+	k.setSpreadBook(ctx, ob)
+	k.SetSpreadOrder(ctx, maker)
+	if err := taker.Fill(qty); err != nil {
+		return nil
+	}
+
+	fills := make([]*types.SpreadFill, 0, 2)
+	for _, order := range []*types.Order{taker, maker} {
+		fill := &types.SpreadFill{
+			SpreadID:   spread.SpreadID,
+			OrderID:    order.OrderID,
+			Trader:     order.Trader,
+			Side:       order.Side,
+			Price:      maker.Price,
+			Quantity:   qty,
+			FrontPrice: front,
+			BackPrice:  back,
+			TradeIDs:   tradeIDs,
+			Timestamp:  ctx.BlockTime(),
+		}
+		k.recordSpreadFill(ctx, fill)
+		fills = append(fills, fill)
+	}
+	return fills[0]
+}
+
+// bookSpreadLeg books one leg of a direct spread fill as a trade in the leg
+// market between the two spread orders' leg orders, charging the market's
+// fees and updating both traders' positions like any other fill
+func (k *Keeper) bookSpreadLeg(ctx sdk.Context, marketID string, takerSide types.Side, taker, maker *types.Order, price, qty math.LegacyDec) *types.Trade {
+	takerLeg := types.NewOrder(types.SpreadLegOrderID(taker.OrderID, marketID), taker.Trader, marketID, takerSide, types.OrderTypeLimit, price, qty)
+	makerLeg := types.NewOrder(types.SpreadLegOrderID(maker.OrderID, marketID), maker.Trader, marketID, takerSide.Opposite(), types.OrderTypeLimit, price, qty)
+
+	engine := NewMatchingEngine(k)
+	takerFee, makerFee := math.LegacyZeroDec(), math.LegacyZeroDec()
+	if market := k.perpetualKeeper.GetMarket(ctx, marketID); market != nil {
+		takerFee = engine.calculateFee(qty, price, market.TakerFeeRate)
+		makerFee = engine.calculateFee(qty, price, market.MakerFeeRate)
+	}
+	trade := types.NewTrade(k.generateTradeID(ctx), marketID, takerLeg, makerLeg, price, qty, takerFee, makerFee)
+	k.sequenceTrade(ctx, trade)
+	k.recordTradeFees(ctx, trade)
+
+	for _, leg := range []struct {
+		order *types.Order
+		fee   math.LegacyDec
+	}{{takerLeg, trade.TakerFee}, {makerLeg, trade.MakerFee}} {
+		if err := k.perpetualKeeper.UpdatePosition(ctx, leg.order.Trader, marketID, leg.order.Side, qty, price, leg.fee); err != nil {
+			k.Logger().Error("failed to update spread leg position", "trader", leg.order.Trader, "market_id", marketID, "error", err)
+		} else {
+			k.afterPositionChanged(ctx, leg.order.Trader, marketID)
+		}
+	}
+
+	k.SetTrade(ctx, trade)
+	k.emitTradeEvent(ctx, trade)
+	k.afterTradeExecuted(ctx, trade)
+	return trade
+}
+
+// impliedQuote is the spread price a spread order can trade at against the
+// top of its legs' books, and the quantity available there
+type impliedQuote struct {
+	price, qty  math.LegacyDec
+	front, back math.LegacyDec // leg prices at the top of each book
+}
+
+// impliedSpreadQuote returns the price implied by the legs' books for a
+// spread order on side: a buy lifts the front ask and hits the back bid, a
+// sell hits the front bid and lifts the back ask
+func (k *Keeper) impliedSpreadQuote(ctx sdk.Context, spread *types.Spread, side types.Side) (impliedQuote, bool) {
+	frontBook := k.GetOrderBook(ctx, spread.FrontMarket)
+	backBook := k.GetOrderBook(ctx, spread.BackMarket)
+	if frontBook == nil || backBook == nil {
+		return impliedQuote{}, false
+	}
+	front, back := frontBook.BestBid(), backBook.BestAsk()
+	if side == types.SideBuy {
+		front, back = frontBook.BestAsk(), backBook.BestBid()
+	}
+	if front == nil || back == nil || !front.Quantity.IsPositive() || !back.Quantity.IsPositive() {
+		return impliedQuote{}, false
+	}
+	return impliedQuote{
+		price: spread.Price(front.Price, back.Price),
+		qty:   math.LegacyMinDec(front.Quantity, back.Quantity.Quo(spread.Ratio)),
+		front: front.Price,
+		back:  back.Price,
+	}, true
+}
+
+// fillSpreadImplied fills qty of a spread order against the legs' books with
+// a front and a back leg order limited to the top of each book. Both legs run
+// in a cache context that is only written if both fill in full, so a spread
+// order never leaves a trader with one leg. Returns nil if they do not.
+func (k *Keeper) fillSpreadImplied(ctx sdk.Context, spread *types.Spread, order *types.Order, implied impliedQuote, qty math.LegacyDec) *types.SpreadFill {
+	if !qty.IsPositive() {
+		return nil
+	}
+	frontSide, backSide := types.LegSides(order.Side)
+	legs := []*types.Order{
+		types.NewOrder(types.SpreadLegOrderID(order.OrderID, spread.FrontMarket), order.Trader, spread.FrontMarket,
+			frontSide, types.OrderTypeLimit, implied.front, qty),
+		types.NewOrder(types.SpreadLegOrderID(order.OrderID, spread.BackMarket), order.Trader, spread.BackMarket,
+			backSide, types.OrderTypeLimit, implied.back, qty.Mul(spread.Ratio)),
+	}
+
+	engine := NewMatchingEngine(k)
+	cacheCtx, write := ctx.CacheContext()
+	results := make([]*MatchResult, 0, len(legs))
+	for _, leg := range legs {
+		result, err := engine.Match(cacheCtx, leg)
+		if err != nil || result.RemainingQty.IsPositive() {
+			return nil
+		}
+		results = append(results, result)
+	}
+	write()
+
+	tradeIDs := make([]string, 0)
+	for i, result := range results {
+		for _, trade := range result.Trades {
+			tradeIDs = append(tradeIDs, trade.TradeID)
+		}
+		for _, trader := range result.MMPTriggered {
+			engine.cancelMMPOrders(ctx, trader, legs[i].MarketID)
+		}
+	}
+	if err := order.Fill(qty); err != nil {
+		return nil
+	}
+
+	fill := &types.SpreadFill{
+		SpreadID:   spread.SpreadID,
+		OrderID:    order.OrderID,
+		Trader:     order.Trader,
+		Side:       order.Side,
+		Price:      spread.Price(results[0].AvgPrice, results[1].AvgPrice),
+		Quantity:   qty,
+		FrontPrice: results[0].AvgPrice,
+		BackPrice:  results[1].AvgPrice,
+		TradeIDs:   tradeIDs,
+		Implied:    true,
+		Timestamp:  ctx.BlockTime(),
+	}
+	k.recordSpreadFill(ctx, fill)
+	return fill
+}
+
+// GetSpreadQuote returns the top of a spread's own book and the prices its
+// legs' books imply: the implied bid is what a spread seller gets, the
+// implied ask what a buyer pays
+func (k *Keeper) GetSpreadQuote(ctx sdk.Context, spreadID string) (*types.SpreadQuote, error) {
+	spread := k.GetSpread(ctx, spreadID)
+	if spread == nil {
+		return nil, types.ErrSpreadNotFound.Wrap(spreadID)
+	}
+	ob := k.GetSpreadBook(ctx, spreadID)
+	quote := &types.SpreadQuote{Spread: spread, BestBid: ob.BestBid(), BestAsk: ob.BestAsk()}
+	if bid, ok := k.impliedSpreadQuote(ctx, spread, types.SideSell); ok {
+		quote.ImpliedBid, quote.ImpliedBidQty = bid.price, bid.qty
+	}
+	if ask, ok := k.impliedSpreadQuote(ctx, spread, types.SideBuy); ok {
+		quote.ImpliedAsk, quote.ImpliedAskQty = ask.price, ask.qty
+	}
+	return quote, nil
+}
@@ -0,0 +1,137 @@
+package keeper
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestSpreadOrders tests that spread orders match each other and the prices
+// implied by their legs' books, booking every fill as a trade in each leg
+func TestSpreadOrders(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	dec := math.LegacyNewDec
+	spread := types.NewSpread("BTC-USDC", "ETH-USDC")
+	if err := k.CreateSpread(ctx, spread); err != nil {
+		t.Fatalf("failed to create spread: %v", err)
+	}
+	if err := k.CreateSpread(ctx, types.NewSpread("BTC-USDC", "ETH-USDC")); !errors.Is(err, types.ErrSpreadExists) {
+		t.Errorf("expected a duplicate spread to be rejected, got %v", err)
+	}
+
+	// Nothing to trade against: the sell rests on the spread's book
+	resting, fills, err := k.PlaceSpreadOrder(ctx, "alice", spread.SpreadID, types.SideSell, dec(10), dec(2))
+	if err != nil || len(fills) != 0 {
+		t.Fatalf("expected the order to rest, got %v (err %v)", fills, err)
+	}
+	if ask := k.GetSpreadBook(ctx, spread.SpreadID).BestAsk(); ask == nil || !ask.Price.Equal(dec(10)) {
+		t.Fatalf("expected a spread ask at 10, got %+v", ask)
+	}
+
+	// A crossing buy fills at the resting price: the back leg at its mark
+	// (50000 in the mock) and the front leg 10 above it
+	_, fills, err = k.PlaceSpreadOrder(ctx, "bob", spread.SpreadID, types.SideBuy, dec(12), dec(1))
+	if err != nil || len(fills) != 1 {
+		t.Fatalf("expected one direct fill, got %v (err %v)", fills, err)
+	}
+	fill := fills[0]
+	if fill.Implied || !fill.Price.Equal(dec(10)) || !fill.FrontPrice.Equal(dec(50010)) || !fill.BackPrice.Equal(dec(50000)) || len(fill.TradeIDs) != 2 {
+		t.Fatalf("unexpected direct fill %+v", fill)
+	}
+	front := k.GetTrade(ctx, fill.TradeIDs[0])
+	back := k.GetTrade(ctx, fill.TradeIDs[1])
+	if front.MarketID != "BTC-USDC" || front.Taker != "bob" || front.TakerSide != types.SideBuy || front.Maker != "alice" ||
+		front.MakerOrderID != types.SpreadLegOrderID(resting.OrderID, "BTC-USDC") {
+		t.Errorf("unexpected front leg trade %+v", front)
+	}
+	if back.MarketID != "ETH-USDC" || back.TakerSide != types.SideSell || !back.Price.Equal(dec(50000)) {
+		t.Errorf("unexpected back leg trade %+v", back)
+	}
+	if got := k.GetSpreadOrder(ctx, resting.OrderID); !got.RemainingQty().Equal(dec(1)) || len(k.GetSpreadFills(ctx, resting.OrderID)) != 1 {
+		t.Errorf("expected the resting order to have 1 left after one fill, got %+v", got)
+	}
+
+	// Leg books imply a spread ask of 50020 - 50000 = 20
+	if _, _, err := k.PlaceOrder(ctx, "carol", "BTC-USDC", types.SideSell, types.OrderTypeLimit, dec(50020), dec(1)); err != nil {
+		t.Fatalf("failed to place front leg order: %v", err)
+	}
+	if _, _, err := k.PlaceOrder(ctx, "dave", "ETH-USDC", types.SideBuy, types.OrderTypeLimit, dec(50000), dec(1)); err != nil {
+		t.Fatalf("failed to place back leg order: %v", err)
+	}
+	quote, err := k.GetSpreadQuote(ctx, spread.SpreadID)
+	if err != nil || !quote.ImpliedAsk.Equal(dec(20)) || !quote.ImpliedAskQty.Equal(dec(1)) || !quote.ImpliedBid.IsNil() {
+		t.Fatalf("expected an implied ask of 1 at 20 and no implied bid, got %+v (err %v)", quote, err)
+	}
+
+	// A buy of 2 takes the better resting ask at 10 first, then the implied 20
+	_, fills, err = k.PlaceSpreadOrder(ctx, "erin", spread.SpreadID, types.SideBuy, dec(25), dec(2))
+	if err != nil || len(fills) != 2 {
+		t.Fatalf("expected a direct and an implied fill, got %v (err %v)", fills, err)
+	}
+	if fills[0].Implied || !fills[0].Price.Equal(dec(10)) {
+		t.Errorf("expected the resting order to fill first, got %+v", fills[0])
+	}
+	if !fills[1].Implied || !fills[1].Price.Equal(dec(20)) || len(fills[1].TradeIDs) != 2 {
+		t.Errorf("expected an implied fill at 20, got %+v", fills[1])
+	}
+	if k.GetOrderBook(ctx, "BTC-USDC").BestAsk() != nil || k.GetOrderBook(ctx, "ETH-USDC").BestBid() != nil {
+		t.Error("expected the implied fill to take both leg orders")
+	}
+
+	// One-sided leg liquidity does not fill a spread order
+	if _, _, err := k.PlaceOrder(ctx, "carol", "BTC-USDC", types.SideSell, types.OrderTypeLimit, dec(50005), dec(1)); err != nil {
+		t.Fatalf("failed to place front leg order: %v", err)
+	}
+	waiting, fills, err := k.PlaceSpreadOrder(ctx, "frank", spread.SpreadID, types.SideBuy, dec(5), dec(1))
+	if err != nil || len(fills) != 0 {
+		t.Fatalf("expected the order to rest without a back leg bid, got %v (err %v)", fills, err)
+	}
+
+	// Once the back leg is bid, the EndBlocker trades the resting order
+	if _, _, err := k.PlaceOrder(ctx, "dave", "ETH-USDC", types.SideBuy, types.OrderTypeLimit, dec(50000), dec(1)); err != nil {
+		t.Fatalf("failed to place back leg order: %v", err)
+	}
+	k.SpreadEndBlocker(ctx)
+	if got := k.GetSpreadOrder(ctx, waiting.OrderID); !got.IsFilled() {
+		t.Errorf("expected the EndBlocker to fill the resting order, got %+v", got)
+	}
+	if bid := k.GetSpreadBook(ctx, spread.SpreadID).BestBid(); bid != nil {
+		t.Errorf("expected the filled order to leave the book, got %+v", bid)
+	}
+
+	if _, _, err := k.PlaceSpreadOrder(ctx, "alice", "BTC-USDC:SOL-USDC", types.SideBuy, dec(1), dec(1)); !errors.Is(err, types.ErrSpreadNotFound) {
+		t.Errorf("expected spread not found, got %v", err)
+	}
+}
+
+// TestSpreadGenesisRoundTrip tests that spreads and their resting orders,
+// including negative spread prices, survive an export and import
+func TestSpreadGenesisRoundTrip(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	spread := types.NewSpread("BTC-USDC", "ETH-USDC")
+	if err := k.CreateSpread(ctx, spread); err != nil {
+		t.Fatalf("failed to create spread: %v", err)
+	}
+	for _, price := range []int64{-3, -5} {
+		if _, _, err := k.PlaceSpreadOrder(ctx, "alice", spread.SpreadID, types.SideBuy, math.LegacyNewDec(price), math.LegacyOneDec()); err != nil {
+			t.Fatalf("failed to place spread order: %v", err)
+		}
+	}
+
+	exported := k.ExportGenesis(ctx)
+	if len(exported.Spreads) != 1 || len(exported.SpreadOrders) != 2 {
+		t.Fatalf("expected 1 spread and 2 spread orders, got %d and %d", len(exported.Spreads), len(exported.SpreadOrders))
+	}
+	k2, ctx2 := setupBenchKeeper(t)
+	if err := k2.InitGenesis(ctx2, exported); err != nil {
+		t.Fatalf("failed to import genesis: %v", err)
+	}
+	want, _ := json.Marshal(k.GetSpreadBook(ctx, spread.SpreadID))
+	got, _ := json.Marshal(k2.GetSpreadBook(ctx2, spread.SpreadID))
+	if string(want) != string(got) {
+		t.Errorf("spread book mismatch after import:\nwant %s\ngot  %s", want, got)
+	}
+}
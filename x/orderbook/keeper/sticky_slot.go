@@ -0,0 +1,87 @@
+package keeper
+
+import (
+	"encoding/binary"
+
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// StickySlotKeyPrefix indexes the order slots freed by cancels in the current
+// block: trader/marketID/side/orderID -> block height
+var StickySlotKeyPrefix = []byte{0x09}
+
+// SetStickySlots enables cancel-replace slot reuse. It needs ClearStickySlots
+// at the end of every block, so it is off for keepers outside a chain.
+func (k *Keeper) SetStickySlots(enabled bool) {
+	k.stickySlots = enabled
+}
+
+// stickySlotKey is trader/marketID/side/orderID, so a trader's free slots on
+// one side of a market share a prefix
+func stickySlotKey(trader, marketID string, side types.Side, orderID string) []byte {
+	key := append(append([]byte{}, StickySlotKeyPrefix...), []byte(trader+"/"+marketID+"/")...)
+	key = append(key, byte(side), '/')
+	return append(key, orderID...)
+}
+
+// releaseStickySlot frees the slot of an order the trader just cancelled, so a
+// replacement on the same side of the market in the same block can take over
+// its ID and order key. Only unfilled limit orders outside OCO groups free a
+// slot: nothing but the cancel event refers to them.
+func (k *Keeper) releaseStickySlot(ctx sdk.Context, order *types.Order) {
+	if order.OrderType != types.OrderTypeLimit || !order.FilledQty.IsZero() {
+		return
+	}
+	if k.GetOCOByOrderID(ctx, order.OrderID) != nil {
+		return
+	}
+	height := binary.BigEndian.AppendUint64(nil, uint64(ctx.BlockHeight()))
+	k.GetStore(ctx).Set(stickySlotKey(order.Trader, order.MarketID, order.Side, order.OrderID), height)
+}
+
+// stickySlot returns the ID of a slot freed by the trader on this side of the
+// market in the current block, if any. Slots from earlier blocks are dropped.
+func (k *Keeper) stickySlot(ctx sdk.Context, trader, marketID string, side types.Side) (string, bool) {
+	store := k.GetStore(ctx)
+	prefix := stickySlotKey(trader, marketID, side, "")
+	iterator := storetypes.KVStorePrefixIterator(store, prefix)
+	defer iterator.Close()
+
+	var stale [][]byte
+	orderID, ok := "", false
+	for ; iterator.Valid(); iterator.Next() {
+		if binary.BigEndian.Uint64(iterator.Value()) != uint64(ctx.BlockHeight()) {
+			stale = append(stale, iterator.Key())
+			continue
+		}
+		orderID, ok = string(iterator.Key()[len(prefix):]), true
+		break
+	}
+	for _, key := range stale {
+		store.Delete(key)
+	}
+	return orderID, ok
+}
+
+// claimStickySlot removes a slot once a replacement order has taken it
+func (k *Keeper) claimStickySlot(ctx sdk.Context, order *types.Order) {
+	k.GetStore(ctx).Delete(stickySlotKey(order.Trader, order.MarketID, order.Side, order.OrderID))
+}
+
+// ClearStickySlots drops every freed slot. Called at the end of each block, as
+// slots only carry over cancel-replaces within one block.
+func (k *Keeper) ClearStickySlots(ctx sdk.Context) {
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, StickySlotKeyPrefix)
+	var keys [][]byte
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
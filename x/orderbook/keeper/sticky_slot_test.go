@@ -0,0 +1,95 @@
+package keeper
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestStickySlotCancelReplace tests that a replace after a cancel in the same
+// block reuses the cancelled order's ID and key, and that it rests like a new
+// order at the back of its price level
+func TestStickySlotCancelReplace(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	k.SetStickySlots(true)
+	ctx = ctx.WithBlockHeight(10)
+
+	place := func(trader string, side types.Side, price int64) *types.Order {
+		t.Helper()
+		order, _, err := k.PlaceOrder(ctx, trader, "BTC-USDC", side, types.OrderTypeLimit,
+			math.LegacyNewDec(price), math.LegacyOneDec())
+		if err != nil {
+			t.Fatalf("failed to place order: %v", err)
+		}
+		return order
+	}
+	cancel := func(trader, orderID string) {
+		t.Helper()
+		if _, err := k.CancelOrder(ctx, trader, orderID); err != nil {
+			t.Fatalf("failed to cancel order: %v", err)
+		}
+	}
+
+	quote := place("maker", types.SideBuy, 49900)
+	other := place("other", types.SideBuy, 49950)
+	counter := k.getCounter(ctx, OrderCounterKey)
+
+	// Cancel-replace one level up, behind the other order
+	cancel("maker", quote.OrderID)
+	replaced := place("maker", types.SideBuy, 49950)
+	if replaced.OrderID != quote.OrderID {
+		t.Fatalf("expected replace to reuse %s, got %s", quote.OrderID, replaced.OrderID)
+	}
+	if got := k.getCounter(ctx, OrderCounterKey); got != counter {
+		t.Errorf("expected order counter to stay at %d, got %d", counter, got)
+	}
+	stored := k.GetOrder(ctx, quote.OrderID)
+	if stored == nil || !stored.IsActive() || !stored.Price.Equal(math.LegacyNewDec(49950)) {
+		t.Fatalf("expected the slot to hold the open replacement, got %+v", stored)
+	}
+	if ids := k.GetOrderBook(ctx, "BTC-USDC").BestBid().OrderIDs; len(ids) != 2 || ids[0] != other.OrderID || ids[1] != quote.OrderID {
+		t.Errorf("expected replacement behind %s, queue %v", other.OrderID, ids)
+	}
+	if msg, broken := BookQuantityInvariant(k)(ctx); broken {
+		t.Fatalf("expected invariant to hold: %s", msg)
+	}
+
+	// A slot is taken once, and only on its own side
+	cancel("maker", replaced.OrderID)
+	if ask := place("maker", types.SideSell, 50100); ask.OrderID == quote.OrderID {
+		t.Error("expected an ask not to take a bid slot")
+	}
+	if bid := place("maker", types.SideBuy, 49900); bid.OrderID != quote.OrderID {
+		t.Errorf("expected a bid to take the freed slot %s, got %s", quote.OrderID, bid.OrderID)
+	}
+	if next := place("maker", types.SideBuy, 49800); next.OrderID == quote.OrderID {
+		t.Error("expected a slot to be taken only once")
+	}
+
+	// A partially filled order keeps its ID for its trades
+	filled := place("maker", types.SideSell, 50050)
+	if _, _, err := k.PlaceOrder(ctx, "taker", "BTC-USDC", types.SideBuy, types.OrderTypeLimit,
+		math.LegacyNewDec(50050), math.LegacyMustNewDecFromStr("0.5")); err != nil {
+		t.Fatalf("failed to place taker order: %v", err)
+	}
+	cancel("maker", filled.OrderID)
+	if next := place("maker", types.SideSell, 50300); next.OrderID == filled.OrderID {
+		t.Error("expected a filled order's slot not to be reused")
+	}
+
+	// Slots do not carry over into the next block
+	last := place("maker", types.SideBuy, 49700)
+	cancel("maker", last.OrderID)
+	ctx = ctx.WithBlockHeight(11)
+	if next := place("maker", types.SideBuy, 49700); next.OrderID == last.OrderID {
+		t.Error("expected a slot from an earlier block not to be reused")
+	}
+
+	last = place("maker", types.SideBuy, 49600)
+	cancel("maker", last.OrderID)
+	k.ClearStickySlots(ctx)
+	if next := place("maker", types.SideBuy, 49600); next.OrderID == last.OrderID {
+		t.Error("expected cleared slots not to be reused")
+	}
+}
@@ -26,7 +26,7 @@ func (k *Keeper) processIOC(ctx sdk.Context, order *types.ExtendedOrder, result
 	// If nothing was filled, return error
 	if result == nil || result.FilledQty.IsZero() {
 		order.Status = types.OrderStatusCancelled
-		k.SetOrder(ctx, order.ToOrder())
+		k.saveOrderUpdate(ctx, order.ToOrder())
 
 		ctx.EventManager().EmitEvent(
 			sdk.NewEvent(
@@ -42,7 +42,7 @@ func (k *Keeper) processIOC(ctx sdk.Context, order *types.ExtendedOrder, result
 	// If partially filled, cancel the remainder
 	if !order.FilledQty.GTE(order.Quantity) {
 		order.Status = types.OrderStatusCancelled
-		k.SetOrder(ctx, order.ToOrder())
+		k.saveOrderUpdate(ctx, order.ToOrder())
 
 		ctx.EventManager().EmitEvent(
 			sdk.NewEvent(
@@ -64,7 +64,7 @@ func (k *Keeper) processFOK(ctx sdk.Context, order *types.ExtendedOrder, result
 	if result == nil || !result.FilledQty.GTE(order.Quantity) {
 		// Cancel the order and reject any partial fills
 		order.Status = types.OrderStatusCancelled
-		k.SetOrder(ctx, order.ToOrder())
+		k.saveOrderUpdate(ctx, order.ToOrder())
 
 		ctx.EventManager().EmitEvent(
 			sdk.NewEvent(
@@ -87,7 +87,7 @@ func (k *Keeper) processGTX(ctx sdk.Context, order *types.ExtendedOrder, result
 	// If any trades occurred, the order would have taken liquidity
 	if result != nil && len(result.Trades) > 0 {
 		order.Status = types.OrderStatusCancelled
-		k.SetOrder(ctx, order.ToOrder())
+		k.saveOrderUpdate(ctx, order.ToOrder())
 
 		ctx.EventManager().EmitEvent(
 			sdk.NewEvent(
@@ -0,0 +1,173 @@
+package keeper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// ExplainTrade decomposes a fill's effect on one of its traders. The
+// trader's position in the market is rebuilt by folding their fills from the
+// trade history, in execution order, up to and including the trade; the fee
+// comes from the trade and its fee ledger entry. A self-trade leaves the
+// position unchanged and is skipped in the fold.
+func (k *Keeper) ExplainTrade(ctx sdk.Context, trader, tradeID string) (*types.TradePnL, error) {
+	trade := k.GetTrade(ctx, tradeID)
+	if trade == nil || (trade.Taker != trader && trade.Maker != trader) {
+		return nil, types.ErrTradeNotFound.Wrapf("%s for trader %s", tradeID, trader)
+	}
+
+	fills := k.traderFillsUntil(ctx, trader, trade)
+	position := types.FlatPosition()
+	explained := &types.TradePnL{Trade: trade, Trader: trader}
+	for _, fill := range fills {
+		if fill.Taker == fill.Maker && fill.TradeID != trade.TradeID {
+			continue
+		}
+		explained.Fills++
+		side := fillSide(fill, trader)
+		if fill.TradeID != trade.TradeID {
+			position, _, _ = position.ApplyFill(side, fill.Quantity, fill.Price, fill.Timestamp)
+			continue
+		}
+
+		explained.Before = position
+		explained.Side = side
+		if trade.Taker == trader {
+			explained.Role, explained.Fee = types.FeeRoleTaker, trade.TakerFee
+		} else {
+			explained.Role, explained.Fee = types.FeeRoleMaker, trade.MakerFee
+		}
+		if trade.Taker == trade.Maker {
+			explained.Fee = trade.TakerFee.Add(trade.MakerFee)
+			explained.After, explained.ClosedQuantity, explained.RealizedPnL = position, math.LegacyZeroDec(), math.LegacyZeroDec()
+		} else {
+			explained.After, explained.ClosedQuantity, explained.RealizedPnL = position.ApplyFill(side, fill.Quantity, fill.Price, fill.Timestamp)
+		}
+		if explained.Fee.IsNil() {
+			explained.Fee = math.LegacyZeroDec()
+		}
+		explained.FeeEntry = k.GetFeeEntry(ctx, trade.MarketID, types.FeeDay(trade.Timestamp), trade.TradeID, explained.Role)
+		break
+	}
+	return explained, nil
+}
+
+// traderFillsUntil returns the trader's fills in the trade's market up to the
+// trade's timestamp, in execution order: by time, then event sequence
+func (k *Keeper) traderFillsUntil(ctx sdk.Context, trader string, trade *types.Trade) []*types.Trade {
+	prefix := historyPrefix(TradeByTraderPrefix, trader)
+	end := binary.BigEndian.AppendUint64(bytes.Clone(prefix), uint64(trade.Timestamp.UnixNano())+1)
+	iterator := k.GetStore(ctx).Iterator(prefix, end)
+	defer iterator.Close()
+
+	fills := make([]*types.Trade, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		pos := iterator.Key()[len(prefix):]
+		if len(pos) < 8 {
+			continue
+		}
+		fill := k.GetTrade(ctx, string(pos[8:]))
+		if fill == nil || fill.MarketID != trade.MarketID {
+			continue
+		}
+		fills = append(fills, fill)
+	}
+	sort.SliceStable(fills, func(i, j int) bool {
+		if !fills[i].Timestamp.Equal(fills[j].Timestamp) {
+			return fills[i].Timestamp.Before(fills[j].Timestamp)
+		}
+		return fills[i].Seq < fills[j].Seq
+	})
+	return fills
+}
+
+// TraderActivity summarizes a trader's fills in [from, to) per market: the
+// trade count, traded notional, fees, and the PnL realized against the
+// position rebuilt from every fill before it. All of the trader's fills
+// before to are folded, so the cost grows with the trader's history.
+func (k *Keeper) TraderActivity(ctx sdk.Context, trader string, from, to time.Time) *types.TraderActivity {
+	prefix := historyPrefix(TradeByTraderPrefix, trader)
+	end := binary.BigEndian.AppendUint64(bytes.Clone(prefix), uint64(to.UnixNano()))
+	iterator := k.GetStore(ctx).Iterator(prefix, end)
+	defer iterator.Close()
+
+	fills := make([]*types.Trade, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		pos := iterator.Key()[len(prefix):]
+		if len(pos) < 8 {
+			continue
+		}
+		if fill := k.GetTrade(ctx, string(pos[8:])); fill != nil {
+			fills = append(fills, fill)
+		}
+	}
+	sort.SliceStable(fills, func(i, j int) bool {
+		if !fills[i].Timestamp.Equal(fills[j].Timestamp) {
+			return fills[i].Timestamp.Before(fills[j].Timestamp)
+		}
+		return fills[i].Seq < fills[j].Seq
+	})
+
+	positions := make(map[string]types.TradePosition)
+	markets := make(map[string]*types.MarketActivity)
+	for _, fill := range fills {
+		position, ok := positions[fill.MarketID]
+		if !ok {
+			position = types.FlatPosition()
+		}
+		realized := math.LegacyZeroDec()
+		if fill.Taker != fill.Maker {
+			position, _, realized = position.ApplyFill(fillSide(fill, trader), fill.Quantity, fill.Price, fill.Timestamp)
+			positions[fill.MarketID] = position
+		}
+		if fill.Timestamp.Before(from) {
+			continue
+		}
+
+		activity, ok := markets[fill.MarketID]
+		if !ok {
+			activity = types.NewMarketActivity(fill.MarketID)
+			markets[fill.MarketID] = activity
+		}
+		fee := fill.MakerFee
+		switch {
+		case fill.Taker == fill.Maker:
+			fee = fill.TakerFee.Add(fill.MakerFee)
+		case fill.Taker == trader:
+			fee = fill.TakerFee
+		}
+		activity.Trades++
+		activity.Volume = activity.Volume.Add(fill.Price.Mul(fill.Quantity))
+		if !fee.IsNil() {
+			activity.Fees = activity.Fees.Add(fee)
+		}
+		activity.RealizedPnL = activity.RealizedPnL.Add(realized)
+		activity.Position = position
+	}
+
+	result := &types.TraderActivity{Trader: trader, From: from, To: to, Markets: make([]*types.MarketActivity, 0, len(markets))}
+	for _, activity := range markets {
+		result.Markets = append(result.Markets, activity)
+	}
+	sort.Slice(result.Markets, func(i, j int) bool {
+		return result.Markets[i].MarketID < result.Markets[j].MarketID
+	})
+	return result
+}
+
+// fillSide returns the trader's side of a fill
+func fillSide(fill *types.Trade, trader string) types.Side {
+	if fill.Taker == trader {
+		return fill.TakerSide
+	}
+	if fill.TakerSide == types.SideBuy {
+		return types.SideSell
+	}
+	return types.SideBuy
+}
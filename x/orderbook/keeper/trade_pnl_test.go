@@ -0,0 +1,171 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestExplainTrade tests that a fill is explained against the position
+// rebuilt from the trader's earlier fills: averaging the entry on an
+// increase, realizing PnL on a reduction, reopening at the fill price on a
+// flip, and that another trader's trade is not found
+func TestExplainTrade(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	trader := sdk.AccAddress([]byte("pnl-trader__________")).String()
+	other := sdk.AccAddress([]byte("pnl-counterparty____")).String()
+
+	step := 0
+	fill := func(makerSide types.Side, maker, taker string, price, quantity int64) *types.Trade {
+		t.Helper()
+		step++
+		ctx = ctx.WithBlockTime(start.Add(time.Duration(step) * time.Minute))
+		takerSide := types.SideBuy
+		if makerSide == types.SideBuy {
+			takerSide = types.SideSell
+		}
+		if _, _, err := k.PlaceOrder(ctx, maker, "BTC-USDC", makerSide, types.OrderTypeLimit,
+			math.LegacyNewDec(price), math.LegacyNewDec(quantity)); err != nil {
+			t.Fatalf("failed to place maker order: %v", err)
+		}
+		_, result, err := k.PlaceOrder(ctx, taker, "BTC-USDC", takerSide, types.OrderTypeLimit,
+			math.LegacyNewDec(price), math.LegacyNewDec(quantity))
+		if err != nil || len(result.Trades) != 1 {
+			t.Fatalf("expected one trade, got %v (err %v)", result, err)
+		}
+		return result.Trades[0]
+	}
+	explain := func(trade *types.Trade) *types.TradePnL {
+		t.Helper()
+		explained, err := k.ExplainTrade(ctx, trader, trade.TradeID)
+		if err != nil {
+			t.Fatalf("failed to explain %s: %v", trade.TradeID, err)
+		}
+		return explained
+	}
+
+	// Buy 2 at 50000, then 2 at 52000 as taker: long 4 at an average of 51000
+	opened := fill(types.SideSell, other, trader, 50000, 2)
+	added := fill(types.SideSell, other, trader, 52000, 2)
+	got := explain(added)
+	if got.Role != types.FeeRoleTaker || got.Side != types.SideBuy || !got.RealizedPnL.IsZero() {
+		t.Errorf("expected an opening taker buy, got %+v", got)
+	}
+	if !got.Before.Size.Equal(math.LegacyNewDec(2)) || !got.After.Size.Equal(math.LegacyNewDec(4)) ||
+		!got.After.EntryPrice.Equal(math.LegacyNewDec(51000)) || !got.After.OpenedAt.Equal(opened.Timestamp) {
+		t.Errorf("unexpected position %+v -> %+v", got.Before, got.After)
+	}
+	if !got.Fee.Equal(added.TakerFee) || got.FeeEntry == nil || !got.FeeEntry.Amount.Equal(added.TakerFee) {
+		t.Errorf("expected the taker fee %s with its ledger entry, got %s %+v", added.TakerFee, got.Fee, got.FeeEntry)
+	}
+
+	// Sell 3 at 53000 as maker: 3 × (53000 − 51000) realized, long 1 left
+	reduced := fill(types.SideSell, trader, other, 53000, 3)
+	got = explain(reduced)
+	if got.Role != types.FeeRoleMaker || got.Side != types.SideSell || !got.Fee.Equal(reduced.MakerFee) {
+		t.Errorf("expected a maker sell paying the maker fee, got %+v", got)
+	}
+	if !got.ClosedQuantity.Equal(math.LegacyNewDec(3)) || !got.RealizedPnL.Equal(math.LegacyNewDec(6000)) {
+		t.Errorf("expected 6000 realized on 3 closed, got %s on %s", got.RealizedPnL, got.ClosedQuantity)
+	}
+	if !got.After.Size.Equal(math.LegacyOneDec()) || !got.After.EntryPrice.Equal(math.LegacyNewDec(51000)) {
+		t.Errorf("expected long 1 at 51000 left, got %+v", got.After)
+	}
+	if !got.BalanceChange().Equal(got.RealizedPnL.Sub(reduced.MakerFee)) {
+		t.Errorf("expected the balance change to be realized PnL less the fee, got %s", got.BalanceChange())
+	}
+
+	// Sell 3 at 50000: closes the 1 at a loss and opens short 2 at 50000
+	flipped := fill(types.SideBuy, other, trader, 50000, 3)
+	got = explain(flipped)
+	if !got.ClosedQuantity.Equal(math.LegacyOneDec()) || !got.RealizedPnL.Equal(math.LegacyNewDec(-1000)) {
+		t.Errorf("expected -1000 realized on 1 closed, got %s on %s", got.RealizedPnL, got.ClosedQuantity)
+	}
+	if !got.After.Size.Equal(math.LegacyNewDec(-2)) || !got.After.EntryPrice.Equal(math.LegacyNewDec(50000)) ||
+		!got.After.OpenedAt.Equal(flipped.Timestamp) || got.Fills != 4 {
+		t.Errorf("expected short 2 at 50000 opened by the flip after 4 fills, got %+v (%d fills)", got.After, got.Fills)
+	}
+
+	// Earlier fills are explained as of their own time
+	if got = explain(opened); !got.Before.IsFlat() || got.Fills != 1 {
+		t.Errorf("expected the first fill to open from flat, got %+v", got)
+	}
+
+	stranger := sdk.AccAddress([]byte("pnl-stranger________")).String()
+	if _, err := k.ExplainTrade(ctx, stranger, opened.TradeID); !errors.Is(err, types.ErrTradeNotFound) {
+		t.Errorf("expected trade not found for another trader, got %v", err)
+	}
+}
+
+// TestTraderActivity tests that a period's activity counts only the fills in
+// the period while realizing PnL against the position opened before it
+func TestTraderActivity(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	trader := sdk.AccAddress([]byte("activity-trader_____")).String()
+	other := sdk.AccAddress([]byte("activity-counterpart")).String()
+
+	step := 0
+	fill := func(marketID string, makerSide types.Side, maker, taker string, price, quantity int64) *types.Trade {
+		t.Helper()
+		step++
+		ctx = ctx.WithBlockTime(start.Add(time.Duration(step) * time.Hour))
+		takerSide := types.SideBuy
+		if makerSide == types.SideBuy {
+			takerSide = types.SideSell
+		}
+		if _, _, err := k.PlaceOrder(ctx, maker, marketID, makerSide, types.OrderTypeLimit,
+			math.LegacyNewDec(price), math.LegacyNewDec(quantity)); err != nil {
+			t.Fatalf("failed to place maker order: %v", err)
+		}
+		_, result, err := k.PlaceOrder(ctx, taker, marketID, takerSide, types.OrderTypeLimit,
+			math.LegacyNewDec(price), math.LegacyNewDec(quantity))
+		if err != nil || len(result.Trades) != 1 {
+			t.Fatalf("expected one trade, got %v (err %v)", result, err)
+		}
+		return result.Trades[0]
+	}
+
+	// Before the period: buy 2 BTC at 50000 as taker
+	fill("BTC-USDC", types.SideSell, other, trader, 50000, 2)
+	from := start.Add(90 * time.Minute)
+
+	// In the period: sell 1 BTC at 52000 as maker and buy 3 ETH at 3000 as taker
+	sold := fill("BTC-USDC", types.SideSell, trader, other, 52000, 1)
+	bought := fill("ETH-USDC", types.SideSell, other, trader, 3000, 3)
+	to := start.Add(210 * time.Minute)
+
+	// After the period
+	fill("BTC-USDC", types.SideSell, trader, other, 53000, 1)
+
+	activity := k.TraderActivity(ctx, trader, from, to)
+	if len(activity.Markets) != 2 || activity.Markets[0].MarketID != "BTC-USDC" || activity.Markets[1].MarketID != "ETH-USDC" {
+		t.Fatalf("expected BTC and ETH activity, got %+v", activity.Markets)
+	}
+	btc := activity.Markets[0]
+	if btc.Trades != 1 || !btc.Volume.Equal(math.LegacyNewDec(52000)) || !btc.Fees.Equal(sold.MakerFee) {
+		t.Errorf("expected one maker fill of 52000, got %+v", btc)
+	}
+	if !btc.RealizedPnL.Equal(math.LegacyNewDec(2000)) || !btc.Position.Size.Equal(math.LegacyOneDec()) {
+		t.Errorf("expected 2000 realized against the earlier entry with long 1 left, got %s %+v", btc.RealizedPnL, btc.Position)
+	}
+	eth := activity.Markets[1]
+	if !eth.Volume.Equal(math.LegacyNewDec(9000)) || !eth.Fees.Equal(bought.TakerFee) || !eth.RealizedPnL.IsZero() {
+		t.Errorf("expected an opening taker fill of 9000, got %+v", eth)
+	}
+
+	trades, volume, fees, realized := activity.Totals()
+	if trades != 2 || !volume.Equal(math.LegacyNewDec(61000)) || !fees.Equal(sold.MakerFee.Add(bought.TakerFee)) ||
+		!realized.Equal(math.LegacyNewDec(2000)) {
+		t.Errorf("unexpected totals %d %s %s %s", trades, volume, fees, realized)
+	}
+
+	if empty := k.TraderActivity(ctx, trader, to.Add(time.Hour), to.Add(2*time.Hour)); len(empty.Markets) != 0 {
+		t.Errorf("expected no activity after the last fill, got %+v", empty.Markets)
+	}
+}
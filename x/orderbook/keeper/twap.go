@@ -413,7 +413,7 @@ func (m *TWAPManager) executeSubOrder(ctx sdk.Context, twapOrder *TWAPOrder) {
 	)
 
 	// Place order
-	m.keeper.SetOrder(ctx, order)
+	m.keeper.saveOrderUpdate(ctx, order)
 
 	// For MVP, simulate immediate execution at market price
 	// In production, this would go through the matching engine
@@ -0,0 +1,48 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// UnitOfWork stages the store writes of one request. Writes made through
+// its context are buffered, read back from the buffer, and reach the
+// parent store once on Commit, in key order and with repeated writes to a
+// key collapsed into the last. A unit that is never committed leaves the
+// parent untouched, so a request that fails half way writes nothing.
+//
+// Units nest: a unit begun inside another commits into the outer buffer,
+// which still reaches the parent store once.
+type UnitOfWork struct {
+	ctx   sdk.Context
+	write func()
+}
+
+// BeginUnitOfWork starts a unit of work on ctx. With units of work disabled
+// the unit writes straight through ctx and Commit does nothing.
+func (k *Keeper) BeginUnitOfWork(ctx sdk.Context) *UnitOfWork {
+	if k.directWrites {
+		return &UnitOfWork{ctx: ctx}
+	}
+	cacheCtx, write := ctx.CacheContext()
+	return &UnitOfWork{ctx: cacheCtx, write: write}
+}
+
+// Context returns the context the unit's writes are made through
+func (u *UnitOfWork) Context() sdk.Context {
+	return u.ctx
+}
+
+// Commit writes the staged writes and events to the parent; later calls
+// do nothing
+func (u *UnitOfWork) Commit() {
+	if u.write != nil {
+		u.write()
+		u.write = nil
+	}
+}
+
+// SetUnitOfWork enables staging each request's writes in a unit of work,
+// the default. Disabled, every write goes to the store as it is made.
+func (k *Keeper) SetUnitOfWork(enabled bool) {
+	k.directWrites = !enabled
+}
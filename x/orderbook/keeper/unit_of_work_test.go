@@ -0,0 +1,52 @@
+package keeper
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestUnitOfWork tests that a unit's writes reach the store only when it is
+// committed, and that an order rejected after writing leaves none behind
+func TestUnitOfWork(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	k.perpetualKeeper = &mockMarginPerpetualKeeper{
+		mark:    math.LegacyNewDec(50000),
+		budgets: map[string]math.LegacyDec{"alice": math.LegacyNewDec(100000), "bob": math.LegacyZeroDec()},
+	}
+
+	uow := k.BeginUnitOfWork(ctx)
+	order, _, err := k.PlaceOrder(uow.Context(), "alice", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(49000), math.LegacyOneDec())
+	if err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+	if k.GetOrder(uow.Context(), order.OrderID) == nil {
+		t.Fatal("expected the order to be read back inside the unit")
+	}
+	if k.GetOrder(ctx, order.OrderID) != nil {
+		t.Fatal("expected the order to be staged until the unit commits")
+	}
+	uow.Commit()
+	if k.GetOrder(ctx, order.OrderID) == nil {
+		t.Fatal("expected the committed order in the store")
+	}
+
+	// The order ID is drawn before the margin check rejects bob's order
+	counter := k.GetStore(ctx).Get(OrderCounterKey)
+	if _, _, err := k.PlaceOrder(ctx, "bob", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(49000), math.LegacyOneDec()); err == nil {
+		t.Fatal("expected bob's order to be rejected")
+	}
+	if got := k.GetStore(ctx).Get(OrderCounterKey); string(got) != string(counter) {
+		t.Errorf("expected the rejected order's writes to be discarded, counter went from %x to %x", counter, got)
+	}
+
+	// Disabled, the same rejection writes through
+	k.SetUnitOfWork(false)
+	if _, _, err := k.PlaceOrder(ctx, "bob", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(49000), math.LegacyOneDec()); err == nil {
+		t.Fatal("expected bob's order to be rejected")
+	}
+	if got := k.GetStore(ctx).Get(OrderCounterKey); string(got) == string(counter) {
+		t.Error("expected direct writes to advance the counter")
+	}
+}
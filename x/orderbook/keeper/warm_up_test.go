@@ -0,0 +1,62 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// mockWarmUpPerpetualKeeper serves bench markets in or out of their
+// post-only warm-up
+type mockWarmUpPerpetualKeeper struct {
+	mockBenchPerpetualKeeper
+	postOnly bool
+}
+
+func (m *mockWarmUpPerpetualKeeper) GetMarket(ctx sdk.Context, marketID string) *Market {
+	market := m.mockBenchPerpetualKeeper.GetMarket(ctx, marketID)
+	market.PostOnly = m.postOnly
+	return market
+}
+
+// TestWarmUpPostOnly tests that a market in its post-only warm-up accepts
+// resting limit orders but rejects market orders and orders or amendments
+// that would trade, and matches normally once the warm-up ends
+func TestWarmUpPostOnly(t *testing.T) {
+	k, ctx := setupBenchKeeper(t)
+	perp := &mockWarmUpPerpetualKeeper{postOnly: true}
+	k.perpetualKeeper = perp
+
+	if _, _, err := k.PlaceOrder(ctx, "maker", "BTC-USDC", types.SideSell, types.OrderTypeLimit, math.LegacyNewDec(50000), math.LegacyOneDec()); err != nil {
+		t.Fatalf("failed to place ask: %v", err)
+	}
+	bid, _, err := k.PlaceOrder(ctx, "taker", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(49000), math.LegacyOneDec())
+	if err != nil {
+		t.Fatalf("failed to place resting bid: %v", err)
+	}
+
+	if _, _, err := k.PlaceOrder(ctx, "taker", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(50000), math.LegacyOneDec()); !errors.Is(err, types.ErrWarmUpPostOnly) {
+		t.Errorf("expected a crossing bid to be rejected, got %v", err)
+	}
+	if _, _, err := k.PlaceOrder(ctx, "taker", "BTC-USDC", types.SideBuy, types.OrderTypeMarket, math.LegacyZeroDec(), math.LegacyOneDec()); !errors.Is(err, types.ErrWarmUpPostOnly) {
+		t.Errorf("expected a market order to be rejected, got %v", err)
+	}
+	if _, _, err := k.AmendOrder(ctx, "taker", bid.OrderID, math.LegacyNewDec(50000), math.LegacyDec{}); !errors.Is(err, types.ErrWarmUpPostOnly) {
+		t.Errorf("expected an amendment crossing the ask to be rejected, got %v", err)
+	}
+	if book := k.GetOrderBook(ctx, "BTC-USDC"); book.BestAsk() == nil || book.BestBid() == nil {
+		t.Fatal("expected both resting orders to remain")
+	}
+
+	perp.postOnly = false
+	_, result, err := k.PlaceOrder(ctx, "taker", "BTC-USDC", types.SideBuy, types.OrderTypeLimit, math.LegacyNewDec(50000), math.LegacyOneDec())
+	if err != nil {
+		t.Fatalf("failed to place bid after the warm-up: %v", err)
+	}
+	if !result.FilledQty.Equal(math.LegacyOneDec()) {
+		t.Errorf("expected the bid to fill after the warm-up, got %s", result.FilledQty)
+	}
+}
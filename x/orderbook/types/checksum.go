@@ -0,0 +1,58 @@
+package types
+
+import (
+	"hash/crc32"
+	"strings"
+)
+
+// ChecksumDepth is the maximum number of levels per side covered by a depth checksum
+const ChecksumDepth = 25
+
+// DepthChecksum computes the CRC32 (IEEE) checksum of the top levels of a book.
+// Each level is [price, quantity] exactly as sent to clients. Up to ChecksumDepth
+// levels per side are interleaved best-first as bid, ask, bid, ask... and each
+// present level contributes "price:quantity"; the parts are joined with ':'.
+// The CRC is returned as a signed 32-bit integer, as OKX sends it, so existing
+// client libraries can verify it.
+func DepthChecksum(bids, asks [][]string) int32 {
+	n := len(bids)
+	if len(asks) > n {
+		n = len(asks)
+	}
+	if n > ChecksumDepth {
+		n = ChecksumDepth
+	}
+
+	parts := make([]string, 0, 4*n)
+	for i := 0; i < n; i++ {
+		if i < len(bids) && len(bids[i]) >= 2 {
+			parts = append(parts, bids[i][0], bids[i][1])
+		}
+		if i < len(asks) && len(asks[i]) >= 2 {
+			parts = append(parts, asks[i][0], asks[i][1])
+		}
+	}
+	return int32(crc32.ChecksumIEEE([]byte(strings.Join(parts, ":"))))
+}
+
+// Levels returns the top depth levels per side as [price, quantity] strings.
+// A depth of zero or less returns all levels.
+func (ob *OrderBook) Levels(depth int) (bids, asks [][]string) {
+	return formatLevels(ob.Bids, depth), formatLevels(ob.Asks, depth)
+}
+
+// Checksum returns the depth checksum of the top depth levels of the book
+func (ob *OrderBook) Checksum(depth int) int32 {
+	return DepthChecksum(ob.Levels(depth))
+}
+
+func formatLevels(levels []*PriceLevel, depth int) [][]string {
+	if depth <= 0 || depth > len(levels) {
+		depth = len(levels)
+	}
+	result := make([][]string, 0, depth)
+	for _, level := range levels[:depth] {
+		result = append(result, []string{level.Price.String(), level.Quantity.String()})
+	}
+	return result
+}
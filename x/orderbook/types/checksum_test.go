@@ -0,0 +1,53 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestDepthChecksum tests the interleaved CRC32 depth checksum
+func TestDepthChecksum(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bids     [][]string
+		asks     [][]string
+		expected int32
+	}{
+		{
+			name:     "interleaved levels",
+			bids:     [][]string{{"3366.1", "7"}, {"3366", "6"}},
+			asks:     [][]string{{"3366.8", "9"}, {"3368", "8"}},
+			expected: -1881014294, // OKX documentation example
+		},
+		{
+			name:     "uneven sides",
+			bids:     [][]string{{"100", "1"}, {"99", "3"}},
+			asks:     [][]string{{"101", "2"}},
+			expected: -563951543,
+		},
+		{
+			name:     "empty book",
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DepthChecksum(tc.bids, tc.asks); got != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestDepthChecksumCapsLevels tests that levels beyond ChecksumDepth are ignored
+func TestDepthChecksumCapsLevels(t *testing.T) {
+	bids := make([][]string, 0, ChecksumDepth+5)
+	for i := 0; i < ChecksumDepth+5; i++ {
+		bids = append(bids, []string{fmt.Sprintf("%d", 1000-i), "1"})
+	}
+
+	if DepthChecksum(bids, nil) != DepthChecksum(bids[:ChecksumDepth], nil) {
+		t.Error("expected levels beyond ChecksumDepth to be excluded")
+	}
+}
@@ -39,4 +39,61 @@ var (
 	// Batch operation errors
 	ErrInvalidOrder  = errors.Register("orderbook", 60, "invalid order")
 	ErrBatchTooLarge = errors.Register("orderbook", 61, "batch size exceeds maximum (100)")
+
+	// Settlement batch errors
+	ErrSettlementBatchNotFound  = errors.Register("orderbook", 70, "settlement batch not found")
+	ErrInvalidBatchSequence     = errors.Register("orderbook", 71, "settlement batch ID out of sequence")
+	ErrInvalidMerkleRoot        = errors.Register("orderbook", 72, "invalid merkle root")
+	ErrInvalidMerkleProof       = errors.Register("orderbook", 73, "invalid merkle proof")
+	ErrDisputeWindowClosed      = errors.Register("orderbook", 74, "dispute window closed")
+	ErrSettlementBatchRejected  = errors.Register("orderbook", 75, "settlement batch already rejected")
+	ErrSettlementOperatorNotSet = errors.Register("orderbook", 76, "settlement operator not configured")
+	ErrDisputeRejected          = errors.Register("orderbook", 77, "disputed trade is valid")
+
+	// Market maker protection errors
+	ErrInvalidMMPConfig = errors.Register("orderbook", 80, "invalid market maker protection config")
+	ErrMMPFrozen        = errors.Register("orderbook", 81, "market maker protection triggered, new quotes are blocked")
+
+	// Liquidity provider obligation errors
+	ErrInvalidLPObligation  = errors.Register("orderbook", 85, "invalid liquidity provider obligation")
+	ErrLPObligationNotFound = errors.Register("orderbook", 86, "liquidity provider obligation not found")
+
+	// History query errors
+	ErrInvalidCursor = errors.Register("orderbook", 87, "invalid pagination cursor")
+
+	// Order expiry errors
+	ErrInvalidExpiry = errors.Register("orderbook", 89, "invalid order expiry")
+
+	// Fee ledger errors
+	ErrInvalidFeeSplit       = errors.Register("orderbook", 88, "invalid fee split")
+	ErrInvalidFeeTokenConfig = errors.Register("orderbook", 91, "invalid fee token config")
+
+	// Open order limit errors
+	ErrMarketOrderLimit   = errors.Register("orderbook", 92, "market resting order limit reached")
+	ErrTraderOrderLimit   = errors.Register("orderbook", 93, "trader resting order limit reached")
+	ErrInvalidOrderLimits = errors.Register("orderbook", 94, "invalid order limits")
+
+	// Trade PnL explanation errors
+	ErrTradeNotFound = errors.Register("orderbook", 95, "trade not found")
+
+	// Spread instrument errors
+	ErrSpreadNotFound    = errors.Register("orderbook", 96, "spread not found")
+	ErrInvalidSpread     = errors.Register("orderbook", 97, "invalid spread")
+	ErrSpreadExists      = errors.Register("orderbook", 98, "spread already exists")
+	ErrSpreadLegUnpriced = errors.Register("orderbook", 99, "spread leg has no mark price")
+
+	// Resting order margin re-check errors
+	ErrInvalidMarginRecheck = errors.Register("orderbook", 100, "invalid margin recheck params")
+
+	// Market order price protection errors
+	ErrInvalidSlippage = errors.Register("orderbook", 101, "invalid max slippage")
+
+	// Maker rebate errors
+	ErrInvalidMakerRebate = errors.Register("orderbook", 102, "invalid maker rebate params")
+
+	// Market warm-up errors
+	ErrWarmUpPostOnly = errors.Register("orderbook", 103, "market is in its post-only warm-up")
+
+	// Genesis errors
+	ErrInvalidGenesis = errors.Register("orderbook", 90, "invalid genesis state")
 )
@@ -0,0 +1,22 @@
+package types
+
+import "time"
+
+// Event types in the global event log
+const (
+	EventTypeOrder = "order"
+	EventTypeTrade = "trade"
+)
+
+// Event is an entry of the global event log: an order update or a trade,
+// numbered by a sequence shared across all markets. Sequence numbers are
+// strictly increasing, so consumers can order events from different markets
+// and resume from the last one they processed.
+type Event struct {
+	Seq       uint64
+	Type      string // EventTypeOrder or EventTypeTrade
+	MarketID  string
+	Order     *Order `json:",omitempty"` // order state after the update
+	Trade     *Trade `json:",omitempty"`
+	Timestamp time.Time
+}
@@ -0,0 +1,64 @@
+package types
+
+import (
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// Execution is one fill of an order, recorded when the trade is stored.
+// CumulativeQty is the order's filled quantity including this fill.
+type Execution struct {
+	OrderID       string
+	TradeID       string
+	MarketID      string
+	Trader        string
+	Side          Side
+	Liquidity     string // FeeRoleMaker or FeeRoleTaker
+	Price         math.LegacyDec
+	Quantity      math.LegacyDec
+	Fee           math.LegacyDec
+	CumulativeQty math.LegacyDec
+	Timestamp     time.Time
+}
+
+// NewExecutions returns the taker's and the maker's execution of a trade,
+// before cumulative quantities are known
+func NewExecutions(trade *Trade) (taker, maker *Execution) {
+	makerSide := SideBuy
+	if trade.TakerSide == SideBuy {
+		makerSide = SideSell
+	}
+	taker = &Execution{
+		OrderID:   trade.TakerOrderID,
+		TradeID:   trade.TradeID,
+		MarketID:  trade.MarketID,
+		Trader:    trade.Taker,
+		Side:      trade.TakerSide,
+		Liquidity: FeeRoleTaker,
+		Price:     trade.Price,
+		Quantity:  trade.Quantity,
+		Fee:       nonNilDec(trade.TakerFee),
+		Timestamp: trade.Timestamp,
+	}
+	maker = &Execution{
+		OrderID:   trade.MakerOrderID,
+		TradeID:   trade.TradeID,
+		MarketID:  trade.MarketID,
+		Trader:    trade.Maker,
+		Side:      makerSide,
+		Liquidity: FeeRoleMaker,
+		Price:     trade.Price,
+		Quantity:  trade.Quantity,
+		Fee:       nonNilDec(trade.MakerFee),
+		Timestamp: trade.Timestamp,
+	}
+	return taker, maker
+}
+
+func nonNilDec(d math.LegacyDec) math.LegacyDec {
+	if d.IsNil() {
+		return math.LegacyZeroDec()
+	}
+	return d
+}
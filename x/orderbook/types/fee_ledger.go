@@ -0,0 +1,179 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// FeeDayDuration is the length of a fee rollup day. Day n covers
+// [n × FeeDayDuration, (n+1) × FeeDayDuration) since the Unix epoch, in UTC.
+const FeeDayDuration = 24 * time.Hour
+
+// FeeDay returns the fee rollup day containing t
+func FeeDay(t time.Time) int64 {
+	return t.Unix() / int64(FeeDayDuration/time.Second)
+}
+
+// FeeDayStart returns the start time of a fee rollup day
+func FeeDayStart(day int64) time.Time {
+	return time.Unix(day*int64(FeeDayDuration/time.Second), 0).UTC()
+}
+
+// Fee roles
+const (
+	FeeRoleMaker = "maker"
+	FeeRoleTaker = "taker"
+)
+
+// Fee destinations
+const (
+	FeeDestinationInsuranceFund = "insurance_fund"
+	FeeDestinationRiverPool     = "riverpool"
+	FeeDestinationTreasury      = "treasury"
+)
+
+// FeeSplit is the share of every trading fee each destination receives. The
+// treasury takes whatever the other shares leave, so a fee always splits
+// exactly.
+type FeeSplit struct {
+	InsuranceFund math.LegacyDec
+	RiverPool     math.LegacyDec
+	Treasury      math.LegacyDec
+}
+
+// DefaultFeeSplit sends 20% of fees to the insurance fund, 50% to the
+// riverpools and 30% to the treasury
+func DefaultFeeSplit() *FeeSplit {
+	return &FeeSplit{
+		InsuranceFund: math.LegacyNewDecWithPrec(2, 1),
+		RiverPool:     math.LegacyNewDecWithPrec(5, 1),
+		Treasury:      math.LegacyNewDecWithPrec(3, 1),
+	}
+}
+
+// Validate checks that the shares are non-negative and sum to one
+func (s *FeeSplit) Validate() error {
+	for _, share := range []math.LegacyDec{s.InsuranceFund, s.RiverPool, s.Treasury} {
+		if share.IsNil() || share.IsNegative() {
+			return fmt.Errorf("%w: shares must be non-negative", ErrInvalidFeeSplit)
+		}
+	}
+	if !s.InsuranceFund.Add(s.RiverPool).Add(s.Treasury).Equal(math.LegacyOneDec()) {
+		return fmt.Errorf("%w: shares must sum to 1", ErrInvalidFeeSplit)
+	}
+	return nil
+}
+
+// Apply splits a fee across the destinations
+func (s *FeeSplit) Apply(fee math.LegacyDec) (insuranceFund, riverPool, treasury math.LegacyDec) {
+	insuranceFund = fee.Mul(s.InsuranceFund)
+	riverPool = fee.Mul(s.RiverPool)
+	return insuranceFund, riverPool, fee.Sub(insuranceFund).Sub(riverPool)
+}
+
+// FeeEntry is one fee charged on one side of a trade and its split. A fee
+// paid in the fee token has a zero USDC amount and split, and records the
+// token amount and the USDC fee it replaced.
+type FeeEntry struct {
+	TradeID       string
+	MarketID      string
+	Trader        string
+	Role          string // FeeRoleMaker or FeeRoleTaker
+	Amount        math.LegacyDec
+	InsuranceFund math.LegacyDec
+	RiverPool     math.LegacyDec
+	Treasury      math.LegacyDec
+	Timestamp     time.Time
+
+	FeeDenom       string
+	FeeTokenAmount math.Int
+	ReplacedFee    math.LegacyDec
+}
+
+// PaidInFeeToken reports whether the fee was paid in the fee token
+func (e *FeeEntry) PaidInFeeToken() bool {
+	return e.FeeDenom != ""
+}
+
+// FeeRollup is one market's fees over one day. Settled is set once the day
+// has ended and its shares were credited: the riverpool share to the pools,
+// the insurance fund and treasury shares to the protocol fee balances.
+type FeeRollup struct {
+	Day           int64
+	MarketID      string
+	Entries       int64
+	MakerFees     math.LegacyDec
+	TakerFees     math.LegacyDec
+	InsuranceFund math.LegacyDec
+	RiverPool     math.LegacyDec
+	Treasury      math.LegacyDec
+	Settled       bool
+
+	// Fees paid in the fee token, in its base units
+	FeeTokenFees math.Int
+}
+
+// NewFeeRollup creates an empty rollup
+func NewFeeRollup(day int64, marketID string) *FeeRollup {
+	return &FeeRollup{
+		Day:           day,
+		MarketID:      marketID,
+		MakerFees:     math.LegacyZeroDec(),
+		TakerFees:     math.LegacyZeroDec(),
+		InsuranceFund: math.LegacyZeroDec(),
+		RiverPool:     math.LegacyZeroDec(),
+		Treasury:      math.LegacyZeroDec(),
+		FeeTokenFees:  math.ZeroInt(),
+	}
+}
+
+// Add counts an entry in the rollup
+func (r *FeeRollup) Add(entry *FeeEntry) {
+	r.Entries++
+	if entry.PaidInFeeToken() {
+		if r.FeeTokenFees.IsNil() { // rollups stored before fee tokens
+			r.FeeTokenFees = math.ZeroInt()
+		}
+		r.FeeTokenFees = r.FeeTokenFees.Add(entry.FeeTokenAmount)
+	}
+	if entry.Role == FeeRoleMaker {
+		r.MakerFees = r.MakerFees.Add(entry.Amount)
+	} else {
+		r.TakerFees = r.TakerFees.Add(entry.Amount)
+	}
+	r.InsuranceFund = r.InsuranceFund.Add(entry.InsuranceFund)
+	r.RiverPool = r.RiverPool.Add(entry.RiverPool)
+	r.Treasury = r.Treasury.Add(entry.Treasury)
+}
+
+// Total returns all fees charged in the rollup
+func (r *FeeRollup) Total() math.LegacyDec {
+	return r.MakerFees.Add(r.TakerFees)
+}
+
+// FeeBalances are the cumulative settled fee shares per destination
+type FeeBalances struct {
+	InsuranceFund math.LegacyDec
+	RiverPool     math.LegacyDec
+	Treasury      math.LegacyDec
+	SettledDay    int64 // last settled day, -1 before the first settlement
+}
+
+// NewFeeBalances creates empty balances
+func NewFeeBalances() *FeeBalances {
+	return &FeeBalances{
+		InsuranceFund: math.LegacyZeroDec(),
+		RiverPool:     math.LegacyZeroDec(),
+		Treasury:      math.LegacyZeroDec(),
+		SettledDay:    -1,
+	}
+}
+
+// Credit adds a settled rollup's shares
+func (b *FeeBalances) Credit(r *FeeRollup) {
+	b.InsuranceFund = b.InsuranceFund.Add(r.InsuranceFund)
+	b.RiverPool = b.RiverPool.Add(r.RiverPool)
+	b.Treasury = b.Treasury.Add(r.Treasury)
+}
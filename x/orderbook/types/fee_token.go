@@ -0,0 +1,62 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// FeeTokenConfig lets traders pay trading fees in an alternative token, such
+// as a protocol token, at a discount. A fee paid in the token is the USDC fee
+// less the discount, converted at the token's rate and rounded up to a whole
+// base unit. Rate is the USDC value of one base unit of Denom; a rate source
+// attached to the keeper takes precedence over it.
+type FeeTokenConfig struct {
+	Enabled  bool           `json:"enabled"`
+	Denom    string         `json:"denom"`
+	Discount math.LegacyDec `json:"discount"`
+	Rate     math.LegacyDec `json:"rate"`
+}
+
+// DefaultFeeTokenConfig is disabled, with a 25% discount once enabled
+func DefaultFeeTokenConfig() *FeeTokenConfig {
+	return &FeeTokenConfig{
+		Discount: math.LegacyNewDecWithPrec(25, 2),
+		Rate:     math.LegacyZeroDec(),
+	}
+}
+
+// Validate checks the denom of an enabled config, that the discount is in
+// [0, 1) and that the rate is not negative
+func (c *FeeTokenConfig) Validate() error {
+	if c.Enabled {
+		if err := sdk.ValidateDenom(c.Denom); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidFeeTokenConfig, err)
+		}
+	}
+	if c.Discount.IsNil() || c.Discount.IsNegative() || c.Discount.GTE(math.LegacyOneDec()) {
+		return fmt.Errorf("%w: discount must be in [0, 1)", ErrInvalidFeeTokenConfig)
+	}
+	if c.Rate.IsNil() || c.Rate.IsNegative() {
+		return fmt.Errorf("%w: rate must not be negative", ErrInvalidFeeTokenConfig)
+	}
+	return nil
+}
+
+// FeeTokenAmount returns how much of the token pays a USDC fee at a rate:
+// the discounted fee over the rate, rounded up
+func (c *FeeTokenConfig) FeeTokenAmount(fee, rate math.LegacyDec) math.Int {
+	discounted := fee.Mul(math.LegacyOneDec().Sub(c.Discount))
+	return discounted.Quo(rate).Ceil().TruncateInt()
+}
+
+// FeePreference is a trader's choice of fee currency. While PayInFeeToken is
+// set and the fee token is enabled, fees are charged in the token whenever the
+// trader's balance covers them, and in USDC otherwise.
+type FeePreference struct {
+	Trader        string    `json:"trader"`
+	PayInFeeToken bool      `json:"pay_in_fee_token"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"math/big"
+	"math/bits"
+
+	"cosmossdk.io/math"
+)
+
+// MaxFixedDecimals is the most decimals a fixed-point value can carry, the
+// precision of LegacyDec
+const MaxFixedDecimals = math.LegacyPrecision
+
+// fixedDivisors[d] is 10^(18-d), the factor between LegacyDec's internal
+// integer and a fixed-point integer with d decimals
+var fixedDivisors = func() [MaxFixedDecimals + 1]*big.Int {
+	var divisors [MaxFixedDecimals + 1]*big.Int
+	for d := range divisors {
+		divisors[d] = new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(MaxFixedDecimals-d)), nil)
+	}
+	return divisors
+}()
+
+// Precision is a market's price and size decimals. Prices and sizes that are
+// multiples of 10^-decimals convert exactly to int64 fixed-point values, and
+// the notional of one fill carries PriceDecimals+SizeDecimals decimals.
+type Precision struct {
+	PriceDecimals uint32
+	SizeDecimals  uint32
+}
+
+// Valid reports whether the notional of a fill fits LegacyDec exactly, so
+// fixed-point and Dec arithmetic produce identical results
+func (p Precision) Valid() bool {
+	return p.PriceDecimals+p.SizeDecimals <= MaxFixedDecimals
+}
+
+// ToFixed converts d to an integer with decimals decimals. ok is false if d
+// has more decimals or does not fit an int64.
+func ToFixed(d math.LegacyDec, decimals uint32) (v int64, ok bool) {
+	if d.IsNil() || decimals > MaxFixedDecimals {
+		return 0, false
+	}
+	q, r := new(big.Int).QuoRem(d.BigInt(), fixedDivisors[decimals], new(big.Int))
+	if r.Sign() != 0 || !q.IsInt64() {
+		return 0, false
+	}
+	return q.Int64(), true
+}
+
+// FromFixed converts an integer with decimals decimals back to a Dec
+func FromFixed(v int64, decimals uint32) math.LegacyDec {
+	return math.LegacyNewDecWithPrec(v, int64(decimals))
+}
+
+// Uint128 is an unsigned 128-bit integer, wide enough for the sum of many
+// int64 price × int64 size products
+type Uint128 struct {
+	Hi, Lo uint64
+}
+
+// MulUint64 returns the full 128-bit product a × b
+func MulUint64(a, b uint64) Uint128 {
+	hi, lo := bits.Mul64(a, b)
+	return Uint128{Hi: hi, Lo: lo}
+}
+
+// Add returns u + v. ok is false if the sum overflows 128 bits.
+func (u Uint128) Add(v Uint128) (sum Uint128, ok bool) {
+	lo, carry := bits.Add64(u.Lo, v.Lo, 0)
+	hi, carry := bits.Add64(u.Hi, v.Hi, carry)
+	return Uint128{Hi: hi, Lo: lo}, carry == 0
+}
+
+// IsZero reports whether u is zero
+func (u Uint128) IsZero() bool {
+	return u.Hi == 0 && u.Lo == 0
+}
+
+// ToDec converts u, carrying decimals decimals, to a Dec
+func (u Uint128) ToDec(decimals uint32) math.LegacyDec {
+	i := new(big.Int).SetUint64(u.Hi)
+	i.Lsh(i, 64)
+	i.Or(i, new(big.Int).SetUint64(u.Lo))
+	return math.LegacyNewDecFromBigIntWithPrec(i, int64(decimals))
+}
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"math"
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+)
+
+// TestToFixed tests exact conversion to and from fixed-point integers
+func TestToFixed(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		decimals uint32
+		want     int64
+		ok       bool
+	}{
+		{"integer", "50000", 2, 5000000, true},
+		{"fractional", "50000.25", 2, 5000025, true},
+		{"negative", "-1.5", 1, -15, true},
+		{"zero decimals", "7", 0, 7, true},
+		{"full precision", "0.000000000000000001", 18, 1, true},
+		{"too many decimals", "50000.255", 2, 0, false},
+		{"int64 overflow", "9223372036854775808", 0, 0, false},
+		{"decimals beyond Dec", "1", 19, 0, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := sdkmath.LegacyMustNewDecFromStr(tc.value)
+			got, ok := ToFixed(d, tc.decimals)
+			if ok != tc.ok || got != tc.want {
+				t.Fatalf("expected (%d, %v), got (%d, %v)", tc.want, tc.ok, got, ok)
+			}
+			if ok && !FromFixed(got, tc.decimals).Equal(d) {
+				t.Errorf("expected round trip to %s, got %s", d, FromFixed(got, tc.decimals))
+			}
+		})
+	}
+}
+
+// TestUint128 tests 128-bit products, sums and conversion to Dec
+func TestUint128(t *testing.T) {
+	product := MulUint64(math.MaxUint64, 2)
+	if product.Hi != 1 || product.Lo != math.MaxUint64-1 {
+		t.Fatalf("unexpected product %+v", product)
+	}
+
+	sum, ok := product.Add(MulUint64(1, 2))
+	if !ok || sum.Hi != 2 || sum.Lo != 0 {
+		t.Fatalf("expected carry into the high word, got %+v ok=%v", sum, ok)
+	}
+	if _, ok := (Uint128{Hi: math.MaxUint64, Lo: math.MaxUint64}).Add(MulUint64(1, 1)); ok {
+		t.Error("expected overflow past 128 bits")
+	}
+
+	// 2^65 with 6 decimals
+	want := sdkmath.LegacyMustNewDecFromStr("36893488147419.103232")
+	if got := sum.ToDec(6); !got.Equal(want) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if !(Uint128{}).IsZero() || sum.IsZero() {
+		t.Error("unexpected IsZero result")
+	}
+}
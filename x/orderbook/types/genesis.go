@@ -0,0 +1,229 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GenesisState is the orderbook module's exported state. Orders holds the
+// resting orders of every book in book priority (bids best first, then asks
+// best first, FIFO within a level), so importing them in order restores time
+// priority. Order books themselves are rebuilt from the orders.
+type GenesisState struct {
+	Orders       []*Order     `json:"orders"`
+	OrderCounter uint64       `json:"order_counter"`
+	TradeCounter uint64       `json:"trade_counter"`
+	EventSeq     uint64       `json:"event_seq"`
+	MMPConfigs   []*MMPConfig `json:"mmp_configs"`
+
+	LPObligations []*LPObligation `json:"lp_obligations"`
+
+	FeeSplit    *FeeSplit    `json:"fee_split,omitempty"`
+	FeeRollups  []*FeeRollup `json:"fee_rollups"`
+	FeeBalances *FeeBalances `json:"fee_balances,omitempty"`
+
+	FeeTokenConfig *FeeTokenConfig  `json:"fee_token_config,omitempty"`
+	FeePreferences []*FeePreference `json:"fee_preferences"`
+
+	OrderLimits   *OrderLimits         `json:"order_limits,omitempty"`
+	MarginRecheck *MarginRecheckParams `json:"margin_recheck,omitempty"`
+
+	MakerRebate *MakerRebateParams `json:"maker_rebate,omitempty"`
+	MakerPoints []*MakerPoints     `json:"maker_points"`
+
+	Spreads      []*Spread `json:"spreads"`
+	SpreadOrders []*Order  `json:"spread_orders"`
+
+	// SettlementOperator is the address allowed to commit Merkle settlement
+	// batches; empty disables commitments. LastSettlementBatchID is the last
+	// committed batch, so the operator's sequence continues after an import.
+	SettlementOperator    string `json:"settlement_operator,omitempty"`
+	LastSettlementBatchID uint64 `json:"last_settlement_batch_id"`
+}
+
+// DefaultGenesis returns an empty orderbook state
+func DefaultGenesis() *GenesisState {
+	return &GenesisState{
+		Orders:         make([]*Order, 0),
+		MMPConfigs:     make([]*MMPConfig, 0),
+		LPObligations:  make([]*LPObligation, 0),
+		FeeRollups:     make([]*FeeRollup, 0),
+		FeePreferences: make([]*FeePreference, 0),
+		MakerPoints:    make([]*MakerPoints, 0),
+		Spreads:        make([]*Spread, 0),
+		SpreadOrders:   make([]*Order, 0),
+	}
+}
+
+// Validate checks that every order can rest on a book and that the counters
+// are past every exported order ID and event sequence number
+func (gs *GenesisState) Validate() error {
+	seen := make(map[string]bool, len(gs.Orders))
+	for _, order := range gs.Orders {
+		if order == nil || order.OrderID == "" {
+			return fmt.Errorf("%w: order without ID", ErrInvalidGenesis)
+		}
+		if seen[order.OrderID] {
+			return fmt.Errorf("%w: duplicate order %s", ErrInvalidGenesis, order.OrderID)
+		}
+		seen[order.OrderID] = true
+
+		if order.Trader == "" || order.MarketID == "" {
+			return fmt.Errorf("%w: order %s has no trader or market", ErrInvalidGenesis, order.OrderID)
+		}
+		if order.OrderType != OrderTypeLimit || !order.IsActive() {
+			return fmt.Errorf("%w: order %s is not a resting limit order", ErrInvalidGenesis, order.OrderID)
+		}
+		if order.Side != SideBuy && order.Side != SideSell {
+			return fmt.Errorf("%w: order %s has no side", ErrInvalidGenesis, order.OrderID)
+		}
+		if order.Price.IsNil() || !order.Price.IsPositive() ||
+			order.Quantity.IsNil() || order.FilledQty.IsNil() || !order.RemainingQty().IsPositive() {
+			return fmt.Errorf("%w: order %s has no remaining quantity at a positive price", ErrInvalidGenesis, order.OrderID)
+		}
+
+		var n uint64
+		if _, err := fmt.Sscanf(order.OrderID, "order-%d", &n); err == nil && n > gs.OrderCounter {
+			return fmt.Errorf("%w: order counter %d is behind order %s", ErrInvalidGenesis, gs.OrderCounter, order.OrderID)
+		}
+		if order.Seq > gs.EventSeq {
+			return fmt.Errorf("%w: event sequence %d is behind order %s", ErrInvalidGenesis, gs.EventSeq, order.OrderID)
+		}
+	}
+
+	configs := make(map[string]bool, len(gs.MMPConfigs))
+	for _, config := range gs.MMPConfigs {
+		if config == nil {
+			return fmt.Errorf("%w: empty mmp config", ErrInvalidGenesis)
+		}
+		if err := config.Validate(); err != nil {
+			return fmt.Errorf("%w: mmp config %s/%s: %v", ErrInvalidGenesis, config.Trader, config.MarketID, err)
+		}
+		key := config.Trader + "/" + config.MarketID
+		if configs[key] {
+			return fmt.Errorf("%w: duplicate mmp config %s", ErrInvalidGenesis, key)
+		}
+		configs[key] = true
+	}
+
+	obligations := make(map[string]bool, len(gs.LPObligations))
+	for _, obligation := range gs.LPObligations {
+		if obligation == nil {
+			return fmt.Errorf("%w: empty lp obligation", ErrInvalidGenesis)
+		}
+		if err := obligation.Validate(); err != nil {
+			return fmt.Errorf("%w: lp obligation %s/%s: %v", ErrInvalidGenesis, obligation.Trader, obligation.MarketID, err)
+		}
+		key := obligation.Trader + "/" + obligation.MarketID
+		if obligations[key] {
+			return fmt.Errorf("%w: duplicate lp obligation %s", ErrInvalidGenesis, key)
+		}
+		obligations[key] = true
+	}
+
+	if gs.FeeSplit != nil {
+		if err := gs.FeeSplit.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidGenesis, err)
+		}
+	}
+	rollups := make(map[string]bool, len(gs.FeeRollups))
+	for _, rollup := range gs.FeeRollups {
+		if rollup == nil || rollup.MarketID == "" {
+			return fmt.Errorf("%w: fee rollup without market", ErrInvalidGenesis)
+		}
+		key := fmt.Sprintf("%d/%s", rollup.Day, rollup.MarketID)
+		if rollups[key] {
+			return fmt.Errorf("%w: duplicate fee rollup %s", ErrInvalidGenesis, key)
+		}
+		rollups[key] = true
+	}
+
+	if gs.FeeTokenConfig != nil {
+		if err := gs.FeeTokenConfig.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidGenesis, err)
+		}
+	}
+	prefs := make(map[string]bool, len(gs.FeePreferences))
+	for _, pref := range gs.FeePreferences {
+		if pref == nil || pref.Trader == "" {
+			return fmt.Errorf("%w: fee preference without trader", ErrInvalidGenesis)
+		}
+		if prefs[pref.Trader] {
+			return fmt.Errorf("%w: duplicate fee preference %s", ErrInvalidGenesis, pref.Trader)
+		}
+		prefs[pref.Trader] = true
+	}
+
+	if gs.OrderLimits != nil {
+		if err := gs.OrderLimits.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidGenesis, err)
+		}
+	}
+	if gs.MarginRecheck != nil {
+		if err := gs.MarginRecheck.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidGenesis, err)
+		}
+	}
+	if gs.MakerRebate != nil {
+		if err := gs.MakerRebate.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidGenesis, err)
+		}
+	}
+	makers := make(map[string]bool, len(gs.MakerPoints))
+	for _, points := range gs.MakerPoints {
+		if points == nil || points.Trader == "" {
+			return fmt.Errorf("%w: maker points without trader", ErrInvalidGenesis)
+		}
+		if makers[points.Trader] {
+			return fmt.Errorf("%w: duplicate maker points %s", ErrInvalidGenesis, points.Trader)
+		}
+		makers[points.Trader] = true
+	}
+
+	spreads := make(map[string]bool, len(gs.Spreads))
+	for _, spread := range gs.Spreads {
+		if spread == nil {
+			return fmt.Errorf("%w: empty spread", ErrInvalidGenesis)
+		}
+		if err := spread.Validate(); err != nil {
+			return fmt.Errorf("%w: spread %s: %v", ErrInvalidGenesis, spread.SpreadID, err)
+		}
+		if spreads[spread.SpreadID] {
+			return fmt.Errorf("%w: duplicate spread %s", ErrInvalidGenesis, spread.SpreadID)
+		}
+		spreads[spread.SpreadID] = true
+	}
+	for _, order := range gs.SpreadOrders {
+		if order == nil || order.OrderID == "" {
+			return fmt.Errorf("%w: spread order without ID", ErrInvalidGenesis)
+		}
+		if seen[order.OrderID] {
+			return fmt.Errorf("%w: duplicate order %s", ErrInvalidGenesis, order.OrderID)
+		}
+		seen[order.OrderID] = true
+
+		if order.Trader == "" || !spreads[order.MarketID] {
+			return fmt.Errorf("%w: spread order %s has no trader or an unknown spread", ErrInvalidGenesis, order.OrderID)
+		}
+		if order.OrderType != OrderTypeLimit || !order.IsActive() || (order.Side != SideBuy && order.Side != SideSell) {
+			return fmt.Errorf("%w: spread order %s is not a resting limit order", ErrInvalidGenesis, order.OrderID)
+		}
+		// Spread prices may be zero or negative
+		if order.Price.IsNil() || order.Quantity.IsNil() || order.FilledQty.IsNil() || !order.RemainingQty().IsPositive() {
+			return fmt.Errorf("%w: spread order %s has no price or remaining quantity", ErrInvalidGenesis, order.OrderID)
+		}
+
+		var n uint64
+		if _, err := fmt.Sscanf(order.OrderID, "order-%d", &n); err == nil && n > gs.OrderCounter {
+			return fmt.Errorf("%w: order counter %d is behind order %s", ErrInvalidGenesis, gs.OrderCounter, order.OrderID)
+		}
+	}
+
+	if gs.SettlementOperator != "" {
+		if _, err := sdk.AccAddressFromBech32(gs.SettlementOperator); err != nil {
+			return fmt.Errorf("%w: settlement operator: %v", ErrInvalidGenesis, err)
+		}
+	}
+	return nil
+}
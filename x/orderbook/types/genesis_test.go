@@ -0,0 +1,30 @@
+package types
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+)
+
+// TestGenesisValidate tests rejection of orders that cannot rest on a book
+func TestGenesisValidate(t *testing.T) {
+	order := NewOrder("order-3", "trader1", "BTC-USDC", SideBuy, OrderTypeLimit,
+		math.LegacyNewDec(50000), math.LegacyOneDec())
+
+	gs := DefaultGenesis()
+	gs.Orders = []*Order{order}
+	gs.OrderCounter = 2
+	if err := gs.Validate(); err == nil {
+		t.Error("expected error for counter behind order ID")
+	}
+
+	gs.OrderCounter = 3
+	if err := gs.Validate(); err != nil {
+		t.Errorf("expected valid genesis, got %v", err)
+	}
+
+	order.Cancel()
+	if err := gs.Validate(); err == nil {
+		t.Error("expected error for cancelled order")
+	}
+}
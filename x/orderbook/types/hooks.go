@@ -0,0 +1,98 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// TradeHooks lets other modules react to fills, position changes and
+// liquidations without the matching engine or the clearinghouse knowing about
+// them. Hooks run after the state change has been applied, inside the same
+// block; an error returned by a hook discards the hook's own writes and is
+// logged, but never undoes the trade, the position change or the liquidation.
+type TradeHooks interface {
+	// AfterTradeExecuted is called once per trade, after the trade has been
+	// sequenced and its fees recorded
+	AfterTradeExecuted(ctx sdk.Context, trade *Trade) error
+
+	// AfterPositionChanged is called after a trader's position in a market
+	// has been opened, changed or closed by a trade or a deleverage. The
+	// position, if any remains, is read from the perpetual module.
+	AfterPositionChanged(ctx sdk.Context, trader, marketID string) error
+
+	// AfterLiquidation is called after size of a trader's position has been
+	// liquidated at price
+	AfterLiquidation(ctx sdk.Context, trader, marketID string, size, price math.LegacyDec) error
+}
+
+// MultiTradeHooks calls each of its hooks in order. Each hook runs in its own
+// cache context, so one failing or panicking hook neither blocks the others
+// nor leaves partial writes behind.
+type MultiTradeHooks []TradeHooks
+
+var _ TradeHooks = MultiTradeHooks{}
+
+// NewMultiTradeHooks combines hooks, skipping nil ones
+func NewMultiTradeHooks(hooks ...TradeHooks) MultiTradeHooks {
+	multi := make(MultiTradeHooks, 0, len(hooks))
+	for _, h := range hooks {
+		if h != nil {
+			multi = append(multi, h)
+		}
+	}
+	return multi
+}
+
+// AfterTradeExecuted implements TradeHooks
+func (h MultiTradeHooks) AfterTradeExecuted(ctx sdk.Context, trade *Trade) error {
+	return h.each(ctx, func(ctx sdk.Context, hook TradeHooks) error {
+		return hook.AfterTradeExecuted(ctx, trade)
+	})
+}
+
+// AfterPositionChanged implements TradeHooks
+func (h MultiTradeHooks) AfterPositionChanged(ctx sdk.Context, trader, marketID string) error {
+	return h.each(ctx, func(ctx sdk.Context, hook TradeHooks) error {
+		return hook.AfterPositionChanged(ctx, trader, marketID)
+	})
+}
+
+// AfterLiquidation implements TradeHooks
+func (h MultiTradeHooks) AfterLiquidation(ctx sdk.Context, trader, marketID string, size, price math.LegacyDec) error {
+	return h.each(ctx, func(ctx sdk.Context, hook TradeHooks) error {
+		return hook.AfterLiquidation(ctx, trader, marketID, size, price)
+	})
+}
+
+// each runs call for every hook, committing the writes of those that succeed,
+// and returns the errors of those that did not
+func (h MultiTradeHooks) each(ctx sdk.Context, call func(sdk.Context, TradeHooks) error) error {
+	var errs []error
+	for i, hook := range h {
+		cacheCtx, write := ctx.CacheContext()
+		if err := safeCall(cacheCtx, hook, call); err != nil {
+			errs = append(errs, fmt.Errorf("trade hook %d (%T): %w", i, hook, err))
+			continue
+		}
+		write()
+	}
+	return errors.Join(errs...)
+}
+
+// safeCall turns a hook panic into an error. Running out of gas is not the
+// hook's fault and still aborts the transaction.
+func safeCall(ctx sdk.Context, hook TradeHooks, call func(sdk.Context, TradeHooks) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(storetypes.ErrorOutOfGas); ok {
+				panic(r)
+			}
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return call(ctx, hook)
+}
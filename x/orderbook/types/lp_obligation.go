@@ -0,0 +1,120 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// LPEpochDuration is the length of a quoting obligation epoch. Epoch n covers
+// [n × LPEpochDuration, (n+1) × LPEpochDuration) since the Unix epoch.
+const LPEpochDuration = 24 * time.Hour
+
+// Default quoting obligation terms
+var (
+	DefaultLPMaxSpreadBps = int64(50)
+	DefaultLPMinUptime    = math.LegacyNewDecWithPrec(9, 1) // 90%
+)
+
+// LPEpoch returns the obligation epoch containing t
+func LPEpoch(t time.Time) int64 {
+	return t.Unix() / int64(LPEpochDuration/time.Second)
+}
+
+// LPEpochStart returns the start time of an obligation epoch
+func LPEpochStart(epoch int64) time.Time {
+	return time.Unix(epoch*int64(LPEpochDuration/time.Second), 0).UTC()
+}
+
+// LPObligation is a designated maker's quoting obligation in one market. The
+// maker must rest at least MinQuantity on each side within MaxSpreadBps of the
+// book's mid price for at least MinUptime of the epoch's samples.
+type LPObligation struct {
+	Trader       string
+	MarketID     string
+	MaxSpreadBps int64
+	MinQuantity  math.LegacyDec
+	MinUptime    math.LegacyDec // fraction of samples, (0, 1]
+	UpdatedAt    time.Time
+}
+
+// NewLPObligation creates an obligation with the default spread and uptime
+func NewLPObligation(trader, marketID string, minQuantity math.LegacyDec) *LPObligation {
+	return &LPObligation{
+		Trader:       trader,
+		MarketID:     marketID,
+		MaxSpreadBps: DefaultLPMaxSpreadBps,
+		MinQuantity:  minQuantity,
+		MinUptime:    DefaultLPMinUptime,
+	}
+}
+
+// Validate checks the obligation terms
+func (o *LPObligation) Validate() error {
+	if o.Trader == "" {
+		return ErrInvalidTrader
+	}
+	if o.MarketID == "" {
+		return ErrInvalidMarketID
+	}
+	if o.MaxSpreadBps <= 0 || o.MaxSpreadBps > 10000 {
+		return fmt.Errorf("%w: max spread must be in (0, 10000] bps", ErrInvalidLPObligation)
+	}
+	if o.MinQuantity.IsNil() || !o.MinQuantity.IsPositive() {
+		return fmt.Errorf("%w: min quantity must be positive", ErrInvalidLPObligation)
+	}
+	if o.MinUptime.IsNil() || !o.MinUptime.IsPositive() || o.MinUptime.GT(math.LegacyOneDec()) {
+		return fmt.Errorf("%w: min uptime must be in (0, 1]", ErrInvalidLPObligation)
+	}
+	return nil
+}
+
+// LPUptime is a maker's compliance with one obligation over one epoch. The
+// book is sampled once per block, so uptime is the share of blocks in which
+// the maker was quoting two-sided within the spread.
+type LPUptime struct {
+	Trader        string
+	MarketID      string
+	Epoch         int64
+	Samples       int64
+	QuotedSamples int64
+	MinUptime     math.LegacyDec // obligation terms at the latest sample
+	MaxSpreadBps  int64
+	LastSampledAt time.Time
+}
+
+// NewLPUptime creates an empty report
+func NewLPUptime(trader, marketID string, epoch int64) *LPUptime {
+	return &LPUptime{
+		Trader:    trader,
+		MarketID:  marketID,
+		Epoch:     epoch,
+		MinUptime: math.LegacyZeroDec(),
+	}
+}
+
+// Record adds a sample taken under obligation o
+func (u *LPUptime) Record(o *LPObligation, quoted bool, now time.Time) {
+	u.Samples++
+	if quoted {
+		u.QuotedSamples++
+	}
+	u.MinUptime = o.MinUptime
+	u.MaxSpreadBps = o.MaxSpreadBps
+	u.LastSampledAt = now
+}
+
+// Uptime returns the share of samples in which the maker was quoting
+func (u *LPUptime) Uptime() math.LegacyDec {
+	if u.Samples == 0 {
+		return math.LegacyZeroDec()
+	}
+	return math.LegacyNewDec(u.QuotedSamples).QuoInt64(u.Samples)
+}
+
+// Met returns whether the uptime reached the obligation. An epoch without
+// samples is not met.
+func (u *LPUptime) Met() bool {
+	return u.Samples > 0 && !u.MinUptime.IsNil() && u.Uptime().GTE(u.MinUptime)
+}
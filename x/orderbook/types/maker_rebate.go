@@ -0,0 +1,115 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// QuoteContext is the book a limit order met when it came to rest: the best
+// bid and ask before it was added, zero for an empty side, and the block time.
+// Maker rebates are scored against it when the order fills.
+type QuoteContext struct {
+	BestBid  math.LegacyDec
+	BestAsk  math.LegacyDec
+	RestedAt time.Time
+}
+
+// Tightness returns how far a quote at price on side closed the prevailing
+// spread, from 0 for a quote at or behind its side's best price towards 1 as
+// it nears the opposite best. Without a two-sided book it is 0.
+func (q *QuoteContext) Tightness(side Side, price math.LegacyDec) math.LegacyDec {
+	if q.BestBid.IsNil() || q.BestAsk.IsNil() || !q.BestBid.IsPositive() || !q.BestAsk.GT(q.BestBid) {
+		return math.LegacyZeroDec()
+	}
+	spread := q.BestAsk.Sub(q.BestBid)
+	improvement := price.Sub(q.BestBid)
+	if side == SideSell {
+		improvement = q.BestAsk.Sub(price)
+	}
+	if !improvement.IsPositive() {
+		return math.LegacyZeroDec()
+	}
+	return math.LegacyMinDec(improvement.Quo(spread), math.LegacyOneDec())
+}
+
+// MakerRebateParams configures the dynamic maker rebate. A filled maker
+// order earns a score in [0, 1]: TightnessWeight of it from how far the quote
+// closed the spread it rested against, the rest from how long it rested,
+// reaching the full rest score at FullRestDuration. A quote that rested less
+// than MinRestDuration scores nothing. The rebate is the fill's notional
+// times MaxRebateRate times the score, taken off the maker fee and capped at
+// the fees the trade pays, so a rebate is never more than the trade collects.
+type MakerRebateParams struct {
+	Enabled          bool           `json:"enabled"`
+	MaxRebateRate    math.LegacyDec `json:"max_rebate_rate"`
+	TightnessWeight  math.LegacyDec `json:"tightness_weight"`
+	MinRestDuration  time.Duration  `json:"min_rest_duration"`
+	FullRestDuration time.Duration  `json:"full_rest_duration"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+// DefaultMakerRebateParams is disabled, with a 1 bp maximum rebate split
+// evenly between tightness and a minute of rest once enabled
+func DefaultMakerRebateParams() *MakerRebateParams {
+	return &MakerRebateParams{
+		MaxRebateRate:    math.LegacyNewDecWithPrec(1, 4),
+		TightnessWeight:  math.LegacyNewDecWithPrec(5, 1),
+		MinRestDuration:  time.Second,
+		FullRestDuration: time.Minute,
+	}
+}
+
+// Validate checks that the rate is in [0, 0.01], the weight in [0, 1] and the
+// durations ordered
+func (p *MakerRebateParams) Validate() error {
+	if p.MaxRebateRate.IsNil() || p.MaxRebateRate.IsNegative() || p.MaxRebateRate.GT(math.LegacyNewDecWithPrec(1, 2)) {
+		return fmt.Errorf("%w: max rebate rate must be in [0, 0.01]", ErrInvalidMakerRebate)
+	}
+	if p.TightnessWeight.IsNil() || p.TightnessWeight.IsNegative() || p.TightnessWeight.GT(math.LegacyOneDec()) {
+		return fmt.Errorf("%w: tightness weight must be in [0, 1]", ErrInvalidMakerRebate)
+	}
+	if p.MinRestDuration < 0 || p.FullRestDuration <= 0 || p.FullRestDuration < p.MinRestDuration {
+		return fmt.Errorf("%w: durations must satisfy 0 <= min rest <= full rest, full rest positive", ErrInvalidMakerRebate)
+	}
+	return nil
+}
+
+// Score returns a maker order's rebate score for a fill at fillTime, 0 if it
+// has no quote context
+func (p *MakerRebateParams) Score(order *Order, fillTime time.Time) math.LegacyDec {
+	if order.Quote == nil {
+		return math.LegacyZeroDec()
+	}
+	rested := fillTime.Sub(order.Quote.RestedAt)
+	if rested < p.MinRestDuration {
+		return math.LegacyZeroDec()
+	}
+	rest := math.LegacyOneDec()
+	if rested < p.FullRestDuration {
+		rest = math.LegacyNewDec(int64(rested)).QuoInt64(int64(p.FullRestDuration))
+	}
+	tightness := order.Quote.Tightness(order.Side, order.Price)
+	return p.TightnessWeight.Mul(tightness).Add(math.LegacyOneDec().Sub(p.TightnessWeight).Mul(rest))
+}
+
+// MakerPoints is a trader's running total in the maker points program:
+// rebate-scored maker volume, the notional of each fill times its score,
+// with the rebates paid on it
+type MakerPoints struct {
+	Trader    string         `json:"trader"`
+	Points    math.LegacyDec `json:"points"`
+	Rebates   math.LegacyDec `json:"rebates"`
+	Fills     int64          `json:"fills"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// NewMakerPoints creates an empty points record
+func NewMakerPoints(trader string) *MakerPoints {
+	return &MakerPoints{
+		Trader:  trader,
+		Points:  math.LegacyZeroDec(),
+		Rebates: math.LegacyZeroDec(),
+	}
+}
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// What a margin re-check does with a resting order its owner can no longer
+// cover at the mark price
+const (
+	MarginRecheckActionCancel = "cancel"
+	MarginRecheckActionFlag   = "flag"
+)
+
+// DefaultMarginRecheckInterval is how many blocks apart resting orders are
+// re-checked by default
+const DefaultMarginRecheckInterval = 10
+
+// MarginRecheckParams configures the periodic margin re-check of resting
+// orders. Margin is checked when an order is placed, at its limit price; the
+// re-check prices each resting order's remaining quantity at its market's
+// current mark price instead. An Interval of zero disables the re-check.
+type MarginRecheckParams struct {
+	Interval  int64     `json:"interval"` // blocks between re-checks
+	Action    string    `json:"action"`   // cancel or flag
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DefaultMarginRecheckParams cancels uncovered orders every 10 blocks
+func DefaultMarginRecheckParams() *MarginRecheckParams {
+	return &MarginRecheckParams{Interval: DefaultMarginRecheckInterval, Action: MarginRecheckActionCancel}
+}
+
+// Validate checks the interval and action
+func (p *MarginRecheckParams) Validate() error {
+	if p.Interval < 0 {
+		return fmt.Errorf("%w: interval must not be negative", ErrInvalidMarginRecheck)
+	}
+	if p.Action != MarginRecheckActionCancel && p.Action != MarginRecheckActionFlag {
+		return fmt.Errorf("%w: action must be %s or %s", ErrInvalidMarginRecheck, MarginRecheckActionCancel, MarginRecheckActionFlag)
+	}
+	return nil
+}
+
+// MarginFlag marks a resting order its owner could not cover at the mark
+// price when it was last re-checked. The flag is cleared once a re-check
+// passes or the order leaves the book.
+type MarginFlag struct {
+	OrderID      string         `json:"order_id"`
+	Trader       string         `json:"trader"`
+	MarketID     string         `json:"market_id"`
+	MarkPrice    math.LegacyDec `json:"mark_price"`
+	RemainingQty math.LegacyDec `json:"remaining_qty"`
+	Reason       string         `json:"reason"`
+	FlaggedAt    time.Time      `json:"flagged_at"`
+}
+
+// MarginRecheckResult is what one re-check of the resting orders did
+type MarginRecheckResult struct {
+	Checked   int
+	Cancelled []*Order
+	Flagged   []*MarginFlag // orders newly flagged by this re-check
+}
@@ -0,0 +1,147 @@
+package types
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// Domain separation prefixes so a leaf can never be passed off as an inner node
+const (
+	merkleLeafPrefix byte = 0x00
+	merkleNodePrefix byte = 0x01
+)
+
+// TradeLeafHash returns the Merkle leaf hash of a trade.
+// Every field that affects settlement is committed, each length-prefixed so
+// that no two distinct trades share an encoding.
+func TradeLeafHash(trade *Trade) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(merkleLeafPrefix)
+
+	writeField := func(s string) {
+		var lenBz [binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(lenBz[:], uint64(len(s)))
+		buf.Write(lenBz[:n])
+		buf.WriteString(s)
+	}
+
+	writeField(trade.TradeID)
+	writeField(trade.MarketID)
+	writeField(trade.TakerOrderID)
+	writeField(trade.MakerOrderID)
+	writeField(trade.Taker)
+	writeField(trade.Maker)
+	writeField(trade.TakerSide.String())
+	writeField(trade.Price.String())
+	writeField(trade.Quantity.String())
+	writeField(trade.TakerFee.String())
+	writeField(trade.MakerFee.String())
+
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(trade.Timestamp.UnixNano()))
+	buf.Write(ts[:])
+
+	sum := sha256.Sum256(buf.Bytes())
+	return sum[:]
+}
+
+// TradeLeaves returns the leaf hashes of trades in batch order
+func TradeLeaves(trades []*Trade) [][]byte {
+	leaves := make([][]byte, len(trades))
+	for i, trade := range trades {
+		leaves[i] = TradeLeafHash(trade)
+	}
+	return leaves
+}
+
+// MerkleRoot computes the root of a binary Merkle tree over the given leaves.
+// An empty tree has a nil root.
+func MerkleRoot(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		return nil
+	}
+
+	level := leaves
+	for len(level) > 1 {
+		level = nextMerkleLevel(level)
+	}
+	return level[0]
+}
+
+// BuildMerkleProof returns the inclusion proof for the leaf at index
+func BuildMerkleProof(leaves [][]byte, index int) (*MerkleProof, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, ErrInvalidMerkleProof.Wrapf("leaf index %d out of range [0, %d)", index, len(leaves))
+	}
+
+	proof := &MerkleProof{
+		LeafIndex:   uint64(index),
+		TotalLeaves: uint64(len(leaves)),
+		Siblings:    make([][]byte, 0),
+	}
+
+	level := leaves
+	idx := index
+	for len(level) > 1 {
+		sibling := idx ^ 1
+		if sibling < len(level) {
+			proof.Siblings = append(proof.Siblings, level[sibling])
+		}
+		level = nextMerkleLevel(level)
+		idx /= 2
+	}
+	return proof, nil
+}
+
+// VerifyMerkleProof reports whether leaf is included in root at the position
+// described by proof
+func VerifyMerkleProof(root, leaf []byte, proof *MerkleProof) bool {
+	if proof == nil || len(root) == 0 || proof.TotalLeaves == 0 || proof.LeafIndex >= proof.TotalLeaves {
+		return false
+	}
+
+	node := leaf
+	idx := proof.LeafIndex
+	width := proof.TotalLeaves
+	used := 0
+	for width > 1 {
+		// The last node of an odd-width level is promoted without a sibling
+		if !(idx == width-1 && width%2 == 1) {
+			if used >= len(proof.Siblings) {
+				return false
+			}
+			if idx%2 == 0 {
+				node = hashMerkleNode(node, proof.Siblings[used])
+			} else {
+				node = hashMerkleNode(proof.Siblings[used], node)
+			}
+			used++
+		}
+		idx /= 2
+		width = (width + 1) / 2
+	}
+
+	return used == len(proof.Siblings) && bytes.Equal(node, root)
+}
+
+// nextMerkleLevel hashes adjacent pairs, promoting an unpaired last node
+func nextMerkleLevel(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+		next = append(next, hashMerkleNode(level[i], level[i+1]))
+	}
+	return next
+}
+
+func hashMerkleNode(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{merkleNodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+func testTrades(n int) []*Trade {
+	trades := make([]*Trade, n)
+	for i := 0; i < n; i++ {
+		trades[i] = &Trade{
+			TradeID:      fmt.Sprintf("trade-%d", i+1),
+			MarketID:     "BTC-USDC",
+			TakerOrderID: fmt.Sprintf("taker-%d", i+1),
+			MakerOrderID: fmt.Sprintf("maker-%d", i+1),
+			Taker:        "cosmos1taker",
+			Maker:        "cosmos1maker",
+			TakerSide:    SideBuy,
+			Price:        math.LegacyNewDec(50000),
+			Quantity:     math.LegacyNewDecWithPrec(int64(i+1), 2),
+			TakerFee:     math.LegacyNewDecWithPrec(5, 1),
+			MakerFee:     math.LegacyNewDecWithPrec(2, 1),
+			Timestamp:    time.Unix(1700000000, int64(i)),
+		}
+	}
+	return trades
+}
+
+// TestMerkleProofRoundTrip tests that every leaf verifies for odd and even tree sizes
+func TestMerkleProofRoundTrip(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		leaves := TradeLeaves(testTrades(n))
+		root := MerkleRoot(leaves)
+
+		for i := range leaves {
+			proof, err := BuildMerkleProof(leaves, i)
+			if err != nil {
+				t.Fatalf("n=%d i=%d: %v", n, i, err)
+			}
+			if !VerifyMerkleProof(root, leaves[i], proof) {
+				t.Errorf("n=%d i=%d: expected proof to verify", n, i)
+			}
+		}
+	}
+}
+
+// TestMerkleProofRejectsTampering tests that altered trades and proofs fail verification
+func TestMerkleProofRejectsTampering(t *testing.T) {
+	trades := testTrades(5)
+	leaves := TradeLeaves(trades)
+	root := MerkleRoot(leaves)
+
+	proof, err := BuildMerkleProof(leaves, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := *trades[2]
+	tampered.Price = math.LegacyNewDec(49000)
+	if VerifyMerkleProof(root, TradeLeafHash(&tampered), proof) {
+		t.Error("expected tampered trade to fail verification")
+	}
+
+	wrongIndex := *proof
+	wrongIndex.LeafIndex = 3
+	if VerifyMerkleProof(root, leaves[2], &wrongIndex) {
+		t.Error("expected proof with wrong index to fail verification")
+	}
+
+	truncated := *proof
+	truncated.Siblings = proof.Siblings[:len(proof.Siblings)-1]
+	if VerifyMerkleProof(root, leaves[2], &truncated) {
+		t.Error("expected truncated proof to fail verification")
+	}
+}
+
+// TestBuildMerkleProofOutOfRange tests index bounds checking
+func TestBuildMerkleProofOutOfRange(t *testing.T) {
+	leaves := TradeLeaves(testTrades(3))
+	if _, err := BuildMerkleProof(leaves, 3); err == nil {
+		t.Error("expected error for out of range index")
+	}
+}
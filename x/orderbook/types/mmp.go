@@ -0,0 +1,169 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// MMP limits that can trip
+const (
+	MMPReasonFills  = "fills"
+	MMPReasonDelta  = "delta"
+	MMPReasonVolume = "volume"
+)
+
+// Default MMP timing
+const (
+	DefaultMMPWindow         = 5 * time.Second
+	DefaultMMPFreezeDuration = 30 * time.Second
+	MaxMMPWindow             = time.Hour
+)
+
+// MMPConfig is a trader's market maker protection for one market. Fills of the
+// trader's resting orders are counted over a rolling window; when any limit is
+// reached, the trader's remaining orders in the market are cancelled and new
+// limit orders are rejected until the freeze ends. Zero limits are not checked.
+type MMPConfig struct {
+	Trader         string
+	MarketID       string
+	Enabled        bool
+	Window         time.Duration // fills count while younger than Window: (now-Window, now]
+	FreezeDuration time.Duration
+	MaxFills       int64
+	MaxDelta       math.LegacyDec // net filled quantity, buys minus sells, in either direction
+	MaxVolume      math.LegacyDec // gross filled quantity
+	UpdatedAt      time.Time
+}
+
+// NewMMPConfig creates an enabled config with the default window and freeze
+func NewMMPConfig(trader, marketID string) *MMPConfig {
+	return &MMPConfig{
+		Trader:         trader,
+		MarketID:       marketID,
+		Enabled:        true,
+		Window:         DefaultMMPWindow,
+		FreezeDuration: DefaultMMPFreezeDuration,
+		MaxDelta:       math.LegacyZeroDec(),
+		MaxVolume:      math.LegacyZeroDec(),
+	}
+}
+
+// Validate checks the window and limits
+func (c *MMPConfig) Validate() error {
+	if c.Trader == "" {
+		return ErrInvalidTrader
+	}
+	if c.MarketID == "" {
+		return ErrInvalidMarketID
+	}
+	if c.Window <= 0 || c.Window > MaxMMPWindow {
+		return fmt.Errorf("%w: window must be in (0, %s]", ErrInvalidMMPConfig, MaxMMPWindow)
+	}
+	if c.FreezeDuration < 0 {
+		return fmt.Errorf("%w: freeze duration must not be negative", ErrInvalidMMPConfig)
+	}
+	if c.MaxFills < 0 || mmpLimitNegative(c.MaxDelta) || mmpLimitNegative(c.MaxVolume) {
+		return fmt.Errorf("%w: limits must not be negative", ErrInvalidMMPConfig)
+	}
+	if c.MaxFills == 0 && !mmpLimitSet(c.MaxDelta) && !mmpLimitSet(c.MaxVolume) {
+		return fmt.Errorf("%w: at least one limit is required", ErrInvalidMMPConfig)
+	}
+	return nil
+}
+
+// MMPFill is a fill of a protected resting order. Quantity is signed: positive
+// for buys, negative for sells.
+type MMPFill struct {
+	Quantity  math.LegacyDec
+	Timestamp time.Time
+}
+
+// MMPState tracks a trader's recent fills in a market and whether protection
+// has tripped
+type MMPState struct {
+	Trader        string
+	MarketID      string
+	Fills         []MMPFill
+	FrozenUntil   time.Time
+	TriggeredAt   time.Time
+	TriggerReason string
+}
+
+// NewMMPState creates an empty state
+func NewMMPState(trader, marketID string) *MMPState {
+	return &MMPState{
+		Trader:   trader,
+		MarketID: marketID,
+		Fills:    make([]MMPFill, 0),
+	}
+}
+
+// IsFrozen returns whether new limit orders are rejected at now
+func (s *MMPState) IsFrozen(now time.Time) bool {
+	return now.Before(s.FrozenUntil)
+}
+
+// Prune drops fills that fell out of the window ending at now. The window is
+// half-open, (now-window, now]: a fill exactly window old has expired, so
+// back-to-back windows never count the same fill twice.
+func (s *MMPState) Prune(now time.Time, window time.Duration) {
+	cutoff := now.Add(-window)
+	i := 0
+	for i < len(s.Fills) && !s.Fills[i].Timestamp.After(cutoff) {
+		i++
+	}
+	s.Fills = s.Fills[i:]
+}
+
+// Totals returns the fill count, net quantity and gross quantity in the window
+func (s *MMPState) Totals() (fills int64, delta, volume math.LegacyDec) {
+	delta, volume = math.LegacyZeroDec(), math.LegacyZeroDec()
+	for _, f := range s.Fills {
+		delta = delta.Add(f.Quantity)
+		volume = volume.Add(f.Quantity.Abs())
+	}
+	return int64(len(s.Fills)), delta, volume
+}
+
+// Record adds a fill at now and trips protection if a limit is reached. It
+// returns the limit that tripped, or "" if none did. Tripping clears the
+// window so counting restarts once the freeze ends.
+func (s *MMPState) Record(cfg *MMPConfig, quantity math.LegacyDec, now time.Time) string {
+	s.Prune(now, cfg.Window)
+	s.Fills = append(s.Fills, MMPFill{Quantity: quantity, Timestamp: now})
+
+	fills, delta, volume := s.Totals()
+	reason := ""
+	switch {
+	case cfg.MaxFills > 0 && fills >= cfg.MaxFills:
+		reason = MMPReasonFills
+	case mmpLimitSet(cfg.MaxDelta) && delta.Abs().GTE(cfg.MaxDelta):
+		reason = MMPReasonDelta
+	case mmpLimitSet(cfg.MaxVolume) && volume.GTE(cfg.MaxVolume):
+		reason = MMPReasonVolume
+	default:
+		return ""
+	}
+
+	s.Fills = s.Fills[:0]
+	s.TriggeredAt = now
+	s.TriggerReason = reason
+	s.FrozenUntil = now.Add(cfg.FreezeDuration)
+	return reason
+}
+
+// Reset lifts a freeze and clears the window
+func (s *MMPState) Reset() {
+	s.Fills = s.Fills[:0]
+	s.FrozenUntil = time.Time{}
+}
+
+func mmpLimitSet(d math.LegacyDec) bool {
+	return !d.IsNil() && d.IsPositive()
+}
+
+func mmpLimitNegative(d math.LegacyDec) bool {
+	return !d.IsNil() && d.IsNegative()
+}
@@ -0,0 +1,118 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// TestMMPConfigValidate tests window and limit validation
+func TestMMPConfigValidate(t *testing.T) {
+	valid := func() *MMPConfig {
+		c := NewMMPConfig("trader1", "BTC-USDC")
+		c.MaxFills = 10
+		return c
+	}
+
+	testCases := []struct {
+		name   string
+		mutate func(c *MMPConfig)
+		ok     bool
+	}{
+		{"fills limit only", func(c *MMPConfig) {}, true},
+		{"delta limit only", func(c *MMPConfig) { c.MaxFills = 0; c.MaxDelta = math.LegacyNewDec(5) }, true},
+		{"no limits", func(c *MMPConfig) { c.MaxFills = 0 }, false},
+		{"zero window", func(c *MMPConfig) { c.Window = 0 }, false},
+		{"window too long", func(c *MMPConfig) { c.Window = 2 * time.Hour }, false},
+		{"negative volume", func(c *MMPConfig) { c.MaxVolume = math.LegacyNewDec(-1) }, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := valid()
+			tc.mutate(c)
+			err := c.Validate()
+			if tc.ok && err != nil {
+				t.Errorf("expected valid, got %v", err)
+			}
+			if !tc.ok && !errors.Is(err, ErrInvalidMMPConfig) {
+				t.Errorf("expected ErrInvalidMMPConfig, got %v", err)
+			}
+		})
+	}
+}
+
+// TestMMPStateRecord tests the rolling window and each limit tripping
+func TestMMPStateRecord(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	t.Run("fills expire from window", func(t *testing.T) {
+		c := NewMMPConfig("trader1", "BTC-USDC")
+		c.MaxFills = 3
+		s := NewMMPState("trader1", "BTC-USDC")
+
+		s.Record(c, math.LegacyOneDec(), start)
+		s.Record(c, math.LegacyOneDec(), start.Add(2*time.Second))
+		// The first fill is outside the 5s window by now
+		if reason := s.Record(c, math.LegacyOneDec(), start.Add(6*time.Second)); reason != "" {
+			t.Fatalf("expected no trigger, got %s", reason)
+		}
+		if reason := s.Record(c, math.LegacyOneDec(), start.Add(6*time.Second)); reason != MMPReasonFills {
+			t.Fatalf("expected fills trigger, got %q", reason)
+		}
+		if !s.IsFrozen(start.Add(7*time.Second)) || s.IsFrozen(start.Add(37*time.Second)) {
+			t.Error("expected a 30s freeze")
+		}
+		if len(s.Fills) != 0 {
+			t.Error("expected window cleared after trigger")
+		}
+	})
+
+	t.Run("window excludes its start", func(t *testing.T) {
+		c := NewMMPConfig("trader1", "BTC-USDC")
+		c.MaxFills = 2
+		s := NewMMPState("trader1", "BTC-USDC")
+
+		s.Record(c, math.LegacyOneDec(), start)
+		// Exactly 5s later the first fill has expired
+		if reason := s.Record(c, math.LegacyOneDec(), start.Add(5*time.Second)); reason != "" {
+			t.Fatalf("expected the fill at the window start expired, got %s", reason)
+		}
+		// A nanosecond short of 5s later the second fill still counts
+		if reason := s.Record(c, math.LegacyOneDec(), start.Add(10*time.Second-time.Nanosecond)); reason != MMPReasonFills {
+			t.Fatalf("expected the fill inside the window counted, got %q", reason)
+		}
+	})
+
+	t.Run("delta nets buys and sells", func(t *testing.T) {
+		c := NewMMPConfig("trader1", "BTC-USDC")
+		c.MaxDelta = math.LegacyNewDec(3)
+		s := NewMMPState("trader1", "BTC-USDC")
+
+		s.Record(c, math.LegacyNewDec(2), start)
+		if reason := s.Record(c, math.LegacyNewDec(-2), start); reason != "" {
+			t.Fatalf("expected no trigger, got %s", reason)
+		}
+		if reason := s.Record(c, math.LegacyNewDec(-3), start); reason != MMPReasonDelta {
+			t.Fatalf("expected delta trigger, got %q", reason)
+		}
+	})
+
+	t.Run("volume counts gross quantity", func(t *testing.T) {
+		c := NewMMPConfig("trader1", "BTC-USDC")
+		c.MaxVolume = math.LegacyNewDec(4)
+		s := NewMMPState("trader1", "BTC-USDC")
+
+		s.Record(c, math.LegacyNewDec(2), start)
+		if reason := s.Record(c, math.LegacyNewDec(-2), start); reason != MMPReasonVolume {
+			t.Fatalf("expected volume trigger, got %q", reason)
+		}
+
+		s.Reset()
+		if s.IsFrozen(start) {
+			t.Error("expected reset to lift the freeze")
+		}
+	})
+}
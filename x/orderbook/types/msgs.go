@@ -10,6 +10,8 @@ func RegisterInterfaces(registry cdctypes.InterfaceRegistry) {
 	registry.RegisterImplementations((*sdk.Msg)(nil),
 		&MsgPlaceOrder{},
 		&MsgCancelOrder{},
+		&MsgCommitTradeBatch{},
+		&MsgDisputeTrade{},
 	)
 }
 
@@ -0,0 +1,121 @@
+package types
+
+import (
+	"encoding/hex"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// ValidateBasic validates the commit message
+func (msg *MsgCommitTradeBatch) ValidateBasic() error {
+	if msg.Submitter == "" {
+		return ErrInvalidTrader
+	}
+	if msg.BatchId == 0 {
+		return ErrInvalidBatchSequence
+	}
+	if msg.TradeCount == 0 || msg.TradeCount > MaxSettlementBatchSize {
+		return ErrInvalidOrder.Wrapf("trade count must be between 1 and %d", MaxSettlementBatchSize)
+	}
+	if _, err := msg.Root(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Root returns the decoded Merkle root
+func (msg *MsgCommitTradeBatch) Root() ([]byte, error) {
+	root, err := hex.DecodeString(msg.MerkleRoot)
+	if err != nil || len(root) != 32 {
+		return nil, ErrInvalidMerkleRoot
+	}
+	return root, nil
+}
+
+// GetSigners returns the signer addresses for MsgCommitTradeBatch
+func (msg *MsgCommitTradeBatch) GetSigners() []sdk.AccAddress {
+	submitter, _ := sdk.AccAddressFromBech32(msg.Submitter)
+	return []sdk.AccAddress{submitter}
+}
+
+// ValidateBasic validates the dispute message
+func (msg *MsgDisputeTrade) ValidateBasic() error {
+	if msg.Challenger == "" {
+		return ErrInvalidTrader
+	}
+	if msg.BatchId == 0 {
+		return ErrSettlementBatchNotFound
+	}
+	if msg.Trade == nil {
+		return ErrInvalidOrder.Wrap("trade is required")
+	}
+	if msg.Proof == nil {
+		return ErrInvalidMerkleProof
+	}
+	if msg.Challenger != msg.Trade.Taker && msg.Challenger != msg.Trade.Maker {
+		return ErrUnauthorized.Wrap("challenger is not a counterparty of the trade")
+	}
+	if _, err := msg.Trade.ToTrade(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetSigners returns the signer addresses for MsgDisputeTrade
+func (msg *MsgDisputeTrade) GetSigners() []sdk.AccAddress {
+	challenger, _ := sdk.AccAddressFromBech32(msg.Challenger)
+	return []sdk.AccAddress{challenger}
+}
+
+// NewSettledTrade returns the revealed form of a trade, as disputed on chain
+func NewSettledTrade(trade *Trade) *SettledTrade {
+	return &SettledTrade{
+		TradeId:      trade.TradeID,
+		MarketId:     trade.MarketID,
+		TakerOrderId: trade.TakerOrderID,
+		MakerOrderId: trade.MakerOrderID,
+		Taker:        trade.Taker,
+		Maker:        trade.Maker,
+		TakerSide:    trade.TakerSide,
+		Price:        trade.Price.String(),
+		Quantity:     trade.Quantity.String(),
+		TakerFee:     trade.TakerFee.String(),
+		MakerFee:     trade.MakerFee.String(),
+		Timestamp:    trade.Timestamp.UnixNano(),
+	}
+}
+
+// ToTrade returns the trade a settled trade reveals. Its Merkle leaf hash is
+// that of the trade the matcher committed.
+func (t *SettledTrade) ToTrade() (*Trade, error) {
+	decs := make([]math.LegacyDec, 4)
+	for i, s := range []string{t.Price, t.Quantity, t.TakerFee, t.MakerFee} {
+		d, err := math.LegacyNewDecFromStr(s)
+		if err != nil {
+			return nil, ErrInvalidOrder.Wrapf("trade %s: invalid decimal %q", t.TradeId, s)
+		}
+		decs[i] = d
+	}
+	return &Trade{
+		TradeID:      t.TradeId,
+		MarketID:     t.MarketId,
+		TakerOrderID: t.TakerOrderId,
+		MakerOrderID: t.MakerOrderId,
+		Taker:        t.Taker,
+		Maker:        t.Maker,
+		TakerSide:    t.TakerSide,
+		Price:        decs[0],
+		Quantity:     decs[1],
+		TakerFee:     decs[2],
+		MakerFee:     decs[3],
+		Timestamp:    time.Unix(0, t.Timestamp).UTC(),
+	}, nil
+}
+
+// Message type constants
+const (
+	TypeMsgCommitTradeBatch = "commit_trade_batch"
+	TypeMsgDisputeTrade     = "dispute_trade"
+)
@@ -52,6 +52,7 @@ const (
 	TimeInForceIOC                    // Immediate Or Cancel
 	TimeInForceFOK                    // Fill Or Kill
 	TimeInForceGTX                    // Post Only (Good Till Crossing)
+	TimeInForceGTD                    // Good Till Date
 )
 
 // String returns the string representation of TimeInForce
@@ -65,6 +66,8 @@ func (t TimeInForce) String() string {
 		return "FOK"
 	case TimeInForceGTX:
 		return "GTX"
+	case TimeInForceGTD:
+		return "GTD"
 	default:
 		return "GTC"
 	}
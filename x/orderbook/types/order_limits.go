@@ -0,0 +1,39 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// Order limit scopes, reported with a rejection
+const (
+	OrderLimitScopeMarket = "market"
+	OrderLimitScopeTrader = "trader"
+)
+
+// OrderLimits caps how many limit orders may rest on the books: in any one
+// market, and for any one trader across all markets. An order that would
+// rest past a cap is rejected; resting orders are never evicted to make room.
+// Market orders are not limited, so a trader at the cap can still trade out.
+// Zero caps are not checked.
+type OrderLimits struct {
+	MaxRestingPerMarket int64     `json:"max_resting_per_market"`
+	MaxRestingPerTrader int64     `json:"max_resting_per_trader"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// DefaultOrderLimits checks neither cap
+func DefaultOrderLimits() *OrderLimits {
+	return &OrderLimits{}
+}
+
+// Validate checks that neither cap is negative
+func (l *OrderLimits) Validate() error {
+	if l.MaxRestingPerMarket < 0 {
+		return fmt.Errorf("%w: max_resting_per_market must not be negative", ErrInvalidOrderLimits)
+	}
+	if l.MaxRestingPerTrader < 0 {
+		return fmt.Errorf("%w: max_resting_per_trader must not be negative", ErrInvalidOrderLimits)
+	}
+	return nil
+}
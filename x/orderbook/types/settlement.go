@@ -0,0 +1,70 @@
+package types
+
+import (
+	"time"
+)
+
+// MaxSettlementBatchSize is the maximum number of trades one commitment may cover
+const MaxSettlementBatchSize = 10000
+
+// SettlementBatchStatus represents the lifecycle state of a settlement batch
+type SettlementBatchStatus int32
+
+const (
+	SettlementBatchStatusUnspecified SettlementBatchStatus = iota
+	SettlementBatchStatusCommitted
+	SettlementBatchStatusRejected
+)
+
+func (s SettlementBatchStatus) String() string {
+	switch s {
+	case SettlementBatchStatusCommitted:
+		return "committed"
+	case SettlementBatchStatusRejected:
+		return "rejected"
+	default:
+		return "unspecified"
+	}
+}
+
+// SettlementBatch is an on-chain commitment to a batch of trades matched off-chain.
+// Only the Merkle root is stored; individual trades are revealed with an
+// inclusion proof when a counterparty disputes them.
+type SettlementBatch struct {
+	BatchID      uint64
+	Submitter    string
+	MerkleRoot   []byte
+	TradeCount   uint64
+	CommitHeight int64
+	CommittedAt  time.Time
+	Status       SettlementBatchStatus
+
+	// Set when a dispute proves an invalid trade was included
+	DisputedTradeID string
+	DisputeReason   string
+}
+
+// NewSettlementBatch creates a committed settlement batch
+func NewSettlementBatch(batchID uint64, submitter string, root []byte, tradeCount uint64, height int64, committedAt time.Time) *SettlementBatch {
+	return &SettlementBatch{
+		BatchID:      batchID,
+		Submitter:    submitter,
+		MerkleRoot:   root,
+		TradeCount:   tradeCount,
+		CommitHeight: height,
+		CommittedAt:  committedAt,
+		Status:       SettlementBatchStatusCommitted,
+	}
+}
+
+// IsRejected returns true if a dispute against the batch was upheld
+func (b *SettlementBatch) IsRejected() bool {
+	return b.Status == SettlementBatchStatusRejected
+}
+
+// Reject marks the batch as rejected because of the given trade
+func (b *SettlementBatch) Reject(tradeID, reason string) {
+	b.Status = SettlementBatchStatusRejected
+	b.DisputedTradeID = tradeID
+	b.DisputeReason = reason
+}
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// SpreadIDSeparator joins a spread's front and back markets into its ID
+const SpreadIDSeparator = ":"
+
+// SpreadID returns the ID of the spread between two markets, e.g.
+// "BTC-USDC:ETH-USDC"
+func SpreadID(frontMarket, backMarket string) string {
+	return frontMarket + SpreadIDSeparator + backMarket
+}
+
+// Spread is a synthetic instrument on the price difference between two perp
+// markets. Buying the spread buys the front market and sells Ratio times the
+// quantity in the back market; selling it does the reverse. Its price is
+// front - Ratio × back, so it can be negative.
+type Spread struct {
+	SpreadID    string
+	FrontMarket string
+	BackMarket  string
+	Ratio       math.LegacyDec // back leg quantity per unit of spread; 1 for a calendar spread
+	TickSize    math.LegacyDec // spread price increment; zero disables the check
+	CreatedAt   time.Time
+}
+
+// NewSpread creates a one-to-one spread between two markets
+func NewSpread(frontMarket, backMarket string) *Spread {
+	return &Spread{
+		SpreadID:    SpreadID(frontMarket, backMarket),
+		FrontMarket: frontMarket,
+		BackMarket:  backMarket,
+		Ratio:       math.LegacyOneDec(),
+		TickSize:    math.LegacyZeroDec(),
+	}
+}
+
+// Validate checks the spread's legs, ratio and tick size
+func (s *Spread) Validate() error {
+	if s.FrontMarket == "" || s.BackMarket == "" {
+		return fmt.Errorf("%w: both legs are required", ErrInvalidSpread)
+	}
+	if s.FrontMarket == s.BackMarket {
+		return fmt.Errorf("%w: legs must be different markets", ErrInvalidSpread)
+	}
+	if s.SpreadID != SpreadID(s.FrontMarket, s.BackMarket) {
+		return fmt.Errorf("%w: spread ID must be %s", ErrInvalidSpread, SpreadID(s.FrontMarket, s.BackMarket))
+	}
+	if s.Ratio.IsNil() || !s.Ratio.IsPositive() {
+		return fmt.Errorf("%w: ratio must be positive", ErrInvalidSpread)
+	}
+	if s.TickSize.IsNil() || s.TickSize.IsNegative() {
+		return fmt.Errorf("%w: tick size must not be negative", ErrInvalidSpread)
+	}
+	return nil
+}
+
+// Price returns the spread price of a pair of leg prices
+func (s *Spread) Price(front, back math.LegacyDec) math.LegacyDec {
+	return front.Sub(s.Ratio.Mul(back))
+}
+
+// OnTick reports whether a spread price is a multiple of the tick size
+func (s *Spread) OnTick(price math.LegacyDec) bool {
+	if s.TickSize.IsZero() {
+		return true
+	}
+	steps := price.Quo(s.TickSize)
+	return steps.Equal(steps.TruncateDec())
+}
+
+// LegSides returns the sides a spread order trades in the front and back markets
+func LegSides(side Side) (front, back Side) {
+	return side, side.Opposite()
+}
+
+// SpreadLegOrderID is the order ID a spread order's fills in one leg are
+// booked under, so leg trades and executions can be traced to the spread order
+func SpreadLegOrderID(orderID, marketID string) string {
+	return orderID + "@" + marketID
+}
+
+// SpreadFill is one execution of a spread order and the leg trades it was
+// booked as. An implied fill traded against the leg order books rather than
+// another spread order.
+type SpreadFill struct {
+	SpreadID   string
+	OrderID    string
+	Trader     string
+	Side       Side
+	Price      math.LegacyDec // spread price
+	Quantity   math.LegacyDec
+	FrontPrice math.LegacyDec // average price of the front leg
+	BackPrice  math.LegacyDec // average price of the back leg
+	TradeIDs   []string
+	Implied    bool
+	Timestamp  time.Time
+}
+
+// SpreadQuote is the top of a spread's own book alongside the prices implied
+// by its legs' books. Nil prices mean that side is empty.
+type SpreadQuote struct {
+	Spread        *Spread
+	BestBid       *PriceLevel
+	BestAsk       *PriceLevel
+	ImpliedBid    math.LegacyDec
+	ImpliedBidQty math.LegacyDec
+	ImpliedAsk    math.LegacyDec
+	ImpliedAskQty math.LegacyDec
+}
@@ -0,0 +1,130 @@
+package types
+
+import (
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// TradePosition is a trader's position in one market just before or after a
+// fill. Size is signed, positive for long.
+type TradePosition struct {
+	Size       math.LegacyDec
+	EntryPrice math.LegacyDec // average entry price, zero when flat
+	OpenedAt   time.Time      // time of the fill that opened it, zero when flat
+}
+
+// FlatPosition returns the position of a trader with no fills
+func FlatPosition() TradePosition {
+	return TradePosition{Size: math.LegacyZeroDec(), EntryPrice: math.LegacyZeroDec()}
+}
+
+// IsFlat reports whether the trader holds no position
+func (p TradePosition) IsFlat() bool {
+	return p.Size.IsZero()
+}
+
+// ApplyFill returns the position after a fill of quantity at price on side,
+// the part of the fill that reduced the position, and the PnL that part
+// realized against the average entry price. Increasing a position averages
+// the entry; a fill that flips the position opens the remainder at price.
+func (p TradePosition) ApplyFill(side Side, quantity, price math.LegacyDec, at time.Time) (TradePosition, math.LegacyDec, math.LegacyDec) {
+	signed := quantity
+	if side == SideSell {
+		signed = quantity.Neg()
+	}
+	next := TradePosition{Size: p.Size.Add(signed), EntryPrice: p.EntryPrice, OpenedAt: p.OpenedAt}
+
+	if p.IsFlat() || p.Size.IsPositive() == signed.IsPositive() {
+		held := p.Size.Abs()
+		if total := held.Add(quantity); total.IsPositive() {
+			next.EntryPrice = held.Mul(p.EntryPrice).Add(quantity.Mul(price)).Quo(total)
+		}
+		if p.IsFlat() {
+			next.OpenedAt = at
+		}
+		return next, math.LegacyZeroDec(), math.LegacyZeroDec()
+	}
+
+	closed := math.LegacyMinDec(quantity, p.Size.Abs())
+	realized := closed.Mul(price.Sub(p.EntryPrice))
+	if p.Size.IsNegative() {
+		realized = realized.Neg()
+	}
+	switch {
+	case next.Size.IsZero():
+		next.EntryPrice = math.LegacyZeroDec()
+		next.OpenedAt = time.Time{}
+	case next.Size.IsPositive() != p.Size.IsPositive():
+		next.EntryPrice = price
+		next.OpenedAt = at
+	}
+	return next, closed, realized
+}
+
+// TradePnL decomposes one fill's effect on one of its traders: the fee, the
+// PnL realized against the average entry price, and the position before and
+// after. Funding is settled outside the orderbook and is not included.
+type TradePnL struct {
+	Trade          *Trade
+	Trader         string
+	Role           string         // FeeRoleMaker or FeeRoleTaker
+	Side           Side           // the trader's side of the fill
+	Fee            math.LegacyDec // USDC fee charged, negative for a rebate
+	FeeEntry       *FeeEntry      // the fee ledger entry, nil if none was recorded
+	ClosedQuantity math.LegacyDec // part of the fill that reduced the position
+	RealizedPnL    math.LegacyDec
+	Before         TradePosition
+	After          TradePosition
+	Fills          int // fills folded to rebuild the position, including this one
+}
+
+// BalanceChange returns the fill's direct effect on the trader's USDC
+// balance: the realized PnL less the fee
+func (p *TradePnL) BalanceChange() math.LegacyDec {
+	return p.RealizedPnL.Sub(p.Fee)
+}
+
+// MarketActivity is a trader's fills in one market over a period
+type MarketActivity struct {
+	MarketID    string
+	Trades      int
+	Volume      math.LegacyDec // traded notional, price × quantity
+	Fees        math.LegacyDec // USDC fees charged, net of rebates
+	RealizedPnL math.LegacyDec
+	Position    TradePosition // at the end of the period
+}
+
+// NewMarketActivity returns the activity of a market with no fills
+func NewMarketActivity(marketID string) *MarketActivity {
+	return &MarketActivity{
+		MarketID:    marketID,
+		Volume:      math.LegacyZeroDec(),
+		Fees:        math.LegacyZeroDec(),
+		RealizedPnL: math.LegacyZeroDec(),
+		Position:    FlatPosition(),
+	}
+}
+
+// TraderActivity is a trader's fills over a period, per market. Realized PnL
+// is measured against the position rebuilt from every earlier fill, as in
+// TradePnL.
+type TraderActivity struct {
+	Trader  string
+	From    time.Time
+	To      time.Time
+	Markets []*MarketActivity // markets traded in the period, sorted by ID
+}
+
+// Totals returns the trade count, volume, fees and realized PnL summed over
+// every market
+func (a *TraderActivity) Totals() (trades int, volume, fees, realized math.LegacyDec) {
+	volume, fees, realized = math.LegacyZeroDec(), math.LegacyZeroDec(), math.LegacyZeroDec()
+	for _, m := range a.Markets {
+		trades += m.Trades
+		volume = volume.Add(m.Volume)
+		fees = fees.Add(m.Fees)
+		realized = realized.Add(m.RealizedPnL)
+	}
+	return trades, volume, fees, realized
+}
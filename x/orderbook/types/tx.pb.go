@@ -256,50 +256,484 @@ func (m *MsgCancelOrderResponse) GetCancelledQty() string {
 	return ""
 }
 
+// MsgCommitTradeBatch commits the Merkle root of a batch of trades matched off-chain
+type MsgCommitTradeBatch struct {
+	Submitter            string   `protobuf:"bytes,1,opt,name=submitter,proto3" json:"submitter,omitempty"`
+	BatchId              uint64   `protobuf:"varint,2,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	MerkleRoot           string   `protobuf:"bytes,3,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
+	TradeCount           uint64   `protobuf:"varint,4,opt,name=trade_count,json=tradeCount,proto3" json:"trade_count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MsgCommitTradeBatch) Reset()         { *m = MsgCommitTradeBatch{} }
+func (m *MsgCommitTradeBatch) String() string { return proto.CompactTextString(m) }
+func (*MsgCommitTradeBatch) ProtoMessage()    {}
+func (*MsgCommitTradeBatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_648f734bf0b553a4, []int{4}
+}
+func (m *MsgCommitTradeBatch) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MsgCommitTradeBatch.Unmarshal(m, b)
+}
+func (m *MsgCommitTradeBatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MsgCommitTradeBatch.Marshal(b, m, deterministic)
+}
+func (m *MsgCommitTradeBatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MsgCommitTradeBatch.Merge(m, src)
+}
+func (m *MsgCommitTradeBatch) XXX_Size() int {
+	return xxx_messageInfo_MsgCommitTradeBatch.Size(m)
+}
+func (m *MsgCommitTradeBatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_MsgCommitTradeBatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MsgCommitTradeBatch proto.InternalMessageInfo
+
+func (m *MsgCommitTradeBatch) GetSubmitter() string {
+	if m != nil {
+		return m.Submitter
+	}
+	return ""
+}
+
+func (m *MsgCommitTradeBatch) GetBatchId() uint64 {
+	if m != nil {
+		return m.BatchId
+	}
+	return 0
+}
+
+func (m *MsgCommitTradeBatch) GetMerkleRoot() string {
+	if m != nil {
+		return m.MerkleRoot
+	}
+	return ""
+}
+
+func (m *MsgCommitTradeBatch) GetTradeCount() uint64 {
+	if m != nil {
+		return m.TradeCount
+	}
+	return 0
+}
+
+// MsgCommitTradeBatchResponse defines the CommitTradeBatch response
+type MsgCommitTradeBatchResponse struct {
+	BatchId              uint64   `protobuf:"varint,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MsgCommitTradeBatchResponse) Reset()         { *m = MsgCommitTradeBatchResponse{} }
+func (m *MsgCommitTradeBatchResponse) String() string { return proto.CompactTextString(m) }
+func (*MsgCommitTradeBatchResponse) ProtoMessage()    {}
+func (*MsgCommitTradeBatchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_648f734bf0b553a4, []int{5}
+}
+func (m *MsgCommitTradeBatchResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MsgCommitTradeBatchResponse.Unmarshal(m, b)
+}
+func (m *MsgCommitTradeBatchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MsgCommitTradeBatchResponse.Marshal(b, m, deterministic)
+}
+func (m *MsgCommitTradeBatchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MsgCommitTradeBatchResponse.Merge(m, src)
+}
+func (m *MsgCommitTradeBatchResponse) XXX_Size() int {
+	return xxx_messageInfo_MsgCommitTradeBatchResponse.Size(m)
+}
+func (m *MsgCommitTradeBatchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MsgCommitTradeBatchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MsgCommitTradeBatchResponse proto.InternalMessageInfo
+
+func (m *MsgCommitTradeBatchResponse) GetBatchId() uint64 {
+	if m != nil {
+		return m.BatchId
+	}
+	return 0
+}
+
+// MsgDisputeTrade challenges a trade included in a committed settlement batch.
+// The challenger must be a counterparty of the trade and reveal it together
+// with its inclusion proof.
+type MsgDisputeTrade struct {
+	Challenger           string        `protobuf:"bytes,1,opt,name=challenger,proto3" json:"challenger,omitempty"`
+	BatchId              uint64        `protobuf:"varint,2,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	Trade                *SettledTrade `protobuf:"bytes,3,opt,name=trade,proto3" json:"trade,omitempty"`
+	Proof                *MerkleProof  `protobuf:"bytes,4,opt,name=proof,proto3" json:"proof,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *MsgDisputeTrade) Reset()         { *m = MsgDisputeTrade{} }
+func (m *MsgDisputeTrade) String() string { return proto.CompactTextString(m) }
+func (*MsgDisputeTrade) ProtoMessage()    {}
+func (*MsgDisputeTrade) Descriptor() ([]byte, []int) {
+	return fileDescriptor_648f734bf0b553a4, []int{6}
+}
+func (m *MsgDisputeTrade) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MsgDisputeTrade.Unmarshal(m, b)
+}
+func (m *MsgDisputeTrade) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MsgDisputeTrade.Marshal(b, m, deterministic)
+}
+func (m *MsgDisputeTrade) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MsgDisputeTrade.Merge(m, src)
+}
+func (m *MsgDisputeTrade) XXX_Size() int {
+	return xxx_messageInfo_MsgDisputeTrade.Size(m)
+}
+func (m *MsgDisputeTrade) XXX_DiscardUnknown() {
+	xxx_messageInfo_MsgDisputeTrade.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MsgDisputeTrade proto.InternalMessageInfo
+
+func (m *MsgDisputeTrade) GetChallenger() string {
+	if m != nil {
+		return m.Challenger
+	}
+	return ""
+}
+
+func (m *MsgDisputeTrade) GetBatchId() uint64 {
+	if m != nil {
+		return m.BatchId
+	}
+	return 0
+}
+
+func (m *MsgDisputeTrade) GetTrade() *SettledTrade {
+	if m != nil {
+		return m.Trade
+	}
+	return nil
+}
+
+func (m *MsgDisputeTrade) GetProof() *MerkleProof {
+	if m != nil {
+		return m.Proof
+	}
+	return nil
+}
+
+// MsgDisputeTradeResponse defines the DisputeTrade response
+type MsgDisputeTradeResponse struct {
+	Upheld               bool     `protobuf:"varint,1,opt,name=upheld,proto3" json:"upheld,omitempty"`
+	Reason               string   `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MsgDisputeTradeResponse) Reset()         { *m = MsgDisputeTradeResponse{} }
+func (m *MsgDisputeTradeResponse) String() string { return proto.CompactTextString(m) }
+func (*MsgDisputeTradeResponse) ProtoMessage()    {}
+func (*MsgDisputeTradeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_648f734bf0b553a4, []int{7}
+}
+func (m *MsgDisputeTradeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MsgDisputeTradeResponse.Unmarshal(m, b)
+}
+func (m *MsgDisputeTradeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MsgDisputeTradeResponse.Marshal(b, m, deterministic)
+}
+func (m *MsgDisputeTradeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MsgDisputeTradeResponse.Merge(m, src)
+}
+func (m *MsgDisputeTradeResponse) XXX_Size() int {
+	return xxx_messageInfo_MsgDisputeTradeResponse.Size(m)
+}
+func (m *MsgDisputeTradeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MsgDisputeTradeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MsgDisputeTradeResponse proto.InternalMessageInfo
+
+func (m *MsgDisputeTradeResponse) GetUpheld() bool {
+	if m != nil {
+		return m.Upheld
+	}
+	return false
+}
+
+func (m *MsgDisputeTradeResponse) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+// SettledTrade is a trade revealed from a settlement batch, carrying every
+// field its Merkle leaf commits to
+type SettledTrade struct {
+	TradeId              string   `protobuf:"bytes,1,opt,name=trade_id,json=tradeId,proto3" json:"trade_id,omitempty"`
+	MarketId             string   `protobuf:"bytes,2,opt,name=market_id,json=marketId,proto3" json:"market_id,omitempty"`
+	TakerOrderId         string   `protobuf:"bytes,3,opt,name=taker_order_id,json=takerOrderId,proto3" json:"taker_order_id,omitempty"`
+	MakerOrderId         string   `protobuf:"bytes,4,opt,name=maker_order_id,json=makerOrderId,proto3" json:"maker_order_id,omitempty"`
+	Taker                string   `protobuf:"bytes,5,opt,name=taker,proto3" json:"taker,omitempty"`
+	Maker                string   `protobuf:"bytes,6,opt,name=maker,proto3" json:"maker,omitempty"`
+	TakerSide            Side     `protobuf:"varint,7,opt,name=taker_side,json=takerSide,proto3,enum=perpdex.orderbook.v1.Side" json:"taker_side,omitempty"`
+	Price                string   `protobuf:"bytes,8,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity             string   `protobuf:"bytes,9,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	TakerFee             string   `protobuf:"bytes,10,opt,name=taker_fee,json=takerFee,proto3" json:"taker_fee,omitempty"`
+	MakerFee             string   `protobuf:"bytes,11,opt,name=maker_fee,json=makerFee,proto3" json:"maker_fee,omitempty"`
+	Timestamp            int64    `protobuf:"varint,12,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SettledTrade) Reset()         { *m = SettledTrade{} }
+func (m *SettledTrade) String() string { return proto.CompactTextString(m) }
+func (*SettledTrade) ProtoMessage()    {}
+func (*SettledTrade) Descriptor() ([]byte, []int) {
+	return fileDescriptor_648f734bf0b553a4, []int{8}
+}
+func (m *SettledTrade) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SettledTrade.Unmarshal(m, b)
+}
+func (m *SettledTrade) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SettledTrade.Marshal(b, m, deterministic)
+}
+func (m *SettledTrade) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SettledTrade.Merge(m, src)
+}
+func (m *SettledTrade) XXX_Size() int {
+	return xxx_messageInfo_SettledTrade.Size(m)
+}
+func (m *SettledTrade) XXX_DiscardUnknown() {
+	xxx_messageInfo_SettledTrade.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SettledTrade proto.InternalMessageInfo
+
+func (m *SettledTrade) GetTradeId() string {
+	if m != nil {
+		return m.TradeId
+	}
+	return ""
+}
+
+func (m *SettledTrade) GetMarketId() string {
+	if m != nil {
+		return m.MarketId
+	}
+	return ""
+}
+
+func (m *SettledTrade) GetTakerOrderId() string {
+	if m != nil {
+		return m.TakerOrderId
+	}
+	return ""
+}
+
+func (m *SettledTrade) GetMakerOrderId() string {
+	if m != nil {
+		return m.MakerOrderId
+	}
+	return ""
+}
+
+func (m *SettledTrade) GetTaker() string {
+	if m != nil {
+		return m.Taker
+	}
+	return ""
+}
+
+func (m *SettledTrade) GetMaker() string {
+	if m != nil {
+		return m.Maker
+	}
+	return ""
+}
+
+func (m *SettledTrade) GetTakerSide() Side {
+	if m != nil {
+		return m.TakerSide
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (m *SettledTrade) GetPrice() string {
+	if m != nil {
+		return m.Price
+	}
+	return ""
+}
+
+func (m *SettledTrade) GetQuantity() string {
+	if m != nil {
+		return m.Quantity
+	}
+	return ""
+}
+
+func (m *SettledTrade) GetTakerFee() string {
+	if m != nil {
+		return m.TakerFee
+	}
+	return ""
+}
+
+func (m *SettledTrade) GetMakerFee() string {
+	if m != nil {
+		return m.MakerFee
+	}
+	return ""
+}
+
+func (m *SettledTrade) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+// MerkleProof proves that a leaf is included in a Merkle root.
+// Siblings are ordered from the leaf level up to the root. When a level has an
+// odd number of nodes the last node is promoted unchanged and contributes no
+// sibling, so the proof also carries the total leaf count.
+type MerkleProof struct {
+	LeafIndex            uint64   `protobuf:"varint,1,opt,name=leaf_index,json=leafIndex,proto3" json:"leaf_index,omitempty"`
+	TotalLeaves          uint64   `protobuf:"varint,2,opt,name=total_leaves,json=totalLeaves,proto3" json:"total_leaves,omitempty"`
+	Siblings             [][]byte `protobuf:"bytes,3,rep,name=siblings,proto3" json:"siblings,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MerkleProof) Reset()         { *m = MerkleProof{} }
+func (m *MerkleProof) String() string { return proto.CompactTextString(m) }
+func (*MerkleProof) ProtoMessage()    {}
+func (*MerkleProof) Descriptor() ([]byte, []int) {
+	return fileDescriptor_648f734bf0b553a4, []int{9}
+}
+func (m *MerkleProof) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MerkleProof.Unmarshal(m, b)
+}
+func (m *MerkleProof) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MerkleProof.Marshal(b, m, deterministic)
+}
+func (m *MerkleProof) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MerkleProof.Merge(m, src)
+}
+func (m *MerkleProof) XXX_Size() int {
+	return xxx_messageInfo_MerkleProof.Size(m)
+}
+func (m *MerkleProof) XXX_DiscardUnknown() {
+	xxx_messageInfo_MerkleProof.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MerkleProof proto.InternalMessageInfo
+
+func (m *MerkleProof) GetLeafIndex() uint64 {
+	if m != nil {
+		return m.LeafIndex
+	}
+	return 0
+}
+
+func (m *MerkleProof) GetTotalLeaves() uint64 {
+	if m != nil {
+		return m.TotalLeaves
+	}
+	return 0
+}
+
+func (m *MerkleProof) GetSiblings() [][]byte {
+	if m != nil {
+		return m.Siblings
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*MsgPlaceOrder)(nil), "perpdex.orderbook.v1.MsgPlaceOrder")
 	proto.RegisterType((*MsgPlaceOrderResponse)(nil), "perpdex.orderbook.v1.MsgPlaceOrderResponse")
 	proto.RegisterType((*MsgCancelOrder)(nil), "perpdex.orderbook.v1.MsgCancelOrder")
 	proto.RegisterType((*MsgCancelOrderResponse)(nil), "perpdex.orderbook.v1.MsgCancelOrderResponse")
+	proto.RegisterType((*MsgCommitTradeBatch)(nil), "perpdex.orderbook.v1.MsgCommitTradeBatch")
+	proto.RegisterType((*MsgCommitTradeBatchResponse)(nil), "perpdex.orderbook.v1.MsgCommitTradeBatchResponse")
+	proto.RegisterType((*MsgDisputeTrade)(nil), "perpdex.orderbook.v1.MsgDisputeTrade")
+	proto.RegisterType((*MsgDisputeTradeResponse)(nil), "perpdex.orderbook.v1.MsgDisputeTradeResponse")
+	proto.RegisterType((*SettledTrade)(nil), "perpdex.orderbook.v1.SettledTrade")
+	proto.RegisterType((*MerkleProof)(nil), "perpdex.orderbook.v1.MerkleProof")
 }
 
 func init() { proto.RegisterFile("perpdex/orderbook/v1/tx.proto", fileDescriptor_648f734bf0b553a4) }
 
 var fileDescriptor_648f734bf0b553a4 = []byte{
-	// 524 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x93, 0xcf, 0x6e, 0xd3, 0x40,
-	0x10, 0xc6, 0xe5, 0xfc, 0x23, 0x99, 0xd2, 0x4a, 0x58, 0x01, 0x5c, 0x57, 0x55, 0xa3, 0x94, 0x43,
-	0x14, 0xa8, 0xb7, 0x49, 0x6f, 0x95, 0x40, 0xa2, 0x9c, 0x7a, 0x88, 0x68, 0xdd, 0x9e, 0x38, 0x10,
-	0x6d, 0xbc, 0x8b, 0xbb, 0x6a, 0xec, 0x75, 0x77, 0xb7, 0x51, 0x7d, 0x43, 0x1c, 0xb9, 0xf0, 0x10,
-	0xbc, 0x40, 0x90, 0x78, 0x0f, 0x24, 0x5e, 0x22, 0xaf, 0x81, 0xbc, 0xeb, 0x44, 0x0e, 0x18, 0xa9,
-	0xea, 0x25, 0xca, 0xcc, 0x37, 0xb3, 0xfb, 0xcd, 0x6f, 0xbc, 0xb0, 0x9b, 0x50, 0x91, 0x10, 0x7a,
-	0x87, 0xb8, 0x20, 0x54, 0x4c, 0x38, 0xbf, 0x46, 0xb3, 0x01, 0x52, 0x77, 0x5e, 0x22, 0xb8, 0xe2,
-	0x76, 0x3b, 0x97, 0xbd, 0x95, 0xec, 0xcd, 0x06, 0x6e, 0xa7, 0xbc, 0x29, 0x4d, 0xa8, 0x34, 0x7d,
-	0xee, 0xf3, 0x80, 0xcb, 0x88, 0x4b, 0x14, 0xc9, 0x30, 0x93, 0x22, 0x19, 0xe6, 0xc2, 0xb6, 0x11,
-	0xc6, 0x3a, 0x42, 0x26, 0xc8, 0xa5, 0x27, 0x38, 0x62, 0x31, 0x47, 0xfa, 0xd7, 0xa4, 0xba, 0x3f,
-	0x2a, 0xb0, 0x39, 0x92, 0xe1, 0xd9, 0x14, 0x07, 0xf4, 0x7d, 0x76, 0x97, 0x7d, 0x08, 0x0d, 0x25,
-	0x30, 0xa1, 0xc2, 0xb1, 0x3a, 0x56, 0xaf, 0x75, 0xe2, 0xfc, 0xfe, 0x79, 0xd0, 0xce, 0x8f, 0x79,
-	0x4b, 0x88, 0xa0, 0x52, 0x5e, 0x28, 0xc1, 0xe2, 0xd0, 0xcf, 0xeb, 0xec, 0x1d, 0x68, 0x45, 0x58,
-	0x5c, 0x53, 0x35, 0x66, 0xc4, 0xa9, 0x64, 0x4d, 0x7e, 0xd3, 0x24, 0x4e, 0x89, 0xed, 0x41, 0x4d,
-	0x32, 0x42, 0x9d, 0x6a, 0xc7, 0xea, 0x6d, 0x0d, 0x5d, 0xaf, 0x6c, 0x5c, 0xef, 0x82, 0x11, 0xea,
-	0xeb, 0x3a, 0xfb, 0x0d, 0x80, 0x96, 0xc6, 0xd9, 0xb0, 0x4e, 0x4d, 0x77, 0xed, 0x95, 0x77, 0x69,
-	0xbf, 0x97, 0x69, 0x42, 0xfd, 0x16, 0x5f, 0xfe, 0xb5, 0xdb, 0x50, 0x4f, 0x04, 0x0b, 0xa8, 0x53,
-	0xd7, 0x46, 0x4c, 0x60, 0xbb, 0xd0, 0xbc, 0xb9, 0xc5, 0xb1, 0x62, 0x2a, 0x75, 0x1a, 0xc6, 0xe1,
-	0x32, 0x3e, 0x46, 0x5f, 0x16, 0xf3, 0x7e, 0x3e, 0xcb, 0xd7, 0xc5, 0xbc, 0xbf, 0xf7, 0x2f, 0xfb,
-	0x35, 0x42, 0xdd, 0xef, 0x16, 0x3c, 0x5d, 0xcb, 0xf8, 0x54, 0x26, 0x3c, 0x96, 0xd4, 0xde, 0x86,
-	0xa6, 0x31, 0xcf, 0x88, 0xa1, 0xe7, 0x3f, 0xd2, 0xf1, 0x29, 0xb1, 0x77, 0x01, 0x3e, 0xb1, 0xe9,
-	0x94, 0x92, 0xf1, 0x8d, 0x4a, 0x73, 0x4a, 0x2d, 0x93, 0x39, 0x57, 0x69, 0xc6, 0x10, 0xcf, 0xc2,
-	0xb1, 0xb1, 0x5e, 0x35, 0x0e, 0xf1, 0x2c, 0x3c, 0xd3, 0xee, 0x8f, 0xf2, 0x95, 0x48, 0xa7, 0xd6,
-	0xa9, 0xf6, 0x36, 0x86, 0x3b, 0xe5, 0x3c, 0x2e, 0xb3, 0x9a, 0x7c, 0x2b, 0xb2, 0xfb, 0xcd, 0x82,
-	0xad, 0x91, 0x0c, 0xdf, 0xe1, 0x38, 0xa0, 0xd3, 0x87, 0xae, 0xb6, 0x38, 0x50, 0x65, 0x6d, 0xa0,
-	0xe3, 0xc3, 0xbf, 0xb0, 0x75, 0x4a, 0xb1, 0x15, 0xae, 0xef, 0xbe, 0x86, 0x67, 0xeb, 0x99, 0x15,
-	0xb7, 0x7d, 0xd8, 0x0c, 0x74, 0x7a, 0xc9, 0xc7, 0xc0, 0x7b, 0xbc, 0x4a, 0x9e, 0xab, 0x74, 0xf8,
-	0xcb, 0x82, 0xea, 0x48, 0x86, 0xf6, 0x47, 0x80, 0xc2, 0xe7, 0xba, 0x5f, 0xce, 0x62, 0x6d, 0x3f,
-	0xee, 0xcb, 0x7b, 0x14, 0xad, 0xcc, 0x60, 0xd8, 0x28, 0x42, 0x7b, 0xf1, 0xdf, 0xde, 0x42, 0x95,
-	0xfb, 0xea, 0x3e, 0x55, 0xcb, 0x2b, 0xdc, 0xfa, 0xe7, 0xc5, 0xbc, 0x6f, 0x9d, 0x0c, 0x3e, 0xa0,
-	0x90, 0xa9, 0xab, 0xdb, 0x89, 0x17, 0xf0, 0x08, 0xf1, 0x84, 0xc6, 0x78, 0x9a, 0x5c, 0x61, 0x94,
-	0x1d, 0x75, 0x90, 0xa1, 0x2c, 0xc2, 0xd4, 0x8f, 0x7f, 0xd2, 0xd0, 0xcf, 0xf6, 0xe8, 0x4f, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0x21, 0x37, 0x46, 0x51, 0x56, 0x04, 0x00, 0x00,
+	// 961 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0x5f, 0x6f, 0xdb, 0x54,
+	0x14, 0x57, 0xea, 0xa4, 0x8b, 0x4f, 0xb2, 0x02, 0xa6, 0x6c, 0x9e, 0xcb, 0xd4, 0xd4, 0xeb, 0xa4,
+	0x50, 0x68, 0xbc, 0x66, 0x02, 0x4a, 0x25, 0x90, 0xe8, 0x10, 0x52, 0x24, 0xa2, 0x75, 0xde, 0x9e,
+	0x78, 0xc0, 0xba, 0xb1, 0x6f, 0x1d, 0x2b, 0xb6, 0xaf, 0xe7, 0x7b, 0x13, 0x35, 0x6f, 0x08, 0xde,
+	0x78, 0x81, 0xef, 0xc0, 0x03, 0xaf, 0x45, 0xe2, 0x93, 0xf0, 0x19, 0x90, 0xfa, 0x35, 0xd0, 0xfd,
+	0x13, 0xc7, 0xe9, 0x9c, 0x11, 0xf1, 0x12, 0xe5, 0xfc, 0xce, 0xef, 0xdc, 0x73, 0xce, 0xef, 0x9e,
+	0x7b, 0x12, 0x78, 0x98, 0xe1, 0x3c, 0x0b, 0xf0, 0x95, 0x43, 0xf2, 0x00, 0xe7, 0x23, 0x42, 0x26,
+	0xce, 0xec, 0xc4, 0x61, 0x57, 0xbd, 0x2c, 0x27, 0x8c, 0x18, 0xbb, 0xca, 0xdd, 0x2b, 0xdc, 0xbd,
+	0xd9, 0x89, 0xd5, 0xa9, 0x0e, 0x9a, 0x67, 0x98, 0xca, 0x38, 0xeb, 0xbe, 0x4f, 0x68, 0x42, 0xa8,
+	0x93, 0xd0, 0x90, 0xbb, 0x12, 0x1a, 0x2a, 0xc7, 0x03, 0xe9, 0xf0, 0x84, 0xe5, 0x48, 0x43, 0xb9,
+	0xde, 0x43, 0x49, 0x94, 0x12, 0x47, 0x7c, 0x4a, 0xc8, 0xfe, 0x73, 0x0b, 0xee, 0x0e, 0x69, 0x78,
+	0x11, 0x23, 0x1f, 0x3f, 0xe7, 0xb9, 0x8c, 0x27, 0xb0, 0xcd, 0x72, 0x14, 0xe0, 0xdc, 0xac, 0x75,
+	0x6a, 0x5d, 0xfd, 0xdc, 0xfc, 0xfb, 0xaf, 0xe3, 0x5d, 0x75, 0xcc, 0xd7, 0x41, 0x90, 0x63, 0x4a,
+	0x5f, 0xb2, 0x3c, 0x4a, 0x43, 0x57, 0xf1, 0x8c, 0x3d, 0xd0, 0x13, 0x94, 0x4f, 0x30, 0xf3, 0xa2,
+	0xc0, 0xdc, 0xe2, 0x41, 0x6e, 0x53, 0x02, 0x83, 0xc0, 0xe8, 0x41, 0x9d, 0x46, 0x01, 0x36, 0xb5,
+	0x4e, 0xad, 0xbb, 0xd3, 0xb7, 0x7a, 0x55, 0xed, 0xf6, 0x5e, 0x46, 0x01, 0x76, 0x05, 0xcf, 0xf8,
+	0x0a, 0x40, 0xb8, 0x3c, 0xde, 0xac, 0x59, 0x17, 0x51, 0xfb, 0xd5, 0x51, 0xa2, 0xde, 0x57, 0xf3,
+	0x0c, 0xbb, 0x3a, 0x59, 0x7c, 0x35, 0x76, 0xa1, 0x91, 0xe5, 0x91, 0x8f, 0xcd, 0x86, 0x28, 0x44,
+	0x1a, 0x86, 0x05, 0xcd, 0xd7, 0x53, 0x94, 0xb2, 0x88, 0xcd, 0xcd, 0x6d, 0x59, 0xe1, 0xc2, 0x3e,
+	0x73, 0x7e, 0xba, 0xb9, 0x3e, 0x52, 0xbd, 0xfc, 0x72, 0x73, 0x7d, 0xb4, 0xff, 0xa6, 0xf6, 0x2b,
+	0x0a, 0xd9, 0xbf, 0xd7, 0xe0, 0x83, 0x15, 0xc4, 0xc5, 0x34, 0x23, 0x29, 0xc5, 0xc6, 0x03, 0x68,
+	0xca, 0xe2, 0xa3, 0x40, 0xaa, 0xe7, 0xde, 0x11, 0xf6, 0x20, 0x30, 0x1e, 0x02, 0x5c, 0x46, 0x71,
+	0x8c, 0x03, 0xef, 0x35, 0x9b, 0x2b, 0x95, 0x74, 0x89, 0xbc, 0x60, 0x73, 0xae, 0x21, 0x9a, 0x85,
+	0x9e, 0x2c, 0x5d, 0x93, 0x15, 0xa2, 0x59, 0x78, 0x21, 0xaa, 0x7f, 0xaa, 0xae, 0x84, 0x9a, 0xf5,
+	0x8e, 0xd6, 0x6d, 0xf5, 0xf7, 0xaa, 0xf5, 0x78, 0xc5, 0x39, 0xea, 0x56, 0xa8, 0xfd, 0x6b, 0x0d,
+	0x76, 0x86, 0x34, 0x7c, 0x86, 0x52, 0x1f, 0xc7, 0xff, 0xf7, 0x6a, 0xcb, 0x0d, 0x6d, 0xad, 0x34,
+	0x74, 0xf6, 0xe4, 0x96, 0x6c, 0x9d, 0x4a, 0xd9, 0x4a, 0xe9, 0xed, 0x2f, 0xe1, 0xde, 0x2a, 0x52,
+	0xe8, 0xf6, 0x08, 0xee, 0xfa, 0x02, 0x5e, 0xe8, 0x23, 0xc5, 0x6b, 0x17, 0xe0, 0x0b, 0x36, 0xb7,
+	0xff, 0xa9, 0xc1, 0xfb, 0x3c, 0x9e, 0x24, 0x49, 0xc4, 0x44, 0xaf, 0xe7, 0x88, 0xf9, 0x63, 0xe3,
+	0x33, 0xd0, 0xe9, 0x74, 0x94, 0x44, 0x8c, 0x6d, 0xd0, 0xd8, 0x92, 0xca, 0x7b, 0x1b, 0xf1, 0x03,
+	0x16, 0xbd, 0xd5, 0xdd, 0x3b, 0xc2, 0x1e, 0x04, 0xc6, 0x3e, 0xb4, 0x12, 0x9c, 0x4f, 0x62, 0xec,
+	0xe5, 0x84, 0x30, 0x75, 0x1f, 0x20, 0x21, 0x97, 0x10, 0xc6, 0x09, 0xa2, 0x73, 0xcf, 0x27, 0xd3,
+	0x94, 0x89, 0x31, 0xad, 0xbb, 0x20, 0xa0, 0x67, 0x1c, 0x39, 0x3b, 0xe5, 0xea, 0x2c, 0x93, 0x71,
+	0x81, 0x1e, 0x57, 0x0b, 0x74, 0xab, 0x1d, 0xfb, 0x14, 0xf6, 0x2a, 0xe0, 0xf2, 0x88, 0x15, 0x55,
+	0xd7, 0x56, 0xaa, 0xb6, 0x7f, 0xdb, 0x82, 0x77, 0x86, 0x34, 0xfc, 0x26, 0xa2, 0xd9, 0x94, 0x61,
+	0x11, 0x6b, 0x9c, 0x02, 0xf8, 0x63, 0x14, 0xc7, 0x38, 0x0d, 0x37, 0x50, 0xa7, 0xc4, 0x7d, 0x9b,
+	0x3c, 0xa7, 0xd0, 0x10, 0xad, 0x0a, 0x61, 0x5a, 0x7d, 0x7b, 0xcd, 0xa3, 0xc6, 0x8c, 0xc5, 0x38,
+	0x90, 0x53, 0x29, 0x03, 0x8c, 0xcf, 0xf9, 0xeb, 0x24, 0xe4, 0x52, 0x28, 0xd6, 0xea, 0x1f, 0x54,
+	0x47, 0x0e, 0x85, 0xd0, 0x17, 0x9c, 0xe8, 0x4a, 0xfe, 0xd9, 0xa7, 0x5c, 0xcf, 0x52, 0x79, 0x5c,
+	0xd0, 0x83, 0x4a, 0x41, 0xcb, 0xed, 0xdb, 0x03, 0xb8, 0x7f, 0x0b, 0x2a, 0x84, 0xbc, 0x07, 0xdb,
+	0xd3, 0x6c, 0x8c, 0x63, 0x29, 0x63, 0xd3, 0x55, 0x16, 0xc7, 0x73, 0x8c, 0x28, 0x49, 0xd5, 0xc0,
+	0x2b, 0xcb, 0xfe, 0x59, 0x83, 0x76, 0xb9, 0x25, 0x2e, 0x90, 0x9c, 0x81, 0xe5, 0x63, 0x17, 0xf6,
+	0x20, 0x78, 0xfb, 0x46, 0x3c, 0x84, 0x1d, 0x86, 0x26, 0x38, 0xf7, 0x8a, 0x97, 0x25, 0xe7, 0xab,
+	0x2d, 0xd0, 0xe7, 0x6a, 0x5f, 0x1c, 0xc2, 0x4e, 0xb2, 0xca, 0xaa, 0x4b, 0x56, 0x52, 0x66, 0xed,
+	0x42, 0x43, 0x44, 0x2d, 0xb6, 0x9d, 0x30, 0x38, 0x2a, 0x58, 0x6a, 0xd5, 0x49, 0xc3, 0xf8, 0x02,
+	0x40, 0xe6, 0x15, 0xfb, 0xf8, 0xce, 0x7f, 0xee, 0x63, 0x5d, 0xb0, 0xf9, 0xd7, 0xe5, 0x52, 0x6d,
+	0xae, 0x5b, 0xaa, 0xfa, 0xea, 0x52, 0xe5, 0x0a, 0xc8, 0x64, 0x97, 0x18, 0x9b, 0x20, 0x9d, 0x02,
+	0xf8, 0x16, 0x63, 0x29, 0xcf, 0xc2, 0xd9, 0x5a, 0xc8, 0xa3, 0x9c, 0x1f, 0x82, 0xce, 0xa2, 0x04,
+	0x53, 0x86, 0x92, 0xcc, 0x6c, 0x77, 0x6a, 0x5d, 0xcd, 0x5d, 0x02, 0xf6, 0x04, 0x5a, 0xa5, 0xe9,
+	0xe0, 0x5b, 0x35, 0xc6, 0xe8, 0xd2, 0x8b, 0xd2, 0x00, 0x5f, 0xa9, 0xf7, 0xa0, 0x73, 0x64, 0xc0,
+	0x01, 0xe3, 0x00, 0xda, 0x8c, 0x30, 0x14, 0x7b, 0x31, 0x46, 0x33, 0x4c, 0xd5, 0x1c, 0xb7, 0x04,
+	0xf6, 0x9d, 0x80, 0x78, 0x13, 0x34, 0x1a, 0xc5, 0x51, 0x1a, 0x52, 0x53, 0xeb, 0x68, 0xdd, 0xb6,
+	0x5b, 0xd8, 0xfd, 0x3f, 0x34, 0xd0, 0x86, 0x34, 0x34, 0x7e, 0x00, 0x28, 0xfd, 0x40, 0x3e, 0x5a,
+	0x33, 0xb4, 0xe5, 0x5f, 0x04, 0xeb, 0xe3, 0x0d, 0x48, 0xc5, 0x28, 0x22, 0x68, 0x95, 0xd7, 0xf4,
+	0xe1, 0xda, 0xd8, 0x12, 0xcb, 0xfa, 0x64, 0x13, 0x56, 0x91, 0x22, 0x83, 0x77, 0xdf, 0x58, 0x9c,
+	0x1f, 0xad, 0x3f, 0xe1, 0x16, 0xd5, 0x3a, 0xd9, 0x98, 0x5a, 0x64, 0x0c, 0xa0, 0xbd, 0xb2, 0x89,
+	0x1e, 0xaf, 0x3d, 0xa2, 0x4c, 0xb3, 0x8e, 0x37, 0xa2, 0x2d, 0xb2, 0x58, 0x8d, 0x1f, 0x6f, 0xae,
+	0x8f, 0x6a, 0xe7, 0x27, 0xdf, 0x3b, 0x61, 0xc4, 0xc6, 0xd3, 0x51, 0xcf, 0x27, 0x89, 0x43, 0x32,
+	0x9c, 0xa2, 0x38, 0x1b, 0x23, 0x87, 0x9f, 0x75, 0xcc, 0x57, 0x44, 0x79, 0x49, 0x88, 0xbf, 0x51,
+	0xa3, 0x6d, 0xf1, 0x07, 0xe8, 0xe9, 0xbf, 0x03, 0x00, 0xc4, 0xf5, 0xef, 0x4c, 0xa0, 0x09, 0x00,
+	0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -318,6 +752,10 @@ type MsgClient interface {
 	PlaceOrder(ctx context.Context, in *MsgPlaceOrder, opts ...grpc.CallOption) (*MsgPlaceOrderResponse, error)
 	// CancelOrder cancels an existing order
 	CancelOrder(ctx context.Context, in *MsgCancelOrder, opts ...grpc.CallOption) (*MsgCancelOrderResponse, error)
+	// CommitTradeBatch commits the Merkle root of a batch of trades matched off-chain
+	CommitTradeBatch(ctx context.Context, in *MsgCommitTradeBatch, opts ...grpc.CallOption) (*MsgCommitTradeBatchResponse, error)
+	// DisputeTrade challenges a trade in a committed settlement batch
+	DisputeTrade(ctx context.Context, in *MsgDisputeTrade, opts ...grpc.CallOption) (*MsgDisputeTradeResponse, error)
 }
 
 type msgClient struct {
@@ -346,12 +784,34 @@ func (c *msgClient) CancelOrder(ctx context.Context, in *MsgCancelOrder, opts ..
 	return out, nil
 }
 
+func (c *msgClient) CommitTradeBatch(ctx context.Context, in *MsgCommitTradeBatch, opts ...grpc.CallOption) (*MsgCommitTradeBatchResponse, error) {
+	out := new(MsgCommitTradeBatchResponse)
+	err := c.cc.Invoke(ctx, "/perpdex.orderbook.v1.Msg/CommitTradeBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgClient) DisputeTrade(ctx context.Context, in *MsgDisputeTrade, opts ...grpc.CallOption) (*MsgDisputeTradeResponse, error) {
+	out := new(MsgDisputeTradeResponse)
+	err := c.cc.Invoke(ctx, "/perpdex.orderbook.v1.Msg/DisputeTrade", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MsgServer is the server API for Msg service.
 type MsgServer interface {
 	// PlaceOrder places a new order
 	PlaceOrder(context.Context, *MsgPlaceOrder) (*MsgPlaceOrderResponse, error)
 	// CancelOrder cancels an existing order
 	CancelOrder(context.Context, *MsgCancelOrder) (*MsgCancelOrderResponse, error)
+	// CommitTradeBatch commits the Merkle root of a batch of trades matched off-chain
+	CommitTradeBatch(context.Context, *MsgCommitTradeBatch) (*MsgCommitTradeBatchResponse, error)
+	// DisputeTrade challenges a trade in a committed settlement batch
+	DisputeTrade(context.Context, *MsgDisputeTrade) (*MsgDisputeTradeResponse, error)
 }
 
 // UnimplementedMsgServer can be embedded to have forward compatible implementations.
@@ -364,6 +824,12 @@ func (*UnimplementedMsgServer) PlaceOrder(ctx context.Context, req *MsgPlaceOrde
 func (*UnimplementedMsgServer) CancelOrder(ctx context.Context, req *MsgCancelOrder) (*MsgCancelOrderResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
 }
+func (*UnimplementedMsgServer) CommitTradeBatch(ctx context.Context, req *MsgCommitTradeBatch) (*MsgCommitTradeBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitTradeBatch not implemented")
+}
+func (*UnimplementedMsgServer) DisputeTrade(ctx context.Context, req *MsgDisputeTrade) (*MsgDisputeTradeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisputeTrade not implemented")
+}
 
 func RegisterMsgServer(s grpc1.Server, srv MsgServer) {
 	s.RegisterService(&_Msg_serviceDesc, srv)
@@ -405,6 +871,42 @@ func _Msg_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Msg_CommitTradeBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsgCommitTradeBatch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgServer).CommitTradeBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/perpdex.orderbook.v1.Msg/CommitTradeBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgServer).CommitTradeBatch(ctx, req.(*MsgCommitTradeBatch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Msg_DisputeTrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsgDisputeTrade)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgServer).DisputeTrade(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/perpdex.orderbook.v1.Msg/DisputeTrade",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgServer).DisputeTrade(ctx, req.(*MsgDisputeTrade))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var Msg_serviceDesc = _Msg_serviceDesc
 var _Msg_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "perpdex.orderbook.v1.Msg",
@@ -418,6 +920,14 @@ var _Msg_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CancelOrder",
 			Handler:    _Msg_CancelOrder_Handler,
 		},
+		{
+			MethodName: "CommitTradeBatch",
+			Handler:    _Msg_CommitTradeBatch_Handler,
+		},
+		{
+			MethodName: "DisputeTrade",
+			Handler:    _Msg_DisputeTrade_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "perpdex/orderbook/v1/tx.proto",
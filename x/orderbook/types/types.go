@@ -111,6 +111,8 @@ const (
 	OrderStatusFilled
 	OrderStatusPartiallyFilled
 	OrderStatusCancelled
+	OrderStatusExpired
+	OrderStatusPriceProtected
 )
 
 // Proto-compatible aliases for OrderStatus enum
@@ -120,6 +122,8 @@ const (
 	OrderStatus_ORDER_STATUS_FILLED           = OrderStatusFilled
 	OrderStatus_ORDER_STATUS_PARTIALLY_FILLED = OrderStatusPartiallyFilled
 	OrderStatus_ORDER_STATUS_CANCELLED        = OrderStatusCancelled
+	OrderStatus_ORDER_STATUS_EXPIRED          = OrderStatusExpired
+	OrderStatus_ORDER_STATUS_PRICE_PROTECTED  = OrderStatusPriceProtected
 )
 
 // Proto-compatible maps for OrderStatus enum
@@ -129,6 +133,8 @@ var OrderStatus_name = map[int32]string{
 	2: "ORDER_STATUS_FILLED",
 	3: "ORDER_STATUS_PARTIALLY_FILLED",
 	4: "ORDER_STATUS_CANCELLED",
+	5: "ORDER_STATUS_EXPIRED",
+	6: "ORDER_STATUS_PRICE_PROTECTED",
 }
 
 var OrderStatus_value = map[string]int32{
@@ -137,6 +143,8 @@ var OrderStatus_value = map[string]int32{
 	"ORDER_STATUS_FILLED":           2,
 	"ORDER_STATUS_PARTIALLY_FILLED": 3,
 	"ORDER_STATUS_CANCELLED":        4,
+	"ORDER_STATUS_EXPIRED":          5,
+	"ORDER_STATUS_PRICE_PROTECTED":  6,
 }
 
 func (s OrderStatus) String() string {
@@ -149,6 +157,10 @@ func (s OrderStatus) String() string {
 		return "ORDER_STATUS_PARTIALLY_FILLED"
 	case OrderStatusCancelled:
 		return "ORDER_STATUS_CANCELLED"
+	case OrderStatusExpired:
+		return "ORDER_STATUS_EXPIRED"
+	case OrderStatusPriceProtected:
+		return "ORDER_STATUS_PRICE_PROTECTED"
 	default:
 		return "ORDER_STATUS_UNSPECIFIED"
 	}
@@ -167,6 +179,10 @@ type Order struct {
 	Status    OrderStatus
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	Seq       uint64         // global event sequence number of the order's latest update
+	ExpireAt  *time.Time     `json:",omitempty"` // good-till-date expiry; nil rests until cancelled
+	PriceCap  math.LegacyDec // worst price a market order fills at; nil or zero walks the book
+	Quote     *QuoteContext  `json:",omitempty"` // book the order met when it came to rest; nil until it rests
 }
 
 // NewOrder creates a new order
@@ -217,12 +233,52 @@ func (o *Order) Fill(qty math.LegacyDec) error {
 	return nil
 }
 
+// HasPriceCap returns true if the order is a market order with slippage
+// protection
+func (o *Order) HasPriceCap() bool {
+	return o.OrderType == OrderTypeMarket && !o.PriceCap.IsNil() && o.PriceCap.IsPositive()
+}
+
+// WithinPriceCap returns true if the order may fill at price: always for a
+// limit order or an uncapped market order
+func (o *Order) WithinPriceCap(price math.LegacyDec) bool {
+	if !o.HasPriceCap() {
+		return true
+	}
+	if o.Side == SideBuy {
+		return price.LTE(o.PriceCap)
+	}
+	return price.GTE(o.PriceCap)
+}
+
 // Cancel cancels the order
 func (o *Order) Cancel() {
 	o.Status = OrderStatusCancelled
 	o.UpdatedAt = time.Now()
 }
 
+// CancelAtPriceCap cancels the remainder of a market order the book could
+// only fill beyond its price cap
+func (o *Order) CancelAtPriceCap() {
+	o.Status = OrderStatusPriceProtected
+	o.UpdatedAt = time.Now()
+}
+
+// SlippagePriceCap returns the worst price a market order on side may fill
+// at: maxSlippage above the mark price for a buy, below it for a sell
+func SlippagePriceCap(side Side, markPrice, maxSlippage math.LegacyDec) math.LegacyDec {
+	if side == SideBuy {
+		return markPrice.Mul(math.LegacyOneDec().Add(maxSlippage))
+	}
+	return markPrice.Mul(math.LegacyOneDec().Sub(maxSlippage))
+}
+
+// Expire closes a good-till-date order that reached its expiry
+func (o *Order) Expire() {
+	o.Status = OrderStatusExpired
+	o.UpdatedAt = time.Now()
+}
+
 // PriceLevel represents a price level in the order book
 type PriceLevel struct {
 	Price    math.LegacyDec
@@ -324,6 +380,22 @@ func (ob *OrderBook) RemoveOrder(order *Order) {
 	}
 }
 
+// ReduceOrder lowers the resting quantity of an order by qty without moving
+// it, so the order keeps its place in the level's FIFO queue
+func (ob *OrderBook) ReduceOrder(order *Order, qty math.LegacyDec) {
+	levels := ob.Asks
+	if order.Side == SideBuy {
+		levels = ob.Bids
+	}
+
+	for _, pl := range levels {
+		if pl.Price.Equal(order.Price) {
+			pl.Quantity = pl.Quantity.Sub(qty)
+			break
+		}
+	}
+}
+
 // sortLevels sorts bids descending and asks ascending
 func (ob *OrderBook) sortLevels() {
 	// Sort bids descending (highest price first)
@@ -375,7 +447,9 @@ type Trade struct {
 	Quantity     math.LegacyDec
 	TakerFee     math.LegacyDec
 	MakerFee     math.LegacyDec
+	MakerRebate  math.LegacyDec // dynamic rebate taken off MakerFee; nil or zero without one
 	Timestamp    time.Time
+	Seq          uint64 // global event sequence number
 }
 
 // TradeWithSettlement contains trade data plus settlement fields.
@@ -424,6 +498,7 @@ func NewTrade(tradeID, marketID string, takerOrder, makerOrder *Order, price, qt
 		Quantity:     qty,
 		TakerFee:     takerFee,
 		MakerFee:     makerFee,
+		MakerRebate:  math.LegacyZeroDec(),
 		Timestamp:    time.Now(),
 	}
 }
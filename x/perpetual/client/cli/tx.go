@@ -2,17 +2,27 @@ package cli
 
 import (
 	"fmt"
-	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"cosmossdk.io/math"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
 	"github.com/openalpha/perp-dex/x/perpetual/types"
 )
 
+// Transaction flags
+const (
+	FlagDestination = "destination"
+	FlagSide        = "side"
+	FlagSize        = "size"
+	FlagPrice       = "price"
+)
+
 // GetTxCmd returns the transaction commands for the perpetual module
 func GetTxCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -26,31 +36,47 @@ func GetTxCmd() *cobra.Command {
 	cmd.AddCommand(
 		CmdDeposit(),
 		CmdWithdraw(),
+		CmdInternalTransfer(),
+		CmdClosePosition(),
 	)
 
 	return cmd
 }
 
+// parseAmount parses a positive decimal amount
+func parseAmount(amount string) (math.LegacyDec, error) {
+	dec, err := math.LegacyNewDecFromStr(amount)
+	if err != nil || !dec.IsPositive() {
+		return math.LegacyDec{}, fmt.Errorf("invalid amount: %q", amount)
+	}
+	return dec, nil
+}
+
 // CmdDeposit returns the command to deposit margin
 func CmdDeposit() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "deposit [amount]",
 		Short: "Deposit margin to trading account",
-		Args:  cobra.ExactArgs(1),
+		Long: `Deposit margin to the trading account of --from.
+
+Examples:
+  perpdexd tx perpetual deposit 1000 --from alice
+  perpdexd tx perpetual deposit 1000 --from cosmos1... --generate-only > unsigned.json`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			clientCtx, err := client.GetClientTxContext(cmd)
 			if err != nil {
 				return err
 			}
 
-			amount, err := strconv.ParseFloat(args[0], 64)
+			amount, err := parseAmount(args[0])
 			if err != nil {
-				return fmt.Errorf("invalid amount: %v", err)
+				return err
 			}
 
 			msg := &types.MsgDeposit{
 				Trader: clientCtx.GetFromAddress().String(),
-				Amount: fmt.Sprintf("%f", amount),
+				Amount: amount.String(),
 			}
 
 			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg)
@@ -66,27 +92,152 @@ func CmdWithdraw() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "withdraw [amount]",
 		Short: "Withdraw margin from trading account",
-		Args:  cobra.ExactArgs(1),
+		Long: `Withdraw free margin from the trading account of --from, to --destination
+or back to the trader. The account's withdrawal timelock and allowlist apply.
+
+Examples:
+  perpdexd tx perpetual withdraw 500 --from alice
+  perpdexd tx perpetual withdraw 500 --destination cosmos1... --from alice`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			clientCtx, err := client.GetClientTxContext(cmd)
 			if err != nil {
 				return err
 			}
 
-			amount, err := strconv.ParseFloat(args[0], 64)
+			amount, err := parseAmount(args[0])
 			if err != nil {
-				return fmt.Errorf("invalid amount: %v", err)
+				return err
 			}
+			destination, _ := cmd.Flags().GetString(FlagDestination)
 
 			msg := &types.MsgWithdraw{
-				Trader: clientCtx.GetFromAddress().String(),
-				Amount: fmt.Sprintf("%f", amount),
+				Trader:      clientCtx.GetFromAddress().String(),
+				Amount:      amount.String(),
+				Destination: destination,
 			}
 
 			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg)
 		},
 	}
 
+	cmd.Flags().String(FlagDestination, "", "Address to withdraw to (default: the trader)")
 	flags.AddTxFlagsToCmd(cmd)
 	return cmd
 }
+
+// CmdInternalTransfer returns the command to move free margin to another account
+func CmdInternalTransfer() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transfer [recipient] [amount]",
+		Short: "Transfer free margin to another trading account",
+		Long: `Move free margin from the trading account of --from to another account at
+once. Locked margin cannot move; the account's withdrawal allowlist applies.
+
+Examples:
+  perpdexd tx perpetual transfer cosmos1... 2500 --from alice`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			amount, err := parseAmount(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := &types.MsgInternalTransfer{
+				Sender:    clientCtx.GetFromAddress().String(),
+				Recipient: args[0],
+				Amount:    amount.String(),
+			}
+
+			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg)
+		},
+	}
+
+	flags.AddTxFlagsToCmd(cmd)
+	return cmd
+}
+
+// CmdClosePosition returns the command to close a position
+func CmdClosePosition() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "close-position [market-id]",
+		Short: "Close all or part of a position",
+		Long: `Close a position by placing an order on the opposite side: a market order, or
+a limit order at --price. --side is the side of the position being closed
+and --size how much of it to close; both are required so the transaction can
+be built offline with --generate-only.
+
+Examples:
+  perpdexd tx perpetual close-position BTC-USDC --side long --size 0.5 --from alice
+  perpdexd tx perpetual close-position ETH-USDC --side short --size 2 --price 3000 --from alice`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			sideStr, _ := cmd.Flags().GetString(FlagSide)
+			sizeStr, _ := cmd.Flags().GetString(FlagSize)
+			priceStr, _ := cmd.Flags().GetString(FlagPrice)
+
+			msg, err := ClosePositionMsg(clientCtx.GetFromAddress().String(), args[0], sideStr, sizeStr, priceStr)
+			if err != nil {
+				return err
+			}
+
+			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg)
+		},
+	}
+
+	cmd.Flags().String(FlagSide, "", "Side of the position to close: long or short (required)")
+	cmd.Flags().String(FlagSize, "", "Position size to close (required)")
+	cmd.Flags().String(FlagPrice, "", "Limit price; a market order if not set")
+	_ = cmd.MarkFlagRequired(FlagSide)
+	_ = cmd.MarkFlagRequired(FlagSize)
+	flags.AddTxFlagsToCmd(cmd)
+	return cmd
+}
+
+// ClosePositionMsg builds the order that closes size of a long or short
+// position: a sell for a long and a buy for a short, at the market unless a
+// limit price is given
+func ClosePositionMsg(trader, marketID, positionSide, size, price string) (*orderbooktypes.MsgPlaceOrder, error) {
+	var side orderbooktypes.Side
+	switch strings.ToLower(positionSide) {
+	case "long":
+		side = orderbooktypes.SideSell
+	case "short":
+		side = orderbooktypes.SideBuy
+	default:
+		return nil, fmt.Errorf("invalid position side: %s (use 'long' or 'short')", positionSide)
+	}
+
+	quantity, err := math.LegacyNewDecFromStr(size)
+	if err != nil || !quantity.IsPositive() {
+		return nil, fmt.Errorf("invalid size: %q", size)
+	}
+
+	msg := &orderbooktypes.MsgPlaceOrder{
+		Trader:    trader,
+		MarketId:  marketID,
+		Side:      side,
+		OrderType: orderbooktypes.OrderTypeMarket,
+		Price:     math.LegacyZeroDec().String(),
+		Quantity:  quantity.String(),
+	}
+	if price != "" {
+		limit, err := math.LegacyNewDecFromStr(price)
+		if err != nil || !limit.IsPositive() {
+			return nil, fmt.Errorf("invalid price: %q", price)
+		}
+		msg.OrderType = orderbooktypes.OrderTypeLimit
+		msg.Price = limit.String()
+	}
+	return msg, msg.ValidateBasic()
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"testing"
+
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+)
+
+// TestClosePositionMsg tests that closing a position places an order on the
+// opposite side, at the market unless a price is given
+func TestClosePositionMsg(t *testing.T) {
+	msg, err := ClosePositionMsg("alice", "BTC-USDC", "long", "0.5", "")
+	if err != nil {
+		t.Fatalf("failed to build close order: %v", err)
+	}
+	if msg.Side != orderbooktypes.SideSell || msg.OrderType != orderbooktypes.OrderTypeMarket || msg.Quantity != "0.500000000000000000" {
+		t.Errorf("expected a market sell of 0.5, got %+v", msg)
+	}
+
+	msg, err = ClosePositionMsg("alice", "ETH-USDC", "SHORT", "2", "3000")
+	if err != nil || msg.Side != orderbooktypes.SideBuy || msg.OrderType != orderbooktypes.OrderTypeLimit || msg.Price != "3000.000000000000000000" {
+		t.Errorf("expected a limit buy at 3000, got %+v (err %v)", msg, err)
+	}
+
+	for _, args := range [][3]string{{"flat", "1", ""}, {"long", "0", ""}, {"long", "1", "0"}, {"short", "x", ""}} {
+		if _, err := ClosePositionMsg("alice", "BTC-USDC", args[0], args[1], args[2]); err == nil {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package keeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// Store key prefix for account suspensions and freezes
+var AccountRestrictionKeyPrefix = []byte{0x15}
+
+func accountRestrictionKey(trader string) []byte {
+	return append(append([]byte{}, AccountRestrictionKeyPrefix...), []byte(trader)...)
+}
+
+// SetAccountRestriction suspends or freezes an account, replacing any
+// restriction it already has. An expiry must be after the block time.
+func (k *Keeper) SetAccountRestriction(ctx sdk.Context, restriction *types.AccountRestriction) error {
+	if err := restriction.Validate(); err != nil {
+		return err
+	}
+	if !restriction.ExpiresAt.IsZero() && !restriction.ExpiresAt.After(ctx.BlockTime()) {
+		return fmt.Errorf("%w: expiry must be in the future", types.ErrInvalidAccountRestriction)
+	}
+	restriction.CreatedAt = ctx.BlockTime()
+	k.setAccountRestriction(ctx, restriction)
+
+	expiresAt := ""
+	if !restriction.ExpiresAt.IsZero() {
+		expiresAt = restriction.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"account_"+string(restriction.Level),
+			sdk.NewAttribute("trader", restriction.Trader),
+			sdk.NewAttribute("reason_code", restriction.ReasonCode),
+			sdk.NewAttribute("reason", restriction.Reason),
+			sdk.NewAttribute("actor", restriction.Actor),
+			sdk.NewAttribute("expires_at", expiresAt),
+		),
+	)
+	return nil
+}
+
+func (k *Keeper) setAccountRestriction(ctx sdk.Context, restriction *types.AccountRestriction) {
+	bz, _ := json.Marshal(restriction)
+	k.GetStore(ctx).Set(accountRestrictionKey(restriction.Trader), bz)
+}
+
+// GetAccountRestriction returns an account's restriction in force at the
+// block time, or nil if it has none or it has expired
+func (k *Keeper) GetAccountRestriction(ctx sdk.Context, trader string) *types.AccountRestriction {
+	restriction := k.getStoredAccountRestriction(ctx, trader)
+	if restriction == nil || !restriction.IsActive(ctx.BlockTime()) {
+		return nil
+	}
+	return restriction
+}
+
+func (k *Keeper) getStoredAccountRestriction(ctx sdk.Context, trader string) *types.AccountRestriction {
+	bz := k.GetStore(ctx).Get(accountRestrictionKey(trader))
+	if bz == nil {
+		return nil
+	}
+	var restriction types.AccountRestriction
+	if err := json.Unmarshal(bz, &restriction); err != nil {
+		return nil
+	}
+	return &restriction
+}
+
+// GetAllAccountRestrictions returns the restrictions in force at the block time
+func (k *Keeper) GetAllAccountRestrictions(ctx sdk.Context) []*types.AccountRestriction {
+	restrictions := make([]*types.AccountRestriction, 0)
+	for _, restriction := range k.getStoredAccountRestrictions(ctx) {
+		if restriction.IsActive(ctx.BlockTime()) {
+			restrictions = append(restrictions, restriction)
+		}
+	}
+	return restrictions
+}
+
+func (k *Keeper) getStoredAccountRestrictions(ctx sdk.Context) []*types.AccountRestriction {
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), AccountRestrictionKeyPrefix)
+	defer iterator.Close()
+
+	restrictions := make([]*types.AccountRestriction, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var restriction types.AccountRestriction
+		if err := json.Unmarshal(iterator.Value(), &restriction); err != nil {
+			continue
+		}
+		restrictions = append(restrictions, &restriction)
+	}
+	return restrictions
+}
+
+// LiftAccountRestriction removes an account's restriction before it expires
+// and returns it
+func (k *Keeper) LiftAccountRestriction(ctx sdk.Context, trader, actor string) (*types.AccountRestriction, error) {
+	restriction := k.GetAccountRestriction(ctx, trader)
+	if restriction == nil {
+		return nil, fmt.Errorf("%w: %s", types.ErrAccountRestrictionNotFound, trader)
+	}
+	k.deleteAccountRestriction(ctx, restriction, actor, "lifted")
+	return restriction, nil
+}
+
+func (k *Keeper) deleteAccountRestriction(ctx sdk.Context, restriction *types.AccountRestriction, actor, cause string) {
+	k.GetStore(ctx).Delete(accountRestrictionKey(restriction.Trader))
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"account_restriction_lifted",
+			sdk.NewAttribute("trader", restriction.Trader),
+			sdk.NewAttribute("level", string(restriction.Level)),
+			sdk.NewAttribute("reason_code", restriction.ReasonCode),
+			sdk.NewAttribute("actor", actor),
+			sdk.NewAttribute("cause", cause),
+		),
+	)
+}
+
+// CheckTradingRestriction returns the restriction's error if it rejects a
+// new order of quantity on side in a market. A suspended account may only
+// place orders against its position there, no larger than it; a frozen
+// account may place none.
+func (k *Keeper) CheckTradingRestriction(ctx sdk.Context, trader, marketID string, side types.PositionSide, quantity math.LegacyDec) error {
+	restriction := k.GetAccountRestriction(ctx, trader)
+	if restriction == nil {
+		return nil
+	}
+	if restriction.Level == types.RestrictionSuspended {
+		position := k.GetPosition(ctx, trader, marketID)
+		if position != nil && position.Side != side && quantity.LTE(position.Size) {
+			return nil
+		}
+	}
+	return restriction.Err()
+}
+
+// checkAccountNotFrozen returns the freeze's error if funds may not leave
+// the account
+func (k *Keeper) checkAccountNotFrozen(ctx sdk.Context, trader string) error {
+	if restriction := k.GetAccountRestriction(ctx, trader); restriction != nil && restriction.Level == types.RestrictionFrozen {
+		return restriction.Err()
+	}
+	return nil
+}
+
+// AccountRestrictionEndBlocker removes restrictions that have expired
+func (k *Keeper) AccountRestrictionEndBlocker(ctx sdk.Context) {
+	for _, restriction := range k.getStoredAccountRestrictions(ctx) {
+		if !restriction.IsActive(ctx.BlockTime()) {
+			k.deleteAccountRestriction(ctx, restriction, "", "expired")
+		}
+	}
+}
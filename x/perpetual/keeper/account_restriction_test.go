@@ -0,0 +1,109 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TestAccountRestriction tests that a suspended account can only reduce its
+// position, that a frozen account can neither trade nor move funds, and that
+// a restriction lapses at its expiry and survives an export and import
+func TestAccountRestriction(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	if err := k.Deposit(ctx, "alice", dec("10000")); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	k.SetPosition(ctx, types.NewPosition("alice", "BTC-USDC", types.PositionSideLong, dec("1"), dec("50000"), dec("2500")))
+	long, short := types.PositionSideLong, types.PositionSideShort
+
+	invalid := &types.AccountRestriction{Trader: "alice", Level: types.RestrictionSuspended, ReasonCode: "because"}
+	if err := k.SetAccountRestriction(ctx, invalid); !errors.Is(err, types.ErrInvalidAccountRestriction) {
+		t.Fatalf("expected an unknown reason code to fail, got %v", err)
+	}
+	invalid.ReasonCode, invalid.ExpiresAt = types.RestrictionReasonCompliance, ctx.BlockTime()
+	if err := k.SetAccountRestriction(ctx, invalid); !errors.Is(err, types.ErrInvalidAccountRestriction) {
+		t.Fatalf("expected a past expiry to fail, got %v", err)
+	}
+
+	expiresAt := ctx.BlockTime().Add(time.Hour)
+	suspension := &types.AccountRestriction{
+		Trader:     "alice",
+		Level:      types.RestrictionSuspended,
+		ReasonCode: types.RestrictionReasonCompliance,
+		Reason:     "KYC review",
+		Actor:      "ops",
+		ExpiresAt:  expiresAt,
+	}
+	if err := k.SetAccountRestriction(ctx, suspension); err != nil {
+		t.Fatalf("failed to suspend: %v", err)
+	}
+	if err := k.CheckMarginRequirement(ctx, "alice", "BTC-USDC", long, dec("0.1"), dec("50000")); !errors.Is(err, types.ErrAccountSuspended) {
+		t.Errorf("expected a suspended account not to add to its position, got %v", err)
+	}
+	if err := k.CheckMarginRequirement(ctx, "alice", "BTC-USDC", short, dec("2"), dec("50000")); !errors.Is(err, types.ErrAccountSuspended) {
+		t.Errorf("expected a suspended account not to flip its position, got %v", err)
+	}
+	if err := k.CheckMarginRequirement(ctx, "alice", "BTC-USDC", short, dec("1"), dec("50000")); err != nil {
+		t.Errorf("expected a suspended account to close its position, got %v", err)
+	}
+	if err := k.Withdraw(ctx, "alice", dec("100")); err != nil {
+		t.Errorf("expected a suspended account to withdraw, got %v", err)
+	}
+
+	// A freeze replaces the suspension
+	suspension.Level = types.RestrictionFrozen
+	if err := k.SetAccountRestriction(ctx, suspension); err != nil {
+		t.Fatalf("failed to freeze: %v", err)
+	}
+	if err := k.CheckMarginRequirement(ctx, "alice", "BTC-USDC", short, dec("1"), dec("50000")); !errors.Is(err, types.ErrAccountFrozen) {
+		t.Errorf("expected a frozen account not to trade, got %v", err)
+	}
+	if err := k.Withdraw(ctx, "alice", dec("100")); !errors.Is(err, types.ErrAccountFrozen) {
+		t.Errorf("expected a frozen account not to withdraw, got %v", err)
+	}
+	if _, err := k.InternalTransfer(ctx, "alice", "bob", dec("100")); !errors.Is(err, types.ErrAccountFrozen) {
+		t.Errorf("expected a frozen account not to transfer, got %v", err)
+	}
+	if err := k.CheckTradingRestriction(ctx, "bob", "BTC-USDC", long, dec("0.01")); err != nil {
+		t.Errorf("expected other accounts to trade, got %v", err)
+	}
+
+	gs := k.ExportGenesis(ctx)
+	if len(gs.AccountRestrictions) != 1 || gs.AccountRestrictions[0].Level != types.RestrictionFrozen {
+		t.Fatalf("expected the freeze to be exported, got %+v", gs.AccountRestrictions)
+	}
+	imported, importCtx := setupFundingKeeper(t)
+	if err := imported.InitGenesis(importCtx, gs); err != nil {
+		t.Fatalf("failed to import genesis: %v", err)
+	}
+	if r := imported.GetAccountRestriction(importCtx, "alice"); r == nil || r.Reason != "KYC review" || !r.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("expected the freeze back, got %+v", r)
+	}
+
+	// At its expiry the freeze no longer applies and the EndBlocker removes it
+	ctx = ctx.WithBlockTime(expiresAt)
+	if err := k.Withdraw(ctx, "alice", dec("100")); err != nil {
+		t.Errorf("expected an expired freeze to allow withdrawals, got %v", err)
+	}
+	if _, err := k.LiftAccountRestriction(ctx, "alice", "ops"); !errors.Is(err, types.ErrAccountRestrictionNotFound) {
+		t.Errorf("expected no restriction to lift, got %v", err)
+	}
+	k.AccountRestrictionEndBlocker(ctx)
+	if stored := k.getStoredAccountRestriction(ctx, "alice"); stored != nil {
+		t.Errorf("expected the expired freeze to be removed, got %+v", stored)
+	}
+
+	suspension.ExpiresAt = time.Time{}
+	if err := k.SetAccountRestriction(ctx, suspension); err != nil {
+		t.Fatalf("failed to freeze: %v", err)
+	}
+	if lifted, err := k.LiftAccountRestriction(ctx, "alice", "ops"); err != nil || lifted.Level != types.RestrictionFrozen {
+		t.Fatalf("expected the freeze to be lifted, got %+v %v", lifted, err)
+	}
+	if len(k.GetAllAccountRestrictions(ctx)) != 0 {
+		t.Errorf("expected no restrictions left")
+	}
+}
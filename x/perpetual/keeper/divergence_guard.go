@@ -0,0 +1,116 @@
+package keeper
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// Store key prefix for market divergence guards
+var DivergenceGuardKeyPrefix = []byte{0x17}
+
+func divergenceGuardKey(marketID string) []byte {
+	return append(DivergenceGuardKeyPrefix, []byte(marketID)...)
+}
+
+// SetDivergenceGuard stores a market's divergence guard; a guard neither
+// engaged nor counting divergence removes the entry
+func (k *Keeper) SetDivergenceGuard(ctx sdk.Context, guard *types.DivergenceGuard) {
+	store := k.GetStore(ctx)
+	if !guard.Engaged && guard.Diverged == 0 {
+		store.Delete(divergenceGuardKey(guard.MarketID))
+		return
+	}
+	bz, _ := json.Marshal(guard)
+	store.Set(divergenceGuardKey(guard.MarketID), bz)
+}
+
+// GetDivergenceGuard returns a market's divergence guard, or nil if its mark
+// has not diverged from its index
+func (k *Keeper) GetDivergenceGuard(ctx sdk.Context, marketID string) *types.DivergenceGuard {
+	bz := k.GetStore(ctx).Get(divergenceGuardKey(marketID))
+	if bz == nil {
+		return nil
+	}
+	var guard types.DivergenceGuard
+	if err := json.Unmarshal(bz, &guard); err != nil {
+		return nil
+	}
+	return &guard
+}
+
+// IsDivergenceGuardEngaged reports whether a market's divergence guard is engaged
+func (k *Keeper) IsDivergenceGuardEngaged(ctx sdk.Context, marketID string) bool {
+	guard := k.GetDivergenceGuard(ctx, marketID)
+	return guard != nil && guard.Engaged
+}
+
+// GetOrderLimits returns the limits a market applies to new orders at the
+// block height: those of its warm-up stage, with price bands tightened while
+// its divergence guard is engaged
+func (k *Keeper) GetOrderLimits(ctx sdk.Context, market *types.Market) types.OrderLimits {
+	limits := market.OrderLimitsAt(ctx.BlockHeight())
+	if guard := k.GetDivergenceGuard(ctx, market.MarketID); guard != nil {
+		guard.Tighten(&limits, k.GetFundingConfig(ctx, market.MarketID))
+	}
+	return limits
+}
+
+// observeDivergence records a funding settlement's mark/index divergence in
+// the market's guard, engaging it once the mark has diverged beyond the
+// threshold for the configured number of settlements in a row and releasing
+// it at the first settlement back within the threshold, or once the guard
+// is disabled
+func (k *Keeper) observeDivergence(ctx sdk.Context, marketID string, priceInfo *types.PriceInfo) {
+	config := k.GetFundingConfig(ctx, marketID)
+	guard := k.GetDivergenceGuard(ctx, marketID)
+	if guard == nil {
+		if !config.GuardEnabled() {
+			return
+		}
+		guard = &types.DivergenceGuard{MarketID: marketID}
+	}
+
+	guard.Divergence = types.Divergence(priceInfo.MarkPrice, priceInfo.IndexPrice)
+	guard.UpdatedAt = ctx.BlockTime()
+	if config.GuardEnabled() && guard.Divergence.GT(config.DivergenceThreshold) {
+		guard.Diverged++
+	} else {
+		guard.Diverged = 0
+	}
+
+	switch {
+	case !guard.Engaged && guard.Diverged >= config.DivergenceIntervals:
+		guard.Engaged = true
+		guard.EngagedAt = ctx.BlockTime()
+		k.emitDivergenceGuardEvent(ctx, "divergence_guard_engaged", guard, config.DivergenceThreshold)
+		k.Logger().Warn("divergence guard engaged",
+			"market_id", marketID,
+			"divergence", guard.Divergence.String(),
+			"intervals", guard.Diverged,
+		)
+	case guard.Engaged && guard.Diverged == 0:
+		guard.Engaged = false
+		k.emitDivergenceGuardEvent(ctx, "divergence_guard_released", guard, config.DivergenceThreshold)
+		k.Logger().Info("divergence guard released",
+			"market_id", marketID,
+			"divergence", guard.Divergence.String(),
+		)
+	}
+	k.SetDivergenceGuard(ctx, guard)
+}
+
+func (k *Keeper) emitDivergenceGuardEvent(ctx sdk.Context, eventType string, guard *types.DivergenceGuard, threshold math.LegacyDec) {
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			eventType,
+			sdk.NewAttribute("market_id", guard.MarketID),
+			sdk.NewAttribute("divergence", guard.Divergence.String()),
+			sdk.NewAttribute("threshold", threshold.String()),
+			sdk.NewAttribute("intervals", strconv.FormatInt(guard.Diverged, 10)),
+		),
+	)
+}
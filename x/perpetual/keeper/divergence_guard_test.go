@@ -0,0 +1,100 @@
+package keeper
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TestDivergenceGuard tests that the guard engages after the configured
+// number of diverged settlements, tightens the price band and dampens the
+// premium while engaged, and releases once the mark is back
+func TestDivergenceGuard(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+
+	config := types.DefaultFundingConfig()
+	config.MaxRate = math.LegacyNewDecWithPrec(1, 2)
+	config.MinRate = math.LegacyNewDecWithPrec(-1, 2)
+	config.DivergenceIntervals = 2
+	if err := k.UpdateFundingConfig(ctx, "BTC-USDC", config); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+	market := k.GetMarket(ctx, "BTC-USDC")
+	band := market.OrderLimitsAt(ctx.BlockHeight())
+
+	// Mark 3% over index, beyond the 2% threshold
+	price := k.GetPrice(ctx, "BTC-USDC")
+	price.MarkPrice = price.IndexPrice.Mul(math.LegacyNewDecWithPrec(103, 2))
+	k.SetPrice(ctx, price)
+	undamped := k.CalculateFundingRate(ctx, "BTC-USDC")
+
+	events := func(ctx sdk.Context) map[string]bool {
+		seen := make(map[string]bool)
+		for _, event := range ctx.EventManager().Events() {
+			seen[event.Type] = true
+		}
+		return seen
+	}
+	settle := func() sdk.Context {
+		ctx := ctx.WithEventManager(sdk.NewEventManager())
+		if err := k.SettleFunding(ctx, "BTC-USDC"); err != nil {
+			t.Fatalf("failed to settle funding: %v", err)
+		}
+		return ctx
+	}
+
+	if events(settle())["divergence_guard_engaged"] || k.IsDivergenceGuardEngaged(ctx, "BTC-USDC") {
+		t.Fatal("guard engaged after a single diverged settlement")
+	}
+	if guard := k.GetDivergenceGuard(ctx, "BTC-USDC"); guard == nil || guard.Diverged != 1 {
+		t.Fatalf("expected one diverged settlement, got %+v", guard)
+	}
+	if !events(settle())["divergence_guard_engaged"] || !k.IsDivergenceGuardEngaged(ctx, "BTC-USDC") {
+		t.Fatal("guard not engaged after two diverged settlements")
+	}
+
+	want := undamped.Mul(math.LegacyNewDecWithPrec(5, 1))
+	if rate := k.CalculateFundingRate(ctx, "BTC-USDC"); !rate.Equal(want) {
+		t.Errorf("expected dampened rate %s, got %s", want, rate)
+	}
+	limits := k.GetOrderLimits(ctx, market)
+	if !limits.DivergenceGuard || !limits.MaxPriceDeviation.Equal(band.MaxPriceDeviation.QuoInt64(2)) || !limits.MaxSlippage.Equal(band.MaxSlippage.QuoInt64(2)) {
+		t.Errorf("expected a halved price band, got %+v", limits)
+	}
+	if !limits.MaxOrderSize.Equal(band.MaxOrderSize) {
+		t.Errorf("guard changed the order size limit to %s", limits.MaxOrderSize)
+	}
+
+	// A catch-up settlement neither counts nor releases
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	price.MarkPrice = price.IndexPrice
+	k.SetPrice(ctx, price)
+	if err := k.settleFundingInterval(ctx, "BTC-USDC", ctx.BlockTime(), true); err != nil {
+		t.Fatal(err)
+	}
+	if !k.IsDivergenceGuardEngaged(ctx, "BTC-USDC") {
+		t.Fatal("catch-up settlement released the guard")
+	}
+
+	if !events(settle())["divergence_guard_released"] || k.GetDivergenceGuard(ctx, "BTC-USDC") != nil {
+		t.Fatal("guard not released with the mark back at the index")
+	}
+	if limits := k.GetOrderLimits(ctx, market); limits.DivergenceGuard || !limits.MaxPriceDeviation.Equal(band.MaxPriceDeviation) {
+		t.Errorf("expected the full price band back, got %+v", limits)
+	}
+
+	// A zero threshold disables the guard
+	config.DivergenceThreshold = math.LegacyZeroDec()
+	if err := k.UpdateFundingConfig(ctx, "BTC-USDC", config); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+	price.MarkPrice = price.IndexPrice.MulInt64(2)
+	k.SetPrice(ctx, price)
+	settle()
+	settle()
+	if guard := k.GetDivergenceGuard(ctx, "BTC-USDC"); guard != nil {
+		t.Errorf("disabled guard tracked divergence: %+v", guard)
+	}
+}
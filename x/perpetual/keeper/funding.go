@@ -21,14 +21,10 @@ var (
 	FundingPaymentCounterKey = []byte{0x09}
 )
 
-const fundingIntervalHours = 8
-
-func nextFundingTimeUTC(now time.Time) time.Time {
-	utc := now.UTC()
-	dayStart := time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC)
-	period := time.Duration(fundingIntervalHours) * time.Hour
-	elapsed := utc.Sub(dayStart)
-	return dayStart.Add((elapsed/period + 1) * period)
+// nextFundingTime returns the market's next settlement boundary after the
+// current block time, using its configured funding interval
+func (k *Keeper) nextFundingTime(ctx sdk.Context, marketID string) time.Time {
+	return k.GetFundingConfig(ctx, marketID).NextSettlement(ctx.BlockTime())
 }
 
 // ============ Funding Rate Storage ============
@@ -93,6 +89,26 @@ func (k *Keeper) GetFundingPaymentsByTrader(ctx sdk.Context, trader string, limi
 	return payments
 }
 
+// GetFundingPaymentsBetween returns a trader's funding payments in a market
+// settled within [from, to]
+func (k *Keeper) GetFundingPaymentsBetween(ctx sdk.Context, trader, marketID string, from, to time.Time) []*types.FundingPayment {
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), FundingPaymentKeyPrefix)
+	defer iterator.Close()
+
+	var payments []*types.FundingPayment
+	for ; iterator.Valid(); iterator.Next() {
+		var payment types.FundingPayment
+		if err := json.Unmarshal(iterator.Value(), &payment); err != nil {
+			continue
+		}
+		if payment.Trader == trader && payment.MarketID == marketID &&
+			!payment.Timestamp.Before(from) && !payment.Timestamp.After(to) {
+			payments = append(payments, &payment)
+		}
+	}
+	return payments
+}
+
 // generatePaymentID generates a unique payment ID
 func (k *Keeper) generatePaymentID(ctx sdk.Context) string {
 	store := k.GetStore(ctx)
@@ -143,26 +159,78 @@ func (k *Keeper) SetFundingConfig(ctx sdk.Context, marketID string, config types
 	store.Set(key, bz)
 }
 
-// GetFundingConfig gets the funding configuration for a market
+// GetFundingConfig gets the funding configuration for a market. Markets
+// without a stored config use the defaults with the market's own interval.
 func (k *Keeper) GetFundingConfig(ctx sdk.Context, marketID string) types.FundingConfig {
+	config, found := k.getStoredFundingConfig(ctx, marketID)
+	if !found {
+		config = types.DefaultFundingConfig()
+		if market := k.GetMarket(ctx, marketID); market != nil && market.FundingInterval > 0 {
+			config.Interval = market.FundingInterval
+		}
+	}
+	return config.WithDefaults()
+}
+
+// getStoredFundingConfig returns the config set through governance or genesis, if any
+func (k *Keeper) getStoredFundingConfig(ctx sdk.Context, marketID string) (types.FundingConfig, bool) {
 	store := k.GetStore(ctx)
 	key := append(FundingConfigKeyPrefix, []byte(marketID)...)
 	bz := store.Get(key)
 	if bz == nil {
-		return types.DefaultFundingConfig()
+		return types.FundingConfig{}, false
 	}
 	var config types.FundingConfig
 	if err := json.Unmarshal(bz, &config); err != nil {
-		return types.DefaultFundingConfig()
+		return types.FundingConfig{}, false
+	}
+	return config, true
+}
+
+// UpdateFundingConfig validates and stores a market's funding parameters.
+// The market's funding interval follows the config, and a shorter interval
+// brings the next settlement forward; a longer one leaves it in place.
+func (k *Keeper) UpdateFundingConfig(ctx sdk.Context, marketID string, config types.FundingConfig) error {
+	market := k.GetMarket(ctx, marketID)
+	if market == nil {
+		return types.ErrMarketNotFound
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	k.SetFundingConfig(ctx, marketID, config)
+	if market.FundingInterval != config.Interval {
+		market.FundingInterval = config.Interval
+		market.UpdatedAt = ctx.BlockTime()
+		k.SetMarket(ctx, market)
+	}
+
+	next := config.NextSettlement(ctx.BlockTime())
+	if current := k.GetNextFundingTime(ctx, marketID); current.IsZero() || next.Before(current) {
+		k.SetNextFundingTime(ctx, marketID, next)
 	}
-	return config
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"funding_config_updated",
+			sdk.NewAttribute("market_id", marketID),
+			sdk.NewAttribute("interval", fmt.Sprintf("%d", config.Interval)),
+			sdk.NewAttribute("max_rate", config.MaxRate.String()),
+			sdk.NewAttribute("min_rate", config.MinRate.String()),
+			sdk.NewAttribute("damping_factor", config.DampingFactor.String()),
+			sdk.NewAttribute("interest_rate", config.InterestRate.String()),
+		),
+	)
+	return nil
 }
 
 // ============ Funding Rate Calculation ============
 
 // CalculateFundingRate calculates the current funding rate for a market
-// Formula: R = dampingFactor × (markPrice - indexPrice) / indexPrice
-// Clamped to [minRate, maxRate]
+// Formula: R = dampingFactor × (markPrice - indexPrice) / indexPrice + interestRate
+// Clamped to [minRate, maxRate]. While the market's divergence guard is
+// engaged the premium term is further scaled by (1 - guardDampening).
 func (k *Keeper) CalculateFundingRate(ctx sdk.Context, marketID string) math.LegacyDec {
 	priceInfo := k.GetPrice(ctx, marketID)
 	if priceInfo == nil || priceInfo.IndexPrice.IsZero() {
@@ -171,18 +239,15 @@ func (k *Keeper) CalculateFundingRate(ctx sdk.Context, marketID string) math.Leg
 
 	config := k.GetFundingConfig(ctx, marketID)
 
-	// R = dampingFactor × (mark - index) / index
+	// R = dampingFactor × (mark - index) / index + interestRate
 	priceDiff := priceInfo.MarkPrice.Sub(priceInfo.IndexPrice)
-	rate := config.DampingFactor.Mul(priceDiff).Quo(priceInfo.IndexPrice)
-
-	// Clamp to [minRate, maxRate]
-	if rate.GT(config.MaxRate) {
-		rate = config.MaxRate
-	} else if rate.LT(config.MinRate) {
-		rate = config.MinRate
+	premium := config.DampingFactor.Mul(priceDiff).Quo(priceInfo.IndexPrice)
+	if k.IsDivergenceGuardEngaged(ctx, marketID) {
+		premium = premium.Mul(math.LegacyOneDec().Sub(config.GuardDampening))
 	}
+	rate := premium.Add(config.InterestRate)
 
-	return rate
+	return config.Clamp(rate)
 }
 
 // OI imbalance multiplier for funding rate adjustment
@@ -219,20 +284,26 @@ func (k *Keeper) CalculateFundingRateV2(ctx sdk.Context, marketID string) math.L
 	adjustedRate := baseRate.Add(adjustment)
 
 	// Clamp to [minRate, maxRate]
-	config := k.GetFundingConfig(ctx, marketID)
-	if adjustedRate.GT(config.MaxRate) {
-		adjustedRate = config.MaxRate
-	} else if adjustedRate.LT(config.MinRate) {
-		adjustedRate = config.MinRate
-	}
-
-	return adjustedRate
+	return k.GetFundingConfig(ctx, marketID).Clamp(adjustedRate)
 }
 
 // ============ Funding Settlement ============
 
-// SettleFunding settles funding for a market
+// SettleFunding settles funding for a market at the current block time and
+// schedules the next settlement
 func (k *Keeper) SettleFunding(ctx sdk.Context, marketID string) error {
+	if err := k.settleFundingInterval(ctx, marketID, ctx.BlockTime(), false); err != nil {
+		return err
+	}
+	k.SetNextFundingTime(ctx, marketID, k.nextFundingTime(ctx, marketID))
+	return nil
+}
+
+// settleFundingInterval settles one funding interval of a market, recording
+// its rate and payments at fundingTime. A catch-up interval is one missed
+// while the chain was halted; it is charged at the current rate, as no rate
+// was observed for it, and does not count towards the divergence guard.
+func (k *Keeper) settleFundingInterval(ctx sdk.Context, marketID string, fundingTime time.Time, catchUp bool) error {
 	logger := k.Logger()
 
 	market := k.GetMarket(ctx, marketID)
@@ -245,11 +316,16 @@ func (k *Keeper) SettleFunding(ctx sdk.Context, marketID string) error {
 		return types.ErrMarketNotFound
 	}
 
+	if !catchUp {
+		k.observeDivergence(ctx, marketID, priceInfo)
+	}
+
 	// Calculate funding rate with OI imbalance adjustment
 	rate := k.CalculateFundingRateV2(ctx, marketID)
 
 	// Save funding rate record
 	fundingRate := types.NewFundingRate(marketID, rate, priceInfo.MarkPrice, priceInfo.IndexPrice)
+	fundingRate.Timestamp = fundingTime
 	k.SetFundingRate(ctx, fundingRate)
 
 	// Get all positions for this market
@@ -313,6 +389,10 @@ func (k *Keeper) SettleFunding(ctx sdk.Context, marketID string) error {
 		account.UpdatedAt = ctx.BlockTime()
 		k.SetAccount(ctx, account)
 
+		// Attribute funding to the position for its history record
+		pos.AddFunding(payment.Neg())
+		k.SetPosition(ctx, pos)
+
 		// Record payment
 		k.SaveFundingPayment(ctx, &types.FundingPayment{
 			PaymentID: k.generatePaymentID(ctx),
@@ -320,16 +400,12 @@ func (k *Keeper) SettleFunding(ctx sdk.Context, marketID string) error {
 			MarketID:  marketID,
 			Amount:    payment,
 			Rate:      rate,
-			Timestamp: ctx.BlockTime(),
+			Timestamp: fundingTime,
 		})
 
 		affectedPositions++
 	}
 
-	// Update next funding time
-	nextTime := nextFundingTimeUTC(ctx.BlockTime())
-	k.SetNextFundingTime(ctx, marketID, nextTime)
-
 	// Emit event
 	ctx.EventManager().EmitEvent(
 		sdk.NewEvent(
@@ -339,6 +415,8 @@ func (k *Keeper) SettleFunding(ctx sdk.Context, marketID string) error {
 			sdk.NewAttribute("mark_price", priceInfo.MarkPrice.String()),
 			sdk.NewAttribute("index_price", priceInfo.IndexPrice.String()),
 			sdk.NewAttribute("positions_affected", fmt.Sprintf("%d", affectedPositions)),
+			sdk.NewAttribute("funding_time", fmt.Sprintf("%d", fundingTime.Unix())),
+			sdk.NewAttribute("catch_up", fmt.Sprintf("%t", catchUp)),
 		),
 	)
 
@@ -348,13 +426,17 @@ func (k *Keeper) SettleFunding(ctx sdk.Context, marketID string) error {
 		"positions_affected", affectedPositions,
 		"total_long_payment", totalLongPayment.String(),
 		"total_short_payment", totalShortPayment.String(),
-		"next_funding", nextTime.String(),
+		"funding_time", fundingTime.String(),
+		"catch_up", catchUp,
 	)
 
 	return nil
 }
 
-// FundingEndBlocker checks and settles funding for all markets
+// FundingEndBlocker checks and settles funding for all markets. A market
+// whose settlement times passed while the chain was halted has each missed
+// interval settled in order, up to its config's MaxCatchUpIntervals of the
+// latest ones, with a funding_intervals_skipped event for any older ones.
 func (k *Keeper) FundingEndBlocker(ctx sdk.Context) {
 	markets := k.ListActiveMarkets(ctx)
 	currentTime := ctx.BlockTime()
@@ -362,28 +444,55 @@ func (k *Keeper) FundingEndBlocker(ctx sdk.Context) {
 	for _, market := range markets {
 		nextFundingTime := k.GetNextFundingTime(ctx, market.MarketID)
 		if nextFundingTime.IsZero() {
-			nextFundingTime = nextFundingTimeUTC(currentTime)
+			nextFundingTime = k.nextFundingTime(ctx, market.MarketID)
 			k.SetNextFundingTime(ctx, market.MarketID, nextFundingTime)
 		}
 
 		// Check if funding is due
-		if currentTime.After(nextFundingTime) || currentTime.Equal(nextFundingTime) {
-			// Set market status to settling
-			market.Status = types.MarketStatusSettling
-			k.SetMarket(ctx, market)
+		due, skipped := k.GetFundingConfig(ctx, market.MarketID).DueSettlements(nextFundingTime, currentTime)
+		if len(due) == 0 {
+			continue
+		}
+		if skipped > 0 {
+			k.Logger().Warn("funding intervals missed beyond the catch-up limit are skipped",
+				"market_id", market.MarketID,
+				"skipped", skipped,
+				"from", nextFundingTime.String(),
+			)
+			ctx.EventManager().EmitEvent(
+				sdk.NewEvent(
+					"funding_intervals_skipped",
+					sdk.NewAttribute("market_id", market.MarketID),
+					sdk.NewAttribute("skipped", fmt.Sprintf("%d", skipped)),
+					sdk.NewAttribute("from", fmt.Sprintf("%d", nextFundingTime.Unix())),
+					sdk.NewAttribute("to", fmt.Sprintf("%d", due[0].Unix())),
+				),
+			)
+		}
 
-			// Settle funding
-			if err := k.SettleFunding(ctx, market.MarketID); err != nil {
+		// Set market status to settling
+		market.Status = types.MarketStatusSettling
+		k.SetMarket(ctx, market)
+
+		// Settle each due interval, the latest on time and any before it as
+		// catch-up; an interval that fails is retried from the next block
+		next := k.nextFundingTime(ctx, market.MarketID)
+		for i, fundingTime := range due {
+			if err := k.settleFundingInterval(ctx, market.MarketID, fundingTime, i < len(due)-1); err != nil {
 				k.Logger().Error("failed to settle funding",
 					"market_id", market.MarketID,
+					"funding_time", fundingTime.String(),
 					"error", err,
 				)
+				next = fundingTime
+				break
 			}
-
-			// Restore market status to active
-			market.Status = types.MarketStatusActive
-			k.SetMarket(ctx, market)
 		}
+		k.SetNextFundingTime(ctx, market.MarketID, next)
+
+		// Restore market status to active
+		market.Status = types.MarketStatusActive
+		k.SetMarket(ctx, market)
 	}
 }
 
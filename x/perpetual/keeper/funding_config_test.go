@@ -0,0 +1,229 @@
+package keeper
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	"cosmossdk.io/store"
+	"cosmossdk.io/store/metrics"
+	storetypes "cosmossdk.io/store/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+const testAuthority = "cosmos10d07y265gmmuvt4z0w9aw880jnsr700j6zn9kn"
+
+// setupFundingKeeper returns a store-backed keeper with the default market
+func setupFundingKeeper(t testing.TB) (*Keeper, sdk.Context) {
+	t.Helper()
+
+	storeKey := storetypes.NewKVStoreKey("perpetual")
+	db := dbm.NewMemDB()
+	stateStore := store.NewCommitMultiStore(db, log.NewNopLogger(), metrics.NewNoOpMetrics())
+	stateStore.MountStoreWithDB(storeKey, storetypes.StoreTypeIAVL, db)
+	if err := stateStore.LoadLatestVersion(); err != nil {
+		t.Fatalf("failed to load store: %v", err)
+	}
+
+	header := cmtproto.Header{Time: time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)}
+	ctx := sdk.NewContext(stateStore, header, false, log.NewNopLogger())
+	cdc := codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+
+	k := NewKeeper(cdc, storeKey, nil, testAuthority, log.NewNopLogger())
+	k.InitDefaultMarket(ctx)
+	return k, ctx
+}
+
+// TestFundingConfigRate tests the interest component and cap/floor clamping
+func TestFundingConfigRate(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+
+	config := types.DefaultFundingConfig()
+	config.InterestRate = math.LegacyNewDecWithPrec(1, 4) // 0.01%
+	if err := k.UpdateFundingConfig(ctx, "BTC-USDC", config); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+
+	// No premium: the rate is the interest component alone
+	if rate := k.CalculateFundingRate(ctx, "BTC-USDC"); !rate.Equal(config.InterestRate) {
+		t.Errorf("expected interest rate %s, got %s", config.InterestRate, rate)
+	}
+
+	// Mark 1% over index: 0.05 × 0.01 + 0.0001 = 0.0006
+	price := k.GetPrice(ctx, "BTC-USDC")
+	price.MarkPrice = math.LegacyNewDec(50500)
+	k.SetPrice(ctx, price)
+	if rate := k.CalculateFundingRate(ctx, "BTC-USDC"); !rate.Equal(math.LegacyNewDecWithPrec(6, 4)) {
+		t.Errorf("expected rate 0.0006, got %s", rate)
+	}
+
+	// A full dampener passes the premium through, up to the cap
+	config.DampingFactor = math.LegacyOneDec()
+	config.MaxRate = math.LegacyNewDecWithPrec(2, 3)
+	if err := k.UpdateFundingConfig(ctx, "BTC-USDC", config); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+	if rate := k.CalculateFundingRate(ctx, "BTC-USDC"); !rate.Equal(config.MaxRate) {
+		t.Errorf("expected cap %s, got %s", config.MaxRate, rate)
+	}
+
+	price.MarkPrice = math.LegacyNewDec(49000)
+	k.SetPrice(ctx, price)
+	if rate := k.CalculateFundingRate(ctx, "BTC-USDC"); !rate.Equal(config.MinRate) {
+		t.Errorf("expected floor %s, got %s", config.MinRate, rate)
+	}
+}
+
+// TestFundingConfigInterval tests that the configured interval drives the
+// settlement schedule instead of a fixed 8 hours
+func TestFundingConfigInterval(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+
+	if next := k.GetNextFundingTime(ctx, "BTC-USDC"); !next.Equal(time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected default 8h boundary, got %v", next)
+	}
+
+	config := types.DefaultFundingConfig()
+	config.Interval = 3600
+	if err := k.UpdateFundingConfig(ctx, "BTC-USDC", config); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+	if next := k.GetNextFundingTime(ctx, "BTC-USDC"); !next.Equal(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected hourly boundary, got %v", next)
+	}
+	if market := k.GetMarket(ctx, "BTC-USDC"); market.FundingInterval != 3600 {
+		t.Errorf("expected market interval 3600, got %d", market.FundingInterval)
+	}
+
+	ctx = ctx.WithBlockTime(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+	k.FundingEndBlocker(ctx)
+	if next := k.GetNextFundingTime(ctx, "BTC-USDC"); !next.Equal(time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected settlement to schedule 11:00, got %v", next)
+	}
+
+	gs := k.ExportGenesis(ctx)
+	if len(gs.FundingConfigs) != 1 || gs.FundingConfigs[0].Config.Interval != 3600 {
+		t.Errorf("expected exported funding config, got %+v", gs.FundingConfigs)
+	}
+}
+
+// TestUpdateFundingConfigMsg tests governance authority and validation
+func TestUpdateFundingConfigMsg(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	server := NewMsgServerImpl(k)
+
+	msg := &types.MsgUpdateFundingConfig{Authority: "cosmos1notgov", MarketID: "BTC-USDC", Config: types.DefaultFundingConfig()}
+	if _, err := server.UpdateFundingConfig(ctx, msg); !errors.Is(err, types.ErrUnauthorized) {
+		t.Errorf("expected unauthorized, got %v", err)
+	}
+
+	invalid := []func(*types.FundingConfig){
+		func(c *types.FundingConfig) { c.Interval = 30 },
+		func(c *types.FundingConfig) { c.MaxRate = math.LegacyNewDecWithPrec(-1, 3) },
+		func(c *types.FundingConfig) { c.MinRate = math.LegacyNewDecWithPrec(1, 3) },
+		func(c *types.FundingConfig) { c.DampingFactor = math.LegacyNewDec(2) },
+		func(c *types.FundingConfig) { c.InterestRate = math.LegacyNewDecWithPrec(1, 2) },
+		func(c *types.FundingConfig) { c.MaxCatchUpIntervals = -1 },
+	}
+	for i, mutate := range invalid {
+		msg = &types.MsgUpdateFundingConfig{Authority: testAuthority, MarketID: "BTC-USDC", Config: types.DefaultFundingConfig()}
+		mutate(&msg.Config)
+		if _, err := server.UpdateFundingConfig(ctx, msg); !errors.Is(err, types.ErrInvalidFundingConfig) {
+			t.Errorf("case %d: expected invalid funding config, got %v", i, err)
+		}
+	}
+
+	msg = &types.MsgUpdateFundingConfig{Authority: testAuthority, MarketID: "ETH-USDC", Config: types.DefaultFundingConfig()}
+	if _, err := server.UpdateFundingConfig(ctx, msg); !errors.Is(err, types.ErrMarketNotFound) {
+		t.Errorf("expected market not found, got %v", err)
+	}
+
+	msg.MarketID = "BTC-USDC"
+	msg.Config.Interval = 14400
+	resp, err := server.UpdateFundingConfig(ctx, msg)
+	if err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+	if want := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC).Unix(); resp.NextFundingTime != want {
+		t.Errorf("expected next funding %d, got %d", want, resp.NextFundingTime)
+	}
+}
+
+// TestFundingCatchUp tests that intervals missed during a halt are settled on
+// resume, the older ones beyond the catch-up limit skipped, with an event per
+// applied interval
+func TestFundingCatchUp(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+
+	config := types.DefaultFundingConfig()
+	config.InterestRate = math.LegacyNewDecWithPrec(1, 4) // 0.01%
+	config.MaxCatchUpIntervals = 2
+	if err := k.UpdateFundingConfig(ctx, "BTC-USDC", config); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+
+	account := types.NewAccount("trader1")
+	account.Balance = math.LegacyNewDec(1000)
+	k.SetAccount(ctx, account)
+	price := k.GetPrice(ctx, "BTC-USDC")
+	k.SetPosition(ctx, types.NewPosition("trader1", "BTC-USDC", types.PositionSideLong, math.LegacyOneDec(), price.MarkPrice, math.LegacyNewDec(500)))
+	// A matching short keeps open interest balanced, so the rate is the
+	// interest component alone
+	k.SetPosition(ctx, types.NewPosition("trader2", "BTC-USDC", types.PositionSideShort, math.LegacyOneDec(), price.MarkPrice, math.LegacyNewDec(500)))
+
+	// Halted from before 16:00 until after 08:00 the next day: 16:00, 00:00
+	// and 08:00 were missed, and only the latest two are settled
+	ctx = ctx.WithBlockTime(time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)).WithEventManager(sdk.NewEventManager())
+	k.FundingEndBlocker(ctx)
+
+	var settled []string
+	var skipped string
+	for _, event := range ctx.EventManager().Events() {
+		attrs := make(map[string]string)
+		for _, attr := range event.Attributes {
+			attrs[attr.Key] = attr.Value
+		}
+		switch event.Type {
+		case "funding_settled":
+			settled = append(settled, attrs["funding_time"]+"/"+attrs["catch_up"])
+		case "funding_intervals_skipped":
+			skipped = attrs["skipped"]
+		}
+	}
+	midnight := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	eight := time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+	want := []string{
+		fmt.Sprintf("%d/true", midnight.Unix()),
+		fmt.Sprintf("%d/false", eight.Unix()),
+	}
+	if fmt.Sprint(settled) != fmt.Sprint(want) {
+		t.Errorf("expected settlements %v, got %v", want, settled)
+	}
+	if skipped != "1" {
+		t.Errorf("expected one skipped interval, got %q", skipped)
+	}
+
+	payments := k.GetFundingPaymentsByTrader(ctx, "trader1", 10)
+	if len(payments) != 2 {
+		t.Fatalf("expected 2 funding payments, got %d", len(payments))
+	}
+	perInterval := price.MarkPrice.Mul(config.InterestRate)
+	wantBalance := math.LegacyNewDec(1000).Sub(perInterval.MulInt64(2))
+	if balance := k.GetAccount(ctx, "trader1").Balance; !balance.Equal(wantBalance) {
+		t.Errorf("expected balance %s after two intervals, got %s", wantBalance, balance)
+	}
+	if !payments[0].Timestamp.Equal(eight) || !payments[1].Timestamp.Equal(midnight) {
+		t.Errorf("expected payments stamped with their intervals, got %v and %v", payments[0].Timestamp, payments[1].Timestamp)
+	}
+	if next := k.GetNextFundingTime(ctx, "BTC-USDC"); !next.Equal(time.Date(2024, 1, 2, 16, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the next settlement at 16:00, got %v", next)
+	}
+}
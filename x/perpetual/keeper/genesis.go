@@ -0,0 +1,121 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// InitGenesis imports perpetual state. A genesis without markets starts with
+// the default market, as a fresh chain does; markets without an exported
+// funding time settle at the next boundary of their funding interval.
+func (k *Keeper) InitGenesis(ctx sdk.Context, gs *types.GenesisState) error {
+	if err := gs.Validate(); err != nil {
+		return err
+	}
+	if len(gs.Markets) == 0 {
+		k.InitDefaultMarket(ctx)
+	}
+
+	for _, market := range gs.Markets {
+		k.SetMarket(ctx, market)
+	}
+	for _, fc := range gs.FundingConfigs {
+		k.SetFundingConfig(ctx, fc.MarketID, fc.Config)
+		if market := k.GetMarket(ctx, fc.MarketID); market.FundingInterval != fc.Config.Interval {
+			market.FundingInterval = fc.Config.Interval
+			k.SetMarket(ctx, market)
+		}
+	}
+	for _, price := range gs.Prices {
+		k.SetPrice(ctx, price)
+	}
+
+	funded := make(map[string]bool, len(gs.NextFundingTimes))
+	for _, next := range gs.NextFundingTimes {
+		k.SetNextFundingTime(ctx, next.MarketID, next.Time)
+		funded[next.MarketID] = true
+	}
+	for _, market := range gs.Markets {
+		if !funded[market.MarketID] {
+			k.SetNextFundingTime(ctx, market.MarketID, k.nextFundingTime(ctx, market.MarketID))
+		}
+	}
+
+	for _, schedule := range gs.TradingSchedules {
+		if err := k.SetTradingSchedule(ctx, schedule); err != nil {
+			return err
+		}
+	}
+	for _, guard := range gs.DivergenceGuards {
+		k.SetDivergenceGuard(ctx, guard)
+	}
+	for _, account := range gs.Accounts {
+		k.SetAccount(ctx, account)
+	}
+	for _, position := range gs.Positions {
+		k.SetPosition(ctx, position)
+	}
+
+	for _, security := range gs.WithdrawalSecurity {
+		k.setWithdrawalSecurity(ctx, security)
+	}
+	for _, address := range gs.WithdrawalAddresses {
+		k.setWithdrawalAddress(ctx, address)
+	}
+	if gs.RiskArray != nil {
+		if err := k.SetRiskArray(ctx, gs.RiskArray); err != nil {
+			return err
+		}
+	}
+	for _, restriction := range gs.AccountRestrictions {
+		k.setAccountRestriction(ctx, restriction)
+	}
+	if err := k.importClosedPositions(ctx, gs.ClosedPositions, gs.ClosedPositionCount); err != nil {
+		return err
+	}
+	return k.importPendingWithdrawals(ctx, gs.PendingWithdrawals)
+}
+
+// ExportGenesis exports markets, prices, funding times and configs, trading
+// schedules, divergence guards, accounts, open positions, withdrawal security settings, pending
+// withdrawals, the portfolio margin risk array, if set, the account
+// restrictions in force and the closed position history. Funding, kline,
+// oracle and transfer history is not exported.
+func (k *Keeper) ExportGenesis(ctx sdk.Context) *types.GenesisState {
+	gs := types.DefaultGenesis()
+
+	for _, market := range k.GetAllMarkets(ctx) {
+		gs.Markets = append(gs.Markets, market)
+		if price := k.GetPrice(ctx, market.MarketID); price != nil {
+			gs.Prices = append(gs.Prices, price)
+		}
+		if next := k.GetNextFundingTime(ctx, market.MarketID); !next.IsZero() {
+			gs.NextFundingTimes = append(gs.NextFundingTimes, types.NextFundingTime{
+				MarketID: market.MarketID,
+				Time:     next,
+			})
+		}
+		if config, found := k.getStoredFundingConfig(ctx, market.MarketID); found {
+			gs.FundingConfigs = append(gs.FundingConfigs, types.MarketFundingConfig{
+				MarketID: market.MarketID,
+				Config:   config.WithDefaults(),
+			})
+		}
+		if schedule := k.GetTradingSchedule(ctx, market.MarketID); schedule != nil {
+			gs.TradingSchedules = append(gs.TradingSchedules, schedule)
+		}
+		if guard := k.GetDivergenceGuard(ctx, market.MarketID); guard != nil {
+			gs.DivergenceGuards = append(gs.DivergenceGuards, guard)
+		}
+	}
+
+	gs.Accounts = append(gs.Accounts, k.GetAllAccounts(ctx)...)
+	gs.Positions = append(gs.Positions, k.GetAllPositions(ctx)...)
+	gs.WithdrawalSecurity, gs.WithdrawalAddresses, gs.PendingWithdrawals = k.exportWithdrawalSecurity(ctx)
+	if risk, found := k.getStoredRiskArray(ctx); found {
+		gs.RiskArray = risk
+	}
+	gs.AccountRestrictions = append(gs.AccountRestrictions, k.GetAllAccountRestrictions(ctx)...)
+	gs.ClosedPositions, gs.ClosedPositionCount = k.exportClosedPositions(ctx)
+	return gs
+}
@@ -0,0 +1,84 @@
+package keeper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// invariantModule is the module name invariants are registered under
+const invariantModule = "perpetual"
+
+// RegisterInvariants registers the perpetual module invariants
+func RegisterInvariants(ir sdk.InvariantRegistry, k *Keeper) {
+	ir.RegisterRoute(invariantModule, "locked-margin", LockedMarginInvariant(k))
+	ir.RegisterRoute(invariantModule, "nonnegative-balance", NonNegativeBalanceInvariant(k))
+}
+
+// AllInvariants runs all invariants of the perpetual module
+func AllInvariants(k *Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		res, stop := LockedMarginInvariant(k)(ctx)
+		if stop {
+			return res, stop
+		}
+		return NonNegativeBalanceInvariant(k)(ctx)
+	}
+}
+
+// LockedMarginInvariant checks that every account's locked margin equals the
+// sum of the margin held by its open positions
+func LockedMarginInvariant(k *Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		positionMargin := make(map[string]math.LegacyDec)
+		for _, pos := range k.GetAllPositions(ctx) {
+			sum, ok := positionMargin[pos.Trader]
+			if !ok {
+				sum = math.LegacyZeroDec()
+			}
+			positionMargin[pos.Trader] = sum.Add(pos.Margin)
+		}
+
+		var broken []string
+		for _, account := range k.GetAllAccounts(ctx) {
+			expected, ok := positionMargin[account.Trader]
+			if !ok {
+				expected = math.LegacyZeroDec()
+			}
+			delete(positionMargin, account.Trader)
+			if !account.LockedMargin.Equal(expected) {
+				broken = append(broken, fmt.Sprintf("\t%s: locked %s, positions %s\n",
+					account.Trader, account.LockedMargin, expected))
+			}
+		}
+		// Positions without an account hold margin nobody has locked
+		for trader, margin := range positionMargin {
+			broken = append(broken, fmt.Sprintf("\t%s: no account, positions %s\n", trader, margin))
+		}
+		sort.Strings(broken)
+
+		return sdk.FormatInvariant(invariantModule, "locked-margin",
+			fmt.Sprintf("%d accounts with locked margin not matching position margin\n%s",
+				len(broken), strings.Join(broken, ""))), len(broken) > 0
+	}
+}
+
+// NonNegativeBalanceInvariant checks that no account has a negative balance or locked margin
+func NonNegativeBalanceInvariant(k *Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		var broken []string
+		for _, account := range k.GetAllAccounts(ctx) {
+			if account.Balance.IsNegative() || account.LockedMargin.IsNegative() {
+				broken = append(broken, fmt.Sprintf("\t%s: balance %s, locked %s\n",
+					account.Trader, account.Balance, account.LockedMargin))
+			}
+		}
+
+		return sdk.FormatInvariant(invariantModule, "nonnegative-balance",
+			fmt.Sprintf("%d accounts with negative balance\n%s",
+				len(broken), strings.Join(broken, ""))), len(broken) > 0
+	}
+}
@@ -118,7 +118,7 @@ func (k *Keeper) InitDefaultMarket(ctx sdk.Context) {
 	price := types.NewPriceInfo("BTC-USDC", math.LegacyNewDec(50000))
 	k.SetPrice(ctx, price)
 
-	k.SetNextFundingTime(ctx, market.MarketID, nextFundingTimeUTC(ctx.BlockTime()))
+	k.SetNextFundingTime(ctx, market.MarketID, k.nextFundingTime(ctx, market.MarketID))
 }
 
 // ============ Position Operations ============
@@ -128,10 +128,12 @@ func positionKey(trader, marketID string) []byte {
 	return append(PositionKeyPrefix, []byte(trader+":"+marketID)...)
 }
 
-// SetPosition saves a position to the store
+// SetPosition saves a position to the store and moves it in its market's
+// long/short stats
 func (k *Keeper) SetPosition(ctx sdk.Context, position *types.Position) {
 	store := k.GetStore(ctx)
 	key := positionKey(position.Trader, position.MarketID)
+	k.updateLongShortStats(ctx, k.GetPosition(ctx, position.Trader, position.MarketID), position)
 	bz, _ := json.Marshal(position)
 	store.Set(key, bz)
 }
@@ -151,10 +153,12 @@ func (k *Keeper) GetPosition(ctx sdk.Context, trader, marketID string) *types.Po
 	return &position
 }
 
-// DeletePosition removes a position from the store
+// DeletePosition removes a position from the store and its market's
+// long/short stats
 func (k *Keeper) DeletePosition(ctx sdk.Context, trader, marketID string) {
 	store := k.GetStore(ctx)
 	key := positionKey(trader, marketID)
+	k.updateLongShortStats(ctx, k.GetPosition(ctx, trader, marketID), nil)
 	store.Delete(key)
 }
 
@@ -177,6 +181,31 @@ func (k *Keeper) GetPositionsByTrader(ctx sdk.Context, trader string) []*types.P
 	return positions
 }
 
+// GetPositionsByTraders returns the positions of several traders, keyed by
+// trader, in a single pass over the position store
+func (k *Keeper) GetPositionsByTraders(ctx sdk.Context, traders []string) map[string][]*types.Position {
+	wanted := make(map[string]bool, len(traders))
+	for _, trader := range traders {
+		wanted[trader] = true
+	}
+
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, PositionKeyPrefix)
+	defer iterator.Close()
+
+	positions := make(map[string][]*types.Position, len(traders))
+	for ; iterator.Valid(); iterator.Next() {
+		var position types.Position
+		if err := json.Unmarshal(iterator.Value(), &position); err != nil {
+			continue
+		}
+		if wanted[position.Trader] {
+			positions[position.Trader] = append(positions[position.Trader], &position)
+		}
+	}
+	return positions
+}
+
 // GetAllPositions returns all positions
 func (k *Keeper) GetAllPositions(ctx sdk.Context) []*types.Position {
 	store := k.GetStore(ctx)
@@ -219,6 +248,35 @@ func (k *Keeper) GetAccount(ctx sdk.Context, trader string) *types.Account {
 	return &account
 }
 
+// GetAccounts retrieves several accounts, keyed by trader. Traders without
+// an account are absent from the result.
+func (k *Keeper) GetAccounts(ctx sdk.Context, traders []string) map[string]*types.Account {
+	accounts := make(map[string]*types.Account, len(traders))
+	for _, trader := range traders {
+		if account := k.GetAccount(ctx, trader); account != nil {
+			accounts[trader] = account
+		}
+	}
+	return accounts
+}
+
+// GetAllAccounts retrieves all accounts from the store
+func (k *Keeper) GetAllAccounts(ctx sdk.Context) []*types.Account {
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, AccountKeyPrefix)
+	defer iterator.Close()
+
+	var accounts []*types.Account
+	for ; iterator.Valid(); iterator.Next() {
+		var account types.Account
+		if err := json.Unmarshal(iterator.Value(), &account); err != nil {
+			continue
+		}
+		accounts = append(accounts, &account)
+	}
+	return accounts
+}
+
 // GetOrCreateAccount gets an existing account or creates a new one
 // New accounts get an initial balance for testing purposes
 func (k *Keeper) GetOrCreateAccount(ctx sdk.Context, trader string) *types.Account {
@@ -270,6 +328,7 @@ func (k *Keeper) Deposit(ctx context.Context, trader string, amount math.LegacyD
 	// Deposit funds
 	account.Deposit(amount)
 	k.SetAccount(sdkCtx, account)
+	k.recordTransfer(sdkCtx, trader, types.TransferDeposit, amount, account.Balance, "")
 
 	// Emit event
 	sdkCtx.EventManager().EmitEvent(
@@ -284,9 +343,39 @@ func (k *Keeper) Deposit(ctx context.Context, trader string, amount math.LegacyD
 	return nil
 }
 
+// CreditAccount adds funds to a trader's account. Unlike Deposit, a new
+// account starts empty rather than with the testing balance, so other
+// modules can fund accounts they own with exactly what they move in.
+func (k *Keeper) CreditAccount(ctx context.Context, trader string, amount math.LegacyDec) error {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	account := k.GetAccount(sdkCtx, trader)
+	if account == nil {
+		account = types.NewAccount(trader)
+		account.CreatedAt = sdkCtx.BlockTime()
+	}
+	account.Deposit(amount)
+	account.UpdatedAt = sdkCtx.BlockTime()
+	k.SetAccount(sdkCtx, account)
+	k.recordTransfer(sdkCtx, trader, types.TransferDeposit, amount, account.Balance, "")
+
+	sdkCtx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"deposit",
+			sdk.NewAttribute("trader", trader),
+			sdk.NewAttribute("amount", amount.String()),
+			sdk.NewAttribute("new_balance", account.Balance.String()),
+		),
+	)
+	return nil
+}
+
 // Withdraw handles margin withdrawal
 func (k *Keeper) Withdraw(ctx context.Context, trader string, amount math.LegacyDec) error {
 	sdkCtx := sdk.UnwrapSDKContext(ctx)
+	if err := k.checkAccountNotFrozen(sdkCtx, trader); err != nil {
+		return err
+	}
 
 	// Get account
 	account := k.GetAccount(sdkCtx, trader)
@@ -299,6 +388,7 @@ func (k *Keeper) Withdraw(ctx context.Context, trader string, amount math.Legacy
 		return err
 	}
 	k.SetAccount(sdkCtx, account)
+	k.recordTransfer(sdkCtx, trader, types.TransferWithdraw, amount, account.Balance, "")
 
 	// Emit event
 	sdkCtx.EventManager().EmitEvent(
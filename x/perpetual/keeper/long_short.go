@@ -0,0 +1,82 @@
+package keeper
+
+import (
+	"encoding/json"
+
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// Store key prefix of each market's long/short stats. SetPosition and
+// DeletePosition move a position's contribution as it changes, so reading a
+// market's ratio never scans its positions.
+var LongShortKeyPrefix = []byte{0x16}
+
+func longShortKey(marketID string) []byte {
+	return append(append([]byte{}, LongShortKeyPrefix...), []byte(marketID)...)
+}
+
+// GetLongShortStats returns a market's long/short account and size totals
+func (k *Keeper) GetLongShortStats(ctx sdk.Context, marketID string) *types.LongShortStats {
+	bz := k.GetStore(ctx).Get(longShortKey(marketID))
+	if bz == nil {
+		return types.NewLongShortStats(marketID)
+	}
+	var stats types.LongShortStats
+	if err := json.Unmarshal(bz, &stats); err != nil {
+		return types.NewLongShortStats(marketID)
+	}
+	return &stats
+}
+
+func (k *Keeper) setLongShortStats(ctx sdk.Context, stats *types.LongShortStats) {
+	bz, _ := json.Marshal(stats)
+	k.GetStore(ctx).Set(longShortKey(stats.MarketID), bz)
+}
+
+// updateLongShortStats moves a position's contribution to its market's
+// stats from its stored state, nil if new, to its next state, nil if deleted
+func (k *Keeper) updateLongShortStats(ctx sdk.Context, old, next *types.Position) {
+	marketID := ""
+	switch {
+	case next != nil:
+		marketID = next.MarketID
+	case old != nil:
+		marketID = old.MarketID
+	default:
+		return
+	}
+	if old != nil && next != nil && old.Side == next.Side && old.Size.Equal(next.Size) {
+		return
+	}
+	stats := k.GetLongShortStats(ctx, marketID)
+	stats.Apply(old, true)
+	stats.Apply(next, false)
+	k.setLongShortStats(ctx, stats)
+}
+
+// rebuildLongShortStats recomputes every market's stats from its positions
+func (k *Keeper) rebuildLongShortStats(ctx sdk.Context) {
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, LongShortKeyPrefix)
+	var keys [][]byte
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+	for _, key := range keys {
+		store.Delete(key)
+	}
+
+	stats := make(map[string]*types.LongShortStats)
+	for _, position := range k.GetAllPositions(ctx) {
+		if stats[position.MarketID] == nil {
+			stats[position.MarketID] = types.NewLongShortStats(position.MarketID)
+		}
+		stats[position.MarketID].Apply(position, false)
+	}
+	for _, s := range stats {
+		k.setLongShortStats(ctx, s)
+	}
+}
@@ -0,0 +1,61 @@
+package keeper
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TestLongShortStats tests that a market's stats follow its positions as
+// they open, grow, flip and close, and that a rebuild reproduces them
+func TestLongShortStats(t *testing.T) {
+	pm, k, ctx := setupPositionManager(t, 100000)
+	expect := func(step string, longAccounts, shortAccounts int64, longSize, shortSize string) {
+		t.Helper()
+		stats := k.GetLongShortStats(ctx, "BTC-USDC")
+		if stats.LongAccounts != longAccounts || stats.ShortAccounts != shortAccounts ||
+			!stats.LongSize.Equal(dec(longSize)) || !stats.ShortSize.Equal(dec(shortSize)) {
+			t.Fatalf("%s: expected %d/%d accounts and %s/%s size, got %+v",
+				step, longAccounts, shortAccounts, longSize, shortSize, stats)
+		}
+	}
+
+	expect("empty", 0, 0, "0", "0")
+
+	if err := pm.UpdatePositionFromTrade(ctx, positionTrader, "BTC-USDC", true, dec("1"), dec("50000"), math.LegacyZeroDec()); err != nil {
+		t.Fatalf("failed to open position: %v", err)
+	}
+	k.SetPosition(ctx, types.NewPosition("cosmos1other", "BTC-USDC", types.PositionSideShort, dec("2"), dec("50000"), dec("10000")))
+	k.SetPosition(ctx, types.NewPosition("cosmos1third", "BTC-USDC", types.PositionSideShort, dec("0.5"), dec("50000"), dec("2500")))
+	expect("opened", 1, 2, "1", "2.5")
+	if ratio := k.GetLongShortStats(ctx, "BTC-USDC").AccountRatio(); !ratio.Equal(dec("0.5")) {
+		t.Errorf("expected an account ratio of 0.5, got %s", ratio)
+	}
+	if ratio := k.GetLongShortStats(ctx, "BTC-USDC").SizeRatio(); !ratio.Equal(dec("0.4")) {
+		t.Errorf("expected a size ratio of 0.4, got %s", ratio)
+	}
+
+	if err := pm.UpdatePositionFromTrade(ctx, positionTrader, "BTC-USDC", true, dec("0.5"), dec("50000"), math.LegacyZeroDec()); err != nil {
+		t.Fatalf("failed to increase position: %v", err)
+	}
+	expect("increased", 1, 2, "1.5", "2.5")
+
+	if err := pm.UpdatePositionFromTrade(ctx, positionTrader, "BTC-USDC", false, dec("2"), dec("50000"), math.LegacyZeroDec()); err != nil {
+		t.Fatalf("failed to flip position: %v", err)
+	}
+	expect("flipped", 0, 3, "0", "3")
+
+	k.DeletePosition(ctx, "cosmos1third", "BTC-USDC")
+	expect("deleted", 0, 2, "0", "2.5")
+	if ratio := k.GetLongShortStats(ctx, "BTC-USDC").AccountRatio(); !ratio.IsZero() {
+		t.Errorf("expected no long accounts, got a ratio of %s", ratio)
+	}
+
+	k.rebuildLongShortStats(ctx)
+	expect("rebuilt", 0, 2, "0", "2.5")
+	if stats := k.GetLongShortStats(ctx, "ETH-USDC"); stats.LongAccounts != 0 || stats.ShortAccounts != 0 {
+		t.Errorf("expected no stats for a market without positions, got %+v", stats)
+	}
+}
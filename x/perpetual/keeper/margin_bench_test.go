@@ -0,0 +1,111 @@
+package keeper
+
+import (
+	"fmt"
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// Margin benchmarks run against the store-backed keeper; see
+// x/orderbook/keeper/keeper_bench_test.go for comparing runs
+
+// seedBenchPositions gives trader a deposit and a 0.1 long in each of
+// positions markets, one base asset per market, priced at 1000
+func seedBenchPositions(tb testing.TB, k *Keeper, ctx sdk.Context, trader string, positions int) {
+	tb.Helper()
+	if err := k.Deposit(ctx, trader, math.LegacyNewDec(1000000)); err != nil {
+		tb.Fatalf("failed to deposit: %v", err)
+	}
+	for i := 0; i < positions; i++ {
+		marketID := fmt.Sprintf("MKT%d-USDC", i)
+		if k.GetMarket(ctx, marketID) == nil {
+			k.SetMarket(ctx, types.NewMarket(marketID, fmt.Sprintf("MKT%d", i), "USDC"))
+			k.SetPrice(ctx, types.NewPriceInfo(marketID, math.LegacyNewDec(1000)))
+		}
+		k.SetPosition(ctx, types.NewPosition(trader, marketID, types.PositionSideLong, math.LegacyNewDecWithPrec(1, 1), math.LegacyNewDec(1000), math.LegacyNewDec(5)))
+	}
+}
+
+// BenchmarkMarginCheckerAccountEquity benchmarks marking an account's
+// positions to market
+func BenchmarkMarginCheckerAccountEquity(b *testing.B) {
+	for _, positions := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("positions=%d", positions), func(b *testing.B) {
+			k, ctx := setupFundingKeeper(b)
+			seedBenchPositions(b, k, ctx, "alice", positions)
+			mc := NewMarginChecker(k)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				mc.CalculateAccountEquity(ctx, "alice")
+			}
+		})
+	}
+}
+
+// BenchmarkMarginCheckerInitialMargin benchmarks the pre-trade initial
+// margin check
+func BenchmarkMarginCheckerInitialMargin(b *testing.B) {
+	k, ctx := setupFundingKeeper(b)
+	seedBenchPositions(b, k, ctx, "alice", 1)
+	mc := NewMarginChecker(k)
+	size, price := math.LegacyNewDecWithPrec(1, 1), math.LegacyNewDec(50000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := mc.CheckInitialMarginRequirement(ctx, "alice", "BTC-USDC", size, price); err != nil {
+			b.Fatalf("unexpected margin error: %v", err)
+		}
+	}
+}
+
+// BenchmarkMarginCheckerUnhealthyPositions benchmarks the liquidation scan
+// over every open position
+func BenchmarkMarginCheckerUnhealthyPositions(b *testing.B) {
+	for _, traders := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("traders=%d", traders), func(b *testing.B) {
+			k, ctx := setupFundingKeeper(b)
+			for n := 0; n < traders; n++ {
+				seedBenchPositions(b, k, ctx, fmt.Sprintf("trader-%d", n), 5)
+			}
+			mc := NewMarginChecker(k)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				mc.GetUnhealthyPositions(ctx)
+			}
+		})
+	}
+}
+
+// BenchmarkKeeperCheckMarginRequirement benchmarks the margin check the
+// orderbook runs for every order, in each margin mode
+func BenchmarkKeeperCheckMarginRequirement(b *testing.B) {
+	modes := []types.MarginMode{types.MarginModeIsolated, types.MarginModeCross, types.MarginModePortfolio}
+	for _, mode := range modes {
+		for _, positions := range []int{1, 10, 50} {
+			b.Run(fmt.Sprintf("%s/positions=%d", mode, positions), func(b *testing.B) {
+				k, ctx := setupFundingKeeper(b)
+				if err := k.SetMarginMode(ctx, "alice", mode); err != nil {
+					b.Fatalf("failed to set margin mode: %v", err)
+				}
+				seedBenchPositions(b, k, ctx, "alice", positions)
+				size, price := math.LegacyNewDecWithPrec(1, 1), math.LegacyNewDec(50000)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if err := k.CheckMarginRequirement(ctx, "alice", "BTC-USDC", types.PositionSideLong, size, price); err != nil {
+						b.Fatalf("unexpected margin error: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -144,8 +144,13 @@ func (k *Keeper) CalculateCrossMargin(ctx sdk.Context, trader string) *types.Cro
 
 // ============ Margin Requirement Checks ============
 
-// CheckMarginRequirement checks if a trader has sufficient margin for a new position
+// CheckMarginRequirement checks if a trader has sufficient margin for a new
+// position, and that the account is not restricted from placing the order
 func (k *Keeper) CheckMarginRequirement(ctx sdk.Context, trader, marketID string, side types.PositionSide, quantity, price math.LegacyDec) error {
+	if err := k.CheckTradingRestriction(ctx, trader, marketID, side, quantity); err != nil {
+		return err
+	}
+
 	// Use GetOrCreateAccount to auto-create account on first trade
 	account := k.GetOrCreateAccount(ctx, trader)
 	if account == nil {
@@ -161,7 +166,10 @@ func (k *Keeper) CheckMarginRequirement(ctx sdk.Context, trader, marketID string
 	notional := quantity.Mul(price)
 	requiredMargin := notional.Mul(market.InitialMarginRate)
 
-	if account.MarginMode.IsCross() {
+	if account.MarginMode.IsPortfolio() {
+		// Portfolio margin mode - check the stressed book after the trade
+		return k.checkPortfolioMarginRequirement(ctx, trader, marketID, side, quantity)
+	} else if account.MarginMode.IsCross() {
 		// Cross margin mode - check total available margin
 		crossInfo := k.CalculateCrossMargin(ctx, trader)
 		if crossInfo == nil || crossInfo.AvailableMargin.LT(requiredMargin) {
@@ -184,6 +192,11 @@ func (k *Keeper) GetEffectiveMargin(ctx sdk.Context, trader, marketID string) ma
 		return math.LegacyZeroDec()
 	}
 
+	if account.MarginMode.IsPortfolio() {
+		if pm := k.CalculatePortfolioMargin(ctx, trader); pm != nil {
+			return pm.AvailableMargin
+		}
+	}
 	if account.MarginMode.IsCross() {
 		crossInfo := k.CalculateCrossMargin(ctx, trader)
 		if crossInfo != nil {
@@ -200,12 +213,49 @@ func (k *Keeper) GetEffectiveMargin(ctx sdk.Context, trader, marketID string) ma
 	return account.AvailableBalance()
 }
 
+// GetFreeCollateral returns the collateral that can leave a trader's account
+// without weakening open positions. In cross mode, equity must still cover the
+// initial margin of every open position, so unrealized losses reduce it; in
+// portfolio mode, it must cover the portfolio initial margin.
+func (k *Keeper) GetFreeCollateral(ctx sdk.Context, trader string) math.LegacyDec {
+	account := k.GetAccount(ctx, trader)
+	if account == nil {
+		return math.LegacyZeroDec()
+	}
+
+	free := account.AvailableBalance()
+	if account.MarginMode.IsPortfolio() {
+		pm := k.CalculatePortfolioMargin(ctx, trader)
+		free = math.LegacyMinDec(free, pm.Equity.Sub(pm.InitialMargin))
+	} else if account.MarginMode.IsCross() {
+		crossInfo := k.CalculateCrossMargin(ctx, trader)
+		if crossInfo == nil {
+			return math.LegacyZeroDec()
+		}
+		initialMargin := math.LegacyZeroDec()
+		for _, pos := range k.GetPositionsByTrader(ctx, trader) {
+			priceInfo := k.GetPrice(ctx, pos.MarketID)
+			market := k.GetMarket(ctx, pos.MarketID)
+			if priceInfo == nil || market == nil {
+				continue
+			}
+			initialMargin = initialMargin.Add(pos.Size.Mul(priceInfo.MarkPrice).Mul(market.InitialMarginRate))
+		}
+		free = math.LegacyMinDec(free, crossInfo.Equity.Sub(initialMargin))
+	}
+
+	if free.IsNegative() {
+		return math.LegacyZeroDec()
+	}
+	return free
+}
+
 // ============ Cross Margin PnL Tracking ============
 
 // UpdateCrossMarginPnL updates the cross margin PnL for an account
 func (k *Keeper) UpdateCrossMarginPnL(ctx sdk.Context, trader string) error {
 	account := k.GetAccount(ctx, trader)
-	if account == nil || !account.MarginMode.IsCross() {
+	if account == nil || account.MarginMode.IsIsolated() {
 		return nil
 	}
 
@@ -237,6 +287,10 @@ func (k *Keeper) CheckLiquidation(ctx sdk.Context, trader, marketID string) (boo
 		return false, nil
 	}
 
+	if account.MarginMode.IsPortfolio() {
+		// Portfolio margin - check the stressed book
+		return k.checkPortfolioMarginLiquidation(ctx, trader)
+	}
 	if account.MarginMode.IsCross() {
 		// Cross margin - check entire account
 		return k.checkCrossMarginLiquidation(ctx, trader)
@@ -269,29 +323,48 @@ func (k *Keeper) checkCrossMarginLiquidation(ctx sdk.Context, trader string) (bo
 	}
 
 	if !crossInfo.IsHealthy {
-		// Return the largest position for liquidation
-		positions := k.GetPositionsByTrader(ctx, trader)
-		var largestPosition *types.Position
-		largestNotional := math.LegacyZeroDec()
+		return true, k.largestPosition(ctx, trader)
+	}
 
-		for _, pos := range positions {
-			priceInfo := k.GetPrice(ctx, pos.MarketID)
-			if priceInfo == nil {
-				continue
-			}
-			notional := pos.Size.Mul(priceInfo.MarkPrice)
-			if notional.GT(largestNotional) {
-				largestNotional = notional
-				largestPosition = pos
-			}
-		}
+	return false, nil
+}
+
+// checkPortfolioMarginLiquidation checks a portfolio margin account for
+// liquidation: equity below the stressed maintenance margin
+func (k *Keeper) checkPortfolioMarginLiquidation(ctx sdk.Context, trader string) (bool, *types.Position) {
+	pm := k.CalculatePortfolioMargin(ctx, trader)
+	if pm == nil {
+		return false, nil
+	}
 
-		return true, largestPosition
+	if !pm.IsHealthy {
+		return true, k.largestPosition(ctx, trader)
 	}
 
 	return false, nil
 }
 
+// largestPosition returns a trader's position with the largest notional at
+// mark, liquidated first when a shared margin account is unhealthy
+func (k *Keeper) largestPosition(ctx sdk.Context, trader string) *types.Position {
+	var largestPosition *types.Position
+	largestNotional := math.LegacyZeroDec()
+
+	for _, pos := range k.GetPositionsByTrader(ctx, trader) {
+		priceInfo := k.GetPrice(ctx, pos.MarketID)
+		if priceInfo == nil {
+			continue
+		}
+		notional := pos.Size.Mul(priceInfo.MarkPrice)
+		if notional.GT(largestNotional) {
+			largestNotional = notional
+			largestPosition = pos
+		}
+	}
+
+	return largestPosition
+}
+
 // GetMarginSummary returns a summary of margin status for a trader
 type MarginSummary struct {
 	Trader             string
@@ -333,7 +406,18 @@ func (k *Keeper) GetMarginSummary(ctx sdk.Context, trader string) *MarginSummary
 		PositionCount:     len(positions),
 	}
 
-	if account.MarginMode.IsCross() {
+	if account.MarginMode.IsPortfolio() {
+		if pm := k.CalculatePortfolioMargin(ctx, trader); pm != nil {
+			summary.TotalUnrealizedPnL = pm.Equity.Sub(account.Balance)
+			summary.TotalEquity = pm.Equity
+			summary.AvailableMargin = pm.AvailableMargin
+			summary.MarginRatio = math.LegacyNewDec(1)
+			if pm.GrossNotional.IsPositive() {
+				summary.MarginRatio = pm.Equity.Quo(pm.GrossNotional)
+			}
+			summary.IsHealthy = pm.IsHealthy
+		}
+	} else if account.MarginMode.IsCross() {
 		crossInfo := k.CalculateCrossMargin(ctx, trader)
 		if crossInfo != nil {
 			summary.TotalUnrealizedPnL = crossInfo.TotalUnrealizedPnL
@@ -2,6 +2,7 @@ package keeper
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"cosmossdk.io/math"
@@ -22,15 +23,20 @@ func (k *Keeper) CreateMarket(ctx sdk.Context, config types.MarketConfig) error
 		return err
 	}
 
-	// Create market
+	// Create market; a warm-up schedule counts from the listing block
 	market := types.NewMarketWithConfig(config)
+	if config.WarmUp != nil {
+		warmUp := *config.WarmUp
+		warmUp.ListedHeight = ctx.BlockHeight()
+		market.WarmUp = &warmUp
+	}
 	k.SetMarket(ctx, market)
 
 	// Initialize price
 	k.SetPrice(ctx, types.NewPriceInfo(config.MarketID, math.LegacyZeroDec()))
 
 	// Set next funding time
-	nextFundingTime := nextFundingTimeUTC(ctx.BlockTime())
+	nextFundingTime := k.nextFundingTime(ctx, config.MarketID)
 	k.SetNextFundingTime(ctx, config.MarketID, nextFundingTime)
 
 	// Emit event
@@ -67,6 +73,26 @@ func (k *Keeper) validateMarketConfig(config types.MarketConfig) error {
 	if config.MaxLeverage.IsNil() || config.MaxLeverage.LTE(math.LegacyZeroDec()) {
 		return types.ErrInvalidLeverage
 	}
+	if config.WarmUp != nil {
+		if err := config.WarmUp.Validate(); err != nil {
+			return err
+		}
+	}
+	return validateMarketPrecision(config)
+}
+
+// validateMarketPrecision checks that the fill notional at the configured
+// decimals stays exact and that tick and lot sizes are representable
+func validateMarketPrecision(config types.MarketConfig) error {
+	if config.PriceDecimals+config.SizeDecimals > types.MaxMarketDecimals {
+		return fmt.Errorf("%w: price plus size decimals exceed %d", types.ErrInvalidMarketPrecision, types.MaxMarketDecimals)
+	}
+	if config.PriceDecimals != 0 && types.DecimalPlaces(config.TickSize) > config.PriceDecimals {
+		return fmt.Errorf("%w: tick size %s has more than %d decimals", types.ErrInvalidMarketPrecision, config.TickSize, config.PriceDecimals)
+	}
+	if config.SizeDecimals != 0 && types.DecimalPlaces(config.LotSize) > config.SizeDecimals {
+		return fmt.Errorf("%w: lot size %s has more than %d decimals", types.ErrInvalidMarketPrecision, config.LotSize, config.SizeDecimals)
+	}
 	return nil
 }
 
@@ -195,7 +221,8 @@ func (k *Keeper) InitDefaultMarkets(ctx sdk.Context) {
 	k.Logger().Info("default markets initialized", "count", len(configs))
 }
 
-// ValidateOrderSize validates order size against market limits
+// ValidateOrderSize validates order size against market limits, reduced
+// while the market is warming up
 func (k *Keeper) ValidateOrderSize(ctx sdk.Context, marketID string, size math.LegacyDec) error {
 	market := k.GetMarket(ctx, marketID)
 	if market == nil {
@@ -205,7 +232,7 @@ func (k *Keeper) ValidateOrderSize(ctx sdk.Context, marketID string, size math.L
 	if size.LT(market.MinOrderSize) {
 		return types.ErrOrderSizeTooSmall
 	}
-	if size.GT(market.MaxOrderSize) {
+	if limits := k.GetOrderLimits(ctx, market); size.GT(limits.MaxOrderSize) {
 		return types.ErrOrderSizeTooLarge
 	}
 
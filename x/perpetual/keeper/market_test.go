@@ -1,6 +1,7 @@
 package keeper
 
 import (
+	"errors"
 	"testing"
 
 	"cosmossdk.io/math"
@@ -230,3 +231,88 @@ func TestMarketConfig(t *testing.T) {
 		t.Errorf("expected insurance fund ID %s, got %s", config.InsuranceFundID, market.InsuranceFundID)
 	}
 }
+
+// TestMarketPrecision tests derived and configured matching decimals and
+// their validation
+func TestMarketPrecision(t *testing.T) {
+	config := types.MarketConfig{
+		MarketID:    "TEST-USDC",
+		BaseAsset:   "TEST",
+		QuoteAsset:  "USDC",
+		MaxLeverage: math.LegacyNewDec(10),
+		TickSize:    math.LegacyNewDecWithPrec(5, 2),
+		LotSize:     math.LegacyNewDecWithPrec(1, 4),
+	}
+
+	market := types.NewMarketWithConfig(config)
+	if price, size := market.Precision(); price != 2 || size != 4 {
+		t.Errorf("expected decimals derived from tick and lot size (2, 4), got (%d, %d)", price, size)
+	}
+
+	config.PriceDecimals, config.SizeDecimals = 3, 6
+	market = types.NewMarketWithConfig(config)
+	if price, size := market.Precision(); price != 3 || size != 6 {
+		t.Errorf("expected configured decimals (3, 6), got (%d, %d)", price, size)
+	}
+	if err := validateMarketPrecision(config); err != nil {
+		t.Errorf("expected valid precision, got %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		price, size uint32
+	}{
+		{"notional beyond Dec precision", 10, 9},
+		{"tick size not representable", 1, 6},
+		{"lot size not representable", 3, 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := config
+			c.PriceDecimals, c.SizeDecimals = tc.price, tc.size
+			if err := validateMarketPrecision(c); !errors.Is(err, types.ErrInvalidMarketPrecision) {
+				t.Errorf("expected ErrInvalidMarketPrecision, got %v", err)
+			}
+		})
+	}
+}
+
+// TestMarketWarmUp tests that a created market's warm-up counts from its
+// listing block, reducing the max order size until the schedule relaxes
+func TestMarketWarmUp(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	ctx = ctx.WithBlockHeight(500)
+	config := types.MarketConfig{
+		MarketID:     "NEW-USDC",
+		BaseAsset:    "NEW",
+		QuoteAsset:   "USDC",
+		MaxLeverage:  math.LegacyNewDec(10),
+		TickSize:     math.LegacyNewDecWithPrec(1, 2),
+		LotSize:      math.LegacyNewDecWithPrec(1, 2),
+		MinOrderSize: math.LegacyNewDecWithPrec(1, 2),
+		MaxOrderSize: math.LegacyNewDec(100),
+		WarmUp:       &types.WarmUpSchedule{Stages: []types.WarmUpStage{{Blocks: 10, PostOnly: true, OrderSizeFraction: math.LegacyNewDecWithPrec(1, 1)}}},
+	}
+
+	invalid := config
+	invalid.WarmUp = &types.WarmUpSchedule{Stages: []types.WarmUpStage{{Blocks: -1}}}
+	if err := k.CreateMarket(ctx, invalid); !errors.Is(err, types.ErrInvalidWarmUp) {
+		t.Fatalf("expected an invalid schedule to be rejected, got %v", err)
+	}
+	if err := k.CreateMarket(ctx, config); err != nil {
+		t.Fatalf("failed to create market: %v", err)
+	}
+	if market := k.GetMarket(ctx, "NEW-USDC"); market.WarmUp == nil || market.WarmUp.ListedHeight != 500 {
+		t.Fatalf("expected the warm-up to start at the listing height 500, got %+v", market.WarmUp)
+	}
+	if config.WarmUp.ListedHeight != 0 {
+		t.Error("expected the config's schedule to be left unchanged")
+	}
+
+	if err := k.ValidateOrderSize(ctx, "NEW-USDC", math.LegacyNewDec(20)); !errors.Is(err, types.ErrOrderSizeTooLarge) {
+		t.Errorf("expected 20 to exceed the warm-up max of 10, got %v", err)
+	}
+	if err := k.ValidateOrderSize(ctx.WithBlockHeight(510), "NEW-USDC", math.LegacyNewDec(20)); err != nil {
+		t.Errorf("expected 20 to be accepted after the warm-up, got %v", err)
+	}
+}
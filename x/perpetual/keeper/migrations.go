@@ -0,0 +1,28 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Migrator runs in-place store migrations for the perpetual module
+type Migrator struct {
+	keeper *Keeper
+}
+
+// NewMigrator returns a new Migrator
+func NewMigrator(keeper *Keeper) Migrator {
+	return Migrator{keeper: keeper}
+}
+
+// Migrate1to2 migrates the store from consensus version 1 to 2. Version 2
+// adds genesis import and export; the store layout is unchanged.
+func (m Migrator) Migrate1to2(ctx sdk.Context) error {
+	return nil
+}
+
+// Migrate2to3 migrates the store from consensus version 2 to 3. Version 3
+// keeps each market's long/short stats, built here from the open positions.
+func (m Migrator) Migrate2to3(ctx sdk.Context) error {
+	m.keeper.rebuildLongShortStats(ctx)
+	return nil
+}
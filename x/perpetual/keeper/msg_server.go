@@ -75,8 +75,9 @@ func (m *msgServer) Withdraw(ctx context.Context, msg *types.MsgWithdraw) (*type
 		return nil, fmt.Errorf("amount must be positive")
 	}
 
-	// Perform withdrawal through keeper
-	if err := m.Keeper.Withdraw(sdkCtx, msg.Trader, amount); err != nil {
+	// Request the withdrawal through keeper, held if the account has a timelock
+	withdrawal, err := m.Keeper.RequestWithdrawal(sdkCtx, msg.Trader, amount, msg.Destination)
+	if err != nil {
 		return nil, err
 	}
 
@@ -88,6 +89,84 @@ func (m *msgServer) Withdraw(ctx context.Context, msg *types.MsgWithdraw) (*type
 	}
 
 	return &types.MsgWithdrawResponse{
-		NewBalance: newBalance.String(),
+		NewBalance:   newBalance.String(),
+		WithdrawalID: withdrawal.WithdrawalID,
+		Status:       string(withdrawal.Status),
+		ReleaseAt:    withdrawal.ReleaseAt.Unix(),
+	}, nil
+}
+
+// CancelWithdrawal handles the MsgCancelWithdrawal message
+func (m *msgServer) CancelWithdrawal(ctx context.Context, msg *types.MsgCancelWithdrawal) (*types.MsgCancelWithdrawalResponse, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	if _, err := m.Keeper.CancelWithdrawal(sdkCtx, msg.Trader, msg.WithdrawalID); err != nil {
+		return nil, err
+	}
+
+	account := m.Keeper.GetAccount(sdkCtx, msg.Trader)
+	return &types.MsgCancelWithdrawalResponse{
+		NewBalance: account.Balance.String(),
+	}, nil
+}
+
+// InternalTransfer handles the MsgInternalTransfer message
+func (m *msgServer) InternalTransfer(ctx context.Context, msg *types.MsgInternalTransfer) (*types.MsgInternalTransferResponse, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	amount, err := math.LegacyNewDecFromStr(msg.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+
+	transfer, err := m.Keeper.InternalTransfer(sdkCtx, msg.Sender, msg.Recipient, amount)
+	if err != nil {
+		return nil, err
+	}
+	return &types.MsgInternalTransferResponse{
+		TransferID: transfer.TransferID,
+		NewBalance: transfer.Balance.String(),
+	}, nil
+}
+
+// UpdateTradingSchedule handles the MsgUpdateTradingSchedule governance message
+func (m *msgServer) UpdateTradingSchedule(ctx context.Context, msg *types.MsgUpdateTradingSchedule) (*types.MsgUpdateTradingScheduleResponse, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	if msg.Authority != m.Keeper.GetAuthority() {
+		return nil, fmt.Errorf("%w: expected %s, got %s", types.ErrUnauthorized, m.Keeper.GetAuthority(), msg.Authority)
+	}
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
+	if err := m.Keeper.SetTradingSchedule(sdkCtx, &msg.Schedule); err != nil {
+		return nil, err
+	}
+	return &types.MsgUpdateTradingScheduleResponse{}, nil
+}
+
+// UpdateFundingConfig handles the MsgUpdateFundingConfig governance message
+func (m *msgServer) UpdateFundingConfig(ctx context.Context, msg *types.MsgUpdateFundingConfig) (*types.MsgUpdateFundingConfigResponse, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	if msg.Authority != m.Keeper.GetAuthority() {
+		return nil, fmt.Errorf("%w: expected %s, got %s", types.ErrUnauthorized, m.Keeper.GetAuthority(), msg.Authority)
+	}
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
+	if err := m.Keeper.UpdateFundingConfig(sdkCtx, msg.MarketID, msg.Config); err != nil {
+		return nil, err
+	}
+	return &types.MsgUpdateFundingConfigResponse{
+		NextFundingTime: m.Keeper.GetNextFundingTime(sdkCtx, msg.MarketID).Unix(),
 	}, nil
 }
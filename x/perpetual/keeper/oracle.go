@@ -44,8 +44,27 @@ type OracleSourcePrice struct {
 
 // weightedPrice represents a price with its source weight for aggregation
 type weightedPrice struct {
-	price  math.LegacyDec
-	weight int
+	sourceID  string
+	price     math.LegacyDec
+	weight    int
+	timestamp time.Time
+}
+
+// IndexComponent is a source price contributing to a market's index price
+type IndexComponent struct {
+	SourceID  string
+	Price     math.LegacyDec
+	Weight    math.LegacyDec // Share of the index after time decay (0-1), zero if excluded
+	Included  bool           // False when filtered as an outlier
+	Timestamp time.Time
+}
+
+// IndexPrice is an aggregated index price with its constituents
+type IndexPrice struct {
+	MarketID   string
+	Price      math.LegacyDec
+	Components []*IndexComponent
+	Timestamp  time.Time
 }
 
 // OracleConfig contains oracle configuration
@@ -272,6 +291,69 @@ func (k *Keeper) getSourcePrice(ctx sdk.Context, sourceID, marketID string) *Ora
 // CRITICAL FIX: Added time-based weight decay to prevent stale price manipulation
 func (k *Keeper) AggregatePrice(ctx sdk.Context, marketID string) (math.LegacyDec, error) {
 	config := k.GetOracleConfig(ctx)
+	validPrices := k.collectSourcePrices(ctx, marketID, config)
+
+	if len(validPrices) < config.MinSources {
+		return math.LegacyZeroDec(), fmt.Errorf("insufficient price sources: %d < %d required",
+			len(validPrices), config.MinSources)
+	}
+
+	// Calculate weighted median
+	return k.calculateWeightedMedian(validPrices, config.MaxDeviation)
+}
+
+// GetIndexPrice aggregates the index price like AggregatePrice and returns it
+// together with every fresh source price and its share of the index
+func (k *Keeper) GetIndexPrice(ctx sdk.Context, marketID string) (*IndexPrice, error) {
+	config := k.GetOracleConfig(ctx)
+	validPrices := k.collectSourcePrices(ctx, marketID, config)
+
+	if len(validPrices) < config.MinSources {
+		return nil, fmt.Errorf("insufficient price sources: %d < %d required",
+			len(validPrices), config.MinSources)
+	}
+	if len(validPrices) == 0 {
+		return nil, fmt.Errorf("no prices to aggregate")
+	}
+
+	filtered := filterOutlierPrices(validPrices, config.MaxDeviation)
+	price, err := weightedAveragePrice(filtered)
+	if err != nil {
+		return nil, err
+	}
+
+	totalWeight := 0
+	included := make(map[string]bool, len(filtered))
+	for _, wp := range filtered {
+		totalWeight += wp.weight
+		included[wp.sourceID] = true
+	}
+
+	index := &IndexPrice{
+		MarketID:   marketID,
+		Price:      price,
+		Components: make([]*IndexComponent, 0, len(validPrices)),
+		Timestamp:  ctx.BlockTime(),
+	}
+	for _, wp := range validPrices {
+		component := &IndexComponent{
+			SourceID:  wp.sourceID,
+			Price:     wp.price,
+			Weight:    math.LegacyZeroDec(),
+			Included:  included[wp.sourceID],
+			Timestamp: wp.timestamp,
+		}
+		if component.Included {
+			component.Weight = math.LegacyNewDec(int64(wp.weight)).QuoInt64(int64(totalWeight))
+		}
+		index.Components = append(index.Components, component)
+	}
+	return index, nil
+}
+
+// collectSourcePrices returns the fresh prices of all active sources for a
+// market, weighted by source weight and age
+func (k *Keeper) collectSourcePrices(ctx sdk.Context, marketID string, config OracleConfig) []weightedPrice {
 	sources := k.GetAllOracleSources(ctx)
 
 	var validPrices []weightedPrice
@@ -321,25 +403,23 @@ func (k *Keeper) AggregatePrice(ctx sdk.Context, marketID string) (math.LegacyDe
 			}
 
 			validPrices = append(validPrices, weightedPrice{
-				price:  priceData.Price,
-				weight: adjustedWeight,
+				sourceID:  source.SourceID,
+				price:     priceData.Price,
+				weight:    adjustedWeight,
+				timestamp: priceData.Timestamp,
 			})
 		} else {
 			// Fallback: no time decay if maxAgeSeconds is 0
 			validPrices = append(validPrices, weightedPrice{
-				price:  priceData.Price,
-				weight: source.Weight,
+				sourceID:  source.SourceID,
+				price:     priceData.Price,
+				weight:    source.Weight,
+				timestamp: priceData.Timestamp,
 			})
 		}
 	}
 
-	if len(validPrices) < config.MinSources {
-		return math.LegacyZeroDec(), fmt.Errorf("insufficient price sources: %d < %d required",
-			len(validPrices), config.MinSources)
-	}
-
-	// Calculate weighted median
-	return k.calculateWeightedMedian(validPrices, config.MaxDeviation)
+	return validPrices
 }
 
 // calculateWeightedMedian calculates the weighted median price with outlier filtering
@@ -348,6 +428,12 @@ func (k *Keeper) calculateWeightedMedian(prices []weightedPrice, maxDeviation ma
 		return math.LegacyZeroDec(), fmt.Errorf("no prices to aggregate")
 	}
 
+	return weightedAveragePrice(filterOutlierPrices(prices, maxDeviation))
+}
+
+// filterOutlierPrices sorts prices by value and drops those deviating from the
+// simple median by more than maxDeviation
+func filterOutlierPrices(prices []weightedPrice, maxDeviation math.LegacyDec) []weightedPrice {
 	// Sort prices by value
 	sort.Slice(prices, func(i, j int) bool {
 		return prices[i].price.LT(prices[j].price)
@@ -365,7 +451,11 @@ func (k *Keeper) calculateWeightedMedian(prices []weightedPrice, maxDeviation ma
 			filteredPrices = append(filteredPrices, wp)
 		}
 	}
+	return filteredPrices
+}
 
+// weightedAveragePrice calculates the weighted average of filtered prices
+func weightedAveragePrice(filteredPrices []weightedPrice) (math.LegacyDec, error) {
 	if len(filteredPrices) == 0 {
 		return math.LegacyZeroDec(), fmt.Errorf("all prices filtered as outliers")
 	}
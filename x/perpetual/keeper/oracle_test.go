@@ -0,0 +1,39 @@
+package keeper
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+)
+
+// TestIndexPriceAggregation tests outlier filtering and weighting of source prices
+func TestIndexPriceAggregation(t *testing.T) {
+	prices := []weightedPrice{
+		{sourceID: "okx", price: math.LegacyNewDec(50100), weight: 20},
+		{sourceID: "kraken", price: math.LegacyNewDec(60000), weight: 10},
+		{sourceID: "binance", price: math.LegacyNewDec(50000), weight: 30},
+	}
+
+	filtered := filterOutlierPrices(prices, math.LegacyNewDecWithPrec(2, 2))
+	if len(filtered) != 2 {
+		t.Fatalf("expected 2 prices after filtering, got %d", len(filtered))
+	}
+	for _, wp := range filtered {
+		if wp.sourceID == "kraken" {
+			t.Error("expected kraken to be filtered as an outlier")
+		}
+	}
+
+	// (50000*30 + 50100*20) / 50 = 50040
+	price, err := weightedAveragePrice(filtered)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !price.Equal(math.LegacyNewDec(50040)) {
+		t.Errorf("expected index price 50040, got %s", price)
+	}
+
+	if _, err := weightedAveragePrice(nil); err == nil {
+		t.Error("expected error when all prices are filtered")
+	}
+}
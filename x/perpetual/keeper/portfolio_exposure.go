@@ -0,0 +1,123 @@
+package keeper
+
+import (
+	"sort"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// CalculatePortfolioExposure values a trader's open positions at the mark
+// price and aggregates them per base asset and across the account. It
+// returns nil for a trader without an account.
+func (k *Keeper) CalculatePortfolioExposure(ctx sdk.Context, trader string) *types.PortfolioExposure {
+	account := k.GetAccount(ctx, trader)
+	if account == nil {
+		return nil
+	}
+	positions := k.GetPositionsByTrader(ctx, trader)
+	sort.Slice(positions, func(i, j int) bool { return positions[i].MarketID < positions[j].MarketID })
+
+	pe := &types.PortfolioExposure{
+		Trader:           trader,
+		Equity:           account.Balance,
+		Positions:        make([]*types.PositionExposure, 0, len(positions)),
+		Assets:           make([]*types.AssetExposure, 0),
+		LongNotional:     math.LegacyZeroDec(),
+		ShortNotional:    math.LegacyZeroDec(),
+		AdjustedNotional: math.LegacyZeroDec(),
+		Leverage:         math.LegacyZeroDec(),
+		Skew:             math.LegacyZeroDec(),
+	}
+
+	assets := make(map[string]*types.AssetExposure)
+	for _, pos := range positions {
+		markPrice := pos.EntryPrice
+		if priceInfo := k.GetPrice(ctx, pos.MarketID); priceInfo != nil {
+			markPrice = priceInfo.MarkPrice
+		}
+		baseAsset := pos.MarketID
+		if market := k.GetMarket(ctx, pos.MarketID); market != nil {
+			baseAsset = market.BaseAsset
+		}
+
+		exposure := &types.PositionExposure{
+			MarketID:      pos.MarketID,
+			BaseAsset:     baseAsset,
+			Side:          pos.Side,
+			Size:          pos.Size,
+			MarkPrice:     markPrice,
+			Notional:      pos.Size.Mul(markPrice),
+			Margin:        pos.Margin,
+			UnrealizedPnL: pos.CalculateUnrealizedPnL(markPrice),
+			Leverage:      math.LegacyZeroDec(),
+			EquityShare:   math.LegacyZeroDec(),
+		}
+		if pos.Margin.IsPositive() {
+			exposure.Leverage = exposure.Notional.Quo(pos.Margin)
+		}
+		pe.Equity = pe.Equity.Add(exposure.UnrealizedPnL)
+		pe.Positions = append(pe.Positions, exposure)
+
+		asset, ok := assets[baseAsset]
+		if !ok {
+			asset = &types.AssetExposure{
+				Asset:         baseAsset,
+				LongNotional:  math.LegacyZeroDec(),
+				ShortNotional: math.LegacyZeroDec(),
+			}
+			assets[baseAsset] = asset
+			pe.Assets = append(pe.Assets, asset)
+		}
+		if pos.Side == types.PositionSideShort {
+			asset.ShortNotional = asset.ShortNotional.Add(exposure.Notional)
+			pe.ShortNotional = pe.ShortNotional.Add(exposure.Notional)
+		} else {
+			asset.LongNotional = asset.LongNotional.Add(exposure.Notional)
+			pe.LongNotional = pe.LongNotional.Add(exposure.Notional)
+		}
+	}
+	sort.Slice(pe.Assets, func(i, j int) bool { return pe.Assets[i].Asset < pe.Assets[j].Asset })
+
+	pe.GrossNotional = pe.LongNotional.Add(pe.ShortNotional)
+	pe.NetNotional = pe.LongNotional.Sub(pe.ShortNotional)
+	if pe.Equity.IsPositive() {
+		pe.Leverage = pe.GrossNotional.Quo(pe.Equity)
+		for _, exposure := range pe.Positions {
+			exposure.EquityShare = exposure.Notional.Quo(pe.Equity)
+		}
+	}
+	if pe.GrossNotional.IsPositive() {
+		pe.Skew = pe.NetNotional.Quo(pe.GrossNotional)
+	}
+
+	for _, asset := range pe.Assets {
+		asset.NetNotional = asset.LongNotional.Sub(asset.ShortNotional)
+		asset.AdjustedNetNotional = asset.NetNotional
+	}
+	// As in portfolio margin, each offset nets the smaller of two opposite
+	// exposures against the other, in order, so none is netted twice
+	for _, offset := range k.GetRiskArray(ctx).Offsets {
+		a, b := assets[offset.AssetA], assets[offset.AssetB]
+		if a == nil || b == nil || !a.AdjustedNetNotional.Mul(b.AdjustedNetNotional).IsNegative() {
+			continue
+		}
+		hedged := math.LegacyMinDec(a.AdjustedNetNotional.Abs(), b.AdjustedNetNotional.Abs()).Mul(offset.Offset)
+		a.AdjustedNetNotional = towardZero(a.AdjustedNetNotional, hedged)
+		b.AdjustedNetNotional = towardZero(b.AdjustedNetNotional, hedged)
+	}
+	for _, asset := range pe.Assets {
+		pe.AdjustedNotional = pe.AdjustedNotional.Add(asset.AdjustedNetNotional.Abs())
+	}
+	return pe
+}
+
+// towardZero moves a signed amount by delta toward zero
+func towardZero(amount, delta math.LegacyDec) math.LegacyDec {
+	if amount.IsNegative() {
+		return amount.Add(delta)
+	}
+	return amount.Sub(delta)
+}
@@ -0,0 +1,55 @@
+package keeper
+
+import (
+	"testing"
+
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TestPortfolioExposure tests that positions are valued at the mark against
+// the account's equity, and that correlation offsets net opposite exposures
+// in correlated assets
+func TestPortfolioExposure(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	if pe := k.CalculatePortfolioExposure(ctx, "alice"); pe != nil {
+		t.Fatalf("expected no exposure without an account, got %+v", pe)
+	}
+	setupPortfolioBook(t, k, ctx, "10000")
+
+	// ETH falls to 2400: the short gains 2000 and is worth 48000
+	k.SetPrice(ctx, types.NewPriceInfo("ETH-USDC", dec("2400")))
+	pe := k.CalculatePortfolioExposure(ctx, "alice")
+	if len(pe.Positions) != 2 || len(pe.Assets) != 2 {
+		t.Fatalf("expected 2 positions in 2 assets, got %+v", pe)
+	}
+	btc, eth := pe.Positions[0], pe.Positions[1]
+	if !btc.Notional.Equal(dec("50000")) || !btc.Leverage.Equal(dec("20")) || !btc.EquityShare.Equal(dec("50000").Quo(dec("12000"))) {
+		t.Errorf("unexpected BTC exposure %+v", btc)
+	}
+	if !eth.Notional.Equal(dec("48000")) || !eth.UnrealizedPnL.Equal(dec("2000")) || eth.BaseAsset != "ETH" {
+		t.Errorf("unexpected ETH exposure %+v", eth)
+	}
+	if !pe.Equity.Equal(dec("12000")) || !pe.GrossNotional.Equal(dec("98000")) || !pe.NetNotional.Equal(dec("2000")) {
+		t.Errorf("expected 12000 equity, 98000 gross and 2000 net, got %s %s %s", pe.Equity, pe.GrossNotional, pe.NetNotional)
+	}
+	if !pe.Skew.Equal(dec("2000").Quo(dec("98000"))) || !pe.Leverage.Equal(dec("98000").Quo(dec("12000"))) {
+		t.Errorf("unexpected skew %s or leverage %s", pe.Skew, pe.Leverage)
+	}
+	if !pe.AdjustedNotional.Equal(pe.GrossNotional) {
+		t.Errorf("expected no netting across assets without offsets, got %s", pe.AdjustedNotional)
+	}
+
+	// 0.5 of the smaller 48000 nets off both: BTC 26000 long, ETH 24000 short
+	risk := types.DefaultRiskArray()
+	risk.Offsets = []types.CorrelationOffset{{AssetA: "BTC", AssetB: "ETH", Offset: dec("0.5")}}
+	if err := k.SetRiskArray(ctx, risk); err != nil {
+		t.Fatalf("failed to set risk array: %v", err)
+	}
+	pe = k.CalculatePortfolioExposure(ctx, "alice")
+	if !pe.Assets[0].AdjustedNetNotional.Equal(dec("26000")) || !pe.Assets[1].AdjustedNetNotional.Equal(dec("-24000")) {
+		t.Errorf("expected 26000 and -24000 adjusted, got %s %s", pe.Assets[0].AdjustedNetNotional, pe.Assets[1].AdjustedNetNotional)
+	}
+	if !pe.AdjustedNotional.Equal(dec("50000")) || !pe.Assets[0].NetNotional.Equal(dec("50000")) {
+		t.Errorf("expected 50000 adjusted with the raw net kept, got %s", pe.AdjustedNotional)
+	}
+}
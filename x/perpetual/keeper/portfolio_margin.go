@@ -0,0 +1,205 @@
+package keeper
+
+import (
+	"encoding/json"
+	"sort"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// RiskArrayKey holds the portfolio margin risk array
+var RiskArrayKey = []byte{0x14}
+
+// ============ Risk Array ============
+
+// GetRiskArray returns the portfolio margin risk array, or the default one
+// when none has been set
+func (k *Keeper) GetRiskArray(ctx sdk.Context) *types.RiskArray {
+	if risk, found := k.getStoredRiskArray(ctx); found {
+		return risk
+	}
+	return types.DefaultRiskArray()
+}
+
+func (k *Keeper) getStoredRiskArray(ctx sdk.Context) (*types.RiskArray, bool) {
+	bz := k.GetStore(ctx).Get(RiskArrayKey)
+	if bz == nil {
+		return nil, false
+	}
+	var risk types.RiskArray
+	if err := json.Unmarshal(bz, &risk); err != nil {
+		return nil, false
+	}
+	return &risk, true
+}
+
+// SetRiskArray validates and saves the portfolio margin risk array. It
+// applies to every portfolio margin account from the next margin check.
+func (k *Keeper) SetRiskArray(ctx sdk.Context, risk *types.RiskArray) error {
+	if err := risk.Validate(); err != nil {
+		return err
+	}
+	risk.UpdatedAt = ctx.BlockTime()
+	bz, err := json.Marshal(risk)
+	if err != nil {
+		return err
+	}
+	k.GetStore(ctx).Set(RiskArrayKey, bz)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"risk_array_updated",
+			sdk.NewAttribute("default_shock", risk.DefaultShock.String()),
+			sdk.NewAttribute("min_margin_rate", risk.MinMarginRate.String()),
+		),
+	)
+	return nil
+}
+
+// ============ Portfolio Margin Calculations ============
+
+// CalculatePortfolioMargin stresses a trader's open positions with the risk
+// array, whatever the account's margin mode
+func (k *Keeper) CalculatePortfolioMargin(ctx sdk.Context, trader string) *types.PortfolioMargin {
+	account := k.GetAccount(ctx, trader)
+	if account == nil {
+		return nil
+	}
+	return k.portfolioMargin(ctx, account, k.GetPositionsByTrader(ctx, trader))
+}
+
+// PreviewPortfolioMargin returns a trader's portfolio margin as it would be
+// after a trade of size on side in a market, filled at the mark price
+func (k *Keeper) PreviewPortfolioMargin(ctx sdk.Context, trader, marketID string, side types.PositionSide, size math.LegacyDec) (*types.PortfolioMargin, error) {
+	if size.IsNil() || !size.IsPositive() {
+		return nil, types.ErrInvalidQuantity
+	}
+	if k.GetMarket(ctx, marketID) == nil {
+		return nil, types.ErrMarketNotFound
+	}
+	priceInfo := k.GetPrice(ctx, marketID)
+	if priceInfo == nil {
+		return nil, types.ErrInvalidPrice
+	}
+
+	account := k.GetAccount(ctx, trader)
+	if account == nil {
+		account = types.NewAccount(trader)
+	}
+	// A fill at the mark has no unrealized PnL, so the trade only adds to the
+	// market's net size
+	trade := types.NewPosition(trader, marketID, side, size, priceInfo.MarkPrice, math.LegacyZeroDec())
+	positions := append(k.GetPositionsByTrader(ctx, trader), trade)
+	return k.portfolioMargin(ctx, account, positions), nil
+}
+
+// portfolioMargin nets positions by market and base asset, stresses each
+// asset by its shock and credits the risk array's correlation offsets
+func (k *Keeper) portfolioMargin(ctx sdk.Context, account *types.Account, positions []*types.Position) *types.PortfolioMargin {
+	risk := k.GetRiskArray(ctx)
+	pm := &types.PortfolioMargin{
+		Trader:         account.Trader,
+		Mode:           account.MarginMode,
+		Equity:         account.Balance,
+		Assets:         make([]*types.AssetRisk, 0),
+		GrossNotional:  math.LegacyZeroDec(),
+		ScanRisk:       math.LegacyZeroDec(),
+		OffsetCredit:   math.LegacyZeroDec(),
+		StandardMargin: math.LegacyZeroDec(),
+	}
+
+	// Net size per market, long positive
+	netSize := make(map[string]math.LegacyDec)
+	for _, pos := range positions {
+		priceInfo := k.GetPrice(ctx, pos.MarketID)
+		if priceInfo == nil {
+			continue
+		}
+		pm.Equity = pm.Equity.Add(pos.CalculateUnrealizedPnL(priceInfo.MarkPrice))
+		size := pos.Size
+		if pos.Side == types.PositionSideShort {
+			size = size.Neg()
+		}
+		if net, ok := netSize[pos.MarketID]; ok {
+			size = size.Add(net)
+		}
+		netSize[pos.MarketID] = size
+	}
+	marketIDs := make([]string, 0, len(netSize))
+	for marketID := range netSize {
+		marketIDs = append(marketIDs, marketID)
+	}
+	sort.Strings(marketIDs)
+
+	assets := make(map[string]*types.AssetRisk)
+	for _, marketID := range marketIDs {
+		market := k.GetMarket(ctx, marketID)
+		if market == nil {
+			continue
+		}
+		notional := netSize[marketID].Mul(k.GetPrice(ctx, marketID).MarkPrice)
+		asset, ok := assets[market.BaseAsset]
+		if !ok {
+			asset = &types.AssetRisk{
+				Asset:         market.BaseAsset,
+				Shock:         risk.ShockFor(market.BaseAsset),
+				NetNotional:   math.LegacyZeroDec(),
+				GrossNotional: math.LegacyZeroDec(),
+			}
+			assets[market.BaseAsset] = asset
+			pm.Assets = append(pm.Assets, asset)
+		}
+		asset.NetNotional = asset.NetNotional.Add(notional)
+		asset.GrossNotional = asset.GrossNotional.Add(notional.Abs())
+		pm.GrossNotional = pm.GrossNotional.Add(notional.Abs())
+		pm.StandardMargin = pm.StandardMargin.Add(notional.Abs().Mul(market.InitialMarginRate))
+	}
+	sort.Slice(pm.Assets, func(i, j int) bool { return pm.Assets[i].Asset < pm.Assets[j].Asset })
+
+	remaining := make(map[string]math.LegacyDec, len(pm.Assets))
+	for _, asset := range pm.Assets {
+		asset.LossUp = math.LegacyMaxDec(asset.NetNotional.Neg().Mul(asset.Shock), math.LegacyZeroDec())
+		asset.LossDown = math.LegacyMaxDec(asset.NetNotional.Mul(asset.Shock), math.LegacyZeroDec())
+		asset.ScanRisk = math.LegacyMaxDec(asset.LossUp, asset.LossDown)
+		pm.ScanRisk = pm.ScanRisk.Add(asset.ScanRisk)
+		remaining[asset.Asset] = asset.ScanRisk
+	}
+
+	// A hedge loses on one asset as the other gains: opposite net notionals
+	for _, offset := range risk.Offsets {
+		a, b := assets[offset.AssetA], assets[offset.AssetB]
+		if a == nil || b == nil || !a.NetNotional.Mul(b.NetNotional).IsNegative() {
+			continue
+		}
+		credit := math.LegacyMinDec(remaining[a.Asset], remaining[b.Asset]).Mul(offset.Offset)
+		remaining[a.Asset] = remaining[a.Asset].Sub(credit)
+		remaining[b.Asset] = remaining[b.Asset].Sub(credit)
+		pm.OffsetCredit = pm.OffsetCredit.Add(credit.MulInt64(2))
+	}
+
+	pm.FloorMargin = pm.GrossNotional.Mul(risk.MinMarginRate)
+	pm.InitialMargin = math.LegacyMaxDec(pm.ScanRisk.Sub(pm.OffsetCredit), pm.FloorMargin)
+	pm.MaintenanceMargin = pm.InitialMargin.Mul(risk.MaintenanceFraction)
+	pm.AvailableMargin = math.LegacyMaxDec(pm.Equity.Sub(pm.InitialMargin), math.LegacyZeroDec())
+	pm.IsHealthy = pm.Equity.GTE(pm.MaintenanceMargin)
+	return pm
+}
+
+// checkPortfolioMarginRequirement accepts a trade if the account's equity
+// covers the portfolio initial margin after it, or if the trade lowers the
+// requirement, so hedges and reductions are never refused
+func (k *Keeper) checkPortfolioMarginRequirement(ctx sdk.Context, trader, marketID string, side types.PositionSide, quantity math.LegacyDec) error {
+	after, err := k.PreviewPortfolioMargin(ctx, trader, marketID, side, quantity)
+	if err != nil {
+		return err
+	}
+	if after.Equity.GTE(after.InitialMargin) {
+		return nil
+	}
+	if before := k.CalculatePortfolioMargin(ctx, trader); before != nil && after.InitialMargin.LT(before.InitialMargin) {
+		return nil
+	}
+	return types.ErrInsufficientMargin
+}
@@ -0,0 +1,139 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// setupPortfolioBook adds an ETH market at 2500 and opens alice's long 1 BTC
+// and short 20 ETH, 50000 of notional each
+func setupPortfolioBook(t *testing.T, k *Keeper, ctx sdk.Context, deposit string) {
+	t.Helper()
+	k.SetMarket(ctx, types.NewMarket("ETH-USDC", "ETH", "USDC"))
+	k.SetPrice(ctx, types.NewPriceInfo("ETH-USDC", dec("2500")))
+	// Set the balance outright: a new account starts with a testing balance
+	account := k.GetOrCreateAccount(ctx, "alice")
+	account.Balance = dec(deposit)
+	k.SetAccount(ctx, account)
+	k.SetPosition(ctx, types.NewPosition("alice", "BTC-USDC", types.PositionSideLong, dec("1"), dec("50000"), dec("2500")))
+	k.SetPosition(ctx, types.NewPosition("alice", "ETH-USDC", types.PositionSideShort, dec("20"), dec("2500"), dec("2500")))
+}
+
+// TestPortfolioMarginOffsets tests that each asset is stressed by its shock,
+// that correlation offsets only credit hedged assets, and that the risk
+// array survives an export and import
+func TestPortfolioMarginOffsets(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	setupPortfolioBook(t, k, ctx, "10000")
+
+	// With the default ±5% shocks and no offsets, a lone asset needs the
+	// per-position initial margin
+	pm := k.CalculatePortfolioMargin(ctx, "alice")
+	if len(pm.Assets) != 2 || !pm.Assets[0].LossDown.Equal(dec("2500")) || !pm.Assets[1].LossUp.Equal(dec("2500")) {
+		t.Fatalf("expected BTC to lose falling and ETH rising, got %+v %+v", pm.Assets[0], pm.Assets[1])
+	}
+	if !pm.InitialMargin.Equal(dec("5000")) || !pm.InitialMargin.Equal(pm.StandardMargin) {
+		t.Errorf("expected 5000 initial margin without offsets, got %s (standard %s)", pm.InitialMargin, pm.StandardMargin)
+	}
+
+	risk := types.DefaultRiskArray()
+	risk.Offsets = []types.CorrelationOffset{{AssetA: "BTC", AssetB: "ETH", Offset: dec("1.5")}}
+	if err := k.SetRiskArray(ctx, risk); !errors.Is(err, types.ErrInvalidRiskArray) {
+		t.Fatalf("expected ErrInvalidRiskArray, got %v", err)
+	}
+	risk.Offsets[0].Offset = dec("0.7")
+	risk.Shocks = []types.AssetShock{{Asset: "ETH", Shock: dec("0.08")}}
+	if err := k.SetRiskArray(ctx, risk); err != nil {
+		t.Fatalf("failed to set risk array: %v", err)
+	}
+
+	// 0.7 of the smaller scan risk, BTC's 2500, comes off both assets:
+	// 2500 + 4000 - 2 × 1750 = 3000
+	pm = k.CalculatePortfolioMargin(ctx, "alice")
+	if !pm.ScanRisk.Equal(dec("6500")) || !pm.OffsetCredit.Equal(dec("3500")) || !pm.InitialMargin.Equal(dec("3000")) {
+		t.Errorf("expected 6500 scan risk less 3500 offset, got %s - %s = %s", pm.ScanRisk, pm.OffsetCredit, pm.InitialMargin)
+	}
+	if !pm.MaintenanceMargin.Equal(dec("1500")) || !pm.AvailableMargin.Equal(dec("7000")) || !pm.IsHealthy {
+		t.Errorf("unexpected maintenance %s or available %s", pm.MaintenanceMargin, pm.AvailableMargin)
+	}
+
+	// Buying 40 ETH flips it long: nothing left to offset
+	preview, err := k.PreviewPortfolioMargin(ctx, "alice", "ETH-USDC", types.PositionSideLong, dec("40"))
+	if err != nil {
+		t.Fatalf("failed to preview: %v", err)
+	}
+	if !preview.OffsetCredit.IsZero() || !preview.InitialMargin.Equal(dec("6500")) {
+		t.Errorf("expected no offset for an unhedged book, got %s of %s", preview.OffsetCredit, preview.InitialMargin)
+	}
+	if _, err := k.PreviewPortfolioMargin(ctx, "alice", "SOL-USDC", types.PositionSideLong, dec("1")); !errors.Is(err, types.ErrMarketNotFound) {
+		t.Errorf("expected ErrMarketNotFound, got %v", err)
+	}
+
+	exported := k.ExportGenesis(ctx)
+	if exported.RiskArray == nil || len(exported.RiskArray.Offsets) != 1 {
+		t.Fatalf("expected the risk array to be exported, got %+v", exported.RiskArray)
+	}
+	imported, ctx2 := setupFundingKeeper(t)
+	if err := imported.InitGenesis(ctx2, exported); err != nil {
+		t.Fatalf("failed to import genesis: %v", err)
+	}
+	if got := imported.GetRiskArray(ctx2); !got.ShockFor("ETH").Equal(dec("0.08")) || !got.ShockFor("BTC").Equal(dec("0.05")) {
+		t.Errorf("expected the imported shocks, got %+v", got)
+	}
+	exported.RiskArray.MaintenanceFraction = dec("0")
+	if err := exported.Validate(); !errors.Is(err, types.ErrInvalidGenesis) {
+		t.Errorf("expected ErrInvalidGenesis for an invalid risk array, got %v", err)
+	}
+}
+
+// TestPortfolioMarginMode tests that a portfolio margin account is checked
+// and liquidated against its stressed book rather than per position
+func TestPortfolioMarginMode(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	if err := k.SetMarginMode(ctx, "alice", types.MarginModePortfolio); err != nil {
+		t.Fatalf("failed to set margin mode: %v", err)
+	}
+	risk := types.DefaultRiskArray()
+	risk.Offsets = []types.CorrelationOffset{{AssetA: "BTC", AssetB: "ETH", Offset: dec("0.7")}}
+	if err := k.SetRiskArray(ctx, risk); err != nil {
+		t.Fatalf("failed to set risk array: %v", err)
+	}
+	setupPortfolioBook(t, k, ctx, "2000")
+
+	// 2500 + 2500 - 2 × 1750 = 1500 needed, where positions alone need 5000
+	if free := k.GetFreeCollateral(ctx, "alice"); !free.Equal(dec("500")) {
+		t.Errorf("expected 500 free collateral, got %s", free)
+	}
+	if err := k.CheckMarginRequirement(ctx, "alice", "BTC-USDC", types.PositionSideLong, dec("0.1"), dec("50000")); err != nil {
+		t.Errorf("expected a small hedged trade to pass, got %v", err)
+	}
+	if err := k.CheckMarginRequirement(ctx, "alice", "BTC-USDC", types.PositionSideLong, dec("1"), dec("50000")); !errors.Is(err, types.ErrInsufficientMargin) {
+		t.Errorf("expected ErrInsufficientMargin, got %v", err)
+	}
+	if liquidate, _ := k.CheckLiquidation(ctx, "alice", "BTC-USDC"); liquidate {
+		t.Error("expected a healthy account")
+	}
+
+	// BTC falls 4%: equity 0 is below the 770 maintenance margin
+	price := k.GetPrice(ctx, "BTC-USDC")
+	price.MarkPrice = dec("48000")
+	k.SetPrice(ctx, price)
+	if summary := k.GetMarginSummary(ctx, "alice"); summary.Mode != types.MarginModePortfolio || summary.IsHealthy || !summary.TotalEquity.IsZero() {
+		t.Errorf("expected an unhealthy portfolio summary, got %+v", summary)
+	}
+	liquidate, position := k.CheckLiquidation(ctx, "alice", "BTC-USDC")
+	if !liquidate || position == nil || position.MarketID != "ETH-USDC" {
+		t.Errorf("expected the largest position, ETH, to be liquidated, got %v %+v", liquidate, position)
+	}
+	// A trade that tightens the hedge lowers the requirement and is still
+	// accepted: 2496 + 2500 - 2 × 1747.2 = 1501.6, down from 1540
+	if err := k.CheckMarginRequirement(ctx, "alice", "BTC-USDC", types.PositionSideLong, dec("0.04"), dec("48000")); err != nil {
+		t.Errorf("expected a hedging trade to pass, got %v", err)
+	}
+	if err := k.CheckMarginRequirement(ctx, "alice", "BTC-USDC", types.PositionSideShort, dec("0.5"), dec("48000")); !errors.Is(err, types.ErrInsufficientMargin) {
+		t.Errorf("expected unwinding the hedge to be refused, got %v", err)
+	}
+}
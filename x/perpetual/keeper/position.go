@@ -20,7 +20,8 @@ func NewPositionManager(keeper *Keeper) *PositionManager {
 	}
 }
 
-// OpenPosition opens a new position or adds to an existing one
+// OpenPosition opens a new position or adds to an existing one. An
+// opposite-side order is netted against the existing position as in ApplyFill.
 func (pm *PositionManager) OpenPosition(
 	ctx sdk.Context,
 	trader string,
@@ -28,6 +29,74 @@ func (pm *PositionManager) OpenPosition(
 	side types.PositionSide,
 	size math.LegacyDec,
 	entryPrice math.LegacyDec,
+) (*types.Position, error) {
+	position, _, err := pm.ApplyFill(ctx, trader, marketID, side, size, entryPrice)
+	return position, err
+}
+
+// ApplyFill nets a fill of size at price into the trader's position:
+//   - no position or same side: open or increase it
+//   - opposite side, smaller: reduce it
+//   - opposite side, equal: close it
+//   - opposite side, larger: close it and open the remainder on side (flip)
+//
+// The closed portion realizes PnL at the fill price, and only the flipped
+// remainder needs initial margin. A flip is atomic: if the remainder fails
+// the margin check the close is discarded too.
+// Returns the resulting position (nil when flat) and the realized PnL.
+func (pm *PositionManager) ApplyFill(
+	ctx sdk.Context,
+	trader string,
+	marketID string,
+	side types.PositionSide,
+	size math.LegacyDec,
+	price math.LegacyDec,
+) (*types.Position, math.LegacyDec, error) {
+	if size.IsNil() || !size.IsPositive() {
+		return nil, math.LegacyDec{}, types.ErrInvalidQuantity
+	}
+
+	existingPosition := pm.keeper.GetPosition(ctx, trader, marketID)
+	if existingPosition == nil || existingPosition.Side == side {
+		position, err := pm.increasePosition(ctx, trader, marketID, side, size, price)
+		if err != nil {
+			return nil, math.LegacyDec{}, err
+		}
+		return position, math.LegacyZeroDec(), nil
+	}
+
+	switch {
+	case size.LT(existingPosition.Size):
+		return pm.ReducePositionAt(ctx, trader, marketID, size, price)
+
+	case size.Equal(existingPosition.Size):
+		realizedPnL, err := pm.ClosePosition(ctx, trader, marketID, price)
+		return nil, realizedPnL, err
+
+	default:
+		cacheCtx, write := ctx.CacheContext()
+		realizedPnL, err := pm.ClosePosition(cacheCtx, trader, marketID, price)
+		if err != nil {
+			return nil, math.LegacyDec{}, err
+		}
+		position, err := pm.increasePosition(cacheCtx, trader, marketID, side, size.Sub(existingPosition.Size), price)
+		if err != nil {
+			return nil, math.LegacyDec{}, err
+		}
+		write()
+		return position, realizedPnL, nil
+	}
+}
+
+// increasePosition opens a new position or adds to a same-side one, locking
+// initial margin for size
+func (pm *PositionManager) increasePosition(
+	ctx sdk.Context,
+	trader string,
+	marketID string,
+	side types.PositionSide,
+	size math.LegacyDec,
+	entryPrice math.LegacyDec,
 ) (*types.Position, error) {
 	// Validate market
 	market := pm.keeper.GetMarket(ctx, marketID)
@@ -38,11 +107,6 @@ func (pm *PositionManager) OpenPosition(
 		return nil, types.ErrMarketNotActive
 	}
 
-	// Validate size
-	if size.IsZero() || size.IsNegative() {
-		return nil, types.ErrInvalidQuantity
-	}
-
 	// Check initial margin requirement
 	if err := pm.marginChecker.CheckInitialMarginRequirement(ctx, trader, marketID, size, entryPrice); err != nil {
 		return nil, err
@@ -51,38 +115,19 @@ func (pm *PositionManager) OpenPosition(
 	// Calculate required margin
 	requiredMargin := pm.marginChecker.CalculateInitialMargin(size, entryPrice)
 
-	// Get existing position
-	existingPosition := pm.keeper.GetPosition(ctx, trader, marketID)
-
-	// Get account
-	account := pm.keeper.GetOrCreateAccount(ctx, trader)
-
 	var position *types.Position
-
-	if existingPosition == nil {
+	if existingPosition := pm.keeper.GetPosition(ctx, trader, marketID); existingPosition == nil {
 		// Create new position
 		position = types.NewPosition(trader, marketID, side, size, entryPrice, requiredMargin)
-	} else if existingPosition.Side == side {
+	} else {
 		// Add to existing position (same side)
 		existingPosition.AddSize(size, entryPrice)
 		existingPosition.Margin = existingPosition.Margin.Add(requiredMargin)
 		position = existingPosition
-	} else {
-		// Opposite side - reduce or flip position
-		if size.LTE(existingPosition.Size) {
-			// Reduce position
-			_, _, _ = pm.ReducePosition(ctx, trader, marketID, size)
-			return pm.keeper.GetPosition(ctx, trader, marketID), nil
-		} else {
-			// Close existing and open opposite
-			_, _ = pm.ClosePosition(ctx, trader, marketID, entryPrice)
-			remainingSize := size.Sub(existingPosition.Size)
-			remainingMargin := pm.marginChecker.CalculateInitialMargin(remainingSize, entryPrice)
-			position = types.NewPosition(trader, marketID, side, remainingSize, entryPrice, remainingMargin)
-		}
 	}
 
 	// Lock margin
+	account := pm.keeper.GetOrCreateAccount(ctx, trader)
 	account.LockMargin(requiredMargin)
 	pm.keeper.SetAccount(ctx, account)
 
@@ -105,12 +150,29 @@ func (pm *PositionManager) OpenPosition(
 	return position, nil
 }
 
-// ReducePosition reduces the size of an existing position
+// ReducePosition reduces the size of an existing position at the mark price
 func (pm *PositionManager) ReducePosition(
 	ctx sdk.Context,
 	trader string,
 	marketID string,
 	reduceSize math.LegacyDec,
+) (*types.Position, math.LegacyDec, error) {
+	// Get current price for PnL calculation
+	priceInfo := pm.keeper.GetPrice(ctx, marketID)
+	if priceInfo == nil {
+		return nil, math.LegacyDec{}, types.ErrMarketNotFound
+	}
+	return pm.ReducePositionAt(ctx, trader, marketID, reduceSize, priceInfo.MarkPrice)
+}
+
+// ReducePositionAt reduces the size of an existing position at closePrice,
+// releasing margin in proportion
+func (pm *PositionManager) ReducePositionAt(
+	ctx sdk.Context,
+	trader string,
+	marketID string,
+	reduceSize math.LegacyDec,
+	closePrice math.LegacyDec,
 ) (*types.Position, math.LegacyDec, error) {
 	position := pm.keeper.GetPosition(ctx, trader, marketID)
 	if position == nil {
@@ -121,13 +183,6 @@ func (pm *PositionManager) ReducePosition(
 		return nil, math.LegacyDec{}, types.ErrCannotReducePosition
 	}
 
-	// Get current price for PnL calculation
-	priceInfo := pm.keeper.GetPrice(ctx, marketID)
-	if priceInfo == nil {
-		return nil, math.LegacyDec{}, types.ErrMarketNotFound
-	}
-	closePrice := priceInfo.MarkPrice
-
 	// Calculate realized PnL for the reduced portion
 	priceDiff := closePrice.Sub(position.EntryPrice)
 	if position.Side == types.PositionSideShort {
@@ -139,6 +194,7 @@ func (pm *PositionManager) ReducePosition(
 	releasedMargin := position.Margin.Mul(reduceSize).Quo(position.Size)
 
 	// Update position
+	position.RecordClose(reduceSize, closePrice, realizedPnL)
 	position.ReduceSize(reduceSize)
 	position.Margin = position.Margin.Sub(releasedMargin)
 
@@ -150,6 +206,7 @@ func (pm *PositionManager) ReducePosition(
 
 	// Save or delete position
 	if position.Size.IsZero() {
+		pm.keeper.RecordClosedPosition(ctx, position, types.CloseReasonClosed)
 		pm.keeper.DeletePosition(ctx, trader, marketID)
 		position = nil
 	} else {
@@ -196,7 +253,9 @@ func (pm *PositionManager) ClosePosition(
 	account.Balance = account.Balance.Add(realizedPnL)
 	pm.keeper.SetAccount(ctx, account)
 
-	// Delete position
+	// Record history and delete position
+	position.RecordClose(position.Size, closePrice, realizedPnL)
+	pm.keeper.RecordClosedPosition(ctx, position, types.CloseReasonClosed)
 	pm.keeper.DeletePosition(ctx, trader, marketID)
 
 	// Emit event
@@ -237,30 +296,28 @@ func (pm *PositionManager) UpdatePositionFromTrade(
 	// Check for existing position
 	existingPosition := pm.keeper.GetPosition(ctx, trader, marketID)
 
-	if existingPosition == nil || existingPosition.Side == side {
-		// Open or add to position
-		_, err := pm.OpenPosition(ctx, trader, marketID, side, size, price)
-		if err != nil {
-			return err
+	// Attribute the fee to the positions the trade closes and opens, so a
+	// closed-position record includes its closing fee
+	openingFee := fee
+	if existingPosition != nil && existingPosition.Side != side {
+		closingFee := fee
+		if size.GT(existingPosition.Size) {
+			closingFee = fee.Mul(existingPosition.Size).Quo(size)
 		}
-	} else {
-		// Opposite trade - reduce or close position
-		if size.LTE(existingPosition.Size) {
-			_, _, err := pm.ReducePosition(ctx, trader, marketID, size)
-			if err != nil {
-				return err
-			}
-		} else {
-			// Close and open opposite
-			_, err := pm.ClosePosition(ctx, trader, marketID, price)
-			if err != nil {
-				return err
-			}
-			remainingSize := size.Sub(existingPosition.Size)
-			_, err = pm.OpenPosition(ctx, trader, marketID, side, remainingSize, price)
-			if err != nil {
-				return err
-			}
+		existingPosition.AddFee(closingFee)
+		pm.keeper.SetPosition(ctx, existingPosition)
+		openingFee = fee.Sub(closingFee)
+	}
+
+	// Open, increase, reduce, close or flip the position
+	if _, _, err := pm.ApplyFill(ctx, trader, marketID, side, size, price); err != nil {
+		return err
+	}
+
+	if !openingFee.IsZero() {
+		if position := pm.keeper.GetPosition(ctx, trader, marketID); position != nil {
+			position.AddFee(openingFee)
+			pm.keeper.SetPosition(ctx, position)
 		}
 	}
 
@@ -289,6 +346,10 @@ func (pm *PositionManager) UpdatePositionFromTrade(
 			account.Balance = account.Balance.Sub(fee)
 			pm.keeper.SetAccount(ctx, account)
 		}
+	} else if account != nil && fee.IsNegative() {
+		// A negative fee is a maker rebate, credited in full
+		account.Balance = account.Balance.Sub(fee)
+		pm.keeper.SetAccount(ctx, account)
 	}
 
 	return nil
@@ -0,0 +1,144 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// Store key prefixes for closed position history
+var (
+	ClosedPositionKeyPrefix  = []byte{0x0A}
+	ClosedPositionCounterKey = []byte{0x0B}
+)
+
+// closedPositionKey orders a trader's history by sequence so reverse iteration returns newest first
+func closedPositionKey(trader string, seq uint64) []byte {
+	key := append(closedPositionTraderPrefix(trader), make([]byte, 8)...)
+	binary.BigEndian.PutUint64(key[len(key)-8:], seq)
+	return key
+}
+
+func closedPositionTraderPrefix(trader string) []byte {
+	prefix := make([]byte, 0, len(ClosedPositionKeyPrefix)+len(trader)+1)
+	prefix = append(prefix, ClosedPositionKeyPrefix...)
+	return append(prefix, []byte(trader+":")...)
+}
+
+// RecordClosedPosition persists the history record of a position that reached zero size.
+// Callers must have applied the final reduction with Position.RecordClose.
+func (k *Keeper) RecordClosedPosition(ctx sdk.Context, position *types.Position, reason types.CloseReason) *types.ClosedPosition {
+	store := k.GetStore(ctx)
+
+	var seq uint64
+	if bz := store.Get(ClosedPositionCounterKey); bz != nil {
+		seq = binary.BigEndian.Uint64(bz)
+	}
+	seq++
+	seqBz := make([]byte, 8)
+	binary.BigEndian.PutUint64(seqBz, seq)
+	store.Set(ClosedPositionCounterKey, seqBz)
+
+	closed := types.NewClosedPosition(types.ClosedPositionID(seq), position, reason, ctx.BlockTime())
+	k.setClosedPosition(ctx, seq, closed)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"position_closed",
+			sdk.NewAttribute("position_id", closed.PositionID),
+			sdk.NewAttribute("trader", closed.Trader),
+			sdk.NewAttribute("market_id", closed.MarketID),
+			sdk.NewAttribute("reason", string(reason)),
+			sdk.NewAttribute("net_pnl", closed.NetPnL.String()),
+		),
+	)
+
+	return closed
+}
+
+// GetClosedPositions returns a page of a trader's closed positions, newest first,
+// optionally filtered by market, and the cursor of the next page, empty on the
+// last page. The cursor is the ID of the last position returned: the scan
+// starts just below it and stops once the page is full, so a page costs the
+// records it passes over rather than the trader's whole history.
+func (k *Keeper) GetClosedPositions(ctx sdk.Context, trader, marketID, cursor string, limit int) ([]*types.ClosedPosition, string, error) {
+	prefix := closedPositionTraderPrefix(trader)
+	end := storetypes.PrefixEndBytes(prefix)
+	if cursor != "" {
+		seq, err := types.ParseClosedPositionID(cursor)
+		if err != nil {
+			return nil, "", types.ErrInvalidCursor
+		}
+		end = closedPositionKey(trader, seq)
+	}
+
+	page := make([]*types.ClosedPosition, 0)
+	if limit <= 0 {
+		return page, "", nil
+	}
+
+	iterator := k.GetStore(ctx).ReverseIterator(prefix, end)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var closed types.ClosedPosition
+		if err := json.Unmarshal(iterator.Value(), &closed); err != nil {
+			continue
+		}
+		if marketID != "" && closed.MarketID != marketID {
+			continue
+		}
+		// One more match means there is a next page
+		if len(page) == limit {
+			return page, page[limit-1].PositionID, nil
+		}
+		page = append(page, &closed)
+	}
+	return page, "", nil
+}
+
+func (k *Keeper) setClosedPosition(ctx sdk.Context, seq uint64, closed *types.ClosedPosition) {
+	bz, _ := json.Marshal(closed)
+	k.GetStore(ctx).Set(closedPositionKey(closed.Trader, seq), bz)
+}
+
+// exportClosedPositions returns every closed position record and the number
+// of positions closed so far
+func (k *Keeper) exportClosedPositions(ctx sdk.Context) ([]*types.ClosedPosition, uint64) {
+	store := k.GetStore(ctx)
+	var count uint64
+	if bz := store.Get(ClosedPositionCounterKey); bz != nil {
+		count = binary.BigEndian.Uint64(bz)
+	}
+
+	iterator := storetypes.KVStorePrefixIterator(store, ClosedPositionKeyPrefix)
+	defer iterator.Close()
+
+	records := make([]*types.ClosedPosition, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var closed types.ClosedPosition
+		if err := json.Unmarshal(iterator.Value(), &closed); err == nil {
+			records = append(records, &closed)
+		}
+	}
+	return records, count
+}
+
+// importClosedPositions restores closed position records under their
+// original sequence numbers and the counter that numbers the next one
+func (k *Keeper) importClosedPositions(ctx sdk.Context, records []*types.ClosedPosition, count uint64) error {
+	for _, closed := range records {
+		seq, err := types.ParseClosedPositionID(closed.PositionID)
+		if err != nil {
+			return err
+		}
+		k.setClosedPosition(ctx, seq, closed)
+	}
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, count)
+	k.GetStore(ctx).Set(ClosedPositionCounterKey, bz)
+	return nil
+}
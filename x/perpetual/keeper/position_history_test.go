@@ -0,0 +1,144 @@
+package keeper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TestClosedPositionGenesis tests that closed position history survives an
+// export and import, and that positions closed afterwards continue its
+// numbering
+func TestClosedPositionGenesis(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	for _, trader := range []string{"alice", "bob", "alice"} {
+		position := types.NewPosition(trader, "BTC-USDC", types.PositionSideLong, dec("1"), dec("50000"), dec("2500"))
+		position.RecordClose(dec("1"), dec("51000"), dec("1000"))
+		k.RecordClosedPosition(ctx, position, types.CloseReasonClosed)
+	}
+
+	exported := k.ExportGenesis(ctx)
+	if len(exported.ClosedPositions) != 3 || exported.ClosedPositionCount != 3 {
+		t.Fatalf("expected 3 closed positions exported, got %d (count %d)", len(exported.ClosedPositions), exported.ClosedPositionCount)
+	}
+
+	k2, ctx2 := setupFundingKeeper(t)
+	if err := k2.InitGenesis(ctx2, exported); err != nil {
+		t.Fatalf("failed to import genesis: %v", err)
+	}
+	page, next, err := k2.GetClosedPositions(ctx2, "alice", "", "", 10)
+	if err != nil || len(page) != 2 || page[0].PositionID != "pos-3" || page[1].PositionID != "pos-1" || next != "" {
+		t.Fatalf("expected alice's pos-3 and pos-1, got %+v (next %q, %v)", page, next, err)
+	}
+
+	position := types.NewPosition("bob", "BTC-USDC", types.PositionSideShort, dec("1"), dec("50000"), dec("2500"))
+	position.RecordClose(dec("1"), dec("49000"), dec("1000"))
+	if closed := k2.RecordClosedPosition(ctx2, position, types.CloseReasonClosed); closed.PositionID != "pos-4" {
+		t.Errorf("expected the next position numbered pos-4, got %s", closed.PositionID)
+	}
+
+	exported.ClosedPositionCount = 2
+	if err := exported.Validate(); !errors.Is(err, types.ErrInvalidGenesis) {
+		t.Errorf("expected a record beyond the count rejected, got %v", err)
+	}
+}
+
+// TestRecordClosedPosition tests that a closed position is recorded with its
+// realized PnL, fees and close time, and announced with an event
+func TestRecordClosedPosition(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	closedAt := time.Unix(1700000000, 0).UTC()
+	ctx = ctx.WithBlockTime(closedAt)
+
+	position := types.NewPosition("alice", "BTC-USDC", types.PositionSideShort, dec("2"), dec("50000"), dec("5000"))
+	position.RecordClose(dec("2"), dec("49000"), dec("2000"))
+	position.AddFee(dec("30"))
+	closed := k.RecordClosedPosition(ctx, position, types.CloseReasonLiquidation)
+
+	if closed.PositionID != "pos-1" || closed.Reason != types.CloseReasonLiquidation || !closed.ClosedAt.Equal(closedAt) {
+		t.Errorf("unexpected record %+v", closed)
+	}
+	if !closed.Size.Equal(dec("2")) || !closed.ExitPrice.Equal(dec("49000")) || !closed.NetPnL.Equal(dec("1970")) {
+		t.Errorf("expected 2 closed at 49000 for 1970 net, got %s at %s for %s", closed.Size, closed.ExitPrice, closed.NetPnL)
+	}
+
+	page, _, err := k.GetClosedPositions(ctx, "alice", "", "", 10)
+	if err != nil || len(page) != 1 || page[0].PositionID != "pos-1" || !page[0].NetPnL.Equal(closed.NetPnL) || !page[0].ClosedAt.Equal(closedAt) {
+		t.Errorf("expected the stored record to match, got %+v, %v", page, err)
+	}
+
+	events := ctx.EventManager().Events()
+	if len(events) != 1 || events[0].Type != "position_closed" {
+		t.Fatalf("expected a position_closed event, got %v", events)
+	}
+	if reason, ok := events[0].GetAttribute("reason"); !ok || reason.Value != string(types.CloseReasonLiquidation) {
+		t.Errorf("expected reason liquidation, got %v", reason)
+	}
+}
+
+// TestClosedPositionPagination tests that a trader's history pages newest
+// first by cursor, that the market filter applies within the pages, and that
+// other traders' records never show up
+func TestClosedPositionPagination(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	// alice closes pos-1 to pos-7, alternating markets; bob's pos-8 sits after hers
+	for i, market := range []string{"BTC-USDC", "ETH-USDC", "BTC-USDC", "ETH-USDC", "BTC-USDC", "ETH-USDC", "BTC-USDC"} {
+		position := types.NewPosition("alice", market, types.PositionSideLong, dec("1"), dec("100"), dec("10"))
+		position.RecordClose(dec("1"), dec("101"), dec(fmt.Sprint(i)))
+		k.RecordClosedPosition(ctx, position, types.CloseReasonClosed)
+	}
+	position := types.NewPosition("bob", "BTC-USDC", types.PositionSideLong, dec("1"), dec("100"), dec("10"))
+	position.RecordClose(dec("1"), dec("101"), dec("1"))
+	k.RecordClosedPosition(ctx, position, types.CloseReasonClosed)
+
+	pages := func(marketID string, limit int) [][]string {
+		var (
+			ids    [][]string
+			cursor string
+		)
+		for {
+			page, next, err := k.GetClosedPositions(ctx, "alice", marketID, cursor, limit)
+			if err != nil {
+				t.Fatalf("failed to get page after %q: %v", cursor, err)
+			}
+			var pageIDs []string
+			for _, closed := range page {
+				pageIDs = append(pageIDs, closed.PositionID)
+			}
+			ids = append(ids, pageIDs)
+			if next == "" {
+				return ids
+			}
+			cursor = next
+		}
+	}
+
+	expected := [][]string{{"pos-7", "pos-6", "pos-5"}, {"pos-4", "pos-3", "pos-2"}, {"pos-1"}}
+	if got := pages("", 3); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected pages %v, got %v", expected, got)
+	}
+	// A full last page has no next cursor
+	expected = [][]string{{"pos-7", "pos-5"}, {"pos-3", "pos-1"}}
+	if got := pages("BTC-USDC", 2); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected BTC-USDC pages %v, got %v", expected, got)
+	}
+
+	// A cursor resumes below its position even if it is in another market
+	page, next, err := k.GetClosedPositions(ctx, "alice", "ETH-USDC", "pos-5", 10)
+	if err != nil || len(page) != 2 || page[0].PositionID != "pos-4" || page[1].PositionID != "pos-2" || next != "" {
+		t.Errorf("expected pos-4 and pos-2, got %+v (next %q, %v)", page, next, err)
+	}
+
+	if page, _, _ := k.GetClosedPositions(ctx, "carol", "", "", 10); len(page) != 0 {
+		t.Errorf("expected no history for carol, got %+v", page)
+	}
+	for _, cursor := range []string{"7", "pos-0", "pos-x"} {
+		if _, _, err := k.GetClosedPositions(ctx, "alice", "", cursor, 10); !errors.Is(err, types.ErrInvalidCursor) {
+			t.Errorf("expected ErrInvalidCursor for %q, got %v", cursor, err)
+		}
+	}
+}
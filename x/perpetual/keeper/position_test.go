@@ -0,0 +1,258 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+const positionTrader = "cosmos1trader"
+
+// setupPositionManager returns a position manager over the default market
+// and a trader funded with balance
+func setupPositionManager(t *testing.T, balance int64) (*PositionManager, *Keeper, sdk.Context) {
+	t.Helper()
+
+	k, ctx := setupFundingKeeper(t)
+	account := types.NewAccount(positionTrader)
+	account.Deposit(math.LegacyNewDec(balance))
+	k.SetAccount(ctx, account)
+	return NewPositionManager(k), k, ctx
+}
+
+func dec(s string) math.LegacyDec {
+	return math.LegacyMustNewDecFromStr(s)
+}
+
+// TestPositionNetting tests every transition of a fill against an existing
+// position: open, increase, reduce, close and flip
+func TestPositionNetting(t *testing.T) {
+	long, short := types.PositionSideLong, types.PositionSideShort
+
+	tests := []struct {
+		name  string
+		fills []struct {
+			side  types.PositionSide
+			size  string
+			price string
+		}
+		wantSide    types.PositionSide
+		wantSize    string // empty: flat
+		wantEntry   string
+		wantMargin  string
+		wantPnL     string // realized PnL of the last fill
+		wantBalance string
+		wantLocked  string
+		wantClosed  int // closed position records
+	}{
+		{
+			name: "open",
+			fills: []struct {
+				side  types.PositionSide
+				size  string
+				price string
+			}{{long, "1", "50000"}},
+			wantSide: long, wantSize: "1", wantEntry: "50000", wantMargin: "2500",
+			wantPnL: "0", wantBalance: "100000", wantLocked: "2500",
+		},
+		{
+			name: "increase averages the entry",
+			fills: []struct {
+				side  types.PositionSide
+				size  string
+				price string
+			}{{long, "1", "50000"}, {long, "1", "52000"}},
+			wantSide: long, wantSize: "2", wantEntry: "51000", wantMargin: "5100",
+			wantPnL: "0", wantBalance: "100000", wantLocked: "5100",
+		},
+		{
+			name: "reduce realizes at the fill price",
+			fills: []struct {
+				side  types.PositionSide
+				size  string
+				price string
+			}{{long, "2", "50000"}, {short, "0.5", "52000"}},
+			wantSide: long, wantSize: "1.5", wantEntry: "50000", wantMargin: "3750",
+			wantPnL: "1000", wantBalance: "101000", wantLocked: "3750",
+		},
+		{
+			name: "reduce a short at a loss",
+			fills: []struct {
+				side  types.PositionSide
+				size  string
+				price string
+			}{{short, "2", "50000"}, {long, "1", "51000"}},
+			wantSide: short, wantSize: "1", wantEntry: "50000", wantMargin: "2500",
+			wantPnL: "-1000", wantBalance: "99000", wantLocked: "2500",
+		},
+		{
+			name: "close",
+			fills: []struct {
+				side  types.PositionSide
+				size  string
+				price string
+			}{{long, "1", "50000"}, {short, "1", "49000"}},
+			wantPnL: "-1000", wantBalance: "99000", wantLocked: "0", wantClosed: 1,
+		},
+		{
+			name: "flip long to short",
+			fills: []struct {
+				side  types.PositionSide
+				size  string
+				price string
+			}{{long, "1", "50000"}, {short, "3", "51000"}},
+			wantSide: short, wantSize: "2", wantEntry: "51000", wantMargin: "5100",
+			wantPnL: "1000", wantBalance: "101000", wantLocked: "5100", wantClosed: 1,
+		},
+		{
+			name: "flip short to long",
+			fills: []struct {
+				side  types.PositionSide
+				size  string
+				price string
+			}{{short, "2", "50000"}, {long, "2.5", "52000"}},
+			wantSide: long, wantSize: "0.5", wantEntry: "52000", wantMargin: "1300",
+			wantPnL: "-4000", wantBalance: "96000", wantLocked: "1300", wantClosed: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pm, k, ctx := setupPositionManager(t, 100000)
+
+			var (
+				position *types.Position
+				pnl      math.LegacyDec
+				err      error
+			)
+			for _, fill := range tc.fills {
+				position, pnl, err = pm.ApplyFill(ctx, positionTrader, "BTC-USDC", fill.side, dec(fill.size), dec(fill.price))
+				if err != nil {
+					t.Fatalf("fill %s %s@%s failed: %v", fill.side, fill.size, fill.price, err)
+				}
+			}
+
+			stored := k.GetPosition(ctx, positionTrader, "BTC-USDC")
+			if tc.wantSize == "" {
+				if position != nil || stored != nil {
+					t.Fatalf("expected flat, got %+v", stored)
+				}
+			} else {
+				if position == nil || stored == nil {
+					t.Fatal("expected a position")
+				}
+				if stored.Side != tc.wantSide || !stored.Size.Equal(dec(tc.wantSize)) {
+					t.Errorf("expected %s %s, got %s %s", tc.wantSide, tc.wantSize, stored.Side, stored.Size)
+				}
+				if !stored.EntryPrice.Equal(dec(tc.wantEntry)) {
+					t.Errorf("expected entry %s, got %s", tc.wantEntry, stored.EntryPrice)
+				}
+				if !stored.Margin.Equal(dec(tc.wantMargin)) {
+					t.Errorf("expected margin %s, got %s", tc.wantMargin, stored.Margin)
+				}
+			}
+			if !pnl.Equal(dec(tc.wantPnL)) {
+				t.Errorf("expected realized PnL %s, got %s", tc.wantPnL, pnl)
+			}
+
+			account := k.GetAccount(ctx, positionTrader)
+			if !account.Balance.Equal(dec(tc.wantBalance)) {
+				t.Errorf("expected balance %s, got %s", tc.wantBalance, account.Balance)
+			}
+			if !account.LockedMargin.Equal(dec(tc.wantLocked)) {
+				t.Errorf("expected locked margin %s, got %s", tc.wantLocked, account.LockedMargin)
+			}
+
+			if closed, _, _ := k.GetClosedPositions(ctx, positionTrader, "", "", 10); len(closed) != tc.wantClosed {
+				t.Errorf("expected %d closed positions, got %d", tc.wantClosed, len(closed))
+			}
+		})
+	}
+}
+
+// TestPositionFlipMarginFailure tests that a flip whose remainder cannot be
+// margined leaves the original position untouched
+func TestPositionFlipMarginFailure(t *testing.T) {
+	pm, k, ctx := setupPositionManager(t, 3000)
+
+	if _, _, err := pm.ApplyFill(ctx, positionTrader, "BTC-USDC", types.PositionSideLong, dec("1"), dec("50000")); err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+
+	// The remainder of 2 needs 5000 of margin against 3000 of balance
+	_, _, err := pm.ApplyFill(ctx, positionTrader, "BTC-USDC", types.PositionSideShort, dec("3"), dec("50000"))
+	if !errors.Is(err, types.ErrInsufficientMargin) {
+		t.Fatalf("expected ErrInsufficientMargin, got %v", err)
+	}
+
+	position := k.GetPosition(ctx, positionTrader, "BTC-USDC")
+	if position == nil || position.Side != types.PositionSideLong || !position.Size.Equal(dec("1")) {
+		t.Fatalf("expected the long to survive, got %+v", position)
+	}
+	account := k.GetAccount(ctx, positionTrader)
+	if !account.Balance.Equal(dec("3000")) || !account.LockedMargin.Equal(dec("2500")) {
+		t.Errorf("expected account unchanged, got balance %s locked %s", account.Balance, account.LockedMargin)
+	}
+	if closed, _, _ := k.GetClosedPositions(ctx, positionTrader, "", "", 10); len(closed) != 0 {
+		t.Errorf("expected no closed position record, got %d", len(closed))
+	}
+}
+
+// TestPositionFlipMarginsRemainderOnly tests that a flip only needs initial
+// margin for the size beyond the closed position
+func TestPositionFlipMarginsRemainderOnly(t *testing.T) {
+	pm, k, ctx := setupPositionManager(t, 3000)
+
+	if _, _, err := pm.ApplyFill(ctx, positionTrader, "BTC-USDC", types.PositionSideLong, dec("1"), dec("50000")); err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+
+	// A margin check on the full 1.5 would need 3750; the 0.5 remainder needs 1250
+	position, _, err := pm.ApplyFill(ctx, positionTrader, "BTC-USDC", types.PositionSideShort, dec("1.5"), dec("50000"))
+	if err != nil {
+		t.Fatalf("flip failed: %v", err)
+	}
+	if position.Side != types.PositionSideShort || !position.Size.Equal(dec("0.5")) {
+		t.Errorf("expected short 0.5, got %s %s", position.Side, position.Size)
+	}
+	if account := k.GetAccount(ctx, positionTrader); !account.LockedMargin.Equal(dec("1250")) {
+		t.Errorf("expected locked margin 1250, got %s", account.LockedMargin)
+	}
+}
+
+// TestUpdatePositionFromTradeFlip tests that a trade fill flips the position
+// and splits the fee between the closed and the opened position
+func TestUpdatePositionFromTradeFlip(t *testing.T) {
+	pm, k, ctx := setupPositionManager(t, 100000)
+
+	if err := pm.UpdatePositionFromTrade(ctx, positionTrader, "BTC-USDC", true, dec("1"), dec("50000"), math.LegacyZeroDec()); err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	if err := pm.UpdatePositionFromTrade(ctx, positionTrader, "BTC-USDC", false, dec("4"), dec("50500"), dec("40")); err != nil {
+		t.Fatalf("flip failed: %v", err)
+	}
+
+	position := k.GetPosition(ctx, positionTrader, "BTC-USDC")
+	if position == nil || position.Side != types.PositionSideShort || !position.Size.Equal(dec("3")) {
+		t.Fatalf("expected short 3, got %+v", position)
+	}
+	if !position.FeesPaid.Equal(dec("30")) {
+		t.Errorf("expected opening fee 30, got %s", position.FeesPaid)
+	}
+
+	closed, _, _ := k.GetClosedPositions(ctx, positionTrader, "", "", 10)
+	if len(closed) != 1 {
+		t.Fatalf("expected 1 closed position, got %d", len(closed))
+	}
+	if !closed[0].RealizedPnL.Equal(dec("500")) || !closed[0].FeesPaid.Equal(dec("10")) {
+		t.Errorf("expected PnL 500 and fee 10, got %s and %s", closed[0].RealizedPnL, closed[0].FeesPaid)
+	}
+
+	// 100000 + 500 realized - 40 fee
+	if account := k.GetAccount(ctx, positionTrader); !account.Balance.Equal(dec("100460")) {
+		t.Errorf("expected balance 100460, got %s", account.Balance)
+	}
+}
@@ -0,0 +1,92 @@
+package keeper
+
+import (
+	"encoding/json"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// Store key prefix for market trading schedules
+var TradingScheduleKeyPrefix = []byte{0x0C}
+
+func tradingScheduleKey(marketID string) []byte {
+	return append(TradingScheduleKeyPrefix, []byte(marketID)...)
+}
+
+// SetTradingSchedule replaces a market's trading schedule. Maintenance windows
+// that have already ended are dropped; an empty schedule removes the entry.
+func (k *Keeper) SetTradingSchedule(ctx sdk.Context, schedule *types.TradingSchedule) error {
+	if k.GetMarket(ctx, schedule.MarketID) == nil {
+		return types.ErrMarketNotFound
+	}
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+
+	schedule.PruneMaintenance(ctx.BlockTime())
+	if schedule.IsEmpty() {
+		k.DeleteTradingSchedule(ctx, schedule.MarketID)
+		return nil
+	}
+
+	schedule.UpdatedAt = ctx.BlockTime()
+	bz, _ := json.Marshal(schedule)
+	k.GetStore(ctx).Set(tradingScheduleKey(schedule.MarketID), bz)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"trading_schedule_updated",
+			sdk.NewAttribute("market_id", schedule.MarketID),
+			sdk.NewAttribute("trading_hours", strconv.Itoa(len(schedule.TradingHours))),
+			sdk.NewAttribute("maintenance_windows", strconv.Itoa(len(schedule.Maintenance))),
+		),
+	)
+	return nil
+}
+
+// GetTradingSchedule returns a market's trading schedule, or nil if it trades around the clock
+func (k *Keeper) GetTradingSchedule(ctx sdk.Context, marketID string) *types.TradingSchedule {
+	bz := k.GetStore(ctx).Get(tradingScheduleKey(marketID))
+	if bz == nil {
+		return nil
+	}
+	var schedule types.TradingSchedule
+	if err := json.Unmarshal(bz, &schedule); err != nil {
+		return nil
+	}
+	return &schedule
+}
+
+// DeleteTradingSchedule removes a market's trading schedule
+func (k *Keeper) DeleteTradingSchedule(ctx sdk.Context, marketID string) {
+	store := k.GetStore(ctx)
+	key := tradingScheduleKey(marketID)
+	if !store.Has(key) {
+		return
+	}
+	store.Delete(key)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"trading_schedule_removed",
+			sdk.NewAttribute("market_id", marketID),
+		),
+	)
+}
+
+// GetTradingStatus returns whether a market accepts new orders at the block time
+func (k *Keeper) GetTradingStatus(ctx sdk.Context, marketID string) *types.TradingStatus {
+	schedule := k.GetTradingSchedule(ctx, marketID)
+	if schedule == nil {
+		return &types.TradingStatus{MarketID: marketID, Open: true}
+	}
+	return schedule.StatusAt(ctx.BlockTime())
+}
+
+// CheckTradingAllowed returns a *types.TradingHaltError if the market's
+// schedule rejects new orders at the block time
+func (k *Keeper) CheckTradingAllowed(ctx sdk.Context, marketID string) error {
+	return k.GetTradingStatus(ctx, marketID).Err()
+}
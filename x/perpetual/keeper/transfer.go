@@ -0,0 +1,133 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// Store key prefixes for the margin transfer ledger
+var (
+	TransferKeyPrefix  = []byte{0x0D}
+	TransferCounterKey = []byte{0x0E}
+)
+
+// transferKey orders a trader's transfers by sequence so reverse iteration returns newest first
+func transferKey(trader string, seq uint64) []byte {
+	return binary.BigEndian.AppendUint64(transferTraderPrefix(trader), seq)
+}
+
+func transferTraderPrefix(trader string) []byte {
+	prefix := make([]byte, 0, len(TransferKeyPrefix)+len(trader)+1)
+	prefix = append(prefix, TransferKeyPrefix...)
+	return append(prefix, []byte(trader+":")...)
+}
+
+// recordTransfer appends a deposit, withdrawal or one side of an internal
+// transfer to the trader's ledger
+func (k *Keeper) recordTransfer(ctx sdk.Context, trader string, kind types.TransferKind, amount, balance math.LegacyDec, counterparty string) *types.Transfer {
+	store := k.GetStore(ctx)
+
+	var seq uint64
+	if bz := store.Get(TransferCounterKey); bz != nil {
+		seq = binary.BigEndian.Uint64(bz)
+	}
+	seq++
+	store.Set(TransferCounterKey, binary.BigEndian.AppendUint64(nil, seq))
+
+	transfer := &types.Transfer{
+		TransferID:   fmt.Sprintf("xfer-%d", seq),
+		Trader:       trader,
+		Kind:         kind,
+		Amount:       amount,
+		Balance:      balance,
+		Counterparty: counterparty,
+		Timestamp:    ctx.BlockTime(),
+	}
+	bz, _ := json.Marshal(transfer)
+	store.Set(transferKey(trader, seq), bz)
+	return transfer
+}
+
+// GetTransfers returns up to limit of a trader's deposits, withdrawals and
+// internal transfers, newest first
+func (k *Keeper) GetTransfers(ctx sdk.Context, trader string, limit int) []*types.Transfer {
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStoreReversePrefixIterator(store, transferTraderPrefix(trader))
+	defer iterator.Close()
+
+	transfers := make([]*types.Transfer, 0)
+	for ; iterator.Valid() && len(transfers) < limit; iterator.Next() {
+		var transfer types.Transfer
+		if err := json.Unmarshal(iterator.Value(), &transfer); err != nil {
+			continue
+		}
+		transfers = append(transfers, &transfer)
+	}
+	return transfers
+}
+
+// InternalTransfer moves free collateral from one account to another at
+// once. Only collateral not backing positions can move: the amount must be
+// within the sender's free collateral in its margin mode. A sender with a
+// withdrawal allowlist can only transfer to active allowlisted accounts, so
+// a transfer cannot get around it, and a frozen account cannot transfer.
+// Returns the sender's ledger record.
+func (k *Keeper) InternalTransfer(ctx sdk.Context, from, to string, amount math.LegacyDec) (*types.Transfer, error) {
+	if amount.IsNil() || !amount.IsPositive() {
+		return nil, fmt.Errorf("%w: amount must be positive", types.ErrInvalidTransfer)
+	}
+	if to == "" || to == from {
+		return nil, fmt.Errorf("%w: recipient must be another account", types.ErrInvalidTransfer)
+	}
+	sender := k.GetAccount(ctx, from)
+	if sender == nil {
+		return nil, types.ErrAccountNotFound
+	}
+	if err := k.checkAccountNotFrozen(ctx, from); err != nil {
+		return nil, err
+	}
+	if free := k.GetFreeCollateral(ctx, from); amount.GT(free) {
+		return nil, fmt.Errorf("%w: %s free, %s requested", types.ErrTransferExceedsFreeCollateral, free, amount)
+	}
+	if k.GetWithdrawalSecurity(ctx, from).AllowlistEnabled {
+		entry := k.getWithdrawalAddress(ctx, from, to)
+		if entry == nil || !entry.IsActive(ctx.BlockTime()) {
+			return nil, fmt.Errorf("%w: %s", types.ErrWithdrawalDestinationNotAllowed, to)
+		}
+	}
+
+	if err := sender.Withdraw(amount); err != nil {
+		return nil, err
+	}
+	sender.UpdatedAt = ctx.BlockTime()
+	k.SetAccount(ctx, sender)
+	// Not GetOrCreateAccount: a new recipient must not get the testing balance
+	recipient := k.GetAccount(ctx, to)
+	if recipient == nil {
+		recipient = types.NewAccount(to)
+		recipient.CreatedAt = ctx.BlockTime()
+	}
+	recipient.Deposit(amount)
+	recipient.UpdatedAt = ctx.BlockTime()
+	k.SetAccount(ctx, recipient)
+
+	transfer := k.recordTransfer(ctx, from, types.TransferInternalOut, amount, sender.Balance, to)
+	k.recordTransfer(ctx, to, types.TransferInternalIn, amount, recipient.Balance, from)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"internal_transfer",
+			sdk.NewAttribute("from", from),
+			sdk.NewAttribute("to", to),
+			sdk.NewAttribute("amount", amount.String()),
+			sdk.NewAttribute("transfer_id", transfer.TransferID),
+		),
+	)
+	return transfer, nil
+}
@@ -0,0 +1,98 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TestTransferLedger tests that deposits and withdrawals are recorded per
+// trader, newest first, and failed withdrawals are not
+func TestTransferLedger(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	// Start from an empty account rather than the testing balance
+	k.SetAccount(ctx, types.NewAccount("alice"))
+
+	if err := k.Deposit(ctx, "alice", dec("1000")); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	if err := k.Deposit(ctx, "bob", dec("50")); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	if err := k.Withdraw(ctx, "alice", dec("250")); err != nil {
+		t.Fatalf("failed to withdraw: %v", err)
+	}
+	if err := k.Withdraw(ctx, "alice", dec("5000")); err == nil {
+		t.Fatalf("expected an overdrawn withdrawal to fail")
+	}
+
+	transfers := k.GetTransfers(ctx, "alice", 10)
+	if len(transfers) != 2 {
+		t.Fatalf("expected 2 transfers, got %d", len(transfers))
+	}
+	if transfers[0].Kind != types.TransferWithdraw || !transfers[0].Amount.Equal(dec("250")) || !transfers[0].Balance.Equal(dec("750")) {
+		t.Errorf("unexpected latest transfer %+v", transfers[0])
+	}
+	if transfers[1].Kind != types.TransferDeposit || transfers[1].TransferID != "xfer-1" {
+		t.Errorf("unexpected first transfer %+v", transfers[1])
+	}
+	if got := k.GetTransfers(ctx, "alice", 1); len(got) != 1 || got[0].Kind != types.TransferWithdraw {
+		t.Errorf("expected the limit to keep the newest transfer, got %+v", got)
+	}
+}
+
+// TestInternalTransfer tests that only free collateral moves between
+// accounts, both sides are recorded and the sender's allowlist applies
+func TestInternalTransfer(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	alice := types.NewAccount("alice")
+	alice.Balance = dec("1000")
+	alice.LockedMargin = dec("400")
+	k.SetAccount(ctx, alice)
+
+	if _, err := k.InternalTransfer(ctx, "alice", "alice", dec("10")); !errors.Is(err, types.ErrInvalidTransfer) {
+		t.Fatalf("expected a self transfer to fail, got %v", err)
+	}
+	if _, err := k.InternalTransfer(ctx, "alice", "mm", dec("0")); !errors.Is(err, types.ErrInvalidTransfer) {
+		t.Fatalf("expected a zero amount to fail, got %v", err)
+	}
+	if _, err := k.InternalTransfer(ctx, "nobody", "mm", dec("10")); !errors.Is(err, types.ErrAccountNotFound) {
+		t.Fatalf("expected ErrAccountNotFound, got %v", err)
+	}
+	if _, err := k.InternalTransfer(ctx, "alice", "mm", dec("700")); !errors.Is(err, types.ErrTransferExceedsFreeCollateral) {
+		t.Fatalf("expected locked margin not to move, got %v", err)
+	}
+
+	transfer, err := k.InternalTransfer(ctx, "alice", "mm", dec("600"))
+	if err != nil {
+		t.Fatalf("failed to transfer: %v", err)
+	}
+	if transfer.Kind != types.TransferInternalOut || transfer.Counterparty != "mm" || !transfer.Balance.Equal(dec("400")) {
+		t.Errorf("unexpected transfer %+v", transfer)
+	}
+	if got := k.GetAccount(ctx, "mm"); got == nil || !got.Balance.Equal(dec("600")) {
+		t.Fatalf("expected the new account to hold exactly 600, got %+v", got)
+	}
+	in := k.GetTransfers(ctx, "mm", 10)
+	if len(in) != 1 || in[0].Kind != types.TransferInternalIn || in[0].Counterparty != "alice" {
+		t.Errorf("expected the receiving side on mm's ledger, got %+v", in)
+	}
+
+	// The recipient's allowlist does not matter, the sender's does
+	if _, err := k.SetWithdrawalSecurity(ctx, "mm", 0, true); err != nil {
+		t.Fatalf("failed to set withdrawal security: %v", err)
+	}
+	if _, err := k.InternalTransfer(ctx, "mm", "bob", dec("100")); !errors.Is(err, types.ErrWithdrawalDestinationNotAllowed) {
+		t.Fatalf("expected ErrWithdrawalDestinationNotAllowed, got %v", err)
+	}
+	if _, err := k.AddWithdrawalAddress(ctx, "mm", "alice", "main"); err != nil {
+		t.Fatalf("failed to add address: %v", err)
+	}
+	if _, err := k.InternalTransfer(ctx, "mm", "alice", dec("100")); err != nil {
+		t.Fatalf("failed to transfer to an allowlisted account: %v", err)
+	}
+	if !k.GetAccount(ctx, "alice").Balance.Equal(dec("500")) {
+		t.Errorf("expected alice to get 100 back, got %s", k.GetAccount(ctx, "alice").Balance)
+	}
+}
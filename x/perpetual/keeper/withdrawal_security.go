@@ -0,0 +1,513 @@
+package keeper
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// Store key prefixes for withdrawal security
+var (
+	WithdrawalSecurityKeyPrefix  = []byte{0x0F}
+	WithdrawalAddressKeyPrefix   = []byte{0x10}
+	WithdrawalKeyPrefix          = []byte{0x11} // trader:seq -> withdrawal
+	WithdrawalReleaseQueuePrefix = []byte{0x12} // releaseAt|seq -> trader
+	WithdrawalCounterKey         = []byte{0x13}
+)
+
+const withdrawalIDPrefix = "wd-"
+
+func withdrawalSecurityKey(trader string) []byte {
+	return append(append([]byte{}, WithdrawalSecurityKeyPrefix...), []byte(trader)...)
+}
+
+func withdrawalAddressPrefix(trader string) []byte {
+	return append(append([]byte{}, WithdrawalAddressKeyPrefix...), []byte(trader+":")...)
+}
+
+func withdrawalAddressKey(trader, address string) []byte {
+	return append(withdrawalAddressPrefix(trader), []byte(address)...)
+}
+
+func withdrawalTraderPrefix(trader string) []byte {
+	return append(append([]byte{}, WithdrawalKeyPrefix...), []byte(trader+":")...)
+}
+
+// withdrawalKey orders a trader's withdrawals by sequence so reverse iteration returns newest first
+func withdrawalKey(trader string, seq uint64) []byte {
+	return binary.BigEndian.AppendUint64(withdrawalTraderPrefix(trader), seq)
+}
+
+// withdrawalReleaseKey orders pending withdrawals by release time
+func withdrawalReleaseKey(releaseAt time.Time, seq uint64) []byte {
+	key := binary.BigEndian.AppendUint64(append([]byte{}, WithdrawalReleaseQueuePrefix...), uint64(releaseAt.UnixNano()))
+	return binary.BigEndian.AppendUint64(key, seq)
+}
+
+// withdrawalSeq parses the sequence number out of a withdrawal ID
+func withdrawalSeq(withdrawalID string) (uint64, error) {
+	seq, ok := strings.CutPrefix(withdrawalID, withdrawalIDPrefix)
+	if !ok {
+		return 0, types.ErrWithdrawalNotFound
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, types.ErrWithdrawalNotFound
+	}
+	return n, nil
+}
+
+// ============ Settings ============
+
+// GetWithdrawalSecurity returns a trader's withdrawal protection, applying a
+// pending weakening change once it is due
+func (k *Keeper) GetWithdrawalSecurity(ctx sdk.Context, trader string) *types.WithdrawalSecurity {
+	security := types.NewWithdrawalSecurity(trader)
+	if bz := k.GetStore(ctx).Get(withdrawalSecurityKey(trader)); bz != nil {
+		if err := json.Unmarshal(bz, security); err != nil {
+			security = types.NewWithdrawalSecurity(trader)
+		}
+	}
+	if pending := security.Pending; pending != nil && !ctx.BlockTime().Before(pending.EffectiveAt) {
+		security.Timelock = pending.Timelock
+		security.AllowlistEnabled = pending.AllowlistEnabled
+		security.UpdatedAt = pending.EffectiveAt
+		security.Pending = nil
+	}
+	return security
+}
+
+func (k *Keeper) setWithdrawalSecurity(ctx sdk.Context, security *types.WithdrawalSecurity) {
+	bz, _ := json.Marshal(security)
+	k.GetStore(ctx).Set(withdrawalSecurityKey(security.Trader), bz)
+}
+
+// SetWithdrawalSecurity changes a trader's withdrawal protection. A longer
+// timelock or enabling the allowlist applies at once; a shorter timelock or
+// disabling the allowlist is scheduled for when the current timelock has passed.
+func (k *Keeper) SetWithdrawalSecurity(ctx sdk.Context, trader string, timelock time.Duration, allowlistEnabled bool) (*types.WithdrawalSecurity, error) {
+	if err := types.ValidateWithdrawalTimelock(timelock); err != nil {
+		return nil, err
+	}
+
+	now := ctx.BlockTime()
+	security := k.GetWithdrawalSecurity(ctx, trader)
+	current := security.Timelock
+	weakens := timelock < current || (security.AllowlistEnabled && !allowlistEnabled)
+
+	security.Pending = nil
+	if weakens && current > 0 {
+		// Keep the stronger of the old and new settings until the change is due
+		if timelock > security.Timelock {
+			security.Timelock = timelock
+		}
+		security.AllowlistEnabled = security.AllowlistEnabled || allowlistEnabled
+		security.Pending = &types.WithdrawalSecurityChange{
+			Timelock:         timelock,
+			AllowlistEnabled: allowlistEnabled,
+			EffectiveAt:      now.Add(current),
+		}
+	} else {
+		security.Timelock = timelock
+		security.AllowlistEnabled = allowlistEnabled
+	}
+	security.UpdatedAt = now
+	k.setWithdrawalSecurity(ctx, security)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"withdrawal_security_updated",
+			sdk.NewAttribute("trader", trader),
+			sdk.NewAttribute("timelock", timelock.String()),
+			sdk.NewAttribute("allowlist_enabled", strconv.FormatBool(allowlistEnabled)),
+			sdk.NewAttribute("scheduled", strconv.FormatBool(security.Pending != nil)),
+		),
+	)
+	return security, nil
+}
+
+// ============ Allowlist ============
+
+// GetWithdrawalAddresses returns a trader's allowlisted destinations
+func (k *Keeper) GetWithdrawalAddresses(ctx sdk.Context, trader string) []*types.WithdrawalAddress {
+	addresses := make([]*types.WithdrawalAddress, 0)
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), withdrawalAddressPrefix(trader))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var address types.WithdrawalAddress
+		if err := json.Unmarshal(iterator.Value(), &address); err != nil {
+			continue
+		}
+		addresses = append(addresses, &address)
+	}
+	return addresses
+}
+
+func (k *Keeper) getWithdrawalAddress(ctx sdk.Context, trader, address string) *types.WithdrawalAddress {
+	bz := k.GetStore(ctx).Get(withdrawalAddressKey(trader, address))
+	if bz == nil {
+		return nil
+	}
+	var entry types.WithdrawalAddress
+	if err := json.Unmarshal(bz, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+func (k *Keeper) setWithdrawalAddress(ctx sdk.Context, entry *types.WithdrawalAddress) {
+	bz, _ := json.Marshal(entry)
+	k.GetStore(ctx).Set(withdrawalAddressKey(entry.Trader, entry.Address), bz)
+}
+
+// AddWithdrawalAddress allowlists a destination, usable once the trader's
+// current timelock has passed. Re-adding an address only updates its label.
+func (k *Keeper) AddWithdrawalAddress(ctx sdk.Context, trader, address, label string) (*types.WithdrawalAddress, error) {
+	if address == "" {
+		return nil, fmt.Errorf("%w: address is required", types.ErrInvalidWithdrawalSecurity)
+	}
+	if entry := k.getWithdrawalAddress(ctx, trader, address); entry != nil {
+		entry.Label = label
+		k.setWithdrawalAddress(ctx, entry)
+		return entry, nil
+	}
+
+	now := ctx.BlockTime()
+	entry := &types.WithdrawalAddress{
+		Trader:   trader,
+		Address:  address,
+		Label:    label,
+		AddedAt:  now,
+		ActiveAt: now.Add(k.GetWithdrawalSecurity(ctx, trader).Timelock),
+	}
+	k.setWithdrawalAddress(ctx, entry)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"withdrawal_address_added",
+			sdk.NewAttribute("trader", trader),
+			sdk.NewAttribute("address", address),
+			sdk.NewAttribute("active_at", entry.ActiveAt.Format(time.RFC3339)),
+		),
+	)
+	return entry, nil
+}
+
+// RemoveWithdrawalAddress removes a destination from the allowlist at once
+func (k *Keeper) RemoveWithdrawalAddress(ctx sdk.Context, trader, address string) error {
+	if k.getWithdrawalAddress(ctx, trader, address) == nil {
+		return fmt.Errorf("%w: %s is not allowlisted", types.ErrWithdrawalDestinationNotAllowed, address)
+	}
+	k.GetStore(ctx).Delete(withdrawalAddressKey(trader, address))
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"withdrawal_address_removed",
+			sdk.NewAttribute("trader", trader),
+			sdk.NewAttribute("address", address),
+		),
+	)
+	return nil
+}
+
+// ============ Withdrawals ============
+
+// GetWithdrawal returns one of a trader's withdrawals
+func (k *Keeper) GetWithdrawal(ctx sdk.Context, trader, withdrawalID string) *types.Withdrawal {
+	seq, err := withdrawalSeq(withdrawalID)
+	if err != nil {
+		return nil
+	}
+	bz := k.GetStore(ctx).Get(withdrawalKey(trader, seq))
+	if bz == nil {
+		return nil
+	}
+	var withdrawal types.Withdrawal
+	if err := json.Unmarshal(bz, &withdrawal); err != nil {
+		return nil
+	}
+	return &withdrawal
+}
+
+func (k *Keeper) setWithdrawal(ctx sdk.Context, withdrawal *types.Withdrawal) {
+	seq, err := withdrawalSeq(withdrawal.WithdrawalID)
+	if err != nil {
+		return
+	}
+	bz, _ := json.Marshal(withdrawal)
+	k.GetStore(ctx).Set(withdrawalKey(withdrawal.Trader, seq), bz)
+}
+
+// GetWithdrawals returns up to limit of a trader's withdrawals, newest first
+func (k *Keeper) GetWithdrawals(ctx sdk.Context, trader string, limit int) []*types.Withdrawal {
+	iterator := storetypes.KVStoreReversePrefixIterator(k.GetStore(ctx), withdrawalTraderPrefix(trader))
+	defer iterator.Close()
+
+	withdrawals := make([]*types.Withdrawal, 0)
+	for ; iterator.Valid() && len(withdrawals) < limit; iterator.Next() {
+		var withdrawal types.Withdrawal
+		if err := json.Unmarshal(iterator.Value(), &withdrawal); err != nil {
+			continue
+		}
+		withdrawals = append(withdrawals, &withdrawal)
+	}
+	return withdrawals
+}
+
+// RequestWithdrawal withdraws margin to destination, the trader's own address
+// if empty. Without a timelock it is paid out at once; with one, the amount
+// leaves the balance now and is paid out by the EndBlocker at ReleaseAt
+// unless cancelled first. With the allowlist enabled the destination must be
+// an active allowlisted address.
+func (k *Keeper) RequestWithdrawal(ctx context.Context, trader string, amount math.LegacyDec, destination string) (*types.Withdrawal, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+	if amount.IsNil() || !amount.IsPositive() {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	if err := k.checkAccountNotFrozen(sdkCtx, trader); err != nil {
+		return nil, err
+	}
+	if destination == "" {
+		destination = trader
+	}
+
+	now := sdkCtx.BlockTime()
+	security := k.GetWithdrawalSecurity(sdkCtx, trader)
+	if security.AllowlistEnabled {
+		entry := k.getWithdrawalAddress(sdkCtx, trader, destination)
+		if entry == nil {
+			return nil, fmt.Errorf("%w: %s", types.ErrWithdrawalDestinationNotAllowed, destination)
+		}
+		if !entry.IsActive(now) {
+			return nil, fmt.Errorf("%w: %s is active from %s", types.ErrWithdrawalDestinationNotAllowed, destination, entry.ActiveAt.Format(time.RFC3339))
+		}
+	}
+
+	seq := k.nextWithdrawalSeq(sdkCtx)
+	withdrawal := &types.Withdrawal{
+		WithdrawalID: withdrawalIDPrefix + strconv.FormatUint(seq, 10),
+		Trader:       trader,
+		Amount:       amount,
+		Destination:  destination,
+		Status:       types.WithdrawalPending,
+		RequestedAt:  now,
+		ReleaseAt:    now.Add(security.Timelock),
+	}
+
+	if security.Timelock == 0 {
+		if err := k.Withdraw(ctx, trader, amount); err != nil {
+			return nil, err
+		}
+		withdrawal.Status = types.WithdrawalCompleted
+		withdrawal.ProcessedAt = now
+		k.setWithdrawal(sdkCtx, withdrawal)
+		return withdrawal, nil
+	}
+
+	// Hold the amount out of the balance until release
+	account := k.GetAccount(sdkCtx, trader)
+	if account == nil {
+		return nil, types.ErrAccountNotFound
+	}
+	if err := account.Withdraw(amount); err != nil {
+		return nil, err
+	}
+	k.SetAccount(sdkCtx, account)
+	k.setWithdrawal(sdkCtx, withdrawal)
+	k.GetStore(sdkCtx).Set(withdrawalReleaseKey(withdrawal.ReleaseAt, seq), []byte(trader))
+
+	sdkCtx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"withdrawal_requested",
+			sdk.NewAttribute("trader", trader),
+			sdk.NewAttribute("withdrawal_id", withdrawal.WithdrawalID),
+			sdk.NewAttribute("amount", amount.String()),
+			sdk.NewAttribute("destination", destination),
+			sdk.NewAttribute("release_at", withdrawal.ReleaseAt.Format(time.RFC3339)),
+		),
+	)
+	return withdrawal, nil
+}
+
+func (k *Keeper) nextWithdrawalSeq(ctx sdk.Context) uint64 {
+	store := k.GetStore(ctx)
+	var seq uint64
+	if bz := store.Get(WithdrawalCounterKey); bz != nil {
+		seq = binary.BigEndian.Uint64(bz)
+	}
+	seq++
+	store.Set(WithdrawalCounterKey, binary.BigEndian.AppendUint64(nil, seq))
+	return seq
+}
+
+// CancelWithdrawal aborts a pending withdrawal before its release, returning
+// the amount to the trader's balance
+func (k *Keeper) CancelWithdrawal(ctx sdk.Context, trader, withdrawalID string) (*types.Withdrawal, error) {
+	withdrawal := k.GetWithdrawal(ctx, trader, withdrawalID)
+	if withdrawal == nil {
+		return nil, fmt.Errorf("%w: %s", types.ErrWithdrawalNotFound, withdrawalID)
+	}
+	now := ctx.BlockTime()
+	if !withdrawal.IsCancellable(now) {
+		return nil, fmt.Errorf("%w: %s is %s", types.ErrWithdrawalNotCancellable, withdrawalID, withdrawal.Status)
+	}
+
+	account := k.GetOrCreateAccount(ctx, trader)
+	account.Deposit(withdrawal.Amount)
+	k.SetAccount(ctx, account)
+
+	seq, _ := withdrawalSeq(withdrawalID)
+	k.GetStore(ctx).Delete(withdrawalReleaseKey(withdrawal.ReleaseAt, seq))
+	withdrawal.Status = types.WithdrawalCancelled
+	withdrawal.ProcessedAt = now
+	k.setWithdrawal(ctx, withdrawal)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"withdrawal_cancelled",
+			sdk.NewAttribute("trader", trader),
+			sdk.NewAttribute("withdrawal_id", withdrawalID),
+			sdk.NewAttribute("amount", withdrawal.Amount.String()),
+		),
+	)
+	return withdrawal, nil
+}
+
+// ReleaseWithdrawals pays out the pending withdrawals whose timelock has
+// passed and returns them. Those of frozen accounts stay queued until the
+// freeze ends.
+func (k *Keeper) ReleaseWithdrawals(ctx sdk.Context) []*types.Withdrawal {
+	store := k.GetStore(ctx)
+	now := ctx.BlockTime()
+	end := withdrawalReleaseKey(now.Add(time.Nanosecond), 0)
+	iterator := store.Iterator(WithdrawalReleaseQueuePrefix, end)
+
+	type due struct {
+		key    []byte
+		trader string
+		seq    uint64
+	}
+	var queue []due
+	for ; iterator.Valid(); iterator.Next() {
+		key := iterator.Key()
+		queue = append(queue, due{
+			key:    append([]byte{}, key...),
+			trader: string(iterator.Value()),
+			seq:    binary.BigEndian.Uint64(key[len(key)-8:]),
+		})
+	}
+	iterator.Close()
+
+	released := make([]*types.Withdrawal, 0, len(queue))
+	for _, d := range queue {
+		if k.checkAccountNotFrozen(ctx, d.trader) != nil {
+			continue
+		}
+		store.Delete(d.key)
+		withdrawal := k.GetWithdrawal(ctx, d.trader, withdrawalIDPrefix+strconv.FormatUint(d.seq, 10))
+		if withdrawal == nil || withdrawal.Status != types.WithdrawalPending {
+			continue
+		}
+		withdrawal.Status = types.WithdrawalCompleted
+		withdrawal.ProcessedAt = now
+		k.setWithdrawal(ctx, withdrawal)
+
+		balance := math.LegacyZeroDec()
+		if account := k.GetAccount(ctx, d.trader); account != nil {
+			balance = account.Balance
+		}
+		k.recordTransfer(ctx, d.trader, types.TransferWithdraw, withdrawal.Amount, balance, "")
+
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				"withdraw",
+				sdk.NewAttribute("trader", d.trader),
+				sdk.NewAttribute("withdrawal_id", withdrawal.WithdrawalID),
+				sdk.NewAttribute("amount", withdrawal.Amount.String()),
+				sdk.NewAttribute("destination", withdrawal.Destination),
+				sdk.NewAttribute("new_balance", balance.String()),
+			),
+		)
+		released = append(released, withdrawal)
+	}
+	return released
+}
+
+// WithdrawalEndBlocker pays out withdrawals whose timelock has passed
+func (k *Keeper) WithdrawalEndBlocker(ctx sdk.Context) {
+	if released := k.ReleaseWithdrawals(ctx); len(released) > 0 {
+		k.Logger().Info("released timelocked withdrawals", "count", len(released))
+	}
+}
+
+// ============ Genesis ============
+
+// importPendingWithdrawals restores pending withdrawals and their release
+// queue, continuing the withdrawal sequence after the highest imported ID
+func (k *Keeper) importPendingWithdrawals(ctx sdk.Context, withdrawals []*types.Withdrawal) error {
+	store := k.GetStore(ctx)
+	var maxSeq uint64
+	for _, withdrawal := range withdrawals {
+		seq, err := withdrawalSeq(withdrawal.WithdrawalID)
+		if err != nil {
+			return fmt.Errorf("%w: withdrawal ID %q", types.ErrInvalidGenesis, withdrawal.WithdrawalID)
+		}
+		k.setWithdrawal(ctx, withdrawal)
+		store.Set(withdrawalReleaseKey(withdrawal.ReleaseAt, seq), []byte(withdrawal.Trader))
+		if seq > maxSeq {
+			maxSeq = seq
+		}
+	}
+	if maxSeq > 0 {
+		store.Set(WithdrawalCounterKey, binary.BigEndian.AppendUint64(nil, maxSeq))
+	}
+	return nil
+}
+
+// exportWithdrawalSecurity returns every account's withdrawal settings and
+// allowlist, and the withdrawals still pending
+func (k *Keeper) exportWithdrawalSecurity(ctx sdk.Context) ([]*types.WithdrawalSecurity, []*types.WithdrawalAddress, []*types.Withdrawal) {
+	store := k.GetStore(ctx)
+	settings := make([]*types.WithdrawalSecurity, 0)
+	addresses := make([]*types.WithdrawalAddress, 0)
+	pending := make([]*types.Withdrawal, 0)
+
+	iterator := storetypes.KVStorePrefixIterator(store, WithdrawalSecurityKeyPrefix)
+	for ; iterator.Valid(); iterator.Next() {
+		var security types.WithdrawalSecurity
+		if err := json.Unmarshal(iterator.Value(), &security); err == nil {
+			settings = append(settings, &security)
+		}
+	}
+	iterator.Close()
+
+	iterator = storetypes.KVStorePrefixIterator(store, WithdrawalAddressKeyPrefix)
+	for ; iterator.Valid(); iterator.Next() {
+		var address types.WithdrawalAddress
+		if err := json.Unmarshal(iterator.Value(), &address); err == nil {
+			addresses = append(addresses, &address)
+		}
+	}
+	iterator.Close()
+
+	iterator = storetypes.KVStorePrefixIterator(store, WithdrawalKeyPrefix)
+	for ; iterator.Valid(); iterator.Next() {
+		var withdrawal types.Withdrawal
+		if err := json.Unmarshal(iterator.Value(), &withdrawal); err == nil && withdrawal.Status == types.WithdrawalPending {
+			pending = append(pending, &withdrawal)
+		}
+	}
+	iterator.Close()
+
+	return settings, addresses, pending
+}
@@ -0,0 +1,166 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openalpha/perp-dex/x/perpetual/types"
+)
+
+// TestWithdrawalTimelockAndAllowlist tests that timelocked withdrawals are
+// held until release and can be cancelled meanwhile, and that the allowlist
+// only accepts destinations once their timelock has passed
+func TestWithdrawalTimelockAndAllowlist(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	start := ctx.BlockTime()
+	k.SetAccount(ctx, types.NewAccount("alice"))
+	if err := k.Deposit(ctx, "alice", dec("1000")); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+
+	// Without protection a withdrawal is paid out at once
+	w, err := k.RequestWithdrawal(ctx, "alice", dec("100"), "")
+	if err != nil {
+		t.Fatalf("failed to withdraw: %v", err)
+	}
+	if w.Status != types.WithdrawalCompleted || w.Destination != "alice" || !k.GetAccount(ctx, "alice").Balance.Equal(dec("900")) {
+		t.Fatalf("expected an immediate withdrawal, got %+v", w)
+	}
+
+	if _, err := k.SetWithdrawalSecurity(ctx, "alice", types.MaxWithdrawalTimelock+time.Second, false); !errors.Is(err, types.ErrInvalidWithdrawalSecurity) {
+		t.Fatalf("expected ErrInvalidWithdrawalSecurity, got %v", err)
+	}
+	if _, err := k.SetWithdrawalSecurity(ctx, "alice", time.Hour, true); err != nil {
+		t.Fatalf("failed to set withdrawal security: %v", err)
+	}
+	if _, err := k.RequestWithdrawal(ctx, "alice", dec("100"), "cold"); !errors.Is(err, types.ErrWithdrawalDestinationNotAllowed) {
+		t.Fatalf("expected ErrWithdrawalDestinationNotAllowed, got %v", err)
+	}
+	if _, err := k.AddWithdrawalAddress(ctx, "alice", "cold", "hardware wallet"); err != nil {
+		t.Fatalf("failed to add address: %v", err)
+	}
+	if _, err := k.RequestWithdrawal(ctx, "alice", dec("100"), "cold"); !errors.Is(err, types.ErrWithdrawalDestinationNotAllowed) {
+		t.Fatalf("expected a new address to be inactive, got %v", err)
+	}
+
+	ctx = ctx.WithBlockTime(start.Add(time.Hour))
+	held, err := k.RequestWithdrawal(ctx, "alice", dec("200"), "cold")
+	if err != nil {
+		t.Fatalf("failed to request withdrawal: %v", err)
+	}
+	if held.Status != types.WithdrawalPending || !held.ReleaseAt.Equal(start.Add(2*time.Hour)) {
+		t.Fatalf("expected a withdrawal pending for an hour, got %+v", held)
+	}
+	cancelled, err := k.RequestWithdrawal(ctx, "alice", dec("50"), "cold")
+	if err != nil {
+		t.Fatalf("failed to request withdrawal: %v", err)
+	}
+	if !k.GetAccount(ctx, "alice").Balance.Equal(dec("650")) {
+		t.Fatalf("expected pending amounts to leave the balance, got %s", k.GetAccount(ctx, "alice").Balance)
+	}
+	if _, err := k.CancelWithdrawal(ctx, "alice", cancelled.WithdrawalID); err != nil {
+		t.Fatalf("failed to cancel withdrawal: %v", err)
+	}
+	if _, err := k.CancelWithdrawal(ctx, "alice", cancelled.WithdrawalID); !errors.Is(err, types.ErrWithdrawalNotCancellable) {
+		t.Fatalf("expected ErrWithdrawalNotCancellable, got %v", err)
+	}
+	if _, err := k.CancelWithdrawal(ctx, "bob", held.WithdrawalID); !errors.Is(err, types.ErrWithdrawalNotFound) {
+		t.Fatalf("expected another trader's withdrawal to be not found, got %v", err)
+	}
+	if !k.GetAccount(ctx, "alice").Balance.Equal(dec("700")) {
+		t.Fatalf("expected the cancelled amount back, got %s", k.GetAccount(ctx, "alice").Balance)
+	}
+
+	if released := k.ReleaseWithdrawals(ctx.WithBlockTime(held.ReleaseAt.Add(-time.Second))); len(released) != 0 {
+		t.Fatalf("expected nothing released before the timelock, got %+v", released)
+	}
+	ctx = ctx.WithBlockTime(held.ReleaseAt)
+	released := k.ReleaseWithdrawals(ctx)
+	if len(released) != 1 || released[0].WithdrawalID != held.WithdrawalID || released[0].Status != types.WithdrawalCompleted {
+		t.Fatalf("expected the held withdrawal released, got %+v", released)
+	}
+	if _, err := k.CancelWithdrawal(ctx, "alice", held.WithdrawalID); !errors.Is(err, types.ErrWithdrawalNotCancellable) {
+		t.Fatalf("expected a released withdrawal to be final, got %v", err)
+	}
+	if transfers := k.GetTransfers(ctx, "alice", 1); transfers[0].Kind != types.TransferWithdraw || !transfers[0].Amount.Equal(dec("200")) {
+		t.Errorf("expected the release in the transfer ledger, got %+v", transfers[0])
+	}
+	if history := k.GetWithdrawals(ctx, "alice", 10); len(history) != 3 || history[0].WithdrawalID != cancelled.WithdrawalID {
+		t.Errorf("unexpected withdrawal history %+v", history)
+	}
+}
+
+// TestWithdrawalSecurityWeakeningDelayed tests that lowering the timelock or
+// disabling the allowlist waits out the current timelock
+func TestWithdrawalSecurityWeakeningDelayed(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	start := ctx.BlockTime()
+
+	if _, err := k.SetWithdrawalSecurity(ctx, "alice", 24*time.Hour, true); err != nil {
+		t.Fatalf("failed to set withdrawal security: %v", err)
+	}
+	security, err := k.SetWithdrawalSecurity(ctx, "alice", 0, false)
+	if err != nil {
+		t.Fatalf("failed to set withdrawal security: %v", err)
+	}
+	if security.Timelock != 24*time.Hour || !security.AllowlistEnabled || security.Pending == nil ||
+		!security.Pending.EffectiveAt.Equal(start.Add(24*time.Hour)) {
+		t.Fatalf("expected the weakening to be scheduled, got %+v", security)
+	}
+
+	// A longer timelock applies at once and replaces the scheduled change
+	if security, _ = k.SetWithdrawalSecurity(ctx, "alice", 48*time.Hour, true); security.Timelock != 48*time.Hour || security.Pending != nil {
+		t.Fatalf("expected a longer timelock at once, got %+v", security)
+	}
+	if _, err := k.SetWithdrawalSecurity(ctx, "alice", time.Hour, true); err != nil {
+		t.Fatalf("failed to set withdrawal security: %v", err)
+	}
+	if security := k.GetWithdrawalSecurity(ctx.WithBlockTime(start.Add(47*time.Hour)), "alice"); security.Timelock != 48*time.Hour {
+		t.Errorf("expected the old timelock until the change is due, got %s", security.Timelock)
+	}
+	if security := k.GetWithdrawalSecurity(ctx.WithBlockTime(start.Add(48*time.Hour)), "alice"); security.Timelock != time.Hour || security.Pending != nil {
+		t.Errorf("expected the shorter timelock once due, got %+v", security)
+	}
+}
+
+// TestWithdrawalSecurityGenesis tests that settings, the allowlist and
+// pending withdrawals survive an export and import
+func TestWithdrawalSecurityGenesis(t *testing.T) {
+	k, ctx := setupFundingKeeper(t)
+	if err := k.Deposit(ctx, "alice", dec("1000")); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	if _, err := k.SetWithdrawalSecurity(ctx, "alice", time.Hour, false); err != nil {
+		t.Fatalf("failed to set withdrawal security: %v", err)
+	}
+	if _, err := k.AddWithdrawalAddress(ctx, "alice", "cold", ""); err != nil {
+		t.Fatalf("failed to add address: %v", err)
+	}
+	held, err := k.RequestWithdrawal(ctx, "alice", dec("300"), "")
+	if err != nil {
+		t.Fatalf("failed to request withdrawal: %v", err)
+	}
+	exported := k.ExportGenesis(ctx)
+	if len(exported.WithdrawalSecurity) != 1 || len(exported.WithdrawalAddresses) != 1 || len(exported.PendingWithdrawals) != 1 {
+		t.Fatalf("unexpected exported withdrawal state %+v", exported)
+	}
+
+	imported, ctx2 := setupFundingKeeper(t)
+	if err := imported.InitGenesis(ctx2, exported); err != nil {
+		t.Fatalf("failed to import genesis: %v", err)
+	}
+	if security := imported.GetWithdrawalSecurity(ctx2, "alice"); security.Timelock != time.Hour {
+		t.Errorf("expected the imported timelock, got %s", security.Timelock)
+	}
+	next, err := imported.RequestWithdrawal(ctx2, "alice", dec("100"), "")
+	if err != nil {
+		t.Fatalf("failed to request withdrawal: %v", err)
+	}
+	if next.WithdrawalID == held.WithdrawalID {
+		t.Errorf("expected a new withdrawal ID after import, got %s", next.WithdrawalID)
+	}
+	if released := imported.ReleaseWithdrawals(ctx2.WithBlockTime(held.ReleaseAt)); len(released) != 2 {
+		t.Errorf("expected both withdrawals released, got %d", len(released))
+	}
+}
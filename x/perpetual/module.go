@@ -2,6 +2,7 @@ package perpetual
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"cosmossdk.io/core/appmodule"
 	"github.com/cosmos/cosmos-sdk/client"
@@ -17,11 +18,17 @@ import (
 
 const (
 	ModuleName = "perpetual"
+
+	// ConsensusVersion is bumped whenever the store layout or state machine changes
+	ConsensusVersion = 3
 )
 
 var (
-	_ module.AppModuleBasic = AppModuleBasic{}
-	_ appmodule.AppModule   = AppModule{}
+	_ module.AppModuleBasic      = AppModuleBasic{}
+	_ appmodule.AppModule        = AppModule{}
+	_ module.HasInvariants       = AppModule{}
+	_ module.HasGenesis          = AppModule{}
+	_ module.HasConsensusVersion = AppModule{}
 )
 
 // AppModuleBasic defines the basic application module for perpetual
@@ -36,6 +43,7 @@ func (AppModuleBasic) Name() string {
 func (AppModuleBasic) RegisterLegacyAminoCodec(cdc *codec.LegacyAmino) {
 	cdc.RegisterConcrete(&types.MsgDeposit{}, "perpetual/MsgDeposit", nil)
 	cdc.RegisterConcrete(&types.MsgWithdraw{}, "perpetual/MsgWithdraw", nil)
+	cdc.RegisterConcrete(&types.MsgInternalTransfer{}, "perpetual/MsgInternalTransfer", nil)
 }
 
 // RegisterInterfaces registers the module's interface types
@@ -43,17 +51,26 @@ func (AppModuleBasic) RegisterInterfaces(registry cdctypes.InterfaceRegistry) {
 	registry.RegisterImplementations((*sdk.Msg)(nil),
 		&types.MsgDeposit{},
 		&types.MsgWithdraw{},
+		&types.MsgInternalTransfer{},
 	)
 }
 
 // DefaultGenesis returns default genesis state as raw bytes
 func (AppModuleBasic) DefaultGenesis(cdc codec.JSONCodec) json.RawMessage {
-	return nil
+	bz, err := json.Marshal(types.DefaultGenesis())
+	if err != nil {
+		panic(err)
+	}
+	return bz
 }
 
 // ValidateGenesis performs genesis state validation
 func (AppModuleBasic) ValidateGenesis(cdc codec.JSONCodec, config client.TxEncodingConfig, bz json.RawMessage) error {
-	return nil
+	var gs types.GenesisState
+	if err := json.Unmarshal(bz, &gs); err != nil {
+		return fmt.Errorf("failed to unmarshal %s genesis state: %w", ModuleName, err)
+	}
+	return gs.Validate()
 }
 
 // RegisterGRPCGatewayRoutes registers the gRPC Gateway routes for the module
@@ -83,8 +100,44 @@ func (am AppModule) Name() string {
 // RegisterServices registers module services
 func (am AppModule) RegisterServices(cfg module.Configurator) {
 	types.RegisterMsgServer(cfg.MsgServer(), keeper.NewMsgServerImpl(am.keeper))
+
+	m := keeper.NewMigrator(am.keeper)
+	if err := cfg.RegisterMigration(ModuleName, 1, m.Migrate1to2); err != nil {
+		panic(fmt.Errorf("failed to register %s migration from version 1 to 2: %w", ModuleName, err))
+	}
+	if err := cfg.RegisterMigration(ModuleName, 2, m.Migrate2to3); err != nil {
+		panic(fmt.Errorf("failed to register %s migration from version 2 to 3: %w", ModuleName, err))
+	}
+}
+
+// RegisterInvariants registers the module invariants with the crisis module
+func (am AppModule) RegisterInvariants(ir sdk.InvariantRegistry) {
+	keeper.RegisterInvariants(ir, am.keeper)
 }
 
+// InitGenesis imports the module's genesis state
+func (am AppModule) InitGenesis(ctx sdk.Context, cdc codec.JSONCodec, data json.RawMessage) {
+	var gs types.GenesisState
+	if err := json.Unmarshal(data, &gs); err != nil {
+		panic(fmt.Errorf("failed to unmarshal %s genesis state: %w", ModuleName, err))
+	}
+	if err := am.keeper.InitGenesis(ctx, &gs); err != nil {
+		panic(err)
+	}
+}
+
+// ExportGenesis exports the module's state as genesis
+func (am AppModule) ExportGenesis(ctx sdk.Context, cdc codec.JSONCodec) json.RawMessage {
+	bz, err := json.Marshal(am.keeper.ExportGenesis(ctx))
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// ConsensusVersion implements module.HasConsensusVersion
+func (AppModule) ConsensusVersion() uint64 { return ConsensusVersion }
+
 // IsOnePerModuleType implements the depinject.OnePerModuleType interface
 func (am AppModule) IsOnePerModuleType() {}
 
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// RestrictionLevel is how far an account's activity is restricted.
+// Cancels are accepted at every level.
+type RestrictionLevel string
+
+const (
+	RestrictionSuspended RestrictionLevel = "suspended" // new orders may only reduce a position
+	RestrictionFrozen    RestrictionLevel = "frozen"    // no orders, withdrawals or outgoing transfers
+)
+
+// Reason codes of account restrictions
+const (
+	RestrictionReasonCompliance = "compliance"
+	RestrictionReasonSanctions  = "sanctions"
+	RestrictionReasonFraud      = "fraud"
+	RestrictionReasonIncident   = "incident"
+	RestrictionReasonOther      = "other"
+)
+
+var restrictionReasonCodes = map[string]bool{
+	RestrictionReasonCompliance: true,
+	RestrictionReasonSanctions:  true,
+	RestrictionReasonFraud:      true,
+	RestrictionReasonIncident:   true,
+	RestrictionReasonOther:      true,
+}
+
+// AccountRestriction is an operator's suspension or freeze of an account.
+// It lapses at ExpiresAt; without one it stays until lifted.
+type AccountRestriction struct {
+	Trader     string
+	Level      RestrictionLevel
+	ReasonCode string
+	Reason     string // free-text detail for the record
+	Actor      string // operator who set it
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+// Validate checks the level and reason code
+func (r *AccountRestriction) Validate() error {
+	if r.Trader == "" {
+		return fmt.Errorf("%w: trader is required", ErrInvalidAccountRestriction)
+	}
+	if r.Level != RestrictionSuspended && r.Level != RestrictionFrozen {
+		return fmt.Errorf("%w: level must be %s or %s", ErrInvalidAccountRestriction, RestrictionSuspended, RestrictionFrozen)
+	}
+	if !restrictionReasonCodes[r.ReasonCode] {
+		return fmt.Errorf("%w: unknown reason code %q", ErrInvalidAccountRestriction, r.ReasonCode)
+	}
+	return nil
+}
+
+// IsActive returns true if the restriction has not expired at t
+func (r *AccountRestriction) IsActive(t time.Time) bool {
+	return r.ExpiresAt.IsZero() || t.Before(r.ExpiresAt)
+}
+
+// Err returns the error an action blocked by the restriction fails with.
+// It wraps ErrAccountSuspended or ErrAccountFrozen.
+func (r *AccountRestriction) Err() error {
+	sentinel := ErrAccountSuspended
+	if r.Level == RestrictionFrozen {
+		sentinel = ErrAccountFrozen
+	}
+	msg := fmt.Sprintf("%s (%s)", r.Trader, r.ReasonCode)
+	if !r.ExpiresAt.IsZero() {
+		msg += " until " + r.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	return fmt.Errorf("%w: %s", sentinel, msg)
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// Divergence guard defaults; see FundingConfig
+var (
+	DefaultDivergenceThreshold = math.LegacyNewDecWithPrec(2, 2) // 2% between mark and index
+	DefaultGuardBandFactor     = math.LegacyNewDecWithPrec(5, 1) // halve the price band
+	DefaultGuardDampening      = math.LegacyNewDecWithPrec(5, 1) // halve the premium
+)
+
+// DefaultDivergenceIntervals is how many funding settlements in a row the
+// mark must diverge from the index before the guard engages
+const DefaultDivergenceIntervals = 3
+
+// DivergenceGuard is the keeper-controlled guard state of a market whose
+// mark price drifts away from its index, where the funding it pays invites
+// pushing the mark further. Divergence is observed at every on-time funding
+// settlement; after DivergenceIntervals settlements in a row beyond the
+// threshold the guard engages, tightening the market's price bands and
+// damping the premium in its funding rate, until a settlement finds the
+// mark back within the threshold.
+type DivergenceGuard struct {
+	MarketID   string         `json:"market_id"`
+	Diverged   int64          `json:"diverged"`   // settlements in a row beyond the threshold
+	Divergence math.LegacyDec `json:"divergence"` // |mark - index| / index at the last settlement
+	Engaged    bool           `json:"engaged"`
+	EngagedAt  time.Time      `json:"engaged_at,omitempty"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// GuardEnabled reports whether the config enables the divergence guard
+func (c FundingConfig) GuardEnabled() bool {
+	return !c.DivergenceThreshold.IsNil() && c.DivergenceThreshold.IsPositive()
+}
+
+// Divergence returns |mark - index| / index, zero without an index
+func Divergence(mark, index math.LegacyDec) math.LegacyDec {
+	if mark.IsNil() || index.IsNil() || !index.IsPositive() {
+		return math.LegacyZeroDec()
+	}
+	return mark.Sub(index).Abs().Quo(index)
+}
+
+// Tighten narrows limits by the config's band factor while the guard is
+// engaged
+func (g *DivergenceGuard) Tighten(limits *OrderLimits, config FundingConfig) {
+	if g == nil || !g.Engaged {
+		return
+	}
+	limits.DivergenceGuard = true
+	factor := config.GuardBandFactor
+	if factor.IsNil() || !factor.IsPositive() {
+		return
+	}
+	if !limits.MaxPriceDeviation.IsNil() {
+		limits.MaxPriceDeviation = limits.MaxPriceDeviation.Mul(factor)
+	}
+	if !limits.MaxSlippage.IsNil() {
+		limits.MaxSlippage = limits.MaxSlippage.Mul(factor)
+	}
+}
@@ -26,6 +26,7 @@ var (
 	ErrInvalidMarketID                    = errors.Register("perpetual", 16, "invalid market ID")
 	ErrInvalidBaseAsset                   = errors.Register("perpetual", 17, "invalid base asset")
 	ErrInvalidQuoteAsset                  = errors.Register("perpetual", 18, "invalid quote asset")
+	ErrInvalidMarketPrecision             = errors.Register("perpetual", 19, "invalid market precision")
 
 	// Funding rate errors
 	ErrFundingNotDue                      = errors.Register("perpetual", 20, "funding settlement not due")
@@ -41,4 +42,37 @@ var (
 	ErrOrderSizeTooSmall                  = errors.Register("perpetual", 40, "order size below minimum")
 	ErrOrderSizeTooLarge                  = errors.Register("perpetual", 41, "order size above maximum")
 	ErrPositionSizeTooLarge               = errors.Register("perpetual", 42, "position size would exceed maximum")
+
+	// Trading schedule errors
+	ErrMarketMaintenance                  = errors.Register("perpetual", 50, "market is under maintenance")
+	ErrOutsideTradingHours                = errors.Register("perpetual", 51, "market is outside trading hours")
+	ErrInvalidTradingSchedule             = errors.Register("perpetual", 52, "invalid trading schedule")
+
+	// Withdrawal security errors
+	ErrInvalidWithdrawalSecurity          = errors.Register("perpetual", 70, "invalid withdrawal security")
+	ErrWithdrawalDestinationNotAllowed    = errors.Register("perpetual", 71, "withdrawal destination not allowlisted")
+	ErrWithdrawalNotFound                 = errors.Register("perpetual", 72, "withdrawal not found")
+	ErrWithdrawalNotCancellable           = errors.Register("perpetual", 73, "withdrawal can no longer be cancelled")
+
+	// Portfolio margin errors
+	ErrInvalidRiskArray                   = errors.Register("perpetual", 80, "invalid portfolio margin risk array")
+
+	// Internal transfer errors
+	ErrInvalidTransfer                    = errors.Register("perpetual", 90, "invalid internal transfer")
+	ErrTransferExceedsFreeCollateral      = errors.Register("perpetual", 91, "transfer exceeds free collateral")
+
+	// Account restriction errors
+	ErrAccountSuspended                   = errors.Register("perpetual", 92, "account is suspended")
+	ErrAccountFrozen                      = errors.Register("perpetual", 93, "account is frozen")
+	ErrInvalidAccountRestriction          = errors.Register("perpetual", 94, "invalid account restriction")
+	ErrAccountRestrictionNotFound         = errors.Register("perpetual", 95, "account restriction not found")
+
+	// Market warm-up errors
+	ErrInvalidWarmUp                      = errors.Register("perpetual", 96, "invalid market warm-up schedule")
+
+	// Position history errors
+	ErrInvalidCursor                      = errors.Register("perpetual", 97, "invalid position history cursor")
+
+	// Genesis errors
+	ErrInvalidGenesis                     = errors.Register("perpetual", 60, "invalid genesis state")
 )
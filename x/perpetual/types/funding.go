@@ -1,6 +1,7 @@
 package types
 
 import (
+	"fmt"
 	"time"
 
 	"cosmossdk.io/math"
@@ -48,30 +49,184 @@ func NewFundingPayment(paymentID, trader, marketID string, amount, rate math.Leg
 	}
 }
 
-// FundingConfig contains funding rate configuration
-// Updated parameters aligned with settlement schedule:
-// - Interval: 8 hours
-// - MaxRate: ±0.5%
+// FundingConfig contains a market's funding parameters, editable through
+// governance with MsgUpdateFundingConfig. Each settlement charges
+//
+//	rate = clamp(DampingFactor × (mark - index) / index + InterestRate, MinRate, MaxRate)
+//
+// All rates are per Interval. After a halt across settlement times, every
+// missed interval is settled on resume, up to MaxCatchUpIntervals of the
+// latest ones. While the market's divergence guard is engaged (see
+// DivergenceGuard) the premium term is reduced by GuardDampening.
 type FundingConfig struct {
 	Interval      int64          // Settlement interval in seconds (default: 28800 = 8 hours)
-	MaxRate       math.LegacyDec // Maximum funding rate per interval
-	MinRate       math.LegacyDec // Minimum funding rate per interval
-	DampingFactor math.LegacyDec // Damping factor for rate calculation (default: 0.05)
+	MaxRate       math.LegacyDec // Funding rate cap per interval
+	MinRate       math.LegacyDec // Funding rate floor per interval
+	DampingFactor math.LegacyDec // Premium dampener applied to the mark/index premium (default: 0.05)
+	InterestRate  math.LegacyDec // Interest rate component added each interval (default: 0)
+
+	MaxCatchUpIntervals int64 // Most intervals settled at once after a halt (default: 3); 1 disables catch-up
+
+	// Divergence guard; a zero DivergenceThreshold disables it
+	DivergenceThreshold math.LegacyDec // |mark - index| / index counted as divergence (default: 0.02)
+	DivergenceIntervals int64          // Settlements in a row beyond the threshold that engage the guard (default: 3)
+	GuardBandFactor     math.LegacyDec // Price band multiplier while engaged, in (0, 1] (default: 0.5)
+	GuardDampening      math.LegacyDec // Share of the premium removed while engaged, in [0, 1] (default: 0.5)
 }
 
+// MinFundingInterval is the shortest settlement interval a market may use
+const MinFundingInterval = 60
+
+// DefaultMaxCatchUpIntervals is how many due intervals are settled at once
+// after a halt, a day at the default 8 hour interval
+const DefaultMaxCatchUpIntervals = 3
+
 // DefaultFundingConfig returns the default funding configuration
 // Updated parameters aligned with settlement schedule:
 // - Interval: 8 hours (28800 seconds)
 // - MaxRate: 0.5% (0.005)
 // - MinRate: -0.5% (-0.005)
 // - DampingFactor: 0.05
+// - InterestRate: 0
 func DefaultFundingConfig() FundingConfig {
 	return FundingConfig{
 		Interval:      28800,                            // 8 hours
 		MaxRate:       math.LegacyNewDecWithPrec(5, 3),  // 0.005 = 0.5% (updated from 0.1%)
 		MinRate:       math.LegacyNewDecWithPrec(-5, 3), // -0.005 = -0.5% (updated from -0.1%)
 		DampingFactor: math.LegacyNewDecWithPrec(5, 2),  // 0.05 (updated from 0.03)
+		InterestRate:  math.LegacyZeroDec(),
+
+		MaxCatchUpIntervals: DefaultMaxCatchUpIntervals,
+
+		DivergenceThreshold: DefaultDivergenceThreshold,
+		DivergenceIntervals: DefaultDivergenceIntervals,
+		GuardBandFactor:     DefaultGuardBandFactor,
+		GuardDampening:      DefaultGuardDampening,
+	}
+}
+
+// WithDefaults fills unset fields from DefaultFundingConfig, so configs
+// stored before a field existed keep working
+func (c FundingConfig) WithDefaults() FundingConfig {
+	defaults := DefaultFundingConfig()
+	if c.Interval <= 0 {
+		c.Interval = defaults.Interval
+	}
+	if c.MaxRate.IsNil() {
+		c.MaxRate = defaults.MaxRate
+	}
+	if c.MinRate.IsNil() {
+		c.MinRate = defaults.MinRate
+	}
+	if c.DampingFactor.IsNil() {
+		c.DampingFactor = defaults.DampingFactor
+	}
+	if c.InterestRate.IsNil() {
+		c.InterestRate = defaults.InterestRate
+	}
+	if c.MaxCatchUpIntervals <= 0 {
+		c.MaxCatchUpIntervals = defaults.MaxCatchUpIntervals
+	}
+	if c.DivergenceThreshold.IsNil() {
+		c.DivergenceThreshold = defaults.DivergenceThreshold
+	}
+	if c.DivergenceIntervals <= 0 {
+		c.DivergenceIntervals = defaults.DivergenceIntervals
+	}
+	if c.GuardBandFactor.IsNil() {
+		c.GuardBandFactor = defaults.GuardBandFactor
+	}
+	if c.GuardDampening.IsNil() {
+		c.GuardDampening = defaults.GuardDampening
+	}
+	return c
+}
+
+// Validate checks that the cap and floor bracket zero and the interest
+// rate, that the premium dampener is within [0, 1] and that the divergence
+// guard only tightens bands and dampens the premium. Unset guard fields
+// take their defaults.
+func (c FundingConfig) Validate() error {
+	if c.Interval < MinFundingInterval {
+		return fmt.Errorf("%w: interval must be at least %d seconds", ErrInvalidFundingConfig, MinFundingInterval)
+	}
+	if c.MaxRate.IsNil() || c.MinRate.IsNil() || c.DampingFactor.IsNil() || c.InterestRate.IsNil() {
+		return fmt.Errorf("%w: all rates must be set", ErrInvalidFundingConfig)
 	}
+	if c.MaxRate.IsNegative() || c.MinRate.IsPositive() {
+		return fmt.Errorf("%w: cap must be >= 0 and floor <= 0", ErrInvalidFundingConfig)
+	}
+	if c.DampingFactor.IsNegative() || c.DampingFactor.GT(math.LegacyOneDec()) {
+		return fmt.Errorf("%w: damping factor must be within [0, 1]", ErrInvalidFundingConfig)
+	}
+	if c.InterestRate.GT(c.MaxRate) || c.InterestRate.LT(c.MinRate) {
+		return fmt.Errorf("%w: interest rate must be within [floor, cap]", ErrInvalidFundingConfig)
+	}
+	if c.MaxCatchUpIntervals < 0 {
+		return fmt.Errorf("%w: max catch-up intervals must not be negative", ErrInvalidFundingConfig)
+	}
+	if c.DivergenceIntervals < 0 {
+		return fmt.Errorf("%w: divergence intervals must not be negative", ErrInvalidFundingConfig)
+	}
+	if !c.DivergenceThreshold.IsNil() && c.DivergenceThreshold.IsNegative() {
+		return fmt.Errorf("%w: divergence threshold must not be negative", ErrInvalidFundingConfig)
+	}
+	if !c.GuardBandFactor.IsNil() && (!c.GuardBandFactor.IsPositive() || c.GuardBandFactor.GT(math.LegacyOneDec())) {
+		return fmt.Errorf("%w: guard band factor must be within (0, 1]", ErrInvalidFundingConfig)
+	}
+	if !c.GuardDampening.IsNil() && (c.GuardDampening.IsNegative() || c.GuardDampening.GT(math.LegacyOneDec())) {
+		return fmt.Errorf("%w: guard dampening must be within [0, 1]", ErrInvalidFundingConfig)
+	}
+	return nil
+}
+
+// Clamp limits rate to [MinRate, MaxRate]
+func (c FundingConfig) Clamp(rate math.LegacyDec) math.LegacyDec {
+	if rate.GT(c.MaxRate) {
+		return c.MaxRate
+	}
+	if rate.LT(c.MinRate) {
+		return c.MinRate
+	}
+	return rate
+}
+
+// NextSettlement returns the first interval boundary after now. Boundaries
+// are multiples of Interval since the Unix epoch, so intervals that divide
+// a day settle at the same UTC times every day (00:00, 08:00 and 16:00 for 8h).
+func (c FundingConfig) NextSettlement(now time.Time) time.Time {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultFundingConfig().Interval
+	}
+	return time.Unix((now.Unix()/interval+1)*interval, 0).UTC()
+}
+
+// DueSettlements returns the settlement times due by now, starting from the
+// scheduled next one and one interval apart. Only the latest
+// MaxCatchUpIntervals are returned; skipped counts the older ones left out.
+func (c FundingConfig) DueSettlements(next, now time.Time) (due []time.Time, skipped int64) {
+	if now.Before(next) {
+		return nil, 0
+	}
+	c = c.WithDefaults()
+	interval := time.Duration(c.Interval) * time.Second
+	count := int64(now.Sub(next)/interval) + 1
+	if count > c.MaxCatchUpIntervals {
+		skipped = count - c.MaxCatchUpIntervals
+		count = c.MaxCatchUpIntervals
+	}
+	due = make([]time.Time, count)
+	for i := range due {
+		due[i] = next.Add(time.Duration(skipped+int64(i)) * interval)
+	}
+	return due, skipped
+}
+
+// MarketFundingConfig is a market's funding configuration in genesis
+type MarketFundingConfig struct {
+	MarketID string        `json:"market_id"`
+	Config   FundingConfig `json:"config"`
 }
 
 // FundingInfo contains current funding information for a market
@@ -0,0 +1,260 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// GenesisState is the perpetual module's exported state
+type GenesisState struct {
+	Markets          []*Market             `json:"markets"`
+	Prices           []*PriceInfo          `json:"prices"`
+	NextFundingTimes []NextFundingTime     `json:"next_funding_times"`
+	FundingConfigs   []MarketFundingConfig `json:"funding_configs"`
+	TradingSchedules []*TradingSchedule    `json:"trading_schedules"`
+	DivergenceGuards []*DivergenceGuard    `json:"divergence_guards"`
+	Accounts         []*Account            `json:"accounts"`
+	Positions        []*Position           `json:"positions"`
+
+	WithdrawalSecurity  []*WithdrawalSecurity `json:"withdrawal_security"`
+	WithdrawalAddresses []*WithdrawalAddress  `json:"withdrawal_addresses"`
+	PendingWithdrawals  []*Withdrawal         `json:"pending_withdrawals"`
+
+	RiskArray *RiskArray `json:"risk_array,omitempty"` // nil keeps the default
+
+	AccountRestrictions []*AccountRestriction `json:"account_restrictions"`
+
+	ClosedPositions     []*ClosedPosition `json:"closed_positions"`
+	ClosedPositionCount uint64            `json:"closed_position_count"`
+}
+
+// NextFundingTime is the next funding settlement of a market
+type NextFundingTime struct {
+	MarketID string    `json:"market_id"`
+	Time     time.Time `json:"time"`
+}
+
+// DefaultGenesis returns an empty perpetual state. A genesis without markets
+// starts with the default market.
+func DefaultGenesis() *GenesisState {
+	return &GenesisState{
+		Markets:          make([]*Market, 0),
+		Prices:           make([]*PriceInfo, 0),
+		NextFundingTimes: make([]NextFundingTime, 0),
+		FundingConfigs:   make([]MarketFundingConfig, 0),
+		TradingSchedules: make([]*TradingSchedule, 0),
+		DivergenceGuards: make([]*DivergenceGuard, 0),
+		Accounts:         make([]*Account, 0),
+		Positions:        make([]*Position, 0),
+
+		WithdrawalSecurity:  make([]*WithdrawalSecurity, 0),
+		WithdrawalAddresses: make([]*WithdrawalAddress, 0),
+		PendingWithdrawals:  make([]*Withdrawal, 0),
+
+		AccountRestrictions: make([]*AccountRestriction, 0),
+
+		ClosedPositions: make([]*ClosedPosition, 0),
+	}
+}
+
+// Validate checks for duplicates and that every entry belongs to a known market
+func (gs *GenesisState) Validate() error {
+	markets := make(map[string]bool, len(gs.Markets))
+	for _, market := range gs.Markets {
+		if market == nil || market.MarketID == "" {
+			return fmt.Errorf("%w: market without ID", ErrInvalidGenesis)
+		}
+		if markets[market.MarketID] {
+			return fmt.Errorf("%w: duplicate market %s", ErrInvalidGenesis, market.MarketID)
+		}
+		markets[market.MarketID] = true
+	}
+	knownMarket := func(kind, marketID string) error {
+		if !markets[marketID] {
+			return fmt.Errorf("%w: %s for unknown market %q", ErrInvalidGenesis, kind, marketID)
+		}
+		return nil
+	}
+
+	prices := make(map[string]bool, len(gs.Prices))
+	for _, price := range gs.Prices {
+		if price == nil {
+			return fmt.Errorf("%w: empty price", ErrInvalidGenesis)
+		}
+		if err := knownMarket("price", price.MarketID); err != nil {
+			return err
+		}
+		if prices[price.MarketID] {
+			return fmt.Errorf("%w: duplicate price for %s", ErrInvalidGenesis, price.MarketID)
+		}
+		if price.MarkPrice.IsNil() || !price.MarkPrice.IsPositive() {
+			return fmt.Errorf("%w: mark price for %s must be positive", ErrInvalidGenesis, price.MarketID)
+		}
+		prices[price.MarketID] = true
+	}
+
+	for _, next := range gs.NextFundingTimes {
+		if err := knownMarket("funding time", next.MarketID); err != nil {
+			return err
+		}
+	}
+
+	fundingConfigs := make(map[string]bool, len(gs.FundingConfigs))
+	for _, fc := range gs.FundingConfigs {
+		if err := knownMarket("funding config", fc.MarketID); err != nil {
+			return err
+		}
+		if fundingConfigs[fc.MarketID] {
+			return fmt.Errorf("%w: duplicate funding config for %s", ErrInvalidGenesis, fc.MarketID)
+		}
+		if err := fc.Config.Validate(); err != nil {
+			return fmt.Errorf("%w: funding config %s: %v", ErrInvalidGenesis, fc.MarketID, err)
+		}
+		fundingConfigs[fc.MarketID] = true
+	}
+
+	for _, schedule := range gs.TradingSchedules {
+		if schedule == nil {
+			return fmt.Errorf("%w: empty trading schedule", ErrInvalidGenesis)
+		}
+		if err := knownMarket("trading schedule", schedule.MarketID); err != nil {
+			return err
+		}
+		if err := schedule.Validate(); err != nil {
+			return fmt.Errorf("%w: trading schedule %s: %v", ErrInvalidGenesis, schedule.MarketID, err)
+		}
+	}
+
+	guards := make(map[string]bool, len(gs.DivergenceGuards))
+	for _, guard := range gs.DivergenceGuards {
+		if guard == nil {
+			return fmt.Errorf("%w: empty divergence guard", ErrInvalidGenesis)
+		}
+		if err := knownMarket("divergence guard", guard.MarketID); err != nil {
+			return err
+		}
+		if guards[guard.MarketID] {
+			return fmt.Errorf("%w: duplicate divergence guard for %s", ErrInvalidGenesis, guard.MarketID)
+		}
+		if guard.Diverged < 0 || guard.Divergence.IsNil() || guard.Divergence.IsNegative() {
+			return fmt.Errorf("%w: invalid divergence guard for %s", ErrInvalidGenesis, guard.MarketID)
+		}
+		guards[guard.MarketID] = true
+	}
+
+	accounts := make(map[string]bool, len(gs.Accounts))
+	for _, account := range gs.Accounts {
+		if account == nil || account.Trader == "" {
+			return fmt.Errorf("%w: account without trader", ErrInvalidGenesis)
+		}
+		if accounts[account.Trader] {
+			return fmt.Errorf("%w: duplicate account %s", ErrInvalidGenesis, account.Trader)
+		}
+		if account.Balance.IsNil() || account.LockedMargin.IsNil() ||
+			account.Balance.IsNegative() || account.LockedMargin.IsNegative() {
+			return fmt.Errorf("%w: account %s has a negative balance", ErrInvalidGenesis, account.Trader)
+		}
+		accounts[account.Trader] = true
+	}
+
+	positions := make(map[string]bool, len(gs.Positions))
+	for _, position := range gs.Positions {
+		if position == nil || position.Trader == "" {
+			return fmt.Errorf("%w: position without trader", ErrInvalidGenesis)
+		}
+		if err := knownMarket("position", position.MarketID); err != nil {
+			return err
+		}
+		key := position.Trader + ":" + position.MarketID
+		if positions[key] {
+			return fmt.Errorf("%w: duplicate position %s", ErrInvalidGenesis, key)
+		}
+		if position.Side != PositionSideLong && position.Side != PositionSideShort {
+			return fmt.Errorf("%w: position %s has no side", ErrInvalidGenesis, key)
+		}
+		if position.Size.IsNil() || !position.Size.IsPositive() {
+			return fmt.Errorf("%w: position %s must have a positive size", ErrInvalidGenesis, key)
+		}
+		positions[key] = true
+	}
+
+	secured := make(map[string]bool, len(gs.WithdrawalSecurity))
+	for _, security := range gs.WithdrawalSecurity {
+		if security == nil {
+			return fmt.Errorf("%w: empty withdrawal security", ErrInvalidGenesis)
+		}
+		if err := security.Validate(); err != nil {
+			return fmt.Errorf("%w: withdrawal security %s: %v", ErrInvalidGenesis, security.Trader, err)
+		}
+		if secured[security.Trader] {
+			return fmt.Errorf("%w: duplicate withdrawal security for %s", ErrInvalidGenesis, security.Trader)
+		}
+		secured[security.Trader] = true
+	}
+	addresses := make(map[string]bool, len(gs.WithdrawalAddresses))
+	for _, address := range gs.WithdrawalAddresses {
+		if address == nil || address.Trader == "" || address.Address == "" {
+			return fmt.Errorf("%w: withdrawal address without trader or address", ErrInvalidGenesis)
+		}
+		key := address.Trader + ":" + address.Address
+		if addresses[key] {
+			return fmt.Errorf("%w: duplicate withdrawal address %s", ErrInvalidGenesis, key)
+		}
+		addresses[key] = true
+	}
+	withdrawals := make(map[string]bool, len(gs.PendingWithdrawals))
+	for _, withdrawal := range gs.PendingWithdrawals {
+		if withdrawal == nil || withdrawal.WithdrawalID == "" || withdrawal.Trader == "" {
+			return fmt.Errorf("%w: withdrawal without ID or trader", ErrInvalidGenesis)
+		}
+		if withdrawals[withdrawal.WithdrawalID] {
+			return fmt.Errorf("%w: duplicate withdrawal %s", ErrInvalidGenesis, withdrawal.WithdrawalID)
+		}
+		if withdrawal.Status != WithdrawalPending {
+			return fmt.Errorf("%w: withdrawal %s is not pending", ErrInvalidGenesis, withdrawal.WithdrawalID)
+		}
+		if withdrawal.Amount.IsNil() || !withdrawal.Amount.IsPositive() {
+			return fmt.Errorf("%w: withdrawal %s must have a positive amount", ErrInvalidGenesis, withdrawal.WithdrawalID)
+		}
+		withdrawals[withdrawal.WithdrawalID] = true
+	}
+
+	if gs.RiskArray != nil {
+		if err := gs.RiskArray.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidGenesis, err)
+		}
+	}
+
+	restricted := make(map[string]bool, len(gs.AccountRestrictions))
+	for _, restriction := range gs.AccountRestrictions {
+		if restriction == nil {
+			return fmt.Errorf("%w: empty account restriction", ErrInvalidGenesis)
+		}
+		if err := restriction.Validate(); err != nil {
+			return fmt.Errorf("%w: account restriction %s: %v", ErrInvalidGenesis, restriction.Trader, err)
+		}
+		if restricted[restriction.Trader] {
+			return fmt.Errorf("%w: duplicate account restriction for %s", ErrInvalidGenesis, restriction.Trader)
+		}
+		restricted[restriction.Trader] = true
+	}
+
+	closed := make(map[uint64]bool, len(gs.ClosedPositions))
+	for _, record := range gs.ClosedPositions {
+		if record == nil || record.Trader == "" {
+			return fmt.Errorf("%w: closed position without trader", ErrInvalidGenesis)
+		}
+		seq, err := ParseClosedPositionID(record.PositionID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidGenesis, err)
+		}
+		if closed[seq] {
+			return fmt.Errorf("%w: duplicate closed position %s", ErrInvalidGenesis, record.PositionID)
+		}
+		if seq > gs.ClosedPositionCount {
+			return fmt.Errorf("%w: closed position %s is beyond the count of %d", ErrInvalidGenesis, record.PositionID, gs.ClosedPositionCount)
+		}
+		closed[seq] = true
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"cosmossdk.io/math"
+)
+
+// LongShortStats aggregates the open positions of a market by side: how
+// many accounts hold each side and their total size
+type LongShortStats struct {
+	MarketID      string
+	LongAccounts  int64
+	ShortAccounts int64
+	LongSize      math.LegacyDec
+	ShortSize     math.LegacyDec
+}
+
+// NewLongShortStats returns the stats of a market without positions
+func NewLongShortStats(marketID string) *LongShortStats {
+	return &LongShortStats{
+		MarketID:  marketID,
+		LongSize:  math.LegacyZeroDec(),
+		ShortSize: math.LegacyZeroDec(),
+	}
+}
+
+// Apply adds a position to the stats, or removes it if remove is set.
+// Positions without size count on neither side.
+func (s *LongShortStats) Apply(p *Position, remove bool) {
+	if p == nil || p.Size.IsNil() || !p.Size.IsPositive() {
+		return
+	}
+	accounts, size := int64(1), p.Size
+	if remove {
+		accounts, size = -1, size.Neg()
+	}
+	if p.Side == PositionSideLong {
+		s.LongAccounts += accounts
+		s.LongSize = s.LongSize.Add(size)
+	} else {
+		s.ShortAccounts += accounts
+		s.ShortSize = s.ShortSize.Add(size)
+	}
+}
+
+// AccountRatio is long accounts over short accounts, zero while no account
+// is short
+func (s *LongShortStats) AccountRatio() math.LegacyDec {
+	if s.ShortAccounts <= 0 {
+		return math.LegacyZeroDec()
+	}
+	return math.LegacyNewDec(s.LongAccounts).QuoInt64(s.ShortAccounts)
+}
+
+// SizeRatio is long size over short size, zero while nothing is short
+func (s *LongShortStats) SizeRatio() math.LegacyDec {
+	if !s.ShortSize.IsPositive() {
+		return math.LegacyZeroDec()
+	}
+	return s.LongSize.Quo(s.ShortSize)
+}
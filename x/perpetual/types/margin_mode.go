@@ -4,8 +4,9 @@ package types
 type MarginMode int
 
 const (
-	MarginModeIsolated MarginMode = iota // Isolated margin mode (per-position)
-	MarginModeCross                      // Cross margin mode (shared across positions)
+	MarginModeIsolated  MarginMode = iota // Isolated margin mode (per-position)
+	MarginModeCross                       // Cross margin mode (shared across positions)
+	MarginModePortfolio                   // Portfolio margin mode (shared, margined by stress scenarios)
 )
 
 // String returns the string representation of MarginMode
@@ -13,6 +14,8 @@ func (m MarginMode) String() string {
 	switch m {
 	case MarginModeCross:
 		return "cross"
+	case MarginModePortfolio:
+		return "portfolio"
 	default:
 		return "isolated"
 	}
@@ -27,3 +30,8 @@ func (m MarginMode) IsCross() bool {
 func (m MarginMode) IsIsolated() bool {
 	return m == MarginModeIsolated
 }
+
+// IsPortfolio returns true if the margin mode is portfolio
+func (m MarginMode) IsPortfolio() bool {
+	return m == MarginModePortfolio
+}
@@ -12,19 +12,31 @@ func RegisterInterfaces(registry cdctypes.InterfaceRegistry) {
 	registry.RegisterImplementations((*sdk.Msg)(nil),
 		&MsgDeposit{},
 		&MsgWithdraw{},
+		&MsgUpdateTradingSchedule{},
+		&MsgUpdateFundingConfig{},
+		&MsgCancelWithdrawal{},
+		&MsgInternalTransfer{},
 	)
 }
 
 // Message types for perpetual module
 const (
-	TypeMsgDeposit  = "deposit"
-	TypeMsgWithdraw = "withdraw"
+	TypeMsgDeposit               = "deposit"
+	TypeMsgWithdraw              = "withdraw"
+	TypeMsgUpdateTradingSchedule = "update_trading_schedule"
+	TypeMsgUpdateFundingConfig   = "update_funding_config"
+	TypeMsgCancelWithdrawal      = "cancel_withdrawal"
+	TypeMsgInternalTransfer      = "internal_transfer"
 )
 
 // MsgServer defines the perpetual module's gRPC message service
 type MsgServer interface {
 	Deposit(context.Context, *MsgDeposit) (*MsgDepositResponse, error)
 	Withdraw(context.Context, *MsgWithdraw) (*MsgWithdrawResponse, error)
+	UpdateTradingSchedule(context.Context, *MsgUpdateTradingSchedule) (*MsgUpdateTradingScheduleResponse, error)
+	UpdateFundingConfig(context.Context, *MsgUpdateFundingConfig) (*MsgUpdateFundingConfigResponse, error)
+	CancelWithdrawal(context.Context, *MsgCancelWithdrawal) (*MsgCancelWithdrawalResponse, error)
+	InternalTransfer(context.Context, *MsgInternalTransfer) (*MsgInternalTransferResponse, error)
 }
 
 // RegisterMsgServer registers the MsgServer to the configurator's MsgServer
@@ -39,10 +51,12 @@ type MsgDeposit struct {
 	Amount string `json:"amount"`
 }
 
-// MsgWithdraw represents a margin withdraw message
+// MsgWithdraw represents a margin withdraw message. Destination defaults to
+// the trader; the account's withdrawal timelock and allowlist apply.
 type MsgWithdraw struct {
-	Trader string `json:"trader"`
-	Amount string `json:"amount"`
+	Trader      string `json:"trader"`
+	Amount      string `json:"amount"`
+	Destination string `json:"destination,omitempty"`
 }
 
 // Proto interface implementations for MsgDeposit
@@ -103,12 +117,188 @@ func (msg *MsgDepositResponse) Reset()         { *msg = MsgDepositResponse{} }
 func (msg *MsgDepositResponse) String() string { return msg.NewBalance }
 func (msg *MsgDepositResponse) ProtoMessage()  {}
 
-// MsgWithdrawResponse is the response for MsgWithdraw
+// MsgWithdrawResponse is the response for MsgWithdraw. A timelocked
+// withdrawal is pending until ReleaseAt.
 type MsgWithdrawResponse struct {
-	NewBalance string `json:"new_balance"`
+	NewBalance   string `json:"new_balance"`
+	WithdrawalID string `json:"withdrawal_id"`
+	Status       string `json:"status"`
+	ReleaseAt    int64  `json:"release_at"` // Unix seconds
 }
 
 // Proto interface implementations for MsgWithdrawResponse
 func (msg *MsgWithdrawResponse) Reset()         { *msg = MsgWithdrawResponse{} }
 func (msg *MsgWithdrawResponse) String() string { return msg.NewBalance }
 func (msg *MsgWithdrawResponse) ProtoMessage()  {}
+
+// MsgUpdateTradingSchedule replaces a market's trading schedule through governance.
+// A schedule without trading hours or maintenance windows removes it.
+type MsgUpdateTradingSchedule struct {
+	Authority string          `json:"authority"`
+	Schedule  TradingSchedule `json:"schedule"`
+}
+
+// Proto interface implementations for MsgUpdateTradingSchedule
+func (msg *MsgUpdateTradingSchedule) Reset()         { *msg = MsgUpdateTradingSchedule{} }
+func (msg *MsgUpdateTradingSchedule) String() string { return msg.Schedule.MarketID }
+func (msg *MsgUpdateTradingSchedule) ProtoMessage()  {}
+
+// XXX_MessageName returns the message type URL for MsgUpdateTradingSchedule
+func (msg *MsgUpdateTradingSchedule) XXX_MessageName() string {
+	return "perpdex.perpetual.v1.MsgUpdateTradingSchedule"
+}
+
+// ValidateBasic for MsgUpdateTradingSchedule
+func (msg *MsgUpdateTradingSchedule) ValidateBasic() error {
+	if msg.Authority == "" {
+		return ErrUnauthorized
+	}
+	return msg.Schedule.Validate()
+}
+
+// GetSigners returns the signer addresses for MsgUpdateTradingSchedule
+func (msg *MsgUpdateTradingSchedule) GetSigners() []sdk.AccAddress {
+	authority, _ := sdk.AccAddressFromBech32(msg.Authority)
+	return []sdk.AccAddress{authority}
+}
+
+// MsgUpdateTradingScheduleResponse is the response for MsgUpdateTradingSchedule
+type MsgUpdateTradingScheduleResponse struct{}
+
+// Proto interface implementations for MsgUpdateTradingScheduleResponse
+func (msg *MsgUpdateTradingScheduleResponse) Reset()         { *msg = MsgUpdateTradingScheduleResponse{} }
+func (msg *MsgUpdateTradingScheduleResponse) String() string { return "" }
+func (msg *MsgUpdateTradingScheduleResponse) ProtoMessage()  {}
+
+// MsgUpdateFundingConfig replaces a market's funding parameters through governance
+type MsgUpdateFundingConfig struct {
+	Authority string        `json:"authority"`
+	MarketID  string        `json:"market_id"`
+	Config    FundingConfig `json:"config"`
+}
+
+// Proto interface implementations for MsgUpdateFundingConfig
+func (msg *MsgUpdateFundingConfig) Reset()         { *msg = MsgUpdateFundingConfig{} }
+func (msg *MsgUpdateFundingConfig) String() string { return msg.MarketID }
+func (msg *MsgUpdateFundingConfig) ProtoMessage()  {}
+
+// XXX_MessageName returns the message type URL for MsgUpdateFundingConfig
+func (msg *MsgUpdateFundingConfig) XXX_MessageName() string {
+	return "perpdex.perpetual.v1.MsgUpdateFundingConfig"
+}
+
+// ValidateBasic for MsgUpdateFundingConfig
+func (msg *MsgUpdateFundingConfig) ValidateBasic() error {
+	if msg.Authority == "" {
+		return ErrUnauthorized
+	}
+	if msg.MarketID == "" {
+		return ErrMarketNotFound
+	}
+	return msg.Config.Validate()
+}
+
+// GetSigners returns the signer addresses for MsgUpdateFundingConfig
+func (msg *MsgUpdateFundingConfig) GetSigners() []sdk.AccAddress {
+	authority, _ := sdk.AccAddressFromBech32(msg.Authority)
+	return []sdk.AccAddress{authority}
+}
+
+// MsgUpdateFundingConfigResponse is the response for MsgUpdateFundingConfig
+type MsgUpdateFundingConfigResponse struct {
+	NextFundingTime int64 `json:"next_funding_time"` // Unix seconds of the next settlement
+}
+
+// Proto interface implementations for MsgUpdateFundingConfigResponse
+func (msg *MsgUpdateFundingConfigResponse) Reset()         { *msg = MsgUpdateFundingConfigResponse{} }
+func (msg *MsgUpdateFundingConfigResponse) String() string { return "" }
+func (msg *MsgUpdateFundingConfigResponse) ProtoMessage()  {}
+
+// MsgCancelWithdrawal aborts a timelocked withdrawal before its release
+type MsgCancelWithdrawal struct {
+	Trader       string `json:"trader"`
+	WithdrawalID string `json:"withdrawal_id"`
+}
+
+// Proto interface implementations for MsgCancelWithdrawal
+func (msg *MsgCancelWithdrawal) Reset()         { *msg = MsgCancelWithdrawal{} }
+func (msg *MsgCancelWithdrawal) String() string { return msg.WithdrawalID }
+func (msg *MsgCancelWithdrawal) ProtoMessage()  {}
+
+// XXX_MessageName returns the message type URL for MsgCancelWithdrawal
+func (msg *MsgCancelWithdrawal) XXX_MessageName() string {
+	return "perpdex.perpetual.v1.MsgCancelWithdrawal"
+}
+
+// ValidateBasic for MsgCancelWithdrawal
+func (msg *MsgCancelWithdrawal) ValidateBasic() error {
+	if msg.Trader == "" {
+		return ErrUnauthorized
+	}
+	if msg.WithdrawalID == "" {
+		return ErrWithdrawalNotFound
+	}
+	return nil
+}
+
+// GetSigners returns the signer addresses for MsgCancelWithdrawal
+func (msg *MsgCancelWithdrawal) GetSigners() []sdk.AccAddress {
+	trader, _ := sdk.AccAddressFromBech32(msg.Trader)
+	return []sdk.AccAddress{trader}
+}
+
+// MsgCancelWithdrawalResponse is the response for MsgCancelWithdrawal
+type MsgCancelWithdrawalResponse struct {
+	NewBalance string `json:"new_balance"`
+}
+
+// Proto interface implementations for MsgCancelWithdrawalResponse
+func (msg *MsgCancelWithdrawalResponse) Reset()         { *msg = MsgCancelWithdrawalResponse{} }
+func (msg *MsgCancelWithdrawalResponse) String() string { return msg.NewBalance }
+func (msg *MsgCancelWithdrawalResponse) ProtoMessage()  {}
+
+// MsgInternalTransfer moves free collateral from the sender's account to
+// another platform account at once, e.g. to a market making subaccount
+type MsgInternalTransfer struct {
+	Sender    string `json:"sender"`
+	Recipient string `json:"recipient"`
+	Amount    string `json:"amount"`
+}
+
+// Proto interface implementations for MsgInternalTransfer
+func (msg *MsgInternalTransfer) Reset()         { *msg = MsgInternalTransfer{} }
+func (msg *MsgInternalTransfer) String() string { return msg.Sender }
+func (msg *MsgInternalTransfer) ProtoMessage()  {}
+
+// XXX_MessageName returns the message type URL for MsgInternalTransfer
+func (msg *MsgInternalTransfer) XXX_MessageName() string {
+	return "perpdex.perpetual.v1.MsgInternalTransfer"
+}
+
+// ValidateBasic for MsgInternalTransfer
+func (msg *MsgInternalTransfer) ValidateBasic() error {
+	if msg.Sender == "" {
+		return ErrUnauthorized
+	}
+	if msg.Recipient == "" || msg.Recipient == msg.Sender {
+		return ErrInvalidTransfer
+	}
+	return nil
+}
+
+// GetSigners returns the signer addresses for MsgInternalTransfer
+func (msg *MsgInternalTransfer) GetSigners() []sdk.AccAddress {
+	sender, _ := sdk.AccAddressFromBech32(msg.Sender)
+	return []sdk.AccAddress{sender}
+}
+
+// MsgInternalTransferResponse is the response for MsgInternalTransfer
+type MsgInternalTransferResponse struct {
+	TransferID string `json:"transfer_id"`
+	NewBalance string `json:"new_balance"`
+}
+
+// Proto interface implementations for MsgInternalTransferResponse
+func (msg *MsgInternalTransferResponse) Reset()         { *msg = MsgInternalTransferResponse{} }
+func (msg *MsgInternalTransferResponse) String() string { return msg.TransferID }
+func (msg *MsgInternalTransferResponse) ProtoMessage()  {}
@@ -0,0 +1,45 @@
+package types
+
+import "cosmossdk.io/math"
+
+// PositionExposure is one open position valued at its market's mark price,
+// or at its entry price while the market has none
+type PositionExposure struct {
+	MarketID      string
+	BaseAsset     string
+	Side          PositionSide
+	Size          math.LegacyDec
+	MarkPrice     math.LegacyDec
+	Notional      math.LegacyDec // size × mark price
+	Margin        math.LegacyDec
+	UnrealizedPnL math.LegacyDec
+	Leverage      math.LegacyDec // notional / margin; zero without margin
+	EquityShare   math.LegacyDec // notional / account equity; zero without equity
+}
+
+// AssetExposure nets a trader's positions in one base asset. The adjusted
+// net notional is what is left of it once the risk array's correlation
+// offsets have netted it against opposite exposures in correlated assets.
+type AssetExposure struct {
+	Asset               string
+	LongNotional        math.LegacyDec
+	ShortNotional       math.LegacyDec
+	NetNotional         math.LegacyDec // long positive, short negative
+	AdjustedNetNotional math.LegacyDec
+}
+
+// PortfolioExposure is a trader's positions and the risk metrics derived
+// from them across the whole account
+type PortfolioExposure struct {
+	Trader           string
+	Equity           math.LegacyDec // balance + unrealized PnL
+	Positions        []*PositionExposure
+	Assets           []*AssetExposure
+	LongNotional     math.LegacyDec
+	ShortNotional    math.LegacyDec
+	GrossNotional    math.LegacyDec // long + short
+	NetNotional      math.LegacyDec // long - short
+	AdjustedNotional math.LegacyDec // sum of the assets' absolute adjusted net notionals
+	Leverage         math.LegacyDec // gross notional / equity; zero without equity
+	Skew             math.LegacyDec // net / gross notional in [-1, 1]: 1 all long, -1 all short
+}
@@ -0,0 +1,140 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// AssetShock is the price move, as a fraction of the mark price, a base
+// asset is stressed by in both directions
+type AssetShock struct {
+	Asset string         `json:"asset"`
+	Shock math.LegacyDec `json:"shock"`
+}
+
+// CorrelationOffset credits a hedge between two correlated base assets: when
+// a book loses on one asset as the other gains, Offset of the smaller of the
+// two scan risks is taken off each of them
+type CorrelationOffset struct {
+	AssetA string         `json:"asset_a"`
+	AssetB string         `json:"asset_b"`
+	Offset math.LegacyDec `json:"offset"`
+}
+
+// RiskArray configures portfolio margin. Each base asset a trader holds is
+// stressed by ±its shock at the mark price, the worse of the two losses being
+// the asset's scan risk. Correlation offsets then credit hedges across assets,
+// applied in order, each consuming the scan risk it credits so no loss is
+// offset twice. The requirement is the remaining scan risk, floored at
+// MinMarginRate of the gross notional; the maintenance requirement is
+// MaintenanceFraction of it.
+type RiskArray struct {
+	DefaultShock        math.LegacyDec      `json:"default_shock"` // for assets without a shock of their own
+	Shocks              []AssetShock        `json:"shocks"`
+	Offsets             []CorrelationOffset `json:"offsets"`
+	MinMarginRate       math.LegacyDec      `json:"min_margin_rate"`
+	MaintenanceFraction math.LegacyDec      `json:"maintenance_fraction"`
+	UpdatedAt           time.Time           `json:"updated_at"`
+}
+
+// DefaultRiskArray stresses every asset by ±5% with no correlation offsets,
+// so a lone position needs the 5% initial and 2.5% maintenance margin of
+// the default market
+func DefaultRiskArray() *RiskArray {
+	return &RiskArray{
+		DefaultShock:        math.LegacyNewDecWithPrec(5, 2),
+		Shocks:              make([]AssetShock, 0),
+		Offsets:             make([]CorrelationOffset, 0),
+		MinMarginRate:       math.LegacyNewDecWithPrec(1, 2),
+		MaintenanceFraction: math.LegacyNewDecWithPrec(5, 1),
+	}
+}
+
+// ShockFor returns the shock an asset is stressed by
+func (r *RiskArray) ShockFor(asset string) math.LegacyDec {
+	for _, shock := range r.Shocks {
+		if shock.Asset == asset {
+			return shock.Shock
+		}
+	}
+	return r.DefaultShock
+}
+
+// Validate checks shocks are in (0, 1], offsets in [0, 1] between two
+// different assets, and the floor and maintenance fraction are in range
+func (r *RiskArray) Validate() error {
+	validShock := func(shock math.LegacyDec) bool {
+		return !shock.IsNil() && shock.IsPositive() && shock.LTE(math.LegacyOneDec())
+	}
+	if !validShock(r.DefaultShock) {
+		return fmt.Errorf("%w: default shock must be in (0, 1]", ErrInvalidRiskArray)
+	}
+	shocked := make(map[string]bool, len(r.Shocks))
+	for _, shock := range r.Shocks {
+		if shock.Asset == "" {
+			return fmt.Errorf("%w: shock without asset", ErrInvalidRiskArray)
+		}
+		if shocked[shock.Asset] {
+			return fmt.Errorf("%w: duplicate shock for %s", ErrInvalidRiskArray, shock.Asset)
+		}
+		if !validShock(shock.Shock) {
+			return fmt.Errorf("%w: shock for %s must be in (0, 1]", ErrInvalidRiskArray, shock.Asset)
+		}
+		shocked[shock.Asset] = true
+	}
+	pairs := make(map[string]bool, len(r.Offsets))
+	for _, offset := range r.Offsets {
+		if offset.AssetA == "" || offset.AssetB == "" || offset.AssetA == offset.AssetB {
+			return fmt.Errorf("%w: offset must be between two different assets", ErrInvalidRiskArray)
+		}
+		pair := offset.AssetA + "/" + offset.AssetB
+		if offset.AssetB < offset.AssetA {
+			pair = offset.AssetB + "/" + offset.AssetA
+		}
+		if pairs[pair] {
+			return fmt.Errorf("%w: duplicate offset for %s", ErrInvalidRiskArray, pair)
+		}
+		if offset.Offset.IsNil() || offset.Offset.IsNegative() || offset.Offset.GT(math.LegacyOneDec()) {
+			return fmt.Errorf("%w: offset for %s must be in [0, 1]", ErrInvalidRiskArray, pair)
+		}
+		pairs[pair] = true
+	}
+	if r.MinMarginRate.IsNil() || r.MinMarginRate.IsNegative() || r.MinMarginRate.GTE(math.LegacyOneDec()) {
+		return fmt.Errorf("%w: min margin rate must be in [0, 1)", ErrInvalidRiskArray)
+	}
+	if r.MaintenanceFraction.IsNil() || !r.MaintenanceFraction.IsPositive() || r.MaintenanceFraction.GT(math.LegacyOneDec()) {
+		return fmt.Errorf("%w: maintenance fraction must be in (0, 1]", ErrInvalidRiskArray)
+	}
+	return nil
+}
+
+// AssetRisk is the stress result of one base asset in a trader's book
+type AssetRisk struct {
+	Asset         string
+	Shock         math.LegacyDec
+	NetNotional   math.LegacyDec // signed at mark: long positive, short negative
+	GrossNotional math.LegacyDec
+	LossUp        math.LegacyDec // loss if the asset rises by the shock
+	LossDown      math.LegacyDec // loss if the asset falls by the shock
+	ScanRisk      math.LegacyDec // the worse of the two losses
+}
+
+// PortfolioMargin is a trader's margin requirement under the risk array,
+// with the per-position requirement it replaces for comparison
+type PortfolioMargin struct {
+	Trader            string
+	Mode              MarginMode
+	Equity            math.LegacyDec // balance + unrealized PnL
+	Assets            []*AssetRisk
+	GrossNotional     math.LegacyDec // at mark, netted by market
+	ScanRisk          math.LegacyDec // sum of the assets' scan risk
+	OffsetCredit      math.LegacyDec // taken off by correlation offsets
+	FloorMargin       math.LegacyDec // MinMarginRate of the gross notional
+	InitialMargin     math.LegacyDec
+	MaintenanceMargin math.LegacyDec
+	StandardMargin    math.LegacyDec // sum of per-position initial margins
+	AvailableMargin   math.LegacyDec // equity - initial margin, not below zero
+	IsHealthy         bool           // equity covers the maintenance margin
+}
@@ -0,0 +1,118 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// CloseReason describes how a position was closed
+type CloseReason string
+
+const (
+	CloseReasonClosed      CloseReason = "closed"      // Reduced to zero by trades or a close request
+	CloseReasonLiquidation CloseReason = "liquidation" // Closed by the liquidation engine
+	CloseReasonADL         CloseReason = "adl"         // Closed by auto-deleveraging
+)
+
+// ClosedPosition is the immutable record of a position after it was fully closed
+type ClosedPosition struct {
+	PositionID  string
+	Trader      string
+	MarketID    string
+	Side        PositionSide
+	Size        math.LegacyDec // total size closed over the position's lifetime
+	EntryPrice  math.LegacyDec // average entry price
+	ExitPrice   math.LegacyDec // size-weighted average exit price
+	RealizedPnL math.LegacyDec // trading PnL before fees and funding
+	FeesPaid    math.LegacyDec // trading fees and liquidation penalties
+	FundingPaid math.LegacyDec // net funding paid (negative = received)
+	NetPnL      math.LegacyDec // RealizedPnL - FeesPaid - FundingPaid
+	Reason      CloseReason
+	OpenedAt    time.Time
+	ClosedAt    time.Time
+}
+
+// ClosedPositionID returns the ID of the seq-th closed position
+func ClosedPositionID(seq uint64) string {
+	return fmt.Sprintf("pos-%d", seq)
+}
+
+// ParseClosedPositionID returns the sequence number of a closed position ID
+func ParseClosedPositionID(id string) (uint64, error) {
+	seq, err := strconv.ParseUint(strings.TrimPrefix(id, "pos-"), 10, 64)
+	if err != nil || !strings.HasPrefix(id, "pos-") || seq == 0 {
+		return 0, fmt.Errorf("invalid closed position ID %q", id)
+	}
+	return seq, nil
+}
+
+// NewClosedPosition builds the history record of a position that reached zero size
+func NewClosedPosition(positionID string, p *Position, reason CloseReason, closedAt time.Time) *ClosedPosition {
+	closedSize := decOrZero(p.ClosedSize)
+	exitPrice := math.LegacyZeroDec()
+	if closedSize.IsPositive() {
+		exitPrice = decOrZero(p.ExitNotional).Quo(closedSize)
+	}
+
+	realizedPnL := decOrZero(p.RealizedPnL)
+	feesPaid := decOrZero(p.FeesPaid)
+	fundingPaid := decOrZero(p.FundingPaid)
+
+	return &ClosedPosition{
+		PositionID:  positionID,
+		Trader:      p.Trader,
+		MarketID:    p.MarketID,
+		Side:        p.Side,
+		Size:        closedSize,
+		EntryPrice:  p.EntryPrice,
+		ExitPrice:   exitPrice,
+		RealizedPnL: realizedPnL,
+		FeesPaid:    feesPaid,
+		FundingPaid: fundingPaid,
+		NetPnL:      realizedPnL.Sub(feesPaid).Sub(fundingPaid),
+		Reason:      reason,
+		OpenedAt:    p.OpenedAt,
+		ClosedAt:    closedAt,
+	}
+}
+
+// HoldingDuration returns how long the position was open
+func (c *ClosedPosition) HoldingDuration() time.Duration {
+	return c.ClosedAt.Sub(c.OpenedAt)
+}
+
+// RecordClose accumulates the exit of part of the position at a price with its realized PnL.
+// It must be called before ReduceSize so the average exit price covers every reduction.
+func (p *Position) RecordClose(size, price, realizedPnL math.LegacyDec) {
+	p.ClosedSize = decOrZero(p.ClosedSize).Add(size)
+	p.ExitNotional = decOrZero(p.ExitNotional).Add(size.Mul(price))
+	p.RealizedPnL = decOrZero(p.RealizedPnL).Add(realizedPnL)
+}
+
+// AddFee attributes a trading fee or penalty to the position
+func (p *Position) AddFee(fee math.LegacyDec) {
+	if fee.IsNil() || fee.IsZero() {
+		return
+	}
+	p.FeesPaid = decOrZero(p.FeesPaid).Add(fee)
+}
+
+// AddFunding attributes a funding payment to the position (positive = paid)
+func (p *Position) AddFunding(paid math.LegacyDec) {
+	if paid.IsNil() || paid.IsZero() {
+		return
+	}
+	p.FundingPaid = decOrZero(p.FundingPaid).Add(paid)
+}
+
+// decOrZero treats unset decimals (positions stored before history tracking) as zero
+func decOrZero(d math.LegacyDec) math.LegacyDec {
+	if d.IsNil() {
+		return math.LegacyZeroDec()
+	}
+	return d
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// TestNewClosedPosition tests PnL attribution across partial closes
+func TestNewClosedPosition(t *testing.T) {
+	opened := time.Unix(1700000000, 0)
+	p := &Position{
+		Trader:     "cosmos1trader",
+		MarketID:   "BTC-USDC",
+		Side:       PositionSideLong,
+		Size:       math.LegacyNewDec(2),
+		EntryPrice: math.LegacyNewDec(50000),
+		OpenedAt:   opened,
+	}
+
+	p.AddFee(math.LegacyNewDec(30))
+	p.AddFunding(math.LegacyNewDec(12))
+	p.AddFunding(math.LegacyNewDec(-2))
+
+	// Close 1 at 51000 (+1000) then 1 at 49000 (-1000)
+	p.RecordClose(math.LegacyNewDec(1), math.LegacyNewDec(51000), math.LegacyNewDec(1000))
+	p.ReduceSize(math.LegacyNewDec(1))
+	p.AddFee(math.LegacyNewDec(20))
+	p.RecordClose(math.LegacyNewDec(1), math.LegacyNewDec(49000), math.LegacyNewDec(-1000))
+	p.ReduceSize(math.LegacyNewDec(1))
+
+	closed := NewClosedPosition("pos-1", p, CloseReasonClosed, opened.Add(90*time.Minute))
+
+	if !closed.Size.Equal(math.LegacyNewDec(2)) {
+		t.Errorf("expected size 2, got %s", closed.Size)
+	}
+	if !closed.ExitPrice.Equal(math.LegacyNewDec(50000)) {
+		t.Errorf("expected average exit price 50000, got %s", closed.ExitPrice)
+	}
+	if !closed.FeesPaid.Equal(math.LegacyNewDec(50)) {
+		t.Errorf("expected fees 50, got %s", closed.FeesPaid)
+	}
+	if !closed.FundingPaid.Equal(math.LegacyNewDec(10)) {
+		t.Errorf("expected funding 10, got %s", closed.FundingPaid)
+	}
+	if !closed.NetPnL.Equal(math.LegacyNewDec(-60)) {
+		t.Errorf("expected net PnL -60, got %s", closed.NetPnL)
+	}
+	if closed.HoldingDuration() != 90*time.Minute {
+		t.Errorf("expected holding duration 90m, got %s", closed.HoldingDuration())
+	}
+}
+
+// TestNewClosedPositionLegacy tests positions stored before history tracking
+func TestNewClosedPositionLegacy(t *testing.T) {
+	p := &Position{Trader: "cosmos1trader", MarketID: "BTC-USDC", EntryPrice: math.LegacyNewDec(50000)}
+
+	closed := NewClosedPosition("pos-1", p, CloseReasonLiquidation, time.Now())
+	if !closed.NetPnL.IsZero() || !closed.ExitPrice.IsZero() {
+		t.Errorf("expected zero totals, got net %s exit %s", closed.NetPnL, closed.ExitPrice)
+	}
+}
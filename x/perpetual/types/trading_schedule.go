@@ -0,0 +1,215 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/errors"
+)
+
+// Reasons a market rejects new orders. Cancels are always accepted.
+const (
+	TradingReasonMaintenance  = "maintenance"
+	TradingReasonOutsideHours = "outside_trading_hours"
+)
+
+// minutesPerDay bounds the open and close of trading hours
+const minutesPerDay = 24 * 60
+
+// TradingHours is a recurring daily trading session in UTC.
+// A session whose close is before its open runs past midnight.
+type TradingHours struct {
+	Days  []time.Weekday // Days the session opens on; empty means every day
+	Open  int            // Minutes after midnight UTC
+	Close int            // Minutes after midnight UTC
+}
+
+// opensOn returns true if the session opens on the given day
+func (h TradingHours) opensOn(day time.Weekday) bool {
+	if len(h.Days) == 0 {
+		return true
+	}
+	for _, d := range h.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns true if t falls within the session
+func (h TradingHours) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if h.Open < h.Close {
+		return h.opensOn(t.Weekday()) && minute >= h.Open && minute < h.Close
+	}
+	// Overnight session: the evening of its opening day or the morning after
+	yesterday := (t.Weekday() + 6) % 7
+	return (h.opensOn(t.Weekday()) && minute >= h.Open) || (h.opensOn(yesterday) && minute < h.Close)
+}
+
+// nextOpen returns the first session open strictly after t
+func (h TradingHours) nextOpen(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	for d := 0; d <= 7; d++ {
+		day := midnight.AddDate(0, 0, d)
+		open := day.Add(time.Duration(h.Open) * time.Minute)
+		if open.After(t) && h.opensOn(day.Weekday()) {
+			return open
+		}
+	}
+	return time.Time{}
+}
+
+// MaintenanceWindow is a one-off period during which new orders are rejected
+type MaintenanceWindow struct {
+	ID     string
+	Start  time.Time
+	End    time.Time
+	Reason string // Operator note shown to traders
+}
+
+// TradingSchedule restricts when a market accepts new orders. A market with no
+// trading hours trades around the clock outside its maintenance windows.
+type TradingSchedule struct {
+	MarketID     string
+	TradingHours []TradingHours
+	Maintenance  []MaintenanceWindow
+	UpdatedAt    time.Time
+}
+
+// Validate checks the trading hours and maintenance windows
+func (s *TradingSchedule) Validate() error {
+	if s.MarketID == "" {
+		return ErrInvalidMarketID
+	}
+	for i, h := range s.TradingHours {
+		if h.Open < 0 || h.Open >= minutesPerDay || h.Close < 0 || h.Close >= minutesPerDay {
+			return errors.Wrapf(ErrInvalidTradingSchedule, "trading hours %d: open and close must be within a day", i)
+		}
+		if h.Open == h.Close {
+			return errors.Wrapf(ErrInvalidTradingSchedule, "trading hours %d: open equals close", i)
+		}
+		for _, d := range h.Days {
+			if d < time.Sunday || d > time.Saturday {
+				return errors.Wrapf(ErrInvalidTradingSchedule, "trading hours %d: invalid day %d", i, d)
+			}
+		}
+	}
+
+	ids := make(map[string]bool, len(s.Maintenance))
+	for _, m := range s.Maintenance {
+		if m.ID == "" {
+			return errors.Wrap(ErrInvalidTradingSchedule, "maintenance window without ID")
+		}
+		if ids[m.ID] {
+			return errors.Wrapf(ErrInvalidTradingSchedule, "duplicate maintenance window %s", m.ID)
+		}
+		ids[m.ID] = true
+		if !m.End.After(m.Start) {
+			return errors.Wrapf(ErrInvalidTradingSchedule, "maintenance window %s: end must be after start", m.ID)
+		}
+	}
+	return nil
+}
+
+// IsEmpty returns true if the schedule places no restrictions on the market
+func (s *TradingSchedule) IsEmpty() bool {
+	return len(s.TradingHours) == 0 && len(s.Maintenance) == 0
+}
+
+// PruneMaintenance drops maintenance windows that ended at or before t
+func (s *TradingSchedule) PruneMaintenance(t time.Time) {
+	kept := s.Maintenance[:0]
+	for _, m := range s.Maintenance {
+		if m.End.After(t) {
+			kept = append(kept, m)
+		}
+	}
+	s.Maintenance = kept
+}
+
+// StatusAt returns whether the market accepts new orders at t.
+// Maintenance takes precedence over trading hours.
+func (s *TradingSchedule) StatusAt(t time.Time) *TradingStatus {
+	t = t.UTC()
+	status := &TradingStatus{MarketID: s.MarketID, Open: true}
+
+	for i := range s.Maintenance {
+		m := &s.Maintenance[i]
+		if !t.Before(m.Start) && t.Before(m.End) {
+			status.Open = false
+			status.Reason = TradingReasonMaintenance
+			status.Message = m.Reason
+			if m.End.After(status.Until) {
+				status.Until = m.End
+			}
+		} else if m.Start.After(t) && (status.NextMaintenance == nil || m.Start.Before(status.NextMaintenance.Start)) {
+			status.NextMaintenance = m
+		}
+	}
+	if !status.Open || len(s.TradingHours) == 0 {
+		return status
+	}
+
+	for _, h := range s.TradingHours {
+		if h.contains(t) {
+			return status
+		}
+	}
+	status.Open = false
+	status.Reason = TradingReasonOutsideHours
+	for _, h := range s.TradingHours {
+		if open := h.nextOpen(t); !open.IsZero() && (status.Until.IsZero() || open.Before(status.Until)) {
+			status.Until = open
+		}
+	}
+	return status
+}
+
+// TradingStatus is whether a market accepts new orders at a point in time
+type TradingStatus struct {
+	MarketID        string
+	Open            bool
+	Reason          string             // TradingReason* when closed
+	Message         string             // Maintenance note, if any
+	Until           time.Time          // When the market reopens; zero if unknown
+	NextMaintenance *MaintenanceWindow // Earliest upcoming maintenance window
+}
+
+// Err returns a TradingHaltError when the market is closed, nil otherwise
+func (s *TradingStatus) Err() error {
+	if s.Open {
+		return nil
+	}
+	return &TradingHaltError{MarketID: s.MarketID, Reason: s.Reason, Message: s.Message, Until: s.Until}
+}
+
+// TradingHaltError reports why a market rejects new orders.
+// It unwraps to ErrMarketMaintenance or ErrOutsideTradingHours.
+type TradingHaltError struct {
+	MarketID string
+	Reason   string
+	Message  string
+	Until    time.Time
+}
+
+// Error implements the error interface
+func (e *TradingHaltError) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.Unwrap(), e.MarketID)
+	if e.Message != "" {
+		msg += " (" + e.Message + ")"
+	}
+	if !e.Until.IsZero() {
+		msg += " until " + e.Until.UTC().Format(time.RFC3339)
+	}
+	return msg
+}
+
+// Unwrap returns the sentinel error of the halt reason
+func (e *TradingHaltError) Unwrap() error {
+	if e.Reason == TradingReasonMaintenance {
+		return ErrMarketMaintenance
+	}
+	return ErrOutsideTradingHours
+}
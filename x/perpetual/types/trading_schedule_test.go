@@ -0,0 +1,88 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestTradingScheduleStatus tests trading hours, overnight sessions and maintenance windows
+func TestTradingScheduleStatus(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.January, day, hour, minute, 0, 0, time.UTC) // Jan 1 2024 is a Monday
+	}
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	schedule := &TradingSchedule{
+		MarketID: "AAPL-USDC",
+		TradingHours: []TradingHours{
+			{Days: weekdays, Open: 13*60 + 30, Close: 20 * 60},
+			{Days: []time.Weekday{time.Friday}, Open: 22 * 60, Close: 2 * 60},
+		},
+		Maintenance: []MaintenanceWindow{
+			{ID: "upgrade", Start: at(2, 14, 0), End: at(2, 15, 0), Reason: "chain upgrade"},
+		},
+	}
+	if err := schedule.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		t      time.Time
+		reason string
+		until  time.Time
+	}{
+		{"regular session", at(1, 14, 0), "", time.Time{}},
+		{"after close", at(1, 21, 0), TradingReasonOutsideHours, at(2, 13, 30)},
+		{"maintenance", at(2, 14, 30), TradingReasonMaintenance, at(2, 15, 0)},
+		{"after maintenance", at(2, 15, 0), "", time.Time{}},
+		{"overnight session", at(6, 1, 0), "", time.Time{}},
+		{"weekend", at(6, 3, 0), TradingReasonOutsideHours, at(8, 13, 30)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := schedule.StatusAt(tc.t)
+			if status.Open != (tc.reason == "") || status.Reason != tc.reason {
+				t.Fatalf("expected reason %q, got open=%v reason %q", tc.reason, status.Open, status.Reason)
+			}
+			if !status.Until.Equal(tc.until) {
+				t.Errorf("expected until %s, got %s", tc.until, status.Until)
+			}
+		})
+	}
+
+	status := schedule.StatusAt(at(1, 14, 0))
+	if status.NextMaintenance == nil || status.NextMaintenance.ID != "upgrade" {
+		t.Errorf("expected upcoming maintenance, got %+v", status.NextMaintenance)
+	}
+
+	err := schedule.StatusAt(at(2, 14, 30)).Err()
+	var haltErr *TradingHaltError
+	if !errors.As(err, &haltErr) || !errors.Is(err, ErrMarketMaintenance) {
+		t.Errorf("expected maintenance halt error, got %v", err)
+	}
+}
+
+// TestTradingScheduleValidate tests rejection of malformed schedules
+func TestTradingScheduleValidate(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		schedule TradingSchedule
+	}{
+		{"empty session", TradingSchedule{MarketID: "BTC-USDC", TradingHours: []TradingHours{{Open: 60, Close: 60}}}},
+		{"open past midnight", TradingSchedule{MarketID: "BTC-USDC", TradingHours: []TradingHours{{Open: minutesPerDay, Close: 60}}}},
+		{"window ends before start", TradingSchedule{MarketID: "BTC-USDC", Maintenance: []MaintenanceWindow{{ID: "a", Start: start, End: start}}}},
+		{"duplicate window", TradingSchedule{MarketID: "BTC-USDC", Maintenance: []MaintenanceWindow{
+			{ID: "a", Start: start, End: start.Add(time.Hour)},
+			{ID: "a", Start: start.Add(2 * time.Hour), End: start.Add(3 * time.Hour)},
+		}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.schedule.Validate(); !errors.Is(err, ErrInvalidTradingSchedule) {
+				t.Errorf("expected invalid schedule error, got %v", err)
+			}
+		})
+	}
+}
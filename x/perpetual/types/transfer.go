@@ -0,0 +1,32 @@
+package types
+
+import (
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// TransferKind is the direction of a margin transfer
+type TransferKind string
+
+const (
+	TransferDeposit  TransferKind = "deposit"
+	TransferWithdraw TransferKind = "withdraw"
+
+	// Internal transfers move free collateral between two accounts; each
+	// side records its half
+	TransferInternalOut TransferKind = "internal_out"
+	TransferInternalIn  TransferKind = "internal_in"
+)
+
+// Transfer is the record of a margin deposit into or withdrawal from a
+// trader's account, or of an internal transfer to or from another account
+type Transfer struct {
+	TransferID   string
+	Trader       string
+	Kind         TransferKind
+	Amount       math.LegacyDec
+	Balance      math.LegacyDec // account balance after the transfer
+	Counterparty string         `json:",omitempty"` // the other account of an internal transfer
+	Timestamp    time.Time
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"strings"
 	"time"
 
 	"cosmossdk.io/math"
@@ -41,14 +42,20 @@ type Market struct {
 	IsActive              bool
 
 	// Extended fields for production
-	Status          MarketStatus   // Market status
-	MinOrderSize    math.LegacyDec // Minimum order size
-	MaxOrderSize    math.LegacyDec // Maximum order size
-	MaxPositionSize math.LegacyDec // Maximum position size per trader
-	FundingInterval int64          // Funding rate interval in seconds (default: 28800 = 8h)
-	InsuranceFundID string         // Insurance fund identifier
-	CreatedAt       time.Time      // Market creation time
-	UpdatedAt       time.Time      // Last update time
+	Status            MarketStatus    // Market status
+	MinOrderSize      math.LegacyDec  // Minimum order size
+	MaxOrderSize      math.LegacyDec  // Maximum order size
+	MaxPositionSize   math.LegacyDec  // Maximum position size per trader
+	MinNotional       math.LegacyDec  // Minimum order notional in quote asset
+	MaxPriceDeviation math.LegacyDec  // Max limit price distance from mark price (0.1 = 10%)
+	MaxSlippage       math.LegacyDec  // Default max market order fill distance from mark price (0.05 = 5%)
+	FundingInterval   int64           // Funding rate interval in seconds (default: 28800 = 8h)
+	InsuranceFundID   string          // Insurance fund identifier
+	PriceDecimals     uint32          // Fixed-point price decimals for matching (0 = those of TickSize)
+	SizeDecimals      uint32          // Fixed-point size decimals for matching (0 = those of LotSize)
+	WarmUp            *WarmUpSchedule // Protections relaxed block by block after listing (nil = none)
+	CreatedAt         time.Time       // Market creation time
+	UpdatedAt         time.Time       // Last update time
 }
 
 // NewMarket creates a new market with default values for MVP
@@ -76,6 +83,7 @@ func NewMarket(marketID, baseAsset, quoteAsset string) *Market {
 		MinOrderSize:    math.LegacyNewDecWithPrec(1, 4), // 0.0001
 		MaxOrderSize:    math.LegacyNewDec(1000),         // 1000
 		MaxPositionSize: math.LegacyNewDec(10000),        // 10000
+		MinNotional:     math.LegacyNewDec(10),           // 10 USDC
 		FundingInterval: 28800,                           // 8 hours
 		InsuranceFundID: "",
 		CreatedAt:       now,
@@ -102,13 +110,50 @@ func NewMarketWithConfig(config MarketConfig) *Market {
 		MinOrderSize:          config.MinOrderSize,
 		MaxOrderSize:          config.MaxOrderSize,
 		MaxPositionSize:       config.MaxPositionSize,
+		MinNotional:           config.MinNotional,
+		MaxPriceDeviation:     config.MaxPriceDeviation,
+		MaxSlippage:           config.MaxSlippage,
 		FundingInterval:       config.FundingInterval,
 		InsuranceFundID:       config.InsuranceFundID,
+		PriceDecimals:         config.PriceDecimals,
+		SizeDecimals:          config.SizeDecimals,
+		WarmUp:                config.WarmUp,
 		CreatedAt:             now,
 		UpdatedAt:             now,
 	}
 }
 
+// MaxMarketDecimals bounds a market's price plus size decimals, so the
+// notional of a fill is exact at LegacyDec precision
+const MaxMarketDecimals = math.LegacyPrecision
+
+// Precision returns the price and size decimals the matching engine uses
+// for fixed-point arithmetic: the configured ones, or those of the tick and
+// lot size when unset
+func (m *Market) Precision() (priceDecimals, sizeDecimals uint32) {
+	priceDecimals, sizeDecimals = m.PriceDecimals, m.SizeDecimals
+	if priceDecimals == 0 {
+		priceDecimals = DecimalPlaces(m.TickSize)
+	}
+	if sizeDecimals == 0 {
+		sizeDecimals = DecimalPlaces(m.LotSize)
+	}
+	return priceDecimals, sizeDecimals
+}
+
+// DecimalPlaces returns the number of significant fractional digits of d,
+// zero for a nil Dec
+func DecimalPlaces(d math.LegacyDec) uint32 {
+	if d.IsNil() {
+		return 0
+	}
+	str := strings.TrimRight(d.String(), "0")
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		return uint32(len(str) - i - 1)
+	}
+	return 0
+}
+
 // MarketConfig contains market configuration parameters
 type MarketConfig struct {
 	MarketID              string
@@ -124,8 +169,14 @@ type MarketConfig struct {
 	MinOrderSize          math.LegacyDec
 	MaxOrderSize          math.LegacyDec
 	MaxPositionSize       math.LegacyDec
+	MinNotional           math.LegacyDec
+	MaxPriceDeviation     math.LegacyDec
+	MaxSlippage           math.LegacyDec
 	FundingInterval       int64
 	InsuranceFundID       string
+	PriceDecimals         uint32          // 0 = the decimals of TickSize
+	SizeDecimals          uint32          // 0 = the decimals of LotSize
+	WarmUp                *WarmUpSchedule // nil = no warm-up
 }
 
 // DefaultMarketConfigs returns default configurations for initial markets
@@ -150,6 +201,9 @@ func DefaultMarketConfigs() map[string]MarketConfig {
 			MinOrderSize:          math.LegacyNewDecWithPrec(1, 4),  // 0.0001
 			MaxOrderSize:          math.LegacyNewDec(100),           // 100 BTC
 			MaxPositionSize:       math.LegacyNewDec(1000),          // 1000 BTC
+			MinNotional:           math.LegacyNewDec(10),            // 10 USDC
+			MaxPriceDeviation:     math.LegacyNewDecWithPrec(1, 1),  // 10% from mark
+			MaxSlippage:           math.LegacyNewDecWithPrec(5, 2),  // 5% from mark
 			FundingInterval:       28800,                            // 8 hours
 		},
 		"ETH-USDC": {
@@ -166,6 +220,9 @@ func DefaultMarketConfigs() map[string]MarketConfig {
 			MinOrderSize:          math.LegacyNewDecWithPrec(1, 3),
 			MaxOrderSize:          math.LegacyNewDec(1000),
 			MaxPositionSize:       math.LegacyNewDec(10000),
+			MinNotional:           math.LegacyNewDec(10),
+			MaxPriceDeviation:     math.LegacyNewDecWithPrec(1, 1),
+			MaxSlippage:           math.LegacyNewDecWithPrec(5, 2),
 			FundingInterval:       28800, // 8 hours
 		},
 		"SOL-USDC": {
@@ -182,6 +239,9 @@ func DefaultMarketConfigs() map[string]MarketConfig {
 			MinOrderSize:          math.LegacyNewDecWithPrec(1, 2),
 			MaxOrderSize:          math.LegacyNewDec(10000),
 			MaxPositionSize:       math.LegacyNewDec(100000),
+			MinNotional:           math.LegacyNewDec(10),
+			MaxPriceDeviation:     math.LegacyNewDecWithPrec(1, 1),
+			MaxSlippage:           math.LegacyNewDecWithPrec(5, 2),
 			FundingInterval:       28800, // 8 hours
 		},
 		"ARB-USDC": {
@@ -198,6 +258,9 @@ func DefaultMarketConfigs() map[string]MarketConfig {
 			MinOrderSize:          math.LegacyNewDecWithPrec(1, 1),
 			MaxOrderSize:          math.LegacyNewDec(100000),
 			MaxPositionSize:       math.LegacyNewDec(1000000),
+			MinNotional:           math.LegacyNewDec(10),
+			MaxPriceDeviation:     math.LegacyNewDecWithPrec(1, 1),
+			MaxSlippage:           math.LegacyNewDecWithPrec(5, 2),
 			FundingInterval:       28800, // 8 hours
 		},
 	}
@@ -215,6 +278,13 @@ type Position struct {
 	LiquidationPrice math.LegacyDec
 	OpenedAt         time.Time
 	UpdatedAt        time.Time
+
+	// Lifetime totals used to build the closed-position history record
+	RealizedPnL  math.LegacyDec // trading PnL realized by reductions
+	FeesPaid     math.LegacyDec // trading fees and liquidation penalties
+	FundingPaid  math.LegacyDec // net funding paid (negative = received)
+	ClosedSize   math.LegacyDec // total size reduced so far
+	ExitNotional math.LegacyDec // sum of reduced size × exit price
 }
 
 // NewPosition creates a new position
@@ -0,0 +1,106 @@
+package types
+
+import (
+	"fmt"
+
+	"cosmossdk.io/math"
+)
+
+// WarmUpStage is one step of a newly listed market's protective regime. It
+// lasts until Blocks blocks after the listing height.
+type WarmUpStage struct {
+	Blocks int64
+
+	// Only limit orders that rest without trading are accepted
+	PostOnly bool
+
+	// Multiplies the market's MaxPriceDeviation, widening the price band while
+	// the mark price is still forming; nil or zero keeps the band
+	PriceBandMultiplier math.LegacyDec
+
+	// Fraction of the market's MaxOrderSize an order may reach, in (0, 1];
+	// nil or zero keeps the size limit
+	OrderSizeFraction math.LegacyDec
+}
+
+// WarmUpSchedule relaxes a new market's order limits stage by stage, stored
+// with the market. Stages are ordered by Blocks; once the last ends the
+// market trades under its own limits.
+type WarmUpSchedule struct {
+	ListedHeight int64 // set by CreateMarket
+	Stages       []WarmUpStage
+}
+
+// DefaultWarmUpSchedule returns a schedule of 100 post-only blocks with a
+// triple price band and a tenth of the order size, then double the band and
+// a quarter of the size until block 1000 and one and a half times the band
+// and half the size until block 5000
+func DefaultWarmUpSchedule() *WarmUpSchedule {
+	return &WarmUpSchedule{Stages: []WarmUpStage{
+		{Blocks: 100, PostOnly: true, PriceBandMultiplier: math.LegacyNewDec(3), OrderSizeFraction: math.LegacyNewDecWithPrec(1, 1)},
+		{Blocks: 1000, PriceBandMultiplier: math.LegacyNewDec(2), OrderSizeFraction: math.LegacyNewDecWithPrec(25, 2)},
+		{Blocks: 5000, PriceBandMultiplier: math.LegacyNewDecWithPrec(15, 1), OrderSizeFraction: math.LegacyNewDecWithPrec(5, 1)},
+	}}
+}
+
+// Validate checks that stages end in increasing order and that multipliers
+// widen and fractions reduce the market's limits
+func (s *WarmUpSchedule) Validate() error {
+	var prev int64
+	for i, stage := range s.Stages {
+		if stage.Blocks <= prev {
+			return fmt.Errorf("%w: stage %d must end after block %d", ErrInvalidWarmUp, i, prev)
+		}
+		prev = stage.Blocks
+		if !stage.PriceBandMultiplier.IsNil() && !stage.PriceBandMultiplier.IsZero() && stage.PriceBandMultiplier.LT(math.LegacyOneDec()) {
+			return fmt.Errorf("%w: stage %d price band multiplier must be at least 1", ErrInvalidWarmUp, i)
+		}
+		if !stage.OrderSizeFraction.IsNil() && (stage.OrderSizeFraction.IsNegative() || stage.OrderSizeFraction.GT(math.LegacyOneDec())) {
+			return fmt.Errorf("%w: stage %d order size fraction must be within (0, 1]", ErrInvalidWarmUp, i)
+		}
+	}
+	return nil
+}
+
+// StageAt returns the stage in force at height and the height it ends at,
+// or nil once the schedule is over
+func (s *WarmUpSchedule) StageAt(height int64) (*WarmUpStage, int64) {
+	if s == nil {
+		return nil, 0
+	}
+	for i := range s.Stages {
+		if end := s.ListedHeight + s.Stages[i].Blocks; height < end {
+			return &s.Stages[i], end
+		}
+	}
+	return nil, 0
+}
+
+// OrderLimits are the limits a market applies to new orders at a height
+type OrderLimits struct {
+	MaxOrderSize      math.LegacyDec
+	MaxPriceDeviation math.LegacyDec
+	MaxSlippage       math.LegacyDec
+	PostOnly          bool
+	WarmUpEndsAt      int64 // height the current warm-up stage ends at, 0 outside warm-up
+	DivergenceGuard   bool  // bands tightened by an engaged divergence guard
+}
+
+// OrderLimitsAt returns the market's order limits at height, tightened by the
+// warm-up stage in force
+func (m *Market) OrderLimitsAt(height int64) OrderLimits {
+	limits := OrderLimits{MaxOrderSize: m.MaxOrderSize, MaxPriceDeviation: m.MaxPriceDeviation, MaxSlippage: m.MaxSlippage}
+	stage, end := m.WarmUp.StageAt(height)
+	if stage == nil {
+		return limits
+	}
+	limits.PostOnly = stage.PostOnly
+	limits.WarmUpEndsAt = end
+	if f := stage.OrderSizeFraction; !f.IsNil() && f.IsPositive() && !m.MaxOrderSize.IsNil() && m.MaxOrderSize.IsPositive() {
+		limits.MaxOrderSize = m.MaxOrderSize.Mul(f)
+	}
+	if mult := stage.PriceBandMultiplier; !mult.IsNil() && mult.IsPositive() && !m.MaxPriceDeviation.IsNil() {
+		limits.MaxPriceDeviation = m.MaxPriceDeviation.Mul(mult)
+	}
+	return limits
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+)
+
+// TestWarmUpOrderLimits tests that a new market's order limits are tightened
+// stage by stage from its listing height and restored after the last stage
+func TestWarmUpOrderLimits(t *testing.T) {
+	market := NewMarket("NEW-USDC", "NEW", "USDC")
+	market.MaxPriceDeviation = math.LegacyNewDecWithPrec(1, 1)
+	market.WarmUp = DefaultWarmUpSchedule()
+	market.WarmUp.ListedHeight = 1000
+	if err := market.WarmUp.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	testCases := []struct {
+		height       int64
+		postOnly     bool
+		maxOrderSize string
+		maxDeviation string
+		endsAt       int64
+	}{
+		{1000, true, "100", "0.3", 1100},
+		{1099, true, "100", "0.3", 1100},
+		{1100, false, "250", "0.2", 2000},
+		{5999, false, "500", "0.15", 6000},
+		{6000, false, "1000", "0.1", 0},
+	}
+	for _, tc := range testCases {
+		limits := market.OrderLimitsAt(tc.height)
+		if limits.PostOnly != tc.postOnly || limits.WarmUpEndsAt != tc.endsAt {
+			t.Errorf("height %d: expected post-only %v until %d, got %v until %d", tc.height, tc.postOnly, tc.endsAt, limits.PostOnly, limits.WarmUpEndsAt)
+		}
+		if !limits.MaxOrderSize.Equal(math.LegacyMustNewDecFromStr(tc.maxOrderSize)) {
+			t.Errorf("height %d: expected max order size %s, got %s", tc.height, tc.maxOrderSize, limits.MaxOrderSize)
+		}
+		if !limits.MaxPriceDeviation.Equal(math.LegacyMustNewDecFromStr(tc.maxDeviation)) {
+			t.Errorf("height %d: expected max price deviation %s, got %s", tc.height, tc.maxDeviation, limits.MaxPriceDeviation)
+		}
+	}
+
+	market.WarmUp = nil
+	if limits := market.OrderLimitsAt(1000); limits.PostOnly || !limits.MaxOrderSize.Equal(market.MaxOrderSize) {
+		t.Errorf("expected a market without warm-up to keep its limits, got %+v", limits)
+	}
+
+	invalid := []*WarmUpSchedule{
+		{Stages: []WarmUpStage{{Blocks: 100}, {Blocks: 100}}},
+		{Stages: []WarmUpStage{{Blocks: 0}}},
+		{Stages: []WarmUpStage{{Blocks: 100, PriceBandMultiplier: math.LegacyNewDecWithPrec(5, 1)}}},
+		{Stages: []WarmUpStage{{Blocks: 100, OrderSizeFraction: math.LegacyNewDec(2)}}},
+	}
+	for i, schedule := range invalid {
+		if err := schedule.Validate(); !errors.Is(err, ErrInvalidWarmUp) {
+			t.Errorf("schedule %d: expected ErrInvalidWarmUp, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// MaxWithdrawalTimelock bounds how long an account may hold its withdrawals
+const MaxWithdrawalTimelock = 7 * 24 * time.Hour
+
+// WithdrawalStatus is the state of a withdrawal request
+type WithdrawalStatus string
+
+const (
+	WithdrawalPending   WithdrawalStatus = "pending"   // held until ReleaseAt, may be cancelled
+	WithdrawalCompleted WithdrawalStatus = "completed" // paid out to the destination
+	WithdrawalCancelled WithdrawalStatus = "cancelled" // returned to the account balance
+)
+
+// WithdrawalSecurity is an account's withdrawal protection. With a timelock,
+// withdrawals are held for Timelock before they are paid out and can be
+// cancelled meanwhile; with the allowlist enabled, they may only go to
+// allowlisted destinations. Changes that weaken the protection, a shorter
+// timelock or disabling the allowlist, only take effect once the current
+// timelock has passed, so a leaked key cannot lift it and withdraw at once.
+type WithdrawalSecurity struct {
+	Trader           string
+	Timelock         time.Duration
+	AllowlistEnabled bool
+	Pending          *WithdrawalSecurityChange // weakening change waiting out the timelock
+	UpdatedAt        time.Time
+}
+
+// WithdrawalSecurityChange is a scheduled weakening of an account's
+// withdrawal protection
+type WithdrawalSecurityChange struct {
+	Timelock         time.Duration
+	AllowlistEnabled bool
+	EffectiveAt      time.Time
+}
+
+// NewWithdrawalSecurity returns an unprotected account: no timelock and no allowlist
+func NewWithdrawalSecurity(trader string) *WithdrawalSecurity {
+	return &WithdrawalSecurity{Trader: trader}
+}
+
+// ValidateWithdrawalTimelock checks a timelock is between zero and MaxWithdrawalTimelock
+func ValidateWithdrawalTimelock(timelock time.Duration) error {
+	if timelock < 0 || timelock > MaxWithdrawalTimelock {
+		return fmt.Errorf("%w: timelock must be between 0 and %s", ErrInvalidWithdrawalSecurity, MaxWithdrawalTimelock)
+	}
+	return nil
+}
+
+// Validate checks the timelocks of the protection and its pending change
+func (s *WithdrawalSecurity) Validate() error {
+	if s.Trader == "" {
+		return fmt.Errorf("%w: trader is required", ErrInvalidWithdrawalSecurity)
+	}
+	if err := ValidateWithdrawalTimelock(s.Timelock); err != nil {
+		return err
+	}
+	if s.Pending != nil {
+		return ValidateWithdrawalTimelock(s.Pending.Timelock)
+	}
+	return nil
+}
+
+// WithdrawalAddress is an allowlisted withdrawal destination. An address
+// added to the allowlist can only be withdrawn to from ActiveAt, one
+// timelock after it was added.
+type WithdrawalAddress struct {
+	Trader   string
+	Address  string
+	Label    string
+	AddedAt  time.Time
+	ActiveAt time.Time
+}
+
+// IsActive returns true if withdrawals may go to the address at t
+func (a *WithdrawalAddress) IsActive(t time.Time) bool {
+	return !t.Before(a.ActiveAt)
+}
+
+// Withdrawal is a request to pay out margin to a destination address. The
+// amount leaves the account balance when the request is made and is paid out
+// at ReleaseAt, or returned to the balance if the request is cancelled first.
+type Withdrawal struct {
+	WithdrawalID string
+	Trader       string
+	Amount       math.LegacyDec
+	Destination  string
+	Status       WithdrawalStatus
+	RequestedAt  time.Time
+	ReleaseAt    time.Time
+	ProcessedAt  time.Time // when it was paid out or cancelled
+}
+
+// IsCancellable returns true if the withdrawal can still be cancelled at t
+func (w *Withdrawal) IsCancellable(t time.Time) bool {
+	return w.Status == WithdrawalPending && t.Before(w.ReleaseAt)
+}
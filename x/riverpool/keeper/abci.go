@@ -19,12 +19,18 @@ func (k *Keeper) EndBlocker(ctx sdk.Context) error {
 	k.UpdateAllPoolNAVs(ctx)
 	navDuration := time.Since(navStart)
 
-	// Phase 2: Process pending withdrawals that are now available
+	// Phase 2: Rebalance pool-of-pools allocations so parents hold enough cash
+	// for the withdrawals processed below
+	rebalanceStart := time.Now()
+	rebalancedCount := k.RebalanceAllPools(ctx)
+	rebalanceDuration := time.Since(rebalanceStart)
+
+	// Phase 3: Process pending withdrawals that are now available
 	processStart := time.Now()
 	processedCount := k.ProcessReadyWithdrawals(ctx)
 	processDuration := time.Since(processStart)
 
-	// Phase 3: Check DDGuard levels and take action if needed
+	// Phase 4: Check DDGuard levels and take action if needed
 	ddStart := time.Now()
 	k.CheckDDGuardActions(ctx)
 	ddDuration := time.Since(ddStart)
@@ -36,9 +42,11 @@ func (k *Keeper) EndBlocker(ctx sdk.Context) error {
 		"block", blockHeight,
 		"total_ms", totalDuration.Milliseconds(),
 		"nav_update_ms", navDuration.Milliseconds(),
+		"rebalance_ms", rebalanceDuration.Milliseconds(),
 		"withdrawal_process_ms", processDuration.Milliseconds(),
 		"ddguard_check_ms", ddDuration.Milliseconds(),
 		"withdrawals_processed", processedCount,
+		"pools_rebalanced", rebalancedCount,
 	)
 
 	// Emit telemetry event
@@ -130,6 +138,19 @@ func (k *Keeper) ProcessReadyWithdrawals(ctx sdk.Context) int {
 				sharesToProcess = pendingShares
 			}
 
+			// Redeemed shares are burned from the withdrawer; a withdrawer
+			// no longer holding them is skipped until it does
+			burnCtx, writeBurn := ctx.CacheContext()
+			if err := k.burnLPTokens(burnCtx, pool.PoolID, w.Withdrawer, sharesToProcess); err != nil {
+				k.logger.Error("failed to burn pool shares",
+					"withdrawal_id", w.WithdrawalID,
+					"pool_id", pool.PoolID,
+					"error", err,
+				)
+				continue
+			}
+			writeBurn()
+
 			// Mark shares as redeemed (partial or full)
 			w.SharesRedeemed = w.SharesRedeemed.Add(sharesToProcess)
 			amountToSend := sharesToProcess.Mul(pool.NAV)
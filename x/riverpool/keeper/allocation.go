@@ -0,0 +1,506 @@
+package keeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// Allocation store key prefixes
+var (
+	AllocationStrategyKeyPrefix = []byte{0x0F}
+	PoolAllocationKeyPrefix     = []byte{0x10}
+	ExposureKeyPrefix           = []byte{0x11}
+)
+
+// ============ Strategy Operations ============
+
+// SetAllocationStrategy validates and stores the allocation strategy of a
+// parent pool. Only the Main LP may allocate, and only to community pools.
+func (k *Keeper) SetAllocationStrategy(ctx sdk.Context, strategy *types.AllocationStrategy) error {
+	if err := strategy.Validate(); err != nil {
+		return err
+	}
+	parent := k.GetPool(ctx, strategy.PoolID)
+	if parent == nil {
+		return types.ErrPoolNotFound
+	}
+	if parent.PoolType != types.PoolTypeMain {
+		return types.ErrNotAllocatingPool
+	}
+	for _, t := range strategy.Targets {
+		pool := k.GetPool(ctx, t.PoolID)
+		if pool == nil {
+			return fmt.Errorf("%w: %s", types.ErrPoolNotFound, t.PoolID)
+		}
+		if pool.PoolType != types.PoolTypeCommunity {
+			return fmt.Errorf("%w: %s", types.ErrInvalidAllocationTarget, t.PoolID)
+		}
+	}
+
+	if existing := k.GetAllocationStrategy(ctx, strategy.PoolID); existing != nil {
+		strategy.LastRebalanceAt = existing.LastRebalanceAt
+	}
+	strategy.UpdatedAt = time.Now().Unix()
+	k.setAllocationStrategy(ctx, strategy)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"riverpool_allocation_strategy_updated",
+			sdk.NewAttribute("pool_id", strategy.PoolID),
+			sdk.NewAttribute("targets", fmt.Sprintf("%d", len(strategy.Targets))),
+			sdk.NewAttribute("max_allocation", strategy.MaxAllocation.String()),
+		),
+	)
+	return nil
+}
+
+func (k *Keeper) setAllocationStrategy(ctx sdk.Context, strategy *types.AllocationStrategy) {
+	store := k.GetStore(ctx)
+	bz, _ := json.Marshal(strategy)
+	store.Set(append(AllocationStrategyKeyPrefix, []byte(strategy.PoolID)...), bz)
+}
+
+// GetAllocationStrategy retrieves the allocation strategy of a parent pool
+func (k *Keeper) GetAllocationStrategy(ctx sdk.Context, poolID string) *types.AllocationStrategy {
+	store := k.GetStore(ctx)
+	bz := store.Get(append(AllocationStrategyKeyPrefix, []byte(poolID)...))
+	if bz == nil {
+		return nil
+	}
+	var strategy types.AllocationStrategy
+	if err := json.Unmarshal(bz, &strategy); err != nil {
+		return nil
+	}
+	return &strategy
+}
+
+// GetAllAllocationStrategies returns all allocation strategies
+func (k *Keeper) GetAllAllocationStrategies(ctx sdk.Context) []*types.AllocationStrategy {
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, AllocationStrategyKeyPrefix)
+	defer iterator.Close()
+
+	var strategies []*types.AllocationStrategy
+	for ; iterator.Valid(); iterator.Next() {
+		var strategy types.AllocationStrategy
+		if err := json.Unmarshal(iterator.Value(), &strategy); err != nil {
+			continue
+		}
+		strategies = append(strategies, &strategy)
+	}
+	return strategies
+}
+
+// ============ Allocation Operations ============
+
+// poolAllocationKey generates the key for a parent's holding in an underlying
+func poolAllocationKey(parentPoolID, poolID string) []byte {
+	return append(PoolAllocationKeyPrefix, []byte(parentPoolID+":"+poolID)...)
+}
+
+// SetPoolAllocation saves an allocation, deleting it once fully redeemed
+func (k *Keeper) SetPoolAllocation(ctx sdk.Context, allocation *types.PoolAllocation) {
+	store := k.GetStore(ctx)
+	key := poolAllocationKey(allocation.ParentPoolID, allocation.PoolID)
+	if !allocation.Shares.IsPositive() {
+		store.Delete(key)
+		return
+	}
+	bz, _ := json.Marshal(allocation)
+	store.Set(key, bz)
+}
+
+// GetPoolAllocation retrieves a parent's holding in an underlying pool
+func (k *Keeper) GetPoolAllocation(ctx sdk.Context, parentPoolID, poolID string) *types.PoolAllocation {
+	store := k.GetStore(ctx)
+	bz := store.Get(poolAllocationKey(parentPoolID, poolID))
+	if bz == nil {
+		return nil
+	}
+	var allocation types.PoolAllocation
+	if err := json.Unmarshal(bz, &allocation); err != nil {
+		return nil
+	}
+	return &allocation
+}
+
+// GetPoolAllocations returns all holdings of a parent pool
+func (k *Keeper) GetPoolAllocations(ctx sdk.Context, parentPoolID string) []*types.PoolAllocation {
+	store := k.GetStore(ctx)
+	prefix := append(PoolAllocationKeyPrefix, []byte(parentPoolID+":")...)
+	iterator := storetypes.KVStorePrefixIterator(store, prefix)
+	defer iterator.Close()
+
+	var allocations []*types.PoolAllocation
+	for ; iterator.Valid(); iterator.Next() {
+		var allocation types.PoolAllocation
+		if err := json.Unmarshal(iterator.Value(), &allocation); err != nil {
+			continue
+		}
+		allocations = append(allocations, &allocation)
+	}
+	return allocations
+}
+
+// ============ Exposure Operations ============
+
+// exposureKey generates the key for a parent's exposure to an underlying
+func exposureKey(parentPoolID, poolID string) []byte {
+	return append(ExposureKeyPrefix, []byte(parentPoolID+":"+poolID)...)
+}
+
+// SetUnderlyingExposure saves a parent's exposure to an underlying pool
+func (k *Keeper) SetUnderlyingExposure(ctx sdk.Context, exposure *types.UnderlyingExposure) {
+	store := k.GetStore(ctx)
+	bz, _ := json.Marshal(exposure)
+	store.Set(exposureKey(exposure.ParentPoolID, exposure.PoolID), bz)
+}
+
+// GetUnderlyingExposures returns a parent's exposures to its underlyings
+func (k *Keeper) GetUnderlyingExposures(ctx sdk.Context, parentPoolID string) []*types.UnderlyingExposure {
+	store := k.GetStore(ctx)
+	prefix := append(ExposureKeyPrefix, []byte(parentPoolID+":")...)
+	iterator := storetypes.KVStorePrefixIterator(store, prefix)
+	defer iterator.Close()
+
+	var exposures []*types.UnderlyingExposure
+	for ; iterator.Valid(); iterator.Next() {
+		var exposure types.UnderlyingExposure
+		if err := json.Unmarshal(iterator.Value(), &exposure); err != nil {
+			continue
+		}
+		exposures = append(exposures, &exposure)
+	}
+	return exposures
+}
+
+// clearUnderlyingExposures removes all exposures of a parent pool
+func (k *Keeper) clearUnderlyingExposures(ctx sdk.Context, parentPoolID string) {
+	store := k.GetStore(ctx)
+	prefix := append(ExposureKeyPrefix, []byte(parentPoolID+":")...)
+	iterator := storetypes.KVStorePrefixIterator(store, prefix)
+	var keys [][]byte
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+// ============ Look-through Valuation ============
+
+// allocationValues returns the current value of each holding of a parent pool
+// at the underlying NAV, and the total unrealized PnL over cost basis
+func (k *Keeper) allocationValues(ctx sdk.Context, parentPoolID string) (map[string]math.LegacyDec, math.LegacyDec) {
+	values := make(map[string]math.LegacyDec)
+	pnl := math.LegacyZeroDec()
+	for _, a := range k.GetPoolAllocations(ctx, parentPoolID) {
+		underlying := k.GetPool(ctx, a.PoolID)
+		if underlying == nil {
+			continue
+		}
+		value := underlying.CalculateValueForShares(a.Shares)
+		values[a.PoolID] = value
+		pnl = pnl.Add(value.Sub(a.CostBasis))
+	}
+	return values, pnl
+}
+
+// GetLookThroughNAV breaks a pool's value into cash and underlying holdings.
+// The parent's TotalDeposits carries allocations at cost, so cash is
+// TotalDeposits less the allocated cost basis.
+func (k *Keeper) GetLookThroughNAV(ctx sdk.Context, poolID string) (*types.LookThroughNAV, error) {
+	pool := k.GetPool(ctx, poolID)
+	if pool == nil {
+		return nil, types.ErrPoolNotFound
+	}
+
+	allocated := math.LegacyZeroDec()
+	costBasis := math.LegacyZeroDec()
+	for _, a := range k.GetPoolAllocations(ctx, poolID) {
+		underlying := k.GetPool(ctx, a.PoolID)
+		if underlying == nil {
+			continue
+		}
+		allocated = allocated.Add(underlying.CalculateValueForShares(a.Shares))
+		costBasis = costBasis.Add(a.CostBasis)
+	}
+
+	cash := pool.TotalDeposits.Sub(costBasis)
+	totalValue := cash.Add(allocated)
+	nav := math.LegacyOneDec()
+	if pool.TotalShares.IsPositive() {
+		nav = totalValue.Quo(pool.TotalShares)
+	}
+
+	return &types.LookThroughNAV{
+		PoolID:         poolID,
+		Cash:           cash,
+		AllocatedValue: allocated,
+		TotalValue:     totalValue,
+		TotalShares:    pool.TotalShares,
+		NAV:            nav,
+		Underlyings:    k.GetUnderlyingExposures(ctx, poolID),
+		Timestamp:      time.Now().Unix(),
+	}, nil
+}
+
+// ============ Capital Movements ============
+
+// allocateToPool moves amount of the parent's cash into an underlying pool at
+// the underlying NAV. The parent's TotalDeposits is unchanged since the cash
+// is now carried as cost basis.
+func (k *Keeper) allocateToPool(ctx sdk.Context, parentPoolID string, underlying *types.Pool, amount math.LegacyDec) {
+	shares := underlying.CalculateSharesForDeposit(amount)
+	underlying.TotalDeposits = underlying.TotalDeposits.Add(amount)
+	underlying.TotalShares = underlying.TotalShares.Add(shares)
+	underlying.UpdatedAt = time.Now().Unix()
+	k.SetPool(ctx, underlying)
+
+	stats := k.GetPoolStats(ctx, underlying.PoolID)
+	stats.TotalValueLocked = underlying.TotalDeposits
+	stats.UpdatedAt = time.Now().Unix()
+	k.SetPoolStats(ctx, stats)
+
+	allocation := k.GetPoolAllocation(ctx, parentPoolID, underlying.PoolID)
+	if allocation == nil {
+		allocation = &types.PoolAllocation{
+			ParentPoolID: parentPoolID,
+			PoolID:       underlying.PoolID,
+			Shares:       math.LegacyZeroDec(),
+			CostBasis:    math.LegacyZeroDec(),
+		}
+	}
+	allocation.Shares = allocation.Shares.Add(shares)
+	allocation.CostBasis = allocation.CostBasis.Add(amount)
+	allocation.UpdatedAt = time.Now().Unix()
+	k.SetPoolAllocation(ctx, allocation)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"riverpool_allocation",
+			sdk.NewAttribute("parent_pool_id", parentPoolID),
+			sdk.NewAttribute("pool_id", underlying.PoolID),
+			sdk.NewAttribute("amount", amount.String()),
+			sdk.NewAttribute("shares", shares.String()),
+		),
+	)
+}
+
+// redeemFromPool redeems up to amount of value from an underlying pool back
+// into the parent's cash. Redemptions count against the underlying's daily
+// redemption limit like any other withdrawal. Returns the amount redeemed.
+func (k *Keeper) redeemFromPool(ctx sdk.Context, parent *types.Pool, underlying *types.Pool, amount math.LegacyDec) math.LegacyDec {
+	allocation := k.GetPoolAllocation(ctx, parent.PoolID, underlying.PoolID)
+	if allocation == nil || !underlying.NAV.IsPositive() {
+		return math.LegacyZeroDec()
+	}
+
+	dailyLimit := underlying.TotalDeposits.Mul(underlying.DailyRedemptionLimit)
+	available := dailyLimit.Sub(k.GetDailyProcessedAmount(ctx, underlying.PoolID))
+	amount = math.LegacyMinDec(amount, available)
+	amount = math.LegacyMinDec(amount, underlying.CalculateValueForShares(allocation.Shares))
+	if !amount.IsPositive() {
+		return math.LegacyZeroDec()
+	}
+
+	shares := math.LegacyMinDec(amount.Quo(underlying.NAV), allocation.Shares)
+	costPortion := allocation.CostBasis.Mul(shares).Quo(allocation.Shares)
+
+	underlying.TotalDeposits = underlying.TotalDeposits.Sub(amount)
+	underlying.TotalShares = underlying.TotalShares.Sub(shares)
+	underlying.UpdatedAt = time.Now().Unix()
+	k.SetPool(ctx, underlying)
+	k.AddDailyProcessedAmount(ctx, underlying.PoolID, amount)
+
+	stats := k.GetPoolStats(ctx, underlying.PoolID)
+	stats.TotalValueLocked = underlying.TotalDeposits
+	stats.UpdatedAt = time.Now().Unix()
+	k.SetPoolStats(ctx, stats)
+
+	allocation.Shares = allocation.Shares.Sub(shares)
+	allocation.CostBasis = allocation.CostBasis.Sub(costPortion)
+	allocation.UpdatedAt = time.Now().Unix()
+	k.SetPoolAllocation(ctx, allocation)
+
+	// Realized gain or loss moves into the parent's book value
+	parent.TotalDeposits = parent.TotalDeposits.Add(amount.Sub(costPortion))
+	parent.UpdatedAt = time.Now().Unix()
+	k.SetPool(ctx, parent)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"riverpool_allocation_redeemed",
+			sdk.NewAttribute("parent_pool_id", parent.PoolID),
+			sdk.NewAttribute("pool_id", underlying.PoolID),
+			sdk.NewAttribute("amount", amount.String()),
+			sdk.NewAttribute("shares", shares.String()),
+			sdk.NewAttribute("realized_pnl", amount.Sub(costPortion).String()),
+		),
+	)
+	return amount
+}
+
+// ============ Rebalancing ============
+
+// RebalanceAllPools rebalances every parent pool with a strategy (called in EndBlocker)
+func (k *Keeper) RebalanceAllPools(ctx sdk.Context) int {
+	rebalanced := 0
+	for _, strategy := range k.GetAllAllocationStrategies(ctx) {
+		if k.RebalanceAllocations(ctx, strategy, false) {
+			rebalanced++
+		}
+	}
+	return rebalanced
+}
+
+// RebalanceAllocations moves a parent pool's holdings toward its targets when
+// the rebalance interval has elapsed, drift exceeds the threshold, the parent
+// is short of cash for redemptions, or force is set. Exposures are refreshed
+// on every call. Returns whether any orders were executed.
+func (k *Keeper) RebalanceAllocations(ctx sdk.Context, strategy *types.AllocationStrategy, force bool) bool {
+	parent := k.GetPool(ctx, strategy.PoolID)
+	if parent == nil || parent.Status == types.PoolStatusClosed {
+		return false
+	}
+	now := time.Now().Unix()
+
+	// Eligibility of every target and every pool still held
+	eligible := make(map[string]bool)
+	reasons := make(map[string]string)
+	targeted := make(map[string]bool, len(strategy.Targets))
+	for _, t := range strategy.Targets {
+		targeted[t.PoolID] = true
+		pool := k.GetPool(ctx, t.PoolID)
+		if pool == nil {
+			reasons[t.PoolID] = types.ExclusionInactive
+			continue
+		}
+		ok, reason := strategy.Filter.Eligible(pool, k.CalculatePoolReturn(ctx, t.PoolID, 30), now)
+		eligible[t.PoolID] = ok
+		reasons[t.PoolID] = reason
+	}
+
+	current, pnl := k.allocationValues(ctx, parent.PoolID)
+	for poolID := range current {
+		if !targeted[poolID] {
+			reasons[poolID] = types.ExclusionNotTargeted
+		}
+	}
+
+	parentValue := parent.TotalDeposits.Add(pnl)
+	targets := strategy.TargetValues(parentValue, eligible)
+	// A paused parent winds down its allocations
+	if parent.Status != types.PoolStatusActive {
+		targets = map[string]math.LegacyDec{}
+	}
+
+	lookThrough, _ := k.GetLookThroughNAV(ctx, parent.PoolID)
+	cash := lookThrough.Cash
+
+	due := strategy.RebalanceInterval > 0 && now-strategy.LastRebalanceAt >= strategy.RebalanceInterval
+	drifted := types.MaxDrift(targets, current, parentValue).GT(strategy.RebalanceThreshold)
+	executed := false
+	if force || due || drifted || cash.IsNegative() {
+		for _, order := range types.PlanRebalance(targets, current, cash) {
+			underlying := k.GetPool(ctx, order.PoolID)
+			if underlying == nil {
+				continue
+			}
+			if order.Amount.IsNegative() {
+				if k.redeemFromPool(ctx, parent, underlying, order.Amount.Neg()).IsPositive() {
+					executed = true
+				}
+				continue
+			}
+			if underlying.Status != types.PoolStatusActive {
+				continue
+			}
+			k.allocateToPool(ctx, parent.PoolID, underlying, order.Amount)
+			executed = true
+		}
+		strategy.LastRebalanceAt = now
+		k.setAllocationStrategy(ctx, strategy)
+
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				"riverpool_rebalance",
+				sdk.NewAttribute("pool_id", parent.PoolID),
+				sdk.NewAttribute("parent_value", parentValue.String()),
+				sdk.NewAttribute("executed", fmt.Sprintf("%t", executed)),
+			),
+		)
+	}
+
+	k.updateUnderlyingExposures(ctx, parent.PoolID, targets, eligible, reasons, now)
+	return executed
+}
+
+// updateUnderlyingExposures rewrites a parent pool's exposure records from its
+// current holdings and the last computed targets
+func (k *Keeper) updateUnderlyingExposures(
+	ctx sdk.Context,
+	parentPoolID string,
+	targets map[string]math.LegacyDec,
+	eligible map[string]bool,
+	reasons map[string]string,
+	now int64,
+) {
+	parent := k.GetPool(ctx, parentPoolID)
+	if parent == nil {
+		return
+	}
+	_, pnl := k.allocationValues(ctx, parentPoolID)
+	parentValue := parent.TotalDeposits.Add(pnl)
+
+	holdings := make(map[string]*types.PoolAllocation)
+	for _, a := range k.GetPoolAllocations(ctx, parentPoolID) {
+		holdings[a.PoolID] = a
+	}
+	poolIDs := make([]string, 0, len(reasons))
+	for poolID := range reasons {
+		poolIDs = append(poolIDs, poolID)
+	}
+	sort.Strings(poolIDs)
+
+	k.clearUnderlyingExposures(ctx, parentPoolID)
+	for _, poolID := range poolIDs {
+		exposure := &types.UnderlyingExposure{
+			ParentPoolID:  parentPoolID,
+			PoolID:        poolID,
+			Shares:        math.LegacyZeroDec(),
+			CostBasis:     math.LegacyZeroDec(),
+			Value:         math.LegacyZeroDec(),
+			UnrealizedPnL: math.LegacyZeroDec(),
+			Weight:        math.LegacyZeroDec(),
+			TargetWeight:  math.LegacyZeroDec(),
+			Eligible:      eligible[poolID],
+			Exclusion:     reasons[poolID],
+			UpdatedAt:     now,
+		}
+		if a := holdings[poolID]; a != nil {
+			if underlying := k.GetPool(ctx, poolID); underlying != nil {
+				exposure.Value = underlying.CalculateValueForShares(a.Shares)
+			}
+			exposure.Shares = a.Shares
+			exposure.CostBasis = a.CostBasis
+			exposure.UnrealizedPnL = exposure.Value.Sub(a.CostBasis)
+		}
+		if parentValue.IsPositive() {
+			exposure.Weight = exposure.Value.Quo(parentValue)
+			if target, ok := targets[poolID]; ok {
+				exposure.TargetWeight = target.Quo(parentValue)
+			}
+		}
+		k.SetUnderlyingExposure(ctx, exposure)
+	}
+}
@@ -0,0 +1,150 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+func dec(s string) math.LegacyDec { return math.LegacyMustNewDecFromStr(s) }
+
+// TestAllocationStrategyValidate tests strategy validation
+func TestAllocationStrategyValidate(t *testing.T) {
+	valid := types.NewAllocationStrategy("main-lp", []types.AllocationTarget{
+		{PoolID: "pool-a", Weight: dec("2")},
+		{PoolID: "pool-b", Weight: dec("1")},
+	})
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid strategy, got %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		mutate func(s *types.AllocationStrategy)
+	}{
+		{"self target", func(s *types.AllocationStrategy) { s.Targets[0].PoolID = "main-lp" }},
+		{"duplicate target", func(s *types.AllocationStrategy) { s.Targets[1].PoolID = "pool-a" }},
+		{"zero weight", func(s *types.AllocationStrategy) { s.Targets[0].Weight = math.LegacyZeroDec() }},
+		{"max allocation above 1", func(s *types.AllocationStrategy) { s.MaxAllocation = dec("1.5") }},
+		{"negative threshold", func(s *types.AllocationStrategy) { s.RebalanceThreshold = dec("-0.01") }},
+		{"negative min age", func(s *types.AllocationStrategy) { s.Filter.MinAgeDays = -1 }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := *valid
+			s.Targets = append([]types.AllocationTarget(nil), valid.Targets...)
+			tc.mutate(&s)
+			if err := s.Validate(); !errors.Is(err, types.ErrInvalidAllocationStrategy) {
+				t.Errorf("expected ErrInvalidAllocationStrategy, got %v", err)
+			}
+		})
+	}
+}
+
+// TestAllocationFilterEligible tests performance filters on underlying pools
+func TestAllocationFilterEligible(t *testing.T) {
+	now := int64(100 * 24 * 60 * 60)
+	filter := types.AllocationFilter{
+		MaxDrawdown:  dec("0.10"),
+		MinReturn30d: dec("1"),
+		MinTVL:       dec("10000"),
+		MinAgeDays:   30,
+	}
+	base := types.Pool{
+		Status:          types.PoolStatusActive,
+		DDGuardLevel:    types.DDGuardLevelNormal,
+		CurrentDrawdown: dec("0.05"),
+		TotalDeposits:   dec("50000"),
+		CreatedAt:       0,
+	}
+
+	testCases := []struct {
+		name      string
+		mutate    func(p *types.Pool)
+		return30d string
+		expected  string
+	}{
+		{"eligible", func(p *types.Pool) {}, "2", ""},
+		{"paused", func(p *types.Pool) { p.Status = types.PoolStatusPaused }, "2", types.ExclusionInactive},
+		{"ddguard reduce", func(p *types.Pool) { p.DDGuardLevel = types.DDGuardLevelReduce }, "2", types.ExclusionDDGuard},
+		{"drawdown", func(p *types.Pool) { p.CurrentDrawdown = dec("0.12") }, "2", types.ExclusionDrawdown},
+		{"return", func(p *types.Pool) {}, "0.5", types.ExclusionReturn},
+		{"tvl", func(p *types.Pool) { p.TotalDeposits = dec("5000") }, "2", types.ExclusionTVL},
+		{"age", func(p *types.Pool) { p.CreatedAt = now - 10*24*60*60 }, "2", types.ExclusionAge},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := base
+			tc.mutate(&pool)
+			ok, reason := filter.Eligible(&pool, dec(tc.return30d), now)
+			if ok != (tc.expected == "") || reason != tc.expected {
+				t.Errorf("expected exclusion %q, got %v %q", tc.expected, ok, reason)
+			}
+		})
+	}
+
+	// An empty filter only checks status and DDGuard
+	if ok, _ := (types.AllocationFilter{}).Eligible(&base, math.LegacyZeroDec(), now); !ok {
+		t.Error("expected empty filter to accept an active pool")
+	}
+}
+
+// TestAllocationTargetValues tests weight normalization and exposure caps
+func TestAllocationTargetValues(t *testing.T) {
+	s := types.NewAllocationStrategy("main-lp", []types.AllocationTarget{
+		{PoolID: "pool-a", Weight: dec("3")},
+		{PoolID: "pool-b", Weight: dec("1")},
+		{PoolID: "pool-c", Weight: dec("4")},
+	})
+	s.MaxAllocation = dec("0.8")
+	s.MaxPoolExposure = dec("0.5")
+
+	// pool-c is excluded, so pool-a and pool-b split 80% of 1000 as 3:1
+	targets := s.TargetValues(dec("1000"), map[string]bool{"pool-a": true, "pool-b": true})
+	if !targets["pool-a"].Equal(dec("500")) { // 600 capped at 50%
+		t.Errorf("expected pool-a target 500, got %s", targets["pool-a"])
+	}
+	if !targets["pool-b"].Equal(dec("200")) {
+		t.Errorf("expected pool-b target 200, got %s", targets["pool-b"])
+	}
+	if _, ok := targets["pool-c"]; ok {
+		t.Error("expected no target for ineligible pool-c")
+	}
+
+	if len(s.TargetValues(dec("1000"), map[string]bool{})) != 0 {
+		t.Error("expected no targets when nothing is eligible")
+	}
+}
+
+// TestPlanRebalance tests order planning and drift
+func TestPlanRebalance(t *testing.T) {
+	targets := map[string]math.LegacyDec{"pool-a": dec("300"), "pool-b": dec("300")}
+	current := map[string]math.LegacyDec{"pool-a": dec("100"), "pool-c": dec("250")}
+
+	drift := types.MaxDrift(targets, current, dec("1000"))
+	if !drift.Equal(dec("0.3")) {
+		t.Errorf("expected drift 0.3, got %s", drift)
+	}
+
+	// Redeeming pool-c adds 250 to 100 cash, funding pool-a in full and pool-b in part
+	orders := types.PlanRebalance(targets, current, dec("100"))
+	expected := []types.RebalanceOrder{
+		{PoolID: "pool-c", Amount: dec("-250")},
+		{PoolID: "pool-a", Amount: dec("200")},
+		{PoolID: "pool-b", Amount: dec("150")},
+	}
+	if len(orders) != len(expected) {
+		t.Fatalf("expected %d orders, got %+v", len(expected), orders)
+	}
+	for i, o := range orders {
+		if o.PoolID != expected[i].PoolID || !o.Amount.Equal(expected[i].Amount) {
+			t.Errorf("order %d: expected %s %s, got %s %s", i, expected[i].PoolID, expected[i].Amount, o.PoolID, o.Amount)
+		}
+	}
+
+	if len(types.PlanRebalance(targets, targets, math.LegacyZeroDec())) != 0 {
+		t.Error("expected no orders when holdings match targets")
+	}
+}
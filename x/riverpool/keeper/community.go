@@ -2,10 +2,15 @@ package keeper
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"strconv"
 	"time"
 
 	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/openalpha/perp-dex/x/riverpool/types"
 )
@@ -25,6 +30,7 @@ type CommunityPoolConfig struct {
 	OwnerMinStake        math.LegacyDec // Min % owner must stake (e.g., 0.05 for 5%)
 	IsPrivate            bool           // Requires invite code
 	MaxSeats             int64          // 0 = unlimited
+	MaxHolders           int64          // 0 = unlimited
 	MaxLeverage          math.LegacyDec // Max leverage allowed
 	AllowedMarkets       []string       // Markets owner can trade
 	Tags                 []string       // Pool tags for discovery
@@ -82,6 +88,7 @@ func (k *Keeper) CreateCommunityPool(
 	pool.OwnerCurrentStake = math.LegacyZeroDec()
 	pool.IsPrivate = config.IsPrivate
 	pool.TotalHolders = 0
+	pool.MaxHolders = config.MaxHolders
 	pool.MaxLeverage = config.MaxLeverage
 	pool.AllowedMarkets = config.AllowedMarkets
 	pool.Tags = config.Tags
@@ -137,6 +144,10 @@ func (k *Keeper) validateCommunityPoolConfig(config CommunityPoolConfig) error {
 		return types.ErrInvalidOwner
 	}
 
+	if config.MaxHolders < 0 {
+		return types.ErrInvalidMaxHolders
+	}
+
 	// Min deposit must be at least $10
 	minAllowed := math.LegacyNewDec(10)
 	if config.MinDeposit.LT(minAllowed) {
@@ -298,6 +309,7 @@ type InviteCode struct {
 	ExpiresAt int64 // 0 = never
 	CreatedAt int64
 	IsActive  bool
+	RevokedAt int64 // 0 = not revoked
 }
 
 // InviteCodeKeyPrefix is the prefix for invite codes
@@ -306,10 +318,17 @@ var InviteCodeKeyPrefix = []byte{0x0D}
 // PoolInviteCodesKeyPrefix is the prefix for pool -> invite codes mapping
 var PoolInviteCodesKeyPrefix = []byte{0x0E}
 
+// InviteRedemptionKeyPrefix is the prefix for code:address -> redemption records
+var InviteRedemptionKeyPrefix = []byte{0x14}
+
+// PoolMemberKeyPrefix is the prefix for pool:address -> redeemed code, marking
+// addresses admitted to a private pool
+var PoolMemberKeyPrefix = []byte{0x15}
+
 // GenerateInviteCode generates a new invite code for a pool
 func (k *Keeper) GenerateInviteCode(ctx sdk.Context, poolID string, maxUses int, expiresInDays int) *InviteCode {
 	now := time.Now().Unix()
-	code := k.generateRandomCode()
+	code := k.generateRandomCode(ctx, poolID)
 
 	var expiresAt int64
 	if expiresInDays > 0 {
@@ -411,32 +430,25 @@ func (k *Keeper) GetInviteCode(ctx sdk.Context, code string) *InviteCode {
 
 // ValidateInviteCode validates an invite code for a pool
 func (k *Keeper) ValidateInviteCode(ctx sdk.Context, poolID, code string) bool {
-	inviteCode := k.GetInviteCode(ctx, code)
-	if inviteCode == nil {
-		return false
-	}
+	return k.checkInviteCode(ctx, poolID, code) == nil
+}
 
-	// Check if code belongs to this pool
-	if inviteCode.PoolID != poolID {
-		return false
+// checkInviteCode returns why an invite code cannot be redeemed for a pool
+func (k *Keeper) checkInviteCode(ctx sdk.Context, poolID, code string) error {
+	inviteCode := k.GetInviteCode(ctx, code)
+	if inviteCode == nil || inviteCode.PoolID != poolID {
+		return types.ErrInvalidInviteCode
 	}
-
-	// Check if code is active
 	if !inviteCode.IsActive {
-		return false
+		return fmt.Errorf("%w: revoked", types.ErrInvalidInviteCode)
 	}
-
-	// Check expiration
 	if inviteCode.ExpiresAt > 0 && time.Now().Unix() > inviteCode.ExpiresAt {
-		return false
+		return fmt.Errorf("%w: expired", types.ErrInvalidInviteCode)
 	}
-
-	// Check max uses
 	if inviteCode.MaxUses > 0 && inviteCode.UsedCount >= inviteCode.MaxUses {
-		return false
+		return fmt.Errorf("%w: all %d uses redeemed", types.ErrInvalidInviteCode, inviteCode.MaxUses)
 	}
-
-	return true
+	return nil
 }
 
 // UseInviteCode marks an invite code as used
@@ -450,12 +462,170 @@ func (k *Keeper) UseInviteCode(ctx sdk.Context, code string) {
 	k.SetInviteCode(ctx, inviteCode)
 }
 
-// generateRandomCode generates a random invite code
-func (k *Keeper) generateRandomCode() string {
-	// Use timestamp + random for simplicity
-	// In production, use crypto/rand
-	timestamp := time.Now().UnixNano()
-	return math.NewInt(timestamp).String()[:8]
+// checkPoolAccess decides whether a depositor may enter a private pool. The
+// owner, current holders, members admitted earlier and holders of the pool's
+// own invite code need nothing more; anyone else must redeem a valid invite
+// code, in which case the code is returned so the deposit can record the
+// redemption.
+func (k *Keeper) checkPoolAccess(ctx sdk.Context, pool *types.Pool, depositor, inviteCode string, isHolder bool) (string, error) {
+	if !pool.IsPrivate || isHolder || depositor == pool.Owner || k.GetPoolMemberCode(ctx, pool.PoolID, depositor) != "" {
+		return "", nil
+	}
+	if inviteCode == "" {
+		return "", types.ErrInvalidInviteCode
+	}
+	if pool.InviteCode != "" && inviteCode == pool.InviteCode {
+		return "", nil
+	}
+	if err := k.checkInviteCode(ctx, pool.PoolID, inviteCode); err != nil {
+		return "", err
+	}
+	return inviteCode, nil
+}
+
+// redeemInviteCode consumes one use of an invite code and records which
+// address redeemed it. It runs in the same state transition as the deposit,
+// so a code can never be redeemed more than MaxUses times.
+func (k *Keeper) redeemInviteCode(ctx sdk.Context, code string, deposit *types.Deposit) {
+	k.UseInviteCode(ctx, code)
+
+	redemption := &types.InviteRedemption{
+		Code:       code,
+		PoolID:     deposit.PoolID,
+		Address:    deposit.Depositor,
+		DepositID:  deposit.DepositID,
+		Amount:     deposit.Amount,
+		RedeemedAt: time.Now().Unix(),
+	}
+	k.setInviteRedemption(ctx, redemption)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"riverpool_invite_code_redeemed",
+			sdk.NewAttribute("pool_id", deposit.PoolID),
+			sdk.NewAttribute("code", code),
+			sdk.NewAttribute("address", deposit.Depositor),
+			sdk.NewAttribute("deposit_id", deposit.DepositID),
+		),
+	)
+}
+
+// setInviteRedemption stores a redemption and admits its address to the pool
+func (k *Keeper) setInviteRedemption(ctx sdk.Context, redemption *types.InviteRedemption) {
+	store := k.GetStore(ctx)
+	bz, err := json.Marshal(redemption)
+	if err != nil {
+		k.logger.Error("Failed to marshal invite redemption", "error", err)
+		return
+	}
+	store.Set(append(InviteRedemptionKeyPrefix, []byte(redemption.Code+":"+redemption.Address)...), bz)
+	store.Set(append(PoolMemberKeyPrefix, []byte(redemption.PoolID+":"+redemption.Address)...), []byte(redemption.Code))
+}
+
+// GetPoolMemberCode returns the invite code an address redeemed to join a
+// pool, empty if it has not redeemed one
+func (k *Keeper) GetPoolMemberCode(ctx sdk.Context, poolID, address string) string {
+	store := k.GetStore(ctx)
+	return string(store.Get(append(PoolMemberKeyPrefix, []byte(poolID+":"+address)...)))
+}
+
+// GetInviteRedemptions returns the redemptions of an invite code
+func (k *Keeper) GetInviteRedemptions(ctx sdk.Context, code string) []*types.InviteRedemption {
+	store := k.GetStore(ctx)
+	prefix := append(InviteRedemptionKeyPrefix, []byte(code+":")...)
+	iterator := storetypes.KVStorePrefixIterator(store, prefix)
+	defer iterator.Close()
+
+	var redemptions []*types.InviteRedemption
+	for ; iterator.Valid(); iterator.Next() {
+		var redemption types.InviteRedemption
+		if err := json.Unmarshal(iterator.Value(), &redemption); err != nil {
+			k.logger.Error("Failed to unmarshal invite redemption", "error", err)
+			continue
+		}
+		redemptions = append(redemptions, &redemption)
+	}
+	return redemptions
+}
+
+// RevokeInviteCode deactivates an invite code (owner only). Addresses that
+// already redeemed it stay admitted.
+func (k *Keeper) RevokeInviteCode(ctx sdk.Context, owner, poolID, code string) error {
+	pool := k.GetPool(ctx, poolID)
+	if pool == nil {
+		return types.ErrPoolNotFound
+	}
+
+	if pool.Owner != owner {
+		return types.ErrNotPoolOwner
+	}
+
+	inviteCode := k.GetInviteCode(ctx, code)
+	if inviteCode == nil || inviteCode.PoolID != poolID {
+		return types.ErrInvalidInviteCode
+	}
+
+	if inviteCode.IsActive {
+		inviteCode.IsActive = false
+		inviteCode.RevokedAt = time.Now().Unix()
+		k.SetInviteCode(ctx, inviteCode)
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"riverpool_invite_code_revoked",
+			sdk.NewAttribute("pool_id", poolID),
+			sdk.NewAttribute("owner", owner),
+			sdk.NewAttribute("code", code),
+		),
+	)
+
+	return nil
+}
+
+// SetPoolMaxHolders changes the holder cap of a community pool (owner only).
+// Lowering it below the current holder count only blocks new holders.
+func (k *Keeper) SetPoolMaxHolders(ctx sdk.Context, owner, poolID string, maxHolders int64) error {
+	pool := k.GetPool(ctx, poolID)
+	if pool == nil {
+		return types.ErrPoolNotFound
+	}
+
+	if pool.Owner != owner {
+		return types.ErrNotPoolOwner
+	}
+
+	if maxHolders < 0 {
+		return types.ErrInvalidMaxHolders
+	}
+
+	pool.MaxHolders = maxHolders
+	pool.UpdatedAt = time.Now().Unix()
+	k.SetPool(ctx, pool)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"riverpool_pool_settings_updated",
+			sdk.NewAttribute("pool_id", poolID),
+			sdk.NewAttribute("owner", owner),
+			sdk.NewAttribute("max_holders", strconv.FormatInt(maxHolders, 10)),
+		),
+	)
+
+	return nil
+}
+
+// generateRandomCode derives an invite code from the pool, block and number
+// of codes already issued, so codes are deterministic and never collide with
+// an existing one
+func (k *Keeper) generateRandomCode(ctx sdk.Context, poolID string) string {
+	for n := len(k.GetPoolInviteCodes(ctx, poolID)); ; n++ {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", poolID, ctx.BlockHeight(), n)))
+		code := hex.EncodeToString(hash[:4])
+		if k.GetInviteCode(ctx, code) == nil {
+			return code
+		}
+	}
 }
 
 // CollectManagementFee collects management fee from a pool
@@ -475,7 +645,7 @@ func (k *Keeper) CollectManagementFee(ctx sdk.Context, poolID string) math.Legac
 
 	// Deduct fee from pool
 	pool.TotalDeposits = pool.TotalDeposits.Sub(feeAmount)
-	pool.UpdateNAV(pool.TotalDeposits)
+	pool.UpdateNAV(k.poolValue(ctx, pool))
 	k.SetPool(ctx, pool)
 
 	// Record fee collection
@@ -508,7 +678,7 @@ func (k *Keeper) CollectPerformanceFee(ctx sdk.Context, poolID string, profit ma
 
 	// Deduct fee from pool
 	pool.TotalDeposits = pool.TotalDeposits.Sub(feeAmount)
-	pool.UpdateNAV(pool.TotalDeposits)
+	pool.UpdateNAV(k.poolValue(ctx, pool))
 	k.SetPool(ctx, pool)
 
 	// Record fee collection
@@ -1,6 +1,7 @@
 package keeper
 
 import (
+	"errors"
 	"testing"
 
 	"cosmossdk.io/math"
@@ -447,3 +448,106 @@ func TestNAVHistory(t *testing.T) {
 		t.Errorf("expected NAV 1.05, got %s", history.NAV.String())
 	}
 }
+
+// TestInviteCodeRedemption tests MaxUses enforcement on deposit, redemption
+// records, owner revocation and the holder cap
+func TestInviteCodeRedemption(t *testing.T) {
+	k, ctx, _ := setupKeeper(t)
+	pool := &types.Pool{
+		PoolID:        "cpool-1",
+		PoolType:      types.PoolTypeCommunity,
+		Status:        types.PoolStatusActive,
+		Owner:         "owner",
+		IsPrivate:     true,
+		MaxHolders:    2,
+		TotalDeposits: math.LegacyZeroDec(),
+		TotalShares:   math.LegacyZeroDec(),
+		NAV:           math.LegacyOneDec(),
+		MinDeposit:    dec("10"),
+		MaxDeposit:    math.LegacyZeroDec(),
+	}
+	k.SetPool(ctx, pool)
+	k.SetPoolStats(ctx, types.NewPoolStats(pool.PoolID))
+
+	single := k.GenerateInviteCode(ctx, pool.PoolID, 1, 0)
+	open := k.GenerateInviteCode(ctx, pool.PoolID, 0, 0)
+	if single.Code == open.Code {
+		t.Fatalf("expected distinct codes, got %s twice", single.Code)
+	}
+
+	if _, err := k.Deposit(ctx, "alice", pool.PoolID, dec("100"), ""); !errors.Is(err, types.ErrInvalidInviteCode) {
+		t.Errorf("expected invite code required, got %v", err)
+	}
+	deposit, err := k.Deposit(ctx, "alice", pool.PoolID, dec("100"), single.Code)
+	if err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	if _, err := k.Deposit(ctx, "bob", pool.PoolID, dec("100"), single.Code); !errors.Is(err, types.ErrInvalidInviteCode) {
+		t.Errorf("expected exhausted code, got %v", err)
+	}
+
+	redemptions := k.GetInviteRedemptions(ctx, single.Code)
+	if len(redemptions) != 1 || redemptions[0].Address != "alice" || redemptions[0].DepositID != deposit.DepositID {
+		t.Errorf("expected alice's redemption, got %+v", redemptions)
+	}
+	if code := k.GetInviteCode(ctx, single.Code); code.UsedCount != 1 {
+		t.Errorf("expected 1 use, got %d", code.UsedCount)
+	}
+
+	// Admitted members deposit again without a code or a new use
+	if _, err := k.Deposit(ctx, "alice", pool.PoolID, dec("50"), ""); err != nil {
+		t.Errorf("expected member top-up, got %v", err)
+	}
+
+	if err := k.RevokeInviteCode(ctx, "mallory", pool.PoolID, open.Code); !errors.Is(err, types.ErrNotPoolOwner) {
+		t.Errorf("expected not pool owner, got %v", err)
+	}
+	if err := k.RevokeInviteCode(ctx, "owner", pool.PoolID, open.Code); err != nil {
+		t.Fatalf("failed to revoke: %v", err)
+	}
+	if _, err := k.Deposit(ctx, "bob", pool.PoolID, dec("100"), open.Code); !errors.Is(err, types.ErrInvalidInviteCode) {
+		t.Errorf("expected revoked code, got %v", err)
+	}
+
+	// The owner fills the second holder slot; a third holder is refused
+	if _, err := k.Deposit(ctx, "owner", pool.PoolID, dec("100"), ""); err != nil {
+		t.Fatalf("failed owner deposit: %v", err)
+	}
+	fresh := k.GenerateInviteCode(ctx, pool.PoolID, 0, 0)
+	if _, err := k.Deposit(ctx, "carol", pool.PoolID, dec("100"), fresh.Code); !errors.Is(err, types.ErrPoolHolderLimit) {
+		t.Errorf("expected holder limit, got %v", err)
+	}
+	if got := k.GetPool(ctx, pool.PoolID).TotalHolders; got != 2 {
+		t.Errorf("expected 2 holders, got %d", got)
+	}
+	if code := k.GetInviteCode(ctx, fresh.Code); code.UsedCount != 0 {
+		t.Errorf("expected refused deposit not to consume a use, got %d", code.UsedCount)
+	}
+
+	// Codes and redemptions carry over to a chain started from the export
+	exported := k.ExportGenesis(ctx)
+	if len(exported.InviteCodes) != 3 || len(exported.InviteRedemptions) != 1 {
+		t.Fatalf("expected 3 codes and 1 redemption exported, got %d and %d", len(exported.InviteCodes), len(exported.InviteRedemptions))
+	}
+	k2, ctx2, _ := setupKeeper(t)
+	if err := k2.InitGenesis(ctx2, exported); err != nil {
+		t.Fatalf("failed to import genesis: %v", err)
+	}
+	if code := k2.GetPoolMemberCode(ctx2, pool.PoolID, "alice"); code != single.Code {
+		t.Errorf("expected alice admitted with %s, got %q", single.Code, code)
+	}
+	if code := k2.GetInviteCode(ctx2, single.Code); code == nil || code.UsedCount != 1 {
+		t.Errorf("expected the single-use code exhausted, got %+v", code)
+	}
+	if code := k2.GetInviteCode(ctx2, open.Code); code == nil || code.IsActive || code.RevokedAt == 0 {
+		t.Errorf("expected the revoked code inactive, got %+v", code)
+	}
+	if codes := k2.GetPoolInviteCodes(ctx2, pool.PoolID); len(codes) != 3 {
+		t.Errorf("expected 3 pool codes, got %d", len(codes))
+	}
+
+	exported.InviteRedemptions[0].PoolID = "other-pool"
+	if err := exported.Validate(); !errors.Is(err, types.ErrInvalidGenesis) {
+		t.Errorf("expected a redemption for another pool rejected, got %v", err)
+	}
+}
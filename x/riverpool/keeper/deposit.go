@@ -37,19 +37,33 @@ func (k *Keeper) Deposit(ctx context.Context, depositor, poolID string, amount m
 		if !pool.HasAvailableSeats() {
 			return nil, types.ErrFoundationPoolFull
 		}
+		if k.lpObligations != nil && !k.lpObligations.LPObligationEligible(sdkCtx, depositor) {
+			return nil, types.ErrFoundationSeatIneligible
+		}
 		// Foundation LP requires exact seat size
 		if !amount.Equal(types.FoundationSeatSize) {
 			return nil, types.ErrDepositTooSmall
 		}
 	}
 
-	// Private pool check
-	if pool.IsPrivate && pool.InviteCode != inviteCode {
-		return nil, types.ErrInvalidInviteCode
+	// Private pool check: invite codes are validated here and consumed below,
+	// in the same state transition as the deposit
+	isHolder := k.GetUserTotalShares(sdkCtx, poolID, depositor).IsPositive()
+	redeemCode, err := k.checkPoolAccess(sdkCtx, pool, depositor, inviteCode, isHolder)
+	if err != nil {
+		return nil, err
+	}
+
+	// Holder cap only applies to new holders
+	if !isHolder && pool.MaxHolders > 0 && pool.TotalHolders >= pool.MaxHolders {
+		return nil, types.ErrPoolHolderLimit
 	}
 
-	// Calculate shares
+	// Calculate shares and mint them as pool share coins
 	shares := pool.CalculateSharesForDeposit(amount)
+	if err := k.mintLPTokens(sdkCtx, poolID, depositor, shares); err != nil {
+		return nil, err
+	}
 
 	// Create deposit record
 	deposit := types.NewDeposit(poolID, depositor, amount, shares, pool.NAV, pool.LockPeriodDays)
@@ -62,11 +76,17 @@ func (k *Keeper) Deposit(ctx context.Context, depositor, poolID string, amount m
 	// Update pool
 	pool.TotalDeposits = pool.TotalDeposits.Add(amount)
 	pool.TotalShares = pool.TotalShares.Add(shares)
+	if !isHolder {
+		pool.TotalHolders++
+	}
 	pool.UpdatedAt = time.Now().Unix()
 
 	// Save to store
 	k.SetDeposit(sdkCtx, deposit)
 	k.SetPool(sdkCtx, pool)
+	if redeemCode != "" {
+		k.redeemInviteCode(sdkCtx, redeemCode, deposit)
+	}
 
 	// Update pool stats
 	stats := k.GetPoolStats(sdkCtx, poolID)
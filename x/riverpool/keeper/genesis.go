@@ -0,0 +1,128 @@
+package keeper
+
+import (
+	"encoding/json"
+
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// InitGenesis imports riverpool state, then creates the Foundation LP and
+// Main LP if the genesis did not list them
+func (k *Keeper) InitGenesis(ctx sdk.Context, gs *types.GenesisState) error {
+	if err := gs.Validate(); err != nil {
+		return err
+	}
+
+	for _, pool := range gs.Pools {
+		k.SetPool(ctx, pool)
+	}
+	for _, stats := range gs.PoolStats {
+		k.SetPoolStats(ctx, stats)
+	}
+	for _, state := range gs.DDGuardStates {
+		k.SetDDGuardState(ctx, state)
+	}
+	for _, deposit := range gs.Deposits {
+		k.SetDeposit(ctx, deposit)
+	}
+	for _, withdrawal := range gs.Withdrawals {
+		k.SetWithdrawal(ctx, withdrawal)
+	}
+	for _, strategy := range gs.AllocationStrategies {
+		k.setAllocationStrategy(ctx, strategy)
+	}
+	for _, allocation := range gs.PoolAllocations {
+		k.SetPoolAllocation(ctx, allocation)
+	}
+	if gs.NAVPricing != nil {
+		if err := k.SetNAVPricingConfig(ctx, gs.NAVPricing); err != nil {
+			return err
+		}
+	}
+	for _, code := range gs.InviteCodes {
+		k.SetInviteCode(ctx, &InviteCode{
+			Code:      code.Code,
+			PoolID:    code.PoolID,
+			UsedCount: code.UsedCount,
+			MaxUses:   code.MaxUses,
+			ExpiresAt: code.ExpiresAt,
+			CreatedAt: code.CreatedAt,
+			IsActive:  code.IsActive,
+			RevokedAt: code.RevokedAt,
+		})
+		k.addInviteCodeToPool(ctx, code.PoolID, code.Code)
+	}
+	for _, redemption := range gs.InviteRedemptions {
+		k.setInviteRedemption(ctx, redemption)
+	}
+
+	k.InitDefaultPools(ctx)
+	return nil
+}
+
+// ExportGenesis exports pools and their stats, DDGuard states, deposits,
+// withdrawals, Main LP allocations, the NAV pricing safeguards and private
+// pool invite codes with their redemptions. NAV and revenue history is not
+// exported.
+func (k *Keeper) ExportGenesis(ctx sdk.Context) *types.GenesisState {
+	gs := types.DefaultGenesis()
+	store := k.GetStore(ctx)
+
+	for _, pool := range k.GetAllPools(ctx) {
+		gs.Pools = append(gs.Pools, pool)
+		gs.PoolStats = append(gs.PoolStats, k.GetPoolStats(ctx, pool.PoolID))
+		if state := k.GetDDGuardState(ctx, pool.PoolID); state != nil {
+			gs.DDGuardStates = append(gs.DDGuardStates, state)
+		}
+		gs.PoolAllocations = append(gs.PoolAllocations, k.GetPoolAllocations(ctx, pool.PoolID)...)
+		for _, code := range k.GetPoolInviteCodes(ctx, pool.PoolID) {
+			gs.InviteCodes = append(gs.InviteCodes, &types.InviteCode{
+				Code:      code.Code,
+				PoolID:    code.PoolID,
+				MaxUses:   code.MaxUses,
+				UsedCount: code.UsedCount,
+				CreatedBy: pool.Owner,
+				CreatedAt: code.CreatedAt,
+				ExpiresAt: code.ExpiresAt,
+				IsActive:  code.IsActive,
+				RevokedAt: code.RevokedAt,
+			})
+		}
+	}
+
+	iterateJSON(store, DepositKeyPrefix, func(bz []byte) {
+		var deposit types.Deposit
+		if err := json.Unmarshal(bz, &deposit); err == nil {
+			gs.Deposits = append(gs.Deposits, &deposit)
+		}
+	})
+	iterateJSON(store, WithdrawalKeyPrefix, func(bz []byte) {
+		var withdrawal types.Withdrawal
+		if err := json.Unmarshal(bz, &withdrawal); err == nil {
+			gs.Withdrawals = append(gs.Withdrawals, &withdrawal)
+		}
+	})
+
+	iterateJSON(store, InviteRedemptionKeyPrefix, func(bz []byte) {
+		var redemption types.InviteRedemption
+		if err := json.Unmarshal(bz, &redemption); err == nil {
+			gs.InviteRedemptions = append(gs.InviteRedemptions, &redemption)
+		}
+	})
+
+	gs.AllocationStrategies = append(gs.AllocationStrategies, k.GetAllAllocationStrategies(ctx)...)
+	gs.NAVPricing = k.GetNAVPricingConfig(ctx)
+	return gs
+}
+
+// iterateJSON calls fn with every value stored under prefix
+func iterateJSON(store storetypes.KVStore, prefix []byte, fn func(bz []byte)) {
+	iterator := storetypes.KVStorePrefixIterator(store, prefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		fn(iterator.Value())
+	}
+}
@@ -0,0 +1,49 @@
+package keeper
+
+import (
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+	"github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// Hooks attributes fills of community pool orders that rest on the book to
+// their pool. Fills of a pool order when it is placed are attributed by
+// PlacePoolOrder.
+type Hooks struct {
+	k *Keeper
+}
+
+var _ orderbooktypes.TradeHooks = Hooks{}
+
+// Hooks returns the riverpool trade hooks
+func (k *Keeper) Hooks() Hooks {
+	return Hooks{k: k}
+}
+
+// AfterTradeExecuted records a trade whose maker is a pool trading account
+func (h Hooks) AfterTradeExecuted(ctx sdk.Context, trade *orderbooktypes.Trade) error {
+	poolID := h.k.getPoolByTradingAccount(ctx, trade.Maker)
+	if poolID == "" {
+		return nil
+	}
+	side := types.OrderSideSell
+	if trade.TakerSide == orderbooktypes.SideSell {
+		side = types.OrderSideBuy
+	}
+	poolTrade := types.NewPoolTrade(poolID, trade.MarketID, side, trade.Quantity, trade.Price, trade.MakerFee)
+	poolTrade.TradeID = trade.TradeID
+	poolTrade.ExecutedAt = ctx.BlockTime().Unix()
+	h.k.SetPoolTrade(ctx, poolTrade)
+	return nil
+}
+
+// AfterPositionChanged implements orderbooktypes.TradeHooks
+func (h Hooks) AfterPositionChanged(ctx sdk.Context, trader, marketID string) error {
+	return nil
+}
+
+// AfterLiquidation implements orderbooktypes.TradeHooks
+func (h Hooks) AfterLiquidation(ctx sdk.Context, trader, marketID string, size, price math.LegacyDec) error {
+	return nil
+}
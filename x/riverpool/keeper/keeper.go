@@ -30,12 +30,32 @@ var (
 // PerpetualKeeper defines the expected interface for perpetual module
 type PerpetualKeeper interface {
 	GetPrice(ctx sdk.Context, marketID string) *perpetualtypes.PriceInfo
+	GetFreeCollateral(ctx sdk.Context, trader string) math.LegacyDec
+	Deposit(ctx context.Context, trader string, amount math.LegacyDec) error
+	CreditAccount(ctx context.Context, trader string, amount math.LegacyDec) error
+	Withdraw(ctx context.Context, trader string, amount math.LegacyDec) error
+	GetPositionsByTrader(ctx sdk.Context, trader string) []*perpetualtypes.Position
 }
 
-// BankKeeper defines the expected interface for the bank module
+// OrderbookKeeper defines the expected interface for the orderbook module.
+// A zero price places a market order.
+type OrderbookKeeper interface {
+	PlaceOrder(ctx sdk.Context, trader, marketID string, isBuy bool, price, quantity math.LegacyDec) (*types.OrderFill, error)
+}
+
+// LPObligationKeeper reports whether designated market makers met their
+// quoting obligations, which Foundation LP seats require
+type LPObligationKeeper interface {
+	LPObligationEligible(ctx sdk.Context, trader string) bool
+}
+
+// BankKeeper defines the expected interface for the bank module. The module
+// mints and burns pool share coins with it.
 type BankKeeper interface {
 	SendCoinsFromAccountToModule(ctx context.Context, senderAddr sdk.AccAddress, recipientModule string, amt sdk.Coins) error
 	SendCoinsFromModuleToAccount(ctx context.Context, senderModule string, recipientAddr sdk.AccAddress, amt sdk.Coins) error
+	MintCoins(ctx context.Context, moduleName string, amt sdk.Coins) error
+	BurnCoins(ctx context.Context, moduleName string, amt sdk.Coins) error
 }
 
 // Keeper manages the riverpool module state
@@ -43,9 +63,12 @@ type Keeper struct {
 	cdc             codec.BinaryCodec
 	storeKey        storetypes.StoreKey
 	perpetualKeeper PerpetualKeeper
+	orderbookKeeper OrderbookKeeper
 	bankKeeper      BankKeeper
 	logger          log.Logger
 	authority       string
+
+	lpObligations LPObligationKeeper // optional
 }
 
 // NewKeeper creates a new riverpool keeper
@@ -53,6 +76,7 @@ func NewKeeper(
 	cdc codec.BinaryCodec,
 	storeKey storetypes.StoreKey,
 	perpetualKeeper PerpetualKeeper,
+	orderbookKeeper OrderbookKeeper,
 	bankKeeper BankKeeper,
 	authority string,
 	logger log.Logger,
@@ -61,6 +85,7 @@ func NewKeeper(
 		cdc:             cdc,
 		storeKey:        storeKey,
 		perpetualKeeper: perpetualKeeper,
+		orderbookKeeper: orderbookKeeper,
 		bankKeeper:      bankKeeper,
 		authority:       authority,
 		logger:          logger.With("module", "x/riverpool"),
@@ -68,6 +93,12 @@ func NewKeeper(
 	return k
 }
 
+// SetLPObligationKeeper makes Foundation LP deposits require designated
+// market makers to have met their quoting obligations
+func (k *Keeper) SetLPObligationKeeper(lpObligations LPObligationKeeper) {
+	k.lpObligations = lpObligations
+}
+
 // Logger returns the module logger
 func (k *Keeper) Logger() log.Logger {
 	return k.logger
@@ -293,6 +324,25 @@ func (k *Keeper) GetUserWithdrawals(ctx sdk.Context, user string) []*types.Withd
 	return withdrawals
 }
 
+// GetPoolWithdrawals returns all withdrawals for a pool
+func (k *Keeper) GetPoolWithdrawals(ctx sdk.Context, poolID string) []*types.Withdrawal {
+	store := k.GetStore(ctx)
+	iterator := storetypes.KVStorePrefixIterator(store, WithdrawalKeyPrefix)
+	defer iterator.Close()
+
+	var withdrawals []*types.Withdrawal
+	for ; iterator.Valid(); iterator.Next() {
+		var withdrawal types.Withdrawal
+		if err := json.Unmarshal(iterator.Value(), &withdrawal); err != nil {
+			continue
+		}
+		if withdrawal.PoolID == poolID {
+			withdrawals = append(withdrawals, &withdrawal)
+		}
+	}
+	return withdrawals
+}
+
 // GetPendingWithdrawals returns all pending withdrawals for a pool
 func (k *Keeper) GetPendingWithdrawals(ctx sdk.Context, poolID string) []*types.Withdrawal {
 	store := k.GetStore(ctx)
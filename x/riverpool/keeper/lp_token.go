@@ -0,0 +1,140 @@
+package keeper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// ============ Pool Share Tokens ============
+
+// Pool shares are minted as rp/{poolID}-lp coins to the depositor and burned
+// as they are redeemed, so a holder's coin balance always equals the shares
+// in its deposit records. Without a bank keeper, as in tests and the
+// standalone API, shares are tracked in the records only.
+
+// mintLPTokens mints shares of a pool to a holder
+func (k *Keeper) mintLPTokens(ctx sdk.Context, poolID, holder string, shares math.LegacyDec) error {
+	if k.bankKeeper == nil || !shares.IsPositive() {
+		return nil
+	}
+	addr, err := sdk.AccAddressFromBech32(holder)
+	if err != nil {
+		return err
+	}
+	coins := sdk.NewCoins(sdk.NewCoin(types.LPDenom(poolID), types.SharesToLPAmount(shares)))
+	if err := k.bankKeeper.MintCoins(ctx, types.ModuleName, coins); err != nil {
+		return err
+	}
+	return k.bankKeeper.SendCoinsFromModuleToAccount(ctx, types.ModuleName, addr, coins)
+}
+
+// burnLPTokens burns shares of a pool from a holder
+func (k *Keeper) burnLPTokens(ctx sdk.Context, poolID, holder string, shares math.LegacyDec) error {
+	if k.bankKeeper == nil || !shares.IsPositive() {
+		return nil
+	}
+	addr, err := sdk.AccAddressFromBech32(holder)
+	if err != nil {
+		return err
+	}
+	coins := sdk.NewCoins(sdk.NewCoin(types.LPDenom(poolID), types.SharesToLPAmount(shares)))
+	if err := k.bankKeeper.SendCoinsFromAccountToModule(ctx, addr, types.ModuleName, coins); err != nil {
+		return err
+	}
+	return k.bankKeeper.BurnCoins(ctx, types.ModuleName, coins)
+}
+
+// LPSendRestriction is registered as a bank send restriction. Transfers of
+// pool share coins between accounts move the shares they represent, and are
+// refused if they would move locked shares, shares queued for withdrawal or
+// Foundation LP seats, or give shares of a private pool to an account it does
+// not admit. Mints and burns through the module account are not restricted.
+func (k *Keeper) LPSendRestriction(ctx context.Context, fromAddr, toAddr sdk.AccAddress, amt sdk.Coins) (sdk.AccAddress, error) {
+	moduleAddr := authtypes.NewModuleAddress(types.ModuleName)
+	if fromAddr.Equals(moduleAddr) || toAddr.Equals(moduleAddr) || fromAddr.Equals(toAddr) {
+		return toAddr, nil
+	}
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+	for _, coin := range amt {
+		poolID, ok := types.PoolIDFromLPDenom(coin.Denom)
+		if !ok {
+			continue
+		}
+		if err := k.TransferShares(sdkCtx, poolID, fromAddr.String(), toAddr.String(), types.LPAmountToShares(coin.Amount)); err != nil {
+			return nil, err
+		}
+	}
+	return toAddr, nil
+}
+
+// GetUserTransferableShares returns a holder's unlocked shares in a pool not
+// already queued for withdrawal
+func (k *Keeper) GetUserTransferableShares(ctx sdk.Context, poolID, user string) math.LegacyDec {
+	transferable := k.GetUserAvailableShares(ctx, poolID, user)
+	for _, w := range k.GetUserWithdrawals(ctx, user) {
+		if w.PoolID != poolID {
+			continue
+		}
+		if w.Status == types.WithdrawalStatusPending || w.Status == types.WithdrawalStatusProcessing {
+			transferable = transferable.Sub(w.SharesRequested.Sub(w.SharesRedeemed))
+		}
+	}
+	return math.LegacyMaxDec(transferable, math.LegacyZeroDec())
+}
+
+// TransferShares moves shares of a pool between holders. The sender's
+// deposits are reduced oldest first; the receiver gets an unlocked deposit
+// record at the current NAV, which becomes its cost basis.
+func (k *Keeper) TransferShares(ctx sdk.Context, poolID, from, to string, shares math.LegacyDec) error {
+	pool := k.GetPool(ctx, poolID)
+	if pool == nil {
+		return types.ErrPoolNotFound
+	}
+	if !shares.IsPositive() {
+		return nil
+	}
+	if pool.PoolType == types.PoolTypeFoundation {
+		return fmt.Errorf("%w: foundation seats cannot be transferred", types.ErrSharesNotTransferable)
+	}
+	if transferable := k.GetUserTransferableShares(ctx, poolID, from); shares.GT(transferable) {
+		return fmt.Errorf("%w: %s transferable, %s requested", types.ErrSharesNotTransferable, transferable, shares)
+	}
+
+	isHolder := k.GetUserTotalShares(ctx, poolID, to).IsPositive()
+	if _, err := k.checkPoolAccess(ctx, pool, to, "", isHolder); err != nil {
+		return fmt.Errorf("%w: receiver is not admitted to private pool %s", types.ErrSharesNotTransferable, poolID)
+	}
+	if !isHolder && pool.MaxHolders > 0 && pool.TotalHolders >= pool.MaxHolders {
+		return types.ErrPoolHolderLimit
+	}
+
+	k.reduceUserShares(ctx, from, poolID, shares)
+	k.SetDeposit(ctx, types.NewDeposit(poolID, to, pool.CalculateValueForShares(shares), shares, pool.NAV, 0))
+
+	if !isHolder {
+		pool.TotalHolders++
+	}
+	if pool.TotalHolders > 0 && !k.GetUserTotalShares(ctx, poolID, from).IsPositive() {
+		pool.TotalHolders--
+	}
+	pool.UpdatedAt = time.Now().Unix()
+	k.SetPool(ctx, pool)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"riverpool_shares_transferred",
+			sdk.NewAttribute("pool_id", poolID),
+			sdk.NewAttribute("from", from),
+			sdk.NewAttribute("to", to),
+			sdk.NewAttribute("shares", shares.String()),
+			sdk.NewAttribute("nav", pool.NAV.String()),
+		),
+	)
+	return nil
+}
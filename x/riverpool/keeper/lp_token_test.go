@@ -0,0 +1,163 @@
+package keeper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// fakeBank keeps coin balances by account address or module name
+type fakeBank struct {
+	balances map[string]sdk.Coins
+}
+
+func (b *fakeBank) SendCoinsFromAccountToModule(ctx context.Context, senderAddr sdk.AccAddress, recipientModule string, amt sdk.Coins) error {
+	return b.move(senderAddr.String(), recipientModule, amt)
+}
+
+func (b *fakeBank) SendCoinsFromModuleToAccount(ctx context.Context, senderModule string, recipientAddr sdk.AccAddress, amt sdk.Coins) error {
+	return b.move(senderModule, recipientAddr.String(), amt)
+}
+
+func (b *fakeBank) MintCoins(ctx context.Context, moduleName string, amt sdk.Coins) error {
+	b.balances[moduleName] = b.balances[moduleName].Add(amt...)
+	return nil
+}
+
+func (b *fakeBank) BurnCoins(ctx context.Context, moduleName string, amt sdk.Coins) error {
+	return b.move(moduleName, "burned", amt)
+}
+
+func (b *fakeBank) move(from, to string, amt sdk.Coins) error {
+	balance, negative := b.balances[from].SafeSub(amt...)
+	if negative {
+		return errors.New("insufficient funds")
+	}
+	b.balances[from] = balance
+	b.balances[to] = b.balances[to].Add(amt...)
+	return nil
+}
+
+// TestLPTokens tests that pool shares are minted and burned as coins, and
+// that transfers move shares only when the pool's rules allow it
+func TestLPTokens(t *testing.T) {
+	k, ctx, _ := setupKeeper(t)
+	bank := &fakeBank{balances: map[string]sdk.Coins{}}
+	k.bankKeeper = bank
+
+	newPool := func(poolID, poolType string, lockDays int64) *types.Pool {
+		pool := &types.Pool{
+			PoolID:         poolID,
+			PoolType:       poolType,
+			Status:         types.PoolStatusActive,
+			Owner:          "owner",
+			TotalDeposits:  math.LegacyZeroDec(),
+			TotalShares:    math.LegacyZeroDec(),
+			NAV:            dec("2"),
+			MinDeposit:     dec("10"),
+			MaxDeposit:     math.LegacyZeroDec(),
+			LockPeriodDays: lockDays,
+		}
+		k.SetPool(ctx, pool)
+		k.SetPoolStats(ctx, types.NewPoolStats(poolID))
+		return pool
+	}
+	alice := sdk.AccAddress("alice_______________")
+	bob := sdk.AccAddress("bob_________________")
+	balance := func(addr sdk.AccAddress, poolID string) math.LegacyDec {
+		return types.LPAmountToShares(bank.balances[addr.String()].AmountOf(types.LPDenom(poolID)))
+	}
+	send := func(from, to sdk.AccAddress, poolID, shares string) error {
+		amt := sdk.NewCoins(sdk.NewCoin(types.LPDenom(poolID), types.SharesToLPAmount(dec(shares))))
+		if _, err := k.LPSendRestriction(ctx, from, to, amt); err != nil {
+			return err
+		}
+		return bank.move(from.String(), to.String(), amt)
+	}
+
+	if poolID, ok := types.PoolIDFromLPDenom("rp/main-lp"); !ok || poolID != "main" {
+		t.Errorf("expected main, got %q %v", poolID, ok)
+	}
+	if _, ok := types.PoolIDFromLPDenom("rp/-lp"); ok {
+		t.Error("expected an empty pool ID to be rejected")
+	}
+
+	newPool("main", types.PoolTypeMain, 0)
+	if _, err := k.Deposit(ctx, alice.String(), "main", dec("100"), ""); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	if got := balance(alice, "main"); !got.Equal(dec("50")) {
+		t.Fatalf("expected 50 shares minted, got %s", got)
+	}
+
+	// A transfer moves the records with the coins; the receiver's cost
+	// basis is the shares' value at the current NAV
+	if err := send(alice, bob, "main", "20"); err != nil {
+		t.Fatalf("failed to transfer: %v", err)
+	}
+	if got := k.GetUserTotalShares(ctx, "main", alice.String()); !got.Equal(dec("30")) || !balance(alice, "main").Equal(got) {
+		t.Errorf("expected alice to keep 30 shares, got %s", got)
+	}
+	if shares, _, cost := k.GetUserPoolBalance(ctx, "main", bob.String()); !shares.Equal(dec("20")) || !cost.Equal(dec("40")) {
+		t.Errorf("expected bob's 20 shares at a cost of 40, got %s at %s", shares, cost)
+	}
+	if got := k.GetPool(ctx, "main").TotalHolders; got != 2 {
+		t.Errorf("expected 2 holders, got %d", got)
+	}
+
+	// Shares queued for withdrawal stay with the withdrawer
+	withdrawal, err := k.RequestWithdrawal(ctx, alice.String(), "main", dec("25"))
+	if err != nil {
+		t.Fatalf("failed to request withdrawal: %v", err)
+	}
+	if err := send(alice, bob, "main", "10"); !errors.Is(err, types.ErrSharesNotTransferable) {
+		t.Errorf("expected queued shares to be refused, got %v", err)
+	}
+	if err := send(alice, bob, "main", "5"); err != nil {
+		t.Errorf("expected the unqueued shares to transfer, got %v", err)
+	}
+
+	// Claiming burns the redeemed shares
+	if _, _, err := k.ClaimWithdrawal(ctx, alice.String(), withdrawal.WithdrawalID); err != nil {
+		t.Fatalf("failed to claim: %v", err)
+	}
+	if got := balance(alice, "main"); !got.IsZero() {
+		t.Errorf("expected alice's shares burned, got %s", got)
+	}
+	if got := bank.balances["burned"].AmountOf(types.LPDenom("main")); !types.LPAmountToShares(got).Equal(dec("25")) {
+		t.Errorf("expected 25 shares burned, got %s", got)
+	}
+	if pool := k.GetPool(ctx, "main"); !pool.TotalShares.Equal(balance(bob, "main")) || pool.TotalHolders != 1 {
+		t.Errorf("expected bob to hold all %s shares alone, got %s of %d holders", pool.TotalShares, balance(bob, "main"), pool.TotalHolders)
+	}
+
+	// Locked shares, seats and private pools restrict transfers
+	newPool("locked", types.PoolTypeCommunity, 30)
+	if _, err := k.Deposit(ctx, alice.String(), "locked", dec("100"), ""); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	if err := send(alice, bob, "locked", "1"); !errors.Is(err, types.ErrSharesNotTransferable) {
+		t.Errorf("expected locked shares to be refused, got %v", err)
+	}
+	newPool("seats", types.PoolTypeFoundation, 0)
+	if err := k.TransferShares(ctx, "seats", alice.String(), bob.String(), dec("1")); !errors.Is(err, types.ErrSharesNotTransferable) {
+		t.Errorf("expected seats to be refused, got %v", err)
+	}
+	private := newPool("private", types.PoolTypeCommunity, 0)
+	private.IsPrivate = true
+	private.InviteCode = "join"
+	k.SetPool(ctx, private)
+	if _, err := k.Deposit(ctx, alice.String(), "private", dec("100"), "join"); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	if err := send(alice, bob, "private", "1"); !errors.Is(err, types.ErrSharesNotTransferable) {
+		t.Errorf("expected a non-member receiver to be refused, got %v", err)
+	}
+	if got := k.GetUserTotalShares(ctx, "private", bob.String()); !got.IsZero() {
+		t.Errorf("expected no shares moved, got %s", got)
+	}
+}
@@ -0,0 +1,48 @@
+package keeper
+
+import (
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Migrator runs in-place store migrations for the riverpool module
+type Migrator struct {
+	keeper *Keeper
+}
+
+// NewMigrator returns a new Migrator
+func NewMigrator(keeper *Keeper) Migrator {
+	return Migrator{keeper: keeper}
+}
+
+// Migrate1to2 migrates the store from consensus version 1 to 2. Version 2
+// adds genesis import and export; the store layout is unchanged.
+func (m Migrator) Migrate1to2(ctx sdk.Context) error {
+	return nil
+}
+
+// Migrate2to3 migrates the store from consensus version 2 to 3. Version 3
+// represents pool shares as bank coins, so each holder is minted the shares
+// in its deposit records.
+func (m Migrator) Migrate2to3(ctx sdk.Context) error {
+	for _, pool := range m.keeper.GetAllPools(ctx) {
+		holders := make([]string, 0)
+		shares := make(map[string]math.LegacyDec)
+		for _, deposit := range m.keeper.GetPoolDeposits(ctx, pool.PoolID) {
+			if !deposit.Shares.IsPositive() {
+				continue
+			}
+			if _, ok := shares[deposit.Depositor]; !ok {
+				holders = append(holders, deposit.Depositor)
+				shares[deposit.Depositor] = math.LegacyZeroDec()
+			}
+			shares[deposit.Depositor] = shares[deposit.Depositor].Add(deposit.Shares)
+		}
+		for _, holder := range holders {
+			if err := m.keeper.mintLPTokens(ctx, pool.PoolID, holder, shares[holder]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -39,6 +39,31 @@ func (m *MsgServer) Deposit(ctx context.Context, msg *types.MsgDeposit) (*types.
 	}, nil
 }
 
+// DepositFromTradingAccount handles MsgDepositFromTradingAccount
+func (m *MsgServer) DepositFromTradingAccount(ctx context.Context, msg *types.MsgDepositFromTradingAccount) (*types.MsgDepositFromTradingAccountResponse, error) {
+	amount, err := math.LegacyNewDecFromStr(msg.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if !amount.IsPositive() {
+		return nil, types.ErrDepositTooSmall
+	}
+
+	deposit, err := m.keeper.DepositFromTradingAccount(ctx, msg.Depositor, msg.PoolID, amount, msg.InviteCode)
+	if err != nil {
+		return nil, err
+	}
+
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+	return &types.MsgDepositFromTradingAccountResponse{
+		DepositID:      deposit.DepositID,
+		SharesReceived: deposit.Shares.String(),
+		NAVAtDeposit:   deposit.NAVAtDeposit.String(),
+		UnlockAt:       deposit.UnlockAt,
+		FreeCollateral: m.keeper.perpetualKeeper.GetFreeCollateral(sdkCtx, msg.Depositor).String(),
+	}, nil
+}
+
 // RequestWithdrawal handles MsgRequestWithdrawal
 func (m *MsgServer) RequestWithdrawal(ctx context.Context, msg *types.MsgRequestWithdrawal) (*types.MsgRequestWithdrawalResponse, error) {
 	shares, err := math.LegacyNewDecFromStr(msg.Shares)
@@ -73,7 +98,11 @@ func (m *MsgServer) RequestWithdrawal(ctx context.Context, msg *types.MsgRequest
 
 // ClaimWithdrawal handles MsgClaimWithdrawal
 func (m *MsgServer) ClaimWithdrawal(ctx context.Context, msg *types.MsgClaimWithdrawal) (*types.MsgClaimWithdrawalResponse, error) {
-	withdrawal, amountReceived, err := m.keeper.ClaimWithdrawal(ctx, msg.Withdrawer, msg.WithdrawalID)
+	claim := m.keeper.ClaimWithdrawal
+	if msg.ToTradingAccount {
+		claim = m.keeper.ClaimWithdrawalToTradingAccount
+	}
+	withdrawal, amountReceived, err := claim(ctx, msg.Withdrawer, msg.WithdrawalID)
 	if err != nil {
 		return nil, err
 	}
@@ -133,3 +162,64 @@ func (m *MsgServer) UpdateDDGuard(ctx context.Context, msg *types.MsgUpdateDDGua
 		CurrentDrawdown: pool.CurrentDrawdown.String(),
 	}, nil
 }
+
+// UpdateAllocationStrategy handles MsgUpdateAllocationStrategy (admin only)
+func (m *MsgServer) UpdateAllocationStrategy(ctx context.Context, msg *types.MsgUpdateAllocationStrategy) (*types.MsgUpdateAllocationStrategyResponse, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	// Verify authority
+	if msg.Authority != m.keeper.GetAuthority() {
+		return nil, types.ErrUnauthorized
+	}
+
+	strategy := msg.Strategy
+	if err := m.keeper.SetAllocationStrategy(sdkCtx, &strategy); err != nil {
+		return nil, err
+	}
+
+	// Apply the new targets immediately
+	rebalanced := m.keeper.RebalanceAllocations(sdkCtx, &strategy, true)
+	m.keeper.UpdatePoolNAV(sdkCtx, strategy.PoolID)
+
+	return &types.MsgUpdateAllocationStrategyResponse{
+		Rebalanced: rebalanced,
+	}, nil
+}
+
+// PlacePoolOrder handles MsgPlacePoolOrder (pool owner only)
+func (m *MsgServer) PlacePoolOrder(ctx context.Context, msg *types.MsgPlacePoolOrder) (*types.MsgPlacePoolOrderResponse, error) {
+	price := math.LegacyZeroDec()
+	if msg.Price != "" {
+		var err error
+		if price, err = math.LegacyNewDecFromStr(msg.Price); err != nil {
+			return nil, err
+		}
+	}
+	quantity, err := math.LegacyNewDecFromStr(msg.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	leverage, err := math.LegacyNewDecFromStr(msg.Leverage)
+	if err != nil {
+		return nil, err
+	}
+
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+	fill, err := m.keeper.PlacePoolOrder(sdkCtx, msg.Owner, msg.PoolID, msg.MarketID, msg.Side, price, quantity, leverage)
+	if err != nil {
+		return nil, err
+	}
+
+	tradeIDs := make([]string, 0, len(fill.Trades))
+	for _, t := range fill.Trades {
+		tradeIDs = append(tradeIDs, t.TradeID)
+	}
+	return &types.MsgPlacePoolOrderResponse{
+		OrderID:   fill.OrderID,
+		Status:    fill.Status,
+		FilledQty: fill.FilledQty.String(),
+		AvgPrice:  fill.AvgPrice.String(),
+		TradeIDs:  tradeIDs,
+		Trader:    types.PoolTradingAccount(msg.PoolID),
+	}, nil
+}
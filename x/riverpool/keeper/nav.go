@@ -8,25 +8,42 @@ import (
 	"github.com/openalpha/perp-dex/x/riverpool/types"
 )
 
-// CalculatePoolNAV calculates the NAV for a pool
-// NAV = (Pool Cash + Position Market Value + Unrealized PnL) / Total Shares
-func (k *Keeper) CalculatePoolNAV(ctx sdk.Context, poolID string) math.LegacyDec {
-	pool := k.GetPool(ctx, poolID)
-	if pool == nil || pool.TotalShares.IsZero() {
-		return math.LegacyOneDec()
+// ComputePoolNAV computes a pool's NAV from its cash, the look-through value
+// of its allocations and the unrealized PnL of its positions, each position
+// annotated with the price it was valued at and that price's source
+func (k *Keeper) ComputePoolNAV(ctx sdk.Context, poolID string) (*types.NAVComputation, error) {
+	lookThrough, err := k.GetLookThroughNAV(ctx, poolID)
+	if err != nil {
+		return nil, err
 	}
 
-	// For MVP, NAV is based on total deposits
-	// In Phase 2+, this would include position market value and unrealized PnL
-	totalValue := pool.TotalDeposits
+	positions, positionPnL := k.pricePoolPositions(ctx, poolID)
+	totalValue := lookThrough.TotalValue.Add(positionPnL)
+	nav := math.LegacyOneDec()
+	if lookThrough.TotalShares.IsPositive() {
+		nav = totalValue.Quo(lookThrough.TotalShares)
+	}
 
-	// TODO: Add position market value when community pools start trading
-	// positionValue := k.calculatePoolPositionValue(ctx, poolID)
-	// unrealizedPnL := k.calculatePoolUnrealizedPnL(ctx, poolID)
-	// totalValue = pool.TotalDeposits.Add(positionValue).Add(unrealizedPnL)
+	return &types.NAVComputation{
+		PoolID:         poolID,
+		Cash:           lookThrough.Cash,
+		AllocatedValue: lookThrough.AllocatedValue,
+		PositionPnL:    positionPnL,
+		TotalValue:     totalValue,
+		TotalShares:    lookThrough.TotalShares,
+		NAV:            nav,
+		Positions:      positions,
+	}, nil
+}
 
-	nav := totalValue.Quo(pool.TotalShares)
-	return nav
+// CalculatePoolNAV calculates the NAV for a pool
+// NAV = (Pool Cash + Allocated Value + Position Unrealized PnL) / Total Shares
+func (k *Keeper) CalculatePoolNAV(ctx sdk.Context, poolID string) math.LegacyDec {
+	computation, err := k.ComputePoolNAV(ctx, poolID)
+	if err != nil || !computation.TotalShares.IsPositive() {
+		return math.LegacyOneDec()
+	}
+	return computation.NAV
 }
 
 // UpdatePoolNAV updates the NAV for a pool
@@ -36,19 +53,32 @@ func (k *Keeper) UpdatePoolNAV(ctx sdk.Context, poolID string) {
 		return
 	}
 
-	// Calculate new NAV
-	totalValue := pool.TotalDeposits // MVP: just use deposits
-	pool.UpdateNAV(totalValue)
+	computation, err := k.ComputePoolNAV(ctx, poolID)
+	if err != nil {
+		return
+	}
+	pool.UpdateNAV(computation.TotalValue)
 
 	// Save updated pool
 	k.SetPool(ctx, pool)
 
+	// Remember fresh prices so a stale oracle falls back to them
+	for _, p := range computation.Positions {
+		if p.Source == types.NAVPriceSourceMark || p.Source == types.NAVPriceSourceClamped {
+			k.setNAVPrice(ctx, p.MarketID, p.Price)
+		}
+	}
+
 	// Record NAV history
 	history := &types.NAVHistory{
-		PoolID:     poolID,
-		NAV:        pool.NAV,
-		TotalValue: totalValue,
-		Timestamp:  time.Now().Unix(),
+		PoolID:         poolID,
+		NAV:            pool.NAV,
+		TotalValue:     computation.TotalValue,
+		Cash:           computation.Cash,
+		AllocatedValue: computation.AllocatedValue,
+		PositionPnL:    computation.PositionPnL,
+		Positions:      computation.Positions,
+		Timestamp:      time.Now().Unix(),
 	}
 	k.AddNAVHistory(ctx, history)
 
@@ -58,19 +88,31 @@ func (k *Keeper) UpdatePoolNAV(ctx sdk.Context, poolID string) {
 	k.logger.Debug("Pool NAV updated",
 		"pool_id", poolID,
 		"nav", pool.NAV.String(),
-		"total_value", totalValue.String(),
+		"total_value", computation.TotalValue.String(),
+		"position_pnl", computation.PositionPnL.String(),
 		"drawdown", pool.CurrentDrawdown.String(),
 		"dd_level", pool.DDGuardLevel,
 	)
 }
 
 // UpdateAllPoolNAVs updates NAV for all pools (called in EndBlocker)
+// Pools that allocate to other pools are updated last so their look-through
+// value uses the underlying NAVs of this block.
 func (k *Keeper) UpdateAllPoolNAVs(ctx sdk.Context) {
 	pools := k.GetAllPools(ctx)
+	var parents []string
 	for _, pool := range pools {
-		if pool.Status != types.PoolStatusClosed {
-			k.UpdatePoolNAV(ctx, pool.PoolID)
+		if pool.Status == types.PoolStatusClosed {
+			continue
+		}
+		if k.GetAllocationStrategy(ctx, pool.PoolID) != nil {
+			parents = append(parents, pool.PoolID)
+			continue
 		}
+		k.UpdatePoolNAV(ctx, pool.PoolID)
+	}
+	for _, poolID := range parents {
+		k.UpdatePoolNAV(ctx, poolID)
 	}
 }
 
@@ -143,10 +185,15 @@ func (k *Keeper) GetPoolValue(ctx sdk.Context, poolID string) math.LegacyDec {
 	if pool == nil {
 		return math.LegacyZeroDec()
 	}
+	return k.poolValue(ctx, pool)
+}
 
-	// MVP: total value = total deposits
-	// Phase 2+: add position value and unrealized PnL
-	return pool.TotalDeposits
+// poolValue returns a pool's total deposits plus the unrealized PnL of its
+// allocations and its positions
+func (k *Keeper) poolValue(ctx sdk.Context, pool *types.Pool) math.LegacyDec {
+	_, allocationPnL := k.allocationValues(ctx, pool.PoolID)
+	_, positionPnL := k.pricePoolPositions(ctx, pool.PoolID)
+	return pool.TotalDeposits.Add(allocationPnL).Add(positionPnL)
 }
 
 // EstimateSharesForDeposit estimates shares for a deposit amount
@@ -0,0 +1,129 @@
+package keeper
+
+import (
+	"encoding/json"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	"github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// NAV pricing store key prefixes
+var (
+	NAVPricingConfigKey = []byte{0x16}
+	NAVPriceKeyPrefix   = []byte{0x17} // marketID -> last accepted NAV price
+)
+
+// navPrice is the last price a market's positions were valued at from a
+// fresh mark price
+type navPrice struct {
+	Price     math.LegacyDec `json:"price"`
+	Timestamp int64          `json:"timestamp"`
+}
+
+// GetNAVPricingConfig returns the NAV pricing safeguards, the defaults if none are set
+func (k *Keeper) GetNAVPricingConfig(ctx sdk.Context) *types.NAVPricingConfig {
+	bz := k.GetStore(ctx).Get(NAVPricingConfigKey)
+	if bz == nil {
+		return types.DefaultNAVPricingConfig()
+	}
+	var config types.NAVPricingConfig
+	if err := json.Unmarshal(bz, &config); err != nil {
+		return types.DefaultNAVPricingConfig()
+	}
+	return &config
+}
+
+// SetNAVPricingConfig validates and saves the NAV pricing safeguards
+func (k *Keeper) SetNAVPricingConfig(ctx sdk.Context, config *types.NAVPricingConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	bz, _ := json.Marshal(config)
+	k.GetStore(ctx).Set(NAVPricingConfigKey, bz)
+	return nil
+}
+
+func (k *Keeper) getNAVPrice(ctx sdk.Context, marketID string) *navPrice {
+	bz := k.GetStore(ctx).Get(append(append([]byte{}, NAVPriceKeyPrefix...), marketID...))
+	if bz == nil {
+		return nil
+	}
+	var price navPrice
+	if err := json.Unmarshal(bz, &price); err != nil {
+		return nil
+	}
+	return &price
+}
+
+func (k *Keeper) setNAVPrice(ctx sdk.Context, marketID string, price math.LegacyDec) {
+	bz, _ := json.Marshal(&navPrice{Price: price, Timestamp: ctx.BlockTime().Unix()})
+	k.GetStore(ctx).Set(append(append([]byte{}, NAVPriceKeyPrefix...), marketID...), bz)
+}
+
+// pricePoolPositions values the positions of a pool's trading account for
+// NAV and returns them with their total unrealized PnL
+func (k *Keeper) pricePoolPositions(ctx sdk.Context, poolID string) ([]*types.NAVPositionPrice, math.LegacyDec) {
+	config := k.GetNAVPricingConfig(ctx)
+	positions := make([]*types.NAVPositionPrice, 0)
+	total := math.LegacyZeroDec()
+	for _, pos := range k.perpetualKeeper.GetPositionsByTrader(ctx, types.PoolTradingAccount(poolID)) {
+		p := &types.NAVPositionPrice{
+			MarketID:   pos.MarketID,
+			Side:       pos.Side.String(),
+			Size:       pos.Size,
+			EntryPrice: pos.EntryPrice,
+			MarkPrice:  math.LegacyZeroDec(),
+			LastPrice:  math.LegacyZeroDec(),
+		}
+		k.navPositionPrice(ctx, config, p)
+
+		pnl := p.Price.Sub(pos.EntryPrice).Mul(pos.Size)
+		if pos.Side == perpetualtypes.PositionSideShort {
+			pnl = pnl.Neg()
+		}
+		p.UnrealizedPnL = pnl
+		total = total.Add(pnl)
+		positions = append(positions, p)
+	}
+	return positions, total
+}
+
+// navPositionPrice sets the price a position is valued at. A mark price set
+// within MaxPriceAge of the block time is used, clamped to within
+// MaxLastDeviation of the last trade price when there is one. Otherwise the
+// market's last accepted price is used, or the entry price if there is none.
+func (k *Keeper) navPositionPrice(ctx sdk.Context, config *types.NAVPricingConfig, p *types.NAVPositionPrice) {
+	fresh := false
+	if priceInfo := k.perpetualKeeper.GetPrice(ctx, p.MarketID); priceInfo != nil {
+		if !priceInfo.MarkPrice.IsNil() {
+			p.MarkPrice = priceInfo.MarkPrice
+		}
+		if !priceInfo.LastPrice.IsNil() {
+			p.LastPrice = priceInfo.LastPrice
+		}
+		if !priceInfo.Timestamp.IsZero() {
+			p.PriceAge = int64(ctx.BlockTime().Sub(priceInfo.Timestamp).Seconds())
+			fresh = p.MarkPrice.IsPositive() && p.PriceAge <= config.MaxPriceAge
+		}
+	}
+
+	if fresh {
+		p.Price, p.Source = p.MarkPrice, types.NAVPriceSourceMark
+		if p.LastPrice.IsPositive() {
+			band := p.LastPrice.Mul(config.MaxLastDeviation)
+			if low := p.LastPrice.Sub(band); p.Price.LT(low) {
+				p.Price, p.Source = low, types.NAVPriceSourceClamped
+			} else if high := p.LastPrice.Add(band); p.Price.GT(high) {
+				p.Price, p.Source = high, types.NAVPriceSourceClamped
+			}
+		}
+		return
+	}
+	if previous := k.getNAVPrice(ctx, p.MarketID); previous != nil {
+		p.Price, p.Source = previous.Price, types.NAVPriceSourcePrevious
+		return
+	}
+	p.Price, p.Source = p.EntryPrice, types.NAVPriceSourceEntry
+}
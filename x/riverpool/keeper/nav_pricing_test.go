@@ -0,0 +1,97 @@
+package keeper
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	"github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// TestComputePoolNAVPricing tests that pool positions are valued at fresh
+// mark prices clamped to the last trade band, and fall back to the last
+// accepted price and then the entry price when the mark price is stale
+func TestComputePoolNAVPricing(t *testing.T) {
+	k, ctx, perp, _ := setupPoolTradingKeeper(t)
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	ctx = ctx.WithBlockTime(now)
+
+	trader := types.PoolTradingAccount("cpool-1")
+	perp.positions[trader] = []*perpetualtypes.Position{
+		perpetualtypes.NewPosition(trader, "BTC-USDC", perpetualtypes.PositionSideLong, dec("1"), dec("50000"), dec("10000")),
+		perpetualtypes.NewPosition(trader, "ETH-USDC", perpetualtypes.PositionSideShort, dec("10"), dec("3000"), dec("6000")),
+		perpetualtypes.NewPosition(trader, "SOL-USDC", perpetualtypes.PositionSideLong, dec("100"), dec("100"), dec("2000")),
+	}
+	perp.prices = map[string]*perpetualtypes.PriceInfo{
+		// Within 5% of the last trade: used as is
+		"BTC-USDC": {MarketID: "BTC-USDC", MarkPrice: dec("51000"), LastPrice: dec("50500"), Timestamp: now.Add(-10 * time.Second)},
+		// 10% above the last trade: clamped to 3150
+		"ETH-USDC": {MarketID: "ETH-USDC", MarkPrice: dec("3300"), LastPrice: dec("3000"), Timestamp: now},
+		// Stale with no accepted price: valued at entry
+		"SOL-USDC": {MarketID: "SOL-USDC", MarkPrice: dec("200"), LastPrice: dec("200"), Timestamp: now.Add(-time.Hour)},
+	}
+
+	computation, err := k.ComputePoolNAV(ctx, "cpool-1")
+	if err != nil {
+		t.Fatalf("failed to compute NAV: %v", err)
+	}
+	sources := map[string]string{}
+	for _, p := range computation.Positions {
+		sources[p.MarketID] = p.Source
+	}
+	if sources["BTC-USDC"] != types.NAVPriceSourceMark || sources["ETH-USDC"] != types.NAVPriceSourceClamped ||
+		sources["SOL-USDC"] != types.NAVPriceSourceEntry {
+		t.Errorf("unexpected price sources %v", sources)
+	}
+	// BTC +1000, ETH short -(3150-3000)*10 = -1500, SOL 0
+	if !computation.PositionPnL.Equal(dec("-500")) || !computation.TotalValue.Equal(dec("9500")) || !computation.NAV.Equal(dec("0.95")) {
+		t.Errorf("unexpected NAV computation %+v", computation)
+	}
+
+	k.UpdatePoolNAV(ctx, "cpool-1")
+	history := k.GetNAVHistory(ctx, "cpool-1", 0, math.MaxInt64)
+	if len(history) != 1 || !history[0].PositionPnL.Equal(dec("-500")) || len(history[0].Positions) != 3 {
+		t.Fatalf("unexpected NAV history %+v", history)
+	}
+
+	// Once the BTC price goes stale it is valued at the last accepted price
+	perp.prices["BTC-USDC"].Timestamp = now.Add(-time.Hour)
+	perp.prices["BTC-USDC"].MarkPrice = dec("10000")
+	computation, err = k.ComputePoolNAV(ctx, "cpool-1")
+	if err != nil {
+		t.Fatalf("failed to compute NAV: %v", err)
+	}
+	for _, p := range computation.Positions {
+		if p.MarketID == "BTC-USDC" && (p.Source != types.NAVPriceSourcePrevious || !p.Price.Equal(dec("51000"))) {
+			t.Errorf("expected the previous price 51000, got %s from %s", p.Price, p.Source)
+		}
+	}
+	if !k.GetPoolValue(ctx, "cpool-1").Equal(dec("9500")) {
+		t.Errorf("expected pool value 9500, got %s", k.GetPoolValue(ctx, "cpool-1"))
+	}
+
+	if _, err := k.ComputePoolNAV(ctx, "missing"); !errors.Is(err, types.ErrPoolNotFound) {
+		t.Errorf("expected ErrPoolNotFound, got %v", err)
+	}
+}
+
+// TestNAVPricingConfig tests the pricing safeguard defaults and validation
+func TestNAVPricingConfig(t *testing.T) {
+	k, ctx, _ := setupKeeper(t)
+
+	if config := k.GetNAVPricingConfig(ctx); config.MaxPriceAge != 300 || !config.MaxLastDeviation.Equal(dec("0.05")) {
+		t.Errorf("unexpected default config %+v", config)
+	}
+	invalid := &types.NAVPricingConfig{MaxPriceAge: 60, MaxLastDeviation: dec("1")}
+	if err := k.SetNAVPricingConfig(ctx, invalid); !errors.Is(err, types.ErrInvalidNAVPricingConfig) {
+		t.Fatalf("expected ErrInvalidNAVPricingConfig, got %v", err)
+	}
+	if err := k.SetNAVPricingConfig(ctx, &types.NAVPricingConfig{MaxPriceAge: 60, MaxLastDeviation: dec("0.02")}); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+	if config := k.GetNAVPricingConfig(ctx); config.MaxPriceAge != 60 {
+		t.Errorf("expected max price age 60, got %d", config.MaxPriceAge)
+	}
+}
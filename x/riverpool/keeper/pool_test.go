@@ -1,8 +1,11 @@
 package keeper
 
 import (
+	"errors"
 	"testing"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
 	"cosmossdk.io/math"
 	"github.com/openalpha/perp-dex/x/riverpool/types"
 )
@@ -414,3 +417,26 @@ func TestNewWithdrawal(t *testing.T) {
 		t.Error("expected withdrawal not to be ready immediately")
 	}
 }
+
+// fakeLPObligations marks listed traders as having missed their obligations
+type fakeLPObligations map[string]bool
+
+func (f fakeLPObligations) LPObligationEligible(ctx sdk.Context, trader string) bool {
+	return !f[trader]
+}
+
+// TestFoundationDepositLPObligation tests that a designated maker who missed
+// its quoting obligations cannot take a Foundation LP seat
+func TestFoundationDepositLPObligation(t *testing.T) {
+	k, ctx, _ := setupKeeper(t)
+	pool := types.NewFoundationPool()
+	k.SetPool(ctx, pool)
+	k.SetLPObligationKeeper(fakeLPObligations{"maker": true})
+
+	if _, err := k.Deposit(ctx, "maker", pool.PoolID, types.FoundationSeatSize, ""); !errors.Is(err, types.ErrFoundationSeatIneligible) {
+		t.Fatalf("expected ErrFoundationSeatIneligible, got %v", err)
+	}
+	if _, err := k.Deposit(ctx, "alice", pool.PoolID, types.FoundationSeatSize, ""); err != nil {
+		t.Fatalf("expected other depositors to take a seat, got %v", err)
+	}
+}
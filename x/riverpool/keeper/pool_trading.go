@@ -0,0 +1,278 @@
+package keeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	"github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// Pool trading store key prefixes
+var (
+	PoolTradeKeyPrefix          = []byte{0x12}
+	PoolTradingCapitalKeyPrefix = []byte{0x13}
+	PoolTradingAccountKeyPrefix = []byte{0x18} // trading account -> poolID
+)
+
+// PlacePoolOrder places an order for a community pool on behalf of its owner.
+// The order must be on one of the pool's allowed markets and within its
+// leverage cap; orders that grow the position must also keep the pool's gross
+// notional within pool value × MaxLeverage, scaled down by the DDGuard
+// exposure limit. It is routed through the orderbook with the pool's trading
+// account as trader, and margin missing from that account is committed from
+// the pool's idle capital. A zero price places a market order.
+func (k *Keeper) PlacePoolOrder(
+	ctx sdk.Context,
+	owner, poolID, marketID, side string,
+	price, quantity, leverage math.LegacyDec,
+) (*types.OrderFill, error) {
+	if k.orderbookKeeper == nil {
+		return nil, fmt.Errorf("orderbook keeper not set")
+	}
+
+	pool := k.GetPool(ctx, poolID)
+	if pool == nil {
+		return nil, types.ErrPoolNotFound
+	}
+	if pool.PoolType != types.PoolTypeCommunity {
+		return nil, types.ErrNotCommunityPool
+	}
+	if pool.Owner != owner {
+		return nil, types.ErrNotPoolOwner
+	}
+	if pool.Status != types.PoolStatusActive {
+		return nil, types.ErrPoolNotActive
+	}
+	if pool.DDGuardLevel == types.DDGuardLevelHalt {
+		return nil, types.ErrDDGuardHalt
+	}
+	if err := pool.ValidateOrder(marketID, side, quantity, leverage); err != nil {
+		return nil, err
+	}
+	if price.IsNil() {
+		price = math.LegacyZeroDec()
+	}
+	if price.IsNegative() {
+		return nil, fmt.Errorf("%w: price must not be negative", types.ErrInvalidPoolOrder)
+	}
+
+	priceInfo := k.perpetualKeeper.GetPrice(ctx, marketID)
+	if priceInfo == nil || !priceInfo.MarkPrice.IsPositive() {
+		return nil, fmt.Errorf("%w: no mark price for %s", types.ErrInvalidPoolOrder, marketID)
+	}
+	refPrice := price
+	if refPrice.IsZero() {
+		refPrice = priceInfo.MarkPrice
+	}
+
+	trader := types.PoolTradingAccount(poolID)
+	isBuy := side == types.OrderSideBuy
+
+	// Exposure check: reducing orders are always allowed so the owner can
+	// de-risk, including under DDGuard
+	gross, size := k.poolGrossNotional(ctx, trader, marketID)
+	delta := quantity
+	if !isBuy {
+		delta = delta.Neg()
+	}
+	newSize := size.Add(delta)
+	increasing := newSize.Abs().GT(size.Abs())
+	if increasing {
+		grossAfter := gross.Add(newSize.Abs().Sub(size.Abs()).Mul(priceInfo.MarkPrice))
+		if limit := k.poolExposureLimit(ctx, pool); grossAfter.GT(limit) {
+			return nil, fmt.Errorf("%w: gross notional %s exceeds %s", types.ErrPoolLeverageExceeded, grossAfter, limit)
+		}
+	}
+
+	cacheCtx, write := ctx.CacheContext()
+
+	if increasing {
+		if err := k.commitPoolMargin(cacheCtx, pool, trader, quantity.Mul(refPrice).Quo(leverage)); err != nil {
+			return nil, err
+		}
+	}
+
+	fill, err := k.orderbookKeeper.PlaceOrder(cacheCtx, trader, marketID, isBuy, price, quantity)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range fill.Trades {
+		trade := types.NewPoolTrade(poolID, marketID, side, t.Quantity, t.Price, t.Fee)
+		trade.TradeID = t.TradeID
+		trade.ExecutedAt = ctx.BlockTime().Unix()
+		k.SetPoolTrade(cacheCtx, trade)
+	}
+	// Remember the account so later fills of a resting order are attributed
+	// by the trade hook
+	k.setPoolTradingAccount(cacheCtx, trader, poolID)
+	write()
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"riverpool_pool_order_placed",
+			sdk.NewAttribute("pool_id", poolID),
+			sdk.NewAttribute("owner", owner),
+			sdk.NewAttribute("trader", trader),
+			sdk.NewAttribute("order_id", fill.OrderID),
+			sdk.NewAttribute("market_id", marketID),
+			sdk.NewAttribute("side", side),
+			sdk.NewAttribute("quantity", quantity.String()),
+			sdk.NewAttribute("leverage", leverage.String()),
+			sdk.NewAttribute("filled_qty", fill.FilledQty.String()),
+		),
+	)
+
+	return fill, nil
+}
+
+// poolGrossNotional returns the gross notional at mark price of the positions
+// held by a pool trading account, and its signed size in marketID (long > 0)
+func (k *Keeper) poolGrossNotional(ctx sdk.Context, trader, marketID string) (math.LegacyDec, math.LegacyDec) {
+	gross := math.LegacyZeroDec()
+	size := math.LegacyZeroDec()
+	for _, pos := range k.perpetualKeeper.GetPositionsByTrader(ctx, trader) {
+		mark := pos.EntryPrice
+		if priceInfo := k.perpetualKeeper.GetPrice(ctx, pos.MarketID); priceInfo != nil && priceInfo.MarkPrice.IsPositive() {
+			mark = priceInfo.MarkPrice
+		}
+		gross = gross.Add(pos.Size.Mul(mark))
+		if pos.MarketID == marketID {
+			size = pos.Size
+			if pos.Side == perpetualtypes.PositionSideShort {
+				size = size.Neg()
+			}
+		}
+	}
+	return gross, size
+}
+
+// poolExposureLimit returns the maximum gross notional a pool may hold:
+// pool value × MaxLeverage × the DDGuard exposure limit
+func (k *Keeper) poolExposureLimit(ctx sdk.Context, pool *types.Pool) math.LegacyDec {
+	limit := k.GetPoolValue(ctx, pool.PoolID).Mul(pool.MaxLeverageOrDefault())
+	if state := k.GetDDGuardState(ctx, pool.PoolID); state != nil && !state.MaxExposureLimit.IsNil() {
+		limit = limit.Mul(state.MaxExposureLimit)
+	}
+	return limit
+}
+
+// commitPoolMargin tops up the pool trading account so its free collateral
+// covers margin, moving the shortfall out of the pool's idle capital. The
+// account is credited without the testing balance Deposit gives new
+// accounts, so everything it trades on is committed pool capital.
+func (k *Keeper) commitPoolMargin(ctx sdk.Context, pool *types.Pool, trader string, margin math.LegacyDec) error {
+	shortfall := margin.Sub(k.perpetualKeeper.GetFreeCollateral(ctx, trader))
+	if !shortfall.IsPositive() {
+		return nil
+	}
+
+	committed := k.GetPoolTradingCapital(ctx, pool.PoolID)
+	if idle := pool.TotalDeposits.Sub(committed); idle.LT(shortfall) {
+		return fmt.Errorf("%w: need %s, idle %s", types.ErrInsufficientPoolCapital, shortfall, idle)
+	}
+	if err := k.perpetualKeeper.CreditAccount(ctx, trader, shortfall); err != nil {
+		return err
+	}
+	k.SetPoolTradingCapital(ctx, pool.PoolID, committed.Add(shortfall))
+	return nil
+}
+
+// GetPoolPositions returns the open positions of a pool's trading account
+func (k *Keeper) GetPoolPositions(ctx sdk.Context, poolID string) []*types.PoolPosition {
+	var positions []*types.PoolPosition
+	for _, pos := range k.perpetualKeeper.GetPositionsByTrader(ctx, types.PoolTradingAccount(poolID)) {
+		position := types.NewPoolPosition(poolID, pos.MarketID, pos.Side.String(), pos.Size, pos.EntryPrice, pos.Leverage, pos.Margin)
+		position.PositionID = poolID + ":" + pos.MarketID
+		position.LiqPrice = pos.LiquidationPrice
+		if !pos.RealizedPnL.IsNil() {
+			position.RealizedPnL = pos.RealizedPnL
+		}
+		position.OpenedAt = pos.OpenedAt.Unix()
+		position.UpdatedAt = pos.UpdatedAt.Unix()
+		if priceInfo := k.perpetualKeeper.GetPrice(ctx, pos.MarketID); priceInfo != nil && priceInfo.MarkPrice.IsPositive() {
+			position.CurrentPrice = priceInfo.MarkPrice
+			pnl := priceInfo.MarkPrice.Sub(pos.EntryPrice).Mul(pos.Size)
+			if pos.Side == perpetualtypes.PositionSideShort {
+				pnl = pnl.Neg()
+			}
+			position.UnrealizedPnL = pnl
+		}
+		positions = append(positions, position)
+	}
+	return positions
+}
+
+// ============ Trade Attribution ============
+
+// poolTradeKey orders a pool's trades by execution time
+func poolTradeKey(poolID string, executedAt int64, tradeID string) []byte {
+	return append(PoolTradeKeyPrefix, []byte(poolID+":"+strconv.FormatInt(executedAt, 10)+":"+tradeID)...)
+}
+
+// SetPoolTrade saves a trade executed for a pool
+func (k *Keeper) SetPoolTrade(ctx sdk.Context, trade *types.PoolTrade) {
+	store := k.GetStore(ctx)
+	bz, _ := json.Marshal(trade)
+	store.Set(poolTradeKey(trade.PoolID, trade.ExecutedAt, trade.TradeID), bz)
+}
+
+// GetPoolTrades returns the trades executed for a pool, newest first
+func (k *Keeper) GetPoolTrades(ctx sdk.Context, poolID string, limit int) []*types.PoolTrade {
+	store := k.GetStore(ctx)
+	prefix := append(PoolTradeKeyPrefix, []byte(poolID+":")...)
+	iterator := storetypes.KVStoreReversePrefixIterator(store, prefix)
+	defer iterator.Close()
+
+	var trades []*types.PoolTrade
+	for ; iterator.Valid(); iterator.Next() {
+		var trade types.PoolTrade
+		if err := json.Unmarshal(iterator.Value(), &trade); err != nil {
+			continue
+		}
+		trades = append(trades, &trade)
+		if limit > 0 && len(trades) >= limit {
+			break
+		}
+	}
+	return trades
+}
+
+// setPoolTradingAccount indexes a pool's trading account by address
+func (k *Keeper) setPoolTradingAccount(ctx sdk.Context, trader, poolID string) {
+	k.GetStore(ctx).Set(append(PoolTradingAccountKeyPrefix, []byte(trader)...), []byte(poolID))
+}
+
+// getPoolByTradingAccount returns the ID of the pool trading from an address,
+// or "" if the address has never traded for a pool
+func (k *Keeper) getPoolByTradingAccount(ctx sdk.Context, trader string) string {
+	return string(k.GetStore(ctx).Get(append(PoolTradingAccountKeyPrefix, []byte(trader)...)))
+}
+
+// ============ Trading Capital ============
+
+// SetPoolTradingCapital records how much pool capital has been committed to
+// the pool's trading account as margin
+func (k *Keeper) SetPoolTradingCapital(ctx sdk.Context, poolID string, amount math.LegacyDec) {
+	store := k.GetStore(ctx)
+	bz, _ := json.Marshal(amount)
+	store.Set(append(PoolTradingCapitalKeyPrefix, []byte(poolID)...), bz)
+}
+
+// GetPoolTradingCapital returns the pool capital committed as margin
+func (k *Keeper) GetPoolTradingCapital(ctx sdk.Context, poolID string) math.LegacyDec {
+	store := k.GetStore(ctx)
+	bz := store.Get(append(PoolTradingCapitalKeyPrefix, []byte(poolID)...))
+	if bz == nil {
+		return math.LegacyZeroDec()
+	}
+	var amount math.LegacyDec
+	if err := json.Unmarshal(bz, &amount); err != nil {
+		return math.LegacyZeroDec()
+	}
+	return amount
+}
@@ -0,0 +1,281 @@
+package keeper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	"cosmossdk.io/store"
+	"cosmossdk.io/store/metrics"
+	storetypes "cosmossdk.io/store/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	orderbooktypes "github.com/openalpha/perp-dex/x/orderbook/types"
+	perpkeeper "github.com/openalpha/perp-dex/x/perpetual/keeper"
+	perpetualtypes "github.com/openalpha/perp-dex/x/perpetual/types"
+	"github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// fakePerpetual is a ledger-only perpetual keeper with one BTC price, unless
+// prices sets a market's price
+type fakePerpetual struct {
+	balances  map[string]math.LegacyDec
+	positions map[string][]*perpetualtypes.Position
+	prices    map[string]*perpetualtypes.PriceInfo
+}
+
+func (f *fakePerpetual) GetPrice(ctx sdk.Context, marketID string) *perpetualtypes.PriceInfo {
+	if price, ok := f.prices[marketID]; ok {
+		return price
+	}
+	return &perpetualtypes.PriceInfo{MarketID: marketID, MarkPrice: dec("50000")}
+}
+
+func (f *fakePerpetual) GetFreeCollateral(ctx sdk.Context, trader string) math.LegacyDec {
+	if b, ok := f.balances[trader]; ok {
+		return b
+	}
+	return math.LegacyZeroDec()
+}
+
+func (f *fakePerpetual) Deposit(ctx context.Context, trader string, amount math.LegacyDec) error {
+	f.balances[trader] = f.GetFreeCollateral(sdk.Context{}, trader).Add(amount)
+	return nil
+}
+
+func (f *fakePerpetual) CreditAccount(ctx context.Context, trader string, amount math.LegacyDec) error {
+	return f.Deposit(ctx, trader, amount)
+}
+
+func (f *fakePerpetual) Withdraw(ctx context.Context, trader string, amount math.LegacyDec) error {
+	f.balances[trader] = f.GetFreeCollateral(sdk.Context{}, trader).Sub(amount)
+	return nil
+}
+
+func (f *fakePerpetual) GetPositionsByTrader(ctx sdk.Context, trader string) []*perpetualtypes.Position {
+	return f.positions[trader]
+}
+
+// fakeOrderbook fills every order in full at the mark price
+type fakeOrderbook struct {
+	perp   *fakePerpetual
+	orders int
+}
+
+func (f *fakeOrderbook) PlaceOrder(ctx sdk.Context, trader, marketID string, isBuy bool, price, quantity math.LegacyDec) (*types.OrderFill, error) {
+	f.orders++
+	side := perpetualtypes.PositionSideShort
+	if isBuy {
+		side = perpetualtypes.PositionSideLong
+	}
+	f.perp.positions[trader] = append(f.perp.positions[trader], perpetualtypes.NewPosition(trader, marketID, side, quantity, dec("50000"), math.LegacyZeroDec()))
+	return &types.OrderFill{
+		OrderID:   "order-1",
+		Status:    "filled",
+		FilledQty: quantity,
+		AvgPrice:  dec("50000"),
+		Trades:    []types.TradeFill{{TradeID: "trade-1", Price: dec("50000"), Quantity: quantity, Fee: dec("1")}},
+	}, nil
+}
+
+// setupKeeper returns a store-backed keeper over fake perpetual and orderbook keepers
+func setupKeeper(t *testing.T) (*Keeper, sdk.Context, *fakePerpetual) {
+	t.Helper()
+
+	storeKey := storetypes.NewKVStoreKey(types.StoreKey)
+	db := dbm.NewMemDB()
+	stateStore := store.NewCommitMultiStore(db, log.NewNopLogger(), metrics.NewNoOpMetrics())
+	stateStore.MountStoreWithDB(storeKey, storetypes.StoreTypeIAVL, db)
+	if err := stateStore.LoadLatestVersion(); err != nil {
+		t.Fatalf("failed to load store: %v", err)
+	}
+	ctx := sdk.NewContext(stateStore, cmtproto.Header{}, false, log.NewNopLogger())
+
+	perp := &fakePerpetual{balances: map[string]math.LegacyDec{}, positions: map[string][]*perpetualtypes.Position{}}
+	k := NewKeeper(nil, storeKey, perp, &fakeOrderbook{perp: perp}, nil, "", log.NewNopLogger())
+	return k, ctx, perp
+}
+
+func setupPoolTradingKeeper(t *testing.T) (*Keeper, sdk.Context, *fakePerpetual, *types.Pool) {
+	t.Helper()
+
+	k, ctx, perp := setupKeeper(t)
+	pool := &types.Pool{
+		PoolID:         "cpool-1",
+		PoolType:       types.PoolTypeCommunity,
+		Status:         types.PoolStatusActive,
+		Owner:          "owner",
+		TotalDeposits:  dec("10000"),
+		TotalShares:    dec("10000"),
+		DDGuardLevel:   types.DDGuardLevelNormal,
+		AllowedMarkets: []string{"BTC-USDC"},
+		MaxLeverage:    dec("5"),
+	}
+	k.SetPool(ctx, pool)
+	return k, ctx, perp, pool
+}
+
+// TestPoolOrderValidation tests the market whitelist and leverage cap
+func TestPoolOrderValidation(t *testing.T) {
+	pool := &types.Pool{AllowedMarkets: []string{"BTC-USDC"}, MaxLeverage: dec("5")}
+
+	if err := pool.ValidateOrder("BTC-USDC", types.OrderSideBuy, dec("1"), dec("5")); err != nil {
+		t.Errorf("expected valid order, got %v", err)
+	}
+	if err := pool.ValidateOrder("ETH-USDC", types.OrderSideBuy, dec("1"), dec("2")); !errors.Is(err, types.ErrMarketNotAllowed) {
+		t.Errorf("expected market not allowed, got %v", err)
+	}
+	if err := pool.ValidateOrder("BTC-USDC", types.OrderSideSell, dec("1"), dec("6")); !errors.Is(err, types.ErrPoolLeverageExceeded) {
+		t.Errorf("expected leverage exceeded, got %v", err)
+	}
+	if err := pool.ValidateOrder("BTC-USDC", "hold", dec("1"), dec("1")); !errors.Is(err, types.ErrInvalidPoolOrder) {
+		t.Errorf("expected invalid side, got %v", err)
+	}
+
+	// No whitelist or cap configured: any market, up to the default leverage
+	open := &types.Pool{}
+	if err := open.ValidateOrder("ETH-USDC", types.OrderSideBuy, dec("1"), types.DefaultPoolMaxLeverage); err != nil {
+		t.Errorf("expected valid order, got %v", err)
+	}
+	if err := open.ValidateOrder("ETH-USDC", types.OrderSideBuy, dec("1"), dec("11")); !errors.Is(err, types.ErrPoolLeverageExceeded) {
+		t.Errorf("expected leverage exceeded, got %v", err)
+	}
+}
+
+// TestPlacePoolOrder tests routing through the orderbook as the pool trading
+// account, margin commitment and trade attribution
+func TestPlacePoolOrder(t *testing.T) {
+	k, ctx, perp, pool := setupPoolTradingKeeper(t)
+	trader := types.PoolTradingAccount(pool.PoolID)
+
+	if _, err := k.PlacePoolOrder(ctx, "intruder", pool.PoolID, "BTC-USDC", types.OrderSideBuy, math.LegacyZeroDec(), dec("0.1"), dec("2")); !errors.Is(err, types.ErrNotPoolOwner) {
+		t.Errorf("expected not pool owner, got %v", err)
+	}
+	if _, err := k.PlacePoolOrder(ctx, "owner", pool.PoolID, "ETH-USDC", types.OrderSideBuy, math.LegacyZeroDec(), dec("0.1"), dec("2")); !errors.Is(err, types.ErrMarketNotAllowed) {
+		t.Errorf("expected market not allowed, got %v", err)
+	}
+	// 1.1 BTC = 55000 notional > 10000 × 5
+	if _, err := k.PlacePoolOrder(ctx, "owner", pool.PoolID, "BTC-USDC", types.OrderSideBuy, math.LegacyZeroDec(), dec("1.1"), dec("5")); !errors.Is(err, types.ErrPoolLeverageExceeded) {
+		t.Errorf("expected gross exposure exceeded, got %v", err)
+	}
+
+	fill, err := k.PlacePoolOrder(ctx, "owner", pool.PoolID, "BTC-USDC", types.OrderSideBuy, math.LegacyZeroDec(), dec("0.4"), dec("4"))
+	if err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+	if !fill.FilledQty.Equal(dec("0.4")) {
+		t.Errorf("expected filled 0.4, got %s", fill.FilledQty)
+	}
+	if committed := k.GetPoolTradingCapital(ctx, pool.PoolID); !committed.Equal(dec("5000")) {
+		t.Errorf("expected 5000 committed margin, got %s", committed)
+	}
+	if balance := perp.GetFreeCollateral(ctx, trader); !balance.Equal(dec("5000")) {
+		t.Errorf("expected trading account funded with 5000, got %s", balance)
+	}
+
+	trades := k.GetPoolTrades(ctx, pool.PoolID, 0)
+	if len(trades) != 1 || trades[0].TradeID != "trade-1" || trades[0].Side != types.OrderSideBuy {
+		t.Errorf("expected attributed trade, got %+v", trades)
+	}
+	positions := k.GetPoolPositions(ctx, pool.PoolID)
+	if len(positions) != 1 || positions[0].MarketID != "BTC-USDC" || positions[0].Side != "long" {
+		t.Errorf("expected pool long position, got %+v", positions)
+	}
+
+	// Under DDGuard reduce the exposure limit halves to 25000: adding is
+	// rejected but reducing is still allowed
+	k.SetDDGuardState(ctx, &types.DDGuardState{PoolID: pool.PoolID, Level: types.DDGuardLevelReduce, MaxExposureLimit: dec("0.5")})
+	if _, err := k.PlacePoolOrder(ctx, "owner", pool.PoolID, "BTC-USDC", types.OrderSideBuy, math.LegacyZeroDec(), dec("0.2"), dec("2")); !errors.Is(err, types.ErrPoolLeverageExceeded) {
+		t.Errorf("expected exposure limit under DDGuard, got %v", err)
+	}
+	if _, err := k.PlacePoolOrder(ctx, "owner", pool.PoolID, "BTC-USDC", types.OrderSideSell, math.LegacyZeroDec(), dec("0.2"), dec("2")); err != nil {
+		t.Errorf("expected reducing order to pass, got %v", err)
+	}
+}
+
+// TestCommitPoolMarginCapital tests against the perpetual keeper that a pool
+// trading account holds exactly the capital committed to it, so margin
+// beyond the pool's idle capital is rejected rather than drawn from a
+// testing balance
+func TestCommitPoolMarginCapital(t *testing.T) {
+	perpKey := storetypes.NewKVStoreKey("perpetual")
+	poolKey := storetypes.NewKVStoreKey(types.StoreKey)
+	db := dbm.NewMemDB()
+	stateStore := store.NewCommitMultiStore(db, log.NewNopLogger(), metrics.NewNoOpMetrics())
+	stateStore.MountStoreWithDB(perpKey, storetypes.StoreTypeIAVL, db)
+	stateStore.MountStoreWithDB(poolKey, storetypes.StoreTypeIAVL, db)
+	if err := stateStore.LoadLatestVersion(); err != nil {
+		t.Fatalf("failed to load store: %v", err)
+	}
+	ctx := sdk.NewContext(stateStore, cmtproto.Header{}, false, log.NewNopLogger())
+
+	cdc := codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+	pk := perpkeeper.NewKeeper(cdc, perpKey, nil, "", log.NewNopLogger())
+	k := NewKeeper(nil, poolKey, pk, nil, nil, "", log.NewNopLogger())
+	pool := &types.Pool{PoolID: "cpool-1", PoolType: types.PoolTypeCommunity, TotalDeposits: dec("10000")}
+	k.SetPool(ctx, pool)
+	trader := types.PoolTradingAccount(pool.PoolID)
+
+	if err := k.commitPoolMargin(ctx, pool, trader, dec("6000")); err != nil {
+		t.Fatalf("failed to commit margin: %v", err)
+	}
+	committed := k.GetPoolTradingCapital(ctx, pool.PoolID)
+	if account := pk.GetAccount(ctx, trader); account == nil || !account.Balance.Equal(committed) || !committed.Equal(dec("6000")) {
+		t.Fatalf("expected the trading account to hold the 6000 committed, got %+v (committed %s)", account, committed)
+	}
+
+	// 5000 more than the account holds exceeds the 4000 left idle
+	if err := k.commitPoolMargin(ctx, pool, trader, dec("11000")); !errors.Is(err, types.ErrInsufficientPoolCapital) {
+		t.Errorf("expected ErrInsufficientPoolCapital, got %v", err)
+	}
+	if balance := pk.GetAccount(ctx, trader).Balance; !balance.Equal(dec("6000")) {
+		t.Errorf("expected the trading account left at 6000, got %s", balance)
+	}
+}
+
+// TestPoolTradeHooks tests that the trade hook attributes fills of a pool's
+// resting orders, and ignores trades of other makers
+func TestPoolTradeHooks(t *testing.T) {
+	k, ctx, _, pool := setupPoolTradingKeeper(t)
+	trader := types.PoolTradingAccount(pool.PoolID)
+	hooks := k.Hooks()
+
+	resting := &orderbooktypes.Trade{TradeID: "trade-2", MarketID: "BTC-USDC", Taker: "alice", Maker: trader,
+		TakerSide: orderbooktypes.SideBuy, Price: dec("50100"), Quantity: dec("0.1"), MakerFee: dec("0.5")}
+	if err := hooks.AfterTradeExecuted(ctx, resting); err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+	if trades := k.GetPoolTrades(ctx, pool.PoolID, 0); len(trades) != 0 {
+		t.Fatalf("expected no attribution before the pool has traded, got %+v", trades)
+	}
+
+	if _, err := k.PlacePoolOrder(ctx, "owner", pool.PoolID, "BTC-USDC", types.OrderSideBuy, math.LegacyZeroDec(), dec("0.2"), dec("2")); err != nil {
+		t.Fatalf("failed to place order: %v", err)
+	}
+	if err := hooks.AfterTradeExecuted(ctx, resting); err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+	other := &orderbooktypes.Trade{TradeID: "trade-3", MarketID: "BTC-USDC", Taker: trader, Maker: "bob",
+		TakerSide: orderbooktypes.SideSell, Price: dec("50000"), Quantity: dec("0.1"), MakerFee: dec("0.5")}
+	if err := hooks.AfterTradeExecuted(ctx, other); err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+
+	var attributed *types.PoolTrade
+	for _, trade := range k.GetPoolTrades(ctx, pool.PoolID, 0) {
+		if trade.TradeID == "trade-3" {
+			t.Error("expected the pool's taker fill to be left to PlacePoolOrder")
+		}
+		if trade.TradeID == "trade-2" {
+			attributed = trade
+		}
+	}
+	if attributed == nil || attributed.Side != types.OrderSideSell || !attributed.Size.Equal(dec("0.1")) || !attributed.Fee.Equal(dec("0.5")) {
+		t.Errorf("expected the resting sell fill attributed, got %+v", attributed)
+	}
+}
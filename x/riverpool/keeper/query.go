@@ -142,6 +142,22 @@ func (q *QueryServer) NAVHistory(ctx context.Context, poolID string, fromTime, t
 	return history, nil
 }
 
+// AllocationStrategy returns the allocation strategy of a parent pool
+func (q *QueryServer) AllocationStrategy(ctx context.Context, poolID string) (*types.AllocationStrategy, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+	strategy := q.keeper.GetAllocationStrategy(sdkCtx, poolID)
+	if strategy == nil {
+		return nil, types.ErrPoolNotFound
+	}
+	return strategy, nil
+}
+
+// LookThroughNAV returns a pool's NAV broken down into cash and underlying holdings
+func (q *QueryServer) LookThroughNAV(ctx context.Context, poolID string) (*types.LookThroughNAV, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+	return q.keeper.GetLookThroughNAV(sdkCtx, poolID)
+}
+
 // DDGuardState returns the DDGuard state for a pool
 func (q *QueryServer) DDGuardState(ctx context.Context, poolID string) (*types.DDGuardState, error) {
 	sdkCtx := sdk.UnwrapSDKContext(ctx)
@@ -13,11 +13,12 @@ import (
 type RevenueSource string
 
 const (
-	RevenueSourceSpread      RevenueSource = "spread"      // Bid-ask spread earnings
-	RevenueSourceFunding     RevenueSource = "funding"     // Funding rate payments
-	RevenueSourceLiquidation RevenueSource = "liquidation" // Liquidation profits
-	RevenueSourceTrading     RevenueSource = "trading"     // Trading PnL
-	RevenueSourceFees        RevenueSource = "fees"        // Fee rebates
+	RevenueSourceSpread      RevenueSource = "spread"       // Bid-ask spread earnings
+	RevenueSourceFunding     RevenueSource = "funding"      // Funding rate payments
+	RevenueSourceLiquidation RevenueSource = "liquidation"  // Liquidation profits
+	RevenueSourceTrading     RevenueSource = "trading"      // Trading PnL
+	RevenueSourceFees        RevenueSource = "fees"         // Fee rebates
+	RevenueSourceTradingFees RevenueSource = "trading_fees" // Share of exchange trading fees
 )
 
 // RevenueRecord tracks individual revenue events
@@ -43,6 +44,7 @@ type PoolRevenueStats struct {
 	LiquidationProfit math.LegacyDec
 	TradingPnL        math.LegacyDec
 	FeeRebates        math.LegacyDec
+	TradingFees       math.LegacyDec
 	LastUpdated       int64
 }
 
@@ -90,7 +92,7 @@ func (k *Keeper) RecordRevenue(
 	// Update pool NAV with new revenue
 	if amount.GT(math.LegacyZeroDec()) {
 		pool.TotalDeposits = pool.TotalDeposits.Add(amount)
-		pool.UpdateNAV(pool.TotalDeposits)
+		pool.UpdateNAV(k.poolValue(ctx, pool))
 		k.SetPool(ctx, pool)
 	}
 
@@ -116,6 +118,43 @@ func (k *Keeper) RecordRevenue(
 	return nil
 }
 
+// RecordFeeRevenue credits the riverpool share of a market's trading fees to
+// the active Foundation and Main pools, pro rata to their deposits. The last
+// pool takes the rounding remainder, so the pools receive exactly amount.
+func (k *Keeper) RecordFeeRevenue(ctx sdk.Context, marketID string, amount math.LegacyDec) error {
+	if !amount.IsPositive() {
+		return nil
+	}
+
+	pools := make([]*types.Pool, 0)
+	totalDeposits := math.LegacyZeroDec()
+	for _, poolType := range []string{types.PoolTypeFoundation, types.PoolTypeMain} {
+		for _, pool := range k.GetPoolsByType(ctx, poolType) {
+			if pool.Status != types.PoolStatusActive || !pool.TotalDeposits.IsPositive() {
+				continue
+			}
+			pools = append(pools, pool)
+			totalDeposits = totalDeposits.Add(pool.TotalDeposits)
+		}
+	}
+	if len(pools) == 0 {
+		return types.ErrPoolNotFound
+	}
+
+	remaining := amount
+	for i, pool := range pools {
+		share := remaining
+		if i < len(pools)-1 {
+			share = amount.Mul(pool.TotalDeposits).Quo(totalDeposits)
+		}
+		remaining = remaining.Sub(share)
+		if err := k.RecordRevenue(ctx, pool.PoolID, RevenueSourceTradingFees, share, marketID, "", "Trading fee share"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // RecordLoss records a loss event for a pool
 func (k *Keeper) RecordLoss(
 	ctx sdk.Context,
@@ -200,8 +239,12 @@ func (k *Keeper) updatePoolRevenueStats(ctx sdk.Context, record *RevenueRecord)
 			LiquidationProfit: math.LegacyZeroDec(),
 			TradingPnL:        math.LegacyZeroDec(),
 			FeeRebates:        math.LegacyZeroDec(),
+			TradingFees:       math.LegacyZeroDec(),
 		}
 	}
+	if stats.TradingFees.IsNil() {
+		stats.TradingFees = math.LegacyZeroDec()
+	}
 
 	// Update total
 	stats.TotalRevenue = stats.TotalRevenue.Add(record.Amount)
@@ -219,6 +262,8 @@ func (k *Keeper) updatePoolRevenueStats(ctx sdk.Context, record *RevenueRecord)
 		stats.TradingPnL = stats.TradingPnL.Add(record.Amount)
 	case RevenueSourceFees:
 		stats.FeeRebates = stats.FeeRebates.Add(record.Amount)
+	case RevenueSourceTradingFees:
+		stats.TradingFees = stats.TradingFees.Add(record.Amount)
 	}
 
 	k.SetPoolRevenueStats(ctx, stats)
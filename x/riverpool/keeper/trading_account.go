@@ -0,0 +1,73 @@
+package keeper
+
+import (
+	"context"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/riverpool/types"
+)
+
+// DepositFromTradingAccount moves free collateral from the depositor's
+// perpetual trading account into a pool. Both legs run in a cache context so
+// either both apply or neither does.
+func (k *Keeper) DepositFromTradingAccount(ctx context.Context, depositor, poolID string, amount math.LegacyDec, inviteCode string) (*types.Deposit, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	// Margin safety: only collateral not backing open positions can move
+	if k.perpetualKeeper.GetFreeCollateral(sdkCtx, depositor).LT(amount) {
+		return nil, types.ErrInsufficientFreeCollateral
+	}
+
+	cacheCtx, write := sdkCtx.CacheContext()
+	if err := k.perpetualKeeper.Withdraw(cacheCtx, depositor, amount); err != nil {
+		return nil, err
+	}
+	deposit, err := k.Deposit(cacheCtx, depositor, poolID, amount, inviteCode)
+	if err != nil {
+		return nil, err
+	}
+	write()
+
+	sdkCtx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"riverpool_deposit_from_trading_account",
+			sdk.NewAttribute("pool_id", poolID),
+			sdk.NewAttribute("depositor", depositor),
+			sdk.NewAttribute("deposit_id", deposit.DepositID),
+			sdk.NewAttribute("amount", amount.String()),
+		),
+	)
+
+	return deposit, nil
+}
+
+// ClaimWithdrawalToTradingAccount claims a withdrawal and credits the amount
+// to the withdrawer's perpetual trading account
+func (k *Keeper) ClaimWithdrawalToTradingAccount(ctx context.Context, withdrawer, withdrawalID string) (*types.Withdrawal, math.LegacyDec, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	cacheCtx, write := sdkCtx.CacheContext()
+	withdrawal, amount, err := k.ClaimWithdrawal(cacheCtx, withdrawer, withdrawalID)
+	if err != nil {
+		return nil, math.LegacyZeroDec(), err
+	}
+	if amount.IsPositive() {
+		if err := k.perpetualKeeper.Deposit(cacheCtx, withdrawer, amount); err != nil {
+			return nil, math.LegacyZeroDec(), err
+		}
+	}
+	write()
+
+	sdkCtx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"riverpool_claim_to_trading_account",
+			sdk.NewAttribute("pool_id", withdrawal.PoolID),
+			sdk.NewAttribute("withdrawer", withdrawer),
+			sdk.NewAttribute("withdrawal_id", withdrawalID),
+			sdk.NewAttribute("amount", amount.String()),
+		),
+	)
+
+	return withdrawal, amount, nil
+}
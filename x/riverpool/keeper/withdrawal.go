@@ -102,6 +102,9 @@ func (k *Keeper) ClaimWithdrawal(ctx context.Context, withdrawer, withdrawalID s
 
 	// Calculate redemption with pro-rata if needed
 	sharesToRedeem, amountToReceive := k.calculateProRataRedemption(sdkCtx, pool, withdrawal)
+	if err := k.burnLPTokens(sdkCtx, withdrawal.PoolID, withdrawer, sharesToRedeem); err != nil {
+		return nil, math.LegacyZeroDec(), err
+	}
 
 	// Update withdrawal
 	withdrawal.SharesRedeemed = withdrawal.SharesRedeemed.Add(sharesToRedeem)
@@ -123,6 +126,11 @@ func (k *Keeper) ClaimWithdrawal(ctx context.Context, withdrawer, withdrawalID s
 	// Reduce user's shares from deposits (FIFO)
 	k.reduceUserShares(sdkCtx, withdrawal.Withdrawer, withdrawal.PoolID, sharesToRedeem)
 
+	// A fully redeemed withdrawer frees its holder slot
+	if pool.TotalHolders > 0 && !k.GetUserTotalShares(sdkCtx, withdrawal.PoolID, withdrawal.Withdrawer).IsPositive() {
+		pool.TotalHolders--
+	}
+
 	// Save changes
 	k.SetWithdrawal(sdkCtx, withdrawal)
 	k.SetPool(sdkCtx, pool)
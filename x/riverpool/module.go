@@ -2,6 +2,7 @@ package riverpool
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"cosmossdk.io/core/appmodule"
 	"github.com/cosmos/cosmos-sdk/client"
@@ -17,11 +18,16 @@ import (
 
 const (
 	ModuleName = types.ModuleName
+
+	// ConsensusVersion is bumped whenever the store layout or state machine changes
+	ConsensusVersion = 3
 )
 
 var (
-	_ module.AppModuleBasic = AppModuleBasic{}
-	_ appmodule.AppModule   = AppModule{}
+	_ module.AppModuleBasic      = AppModuleBasic{}
+	_ appmodule.AppModule        = AppModule{}
+	_ module.HasGenesis          = AppModule{}
+	_ module.HasConsensusVersion = AppModule{}
 )
 
 // AppModuleBasic defines the basic application module for riverpool
@@ -40,6 +46,8 @@ func (AppModuleBasic) RegisterLegacyAminoCodec(cdc *codec.LegacyAmino) {
 	cdc.RegisterConcrete(&types.MsgCancelWithdrawal{}, "riverpool/MsgCancelWithdrawal", nil)
 	cdc.RegisterConcrete(&types.MsgCreateCommunityPool{}, "riverpool/MsgCreateCommunityPool", nil)
 	cdc.RegisterConcrete(&types.MsgUpdateDDGuard{}, "riverpool/MsgUpdateDDGuard", nil)
+	cdc.RegisterConcrete(&types.MsgUpdateAllocationStrategy{}, "riverpool/MsgUpdateAllocationStrategy", nil)
+	cdc.RegisterConcrete(&types.MsgDepositFromTradingAccount{}, "riverpool/MsgDepositFromTradingAccount", nil)
 }
 
 // RegisterInterfaces registers the module's interface types
@@ -51,17 +59,27 @@ func (AppModuleBasic) RegisterInterfaces(registry cdctypes.InterfaceRegistry) {
 		&types.MsgCancelWithdrawal{},
 		&types.MsgCreateCommunityPool{},
 		&types.MsgUpdateDDGuard{},
+		&types.MsgUpdateAllocationStrategy{},
+		&types.MsgDepositFromTradingAccount{},
 	)
 }
 
 // DefaultGenesis returns default genesis state as raw bytes
 func (AppModuleBasic) DefaultGenesis(cdc codec.JSONCodec) json.RawMessage {
-	return nil
+	bz, err := json.Marshal(types.DefaultGenesis())
+	if err != nil {
+		panic(err)
+	}
+	return bz
 }
 
 // ValidateGenesis performs genesis state validation
 func (AppModuleBasic) ValidateGenesis(cdc codec.JSONCodec, config client.TxEncodingConfig, bz json.RawMessage) error {
-	return nil
+	var gs types.GenesisState
+	if err := json.Unmarshal(bz, &gs); err != nil {
+		return fmt.Errorf("failed to unmarshal %s genesis state: %w", ModuleName, err)
+	}
+	return gs.Validate()
 }
 
 // RegisterGRPCGatewayRoutes registers the gRPC Gateway routes for the module
@@ -94,8 +112,39 @@ func (am AppModule) RegisterServices(cfg module.Configurator) {
 	// Note: In a full implementation, you would register the proto-generated server
 	// For now, we'll use the custom MsgServer
 	_ = keeper.NewMsgServerImpl(am.keeper)
+
+	m := keeper.NewMigrator(am.keeper)
+	if err := cfg.RegisterMigration(ModuleName, 1, m.Migrate1to2); err != nil {
+		panic(fmt.Errorf("failed to register %s migration from version 1 to 2: %w", ModuleName, err))
+	}
+	if err := cfg.RegisterMigration(ModuleName, 2, m.Migrate2to3); err != nil {
+		panic(fmt.Errorf("failed to register %s migration from version 2 to 3: %w", ModuleName, err))
+	}
+}
+
+// InitGenesis imports the module's genesis state
+func (am AppModule) InitGenesis(ctx sdk.Context, cdc codec.JSONCodec, data json.RawMessage) {
+	var gs types.GenesisState
+	if err := json.Unmarshal(data, &gs); err != nil {
+		panic(fmt.Errorf("failed to unmarshal %s genesis state: %w", ModuleName, err))
+	}
+	if err := am.keeper.InitGenesis(ctx, &gs); err != nil {
+		panic(err)
+	}
 }
 
+// ExportGenesis exports the module's state as genesis
+func (am AppModule) ExportGenesis(ctx sdk.Context, cdc codec.JSONCodec) json.RawMessage {
+	bz, err := json.Marshal(am.keeper.ExportGenesis(ctx))
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// ConsensusVersion implements module.HasConsensusVersion
+func (AppModule) ConsensusVersion() uint64 { return ConsensusVersion }
+
 // IsOnePerModuleType implements the depinject.OnePerModuleType interface
 func (am AppModule) IsOnePerModuleType() {}
 
@@ -0,0 +1,279 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"cosmossdk.io/math"
+)
+
+// Allocation defaults
+var (
+	DefaultMaxAllocation      = math.LegacyMustNewDecFromStr("0.50") // 50% of Main LP value; the rest stays liquid for redemptions
+	DefaultRebalanceThreshold = math.LegacyMustNewDecFromStr("0.05") // 5% drift of Main LP value
+	DefaultRebalanceInterval  = int64(24 * 60 * 60)                  // daily
+)
+
+// Allocation errors
+var (
+	ErrInvalidAllocationStrategy = errors.New("invalid allocation strategy")
+	ErrNotAllocatingPool         = errors.New("only the Main LP can allocate to other pools")
+	ErrInvalidAllocationTarget   = errors.New("allocation target must be a community pool")
+)
+
+// Reasons an underlying pool is excluded from allocation
+const (
+	ExclusionNotTargeted = "not_targeted"
+	ExclusionInactive    = "inactive"
+	ExclusionDDGuard     = "ddguard"
+	ExclusionDrawdown    = "drawdown"
+	ExclusionReturn      = "return"
+	ExclusionTVL         = "tvl"
+	ExclusionAge         = "age"
+)
+
+// AllocationTarget is the configured weight of one underlying pool.
+// Weights are relative and normalized over the eligible targets.
+type AllocationTarget struct {
+	PoolID string         `json:"pool_id"`
+	Weight math.LegacyDec `json:"weight"`
+}
+
+// AllocationFilter excludes underlying pools that fail performance checks.
+// Zero fields are not checked.
+type AllocationFilter struct {
+	MaxDrawdown  math.LegacyDec `json:"max_drawdown,omitempty"`   // e.g. 0.10 excludes pools more than 10% below their high water mark
+	MinReturn30d math.LegacyDec `json:"min_return_30d,omitempty"` // percent, as reported by CalculatePoolReturn
+	MinTVL       math.LegacyDec `json:"min_tvl,omitempty"`
+	MinAgeDays   int64          `json:"min_age_days,omitempty"`
+}
+
+// AllocationStrategy configures how a parent pool spreads capital across
+// community pools
+type AllocationStrategy struct {
+	PoolID             string             `json:"pool_id"` // parent pool
+	Targets            []AllocationTarget `json:"targets"`
+	MaxAllocation      math.LegacyDec     `json:"max_allocation"`              // fraction of parent value deployed
+	MaxPoolExposure    math.LegacyDec     `json:"max_pool_exposure,omitempty"` // cap per underlying as fraction of parent value, zero for none
+	RebalanceThreshold math.LegacyDec     `json:"rebalance_threshold"`         // drift as fraction of parent value that triggers an early rebalance
+	RebalanceInterval  int64              `json:"rebalance_interval"`          // seconds between scheduled rebalances
+	Filter             AllocationFilter   `json:"filter"`
+	LastRebalanceAt    int64              `json:"last_rebalance_at"`
+	UpdatedAt          int64              `json:"updated_at"`
+}
+
+// NewAllocationStrategy creates a strategy with default limits
+func NewAllocationStrategy(poolID string, targets []AllocationTarget) *AllocationStrategy {
+	return &AllocationStrategy{
+		PoolID:             poolID,
+		Targets:            targets,
+		MaxAllocation:      DefaultMaxAllocation,
+		RebalanceThreshold: DefaultRebalanceThreshold,
+		RebalanceInterval:  DefaultRebalanceInterval,
+	}
+}
+
+// Validate checks weights and limits
+func (s *AllocationStrategy) Validate() error {
+	if s.PoolID == "" {
+		return fmt.Errorf("%w: missing pool ID", ErrInvalidAllocationStrategy)
+	}
+	seen := make(map[string]bool, len(s.Targets))
+	for _, t := range s.Targets {
+		if t.PoolID == "" || t.PoolID == s.PoolID {
+			return fmt.Errorf("%w: invalid target pool %q", ErrInvalidAllocationStrategy, t.PoolID)
+		}
+		if seen[t.PoolID] {
+			return fmt.Errorf("%w: duplicate target %s", ErrInvalidAllocationStrategy, t.PoolID)
+		}
+		seen[t.PoolID] = true
+		if t.Weight.IsNil() || !t.Weight.IsPositive() {
+			return fmt.Errorf("%w: weight of %s must be positive", ErrInvalidAllocationStrategy, t.PoolID)
+		}
+	}
+	if s.MaxAllocation.IsNil() || !s.MaxAllocation.IsPositive() || s.MaxAllocation.GT(math.LegacyOneDec()) {
+		return fmt.Errorf("%w: max allocation must be in (0, 1]", ErrInvalidAllocationStrategy)
+	}
+	if isSet(s.MaxPoolExposure) && (s.MaxPoolExposure.IsNegative() || s.MaxPoolExposure.GT(math.LegacyOneDec())) {
+		return fmt.Errorf("%w: max pool exposure must be in [0, 1]", ErrInvalidAllocationStrategy)
+	}
+	if s.RebalanceThreshold.IsNil() || s.RebalanceThreshold.IsNegative() || s.RebalanceThreshold.GTE(math.LegacyOneDec()) {
+		return fmt.Errorf("%w: rebalance threshold must be in [0, 1)", ErrInvalidAllocationStrategy)
+	}
+	if s.RebalanceInterval < 0 {
+		return fmt.Errorf("%w: rebalance interval must not be negative", ErrInvalidAllocationStrategy)
+	}
+	f := s.Filter
+	if (isSet(f.MaxDrawdown) && f.MaxDrawdown.IsNegative()) || (isSet(f.MinTVL) && f.MinTVL.IsNegative()) || f.MinAgeDays < 0 {
+		return fmt.Errorf("%w: filter limits must not be negative", ErrInvalidAllocationStrategy)
+	}
+	return nil
+}
+
+// Eligible returns whether an underlying pool passes the filter at now (unix
+// seconds), and the exclusion reason if not. Paused, closed and DDGuard-reduced
+// pools are always excluded.
+func (f AllocationFilter) Eligible(pool *Pool, return30d math.LegacyDec, now int64) (bool, string) {
+	if pool.Status != PoolStatusActive {
+		return false, ExclusionInactive
+	}
+	if pool.DDGuardLevel == DDGuardLevelReduce || pool.DDGuardLevel == DDGuardLevelHalt {
+		return false, ExclusionDDGuard
+	}
+	if isSet(f.MaxDrawdown) && pool.CurrentDrawdown.GT(f.MaxDrawdown) {
+		return false, ExclusionDrawdown
+	}
+	if isSet(f.MinReturn30d) && return30d.LT(f.MinReturn30d) {
+		return false, ExclusionReturn
+	}
+	if isSet(f.MinTVL) && pool.TotalDeposits.LT(f.MinTVL) {
+		return false, ExclusionTVL
+	}
+	if f.MinAgeDays > 0 && now-pool.CreatedAt < f.MinAgeDays*24*60*60 {
+		return false, ExclusionAge
+	}
+	return true, ""
+}
+
+// TargetValues returns the value each eligible target should hold given the
+// parent pool value. Capital freed by ineligible targets and exposure caps
+// stays in the parent as cash.
+func (s *AllocationStrategy) TargetValues(parentValue math.LegacyDec, eligible map[string]bool) map[string]math.LegacyDec {
+	targets := make(map[string]math.LegacyDec)
+	totalWeight := math.LegacyZeroDec()
+	for _, t := range s.Targets {
+		if eligible[t.PoolID] {
+			totalWeight = totalWeight.Add(t.Weight)
+		}
+	}
+	if !totalWeight.IsPositive() || !parentValue.IsPositive() {
+		return targets
+	}
+
+	deployable := parentValue.Mul(s.MaxAllocation)
+	for _, t := range s.Targets {
+		if !eligible[t.PoolID] {
+			continue
+		}
+		value := deployable.Mul(t.Weight).Quo(totalWeight)
+		if isSet(s.MaxPoolExposure) {
+			value = math.LegacyMinDec(value, parentValue.Mul(s.MaxPoolExposure))
+		}
+		targets[t.PoolID] = value
+	}
+	return targets
+}
+
+// PoolAllocation is a parent pool's holding in an underlying pool
+type PoolAllocation struct {
+	ParentPoolID string         `json:"parent_pool_id"`
+	PoolID       string         `json:"pool_id"`
+	Shares       math.LegacyDec `json:"shares"`
+	CostBasis    math.LegacyDec `json:"cost_basis"`
+	UpdatedAt    int64          `json:"updated_at"`
+}
+
+// RebalanceOrder moves capital between the parent and one underlying pool.
+// A positive amount allocates, a negative amount redeems.
+type RebalanceOrder struct {
+	PoolID string         `json:"pool_id"`
+	Amount math.LegacyDec `json:"amount"`
+}
+
+// MaxDrift returns the largest difference between current and target value
+// across underlyings, as a fraction of the parent value
+func MaxDrift(targets, current map[string]math.LegacyDec, parentValue math.LegacyDec) math.LegacyDec {
+	drift := math.LegacyZeroDec()
+	if !parentValue.IsPositive() {
+		return drift
+	}
+	for _, poolID := range unionKeys(targets, current) {
+		diff := valueOf(current, poolID).Sub(valueOf(targets, poolID)).Abs()
+		drift = math.LegacyMaxDec(drift, diff.Quo(parentValue))
+	}
+	return drift
+}
+
+// PlanRebalance returns the orders that move current holdings to their
+// targets. Redemptions come first so their proceeds can fund allocations;
+// allocations are limited to the available cash. Orders within each group
+// are sorted by pool ID.
+func PlanRebalance(targets, current map[string]math.LegacyDec, cash math.LegacyDec) []RebalanceOrder {
+	var redeem, allocate []RebalanceOrder
+	for _, poolID := range unionKeys(targets, current) {
+		diff := valueOf(targets, poolID).Sub(valueOf(current, poolID))
+		switch {
+		case diff.IsNegative():
+			redeem = append(redeem, RebalanceOrder{PoolID: poolID, Amount: diff})
+			cash = cash.Sub(diff)
+		case diff.IsPositive():
+			allocate = append(allocate, RebalanceOrder{PoolID: poolID, Amount: diff})
+		}
+	}
+
+	orders := redeem
+	for _, o := range allocate {
+		if !cash.IsPositive() {
+			break
+		}
+		o.Amount = math.LegacyMinDec(o.Amount, cash)
+		cash = cash.Sub(o.Amount)
+		orders = append(orders, o)
+	}
+	return orders
+}
+
+// UnderlyingExposure is a parent pool's look-through position in one underlying
+type UnderlyingExposure struct {
+	ParentPoolID  string         `json:"parent_pool_id"`
+	PoolID        string         `json:"pool_id"`
+	Shares        math.LegacyDec `json:"shares"`
+	CostBasis     math.LegacyDec `json:"cost_basis"`
+	Value         math.LegacyDec `json:"value"`
+	UnrealizedPnL math.LegacyDec `json:"unrealized_pnl"`
+	Weight        math.LegacyDec `json:"weight"`        // share of parent value
+	TargetWeight  math.LegacyDec `json:"target_weight"` // share of parent value at the last rebalance
+	Eligible      bool           `json:"eligible"`
+	Exclusion     string         `json:"exclusion,omitempty"`
+	UpdatedAt     int64          `json:"updated_at"`
+}
+
+// LookThroughNAV breaks a parent pool's NAV into cash and underlying holdings
+type LookThroughNAV struct {
+	PoolID         string                `json:"pool_id"`
+	Cash           math.LegacyDec        `json:"cash"`
+	AllocatedValue math.LegacyDec        `json:"allocated_value"`
+	TotalValue     math.LegacyDec        `json:"total_value"`
+	TotalShares    math.LegacyDec        `json:"total_shares"`
+	NAV            math.LegacyDec        `json:"nav"`
+	Underlyings    []*UnderlyingExposure `json:"underlyings"`
+	Timestamp      int64                 `json:"timestamp"`
+}
+
+// isSet reports whether an optional limit is configured. Unset limits are
+// stored as zero since LegacyDec encodes nil as zero in JSON.
+func isSet(d math.LegacyDec) bool {
+	return !d.IsNil() && !d.IsZero()
+}
+
+func valueOf(values map[string]math.LegacyDec, poolID string) math.LegacyDec {
+	if v, ok := values[poolID]; ok {
+		return v
+	}
+	return math.LegacyZeroDec()
+}
+
+func unionKeys(a, b map[string]math.LegacyDec) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
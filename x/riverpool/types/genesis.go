@@ -0,0 +1,162 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidGenesis is returned for a genesis state that cannot be imported
+var ErrInvalidGenesis = errors.New("invalid genesis state")
+
+// GenesisState is the riverpool module's exported state
+type GenesisState struct {
+	Pools                []*Pool               `json:"pools"`
+	PoolStats            []*PoolStats          `json:"pool_stats"`
+	DDGuardStates        []*DDGuardState       `json:"ddguard_states"`
+	Deposits             []*Deposit            `json:"deposits"`
+	Withdrawals          []*Withdrawal         `json:"withdrawals"`
+	AllocationStrategies []*AllocationStrategy `json:"allocation_strategies"`
+	PoolAllocations      []*PoolAllocation     `json:"pool_allocations"`
+	NAVPricing           *NAVPricingConfig     `json:"nav_pricing,omitempty"` // defaults if unset
+	InviteCodes          []*InviteCode         `json:"invite_codes"`
+	InviteRedemptions    []*InviteRedemption   `json:"invite_redemptions"`
+}
+
+// DefaultGenesis returns an empty riverpool state. The Foundation LP and
+// Main LP are created at genesis if they are not listed.
+func DefaultGenesis() *GenesisState {
+	return &GenesisState{
+		Pools:                make([]*Pool, 0),
+		PoolStats:            make([]*PoolStats, 0),
+		DDGuardStates:        make([]*DDGuardState, 0),
+		Deposits:             make([]*Deposit, 0),
+		Withdrawals:          make([]*Withdrawal, 0),
+		AllocationStrategies: make([]*AllocationStrategy, 0),
+		PoolAllocations:      make([]*PoolAllocation, 0),
+		InviteCodes:          make([]*InviteCode, 0),
+		InviteRedemptions:    make([]*InviteRedemption, 0),
+	}
+}
+
+// Validate checks for duplicates and that every record belongs to a known pool
+func (gs *GenesisState) Validate() error {
+	pools := make(map[string]bool, len(gs.Pools))
+	for _, pool := range gs.Pools {
+		if pool == nil || pool.PoolID == "" {
+			return fmt.Errorf("%w: pool without ID", ErrInvalidGenesis)
+		}
+		if pools[pool.PoolID] {
+			return fmt.Errorf("%w: duplicate pool %s", ErrInvalidGenesis, pool.PoolID)
+		}
+		pools[pool.PoolID] = true
+	}
+	knownPool := func(kind, id, poolID string) error {
+		if !pools[poolID] {
+			return fmt.Errorf("%w: %s %s belongs to unknown pool %q", ErrInvalidGenesis, kind, id, poolID)
+		}
+		return nil
+	}
+
+	for _, stats := range gs.PoolStats {
+		if stats == nil {
+			return fmt.Errorf("%w: empty pool stats", ErrInvalidGenesis)
+		}
+		if err := knownPool("stats", stats.PoolID, stats.PoolID); err != nil {
+			return err
+		}
+	}
+	for _, state := range gs.DDGuardStates {
+		if state == nil {
+			return fmt.Errorf("%w: empty ddguard state", ErrInvalidGenesis)
+		}
+		if err := knownPool("ddguard state", state.PoolID, state.PoolID); err != nil {
+			return err
+		}
+	}
+
+	deposits := make(map[string]bool, len(gs.Deposits))
+	for _, deposit := range gs.Deposits {
+		if deposit == nil || deposit.DepositID == "" || deposit.Depositor == "" {
+			return fmt.Errorf("%w: deposit without ID or depositor", ErrInvalidGenesis)
+		}
+		if deposits[deposit.DepositID] {
+			return fmt.Errorf("%w: duplicate deposit %s", ErrInvalidGenesis, deposit.DepositID)
+		}
+		if err := knownPool("deposit", deposit.DepositID, deposit.PoolID); err != nil {
+			return err
+		}
+		deposits[deposit.DepositID] = true
+	}
+
+	withdrawals := make(map[string]bool, len(gs.Withdrawals))
+	for _, withdrawal := range gs.Withdrawals {
+		if withdrawal == nil || withdrawal.WithdrawalID == "" || withdrawal.Withdrawer == "" {
+			return fmt.Errorf("%w: withdrawal without ID or withdrawer", ErrInvalidGenesis)
+		}
+		if withdrawals[withdrawal.WithdrawalID] {
+			return fmt.Errorf("%w: duplicate withdrawal %s", ErrInvalidGenesis, withdrawal.WithdrawalID)
+		}
+		if err := knownPool("withdrawal", withdrawal.WithdrawalID, withdrawal.PoolID); err != nil {
+			return err
+		}
+		withdrawals[withdrawal.WithdrawalID] = true
+	}
+
+	for _, strategy := range gs.AllocationStrategies {
+		if strategy == nil {
+			return fmt.Errorf("%w: empty allocation strategy", ErrInvalidGenesis)
+		}
+		if err := knownPool("allocation strategy", strategy.PoolID, strategy.PoolID); err != nil {
+			return err
+		}
+		if err := strategy.Validate(); err != nil {
+			return fmt.Errorf("%w: allocation strategy %s: %v", ErrInvalidGenesis, strategy.PoolID, err)
+		}
+	}
+	for _, allocation := range gs.PoolAllocations {
+		if allocation == nil {
+			return fmt.Errorf("%w: empty pool allocation", ErrInvalidGenesis)
+		}
+		id := allocation.ParentPoolID + ":" + allocation.PoolID
+		if err := knownPool("allocation", id, allocation.ParentPoolID); err != nil {
+			return err
+		}
+		if err := knownPool("allocation", id, allocation.PoolID); err != nil {
+			return err
+		}
+	}
+	if gs.NAVPricing != nil {
+		if err := gs.NAVPricing.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidGenesis, err)
+		}
+	}
+
+	codes := make(map[string]string, len(gs.InviteCodes))
+	for _, code := range gs.InviteCodes {
+		if code == nil || code.Code == "" {
+			return fmt.Errorf("%w: invite code without code", ErrInvalidGenesis)
+		}
+		if _, ok := codes[code.Code]; ok {
+			return fmt.Errorf("%w: duplicate invite code %s", ErrInvalidGenesis, code.Code)
+		}
+		if err := knownPool("invite code", code.Code, code.PoolID); err != nil {
+			return err
+		}
+		codes[code.Code] = code.PoolID
+	}
+	redemptions := make(map[string]bool, len(gs.InviteRedemptions))
+	for _, redemption := range gs.InviteRedemptions {
+		if redemption == nil || redemption.Address == "" {
+			return fmt.Errorf("%w: invite redemption without address", ErrInvalidGenesis)
+		}
+		key := redemption.Code + ":" + redemption.Address
+		if poolID, ok := codes[redemption.Code]; !ok || poolID != redemption.PoolID {
+			return fmt.Errorf("%w: redemption %s of unknown invite code for pool %q", ErrInvalidGenesis, key, redemption.PoolID)
+		}
+		if redemptions[key] {
+			return fmt.Errorf("%w: duplicate invite redemption %s", ErrInvalidGenesis, key)
+		}
+		redemptions[key] = true
+	}
+	return nil
+}
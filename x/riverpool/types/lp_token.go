@@ -0,0 +1,46 @@
+package types
+
+import (
+	"errors"
+	"strings"
+
+	"cosmossdk.io/math"
+)
+
+// Pool shares are also bank coins of denom rp/{poolID}-lp, so they can be
+// transferred and used outside the module. One share is 10^18 base units, the
+// precision of LegacyDec, so coin balances and share records convert exactly.
+const (
+	LPDenomPrefix = "rp/"
+	LPDenomSuffix = "-lp"
+)
+
+// ErrSharesNotTransferable is returned when a pool share transfer would move
+// locked, pending-withdrawal or seat shares, or reach a receiver the pool does
+// not accept
+var ErrSharesNotTransferable = errors.New("pool shares not transferable")
+
+// LPDenom returns the bank denom of a pool's shares
+func LPDenom(poolID string) string {
+	return LPDenomPrefix + poolID + LPDenomSuffix
+}
+
+// PoolIDFromLPDenom returns the pool whose shares a denom represents, or
+// false if it is not a pool share denom
+func PoolIDFromLPDenom(denom string) (string, bool) {
+	if !strings.HasPrefix(denom, LPDenomPrefix) || !strings.HasSuffix(denom, LPDenomSuffix) {
+		return "", false
+	}
+	poolID := strings.TrimSuffix(strings.TrimPrefix(denom, LPDenomPrefix), LPDenomSuffix)
+	return poolID, poolID != ""
+}
+
+// SharesToLPAmount converts shares to the coin amount representing them
+func SharesToLPAmount(shares math.LegacyDec) math.Int {
+	return math.NewIntFromBigInt(shares.BigInt())
+}
+
+// LPAmountToShares converts a coin amount of a pool share denom to shares
+func LPAmountToShares(amount math.Int) math.LegacyDec {
+	return math.LegacyNewDecFromBigIntWithPrec(amount.BigInt(), math.LegacyPrecision)
+}
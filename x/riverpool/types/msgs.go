@@ -15,6 +15,9 @@ const (
 	TypeMsgCancelWithdrawal     = "cancel_withdrawal"
 	TypeMsgCreateCommunityPool  = "create_community_pool"
 	TypeMsgUpdateDDGuard        = "update_dd_guard"
+	TypeMsgUpdateAllocationStrategy = "update_allocation_strategy"
+	TypeMsgDepositFromTradingAccount = "deposit_from_trading_account"
+	TypeMsgPlacePoolOrder       = "place_pool_order"
 )
 
 // MsgDeposit defines the Deposit message
@@ -67,6 +70,58 @@ type MsgDepositResponse struct {
 	UnlockAt       int64  `json:"unlock_at"`
 }
 
+// MsgDepositFromTradingAccount deposits free collateral from the depositor's
+// perpetual trading account into a pool in one step
+type MsgDepositFromTradingAccount struct {
+	Depositor  string `json:"depositor"`
+	PoolID     string `json:"pool_id"`
+	Amount     string `json:"amount"`
+	InviteCode string `json:"invite_code,omitempty"`
+}
+
+// Route implements sdk.Msg
+func (msg MsgDepositFromTradingAccount) Route() string { return ModuleName }
+
+// Type implements sdk.Msg
+func (msg MsgDepositFromTradingAccount) Type() string { return TypeMsgDepositFromTradingAccount }
+
+// ValidateBasic implements sdk.Msg
+func (msg MsgDepositFromTradingAccount) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Depositor); err != nil {
+		return err
+	}
+	if msg.PoolID == "" {
+		return ErrPoolNotFound
+	}
+	return nil
+}
+
+// GetSigners implements sdk.Msg
+func (msg MsgDepositFromTradingAccount) GetSigners() []sdk.AccAddress {
+	addr, _ := sdk.AccAddressFromBech32(msg.Depositor)
+	return []sdk.AccAddress{addr}
+}
+
+// ProtoMessage implements proto.Message
+func (*MsgDepositFromTradingAccount) ProtoMessage() {}
+
+// Reset implements proto.Message
+func (msg *MsgDepositFromTradingAccount) Reset() { *msg = MsgDepositFromTradingAccount{} }
+
+// String implements proto.Message
+func (msg MsgDepositFromTradingAccount) String() string {
+	return fmt.Sprintf("MsgDepositFromTradingAccount{Depositor: %s, PoolID: %s, Amount: %s}", msg.Depositor, msg.PoolID, msg.Amount)
+}
+
+// MsgDepositFromTradingAccountResponse defines the DepositFromTradingAccount response
+type MsgDepositFromTradingAccountResponse struct {
+	DepositID      string `json:"deposit_id"`
+	SharesReceived string `json:"shares_received"`
+	NAVAtDeposit   string `json:"nav_at_deposit"`
+	UnlockAt       int64  `json:"unlock_at"`
+	FreeCollateral string `json:"free_collateral"` // remaining in the trading account
+}
+
 // MsgRequestWithdrawal defines the RequestWithdrawal message
 type MsgRequestWithdrawal struct {
 	Withdrawer string `json:"withdrawer"`
@@ -121,6 +176,10 @@ type MsgRequestWithdrawalResponse struct {
 type MsgClaimWithdrawal struct {
 	Withdrawer   string `json:"withdrawer"`
 	WithdrawalID string `json:"withdrawal_id"`
+
+	// ToTradingAccount credits the claimed amount to the withdrawer's
+	// perpetual trading account instead of paying it out
+	ToTradingAccount bool `json:"to_trading_account,omitempty"`
 }
 
 // Route implements sdk.Msg
@@ -308,6 +367,111 @@ type MsgUpdateDDGuardResponse struct {
 	CurrentDrawdown string `json:"current_drawdown"`
 }
 
+// MsgUpdateAllocationStrategy defines the UpdateAllocationStrategy message
+type MsgUpdateAllocationStrategy struct {
+	Authority string             `json:"authority"`
+	Strategy  AllocationStrategy `json:"strategy"`
+}
+
+// Route implements sdk.Msg
+func (msg MsgUpdateAllocationStrategy) Route() string { return ModuleName }
+
+// Type implements sdk.Msg
+func (msg MsgUpdateAllocationStrategy) Type() string { return TypeMsgUpdateAllocationStrategy }
+
+// ValidateBasic implements sdk.Msg
+func (msg MsgUpdateAllocationStrategy) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Authority); err != nil {
+		return err
+	}
+	return msg.Strategy.Validate()
+}
+
+// GetSigners implements sdk.Msg
+func (msg MsgUpdateAllocationStrategy) GetSigners() []sdk.AccAddress {
+	addr, _ := sdk.AccAddressFromBech32(msg.Authority)
+	return []sdk.AccAddress{addr}
+}
+
+// ProtoMessage implements proto.Message
+func (*MsgUpdateAllocationStrategy) ProtoMessage() {}
+
+// Reset implements proto.Message
+func (msg *MsgUpdateAllocationStrategy) Reset() { *msg = MsgUpdateAllocationStrategy{} }
+
+// String implements proto.Message
+func (msg MsgUpdateAllocationStrategy) String() string {
+	return fmt.Sprintf("MsgUpdateAllocationStrategy{Authority: %s, PoolID: %s}", msg.Authority, msg.Strategy.PoolID)
+}
+
+// MsgUpdateAllocationStrategyResponse defines the UpdateAllocationStrategy response
+type MsgUpdateAllocationStrategyResponse struct {
+	Rebalanced bool `json:"rebalanced"`
+}
+
+// MsgPlacePoolOrder places an order for a community pool, signed by its
+// owner. An empty price places a market order.
+type MsgPlacePoolOrder struct {
+	Owner    string `json:"owner"`
+	PoolID   string `json:"pool_id"`
+	MarketID string `json:"market_id"`
+	Side     string `json:"side"` // "buy" or "sell"
+	Price    string `json:"price,omitempty"`
+	Quantity string `json:"quantity"`
+	Leverage string `json:"leverage"`
+}
+
+// Route implements sdk.Msg
+func (msg MsgPlacePoolOrder) Route() string { return ModuleName }
+
+// Type implements sdk.Msg
+func (msg MsgPlacePoolOrder) Type() string { return TypeMsgPlacePoolOrder }
+
+// ValidateBasic implements sdk.Msg
+func (msg MsgPlacePoolOrder) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Owner); err != nil {
+		return err
+	}
+	if msg.PoolID == "" {
+		return ErrPoolNotFound
+	}
+	if msg.MarketID == "" {
+		return fmt.Errorf("%w: market_id is required", ErrInvalidPoolOrder)
+	}
+	if msg.Side != OrderSideBuy && msg.Side != OrderSideSell {
+		return fmt.Errorf("%w: side must be %q or %q", ErrInvalidPoolOrder, OrderSideBuy, OrderSideSell)
+	}
+	return nil
+}
+
+// GetSigners implements sdk.Msg
+func (msg MsgPlacePoolOrder) GetSigners() []sdk.AccAddress {
+	addr, _ := sdk.AccAddressFromBech32(msg.Owner)
+	return []sdk.AccAddress{addr}
+}
+
+// ProtoMessage implements proto.Message
+func (*MsgPlacePoolOrder) ProtoMessage() {}
+
+// Reset implements proto.Message
+func (msg *MsgPlacePoolOrder) Reset() { *msg = MsgPlacePoolOrder{} }
+
+// String implements proto.Message
+func (msg MsgPlacePoolOrder) String() string {
+	return fmt.Sprintf("MsgPlacePoolOrder{Owner: %s, PoolID: %s, MarketID: %s, Side: %s, Quantity: %s, Leverage: %s}",
+		msg.Owner, msg.PoolID, msg.MarketID, msg.Side, msg.Quantity, msg.Leverage)
+}
+
+// MsgPlacePoolOrderResponse defines the PlacePoolOrder response
+type MsgPlacePoolOrderResponse struct {
+	OrderID   string   `json:"order_id"`
+	Status    string   `json:"status"`
+	FilledQty string   `json:"filled_qty"`
+	AvgPrice  string   `json:"avg_price"`
+	TradeIDs  []string `json:"trade_ids"`
+	Trader    string   `json:"trader"` // the pool trading account holding the position
+}
+
 // Ensure all messages implement sdk.Msg interface
 var (
 	_ sdk.Msg = &MsgDeposit{}
@@ -316,4 +480,7 @@ var (
 	_ sdk.Msg = &MsgCancelWithdrawal{}
 	_ sdk.Msg = &MsgCreateCommunityPool{}
 	_ sdk.Msg = &MsgUpdateDDGuard{}
+	_ sdk.Msg = &MsgUpdateAllocationStrategy{}
+	_ sdk.Msg = &MsgDepositFromTradingAccount{}
+	_ sdk.Msg = &MsgPlacePoolOrder{}
 )
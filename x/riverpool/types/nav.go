@@ -0,0 +1,72 @@
+package types
+
+import (
+	"fmt"
+
+	"cosmossdk.io/math"
+)
+
+// NAV price sources: how a pool position was priced for NAV
+const (
+	NAVPriceSourceMark     = "mark"         // fresh mark price within the band around the last trade
+	NAVPriceSourceClamped  = "mark_clamped" // fresh mark price clamped to the band around the last trade
+	NAVPriceSourcePrevious = "previous"     // stale or missing mark price: the last accepted price
+	NAVPriceSourceEntry    = "entry"        // no accepted price yet: entry price, so no unrealized PnL
+)
+
+// NAVPricingConfig bounds the prices pool positions are valued at, so a stale
+// oracle or a thin book cannot move pool NAV. A mark price older than
+// MaxPriceAge is not used, and a fresh mark price is clamped to within
+// MaxLastDeviation of the last trade price.
+type NAVPricingConfig struct {
+	MaxPriceAge      int64          `json:"max_price_age"` // seconds
+	MaxLastDeviation math.LegacyDec `json:"max_last_deviation"`
+}
+
+// DefaultNAVPricingConfig uses mark prices up to 5 minutes old, at most 5%
+// away from the last trade price
+func DefaultNAVPricingConfig() *NAVPricingConfig {
+	return &NAVPricingConfig{
+		MaxPriceAge:      300,
+		MaxLastDeviation: math.LegacyNewDecWithPrec(5, 2),
+	}
+}
+
+// Validate checks the price age is positive and the deviation is in (0, 1)
+func (c *NAVPricingConfig) Validate() error {
+	if c.MaxPriceAge <= 0 {
+		return fmt.Errorf("%w: max price age must be positive", ErrInvalidNAVPricingConfig)
+	}
+	if c.MaxLastDeviation.IsNil() || !c.MaxLastDeviation.IsPositive() || c.MaxLastDeviation.GTE(math.LegacyOneDec()) {
+		return fmt.Errorf("%w: max last deviation must be between 0 and 1", ErrInvalidNAVPricingConfig)
+	}
+	return nil
+}
+
+// NAVPositionPrice is one pool position as valued for NAV, annotated with the
+// prices considered and the source of the price used
+type NAVPositionPrice struct {
+	MarketID      string         `json:"market_id"`
+	Side          string         `json:"side"`
+	Size          math.LegacyDec `json:"size"`
+	EntryPrice    math.LegacyDec `json:"entry_price"`
+	MarkPrice     math.LegacyDec `json:"mark_price"` // zero if none
+	LastPrice     math.LegacyDec `json:"last_price"` // zero if none
+	PriceAge      int64          `json:"price_age"`  // seconds since the mark price was set
+	Price         math.LegacyDec `json:"price"`      // price the position was valued at
+	Source        string         `json:"source"`
+	UnrealizedPnL math.LegacyDec `json:"unrealized_pnl"`
+}
+
+// NAVComputation is a pool's value broken down into cash, allocations to
+// other pools and the unrealized PnL of its positions
+type NAVComputation struct {
+	PoolID         string              `json:"pool_id"`
+	Cash           math.LegacyDec      `json:"cash"`
+	AllocatedValue math.LegacyDec      `json:"allocated_value"`
+	PositionPnL    math.LegacyDec      `json:"position_pnl"`
+	TotalValue     math.LegacyDec      `json:"total_value"`
+	TotalShares    math.LegacyDec      `json:"total_shares"`
+	NAV            math.LegacyDec      `json:"nav"`
+	Positions      []*NAVPositionPrice `json:"positions"`
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/address"
+)
+
+// Pool trading defaults
+var (
+	DefaultPoolMaxLeverage = math.LegacyNewDec(10) // cap for community pools created without MaxLeverage
+)
+
+// Order sides
+const (
+	OrderSideBuy  = "buy"
+	OrderSideSell = "sell"
+)
+
+// Pool trading errors
+var (
+	ErrNotCommunityPool        = errors.New("only community pools can trade")
+	ErrInvalidPoolOrder        = errors.New("invalid pool order")
+	ErrMarketNotAllowed        = errors.New("market not allowed for pool")
+	ErrPoolLeverageExceeded    = errors.New("pool leverage limit exceeded")
+	ErrInsufficientPoolCapital = errors.New("insufficient idle pool capital")
+)
+
+// PoolTradingAccount returns the address a community pool trades from. It is
+// derived from the module name and pool ID, so every pool holds its positions
+// in its own perpetual account.
+func PoolTradingAccount(poolID string) string {
+	return sdk.AccAddress(address.Module(ModuleName, []byte(poolID))).String()
+}
+
+// OrderFill is the orderbook's outcome for an order placed by a pool
+type OrderFill struct {
+	OrderID   string
+	Status    string
+	FilledQty math.LegacyDec
+	AvgPrice  math.LegacyDec
+	Trades    []TradeFill
+}
+
+// TradeFill is one trade matched for a pool order
+type TradeFill struct {
+	TradeID  string
+	Price    math.LegacyDec
+	Quantity math.LegacyDec
+	Fee      math.LegacyDec
+}
+
+// MaxLeverageOrDefault returns the pool's leverage cap, DefaultPoolMaxLeverage
+// when none is configured
+func (p *Pool) MaxLeverageOrDefault() math.LegacyDec {
+	if p.MaxLeverage.IsNil() || !p.MaxLeverage.IsPositive() {
+		return DefaultPoolMaxLeverage
+	}
+	return p.MaxLeverage
+}
+
+// IsMarketAllowed reports whether the owner may trade a market. An empty
+// AllowedMarkets list allows every market.
+func (p *Pool) IsMarketAllowed(marketID string) bool {
+	if len(p.AllowedMarkets) == 0 {
+		return true
+	}
+	for _, m := range p.AllowedMarkets {
+		if m == marketID {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateOrder checks an owner order against the pool's market whitelist and
+// leverage cap
+func (p *Pool) ValidateOrder(marketID, side string, quantity, leverage math.LegacyDec) error {
+	if side != OrderSideBuy && side != OrderSideSell {
+		return fmt.Errorf("%w: side must be %q or %q", ErrInvalidPoolOrder, OrderSideBuy, OrderSideSell)
+	}
+	if quantity.IsNil() || !quantity.IsPositive() {
+		return fmt.Errorf("%w: quantity must be positive", ErrInvalidPoolOrder)
+	}
+	if leverage.IsNil() || !leverage.IsPositive() {
+		return fmt.Errorf("%w: leverage must be positive", ErrInvalidPoolOrder)
+	}
+	if !p.IsMarketAllowed(marketID) {
+		return fmt.Errorf("%w: %s", ErrMarketNotAllowed, marketID)
+	}
+	if maxLeverage := p.MaxLeverageOrDefault(); leverage.GT(maxLeverage) {
+		return fmt.Errorf("%w: %s > %s", ErrPoolLeverageExceeded, leverage, maxLeverage)
+	}
+	return nil
+}
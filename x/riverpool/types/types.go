@@ -82,6 +82,7 @@ var (
 	ErrWithdrawalNotFound     = errors.New("withdrawal not found")
 	ErrInvalidInviteCode      = errors.New("invalid invite code for private pool")
 	ErrFoundationPoolFull     = errors.New("foundation pool is full")
+	ErrFoundationSeatIneligible = errors.New("market maker missed its quoting obligations in the last epoch")
 	ErrOwnerStakeTooLow       = errors.New("owner stake must be at least 5%")
 	ErrUnauthorized           = errors.New("unauthorized")
 	ErrDDGuardHalt            = errors.New("pool trading halted due to DDGuard")
@@ -93,6 +94,10 @@ var (
 	ErrInvalidManagementFee   = errors.New("invalid management fee (max 5%)")
 	ErrInvalidPerformanceFee  = errors.New("invalid performance fee (max 50%)")
 	ErrInvalidRedemptionLimit = errors.New("invalid daily redemption limit")
+	ErrInsufficientFreeCollateral = errors.New("insufficient free collateral in trading account")
+	ErrPoolHolderLimit        = errors.New("pool holder limit reached")
+	ErrInvalidMaxHolders      = errors.New("invalid maximum holder count")
+	ErrInvalidNAVPricingConfig = errors.New("invalid NAV pricing config")
 )
 
 // Pool represents a liquidity pool
@@ -131,6 +136,7 @@ type Pool struct {
 	IsPrivate          bool           `json:"is_private,omitempty"`
 	InviteCode         string         `json:"invite_code,omitempty"`
 	TotalHolders       int64          `json:"total_holders,omitempty"`        // Number of unique depositors
+	MaxHolders         int64          `json:"max_holders,omitempty"`          // Holder cap, 0 = unlimited
 	AllowedMarkets     []string       `json:"allowed_markets,omitempty"`      // Markets owner can trade
 	MaxLeverage        math.LegacyDec `json:"max_leverage,omitempty"`         // Max leverage allowed (e.g., 10)
 	Tags               []string       `json:"tags,omitempty"`                 // Pool tags for discovery
@@ -401,6 +407,14 @@ type NAVHistory struct {
 	NAV        math.LegacyDec `json:"nav"`
 	TotalValue math.LegacyDec `json:"total_value"`
 	Timestamp  int64          `json:"timestamp"`
+
+	// Look-through breakdown for pools that allocate to other pools
+	Cash           math.LegacyDec `json:"cash"`
+	AllocatedValue math.LegacyDec `json:"allocated_value"`
+
+	// Unrealized PnL of the pool's positions and how each was priced
+	PositionPnL math.LegacyDec      `json:"position_pnl"`
+	Positions   []*NAVPositionPrice `json:"positions,omitempty"`
 }
 
 // RevenueRecord tracks revenue sources for a pool
@@ -441,6 +455,18 @@ type InviteCode struct {
 	CreatedAt  int64  `json:"created_at"`
 	ExpiresAt  int64  `json:"expires_at"`  // 0 = never expires
 	IsActive   bool   `json:"is_active"`
+	RevokedAt  int64  `json:"revoked_at"`  // 0 = not revoked
+}
+
+// InviteRedemption records the deposit that admitted an address to a private
+// pool with an invite code
+type InviteRedemption struct {
+	Code       string
+	PoolID     string
+	Address    string
+	DepositID  string
+	Amount     math.LegacyDec
+	RedeemedAt int64
 }
 
 // NewInviteCode creates a new invite code
@@ -569,6 +595,7 @@ type CommunityPoolConfig struct {
 	RedemptionDelayDays  int64          `json:"redemption_delay_days"`
 	DailyRedemptionLimit math.LegacyDec `json:"daily_redemption_limit"`
 	IsPrivate            bool           `json:"is_private"`
+	MaxHolders           int64          `json:"max_holders"` // 0 = unlimited
 	MaxLeverage          math.LegacyDec `json:"max_leverage"`
 	AllowedMarkets       []string       `json:"allowed_markets"`
 	Tags                 []string       `json:"tags"`
@@ -634,6 +661,7 @@ func NewCommunityPool(config *CommunityPoolConfig) (*Pool, error) {
 		OwnerCurrentStake:    math.LegacyZeroDec(),
 		IsPrivate:            config.IsPrivate,
 		TotalHolders:         0,
+		MaxHolders:           config.MaxHolders,
 		AllowedMarkets:       config.AllowedMarkets,
 		MaxLeverage:          config.MaxLeverage,
 		Tags:                 config.Tags,
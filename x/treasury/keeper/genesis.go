@@ -0,0 +1,43 @@
+package keeper
+
+import (
+	"encoding/binary"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/treasury/types"
+)
+
+// InitGenesis imports the treasury params, balance and ledger. New entries
+// continue after the highest imported sequence number.
+func (k *Keeper) InitGenesis(ctx sdk.Context, gs *types.GenesisState) error {
+	if err := gs.Validate(); err != nil {
+		return err
+	}
+
+	if err := k.SetParams(ctx, gs.Params); err != nil {
+		return err
+	}
+	if gs.Balance != nil {
+		k.setBalance(ctx, gs.Balance)
+	}
+	var last uint64
+	for _, entry := range gs.Entries {
+		k.setEntry(ctx, entry)
+		if entry.Seq > last {
+			last = entry.Seq
+		}
+	}
+	if last > 0 {
+		k.GetStore(ctx).Set(EntryCounterKey, binary.BigEndian.AppendUint64(nil, last))
+	}
+	return nil
+}
+
+// ExportGenesis exports the treasury params, balance and ledger
+func (k *Keeper) ExportGenesis(ctx sdk.Context) *types.GenesisState {
+	return &types.GenesisState{
+		Params:  k.GetParams(ctx),
+		Balance: k.GetBalance(ctx),
+		Entries: k.GetAllEntries(ctx),
+	}
+}
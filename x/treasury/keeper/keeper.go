@@ -0,0 +1,262 @@
+package keeper
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/treasury/types"
+)
+
+// Store key prefixes
+var (
+	ParamsKey       = []byte{0x01}
+	BalanceKey      = []byte{0x02}
+	EntryKeyPrefix  = []byte{0x03} // big-endian seq -> Entry
+	EntryCounterKey = []byte{0x04}
+)
+
+// PerpetualKeeper defines the expected interface for the perpetual module,
+// which holds the margin accounts spends are paid into
+type PerpetualKeeper interface {
+	Deposit(ctx context.Context, trader string, amount math.LegacyDec) error
+}
+
+// Keeper manages the protocol treasury: the fee distribution parameters, the
+// treasury balance and its ledger of fee credits and spends
+type Keeper struct {
+	cdc             codec.BinaryCodec
+	storeKey        storetypes.StoreKey
+	perpetualKeeper PerpetualKeeper
+	logger          log.Logger
+	authority       string // governance authority address
+}
+
+// NewKeeper creates a new treasury keeper
+func NewKeeper(
+	cdc codec.BinaryCodec,
+	storeKey storetypes.StoreKey,
+	perpetualKeeper PerpetualKeeper,
+	authority string,
+	logger log.Logger,
+) *Keeper {
+	return &Keeper{
+		cdc:             cdc,
+		storeKey:        storeKey,
+		perpetualKeeper: perpetualKeeper,
+		authority:       authority,
+		logger:          logger.With("module", "x/treasury"),
+	}
+}
+
+// Logger returns the module logger
+func (k *Keeper) Logger() log.Logger {
+	return k.logger
+}
+
+// GetAuthority returns the governance authority address
+func (k *Keeper) GetAuthority() string {
+	return k.authority
+}
+
+// GetStore returns the KVStore
+func (k *Keeper) GetStore(ctx sdk.Context) storetypes.KVStore {
+	return ctx.KVStore(k.storeKey)
+}
+
+func entryKey(seq uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte{}, EntryKeyPrefix...), seq)
+}
+
+// ============ Params ============
+
+// GetParams returns the fee distribution parameters, the defaults if none are set
+func (k *Keeper) GetParams(ctx sdk.Context) types.Params {
+	bz := k.GetStore(ctx).Get(ParamsKey)
+	if bz == nil {
+		return types.DefaultParams()
+	}
+	var params types.Params
+	if err := json.Unmarshal(bz, &params); err != nil {
+		return types.DefaultParams()
+	}
+	return params
+}
+
+// SetParams validates and saves the fee distribution parameters
+func (k *Keeper) SetParams(ctx sdk.Context, params types.Params) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	bz, _ := json.Marshal(params)
+	k.GetStore(ctx).Set(ParamsKey, bz)
+	return nil
+}
+
+// FeeSplit returns the share of every trading fee each destination receives,
+// for the orderbook fee ledger
+func (k *Keeper) FeeSplit(ctx sdk.Context) (insuranceFund, riverPool, treasury math.LegacyDec) {
+	params := k.GetParams(ctx)
+	return params.InsuranceFund, params.RiverPool, params.Treasury
+}
+
+// ============ Balance ============
+
+// GetBalance returns the treasury balance
+func (k *Keeper) GetBalance(ctx sdk.Context) *types.Balance {
+	bz := k.GetStore(ctx).Get(BalanceKey)
+	if bz == nil {
+		return types.NewBalance()
+	}
+	var balance types.Balance
+	if err := json.Unmarshal(bz, &balance); err != nil {
+		return types.NewBalance()
+	}
+	return &balance
+}
+
+func (k *Keeper) setBalance(ctx sdk.Context, balance *types.Balance) {
+	bz, _ := json.Marshal(balance)
+	k.GetStore(ctx).Set(BalanceKey, bz)
+}
+
+// CreditFees adds the treasury share of a market's fees for a settled
+// orderbook fee day
+func (k *Keeper) CreditFees(ctx sdk.Context, marketID string, day int64, amount math.LegacyDec) error {
+	if amount.IsNil() || !amount.IsPositive() {
+		return fmt.Errorf("%w: fee credit must be positive", types.ErrInvalidAmount)
+	}
+
+	balance := k.GetBalance(ctx)
+	balance.Balance = balance.Balance.Add(amount)
+	balance.TotalReceived = balance.TotalReceived.Add(amount)
+	k.setBalance(ctx, balance)
+	entry := k.appendEntry(ctx, &types.Entry{
+		Kind:     types.EntryFees,
+		Amount:   amount,
+		Balance:  balance.Balance,
+		MarketID: marketID,
+		Day:      day,
+	})
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"treasury_fees_credited",
+			sdk.NewAttribute("seq", fmt.Sprint(entry.Seq)),
+			sdk.NewAttribute("market_id", marketID),
+			sdk.NewAttribute("amount", amount.String()),
+			sdk.NewAttribute("balance", balance.Balance.String()),
+		),
+	)
+	return nil
+}
+
+// Spend pays amount out of the treasury into the recipient's margin account
+func (k *Keeper) Spend(ctx sdk.Context, recipient string, amount math.LegacyDec, reason string) (*types.Entry, error) {
+	if recipient == "" {
+		return nil, types.ErrInvalidRecipient
+	}
+	if amount.IsNil() || !amount.IsPositive() {
+		return nil, fmt.Errorf("%w: spend must be positive", types.ErrInvalidAmount)
+	}
+	balance := k.GetBalance(ctx)
+	if amount.GT(balance.Balance) {
+		return nil, fmt.Errorf("%w: spending %s of %s", types.ErrInsufficientFunds, amount, balance.Balance)
+	}
+	if err := k.perpetualKeeper.Deposit(ctx, recipient, amount); err != nil {
+		return nil, err
+	}
+
+	balance.Balance = balance.Balance.Sub(amount)
+	balance.TotalSpent = balance.TotalSpent.Add(amount)
+	k.setBalance(ctx, balance)
+	entry := k.appendEntry(ctx, &types.Entry{
+		Kind:      types.EntrySpend,
+		Amount:    amount,
+		Balance:   balance.Balance,
+		Recipient: recipient,
+		Reason:    reason,
+	})
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			"treasury_spend",
+			sdk.NewAttribute("seq", fmt.Sprint(entry.Seq)),
+			sdk.NewAttribute("recipient", recipient),
+			sdk.NewAttribute("amount", amount.String()),
+			sdk.NewAttribute("reason", reason),
+			sdk.NewAttribute("balance", balance.Balance.String()),
+		),
+	)
+	return entry, nil
+}
+
+// ============ History ============
+
+// appendEntry numbers an entry and appends it to the ledger
+func (k *Keeper) appendEntry(ctx sdk.Context, entry *types.Entry) *types.Entry {
+	store := k.GetStore(ctx)
+	var seq uint64
+	if bz := store.Get(EntryCounterKey); bz != nil {
+		seq = binary.BigEndian.Uint64(bz)
+	}
+	seq++
+	store.Set(EntryCounterKey, binary.BigEndian.AppendUint64(nil, seq))
+
+	entry.Seq = seq
+	entry.BlockHeight = ctx.BlockHeight()
+	entry.Timestamp = ctx.BlockTime()
+	k.setEntry(ctx, entry)
+	return entry
+}
+
+func (k *Keeper) setEntry(ctx sdk.Context, entry *types.Entry) {
+	bz, _ := json.Marshal(entry)
+	k.GetStore(ctx).Set(entryKey(entry.Seq), bz)
+}
+
+// GetHistory returns up to limit ledger entries with a sequence number below
+// before (0 for the latest), newest first, optionally of one kind
+func (k *Keeper) GetHistory(ctx sdk.Context, kind types.EntryKind, before uint64, limit int) []*types.Entry {
+	end := storetypes.PrefixEndBytes(EntryKeyPrefix)
+	if before > 0 {
+		end = entryKey(before)
+	}
+	iterator := k.GetStore(ctx).ReverseIterator(EntryKeyPrefix, end)
+	defer iterator.Close()
+
+	entries := make([]*types.Entry, 0)
+	for ; iterator.Valid() && len(entries) < limit; iterator.Next() {
+		var entry types.Entry
+		if err := json.Unmarshal(iterator.Value(), &entry); err != nil {
+			continue
+		}
+		if kind != "" && entry.Kind != kind {
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	return entries
+}
+
+// GetAllEntries returns the whole ledger, oldest first
+func (k *Keeper) GetAllEntries(ctx sdk.Context) []*types.Entry {
+	iterator := storetypes.KVStorePrefixIterator(k.GetStore(ctx), EntryKeyPrefix)
+	defer iterator.Close()
+
+	entries := make([]*types.Entry, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var entry types.Entry
+		if err := json.Unmarshal(iterator.Value(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	return entries
+}
@@ -0,0 +1,158 @@
+package keeper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	"cosmossdk.io/store"
+	"cosmossdk.io/store/metrics"
+	storetypes "cosmossdk.io/store/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/treasury/types"
+)
+
+const testAuthority = "cosmos10d07y265gmmuvt4z0w9aw880jnsr700j6zn9kn"
+
+// mockPerpetualKeeper records the margin deposits spends make
+type mockPerpetualKeeper struct {
+	deposits map[string]math.LegacyDec
+}
+
+func (m *mockPerpetualKeeper) Deposit(ctx context.Context, trader string, amount math.LegacyDec) error {
+	m.deposits[trader] = amount
+	return nil
+}
+
+// setupKeeper returns a store-backed treasury keeper
+func setupKeeper(t *testing.T) (*Keeper, *mockPerpetualKeeper, sdk.Context) {
+	t.Helper()
+
+	storeKey := storetypes.NewKVStoreKey(types.StoreKey)
+	db := dbm.NewMemDB()
+	stateStore := store.NewCommitMultiStore(db, log.NewNopLogger(), metrics.NewNoOpMetrics())
+	stateStore.MountStoreWithDB(storeKey, storetypes.StoreTypeIAVL, db)
+	if err := stateStore.LoadLatestVersion(); err != nil {
+		t.Fatalf("failed to load store: %v", err)
+	}
+
+	header := cmtproto.Header{Height: 10, Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ctx := sdk.NewContext(stateStore, header, false, log.NewNopLogger())
+	cdc := codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+
+	perp := &mockPerpetualKeeper{deposits: make(map[string]math.LegacyDec)}
+	return NewKeeper(cdc, storeKey, perp, testAuthority, log.NewNopLogger()), perp, ctx
+}
+
+// TestTreasuryLedger tests fee credits, spends and the history
+func TestTreasuryLedger(t *testing.T) {
+	k, perp, ctx := setupKeeper(t)
+
+	if err := k.CreditFees(ctx, "BTC-USDC", 19723, math.LegacyNewDec(300)); err != nil {
+		t.Fatalf("failed to credit fees: %v", err)
+	}
+	if err := k.CreditFees(ctx, "ETH-USDC", 19723, math.LegacyNewDec(200)); err != nil {
+		t.Fatalf("failed to credit fees: %v", err)
+	}
+
+	if _, err := k.Spend(ctx, "grantee", math.LegacyNewDec(600), "too much"); !errors.Is(err, types.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+	entry, err := k.Spend(ctx, "grantee", math.LegacyNewDec(150), "audit grant")
+	if err != nil {
+		t.Fatalf("failed to spend: %v", err)
+	}
+	if entry.Seq != 3 || !entry.Balance.Equal(math.LegacyNewDec(350)) {
+		t.Errorf("unexpected spend entry %+v", entry)
+	}
+	if !perp.deposits["grantee"].Equal(math.LegacyNewDec(150)) {
+		t.Errorf("expected the grantee to be paid 150, got %s", perp.deposits["grantee"])
+	}
+
+	balance := k.GetBalance(ctx)
+	if !balance.Balance.Equal(math.LegacyNewDec(350)) || !balance.TotalReceived.Equal(math.LegacyNewDec(500)) ||
+		!balance.TotalSpent.Equal(math.LegacyNewDec(150)) {
+		t.Errorf("unexpected balance %+v", balance)
+	}
+
+	history := k.GetHistory(ctx, "", 0, 10)
+	if len(history) != 3 || history[0].Kind != types.EntrySpend || history[2].MarketID != "BTC-USDC" {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	if fees := k.GetHistory(ctx, types.EntryFees, 0, 10); len(fees) != 2 {
+		t.Errorf("expected 2 fee entries, got %d", len(fees))
+	}
+	if page := k.GetHistory(ctx, "", 3, 1); len(page) != 1 || page[0].Seq != 2 {
+		t.Errorf("expected entry 2 before 3, got %+v", page)
+	}
+}
+
+// TestTreasuryMsgServer tests that only the authority can change the fee
+// split and spend, and that an invalid split is rejected
+func TestTreasuryMsgServer(t *testing.T) {
+	k, _, ctx := setupKeeper(t)
+	srv := NewMsgServerImpl(k)
+
+	params := types.Params{
+		InsuranceFund: math.LegacyNewDecWithPrec(1, 1),
+		RiverPool:     math.LegacyNewDecWithPrec(6, 1),
+		Treasury:      math.LegacyNewDecWithPrec(3, 1),
+	}
+	if _, err := srv.UpdateParams(ctx, &types.MsgUpdateParams{Authority: "someone", Params: params}); !errors.Is(err, types.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	invalid := params
+	invalid.Treasury = math.LegacyNewDecWithPrec(4, 1)
+	if _, err := srv.UpdateParams(ctx, &types.MsgUpdateParams{Authority: testAuthority, Params: invalid}); !errors.Is(err, types.ErrInvalidParams) {
+		t.Fatalf("expected ErrInvalidParams, got %v", err)
+	}
+	if _, err := srv.UpdateParams(ctx, &types.MsgUpdateParams{Authority: testAuthority, Params: params}); err != nil {
+		t.Fatalf("failed to update params: %v", err)
+	}
+	if _, riverPool, _ := k.FeeSplit(ctx); !riverPool.Equal(params.RiverPool) {
+		t.Errorf("expected riverpool share %s, got %s", params.RiverPool, riverPool)
+	}
+
+	if _, err := srv.Spend(ctx, &types.MsgSpend{Authority: "someone", Recipient: "grantee", Amount: "1"}); !errors.Is(err, types.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if _, err := srv.Spend(ctx, &types.MsgSpend{Authority: testAuthority, Recipient: "grantee", Amount: "-1"}); !errors.Is(err, types.ErrInvalidAmount) {
+		t.Fatalf("expected ErrInvalidAmount, got %v", err)
+	}
+}
+
+// TestTreasuryGenesis tests that an exported treasury imports unchanged and
+// new entries continue its sequence
+func TestTreasuryGenesis(t *testing.T) {
+	k, _, ctx := setupKeeper(t)
+	if err := k.CreditFees(ctx, "BTC-USDC", 19723, math.LegacyNewDec(100)); err != nil {
+		t.Fatalf("failed to credit fees: %v", err)
+	}
+	exported := k.ExportGenesis(ctx)
+
+	imported, _, ctx2 := setupKeeper(t)
+	if err := imported.InitGenesis(ctx2, exported); err != nil {
+		t.Fatalf("failed to import genesis: %v", err)
+	}
+	if !imported.GetBalance(ctx2).Balance.Equal(math.LegacyNewDec(100)) {
+		t.Errorf("expected imported balance 100, got %s", imported.GetBalance(ctx2).Balance)
+	}
+	if err := imported.CreditFees(ctx2, "ETH-USDC", 19723, math.LegacyNewDec(1)); err != nil {
+		t.Fatalf("failed to credit fees: %v", err)
+	}
+	if latest := imported.GetHistory(ctx2, "", 0, 1); latest[0].Seq != 2 {
+		t.Errorf("expected the next entry to be 2, got %d", latest[0].Seq)
+	}
+
+	exported.Entries = append(exported.Entries, exported.Entries[0])
+	if err := exported.Validate(); !errors.Is(err, types.ErrInvalidGenesis) {
+		t.Errorf("expected duplicate entries to be rejected, got %v", err)
+	}
+}
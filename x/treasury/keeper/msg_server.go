@@ -0,0 +1,63 @@
+package keeper
+
+import (
+	"context"
+	"fmt"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/openalpha/perp-dex/x/treasury/types"
+)
+
+var _ types.MsgServer = (*msgServer)(nil)
+
+type msgServer struct {
+	Keeper *Keeper
+}
+
+// NewMsgServerImpl returns an implementation of the MsgServer interface
+func NewMsgServerImpl(keeper *Keeper) types.MsgServer {
+	return &msgServer{Keeper: keeper}
+}
+
+// UpdateParams handles the MsgUpdateParams governance message
+func (m *msgServer) UpdateParams(ctx context.Context, msg *types.MsgUpdateParams) (*types.MsgUpdateParamsResponse, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	if msg.Authority != m.Keeper.GetAuthority() {
+		return nil, fmt.Errorf("%w: expected %s, got %s", types.ErrUnauthorized, m.Keeper.GetAuthority(), msg.Authority)
+	}
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
+	if err := m.Keeper.SetParams(sdkCtx, msg.Params); err != nil {
+		return nil, err
+	}
+	return &types.MsgUpdateParamsResponse{}, nil
+}
+
+// Spend handles the MsgSpend governance message of a passed spend proposal
+func (m *msgServer) Spend(ctx context.Context, msg *types.MsgSpend) (*types.MsgSpendResponse, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	if msg.Authority != m.Keeper.GetAuthority() {
+		return nil, fmt.Errorf("%w: expected %s, got %s", types.ErrUnauthorized, m.Keeper.GetAuthority(), msg.Authority)
+	}
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
+	amount, err := math.LegacyNewDecFromStr(msg.Amount)
+	if err != nil {
+		return nil, types.ErrInvalidAmount
+	}
+	entry, err := m.Keeper.Spend(sdkCtx, msg.Recipient, amount, msg.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return &types.MsgSpendResponse{
+		Seq:     entry.Seq,
+		Balance: entry.Balance.String(),
+	}, nil
+}
@@ -0,0 +1,15 @@
+package types
+
+import (
+	"cosmossdk.io/errors"
+)
+
+// Module error codes
+var (
+	ErrUnauthorized      = errors.Register("treasury", 1, "unauthorized")
+	ErrInvalidParams     = errors.Register("treasury", 2, "invalid treasury params")
+	ErrInvalidAmount     = errors.Register("treasury", 3, "invalid amount")
+	ErrInvalidRecipient  = errors.Register("treasury", 4, "invalid recipient")
+	ErrInsufficientFunds = errors.Register("treasury", 5, "insufficient treasury balance")
+	ErrInvalidGenesis    = errors.Register("treasury", 6, "invalid genesis state")
+)
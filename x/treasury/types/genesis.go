@@ -0,0 +1,51 @@
+package types
+
+import (
+	"fmt"
+)
+
+// GenesisState is the treasury module's exported state
+type GenesisState struct {
+	Params  Params   `json:"params"`
+	Balance *Balance `json:"balance,omitempty"`
+	Entries []*Entry `json:"entries"`
+}
+
+// DefaultGenesis returns the default params and an empty treasury
+func DefaultGenesis() *GenesisState {
+	return &GenesisState{
+		Params:  DefaultParams(),
+		Entries: make([]*Entry, 0),
+	}
+}
+
+// Validate checks the params, that the balance is not negative and that
+// entry sequence numbers are unique
+func (gs *GenesisState) Validate() error {
+	if err := gs.Params.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidGenesis, err)
+	}
+	if b := gs.Balance; b != nil {
+		if b.Balance.IsNil() || b.TotalReceived.IsNil() || b.TotalSpent.IsNil() {
+			return fmt.Errorf("%w: incomplete treasury balance", ErrInvalidGenesis)
+		}
+		if b.Balance.IsNegative() || b.TotalReceived.IsNegative() || b.TotalSpent.IsNegative() {
+			return fmt.Errorf("%w: negative treasury balance", ErrInvalidGenesis)
+		}
+	}
+
+	seqs := make(map[uint64]bool, len(gs.Entries))
+	for _, entry := range gs.Entries {
+		if entry == nil || entry.Seq == 0 {
+			return fmt.Errorf("%w: entry without sequence number", ErrInvalidGenesis)
+		}
+		if seqs[entry.Seq] {
+			return fmt.Errorf("%w: duplicate entry %d", ErrInvalidGenesis, entry.Seq)
+		}
+		if entry.Kind != EntryFees && entry.Kind != EntrySpend {
+			return fmt.Errorf("%w: entry %d has unknown kind %q", ErrInvalidGenesis, entry.Seq, entry.Kind)
+		}
+		seqs[entry.Seq] = true
+	}
+	return nil
+}
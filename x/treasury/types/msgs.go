@@ -0,0 +1,119 @@
+package types
+
+import (
+	"context"
+
+	"cosmossdk.io/math"
+	cdctypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// RegisterInterfaces registers the module's interface types
+func RegisterInterfaces(registry cdctypes.InterfaceRegistry) {
+	registry.RegisterImplementations((*sdk.Msg)(nil),
+		&MsgUpdateParams{},
+		&MsgSpend{},
+	)
+}
+
+// Message types for treasury module
+const (
+	TypeMsgUpdateParams = "update_params"
+	TypeMsgSpend        = "spend"
+)
+
+// MsgServer defines the treasury module's gRPC message service
+type MsgServer interface {
+	UpdateParams(context.Context, *MsgUpdateParams) (*MsgUpdateParamsResponse, error)
+	Spend(context.Context, *MsgSpend) (*MsgSpendResponse, error)
+}
+
+// MsgUpdateParams replaces the fee distribution parameters through governance.
+// The new split applies to fees charged from then on.
+type MsgUpdateParams struct {
+	Authority string `json:"authority"`
+	Params    Params `json:"params"`
+}
+
+// Proto interface implementations for MsgUpdateParams
+func (msg *MsgUpdateParams) Reset()         { *msg = MsgUpdateParams{} }
+func (msg *MsgUpdateParams) String() string { return msg.Authority }
+func (msg *MsgUpdateParams) ProtoMessage()  {}
+
+// XXX_MessageName returns the message type URL for MsgUpdateParams
+func (msg *MsgUpdateParams) XXX_MessageName() string {
+	return "perpdex.treasury.v1.MsgUpdateParams"
+}
+
+// ValidateBasic for MsgUpdateParams
+func (msg *MsgUpdateParams) ValidateBasic() error {
+	if msg.Authority == "" {
+		return ErrUnauthorized
+	}
+	return msg.Params.Validate()
+}
+
+// GetSigners returns the signer addresses for MsgUpdateParams
+func (msg *MsgUpdateParams) GetSigners() []sdk.AccAddress {
+	authority, _ := sdk.AccAddressFromBech32(msg.Authority)
+	return []sdk.AccAddress{authority}
+}
+
+// MsgUpdateParamsResponse is the response for MsgUpdateParams
+type MsgUpdateParamsResponse struct{}
+
+// Proto interface implementations for MsgUpdateParamsResponse
+func (msg *MsgUpdateParamsResponse) Reset()         { *msg = MsgUpdateParamsResponse{} }
+func (msg *MsgUpdateParamsResponse) String() string { return "" }
+func (msg *MsgUpdateParamsResponse) ProtoMessage()  {}
+
+// MsgSpend pays out of the treasury into the recipient's margin account. It
+// is the message a passed treasury spend proposal executes.
+type MsgSpend struct {
+	Authority string `json:"authority"`
+	Recipient string `json:"recipient"`
+	Amount    string `json:"amount"`
+	Reason    string `json:"reason"`
+}
+
+// Proto interface implementations for MsgSpend
+func (msg *MsgSpend) Reset()         { *msg = MsgSpend{} }
+func (msg *MsgSpend) String() string { return msg.Recipient }
+func (msg *MsgSpend) ProtoMessage()  {}
+
+// XXX_MessageName returns the message type URL for MsgSpend
+func (msg *MsgSpend) XXX_MessageName() string {
+	return "perpdex.treasury.v1.MsgSpend"
+}
+
+// ValidateBasic for MsgSpend
+func (msg *MsgSpend) ValidateBasic() error {
+	if msg.Authority == "" {
+		return ErrUnauthorized
+	}
+	if msg.Recipient == "" {
+		return ErrInvalidRecipient
+	}
+	amount, err := math.LegacyNewDecFromStr(msg.Amount)
+	if err != nil || !amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+// GetSigners returns the signer addresses for MsgSpend
+func (msg *MsgSpend) GetSigners() []sdk.AccAddress {
+	authority, _ := sdk.AccAddressFromBech32(msg.Authority)
+	return []sdk.AccAddress{authority}
+}
+
+// MsgSpendResponse is the response for MsgSpend
+type MsgSpendResponse struct {
+	Seq     uint64 `json:"seq"`     // ledger entry of the spend
+	Balance string `json:"balance"` // treasury balance after the spend
+}
+
+// Proto interface implementations for MsgSpendResponse
+func (msg *MsgSpendResponse) Reset()         { *msg = MsgSpendResponse{} }
+func (msg *MsgSpendResponse) String() string { return msg.Balance }
+func (msg *MsgSpendResponse) ProtoMessage()  {}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// Module name and store key
+const (
+	ModuleName = "treasury"
+	StoreKey   = ModuleName
+)
+
+// Params are the governance-set fee distribution parameters: the share of
+// every trading fee each destination receives. The orderbook splits fees by
+// them while a treasury is attached.
+type Params struct {
+	InsuranceFund math.LegacyDec `json:"insurance_fund"`
+	RiverPool     math.LegacyDec `json:"riverpool"`
+	Treasury      math.LegacyDec `json:"treasury"`
+}
+
+// DefaultParams sends 20% of fees to the insurance fund, 50% to the
+// riverpools and 30% to the treasury
+func DefaultParams() Params {
+	return Params{
+		InsuranceFund: math.LegacyNewDecWithPrec(2, 1),
+		RiverPool:     math.LegacyNewDecWithPrec(5, 1),
+		Treasury:      math.LegacyNewDecWithPrec(3, 1),
+	}
+}
+
+// Validate checks that the shares are non-negative and sum to one
+func (p Params) Validate() error {
+	for _, share := range []math.LegacyDec{p.InsuranceFund, p.RiverPool, p.Treasury} {
+		if share.IsNil() || share.IsNegative() {
+			return fmt.Errorf("%w: shares must be non-negative", ErrInvalidParams)
+		}
+	}
+	if !p.InsuranceFund.Add(p.RiverPool).Add(p.Treasury).Equal(math.LegacyOneDec()) {
+		return fmt.Errorf("%w: shares must sum to 1", ErrInvalidParams)
+	}
+	return nil
+}
+
+// EntryKind is the direction of a treasury ledger entry
+type EntryKind string
+
+const (
+	EntryFees  EntryKind = "fees"  // the treasury share of a market's fees for a day
+	EntrySpend EntryKind = "spend" // a governance-approved spend
+)
+
+// Entry is one credit to or debit from the treasury
+type Entry struct {
+	Seq         uint64         `json:"seq"`
+	Kind        EntryKind      `json:"kind"`
+	Amount      math.LegacyDec `json:"amount"`
+	Balance     math.LegacyDec `json:"balance"`             // treasury balance after the entry
+	MarketID    string         `json:"market_id,omitempty"` // fees only
+	Day         int64          `json:"day,omitempty"`       // fees only: the orderbook fee day
+	Recipient   string         `json:"recipient,omitempty"` // spends only
+	Reason      string         `json:"reason,omitempty"`    // spends only
+	BlockHeight int64          `json:"block_height"`
+	Timestamp   time.Time      `json:"timestamp"`
+}
+
+// Balance is the treasury's current balance and what it has received and
+// spent in total
+type Balance struct {
+	Balance       math.LegacyDec `json:"balance"`
+	TotalReceived math.LegacyDec `json:"total_received"`
+	TotalSpent    math.LegacyDec `json:"total_spent"`
+}
+
+// NewBalance creates an empty balance
+func NewBalance() *Balance {
+	return &Balance{
+		Balance:       math.LegacyZeroDec(),
+		TotalReceived: math.LegacyZeroDec(),
+		TotalSpent:    math.LegacyZeroDec(),
+	}
+}